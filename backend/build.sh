@@ -8,8 +8,21 @@ sqlc compile
 
 sqlc generate
 
+# With EMBED_WEB=1 the web app is exported and embedded into the binary,
+# served when WEB_APP=true. Its assets are renamed by content hash and
+# precompressed, which needs the brotli CLI.
+TAGS=""
+if [ "${EMBED_WEB:-0}" = "1" ]; then
+    echo "Exporting web app..."
+    (cd ../brewd-mobile && npx expo export --platform web --output-dir dist)
+    rm -rf internal/webapp/dist
+    cp -r ../brewd-mobile/dist internal/webapp/dist
+    go run ./cmd/webassets internal/webapp/dist
+    TAGS="embedweb"
+fi
+
 echo "Building backend..."
 
-go build -o bin/brewd-backend ./cmd/server
+go build -tags "$TAGS" -o bin/brewd-backend ./cmd/server
 
-echo "Backend build complete!"
\ No newline at end of file
+echo "Backend build complete!"
@@ -0,0 +1,174 @@
+// Command mediamigrate moves brewd's stored media between object stores,
+// or queues brew photos to be processed again. It reads the server's
+// environment: the database settings, OBJECT_STORE_URL and its keys.
+//
+// copy copies every photo upload, rendition and backup dump to the store
+// at -to, checking each copy's SHA-256, then the server can be pointed at
+// it. Runs resume where the last stopped; run it once more with uploads
+// paused to catch objects written meanwhile, then switch OBJECT_STORE_URL.
+// DESTINATION_ACCESS_KEY and DESTINATION_SECRET_KEY sign requests to an S3
+// destination, defaulting to the source store's keys.
+//
+//	go run ./cmd/mediamigrate copy -to s3://brewd-media?region=eu-west-1
+//
+// reprocess queues photos with the given statuses to be rendered, scanned
+// and screened again from their originals by the servers' photo processors.
+//
+//	go run ./cmd/mediamigrate reprocess -status failed,rejected
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/logger"
+	"brewd/internal/mediamigrate"
+	"brewd/internal/objectstore"
+	"brewd/internal/photos"
+	"brewd/pkg/database"
+)
+
+const usage = `usage:
+  mediamigrate copy -to <object store URL> [-from <object store URL>] [-workers 4] [-restart]
+  mediamigrate reprocess [-status failed]`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	logger.Init(os.Getenv("LOG_LEVEL"))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var err error
+	switch os.Args[1] {
+	case "copy":
+		err = runCopy(ctx, os.Args[2:])
+	case "reprocess":
+		err = runReprocess(ctx, os.Args[2:])
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "mediamigrate:", err)
+		os.Exit(1)
+	}
+}
+
+func runCopy(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("copy", flag.ExitOnError)
+	from := flags.String("from", os.Getenv("OBJECT_STORE_URL"), "object store to copy from")
+	to := flags.String("to", "", "object store to copy to")
+	workers := flags.Int("workers", 4, "objects to copy at once")
+	restart := flags.Bool("restart", false, "copy everything again, forgetting earlier runs to the destination")
+	interval := flags.Duration("progress", 10*time.Second, "how often to report progress")
+	flags.Parse(args)
+
+	if *from == "" || *to == "" {
+		return errors.New("-from (or OBJECT_STORE_URL) and -to are required")
+	}
+	if *from == *to {
+		return errors.New("source and destination are the same store")
+	}
+	if *interval <= 0 {
+		return errors.New("-progress must be positive")
+	}
+	accessKey, secretKey := os.Getenv("OBJECT_STORE_ACCESS_KEY"), os.Getenv("OBJECT_STORE_SECRET_KEY")
+	source, err := objectstore.New(*from, accessKey, secretKey)
+	if err != nil {
+		return fmt.Errorf("invalid source store: %w", err)
+	}
+	destination, err := objectstore.New(*to, envOr("DESTINATION_ACCESS_KEY", accessKey), envOr("DESTINATION_SECRET_KEY", secretKey))
+	if err != nil {
+		return fmt.Errorf("invalid destination store: %w", err)
+	}
+
+	queries, closeDB, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	migrator := mediamigrate.New(queries, source, destination, *to, *workers)
+	if *restart {
+		if err := migrator.Reset(ctx); err != nil {
+			return err
+		}
+	}
+	progress, err := migrator.Run(ctx, *interval)
+	logger.Info("Media migration finished",
+		"migrated", progress.Migrated,
+		"total", progress.Total,
+		"copied", progress.Copied,
+		"copied_mb", progress.Bytes>>20,
+		"missing", progress.Missing,
+		"failed", progress.Failed,
+	)
+	if err != nil {
+		return err
+	}
+	if progress.Failed > 0 {
+		return fmt.Errorf("%d objects failed to copy; run again to retry them", progress.Failed)
+	}
+	return nil
+}
+
+func runReprocess(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("reprocess", flag.ExitOnError)
+	statuses := flags.String("status", photos.StatusFailed, "comma-separated statuses of the photos to reprocess: ready, failed or rejected")
+	flags.Parse(args)
+
+	var requeue []string
+	for _, status := range strings.Split(*statuses, ",") {
+		switch status = strings.TrimSpace(status); status {
+		case photos.StatusReady, photos.StatusFailed, photos.StatusRejected:
+			requeue = append(requeue, status)
+		default:
+			return fmt.Errorf("can't reprocess %q photos", status)
+		}
+	}
+
+	queries, closeDB, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	n, err := queries.RequeueBrewPhotos(ctx, requeue)
+	if err != nil {
+		return err
+	}
+	logger.Info("Brew photos queued for reprocessing", "photos", n, "statuses", strings.Join(requeue, ","))
+	return nil
+}
+
+// connect opens the database the server's environment configures
+func connect(ctx context.Context) (*db.Queries, func(), error) {
+	dbConfig, err := database.LoadConfigFromEnv()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid database configuration: %w", err)
+	}
+	pool, err := database.NewPool(ctx, dbConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("connecting to database: %w", err)
+	}
+	return db.New(pool), pool.Close, nil
+}
+
+func envOr(key, fallback string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return fallback
+}
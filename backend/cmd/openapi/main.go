@@ -0,0 +1,339 @@
+// Command openapi generates the API's OpenAPI document from the server's
+// route registrations and handlers, which the official Go and TypeScript
+// client SDKs are generated from. It reads cmd/server's newApp for the
+// published routes and their middleware, and each handler for the bodies
+// it binds, the query parameters it reads and what it responds with, so
+// the document changes with the handlers. sdk.sh runs it; CI runs it with
+// -check, failing when the committed document is out of date.
+//
+//	go run ./cmd/openapi -o api/openapi.json
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const (
+	serverPath     = "brewd/cmd/server"
+	validationPath = "brewd/internal/validation"
+	cookiesPath    = "brewd/internal/cookies"
+	i18nPath       = "brewd/internal/i18n"
+)
+
+// apiVersion is the document's version, that of the /api/v1 routes
+const apiVersion = "1.0.0"
+
+func main() {
+	out := flag.String("o", "api/openapi.json", "path of the OpenAPI document")
+	check := flag.Bool("check", false, "fail if the document at -o is out of date instead of writing it")
+	flag.Parse()
+
+	if err := run(*out, *check); err != nil {
+		fmt.Fprintln(os.Stderr, "openapi:", err)
+		os.Exit(1)
+	}
+}
+
+func run(out string, check bool) error {
+	doc, err := generate()
+	if err != nil {
+		return err
+	}
+	encoded, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	encoded = append(encoded, '\n')
+
+	if check {
+		current, err := os.ReadFile(out)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(current, encoded) {
+			return fmt.Errorf("%s is out of date; run go run ./cmd/openapi", out)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(out, encoded, 0o644)
+}
+
+// funcDecl is a function's declaration, with the type information of its
+// package
+type funcDecl struct {
+	decl *ast.FuncDecl
+	info *types.Info
+}
+
+// docs are the doc comments of types and struct fields
+type docs struct {
+	types  map[*types.TypeName]string
+	fields map[*types.Var]string
+}
+
+// program is the loaded server and internal packages
+type program struct {
+	pkgs        map[string]*packages.Package
+	decls       map[*types.Func]funcDecl
+	docs        docs
+	schemas     *schemas
+	meta        *schema
+	invalidCode string
+}
+
+func load() (*program, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports |
+			packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo | packages.NeedDeps,
+	}
+	loaded, err := packages.Load(cfg, "./cmd/server", "./internal/...")
+	if err != nil {
+		return nil, err
+	}
+	if packages.PrintErrors(loaded) > 0 {
+		return nil, errors.New("packages failed to load")
+	}
+
+	p := &program{
+		pkgs:  make(map[string]*packages.Package, len(loaded)),
+		decls: make(map[*types.Func]funcDecl),
+		docs: docs{
+			types:  make(map[*types.TypeName]string),
+			fields: make(map[*types.Var]string),
+		},
+	}
+	for _, pkg := range loaded {
+		p.pkgs[pkg.PkgPath] = pkg
+		for _, file := range pkg.Syntax {
+			p.index(file, pkg.TypesInfo)
+		}
+	}
+	p.schemas = newSchemas(p.docs)
+	return p, nil
+}
+
+// index records a file's function declarations and the doc comments of
+// its types and fields
+func (p *program) index(file *ast.File, info *types.Info) {
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if fn, ok := info.Defs[decl.Name].(*types.Func); ok {
+				p.decls[fn] = funcDecl{decl: decl, info: info}
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				spec, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				doc := spec.Doc
+				if doc == nil && len(decl.Specs) == 1 {
+					doc = decl.Doc
+				}
+				if obj, ok := info.Defs[spec.Name].(*types.TypeName); ok && doc != nil {
+					p.docs.types[obj] = docText(doc)
+				}
+				if st, ok := spec.Type.(*ast.StructType); ok {
+					p.indexFields(st, info)
+				}
+			}
+		}
+	}
+}
+
+func (p *program) indexFields(st *ast.StructType, info *types.Info) {
+	for _, field := range st.Fields.List {
+		doc := field.Doc
+		if doc == nil {
+			doc = field.Comment
+		}
+		for _, name := range field.Names {
+			if obj, ok := info.Defs[name].(*types.Var); ok && doc != nil {
+				p.docs.fields[obj] = docText(doc)
+			}
+		}
+		if nested, ok := field.Type.(*ast.StructType); ok {
+			p.indexFields(nested, info)
+		}
+	}
+}
+
+// lookup returns the package-level object name in the package at path
+func (p *program) lookup(path, name string) (types.Object, error) {
+	pkg, ok := p.pkgs[path]
+	if !ok {
+		return nil, fmt.Errorf("package %s not loaded", path)
+	}
+	obj := pkg.Types.Scope().Lookup(name)
+	if obj == nil {
+		return nil, fmt.Errorf("%s.%s not found", path, name)
+	}
+	return obj, nil
+}
+
+func generate() (*document, error) {
+	p, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	serverPkg := p.pkgs[serverPath]
+	var appDecl *ast.FuncDecl
+	for _, file := range serverPkg.Syntax {
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name.Name == appFunc && fn.Recv == nil {
+				appDecl = fn
+			}
+		}
+	}
+	if appDecl == nil {
+		return nil, fmt.Errorf("%s has no %s", serverPath, appFunc)
+	}
+
+	// Types every document refers to
+	meta, err := p.lookup(respondPath, "Meta")
+	if err != nil {
+		return nil, err
+	}
+	problem, err := p.lookup(respondPath, "Problem")
+	if err != nil {
+		return nil, err
+	}
+	fieldError, err := p.lookup(validationPath, "FieldError")
+	if err != nil {
+		return nil, err
+	}
+	session, err := p.lookup(cookiesPath, "Session")
+	if err != nil {
+		return nil, err
+	}
+	invalid, err := p.lookup(i18nPath, "CodeInvalidRequest")
+	if err != nil {
+		return nil, err
+	}
+	p.meta = p.schemas.of(meta.Type(), modeResponse)
+	p.schemas.of(problem.Type(), modeResponse)
+	fieldErrors := &schema{Type: "array", Items: p.schemas.of(fieldError.Type(), modeResponse)}
+	p.invalidCode = constValue(invalid)
+
+	paths := make(map[string]pathItem)
+	tagged := make(map[string]bool)
+	var ops []*operation
+	var opRoutes []route
+	for _, r := range findRoutes(appDecl, serverPkg.TypesInfo) {
+		op := p.operation(r, serverPkg.TypesInfo)
+		if op == nil {
+			continue
+		}
+		if paths[r.path] == nil {
+			paths[r.path] = make(pathItem)
+		}
+		paths[r.path][strings.ToLower(r.method)] = op
+		tagged[op.Tags[0]] = true
+		ops = append(ops, op)
+		opRoutes = append(opRoutes, r)
+	}
+	uniqueOperationIDs(ops, opRoutes)
+
+	schemas := p.schemas.finish()
+	if _, ok := schemas["Error"]; ok {
+		return nil, errors.New("a component is already named Error")
+	}
+	schemas["Error"] = &schema{
+		Type:        "object",
+		Description: "The envelope of a failed request",
+		Properties: map[string]*schema{
+			"success":    {Type: "boolean"},
+			"error":      {Type: "string", Description: "The localized message"},
+			"code":       {Type: "string", Description: "The stable error code"},
+			"details":    {Type: "object", AdditionalProperties: &schema{Type: "string"}, Description: "Messages by field, for validation failures"},
+			"fields":     fieldErrors,
+			"request_id": {Type: "string"},
+		},
+		Required: []string{"code", "error", "success"},
+	}
+
+	tags := make([]tag, 0, len(tagged))
+	for name := range tagged {
+		tags = append(tags, tag{Name: name})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+
+	return &document{
+		OpenAPI: "3.0.3",
+		Info: info{
+			Title:       "brewd API",
+			Description: "Generated from the server's routes and handlers by cmd/openapi.",
+			Version:     apiVersion,
+		},
+		Servers: []server{{URL: "/"}},
+		Tags:    tags,
+		Paths:   paths,
+		Components: components{
+			Schemas: schemas,
+			SecuritySchemes: map[string]securityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+				"cookieAuth": {Type: "apiKey", In: "cookie", Name: constValue(session),
+					Description: "The web client's session; requests that change state also send the CSRF token"},
+				"apiKeyAuth": {Type: "apiKey", In: "header", Name: "X-API-Key"},
+			},
+		},
+	}, nil
+}
+
+// uniqueOperationIDs renames operations sharing a handler, appending their
+// path's last static segment and then their method
+func uniqueOperationIDs(ops []*operation, routes []route) {
+	count := make(map[string]int)
+	for _, op := range ops {
+		count[op.OperationID]++
+	}
+	for i, op := range ops {
+		if count[op.OperationID] < 2 {
+			continue
+		}
+		segments := staticSegments(routes[i].path)
+		if last := camel(segments[len(segments)-1]); !strings.HasSuffix(op.OperationID, last) {
+			op.OperationID += last
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i, op := range ops {
+		if seen[op.OperationID] {
+			op.OperationID += exportedName(strings.ToLower(routes[i].method))
+		}
+		seen[op.OperationID] = true
+	}
+}
+
+func constValue(obj types.Object) string {
+	c, ok := obj.(*types.Const)
+	if !ok {
+		return ""
+	}
+	s, _ := constantString(c)
+	return s
+}
+
+// docText is a doc comment as one paragraph
+func docText(doc *ast.CommentGroup) string {
+	return strings.Join(strings.Fields(doc.Text()), " ")
+}
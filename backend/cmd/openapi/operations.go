@@ -0,0 +1,692 @@
+package main
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const (
+	respondPath  = "brewd/internal/respond"
+	handlersPath = "brewd/internal/handlers"
+
+	jsonContentType       = "application/json"
+	mergePatchContentType = "application/merge-patch+json"
+	multipartContentType  = "multipart/form-data"
+	eventStreamType       = "text/event-stream"
+	binaryContentType     = "application/octet-stream"
+	problemContentType    = "application/problem+json"
+)
+
+// analysis is what an operation's middleware and handler were found to
+// read and write
+type analysis struct {
+	body        types.Type
+	contentType string
+	files       []string
+	params      []parameter
+	seen        map[string]bool
+	responses   map[int]*outcome
+	security    []map[string][]string
+	requires    []string
+}
+
+// outcome is the bodies written with one status, by content type, and the
+// error codes among them
+type outcome struct {
+	content map[string][]*schema
+	codes   []string
+}
+
+func (a *analysis) respond(status int, contentType string, body *schema) *outcome {
+	if a.responses == nil {
+		a.responses = make(map[int]*outcome)
+	}
+	out := a.responses[status]
+	if out == nil {
+		out = &outcome{content: make(map[string][]*schema)}
+		a.responses[status] = out
+	}
+	if contentType != "" {
+		out.content[contentType] = append(out.content[contentType], body)
+	}
+	return out
+}
+
+func (a *analysis) param(p parameter) {
+	key := p.In + ":" + p.Name
+	if a.seen == nil {
+		a.seen = make(map[string]bool)
+	}
+	if a.seen[key] {
+		return
+	}
+	a.seen[key] = true
+	a.params = append(a.params, p)
+}
+
+// analyzer finds the requests and responses of a handler or middleware,
+// following calls into functions given the gin.Context, and into the
+// handler constructors a constructor returns the handler of
+type analyzer struct {
+	p       *program
+	a       *analysis
+	visited map[*ast.FuncDecl]bool
+
+	// statuses are the constants int variables may hold: parameters of
+	// the functions followed, by the arguments of their calls, and
+	// locals, by their assignments
+	statuses map[types.Object][]int
+}
+
+func (z *analyzer) decl(fn *types.Func) {
+	d, ok := z.p.decls[fn]
+	if !ok || d.decl.Body == nil || z.visited[d.decl] {
+		return
+	}
+	z.visited[d.decl] = true
+	z.body(d.decl.Body, d.info)
+}
+
+// call follows a call into fn, binding the constants its int arguments
+// may hold to fn's parameters. A function followed before is followed
+// again when that binds new constants.
+func (z *analyzer) call(fn *types.Func, call *ast.CallExpr, info *types.Info) {
+	params := fn.Signature().Params()
+	for i := 0; i < params.Len() && i < len(call.Args); i++ {
+		param := params.At(i)
+		if basic, ok := param.Type().Underlying().(*types.Basic); !ok || basic.Kind() != types.Int {
+			continue
+		}
+		for _, status := range z.status(info, call.Args[i]) {
+			if !slices.Contains(z.statuses[param], status) {
+				z.statuses[param] = append(z.statuses[param], status)
+				if d, ok := z.p.decls[fn]; ok {
+					delete(z.visited, d.decl)
+				}
+			}
+		}
+	}
+	z.decl(fn)
+}
+
+// status returns the constants expr may hold
+func (z *analyzer) status(info *types.Info, expr ast.Expr) []int {
+	if n, ok := constInt(info, expr); ok {
+		return []int{n}
+	}
+	if ident, ok := ast.Unparen(expr).(*ast.Ident); ok {
+		return z.statuses[info.Uses[ident]]
+	}
+	return nil
+}
+
+func (z *analyzer) body(body ast.Node, info *types.Info) {
+	// Constants assigned to locals, wherever they're read
+	ast.Inspect(body, func(n ast.Node) bool {
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != len(assign.Rhs) {
+			return true
+		}
+		for i, lhs := range assign.Lhs {
+			ident, ok := lhs.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			obj := info.Defs[ident]
+			if obj == nil {
+				obj = info.Uses[ident]
+			}
+			if n, ok := constInt(info, assign.Rhs[i]); ok && obj != nil && !slices.Contains(z.statuses[obj], n) {
+				z.statuses[obj] = append(z.statuses[obj], n)
+			}
+		}
+		return true
+	})
+
+	contentType := ""
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		fn := typeutil.StaticCallee(info, call)
+		if fn == nil {
+			return true
+		}
+		switch {
+		case isContextMethod(fn):
+			if fn.Name() == "Header" && len(call.Args) == 2 {
+				if name, ok := constString(info, call.Args[0]); ok && http.CanonicalHeaderKey(name) == "Content-Type" {
+					contentType, _ = constString(info, call.Args[1])
+				}
+				return true
+			}
+			z.context(fn.Name(), call, info, contentType)
+		case fn.Pkg() != nil && fn.Pkg().Path() == respondPath:
+			z.respond(fn.Name(), call, info)
+		case fn.Pkg() != nil && fn.Pkg().Path() == handlersPath && fn.Name() == "bindMergePatch":
+			if len(call.Args) == 3 {
+				z.a.body = deref(info.TypeOf(call.Args[2]))
+				z.a.contentType = mergePatchContentType
+			}
+			z.decl(fn)
+		case takesContext(fn), isHandlerFunc(fn):
+			z.call(fn, call, info)
+		}
+		return true
+	})
+}
+
+// context handles a call of a gin.Context method
+func (z *analyzer) context(name string, call *ast.CallExpr, info *types.Info, contentType string) {
+	a := z.a
+	arg := func(i int) ast.Expr {
+		if i < len(call.Args) {
+			return call.Args[i]
+		}
+		return nil
+	}
+	switch name {
+	case "ShouldBindJSON", "BindJSON", "ShouldBindBodyWith", "ShouldBindBodyWithJSON":
+		if len(call.Args) > 0 {
+			a.body = deref(info.TypeOf(arg(0)))
+			a.contentType = jsonContentType
+		}
+	case "ShouldBindQuery", "BindQuery":
+		if len(call.Args) > 0 {
+			for _, p := range z.p.queryParams(deref(info.TypeOf(arg(0)))) {
+				a.param(p)
+			}
+		}
+	case "Query", "DefaultQuery", "GetQuery", "QueryArray":
+		if len(call.Args) == 0 {
+			return
+		}
+		if key, ok := constString(info, arg(0)); ok {
+			sch := &schema{Type: "string"}
+			if name == "QueryArray" {
+				sch = &schema{Type: "array", Items: sch}
+			}
+			a.param(parameter{Name: key, In: "query", Schema: sch})
+		}
+	case "FormFile":
+		if len(call.Args) > 0 {
+			if key, ok := constString(info, arg(0)); ok {
+				a.files = append(a.files, key)
+			}
+		}
+	case "JSON", "AbortWithStatusJSON":
+		if len(call.Args) != 2 {
+			return
+		}
+		for _, status := range z.status(info, arg(0)) {
+			ct := contentType
+			if ct == "" {
+				ct = jsonContentType
+			}
+			a.respond(status, ct, z.p.data(arg(1), info))
+		}
+	case "Data":
+		if len(call.Args) != 3 {
+			return
+		}
+		for _, status := range z.status(info, arg(0)) {
+			ct, ok := constString(info, arg(1))
+			if !ok {
+				ct = binaryContentType
+			}
+			a.respond(status, ct, &schema{Type: "string", Format: "binary"})
+		}
+	case "Status", "AbortWithStatus", "Redirect":
+		if len(call.Args) == 0 {
+			return
+		}
+		for _, status := range z.status(info, arg(0)) {
+			if contentType != "" && status == http.StatusOK {
+				a.respond(status, contentType, &schema{Type: "string", Format: "binary"})
+				continue
+			}
+			a.respond(status, "", nil)
+		}
+	case "Stream", "SSEvent":
+		a.respond(http.StatusOK, eventStreamType, &schema{Type: "string"})
+	}
+}
+
+// respond handles a call of the respond package, which writes the
+// envelope
+func (z *analyzer) respond(name string, call *ast.CallExpr, info *types.Info) {
+	a, p := z.a, z.p
+	arg := func(i int) ast.Expr {
+		if i < len(call.Args) {
+			return call.Args[i]
+		}
+		return nil
+	}
+	switch name {
+	case "OK", "Created":
+		if len(call.Args) == 2 {
+			status := http.StatusOK
+			if name == "Created" {
+				status = http.StatusCreated
+			}
+			a.respond(status, jsonContentType, p.envelope(p.data(arg(1), info), false))
+		}
+	case "Status":
+		if len(call.Args) != 3 {
+			return
+		}
+		for _, status := range z.status(info, arg(1)) {
+			a.respond(status, jsonContentType, p.envelope(p.data(arg(2), info), false))
+		}
+	case "Page":
+		if len(call.Args) > 1 {
+			a.respond(http.StatusOK, jsonContentType, p.envelope(p.data(arg(1), info), true))
+		}
+	case "Write":
+		if len(call.Args) != 3 {
+			return
+		}
+		for _, status := range z.status(info, arg(1)) {
+			a.respond(status, jsonContentType, p.schemas.of(info.TypeOf(arg(2)), modeResponse))
+		}
+	case "Error", "Errorf", "Details", "Rejected":
+		if len(call.Args) <= 2 {
+			return
+		}
+		for _, status := range z.status(info, arg(1)) {
+			out := a.respond(status, jsonContentType, ref("Error"))
+			if code, ok := constString(info, arg(2)); ok {
+				out.codes = append(out.codes, code)
+			}
+		}
+	case "Invalid":
+		out := a.respond(http.StatusBadRequest, jsonContentType, ref("Error"))
+		out.codes = append(out.codes, p.invalidCode)
+	case "WriteProblem":
+		if len(call.Args) <= 2 {
+			return
+		}
+		for _, status := range z.status(info, arg(1)) {
+			out := a.respond(status, problemContentType, ref("Problem"))
+			if code, ok := constString(info, arg(2)); ok {
+				out.codes = append(out.codes, code)
+			}
+		}
+	}
+}
+
+// data returns the schema of the value expr writes: a map literal's
+// constant keys become properties, and nil means no data
+func (p *program) data(expr ast.Expr, info *types.Info) *schema {
+	t := info.TypeOf(expr)
+	if t == nil || t == types.Typ[types.UntypedNil] {
+		return nil
+	}
+	if lit, ok := ast.Unparen(expr).(*ast.CompositeLit); ok {
+		if m, ok := t.Underlying().(*types.Map); ok && len(lit.Elts) > 0 {
+			sch := &schema{Type: "object", Properties: make(map[string]*schema)}
+			for _, elt := range lit.Elts {
+				kv, ok := elt.(*ast.KeyValueExpr)
+				if !ok {
+					return p.schemas.of(m, modeResponse)
+				}
+				key, ok := constString(info, kv.Key)
+				if !ok {
+					return p.schemas.of(m, modeResponse)
+				}
+				sch.Properties[key] = p.schemas.of(info.TypeOf(kv.Value), modeResponse)
+				sch.Required = append(sch.Required, key)
+			}
+			sort.Strings(sch.Required)
+			return sch
+		}
+	}
+	return p.schemas.of(t, modeResponse)
+}
+
+// envelope is the successful envelope carrying data, with the page's meta
+// when paged
+func (p *program) envelope(data *schema, paged bool) *schema {
+	sch := &schema{
+		Type: "object",
+		Properties: map[string]*schema{
+			"success":    {Type: "boolean"},
+			"request_id": {Type: "string"},
+		},
+		Required: []string{"success"},
+	}
+	if data != nil {
+		sch.Properties["data"] = data
+		sch.Required = append(sch.Required, "data")
+	}
+	if paged {
+		sch.Properties["meta"] = p.meta
+		sch.Required = append(sch.Required, "meta")
+	}
+	sort.Strings(sch.Required)
+	return sch
+}
+
+// queryParams returns the query parameters a struct binds, by form tag
+func (p *program) queryParams(t types.Type) []parameter {
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return nil
+	}
+	var params []parameter
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		tag := reflect.StructTag(st.Tag(i))
+		name, _, _ := strings.Cut(tag.Get("form"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Embedded() && name == "" {
+			params = append(params, p.queryParams(deref(field.Type()))...)
+			continue
+		}
+		if !field.Exported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name()
+		}
+		t := types.Unalias(field.Type())
+		if ptr, ok := t.(*types.Pointer); ok {
+			t = ptr.Elem()
+		}
+		binding := tag.Get("binding")
+		params = append(params, parameter{
+			Name:        name,
+			In:          "query",
+			Description: describes(field.Name(), p.docs.fields[field]),
+			Required:    hasRule(binding, "required"),
+			Schema:      constrain(p.schemas.of(t, modeRequest), binding),
+		})
+	}
+	return params
+}
+
+// middleware applies what a middleware call adds to the operations it
+// guards: its security requirement or other requirement, and its error
+// responses
+func (z *analyzer) middleware(expr ast.Expr, info *types.Info) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return
+	}
+	fn := typeutil.StaticCallee(info, call)
+	if fn == nil {
+		return
+	}
+	switch fn.Name() {
+	case "RequireAuth":
+		z.a.security = []map[string][]string{{"bearerAuth": {}}, {"cookieAuth": {}}}
+	case "RequireAPIKey":
+		z.a.security = []map[string][]string{{"apiKeyAuth": {}}, {"bearerAuth": {}}}
+		if len(call.Args) > 1 {
+			if scope, ok := constString(info, call.Args[1]); ok && scope != "" {
+				z.a.requires = append(z.a.requires, "scope:"+scope)
+			}
+		}
+	default:
+		if requirement, ok := strings.CutPrefix(fn.Name(), "Require"); ok {
+			z.a.requires = append(z.a.requires, kebab(requirement))
+		}
+	}
+	z.decl(fn)
+}
+
+// operation builds the operation of a route
+func (p *program) operation(r route, info *types.Info) *operation {
+	a := &analysis{}
+	z := &analyzer{p: p, a: a, visited: make(map[*ast.FuncDecl]bool), statuses: make(map[types.Object][]int)}
+
+	for _, m := range r.middleware {
+		z.middleware(m, info)
+	}
+
+	op := &operation{}
+	switch h := r.handler.(type) {
+	case *ast.CallExpr:
+		fn := typeutil.StaticCallee(info, h)
+		if fn == nil {
+			return nil
+		}
+		op.OperationID = fn.Name()
+		if d, ok := p.decls[fn]; ok && d.decl.Doc != nil {
+			op.Summary, op.Description = summarize(fn.Name(), d.decl.Doc.Text())
+		}
+		z.decl(fn)
+	case *ast.FuncLit:
+		op.OperationID = exportedName(strings.ToLower(r.method)) + camel(staticSegments(r.path)...)
+		z.body(h.Body, info)
+	default:
+		return nil
+	}
+
+	for _, name := range pathParams(r.path) {
+		op.Parameters = append(op.Parameters, parameter{
+			Name: name, In: "path", Required: true, Schema: &schema{Type: "string"},
+		})
+	}
+	op.Parameters = append(op.Parameters, a.params...)
+
+	if a.body != nil {
+		sch := p.schemas.of(a.body, modeRequest)
+		content := map[string]mediaType{a.contentType: {Schema: sch}}
+		if a.contentType == mergePatchContentType {
+			content[jsonContentType] = mediaType{Schema: sch}
+		}
+		op.RequestBody = &requestBody{Required: true, Content: content}
+	} else if len(a.files) > 0 {
+		form := &schema{Type: "object", Properties: make(map[string]*schema), Required: a.files}
+		for _, name := range a.files {
+			form.Properties[name] = &schema{Type: "string", Format: "binary"}
+		}
+		op.RequestBody = &requestBody{Required: true, Content: map[string]mediaType{multipartContentType: {Schema: form}}}
+	}
+
+	op.Responses = make(map[string]*response, len(a.responses))
+	for status, out := range a.responses {
+		op.Responses[strconv.Itoa(status)] = out.response(status)
+	}
+	if len(op.Responses) == 0 {
+		op.Responses["default"] = &response{Description: "Unspecified"}
+	}
+	op.Security = a.security
+	if op.Security == nil {
+		// No authentication, overriding none at the document level
+		op.Security = []map[string][]string{}
+	}
+	op.Requires = a.requires
+	op.Tags = []string{tagOf(r.path)}
+	return op
+}
+
+func (out *outcome) response(status int) *response {
+	resp := &response{Description: http.StatusText(status)}
+	codes := unique(out.codes)
+	if len(codes) > 0 {
+		resp.Description += ": " + strings.Join(codes, ", ")
+	}
+	for contentType, bodies := range out.content {
+		bodies = uniqueSchemas(bodies)
+		var sch *schema
+		switch {
+		case len(bodies) == 1 && bodies[0] == nil:
+			continue
+		case len(bodies) == 1:
+			sch = bodies[0]
+		default:
+			sch = &schema{OneOf: bodies}
+		}
+		if resp.Content == nil {
+			resp.Content = make(map[string]mediaType)
+		}
+		resp.Content[contentType] = mediaType{Schema: sch}
+	}
+	return resp
+}
+
+// summarize splits a handler's doc into a summary, its first sentence
+// without the handler's name, and the rest
+func summarize(name, doc string) (summary, description string) {
+	doc = strings.Join(strings.Fields(doc), " ")
+	doc = strings.TrimPrefix(doc, name+" ")
+	if doc == "" {
+		return "", ""
+	}
+	doc = strings.ToUpper(doc[:1]) + doc[1:]
+	summary, rest, _ := strings.Cut(doc, ". ")
+	summary = strings.TrimSuffix(summary, ".")
+	if rest == "" {
+		return summary, ""
+	}
+	return summary, doc
+}
+
+// tagOf groups an operation by its path's first segment after the API
+// version, or its published prefix's name
+func tagOf(path string) string {
+	segments := staticSegments(path)
+	switch {
+	case len(segments) >= 3 && segments[0] == "api" && segments[1] == "v1":
+		return segments[2]
+	case len(segments) >= 2 && segments[0] == "api":
+		return segments[1]
+	case len(segments) > 0:
+		return segments[0]
+	}
+	return "default"
+}
+
+func staticSegments(path string) []string {
+	var segments []string
+	for _, s := range strings.Split(path, "/") {
+		if s != "" && !strings.HasPrefix(s, "{") {
+			segments = append(segments, s)
+		}
+	}
+	return segments
+}
+
+func pathParams(path string) []string {
+	var names []string
+	for _, m := range pathParam.FindAllStringSubmatch(strings.NewReplacer("{", ":", "}", "").Replace(path), -1) {
+		names = append(names, m[1])
+	}
+	return names
+}
+
+func camel(words ...string) string {
+	var b strings.Builder
+	for _, word := range words {
+		for _, part := range strings.FieldsFunc(word, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+			b.WriteString(exportedName(part))
+		}
+	}
+	return b.String()
+}
+
+func kebab(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 && !(s[i-1] >= 'A' && s[i-1] <= 'Z') {
+				b.WriteByte('-')
+			}
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func unique(items []string) []string {
+	if len(items) == 0 {
+		return nil
+	}
+	sort.Strings(items)
+	out := items[:1]
+	for _, item := range items[1:] {
+		if item != out[len(out)-1] {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// uniqueSchemas drops bodies encoding like an earlier one
+func uniqueSchemas(bodies []*schema) []*schema {
+	seen := make(map[string]bool)
+	var out []*schema
+	for _, body := range bodies {
+		encoded, _ := json.Marshal(body)
+		if seen[string(encoded)] {
+			continue
+		}
+		seen[string(encoded)] = true
+		out = append(out, body)
+	}
+	return out
+}
+
+func isContextMethod(fn *types.Func) bool {
+	recv := fn.Signature().Recv()
+	return recv != nil && isGin(deref(recv.Type()), "Context")
+}
+
+// takesContext reports whether fn is given the gin.Context, so it may
+// read the request or respond
+func takesContext(fn *types.Func) bool {
+	params := fn.Signature().Params()
+	for i := 0; i < params.Len(); i++ {
+		if ptr, ok := types.Unalias(params.At(i).Type()).(*types.Pointer); ok && isGin(ptr.Elem(), "Context") {
+			return true
+		}
+	}
+	return false
+}
+
+func deref(t types.Type) types.Type {
+	if ptr, ok := types.Unalias(t).(*types.Pointer); ok {
+		return ptr.Elem()
+	}
+	return t
+}
+
+func constString(info *types.Info, expr ast.Expr) (string, bool) {
+	tv, ok := info.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
+
+func constInt(info *types.Info, expr ast.Expr) (int, bool) {
+	tv, ok := info.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.Int {
+		return 0, false
+	}
+	n, ok := constant.Int64Val(tv.Value)
+	return int(n), ok
+}
+
+func constantString(c *types.Const) (string, bool) {
+	if c.Val().Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(c.Val()), true
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"go/ast"
+	"go/types"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// published are the path prefixes of the API clients call. Operational
+// routes, webhooks, feeds and the OIDC and SCIM protocols aren't part of
+// the SDKs.
+var published = []string{"/auth/", "/api/", "/public/v1/", "/devices/v1/", "/integrations/v1/"}
+
+// appFunc is the function of cmd/server registering the routes
+const appFunc = "newApp"
+
+// routerName is the variable appFunc registers the public API on
+const routerName = "router"
+
+var methods = map[string]bool{"GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true}
+
+// route is one registration in appFunc: its method, path and the calls
+// building its middleware and handler
+type route struct {
+	method     string
+	path       string
+	middleware []ast.Expr
+	handler    ast.Expr
+}
+
+// group is a router group: its path prefix and middleware so far
+type group struct {
+	prefix     string
+	middleware []ast.Expr
+}
+
+// routeWalker follows appFunc's statements in order, tracking router groups,
+// middleware kept in variables and the functions registering routes on a
+// group they're given
+type routeWalker struct {
+	info   *types.Info
+	groups map[types.Object]*group
+	values map[types.Object]ast.Expr
+	funcs  map[types.Object]*ast.FuncLit
+	routes []route
+}
+
+// findRoutes returns the published routes fn registers on the API router
+func findRoutes(fn *ast.FuncDecl, info *types.Info) []route {
+	w := &routeWalker{
+		info:   info,
+		groups: make(map[types.Object]*group),
+		values: make(map[types.Object]ast.Expr),
+		funcs:  make(map[types.Object]*ast.FuncLit),
+	}
+	w.walk(fn.Body)
+
+	var routes []route
+	for _, r := range w.routes {
+		for _, prefix := range published {
+			if strings.HasPrefix(r.path+"/", prefix) {
+				routes = append(routes, r)
+				break
+			}
+		}
+	}
+	return routes
+}
+
+func (w *routeWalker) walk(node ast.Node) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			// Walked when called with a group
+			return false
+		case *ast.AssignStmt:
+			w.assign(n)
+		case *ast.CallExpr:
+			w.call(n)
+		}
+		return true
+	})
+}
+
+func (w *routeWalker) assign(stmt *ast.AssignStmt) {
+	if len(stmt.Lhs) != 1 || len(stmt.Rhs) != 1 {
+		return
+	}
+	ident, ok := stmt.Lhs[0].(*ast.Ident)
+	if !ok {
+		return
+	}
+	obj := w.object(ident)
+	if obj == nil {
+		return
+	}
+
+	switch rhs := stmt.Rhs[0].(type) {
+	case *ast.FuncLit:
+		w.funcs[obj] = rhs
+	case *ast.CallExpr:
+		if ident.Name == routerName {
+			if named, ok := types.Unalias(obj.Type()).(*types.Pointer); ok && isGin(named.Elem(), "Engine") {
+				w.groups[obj] = &group{}
+			}
+			return
+		}
+		if g := w.group(rhs); g != nil {
+			w.groups[obj] = g
+			return
+		}
+		if fn := typeutil.StaticCallee(w.info, rhs); fn != nil && isHandlerFunc(fn) {
+			w.values[obj] = rhs
+		}
+	}
+}
+
+func (w *routeWalker) call(call *ast.CallExpr) {
+	if ident, ok := call.Fun.(*ast.Ident); ok {
+		// A function literal registering routes on the group it's given
+		lit := w.funcs[w.info.Uses[ident]]
+		if lit == nil || len(call.Args) != 1 || len(lit.Type.Params.List) != 1 {
+			return
+		}
+		g := w.group(call.Args[0])
+		if g == nil || len(lit.Type.Params.List[0].Names) != 1 {
+			return
+		}
+		w.groups[w.info.Defs[lit.Type.Params.List[0].Names[0]]] = g
+		w.walk(lit.Body)
+		return
+	}
+
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+	g := w.group(sel.X)
+	if g == nil {
+		return
+	}
+	switch {
+	case sel.Sel.Name == "Use":
+		g.middleware = append(g.middleware, w.resolve(call.Args)...)
+	case methods[sel.Sel.Name] && len(call.Args) >= 2:
+		path, ok := w.constString(call.Args[0])
+		if !ok {
+			return
+		}
+		chain := w.resolve(call.Args[1:])
+		w.routes = append(w.routes, route{
+			method:     sel.Sel.Name,
+			path:       openAPIPath(g.prefix + path),
+			middleware: append(append([]ast.Expr{}, g.middleware...), chain[:len(chain)-1]...),
+			handler:    chain[len(chain)-1],
+		})
+	}
+}
+
+// group returns the group expr evaluates to, if it's one being tracked or
+// a subgroup of one
+func (w *routeWalker) group(expr ast.Expr) *group {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return w.groups[w.info.Uses[expr]]
+	case *ast.CallExpr:
+		sel, ok := expr.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Group" || len(expr.Args) == 0 {
+			return nil
+		}
+		parent := w.group(sel.X)
+		if parent == nil {
+			return nil
+		}
+		path, ok := w.constString(expr.Args[0])
+		if !ok {
+			return nil
+		}
+		return &group{
+			prefix:     parent.prefix + path,
+			middleware: append(append([]ast.Expr{}, parent.middleware...), w.resolve(expr.Args[1:])...),
+		}
+	}
+	return nil
+}
+
+// resolve replaces variables holding middleware with the calls building it
+func (w *routeWalker) resolve(exprs []ast.Expr) []ast.Expr {
+	out := make([]ast.Expr, 0, len(exprs))
+	for _, expr := range exprs {
+		if ident, ok := expr.(*ast.Ident); ok {
+			if value, ok := w.values[w.info.Uses[ident]]; ok {
+				expr = value
+			}
+		}
+		out = append(out, expr)
+	}
+	return out
+}
+
+func (w *routeWalker) object(ident *ast.Ident) types.Object {
+	if obj := w.info.Defs[ident]; obj != nil {
+		return obj
+	}
+	return w.info.Uses[ident]
+}
+
+func (w *routeWalker) constString(expr ast.Expr) (string, bool) {
+	return constString(w.info, expr)
+}
+
+var pathParam = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+// openAPIPath writes gin's :name and *name parameters as {name}
+func openAPIPath(path string) string {
+	return pathParam.ReplaceAllString(path, "{$1}")
+}
+
+// isHandlerFunc reports whether fn returns a gin.HandlerFunc
+func isHandlerFunc(fn *types.Func) bool {
+	results := fn.Signature().Results()
+	return results.Len() == 1 && isGin(results.At(0).Type(), "HandlerFunc")
+}
+
+func isGin(t types.Type, name string) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	return ok && named.Obj().Pkg() != nil &&
+		named.Obj().Pkg().Path() == "github.com/gin-gonic/gin" && named.Obj().Name() == name
+}
@@ -0,0 +1,441 @@
+package main
+
+import (
+	"go/types"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// mode is whether a type is read from requests or written in responses,
+// which decides its required properties: a request's are those binding
+// requires, a response's those always encoded
+type mode int
+
+const (
+	modeResponse mode = iota
+	modeRequest
+)
+
+// wellKnown are the schemas of types not encoded as their fields are, by
+// qualified name
+var wellKnown = map[string]schema{
+	"time.Time":                                  {Type: "string", Format: "date-time"},
+	"time.Duration":                              {Type: "integer", Format: "int64"},
+	"encoding/json.RawMessage":                   {},
+	"github.com/google/uuid.UUID":                {Type: "string", Format: "uuid"},
+	"github.com/shopspring/decimal.Decimal":      {Type: "string", Format: "decimal"},
+	"github.com/jackc/pgx/v5/pgtype.Timestamptz": {Type: "string", Format: "date-time", Nullable: true},
+	"github.com/jackc/pgx/v5/pgtype.Timestamp":   {Type: "string", Format: "date-time", Nullable: true},
+	"github.com/jackc/pgx/v5/pgtype.Date":        {Type: "string", Format: "date", Nullable: true},
+	"github.com/jackc/pgx/v5/pgtype.Text":        {Type: "string", Nullable: true},
+	"github.com/jackc/pgx/v5/pgtype.Bool":        {Type: "boolean", Nullable: true},
+	"github.com/jackc/pgx/v5/pgtype.Int2":        {Type: "integer", Format: "int32", Nullable: true},
+	"github.com/jackc/pgx/v5/pgtype.Int4":        {Type: "integer", Format: "int32", Nullable: true},
+	"github.com/jackc/pgx/v5/pgtype.Int8":        {Type: "integer", Format: "int64", Nullable: true},
+	"github.com/jackc/pgx/v5/pgtype.Float4":      {Type: "number", Format: "float", Nullable: true},
+	"github.com/jackc/pgx/v5/pgtype.Float8":      {Type: "number", Format: "double", Nullable: true},
+	"github.com/jackc/pgx/v5/pgtype.Numeric":     {Type: "number", Nullable: true},
+	"github.com/jackc/pgx/v5/pgtype.UUID":        {Type: "string", Format: "uuid", Nullable: true},
+	"github.com/jackc/pgx/v5/pgtype.Interval":    {Type: "string", Nullable: true},
+	"github.com/gin-gonic/gin.H":                 {Type: "object", AdditionalProperties: &schema{}},
+}
+
+// schemas builds the schemas of Go types, keeping named structs as
+// components. Components are keyed by qualified type name and mode until
+// names are assigned, once every type is known.
+type schemas struct {
+	docs       docs
+	components map[string]*schema
+	types      map[string]*types.TypeName
+	bases      map[string]string
+	modes      map[string]mode
+	refs       []*schema
+}
+
+func newSchemas(d docs) *schemas {
+	return &schemas{
+		docs:       d,
+		components: make(map[string]*schema),
+		types:      make(map[string]*types.TypeName),
+		bases:      make(map[string]string),
+		modes:      make(map[string]mode),
+	}
+}
+
+// of returns the schema of t in mode m
+func (s *schemas) of(t types.Type, m mode) *schema {
+	t = types.Unalias(t)
+	switch t := t.(type) {
+	case *types.Named:
+		return s.named(t, m)
+	case *types.Pointer:
+		return nullable(s.of(t.Elem(), m))
+	case *types.Basic:
+		return basic(t)
+	case *types.Slice:
+		if b, ok := t.Elem().Underlying().(*types.Basic); ok && b.Kind() == types.Byte {
+			return &schema{Type: "string", Format: "byte"}
+		}
+		return &schema{Type: "array", Items: s.of(t.Elem(), m)}
+	case *types.Array:
+		return &schema{Type: "array", Items: s.of(t.Elem(), m)}
+	case *types.Map:
+		return &schema{Type: "object", AdditionalProperties: s.of(t.Elem(), m)}
+	case *types.Struct:
+		return s.object(t, m)
+	default:
+		// Interfaces and anything else: any JSON value
+		return &schema{}
+	}
+}
+
+func (s *schemas) named(t *types.Named, m mode) *schema {
+	obj := t.Obj()
+	qualified := qualifiedName(obj)
+	if known, ok := wellKnown[qualified]; ok {
+		copied := known
+		return &copied
+	}
+	if hasMethod(t, "MarshalJSON") {
+		return &schema{Description: "Encoded by " + obj.Name() + "'s MarshalJSON"}
+	}
+	if hasMethod(t, "MarshalText") {
+		return &schema{Type: "string"}
+	}
+
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		// Named basic types, slices and maps are written as their
+		// underlying type, described by the named type's doc
+		sch := s.of(t.Underlying(), m)
+		if doc := s.docs.types[obj]; doc != "" && sch.Ref == "" && sch.Description == "" {
+			sch.Description = describes(obj.Name(), doc)
+		}
+		return sch
+	}
+
+	key := componentKey(t, m)
+	if _, ok := s.components[key]; !ok {
+		// Registered before its fields, so recursive types refer to it
+		s.components[key] = &schema{}
+		s.types[key] = obj
+		s.bases[key] = baseName(t)
+		s.modes[key] = m
+		sch := s.object(st, m)
+		sch.Description = describes(obj.Name(), s.docs.types[obj])
+		s.components[key] = sch
+	}
+	r := &schema{Ref: key}
+	s.refs = append(s.refs, r)
+	return r
+}
+
+// object returns the schema of a struct's JSON encoding, promoting the
+// fields of embedded structs as encoding/json does
+func (s *schemas) object(st *types.Struct, m mode) *schema {
+	sch := &schema{Type: "object", Properties: make(map[string]*schema)}
+	s.fields(sch, st, m)
+	if len(sch.Properties) == 0 {
+		sch.Properties = nil
+	}
+	sort.Strings(sch.Required)
+	return sch
+}
+
+func (s *schemas) fields(sch *schema, st *types.Struct, m mode) {
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		tag := reflect.StructTag(st.Tag(i))
+		name, opts := jsonName(tag.Get("json"))
+		if name == "-" && opts == "" {
+			continue
+		}
+		if field.Embedded() && name == "" {
+			embedded := types.Unalias(field.Type())
+			if p, ok := embedded.(*types.Pointer); ok {
+				embedded = p.Elem()
+			}
+			if est, ok := embedded.Underlying().(*types.Struct); ok {
+				s.fields(sch, est, m)
+				continue
+			}
+		}
+		if !field.Exported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name()
+		}
+
+		prop := s.of(field.Type(), m)
+		if strings.Contains(opts, "string") {
+			prop = &schema{Type: "string", Nullable: prop.Nullable}
+		}
+		binding := tag.Get("binding")
+		if m == modeRequest {
+			prop = constrain(prop, binding)
+		}
+		if doc := s.docs.fields[field]; doc != "" {
+			prop = describe(prop, describes(field.Name(), doc))
+		}
+		sch.Properties[name] = prop
+
+		omitempty := strings.Contains(opts, "omitempty") || strings.Contains(opts, "omitzero")
+		if m == modeRequest && hasRule(binding, "required") || m == modeResponse && !omitempty {
+			sch.Required = append(sch.Required, name)
+		}
+	}
+}
+
+// constrain applies a field's binding rules that have OpenAPI
+// equivalents. Rules after dive apply to a slice's items.
+func constrain(sch *schema, binding string) *schema {
+	if binding == "" {
+		return sch
+	}
+	rules, itemRules, dive := strings.Cut(binding, ",dive")
+	target := sch
+	if target.Ref != "" || len(target.AllOf) > 0 {
+		// Constraints can't sit beside a reference
+		return sch
+	}
+	applyRules(target, rules)
+	if dive && target.Items != nil && target.Items.Ref == "" {
+		target.Items = cloneSchema(target.Items)
+		applyRules(target.Items, strings.TrimPrefix(itemRules, ","))
+	}
+	return sch
+}
+
+func applyRules(sch *schema, rules string) {
+	for _, rule := range strings.Split(rules, ",") {
+		name, arg, _ := strings.Cut(rule, "=")
+		switch name {
+		case "email":
+			sch.Format = "email"
+		case "url", "http_url":
+			sch.Format = "uri"
+		case "uuid":
+			sch.Format = "uuid"
+		case "oneof":
+			for _, v := range strings.Fields(arg) {
+				if sch.Type == "integer" {
+					if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+						sch.Enum = append(sch.Enum, n)
+						continue
+					}
+				}
+				sch.Enum = append(sch.Enum, v)
+			}
+		case "min", "gte", "max", "lte", "len":
+			n, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				continue
+			}
+			lower := name == "min" || name == "gte" || name == "len"
+			upper := name == "max" || name == "lte" || name == "len"
+			switch sch.Type {
+			case "string":
+				if lower {
+					sch.MinLength = intPtr(n)
+				}
+				if upper {
+					sch.MaxLength = intPtr(n)
+				}
+			case "array":
+				if lower {
+					sch.MinItems = intPtr(n)
+				}
+				if upper {
+					sch.MaxItems = intPtr(n)
+				}
+			case "integer", "number":
+				if lower {
+					sch.Minimum = &n
+				}
+				if upper {
+					sch.Maximum = &n
+				}
+			}
+		}
+	}
+}
+
+// finish names the components and points every reference at its name.
+// A type keeps its own name unless another package has a type of that
+// name, when it is prefixed with its package's; a type read from requests
+// as well as written in responses is suffixed Input in requests.
+func (s *schemas) finish() map[string]*schema {
+	byName := make(map[string][]string)
+	for key := range s.types {
+		byName[s.bases[key]] = append(byName[s.bases[key]], key)
+	}
+
+	names := make(map[string]string, len(s.types))
+	for base, keys := range byName {
+		packages := make(map[string]bool)
+		for _, key := range keys {
+			packages[s.types[key].Pkg().Path()] = true
+		}
+		for _, key := range keys {
+			obj := s.types[key]
+			name := base
+			if len(packages) > 1 {
+				name = exportedName(obj.Pkg().Name()) + base
+			}
+			if s.modes[key] == modeRequest && s.hasMode(obj, modeResponse) {
+				name += "Input"
+			}
+			names[key] = name
+		}
+	}
+
+	out := make(map[string]*schema, len(s.components))
+	for key, sch := range s.components {
+		out[names[key]] = sch
+	}
+	for _, r := range s.refs {
+		r.Ref = "#/components/schemas/" + names[r.Ref]
+	}
+	return out
+}
+
+func (s *schemas) hasMode(obj *types.TypeName, m mode) bool {
+	for key, o := range s.types {
+		if o == obj && s.modes[key] == m {
+			return true
+		}
+	}
+	return false
+}
+
+func basic(t *types.Basic) *schema {
+	switch t.Kind() {
+	case types.Bool, types.UntypedBool:
+		return &schema{Type: "boolean"}
+	case types.Int8, types.Int16, types.Int32, types.Uint8, types.Uint16:
+		return &schema{Type: "integer", Format: "int32"}
+	case types.Int, types.Int64, types.Uint, types.Uint32, types.Uint64, types.UntypedInt:
+		return &schema{Type: "integer", Format: "int64"}
+	case types.Float32:
+		return &schema{Type: "number", Format: "float"}
+	case types.Float64, types.UntypedFloat:
+		return &schema{Type: "number", Format: "double"}
+	case types.String, types.UntypedString:
+		return &schema{Type: "string"}
+	default:
+		return &schema{}
+	}
+}
+
+// nullable marks sch as accepting null. OpenAPI 3.0 ignores siblings of
+// $ref, so a reference is wrapped.
+func nullable(sch *schema) *schema {
+	if sch.Ref != "" {
+		return &schema{AllOf: []*schema{sch}, Nullable: true}
+	}
+	sch.Nullable = true
+	return sch
+}
+
+// describe sets a property's description, wrapping references as nullable
+// does
+func describe(sch *schema, doc string) *schema {
+	if sch.Ref != "" {
+		return &schema{AllOf: []*schema{sch}, Description: doc}
+	}
+	if sch.Description == "" {
+		sch.Description = doc
+	}
+	return sch
+}
+
+// describes is a doc comment without the name of what it documents, as
+// descriptions read in generated clients
+func describes(name, doc string) string {
+	for _, prefix := range []string{name + " is ", name + " are ", name + " "} {
+		if rest, ok := strings.CutPrefix(doc, prefix); ok {
+			doc = rest
+			break
+		}
+	}
+	if doc == "" {
+		return doc
+	}
+	return strings.ToUpper(doc[:1]) + doc[1:]
+}
+
+func cloneSchema(sch *schema) *schema {
+	copied := *sch
+	return &copied
+}
+
+func hasMethod(t types.Type, name string) bool {
+	for _, typ := range []types.Type{t, types.NewPointer(t)} {
+		if sel := types.NewMethodSet(typ).Lookup(nil, name); sel != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRule reports whether a binding tag has rule
+func hasRule(binding, rule string) bool {
+	rules, _, _ := strings.Cut(binding, ",dive")
+	for _, r := range strings.Split(rules, ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonName(tag string) (name, opts string) {
+	name, opts, _ = strings.Cut(tag, ",")
+	return name, opts
+}
+
+func qualifiedName(obj *types.TypeName) string {
+	if obj.Pkg() == nil {
+		return obj.Name()
+	}
+	return obj.Pkg().Path() + "." + obj.Name()
+}
+
+// baseName is a component's name before any prefix or suffix: its type's,
+// followed by the names of any type arguments
+func baseName(t *types.Named) string {
+	name := t.Obj().Name()
+	if args := t.TypeArgs(); args != nil {
+		for i := 0; i < args.Len(); i++ {
+			if named, ok := types.Unalias(deref(args.At(i))).(*types.Named); ok {
+				name += exportedName(named.Obj().Name())
+			}
+		}
+	}
+	return name
+}
+
+func componentKey(t *types.Named, m mode) string {
+	key := qualifiedName(t.Obj())
+	if args := t.TypeArgs(); args != nil {
+		for i := 0; i < args.Len(); i++ {
+			key += "[" + types.TypeString(args.At(i), nil) + "]"
+		}
+	}
+	return key + "#" + strconv.Itoa(int(m))
+}
+
+func exportedName(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func intPtr(f float64) *int64 {
+	n := int64(f)
+	return &n
+}
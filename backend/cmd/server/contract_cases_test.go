@@ -0,0 +1,525 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"brewd/internal/agegate"
+	"brewd/internal/db"
+)
+
+// object is a JSON object of a case's body
+type object = map[string]any
+
+// contractCases are the contract suite's requests, in the order they run.
+// Each area creates what it needs as alice, the admin, with bob and carol
+// joining in where an operation is about another user, and deletes it at
+// the end. Policy publishing comes last, as it leaves every other user with
+// a policy to accept.
+var contractCases = []contractCase{
+	// Signing up and in
+	{Op: "GET /auth/csrf"},
+	{Op: "GET /auth/captcha"},
+	{Op: "GET /auth/policies"},
+	{Op: "GET /auth/availability", Query: "username=dave{run}&email=dave{run}@example.com"},
+	{Op: "POST /auth/register", Status: http.StatusCreated, SignIn: "dave", Body: object{
+		"email":             "dave{run}@example.com",
+		"username":          "dave{run}",
+		"password":          contractPassword,
+		"birth_date":        "1990-04-01",
+		"accepted_policies": "{policies}",
+	}, Save: map[string]string{"dave": "data.user.id"}},
+	{Op: "POST /auth/register", Status: http.StatusAccepted, Body: object{
+		"email":             "erin{run}@example.com",
+		"username":          "erin{run}",
+		"password":          contractPassword,
+		"birth_date":        "{child_birth_date}",
+		"parent_email":      "erin.parent{run}@example.com",
+		"accepted_policies": "{policies}",
+	}},
+	{Op: "GET /auth/parental-consent/{consent_token}", Setup: seedConsentRequest},
+	{Op: "POST /auth/parental-consent/{consent_token}"},
+	{Op: "POST /auth/login", Status: http.StatusOK, Body: object{
+		"email":    "{alice_email}",
+		"password": contractPassword,
+	}},
+	{Op: "POST /auth/logout"},
+	{Op: "GET /api/me", As: "alice"},
+
+	// The signed-in user
+	{Op: "GET /api/v1/users/me", As: "alice"},
+	{Op: "PATCH /api/v1/users/me", As: "alice", Body: object{"bio": "Filter coffee, mostly", "location": "Oslo"}},
+	{Op: "GET /api/v1/users/me/preferences", As: "alice"},
+	{Op: "PATCH /api/v1/users/me/preferences", As: "alice", Body: object{"timezone": "Europe/Oslo", "currency": "NOK"}},
+	{Op: "GET /api/v1/users/me/plan", As: "alice"},
+	{Op: "GET /api/v1/users/me/auth-events", As: "dave"},
+	{Op: "GET /api/v1/users/me/calendar", As: "alice"},
+	{Op: "POST /api/v1/users/me/calendar/reset", As: "alice"},
+	{Op: "GET /api/v1/users/me/referrals", As: "alice"},
+	{Op: "POST /api/v1/invites", As: "alice", Body: object{"max_uses": 1}, Save: map[string]string{"invite": "data.code"}},
+	{Op: "GET /api/v1/invites", As: "alice"},
+	{Op: "GET /auth/invites/{invite}"},
+	{Op: "DELETE /api/v1/invites/{invite}", As: "alice"},
+	{Op: "PUT /api/v1/users/me/username", As: "carol", Body: object{"username": "carolyn{run}"},
+		Save: map[string]string{"carol_name": "data.user.username"}, SignIn: "carol"},
+	{Op: "GET /api/v1/users/{alice}/badges", As: "bob"},
+	{Op: "GET /api/v1/users/me/badges", As: "alice"},
+	{Op: "GET /api/v1/challenges", As: "bob"},
+	{Op: "GET /api/v1/methods", As: "alice"},
+	{Op: "GET /api/v1/origins", As: "alice"},
+
+	// Second factors: TOTP, the step-up challenge it puts on logins, and
+	// the devices trusted to skip it
+	{Op: "POST /api/v1/users/me/totp", As: "dave", Save: map[string]string{"totp_secret": "data.secret"}},
+	{Op: "POST /api/v1/users/me/totp/confirm", As: "dave", Setup: totpStep(-1), Body: object{"code": "{totp_code}"}},
+	{Op: "POST /auth/login", Status: http.StatusAccepted, Body: object{
+		"email":    "dave{run}@example.com",
+		"password": contractPassword,
+	}, Save: map[string]string{"challenge": "data.challenge_id"}},
+	{Op: "POST /auth/step-up", Setup: totpStep(0), SignIn: "dave", Body: object{
+		"challenge_id": "{challenge}",
+		"code":         "{totp_code}",
+		"trust_device": true,
+	}},
+	{Op: "GET /api/v1/users/me/trusted-devices", As: "dave", Save: map[string]string{"trusted_device": "data.0.id"}},
+	{Op: "DELETE /api/v1/users/me/trusted-devices/{trusted_device}", As: "dave"},
+	{Op: "DELETE /api/v1/users/me/trusted-devices", As: "dave"},
+	{Op: "POST /api/v1/users/me/totp/disable", As: "dave", Setup: totpStep(1), Body: object{"code": "{totp_code}"}},
+
+	// API keys, for the device and automation cases
+	{Op: "POST /api/v1/api-keys", As: "alice", Body: object{
+		"name":   "Kitchen bridge",
+		"scopes": []any{"tds_readings:write", "pour_curves:write", "automations"},
+	}, Save: map[string]string{"api_key": "data.key", "api_key_id": "data.id"}},
+	{Op: "GET /api/v1/api-keys", As: "alice"},
+
+	// Bean bags, custom fields and flavors, which brews refer to
+	{Op: "POST /api/v1/bean-bags", As: "alice", Body: object{
+		"name":        "Gesha Village",
+		"weight":      250,
+		"roaster":     "Tim Wendelboe",
+		"bean_origin": "Ethiopia",
+		"price":       240,
+		"currency":    "NOK",
+		"rest_days":   7,
+	}, Save: map[string]string{"bean_bag": "data.id"}},
+	{Op: "GET /api/v1/bean-bags", As: "alice"},
+	{Op: "GET /api/v1/bean-bags/{bean_bag}", As: "alice"},
+	{Op: "PATCH /api/v1/bean-bags/{bean_bag}", As: "alice", Body: object{"process": "washed", "variety": "Gesha"}},
+	{Op: "GET /api/v1/flavors", As: "alice", Save: map[string]string{"flavor": "data.0.id"}},
+	{Op: "PUT /api/v1/bean-bags/{bean_bag}/flavors", As: "alice", Body: object{"descriptor_ids": []any{"{flavor}"}}},
+	{Op: "GET /api/v1/bean-bags/{bean_bag}/flavors", As: "alice"},
+	{Op: "POST /api/v1/custom-fields", As: "alice", Body: object{
+		"name": "{field_name}",
+		"type": "number",
+		"unit": "g",
+	}, Save: map[string]string{"custom_field": "data.id"}},
+	{Op: "GET /api/v1/custom-fields", As: "alice"},
+	{Op: "PATCH /api/v1/custom-fields/{custom_field}", As: "alice", Body: object{"unit": "ml"}},
+
+	// Brews
+	{Op: "POST /api/v1/brews", As: "alice", Body: object{
+		"name":              "Morning V60",
+		"brew_method":       "v60",
+		"bean_origin":       "Ethiopia",
+		"roaster":           "Tim Wendelboe",
+		"notes":             "Bright and floral",
+		"is_public":         true,
+		"dose":              18,
+		"water":             300,
+		"water_temp":        94,
+		"grind_setting":     "medium-fine",
+		"brew_time_seconds": 180,
+		"bean_bag_id":       "{bean_bag}",
+		"custom_fields":     object{"{field_name}": 40},
+	}, Save: map[string]string{"brew": "data.id"}},
+	{Op: "GET /api/v1/brews", As: "alice"},
+	{Op: "GET /api/v1/brews/{brew}", As: "alice"},
+	{Op: "PATCH /api/v1/brews/{brew}", As: "alice", Body: object{"name": "Morning V60", "notes": "Bright, floral, a little thin"}},
+	{Op: "POST /api/v1/brews/{brew}/duplicate", As: "alice", Body: object{"carry_bean": true, "carry_equipment": true},
+		Save: map[string]string{"brew_copy": "data.id"}},
+	{Op: "POST /api/v1/brews/quick", As: "alice", Body: object{"brew_method": "aeropress", "bean_bag_id": "{bean_bag}"}},
+	{Op: "POST /api/v1/brews/batch", As: "alice", Body: object{"brews": []any{
+		object{"name": "Afternoon Chemex", "brew_method": "chemex", "dose": 30, "water": 500},
+		object{"name": "Evening espresso", "brew_method": "espresso", "dose": 18, "water": 36},
+	}}},
+	{Op: "GET /api/v1/brews/compare", As: "alice", Query: "ids={brew},{brew_copy}"},
+	{Op: "PUT /api/v1/brews/{brew}/flavors", As: "alice", Body: object{"descriptor_ids": []any{"{flavor}"}}},
+	{Op: "GET /api/v1/brews/{brew}/flavors", As: "alice"},
+	{Op: "GET /api/v1/flavors/top", As: "alice", Query: "origin=Ethiopia"},
+	{Op: "GET /api/v1/autocomplete/origins", As: "alice", Query: "q=Eth"},
+	{Op: "GET /api/v1/autocomplete/roasters", As: "alice", Query: "q=Tim"},
+	{Op: "POST /api/v1/brews/{brew_copy}/timer/start", As: "alice", Body: object{"remind_in_seconds": 240}},
+	{Op: "POST /api/v1/brews/{brew_copy}/timer/stop", As: "alice"},
+	{Op: "GET /api/v1/bean-bags/{bean_bag}/forecast", As: "alice"},
+	{Op: "GET /public/v1/brews/{brew}"},
+
+	// Photos
+	{Op: "POST /api/v1/brews/{brew}/photos", As: "alice", Upload: &upload{
+		Field:       "photo",
+		Name:        "v60.jpg",
+		ContentType: "image/jpeg",
+		Data:        contractJPEG(),
+	}, Save: map[string]string{"photo": "data.id"}},
+	{Op: "GET /api/v1/brews/{brew}/photos", As: "alice"},
+	{Op: "GET /api/v1/brews/{brew}/photos/{photo}", As: "alice"},
+	{Op: "GET /api/v1/brews/{brew}/photos/{photo}/image", As: "alice", Setup: func(t *testing.T, s *contractSuite) {
+		s.processPhoto(t, "brew", "photo")
+	}},
+	{Op: "GET /api/v1/brews/{brew}/photos/{photo}/thumbnail", As: "alice"},
+	{Op: "DELETE /api/v1/brews/{brew}/photos/{photo}", As: "alice"},
+
+	// Sharing brews with other users
+	{Op: "POST /api/v1/brews/{brew}/shares", As: "alice", Body: object{"username": "{carol_name}"},
+		Save: map[string]string{"brew_share": "data.id"}},
+	{Op: "GET /api/v1/brews/{brew}/shares", As: "alice"},
+	{Op: "GET /api/v1/users/me/shared", As: "carol"},
+	{Op: "DELETE /api/v1/brews/{brew}/shares/{brew_share}", As: "alice"},
+
+	// Devices: a refractometer's readings and a scale's pour curve
+	{Op: "POST /devices/v1/tds-readings", Header: map[string]string{"X-API-Key": "{api_key}"}, Body: object{
+		"readings": []any{object{"tds_percent": 1.38, "temperature_c": 24.5, "beverage_grams": 260, "device_model": "VST Lab II"}},
+	}, Save: map[string]string{"tds_reading": "data.0.id"}},
+	{Op: "GET /api/v1/users/me/tds-readings", As: "alice", Query: "unattached=true"},
+	{Op: "PATCH /api/v1/tds-readings/{tds_reading}", As: "alice", Body: object{"brew_id": "{brew}"}},
+	{Op: "GET /api/v1/brews/{brew}/tds-readings", As: "alice"},
+	{Op: "DELETE /api/v1/tds-readings/{tds_reading}", As: "alice"},
+	{Op: "POST /devices/v1/pour-curves", Status: http.StatusCreated, Query: "client_id=curve{run}&brew_id={brew}",
+		Header: map[string]string{"X-API-Key": "{api_key}"},
+		Raw:    "{\"t_ms\":0,\"grams\":0}\n{\"t_ms\":500,\"grams\":4.2}\n{\"t_ms\":1000,\"grams\":9.8}\n"},
+	{Op: "POST /devices/v1/pour-curves", Status: http.StatusOK, Query: "client_id=curve{run}&brew_id={brew}",
+		Header: map[string]string{"X-API-Key": "{api_key}"},
+		Raw:    "{\"t_ms\":1500,\"grams\":16.1}\n{\"t_ms\":2000,\"grams\":22.7}\n"},
+	{Op: "GET /api/v1/brews/{brew}/pour-curve", As: "alice", Query: "resolution=500"},
+	{Op: "DELETE /api/v1/brews/{brew}/pour-curve", As: "alice"},
+
+	// Smart-home automations
+	{Op: "POST /api/v1/automations/webhooks", As: "alice", Body: object{
+		"name":   "Kitchen lights",
+		"format": "home_assistant",
+		"url":    "https://hooks.example.com/brewd",
+	}, Save: map[string]string{"webhook": "data.id"}},
+	{Op: "GET /api/v1/automations/webhooks", As: "alice"},
+	{Op: "POST /api/v1/automations/webhooks/{webhook}/test", As: "alice"},
+	{Op: "POST /integrations/v1/timer/start", Header: map[string]string{"X-API-Key": "{api_key}"},
+		Body: object{"brew_id": "{brew_copy}"}},
+	{Op: "GET /integrations/v1/status", Header: map[string]string{"X-API-Key": "{api_key}"}},
+	{Op: "POST /integrations/v1/timer/stop", Header: map[string]string{"X-API-Key": "{api_key}"}},
+	{Op: "GET /integrations/v1/usage", Header: map[string]string{"X-API-Key": "{api_key}"}},
+	{Op: "GET /api/v1/api-keys/{api_key_id}/usage", As: "alice"},
+	{Op: "DELETE /api/v1/automations/webhooks/{webhook}", As: "alice"},
+	{Op: "DELETE /api/v1/api-keys/{api_key_id}", As: "alice"},
+
+	// Drafts and offline sync
+	{Op: "POST /api/v1/drafts", As: "alice", Body: object{"kind": "brew", "data": object{"name": "Half-logged Kalita"}},
+		Save: map[string]string{"draft": "data.id"}},
+	{Op: "GET /api/v1/drafts", As: "alice", Query: "kind=brew"},
+	{Op: "GET /api/v1/drafts/{draft}", As: "alice"},
+	{Op: "PATCH /api/v1/drafts/{draft}", As: "alice", ContentType: "application/merge-patch+json",
+		Raw: "{\"brew_method\":\"pour_over\",\"dose\":20}"},
+	{Op: "POST /api/v1/drafts/{draft}/publish", As: "alice"},
+	{Op: "POST /api/v1/drafts", As: "alice", Body: object{"kind": "recipe", "data": object{"name": "Iced V60"}},
+		Save: map[string]string{"draft": "data.id"}},
+	{Op: "DELETE /api/v1/drafts/{draft}", As: "alice"},
+	{Op: "POST /api/v1/sync/push", As: "alice", Body: object{"changes": []any{
+		object{
+			"resource": "brew",
+			"id":       "{sync_brew}",
+			"op":       "upsert",
+			"vector":   object{"phone": 1},
+			"data":     object{"name": "Logged on the train", "brew_method": "aeropress"},
+		},
+		object{
+			"resource": "draft",
+			"id":       "{sync_draft}",
+			"op":       "upsert",
+			"vector":   object{"phone": 1},
+			"data":     object{"kind": "brew", "data": object{"name": "Unfinished"}},
+		},
+	}}},
+	{Op: "GET /api/v1/sync/pull", As: "alice", Query: "limit=50"},
+	{Op: "GET /api/v1/users/me/recent", As: "alice"},
+
+	// Stats, with the supporter export
+	{Op: "GET /api/v1/users/me/stats", As: "alice"},
+	{Op: "GET /api/v1/users/me/stats/costs", As: "alice", Query: "months=3"},
+	{Op: "GET /api/v1/users/me/stats/costs/breakdown", As: "alice", Query: "by=roaster"},
+	{Op: "GET /api/v1/users/me/stats/costs/export", As: "alice", Query: "by=origin"},
+	{Op: "GET /api/v1/users/me/stats/origins", As: "alice", Query: "by=country"},
+	{Op: "GET /api/v1/users/me/stats/custom-fields/{field_name}", As: "alice"},
+	{Op: "DELETE /api/v1/custom-fields/{custom_field}", As: "alice"},
+
+	// Recipes, their revisions, collaborators and forks
+	{Op: "POST /api/v1/recipes", As: "alice", Body: object{
+		"name":              "Everyday V60",
+		"brew_method":       "v60",
+		"is_public":         true,
+		"dose":              15,
+		"water":             250,
+		"water_temp":        94,
+		"grind_setting":     "medium-fine",
+		"brew_time_seconds": 180,
+		"instructions":      "Bloom with 45 g for 45 s, then pour to 250 g by 1:45.",
+		"changelog":         "First version",
+	}, Save: map[string]string{"recipe": "data.id"}},
+	{Op: "GET /api/v1/recipes/{recipe}", As: "alice"},
+	{Op: "PUT /api/v1/recipes/{recipe}", As: "alice", Body: object{
+		"dose":      16,
+		"water":     260,
+		"changelog": "A little stronger",
+	}},
+	{Op: "PATCH /api/v1/recipes/{recipe}", As: "alice", Body: object{"name": "Everyday V60"}},
+	{Op: "GET /api/v1/recipes/{recipe}/revisions", As: "alice"},
+	{Op: "GET /api/v1/recipes/{recipe}/revisions/{first_revision}", As: "alice"},
+	{Op: "POST /api/v1/recipes/{recipe}/rollback", As: "alice", Body: object{
+		"revision":  "{first_revision}",
+		"changelog": "Back to the original",
+	}},
+	{Op: "POST /api/v1/recipes/{recipe}/collaborators", As: "alice", Body: object{
+		"username": "{bob_name}",
+		"role":     "editor",
+	}},
+	{Op: "GET /api/v1/users/me/recipe-invitations", As: "bob"},
+	{Op: "POST /api/v1/recipes/{recipe}/collaborators/accept", As: "bob"},
+	{Op: "GET /api/v1/recipes/{recipe}/collaborators", As: "alice"},
+	{Op: "PATCH /api/v1/recipes/{recipe}/collaborators/{bob}", As: "alice", Body: object{"role": "viewer"}},
+	{Op: "DELETE /api/v1/recipes/{recipe}/collaborators/{bob}", As: "alice"},
+	{Op: "POST /api/v1/recipes/{recipe}/fork", As: "bob", Body: object{"name": "Bob's V60"}},
+	{Op: "GET /api/v1/recipes/{recipe}/forks", As: "alice"},
+	{Op: "POST /api/v1/recipes/{recipe}/shares", As: "alice", Body: object{"username": "{carol_name}"},
+		Save: map[string]string{"recipe_share": "data.id"}},
+	{Op: "GET /api/v1/recipes/{recipe}/shares", As: "alice"},
+	{Op: "DELETE /api/v1/recipes/{recipe}/shares/{recipe_share}", As: "alice"},
+	{Op: "GET /public/v1/recipes"},
+	{Op: "GET /public/v1/recipes/{recipe}"},
+	{Op: "GET /public/v1/users"},
+	{Op: "GET /public/v1/users/{alice_name}"},
+	{Op: "GET /api/v1/recommendations", As: "bob"},
+	{Op: "POST /api/v1/recommendations/not-interested", As: "bob", Body: object{"kind": "recipe", "id": "{recipe}"}},
+	{Op: "GET /api/v1/discover", As: "bob", Query: "period=week"},
+	{Op: "POST /api/v1/events", As: "bob", Body: object{"events": []any{
+		object{"name": "recipe.viewed", "session_id": "contract", "properties": object{"source": "discover"}},
+	}}},
+
+	// Roasters and their official beans, which need an admin's
+	// verification
+	{Op: "POST /api/v1/roasters", As: "alice", Body: object{
+		"name":     "Alice Roasts {run}",
+		"website":  "https://roasters.example.com",
+		"location": "Oslo",
+	}, Save: map[string]string{"roaster": "data.id"}},
+	{Op: "GET /api/v1/roasters", As: "bob"},
+	{Op: "GET /api/v1/roasters/{roaster}", As: "bob"},
+	{Op: "PATCH /api/v1/roasters/{roaster}", As: "alice", Body: object{"description": "Light roasts from Oslo"}},
+	{Op: "GET /api/v1/users/me/roaster", As: "alice"},
+	{Op: "PUT /api/v1/admin/roasters/{roaster}/verification", As: "alice"},
+	{Op: "POST /api/v1/beans", As: "alice", Body: object{
+		"name":           "Nano Challa",
+		"bean_origin":    "Ethiopia",
+		"origin_country": "Ethiopia",
+		"process":        "washed",
+		"official":       true,
+	}, Save: map[string]string{"bean": "data.id"}},
+	{Op: "GET /api/v1/beans", As: "bob", Query: "official=true"},
+	{Op: "GET /api/v1/beans/{bean}", As: "bob"},
+	{Op: "PATCH /api/v1/beans/{bean}", As: "alice", Body: object{"variety": "Heirloom"}},
+	{Op: "PUT /api/v1/beans/{bean}/recipes/{recipe}", As: "alice"},
+	{Op: "DELETE /api/v1/beans/{bean}/recipes/{recipe}", As: "alice"},
+	{Op: "DELETE /api/v1/admin/roasters/{roaster}/verification", As: "alice"},
+
+	// Brew events around the public recipe
+	{Op: "POST /api/v1/brew-events", As: "alice", Body: object{
+		"title":       "Sunday V60 brew-along",
+		"description": "Bring a V60 and 15 g of coffee",
+		"recipe_id":   "{recipe}",
+		"starts_at":   "{tomorrow}",
+	}, Save: map[string]string{"brew_event": "data.id"}},
+	{Op: "GET /api/v1/brew-events", As: "bob"},
+	{Op: "GET /api/v1/brew-events/{brew_event}", As: "bob"},
+	{Op: "PUT /api/v1/brew-events/{brew_event}/rsvp", As: "bob", Body: object{"status": "going"}},
+	{Op: "GET /api/v1/brew-events/{brew_event}/rsvps", As: "alice"},
+	{Op: "POST /api/v1/brew-events/{brew_event}/timer/start", As: "alice"},
+	{Op: "GET /api/v1/brew-events/{brew_event}/stream", As: "bob", Timeout: 300 * time.Millisecond},
+	{Op: "POST /api/v1/brew-events/{brew_event}/timer/stop", As: "alice"},
+	{Op: "DELETE /api/v1/brew-events/{brew_event}", As: "alice"},
+
+	// Clubs, their invites and feeds
+	{Op: "POST /api/v1/clubs", As: "alice", Body: object{
+		"name":        "Filter Friends {run}",
+		"description": "Light roasts and long pours",
+		"is_private":  false,
+	}, Save: map[string]string{"club": "data.id"}},
+	{Op: "GET /api/v1/clubs", As: "bob"},
+	{Op: "GET /api/v1/clubs/{club}", As: "bob"},
+	{Op: "PATCH /api/v1/clubs/{club}", As: "alice", Body: object{"description": "Light roasts, long pours"}},
+	{Op: "POST /api/v1/clubs/{club}/join", As: "bob"},
+	{Op: "PUT /api/v1/clubs/{club}/members/{bob}", As: "alice", Body: object{"role": "moderator"}},
+	{Op: "POST /api/v1/clubs/{club}/invites", As: "alice", Body: object{"max_uses": 5, "expires_in_hours": 48},
+		Save: map[string]string{"club_invite": "data.id", "club_invite_token": "data.token"}},
+	{Op: "GET /api/v1/clubs/{club}/invites", As: "alice"},
+	{Op: "GET /api/v1/club-invites/{club_invite_token}", As: "carol"},
+	{Op: "POST /api/v1/club-invites/{club_invite_token}/accept", As: "carol"},
+	{Op: "DELETE /api/v1/clubs/{club}/invites/{club_invite}", As: "alice"},
+	{Op: "GET /api/v1/clubs/{club}/members", As: "carol"},
+	{Op: "GET /api/v1/users/me/clubs", As: "carol"},
+	{Op: "POST /api/v1/clubs/{club}/feed", As: "alice", Body: object{"brew_id": "{brew}", "note": "Try this one"},
+		Save: map[string]string{"club_share": "data.id"}},
+	{Op: "POST /api/v1/brews/{brew_copy}/shares", As: "alice", Body: object{"club_id": "{club}"},
+		Save: map[string]string{"brew_share": "data.id"}},
+	{Op: "GET /api/v1/clubs/{club}/feed", As: "carol", Query: "limit=10"},
+	{Op: "DELETE /api/v1/clubs/{club}/feed/{club_share}", As: "alice"},
+	{Op: "DELETE /api/v1/clubs/{club}/members/{carol}", As: "bob"},
+	{Op: "DELETE /api/v1/clubs/{club}", As: "alice"},
+
+	// Cupping sessions
+	{Op: "POST /api/v1/cupping-sessions", As: "alice", Body: object{
+		"title":   "Ethiopia washed vs natural",
+		"samples": []any{object{"name": "Washed"}, object{"name": "Natural"}},
+	}, Save: map[string]string{"cupping": "data.id"}},
+	{Op: "POST /api/v1/cupping-sessions/{cupping}/participants", As: "alice", Body: object{"username": "{bob_name}"}},
+	{Op: "GET /api/v1/cupping-sessions", As: "bob"},
+	{Op: "GET /api/v1/cupping-sessions/{cupping}", As: "bob"},
+	{Op: "PUT /api/v1/cupping-sessions/{cupping}/samples/{sample_label}/score", As: "bob", Body: object{
+		"score": 86.5,
+		"notes": "Jasmine, bergamot",
+	}},
+	{Op: "GET /api/v1/cupping-sessions/{cupping}/scores", As: "bob"},
+	{Op: "POST /api/v1/cupping-sessions/{cupping}/reveal", As: "alice"},
+	{Op: "GET /api/v1/cupping-sessions/{cupping}/results", As: "bob"},
+	{Op: "DELETE /api/v1/cupping-sessions/{cupping}/participants/{bob}", As: "alice"},
+	{Op: "DELETE /api/v1/cupping-sessions/{cupping}", As: "alice"},
+
+	// Organizations
+	{Op: "POST /api/v1/orgs", As: "alice", Body: object{"name": "Alice's Cafe {run}", "kind": "cafe"},
+		Save: map[string]string{"org": "data.id"}},
+	{Op: "GET /api/v1/orgs/{org}", As: "alice"},
+	{Op: "GET /api/v1/users/me/orgs", As: "alice"},
+	{Op: "PATCH /api/v1/orgs/{org}", As: "alice", Body: object{"name": "Alice's Coffee Bar {run}"}},
+	{Op: "POST /api/v1/orgs/{org}/members", As: "alice", Body: object{"username": "{bob_name}", "role": "member"}},
+	{Op: "GET /api/v1/orgs/{org}/members", As: "bob"},
+	{Op: "PUT /api/v1/orgs/{org}/members/{bob}", As: "alice", Body: object{"role": "admin"}},
+	{Op: "POST /api/v1/orgs/{org}/brews", As: "bob", Body: object{
+		"name":        "Opening dial-in",
+		"brew_method": "espresso",
+		"dose":        18,
+		"water":       38,
+	}, Save: map[string]string{"org_brew": "data.id"}},
+	{Op: "GET /api/v1/orgs/{org}/brews", As: "alice"},
+	{Op: "GET /api/v1/orgs/{org}/brews/{org_brew}", As: "alice"},
+	{Op: "DELETE /api/v1/orgs/{org}/members/{bob}", As: "alice"},
+	{Op: "DELETE /api/v1/orgs/{org}", As: "alice"},
+
+	// Billing, against stubbed stores: bob checks out with Stripe, then
+	// subscribes on Android, and carol on iOS
+	{Op: "POST /api/v1/billing/checkout", As: "bob"},
+	{Op: "POST /api/v1/billing/google/purchases", As: "bob", Body: object{"purchase_token": "play-{run}"}},
+	{Op: "POST /api/v1/billing/apple/receipts", As: "carol", Body: object{"receipt_data": "apple-{run}"}},
+
+	// Administration
+	{Op: "GET /api/v1/admin/stats", As: "alice", Query: "days=7"},
+	{Op: "GET /api/v1/admin/tables", As: "alice"},
+	{Op: "GET /api/v1/admin/slo", As: "alice"},
+	{Op: "GET /api/v1/admin/retention", As: "alice"},
+	{Op: "GET /api/v1/admin/job-leases", As: "alice"},
+	{Op: "GET /api/v1/admin/storage", As: "alice"},
+	{Op: "GET /api/v1/admin/auth-events", As: "alice", Query: "user_id={dave}"},
+	{Op: "GET /api/v1/admin/disposable-email-users", As: "alice"},
+	{Op: "POST /api/v1/admin/backups", As: "alice", Setup: func(t *testing.T, s *contractSuite) {
+		// Earlier runs leave their manual backup running, with no worker
+		// to take it
+		s.exec(t, `UPDATE backup_run SET status = 'failed', error = 'interrupted', finished_at = NOW() WHERE status = 'running'`)
+	}},
+	{Op: "GET /api/v1/admin/backups", As: "alice"},
+	{Op: "GET /api/v1/admin/backups/{backup}/download", As: "alice", Setup: func(t *testing.T, s *contractSuite) {
+		s.fixtures["backup"] = newID()
+		s.exec(t, `INSERT INTO backup_run (id, trigger, status, object_key, finished_at)
+			VALUES ($1, 'scheduled', 'succeeded', 'backups/contract.dump', NOW())`, s.fixtures["backup"])
+	}},
+	{Op: "POST /api/v1/brews/{brew}/photos", As: "alice", Upload: &upload{
+		Field:       "photo",
+		Name:        "eicar.jpg",
+		ContentType: "image/jpeg",
+		Data:        contractJPEG(),
+	}, Save: map[string]string{"photo": "data.id"}},
+	{Op: "GET /api/v1/admin/photos/quarantined", As: "alice", Setup: func(t *testing.T, s *contractSuite) {
+		s.exec(t, `UPDATE brew_photo SET status = 'quarantined', error = 'Eicar-Test-Signature', processed_at = NOW()
+			WHERE id = $1`, s.fixtures["photo"])
+	}},
+	{Op: "DELETE /api/v1/admin/photos/{photo}", As: "alice"},
+	{Op: "POST /api/v1/admin/challenges", As: "alice", Body: object{
+		"name":      "Ten brews {run}",
+		"metric":    "brews",
+		"threshold": 10,
+	}, Save: map[string]string{"challenge": "data.id"}},
+	{Op: "GET /api/v1/admin/challenges", As: "alice"},
+	{Op: "PATCH /api/v1/admin/challenges/{challenge}", As: "alice", Body: object{"threshold": 12, "is_active": false}},
+	{Op: "DELETE /api/v1/admin/challenges/{challenge}", As: "alice"},
+	{Op: "POST /api/v1/admin/email-suppressions", As: "alice", Body: object{
+		"email":  "{suppressed_email}",
+		"detail": "Bounced",
+	}},
+	{Op: "GET /api/v1/admin/email-suppressions", As: "alice"},
+	{Op: "DELETE /api/v1/admin/email-suppressions/{suppressed_email}", As: "alice"},
+	{Op: "POST /api/v1/admin/mail/test", As: "alice", Body: object{"to": "{alice_email}"}},
+	{Op: "PUT /api/v1/admin/email-domains/{domain}", As: "alice", Body: object{"action": "block", "note": "Throwaway"}},
+	{Op: "GET /api/v1/admin/email-domains", As: "alice"},
+	{Op: "DELETE /api/v1/admin/email-domains/{domain}", As: "alice"},
+	{Op: "GET /api/v1/admin/waitlist", As: "alice"},
+	{Op: "POST /api/v1/admin/waitlist/approve", As: "alice", Body: object{"emails": []any{"waiting{run}@example.com"}}},
+	{Op: "POST /api/v1/admin/oidc-clients", As: "alice", Body: object{
+		"name":          "Brew scale app {run}",
+		"redirect_uris": []any{"https://scale.example.com/callback"},
+	}, Save: map[string]string{"oidc_client": "data.client_id"}},
+	{Op: "GET /api/v1/admin/oidc-clients", As: "alice"},
+	{Op: "DELETE /api/v1/admin/oidc-clients/{oidc_client}", As: "alice"},
+	{Op: "POST /api/v1/admin/scim-clients", As: "alice", Body: object{"name": "Training directory {run}"},
+		Save: map[string]string{"scim_client": "data.id"}},
+	{Op: "GET /api/v1/admin/scim-clients", As: "alice"},
+	{Op: "DELETE /api/v1/admin/scim-clients/{scim_client}", As: "alice"},
+
+	// Policies last: once one is published, users must accept it
+	{Op: "GET /api/v1/admin/policies", As: "alice"},
+	{Op: "POST /api/v1/admin/policies", As: "alice", Body: object{
+		"kind":    "privacy",
+		"version": "{run}",
+		"url":     "https://brewd.app/privacy/{run}",
+		"summary": "Clarifies how long backups are kept",
+	}, Save: map[string]string{"policy": "data.id"}},
+	{Op: "GET /api/v1/users/me/policies", As: "alice"},
+	{Op: "POST /api/v1/users/me/policies/accept", As: "alice", Body: object{"document_ids": []any{"{policy}"}}},
+}
+
+// totpStep keeps the TOTP code of the totp_secret fixture for the step
+// offset steps from now as the totp_code fixture. Each code is accepted
+// once, so consecutive cases use consecutive steps.
+func totpStep(offset int64) func(t *testing.T, s *contractSuite) {
+	return func(t *testing.T, s *contractSuite) {
+		s.fixtures["totp_code"] = totpCode(t, s.fixture(t, "totp_secret"), offset)
+	}
+}
+
+// seedConsentRequest seeds an under-age user waiting on their parent's
+// consent, keeping the token emailed to the parent as the consent_token
+// fixture
+func seedConsentRequest(t *testing.T, s *contractSuite) {
+	t.Helper()
+	token, hash, err := agegate.NewToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent := "frank.parent" + s.fixture(t, "run") + "@example.com"
+	if _, err := s.queries.CreateUser(context.Background(), db.CreateUserParams{
+		ID:                newID(),
+		Username:          "frank" + s.fixture(t, "run"),
+		Email:             "frank" + s.fixture(t, "run") + "@example.com",
+		PasswordHash:      "unused",
+		ParentEmail:       &parent,
+		ConsentTokenHash:  &hash,
+		ConsentTtlSeconds: 7 * 24 * 60 * 60,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	s.fixtures["consent_token"] = token
+}
@@ -0,0 +1,775 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"brewd/internal/auth"
+	"brewd/internal/config"
+	"brewd/internal/db"
+	"brewd/internal/logger"
+	"brewd/internal/objectstore"
+	"brewd/internal/photos"
+	"brewd/pkg/database"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// contractPassword is every seeded user's password
+const contractPassword = "contract-password"
+
+// contractCase is a request of the contract suite, sent as documented with
+// the fixtures it names. Op is the documented method and path with the
+// fixtures for its parameters, e.g. "GET /api/v1/brews/{brew}"; Query and
+// Body may name fixtures the same way, Body in any string or as a whole
+// value. The answer must have Status, by default the one success status
+// documented, and match its documented schema; Save keeps values of its
+// JSON body as fixtures, by dotted path, and SignIn keeps its data.token
+// as the token of the user it names. Setup runs before the request, for
+// anything earlier cases can't provide.
+type contractCase struct {
+	Op     string
+	As     string
+	Query  string
+	Header map[string]string
+	Body   any
+	Upload *upload
+	Raw    string
+	// ContentType is Raw's media type, by default application/x-ndjson
+	ContentType string
+	Status      int
+	Save        map[string]string
+	SignIn      string
+	Setup       func(t *testing.T, s *contractSuite)
+	Timeout     time.Duration
+}
+
+// upload is a multipart/form-data body of one file
+type upload struct {
+	Field       string
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// contractSuite is the state the contract cases run in: the router, the
+// database behind it and the fixtures seeded or saved so far
+type contractSuite struct {
+	router   http.Handler
+	pool     *database.Pool
+	queries  *db.Queries
+	auth     *auth.Service
+	doc      *document
+	v        *validator
+	fixtures map[string]any
+	tokens   map[string]string
+	// store is the object store directory, for cases that process photos
+	store string
+}
+
+// contractUser is a user seeded for the suite. The admin is also a
+// supporter.
+type contractUser struct {
+	name      string
+	admin     bool
+	supporter bool
+}
+
+var contractUsers = []contractUser{
+	{name: "alice", admin: true, supporter: true},
+	{name: "bob"},
+	{name: "carol"},
+}
+
+// TestContract sends every operation of the OpenAPI document to the router,
+// against a migrated database named by TEST_DATABASE_URL, and checks each
+// answers with its documented success status and a body matching its
+// documented schema, and that each one documenting security refuses a
+// request without credentials. The cases run in order, each building on the
+// fixtures seeded or saved before it. Rows are added under fresh names on
+// each run, so the database can be reused.
+func TestContract(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	s := newContractSuite(t, dsn)
+
+	t.Run("unauthenticated", s.runUnauthenticated)
+	for i, tc := range contractCases {
+		if !t.Run(fmt.Sprintf("%03d %s", i, tc.Op), func(t *testing.T) { s.run(t, tc) }) {
+			// Later cases depend on the fixtures this one saves
+			t.FailNow()
+		}
+	}
+}
+
+// TestContractCases checks the cases without a database: each names a
+// documented operation and every operation has one, and their bodies
+// match the documented request schemas. Operations requiring credentials
+// must document the 401 TestContract checks for.
+func TestContractCases(t *testing.T) {
+	doc := loadDocument(t)
+	ops := doc.operations()
+	byShape := make(map[string]string, len(ops))
+	for key := range ops {
+		byShape[pathShape(key)] = key
+	}
+	v := &validator{components: doc.Components.Schemas, ignore: isPlaceholder}
+
+	covered := make(map[string]bool)
+	for i, tc := range contractCases {
+		key, ok := byShape[pathShape(tc.Op)]
+		if !ok {
+			t.Errorf("case %d: %s isn't documented", i, tc.Op)
+			continue
+		}
+		covered[key] = true
+		op := ops[key]
+
+		if tc.Status == 0 && len(op.success()) != 1 {
+			t.Errorf("case %d: %s documents %v, so the case needs a Status", i, tc.Op, op.success())
+		}
+		if tc.Status != 0 && op.Responses[strconv.Itoa(tc.Status)] == nil {
+			t.Errorf("case %d: %s doesn't document %d", i, tc.Op, tc.Status)
+		}
+
+		hasBody := tc.Body != nil || tc.Upload != nil || tc.Raw != ""
+		if hasBody != (op.RequestBody != nil) && tc.Raw == "" {
+			t.Errorf("case %d: %s sends a body: %v, documents one: %v", i, tc.Op, hasBody, op.RequestBody != nil)
+		}
+		if tc.Body == nil || op.RequestBody == nil {
+			continue
+		}
+		media, ok := op.RequestBody.Content["application/json"]
+		if !ok {
+			t.Errorf("case %d: %s takes no JSON body", i, tc.Op)
+			continue
+		}
+		body, err := roundTrip(tc.Body)
+		if err != nil {
+			t.Errorf("case %d: %v", i, err)
+			continue
+		}
+		for _, problem := range v.validate(media.Schema, body, "body") {
+			t.Errorf("case %d: %s: %s", i, tc.Op, problem)
+		}
+	}
+
+	for key, op := range ops {
+		if !covered[key] {
+			t.Errorf("%s has no contract case", key)
+		}
+		if op.requiresCredentials() && op.Responses["401"] == nil {
+			t.Errorf("%s requires credentials but doesn't document 401", key)
+		}
+	}
+}
+
+// newContractSuite connects to the database at dsn, seeds the users and
+// builds the router, configured for every operation to be reachable
+func newContractSuite(t *testing.T, dsn string) *contractSuite {
+	t.Helper()
+	ctx := context.Background()
+
+	t.Setenv("DATABASE_URL", dsn)
+	dbConfig, err := database.LoadConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, err := database.NewPool(ctx, dbConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+
+	s := &contractSuite{
+		pool:     pool,
+		queries:  db.New(pool),
+		fixtures: make(map[string]any),
+		tokens:   make(map[string]string),
+	}
+	s.doc = loadDocument(t)
+	s.v = &validator{components: s.doc.Components.Schemas}
+
+	// Names are fresh on every run
+	run := strings.ToLower(newID()[20:])
+	s.fixtures["run"] = run
+	var admins []string
+	for _, u := range contractUsers {
+		id := newID()
+		s.fixtures[u.name] = id
+		s.fixtures[u.name+"_name"] = u.name + run
+		s.fixtures[u.name+"_email"] = u.name + run + "@example.com"
+		if u.admin {
+			admins = append(admins, id)
+		}
+	}
+
+	// As are the values cases send as whole path segments, or that are
+	// relative to now
+	now := time.Now()
+	for name, value := range map[string]any{
+		"domain":           run + ".example.com",
+		"suppressed_email": "suppressed" + run + "@example.com",
+		"field_name":       "bloom",
+		"sample_label":     "A",
+		"first_revision":   1,
+		"sync_brew":        newID(),
+		"sync_draft":       newID(),
+		"child_birth_date": now.AddDate(-10, 0, 0).Format("2006-01-02"),
+		"tomorrow":         now.Add(24 * time.Hour).UTC().Format(time.RFC3339),
+	} {
+		s.fixtures[name] = value
+	}
+
+	s.store = t.TempDir()
+	stubStores(t)
+
+	for key, value := range map[string]string{
+		"JWT_SECRET":                   "contract-secret",
+		"LOG_LEVEL":                    "ERROR",
+		"ADMIN_USER_IDS":               strings.Join(admins, ","),
+		"BCRYPT_COST":                  "4",
+		"MINIMUM_AGE":                  "13",
+		"PARENTAL_CONSENT":             "true",
+		"AVAILABILITY_RATE_LIMIT":      "10000",
+		"PUBLIC_RATE_LIMIT":            "10000",
+		"OBJECT_STORE_URL":             "file://" + s.store,
+		"STRIPE_SECRET_KEY":            "sk_test_contract",
+		"STRIPE_PRICE_ID":              "price_contract",
+		"APPLE_SHARED_SECRET":          "contract-secret",
+		"GOOGLE_PLAY_CREDENTIALS_FILE": playCredentials(t),
+	} {
+		t.Setenv(key, value)
+	}
+	cfg := config.LoadConfig()
+	logger.Init(cfg.LogLevel)
+	s.auth = auth.NewService(cfg.JWTSecret, cfg.JWTExpirationHrs)
+
+	s.seedUsers(t)
+
+	a := newApp(ctx, cfg, dbConfig, pool, pool)
+	t.Cleanup(a.close)
+	s.router = a.router
+	return s
+}
+
+// seedUsers creates the suite's users, signed in and having accepted the
+// current policies
+func (s *contractSuite) seedUsers(t *testing.T) {
+	t.Helper()
+	ctx := context.Background()
+	hash, err := auth.HashPassword(contractPassword, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := s.queries.ListCurrentPolicyDocuments(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policies := make([]string, 0, len(current))
+	for _, doc := range current {
+		policies = append(policies, doc.ID)
+	}
+	s.fixtures["policies"] = policies
+
+	for _, u := range contractUsers {
+		id := s.fixture(t, u.name)
+		name := s.fixture(t, u.name+"_name")
+		if _, err := s.queries.CreateUser(ctx, db.CreateUserParams{
+			ID:           id,
+			Username:     name,
+			Email:        s.fixture(t, u.name+"_email"),
+			PasswordHash: hash,
+		}); err != nil {
+			t.Fatalf("seed %s: %v", u.name, err)
+		}
+		if len(policies) > 0 {
+			if _, err := s.queries.AcceptPolicyDocuments(ctx, db.AcceptPolicyDocumentsParams{
+				UserID:      id,
+				DocumentIds: policies,
+			}); err != nil {
+				t.Fatalf("seed %s: %v", u.name, err)
+			}
+		}
+		if u.supporter {
+			s.exec(t, `UPDATE "user" SET plan = 'supporter' WHERE id = $1`, id)
+		}
+		s.signIn(t, u.name, id, name)
+	}
+}
+
+// signIn keeps a token for user as the name cases send requests as
+func (s *contractSuite) signIn(t *testing.T, as, id, username string) {
+	t.Helper()
+	token, err := s.auth.GenerateToken(id, username)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.tokens[as] = token
+}
+
+// exec runs a statement seeding what no operation can
+func (s *contractSuite) exec(t *testing.T, sql string, args ...any) {
+	t.Helper()
+	if _, err := s.pool.Exec(context.Background(), sql, args...); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+}
+
+// fixture returns the fixture name, which must be a string
+func (s *contractSuite) fixture(t *testing.T, name string) string {
+	t.Helper()
+	value, ok := s.fixtures[name].(string)
+	if !ok {
+		t.Fatalf("no fixture %q", name)
+	}
+	return value
+}
+
+// run sends tc's request and checks the answer against the document
+func (s *contractSuite) run(t *testing.T, tc contractCase) {
+	if tc.Setup != nil {
+		tc.Setup(t, s)
+	}
+
+	method, path, _ := strings.Cut(tc.Op, " ")
+	target := s.expand(t, path)
+	if tc.Query != "" {
+		target += "?" + s.expand(t, tc.Query)
+	}
+
+	var body io.Reader
+	contentType := ""
+	switch {
+	case tc.Upload != nil:
+		var buf bytes.Buffer
+		form := multipart.NewWriter(&buf)
+		header := make(map[string][]string)
+		header["Content-Disposition"] = []string{fmt.Sprintf(`form-data; name=%q; filename=%q`, tc.Upload.Field, tc.Upload.Name)}
+		header["Content-Type"] = []string{tc.Upload.ContentType}
+		part, err := form.CreatePart(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := part.Write(tc.Upload.Data); err != nil {
+			t.Fatal(err)
+		}
+		if err := form.Close(); err != nil {
+			t.Fatal(err)
+		}
+		body, contentType = &buf, form.FormDataContentType()
+	case tc.Raw != "":
+		body, contentType = strings.NewReader(s.expand(t, tc.Raw)), "application/x-ndjson"
+		if tc.ContentType != "" {
+			contentType = tc.ContentType
+		}
+	case tc.Body != nil:
+		raw, err := json.Marshal(s.expandValue(t, tc.Body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, contentType = bytes.NewReader(raw), "application/json"
+	}
+
+	ctx := context.Background()
+	if tc.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, tc.Timeout)
+		defer cancel()
+	}
+	req := httptest.NewRequestWithContext(ctx, method, target, body)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if tc.As != "" {
+		token, ok := s.tokens[tc.As]
+		if !ok {
+			t.Fatalf("no user %q to send as", tc.As)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for name, value := range tc.Header {
+		req.Header.Set(name, s.expand(t, value))
+	}
+	rec := streamRecorder{httptest.NewRecorder()}
+	s.router.ServeHTTP(rec, req)
+
+	key := method + " " + s.documentedPath(t, path)
+	op := s.doc.operations()[key]
+	want := tc.Status
+	if want == 0 {
+		want, _ = strconv.Atoi(op.success()[0])
+	}
+	if rec.Code != want {
+		t.Fatalf("%s %s: status %d, want %d: %s", method, target, rec.Code, want, rec.Body.String())
+	}
+
+	documented := op.Responses[strconv.Itoa(rec.Code)]
+	if len(documented.Content) == 0 {
+		return
+	}
+	mediaType, _, _ := mime.ParseMediaType(rec.Header().Get("Content-Type"))
+	media, ok := documented.Content[mediaType]
+	if !ok {
+		t.Fatalf("%s %s: content type %q isn't documented", method, target, mediaType)
+	}
+	if mediaType != "application/json" {
+		return
+	}
+
+	var value any
+	if err := json.Unmarshal(rec.Body.Bytes(), &value); err != nil {
+		t.Fatalf("%s %s: body isn't JSON: %v", method, target, err)
+	}
+	for _, problem := range s.v.validate(media.Schema, value, "body") {
+		t.Errorf("%s %s: %s", method, target, problem)
+	}
+	for name, at := range tc.Save {
+		saved, ok := lookup(value, at)
+		if !ok {
+			t.Fatalf("%s %s: no %s in %s", method, target, at, rec.Body.String())
+		}
+		s.fixtures[name] = saved
+	}
+	if tc.SignIn != "" {
+		token, ok := lookup(value, "data.token")
+		if !ok {
+			t.Fatalf("%s %s: no token in %s", method, target, rec.Body.String())
+		}
+		s.tokens[tc.SignIn] = fmt.Sprint(token)
+	}
+}
+
+// runUnauthenticated sends every operation that requires credentials
+// without any, with fresh IDs for its path parameters, and checks each
+// answers 401
+func (s *contractSuite) runUnauthenticated(t *testing.T) {
+	var keys []string
+	for key, op := range s.doc.operations() {
+		if op.requiresCredentials() {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		method, path, _ := strings.Cut(key, " ")
+		target := pathParam.ReplaceAllStringFunc(path, func(string) string { return newID() })
+		req := httptest.NewRequest(method, target, nil)
+		rec := streamRecorder{httptest.NewRecorder()}
+		s.router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("%s without credentials: status %d, want 401: %s", key, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+// documentedPath returns the documented path path is a case of
+func (s *contractSuite) documentedPath(t *testing.T, path string) string {
+	t.Helper()
+	for documented := range s.doc.Paths {
+		if pathShape(documented) == pathShape(path) {
+			return documented
+		}
+	}
+	t.Fatalf("%s isn't documented", path)
+	return ""
+}
+
+var placeholder = regexp.MustCompile(`\{([a-z0-9_]+)\}`)
+
+// expand replaces the fixtures text names with their values, which are
+// path escaped as a path or query is
+func (s *contractSuite) expand(t *testing.T, text string) string {
+	t.Helper()
+	return placeholder.ReplaceAllStringFunc(text, func(match string) string {
+		name := match[1 : len(match)-1]
+		value, ok := s.fixtures[name]
+		if !ok {
+			t.Fatalf("no fixture %q", name)
+		}
+		return fmt.Sprint(value)
+	})
+}
+
+// expandValue replaces the fixtures a body names: a string that's only a
+// name becomes the fixture's value, of whatever type
+func (s *contractSuite) expandValue(t *testing.T, value any) any {
+	t.Helper()
+	switch v := value.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			out[key] = s.expandValue(t, item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = s.expandValue(t, item)
+		}
+		return out
+	case string:
+		if match := placeholder.FindStringSubmatch(v); match != nil && match[0] == v {
+			saved, ok := s.fixtures[match[1]]
+			if !ok {
+				t.Fatalf("no fixture %q", match[1])
+			}
+			return saved
+		}
+		return s.expand(t, v)
+	}
+	return value
+}
+
+// isPlaceholder reports whether value only names a fixture
+func isPlaceholder(value any) bool {
+	text, ok := value.(string)
+	if !ok {
+		return false
+	}
+	match := placeholder.FindString(text)
+	return match != "" && match == text
+}
+
+// lookup returns the value at a dotted path of JSON, with array indexes
+// as numbers
+func lookup(value any, path string) (any, bool) {
+	for _, key := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]any:
+			var ok bool
+			if value, ok = v[key]; !ok {
+				return nil, false
+			}
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			value = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// roundTrip returns value as decoded from its JSON
+func roundTrip(value any) (any, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	return out, json.Unmarshal(raw, &out)
+}
+
+// newID returns a new ULID, as the handlers generate them
+func newID() string {
+	return ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String()
+}
+
+// totpCode returns the TOTP code of secret for the step offset steps from
+// now, as an authenticator app would show it
+func totpCode(t *testing.T, secret string, offset int64) string {
+	t.Helper()
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(time.Now().Unix()/30+offset))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	at := sum[len(sum)-1] & 0x0f
+	return fmt.Sprintf("%06d", (binary.BigEndian.Uint32(sum[at:])&0x7fffffff)%1000000)
+}
+
+// streamRecorder is a recorder gin can stream server-sent events to, which
+// needs the response to report when the client goes away. The request's
+// context does that here, so the channel never fires.
+type streamRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (streamRecorder) CloseNotify() <-chan bool {
+	return make(chan bool)
+}
+
+// stubStores answers the billing providers' APIs in place of Stripe, the
+// App Store and Google Play until the test ends. Their clients use the
+// default transport; requests anywhere else fail.
+func stubStores(t *testing.T) {
+	t.Helper()
+	original := http.DefaultTransport
+	http.DefaultTransport = storeTransport{}
+	t.Cleanup(func() { http.DefaultTransport = original })
+}
+
+type storeTransport struct{}
+
+func (storeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var sent []byte
+	if req.Body != nil {
+		var err error
+		if sent, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+	expires := time.Now().AddDate(1, 0, 0)
+
+	var body any
+	switch host, path := req.URL.Host, req.URL.Path; {
+	case host == "api.stripe.com" && path == "/v1/customers":
+		form, _ := url.ParseQuery(string(sent))
+		body = map[string]any{"id": "cus_" + form.Get("metadata[user_id]")}
+	case host == "api.stripe.com" && path == "/v1/checkout/sessions":
+		body = map[string]any{"url": "https://checkout.stripe.com/c/pay/contract"}
+	case host == "buy.itunes.apple.com":
+		var receipt struct {
+			Data string `json:"receipt-data"`
+		}
+		if err := json.Unmarshal(sent, &receipt); err != nil {
+			return nil, err
+		}
+		body = map[string]any{
+			"status":  0,
+			"receipt": map[string]any{"bundle_id": "app.brewd"},
+			"latest_receipt_info": []any{map[string]any{
+				"product_id":              "supporter",
+				"original_transaction_id": receipt.Data,
+				"expires_date_ms":         strconv.FormatInt(expires.UnixMilli(), 10),
+			}},
+		}
+	case host == "oauth2.googleapis.com":
+		body = map[string]any{"access_token": "contract", "expires_in": 3600}
+	case host == "androidpublisher.googleapis.com":
+		body = map[string]any{
+			"subscriptionState":    "SUBSCRIPTION_STATE_ACTIVE",
+			"acknowledgementState": "ACKNOWLEDGEMENT_STATE_ACKNOWLEDGED",
+			"lineItems": []any{map[string]any{
+				"productId":  "supporter",
+				"expiryTime": expires.UTC().Format(time.RFC3339),
+			}},
+		}
+	default:
+		return nil, fmt.Errorf("no request to %s expected", req.URL)
+	}
+
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(raw)),
+		Request:    req,
+	}, nil
+}
+
+// playCredentials writes a Google Play service account key, of a key made
+// for the test, and returns its path
+func playCredentials(t *testing.T) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := json.Marshal(map[string]string{
+		"client_email": "contract@brewd.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    "https://oauth2.googleapis.com/token",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "play.json")
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// processPhoto runs the photo processor, as the server's worker does,
+// until the photo fixture of the brew fixture is ready
+func (s *contractSuite) processPhoto(t *testing.T, brew, photo string) {
+	t.Helper()
+	store, err := objectstore.New("file://"+s.store, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	go photos.NewProcessor(s.queries, store, nil, nil, nil, nil, 1).Run(ctx, 100*time.Millisecond)
+
+	params := db.GetBrewPhotoParams{ID: s.fixture(t, photo), BrewID: s.fixture(t, brew)}
+	for {
+		got, err := s.queries.GetBrewPhoto(ctx, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch got.Status {
+		case photos.StatusReady:
+			return
+		case photos.StatusPending, photos.StatusProcessing:
+		default:
+			t.Fatalf("photo %s is %s", params.ID, got.Status)
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("photo %s wasn't processed", params.ID)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// contractJPEG returns a small JPEG to upload as a brew photo
+func contractJPEG() []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 48))
+	for x := range 64 {
+		for y := range 48 {
+			img.Set(x, y, color.RGBA{R: uint8(x * 4), G: uint8(y * 5), B: 96, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	// Encoding to memory can't fail
+	_ = jpeg.Encode(&buf, img, nil)
+	return buf.Bytes()
+}
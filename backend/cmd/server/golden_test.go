@@ -0,0 +1,426 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"brewd/internal/auth"
+	"brewd/internal/config"
+	"brewd/internal/db"
+	"brewd/internal/db/dbtest"
+	"brewd/internal/logger"
+	"brewd/internal/plans"
+	"brewd/internal/schemacheck"
+	"brewd/pkg/database"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the responses")
+
+// goldenDir holds the golden files, one per compared answer
+const goldenDir = "testdata/golden"
+
+var (
+	ulidPattern  = regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`)
+	tokenPattern = regexp.MustCompile(`^eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+$`)
+)
+
+// TestGolden replays a representative session through the router
+// (registering, logging in, logging a brew and reading a page of a club
+// feed) and compares each answer with its golden file, so an accidental
+// change to the JSON envelope fails before it breaks the mobile clients.
+// Queries are answered by goldenScript rather than a database. IDs, tokens
+// and timestamps are replaced with placeholders first. After an intended
+// change, run it with -update and commit the golden files:
+//
+//	go test ./cmd/server -run TestGolden -update
+func TestGolden(t *testing.T) {
+	for key, value := range map[string]string{
+		"JWT_SECRET":  "golden-secret",
+		"LOG_LEVEL":   "ERROR",
+		"BCRYPT_COST": "4",
+	} {
+		t.Setenv(key, value)
+	}
+	cfg := config.LoadConfig()
+	logger.Init(cfg.LogLevel)
+	gin.SetMode(gin.TestMode)
+
+	conn := dbtest.New(t, goldenScript(t))
+	a := newApp(context.Background(), cfg, &database.Config{}, nil, conn)
+	t.Cleanup(a.close)
+	s := &goldenSession{t: t, router: a.router, conn: conn}
+
+	registered := s.step("register", http.MethodPost, "/auth/register", map[string]any{
+		"email":    "golden@example.com",
+		"username": "golden",
+		"password": goldenPassword,
+	}, http.StatusCreated)
+	s.token, _ = registered["token"].(string)
+	if s.token == "" {
+		t.Fatal("register: no token in the response")
+	}
+
+	s.step("login", http.MethodPost, "/auth/login", map[string]any{
+		"email":    "golden@example.com",
+		"password": goldenPassword,
+	}, http.StatusOK)
+
+	brew := s.step("brew-create", http.MethodPost, "/api/v1/brews", map[string]any{
+		"name":              "Morning V60",
+		"brew_method":       "v60",
+		"bean_origin":       "Ethiopia",
+		"roaster":           "Tim Wendelboe",
+		"notes":             "Bright and floral",
+		"dose":              18,
+		"water":             300,
+		"water_temp":        94,
+		"grind_setting":     "medium-fine",
+		"brew_time_seconds": 180,
+	}, http.StatusCreated)
+
+	club := s.step("", http.MethodPost, "/api/v1/clubs", map[string]any{
+		"name": "Golden Club",
+	}, http.StatusCreated)
+	feed := fmt.Sprintf("/api/v1/clubs/%v/feed", club["id"])
+	s.step("", http.MethodPost, feed, map[string]any{
+		"brew_id": brew["id"],
+		"note":    "Try this one",
+	}, http.StatusCreated)
+
+	s.step("feed-page", http.MethodGet, feed+"?limit=10", nil, http.StatusOK)
+}
+
+// goldenSession sends the requests of TestGolden, as the registered user
+// once there is one
+type goldenSession struct {
+	t      *testing.T
+	router http.Handler
+	conn   *dbtest.DB
+	token  string
+}
+
+// step sends a request and returns the data of its answer, which must
+// have status want. With a golden name, the answer is also compared with
+// that golden file. Steps that only set up the next ones have none.
+func (s *goldenSession) step(golden, method, path string, payload any, want int) map[string]any {
+	s.t.Helper()
+	var body io.Reader
+	if payload != nil {
+		raw, err := json.Marshal(payload)
+		if err != nil {
+			s.t.Fatal(err)
+		}
+		body = bytes.NewReader(raw)
+	}
+	req := httptest.NewRequest(method, path, body)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	// A fixed request ID keeps the echoed one out of the placeholders
+	if golden != "" {
+		req.Header.Set("X-Request-ID", "golden-"+golden)
+	}
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+
+	queries := s.conn.Take()
+	if rec.Code != want {
+		s.t.Fatalf("%s %s: status %d, want %d: %s\nqueries: %s", method, path, rec.Code, want, rec.Body.String(),
+			strings.Join(queries, ", "))
+	}
+
+	var envelope struct {
+		Data map[string]any `json:"data"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &envelope)
+
+	if golden != "" {
+		contentType, _, _ := mime.ParseMediaType(rec.Header().Get("Content-Type"))
+		s.compare(golden, rec.Code, contentType, rec.Body.Bytes())
+	}
+	return envelope.Data
+}
+
+// goldenAnswer is the content of a golden file. Object keys in Body are
+// sorted, as their order doesn't matter to clients.
+type goldenAnswer struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type"`
+	Body        any    `json:"body"`
+}
+
+// compare checks an answer against the golden file name, or writes it
+// there with -update
+func (s *goldenSession) compare(name string, status int, contentType string, body []byte) {
+	s.t.Helper()
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		s.t.Fatalf("%s: body isn't JSON: %v", name, err)
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(goldenAnswer{Status: status, ContentType: contentType, Body: normalize(value)}); err != nil {
+		s.t.Fatal(err)
+	}
+	got := buf.Bytes()
+
+	path := filepath.Join(goldenDir, name+".json")
+	if *update {
+		if err := os.MkdirAll(goldenDir, 0o755); err != nil {
+			s.t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			s.t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		s.t.Errorf("%s changed; if intended, run with -update and commit it:\n%s", path, diff(string(want), string(got)))
+	}
+}
+
+// normalize replaces the values that differ between runs with
+// placeholders: IDs, timestamps and tokens with <ulid>, <timestamp> and
+// <token>
+func normalize(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalize(item)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = normalize(item)
+		}
+		return v
+	case string:
+		switch {
+		case ulidPattern.MatchString(v):
+			return "<ulid>"
+		case tokenPattern.MatchString(v):
+			return "<token>"
+		}
+		if _, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return "<timestamp>"
+		}
+		return v
+	}
+	return value
+}
+
+// diff lists the lines of want missing from got with -, and those added
+// with +, leaving out the lines both share
+func diff(want, got string) string {
+	a, b := strings.Split(want, "\n"), strings.Split(got, "\n")
+
+	// common[i][j] is the length of the longest common subsequence of
+	// a[i:] and b[j:]
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var out strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case j < len(b) && (i == len(a) || common[i][j+1] >= common[i+1][j]):
+			fmt.Fprintf(&out, "  + %s\n", b[j])
+			j++
+		default:
+			fmt.Fprintf(&out, "  - %s\n", a[i])
+			i++
+		}
+	}
+	return out.String()
+}
+
+// goldenTime is when everything in the script happened
+var goldenTime = time.Date(2026, 3, 14, 8, 30, 0, 0, time.UTC)
+
+const goldenPassword = "golden-password-1"
+
+// goldenScript answers the queries of TestGolden's session as a database
+// would for a new user: each row it creates is kept for the queries
+// reading it back, with what the database would fill in
+func goldenScript(t *testing.T) dbtest.Script {
+	hash, err := auth.HashPassword(goldenPassword, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		user  db.CreateUserRow
+		brew  db.Brew
+		club  db.Club
+		share *db.ClubShare
+	)
+	return dbtest.Script{
+		"SELECT version, dirty FROM schema_migrations LIMIT 1": func([]any) []any {
+			version, err := schemacheck.Expected()
+			if err != nil {
+				t.Fatal(err)
+			}
+			return []any{struct {
+				Version int64
+				Dirty   bool
+			}{version, false}}
+		},
+		"SELECT extname FROM pg_extension": func([]any) []any {
+			var rows []any
+			for _, name := range schemacheck.RequiredExtensions {
+				rows = append(rows, name)
+			}
+			return rows
+		},
+		"IsEmailDomainBlocked":       dbtest.Rows(false),
+		"ListCurrentPolicyDocuments": dbtest.Rows(),
+		"ListUserPolicyDocuments":    dbtest.Rows(),
+		"RecordAuthEvent":            dbtest.Rows(),
+		"QueueBadgeEvaluation":       dbtest.Rows(),
+		"CheckEmailAvailability":     dbtest.Rows(true),
+		"CheckUsernameAvailability":  dbtest.Rows(true),
+		"CreateUser": func(args []any) []any {
+			params := dbtest.Params[db.CreateUserParams](args)
+			user = db.CreateUserRow{
+				ID:        params.ID,
+				Username:  params.Username,
+				Email:     params.Email,
+				JoinedAt:  pgtype.Timestamptz{Time: goldenTime, Valid: true},
+				CreatedAt: goldenTime,
+			}
+			return []any{user}
+		},
+		"GetUserByEmail": func([]any) []any {
+			return []any{db.GetUserByEmailRow{ID: user.ID, Username: user.Username, Email: user.Email, PasswordHash: hash}}
+		},
+		"GetUserTOTP":  func([]any) []any { return []any{db.GetUserTOTPRow{Username: user.Username}} },
+		"GetLoginRisk": dbtest.Rows(db.GetLoginRiskRow{HasHistory: true, KnownDevice: true, KnownLocation: true}),
+		"IsUserActive": dbtest.Rows(true),
+		"GetUserPlan":  dbtest.Rows(plans.Free),
+		"GetUserPreferences": dbtest.Rows(db.GetUserPreferencesRow{
+			WeightUnit: "g", TemperatureUnit: "c", Timezone: "UTC", Currency: "USD",
+		}),
+		"CreateBrew": func(args []any) []any {
+			params := dbtest.Params[db.CreateBrewParams](args)
+			brew = db.Brew{
+				ID:              params.ID,
+				Name:            params.Name,
+				BrewMethod:      params.BrewMethod,
+				BeanOrigin:      params.BeanOrigin,
+				Roaster:         params.Roaster,
+				Notes:           params.Notes,
+				CreatedBy:       params.CreatedBy,
+				IsPublic:        params.IsPublic,
+				CreatedAt:       goldenTime,
+				UpdatedAt:       goldenTime,
+				DoseGrams:       params.DoseGrams,
+				WaterGrams:      params.WaterGrams,
+				WaterTempC:      params.WaterTempC,
+				GrindSetting:    params.GrindSetting,
+				BrewTimeSeconds: params.BrewTimeSeconds,
+				StartedAt:       params.StartedAt,
+				EndedAt:         params.EndedAt,
+				RecipeID:        params.RecipeID,
+				RecipeRevision:  params.RecipeRevision,
+				BeanBagID:       params.BeanBagID,
+				CustomFields:    orDefault(params.CustomFields, []byte("{}")),
+				SyncSeq:         1,
+				SyncVector:      orDefault(params.SyncVector, []byte("{}")),
+				OrganizationID:  params.OrganizationID,
+			}
+			return []any{brew}
+		},
+		"GetBrewByID": func([]any) []any { return []any{brew} },
+		"CreateClub": func(args []any) []any {
+			params := dbtest.Params[db.CreateClubParams](args)
+			club = db.Club{
+				ID:          params.ID,
+				OwnerID:     params.OwnerID,
+				Name:        params.Name,
+				Description: params.Description,
+				IsPrivate:   params.IsPrivate,
+				CreatedAt:   goldenTime,
+				UpdatedAt:   goldenTime,
+			}
+			return []any{club}
+		},
+		"GetClubByID":       func([]any) []any { return []any{club} },
+		"GetClubMemberRole": dbtest.Rows("owner"),
+		"CreateClubShare": func(args []any) []any {
+			params := dbtest.Params[db.CreateClubShareParams](args)
+			share = &db.ClubShare{
+				ID:        params.ID,
+				ClubID:    params.ClubID,
+				UserID:    params.UserID,
+				BrewID:    params.BrewID,
+				RecipeID:  params.RecipeID,
+				Note:      params.Note,
+				CreatedAt: goldenTime,
+			}
+			return []any{*share}
+		},
+		"ListClubFeed": func([]any) []any {
+			if share == nil {
+				return nil
+			}
+			return []any{db.ListClubFeedRow{
+				ID:         share.ID,
+				UserID:     share.UserID,
+				Username:   user.Username,
+				BrewID:     share.BrewID,
+				BrewName:   &brew.Name,
+				BrewMethod: brew.BrewMethod,
+				BeanOrigin: brew.BeanOrigin,
+				Note:       share.Note,
+				CreatedAt:  goldenTime,
+			}}
+		},
+	}
+}
+
+// orDefault returns value, or def for an empty value as COALESCE would
+func orDefault(value, def []byte) []byte {
+	if len(value) == 0 {
+		return def
+	}
+	return value
+}
@@ -2,27 +2,80 @@ package main
 
 import (
 	"context"
+	"errors"
+	"io/fs"
 	"net/http"
 	"os"
+	"syscall"
 	"time"
 
+	"brewd/internal/accounts"
+	"brewd/internal/agegate"
+	"brewd/internal/apikeys"
+	"brewd/internal/archive"
 	"brewd/internal/auth"
+	"brewd/internal/authevents"
+	"brewd/internal/automations"
+	"brewd/internal/backup"
+	"brewd/internal/badges"
+	"brewd/internal/beans"
+	"brewd/internal/billing"
+	"brewd/internal/calendar"
+	"brewd/internal/captcha"
 	"brewd/internal/config"
+	"brewd/internal/cookies"
 	"brewd/internal/db"
+	"brewd/internal/disposable"
+	"brewd/internal/drain"
+	"brewd/internal/errorsink"
+	"brewd/internal/events"
+	"brewd/internal/geoip"
 	"brewd/internal/handlers"
+	"brewd/internal/health"
+	"brewd/internal/lease"
+	"brewd/internal/links"
 	"brewd/internal/logger"
+	"brewd/internal/mail"
+	"brewd/internal/malware"
+	"brewd/internal/media"
 	"brewd/internal/middleware"
+	"brewd/internal/objectstore"
+	"brewd/internal/oidc"
+	"brewd/internal/opsstats"
+	"brewd/internal/outbox"
+	"brewd/internal/partitions"
+	"brewd/internal/photos"
+	"brewd/internal/plans"
+	"brewd/internal/policies"
+	"brewd/internal/realtime"
+	"brewd/internal/recommendations"
+	"brewd/internal/reminders"
+	"brewd/internal/replay"
+	"brewd/internal/respond"
+	"brewd/internal/retention"
+	"brewd/internal/schemacheck"
+	"brewd/internal/scim"
+	"brewd/internal/server"
+	"brewd/internal/slo"
+	"brewd/internal/stepup"
+	"brewd/internal/telemetry"
+	"brewd/internal/trending"
+	"brewd/internal/validation"
+	"brewd/internal/waitlist"
+	"brewd/internal/webapp"
 	"brewd/pkg/database"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 func main() {
 	cfg := config.LoadConfig()
 	logger.Init(cfg.LogLevel)
 
-	// Initialize database connection
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Initialize database connection. Startup and the workers act for the
+	// server itself, not for a user, under row security.
+	ctx, cancel := context.WithTimeout(database.AsServer(context.Background()), 30*time.Second)
 	defer cancel()
 
 	// Get db config
@@ -39,58 +92,1013 @@ func main() {
 	}
 	defer pool.Close()
 
+	// Open and prime the minimum connections before serving, so the first
+	// requests after a deploy don't pay for connecting
+	if dbConfig.WarmUp {
+		start := time.Now()
+		warmed, err := pool.WarmUp(ctx)
+		if err != nil {
+			logger.Warn("Failed to warm up all database connections", "warmed", warmed, "error", err)
+		} else {
+			logger.Info("Database connections warmed up", "warmed", warmed, "duration_ms", time.Since(start).Milliseconds())
+		}
+	}
+
+	a := newApp(ctx, cfg, dbConfig, pool, pool)
+	defer a.close()
+	workerCtx, stopWorkers := context.WithCancel(database.AsServer(context.Background()))
+	defer stopWorkers()
+	a.run(workerCtx)
+
+	// Serve the API, terminating TLS with HTTP/2 when configured
+	srv, err := server.New(server.Config{
+		Name:             "api",
+		Addr:             ":" + cfg.Port,
+		Listen:           cfg.Listen,
+		UnixSocketMode:   fs.FileMode(cfg.UnixSocketMode),
+		TLSCertFile:      cfg.TLSCertFile,
+		TLSKeyFile:       cfg.TLSKeyFile,
+		AutocertDomains:  cfg.AutocertDomains,
+		AutocertCacheDir: cfg.AutocertCacheDir,
+		AutocertEmail:    cfg.AutocertEmail,
+		RedirectAddr:     cfg.HTTPRedirectAddr,
+	}, a.router)
+	if err != nil {
+		logger.Error("Invalid server configuration", "error", err)
+		os.Exit(1)
+	}
+
+	servers := []drain.Server{srv}
+
+	// Admin tooling, metrics and pprof on the internal listener, if any
+	if a.internalRouter != nil {
+		internalSrv, err := server.New(server.Config{
+			Name:           "internal",
+			Listen:         cfg.InternalListen,
+			UnixSocketMode: fs.FileMode(cfg.UnixSocketMode),
+		}, a.internalRouter)
+		if err != nil {
+			logger.Error("Invalid internal listener configuration", "error", err)
+			os.Exit(1)
+		}
+		go func() {
+			if err := internalSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error("Failed to start internal listener", "error", err)
+				os.Exit(1)
+			}
+		}()
+		servers = append(servers, internalSrv)
+	}
+
+	// Kubernetes and ECS send SIGTERM to stop the instance
+	a.drainer.Notify(syscall.SIGTERM, os.Interrupt)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Failed to start server", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+	if err := a.drainer.Drain(servers...); err != nil {
+		logger.Warn("Requests were still in flight at the shutdown timeout", "error", err)
+	}
+	stopWorkers()
+	logger.Info("Shut down")
+}
+
+// app is the API wired to its dependencies: the routers, and the background
+// workers and cleanups main runs around serving them
+type app struct {
+	router         *gin.Engine
+	internalRouter *gin.Engine
+	drainer        *drain.Coordinator
+	leases         *lease.Manager
+	workers        []func(ctx context.Context)
+	closers        []func() error
+}
+
+// worker adds a background job, started by run
+func (a *app) worker(job func(ctx context.Context)) {
+	a.workers = append(a.workers, job)
+}
+
+// singleton adds a background job run on whichever instance holds its
+// lease
+func (a *app) singleton(name string, job func(ctx context.Context)) {
+	a.worker(func(ctx context.Context) { a.leases.Run(ctx, name, job) })
+}
+
+// run starts the background workers, which stop with ctx
+func (a *app) run(ctx context.Context) {
+	for _, job := range a.workers {
+		go job(ctx)
+	}
+}
+
+// close releases what newApp opened
+func (a *app) close() {
+	for _, closer := range a.closers {
+		if err := closer(); err != nil {
+			logger.Warn("Failed to close", "error", err)
+		}
+	}
+}
+
+// newApp builds the API's dependencies and registers its routes, exiting on
+// invalid configuration like main. Queries go to conn, which is pool
+// outside tests; /health also reports pool's connection stats.
+func newApp(ctx context.Context, cfg *config.Config, dbConfig *database.Config, pool *database.Pool, conn db.DBTX) *app {
+	a := &app{}
+
 	// Create queries instance for database operations
-	queries := db.New(pool)
+	queries := db.New(conn)
 	logger.Info("Database connection established")
 
 	// Initialize authentication service
 	authService := auth.NewService(cfg.JWTSecret, cfg.JWTExpirationHrs)
 	logger.Info("Authentication service initialized")
 
+	// Register the custom validation rules and localize validation messages
+	// for bound request payloads
+	if err := validation.Register(); err != nil {
+		logger.Error("Failed to register validation rules", "error", err)
+		os.Exit(1)
+	}
+
+	// Domain events are relayed from the outbox to the configured broker
+	eventPublisher, err := events.NewPublisher(cfg.EventPublisher, cfg.NATSURL)
+	if err != nil {
+		logger.Error("Invalid EVENT_PUBLISHER", "error", err)
+		os.Exit(1)
+	}
+	a.closers = append(a.closers, eventPublisher.Close)
+
+	// Email goes out through the configured sender, skipping suppressed
+	// addresses
+	mailSender, err := mail.NewSender(cfg.MailSender, cfg.SMTPURL, cfg.MailFrom)
+	if err != nil {
+		logger.Error("Invalid mail configuration", "error", err)
+		os.Exit(1)
+	}
+	mailer := mail.NewMailer(mailSender, queries)
+
+	// Readiness checks reported by /health
+	healthChecks := health.NewRegistry()
+
+	// Draining turns readiness false and shuts down once load balancers
+	// have stopped routing here, on SIGTERM or the internal drain endpoint
+	drainTimings, err := drain.PlatformTimings(cfg.DrainPlatform)
+	if err != nil {
+		logger.Error("Invalid drain configuration", "error", err)
+		os.Exit(1)
+	}
+	if cfg.DrainDelaySeconds >= 0 {
+		drainTimings.Delay = time.Duration(cfg.DrainDelaySeconds) * time.Second
+	}
+	if cfg.ShutdownTimeoutSeconds >= 0 {
+		drainTimings.Timeout = time.Duration(cfg.ShutdownTimeoutSeconds) * time.Second
+	}
+	drainer := drain.New(drainTimings)
+
+	// The instance reports not ready until the schema has every migration
+	// it was built with, rather than failing requests against it
+	schemaStatus, err := schemacheck.Check(ctx, conn)
+	switch {
+	case err != nil:
+		logger.Error("Failed to check database schema", "error", err)
+	case !schemaStatus.Ready():
+		logger.Error("Database schema is not ready, refusing traffic until it is",
+			"schema_version", schemaStatus.Version, "expected_version", schemaStatus.Expected,
+			"dirty", schemaStatus.Dirty, "missing_extensions", schemaStatus.MissingExtensions,
+			"error", schemaStatus.Err())
+	case schemaStatus.Version > schemaStatus.Expected:
+		logger.Warn("Database schema is ahead of this server",
+			"schema_version", schemaStatus.Version, "expected_version", schemaStatus.Expected)
+	default:
+		logger.Info("Database schema is up to date", "schema_version", schemaStatus.Version)
+	}
+	healthChecks.Register("schema", schemacheck.HealthCheck(conn),
+		health.Options{Timeout: 5 * time.Second, CacheTTL: 30 * time.Second})
+
+	// Downstream dependencies. The API can serve most requests without
+	// them, so they only degrade its status.
+	if pinger, ok := mailSender.(mail.Pinger); ok {
+		healthChecks.Register("mail", health.CheckerFunc(pinger.Ping),
+			health.Options{Timeout: 10 * time.Second, CacheTTL: time.Minute, Optional: true})
+	}
+	if pinger, ok := eventPublisher.(events.Pinger); ok {
+		healthChecks.Register("event_broker", health.CheckerFunc(pinger.Ping),
+			health.Options{Timeout: 5 * time.Second, CacheTTL: 30 * time.Second, Optional: true})
+	}
+	healthChecks.Register("job_queue", opsstats.QueueCheck(queries, 15*time.Minute),
+		health.Options{Timeout: 3 * time.Second, CacheTTL: 30 * time.Second, Optional: true})
+
+	// Bots are held off public auth endpoints with a CAPTCHA, or a
+	// proof-of-work puzzle keyed by the JWT secret
+	captchaSecret := cfg.CaptchaSecret
+	if cfg.CaptchaProvider == captcha.ProviderPoW && captchaSecret == "" {
+		captchaSecret = cfg.JWTSecret
+	}
+	captchaVerifier, err := captcha.NewVerifier(cfg.CaptchaProvider, captchaSecret, cfg.CaptchaSiteKey, cfg.CaptchaPoWDifficulty)
+	if err != nil {
+		logger.Error("Invalid captcha configuration", "error", err)
+		os.Exit(1)
+	}
+	loginFailures := captcha.NewFailures(cfg.CaptchaLoginFailures)
+
+	// Suspicious logins (new device, new location, many failures) are held
+	// until the user enters an emailed or TOTP code
+	stepUpGuard := stepup.NewGuard(mailer, cfg.StepUpThreshold, cfg.StepUpFailures)
+
+	// Web clients can keep their JWT in an HttpOnly session cookie instead
+	// of script-readable storage
+	cookiePolicy := cookies.Policy{
+		Secure:     cfg.CookieSecure,
+		Domain:     cfg.CookieDomain,
+		SessionTTL: time.Duration(cfg.JWTExpirationHrs) * time.Hour,
+	}
+
+	// Registrations from disposable email domains are rejected or flagged
+	switch cfg.DisposableEmails {
+	case disposable.ActionReject, disposable.ActionFlag, disposable.ActionOff:
+	default:
+		logger.Error("Invalid DISPOSABLE_EMAILS", "value", cfg.DisposableEmails)
+		os.Exit(1)
+	}
+
+	// Auth events are located with a MaxMind GeoIP database, if one is
+	// configured
+	geoLocator, err := geoip.Open(cfg.GeoIPDatabase)
+	if err != nil {
+		logger.Error("Invalid GEOIP_DATABASE", "error", err)
+		os.Exit(1)
+	}
+
+	// Signups under the minimum age for their country, as the GeoIP
+	// database places them, need a parent's consent
+	ageGate, err := agegate.New(cfg.MinimumAge, cfg.MinimumAgeRegions, cfg.ParentalConsent)
+	if err != nil {
+		logger.Error("Invalid MINIMUM_AGE_REGIONS", "error", err)
+		os.Exit(1)
+	}
+
+	// App store purchases are verified with whichever stores are configured
+	storeVerifiers := map[string]billing.Verifier{}
+	if cfg.AppleSharedSecret != "" {
+		storeVerifiers[billing.ProviderApple] = billing.NewAppStore(cfg.AppleSharedSecret, cfg.AppleBundleID)
+	}
+	if cfg.GooglePlayCredentialsFile != "" {
+		playStore, err := billing.NewPlayStore(cfg.GooglePlayPackageName, cfg.GooglePlayCredentialsFile)
+		if err != nil {
+			logger.Error("Invalid GOOGLE_PLAY_CREDENTIALS_FILE", "error", err)
+			os.Exit(1)
+		}
+		storeVerifiers[billing.ProviderGoogle] = playStore
+	}
+
+	// Deliver steeping reminders for long-running brew timers and bean
+	// reorder suggestions, award badges for completed challenges, dispatch
+	// outbox notifications and webhook events, send smart-home webhooks,
+	// rank trending recipes and beans and score personal recommendations,
+	// publish domain events, aggregate the admin dashboard's stats, verify
+	// app store subscriptions as they come up for renewal, and refresh the
+	// disposable email domain blocklist. Workers claiming rows run on every
+	// instance; singleton jobs run on whichever instance holds their lease.
+	instanceID := uuid.NewString()
+	a.leases = lease.NewManager(queries, instanceID)
+	a.worker(func(ctx context.Context) {
+		reminders.Run(ctx, queries, time.Duration(cfg.ReminderPollSeconds)*time.Second)
+	})
+	a.singleton("bean_reorder_checks", func(ctx context.Context) {
+		beans.RunReorderChecks(ctx, queries, time.Duration(cfg.ReorderCheckMinutes)*time.Minute, float64(cfg.DefaultDoseGrams))
+	})
+	a.worker(func(ctx context.Context) {
+		badges.Run(ctx, queries, time.Duration(cfg.BadgePollSeconds)*time.Second)
+	})
+	a.worker(func(ctx context.Context) {
+		outbox.Run(ctx, queries, time.Duration(cfg.OutboxPollSeconds)*time.Second)
+	})
+	a.worker(func(ctx context.Context) {
+		automations.Run(ctx, queries, time.Duration(cfg.AutomationPollSeconds)*time.Second)
+	})
+	a.singleton("trending", func(ctx context.Context) {
+		trending.Run(ctx, queries, time.Duration(cfg.TrendingRefreshMinutes)*time.Minute)
+	})
+	a.worker(func(ctx context.Context) {
+		recommendations.Run(ctx, queries, time.Duration(cfg.RecommendationPollMinutes)*time.Minute)
+	})
+	a.worker(func(ctx context.Context) {
+		events.Run(ctx, queries, eventPublisher, time.Duration(cfg.EventPollSeconds)*time.Second)
+	})
+	requestRecorder := opsstats.NewRecorder()
+	a.worker(func(ctx context.Context) {
+		opsstats.Run(ctx, queries, requestRecorder, time.Duration(cfg.OpsStatsMinutes)*time.Minute)
+	})
+	a.singleton("iap_renewals", func(ctx context.Context) {
+		billing.RunRenewals(ctx, queries, storeVerifiers, time.Duration(cfg.IAPRenewalPollMinutes)*time.Minute)
+	})
+	a.singleton("disposable_domains", func(ctx context.Context) {
+		disposable.Run(ctx, queries, cfg.DisposableDomainsURL, time.Duration(cfg.DisposableRefreshHours)*time.Hour)
+	})
+
+	// Canonical web URLs for public content, used by oEmbed and sitemaps
+	site, err := links.NewSite(cfg.PublicBaseURL)
+	if err != nil {
+		logger.Error("Invalid PUBLIC_BASE_URL", "error", err)
+		os.Exit(1)
+	}
+
+	// Mail the invites of approved waitlist entries, which link to the web app
+	a.singleton("waitlist_invites", func(ctx context.Context) {
+		waitlist.Run(ctx, queries, mailer, site, time.Duration(cfg.WaitlistPollSeconds)*time.Second)
+	})
+
+	// Email users about logins from new locations. Without a GeoIP database
+	// no login has a location, so none is new.
+	a.singleton("login_alerts", func(ctx context.Context) {
+		authevents.Run(ctx, queries, mailer, cfg.LoginAlerts, time.Duration(cfg.AuthEventPollSeconds)*time.Second)
+	})
+
+	// Create the brew table's monthly partitions ahead, and detach those
+	// past retention, if one is set
+	a.singleton("brew_partitions", func(ctx context.Context) {
+		partitions.Run(ctx, queries, time.Duration(cfg.BrewPartitionCheckHours)*time.Hour, cfg.PartitionRetentionMonths)
+	})
+
+	// Move brews past the archive age into cold storage, if one is set;
+	// history reads them back marked archived
+	a.singleton("brew_archive", func(ctx context.Context) {
+		archive.Run(ctx, queries, time.Duration(cfg.BrewArchivePollMinutes)*time.Minute, cfg.BrewArchiveAfterDays)
+	})
+
+	// Purge auth events, analytics events, notifications and revoked
+	// records past their retention periods, or only log them in a dry run
+	retentionJob := retention.NewJob(queries, retention.Policy{
+		AuthEventDays:    cfg.AuthEventRetentionDays,
+		AnalyticsDays:    cfg.AnalyticsRetentionDays,
+		NotificationDays: cfg.NotificationRetentionDays,
+		SoftDeletedDays:  cfg.SoftDeletedRetentionDays,
+	}, cfg.RetentionDryRun)
+	a.singleton("retention", func(ctx context.Context) {
+		retentionJob.Run(ctx, time.Duration(cfg.RetentionPollMinutes)*time.Minute)
+	})
+
+	// Backups and brew photos are kept in the object store, if one is
+	// configured
+	var store objectstore.Store
+	if cfg.ObjectStoreURL != "" {
+		store, err = objectstore.New(cfg.ObjectStoreURL, cfg.ObjectStoreAccessKey, cfg.ObjectStoreSecretKey)
+		if err != nil {
+			logger.Error("Invalid OBJECT_STORE_URL", "error", err)
+			os.Exit(1)
+		}
+		healthChecks.Register("object_store", health.CheckerFunc(func(ctx context.Context) error {
+			return objectstore.Ping(ctx, store)
+		}), health.Options{Timeout: 10 * time.Second, CacheTTL: time.Minute, Optional: true})
+	}
+
+	// Clients download photos and backup dumps through signed URLs, served
+	// by brewd, the object store or a CDN
+	var mediaURLs *media.URLs
+	if store != nil {
+		mediaURLs, err = media.New(media.Config{
+			Signer:         cfg.MediaURLSigner,
+			TTL:            time.Duration(cfg.MediaURLTTLMinutes) * time.Minute,
+			Site:           site,
+			Secret:         cfg.JWTSecret,
+			Store:          store,
+			PublicEndpoint: cfg.MediaPublicEndpoint,
+			CDNURL:         cfg.MediaCDNURL,
+			KeyPairID:      cfg.CloudFrontKeyPairID,
+			PrivateKey:     cfg.CloudFrontPrivateKey,
+		})
+		if err != nil {
+			logger.Error("Invalid media URL configuration", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Take logical backups into the object store, verifying each restores;
+	// admins can also trigger them
+	var backups *backup.Runner
+	if store != nil {
+		backups, err = backup.NewRunner(queries, store, mailer, cfg.AdminUserIDs, dbConfig.ConnectionString(),
+			cfg.BackupScratchDatabaseURL)
+		if err != nil {
+			logger.Error("Invalid BACKUP_SCRATCH_DATABASE_URL", "error", err)
+			os.Exit(1)
+		}
+		a.singleton("backups", func(ctx context.Context) {
+			backups.Run(ctx, time.Duration(cfg.BackupIntervalHours)*time.Hour, cfg.BackupRetentionDays)
+		})
+	}
+
+	// Render uploaded brew photos, scanning them for malware and screening
+	// them if a scanner and screener are configured; any instance may
+	// process the queue
+	if store != nil {
+		scanner, err := malware.New(cfg.UploadScanner, cfg.UploadScannerURL)
+		if err != nil {
+			logger.Error("Invalid UPLOAD_SCANNER", "error", err)
+			os.Exit(1)
+		}
+		screener, err := photos.NewScreener(cfg.PhotoScreener, cfg.PhotoScreenerURL)
+		if err != nil {
+			logger.Error("Invalid PHOTO_SCREENER", "error", err)
+			os.Exit(1)
+		}
+		processor := photos.NewProcessor(queries, store, mailer, cfg.AdminUserIDs, scanner, screener, cfg.PhotoScreenThreshold)
+		a.worker(func(ctx context.Context) {
+			processor.Run(ctx, time.Duration(cfg.PhotoPollSeconds)*time.Second)
+		})
+	}
+
+	// Signs the per-user calendar feed URLs that calendar apps subscribe to
+	calendarSigner := calendar.NewSigner(cfg.JWTSecret)
+
+	// Client analytics events go to the configured sink
+	analyticsSink, err := telemetry.NewSink(cfg.AnalyticsSink, queries)
+	if err != nil {
+		logger.Error("Invalid ANALYTICS_SINK", "error", err)
+		os.Exit(1)
+	}
+
+	// Where recovered panics are reported, beyond the log
+	errorSink, err := errorsink.NewSink(cfg.ErrorSink, cfg.ErrorSinkURL)
+	if err != nil {
+		logger.Error("Invalid ERROR_SINK", "error", err)
+		os.Exit(1)
+	}
+
+	// Users' plan tiers, which set their quotas
+	planCache := plans.NewCache(queries)
+
+	// Whether users have accepted the current terms and privacy policy
+	policyCache := policies.NewCache(queries)
+
+	// Whether users' accounts are still active, checked on every
+	// authenticated request
+	activeCache := accounts.NewCache(queries)
+
+	// Inbound webhooks and device requests are accepted once, within the
+	// clock-skew tolerance
+	replayGuard := replay.NewGuard(queries, time.Duration(cfg.RequestClockSkewSeconds)*time.Second)
+
+	// Supporter subscriptions are sold through Stripe when it is configured
+	var stripe *billing.Stripe
+	if cfg.StripeSecretKey != "" {
+		if cfg.StripePriceID == "" {
+			logger.Error("STRIPE_PRICE_ID is required with STRIPE_SECRET_KEY")
+			os.Exit(1)
+		}
+		stripe = billing.NewStripe(cfg.StripeSecretKey, cfg.StripePriceID)
+	}
+
+	// Companion services can sign users in with brewd when OIDC is set up
+	var oidcProvider *oidc.Provider
+	if cfg.OIDCIssuer != "" {
+		oidcProvider, err = oidc.NewProvider(cfg.OIDCIssuer, cfg.OIDCSigningKey)
+		if err != nil {
+			logger.Error("Invalid OIDC configuration", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Fan brew event timer, RSVP and presence updates out to streaming
+	// clients, on every instance when relayed through Postgres
+	hub := realtime.NewHub()
+	switch cfg.RealtimeBroker {
+	case "local":
+	case "postgres":
+		connString := cfg.RealtimeDatabaseURL
+		if connString == "" {
+			if dbConfig.PgBouncer {
+				logger.Error("REALTIME_BROKER=postgres needs REALTIME_DATABASE_URL behind pgbouncer, which can't hold a LISTEN session")
+				os.Exit(1)
+			}
+			connString = dbConfig.ConnectionString()
+		}
+		relay := realtime.NewRelay(hub, queries, connString, instanceID)
+		hub.SetRelay(relay)
+		a.worker(relay.Run)
+	default:
+		logger.Error("Unknown REALTIME_BROKER, want local or postgres", "broker", cfg.RealtimeBroker)
+		os.Exit(1)
+	}
+	presence := realtime.NewPresence(queries, hub, instanceID)
+	a.worker(presence.Run)
+
 	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	// Create router
-	router := gin.Default()
+	// Create router. Requests are logged and panics recovered by the
+	// middleware below rather than gin's defaults.
+	router := gin.New()
 
-	// Add recovery middleware first to catch panics
-	router.Use(gin.Recovery())
+	// Only proxies in TRUSTED_PROXIES may name the client with
+	// X-Forwarded-For or X-Real-IP: the client IP is the rightmost address
+	// not of a trusted proxy. Without any, it's the connection's peer. A
+	// platform header such as CF-Connecting-IP is taken as the client IP
+	// when set.
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		logger.Error("Invalid TRUSTED_PROXIES", "error", err)
+		os.Exit(1)
+	}
+	router.TrustedPlatform = cfg.TrustedPlatform
 
-	// Add logger middleware
-	router.Use(middleware.Logger())
+	// Add logger middleware, which also assigns the request ID
+	router.Use(middleware.Logger(cfg.AccessLogSkipHealth))
+
+	// Tag the SQL each request sends with its route and request ID
+	if dbConfig.QueryTags {
+		router.Use(middleware.QueryTags())
+	}
+
+	// Count requests and error responses for the admin dashboard
+	router.Use(middleware.RecordRequests(requestRecorder))
+
+	// Measure each route's success ratio and latency against the SLOs
+	sloRecorder := slo.NewRecorder(slo.Objectives{
+		Availability:     cfg.SLOAvailabilityPercent,
+		LatencyThreshold: time.Duration(cfg.SLOLatencyMs) * time.Millisecond,
+		Latency:          cfg.SLOLatencyPercent,
+	})
+	router.Use(middleware.MeasureRequests(sloRecorder))
+
+	// Negotiate response language from Accept-Language
+	router.Use(middleware.Locale())
+
+	// Recover from panics in handlers with a logged stack, a report to the
+	// error sink and a 500 problem response. It runs after the middleware
+	// above, so the failed request is still logged, counted and localized.
+	router.Use(middleware.Recovery(errorSink))
+
+	// Cookie-authenticated requests that change state need the CSRF token
+	router.Use(middleware.CSRF())
 
 	// Public routes
-	router.GET("/health", handlers.HealthCheckWithDB(pool))
+	router.GET("/health", handlers.HealthCheckWithDB(pool, healthChecks, drainer))
+
+	// Operational endpoints (admin tooling, metrics and pprof) are served
+	// on the internal listener when one is configured, and never on the
+	// public API; without one, admin tooling and token-protected metrics
+	// stay on the API
+	var internalRouter *gin.Engine
+	if cfg.InternalListen != "" {
+		internalRouter = gin.New()
+		// Client IPs are resolved as on the public router; gin would
+		// otherwise take X-Forwarded-For from anyone
+		if err := internalRouter.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+			logger.Error("Invalid TRUSTED_PROXIES", "error", err)
+			os.Exit(1)
+		}
+		internalRouter.TrustedPlatform = cfg.TrustedPlatform
+		internalRouter.Use(middleware.Logger(cfg.AccessLogSkipHealth))
+		if dbConfig.QueryTags {
+			internalRouter.Use(middleware.QueryTags())
+		}
+		internalRouter.Use(middleware.Locale())
+		internalRouter.Use(middleware.Recovery(errorSink))
+		internalRouter.Use(middleware.CSRF())
+
+		internalRouter.GET("/health", handlers.HealthCheckWithDB(pool, healthChecks, drainer))
+		internalRouter.GET("/metrics", handlers.InternalMetrics(sloRecorder))
+		internalRouter.Any("/debug/pprof/*profile", handlers.Pprof())
+		internalRouter.POST("/internal/drain", handlers.Drain(drainer))
+	} else {
+		// Prometheus scrapes the SLO metrics with METRICS_TOKEN
+		router.GET("/metrics", handlers.Metrics(sloRecorder, cfg.MetricsToken))
+	}
 
 	// Auth routes (public)
 	authGroup := router.Group("/auth")
 	{
-		authGroup.POST("/register", handlers.Register(queries, authService, cfg.BcryptCost))
-		authGroup.POST("/login", handlers.Login(queries, authService))
+		authGroup.GET("/captcha",
+			middleware.RateLimit(cfg.AvailabilityRateLimit, time.Minute),
+			handlers.GetCaptcha(captchaVerifier),
+		)
+		authGroup.POST("/register",
+			middleware.RequireCaptcha(captchaVerifier),
+			handlers.Register(queries, authService, cfg.BcryptCost, handlers.RegistrationPolicy{
+				InviteOnly:       cfg.InviteOnly,
+				Waitlist:         cfg.Waitlist,
+				DisposableEmails: cfg.DisposableEmails,
+				AgeGate:          ageGate,
+			}, geoLocator, cookiePolicy, mailer, site),
+		)
+		authGroup.POST("/login", handlers.Login(queries, authService, captchaVerifier, loginFailures, geoLocator, stepUpGuard, cookiePolicy, mailer, site))
+		authGroup.POST("/step-up", handlers.VerifyStepUp(queries, authService, loginFailures, geoLocator, cookiePolicy, cfg.TrustedDeviceDays))
+		authGroup.POST("/logout", handlers.Logout(cookiePolicy))
+		authGroup.GET("/csrf", handlers.GetCSRFToken(cookiePolicy))
+		authGroup.GET("/policies", handlers.ListPolicies(queries))
+		authGroup.GET("/availability",
+			middleware.RateLimit(cfg.AvailabilityRateLimit, time.Minute),
+			handlers.CheckAvailability(queries),
+		)
+		authGroup.GET("/invites/:code",
+			middleware.RateLimit(cfg.AvailabilityRateLimit, time.Minute),
+			handlers.CheckInvite(queries),
+		)
+		authGroup.GET("/parental-consent/:token",
+			middleware.RateLimit(cfg.AvailabilityRateLimit, time.Minute),
+			handlers.GetParentalConsent(queries),
+		)
+		authGroup.POST("/parental-consent/:token",
+			middleware.RateLimit(cfg.AvailabilityRateLimit, time.Minute),
+			handlers.GrantParentalConsent(queries),
+		)
 	}
 
-	// Protected API routes (require authentication)
+	// OIDC provider for companion services. The browser reaches authorize
+	// by navigation and the client's server calls token and userinfo.
+	if oidcProvider != nil {
+		router.GET(oidc.DiscoveryPath, handlers.OIDCDiscovery(oidcProvider))
+		router.GET(oidc.KeysPath, handlers.OIDCKeys(oidcProvider))
+		router.GET(oidc.AuthorizePath, handlers.OIDCAuthorize(queries, oidcProvider, authService, activeCache, site))
+		router.POST(oidc.TokenPath,
+			middleware.RateLimit(cfg.AvailabilityRateLimit, time.Minute),
+			handlers.OIDCToken(queries, oidcProvider),
+		)
+		router.GET(oidc.UserInfoPath, handlers.OIDCUserInfo(queries, oidcProvider))
+		router.POST(oidc.UserInfoPath, handlers.OIDCUserInfo(queries, oidcProvider))
+	}
+
+	// SCIM provisioning, for training programs' identity systems to manage
+	// student accounts in bulk
+	scimGroup := router.Group(scim.BasePath, middleware.RequireSCIMClient(queries))
+	{
+		scimGroup.GET("/ServiceProviderConfig", handlers.SCIMServiceProviderConfig())
+		scimGroup.GET("/ResourceTypes", handlers.SCIMResourceTypes())
+		scimGroup.GET("/Users", handlers.SCIMListUsers(queries))
+		scimGroup.POST("/Users", handlers.SCIMCreateUser(queries, cfg.BcryptCost))
+		scimGroup.GET("/Users/:id", handlers.SCIMGetUser(queries))
+		scimGroup.PUT("/Users/:id", handlers.SCIMReplaceUser(queries, activeCache, cfg.BcryptCost))
+		scimGroup.PATCH("/Users/:id", handlers.SCIMPatchUser(queries, activeCache, cfg.BcryptCost))
+		scimGroup.DELETE("/Users/:id", handlers.SCIMDeleteUser(queries, activeCache))
+		scimGroup.POST("/Bulk", handlers.SCIMBulk(queries, activeCache, cfg.BcryptCost))
+	}
+
+	// Public content (no authentication), for embeds and link previews
+	router.GET("/oembed", middleware.RateLimit(cfg.PublicRateLimit, time.Minute), handlers.OEmbed(queries, site))
+	publicGroup := router.Group("/public/v1")
+	publicGroup.Use(middleware.RateLimit(cfg.PublicRateLimit, time.Minute))
+	{
+		publicGroup.GET("/recipes", handlers.ListPublicRecipes(queries, site))
+		publicGroup.GET("/recipes/:id", handlers.GetPublicRecipe(queries, site))
+		publicGroup.GET("/brews/:id", handlers.GetPublicBrew(queries, site))
+		publicGroup.GET("/users", handlers.ListPublicProfiles(queries, site))
+		publicGroup.GET("/users/:username", handlers.GetPublicProfile(queries, site))
+	}
+
+	// Sitemaps of public content, proxied by the web app for crawlers
+	router.GET("/sitemap.xml", handlers.SitemapIndex(queries, site))
+	router.GET("/sitemaps/:file", handlers.Sitemap(queries, site))
+
+	// Atom feeds of public profiles and clubs, proxied by the web app like
+	// sitemaps
+	feedsGroup := router.Group("/feeds")
+	feedsGroup.Use(middleware.RateLimit(cfg.PublicRateLimit, time.Minute))
+	{
+		feedsGroup.GET("/users/:username", handlers.UserFeed(queries, site))
+		feedsGroup.GET("/clubs/:id", handlers.ClubFeed(queries, site))
+	}
+
+	// Objects linked by the app media signer, authenticated by their signed
+	// URL and proxied by the web app like calendar feeds
+	if store != nil && cfg.MediaURLSigner == media.SignerApp {
+		router.GET(media.AppPath+"/*key", handlers.ServeMedia(store, media.NewAppSigner(site, cfg.JWTSecret)))
+	}
+
+	// Calendar feeds, authenticated by their signed URL and proxied by the
+	// web app like sitemaps
+	router.GET("/calendar/:user_id/:file",
+		middleware.RateLimit(cfg.PublicRateLimit, time.Minute),
+		handlers.ServeCalendarFeed(queries, calendarSigner))
+
+	// Billing provider webhooks. Stripe's are authenticated by their
+	// signatures, which can't be replayed; app store notifications only prompt verifying a stored
+	// purchase again, and Google's carry a shared token.
+	router.POST("/webhooks/stripe", handlers.StripeWebhook(queries, planCache, replayGuard, cfg.StripeWebhookSecret))
+	router.POST("/webhooks/apple", handlers.AppleNotification(queries, planCache, storeVerifiers))
+	router.POST("/webhooks/google", handlers.GoogleNotification(queries, planCache, storeVerifiers, cfg.GooglePlayNotifyToken))
+
+	// Mail provider bounce and complaint webhooks, authenticated by a
+	// shared token, suppress the addresses they report
+	router.POST("/webhooks/mail/:provider", handlers.MailWebhook(queries, cfg.MailWebhookToken))
+
+	// Device integration routes, authenticated with scoped API keys
+	keyLimiter := apikeys.NewLimiter(cfg.APIKeyRateLimit, cfg.APIKeyBurst)
+	devicesGroup := router.Group("/devices/v1")
+	{
+		devicesGroup.POST("/tds-readings",
+			middleware.RequireAPIKey(queries, apikeys.ScopeTDSReadings, keyLimiter),
+			middleware.ReplayProtection(replayGuard, cfg.RequireRequestNonce),
+			handlers.IngestTDSReadings(queries))
+		devicesGroup.POST("/pour-curves",
+			middleware.RequireAPIKey(queries, apikeys.ScopePourCurves, keyLimiter),
+			middleware.StreamReplayProtection(replayGuard, cfg.RequireRequestNonce),
+			handlers.StreamPourCurve(queries))
+	}
+
+	// Smart-home trigger routes for Home Assistant and IFTTT, authenticated
+	// with API keys. Any key can check its own quota.
+	integrationsGroup := router.Group("/integrations/v1")
+	integrationsGroup.GET("/usage",
+		middleware.RequireAPIKey(queries, apikeys.AnyScope, keyLimiter),
+		handlers.GetAPIKeyUsage(keyLimiter))
+	integrationsGroup.Use(middleware.RequireAPIKey(queries, apikeys.ScopeAutomations, keyLimiter))
+	{
+		integrationsGroup.GET("/status", handlers.GetAutomationStatus(queries))
+		integrationsGroup.POST("/timer/start", handlers.StartAutomationTimer(queries))
+		integrationsGroup.POST("/timer/stop", handlers.StopAutomationTimer(queries))
+	}
+
+	// Admin tooling, registered on the public API or the internal listener
+	adminRoutes := func(admin *gin.RouterGroup) {
+		admin.GET("/challenges", handlers.AdminListChallenges(queries))
+		admin.POST("/challenges", handlers.AdminCreateChallenge(queries))
+		admin.PATCH("/challenges/:id", handlers.AdminUpdateChallenge(queries))
+		admin.DELETE("/challenges/:id", handlers.AdminDeleteChallenge(queries))
+		admin.PUT("/roasters/:id/verification", handlers.AdminVerifyRoaster(queries))
+		admin.DELETE("/roasters/:id/verification", handlers.AdminUnverifyRoaster(queries))
+		admin.GET("/stats", handlers.AdminGetStats(queries))
+		admin.GET("/tables", handlers.AdminGetTableStats(queries))
+		admin.GET("/slo", handlers.AdminGetSLO(sloRecorder))
+		admin.GET("/backups", handlers.AdminListBackups(queries))
+		admin.POST("/backups", handlers.AdminTriggerBackup(backups))
+		admin.GET("/backups/:id/download", handlers.AdminDownloadBackup(queries, mediaURLs))
+		admin.GET("/retention", handlers.AdminGetRetention(retentionJob))
+		admin.GET("/job-leases", handlers.AdminListJobLeases(queries))
+		admin.GET("/storage", handlers.AdminListStorageUsage(queries))
+		admin.GET("/photos/quarantined", handlers.AdminListQuarantinedPhotos(queries))
+		admin.DELETE("/photos/:photo_id", handlers.AdminDeleteQuarantinedPhoto(queries))
+		admin.GET("/email-suppressions", handlers.AdminListEmailSuppressions(queries))
+		admin.POST("/email-suppressions", handlers.AdminSuppressEmail(queries))
+		admin.DELETE("/email-suppressions/:email", handlers.AdminUnsuppressEmail(queries))
+		admin.POST("/mail/test", handlers.AdminSendTestEmail(mailer))
+		admin.GET("/waitlist", handlers.AdminListWaitlist(queries))
+		admin.POST("/waitlist/approve", handlers.AdminApproveWaitlist(queries))
+		admin.GET("/email-domains", handlers.AdminListEmailDomains(queries))
+		admin.PUT("/email-domains/:domain", handlers.AdminSetEmailDomain(queries))
+		admin.DELETE("/email-domains/:domain", handlers.AdminDeleteEmailDomain(queries))
+		admin.GET("/disposable-email-users", handlers.AdminListDisposableEmailUsers(queries))
+		admin.GET("/auth-events", handlers.AdminListAuthEvents(queries))
+		admin.GET("/oidc-clients", handlers.AdminListOIDCClients(queries))
+		admin.POST("/oidc-clients", handlers.AdminCreateOIDCClient(queries))
+		admin.DELETE("/oidc-clients/:id", handlers.AdminDeleteOIDCClient(queries))
+		admin.GET("/scim-clients", handlers.AdminListSCIMClients(queries))
+		admin.POST("/scim-clients", handlers.AdminCreateSCIMClient(queries))
+		admin.DELETE("/scim-clients/:id", handlers.AdminDeleteSCIMClient(queries))
+		admin.GET("/policies", handlers.AdminListPolicies(queries))
+		admin.POST("/policies", handlers.AdminPublishPolicy(queries, policyCache))
+	}
+
+	// Protected API routes (require authentication), metered against each
+	// user's plan
 	apiGroup := router.Group("/api")
-	apiGroup.Use(middleware.RequireAuth(authService))
+	apiGroup.Use(middleware.RequireAuth(authService, activeCache))
+	apiGroup.Use(middleware.Quota(planCache, plans.ResourceAPICalls))
 	{
 		// Get current user
 		apiGroup.GET("/me", func(c *gin.Context) {
 			userID := c.GetString("user_id")
 			username := c.GetString("username")
-			c.JSON(http.StatusOK, gin.H{
-				"success": true,
-				"data": gin.H{
-					"user_id":  userID,
-					"username": username,
-				},
+			respond.OK(c, gin.H{
+				"user_id":  userID,
+				"username": username,
 			})
 		})
+
+		// Versioned API
+		v1 := apiGroup.Group("/v1")
+		{
+			// Reading and accepting policies stays open to users with a
+			// new version to accept; every route registered after
+			// RequirePolicies is held until they accept it
+			v1.GET("/users/me/policies", handlers.ListMyPolicies(queries))
+			v1.POST("/users/me/policies/accept", handlers.AcceptPolicies(queries, policyCache))
+			v1.Use(middleware.RequirePolicies(policyCache))
+
+			v1.GET("/users/me", handlers.GetProfile(queries, planCache))
+			v1.PATCH("/users/me", handlers.UpdateProfile(queries, planCache))
+			v1.PUT("/users/me/username", handlers.ChangeUsername(queries, authService))
+			v1.GET("/users/me/preferences", handlers.GetPreferences(queries))
+			v1.PATCH("/users/me/preferences", handlers.UpdatePreferences(queries))
+			v1.GET("/users/me/plan", handlers.GetMyPlan(queries))
+			v1.POST("/billing/checkout", handlers.CreateCheckout(queries, stripe, site))
+			v1.POST("/billing/apple/receipts", handlers.SubmitAppleReceipt(queries, planCache, storeVerifiers))
+			v1.POST("/billing/google/purchases", handlers.SubmitGooglePurchase(queries, planCache, storeVerifiers))
+			v1.POST("/invites", handlers.CreateInvite(queries, site, cfg.InviteQuota, cfg.AdminUserIDs))
+			v1.GET("/invites", handlers.ListInvites(queries, site))
+			v1.DELETE("/invites/:code", handlers.RevokeInvite(queries))
+			v1.GET("/users/me/referrals", handlers.GetReferralStats(queries, cfg.InviteQuota, cfg.AdminUserIDs))
+			v1.GET("/users/me/auth-events", handlers.ListAuthEvents(queries))
+			v1.POST("/users/me/totp", handlers.SetupTOTP(queries))
+			v1.POST("/users/me/totp/confirm", handlers.ConfirmTOTP(queries))
+			v1.POST("/users/me/totp/disable", handlers.DisableTOTP(queries))
+			v1.GET("/users/me/trusted-devices", handlers.ListTrustedDevices(queries))
+			v1.DELETE("/users/me/trusted-devices", handlers.RevokeTrustedDevices(queries))
+			v1.DELETE("/users/me/trusted-devices/:id", handlers.RevokeTrustedDevice(queries))
+			v1.GET("/users/me/stats", handlers.GetMyStats(queries))
+			v1.GET("/users/me/stats/costs", handlers.GetMyCostStats(queries, float64(cfg.DefaultDoseGrams)))
+			v1.GET("/users/me/stats/costs/breakdown", handlers.GetMyCostBreakdown(queries, float64(cfg.DefaultDoseGrams)))
+			v1.GET("/users/me/stats/costs/export", middleware.RequireSupporter(planCache), handlers.ExportMyCostBreakdown(queries, float64(cfg.DefaultDoseGrams)))
+			v1.GET("/users/me/stats/origins", handlers.GetMyOriginStats(queries))
+			v1.GET("/users/me/stats/custom-fields/:name", handlers.GetMyCustomFieldStats(queries))
+			v1.GET("/users/me/recent", handlers.GetRecent(queries))
+			v1.GET("/users/me/recipe-invitations", handlers.ListMyRecipeInvitations(queries))
+			v1.GET("/users/me/shared", handlers.ListSharedWithMe(queries))
+			v1.GET("/users/me/badges", handlers.GetMyBadges(queries))
+			v1.GET("/users/me/tds-readings", handlers.ListMyTDSReadings(queries))
+			v1.GET("/users/me/calendar", handlers.GetCalendarFeed(queries, calendarSigner, site))
+			v1.POST("/users/me/calendar/reset", handlers.ResetCalendarFeed(queries, calendarSigner, site))
+			v1.GET("/users/:id/badges", handlers.GetUserBadges(queries))
+			v1.GET("/challenges", handlers.ListChallenges(queries))
+
+			v1.GET("/methods", handlers.ListMethods())
+			v1.GET("/origins", handlers.ListOrigins())
+			v1.GET("/autocomplete/roasters", handlers.AutocompleteRoasters(queries))
+			v1.GET("/autocomplete/origins", handlers.AutocompleteOrigins(queries))
+			v1.GET("/discover", handlers.Discover(queries))
+			v1.GET("/recommendations", handlers.GetRecommendations(queries))
+			v1.POST("/recommendations/not-interested", handlers.MarkNotInterested(queries))
+			v1.POST("/events", handlers.IngestEvents(queries, analyticsSink, cfg.AnalyticsSamplePercent))
+
+			v1.POST("/brews", handlers.CreateBrew(queries))
+			v1.POST("/brews/quick", handlers.QuickLogBrew(queries))
+			v1.POST("/brews/batch", handlers.BatchLogBrews(queries))
+			v1.GET("/brews", handlers.ListBrews(queries))
+			v1.GET("/brews/compare", handlers.CompareBrews(queries))
+			v1.GET("/brews/:id", handlers.GetBrew(queries))
+			v1.PATCH("/brews/:id", handlers.UpdateBrew(queries))
+			v1.POST("/brews/:id/duplicate", handlers.DuplicateBrew(queries))
+			v1.PUT("/brews/:id/flavors", handlers.SetBrewFlavors(queries))
+			v1.GET("/brews/:id/flavors", handlers.GetBrewFlavors(queries))
+			v1.POST("/brews/:id/timer/start", handlers.StartBrewTimer(queries))
+			v1.POST("/brews/:id/timer/stop", handlers.StopBrewTimer(queries))
+			v1.GET("/brews/:id/tds-readings", handlers.ListBrewTDSReadings(queries))
+			v1.GET("/brews/:id/pour-curve", handlers.GetBrewPourCurve(queries))
+			v1.DELETE("/brews/:id/pour-curve", handlers.DeleteBrewPourCurve(queries))
+			v1.POST("/brews/:id/shares", handlers.ShareBrew(queries))
+			v1.GET("/brews/:id/shares", handlers.ListBrewShares(queries))
+			v1.DELETE("/brews/:id/shares/:share_id", handlers.UnshareBrew(queries))
+			v1.POST("/brews/:id/photos", handlers.UploadBrewPhoto(queries, store, planCache, int64(cfg.PhotoMaxUploadMB)<<20))
+			v1.GET("/brews/:id/photos", handlers.ListBrewPhotos(queries, mediaURLs))
+			v1.GET("/brews/:id/photos/:photo_id", handlers.GetBrewPhoto(queries, mediaURLs))
+			v1.GET("/brews/:id/photos/:photo_id/image", handlers.GetBrewPhotoImage(queries, store, false))
+			v1.GET("/brews/:id/photos/:photo_id/thumbnail", handlers.GetBrewPhotoImage(queries, store, true))
+			v1.DELETE("/brews/:id/photos/:photo_id", handlers.DeleteBrewPhoto(queries))
+
+			v1.PATCH("/tds-readings/:id", handlers.UpdateTDSReading(queries))
+			v1.DELETE("/tds-readings/:id", handlers.DeleteTDSReading(queries))
+
+			v1.POST("/custom-fields", handlers.CreateCustomField(queries))
+			v1.GET("/custom-fields", handlers.ListCustomFields(queries))
+			v1.PATCH("/custom-fields/:id", handlers.UpdateCustomField(queries))
+			v1.DELETE("/custom-fields/:id", handlers.DeleteCustomField(queries))
+
+			v1.POST("/drafts", handlers.CreateDraft(queries))
+			v1.GET("/drafts", handlers.ListDrafts(queries))
+			v1.GET("/drafts/:id", handlers.GetDraft(queries))
+			v1.PATCH("/drafts/:id", handlers.AutosaveDraft(queries))
+			v1.DELETE("/drafts/:id", handlers.DeleteDraft(queries))
+			v1.POST("/drafts/:id/publish", handlers.PublishDraft(queries))
+
+			v1.GET("/sync/pull", handlers.SyncPull(queries))
+			v1.POST("/sync/push", handlers.SyncPush(queries))
+
+			v1.POST("/api-keys", handlers.CreateAPIKey(queries))
+			v1.GET("/api-keys", handlers.ListAPIKeys(queries))
+			v1.DELETE("/api-keys/:id", handlers.RevokeAPIKey(queries))
+			v1.GET("/api-keys/:id/usage", handlers.GetMyAPIKeyUsage(queries, keyLimiter))
+
+			v1.POST("/automations/webhooks", handlers.CreateAutomationWebhook(queries))
+			v1.GET("/automations/webhooks", handlers.ListAutomationWebhooks(queries))
+			v1.DELETE("/automations/webhooks/:id", handlers.DeleteAutomationWebhook(queries))
+			v1.POST("/automations/webhooks/:id/test", handlers.TestAutomationWebhook(queries))
+
+			v1.POST("/bean-bags", handlers.CreateBeanBag(queries))
+			v1.GET("/bean-bags", handlers.ListBeanBags(queries))
+			v1.GET("/bean-bags/:id", handlers.GetBeanBag(queries))
+			v1.PATCH("/bean-bags/:id", handlers.UpdateBeanBag(queries))
+			v1.GET("/bean-bags/:id/forecast", handlers.GetBeanBagForecast(queries, float64(cfg.DefaultDoseGrams)))
+			v1.PUT("/bean-bags/:id/flavors", handlers.SetBeanBagFlavors(queries))
+			v1.GET("/bean-bags/:id/flavors", handlers.GetBeanBagFlavors(queries))
+			v1.POST("/beans", handlers.CreateBean(queries))
+			v1.GET("/beans", handlers.ListBeans(queries))
+			v1.GET("/beans/:id", handlers.GetBean(queries))
+			v1.PATCH("/beans/:id", handlers.UpdateBean(queries))
+			v1.PUT("/beans/:id/recipes/:recipe_id", handlers.PublishBeanRecipe(queries))
+			v1.DELETE("/beans/:id/recipes/:recipe_id", handlers.UnpublishBeanRecipe(queries))
+			v1.POST("/roasters", handlers.CreateRoaster(queries))
+			v1.GET("/roasters", handlers.ListRoasters(queries))
+			v1.GET("/users/me/roaster", handlers.GetMyRoaster(queries))
+			v1.GET("/roasters/:id", handlers.GetRoaster(queries))
+			v1.PATCH("/roasters/:id", handlers.UpdateRoaster(queries))
+			v1.GET("/flavors", handlers.ListFlavors(queries))
+			v1.GET("/flavors/top", handlers.GetOriginFlavors(queries))
+			v1.POST("/recipes", handlers.CreateRecipe(queries))
+			v1.GET("/recipes/:id", handlers.GetRecipe(queries))
+			v1.PUT("/recipes/:id", handlers.ReviseRecipe(queries))
+			v1.PATCH("/recipes/:id", handlers.UpdateRecipe(queries))
+			v1.GET("/recipes/:id/revisions", handlers.ListRecipeRevisions(queries))
+			v1.GET("/recipes/:id/revisions/:revision", handlers.GetRecipeRevision(queries))
+			v1.POST("/recipes/:id/rollback", handlers.RollbackRecipe(queries))
+			v1.POST("/recipes/:id/fork", handlers.ForkRecipe(queries))
+			v1.GET("/recipes/:id/forks", handlers.GetRecipeForks(queries))
+			v1.POST("/recipes/:id/collaborators", handlers.InviteRecipeCollaborator(queries))
+			v1.GET("/recipes/:id/collaborators", handlers.ListRecipeCollaborators(queries))
+			v1.POST("/recipes/:id/collaborators/accept", handlers.AcceptRecipeInvitation(queries))
+			v1.PATCH("/recipes/:id/collaborators/:user_id", handlers.UpdateRecipeCollaboratorRole(queries))
+			v1.DELETE("/recipes/:id/collaborators/:user_id", handlers.RemoveRecipeCollaborator(queries))
+			v1.POST("/recipes/:id/shares", handlers.ShareRecipe(queries))
+			v1.GET("/recipes/:id/shares", handlers.ListRecipeShares(queries))
+			v1.DELETE("/recipes/:id/shares/:share_id", handlers.UnshareRecipe(queries))
+			v1.POST("/brew-events", handlers.CreateBrewEvent(queries))
+			v1.GET("/brew-events", handlers.ListBrewEvents(queries))
+			v1.GET("/brew-events/:id", handlers.GetBrewEvent(queries))
+			v1.DELETE("/brew-events/:id", handlers.DeleteBrewEvent(queries, hub))
+			v1.PUT("/brew-events/:id/rsvp", handlers.RSVPBrewEvent(queries, hub))
+			v1.GET("/brew-events/:id/rsvps", handlers.ListBrewEventRSVPs(queries))
+			v1.POST("/brew-events/:id/timer/start", handlers.StartBrewEventTimer(queries, hub))
+			v1.POST("/brew-events/:id/timer/stop", handlers.StopBrewEventTimer(queries, hub))
+			v1.GET("/brew-events/:id/stream", handlers.StreamBrewEvent(queries, hub, presence, drainer))
+			v1.POST("/clubs", handlers.CreateClub(queries))
+			v1.GET("/clubs", handlers.ListClubs(queries))
+			v1.GET("/users/me/clubs", handlers.ListMyClubs(queries))
+			v1.GET("/clubs/:id", handlers.GetClub(queries))
+			v1.PATCH("/clubs/:id", handlers.UpdateClub(queries))
+			v1.DELETE("/clubs/:id", handlers.DeleteClub(queries))
+			v1.POST("/clubs/:id/join", handlers.JoinClub(queries))
+			v1.GET("/clubs/:id/members", handlers.ListClubMembers(queries))
+			v1.PUT("/clubs/:id/members/:user_id", handlers.UpdateClubMemberRole(queries))
+			v1.DELETE("/clubs/:id/members/:user_id", handlers.RemoveClubMember(queries))
+			v1.POST("/clubs/:id/invites", handlers.CreateClubInvite(queries))
+			v1.GET("/clubs/:id/invites", handlers.ListClubInvites(queries))
+			v1.DELETE("/clubs/:id/invites/:invite_id", handlers.RevokeClubInvite(queries))
+			v1.GET("/club-invites/:token", handlers.GetClubInvite(queries))
+			v1.POST("/club-invites/:token/accept", handlers.AcceptClubInvite(queries))
+			v1.POST("/clubs/:id/feed", handlers.ShareToClub(queries))
+			v1.GET("/clubs/:id/feed", handlers.ListClubFeed(queries))
+			v1.DELETE("/clubs/:id/feed/:share_id", handlers.DeleteClubShare(queries))
+			v1.POST("/cupping-sessions", handlers.CreateCuppingSession(queries))
+			v1.GET("/cupping-sessions", handlers.ListCuppingSessions(queries))
+			v1.GET("/cupping-sessions/:id", handlers.GetCuppingSession(queries))
+			v1.DELETE("/cupping-sessions/:id", handlers.DeleteCuppingSession(queries))
+			v1.POST("/cupping-sessions/:id/participants", handlers.InviteCuppingParticipant(queries))
+			v1.DELETE("/cupping-sessions/:id/participants/:user_id", handlers.RemoveCuppingParticipant(queries))
+			v1.PUT("/cupping-sessions/:id/samples/:label/score", handlers.ScoreCuppingSample(queries))
+			v1.GET("/cupping-sessions/:id/scores", handlers.ListMyCuppingScores(queries))
+			v1.POST("/cupping-sessions/:id/reveal", handlers.RevealCuppingSession(queries))
+			v1.GET("/cupping-sessions/:id/results", handlers.GetCuppingResults(queries))
+			v1.POST("/orgs", handlers.CreateOrganization(queries))
+			v1.GET("/users/me/orgs", handlers.ListMyOrganizations(queries))
+
+			// Organization routes act in the :org_id tenant, once membership
+			// is checked
+			org := v1.Group("/orgs/:org_id", middleware.RequireOrganization(queries))
+			org.GET("", handlers.GetOrganization(queries))
+			org.PATCH("", handlers.UpdateOrganization(queries))
+			org.DELETE("", handlers.DeleteOrganization(queries))
+			org.GET("/members", handlers.ListOrganizationMembers(queries))
+			org.POST("/members", handlers.AddOrganizationMember(queries))
+			org.PUT("/members/:user_id", handlers.UpdateOrganizationMemberRole(queries))
+			org.DELETE("/members/:user_id", handlers.RemoveOrganizationMember(queries))
+			org.POST("/brews", handlers.CreateOrganizationBrew(queries))
+			org.GET("/brews", handlers.ListOrganizationBrews(queries))
+			org.GET("/brews/:id", handlers.GetOrganizationBrew(queries))
+
+			// Admin tooling, unless it's served on the internal listener
+			if internalRouter == nil {
+				adminRoutes(v1.Group("/admin", middleware.RequireAdmin(cfg.AdminUserIDs)))
+			}
+		}
 	}
 
-	logger.Info("Starting server", "port", cfg.Port)
-	if err := http.ListenAndServe(":"+cfg.Port, router); err != nil {
-		logger.Error("Failed to start server", "error", err)
-		os.Exit(1)
+	// Serve the embedded web app for paths no route matched, so small
+	// deployments ship one binary: its files precompressed and cached by
+	// content hash, then its pages. It needs a build with -tags embedweb.
+	if cfg.WebApp {
+		files, err := webapp.FS()
+		if err != nil {
+			logger.Error("WEB_APP is set but the web app can't be served", "error", err)
+			os.Exit(1)
+		}
+		router.NoRoute(middleware.Static(files), handlers.WebApp(files))
 	}
+
+	// Admin tooling on the internal listener authenticates as on the API,
+	// but isn't metered
+	if internalRouter != nil {
+		adminRoutes(internalRouter.Group("/api/v1/admin",
+			middleware.RequireAuth(authService, activeCache),
+			middleware.RequireAdmin(cfg.AdminUserIDs),
+		))
+	}
+
+	a.router = router
+	a.internalRouter = internalRouter
+	a.drainer = drainer
+	return a
 }
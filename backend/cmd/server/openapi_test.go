@@ -0,0 +1,238 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// specPath is the OpenAPI document the router is checked against
+const specPath = "../../api/openapi.json"
+
+// document is the part of the OpenAPI document the tests read
+type document struct {
+	Paths      map[string]map[string]*operation `json:"paths"`
+	Components struct {
+		Schemas map[string]*schema `json:"schemas"`
+	} `json:"components"`
+}
+
+type operation struct {
+	OperationID string                `json:"operationId"`
+	Parameters  []parameter           `json:"parameters"`
+	RequestBody *content              `json:"requestBody"`
+	Responses   map[string]*content   `json:"responses"`
+	Requires    []string              `json:"x-brewd-requires"`
+	Security    []map[string][]string `json:"security"`
+}
+
+type parameter struct {
+	Name     string `json:"name"`
+	In       string `json:"in"`
+	Required bool   `json:"required"`
+}
+
+// content is a request body or response: its schema by media type
+type content struct {
+	Content map[string]struct {
+		Schema *schema `json:"schema"`
+	} `json:"content"`
+}
+
+// schema is the subset of an OpenAPI 3.0 schema cmd/openapi writes
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Nullable             bool               `json:"nullable"`
+	Enum                 []any              `json:"enum"`
+	Items                *schema            `json:"items"`
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *schema            `json:"additionalProperties"`
+	AllOf                []*schema          `json:"allOf"`
+	OneOf                []*schema          `json:"oneOf"`
+}
+
+// loadDocument reads the OpenAPI document
+func loadDocument(t *testing.T) *document {
+	t.Helper()
+	raw, err := os.ReadFile(specPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc document
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("parse %s: %v", specPath, err)
+	}
+	return &doc
+}
+
+// operations returns the document's operations by "METHOD /path"
+func (doc *document) operations() map[string]*operation {
+	ops := make(map[string]*operation)
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			ops[strings.ToUpper(method)+" "+path] = op
+		}
+	}
+	return ops
+}
+
+// success returns the documented 2xx statuses of op
+func (op *operation) success() []string {
+	var statuses []string
+	for status := range op.Responses {
+		if strings.HasPrefix(status, "2") {
+			statuses = append(statuses, status)
+		}
+	}
+	sort.Strings(statuses)
+	return statuses
+}
+
+// requiresCredentials reports whether op documents security without an
+// anonymous alternative
+func (op *operation) requiresCredentials() bool {
+	if len(op.Security) == 0 {
+		return false
+	}
+	for _, requirement := range op.Security {
+		if len(requirement) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+var pathParam = regexp.MustCompile(`\{[^}]*\}`)
+
+// pathShape is path with its parameters unnamed, so paths naming them
+// differently compare equal
+func pathShape(path string) string {
+	return pathParam.ReplaceAllString(path, "{}")
+}
+
+// validator checks decoded JSON against schemas, resolving references to
+// the document's components. Values ignore accepts are taken as matching
+// any schema.
+type validator struct {
+	components map[string]*schema
+	ignore     func(value any) bool
+}
+
+// validate returns the ways value, at path, doesn't match sch
+func (v *validator) validate(sch *schema, value any, path string) []string {
+	if sch == nil || (v.ignore != nil && v.ignore(value)) {
+		return nil
+	}
+	if sch.Ref != "" {
+		target, ok := v.components[strings.TrimPrefix(sch.Ref, "#/components/schemas/")]
+		if !ok {
+			return []string{fmt.Sprintf("%s: unresolved reference %s", path, sch.Ref)}
+		}
+		return v.validate(target, value, path)
+	}
+
+	if value == nil && sch.Nullable {
+		return nil
+	}
+
+	var problems []string
+	for _, part := range sch.AllOf {
+		problems = append(problems, v.validate(part, value, path)...)
+	}
+	if len(sch.OneOf) > 0 {
+		matched := 0
+		for _, option := range sch.OneOf {
+			if len(v.validate(option, value, path)) == 0 {
+				matched++
+			}
+		}
+		if matched == 0 {
+			problems = append(problems, fmt.Sprintf("%s: matches none of %d schemas", path, len(sch.OneOf)))
+		}
+	}
+
+	if value == nil {
+		if sch.Type != "" {
+			problems = append(problems, fmt.Sprintf("%s: null where %s is expected", path, sch.Type))
+		}
+		return problems
+	}
+	if len(sch.Enum) > 0 && !contains(sch.Enum, value) {
+		problems = append(problems, fmt.Sprintf("%s: %v isn't one of %v", path, value, sch.Enum))
+	}
+
+	switch sch.Type {
+	case "":
+		// Any value
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return append(problems, mismatch(path, sch.Type, value))
+		}
+		for _, name := range sch.Required {
+			if _, ok := obj[name]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: missing required %q", path, name))
+			}
+		}
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			prop, ok := sch.Properties[name]
+			if !ok {
+				if sch.AdditionalProperties == nil && len(sch.Properties) > 0 {
+					problems = append(problems, fmt.Sprintf("%s: undocumented property %q", path, name))
+					continue
+				}
+				prop = sch.AdditionalProperties
+			}
+			problems = append(problems, v.validate(prop, obj[name], path+"."+name)...)
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return append(problems, mismatch(path, sch.Type, value))
+		}
+		for i, item := range items {
+			problems = append(problems, v.validate(sch.Items, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			problems = append(problems, mismatch(path, sch.Type, value))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			problems = append(problems, mismatch(path, sch.Type, value))
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			problems = append(problems, mismatch(path, sch.Type, value))
+		}
+	case "integer":
+		if n, ok := value.(float64); !ok || n != float64(int64(n)) {
+			problems = append(problems, mismatch(path, sch.Type, value))
+		}
+	}
+	return problems
+}
+
+func mismatch(path, want string, value any) string {
+	return fmt.Sprintf("%s: %T where %s is expected", path, value, want)
+}
+
+func contains(values []any, value any) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,324 @@
+// Command webassets prepares an exported web app for embedding: it names
+// the app's assets by content hash, so the server can cache them for good,
+// and writes brotli and gzip variants beside its text files, so the server
+// never compresses at request time. build.sh runs it over
+// internal/webapp/dist; the brotli CLI must be on PATH.
+//
+//	go run ./cmd/webassets internal/webapp/dist
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"brewd/internal/webapp"
+)
+
+// hashedDirs are the directories of the export holding assets that pages
+// and bundles refer to by path. Files elsewhere, like favicon.ico, are
+// fetched by well-known names and keep them.
+var hashedDirs = []string{"assets/", "_expo/"}
+
+// referrers are the extensions of files that may refer to assets by path
+var referrers = map[string]bool{".html": true, ".js": true, ".css": true, ".json": true}
+
+// compressible are the extensions of files worth precompressing; images and
+// fonts are compressed already
+var compressible = map[string]bool{
+	".html": true, ".js": true, ".mjs": true, ".css": true, ".json": true, ".map": true,
+	".svg": true, ".txt": true, ".xml": true, ".webmanifest": true, ".wasm": true, ".ico": true,
+}
+
+// minCompressSize is the size below which a file isn't worth compressing
+const minCompressSize = 1024
+
+// hashLen is the number of hex digits of a content hash in a file name
+const hashLen = 16
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: webassets <dist dir>")
+		os.Exit(2)
+	}
+	if err := run(os.Args[1]); err != nil {
+		fmt.Fprintln(os.Stderr, "webassets:", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir string) error {
+	brotli, err := exec.LookPath("brotli")
+	if err != nil {
+		return fmt.Errorf("brotli CLI not found: %w", err)
+	}
+
+	files, err := listFiles(dir)
+	if err != nil {
+		return err
+	}
+	renamed, err := hashNames(dir, files)
+	if err != nil {
+		return err
+	}
+	if err := rewriteReferences(dir, renamed); err != nil {
+		return err
+	}
+
+	// List again, as hashing renamed files
+	if files, err = listFiles(dir); err != nil {
+		return err
+	}
+	compressed := 0
+	for _, name := range files {
+		if !compressible[path.Ext(name)] {
+			continue
+		}
+		n, err := precompress(filepath.Join(dir, filepath.FromSlash(name)), brotli)
+		if err != nil {
+			return fmt.Errorf("compress %s: %w", name, err)
+		}
+		compressed += n
+	}
+
+	fmt.Printf("webassets: hashed %d assets, wrote %d compressed variants\n", len(renamed), compressed)
+	return nil
+}
+
+// listFiles returns the slash-separated paths of the files under dir, other
+// than precompressed variants, in order
+func listFiles(dir string) ([]string, error) {
+	var files []string
+	err := fs.WalkDir(os.DirFS(dir), ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if ext := path.Ext(name); ext == ".br" || ext == ".gz" {
+			return nil
+		}
+		files = append(files, name)
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// hashNames renames the assets under hashedDirs not yet named by content
+// hash to name.<hash>.ext, returning the new name of each renamed file. An
+// asset's references to the others are rewritten before it is hashed, so
+// assets are renamed leaves first, and one whose dependencies change gets a
+// new name too.
+func hashNames(dir string, files []string) (map[string]string, error) {
+	var assets []string
+	for _, name := range files {
+		if hashable(name) && !webapp.Hashed(name) {
+			assets = append(assets, name)
+		}
+	}
+	order, err := leavesFirst(dir, assets)
+	if err != nil {
+		return nil, err
+	}
+
+	renamed := make(map[string]string)
+	for _, name := range order {
+		src := filepath.Join(dir, filepath.FromSlash(name))
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return nil, err
+		}
+		if referrers[path.Ext(name)] && len(renamed) > 0 {
+			data = []byte(newReplacer(renamed).Replace(string(data)))
+		}
+		sum := sha256.Sum256(data)
+		ext := path.Ext(name)
+		hashed := strings.TrimSuffix(name, ext) + "." + hex.EncodeToString(sum[:])[:hashLen] + ext
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(hashed)), data, 0o644); err != nil {
+			return nil, err
+		}
+		if err := os.Remove(src); err != nil {
+			return nil, err
+		}
+		renamed[name] = hashed
+	}
+	return renamed, nil
+}
+
+// leavesFirst orders assets so each comes after the assets it refers to.
+// Assets referring to each other in a cycle can't all be named by content,
+// so they're an error.
+func leavesFirst(dir string, assets []string) ([]string, error) {
+	deps := make(map[string][]string, len(assets))
+	for _, name := range assets {
+		if !referrers[path.Ext(name)] {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			return nil, err
+		}
+		for _, dep := range assets {
+			if dep != name && bytes.Contains(data, []byte("/"+dep)) {
+				deps[name] = append(deps[name], dep)
+			}
+		}
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(assets))
+	order := make([]string, 0, len(assets))
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("assets refer to each other in a cycle through %s", name)
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		for _, dep := range deps[name] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		order = append(order, name)
+		return nil
+	}
+	for _, name := range assets {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// hashable reports whether name is in one of hashedDirs. Source maps are
+// found by a relative comment in their bundle, so they keep their names.
+func hashable(name string) bool {
+	if path.Ext(name) == ".map" {
+		return false
+	}
+	for _, prefix := range hashedDirs {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// newReplacer replaces the absolute paths of renamed assets with their new
+// ones
+func newReplacer(renamed map[string]string) *strings.Replacer {
+	// Longer paths first, so one that extends another isn't cut short
+	olds := make([]string, 0, len(renamed))
+	for old := range renamed {
+		olds = append(olds, old)
+	}
+	sort.Slice(olds, func(i, j int) bool { return len(olds[i]) > len(olds[j]) })
+	pairs := make([]string, 0, 2*len(olds))
+	for _, old := range olds {
+		pairs = append(pairs, "/"+old, "/"+renamed[old])
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// rewriteReferences replaces the absolute paths of renamed assets with
+// their new ones in the other files that may refer to them, such as pages.
+// Renamed assets were rewritten before they were hashed, so are left alone.
+func rewriteReferences(dir string, renamed map[string]string) error {
+	if len(renamed) == 0 {
+		return nil
+	}
+	replacer := newReplacer(renamed)
+	hashed := make(map[string]bool, len(renamed))
+	for _, name := range renamed {
+		hashed[name] = true
+	}
+
+	files, err := listFiles(dir)
+	if err != nil {
+		return err
+	}
+	for _, name := range files {
+		if !referrers[path.Ext(name)] || hashed[name] {
+			continue
+		}
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		rewritten := replacer.Replace(string(data))
+		if rewritten == string(data) {
+			continue
+		}
+		if err := os.WriteFile(file, []byte(rewritten), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// precompress writes the brotli and gzip variants of file beside it,
+// keeping each only if it is smaller, and returns how many it kept
+func precompress(file, brotli string) (int, error) {
+	data, err := os.ReadFile(file)
+	if err != nil || len(data) < minCompressSize {
+		return 0, err
+	}
+
+	kept := 0
+	cmd := exec.Command(brotli, "--best", "--force", "--keep", "--output="+file+".br", file)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return kept, fmt.Errorf("brotli: %w: %s", err, bytes.TrimSpace(out))
+	}
+	if ok, err := keepIfSmaller(file+".br", len(data)); err != nil {
+		return kept, err
+	} else if ok {
+		kept++
+	}
+
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return kept, err
+	}
+	if _, err := zw.Write(data); err != nil {
+		return kept, err
+	}
+	if err := zw.Close(); err != nil {
+		return kept, err
+	}
+	if buf.Len() < len(data) {
+		if err := os.WriteFile(file+".gz", buf.Bytes(), 0o644); err != nil {
+			return kept, err
+		}
+		kept++
+	}
+	return kept, nil
+}
+
+// keepIfSmaller removes the variant at file unless it is smaller than size
+func keepIfSmaller(file string, size int) (bool, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return false, err
+	}
+	if info.Size() < int64(size) {
+		return true, nil
+	}
+	return false, os.Remove(file)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeDist writes files, by slash-separated path, under a new directory
+func writeDist(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// hashDist hashes and rewrites dir as run does, without compressing
+func hashDist(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files, err := listFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	renamed, err := hashNames(dir, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rewriteReferences(dir, renamed); err != nil {
+		t.Fatal(err)
+	}
+	return renamed
+}
+
+// readDist returns the content of name under dir
+func readDist(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestHashNamesRewritesLeavesFirst(t *testing.T) {
+	files := map[string]string{
+		"index.html":               `<link href="/assets/app.css"><script src="/_expo/entry.js"></script>`,
+		"_expo/entry.js":           `import("/_expo/chunk.js")`,
+		"_expo/chunk.js":           `fetch("/assets/data.json")`,
+		"assets/app.css":           `@font-face { src: url(/assets/font.woff2) }`,
+		"assets/font.woff2":        "font v1",
+		"assets/data.json":         `{}`,
+		"assets/logo.abc12345.png": "logo",
+	}
+	dir := writeDist(t, files)
+	renamed := hashDist(t, dir)
+
+	if len(renamed) != 5 {
+		t.Fatalf("renamed %d assets, want 5: %v", len(renamed), renamed)
+	}
+	if _, ok := renamed["assets/logo.abc12345.png"]; ok {
+		t.Error("renamed an asset already named by hash")
+	}
+	// Each file refers to the hashed names of the assets it uses
+	for referrer, ref := range map[string]string{
+		"index.html":              "assets/app.css",
+		renamed["_expo/entry.js"]: "_expo/chunk.js",
+		renamed["_expo/chunk.js"]: "assets/data.json",
+		renamed["assets/app.css"]: "assets/font.woff2",
+	} {
+		if content := readDist(t, dir, referrer); !strings.Contains(content, "/"+renamed[ref]) {
+			t.Errorf("%s = %q, want a reference to %s", referrer, content, renamed[ref])
+		}
+	}
+
+	// A changed leaf renames everything that refers to it, directly or not
+	files["assets/font.woff2"] = "font v2"
+	changed := hashDist(t, writeDist(t, files))
+	for _, name := range []string{"assets/font.woff2", "assets/app.css"} {
+		if changed[name] == renamed[name] {
+			t.Errorf("%s kept its name %s after the font changed", name, renamed[name])
+		}
+	}
+	for _, name := range []string{"_expo/entry.js", "_expo/chunk.js", "assets/data.json"} {
+		if changed[name] != renamed[name] {
+			t.Errorf("%s was renamed %s, want %s as it doesn't use the font", name, changed[name], renamed[name])
+		}
+	}
+}
+
+func TestHashNamesRejectsCycle(t *testing.T) {
+	dir := writeDist(t, map[string]string{
+		"_expo/a.js": `import("/_expo/b.js")`,
+		"_expo/b.js": `import("/_expo/a.js")`,
+	})
+	files, err := listFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hashNames(dir, files); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("hashNames = %v, want a cycle error", err)
+	}
+}
@@ -0,0 +1,10 @@
+-- ============================================================================
+-- ROLLBACK - IDENTITY POLICY
+-- ============================================================================
+-- Migration: 000002_identity_policy
+-- Created: 2026-10-17
+
+-- Usernames and emails renamed to resolve case-only duplicates stay renamed
+
+DROP INDEX IF EXISTS idx_user_email_lower;
+DROP INDEX IF EXISTS idx_user_username_lower;
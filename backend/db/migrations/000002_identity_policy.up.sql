@@ -0,0 +1,31 @@
+-- ============================================================================
+-- IDENTITY POLICY
+-- ============================================================================
+-- Enforces case-insensitive uniqueness for usernames and emails
+-- Migration: 000002_identity_policy
+-- Created: 2026-10-17
+
+-- Accounts differing from an older one only by case can't coexist under
+-- these indexes. The oldest account keeps its username and email; newer
+-- ones have the end of their ULID appended to their username, and their
+-- email moved under the reserved .invalid domain until support restores it
+UPDATE "user" u
+SET username = LEFT(u.username, 23) || '_' || LOWER(RIGHT(u.id, 6))
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY LOWER(username) ORDER BY created_at, id) AS n
+    FROM "user"
+) ranked
+WHERE ranked.id = u.id
+  AND ranked.n > 1;
+
+UPDATE "user" u
+SET email = LOWER(u.id) || '@duplicate.invalid'
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY LOWER(email) ORDER BY created_at, id) AS n
+    FROM "user"
+) ranked
+WHERE ranked.id = u.id
+  AND ranked.n > 1;
+
+CREATE UNIQUE INDEX idx_user_username_lower ON "user"(LOWER(username));
+CREATE UNIQUE INDEX idx_user_email_lower ON "user"(LOWER(email));
@@ -0,0 +1,16 @@
+-- ============================================================================
+-- ROLLBACK - BREW PARAMETERS AND UNIT PREFERENCES
+-- ============================================================================
+-- Migration: 000003_brew_parameters_and_units
+-- Created: 2026-10-17
+
+ALTER TABLE "user"
+    DROP COLUMN IF EXISTS temperature_unit,
+    DROP COLUMN IF EXISTS weight_unit;
+
+ALTER TABLE brew
+    DROP COLUMN IF EXISTS brew_time_seconds,
+    DROP COLUMN IF EXISTS grind_setting,
+    DROP COLUMN IF EXISTS water_temp_c,
+    DROP COLUMN IF EXISTS water_grams,
+    DROP COLUMN IF EXISTS dose_grams;
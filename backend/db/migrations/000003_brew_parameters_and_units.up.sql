@@ -0,0 +1,18 @@
+-- ============================================================================
+-- BREW PARAMETERS AND UNIT PREFERENCES
+-- ============================================================================
+-- Adds recipe parameters to brews (stored canonically in grams / Celsius)
+-- and per-user display unit preferences
+-- Migration: 000003_brew_parameters_and_units
+-- Created: 2026-10-17
+
+ALTER TABLE brew
+    ADD COLUMN dose_grams DOUBLE PRECISION CHECK (dose_grams IS NULL OR dose_grams > 0),
+    ADD COLUMN water_grams DOUBLE PRECISION CHECK (water_grams IS NULL OR water_grams > 0),
+    ADD COLUMN water_temp_c DOUBLE PRECISION CHECK (water_temp_c IS NULL OR (water_temp_c >= 0 AND water_temp_c <= 100)),
+    ADD COLUMN grind_setting VARCHAR(50),
+    ADD COLUMN brew_time_seconds INTEGER CHECK (brew_time_seconds IS NULL OR brew_time_seconds >= 0);
+
+ALTER TABLE "user"
+    ADD COLUMN weight_unit VARCHAR(10) NOT NULL DEFAULT 'g' CHECK (weight_unit IN ('g', 'oz')),
+    ADD COLUMN temperature_unit VARCHAR(10) NOT NULL DEFAULT 'c' CHECK (temperature_unit IN ('c', 'f'));
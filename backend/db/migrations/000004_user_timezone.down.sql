@@ -0,0 +1,7 @@
+-- ============================================================================
+-- ROLLBACK - USER TIMEZONE
+-- ============================================================================
+-- Migration: 000004_user_timezone
+-- Created: 2026-10-17
+
+ALTER TABLE "user" DROP COLUMN IF EXISTS timezone;
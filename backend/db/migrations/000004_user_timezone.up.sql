@@ -0,0 +1,16 @@
+-- ============================================================================
+-- USER TIMEZONE
+-- ============================================================================
+-- Adds a per-user IANA timezone so stats and streaks use local day/week
+-- boundaries instead of UTC. Existing users are backfilled to 'UTC', which
+-- keeps their current boundaries until they set a timezone.
+-- Migration: 000004_user_timezone
+-- Created: 2026-10-17
+
+ALTER TABLE "user" ADD COLUMN timezone VARCHAR(64);
+
+UPDATE "user" SET timezone = 'UTC' WHERE timezone IS NULL;
+
+ALTER TABLE "user"
+    ALTER COLUMN timezone SET DEFAULT 'UTC',
+    ALTER COLUMN timezone SET NOT NULL;
@@ -0,0 +1,22 @@
+-- ============================================================================
+-- ROLLBACK - BREW TIMER SESSIONS
+-- ============================================================================
+-- Migration: 000005_brew_timer_sessions
+-- Created: 2026-10-17
+
+DELETE FROM notification WHERE type = 'brew_reminder' OR reference_type = 'brew';
+
+ALTER TABLE notification
+    DROP CONSTRAINT notification_reference_type_check,
+    ADD CONSTRAINT notification_reference_type_check CHECK (reference_type IN ('post', 'comment', 'friendship')),
+    DROP CONSTRAINT notification_type_check,
+    ADD CONSTRAINT notification_type_check CHECK (type IN ('like', 'comment', 'friend_request', 'tag', 'follow'));
+
+DROP INDEX IF EXISTS idx_brew_remind_at;
+
+ALTER TABLE brew
+    DROP CONSTRAINT IF EXISTS brew_timer_check,
+    DROP COLUMN IF EXISTS reminder_sent_at,
+    DROP COLUMN IF EXISTS remind_at,
+    DROP COLUMN IF EXISTS ended_at,
+    DROP COLUMN IF EXISTS started_at;
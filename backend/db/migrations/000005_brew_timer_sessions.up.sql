@@ -0,0 +1,23 @@
+-- ============================================================================
+-- BREW TIMER SESSIONS
+-- ============================================================================
+-- Adds start/end timestamps and steeping reminders to brews so multi-hour
+-- and days-long brews (cold brew) can be timed, plus the brew_reminder
+-- notification type
+-- Migration: 000005_brew_timer_sessions
+-- Created: 2026-10-17
+
+ALTER TABLE brew
+    ADD COLUMN started_at TIMESTAMPTZ,
+    ADD COLUMN ended_at TIMESTAMPTZ,
+    ADD COLUMN remind_at TIMESTAMPTZ,
+    ADD COLUMN reminder_sent_at TIMESTAMPTZ,
+    ADD CONSTRAINT brew_timer_check CHECK (ended_at IS NULL OR (started_at IS NOT NULL AND ended_at >= started_at));
+
+CREATE INDEX idx_brew_remind_at ON brew(remind_at) WHERE reminder_sent_at IS NULL;
+
+ALTER TABLE notification
+    DROP CONSTRAINT notification_type_check,
+    ADD CONSTRAINT notification_type_check CHECK (type IN ('like', 'comment', 'friend_request', 'tag', 'follow', 'brew_reminder')),
+    DROP CONSTRAINT notification_reference_type_check,
+    ADD CONSTRAINT notification_reference_type_check CHECK (reference_type IN ('post', 'comment', 'friendship', 'brew'));
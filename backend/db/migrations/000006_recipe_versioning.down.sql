@@ -0,0 +1,17 @@
+-- ============================================================================
+-- ROLLBACK - RECIPE VERSIONING
+-- ============================================================================
+-- Migration: 000006_recipe_versioning
+-- Created: 2026-10-17
+
+DROP INDEX IF EXISTS idx_brew_recipe;
+
+ALTER TABLE brew
+    DROP CONSTRAINT IF EXISTS brew_recipe_check,
+    DROP CONSTRAINT IF EXISTS brew_recipe_revision_fkey,
+    DROP COLUMN IF EXISTS recipe_revision,
+    DROP COLUMN IF EXISTS recipe_id;
+
+DROP TRIGGER IF EXISTS update_recipe_updated_at ON recipe;
+DROP TABLE IF EXISTS recipe_revision;
+DROP TABLE IF EXISTS recipe;
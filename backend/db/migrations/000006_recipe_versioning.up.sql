@@ -0,0 +1,56 @@
+-- ============================================================================
+-- RECIPE VERSIONING
+-- ============================================================================
+-- Adds versioned recipes: every edit or rollback appends an immutable
+-- revision, and brews pin the exact revision they were made with
+-- Migration: 000006_recipe_versioning
+-- Created: 2026-10-17
+
+CREATE TABLE recipe (
+    id TEXT PRIMARY KEY,
+    owner_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    brew_method VARCHAR(100) CHECK (
+        brew_method IS NULL OR
+        brew_method IN ('espresso', 'pour_over', 'french_press', 'aeropress',
+                       'cold_brew', 'drip', 'moka_pot', 'siphon', 'chemex',
+                       'v60', 'turkish', 'percolator', 'other')
+    ),
+    is_public BOOLEAN NOT NULL DEFAULT true,
+    current_revision INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_recipe_owner_id ON recipe(owner_id);
+CREATE INDEX idx_recipe_is_public ON recipe(is_public);
+
+CREATE TABLE recipe_revision (
+    recipe_id TEXT NOT NULL REFERENCES recipe(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL CHECK (revision > 0),
+    dose_grams DOUBLE PRECISION CHECK (dose_grams IS NULL OR dose_grams > 0),
+    water_grams DOUBLE PRECISION CHECK (water_grams IS NULL OR water_grams > 0),
+    water_temp_c DOUBLE PRECISION CHECK (water_temp_c IS NULL OR (water_temp_c >= 0 AND water_temp_c <= 100)),
+    grind_setting VARCHAR(50),
+    brew_time_seconds INTEGER CHECK (brew_time_seconds IS NULL OR brew_time_seconds >= 0),
+    instructions TEXT,
+    changelog TEXT,
+    rolled_back_from INTEGER,
+    created_by TEXT REFERENCES "user"(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (recipe_id, revision)
+);
+
+CREATE TRIGGER update_recipe_updated_at
+BEFORE UPDATE ON recipe
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE brew
+    ADD COLUMN recipe_id TEXT,
+    ADD COLUMN recipe_revision INTEGER,
+    ADD CONSTRAINT brew_recipe_revision_fkey FOREIGN KEY (recipe_id, recipe_revision)
+        REFERENCES recipe_revision(recipe_id, revision) ON DELETE SET NULL,
+    ADD CONSTRAINT brew_recipe_check CHECK ((recipe_id IS NULL) = (recipe_revision IS NULL));
+
+CREATE INDEX idx_brew_recipe ON brew(recipe_id, recipe_revision);
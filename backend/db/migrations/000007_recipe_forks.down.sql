@@ -0,0 +1,11 @@
+-- ============================================================================
+-- ROLLBACK - RECIPE FORKS
+-- ============================================================================
+-- Migration: 000007_recipe_forks
+-- Created: 2026-10-17
+
+DROP INDEX IF EXISTS idx_recipe_forked_from;
+
+ALTER TABLE recipe
+    DROP COLUMN IF EXISTS forked_from_revision,
+    DROP COLUMN IF EXISTS forked_from_id;
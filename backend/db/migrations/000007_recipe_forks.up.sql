@@ -0,0 +1,13 @@
+-- ============================================================================
+-- RECIPE FORKS
+-- ============================================================================
+-- Records which recipe (and revision) a recipe was forked from, forming an
+-- attribution graph that can be walked in either direction
+-- Migration: 000007_recipe_forks
+-- Created: 2026-10-17
+
+ALTER TABLE recipe
+    ADD COLUMN forked_from_id TEXT REFERENCES recipe(id) ON DELETE SET NULL,
+    ADD COLUMN forked_from_revision INTEGER;
+
+CREATE INDEX idx_recipe_forked_from ON recipe(forked_from_id);
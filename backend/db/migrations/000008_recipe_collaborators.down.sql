@@ -0,0 +1,7 @@
+-- ============================================================================
+-- ROLLBACK - RECIPE COLLABORATORS
+-- ============================================================================
+-- Migration: 000008_recipe_collaborators
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS recipe_collaborator;
@@ -0,0 +1,19 @@
+-- ============================================================================
+-- RECIPE COLLABORATORS
+-- ============================================================================
+-- Adds per-recipe collaborator invitations with editor/viewer roles so a
+-- team can co-maintain a recipe
+-- Migration: 000008_recipe_collaborators
+-- Created: 2026-10-17
+
+CREATE TABLE recipe_collaborator (
+    recipe_id TEXT NOT NULL REFERENCES recipe(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('editor', 'viewer')),
+    invited_by TEXT REFERENCES "user"(id) ON DELETE SET NULL,
+    accepted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (recipe_id, user_id)
+);
+
+CREATE INDEX idx_recipe_collaborator_user ON recipe_collaborator(user_id, accepted_at);
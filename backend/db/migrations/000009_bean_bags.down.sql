@@ -0,0 +1,20 @@
+-- ============================================================================
+-- ROLLBACK - BEAN BAGS
+-- ============================================================================
+-- Migration: 000009_bean_bags
+-- Created: 2026-10-17
+
+DELETE FROM notification WHERE type = 'bean_reorder' OR reference_type = 'bean_bag';
+
+ALTER TABLE notification
+    DROP CONSTRAINT notification_reference_type_check,
+    ADD CONSTRAINT notification_reference_type_check CHECK (reference_type IN ('post', 'comment', 'friendship', 'brew')),
+    DROP CONSTRAINT notification_type_check,
+    ADD CONSTRAINT notification_type_check CHECK (type IN ('like', 'comment', 'friend_request', 'tag', 'follow', 'brew_reminder'));
+
+DROP INDEX IF EXISTS idx_brew_bean_bag;
+
+ALTER TABLE brew
+    DROP COLUMN IF EXISTS bean_bag_id;
+
+DROP TABLE IF EXISTS bean_bag;
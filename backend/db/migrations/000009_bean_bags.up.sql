@@ -0,0 +1,42 @@
+-- ============================================================================
+-- BEAN BAGS
+-- ============================================================================
+-- Adds bean bags that brews can be logged against, so the server can forecast
+-- when a bag will run out and suggest reordering, plus the bean_reorder
+-- notification type
+-- Migration: 000009_bean_bags
+-- Created: 2026-10-17
+
+CREATE TABLE bean_bag (
+    id TEXT PRIMARY KEY,
+    owner_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    roaster TEXT,
+    bean_origin TEXT,
+    weight_grams DOUBLE PRECISION NOT NULL CHECK (weight_grams > 0),
+    roasted_on DATE,
+    assumed_dose_grams DOUBLE PRECISION CHECK (assumed_dose_grams IS NULL OR assumed_dose_grams > 0),
+    reorder_lead_days INTEGER NOT NULL DEFAULT 3 CHECK (reorder_lead_days >= 0),
+    reorder_notified_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_bean_bag_owner_id ON bean_bag(owner_id);
+
+CREATE TRIGGER update_bean_bag_updated_at
+BEFORE UPDATE ON bean_bag
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE brew
+    ADD COLUMN bean_bag_id TEXT REFERENCES bean_bag(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_brew_bean_bag ON brew(bean_bag_id, created_at);
+
+ALTER TABLE notification
+    DROP CONSTRAINT notification_type_check,
+    ADD CONSTRAINT notification_type_check CHECK (type IN ('like', 'comment', 'friend_request', 'tag', 'follow', 'brew_reminder', 'bean_reorder')),
+    DROP CONSTRAINT notification_reference_type_check,
+    ADD CONSTRAINT notification_reference_type_check CHECK (reference_type IN ('post', 'comment', 'friendship', 'brew', 'bean_bag'));
@@ -0,0 +1,14 @@
+-- ============================================================================
+-- ROLLBACK - BEAN PRICES
+-- ============================================================================
+-- Migration: 000010_bean_prices
+-- Created: 2026-10-17
+
+ALTER TABLE bean_bag
+    DROP CONSTRAINT IF EXISTS bean_bag_price_check,
+    DROP COLUMN IF EXISTS purchased_on,
+    DROP COLUMN IF EXISTS currency,
+    DROP COLUMN IF EXISTS price_minor;
+
+ALTER TABLE "user"
+    DROP COLUMN IF EXISTS currency;
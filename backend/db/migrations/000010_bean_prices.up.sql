@@ -0,0 +1,16 @@
+-- ============================================================================
+-- BEAN PRICES
+-- ============================================================================
+-- Adds prices to bean bags and a preferred currency to users, for
+-- cost-per-cup and monthly spend stats
+-- Migration: 000010_bean_prices
+-- Created: 2026-10-17
+
+ALTER TABLE "user"
+    ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'USD';
+
+ALTER TABLE bean_bag
+    ADD COLUMN price_minor BIGINT CHECK (price_minor IS NULL OR price_minor >= 0),
+    ADD COLUMN currency VARCHAR(3),
+    ADD COLUMN purchased_on DATE,
+    ADD CONSTRAINT bean_bag_price_check CHECK ((price_minor IS NULL) = (currency IS NULL));
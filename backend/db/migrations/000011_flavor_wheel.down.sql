@@ -0,0 +1,9 @@
+-- ============================================================================
+-- ROLLBACK - FLAVOR WHEEL
+-- ============================================================================
+-- Migration: 000011_flavor_wheel
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS bean_bag_flavor;
+DROP TABLE IF EXISTS brew_flavor;
+DROP TABLE IF EXISTS flavor_descriptor;
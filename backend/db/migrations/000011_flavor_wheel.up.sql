@@ -0,0 +1,145 @@
+-- ============================================================================
+-- FLAVOR WHEEL
+-- ============================================================================
+-- Adds the SCA coffee taster's flavor wheel as a descriptor taxonomy, and
+-- tables attaching descriptors to brews and bean bags
+-- Migration: 000011_flavor_wheel
+-- Created: 2026-10-17
+
+CREATE TABLE flavor_descriptor (
+    id VARCHAR(100) PRIMARY KEY,
+    parent_id VARCHAR(100) REFERENCES flavor_descriptor(id),
+    name VARCHAR(100) NOT NULL,
+    depth INTEGER NOT NULL CHECK (depth BETWEEN 1 AND 3)
+);
+
+CREATE INDEX idx_flavor_descriptor_parent ON flavor_descriptor(parent_id);
+
+CREATE TABLE brew_flavor (
+    brew_id TEXT NOT NULL REFERENCES brew(id) ON DELETE CASCADE,
+    descriptor_id VARCHAR(100) NOT NULL REFERENCES flavor_descriptor(id),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (brew_id, descriptor_id)
+);
+
+CREATE INDEX idx_brew_flavor_descriptor ON brew_flavor(descriptor_id);
+
+CREATE TABLE bean_bag_flavor (
+    bean_bag_id TEXT NOT NULL REFERENCES bean_bag(id) ON DELETE CASCADE,
+    descriptor_id VARCHAR(100) NOT NULL REFERENCES flavor_descriptor(id),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (bean_bag_id, descriptor_id)
+);
+
+-- SCA Coffee Taster's Flavor Wheel (2016)
+INSERT INTO flavor_descriptor (id, parent_id, name, depth) VALUES
+    ('floral', NULL, 'Floral', 1),
+    ('floral.black_tea', 'floral', 'Black Tea', 2),
+    ('floral.floral', 'floral', 'Floral', 2),
+    ('floral.floral.chamomile', 'floral.floral', 'Chamomile', 3),
+    ('floral.floral.rose', 'floral.floral', 'Rose', 3),
+    ('floral.floral.jasmine', 'floral.floral', 'Jasmine', 3),
+    ('fruity', NULL, 'Fruity', 1),
+    ('fruity.berry', 'fruity', 'Berry', 2),
+    ('fruity.berry.blackberry', 'fruity.berry', 'Blackberry', 3),
+    ('fruity.berry.raspberry', 'fruity.berry', 'Raspberry', 3),
+    ('fruity.berry.blueberry', 'fruity.berry', 'Blueberry', 3),
+    ('fruity.berry.strawberry', 'fruity.berry', 'Strawberry', 3),
+    ('fruity.dried_fruit', 'fruity', 'Dried Fruit', 2),
+    ('fruity.dried_fruit.raisin', 'fruity.dried_fruit', 'Raisin', 3),
+    ('fruity.dried_fruit.prune', 'fruity.dried_fruit', 'Prune', 3),
+    ('fruity.other_fruit', 'fruity', 'Other Fruit', 2),
+    ('fruity.other_fruit.coconut', 'fruity.other_fruit', 'Coconut', 3),
+    ('fruity.other_fruit.cherry', 'fruity.other_fruit', 'Cherry', 3),
+    ('fruity.other_fruit.pomegranate', 'fruity.other_fruit', 'Pomegranate', 3),
+    ('fruity.other_fruit.pineapple', 'fruity.other_fruit', 'Pineapple', 3),
+    ('fruity.other_fruit.grape', 'fruity.other_fruit', 'Grape', 3),
+    ('fruity.other_fruit.apple', 'fruity.other_fruit', 'Apple', 3),
+    ('fruity.other_fruit.peach', 'fruity.other_fruit', 'Peach', 3),
+    ('fruity.other_fruit.pear', 'fruity.other_fruit', 'Pear', 3),
+    ('fruity.citrus_fruit', 'fruity', 'Citrus Fruit', 2),
+    ('fruity.citrus_fruit.grapefruit', 'fruity.citrus_fruit', 'Grapefruit', 3),
+    ('fruity.citrus_fruit.orange', 'fruity.citrus_fruit', 'Orange', 3),
+    ('fruity.citrus_fruit.lemon', 'fruity.citrus_fruit', 'Lemon', 3),
+    ('fruity.citrus_fruit.lime', 'fruity.citrus_fruit', 'Lime', 3),
+    ('sour_fermented', NULL, 'Sour/Fermented', 1),
+    ('sour_fermented.sour', 'sour_fermented', 'Sour', 2),
+    ('sour_fermented.sour.sour_aromatics', 'sour_fermented.sour', 'Sour Aromatics', 3),
+    ('sour_fermented.sour.acetic_acid', 'sour_fermented.sour', 'Acetic Acid', 3),
+    ('sour_fermented.sour.butyric_acid', 'sour_fermented.sour', 'Butyric Acid', 3),
+    ('sour_fermented.sour.isovaleric_acid', 'sour_fermented.sour', 'Isovaleric Acid', 3),
+    ('sour_fermented.sour.citric_acid', 'sour_fermented.sour', 'Citric Acid', 3),
+    ('sour_fermented.sour.malic_acid', 'sour_fermented.sour', 'Malic Acid', 3),
+    ('sour_fermented.alcohol_fermented', 'sour_fermented', 'Alcohol/Fermented', 2),
+    ('sour_fermented.alcohol_fermented.winey', 'sour_fermented.alcohol_fermented', 'Winey', 3),
+    ('sour_fermented.alcohol_fermented.whiskey', 'sour_fermented.alcohol_fermented', 'Whiskey', 3),
+    ('sour_fermented.alcohol_fermented.fermented', 'sour_fermented.alcohol_fermented', 'Fermented', 3),
+    ('sour_fermented.alcohol_fermented.overripe', 'sour_fermented.alcohol_fermented', 'Overripe', 3),
+    ('green_vegetative', NULL, 'Green/Vegetative', 1),
+    ('green_vegetative.olive_oil', 'green_vegetative', 'Olive Oil', 2),
+    ('green_vegetative.raw', 'green_vegetative', 'Raw', 2),
+    ('green_vegetative.green_vegetative', 'green_vegetative', 'Green/Vegetative', 2),
+    ('green_vegetative.green_vegetative.under_ripe', 'green_vegetative.green_vegetative', 'Under-ripe', 3),
+    ('green_vegetative.green_vegetative.peapod', 'green_vegetative.green_vegetative', 'Peapod', 3),
+    ('green_vegetative.green_vegetative.fresh', 'green_vegetative.green_vegetative', 'Fresh', 3),
+    ('green_vegetative.green_vegetative.dark_green', 'green_vegetative.green_vegetative', 'Dark Green', 3),
+    ('green_vegetative.green_vegetative.vegetative', 'green_vegetative.green_vegetative', 'Vegetative', 3),
+    ('green_vegetative.green_vegetative.hay_like', 'green_vegetative.green_vegetative', 'Hay-like', 3),
+    ('green_vegetative.green_vegetative.herb_like', 'green_vegetative.green_vegetative', 'Herb-like', 3),
+    ('green_vegetative.beany', 'green_vegetative', 'Beany', 2),
+    ('other', NULL, 'Other', 1),
+    ('other.papery_musty', 'other', 'Papery/Musty', 2),
+    ('other.papery_musty.stale', 'other.papery_musty', 'Stale', 3),
+    ('other.papery_musty.cardboard', 'other.papery_musty', 'Cardboard', 3),
+    ('other.papery_musty.papery', 'other.papery_musty', 'Papery', 3),
+    ('other.papery_musty.woody', 'other.papery_musty', 'Woody', 3),
+    ('other.papery_musty.moldy_damp', 'other.papery_musty', 'Moldy/Damp', 3),
+    ('other.papery_musty.musty_dusty', 'other.papery_musty', 'Musty/Dusty', 3),
+    ('other.papery_musty.musty_earthy', 'other.papery_musty', 'Musty/Earthy', 3),
+    ('other.papery_musty.animalic', 'other.papery_musty', 'Animalic', 3),
+    ('other.papery_musty.meaty_brothy', 'other.papery_musty', 'Meaty Brothy', 3),
+    ('other.papery_musty.phenolic', 'other.papery_musty', 'Phenolic', 3),
+    ('other.chemical', 'other', 'Chemical', 2),
+    ('other.chemical.bitter', 'other.chemical', 'Bitter', 3),
+    ('other.chemical.salty', 'other.chemical', 'Salty', 3),
+    ('other.chemical.medicinal', 'other.chemical', 'Medicinal', 3),
+    ('other.chemical.petroleum', 'other.chemical', 'Petroleum', 3),
+    ('other.chemical.skunky', 'other.chemical', 'Skunky', 3),
+    ('other.chemical.rubber', 'other.chemical', 'Rubber', 3),
+    ('roasted', NULL, 'Roasted', 1),
+    ('roasted.pipe_tobacco', 'roasted', 'Pipe Tobacco', 2),
+    ('roasted.tobacco', 'roasted', 'Tobacco', 2),
+    ('roasted.burnt', 'roasted', 'Burnt', 2),
+    ('roasted.burnt.acrid', 'roasted.burnt', 'Acrid', 3),
+    ('roasted.burnt.ashy', 'roasted.burnt', 'Ashy', 3),
+    ('roasted.burnt.smoky', 'roasted.burnt', 'Smoky', 3),
+    ('roasted.burnt.brown_roast', 'roasted.burnt', 'Brown Roast', 3),
+    ('roasted.cereal', 'roasted', 'Cereal', 2),
+    ('roasted.cereal.grain', 'roasted.cereal', 'Grain', 3),
+    ('roasted.cereal.malt', 'roasted.cereal', 'Malt', 3),
+    ('spices', NULL, 'Spices', 1),
+    ('spices.pungent', 'spices', 'Pungent', 2),
+    ('spices.pepper', 'spices', 'Pepper', 2),
+    ('spices.brown_spice', 'spices', 'Brown Spice', 2),
+    ('spices.brown_spice.anise', 'spices.brown_spice', 'Anise', 3),
+    ('spices.brown_spice.nutmeg', 'spices.brown_spice', 'Nutmeg', 3),
+    ('spices.brown_spice.cinnamon', 'spices.brown_spice', 'Cinnamon', 3),
+    ('spices.brown_spice.clove', 'spices.brown_spice', 'Clove', 3),
+    ('nutty_cocoa', NULL, 'Nutty/Cocoa', 1),
+    ('nutty_cocoa.nutty', 'nutty_cocoa', 'Nutty', 2),
+    ('nutty_cocoa.nutty.peanuts', 'nutty_cocoa.nutty', 'Peanuts', 3),
+    ('nutty_cocoa.nutty.hazelnut', 'nutty_cocoa.nutty', 'Hazelnut', 3),
+    ('nutty_cocoa.nutty.almond', 'nutty_cocoa.nutty', 'Almond', 3),
+    ('nutty_cocoa.cocoa', 'nutty_cocoa', 'Cocoa', 2),
+    ('nutty_cocoa.cocoa.chocolate', 'nutty_cocoa.cocoa', 'Chocolate', 3),
+    ('nutty_cocoa.cocoa.dark_chocolate', 'nutty_cocoa.cocoa', 'Dark Chocolate', 3),
+    ('sweet', NULL, 'Sweet', 1),
+    ('sweet.brown_sugar', 'sweet', 'Brown Sugar', 2),
+    ('sweet.brown_sugar.molasses', 'sweet.brown_sugar', 'Molasses', 3),
+    ('sweet.brown_sugar.maple_syrup', 'sweet.brown_sugar', 'Maple Syrup', 3),
+    ('sweet.brown_sugar.caramelized', 'sweet.brown_sugar', 'Caramelized', 3),
+    ('sweet.brown_sugar.honey', 'sweet.brown_sugar', 'Honey', 3),
+    ('sweet.vanilla', 'sweet', 'Vanilla', 2),
+    ('sweet.vanillin', 'sweet', 'Vanillin', 2),
+    ('sweet.overall_sweet', 'sweet', 'Overall Sweet', 2),
+    ('sweet.sweet_aromatics', 'sweet', 'Sweet Aromatics', 2);
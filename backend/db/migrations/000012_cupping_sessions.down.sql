@@ -0,0 +1,10 @@
+-- ============================================================================
+-- ROLLBACK - CUPPING SESSIONS
+-- ============================================================================
+-- Migration: 000012_cupping_sessions
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS cupping_score;
+DROP TABLE IF EXISTS cupping_participant;
+DROP TABLE IF EXISTS cupping_sample;
+DROP TABLE IF EXISTS cupping_session;
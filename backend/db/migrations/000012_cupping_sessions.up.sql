@@ -0,0 +1,62 @@
+-- ============================================================================
+-- CUPPING SESSIONS
+-- ============================================================================
+-- Adds blind cupping sessions: anonymized samples, invited participants,
+-- independent scores and a host-controlled reveal
+-- Migration: 000012_cupping_sessions
+-- Created: 2026-10-17
+
+CREATE TABLE cupping_session (
+    id TEXT PRIMARY KEY,
+    host_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    notes TEXT,
+    revealed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_cupping_session_host_id ON cupping_session(host_id);
+
+CREATE TABLE cupping_sample (
+    id TEXT PRIMARY KEY,
+    session_id TEXT NOT NULL REFERENCES cupping_session(id) ON DELETE CASCADE,
+    label VARCHAR(10) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    roaster TEXT,
+    bean_origin TEXT,
+    bean_bag_id TEXT REFERENCES bean_bag(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (session_id, label)
+);
+
+CREATE TABLE cupping_participant (
+    session_id TEXT NOT NULL REFERENCES cupping_session(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (session_id, user_id)
+);
+
+CREATE INDEX idx_cupping_participant_user ON cupping_participant(user_id);
+
+CREATE TABLE cupping_score (
+    sample_id TEXT NOT NULL REFERENCES cupping_sample(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL CHECK (score >= 0 AND score <= 100),
+    notes TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (sample_id, user_id)
+);
+
+CREATE INDEX idx_cupping_score_user ON cupping_score(user_id);
+
+CREATE TRIGGER update_cupping_session_updated_at
+BEFORE UPDATE ON cupping_session
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_cupping_score_updated_at
+BEFORE UPDATE ON cupping_score
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
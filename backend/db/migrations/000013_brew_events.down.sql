@@ -0,0 +1,8 @@
+-- ============================================================================
+-- ROLLBACK - BREW EVENTS
+-- ============================================================================
+-- Migration: 000013_brew_events
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS brew_event_rsvp;
+DROP TABLE IF EXISTS brew_event;
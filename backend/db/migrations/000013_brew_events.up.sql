@@ -0,0 +1,47 @@
+-- ============================================================================
+-- BREW EVENTS
+-- ============================================================================
+-- Adds scheduled brew-along events around a shared recipe revision, with
+-- RSVPs and a host-controlled timer that is broadcast to attendees
+-- Migration: 000013_brew_events
+-- Created: 2026-10-17
+
+CREATE TABLE brew_event (
+    id TEXT PRIMARY KEY,
+    host_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    recipe_id TEXT NOT NULL REFERENCES recipe(id) ON DELETE CASCADE,
+    recipe_revision INTEGER NOT NULL,
+    starts_at TIMESTAMPTZ NOT NULL,
+    timer_started_at TIMESTAMPTZ,
+    timer_ended_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    CONSTRAINT brew_event_timer_check CHECK (timer_ended_at IS NULL OR (timer_started_at IS NOT NULL AND timer_ended_at >= timer_started_at)),
+    FOREIGN KEY (recipe_id, recipe_revision) REFERENCES recipe_revision(recipe_id, revision)
+);
+
+CREATE INDEX idx_brew_event_starts_at ON brew_event(starts_at);
+CREATE INDEX idx_brew_event_host_id ON brew_event(host_id);
+
+CREATE TABLE brew_event_rsvp (
+    event_id TEXT NOT NULL REFERENCES brew_event(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('going', 'maybe', 'not_going')),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (event_id, user_id)
+);
+
+CREATE INDEX idx_brew_event_rsvp_user ON brew_event_rsvp(user_id);
+
+CREATE TRIGGER update_brew_event_updated_at
+BEFORE UPDATE ON brew_event
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_brew_event_rsvp_updated_at
+BEFORE UPDATE ON brew_event_rsvp
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
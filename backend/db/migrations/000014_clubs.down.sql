@@ -0,0 +1,10 @@
+-- ============================================================================
+-- ROLLBACK - CLUBS
+-- ============================================================================
+-- Migration: 000014_clubs
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS club_share;
+DROP TABLE IF EXISTS club_invite;
+DROP TABLE IF EXISTS club_member;
+DROP TABLE IF EXISTS club;
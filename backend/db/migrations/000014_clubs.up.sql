@@ -0,0 +1,62 @@
+-- ============================================================================
+-- CLUBS
+-- ============================================================================
+-- Adds clubs with member roles, invite links and a shared feed of brews and
+-- recipes
+-- Migration: 000014_clubs
+-- Created: 2026-10-17
+
+CREATE TABLE club (
+    id TEXT PRIMARY KEY,
+    owner_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    is_private BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_club_owner_id ON club(owner_id);
+CREATE INDEX idx_club_public ON club(created_at DESC) WHERE NOT is_private;
+
+CREATE TABLE club_member (
+    club_id TEXT NOT NULL REFERENCES club(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'moderator', 'member')),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (club_id, user_id)
+);
+
+CREATE INDEX idx_club_member_user ON club_member(user_id);
+
+CREATE TABLE club_invite (
+    id TEXT PRIMARY KEY,
+    club_id TEXT NOT NULL REFERENCES club(id) ON DELETE CASCADE,
+    created_by TEXT REFERENCES "user"(id) ON DELETE SET NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    max_uses INTEGER CHECK (max_uses IS NULL OR max_uses > 0),
+    use_count INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_club_invite_club ON club_invite(club_id);
+
+CREATE TABLE club_share (
+    id TEXT PRIMARY KEY,
+    club_id TEXT NOT NULL REFERENCES club(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    brew_id TEXT REFERENCES brew(id) ON DELETE CASCADE,
+    recipe_id TEXT REFERENCES recipe(id) ON DELETE CASCADE,
+    note TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    CONSTRAINT club_share_item_check CHECK ((brew_id IS NULL) <> (recipe_id IS NULL))
+);
+
+CREATE INDEX idx_club_share_feed ON club_share(club_id, created_at DESC);
+
+CREATE TRIGGER update_club_updated_at
+BEFORE UPDATE ON club
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,17 @@
+-- ============================================================================
+-- ROLLBACK - CHALLENGES AND BADGES
+-- ============================================================================
+-- Migration: 000015_badges
+-- Created: 2026-10-17
+
+DELETE FROM notification WHERE type = 'badge_earned' OR reference_type = 'challenge';
+
+ALTER TABLE notification
+    DROP CONSTRAINT notification_reference_type_check,
+    ADD CONSTRAINT notification_reference_type_check CHECK (reference_type IN ('post', 'comment', 'friendship', 'brew', 'bean_bag')),
+    DROP CONSTRAINT notification_type_check,
+    ADD CONSTRAINT notification_type_check CHECK (type IN ('like', 'comment', 'friend_request', 'tag', 'follow', 'brew_reminder', 'bean_reorder'));
+
+DROP TABLE IF EXISTS badge_evaluation;
+DROP TABLE IF EXISTS user_badge;
+DROP TABLE IF EXISTS challenge;
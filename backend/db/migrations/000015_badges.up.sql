@@ -0,0 +1,59 @@
+-- ============================================================================
+-- CHALLENGES AND BADGES
+-- ============================================================================
+-- Adds challenges users complete to earn badges, a queue of users awaiting
+-- evaluation, a starter set of challenges and the badge_earned notification
+-- Migration: 000015_badges
+-- Created: 2026-10-17
+
+CREATE TABLE challenge (
+    id TEXT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    metric VARCHAR(20) NOT NULL CHECK (metric IN ('brews', 'origins', 'methods')),
+    brew_method VARCHAR(100) CHECK (brew_method IS NULL OR metric = 'brews'),
+    threshold INTEGER NOT NULL CHECK (threshold > 0),
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_by TEXT REFERENCES "user"(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE user_badge (
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    challenge_id TEXT NOT NULL REFERENCES challenge(id) ON DELETE CASCADE,
+    earned_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, challenge_id)
+);
+
+CREATE INDEX idx_user_badge_challenge ON user_badge(challenge_id);
+
+CREATE TABLE badge_evaluation (
+    user_id TEXT PRIMARY KEY REFERENCES "user"(id) ON DELETE CASCADE,
+    queued_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_badge_evaluation_queued_at ON badge_evaluation(queued_at);
+
+CREATE TRIGGER update_challenge_updated_at
+BEFORE UPDATE ON challenge
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE notification
+    DROP CONSTRAINT notification_type_check,
+    ADD CONSTRAINT notification_type_check CHECK (type IN ('like', 'comment', 'friend_request', 'tag', 'follow', 'brew_reminder', 'bean_reorder', 'badge_earned')),
+    DROP CONSTRAINT notification_reference_type_check,
+    ADD CONSTRAINT notification_reference_type_check CHECK (reference_type IN ('post', 'comment', 'friendship', 'brew', 'bean_bag', 'challenge'));
+
+-- Starter challenges
+INSERT INTO challenge (id, name, description, metric, brew_method, threshold) VALUES
+    ('01M54HDSM0KHM4H08Z7RAG68J8', 'First Brew', 'Log your first brew', 'brews', NULL, 1),
+    ('01M54HDSM1R2V8QVXGEBE6Z6CX', 'First V60', 'Log a brew made with a V60', 'brews', 'v60', 1),
+    ('01M54HDSM2N82DQ66DPZVMFNPN', 'Centurion', 'Log 100 brews', 'brews', NULL, 100),
+    ('01M54HDSM3V8PN61XCYDADZ3S7', 'World Tour', 'Brew beans from 10 different origins', 'origins', NULL, 10),
+    ('01M54HDSM4GHHT3CDMG8R97X9T', 'Method Explorer', 'Brew with 5 different brew methods', 'methods', NULL, 5);
+
+-- Award badges for brews logged before this migration
+INSERT INTO badge_evaluation (user_id)
+SELECT DISTINCT created_by FROM brew WHERE created_by IS NOT NULL;
@@ -0,0 +1,9 @@
+-- ============================================================================
+-- ROLLBACK - ROASTER ACCOUNTS
+-- ============================================================================
+-- Migration: 000016_roasters
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS bean_recipe;
+DROP TABLE IF EXISTS bean;
+DROP TABLE IF EXISTS roaster;
@@ -0,0 +1,55 @@
+-- ============================================================================
+-- ROASTER ACCOUNTS
+-- ============================================================================
+-- Adds roaster accounts with admin verification, a community bean catalogue
+-- holding roasters' official listings, and official recipes on those listings
+-- Migration: 000016_roasters
+-- Created: 2026-10-17
+
+CREATE TABLE roaster (
+    id TEXT PRIMARY KEY, -- ULID format
+    user_id TEXT NOT NULL UNIQUE REFERENCES "user"(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    website TEXT,
+    location TEXT,
+    description TEXT,
+    verified_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_roaster_name_lower ON roaster(LOWER(name));
+
+CREATE TABLE bean (
+    id TEXT PRIMARY KEY, -- ULID format
+    name VARCHAR(255) NOT NULL,
+    roaster_name VARCHAR(255),
+    bean_origin TEXT,
+    description TEXT,
+    roaster_id TEXT REFERENCES roaster(id) ON DELETE SET NULL,
+    created_by TEXT REFERENCES "user"(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_bean_name_lower ON bean(LOWER(name));
+CREATE INDEX idx_bean_roaster_id ON bean(roaster_id);
+
+CREATE TABLE bean_recipe (
+    bean_id TEXT NOT NULL REFERENCES bean(id) ON DELETE CASCADE,
+    recipe_id TEXT NOT NULL REFERENCES recipe(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (bean_id, recipe_id)
+);
+
+CREATE INDEX idx_bean_recipe_recipe ON bean_recipe(recipe_id);
+
+CREATE TRIGGER update_roaster_updated_at
+BEFORE UPDATE ON roaster
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_bean_updated_at
+BEFORE UPDATE ON bean
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,24 @@
+-- ============================================================================
+-- ROLLBACK - ORIGIN METADATA
+-- ============================================================================
+-- Migration: 000017_origin_metadata
+-- Created: 2026-10-17
+
+DROP INDEX IF EXISTS idx_bean_process;
+DROP INDEX IF EXISTS idx_bean_origin_country;
+
+ALTER TABLE bean
+    DROP COLUMN IF EXISTS process,
+    DROP COLUMN IF EXISTS altitude_m,
+    DROP COLUMN IF EXISTS variety,
+    DROP COLUMN IF EXISTS farm,
+    DROP COLUMN IF EXISTS origin_region,
+    DROP COLUMN IF EXISTS origin_country;
+
+ALTER TABLE bean_bag
+    DROP COLUMN IF EXISTS process,
+    DROP COLUMN IF EXISTS altitude_m,
+    DROP COLUMN IF EXISTS variety,
+    DROP COLUMN IF EXISTS farm,
+    DROP COLUMN IF EXISTS origin_region,
+    DROP COLUMN IF EXISTS origin_country;
@@ -0,0 +1,34 @@
+-- ============================================================================
+-- ORIGIN METADATA
+-- ============================================================================
+-- Adds structured origin (country, region, farm, variety, altitude and
+-- process) to bean bags and catalogue beans, for origin filters and stats
+-- Migration: 000017_origin_metadata
+-- Created: 2026-10-17
+
+ALTER TABLE bean_bag
+    ADD COLUMN origin_country VARCHAR(2),
+    ADD COLUMN origin_region VARCHAR(100),
+    ADD COLUMN farm VARCHAR(255),
+    ADD COLUMN variety VARCHAR(50),
+    ADD COLUMN altitude_m INTEGER CHECK (altitude_m IS NULL OR altitude_m BETWEEN 0 AND 6000),
+    ADD COLUMN process VARCHAR(30) CHECK (
+        process IS NULL OR
+        process IN ('washed', 'natural', 'honey', 'semi_washed', 'wet_hulled',
+                    'anaerobic', 'carbonic_maceration', 'other')
+    );
+
+ALTER TABLE bean
+    ADD COLUMN origin_country VARCHAR(2),
+    ADD COLUMN origin_region VARCHAR(100),
+    ADD COLUMN farm VARCHAR(255),
+    ADD COLUMN variety VARCHAR(50),
+    ADD COLUMN altitude_m INTEGER CHECK (altitude_m IS NULL OR altitude_m BETWEEN 0 AND 6000),
+    ADD COLUMN process VARCHAR(30) CHECK (
+        process IS NULL OR
+        process IN ('washed', 'natural', 'honey', 'semi_washed', 'wet_hulled',
+                    'anaerobic', 'carbonic_maceration', 'other')
+    );
+
+CREATE INDEX idx_bean_origin_country ON bean(origin_country);
+CREATE INDEX idx_bean_process ON bean(process);
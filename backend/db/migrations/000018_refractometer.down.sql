@@ -0,0 +1,8 @@
+-- ============================================================================
+-- ROLLBACK - REFRACTOMETER INTEGRATIONS
+-- ============================================================================
+-- Migration: 000018_refractometer
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS tds_reading;
+DROP TABLE IF EXISTS api_key;
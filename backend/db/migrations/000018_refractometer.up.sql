@@ -0,0 +1,39 @@
+-- ============================================================================
+-- REFRACTOMETER INTEGRATIONS
+-- ============================================================================
+-- Adds scoped API keys for companion apps and TDS readings ingested from
+-- Bluetooth refractometers, attached to brew sessions
+-- Migration: 000018_refractometer
+-- Created: 2026-10-17
+
+CREATE TABLE api_key (
+    id TEXT PRIMARY KEY, -- ULID format
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    key_prefix VARCHAR(16) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_api_key_user ON api_key(user_id);
+
+CREATE TABLE tds_reading (
+    id TEXT PRIMARY KEY, -- ULID format
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    brew_id TEXT REFERENCES brew(id) ON DELETE SET NULL,
+    api_key_id TEXT REFERENCES api_key(id) ON DELETE SET NULL,
+    client_id VARCHAR(100),
+    tds_percent DOUBLE PRECISION NOT NULL CHECK (tds_percent > 0 AND tds_percent <= 30),
+    temperature_c DOUBLE PRECISION CHECK (temperature_c IS NULL OR (temperature_c >= 0 AND temperature_c <= 100)),
+    beverage_grams DOUBLE PRECISION CHECK (beverage_grams IS NULL OR beverage_grams > 0),
+    device_model VARCHAR(100),
+    measured_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    CONSTRAINT tds_reading_client_unique UNIQUE (user_id, client_id)
+);
+
+CREATE INDEX idx_tds_reading_brew ON tds_reading(brew_id, measured_at);
+CREATE INDEX idx_tds_reading_user ON tds_reading(user_id, measured_at DESC);
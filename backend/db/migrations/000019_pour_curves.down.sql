@@ -0,0 +1,8 @@
+-- ============================================================================
+-- ROLLBACK - POUR CURVES
+-- ============================================================================
+-- Migration: 000019_pour_curves
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS pour_sample;
+DROP TABLE IF EXISTS pour_curve;
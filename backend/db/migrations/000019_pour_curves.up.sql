@@ -0,0 +1,35 @@
+-- ============================================================================
+-- POUR CURVES
+-- ============================================================================
+-- Adds pour curves streamed from smart scale bridges, stored as one row per
+-- sample and attached to brews
+-- Migration: 000019_pour_curves
+-- Created: 2026-10-17
+
+CREATE TABLE pour_curve (
+    id TEXT PRIMARY KEY, -- ULID format
+    brew_id TEXT NOT NULL UNIQUE REFERENCES brew(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    api_key_id TEXT REFERENCES api_key(id) ON DELETE SET NULL,
+    client_id VARCHAR(100) NOT NULL,
+    device_model VARCHAR(100),
+    started_at TIMESTAMPTZ NOT NULL,
+    sample_count INTEGER NOT NULL DEFAULT 0,
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    final_grams DOUBLE PRECISION,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    CONSTRAINT pour_curve_client_unique UNIQUE (user_id, client_id)
+);
+
+CREATE TABLE pour_sample (
+    curve_id TEXT NOT NULL REFERENCES pour_curve(id) ON DELETE CASCADE,
+    t_ms INTEGER NOT NULL CHECK (t_ms >= 0),
+    grams DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (curve_id, t_ms)
+);
+
+CREATE TRIGGER update_pour_curve_updated_at
+BEFORE UPDATE ON pour_curve
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
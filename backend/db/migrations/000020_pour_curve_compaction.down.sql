@@ -0,0 +1,19 @@
+-- ============================================================================
+-- ROLLBACK - POUR CURVE COMPACTION
+-- ============================================================================
+-- Migration: 000020_pour_curve_compaction
+-- Created: 2026-10-17
+
+INSERT INTO pour_sample (curve_id, t_ms, grams)
+SELECT pc.id, s.t_ms, s.grams
+FROM pour_curve pc,
+    unnest(pc.sample_t_ms, pc.sample_grams) AS s(t_ms, grams)
+ON CONFLICT (curve_id, t_ms) DO NOTHING;
+
+ALTER TABLE pour_sample
+    DROP COLUMN IF EXISTS pressure_bar;
+
+ALTER TABLE pour_curve
+    DROP COLUMN IF EXISTS sample_pressure_bar,
+    DROP COLUMN IF EXISTS sample_grams,
+    DROP COLUMN IF EXISTS sample_t_ms;
@@ -0,0 +1,31 @@
+-- ============================================================================
+-- POUR CURVE COMPACTION
+-- ============================================================================
+-- Stores finished pour curves as arrays on pour_curve, keeping pour_sample
+-- rows only for streams in progress, and adds pressure for espresso curves
+-- Migration: 000020_pour_curve_compaction
+-- Created: 2026-10-17
+
+ALTER TABLE pour_curve
+    ADD COLUMN sample_t_ms INTEGER[] NOT NULL DEFAULT '{}',
+    ADD COLUMN sample_grams REAL[] NOT NULL DEFAULT '{}',
+    ADD COLUMN sample_pressure_bar REAL[];
+
+ALTER TABLE pour_sample
+    ADD COLUMN pressure_bar DOUBLE PRECISION CHECK (pressure_bar IS NULL OR (pressure_bar >= 0 AND pressure_bar <= 20));
+
+UPDATE pour_curve pc
+SET
+    sample_t_ms = s.t_ms,
+    sample_grams = s.grams
+FROM (
+    SELECT
+        curve_id,
+        ARRAY_AGG(t_ms ORDER BY t_ms) AS t_ms,
+        ARRAY_AGG(grams::real ORDER BY t_ms) AS grams
+    FROM pour_sample
+    GROUP BY curve_id
+) s
+WHERE pc.id = s.curve_id;
+
+DELETE FROM pour_sample;
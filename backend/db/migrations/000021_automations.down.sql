@@ -0,0 +1,8 @@
+-- ============================================================================
+-- ROLLBACK - AUTOMATIONS
+-- ============================================================================
+-- Migration: 000021_automations
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS automation_delivery;
+DROP TABLE IF EXISTS automation_webhook;
@@ -0,0 +1,43 @@
+-- ============================================================================
+-- AUTOMATIONS
+-- ============================================================================
+-- Adds Home Assistant and IFTTT webhooks notified of brew activity, with a
+-- delivery queue
+-- Migration: 000021_automations
+-- Created: 2026-10-17
+
+CREATE TABLE automation_webhook (
+    id TEXT PRIMARY KEY, -- ULID format
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    format VARCHAR(20) NOT NULL CHECK (format IN ('home_assistant', 'ifttt')),
+    url TEXT NOT NULL,
+    events TEXT[] NOT NULL,
+    last_status INTEGER,
+    last_error TEXT,
+    last_delivered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_automation_webhook_user ON automation_webhook(user_id);
+
+CREATE TABLE automation_delivery (
+    id TEXT PRIMARY KEY, -- ULID format
+    webhook_id TEXT NOT NULL REFERENCES automation_webhook(id) ON DELETE CASCADE,
+    event VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMPTZ,
+    failed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_automation_delivery_due ON automation_delivery(next_attempt_at)
+    WHERE delivered_at IS NULL AND failed_at IS NULL;
+
+CREATE TRIGGER update_automation_webhook_updated_at
+BEFORE UPDATE ON automation_webhook
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
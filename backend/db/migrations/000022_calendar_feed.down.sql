@@ -0,0 +1,11 @@
+-- ============================================================================
+-- ROLLBACK - CALENDAR FEED
+-- ============================================================================
+-- Migration: 000022_calendar_feed
+-- Created: 2026-10-17
+
+ALTER TABLE bean_bag
+    DROP COLUMN IF EXISTS rest_days;
+
+ALTER TABLE "user"
+    DROP COLUMN IF EXISTS calendar_feed_version;
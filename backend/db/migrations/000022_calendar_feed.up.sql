@@ -0,0 +1,13 @@
+-- ============================================================================
+-- CALENDAR FEED
+-- ============================================================================
+-- Adds a revocable version to each user's signed calendar feed URL and a
+-- resting period to bean bags, shown in the feed as the date they're ready
+-- Migration: 000022_calendar_feed
+-- Created: 2026-10-17
+
+ALTER TABLE "user"
+    ADD COLUMN calendar_feed_version INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE bean_bag
+    ADD COLUMN rest_days INTEGER CHECK (rest_days IS NULL OR rest_days BETWEEN 0 AND 60);
@@ -0,0 +1,10 @@
+-- ============================================================================
+-- ROLLBACK - BREW CUSTOM FIELDS
+-- ============================================================================
+-- Migration: 000023_brew_custom_fields
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS brew_custom_field;
+
+ALTER TABLE brew
+    DROP COLUMN IF EXISTS custom_fields;
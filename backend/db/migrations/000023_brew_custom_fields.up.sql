@@ -0,0 +1,26 @@
+-- ============================================================================
+-- BREW CUSTOM FIELDS
+-- ============================================================================
+-- Adds user-defined fields to brews: each user's field definitions, and the
+-- values on each brew as JSONB
+-- Migration: 000023_brew_custom_fields
+-- Created: 2026-10-17
+
+ALTER TABLE brew
+    ADD COLUMN custom_fields JSONB NOT NULL DEFAULT '{}';
+
+CREATE TABLE brew_custom_field (
+    id TEXT PRIMARY KEY, -- ULID format
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('number', 'text', 'boolean')),
+    unit VARCHAR(20),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (user_id, name)
+);
+
+CREATE TRIGGER update_brew_custom_field_updated_at
+BEFORE UPDATE ON brew_custom_field
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,7 @@
+-- ============================================================================
+-- ROLLBACK - DRAFTS
+-- ============================================================================
+-- Migration: 000024_drafts
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS draft;
@@ -0,0 +1,22 @@
+-- ============================================================================
+-- DRAFTS
+-- ============================================================================
+-- Adds autosaved drafts of brews and recipes that are still being entered
+-- Migration: 000024_drafts
+-- Created: 2026-10-17
+
+CREATE TABLE draft (
+    id TEXT PRIMARY KEY, -- ULID format
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('brew', 'recipe')),
+    data JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_draft_user_kind ON draft(user_id, kind, updated_at DESC);
+
+CREATE TRIGGER update_draft_updated_at
+BEFORE UPDATE ON draft
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,26 @@
+-- ============================================================================
+-- ROLLBACK - OFFLINE SYNC
+-- ============================================================================
+-- Migration: 000025_offline_sync
+-- Created: 2026-10-17
+
+DROP TRIGGER IF EXISTS sync_tombstone_draft ON draft;
+DROP TRIGGER IF EXISTS sync_track_draft ON draft;
+DROP TRIGGER IF EXISTS sync_tombstone_brew ON brew;
+DROP TRIGGER IF EXISTS sync_track_brew ON brew;
+
+DROP FUNCTION IF EXISTS sync_tombstone();
+DROP FUNCTION IF EXISTS sync_track();
+DROP FUNCTION IF EXISTS sync_lock(TEXT);
+
+DROP TABLE IF EXISTS sync_tombstone;
+
+ALTER TABLE draft
+    DROP COLUMN IF EXISTS sync_vector,
+    DROP COLUMN IF EXISTS sync_seq;
+
+ALTER TABLE brew
+    DROP COLUMN IF EXISTS sync_vector,
+    DROP COLUMN IF EXISTS sync_seq;
+
+DROP SEQUENCE IF EXISTS sync_seq;
@@ -0,0 +1,102 @@
+-- ============================================================================
+-- OFFLINE SYNC
+-- ============================================================================
+-- Adds change sequences, revision vectors and tombstones to brews and drafts
+-- for the mobile client's offline sync
+-- Migration: 000025_offline_sync
+-- Created: 2026-10-17
+
+CREATE SEQUENCE sync_seq;
+
+-- Volatile defaults are evaluated per existing row without firing triggers,
+-- which backfills both columns without touching updated_at
+ALTER TABLE brew
+    ADD COLUMN sync_seq BIGINT NOT NULL DEFAULT nextval('sync_seq'),
+    ADD COLUMN sync_vector JSONB NOT NULL DEFAULT '{"server": 1}';
+ALTER TABLE brew
+    ALTER COLUMN sync_seq SET DEFAULT 0,
+    ALTER COLUMN sync_vector SET DEFAULT '{}';
+
+ALTER TABLE draft
+    ADD COLUMN sync_seq BIGINT NOT NULL DEFAULT nextval('sync_seq'),
+    ADD COLUMN sync_vector JSONB NOT NULL DEFAULT '{"server": 1}';
+ALTER TABLE draft
+    ALTER COLUMN sync_seq SET DEFAULT 0,
+    ALTER COLUMN sync_vector SET DEFAULT '{}';
+
+CREATE INDEX idx_brew_sync ON brew(created_by, sync_seq);
+CREATE INDEX idx_draft_sync ON draft(user_id, sync_seq);
+
+CREATE TABLE sync_tombstone (
+    resource VARCHAR(20) NOT NULL,
+    record_id TEXT NOT NULL,
+    user_id TEXT NOT NULL, -- no foreign key: outlives the user's rows
+    vector JSONB NOT NULL DEFAULT '{}',
+    seq BIGINT NOT NULL DEFAULT nextval('sync_seq'),
+    deleted_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (resource, record_id)
+);
+
+CREATE INDEX idx_sync_tombstone_user ON sync_tombstone(user_id, seq);
+
+-- A user's changes take sync_seq values in commit order: each transaction
+-- changing a user's synced rows waits for the user's earlier ones to
+-- finish, so a pull's cursor never passes a change that commits after it.
+CREATE OR REPLACE FUNCTION sync_lock(owner TEXT)
+RETURNS VOID AS $$
+BEGIN
+    IF owner IS NOT NULL THEN
+        PERFORM pg_advisory_xact_lock(hashtext('sync:' || owner));
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION sync_track()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM sync_lock(to_jsonb(NEW)->>TG_ARGV[0]);
+    NEW.sync_seq = nextval('sync_seq');
+    IF TG_OP = 'INSERT' THEN
+        IF NEW.sync_vector = '{}'::jsonb THEN
+            NEW.sync_vector = '{"server": 1}'::jsonb;
+        END IF;
+    ELSIF NEW.sync_vector = OLD.sync_vector THEN
+        NEW.sync_vector = jsonb_set(OLD.sync_vector, '{server}',
+            to_jsonb(COALESCE((OLD.sync_vector->>'server')::bigint, 0) + 1));
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION sync_tombstone()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM sync_lock(to_jsonb(OLD)->>TG_ARGV[1]);
+    INSERT INTO sync_tombstone (resource, record_id, user_id, vector)
+    VALUES (TG_ARGV[0], OLD.id, to_jsonb(OLD)->>TG_ARGV[1], OLD.sync_vector)
+    ON CONFLICT (resource, record_id) DO UPDATE
+    SET vector = EXCLUDED.vector, seq = nextval('sync_seq'), deleted_at = NOW();
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER sync_track_brew
+BEFORE INSERT OR UPDATE ON brew
+FOR EACH ROW
+EXECUTE FUNCTION sync_track('created_by');
+
+CREATE TRIGGER sync_tombstone_brew
+AFTER DELETE ON brew
+FOR EACH ROW
+WHEN (OLD.created_by IS NOT NULL)
+EXECUTE FUNCTION sync_tombstone('brew', 'created_by');
+
+CREATE TRIGGER sync_track_draft
+BEFORE INSERT OR UPDATE ON draft
+FOR EACH ROW
+EXECUTE FUNCTION sync_track('user_id');
+
+CREATE TRIGGER sync_tombstone_draft
+AFTER DELETE ON draft
+FOR EACH ROW
+EXECUTE FUNCTION sync_tombstone('draft', 'user_id');
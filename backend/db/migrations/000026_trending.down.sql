@@ -0,0 +1,8 @@
+-- ============================================================================
+-- ROLLBACK - TRENDING
+-- ============================================================================
+-- Migration: 000026_trending
+-- Created: 2026-10-17
+
+DROP INDEX IF EXISTS idx_brew_created_at;
+DROP TABLE IF EXISTS trending_item;
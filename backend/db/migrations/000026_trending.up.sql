@@ -0,0 +1,26 @@
+-- ============================================================================
+-- TRENDING
+-- ============================================================================
+-- Adds the precomputed trending recipes and beans read by discovery
+-- Migration: 000026_trending
+-- Created: 2026-10-17
+
+CREATE TABLE trending_item (
+    period VARCHAR(10) NOT NULL CHECK (period IN ('day', 'week', 'month')),
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('recipe', 'bean')),
+    rank INTEGER NOT NULL CHECK (rank > 0),
+    recipe_id TEXT REFERENCES recipe(id) ON DELETE CASCADE,
+    roaster TEXT,
+    bean_origin TEXT,
+    brew_count INTEGER NOT NULL,
+    rating_count INTEGER NOT NULL,
+    avg_rating DOUBLE PRECISION,
+    score DOUBLE PRECISION NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT trending_item_kind_check CHECK ((kind = 'recipe') = (recipe_id IS NOT NULL))
+);
+
+CREATE INDEX idx_trending_item_period ON trending_item(period, kind, rank);
+
+-- Trending scans the brews logged within each period
+CREATE INDEX idx_brew_created_at ON brew(created_at);
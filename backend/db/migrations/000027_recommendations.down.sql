@@ -0,0 +1,9 @@
+-- ============================================================================
+-- ROLLBACK - RECOMMENDATIONS
+-- ============================================================================
+-- Migration: 000027_recommendations
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS recommendation_dismissal;
+DROP TABLE IF EXISTS recommendation_state;
+DROP TABLE IF EXISTS recommendation;
@@ -0,0 +1,32 @@
+-- ============================================================================
+-- RECOMMENDATIONS
+-- ============================================================================
+-- Adds per-user recipe and bean recommendations, their scoring state and
+-- "not interested" dismissals
+-- Migration: 000027_recommendations
+-- Created: 2026-10-17
+
+CREATE TABLE recommendation (
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('recipe', 'bean')),
+    item_id TEXT NOT NULL,
+    score DOUBLE PRECISION NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_recommendation_user_score ON recommendation(user_id, kind, score DESC);
+
+CREATE TABLE recommendation_state (
+    user_id TEXT PRIMARY KEY REFERENCES "user"(id) ON DELETE CASCADE,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_recommendation_state_computed_at ON recommendation_state(computed_at);
+
+CREATE TABLE recommendation_dismissal (
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('recipe', 'bean')),
+    item_id TEXT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (user_id, kind, item_id)
+);
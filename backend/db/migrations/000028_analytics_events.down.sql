@@ -0,0 +1,8 @@
+-- ============================================================================
+-- ROLLBACK - ANALYTICS EVENTS
+-- ============================================================================
+-- Migration: 000028_analytics_events
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS analytics_event;
+ALTER TABLE "user" DROP COLUMN IF EXISTS analytics_consent;
@@ -0,0 +1,21 @@
+-- ============================================================================
+-- ANALYTICS EVENTS
+-- ============================================================================
+-- Adds client analytics events and the per-user consent that gates them
+-- Migration: 000028_analytics_events
+-- Created: 2026-10-17
+
+ALTER TABLE "user" ADD COLUMN analytics_consent BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE analytics_event (
+    id TEXT PRIMARY KEY, -- ULID format
+    user_id TEXT REFERENCES "user"(id) ON DELETE CASCADE,
+    session_id VARCHAR(64),
+    name VARCHAR(64) NOT NULL,
+    properties JSONB NOT NULL DEFAULT '{}',
+    occurred_at TIMESTAMPTZ NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_analytics_event_name ON analytics_event(name, occurred_at);
+CREATE INDEX idx_analytics_event_user ON analytics_event(user_id, occurred_at);
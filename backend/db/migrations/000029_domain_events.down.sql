@@ -0,0 +1,11 @@
+-- ============================================================================
+-- ROLLBACK - DOMAIN EVENTS
+-- ============================================================================
+-- Migration: 000029_domain_events
+-- Created: 2026-10-17
+
+DROP TRIGGER IF EXISTS domain_event_recipe_forked ON recipe;
+DROP TRIGGER IF EXISTS domain_event_brew_created ON brew;
+DROP TRIGGER IF EXISTS domain_event_user_registered ON "user";
+DROP FUNCTION IF EXISTS record_domain_event();
+DROP TABLE IF EXISTS domain_event;
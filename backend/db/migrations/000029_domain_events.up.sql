@@ -0,0 +1,57 @@
+-- ============================================================================
+-- DOMAIN EVENTS
+-- ============================================================================
+-- Adds the domain event outbox and the triggers that write user.registered,
+-- brew.created and recipe.forked events to it
+-- Migration: 000029_domain_events
+-- Created: 2026-10-17
+
+CREATE TABLE domain_event (
+    id BIGSERIAL PRIMARY KEY,
+    topic VARCHAR(64) NOT NULL,
+    aggregate_id TEXT NOT NULL, -- no foreign key: outlives the row
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    published_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_domain_event_due ON domain_event(next_attempt_at, id)
+    WHERE published_at IS NULL;
+CREATE INDEX idx_domain_event_published ON domain_event(published_at)
+    WHERE published_at IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION record_domain_event()
+RETURNS TRIGGER AS $$
+DECLARE
+    row_data JSONB := to_jsonb(NEW);
+BEGIN
+    INSERT INTO domain_event (topic, aggregate_id, payload)
+    SELECT TG_ARGV[0], NEW.id, jsonb_object_agg(col, row_data->col)
+    FROM unnest(TG_ARGV[1:]) AS col;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- User table
+CREATE TRIGGER domain_event_user_registered
+AFTER INSERT ON "user"
+FOR EACH ROW
+EXECUTE FUNCTION record_domain_event('user.registered', 'id', 'username', 'created_at');
+
+-- Brew table
+CREATE TRIGGER domain_event_brew_created
+AFTER INSERT ON brew
+FOR EACH ROW
+EXECUTE FUNCTION record_domain_event('brew.created', 'id', 'created_by', 'brew_method', 'recipe_id',
+    'recipe_revision', 'bean_bag_id', 'is_public', 'created_at');
+
+-- Recipe table
+CREATE TRIGGER domain_event_recipe_forked
+AFTER INSERT ON recipe
+FOR EACH ROW
+WHEN (NEW.forked_from_id IS NOT NULL)
+EXECUTE FUNCTION record_domain_event('recipe.forked', 'id', 'owner_id', 'forked_from_id',
+    'forked_from_revision', 'is_public', 'created_at');
@@ -0,0 +1,14 @@
+-- ============================================================================
+-- ROLLBACK - OUTBOX
+-- ============================================================================
+-- Migration: 000030_outbox
+-- Created: 2026-10-17
+
+DROP TRIGGER IF EXISTS outbox_badge_earned ON user_badge;
+DROP TRIGGER IF EXISTS outbox_bean_reorder ON bean_bag;
+DROP TRIGGER IF EXISTS outbox_brew_reminder ON brew;
+DROP TRIGGER IF EXISTS outbox_brew_timer ON brew;
+DROP TRIGGER IF EXISTS outbox_brew_logged ON brew;
+DROP FUNCTION IF EXISTS outbox_brew_webhook();
+DROP FUNCTION IF EXISTS outbox_notify();
+DROP TABLE IF EXISTS outbox_message;
@@ -0,0 +1,108 @@
+-- ============================================================================
+-- OUTBOX
+-- ============================================================================
+-- Adds the notification and webhook outbox and the triggers that write to it
+-- alongside brews, reminders, reorder suggestions and badges
+-- Migration: 000030_outbox
+-- Created: 2026-10-17
+
+CREATE TABLE outbox_message (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('notification', 'webhook')),
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    dispatched_at TIMESTAMPTZ,
+    failed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_outbox_message_due ON outbox_message(next_attempt_at, id)
+    WHERE dispatched_at IS NULL AND failed_at IS NULL;
+CREATE INDEX idx_outbox_message_dispatched ON outbox_message(dispatched_at)
+    WHERE dispatched_at IS NOT NULL;
+
+-- Notifies a user about a row. Arguments: the notification type, the
+-- reference type, the column holding the user (who is also the actor) and
+-- the column holding the reference.
+CREATE OR REPLACE FUNCTION outbox_notify()
+RETURNS TRIGGER AS $$
+DECLARE
+    row_data JSONB := to_jsonb(NEW);
+BEGIN
+    INSERT INTO outbox_message (kind, payload)
+    VALUES ('notification', jsonb_build_object(
+        'recipient_user_id', row_data->>TG_ARGV[2],
+        'actor_user_id', row_data->>TG_ARGV[2],
+        'type', TG_ARGV[0],
+        'reference_type', TG_ARGV[1],
+        'reference_id', row_data->>TG_ARGV[3]
+    ));
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Fires the brew owner's webhooks when a brew is logged and when its timer
+-- starts or stops
+CREATE OR REPLACE FUNCTION outbox_brew_webhook()
+RETURNS TRIGGER AS $$
+DECLARE
+    webhook_event TEXT;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        webhook_event := 'brew.logged';
+    ELSIF NEW.started_at IS DISTINCT FROM OLD.started_at
+        AND NEW.started_at IS NOT NULL AND NEW.ended_at IS NULL THEN
+        webhook_event := 'brew.started';
+    ELSIF OLD.started_at IS NOT NULL AND OLD.ended_at IS NULL AND NEW.ended_at IS NOT NULL THEN
+        webhook_event := 'brew.stopped';
+    ELSE
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO outbox_message (kind, payload)
+    VALUES ('webhook', jsonb_build_object(
+        'user_id', NEW.created_by,
+        'event', webhook_event,
+        'occurred_at', NOW(),
+        'brew_id', NEW.id,
+        'brew_name', NEW.name,
+        'brew_method', NEW.brew_method,
+        'brew_time_seconds', NEW.brew_time_seconds
+    ));
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Brew table
+CREATE TRIGGER outbox_brew_logged
+AFTER INSERT ON brew
+FOR EACH ROW
+WHEN (NEW.created_by IS NOT NULL)
+EXECUTE FUNCTION outbox_brew_webhook();
+
+CREATE TRIGGER outbox_brew_timer
+AFTER UPDATE OF started_at, ended_at ON brew
+FOR EACH ROW
+WHEN (NEW.created_by IS NOT NULL)
+EXECUTE FUNCTION outbox_brew_webhook();
+
+CREATE TRIGGER outbox_brew_reminder
+AFTER UPDATE OF reminder_sent_at ON brew
+FOR EACH ROW
+WHEN (OLD.reminder_sent_at IS NULL AND NEW.reminder_sent_at IS NOT NULL AND NEW.created_by IS NOT NULL)
+EXECUTE FUNCTION outbox_notify('brew_reminder', 'brew', 'created_by', 'id');
+
+-- Bean bag table
+CREATE TRIGGER outbox_bean_reorder
+AFTER UPDATE OF reorder_notified_at ON bean_bag
+FOR EACH ROW
+WHEN (OLD.reorder_notified_at IS NULL AND NEW.reorder_notified_at IS NOT NULL)
+EXECUTE FUNCTION outbox_notify('bean_reorder', 'bean_bag', 'owner_id', 'id');
+
+-- User badge table
+CREATE TRIGGER outbox_badge_earned
+AFTER INSERT ON user_badge
+FOR EACH ROW
+EXECUTE FUNCTION outbox_notify('badge_earned', 'challenge', 'user_id', 'challenge_id');
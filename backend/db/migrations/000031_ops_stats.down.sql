@@ -0,0 +1,9 @@
+-- ============================================================================
+-- ROLLBACK - OPS STATS
+-- ============================================================================
+-- Migration: 000031_ops_stats
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS ops_queue_stat;
+DROP TABLE IF EXISTS ops_method_stat;
+DROP TABLE IF EXISTS ops_daily_stat;
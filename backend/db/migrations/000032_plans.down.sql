@@ -0,0 +1,7 @@
+-- ============================================================================
+-- ROLLBACK - PLANS
+-- ============================================================================
+-- Migration: 000032_plans
+-- Created: 2026-10-17
+
+ALTER TABLE "user" DROP COLUMN IF EXISTS plan;
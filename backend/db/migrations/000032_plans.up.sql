@@ -0,0 +1,9 @@
+-- ============================================================================
+-- PLANS
+-- ============================================================================
+-- Adds the plan tier that sets each user's quotas
+-- Migration: 000032_plans
+-- Created: 2026-10-17
+
+ALTER TABLE "user" ADD COLUMN plan VARCHAR(20) NOT NULL DEFAULT 'free'
+    CHECK (plan IN ('free', 'supporter'));
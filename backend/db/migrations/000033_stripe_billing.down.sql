@@ -0,0 +1,15 @@
+-- ============================================================================
+-- ROLLBACK - STRIPE BILLING
+-- ============================================================================
+-- Migration: 000033_stripe_billing
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS stripe_event;
+ALTER TABLE "user"
+    DROP COLUMN IF EXISTS subscription_event_at,
+    DROP COLUMN IF EXISTS subscription_cancel_at_period_end,
+    DROP COLUMN IF EXISTS subscription_period_end,
+    DROP COLUMN IF EXISTS subscription_status,
+    DROP COLUMN IF EXISTS subscription_id,
+    DROP COLUMN IF EXISTS subscription_provider,
+    DROP COLUMN IF EXISTS stripe_customer_id;
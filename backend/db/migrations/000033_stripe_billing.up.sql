@@ -0,0 +1,22 @@
+-- ============================================================================
+-- STRIPE BILLING
+-- ============================================================================
+-- Adds Stripe customers, subscription state on users and processed webhook
+-- events
+-- Migration: 000033_stripe_billing
+-- Created: 2026-10-17
+
+ALTER TABLE "user"
+    ADD COLUMN stripe_customer_id TEXT UNIQUE,
+    ADD COLUMN subscription_provider VARCHAR(20) CHECK (subscription_provider IN ('stripe')),
+    ADD COLUMN subscription_id TEXT,
+    ADD COLUMN subscription_status VARCHAR(30),
+    ADD COLUMN subscription_period_end TIMESTAMPTZ,
+    ADD COLUMN subscription_cancel_at_period_end BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN subscription_event_at TIMESTAMPTZ;
+
+CREATE TABLE stripe_event (
+    id TEXT PRIMARY KEY, -- Stripe event ID (evt_...)
+    type VARCHAR(100) NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
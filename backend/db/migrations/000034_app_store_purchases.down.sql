@@ -0,0 +1,23 @@
+-- ============================================================================
+-- ROLLBACK - APP STORE PURCHASES
+-- ============================================================================
+-- Migration: 000034_app_store_purchases
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS iap_purchase;
+
+-- App store subscribers return to the free plan
+UPDATE "user"
+SET
+    plan = 'free',
+    subscription_provider = NULL,
+    subscription_id = NULL,
+    subscription_status = NULL,
+    subscription_period_end = NULL,
+    subscription_cancel_at_period_end = false,
+    subscription_event_at = NULL
+WHERE subscription_provider IN ('apple', 'google');
+
+ALTER TABLE "user" DROP CONSTRAINT user_subscription_provider_check;
+ALTER TABLE "user" ADD CONSTRAINT user_subscription_provider_check
+    CHECK (subscription_provider IN ('stripe'));
@@ -0,0 +1,32 @@
+-- ============================================================================
+-- APP STORE PURCHASES
+-- ============================================================================
+-- Adds App Store and Google Play subscriptions as providers of the
+-- supporter plan
+-- Migration: 000034_app_store_purchases
+-- Created: 2026-10-17
+
+ALTER TABLE "user" DROP CONSTRAINT user_subscription_provider_check;
+ALTER TABLE "user" ADD CONSTRAINT user_subscription_provider_check
+    CHECK (subscription_provider IN ('stripe', 'apple', 'google'));
+
+CREATE TABLE iap_purchase (
+    provider VARCHAR(20) NOT NULL CHECK (provider IN ('apple', 'google')),
+    -- Apple original transaction ID or Google purchase token
+    purchase_id TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    product_id TEXT NOT NULL,
+    receipt TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('active', 'grace_period', 'billing_retry', 'expired', 'revoked')),
+    expires_at TIMESTAMPTZ NOT NULL,
+    grace_until TIMESTAMPTZ,
+    auto_renew BOOLEAN NOT NULL,
+    verified_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (provider, purchase_id)
+);
+
+CREATE INDEX idx_iap_purchase_user_id ON iap_purchase(user_id);
+-- Renewal checks look for live purchases whose paid or grace period ends
+CREATE INDEX idx_iap_purchase_renewal ON iap_purchase(COALESCE(grace_until, expires_at))
+    WHERE status IN ('active', 'grace_period', 'billing_retry');
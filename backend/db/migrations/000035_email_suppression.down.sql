@@ -0,0 +1,7 @@
+-- ============================================================================
+-- ROLLBACK - EMAIL SUPPRESSION
+-- ============================================================================
+-- Migration: 000035_email_suppression
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS email_suppression;
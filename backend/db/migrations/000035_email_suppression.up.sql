@@ -0,0 +1,21 @@
+-- ============================================================================
+-- EMAIL SUPPRESSION
+-- ============================================================================
+-- Adds the list of addresses mail is no longer sent to
+-- Migration: 000035_email_suppression
+-- Created: 2026-10-17
+
+-- Email suppression table
+-- Addresses mail is no longer sent to: hard bounces and spam complaints
+-- reported by the mail provider's webhooks, or added by an admin. Checked
+-- by internal/mail before every send.
+CREATE TABLE email_suppression (
+    email VARCHAR(254) PRIMARY KEY, -- lowercased
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('bounce', 'complaint', 'manual')),
+    -- Mail provider that reported it, for bounces and complaints
+    provider VARCHAR(20),
+    detail TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_email_suppression_created_at ON email_suppression(created_at);
@@ -0,0 +1,15 @@
+-- ============================================================================
+-- ROLLBACK - INVITES
+-- ============================================================================
+-- Migration: 000036_invites
+-- Created: 2026-10-17
+
+DROP FUNCTION IF EXISTS create_invite(TEXT, VARCHAR, INTEGER, TIMESTAMPTZ, INTEGER);
+
+DROP TABLE IF EXISTS invite;
+
+DROP INDEX IF EXISTS idx_user_invited_by;
+
+ALTER TABLE "user"
+    DROP COLUMN IF EXISTS invited_by,
+    DROP COLUMN IF EXISTS invite_code;
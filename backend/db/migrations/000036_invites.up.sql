@@ -0,0 +1,60 @@
+-- ============================================================================
+-- INVITES
+-- ============================================================================
+-- Adds invite codes and records which invite, and whose, each user signed
+-- up with
+-- Migration: 000036_invites
+-- Created: 2026-10-17
+
+ALTER TABLE "user"
+    ADD COLUMN invite_code VARCHAR(16),
+    ADD COLUMN invited_by TEXT REFERENCES "user"(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_user_invited_by ON "user"(invited_by, joined_at DESC) WHERE invited_by IS NOT NULL;
+
+-- Invite table
+-- Invite codes users hand out, shared as links to the web app's signup
+-- page. Each can be redeemed max_uses times; registering with one records
+-- the invite and its creator on the new user for referral stats.
+CREATE TABLE invite (
+    code VARCHAR(16) PRIMARY KEY,
+    inviter_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    max_uses INTEGER NOT NULL DEFAULT 1 CHECK (max_uses > 0),
+    use_count INTEGER NOT NULL DEFAULT 0 CHECK (use_count <= max_uses),
+    expires_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_invite_inviter ON invite(inviter_id, created_at DESC);
+
+-- Creates an invite for inviter unless its uses would take them past quota
+-- (NULL for none), returning no row then. An invite's uses count until it
+-- is revoked or expires; then only those redeemed do. The inviter's row is
+-- locked first, so concurrent creations for one user run one at a time,
+-- and each statement after the lock sees the invites the last committed.
+CREATE OR REPLACE FUNCTION create_invite(inviter TEXT, new_code VARCHAR, uses INTEGER, expires TIMESTAMPTZ, quota INTEGER)
+RETURNS SETOF invite AS $$
+DECLARE
+    allotted BIGINT;
+BEGIN
+    PERFORM 1 FROM "user" WHERE id = inviter FOR NO KEY UPDATE;
+    IF quota IS NOT NULL THEN
+        SELECT COALESCE(SUM(
+            CASE
+                WHEN i.revoked_at IS NULL AND (i.expires_at IS NULL OR i.expires_at > NOW()) THEN i.max_uses
+                ELSE i.use_count
+            END
+        ), 0) INTO allotted
+        FROM invite i
+        WHERE i.inviter_id = inviter;
+        IF allotted + uses > quota THEN
+            RETURN;
+        END IF;
+    END IF;
+    RETURN QUERY
+    INSERT INTO invite (code, inviter_id, max_uses, expires_at)
+    VALUES (new_code, inviter, uses, expires)
+    RETURNING *;
+END;
+$$ LANGUAGE plpgsql;
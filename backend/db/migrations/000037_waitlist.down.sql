@@ -0,0 +1,7 @@
+-- ============================================================================
+-- ROLLBACK - WAITLIST
+-- ============================================================================
+-- Migration: 000037_waitlist
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS waitlist_entry;
@@ -0,0 +1,27 @@
+-- ============================================================================
+-- WAITLIST
+-- ============================================================================
+-- Adds the registration waitlist
+-- Migration: 000037_waitlist
+-- Created: 2026-10-17
+
+-- Waitlist entry table
+-- Emails queued by registration in waitlist mode. Admins approve them in
+-- batches, which creates a single-use invite for each; the waitlist worker
+-- then mails the invite link.
+CREATE TABLE waitlist_entry (
+    email VARCHAR(254) PRIMARY KEY, -- lowercased
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    approved_at TIMESTAMPTZ,
+    approved_by TEXT REFERENCES "user"(id) ON DELETE SET NULL,
+    invite_code VARCHAR(16) REFERENCES invite(code) ON DELETE SET NULL,
+    -- Invitation email delivery; failed sends are retried a few times
+    invite_sent_at TIMESTAMPTZ,
+    send_attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT
+);
+
+CREATE INDEX idx_waitlist_entry_pending ON waitlist_entry(created_at) WHERE approved_at IS NULL;
+CREATE INDEX idx_waitlist_entry_approved ON waitlist_entry(approved_at DESC) WHERE approved_at IS NOT NULL;
+CREATE INDEX idx_waitlist_entry_unsent ON waitlist_entry(approved_at)
+    WHERE approved_at IS NOT NULL AND invite_sent_at IS NULL;
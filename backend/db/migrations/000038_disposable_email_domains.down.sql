@@ -0,0 +1,12 @@
+-- ============================================================================
+-- ROLLBACK - DISPOSABLE EMAIL DOMAINS
+-- ============================================================================
+-- Migration: 000038_disposable_email_domains
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS email_domain_override;
+DROP TABLE IF EXISTS disposable_domain;
+
+DROP INDEX IF EXISTS idx_user_disposable_email;
+
+ALTER TABLE "user" DROP COLUMN IF EXISTS disposable_email;
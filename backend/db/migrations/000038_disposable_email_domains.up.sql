@@ -0,0 +1,30 @@
+-- ============================================================================
+-- DISPOSABLE EMAIL DOMAINS
+-- ============================================================================
+-- Adds the disposable email domain blocklist, admin overrides, and the flag
+-- on users who registered with a listed domain
+-- Migration: 000038_disposable_email_domains
+-- Created: 2026-10-17
+
+ALTER TABLE "user" ADD COLUMN disposable_email BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX idx_user_disposable_email ON "user"(joined_at DESC) WHERE disposable_email;
+
+-- Disposable domain table
+-- Throwaway email domains registration rejects or flags. Replaced on each
+-- refresh with the built-in list plus the remote blocklist.
+CREATE TABLE disposable_domain (
+    domain VARCHAR(253) PRIMARY KEY -- lowercased
+);
+
+-- Email domain override table
+-- Admin decisions that take precedence over the disposable list: allow a
+-- listed domain, or block one the list misses. Subdomains inherit the most
+-- specific override.
+CREATE TABLE email_domain_override (
+    domain VARCHAR(253) PRIMARY KEY, -- lowercased
+    action VARCHAR(10) NOT NULL CHECK (action IN ('allow', 'block')),
+    note TEXT,
+    created_by TEXT REFERENCES "user"(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
@@ -0,0 +1,7 @@
+-- ============================================================================
+-- ROLLBACK - AUTH EVENTS
+-- ============================================================================
+-- Migration: 000039_auth_events
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS auth_event;
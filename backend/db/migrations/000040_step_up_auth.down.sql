@@ -0,0 +1,18 @@
+-- ============================================================================
+-- ROLLBACK - STEP-UP AUTH
+-- ============================================================================
+-- Migration: 000040_step_up_auth
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS trusted_device;
+DROP TABLE IF EXISTS step_up_challenge;
+
+DELETE FROM auth_event WHERE event IN ('step_up', 'step_up_failed');
+ALTER TABLE auth_event DROP CONSTRAINT auth_event_event_check;
+ALTER TABLE auth_event ADD CONSTRAINT auth_event_event_check
+    CHECK (event IN ('register', 'login', 'login_failed'));
+
+ALTER TABLE "user"
+    DROP COLUMN IF EXISTS totp_last_step,
+    DROP COLUMN IF EXISTS totp_enabled_at,
+    DROP COLUMN IF EXISTS totp_secret;
@@ -0,0 +1,8 @@
+-- ============================================================================
+-- ROLLBACK - OIDC PROVIDER
+-- ============================================================================
+-- Migration: 000041_oidc_provider
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS oidc_auth_code;
+DROP TABLE IF EXISTS oidc_client;
@@ -0,0 +1,39 @@
+-- ============================================================================
+-- OIDC PROVIDER
+-- ============================================================================
+-- Adds the clients and authorization codes of brewd's OIDC provider, for
+-- companion services to sign users in with
+-- Migration: 000041_oidc_provider
+-- Created: 2026-10-17
+
+-- OIDC client table
+-- Companion services (the community forum, the stats site) that let users
+-- sign in with brewd. Admins register them; only a SHA-256 hash of the
+-- client secret is stored. Redirect URIs must match exactly.
+CREATE TABLE oidc_client (
+    id TEXT PRIMARY KEY, -- ULID format, the client_id
+    name VARCHAR(100) NOT NULL,
+    secret_hash VARCHAR(64) NOT NULL,
+    redirect_uris TEXT[] NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- OIDC authorization code table
+-- Codes issued to a client when a user signs in to it, until the client
+-- redeems them for tokens. Only a hash is stored; a code is single use and
+-- short-lived. code_challenge is the client's PKCE S256 challenge, if it
+-- sent one.
+CREATE TABLE oidc_auth_code (
+    code_hash VARCHAR(64) PRIMARY KEY,
+    client_id TEXT NOT NULL REFERENCES oidc_client(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    redirect_uri TEXT NOT NULL,
+    scopes TEXT[] NOT NULL,
+    nonce TEXT,
+    code_challenge VARCHAR(128),
+    auth_time TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_oidc_auth_code_expires_at ON oidc_auth_code(expires_at);
@@ -0,0 +1,10 @@
+-- ============================================================================
+-- ROLLBACK - SCIM PROVISIONING
+-- ============================================================================
+-- Migration: 000042_scim_provisioning
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS scim_user;
+DROP TABLE IF EXISTS scim_client;
+
+ALTER TABLE "user" DROP COLUMN IF EXISTS deactivated_at;
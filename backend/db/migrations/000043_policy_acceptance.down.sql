@@ -0,0 +1,8 @@
+-- ============================================================================
+-- ROLLBACK - POLICY ACCEPTANCE
+-- ============================================================================
+-- Migration: 000043_policy_acceptance
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS policy_acceptance;
+DROP TABLE IF EXISTS policy_document;
@@ -0,0 +1,38 @@
+-- ============================================================================
+-- POLICY ACCEPTANCE
+-- ============================================================================
+-- Adds versioned terms of service and privacy policy documents, and each
+-- user's acceptance of them
+-- Migration: 000043_policy_acceptance
+-- Created: 2026-10-17
+
+-- Policy document table
+-- Published versions of the terms of service and privacy policy. The
+-- current version of each kind is the latest one whose published_at has
+-- passed, so a version can be scheduled ahead of time. Versions are never
+-- edited; a change is published as a new version.
+CREATE TABLE policy_document (
+    id TEXT PRIMARY KEY, -- ULID format
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('terms', 'privacy')),
+    version VARCHAR(20) NOT NULL,
+    url TEXT NOT NULL,
+    summary TEXT,
+    published_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (kind, version)
+);
+
+CREATE INDEX idx_policy_document_kind ON policy_document(kind, published_at DESC);
+
+-- Policy acceptance table
+-- Which versions each user accepted, when and from where, as the record
+-- that they agreed to them
+CREATE TABLE policy_acceptance (
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    document_id TEXT NOT NULL REFERENCES policy_document(id) ON DELETE CASCADE,
+    ip VARCHAR(45),
+    accepted_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (user_id, document_id)
+);
+
+CREATE INDEX idx_policy_acceptance_document ON policy_acceptance(document_id);
@@ -0,0 +1,10 @@
+-- ============================================================================
+-- ROLLBACK - AGE GATE
+-- ============================================================================
+-- Migration: 000044_age_gate
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS parental_consent;
+
+ALTER TABLE "user" DROP COLUMN IF EXISTS consent_pending;
+ALTER TABLE "user" DROP COLUMN IF EXISTS birth_date;
@@ -0,0 +1,24 @@
+-- ============================================================================
+-- AGE GATE
+-- ============================================================================
+-- Adds users' birth dates, the blocked state of users awaiting parental
+-- consent, and the consent requests sent to their parents
+-- Migration: 000044_age_gate
+-- Created: 2026-10-17
+
+ALTER TABLE "user" ADD COLUMN birth_date DATE;
+ALTER TABLE "user" ADD COLUMN consent_pending BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Parental consent table
+-- Consent requests sent to the parents of users who signed up under the
+-- minimum age. Only a SHA-256 hash of the emailed token is stored. Once
+-- granted, the row is kept as the record of consent.
+CREATE TABLE parental_consent (
+    user_id TEXT PRIMARY KEY REFERENCES "user"(id) ON DELETE CASCADE,
+    parent_email VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    granted_at TIMESTAMPTZ,
+    granted_ip VARCHAR(45),
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
@@ -0,0 +1,7 @@
+-- ============================================================================
+-- ROLLBACK - RESOURCE SHARING
+-- ============================================================================
+-- Migration: 000045_resource_sharing
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS resource_share;
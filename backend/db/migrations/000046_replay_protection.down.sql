@@ -0,0 +1,7 @@
+-- ============================================================================
+-- ROLLBACK - REPLAY PROTECTION
+-- ============================================================================
+-- Migration: 000046_replay_protection
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS request_nonce;
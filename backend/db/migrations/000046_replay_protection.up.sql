@@ -0,0 +1,21 @@
+-- ============================================================================
+-- REPLAY PROTECTION
+-- ============================================================================
+-- Adds the nonces of signed inbound requests, so a captured webhook or
+-- device request can't be replayed
+-- Migration: 000046_replay_protection
+-- Created: 2026-10-17
+
+-- Request nonce table
+-- Nonces of signed inbound requests (Stripe webhooks, device bridges), kept
+-- until their timestamp falls outside the clock-skew tolerance, after which
+-- the timestamp check rejects a replay on its own. Nonces are stored hashed,
+-- scoped by source so senders can't collide.
+CREATE TABLE request_nonce (
+    source VARCHAR(50) NOT NULL,
+    nonce_hash VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (source, nonce_hash)
+);
+
+CREATE INDEX idx_request_nonce_expires_at ON request_nonce(expires_at);
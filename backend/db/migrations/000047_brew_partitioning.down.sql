@@ -0,0 +1,98 @@
+-- ============================================================================
+-- ROLLBACK - BREW PARTITIONING
+-- ============================================================================
+-- Copies brews back into an unpartitioned table. Partitions already
+-- detached (detached_brew_p*) are left as they are.
+-- Migration: 000047_brew_partitioning
+-- Created: 2026-10-17
+
+ALTER TABLE post DROP CONSTRAINT post_brew_id_fkey;
+ALTER TABLE brew_flavor DROP CONSTRAINT brew_flavor_brew_id_fkey;
+ALTER TABLE club_share DROP CONSTRAINT club_share_brew_id_fkey;
+ALTER TABLE tds_reading DROP CONSTRAINT tds_reading_brew_id_fkey;
+ALTER TABLE pour_curve DROP CONSTRAINT pour_curve_brew_id_fkey;
+ALTER TABLE resource_share DROP CONSTRAINT resource_share_brew_id_fkey;
+
+ALTER TABLE brew RENAME TO brew_partitioned;
+
+CREATE TABLE brew (LIKE brew_partitioned INCLUDING DEFAULTS INCLUDING CONSTRAINTS);
+ALTER TABLE brew ALTER COLUMN id TYPE TEXT COLLATE "default";
+
+-- Copied before brew has triggers, so rows keep their sync state
+INSERT INTO brew SELECT * FROM brew_partitioned;
+
+DROP TABLE brew_partitioned;
+
+DROP FUNCTION IF EXISTS detach_brew_partition(TEXT);
+DROP FUNCTION IF EXISTS ensure_brew_partition(DATE);
+DROP FUNCTION IF EXISTS ulid_floor(TIMESTAMPTZ);
+
+ALTER TABLE brew ADD PRIMARY KEY (id);
+ALTER TABLE brew
+    ADD CONSTRAINT brew_created_by_fkey FOREIGN KEY (created_by) REFERENCES "user"(id),
+    ADD CONSTRAINT brew_recipe_revision_fkey FOREIGN KEY (recipe_id, recipe_revision)
+        REFERENCES recipe_revision(recipe_id, revision) ON DELETE SET NULL,
+    ADD CONSTRAINT brew_bean_bag_id_fkey FOREIGN KEY (bean_bag_id) REFERENCES bean_bag(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_brew_created_by ON brew(created_by);
+CREATE INDEX idx_brew_is_public ON brew(is_public);
+CREATE INDEX idx_brew_name ON brew(name);
+CREATE INDEX idx_brew_method ON brew(brew_method);
+CREATE INDEX idx_brew_recipe ON brew(recipe_id, recipe_revision);
+CREATE INDEX idx_brew_bean_bag ON brew(bean_bag_id, created_at);
+CREATE INDEX idx_brew_remind_at ON brew(remind_at) WHERE reminder_sent_at IS NULL;
+CREATE INDEX idx_brew_sync ON brew(created_by, sync_seq);
+CREATE INDEX idx_brew_created_at ON brew(created_at);
+
+CREATE TRIGGER update_brew_updated_at
+BEFORE UPDATE ON brew
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER sync_track_brew
+BEFORE INSERT OR UPDATE ON brew
+FOR EACH ROW
+EXECUTE FUNCTION sync_track('created_by');
+
+CREATE TRIGGER sync_tombstone_brew
+AFTER DELETE ON brew
+FOR EACH ROW
+WHEN (OLD.created_by IS NOT NULL)
+EXECUTE FUNCTION sync_tombstone('brew', 'created_by');
+
+CREATE TRIGGER domain_event_brew_created
+AFTER INSERT ON brew
+FOR EACH ROW
+EXECUTE FUNCTION record_domain_event('brew.created', 'id', 'created_by', 'brew_method', 'recipe_id',
+    'recipe_revision', 'bean_bag_id', 'is_public', 'created_at');
+
+CREATE TRIGGER outbox_brew_logged
+AFTER INSERT ON brew
+FOR EACH ROW
+WHEN (NEW.created_by IS NOT NULL)
+EXECUTE FUNCTION outbox_brew_webhook();
+
+CREATE TRIGGER outbox_brew_timer
+AFTER UPDATE OF started_at, ended_at ON brew
+FOR EACH ROW
+WHEN (NEW.created_by IS NOT NULL)
+EXECUTE FUNCTION outbox_brew_webhook();
+
+CREATE TRIGGER outbox_brew_reminder
+AFTER UPDATE OF reminder_sent_at ON brew
+FOR EACH ROW
+WHEN (OLD.reminder_sent_at IS NULL AND NEW.reminder_sent_at IS NOT NULL AND NEW.created_by IS NOT NULL)
+EXECUTE FUNCTION outbox_notify('brew_reminder', 'brew', 'created_by', 'id');
+
+ALTER TABLE post ADD CONSTRAINT post_brew_id_fkey
+    FOREIGN KEY (brew_id) REFERENCES brew(id);
+ALTER TABLE brew_flavor ADD CONSTRAINT brew_flavor_brew_id_fkey
+    FOREIGN KEY (brew_id) REFERENCES brew(id) ON DELETE CASCADE;
+ALTER TABLE club_share ADD CONSTRAINT club_share_brew_id_fkey
+    FOREIGN KEY (brew_id) REFERENCES brew(id) ON DELETE CASCADE;
+ALTER TABLE tds_reading ADD CONSTRAINT tds_reading_brew_id_fkey
+    FOREIGN KEY (brew_id) REFERENCES brew(id) ON DELETE SET NULL;
+ALTER TABLE pour_curve ADD CONSTRAINT pour_curve_brew_id_fkey
+    FOREIGN KEY (brew_id) REFERENCES brew(id) ON DELETE CASCADE;
+ALTER TABLE resource_share ADD CONSTRAINT resource_share_brew_id_fkey
+    FOREIGN KEY (brew_id) REFERENCES brew(id) ON DELETE CASCADE;
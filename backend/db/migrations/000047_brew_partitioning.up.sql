@@ -0,0 +1,185 @@
+-- ============================================================================
+-- BREW PARTITIONING
+-- ============================================================================
+-- Partitions brews by month on their ULID, so heavy users and pour curve
+-- data don't grow one unwieldy table. The existing table is kept, without
+-- rewriting it, as the partition of brews created up to the end of this
+-- month; later months get their own partitions.
+-- Migration: 000047_brew_partitioning
+-- Created: 2026-10-17
+
+-- Foreign keys to brew are recreated against the partitioned table
+ALTER TABLE post DROP CONSTRAINT post_brew_id_fkey;
+ALTER TABLE brew_flavor DROP CONSTRAINT brew_flavor_brew_id_fkey;
+ALTER TABLE club_share DROP CONSTRAINT club_share_brew_id_fkey;
+ALTER TABLE tds_reading DROP CONSTRAINT tds_reading_brew_id_fkey;
+ALTER TABLE pour_curve DROP CONSTRAINT pour_curve_brew_id_fkey;
+ALTER TABLE resource_share DROP CONSTRAINT resource_share_brew_id_fkey;
+
+-- Triggers move to the partitioned table, which runs them for every
+-- partition
+DROP TRIGGER IF EXISTS update_brew_updated_at ON brew;
+DROP TRIGGER IF EXISTS sync_track_brew ON brew;
+DROP TRIGGER IF EXISTS sync_tombstone_brew ON brew;
+DROP TRIGGER IF EXISTS domain_event_brew_created ON brew;
+DROP TRIGGER IF EXISTS outbox_brew_logged ON brew;
+DROP TRIGGER IF EXISTS outbox_brew_timer ON brew;
+DROP TRIGGER IF EXISTS outbox_brew_reminder ON brew;
+
+-- The existing table becomes brew_legacy. Its indexes are renamed so the
+-- partitioned table's can take their names, then adopt them.
+ALTER TABLE brew RENAME TO brew_legacy;
+ALTER TABLE brew_legacy RENAME CONSTRAINT brew_pkey TO brew_legacy_pkey;
+ALTER INDEX idx_brew_created_by RENAME TO brew_legacy_created_by_idx;
+ALTER INDEX idx_brew_is_public RENAME TO brew_legacy_is_public_idx;
+ALTER INDEX idx_brew_name RENAME TO brew_legacy_name_idx;
+ALTER INDEX idx_brew_method RENAME TO brew_legacy_method_idx;
+ALTER INDEX idx_brew_recipe RENAME TO brew_legacy_recipe_idx;
+ALTER INDEX idx_brew_bean_bag RENAME TO brew_legacy_bean_bag_idx;
+ALTER INDEX idx_brew_remind_at RENAME TO brew_legacy_remind_at_idx;
+ALTER INDEX idx_brew_sync RENAME TO brew_legacy_sync_idx;
+ALTER INDEX idx_brew_created_at RENAME TO brew_legacy_created_at_idx;
+
+-- IDs are compared bytewise, as ULIDs order, whatever the database's
+-- collation
+ALTER TABLE brew_legacy ALTER COLUMN id TYPE TEXT COLLATE "C";
+
+-- Brew table
+-- Same columns, defaults and checks as before, partitioned by id
+CREATE TABLE brew (LIKE brew_legacy INCLUDING DEFAULTS INCLUDING CONSTRAINTS)
+PARTITION BY RANGE (id);
+
+-- Lowest ULID with the timestamp ts: its 48-bit millisecond time in
+-- Crockford base32, then zero randomness. Brew partitions are bounded by
+-- these.
+CREATE OR REPLACE FUNCTION ulid_floor(ts TIMESTAMPTZ)
+RETURNS TEXT AS $$
+DECLARE
+    alphabet CONSTANT TEXT := '0123456789ABCDEFGHJKMNPQRSTVWXYZ';
+    ms BIGINT := floor(extract(epoch FROM ts) * 1000);
+    encoded TEXT := '';
+BEGIN
+    FOR i IN 1..10 LOOP
+        encoded := substr(alphabet, (ms % 32)::int + 1, 1) || encoded;
+        ms := ms / 32;
+    END LOOP;
+    RETURN encoded || repeat('0', 16);
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- Creates the partition of brews created in the UTC month containing
+-- month, brew_pYYYY_MM, unless it exists. Returns whether it was created.
+-- Instances maintaining partitions at once are serialized by an advisory
+-- lock.
+CREATE OR REPLACE FUNCTION ensure_brew_partition(month DATE)
+RETURNS BOOLEAN AS $$
+DECLARE
+    month_start TIMESTAMPTZ := date_trunc('month', month::timestamp) AT TIME ZONE 'UTC';
+    partition_name TEXT := 'brew_p' || to_char(month, 'YYYY_MM');
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('brew_partition'));
+    IF to_regclass(partition_name) IS NOT NULL THEN
+        RETURN false;
+    END IF;
+    EXECUTE format('CREATE TABLE %I PARTITION OF brew FOR VALUES FROM (%L) TO (%L)',
+        partition_name, ulid_floor(month_start), ulid_floor(month_start + INTERVAL '1 month'));
+    RETURN true;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Detaches the brew partition brew_pYYYY_MM from brew and renames it
+-- detached_brew_pYYYY_MM, keeping its rows out of the table for operators
+-- to dump and drop. Fails with a foreign key violation while other rows
+-- (flavors, pour curves, posts, shares) still reference its brews. Returns
+-- the detached table's name.
+CREATE OR REPLACE FUNCTION detach_brew_partition(partition_name TEXT)
+RETURNS TEXT AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('brew_partition'));
+    EXECUTE format('ALTER TABLE brew DETACH PARTITION %I', partition_name);
+    EXECUTE format('ALTER TABLE %I RENAME TO %I', partition_name, 'detached_' || partition_name);
+    RETURN 'detached_' || partition_name;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Existing brews, and those created in the rest of this month
+ALTER TABLE brew ATTACH PARTITION brew_legacy
+    FOR VALUES FROM (MINVALUE) TO (ulid_floor(date_trunc('month', NOW() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' + INTERVAL '1 month'));
+
+-- Keys and indexes on the partitioned table adopt brew_legacy's
+ALTER TABLE brew ADD PRIMARY KEY (id);
+ALTER TABLE brew
+    ADD CONSTRAINT brew_created_by_fkey FOREIGN KEY (created_by) REFERENCES "user"(id),
+    ADD CONSTRAINT brew_recipe_revision_fkey FOREIGN KEY (recipe_id, recipe_revision)
+        REFERENCES recipe_revision(recipe_id, revision) ON DELETE SET NULL,
+    ADD CONSTRAINT brew_bean_bag_id_fkey FOREIGN KEY (bean_bag_id) REFERENCES bean_bag(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_brew_created_by ON brew(created_by);
+CREATE INDEX idx_brew_is_public ON brew(is_public);
+CREATE INDEX idx_brew_name ON brew(name);
+CREATE INDEX idx_brew_method ON brew(brew_method);
+CREATE INDEX idx_brew_recipe ON brew(recipe_id, recipe_revision);
+CREATE INDEX idx_brew_bean_bag ON brew(bean_bag_id, created_at);
+CREATE INDEX idx_brew_remind_at ON brew(remind_at) WHERE reminder_sent_at IS NULL;
+CREATE INDEX idx_brew_sync ON brew(created_by, sync_seq);
+CREATE INDEX idx_brew_created_at ON brew(created_at);
+
+-- Brews no partition covers, should maintenance fall behind
+CREATE TABLE brew_default PARTITION OF brew DEFAULT;
+
+-- The next three months; the maintenance job keeps creating them ahead
+SELECT ensure_brew_partition((date_trunc('month', NOW() AT TIME ZONE 'UTC') + make_interval(months => n))::date)
+FROM generate_series(1, 3) AS n;
+
+CREATE TRIGGER update_brew_updated_at
+BEFORE UPDATE ON brew
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER sync_track_brew
+BEFORE INSERT OR UPDATE ON brew
+FOR EACH ROW
+EXECUTE FUNCTION sync_track('created_by');
+
+CREATE TRIGGER sync_tombstone_brew
+AFTER DELETE ON brew
+FOR EACH ROW
+WHEN (OLD.created_by IS NOT NULL)
+EXECUTE FUNCTION sync_tombstone('brew', 'created_by');
+
+CREATE TRIGGER domain_event_brew_created
+AFTER INSERT ON brew
+FOR EACH ROW
+EXECUTE FUNCTION record_domain_event('brew.created', 'id', 'created_by', 'brew_method', 'recipe_id',
+    'recipe_revision', 'bean_bag_id', 'is_public', 'created_at');
+
+CREATE TRIGGER outbox_brew_logged
+AFTER INSERT ON brew
+FOR EACH ROW
+WHEN (NEW.created_by IS NOT NULL)
+EXECUTE FUNCTION outbox_brew_webhook();
+
+CREATE TRIGGER outbox_brew_timer
+AFTER UPDATE OF started_at, ended_at ON brew
+FOR EACH ROW
+WHEN (NEW.created_by IS NOT NULL)
+EXECUTE FUNCTION outbox_brew_webhook();
+
+CREATE TRIGGER outbox_brew_reminder
+AFTER UPDATE OF reminder_sent_at ON brew
+FOR EACH ROW
+WHEN (OLD.reminder_sent_at IS NULL AND NEW.reminder_sent_at IS NOT NULL AND NEW.created_by IS NOT NULL)
+EXECUTE FUNCTION outbox_notify('brew_reminder', 'brew', 'created_by', 'id');
+
+ALTER TABLE post ADD CONSTRAINT post_brew_id_fkey
+    FOREIGN KEY (brew_id) REFERENCES brew(id);
+ALTER TABLE brew_flavor ADD CONSTRAINT brew_flavor_brew_id_fkey
+    FOREIGN KEY (brew_id) REFERENCES brew(id) ON DELETE CASCADE;
+ALTER TABLE club_share ADD CONSTRAINT club_share_brew_id_fkey
+    FOREIGN KEY (brew_id) REFERENCES brew(id) ON DELETE CASCADE;
+ALTER TABLE tds_reading ADD CONSTRAINT tds_reading_brew_id_fkey
+    FOREIGN KEY (brew_id) REFERENCES brew(id) ON DELETE SET NULL;
+ALTER TABLE pour_curve ADD CONSTRAINT pour_curve_brew_id_fkey
+    FOREIGN KEY (brew_id) REFERENCES brew(id) ON DELETE CASCADE;
+ALTER TABLE resource_share ADD CONSTRAINT resource_share_brew_id_fkey
+    FOREIGN KEY (brew_id) REFERENCES brew(id) ON DELETE CASCADE;
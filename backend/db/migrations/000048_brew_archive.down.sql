@@ -0,0 +1,32 @@
+-- ============================================================================
+-- ROLLBACK - BREW ARCHIVE
+-- ============================================================================
+-- Refuses while brews are archived, rather than dropping them; stop the
+-- archive job (BREW_ARCHIVE_AFTER_DAYS=0) and restore or export them first.
+-- Migration: 000048_brew_archive
+-- Created: 2026-10-17
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM brew_archive) THEN
+        RAISE EXCEPTION 'brew_archive still holds archived brews';
+    END IF;
+END;
+$$;
+
+-- Deleting a synced row leaves a tombstone. Arguments: the resource name and
+-- the column holding the row's owner.
+CREATE OR REPLACE FUNCTION sync_tombstone()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM sync_lock(to_jsonb(OLD)->>TG_ARGV[1]);
+    INSERT INTO sync_tombstone (resource, record_id, user_id, vector)
+    VALUES (TG_ARGV[0], OLD.id, to_jsonb(OLD)->>TG_ARGV[1], OLD.sync_vector)
+    ON CONFLICT (resource, record_id) DO UPDATE
+    SET vector = EXCLUDED.vector, seq = nextval('sync_seq'), deleted_at = NOW();
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP FUNCTION IF EXISTS user_brews(TEXT);
+DROP TABLE IF EXISTS brew_archive;
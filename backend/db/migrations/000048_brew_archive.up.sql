@@ -0,0 +1,58 @@
+-- ============================================================================
+-- BREW ARCHIVE
+-- ============================================================================
+-- Adds cold storage for old brews, which the archive job moves out of brew
+-- into compressed JSONB documents
+-- Migration: 000048_brew_archive
+-- Created: 2026-10-17
+
+-- Brew archive table
+-- Cold storage for old brews, moved out of brew by the archive job. Each
+-- brew is kept whole as one JSONB document: the brew row, its flavor
+-- descriptors and its pour curve. Documents are compressed as soon as they
+-- outgrow toast_tuple_target, so even small brews are stored compressed.
+-- Archived brews are read-only; history reads them back through
+-- jsonb_populate_record, so columns added to brew later read as NULL.
+CREATE TABLE brew_archive (
+    id TEXT PRIMARY KEY, -- the brew's ULID
+    created_by TEXT REFERENCES "user"(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL,
+    archived_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- {"brew": {...}, "flavors": ["fruity.berry", ...], "pour_curve": {...} | null}
+    data JSONB NOT NULL
+) WITH (toast_tuple_target = 128);
+
+CREATE INDEX idx_brew_archive_created_by ON brew_archive(created_by, created_at DESC);
+
+-- A user's brews, live and archived, as brew rows. Stats read brews
+-- through it so archiving doesn't change them; each side is read by its
+-- created_by index, so only the user's archived documents are decompressed.
+CREATE OR REPLACE FUNCTION user_brews(uid TEXT)
+RETURNS SETOF brew AS $$
+    SELECT * FROM brew
+    WHERE created_by = uid
+    UNION ALL
+    SELECT ab.*
+    FROM brew_archive a
+    CROSS JOIN LATERAL jsonb_populate_record(NULL::brew, a.data->'brew') ab
+    WHERE a.created_by = uid
+$$ LANGUAGE sql STABLE;
+
+-- Deleting a synced row leaves a tombstone. Brews the archive job moved to
+-- brew_archive in the same statement are left without one, so offline
+-- clients keep them. Arguments: the resource name and the column holding
+-- the row's owner.
+CREATE OR REPLACE FUNCTION sync_tombstone()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_ARGV[0] = 'brew' AND EXISTS (SELECT 1 FROM brew_archive WHERE id = OLD.id) THEN
+        RETURN OLD;
+    END IF;
+    PERFORM sync_lock(to_jsonb(OLD)->>TG_ARGV[1]);
+    INSERT INTO sync_tombstone (resource, record_id, user_id, vector)
+    VALUES (TG_ARGV[0], OLD.id, to_jsonb(OLD)->>TG_ARGV[1], OLD.sync_vector)
+    ON CONFLICT (resource, record_id) DO UPDATE
+    SET vector = EXCLUDED.vector, seq = nextval('sync_seq'), deleted_at = NOW();
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,7 @@
+-- ============================================================================
+-- ROLLBACK - BACKUPS
+-- ============================================================================
+-- Migration: 000049_backups
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS backup_run;
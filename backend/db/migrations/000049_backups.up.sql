@@ -0,0 +1,37 @@
+-- ============================================================================
+-- BACKUPS
+-- ============================================================================
+-- Adds the history of logical backups, which the backup job records
+-- Migration: 000049_backups
+-- Created: 2026-10-17
+
+-- Backup run table
+-- History of logical backups: each run dumps the database with pg_dump,
+-- uploads the dump to object storage and verifies it restores. Only one
+-- run is in progress at a time; a run that outlives the server that
+-- started it is marked failed by the next check. Dumps older than the
+-- retention period are deleted from object storage, except the newest
+-- successful one; their runs stay in the history.
+CREATE TABLE backup_run (
+    id TEXT PRIMARY KEY, -- ULID format
+    trigger VARCHAR(20) NOT NULL CHECK (trigger IN ('scheduled', 'manual')),
+    -- Admin who triggered a manual run
+    requested_by TEXT REFERENCES "user"(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'succeeded', 'failed')),
+    -- The uploaded dump: its key in object storage, size and SHA-256
+    object_key TEXT,
+    size_bytes BIGINT,
+    sha256 VARCHAR(64),
+    -- restored: restored into the scratch database; listed: its table of
+    -- contents was read, with no scratch database configured
+    verification VARCHAR(20) CHECK (verification IN ('restored', 'listed')),
+    tables_restored INTEGER,
+    error TEXT,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ,
+    -- When retention deleted the dump, clearing object_key
+    pruned_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_backup_run_running ON backup_run((true)) WHERE status = 'running';
+CREATE INDEX idx_backup_run_started_at ON backup_run(started_at DESC);
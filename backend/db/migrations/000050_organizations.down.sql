@@ -0,0 +1,23 @@
+-- ============================================================================
+-- ROLLBACK - ORGANIZATIONS
+-- ============================================================================
+-- Brews logged in organizations stay with the members who logged them, as
+-- private brews
+-- Migration: 000050_organizations
+-- Created: 2026-10-17
+
+CREATE OR REPLACE FUNCTION user_brews(uid TEXT)
+RETURNS SETOF brew AS $$
+    SELECT * FROM brew
+    WHERE created_by = uid
+    UNION ALL
+    SELECT ab.*
+    FROM brew_archive a
+    CROSS JOIN LATERAL jsonb_populate_record(NULL::brew, a.data->'brew') ab
+    WHERE a.created_by = uid
+$$ LANGUAGE sql STABLE;
+
+ALTER TABLE brew DROP COLUMN IF EXISTS organization_id;
+
+DROP TABLE IF EXISTS organization_member;
+DROP TABLE IF EXISTS organization;
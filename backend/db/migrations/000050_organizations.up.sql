@@ -0,0 +1,60 @@
+-- ============================================================================
+-- ORGANIZATIONS
+-- ============================================================================
+-- Adds organizations (cafés, roasteries, training programs), their members,
+-- and brews logged in an organization
+-- Migration: 000050_organizations
+-- Created: 2026-10-17
+
+-- Organization table
+-- A café, roastery or training program whose members log brews together.
+-- Organization brews belong to the organization: only its members see them,
+-- through its routes, which scope every query to it.
+CREATE TABLE organization (
+    id TEXT PRIMARY KEY, -- ULID format
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('cafe', 'roastery', 'training_program')),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TRIGGER update_organization_updated_at
+BEFORE UPDATE ON organization
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Organization member table
+-- Membership and role: owners manage the organization and its admins;
+-- admins manage members
+CREATE TABLE organization_member (
+    organization_id TEXT NOT NULL REFERENCES organization(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'admin', 'member')),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX idx_organization_member_user ON organization_member(user_id);
+
+-- Brews logged in an organization are deleted with it, and are never
+-- public, so no public listing can reach them
+ALTER TABLE brew
+    ADD COLUMN organization_id TEXT,
+    ADD CONSTRAINT brew_organization_fkey FOREIGN KEY (organization_id)
+        REFERENCES organization(id) ON DELETE CASCADE,
+    ADD CONSTRAINT brew_organization_private_check CHECK (organization_id IS NULL OR is_public = false);
+
+CREATE INDEX idx_brew_organization ON brew(organization_id, created_at DESC) WHERE organization_id IS NOT NULL;
+
+-- A user's personal brews, live and archived, leave out organization
+-- brews, as they're the organization's
+CREATE OR REPLACE FUNCTION user_brews(uid TEXT)
+RETURNS SETOF brew AS $$
+    SELECT * FROM brew
+    WHERE created_by = uid AND organization_id IS NULL
+    UNION ALL
+    SELECT ab.*
+    FROM brew_archive a
+    CROSS JOIN LATERAL jsonb_populate_record(NULL::brew, a.data->'brew') ab
+    WHERE a.created_by = uid
+$$ LANGUAGE sql STABLE;
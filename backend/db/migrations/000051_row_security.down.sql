@@ -0,0 +1,28 @@
+-- ============================================================================
+-- ROLLBACK - ROW SECURITY
+-- ============================================================================
+-- Migration: 000051_row_security
+-- Created: 2026-10-17
+
+DROP POLICY IF EXISTS notification_insert ON notification;
+DROP POLICY IF EXISTS notification_recipient ON notification;
+ALTER TABLE notification NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE notification DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS api_key_owner ON api_key;
+ALTER TABLE api_key NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE api_key DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS draft_owner ON draft;
+ALTER TABLE draft NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE draft DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS brew_delete ON brew;
+DROP POLICY IF EXISTS brew_update ON brew;
+DROP POLICY IF EXISTS brew_insert ON brew;
+DROP POLICY IF EXISTS brew_read ON brew;
+ALTER TABLE brew NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE brew DISABLE ROW LEVEL SECURITY;
+
+DROP FUNCTION IF EXISTS app_acting_as_server();
+DROP FUNCTION IF EXISTS app_current_user_id();
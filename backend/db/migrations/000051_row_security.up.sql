@@ -0,0 +1,103 @@
+-- ============================================================================
+-- ROW SECURITY
+-- ============================================================================
+-- Adds row security policies on brews, drafts, API keys and notifications,
+-- enforced for the user the server sets in app.current_user_id
+-- (DB_ROW_SECURITY)
+-- Migration: 000051_row_security
+-- Created: 2026-10-17
+
+-- Row security
+-- Defense in depth behind the handlers' authorization checks. With
+-- DB_ROW_SECURITY=true the server sets app.current_user_id on each
+-- connection to the user a request acts for, and these policies keep its
+-- queries to the rows that user may reach. Queries acting for no user,
+-- such as signed-out requests, reach only public rows; the server marks
+-- the queries it sends for itself, from background jobs and backups, with
+-- the user "server", and those reach every row. FORCE applies the policies
+-- to the tables' owner, which the server usually connects as. Foreign key
+-- actions, such as deleting a user's rows with their account, bypass row
+-- security.
+
+-- The user the server's queries act for, from app.current_user_id; NULL
+-- when it's unset or empty, as for signed-out requests
+CREATE OR REPLACE FUNCTION app_current_user_id()
+RETURNS TEXT AS $$
+    SELECT NULLIF(current_setting('app.current_user_id', true), '')
+$$ LANGUAGE sql STABLE;
+
+-- Whether the server's queries act for the server itself rather than for
+-- a user (database.ServerUser)
+CREATE OR REPLACE FUNCTION app_acting_as_server()
+RETURNS BOOLEAN AS $$
+    SELECT COALESCE(current_setting('app.current_user_id', true) = 'server', false)
+$$ LANGUAGE sql STABLE;
+
+-- Brews: personal brews are read by their author, by anyone when public,
+-- and by the users and clubs they're shared with. Organization brews are
+-- only reached by the organization's current members. Users write only
+-- their own brews, and only in organizations they still belong to.
+ALTER TABLE brew ENABLE ROW LEVEL SECURITY;
+ALTER TABLE brew FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY brew_read ON brew FOR SELECT
+    USING (app_acting_as_server()
+           OR (organization_id IS NULL
+               AND (is_public
+                    OR created_by = app_current_user_id()
+                    OR id IN (SELECT brew_id FROM resource_share
+                              WHERE user_id = app_current_user_id()
+                                 OR club_id IN (SELECT club_id FROM club_member
+                                                WHERE user_id = app_current_user_id()))))
+           OR organization_id IN (SELECT organization_id FROM organization_member
+                                  WHERE user_id = app_current_user_id()));
+
+CREATE POLICY brew_insert ON brew FOR INSERT
+    WITH CHECK (app_acting_as_server()
+                OR (created_by = app_current_user_id()
+                    AND (organization_id IS NULL
+                         OR organization_id IN (SELECT organization_id FROM organization_member
+                                                WHERE user_id = app_current_user_id()))));
+
+CREATE POLICY brew_update ON brew FOR UPDATE
+    USING (app_acting_as_server()
+           OR (created_by = app_current_user_id()
+               AND (organization_id IS NULL
+                    OR organization_id IN (SELECT organization_id FROM organization_member
+                                           WHERE user_id = app_current_user_id()))))
+    WITH CHECK (app_acting_as_server()
+                OR (created_by = app_current_user_id()
+                    AND (organization_id IS NULL
+                         OR organization_id IN (SELECT organization_id FROM organization_member
+                                                WHERE user_id = app_current_user_id()))));
+
+CREATE POLICY brew_delete ON brew FOR DELETE
+    USING (app_acting_as_server()
+           OR (created_by = app_current_user_id()
+               AND (organization_id IS NULL
+                    OR organization_id IN (SELECT organization_id FROM organization_member
+                                           WHERE user_id = app_current_user_id()))));
+
+-- Drafts and API keys are only their owner's
+ALTER TABLE draft ENABLE ROW LEVEL SECURITY;
+ALTER TABLE draft FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY draft_owner ON draft
+    USING (app_acting_as_server() OR user_id = app_current_user_id());
+
+ALTER TABLE api_key ENABLE ROW LEVEL SECURITY;
+ALTER TABLE api_key FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY api_key_owner ON api_key
+    USING (app_acting_as_server() OR user_id = app_current_user_id());
+
+-- Notifications are only read, marked and deleted by their recipient; any
+-- user's actions may notify others
+ALTER TABLE notification ENABLE ROW LEVEL SECURITY;
+ALTER TABLE notification FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY notification_recipient ON notification
+    USING (app_acting_as_server() OR recipient_user_id = app_current_user_id());
+
+CREATE POLICY notification_insert ON notification FOR INSERT
+    WITH CHECK (true);
@@ -0,0 +1,7 @@
+-- ============================================================================
+-- ROLLBACK - RETENTION
+-- ============================================================================
+-- Migration: 000052_retention
+-- Created: 2026-10-17
+
+DROP INDEX IF EXISTS idx_analytics_event_received_at;
@@ -0,0 +1,9 @@
+-- ============================================================================
+-- RETENTION
+-- ============================================================================
+-- Indexes analytics events by when they were received, which the retention
+-- job purges them by
+-- Migration: 000052_retention
+-- Created: 2026-10-17
+
+CREATE INDEX IF NOT EXISTS idx_analytics_event_received_at ON analytics_event(received_at);
@@ -0,0 +1,7 @@
+-- ============================================================================
+-- ROLLBACK - REALTIME PRESENCE
+-- ============================================================================
+-- Migration: 000053_realtime_presence
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS realtime_presence;
@@ -0,0 +1,7 @@
+-- ============================================================================
+-- ROLLBACK - JOB LEASES
+-- ============================================================================
+-- Migration: 000054_job_leases
+-- Created: 2026-10-17
+
+DROP TABLE IF EXISTS job_lease;
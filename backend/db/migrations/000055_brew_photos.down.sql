@@ -0,0 +1,10 @@
+-- ============================================================================
+-- ROLLBACK - BREW PHOTOS
+-- ============================================================================
+-- Migration: 000055_brew_photos
+-- Created: 2026-10-17
+
+DROP TRIGGER IF EXISTS record_deleted_brew_photo_objects ON brew_photo;
+DROP FUNCTION IF EXISTS record_deleted_photo_objects();
+DROP TABLE IF EXISTS deleted_photo_object;
+DROP TABLE IF EXISTS brew_photo;
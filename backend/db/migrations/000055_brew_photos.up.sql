@@ -0,0 +1,80 @@
+-- ============================================================================
+-- BREW PHOTOS
+-- ============================================================================
+-- Photos attached to brews, processed asynchronously after upload
+-- Migration: 000055_brew_photos
+-- Created: 2026-10-17
+
+-- Brew photo table
+-- Photos attached to a brew by its owner. The upload is stored as
+-- original_key and queued: the photo processor claims pending photos,
+-- checks the sniffed content type, strips metadata, resizes the image and
+-- renders a thumbnail, and optionally screens it before marking it ready.
+-- Claiming a photo leases it until next_attempt_at, so a processor that
+-- dies doesn't strand it; failures are retried with backoff until the
+-- photo is marked failed.
+CREATE TABLE brew_photo (
+    id TEXT PRIMARY KEY, -- ULID format
+    brew_id TEXT NOT NULL REFERENCES brew(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'processing', 'ready', 'failed', 'rejected')),
+    -- Type sniffed from the uploaded bytes, not the client's header
+    content_type VARCHAR(50) NOT NULL,
+    original_key TEXT NOT NULL,
+    original_bytes BIGINT NOT NULL,
+    -- Set once processed
+    image_key TEXT,
+    thumbnail_key TEXT,
+    width INTEGER,
+    height INTEGER,
+    image_bytes BIGINT,
+    thumbnail_bytes BIGINT,
+    -- Screening score in [0, 1] when a screener is configured
+    screening_score DOUBLE PRECISION,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    error TEXT,
+    processed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_brew_photo_brew ON brew_photo(brew_id, created_at);
+CREATE INDEX idx_brew_photo_user ON brew_photo(user_id);
+CREATE INDEX idx_brew_photo_due ON brew_photo(next_attempt_at, id)
+    WHERE status IN ('pending', 'processing');
+
+-- Deleted photo object table
+-- Object keys of deleted photos, recorded by a trigger whenever a
+-- brew_photo row goes, including with its brew or owner. The photo
+-- processor sweeps them from the object store, so a failed delete or a
+-- cascade can't leave objects behind. Claiming a key leases it until
+-- next_attempt_at, and failed deletes are retried once the lease runs out.
+CREATE TABLE deleted_photo_object (
+    key TEXT PRIMARY KEY,
+    photo_id TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_deleted_photo_object_due ON deleted_photo_object(next_attempt_at, key);
+
+-- Records a deleted photo's original and renditions. Runs for photos
+-- deleted with their brew or owner too, which no handler sees.
+CREATE OR REPLACE FUNCTION record_deleted_photo_objects()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO deleted_photo_object (key, photo_id)
+    SELECT k, OLD.id
+    FROM unnest(ARRAY[OLD.original_key, OLD.image_key, OLD.thumbnail_key]) AS k
+    WHERE k IS NOT NULL
+    ON CONFLICT (key) DO NOTHING;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER record_deleted_brew_photo_objects
+AFTER DELETE ON brew_photo
+FOR EACH ROW
+EXECUTE FUNCTION record_deleted_photo_objects();
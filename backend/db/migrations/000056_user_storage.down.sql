@@ -0,0 +1,11 @@
+-- ============================================================================
+-- ROLLBACK - USER STORAGE
+-- ============================================================================
+-- Migration: 000056_user_storage
+-- Created: 2026-10-17
+
+DROP TRIGGER IF EXISTS track_brew_photo_storage ON brew_photo;
+DROP FUNCTION IF EXISTS track_photo_storage();
+DROP FUNCTION IF EXISTS reserve_storage(TEXT, TEXT, BIGINT, BIGINT, INTEGER);
+DROP TABLE IF EXISTS storage_reservation;
+DROP TABLE IF EXISTS user_storage;
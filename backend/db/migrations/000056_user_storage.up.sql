@@ -0,0 +1,107 @@
+-- ============================================================================
+-- USER STORAGE
+-- ============================================================================
+-- Tracks the bytes each user keeps in the object store, for storage quotas,
+-- counting existing photos
+-- Migration: 000056_user_storage
+-- Created: 2026-10-17
+
+CREATE TABLE user_storage (
+    user_id TEXT PRIMARY KEY REFERENCES "user"(id) ON DELETE CASCADE,
+    photo_bytes BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_user_storage_photo_bytes ON user_storage(photo_bytes DESC);
+
+INSERT INTO user_storage (user_id, photo_bytes)
+SELECT user_id, SUM(original_bytes + COALESCE(image_bytes, 0) + COALESCE(thumbnail_bytes, 0))
+FROM brew_photo
+GROUP BY user_id;
+
+-- Storage reservation table
+-- Bytes set aside for uploads in flight, so concurrent uploads can't
+-- together exceed a quota each fits under alone. An upload reserves its
+-- size before storing the object and releases it once its row counts the
+-- bytes or it fails; reservations of a server that died lapse at
+-- expires_at.
+CREATE TABLE storage_reservation (
+    id TEXT PRIMARY KEY, -- ULID format
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    bytes BIGINT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_storage_reservation_user ON storage_reservation(user_id);
+
+-- Reserves size_bytes of uid's storage as reservation unless what they
+-- store, what they have reserved and size_bytes would exceed limit_bytes, returning
+-- whether it did. The user's storage row is locked first, so concurrent
+-- reservations and photo changes for one user run one at a time, and each
+-- statement after the lock sees the reservations the last committed.
+CREATE OR REPLACE FUNCTION reserve_storage(uid TEXT, reservation TEXT, size_bytes BIGINT, limit_bytes BIGINT, ttl_seconds INTEGER)
+RETURNS BOOLEAN AS $$
+DECLARE
+    stored BIGINT;
+    reserved BIGINT;
+BEGIN
+    INSERT INTO user_storage (user_id) VALUES (uid)
+    ON CONFLICT (user_id) DO NOTHING;
+    SELECT s.photo_bytes INTO stored
+    FROM user_storage s
+    WHERE s.user_id = uid
+    FOR NO KEY UPDATE;
+
+    DELETE FROM storage_reservation r WHERE r.user_id = uid AND r.expires_at <= NOW();
+    SELECT COALESCE(SUM(r.bytes), 0) INTO reserved
+    FROM storage_reservation r
+    WHERE r.user_id = uid;
+    IF stored + reserved + size_bytes > limit_bytes THEN
+        RETURN false;
+    END IF;
+
+    INSERT INTO storage_reservation (id, user_id, bytes, expires_at)
+    VALUES (reservation, uid, size_bytes, NOW() + ttl_seconds * INTERVAL '1 second');
+    RETURN true;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Adds the change in a photo's bytes to its owner's storage. Deletes only
+-- update an existing row, since a photo deleted with its owner has no
+-- storage row left to update.
+CREATE OR REPLACE FUNCTION track_photo_storage()
+RETURNS TRIGGER AS $$
+DECLARE
+    owner_id TEXT;
+    delta BIGINT := 0;
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        owner_id := OLD.user_id;
+        delta := delta - (OLD.original_bytes + COALESCE(OLD.image_bytes, 0) + COALESCE(OLD.thumbnail_bytes, 0));
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        owner_id := NEW.user_id;
+        delta := delta + (NEW.original_bytes + COALESCE(NEW.image_bytes, 0) + COALESCE(NEW.thumbnail_bytes, 0));
+    END IF;
+    IF delta = 0 THEN
+        RETURN NULL;
+    END IF;
+
+    UPDATE user_storage
+    SET photo_bytes = photo_bytes + delta, updated_at = NOW()
+    WHERE user_id = owner_id;
+    IF NOT FOUND AND TG_OP <> 'DELETE' THEN
+        INSERT INTO user_storage (user_id, photo_bytes)
+        VALUES (owner_id, delta)
+        ON CONFLICT (user_id) DO UPDATE
+        SET photo_bytes = user_storage.photo_bytes + EXCLUDED.photo_bytes, updated_at = NOW();
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Brew photo table
+CREATE TRIGGER track_brew_photo_storage
+AFTER INSERT OR DELETE OR UPDATE OF original_bytes, image_bytes, thumbnail_bytes ON brew_photo
+FOR EACH ROW
+EXECUTE FUNCTION track_photo_storage();
@@ -0,0 +1,15 @@
+-- ============================================================================
+-- ROLLBACK - PHOTO QUARANTINE
+-- ============================================================================
+-- Migration: 000057_photo_quarantine
+-- Created: 2026-10-17
+
+DROP INDEX IF EXISTS idx_brew_photo_quarantined;
+
+-- Quarantined photos can't be represented without the status; their
+-- originals stay under quarantine/ in the object store
+DELETE FROM brew_photo WHERE status = 'quarantined';
+
+ALTER TABLE brew_photo DROP CONSTRAINT brew_photo_status_check;
+ALTER TABLE brew_photo ADD CONSTRAINT brew_photo_status_check
+    CHECK (status IN ('pending', 'processing', 'ready', 'failed', 'rejected'));
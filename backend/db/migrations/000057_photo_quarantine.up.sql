@@ -0,0 +1,14 @@
+-- ============================================================================
+-- PHOTO QUARANTINE
+-- ============================================================================
+-- Photos whose uploads an upload scanner finds malware in are quarantined
+-- rather than processed
+-- Migration: 000057_photo_quarantine
+-- Created: 2026-10-17
+
+ALTER TABLE brew_photo DROP CONSTRAINT brew_photo_status_check;
+ALTER TABLE brew_photo ADD CONSTRAINT brew_photo_status_check
+    CHECK (status IN ('pending', 'processing', 'ready', 'failed', 'rejected', 'quarantined'));
+
+CREATE INDEX idx_brew_photo_quarantined ON brew_photo(processed_at DESC)
+    WHERE status = 'quarantined';
@@ -0,0 +1,11 @@
+-- ============================================================================
+-- ROLLBACK - MEDIA MIGRATION
+-- ============================================================================
+-- Migration: 000058_media_migration
+-- Created: 2026-10-17
+
+DROP INDEX IF EXISTS idx_backup_run_object_key;
+DROP INDEX IF EXISTS idx_brew_photo_thumbnail_key;
+DROP INDEX IF EXISTS idx_brew_photo_image_key;
+DROP INDEX IF EXISTS idx_brew_photo_original_key;
+DROP TABLE IF EXISTS media_migration_object;
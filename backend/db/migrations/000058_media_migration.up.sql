@@ -0,0 +1,31 @@
+-- ============================================================================
+-- MEDIA MIGRATION
+-- ============================================================================
+-- Tracks objects copied between object stores by the media migration tool,
+-- making migrations resumable
+-- Migration: 000058_media_migration
+-- Created: 2026-10-17
+
+-- Media migration object table
+-- Objects the media migration tool (cmd/mediamigrate) has copied to a
+-- destination object store and verified, so an interrupted migration
+-- resumes where it stopped. destination is the store's URL as given to the
+-- tool; version is when the row referring to the object last had it
+-- written, so an object rewritten under the same key (a photo rendered
+-- again) is copied again; sha256 is the checksum both copies were verified
+-- to have.
+CREATE TABLE media_migration_object (
+    destination TEXT NOT NULL,
+    object_key TEXT NOT NULL,
+    version TIMESTAMPTZ,
+    size_bytes BIGINT NOT NULL,
+    sha256 VARCHAR(64) NOT NULL,
+    migrated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (destination, object_key)
+);
+
+-- Indexes paging through each source of media objects in key order
+CREATE INDEX idx_brew_photo_original_key ON brew_photo(original_key);
+CREATE INDEX idx_brew_photo_image_key ON brew_photo(image_key) WHERE image_key IS NOT NULL;
+CREATE INDEX idx_brew_photo_thumbnail_key ON brew_photo(thumbnail_key) WHERE thumbnail_key IS NOT NULL;
+CREATE INDEX idx_backup_run_object_key ON backup_run(object_key) WHERE object_key IS NOT NULL;
@@ -0,0 +1,10 @@
+// Package migrations embeds the migration files, so the server knows the
+// schema version it was built for. golang-migrate ignores this file.
+package migrations
+
+import "embed"
+
+// FS holds the up migrations
+//
+//go:embed *.up.sql
+var FS embed.FS
@@ -0,0 +1,23 @@
+-- ============================================================================
+-- ANALYTICS EVENT QUERIES
+-- ============================================================================
+-- Operations for storing client analytics events
+
+
+-- ----------------------------------------------------------------------------
+-- 1. INSERT ANALYTICS EVENTS
+-- ----------------------------------------------------------------------------
+-- Parameters: user_id, events (JSON array of objects with id, session_id,
+--             name, properties and occurred_at)
+-- Returns: Nothing
+-- Usage: Database analytics sink; one round trip per client batch
+-- name: InsertAnalyticsEvents :exec
+INSERT INTO analytics_event (id, user_id, session_id, name, properties, occurred_at)
+SELECT e.id, sqlc.arg(user_id), e.session_id, e.name, COALESCE(e.properties, '{}'), e.occurred_at
+FROM jsonb_to_recordset(sqlc.arg(events)::jsonb) AS e(
+    id TEXT,
+    session_id TEXT,
+    name TEXT,
+    properties JSONB,
+    occurred_at TIMESTAMPTZ
+);
@@ -0,0 +1,72 @@
+-- ============================================================================
+-- API KEY QUERIES
+-- ============================================================================
+-- Operations for the API keys companion apps and device bridges authenticate
+-- with
+
+
+-- ----------------------------------------------------------------------------
+-- 1. CREATE API KEY
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id, $2 = user_id, $3 = name, $4 = key_prefix,
+--             $5 = key_hash, $6 = scopes
+-- Returns: The created key (the key itself is never stored)
+-- Usage: User issues a key for a companion app
+-- name: CreateAPIKey :one
+INSERT INTO api_key (id, user_id, name, key_prefix, key_hash, scopes)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. LIST USER API KEYS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id
+-- Returns: The user's keys, including revoked ones, newest first
+-- Usage: API key settings screen
+-- Performance: Uses idx_api_key_user
+-- name: ListUserAPIKeys :many
+SELECT * FROM api_key
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. GET API KEY BY HASH
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = key_hash
+-- Returns: The unrevoked key with that hash, if its owner's account
+--          hasn't been deactivated
+-- Usage: Authenticating an integration request
+-- name: GetAPIKeyByHash :one
+SELECT * FROM api_key
+WHERE key_hash = $1 AND revoked_at IS NULL
+  AND EXISTS (
+      SELECT 1 FROM "user" u
+      WHERE u.id = api_key.user_id AND u.deactivated_at IS NULL
+  );
+
+
+-- ----------------------------------------------------------------------------
+-- 4. REVOKE API KEY
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id, $2 = user_id
+-- Returns: Number of rows updated; 0 if not the user's or already revoked
+-- Usage: User revokes a key
+-- name: RevokeAPIKey :execrows
+UPDATE api_key
+SET revoked_at = NOW()
+WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL;
+
+
+-- ----------------------------------------------------------------------------
+-- 5. TOUCH API KEY
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id
+-- Returns: None
+-- Usage: Record when a key was last used; skipped within a minute of the
+--        last write so busy integrations don't update the row every request
+-- name: TouchAPIKey :exec
+UPDATE api_key
+SET last_used_at = NOW()
+WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute');
@@ -0,0 +1,107 @@
+-- ============================================================================
+-- AUTH EVENT QUERIES
+-- ============================================================================
+-- Operations for the auth event audit log: recording registrations and
+-- sign-in attempts with their coarse location, listing them for users and
+-- admins, and tracking new location login alerts.
+
+
+-- ----------------------------------------------------------------------------
+-- 1. RECORD AUTH EVENT
+-- ----------------------------------------------------------------------------
+-- Parameters: user_id, event, ip, user_agent, country, region, city
+-- Returns: None
+-- Usage: Registration and login. A login is marked new_location when it
+--        has a known country, the user has signed in from somewhere known
+--        before, and never from this country and region.
+-- Performance: Uses idx_auth_event_user
+-- name: RecordAuthEvent :exec
+INSERT INTO auth_event (user_id, event, ip, user_agent, country, region, city, new_location)
+SELECT
+    sqlc.arg(user_id),
+    sqlc.arg(event),
+    sqlc.narg(ip),
+    sqlc.narg(user_agent),
+    sqlc.narg(country),
+    sqlc.narg(region),
+    sqlc.narg(city),
+    sqlc.arg(event) = 'login'
+        AND sqlc.narg(country)::text IS NOT NULL
+        AND EXISTS (
+            SELECT 1 FROM auth_event
+            WHERE user_id = sqlc.arg(user_id)
+              AND event IN ('register', 'login')
+              AND country IS NOT NULL
+        )
+        AND NOT EXISTS (
+            SELECT 1 FROM auth_event
+            WHERE user_id = sqlc.arg(user_id)
+              AND event IN ('register', 'login')
+              AND country = sqlc.narg(country)
+              AND region IS NOT DISTINCT FROM sqlc.narg(region)
+        );
+
+
+-- ----------------------------------------------------------------------------
+-- 2. LIST USER AUTH EVENTS
+-- ----------------------------------------------------------------------------
+-- Parameters: user_id, row_limit, row_offset
+-- Returns: The user's auth events, newest first
+-- Usage: User reviews their recent sign-ins
+-- Performance: Uses idx_auth_event_user
+-- name: ListUserAuthEvents :many
+SELECT * FROM auth_event
+WHERE user_id = sqlc.arg(user_id)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+
+-- ----------------------------------------------------------------------------
+-- 3. LIST AUTH EVENTS
+-- ----------------------------------------------------------------------------
+-- Parameters: user_id (NULL for all users), event (NULL for all events),
+--             row_limit, row_offset
+-- Returns: Auth events with the user's username, newest first
+-- Usage: Admin audit log
+-- Performance: Uses idx_auth_event_user when filtered by user
+-- name: ListAuthEvents :many
+SELECT e.*, u.username
+FROM auth_event e
+JOIN "user" u ON u.id = e.user_id
+WHERE (sqlc.narg(user_id)::text IS NULL OR e.user_id = sqlc.narg(user_id))
+  AND (sqlc.narg(event)::text IS NULL OR e.event = sqlc.narg(event))
+ORDER BY e.created_at DESC, e.id DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+
+-- ----------------------------------------------------------------------------
+-- 4. LIST PENDING LOGIN ALERTS
+-- ----------------------------------------------------------------------------
+-- Parameters: max_age_hours, row_limit
+-- Returns: New location logins no alert has been sent for, with the
+--          user's email and username, oldest first
+-- Usage: Login alert worker. Alerts older than max_age_hours are no
+--        longer useful and are skipped.
+-- Performance: Uses idx_auth_event_alert_pending
+-- name: ListPendingLoginAlerts :many
+SELECT e.id, e.ip, e.user_agent, e.country, e.region, e.city, e.created_at,
+       u.email, u.username
+FROM auth_event e
+JOIN "user" u ON u.id = e.user_id
+WHERE e.new_location
+  AND e.alert_sent_at IS NULL
+  AND e.created_at > NOW() - sqlc.arg(max_age_hours)::int * INTERVAL '1 hour'
+ORDER BY e.created_at
+LIMIT sqlc.arg(row_limit);
+
+
+-- ----------------------------------------------------------------------------
+-- 5. MARK LOGIN ALERT SENT
+-- ----------------------------------------------------------------------------
+-- Parameters: id
+-- Returns: None
+-- Usage: Login alert worker, after the email was sent or suppressed
+-- name: MarkLoginAlertSent :exec
+UPDATE auth_event
+SET alert_sent_at = NOW()
+WHERE id = sqlc.arg(id);
@@ -0,0 +1,133 @@
+-- ============================================================================
+-- AUTOMATION QUERIES
+-- ============================================================================
+-- Operations for smart-home webhooks and their delivery queue
+
+
+-- ----------------------------------------------------------------------------
+-- 1. CREATE AUTOMATION WEBHOOK
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id, $2 = user_id, $3 = name, $4 = format, $5 = url,
+--             $6 = events
+-- Returns: The created webhook
+-- Usage: User connects Home Assistant or IFTTT
+-- name: CreateAutomationWebhook :one
+INSERT INTO automation_webhook (id, user_id, name, format, url, events)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. LIST USER AUTOMATION WEBHOOKS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id
+-- Returns: The user's webhooks, newest first
+-- Usage: Integration settings
+-- Performance: Uses idx_automation_webhook_user
+-- name: ListUserAutomationWebhooks :many
+SELECT * FROM automation_webhook
+WHERE user_id = $1
+ORDER BY created_at DESC, id DESC;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. GET AUTOMATION WEBHOOK
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id, $2 = user_id
+-- Returns: One of the user's webhooks
+-- Usage: Sending a test event
+-- name: GetAutomationWebhook :one
+SELECT * FROM automation_webhook
+WHERE id = $1 AND user_id = $2;
+
+
+-- ----------------------------------------------------------------------------
+-- 4. DELETE AUTOMATION WEBHOOK
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id, $2 = user_id
+-- Returns: Number of rows deleted; pending deliveries are deleted with it
+-- Usage: User disconnects an integration
+-- name: DeleteAutomationWebhook :execrows
+DELETE FROM automation_webhook
+WHERE id = $1 AND user_id = $2;
+
+
+-- ----------------------------------------------------------------------------
+-- 5. LIST EVENT AUTOMATION WEBHOOKS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id, $2 = event
+-- Returns: IDs of the user's webhooks subscribed to the event
+-- Usage: Outbox dispatcher, queueing deliveries for a brew event
+-- Performance: Uses idx_automation_webhook_user
+-- name: ListEventAutomationWebhooks :many
+SELECT id FROM automation_webhook
+WHERE user_id = $1 AND sqlc.arg(event)::text = ANY(events);
+
+
+-- ----------------------------------------------------------------------------
+-- 6. CREATE AUTOMATION DELIVERY
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id, $2 = webhook_id, $3 = event, $4 = payload
+-- Returns: None
+-- Usage: Queue an event for a webhook; the delivery worker sends it
+-- name: CreateAutomationDelivery :exec
+INSERT INTO automation_delivery (id, webhook_id, event, payload)
+VALUES ($1, $2, $3, $4);
+
+
+-- ----------------------------------------------------------------------------
+-- 7. CLAIM DUE AUTOMATION DELIVERIES
+-- ----------------------------------------------------------------------------
+-- Parameters: lease_seconds, limit
+-- Returns: Due deliveries with their webhook's format and URL, counted as
+--          attempted and leased so other instances skip them until sent or
+--          the lease runs out
+-- Usage: Automation delivery worker; SKIP LOCKED lets several instances poll
+--        safely
+-- Performance: Uses idx_automation_delivery_due
+-- name: ClaimDueAutomationDeliveries :many
+UPDATE automation_delivery d
+SET
+    attempts = d.attempts + 1,
+    next_attempt_at = NOW() + sqlc.arg(lease_seconds)::int * INTERVAL '1 second'
+FROM automation_webhook w
+WHERE w.id = d.webhook_id
+    AND d.id IN (
+        SELECT ad.id FROM automation_delivery ad
+        WHERE ad.next_attempt_at <= NOW()
+            AND ad.delivered_at IS NULL
+            AND ad.failed_at IS NULL
+        ORDER BY ad.next_attempt_at
+        LIMIT sqlc.arg(row_limit)
+        FOR UPDATE SKIP LOCKED
+    )
+RETURNING d.id, d.webhook_id, d.event, d.payload, d.attempts, w.format, w.url;
+
+
+-- ----------------------------------------------------------------------------
+-- 8. COMPLETE AUTOMATION DELIVERY
+-- ----------------------------------------------------------------------------
+-- Parameters: id, status (HTTP status, NULL if no response), error (NULL on
+--             success), retry_in_seconds (NULL to stop retrying)
+-- Returns: None
+-- Usage: Record a delivery attempt's outcome on the delivery and its
+--        webhook. Successful deliveries are marked delivered; failures are
+--        rescheduled, or marked failed when out of retries.
+-- name: CompleteAutomationDelivery :exec
+WITH delivery AS (
+    UPDATE automation_delivery
+    SET
+        delivered_at = CASE WHEN sqlc.narg(error)::text IS NULL THEN NOW() END,
+        failed_at = CASE
+            WHEN sqlc.narg(error)::text IS NOT NULL AND sqlc.narg(retry_in_seconds)::int IS NULL THEN NOW()
+        END,
+        next_attempt_at = NOW() + COALESCE(sqlc.narg(retry_in_seconds)::int, 0) * INTERVAL '1 second'
+    WHERE id = sqlc.arg(id)
+    RETURNING webhook_id
+)
+UPDATE automation_webhook
+SET
+    last_status = sqlc.narg(status),
+    last_error = sqlc.narg(error),
+    last_delivered_at = CASE WHEN sqlc.narg(error)::text IS NULL THEN NOW() ELSE last_delivered_at END
+WHERE id = (SELECT webhook_id FROM delivery);
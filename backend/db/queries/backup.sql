@@ -0,0 +1,143 @@
+-- ============================================================================
+-- BACKUP QUERIES
+-- ============================================================================
+-- History of logical backups taken by the backup job
+
+
+-- ----------------------------------------------------------------------------
+-- 1. START BACKUP RUN
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id (ULID), $2 = trigger, $3 = requested_by (NULL for
+--             scheduled runs)
+-- Returns: The started run
+-- Usage: Backup job and the admin trigger; fails with a unique violation
+--        (idx_backup_run_running) while another run is in progress
+-- name: StartBackupRun :one
+INSERT INTO backup_run (id, trigger, requested_by)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. FINISH BACKUP RUN
+-- ----------------------------------------------------------------------------
+-- Parameters: id, status, object_key, size_bytes, sha256, verification,
+--             tables_restored, error (NULL unless failed)
+-- Returns: The finished run
+-- Usage: Backup job records a run's outcome
+-- name: FinishBackupRun :one
+UPDATE backup_run
+SET status = sqlc.arg(status),
+    object_key = sqlc.narg(object_key),
+    size_bytes = sqlc.narg(size_bytes),
+    sha256 = sqlc.narg(sha256),
+    verification = sqlc.narg(verification),
+    tables_restored = sqlc.narg(tables_restored),
+    error = sqlc.narg(error),
+    finished_at = NOW()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. FAIL STALE BACKUP RUNS
+-- ----------------------------------------------------------------------------
+-- Parameters: stale_hours
+-- Returns: Number of runs marked failed
+-- Usage: Backup job; a run still in progress after stale_hours was
+--        interrupted, e.g. by a restart, and would block every later run
+-- name: FailStaleBackupRuns :execrows
+UPDATE backup_run
+SET status = 'failed',
+    error = 'interrupted',
+    finished_at = NOW()
+WHERE status = 'running'
+  AND started_at < NOW() - sqlc.arg(stale_hours)::int * INTERVAL '1 hour';
+
+
+-- ----------------------------------------------------------------------------
+-- 4. GET LATEST BACKUP RUN
+-- ----------------------------------------------------------------------------
+-- Parameters: None
+-- Returns: The most recently started run
+-- Usage: Backup job decides whether a scheduled run is due
+-- Performance: Uses idx_backup_run_started_at
+-- name: GetLatestBackupRun :one
+SELECT * FROM backup_run
+ORDER BY started_at DESC
+LIMIT 1;
+
+
+-- ----------------------------------------------------------------------------
+-- 5. LIST BACKUP RUNS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = limit, $2 = offset
+-- Returns: Backup runs, newest first
+-- Usage: Admin backup history
+-- Performance: Uses idx_backup_run_started_at
+-- name: ListBackupRuns :many
+SELECT * FROM backup_run
+ORDER BY started_at DESC
+LIMIT $1 OFFSET $2;
+
+
+-- ----------------------------------------------------------------------------
+-- 6. GET BACKUP RUN
+-- ----------------------------------------------------------------------------
+-- Parameters: id
+-- Returns: The run
+-- Usage: Admin backup download link
+-- Performance: Uses the primary key
+-- name: GetBackupRun :one
+SELECT * FROM backup_run
+WHERE id = sqlc.arg(id);
+
+
+-- ----------------------------------------------------------------------------
+-- 7. GET RUNNING BACKUP RUN
+-- ----------------------------------------------------------------------------
+-- Parameters: None
+-- Returns: The run in progress, if any
+-- Usage: Backup job picks up runs admins triggered on any instance, and
+--        runs left in progress by an instance that lost the job
+-- Performance: Uses idx_backup_run_running
+-- name: GetRunningBackupRun :one
+SELECT * FROM backup_run
+WHERE status = 'running';
+
+
+-- ----------------------------------------------------------------------------
+-- 8. LIST EXPIRED BACKUP RUNS
+-- ----------------------------------------------------------------------------
+-- Parameters: retention_days, batch_size
+-- Returns: Finished runs started over retention_days ago whose dumps are
+--          still stored, oldest first, except the newest successful run's
+-- Usage: Backup job deletes their dumps, then PruneBackupRun
+-- Performance: Uses idx_backup_run_started_at
+-- name: ListExpiredBackupRuns :many
+SELECT * FROM backup_run
+WHERE object_key IS NOT NULL
+  AND status <> 'running'
+  AND started_at < NOW() - sqlc.arg(retention_days)::int * INTERVAL '1 day'
+  AND id IS DISTINCT FROM (
+      SELECT id FROM backup_run
+      WHERE status = 'succeeded' AND object_key IS NOT NULL
+      ORDER BY started_at DESC
+      LIMIT 1
+  )
+ORDER BY started_at
+LIMIT sqlc.arg(batch_size);
+
+
+-- ----------------------------------------------------------------------------
+-- 9. PRUNE BACKUP RUN
+-- ----------------------------------------------------------------------------
+-- Parameters: id
+-- Returns: Nothing
+-- Usage: Backup job, once the run's dump is deleted from object storage
+-- Performance: Uses the primary key
+-- name: PruneBackupRun :exec
+UPDATE backup_run
+SET object_key = NULL,
+    pruned_at = NOW()
+WHERE id = sqlc.arg(id);
@@ -0,0 +1,177 @@
+-- ============================================================================
+-- BADGE QUERIES
+-- ============================================================================
+-- Operations for challenges, the badges users earn by completing them and
+-- the queue of users awaiting evaluation
+
+
+-- ----------------------------------------------------------------------------
+-- 1. CREATE CHALLENGE
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id, $2 = name, $3 = description, $4 = metric,
+--             $5 = brew_method, $6 = threshold, $7 = created_by
+-- Returns: The created challenge
+-- Usage: Admin defines a new challenge
+-- name: CreateChallenge :one
+INSERT INTO challenge (id, name, description, metric, brew_method, threshold, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. GET CHALLENGE BY ID
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id
+-- Returns: The challenge
+-- Usage: Admin challenge tooling
+-- name: GetChallengeByID :one
+SELECT * FROM challenge
+WHERE id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. UPDATE CHALLENGE
+-- ----------------------------------------------------------------------------
+-- Parameters: name, description, threshold, is_active, id (NULL leaves a
+--             field as is)
+-- Returns: The updated challenge
+-- Usage: Admin edits or retires a challenge; badges already earned are kept
+-- name: UpdateChallenge :one
+UPDATE challenge
+SET
+    name = COALESCE(sqlc.narg(name), name),
+    description = COALESCE(sqlc.narg(description), description),
+    threshold = COALESCE(sqlc.narg(threshold), threshold),
+    is_active = COALESCE(sqlc.narg(is_active), is_active)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 4. DELETE CHALLENGE
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id
+-- Returns: Number of rows deleted
+-- Usage: Admin removes a challenge and every badge earned from it
+-- name: DeleteChallenge :execrows
+DELETE FROM challenge
+WHERE id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 5. LIST CHALLENGES
+-- ----------------------------------------------------------------------------
+-- Parameters: include_inactive
+-- Returns: Challenges ordered by metric and threshold
+-- Usage: Challenge list, badge progress and the badge worker
+-- name: ListChallenges :many
+SELECT * FROM challenge
+WHERE is_active OR sqlc.arg(include_inactive)::boolean
+ORDER BY metric, brew_method NULLS FIRST, threshold, id;
+
+
+-- ----------------------------------------------------------------------------
+-- 6. QUEUE BADGE EVALUATION
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id
+-- Returns: None
+-- Usage: After a user logs a brew; a user already queued keeps their place
+-- name: QueueBadgeEvaluation :exec
+INSERT INTO badge_evaluation (user_id)
+VALUES ($1)
+ON CONFLICT (user_id) DO NOTHING;
+
+
+-- ----------------------------------------------------------------------------
+-- 7. QUEUE ALL BADGE EVALUATIONS
+-- ----------------------------------------------------------------------------
+-- Parameters: None
+-- Returns: Number of users queued
+-- Usage: After a challenge is created or changed, so it is awarded for
+--        brews already logged
+-- name: QueueAllBadgeEvaluations :execrows
+INSERT INTO badge_evaluation (user_id)
+SELECT DISTINCT created_by FROM brew
+WHERE created_by IS NOT NULL
+ON CONFLICT (user_id) DO NOTHING;
+
+
+-- ----------------------------------------------------------------------------
+-- 8. CLAIM BADGE EVALUATIONS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = limit
+-- Returns: IDs of the users claimed, oldest first
+-- Usage: Badge worker; SKIP LOCKED lets several workers share the queue
+-- name: ClaimBadgeEvaluations :many
+DELETE FROM badge_evaluation
+WHERE user_id IN (
+    SELECT be.user_id FROM badge_evaluation be
+    ORDER BY be.queued_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING user_id;
+
+
+-- ----------------------------------------------------------------------------
+-- 9. GET USER BREW TOTALS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id
+-- Returns: Brews logged, distinct bean origins (case-insensitive) and
+--          distinct brew methods
+-- Usage: Challenge progress
+-- name: GetUserBrewTotals :one
+SELECT
+    COUNT(*) AS brew_count,
+    COUNT(DISTINCT LOWER(NULLIF(TRIM(bean_origin), ''))) AS origin_count,
+    COUNT(DISTINCT brew_method) AS method_count
+FROM brew
+WHERE created_by = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 10. LIST USER METHOD BREW COUNTS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id
+-- Returns: Brews logged per brew method
+-- Usage: Progress on method-specific challenges
+-- name: ListUserMethodBrewCounts :many
+SELECT
+    brew_method::text AS brew_method,
+    COUNT(*) AS brew_count
+FROM brew
+WHERE created_by = $1 AND brew_method IS NOT NULL
+GROUP BY brew_method;
+
+
+-- ----------------------------------------------------------------------------
+-- 11. AWARD BADGE
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id, $2 = challenge_id
+-- Returns: Number of rows inserted (0 if the badge was already earned)
+-- Usage: Badge worker; an award queues its notification in the outbox
+-- name: AwardBadge :execrows
+INSERT INTO user_badge (user_id, challenge_id)
+VALUES ($1, $2)
+ON CONFLICT (user_id, challenge_id) DO NOTHING;
+
+
+-- ----------------------------------------------------------------------------
+-- 12. LIST USER BADGES
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id
+-- Returns: Badges earned, newest first, including from retired challenges
+-- Usage: Profile badges and badge progress
+-- name: ListUserBadges :many
+SELECT
+    c.id AS challenge_id,
+    c.name,
+    c.description,
+    c.metric,
+    c.brew_method,
+    c.threshold,
+    ub.earned_at
+FROM user_badge ub
+JOIN challenge c ON c.id = ub.challenge_id
+WHERE ub.user_id = $1
+ORDER BY ub.earned_at DESC, c.id;
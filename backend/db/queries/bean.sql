@@ -0,0 +1,302 @@
+-- ============================================================================
+-- BEAN BAG QUERIES
+-- ============================================================================
+-- Operations for bean bags: create, read, update, and consumption forecasting
+
+
+-- ----------------------------------------------------------------------------
+-- 1. CREATE BEAN BAG
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id (ULID), $2 = owner_id, $3 = name, $4 = roaster,
+--             $5 = bean_origin, $6 = weight_grams, $7 = roasted_on,
+--             $8 = assumed_dose_grams, $9 = reorder_lead_days,
+--             $10 = price_minor, $11 = currency, $12 = purchased_on,
+--             $13 = origin_country, $14 = origin_region, $15 = farm,
+--             $16 = variety, $17 = altitude_m, $18 = process,
+--             $19 = rest_days
+-- Returns: The created bean bag record
+-- Usage: User adds a new bag of beans (weight already converted to grams,
+--        price to minor units)
+-- name: CreateBeanBag :one
+INSERT INTO bean_bag (
+    id, owner_id, name, roaster, bean_origin, weight_grams, roasted_on,
+    assumed_dose_grams, reorder_lead_days, price_minor, currency, purchased_on,
+    origin_country, origin_region, farm, variety, altitude_m, process, rest_days
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. GET BEAN BAG BY ID
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = bean_bag_id
+-- Returns: Single bean bag record
+-- Usage: View a bag; callers must check owner_id for access
+-- name: GetBeanBagByID :one
+SELECT * FROM bean_bag
+WHERE id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. LIST USER BEAN BAGS
+-- ----------------------------------------------------------------------------
+-- Parameters: owner_id, origin_country, process, variety (NULL matches any)
+-- Returns: The user's bags, open bags first, newest first
+-- Usage: Pantry screen and its origin filters
+-- Performance: Uses idx_bean_bag_owner_id
+-- name: ListUserBeanBags :many
+SELECT * FROM bean_bag
+WHERE owner_id = sqlc.arg(owner_id)
+    AND (sqlc.narg(origin_country)::text IS NULL OR origin_country = sqlc.narg(origin_country))
+    AND (sqlc.narg(process)::text IS NULL OR process = sqlc.narg(process))
+    AND (sqlc.narg(variety)::text IS NULL OR variety = sqlc.narg(variety))
+ORDER BY finished_at IS NOT NULL, created_at DESC;
+
+
+-- ----------------------------------------------------------------------------
+-- 4. UPDATE BEAN BAG
+-- ----------------------------------------------------------------------------
+-- Parameters: id, owner_id, and optional name, weight_grams,
+--             assumed_dose_grams, reorder_lead_days, price_minor, currency,
+--             purchased_on, origin fields, rest_days, finished (NULL keeps
+--             the current value)
+-- Returns: The updated bean bag record, or no rows if not the owner's
+-- Usage: Correct a bag or change its forecast assumptions; changing them
+--        re-arms the reorder suggestion
+-- name: UpdateBeanBag :one
+UPDATE bean_bag
+SET
+    name = COALESCE(sqlc.narg(name), name),
+    weight_grams = COALESCE(sqlc.narg(weight_grams), weight_grams),
+    assumed_dose_grams = COALESCE(sqlc.narg(assumed_dose_grams), assumed_dose_grams),
+    reorder_lead_days = COALESCE(sqlc.narg(reorder_lead_days), reorder_lead_days),
+    price_minor = COALESCE(sqlc.narg(price_minor), price_minor),
+    currency = COALESCE(sqlc.narg(currency), currency),
+    purchased_on = COALESCE(sqlc.narg(purchased_on), purchased_on),
+    origin_country = COALESCE(sqlc.narg(origin_country), origin_country),
+    origin_region = COALESCE(sqlc.narg(origin_region), origin_region),
+    farm = COALESCE(sqlc.narg(farm), farm),
+    variety = COALESCE(sqlc.narg(variety), variety),
+    altitude_m = COALESCE(sqlc.narg(altitude_m), altitude_m),
+    process = COALESCE(sqlc.narg(process), process),
+    rest_days = COALESCE(sqlc.narg(rest_days), rest_days),
+    finished_at = CASE
+        WHEN sqlc.narg(finished)::boolean IS NULL THEN finished_at
+        WHEN sqlc.narg(finished)::boolean THEN COALESCE(finished_at, NOW())
+        ELSE NULL
+    END,
+    reorder_notified_at = NULL
+WHERE id = sqlc.arg(id) AND owner_id = sqlc.arg(owner_id)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 5. GET BEAN BAG USAGE
+-- ----------------------------------------------------------------------------
+-- Parameters: bean_bag_id, default_dose_grams (assumed for brews with no
+--             dose when the bag has no assumption), window_start
+-- Returns: Grams used in total and since window_start, the number of
+--          linked brews, and when the first one was logged
+-- Usage: Bean consumption forecast
+-- Performance: Uses idx_brew_bean_bag
+-- name: GetBeanBagUsage :one
+SELECT
+    COALESCE(SUM(COALESCE(b.dose_grams, bb.assumed_dose_grams, sqlc.arg(default_dose_grams)::float8)), 0)::float8 AS used_grams,
+    COALESCE(SUM(COALESCE(b.dose_grams, bb.assumed_dose_grams, sqlc.arg(default_dose_grams)::float8))
+        FILTER (WHERE b.created_at >= sqlc.arg(window_start)), 0)::float8 AS recent_grams,
+    COUNT(b.id) AS brew_count,
+    MIN(b.created_at)::timestamptz AS first_brew_at
+FROM bean_bag bb
+LEFT JOIN brew b ON b.bean_bag_id = bb.id
+WHERE bb.id = sqlc.arg(bean_bag_id);
+
+
+-- ----------------------------------------------------------------------------
+-- 6. LIST REORDER CANDIDATES
+-- ----------------------------------------------------------------------------
+-- Parameters: default_dose_grams, window_start, after_id (keyset cursor,
+--             '' to start), limit
+-- Returns: Open bags without a reorder suggestion yet, with the same usage
+--          figures as GetBeanBagUsage, ordered by id
+-- Usage: Background reorder check
+-- name: ListReorderCandidates :many
+SELECT
+    bb.id,
+    bb.owner_id,
+    bb.name,
+    bb.weight_grams,
+    bb.reorder_lead_days,
+    COALESCE(SUM(COALESCE(b.dose_grams, bb.assumed_dose_grams, sqlc.arg(default_dose_grams)::float8)), 0)::float8 AS used_grams,
+    COALESCE(SUM(COALESCE(b.dose_grams, bb.assumed_dose_grams, sqlc.arg(default_dose_grams)::float8))
+        FILTER (WHERE b.created_at >= sqlc.arg(window_start)), 0)::float8 AS recent_grams,
+    MIN(b.created_at)::timestamptz AS first_brew_at
+FROM bean_bag bb
+LEFT JOIN brew b ON b.bean_bag_id = bb.id
+WHERE bb.finished_at IS NULL
+    AND bb.reorder_notified_at IS NULL
+    AND bb.id > sqlc.arg(after_id)
+GROUP BY bb.id
+ORDER BY bb.id
+LIMIT sqlc.arg(row_limit);
+
+
+-- ----------------------------------------------------------------------------
+-- 7. MARK BEAN BAG REORDER NOTIFIED
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = bean_bag_id
+-- Returns: Number of rows updated; 0 if another worker got there first
+-- Usage: Claim a bag's reorder suggestion; the claim queues the owner's
+--        notification in the outbox
+-- name: MarkBeanBagReorderNotified :execrows
+UPDATE bean_bag
+SET reorder_notified_at = NOW()
+WHERE id = $1 AND reorder_notified_at IS NULL;
+
+
+-- ----------------------------------------------------------------------------
+-- 8. GET USER MONTHLY BEAN SPEND
+-- ----------------------------------------------------------------------------
+-- Parameters: timezone (IANA name), user_id, since (first month to include)
+-- Returns: Amount spent on priced bags per month and currency, oldest first;
+--          a bag counts in the month it was purchased (or added, if unset)
+-- Usage: Cost stats
+-- Performance: Uses idx_bean_bag_owner_id
+-- name: GetUserMonthlyBeanSpend :many
+SELECT
+    date_trunc('month', COALESCE(purchased_on, (created_at AT TIME ZONE sqlc.arg(timezone)::text)::date))::date AS month,
+    currency::text AS currency,
+    SUM(price_minor)::bigint AS spent_minor,
+    COUNT(*) AS bag_count
+FROM bean_bag
+WHERE owner_id = sqlc.arg(user_id)
+    AND price_minor IS NOT NULL
+    AND COALESCE(purchased_on, (created_at AT TIME ZONE sqlc.arg(timezone)::text)::date) >= sqlc.arg(since)::date
+GROUP BY 1, 2
+ORDER BY 1, 2;
+
+
+-- ----------------------------------------------------------------------------
+-- 9. GET USER MONTHLY BREW COSTS
+-- ----------------------------------------------------------------------------
+-- Parameters: timezone (IANA name), default_dose_grams, user_id, since
+-- Returns: Number of brews from priced bags and their bean cost (fractional
+--          minor units) per local month and currency, oldest first,
+--          archived brews included
+-- Usage: Cost-per-cup stats; a brew costs its dose's share of the bag price
+-- Performance: Uses idx_brew_created_by and idx_brew_archive_created_by
+-- name: GetUserMonthlyBrewCosts :many
+SELECT
+    date_trunc('month', (b.created_at AT TIME ZONE sqlc.arg(timezone)::text))::date AS month,
+    bb.currency::text AS currency,
+    COUNT(*) AS brew_count,
+    SUM(COALESCE(b.dose_grams, bb.assumed_dose_grams, sqlc.arg(default_dose_grams)::float8)
+        * bb.price_minor / bb.weight_grams)::float8 AS cost_minor
+FROM user_brews(sqlc.arg(user_id)) b
+JOIN bean_bag bb ON bb.id = b.bean_bag_id
+WHERE bb.price_minor IS NOT NULL
+    AND (b.created_at AT TIME ZONE sqlc.arg(timezone)::text)::date >= sqlc.arg(since)::date
+GROUP BY 1, 2
+ORDER BY 1, 2;
+
+
+-- ----------------------------------------------------------------------------
+-- 10. GET USER BEAN COST BREAKDOWN
+-- ----------------------------------------------------------------------------
+-- Parameters: group_by ('roaster' or 'origin'), default_dose_grams, user_id
+-- Returns: Per roaster (or origin) and currency: priced bags, amount spent,
+--          brews (archived ones included) and their bean cost, highest
+--          spend first. Bags without a roaster (or origin) are grouped
+--          under a NULL label.
+-- Usage: Cost breakdown screen
+-- name: GetUserBeanCostBreakdown :many
+WITH bags AS (
+    SELECT
+        id,
+        CASE WHEN sqlc.arg(group_by)::text = 'origin' THEN bean_origin ELSE roaster END AS label,
+        currency,
+        price_minor,
+        weight_grams,
+        assumed_dose_grams
+    FROM bean_bag
+    WHERE owner_id = sqlc.arg(user_id) AND price_minor IS NOT NULL
+), usage AS (
+    SELECT
+        b.bean_bag_id,
+        COUNT(*) AS brew_count,
+        SUM(COALESCE(b.dose_grams, bags.assumed_dose_grams, sqlc.arg(default_dose_grams)::float8)) AS grams
+    FROM user_brews(sqlc.arg(user_id)) b
+    JOIN bags ON bags.id = b.bean_bag_id
+    GROUP BY b.bean_bag_id
+)
+SELECT
+    bags.label::text AS label,
+    bags.currency::text AS currency,
+    COUNT(*) AS bag_count,
+    SUM(bags.price_minor)::bigint AS spent_minor,
+    COALESCE(SUM(u.brew_count), 0)::bigint AS brew_count,
+    COALESCE(SUM(u.grams * bags.price_minor / bags.weight_grams), 0)::float8 AS cost_minor
+FROM bags
+LEFT JOIN usage u ON u.bean_bag_id = bags.id
+GROUP BY bags.label, bags.currency
+ORDER BY spent_minor DESC, label;
+
+
+-- ----------------------------------------------------------------------------
+-- 11. GET USER ORIGIN RATINGS
+-- ----------------------------------------------------------------------------
+-- Parameters: group_by ('country', 'region', 'variety' or 'process'), user_id
+-- Returns: Per origin value: the user's brews from bags with that value,
+--          how many of the user's posts about them carry a rating, and the
+--          average of those ratings; best rated first. Bags without the
+--          value are left out.
+-- Usage: "My average rating by process" and similar origin stats
+-- Performance: Uses idx_brew_created_by
+-- name: GetUserOriginRatings :many
+WITH rated AS (
+    SELECT
+        b.id AS brew_id,
+        CASE sqlc.arg(group_by)::text
+            WHEN 'country' THEN bb.origin_country
+            WHEN 'region' THEN bb.origin_region
+            WHEN 'variety' THEN bb.variety
+            ELSE bb.process
+        END AS value,
+        p.rating
+    FROM brew b
+    JOIN bean_bag bb ON bb.id = b.bean_bag_id
+    LEFT JOIN post p ON p.brew_id = b.id AND p.owner_id = b.created_by
+    WHERE b.created_by = sqlc.arg(user_id)
+)
+SELECT
+    value::text AS value,
+    COUNT(DISTINCT brew_id) AS brew_count,
+    COUNT(rating) AS rating_count,
+    AVG(rating)::float8 AS avg_rating
+FROM rated
+WHERE value IS NOT NULL
+GROUP BY value
+ORDER BY avg_rating DESC NULLS LAST, brew_count DESC, value;
+
+
+-- ----------------------------------------------------------------------------
+-- 12. LIST RECENT BEAN BAGS
+-- ----------------------------------------------------------------------------
+-- Parameters: owner_id, row_limit
+-- Returns: The user's open bags, most recently brewed from first; bags not
+--          brewed from yet are ordered by when they were added
+-- Usage: Recent picks on the log-a-brew form
+-- Performance: Uses idx_bean_bag_owner_id and idx_brew_bean_bag
+-- name: ListRecentBeanBags :many
+SELECT
+    bb.id,
+    bb.name,
+    bb.roaster,
+    bb.bean_origin,
+    MAX(b.created_at)::timestamptz AS last_used_at
+FROM bean_bag bb
+LEFT JOIN brew b ON b.bean_bag_id = bb.id
+WHERE bb.owner_id = sqlc.arg(owner_id) AND bb.finished_at IS NULL
+GROUP BY bb.id
+ORDER BY COALESCE(MAX(b.created_at), bb.created_at) DESC NULLS LAST, bb.id
+LIMIT sqlc.arg(row_limit);
@@ -0,0 +1,136 @@
+-- ============================================================================
+-- BEAN CATALOGUE QUERIES
+-- ============================================================================
+-- Operations for the community bean catalogue, roasters' official listings
+-- in it and the official recipes on those listings
+
+
+-- ----------------------------------------------------------------------------
+-- 1. CREATE BEAN
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id, $2 = name, $3 = roaster_name, $4 = bean_origin,
+--             $5 = description, $6 = roaster_id (official listings only),
+--             $7 = created_by, $8 = origin_country, $9 = origin_region,
+--             $10 = farm, $11 = variety, $12 = altitude_m, $13 = process
+-- Returns: The created bean
+-- Usage: Community entry or official listing
+-- name: CreateBean :one
+INSERT INTO bean (
+    id, name, roaster_name, bean_origin, description, roaster_id, created_by,
+    origin_country, origin_region, farm, variety, altitude_m, process
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. GET BEAN BY ID
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id
+-- Returns: The bean
+-- Usage: Bean page and edit checks
+-- name: GetBeanByID :one
+SELECT * FROM bean
+WHERE id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. UPDATE BEAN
+-- ----------------------------------------------------------------------------
+-- Parameters: name, roaster_name, bean_origin, description, origin fields,
+--             id (NULL leaves a field as is)
+-- Returns: The updated bean
+-- Usage: Creator edits a community entry, or a roaster its listing
+-- name: UpdateBean :one
+UPDATE bean
+SET
+    name = COALESCE(sqlc.narg(name), name),
+    roaster_name = COALESCE(sqlc.narg(roaster_name), roaster_name),
+    bean_origin = COALESCE(sqlc.narg(bean_origin), bean_origin),
+    description = COALESCE(sqlc.narg(description), description),
+    origin_country = COALESCE(sqlc.narg(origin_country), origin_country),
+    origin_region = COALESCE(sqlc.narg(origin_region), origin_region),
+    farm = COALESCE(sqlc.narg(farm), farm),
+    variety = COALESCE(sqlc.narg(variety), variety),
+    altitude_m = COALESCE(sqlc.narg(altitude_m), altitude_m),
+    process = COALESCE(sqlc.narg(process), process)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 4. LIST BEANS
+-- ----------------------------------------------------------------------------
+-- Parameters: search (name or roaster contains, case-insensitive; NULL for
+--             all), roaster_id, origin_country, process, variety (NULL
+--             matches any), official_only, row_limit, row_offset
+-- Returns: Beans with official listings first, then by name, with whether
+--          the listing's roaster is verified
+-- Usage: Bean catalogue browsing and roaster pages
+-- name: ListBeans :many
+SELECT
+    b.id,
+    b.name,
+    b.roaster_name,
+    b.bean_origin,
+    b.origin_country,
+    b.variety,
+    b.process,
+    b.roaster_id,
+    (r.verified_at IS NOT NULL)::boolean AS roaster_verified,
+    b.created_at
+FROM bean b
+LEFT JOIN roaster r ON r.id = b.roaster_id
+WHERE (sqlc.narg(search)::text IS NULL
+        OR b.name ILIKE '%' || sqlc.narg(search) || '%'
+        OR b.roaster_name ILIKE '%' || sqlc.narg(search) || '%')
+    AND (sqlc.narg(roaster_id)::text IS NULL OR b.roaster_id = sqlc.narg(roaster_id))
+    AND (sqlc.narg(origin_country)::text IS NULL OR b.origin_country = sqlc.narg(origin_country))
+    AND (sqlc.narg(process)::text IS NULL OR b.process = sqlc.narg(process))
+    AND (sqlc.narg(variety)::text IS NULL OR b.variety = sqlc.narg(variety))
+    AND (b.roaster_id IS NOT NULL OR NOT sqlc.arg(official_only)::boolean)
+ORDER BY (b.roaster_id IS NULL), LOWER(b.name), b.id
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+
+-- ----------------------------------------------------------------------------
+-- 5. ADD BEAN RECIPE
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = bean_id, $2 = recipe_id
+-- Returns: None
+-- Usage: Roaster publishes an official recipe on its listing; publishing
+--        again is a no-op
+-- name: AddBeanRecipe :exec
+INSERT INTO bean_recipe (bean_id, recipe_id)
+VALUES ($1, $2)
+ON CONFLICT (bean_id, recipe_id) DO NOTHING;
+
+
+-- ----------------------------------------------------------------------------
+-- 6. REMOVE BEAN RECIPE
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = bean_id, $2 = recipe_id
+-- Returns: Number of rows deleted
+-- Usage: Roaster unpublishes an official recipe
+-- name: RemoveBeanRecipe :execrows
+DELETE FROM bean_recipe
+WHERE bean_id = $1 AND recipe_id = $2;
+
+
+-- ----------------------------------------------------------------------------
+-- 7. LIST BEAN RECIPES
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = bean_id
+-- Returns: Public official recipes on a listing, oldest published first
+-- Usage: Bean page
+-- name: ListBeanRecipes :many
+SELECT
+    r.id,
+    r.name,
+    r.brew_method,
+    r.current_revision,
+    br.created_at AS published_at
+FROM bean_recipe br
+JOIN recipe r ON r.id = br.recipe_id
+WHERE br.bean_id = $1 AND r.is_public
+ORDER BY br.created_at, r.id;
@@ -0,0 +1,178 @@
+-- ============================================================================
+-- BILLING QUERIES
+-- ============================================================================
+-- Operations for supporter subscriptions: Stripe customers, checkout and
+-- webhook-reported subscription state.
+
+
+-- ----------------------------------------------------------------------------
+-- 1. GET USER BILLING
+-- ----------------------------------------------------------------------------
+-- Parameters: id
+-- Returns: The user's email, plan, Stripe customer and subscription state
+-- Usage: Start a checkout, show the plan endpoint's subscription
+-- name: GetUserBilling :one
+SELECT
+    email,
+    plan,
+    stripe_customer_id,
+    subscription_provider,
+    subscription_status,
+    subscription_period_end,
+    subscription_cancel_at_period_end
+FROM "user"
+WHERE id = sqlc.arg(id);
+
+
+-- ----------------------------------------------------------------------------
+-- 2. SET STRIPE CUSTOMER
+-- ----------------------------------------------------------------------------
+-- Parameters: id, stripe_customer_id
+-- Returns: Rows affected; 0 if the user already has a customer
+-- Usage: First checkout links the Stripe customer created for the user
+-- name: SetStripeCustomer :execrows
+UPDATE "user"
+SET stripe_customer_id = sqlc.arg(stripe_customer_id)
+WHERE id = sqlc.arg(id) AND stripe_customer_id IS NULL;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. APPLY STRIPE SUBSCRIPTION
+-- ----------------------------------------------------------------------------
+-- Parameters: event_id, event_type, subscription_id, status, period_end,
+--             cancel_at_period_end, plan, event_at, customer_id
+-- Returns: The ID of the customer's user
+-- Usage: Stripe webhook. Records the event and applies the subscription
+--        state it carries in one statement, so each event is applied at
+--        most once; returns no row for an event already processed, an
+--        unknown customer, or state older than the user's current state.
+--        A lapsed subscription doesn't end a supporter plan another
+--        provider's subscription grants.
+-- Performance: Uses the stripe_customer_id unique index
+-- name: ApplyStripeSubscription :one
+WITH recorded AS (
+    INSERT INTO stripe_event (id, type)
+    VALUES (sqlc.arg(event_id), sqlc.arg(event_type))
+    ON CONFLICT (id) DO NOTHING
+    RETURNING id
+)
+UPDATE "user"
+SET
+    subscription_provider = 'stripe',
+    subscription_id = sqlc.arg(subscription_id),
+    subscription_status = sqlc.arg(status),
+    subscription_period_end = sqlc.narg(period_end),
+    subscription_cancel_at_period_end = sqlc.arg(cancel_at_period_end),
+    plan = sqlc.arg(plan),
+    subscription_event_at = sqlc.arg(event_at),
+    updated_at = NOW()
+WHERE stripe_customer_id = sqlc.arg(customer_id)
+    AND EXISTS (SELECT 1 FROM recorded)
+    AND (subscription_provider IS DISTINCT FROM 'stripe' OR subscription_event_at IS NULL
+        OR subscription_event_at <= sqlc.arg(event_at))
+    AND (subscription_provider IS NULL OR subscription_provider = 'stripe'
+        OR plan = 'free' OR sqlc.arg(plan) = 'supporter')
+RETURNING id;
+
+
+-- ----------------------------------------------------------------------------
+-- 4. GET IAP PURCHASE
+-- ----------------------------------------------------------------------------
+-- Parameters: provider, purchase_id
+-- Returns: The app store purchase
+-- Usage: Check who a submitted purchase belongs to; find the receipt a
+--        store notification is about
+-- name: GetIAPPurchase :one
+SELECT * FROM iap_purchase
+WHERE provider = sqlc.arg(provider) AND purchase_id = sqlc.arg(purchase_id);
+
+
+-- ----------------------------------------------------------------------------
+-- 5. APPLY IAP PURCHASE
+-- ----------------------------------------------------------------------------
+-- Parameters: provider, purchase_id, user_id, product_id, receipt, status,
+--             expires_at, grace_until, auto_renew, plan
+-- Returns: The ID of the purchase's user
+-- Usage: Receipt submission, store notifications and renewal checks. Saves
+--        a verified purchase and applies its state and plan to its user in
+--        one statement. Returns no row if the purchase belongs to another
+--        user, or if it lapsed while another subscription makes the user a
+--        supporter.
+-- name: ApplyIAPPurchase :one
+WITH saved AS (
+    INSERT INTO iap_purchase (
+        provider, purchase_id, user_id, product_id, receipt,
+        status, expires_at, grace_until, auto_renew
+    )
+    VALUES (
+        sqlc.arg(provider), sqlc.arg(purchase_id), sqlc.arg(user_id), sqlc.arg(product_id), sqlc.arg(receipt),
+        sqlc.arg(status), sqlc.arg(expires_at), sqlc.narg(grace_until), sqlc.arg(auto_renew)
+    )
+    ON CONFLICT (provider, purchase_id) DO UPDATE
+    SET
+        product_id = EXCLUDED.product_id,
+        receipt = EXCLUDED.receipt,
+        status = EXCLUDED.status,
+        expires_at = EXCLUDED.expires_at,
+        grace_until = EXCLUDED.grace_until,
+        auto_renew = EXCLUDED.auto_renew,
+        verified_at = NOW()
+    WHERE iap_purchase.user_id = EXCLUDED.user_id
+    RETURNING user_id
+)
+UPDATE "user" u
+SET
+    subscription_provider = sqlc.arg(provider),
+    subscription_id = sqlc.arg(purchase_id),
+    subscription_status = sqlc.arg(status),
+    subscription_period_end = sqlc.arg(expires_at),
+    subscription_cancel_at_period_end = NOT sqlc.arg(auto_renew)::boolean,
+    plan = sqlc.arg(plan),
+    subscription_event_at = NOW(),
+    updated_at = NOW()
+FROM saved
+WHERE u.id = saved.user_id
+    AND (u.subscription_provider IS NULL
+        OR (u.subscription_provider = sqlc.arg(provider) AND u.subscription_id = sqlc.arg(purchase_id))
+        OR u.plan = 'free' OR sqlc.arg(plan) = 'supporter')
+RETURNING u.id;
+
+
+-- ----------------------------------------------------------------------------
+-- 6. LIST DUE IAP PURCHASES
+-- ----------------------------------------------------------------------------
+-- Parameters: row_limit
+-- Returns: Live purchases whose paid or grace period ends within the hour,
+--          not verified in the last hour, least recently verified first
+-- Usage: Renewal checks verify them again, since stores don't notify every
+--        renewal or expiry
+-- Performance: Uses idx_iap_purchase_renewal
+-- name: ListDueIAPPurchases :many
+SELECT * FROM iap_purchase
+WHERE status IN ('active', 'grace_period', 'billing_retry')
+    AND COALESCE(grace_until, expires_at) < NOW() + INTERVAL '1 hour'
+    AND verified_at < NOW() - INTERVAL '1 hour'
+ORDER BY verified_at
+LIMIT sqlc.arg(row_limit);
+
+
+-- ----------------------------------------------------------------------------
+-- 7. EXPIRE IAP SUBSCRIPTIONS
+-- ----------------------------------------------------------------------------
+-- Parameters: None
+-- Returns: Rows affected
+-- Usage: Renewal checks end the supporter plan of users whose app store
+--        subscription ran out over a day ago and couldn't be verified since,
+--        so a store outage can't extend it indefinitely
+-- name: ExpireIAPSubscriptions :execrows
+UPDATE "user" u
+SET
+    plan = 'free',
+    subscription_status = 'expired',
+    updated_at = NOW()
+FROM iap_purchase p
+WHERE p.provider = u.subscription_provider
+    AND p.purchase_id = u.subscription_id
+    AND u.plan = 'supporter'
+    AND COALESCE(p.grace_until, p.expires_at) < NOW() - INTERVAL '1 day'
+    AND p.verified_at < NOW() - INTERVAL '1 day';
@@ -0,0 +1,426 @@
+-- ============================================================================
+-- BREW QUERIES
+-- ============================================================================
+-- Operations for logged brews: create, read, and brew history
+
+
+-- ----------------------------------------------------------------------------
+-- 1. CREATE BREW
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id (ULID), $2 = name, $3 = brew_method, $4 = bean_origin,
+--             $5 = roaster, $6 = notes, $7 = created_by, $8 = is_public,
+--             $9 = dose_grams, $10 = water_grams, $11 = water_temp_c,
+--             $12 = grind_setting, $13 = brew_time_seconds, $14 = started_at,
+--             $15 = ended_at, $16 = recipe_id, $17 = recipe_revision,
+--             $18 = bean_bag_id, $19 = custom_fields (NULL for none),
+--             $20 = created_at (NULL for now; set when logging past brews),
+--             $21 = sync_vector (NULL unless pushed by an offline client),
+--             $22 = organization_id (NULL for a personal brew)
+-- Returns: The created brew record
+-- Usage: User logs a new brew (values already converted to grams / Celsius,
+--        custom fields already validated)
+-- name: CreateBrew :one
+INSERT INTO brew (
+    id, name, brew_method, bean_origin, roaster, notes, created_by, is_public,
+    dose_grams, water_grams, water_temp_c, grind_setting, brew_time_seconds,
+    started_at, ended_at, recipe_id, recipe_revision, bean_bag_id, custom_fields,
+    created_at, sync_vector, organization_id
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18,
+        COALESCE($19::jsonb, '{}'), COALESCE($20::timestamptz, NOW()), COALESCE($21::jsonb, '{}'), $22)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. GET BREW BY ID
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = brew_id
+-- Returns: Single brew record
+-- Usage: View a brew; callers must check is_public / created_by for access
+-- name: GetBrewByID :one
+SELECT * FROM brew
+WHERE id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. LIST USER BREWS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id, $2 = limit, $3 = offset
+-- Returns: The user's personal brews, newest first
+-- Usage: Brew history screen
+-- Performance: Uses idx_brew_created_by
+-- name: ListUserBrews :many
+SELECT * FROM brew
+WHERE created_by = $1 AND organization_id IS NULL
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+
+-- ----------------------------------------------------------------------------
+-- 4. GET USER BREW DAYS
+-- ----------------------------------------------------------------------------
+-- Parameters: user_id, timezone (IANA name, e.g. 'America/New_York')
+-- Returns: Personal brew count per local calendar day, newest first,
+--          archived brews included
+-- Usage: Stats and streaks, bucketed by the user's own day boundaries
+-- Performance: Uses idx_brew_created_by and idx_brew_archive_created_by
+-- name: GetUserBrewDays :many
+SELECT
+    (created_at AT TIME ZONE sqlc.arg(timezone)::text)::date AS brew_date,
+    COUNT(*) AS brew_count
+FROM user_brews(sqlc.arg(user_id))
+GROUP BY brew_date
+ORDER BY brew_date DESC;
+
+
+-- ----------------------------------------------------------------------------
+-- 5. START BREW TIMER
+-- ----------------------------------------------------------------------------
+-- Parameters: brew_id, user_id, remind_in_seconds (NULL for no reminder)
+-- Returns: The updated brew record
+-- Usage: Start (or restart) a timer session; clears any previous result
+-- name: StartBrewTimer :one
+UPDATE brew
+SET
+    started_at = NOW(),
+    ended_at = NULL,
+    brew_time_seconds = NULL,
+    remind_at = NOW() + sqlc.narg(remind_in_seconds)::int * INTERVAL '1 second',
+    reminder_sent_at = NULL,
+    updated_at = NOW()
+WHERE id = sqlc.arg(id) AND created_by = sqlc.arg(user_id)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 6. STOP BREW TIMER
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = brew_id, $2 = user_id
+-- Returns: The updated brew record, or no rows if no timer is running
+-- Usage: End a timer session and record the elapsed brew time
+-- name: StopBrewTimer :one
+UPDATE brew
+SET
+    ended_at = NOW(),
+    brew_time_seconds = EXTRACT(EPOCH FROM NOW() - started_at)::int,
+    remind_at = NULL,
+    updated_at = NOW()
+WHERE id = $1 AND created_by = $2
+    AND started_at IS NOT NULL
+    AND ended_at IS NULL
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 7. CLAIM DUE BREW REMINDERS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = limit
+-- Returns: Running brews whose reminder is due, marked as sent
+-- Usage: Reminder worker; SKIP LOCKED lets several instances poll safely.
+--        Marking a reminder sent queues its notification in the outbox.
+-- Performance: Uses idx_brew_remind_at
+-- name: ClaimDueBrewReminders :many
+UPDATE brew
+SET reminder_sent_at = NOW()
+WHERE id IN (
+    SELECT b.id FROM brew b
+    WHERE b.remind_at <= NOW()
+        AND b.reminder_sent_at IS NULL
+        AND b.ended_at IS NULL
+        AND b.created_by IS NOT NULL
+    ORDER BY b.remind_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, name, created_by;
+
+
+-- ----------------------------------------------------------------------------
+-- 8. GET USER BREW TIME STATS
+-- ----------------------------------------------------------------------------
+-- Parameters: user_id, long_methods (brew methods timed in hours/days)
+-- Returns: Average brew time for regular and long-duration methods, kept
+--          apart so cold brews don't skew the regular average, archived
+--          brews included
+-- Usage: Stats page
+-- name: GetUserBrewTimeStats :one
+SELECT
+    COALESCE(AVG(brew_time_seconds) FILTER (
+        WHERE brew_method IS NULL OR NOT (brew_method = ANY(sqlc.arg(long_methods)::text[]))
+    ), 0)::float8 AS avg_brew_time_seconds,
+    COALESCE(AVG(brew_time_seconds) FILTER (
+        WHERE brew_method = ANY(sqlc.arg(long_methods)::text[])
+    ), 0)::float8 AS avg_long_brew_time_seconds
+FROM user_brews(sqlc.arg(user_id))
+WHERE brew_time_seconds IS NOT NULL;
+
+
+-- ----------------------------------------------------------------------------
+-- 9. GET VISIBLE BREWS BY IDS
+-- ----------------------------------------------------------------------------
+-- Parameters: ids (brew IDs), viewer_id
+-- Returns: The matching brews the viewer may see, as in GetVisibleBrew, in
+--          no particular order; missing and hidden IDs are skipped
+-- Usage: Brew comparison
+-- name: GetVisibleBrewsByIDs :many
+SELECT b.* FROM brew b
+WHERE b.id = ANY(sqlc.arg(ids)::text[])
+  AND (b.created_by = sqlc.arg(viewer_id)
+       OR b.is_public IS NOT FALSE
+       OR EXISTS (
+           SELECT 1 FROM resource_share s
+           WHERE s.brew_id = b.id
+             AND (s.user_id = sqlc.arg(viewer_id)
+                  OR s.club_id IN (SELECT cm.club_id FROM club_member cm WHERE cm.user_id = sqlc.arg(viewer_id)))
+       ));
+
+
+-- ----------------------------------------------------------------------------
+-- 10. GET BREW RATINGS
+-- ----------------------------------------------------------------------------
+-- Parameters: brew_ids (brew IDs)
+-- Returns: Average tasting score and number of scores per brew, from the
+--          brewer's own rated posts; unrated brews are omitted
+-- Usage: Brew comparison
+-- Performance: Uses idx_post_brew_id
+-- name: GetBrewRatings :many
+SELECT
+    b.id AS brew_id,
+    AVG(p.rating)::float8 AS avg_rating,
+    COUNT(p.rating) AS rating_count
+FROM brew b
+JOIN post p ON p.brew_id = b.id AND p.owner_id = b.created_by
+WHERE b.id = ANY(sqlc.arg(brew_ids)::text[])
+    AND p.rating IS NOT NULL
+GROUP BY b.id;
+
+
+-- ----------------------------------------------------------------------------
+-- 11. GET LATEST SIMILAR BREW
+-- ----------------------------------------------------------------------------
+-- Parameters: created_by, brew_method, bean_bag_id (NULL for any bag)
+-- Returns: The user's most recent brew with the method, preferring one from
+--          the given bag; no rows if they never used the method
+-- Usage: Quick log fills in the parameters of the brew being repeated
+-- Performance: Uses idx_brew_created_by
+-- name: GetLatestSimilarBrew :one
+SELECT * FROM brew
+WHERE created_by = sqlc.arg(created_by) AND brew_method = sqlc.arg(brew_method)
+ORDER BY COALESCE(bean_bag_id = sqlc.narg(bean_bag_id), false) DESC, created_at DESC, id DESC
+LIMIT 1;
+
+
+-- ----------------------------------------------------------------------------
+-- 12. CREATE BREW CUSTOM FIELD
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id (ULID), $2 = user_id, $3 = name, $4 = type, $5 = unit
+-- Returns: The created field
+-- Usage: User adds a field to their brews; fails on a duplicate name
+-- name: CreateBrewCustomField :one
+INSERT INTO brew_custom_field (id, user_id, name, type, unit)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 13. LIST USER BREW CUSTOM FIELDS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id
+-- Returns: The user's custom fields by name
+-- Usage: Field settings, and validating custom fields on brews
+-- name: ListUserBrewCustomFields :many
+SELECT * FROM brew_custom_field
+WHERE user_id = $1
+ORDER BY name;
+
+
+-- ----------------------------------------------------------------------------
+-- 14. UPDATE BREW CUSTOM FIELD
+-- ----------------------------------------------------------------------------
+-- Parameters: unit (NULL clears it), id, user_id
+-- Returns: The updated field, or no rows if not the user's
+-- Usage: Change a field's unit label; names and types are fixed once
+--        values are stored under them
+-- name: UpdateBrewCustomField :one
+UPDATE brew_custom_field
+SET unit = sqlc.narg(unit)
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 15. DELETE BREW CUSTOM FIELD
+-- ----------------------------------------------------------------------------
+-- Parameters: id, user_id
+-- Returns: The deleted field's name, or no rows if not the user's
+-- Usage: User removes a field; its values are removed from their brews
+-- Performance: Uses idx_brew_created_by
+-- name: DeleteBrewCustomField :one
+WITH deleted AS (
+    DELETE FROM brew_custom_field
+    WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id)
+    RETURNING user_id, name
+), cleared AS (
+    UPDATE brew b
+    SET custom_fields = b.custom_fields - d.name
+    FROM deleted d
+    WHERE b.created_by = d.user_id AND b.custom_fields -> d.name IS NOT NULL
+    RETURNING b.id
+)
+SELECT name FROM deleted;
+
+
+-- ----------------------------------------------------------------------------
+-- 16. GET USER CUSTOM FIELD RATINGS
+-- ----------------------------------------------------------------------------
+-- Parameters: name (custom field), user_id
+-- Returns: Per value of the field: the user's brews with that value, how
+--          many of the user's posts about them carry a rating, and the
+--          average of those ratings; best rated first. Brews without a
+--          value are left out.
+-- Usage: "My average rating by filter paper" and similar custom field stats
+-- Performance: Uses idx_brew_created_by
+-- name: GetUserCustomFieldRatings :many
+WITH rated AS (
+    SELECT
+        b.id AS brew_id,
+        b.custom_fields ->> sqlc.arg(name)::text AS value,
+        p.rating
+    FROM brew b
+    LEFT JOIN post p ON p.brew_id = b.id AND p.owner_id = b.created_by
+    WHERE b.created_by = sqlc.arg(user_id)
+)
+SELECT
+    value::text AS value,
+    COUNT(DISTINCT brew_id) AS brew_count,
+    COUNT(rating) AS rating_count,
+    AVG(rating)::float8 AS avg_rating
+FROM rated
+WHERE value IS NOT NULL
+GROUP BY value
+ORDER BY avg_rating DESC NULLS LAST, brew_count DESC, value;
+
+
+-- ----------------------------------------------------------------------------
+-- 17. UPDATE BREW
+-- ----------------------------------------------------------------------------
+-- Parameters: id, created_by, every editable column (already converted and
+--             validated as for CreateBrew), created_at (NULL keeps it),
+--             sync_vector (NULL for server-side edits)
+-- Returns: The updated brew; no rows if it isn't the user's
+-- Usage: Applying a brew edited on an offline client
+-- name: UpdateBrew :one
+UPDATE brew
+SET name = sqlc.arg(name),
+    brew_method = sqlc.narg(brew_method),
+    bean_origin = sqlc.narg(bean_origin),
+    roaster = sqlc.narg(roaster),
+    notes = sqlc.narg(notes),
+    is_public = sqlc.narg(is_public),
+    dose_grams = sqlc.narg(dose_grams),
+    water_grams = sqlc.narg(water_grams),
+    water_temp_c = sqlc.narg(water_temp_c),
+    grind_setting = sqlc.narg(grind_setting),
+    brew_time_seconds = sqlc.narg(brew_time_seconds),
+    started_at = sqlc.narg(started_at),
+    ended_at = sqlc.narg(ended_at),
+    recipe_id = sqlc.narg(recipe_id),
+    recipe_revision = sqlc.narg(recipe_revision),
+    bean_bag_id = sqlc.narg(bean_bag_id),
+    custom_fields = COALESCE(sqlc.narg(custom_fields)::jsonb, '{}'),
+    created_at = COALESCE(sqlc.narg(created_at)::timestamptz, created_at),
+    sync_vector = COALESCE(sqlc.narg(sync_vector)::jsonb, sync_vector)
+WHERE id = sqlc.arg(id) AND created_by = sqlc.arg(created_by)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 18. LIST RECENT BREW METHODS
+-- ----------------------------------------------------------------------------
+-- Parameters: user_id, row_limit
+-- Returns: The methods the user has brewed with, most recently used first,
+--          with how many brews used each
+-- Usage: Recent picks on the log-a-brew form
+-- Performance: Uses idx_brew_created_by
+-- name: ListRecentBrewMethods :many
+SELECT
+    brew_method::text AS brew_method,
+    COUNT(*) AS brew_count,
+    MAX(created_at)::timestamptz AS last_used_at
+FROM brew
+WHERE created_by = sqlc.arg(user_id) AND brew_method IS NOT NULL
+GROUP BY brew_method
+ORDER BY last_used_at DESC NULLS LAST, brew_method
+LIMIT sqlc.arg(row_limit);
+
+
+-- ----------------------------------------------------------------------------
+-- 19. LIST RECENT GRIND SETTINGS
+-- ----------------------------------------------------------------------------
+-- Parameters: user_id, row_limit
+-- Returns: The grind settings the user has brewed with per method, most
+--          recently used first
+-- Usage: Recent picks on the log-a-brew form
+-- Performance: Uses idx_brew_created_by
+-- name: ListRecentGrindSettings :many
+SELECT
+    grind_setting::text AS grind_setting,
+    brew_method,
+    COUNT(*) AS brew_count,
+    MAX(created_at)::timestamptz AS last_used_at
+FROM brew
+WHERE created_by = sqlc.arg(user_id) AND grind_setting IS NOT NULL
+GROUP BY grind_setting, brew_method
+ORDER BY last_used_at DESC NULLS LAST, grind_setting
+LIMIT sqlc.arg(row_limit);
+
+
+-- ----------------------------------------------------------------------------
+-- 20. AUTOCOMPLETE ROASTERS
+-- ----------------------------------------------------------------------------
+-- Parameters: user_id, prefix (matched case-insensitively), row_limit
+-- Returns: Roaster names starting with prefix, from the user's brews and
+--          bags, registered roasters and the bean catalogue. Names differing
+--          only in case are merged under their most common spelling; names
+--          the user has used come first, then the most used.
+-- Usage: Roaster field autocomplete
+-- name: AutocompleteRoasters :many
+WITH names AS (
+    SELECT roaster AS name, 1 AS own FROM brew WHERE created_by = sqlc.arg(user_id)
+    UNION ALL
+    SELECT roaster, 1 FROM bean_bag WHERE owner_id = sqlc.arg(user_id)
+    UNION ALL
+    SELECT name, 0 FROM roaster
+    UNION ALL
+    SELECT roaster_name, 0 FROM bean
+)
+SELECT (MODE() WITHIN GROUP (ORDER BY name))::text AS name
+FROM names
+WHERE starts_with(LOWER(name), LOWER(sqlc.arg(prefix)::text))
+GROUP BY LOWER(name)
+ORDER BY SUM(own) DESC, COUNT(*) DESC, LOWER(name)
+LIMIT sqlc.arg(row_limit);
+
+
+-- ----------------------------------------------------------------------------
+-- 21. AUTOCOMPLETE ORIGINS
+-- ----------------------------------------------------------------------------
+-- Parameters: user_id, prefix (matched case-insensitively), row_limit
+-- Returns: Bean origins starting with prefix, from the user's brews and
+--          bags and the bean catalogue, merged and ordered as for
+--          AutocompleteRoasters
+-- Usage: Bean origin field autocomplete
+-- name: AutocompleteOrigins :many
+WITH names AS (
+    SELECT bean_origin AS name, 1 AS own FROM brew WHERE created_by = sqlc.arg(user_id)
+    UNION ALL
+    SELECT bean_origin, 1 FROM bean_bag WHERE owner_id = sqlc.arg(user_id)
+    UNION ALL
+    SELECT bean_origin, 0 FROM bean
+)
+SELECT (MODE() WITHIN GROUP (ORDER BY name))::text AS name
+FROM names
+WHERE starts_with(LOWER(name), LOWER(sqlc.arg(prefix)::text))
+GROUP BY LOWER(name)
+ORDER BY SUM(own) DESC, COUNT(*) DESC, LOWER(name)
+LIMIT sqlc.arg(row_limit);
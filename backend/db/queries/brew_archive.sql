@@ -0,0 +1,130 @@
+-- ============================================================================
+-- BREW ARCHIVE QUERIES
+-- ============================================================================
+-- Moving old brews into cold storage, and reading them back in history
+
+
+-- ----------------------------------------------------------------------------
+-- 1. ARCHIVE BREWS
+-- ----------------------------------------------------------------------------
+-- Parameters: after_days, batch_size
+-- Returns: Number of brews archived
+-- Usage: Archive job; moves up to batch_size brews created more than
+--        after_days ago into brew_archive, with their flavors and pour
+--        curve, and deletes them from brew. Brews a post, share, TDS
+--        reading or photo links to are left in place, so nothing loses its
+--        brew, as are organization brews, which stay in the organization's
+--        log, and brews whose pour stream was never compacted, which
+--        would lose their staged samples.
+--        Archived brews get no sync tombstone (see sync_tombstone), so
+--        offline clients keep them.
+-- Performance: Uses idx_brew_created_at; SKIP LOCKED lets instances archive
+--              at once without waiting on each other or on users' writes
+-- name: ArchiveBrews :execrows
+WITH candidate AS (
+    SELECT b.id
+    FROM brew b
+    WHERE b.created_at < NOW() - sqlc.arg(after_days)::int * INTERVAL '1 day'
+      AND b.organization_id IS NULL
+      AND NOT EXISTS (SELECT 1 FROM post p WHERE p.brew_id = b.id)
+      AND NOT EXISTS (SELECT 1 FROM club_share cs WHERE cs.brew_id = b.id)
+      AND NOT EXISTS (SELECT 1 FROM resource_share rs WHERE rs.brew_id = b.id)
+      AND NOT EXISTS (SELECT 1 FROM tds_reading t WHERE t.brew_id = b.id)
+      AND NOT EXISTS (SELECT 1 FROM brew_photo bp WHERE bp.brew_id = b.id)
+      AND NOT EXISTS (SELECT 1 FROM pour_curve pc JOIN pour_sample ps ON ps.curve_id = pc.id
+                      WHERE pc.brew_id = b.id)
+    ORDER BY b.created_at
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+),
+archived AS (
+    INSERT INTO brew_archive (id, created_by, created_at, data)
+    SELECT b.id, b.created_by, b.created_at, jsonb_build_object(
+        'brew', to_jsonb(b),
+        'flavors', COALESCE((SELECT jsonb_agg(bf.descriptor_id ORDER BY bf.descriptor_id)
+                             FROM brew_flavor bf WHERE bf.brew_id = b.id), '[]'::jsonb),
+        'pour_curve', (SELECT to_jsonb(pc) FROM pour_curve pc WHERE pc.brew_id = b.id)
+    )
+    FROM brew b
+    JOIN candidate c ON c.id = b.id
+    RETURNING brew_archive.id
+)
+DELETE FROM brew
+WHERE id IN (SELECT id FROM archived);
+
+
+-- ----------------------------------------------------------------------------
+-- 2. LIST USER BREW HISTORY
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id, $2 = limit, $3 = offset
+-- Returns: The user's personal brews, live and archived, newest first,
+--          each with whether it's archived. Brews logged in organizations
+--          are listed by the organization instead.
+-- Usage: Brew history screen
+-- Performance: Each side reads at most limit + offset rows by
+--              idx_brew_created_by and idx_brew_archive_created_by, so only
+--              that many archived documents are decompressed
+-- name: ListUserBrewHistory :many
+(SELECT sqlc.embed(b), false AS archived
+ FROM brew b
+ WHERE b.created_by = $1 AND b.organization_id IS NULL
+ ORDER BY b.created_at DESC
+ LIMIT $2 + $3)
+UNION ALL
+(SELECT ab.*, true AS archived
+ FROM (
+     SELECT id, data
+     FROM brew_archive
+     WHERE created_by = $1
+     ORDER BY created_at DESC
+     LIMIT $2 + $3
+ ) a
+ CROSS JOIN LATERAL jsonb_populate_record(NULL::brew, a.data->'brew') ab)
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. GET ARCHIVED BREW
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = brew_id, $2 = user_id
+-- Returns: The archived brew, if user_id logged it
+-- Usage: Viewing an archived brew; only its owner sees it
+-- Performance: Primary key lookup
+-- name: GetArchivedBrew :one
+SELECT ab.*
+FROM brew_archive a
+CROSS JOIN LATERAL jsonb_populate_record(NULL::brew, a.data->'brew') ab
+WHERE a.id = $1 AND a.created_by = $2;
+
+
+-- ----------------------------------------------------------------------------
+-- 4. LIST ARCHIVED BREW FLAVORS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = brew_id
+-- Returns: The archived brew's descriptors in wheel order
+-- Usage: Showing an archived brew's tasting notes, once its owner has
+--        loaded it
+-- Performance: Primary key lookup
+-- name: ListArchivedBrewFlavors :many
+SELECT fd.*
+FROM brew_archive a
+CROSS JOIN LATERAL jsonb_array_elements_text(a.data->'flavors') f(descriptor_id)
+JOIN flavor_descriptor fd ON fd.id = f.descriptor_id
+WHERE a.id = $1
+ORDER BY fd.id;
+
+
+-- ----------------------------------------------------------------------------
+-- 5. GET ARCHIVED BREW POUR CURVE
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = brew_id
+-- Returns: The archived brew's pour curve, if it had one. Only compacted
+--          curves are archived, so its samples are all in the row.
+-- Usage: Charting an archived brew, once its owner has loaded it
+-- Performance: Primary key lookup
+-- name: GetArchivedBrewPourCurve :one
+SELECT pc.*
+FROM brew_archive a
+CROSS JOIN LATERAL jsonb_populate_record(NULL::pour_curve, a.data->'pour_curve') pc
+WHERE a.id = $1 AND jsonb_typeof(a.data->'pour_curve') = 'object';
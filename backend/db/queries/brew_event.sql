@@ -0,0 +1,151 @@
+-- ============================================================================
+-- BREW EVENT QUERIES
+-- ============================================================================
+-- Operations for brew-along events: scheduling, RSVPs and the shared timer
+
+
+-- ----------------------------------------------------------------------------
+-- 1. CREATE BREW EVENT
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id, $2 = host_id, $3 = title, $4 = description,
+--             $5 = recipe_id, $6 = recipe_revision, $7 = starts_at
+-- Returns: The created event
+-- Usage: Host schedules a brew-along
+-- name: CreateBrewEvent :one
+INSERT INTO brew_event (id, host_id, title, description, recipe_id, recipe_revision, starts_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. GET BREW EVENT BY ID
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id
+-- Returns: The event
+-- Usage: Event detail page and access checks
+-- name: GetBrewEventByID :one
+SELECT * FROM brew_event
+WHERE id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. LIST UPCOMING BREW EVENTS
+-- ----------------------------------------------------------------------------
+-- Parameters: since (events starting at or after), row_limit, row_offset
+-- Returns: Events soonest first, with host usernames, recipe names and how
+--          many users are going
+-- Usage: Browse upcoming brew-alongs
+-- name: ListUpcomingBrewEvents :many
+SELECT
+    be.id,
+    be.host_id,
+    u.username AS host_username,
+    be.title,
+    be.recipe_id,
+    r.name AS recipe_name,
+    be.starts_at,
+    be.timer_started_at,
+    be.timer_ended_at,
+    (
+        SELECT COUNT(*) FROM brew_event_rsvp
+        WHERE event_id = be.id AND status = 'going'
+    ) AS going_count
+FROM brew_event be
+JOIN "user" u ON u.id = be.host_id
+JOIN recipe r ON r.id = be.recipe_id
+WHERE be.starts_at >= sqlc.arg(since)
+ORDER BY be.starts_at, be.id
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+
+-- ----------------------------------------------------------------------------
+-- 4. DELETE BREW EVENT
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id
+-- Returns: Nothing
+-- Usage: Host cancels an event (RSVPs CASCADE)
+-- name: DeleteBrewEvent :exec
+DELETE FROM brew_event
+WHERE id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 5. UPSERT BREW EVENT RSVP
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = event_id, $2 = user_id, $3 = status
+-- Returns: The RSVP
+-- Usage: User answers 'going', 'maybe' or 'not_going', or changes their answer
+-- name: UpsertBrewEventRSVP :one
+INSERT INTO brew_event_rsvp (event_id, user_id, status)
+VALUES ($1, $2, $3)
+ON CONFLICT (event_id, user_id) DO UPDATE SET status = EXCLUDED.status
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 6. GET USER BREW EVENT RSVP
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = event_id, $2 = user_id
+-- Returns: The user's RSVP status, or no rows if they haven't answered
+-- Usage: Show the viewer's own answer on the event page
+-- name: GetUserBrewEventRSVP :one
+SELECT status FROM brew_event_rsvp
+WHERE event_id = $1 AND user_id = $2;
+
+
+-- ----------------------------------------------------------------------------
+-- 7. LIST BREW EVENT RSVPS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = event_id
+-- Returns: Users going or maybe going, with usernames, going first
+-- Usage: Attendee list
+-- name: ListBrewEventRSVPs :many
+SELECT
+    ber.user_id,
+    u.username,
+    ber.status,
+    ber.updated_at
+FROM brew_event_rsvp ber
+JOIN "user" u ON u.id = ber.user_id
+WHERE ber.event_id = $1 AND ber.status <> 'not_going'
+ORDER BY ber.status, ber.created_at;
+
+
+-- ----------------------------------------------------------------------------
+-- 8. GET BREW EVENT RSVP COUNTS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = event_id
+-- Returns: How many users are going and maybe going
+-- Usage: Event summaries and realtime RSVP updates
+-- name: GetBrewEventRSVPCounts :one
+SELECT
+    COUNT(*) FILTER (WHERE status = 'going') AS going,
+    COUNT(*) FILTER (WHERE status = 'maybe') AS maybe
+FROM brew_event_rsvp
+WHERE event_id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 9. START BREW EVENT TIMER
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id
+-- Returns: The event with its timer (re)started now
+-- Usage: Host starts the shared timer; attendees are notified in realtime
+-- name: StartBrewEventTimer :one
+UPDATE brew_event
+SET timer_started_at = NOW(), timer_ended_at = NULL
+WHERE id = $1
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 10. STOP BREW EVENT TIMER
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id
+-- Returns: The event with its timer stopped, or no rows if none was running
+-- Usage: Host stops the shared timer
+-- name: StopBrewEventTimer :one
+UPDATE brew_event
+SET timer_ended_at = NOW()
+WHERE id = $1 AND timer_started_at IS NOT NULL AND timer_ended_at IS NULL
+RETURNING *;
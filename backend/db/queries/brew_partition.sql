@@ -0,0 +1,48 @@
+-- ============================================================================
+-- BREW PARTITION QUERIES
+-- ============================================================================
+-- Maintenance of the monthly partitions of the brew table: creating them
+-- ahead and detaching those past retention
+
+
+-- ----------------------------------------------------------------------------
+-- 1. ENSURE BREW PARTITION
+-- ----------------------------------------------------------------------------
+-- Parameters: month
+-- Returns: Whether the partition for month's UTC month was created, false
+--          if it already existed
+-- Usage: Creating partitions ahead of the brews that will fill them
+-- Performance: Scans brew_default for rows in the new range, normally none
+-- name: EnsureBrewPartition :one
+SELECT ensure_brew_partition(sqlc.arg(month)::date) AS created;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. LIST BREW PARTITIONS
+-- ----------------------------------------------------------------------------
+-- Parameters: none
+-- Returns: The monthly partitions of brew and the month each holds, oldest
+--          first. brew_legacy and brew_default aren't listed.
+-- Usage: Finding partitions past retention
+-- Performance: Reads the catalog
+-- name: ListBrewPartitions :many
+SELECT c.relname::text AS name,
+       to_date(substr(c.relname, 7), 'YYYY_MM') AS month
+FROM pg_inherits i
+JOIN pg_class c ON c.oid = i.inhrelid
+WHERE i.inhparent = 'brew'::regclass
+  AND c.relname ~ '^brew_p[0-9]{4}_[0-9]{2}$'
+ORDER BY c.relname;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. DETACH BREW PARTITION
+-- ----------------------------------------------------------------------------
+-- Parameters: partition_name
+-- Returns: The name of the detached table
+-- Usage: Taking a partition past retention out of brew. Fails with a
+--        foreign key violation while other rows reference its brews.
+-- Performance: Checks every table referencing brew for rows referencing the
+--              partition
+-- name: DetachBrewPartition :one
+SELECT detach_brew_partition(sqlc.arg(partition_name)::text) AS detached_name;
@@ -0,0 +1,250 @@
+-- ============================================================================
+-- BREW PHOTO QUERIES
+-- ============================================================================
+-- Photos attached to brews, and the queue the photo processor works
+-- through after each upload
+
+
+-- ----------------------------------------------------------------------------
+-- 1. CREATE BREW PHOTO
+-- ----------------------------------------------------------------------------
+-- Parameters: id, brew_id, user_id, content_type, original_key,
+--             original_bytes
+-- Returns: The photo, pending processing
+-- Usage: Uploading a photo, once the original is stored
+-- name: CreateBrewPhoto :one
+INSERT INTO brew_photo (id, brew_id, user_id, content_type, original_key, original_bytes)
+VALUES (
+    sqlc.arg(id),
+    sqlc.arg(brew_id),
+    sqlc.arg(user_id),
+    sqlc.arg(content_type),
+    sqlc.arg(original_key),
+    sqlc.arg(original_bytes)
+)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. LIST BREW PHOTOS
+-- ----------------------------------------------------------------------------
+-- Parameters: brew_id
+-- Returns: The brew's photos, oldest first, whatever their status
+-- Usage: Brew photo gallery; callers show only ready photos to others
+-- Performance: Uses idx_brew_photo_brew
+-- name: ListBrewPhotos :many
+SELECT * FROM brew_photo
+WHERE brew_id = sqlc.arg(brew_id)
+ORDER BY created_at, id;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. GET BREW PHOTO
+-- ----------------------------------------------------------------------------
+-- Parameters: id, brew_id
+-- Returns: The photo, if it belongs to the brew
+-- Usage: Photo status and image downloads
+-- Performance: Uses the primary key
+-- name: GetBrewPhoto :one
+SELECT * FROM brew_photo
+WHERE id = sqlc.arg(id) AND brew_id = sqlc.arg(brew_id);
+
+
+-- ----------------------------------------------------------------------------
+-- 4. DELETE BREW PHOTO
+-- ----------------------------------------------------------------------------
+-- Parameters: id, brew_id
+-- Returns: The deleted photo; its objects are recorded for the photo
+--          processor to sweep
+-- Usage: Owner deleting a photo
+-- Performance: Uses the primary key
+-- name: DeleteBrewPhoto :one
+DELETE FROM brew_photo
+WHERE id = sqlc.arg(id) AND brew_id = sqlc.arg(brew_id)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 5. CLAIM BREW PHOTOS
+-- ----------------------------------------------------------------------------
+-- Parameters: lease_seconds, row_limit
+-- Returns: The oldest due photos, counted as attempted and leased so other
+--          instances skip them until processed or the lease runs out
+-- Usage: Photo processor; photos left processing by an instance that died
+--        are claimed again once their lease expires
+-- Performance: Uses idx_brew_photo_due
+-- name: ClaimBrewPhotos :many
+UPDATE brew_photo p
+SET
+    status = 'processing',
+    attempts = p.attempts + 1,
+    next_attempt_at = NOW() + sqlc.arg(lease_seconds)::int * INTERVAL '1 second'
+WHERE p.id IN (
+    SELECT bp.id FROM brew_photo bp
+    WHERE bp.status IN ('pending', 'processing')
+        AND bp.next_attempt_at <= NOW()
+    ORDER BY bp.next_attempt_at, bp.id
+    LIMIT sqlc.arg(row_limit)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING p.*;
+
+
+-- ----------------------------------------------------------------------------
+-- 6. FINISH BREW PHOTO
+-- ----------------------------------------------------------------------------
+-- Parameters: status (ready or rejected), image_key, thumbnail_key, width,
+--             height, image_bytes, thumbnail_bytes, screening_score, error,
+--             id
+-- Returns: Number of photos updated; 0 when the photo was deleted while
+--          being processed
+-- Usage: Photo processor, once a photo is processed
+-- Performance: Uses the primary key
+-- name: FinishBrewPhoto :execrows
+UPDATE brew_photo
+SET
+    status = sqlc.arg(status),
+    image_key = sqlc.narg(image_key),
+    thumbnail_key = sqlc.narg(thumbnail_key),
+    width = sqlc.narg(width),
+    height = sqlc.narg(height),
+    image_bytes = sqlc.narg(image_bytes),
+    thumbnail_bytes = sqlc.narg(thumbnail_bytes),
+    screening_score = sqlc.narg(screening_score),
+    error = sqlc.narg(error),
+    processed_at = NOW()
+WHERE id = sqlc.arg(id) AND status = 'processing';
+
+
+-- ----------------------------------------------------------------------------
+-- 7. RETRY BREW PHOTO
+-- ----------------------------------------------------------------------------
+-- Parameters: error, retry_in_seconds (NULL to stop retrying), id
+-- Returns: None
+-- Usage: Photo processor; reschedules a photo that couldn't be processed,
+--        or marks it failed when out of retries or unreadable
+-- Performance: Uses the primary key
+-- name: RetryBrewPhoto :exec
+UPDATE brew_photo
+SET
+    status = CASE WHEN sqlc.narg(retry_in_seconds)::int IS NULL THEN 'failed' ELSE 'pending' END,
+    error = sqlc.arg(error),
+    processed_at = CASE WHEN sqlc.narg(retry_in_seconds)::int IS NULL THEN NOW() END,
+    next_attempt_at = NOW() + COALESCE(sqlc.narg(retry_in_seconds)::int, 0) * INTERVAL '1 second'
+WHERE id = sqlc.arg(id) AND status = 'processing';
+
+
+-- ----------------------------------------------------------------------------
+-- 8. QUARANTINE BREW PHOTO
+-- ----------------------------------------------------------------------------
+-- Parameters: original_key, threat, id
+-- Returns: Number of rows affected; 0 if the photo was deleted meanwhile
+-- Usage: Photo processor; quarantines a photo the upload scanner found
+--        malware in, once its original has been moved to original_key
+-- name: QuarantineBrewPhoto :execrows
+UPDATE brew_photo
+SET
+    status = 'quarantined',
+    original_key = sqlc.arg(original_key),
+    error = sqlc.arg(threat),
+    processed_at = NOW()
+WHERE id = sqlc.arg(id) AND status = 'processing';
+
+
+-- ----------------------------------------------------------------------------
+-- 9. LIST QUARANTINED BREW PHOTOS
+-- ----------------------------------------------------------------------------
+-- Parameters: row_limit, row_offset
+-- Returns: Quarantined photos with their uploaders' usernames, most
+--          recently quarantined first
+-- Usage: Admin review of quarantined uploads
+-- Performance: Uses idx_brew_photo_quarantined
+-- name: ListQuarantinedBrewPhotos :many
+SELECT
+    p.id,
+    p.brew_id,
+    p.user_id,
+    u.username,
+    p.content_type,
+    p.original_key,
+    p.original_bytes,
+    p.error AS threat,
+    p.processed_at,
+    p.created_at
+FROM brew_photo p
+JOIN "user" u ON u.id = p.user_id
+WHERE p.status = 'quarantined'
+ORDER BY p.processed_at DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+
+-- ----------------------------------------------------------------------------
+-- 10. DELETE QUARANTINED BREW PHOTO
+-- ----------------------------------------------------------------------------
+-- Parameters: id
+-- Returns: The deleted photo; its objects are recorded for the photo
+--          processor to sweep
+-- Usage: Admins disposing of a quarantined upload
+-- Performance: Uses the primary key
+-- name: DeleteQuarantinedBrewPhoto :one
+DELETE FROM brew_photo
+WHERE id = sqlc.arg(id) AND status = 'quarantined'
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 11. REQUEUE BREW PHOTOS
+-- ----------------------------------------------------------------------------
+-- Parameters: statuses
+-- Returns: Number of photos requeued
+-- Usage: Media migration tool, reprocessing photos from their originals,
+--        e.g. after rendering or scanning changes. Quarantined photos,
+--        whose originals have moved, can't be requeued.
+-- Performance: Scans brew_photo
+-- name: RequeueBrewPhotos :execrows
+UPDATE brew_photo
+SET
+    status = 'pending',
+    attempts = 0,
+    error = NULL,
+    next_attempt_at = NOW()
+WHERE status = ANY(sqlc.arg(statuses)::text[])
+  AND status IN ('ready', 'failed', 'rejected');
+
+
+-- ----------------------------------------------------------------------------
+-- 12. CLAIM DELETED PHOTO OBJECTS
+-- ----------------------------------------------------------------------------
+-- Parameters: lease_seconds, row_limit
+-- Returns: The oldest due object keys of deleted photos, counted as
+--          attempted and leased so other instances skip them until swept
+--          or the lease runs out
+-- Usage: Photo processor, sweeping deleted photos from the object store;
+--        keys it fails to delete are claimed again once their lease expires
+-- Performance: Uses idx_deleted_photo_object_due
+-- name: ClaimDeletedPhotoObjects :many
+UPDATE deleted_photo_object o
+SET
+    attempts = o.attempts + 1,
+    next_attempt_at = NOW() + sqlc.arg(lease_seconds)::int * INTERVAL '1 second'
+WHERE o.key IN (
+    SELECT d.key FROM deleted_photo_object d
+    WHERE d.next_attempt_at <= NOW()
+    ORDER BY d.next_attempt_at, d.key
+    LIMIT sqlc.arg(row_limit)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING o.*;
+
+
+-- ----------------------------------------------------------------------------
+-- 13. FORGET DELETED PHOTO OBJECT
+-- ----------------------------------------------------------------------------
+-- Parameters: key
+-- Returns: None
+-- Usage: Photo processor, once a deleted photo's object is gone from the
+--        object store
+-- Performance: Uses the primary key
+-- name: ForgetDeletedPhotoObject :exec
+DELETE FROM deleted_photo_object
+WHERE key = sqlc.arg(key);
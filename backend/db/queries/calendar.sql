@@ -0,0 +1,88 @@
+-- ============================================================================
+-- CALENDAR FEED QUERIES
+-- ============================================================================
+-- Lookups behind each user's signed iCalendar feed: the feed URL's version
+-- and the scheduled dates the feed lists
+
+
+-- ----------------------------------------------------------------------------
+-- 1. GET CALENDAR FEED VERSION
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id
+-- Returns: The version signed into the user's feed URL
+-- Usage: Build the feed URL and verify requests for it
+-- name: GetCalendarFeedVersion :one
+SELECT calendar_feed_version FROM "user"
+WHERE id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. RESET CALENDAR FEED
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id
+-- Returns: The new version
+-- Usage: User revokes a leaked feed URL; the old URL stops verifying
+-- name: ResetCalendarFeed :one
+UPDATE "user"
+SET calendar_feed_version = calendar_feed_version + 1
+WHERE id = $1
+RETURNING calendar_feed_version;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. LIST CALENDAR BREW EVENTS
+-- ----------------------------------------------------------------------------
+-- Parameters: user_id, since (events starting at or after), row_limit
+-- Returns: Brew-alongs the user hosts or is going or maybe going to,
+--          soonest first, with the user's RSVP status (NULL when hosting
+--          without one)
+-- Usage: Calendar feed
+-- Performance: Uses idx_brew_event_host_id and the RSVP primary key
+-- name: ListCalendarBrewEvents :many
+SELECT
+    be.id,
+    be.host_id,
+    u.username AS host_username,
+    be.title,
+    be.description,
+    r.name AS recipe_name,
+    be.starts_at,
+    be.timer_started_at,
+    be.timer_ended_at,
+    be.updated_at,
+    ber.status AS rsvp_status
+FROM brew_event be
+JOIN "user" u ON u.id = be.host_id
+JOIN recipe r ON r.id = be.recipe_id
+LEFT JOIN brew_event_rsvp ber ON ber.event_id = be.id AND ber.user_id = sqlc.arg(user_id)
+WHERE (be.host_id = sqlc.arg(user_id) OR ber.status IN ('going', 'maybe'))
+    AND be.starts_at >= sqlc.arg(since)
+ORDER BY be.starts_at, be.id
+LIMIT sqlc.arg(row_limit);
+
+
+-- ----------------------------------------------------------------------------
+-- 4. LIST CALENDAR RESTING BEAN BAGS
+-- ----------------------------------------------------------------------------
+-- Parameters: owner_id, since (bags ready on or after), row_limit
+-- Returns: The user's open bags with a roast date and resting period, with
+--          the date their rest ends, soonest first
+-- Usage: Calendar feed
+-- Performance: Uses idx_bean_bag_owner_id
+-- name: ListCalendarRestingBeanBags :many
+SELECT
+    id,
+    name,
+    roaster,
+    roasted_on,
+    rest_days,
+    (roasted_on + rest_days)::date AS ready_on,
+    updated_at
+FROM bean_bag
+WHERE owner_id = sqlc.arg(owner_id)
+    AND finished_at IS NULL
+    AND roasted_on IS NOT NULL
+    AND rest_days IS NOT NULL
+    AND roasted_on + rest_days >= sqlc.arg(since)::date
+ORDER BY ready_on, id
+LIMIT sqlc.arg(row_limit);
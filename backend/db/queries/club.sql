@@ -0,0 +1,331 @@
+-- ============================================================================
+-- CLUB QUERIES
+-- ============================================================================
+-- Operations for clubs: membership and roles, invite links and the shared
+-- feed
+
+
+-- ----------------------------------------------------------------------------
+-- 1. CREATE CLUB
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id, $2 = owner_id, $3 = name, $4 = description,
+--             $5 = is_private
+-- Returns: The created club
+-- Usage: Create a club with its owner as the first member, in one statement
+-- name: CreateClub :one
+WITH club_row AS (
+    INSERT INTO club (id, owner_id, name, description, is_private)
+    VALUES ($1, $2, $3, $4, $5)
+    RETURNING *
+), owner_member AS (
+    INSERT INTO club_member (club_id, user_id, role)
+    SELECT id, owner_id, 'owner' FROM club_row
+)
+SELECT * FROM club_row;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. GET CLUB BY ID
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id
+-- Returns: The club
+-- Usage: Club page and access checks
+-- name: GetClubByID :one
+SELECT * FROM club
+WHERE id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. UPDATE CLUB
+-- ----------------------------------------------------------------------------
+-- Parameters: id, name, description, is_private (NULL leaves a field as is)
+-- Returns: The updated club
+-- Usage: Owner edits the club's details
+-- name: UpdateClub :one
+UPDATE club
+SET
+    name = COALESCE(sqlc.narg(name), name),
+    description = COALESCE(sqlc.narg(description), description),
+    is_private = COALESCE(sqlc.narg(is_private), is_private)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 4. DELETE CLUB
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id
+-- Returns: Nothing
+-- Usage: Owner deletes the club with its members, invites and feed (CASCADE)
+-- name: DeleteClub :exec
+DELETE FROM club
+WHERE id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 5. LIST PUBLIC CLUBS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = limit, $2 = offset
+-- Returns: Public clubs, newest first, with member counts
+-- Usage: Discover clubs to join
+-- name: ListPublicClubs :many
+SELECT
+    c.id,
+    c.name,
+    c.description,
+    c.created_at,
+    (SELECT COUNT(*) FROM club_member WHERE club_id = c.id) AS member_count
+FROM club c
+WHERE NOT c.is_private
+ORDER BY c.created_at DESC, c.id
+LIMIT $1 OFFSET $2;
+
+
+-- ----------------------------------------------------------------------------
+-- 6. LIST USER CLUBS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id
+-- Returns: Clubs the user belongs to, with their role and member counts
+-- Usage: "My clubs" screen
+-- name: ListUserClubs :many
+SELECT
+    c.id,
+    c.name,
+    c.description,
+    c.is_private,
+    cm.role,
+    c.created_at,
+    (SELECT COUNT(*) FROM club_member WHERE club_id = c.id) AS member_count
+FROM club_member cm
+JOIN club c ON c.id = cm.club_id
+WHERE cm.user_id = $1
+ORDER BY c.name;
+
+
+-- ----------------------------------------------------------------------------
+-- 7. COUNT CLUB MEMBERS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = club_id
+-- Returns: Number of members, owner included
+-- Usage: Club page
+-- name: CountClubMembers :one
+SELECT COUNT(*) FROM club_member
+WHERE club_id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 8. GET CLUB MEMBER ROLE
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = club_id, $2 = user_id
+-- Returns: The user's role, or no rows if they are not a member
+-- Usage: Access checks
+-- name: GetClubMemberRole :one
+SELECT role FROM club_member
+WHERE club_id = $1 AND user_id = $2;
+
+
+-- ----------------------------------------------------------------------------
+-- 9. ADD CLUB MEMBER
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = club_id, $2 = user_id
+-- Returns: Number of members added (0 if already a member)
+-- Usage: User joins a public club
+-- name: AddClubMember :execrows
+INSERT INTO club_member (club_id, user_id, role)
+VALUES ($1, $2, 'member')
+ON CONFLICT (club_id, user_id) DO NOTHING;
+
+
+-- ----------------------------------------------------------------------------
+-- 10. UPDATE CLUB MEMBER ROLE
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = club_id, $2 = user_id, $3 = role
+-- Returns: The updated membership, or no rows for non-members and the owner
+-- Usage: Owner promotes a member to moderator or demotes them
+-- name: UpdateClubMemberRole :one
+UPDATE club_member
+SET role = $3
+WHERE club_id = $1 AND user_id = $2 AND role <> 'owner'
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 11. REMOVE CLUB MEMBER
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = club_id, $2 = user_id
+-- Returns: Number of members removed; the owner is never removed
+-- Usage: Member leaves, or a moderator removes them
+-- name: RemoveClubMember :execrows
+DELETE FROM club_member
+WHERE club_id = $1 AND user_id = $2 AND role <> 'owner';
+
+
+-- ----------------------------------------------------------------------------
+-- 12. LIST CLUB MEMBERS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = club_id
+-- Returns: Members with usernames: owner, then moderators, then members
+-- Usage: Member list
+-- name: ListClubMembers :many
+SELECT
+    cm.user_id,
+    u.username,
+    cm.role,
+    cm.created_at
+FROM club_member cm
+JOIN "user" u ON u.id = cm.user_id
+WHERE cm.club_id = $1
+ORDER BY
+    CASE cm.role WHEN 'owner' THEN 0 WHEN 'moderator' THEN 1 ELSE 2 END,
+    cm.created_at;
+
+
+-- ----------------------------------------------------------------------------
+-- 13. CREATE CLUB INVITE
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id, $2 = club_id, $3 = created_by, $4 = token_hash,
+--             $5 = max_uses, $6 = expires_at
+-- Returns: The created invite
+-- Usage: Moderator creates an invite link
+-- name: CreateClubInvite :one
+INSERT INTO club_invite (id, club_id, created_by, token_hash, max_uses, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 14. LIST CLUB INVITES
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = club_id
+-- Returns: The club's unrevoked invites, newest first
+-- Usage: Manage invite links
+-- name: ListClubInvites :many
+SELECT * FROM club_invite
+WHERE club_id = $1 AND revoked_at IS NULL
+ORDER BY created_at DESC;
+
+
+-- ----------------------------------------------------------------------------
+-- 15. REVOKE CLUB INVITE
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id, $2 = club_id
+-- Returns: Number of invites revoked
+-- Usage: Moderator disables an invite link
+-- name: RevokeClubInvite :execrows
+UPDATE club_invite
+SET revoked_at = NOW()
+WHERE id = $1 AND club_id = $2 AND revoked_at IS NULL;
+
+
+-- ----------------------------------------------------------------------------
+-- 16. GET CLUB INVITE BY TOKEN HASH
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = token_hash
+-- Returns: A usable invite (unrevoked, unexpired, uses left) with its club
+-- Usage: Invite link landing page
+-- name: GetClubInviteByTokenHash :one
+SELECT
+    ci.id,
+    ci.club_id,
+    c.name AS club_name,
+    c.description AS club_description,
+    c.is_private AS club_is_private,
+    ci.expires_at
+FROM club_invite ci
+JOIN club c ON c.id = ci.club_id
+WHERE ci.token_hash = $1
+    AND ci.revoked_at IS NULL
+    AND (ci.expires_at IS NULL OR ci.expires_at > NOW())
+    AND (ci.max_uses IS NULL OR ci.use_count < ci.max_uses);
+
+
+-- ----------------------------------------------------------------------------
+-- 17. REDEEM CLUB INVITE
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = token_hash, $2 = user_id
+-- Returns: The joined club's ID, or no rows if the invite is no longer
+--          usable or the user is already a member (which uses nothing)
+-- Usage: Join a club through an invite link, counting the use atomically
+-- name: RedeemClubInvite :one
+WITH invite AS (
+    UPDATE club_invite ci
+    SET use_count = ci.use_count + 1
+    WHERE ci.token_hash = $1
+        AND ci.revoked_at IS NULL
+        AND (ci.expires_at IS NULL OR ci.expires_at > NOW())
+        AND (ci.max_uses IS NULL OR ci.use_count < ci.max_uses)
+        AND NOT EXISTS (
+            SELECT 1 FROM club_member cm
+            WHERE cm.club_id = ci.club_id AND cm.user_id = $2
+        )
+    RETURNING ci.club_id
+)
+INSERT INTO club_member (club_id, user_id, role)
+SELECT club_id, $2, 'member' FROM invite
+ON CONFLICT (club_id, user_id) DO NOTHING
+RETURNING club_id;
+
+
+-- ----------------------------------------------------------------------------
+-- 18. CREATE CLUB SHARE
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id, $2 = club_id, $3 = user_id, $4 = brew_id,
+--             $5 = recipe_id, $6 = note
+-- Returns: The share
+-- Usage: Member shares a brew or recipe to the club feed
+-- name: CreateClubShare :one
+INSERT INTO club_share (id, club_id, user_id, brew_id, recipe_id, note)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 19. GET CLUB SHARE
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id, $2 = club_id
+-- Returns: The share
+-- Usage: Permission checks before removing a share
+-- name: GetClubShare :one
+SELECT * FROM club_share
+WHERE id = $1 AND club_id = $2;
+
+
+-- ----------------------------------------------------------------------------
+-- 20. DELETE CLUB SHARE
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id
+-- Returns: Nothing
+-- Usage: Sharer or a moderator removes a share from the feed
+-- name: DeleteClubShare :exec
+DELETE FROM club_share
+WHERE id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 21. LIST CLUB FEED
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = club_id, $2 = limit, $3 = offset
+-- Returns: Shares newest first, with sharer usernames and brew or recipe
+--          summaries
+-- Usage: Club feed
+-- name: ListClubFeed :many
+SELECT
+    cs.id,
+    cs.user_id,
+    u.username,
+    cs.brew_id,
+    b.name AS brew_name,
+    b.brew_method,
+    b.bean_origin,
+    cs.recipe_id,
+    r.name AS recipe_name,
+    r.brew_method AS recipe_brew_method,
+    cs.note,
+    cs.created_at
+FROM club_share cs
+JOIN "user" u ON u.id = cs.user_id
+LEFT JOIN brew b ON b.id = cs.brew_id
+LEFT JOIN recipe r ON r.id = cs.recipe_id
+WHERE cs.club_id = $1
+ORDER BY cs.created_at DESC, cs.id DESC
+LIMIT $2 OFFSET $3;
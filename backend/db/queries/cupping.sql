@@ -0,0 +1,232 @@
+-- ============================================================================
+-- CUPPING QUERIES
+-- ============================================================================
+-- Operations for blind cupping sessions: samples, participants, scores and
+-- the reveal
+
+
+-- ----------------------------------------------------------------------------
+-- 1. CREATE CUPPING SESSION
+-- ----------------------------------------------------------------------------
+-- Parameters: id, host_id, title, notes, and parallel sample arrays
+--             (sample_ids, labels, names, roasters, bean_origins, bean_bag_ids)
+-- Returns: The created session
+-- Usage: Host sets up a session and its samples in one statement
+-- name: CreateCuppingSession :one
+WITH session AS (
+    INSERT INTO cupping_session (id, host_id, title, notes)
+    VALUES (sqlc.arg(id), sqlc.arg(host_id), sqlc.arg(title), sqlc.narg(notes))
+    RETURNING *
+), samples AS (
+    INSERT INTO cupping_sample (id, session_id, label, name, roaster, bean_origin, bean_bag_id)
+    SELECT s.id, session.id, s.label, s.name, s.roaster, s.bean_origin, s.bean_bag_id
+    FROM session, unnest(
+        sqlc.arg(sample_ids)::text[],
+        sqlc.arg(labels)::text[],
+        sqlc.arg(names)::text[],
+        sqlc.arg(roasters)::text[],
+        sqlc.arg(bean_origins)::text[],
+        sqlc.arg(bean_bag_ids)::text[]
+    ) AS s(id, label, name, roaster, bean_origin, bean_bag_id)
+)
+SELECT * FROM session;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. GET CUPPING SESSION BY ID
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id
+-- Returns: The session
+-- Usage: Load a session for access checks
+-- name: GetCuppingSessionByID :one
+SELECT * FROM cupping_session
+WHERE id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. LIST USER CUPPING SESSIONS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id
+-- Returns: Sessions the user hosts or was invited to, newest first, with
+--          host usernames and sample counts
+-- Usage: "My cuppings" screen
+-- name: ListUserCuppingSessions :many
+SELECT
+    cs.id,
+    cs.host_id,
+    u.username AS host_username,
+    cs.title,
+    cs.revealed_at,
+    cs.created_at,
+    (SELECT COUNT(*) FROM cupping_sample WHERE session_id = cs.id) AS sample_count
+FROM cupping_session cs
+JOIN "user" u ON u.id = cs.host_id
+WHERE cs.host_id = $1
+    OR EXISTS (
+        SELECT 1 FROM cupping_participant cp
+        WHERE cp.session_id = cs.id AND cp.user_id = $1
+    )
+ORDER BY cs.created_at DESC;
+
+
+-- ----------------------------------------------------------------------------
+-- 4. DELETE CUPPING SESSION
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id
+-- Returns: Nothing
+-- Usage: Host deletes a session with its samples and scores (CASCADE)
+-- name: DeleteCuppingSession :exec
+DELETE FROM cupping_session
+WHERE id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 5. REVEAL CUPPING SESSION
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id
+-- Returns: The revealed session, or no rows if it was already revealed
+-- Usage: Host reveals the samples and locks scoring
+-- name: RevealCuppingSession :one
+UPDATE cupping_session
+SET revealed_at = NOW()
+WHERE id = $1 AND revealed_at IS NULL
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 6. LIST CUPPING SAMPLES
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = session_id
+-- Returns: The session's samples by label
+-- Usage: Show the cupping table (identities are hidden by the handler)
+-- name: ListCuppingSamples :many
+SELECT * FROM cupping_sample
+WHERE session_id = $1
+ORDER BY label;
+
+
+-- ----------------------------------------------------------------------------
+-- 7. ADD CUPPING PARTICIPANT
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = session_id, $2 = user_id
+-- Returns: Nothing; inviting an existing participant is a no-op
+-- Usage: Host invites a user to score
+-- name: AddCuppingParticipant :exec
+INSERT INTO cupping_participant (session_id, user_id)
+VALUES ($1, $2)
+ON CONFLICT (session_id, user_id) DO NOTHING;
+
+
+-- ----------------------------------------------------------------------------
+-- 8. REMOVE CUPPING PARTICIPANT
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = session_id, $2 = user_id
+-- Returns: Number of participants removed
+-- Usage: Host removes a participant, or a participant leaves. Their scores
+--        are dropped unless the session was already revealed.
+-- name: RemoveCuppingParticipant :execrows
+WITH scores AS (
+    DELETE FROM cupping_score
+    WHERE user_id = $2
+        AND sample_id IN (
+            SELECT cs.id FROM cupping_sample cs
+            JOIN cupping_session s ON s.id = cs.session_id
+            WHERE cs.session_id = $1 AND s.revealed_at IS NULL
+        )
+)
+DELETE FROM cupping_participant
+WHERE session_id = $1 AND user_id = $2;
+
+
+-- ----------------------------------------------------------------------------
+-- 9. IS CUPPING PARTICIPANT
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = session_id, $2 = user_id
+-- Returns: Whether the user was invited to the session
+-- Usage: Access checks for participants
+-- name: IsCuppingParticipant :one
+SELECT EXISTS (
+    SELECT 1 FROM cupping_participant
+    WHERE session_id = $1 AND user_id = $2
+);
+
+
+-- ----------------------------------------------------------------------------
+-- 10. LIST CUPPING PARTICIPANTS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = session_id
+-- Returns: Participants with usernames and how many samples each has scored
+-- Usage: Track scoring progress without revealing scores
+-- name: ListCuppingParticipants :many
+SELECT
+    cp.user_id,
+    u.username,
+    cp.created_at,
+    (
+        SELECT COUNT(*) FROM cupping_score sc
+        JOIN cupping_sample cs ON cs.id = sc.sample_id
+        WHERE cs.session_id = cp.session_id AND sc.user_id = cp.user_id
+    ) AS scored_count
+FROM cupping_participant cp
+JOIN "user" u ON u.id = cp.user_id
+WHERE cp.session_id = $1
+ORDER BY cp.created_at;
+
+
+-- ----------------------------------------------------------------------------
+-- 11. UPSERT CUPPING SCORE
+-- ----------------------------------------------------------------------------
+-- Parameters: user_id, score, notes, session_id, label
+-- Returns: The score, or no rows if the label doesn't exist or the session
+--          has been revealed
+-- Usage: Scorer records or changes their score for a sample
+-- name: UpsertCuppingScore :one
+INSERT INTO cupping_score (sample_id, user_id, score, notes)
+SELECT cs.id, sqlc.arg(user_id)::text, sqlc.arg(score)::double precision, sqlc.narg(notes)::text
+FROM cupping_sample cs
+JOIN cupping_session s ON s.id = cs.session_id
+WHERE cs.session_id = sqlc.arg(session_id)
+    AND cs.label = sqlc.arg(label)
+    AND s.revealed_at IS NULL
+ON CONFLICT (sample_id, user_id) DO UPDATE
+SET score = EXCLUDED.score, notes = EXCLUDED.notes
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 12. LIST USER CUPPING SCORES
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = session_id, $2 = user_id
+-- Returns: The user's own scores in the session, by label
+-- Usage: Scorer reviews their scoresheet
+-- name: ListUserCuppingScores :many
+SELECT
+    sc.sample_id,
+    cs.label,
+    sc.score,
+    sc.notes,
+    sc.updated_at
+FROM cupping_score sc
+JOIN cupping_sample cs ON cs.id = sc.sample_id
+WHERE cs.session_id = $1 AND sc.user_id = $2
+ORDER BY cs.label;
+
+
+-- ----------------------------------------------------------------------------
+-- 13. LIST CUPPING SCORES
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = session_id
+-- Returns: Every score in the session with scorer usernames
+-- Usage: Aggregate results after the reveal
+-- name: ListCuppingScores :many
+SELECT
+    sc.sample_id,
+    sc.user_id,
+    u.username,
+    sc.score,
+    sc.notes
+FROM cupping_score sc
+JOIN cupping_sample cs ON cs.id = sc.sample_id
+JOIN "user" u ON u.id = sc.user_id
+WHERE cs.session_id = $1
+ORDER BY cs.label, u.username;
@@ -0,0 +1,266 @@
+-- ============================================================================
+-- DEVICE QUERIES
+-- ============================================================================
+-- Operations for readings sent by device companion apps
+
+
+-- ----------------------------------------------------------------------------
+-- 1. CREATE TDS READING
+-- ----------------------------------------------------------------------------
+-- Parameters: id, user_id, brew_id (NULL attaches the reading to the user's
+--             brew whose timer session was running at measured_at, or ended
+--             at most 30 minutes before it), api_key_id, client_id,
+--             tds_percent, temperature_c, beverage_grams, device_model,
+--             measured_at
+-- Returns: The created reading, or no rows if the user already sent a
+--          reading with this client_id
+-- Usage: Refractometer companion app ingestion
+-- Performance: Uses idx_brew_created_by
+-- name: CreateTDSReading :one
+INSERT INTO tds_reading (
+    id, user_id, brew_id, api_key_id, client_id, tds_percent, temperature_c,
+    beverage_grams, device_model, measured_at
+)
+VALUES (
+    sqlc.arg(id),
+    sqlc.arg(user_id),
+    COALESCE(sqlc.narg(brew_id), (
+        SELECT b.id FROM brew b
+        WHERE b.created_by = sqlc.arg(user_id)
+            AND b.started_at <= sqlc.arg(measured_at)
+            AND (b.ended_at IS NULL OR b.ended_at >= sqlc.arg(measured_at)::timestamptz - INTERVAL '30 minutes')
+        ORDER BY b.started_at DESC
+        LIMIT 1
+    )),
+    sqlc.narg(api_key_id),
+    sqlc.narg(client_id),
+    sqlc.arg(tds_percent),
+    sqlc.narg(temperature_c),
+    sqlc.narg(beverage_grams),
+    sqlc.narg(device_model),
+    sqlc.arg(measured_at)
+)
+ON CONFLICT (user_id, client_id) DO NOTHING
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. GET TDS READING BY CLIENT ID
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id, $2 = client_id
+-- Returns: The reading the user sent with that client_id
+-- Usage: Answer a resent reading with the stored one
+-- name: GetTDSReadingByClientID :one
+SELECT * FROM tds_reading
+WHERE user_id = $1 AND client_id = $2;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. GET TDS READING
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id
+-- Returns: A single reading; callers must check user_id
+-- Usage: Reattaching or deleting a reading
+-- name: GetTDSReading :one
+SELECT * FROM tds_reading
+WHERE id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 4. LIST BREW TDS READINGS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = brew_id
+-- Returns: The brew's readings, in the order they were taken
+-- Usage: Brew detail
+-- Performance: Uses idx_tds_reading_brew
+-- name: ListBrewTDSReadings :many
+SELECT * FROM tds_reading
+WHERE brew_id = $1
+ORDER BY measured_at, id;
+
+
+-- ----------------------------------------------------------------------------
+-- 5. LIST USER TDS READINGS
+-- ----------------------------------------------------------------------------
+-- Parameters: user_id, unattached_only, limit, offset
+-- Returns: The user's readings, newest first; with unattached_only, just
+--          those not attached to a brew
+-- Usage: Reading history and attaching stray readings
+-- Performance: Uses idx_tds_reading_user
+-- name: ListUserTDSReadings :many
+SELECT * FROM tds_reading
+WHERE user_id = sqlc.arg(user_id)
+    AND (brew_id IS NULL OR NOT sqlc.arg(unattached_only)::boolean)
+ORDER BY measured_at DESC, id DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+
+-- ----------------------------------------------------------------------------
+-- 6. SET TDS READING BREW
+-- ----------------------------------------------------------------------------
+-- Parameters: brew_id (NULL detaches), id
+-- Returns: The updated reading
+-- Usage: Attach a reading to a different brew, or detach it
+-- name: SetTDSReadingBrew :one
+UPDATE tds_reading
+SET brew_id = sqlc.narg(brew_id)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 7. DELETE TDS READING
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id
+-- Returns: None
+-- Usage: Discard a bad reading
+-- name: DeleteTDSReading :exec
+DELETE FROM tds_reading
+WHERE id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 8. GET RUNNING BREW
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id
+-- Returns: The user's most recently started brew whose timer is running
+-- Usage: Attach a pour stream to the brew being made
+-- Performance: Uses idx_brew_created_by
+-- name: GetRunningBrew :one
+SELECT * FROM brew
+WHERE created_by = $1
+    AND started_at IS NOT NULL
+    AND ended_at IS NULL
+ORDER BY started_at DESC
+LIMIT 1;
+
+
+-- ----------------------------------------------------------------------------
+-- 9. GET POUR CURVE BY CLIENT ID
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id, $2 = client_id
+-- Returns: The curve the user's bridge streamed with that client_id
+-- Usage: Resume a dropped pour stream
+-- name: GetPourCurveByClientID :one
+SELECT * FROM pour_curve
+WHERE user_id = $1 AND client_id = $2;
+
+
+-- ----------------------------------------------------------------------------
+-- 10. DELETE BREW POUR CURVE
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = brew_id
+-- Returns: Number of rows deleted
+-- Usage: Replace a brew's curve with a new stream, or discard it
+-- name: DeleteBrewPourCurve :execrows
+DELETE FROM pour_curve
+WHERE brew_id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 11. CREATE POUR CURVE
+-- ----------------------------------------------------------------------------
+-- Parameters: id, brew_id, user_id, api_key_id, client_id, device_model,
+--             started_at
+-- Returns: The created curve, with no samples yet
+-- Usage: Start of a pour stream
+-- name: CreatePourCurve :one
+INSERT INTO pour_curve (
+    id, brew_id, user_id, api_key_id, client_id, device_model, started_at
+)
+VALUES (
+    sqlc.arg(id),
+    sqlc.arg(brew_id),
+    sqlc.arg(user_id),
+    sqlc.narg(api_key_id),
+    sqlc.arg(client_id),
+    sqlc.narg(device_model),
+    sqlc.arg(started_at)
+)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 12. ADD POUR SAMPLES
+-- ----------------------------------------------------------------------------
+-- Parameters: curve_id, t_ms, grams, pressure_bar (arrays of the same
+--             length; NaN pressures are stored as NULL)
+-- Returns: Number of samples stored; samples already staged at the same
+--          t_ms are ignored, so resent samples are harmless
+-- Usage: Each batch of a pour stream
+-- name: AddPourSamples :execrows
+INSERT INTO pour_sample (curve_id, t_ms, grams, pressure_bar)
+SELECT sqlc.arg(curve_id), s.t_ms, s.grams, NULLIF(s.pressure_bar, 'NaN')
+FROM unnest(
+    sqlc.arg(t_ms)::int[],
+    sqlc.arg(grams)::float8[],
+    sqlc.arg(pressure_bar)::float8[]
+) AS s(t_ms, grams, pressure_bar)
+ON CONFLICT (curve_id, t_ms) DO NOTHING;
+
+
+-- ----------------------------------------------------------------------------
+-- 13. COMPACT POUR CURVE
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = curve_id
+-- Returns: The curve with its staged samples merged into the sample_*
+--          arrays and sample_count, duration_ms and final_grams
+--          recalculated. Samples already compacted win over staged ones at
+--          the same t_ms, as when staging. Missing pressures are compacted
+--          as NaN.
+-- Usage: End of a pour stream
+-- name: CompactPourCurve :one
+WITH staged AS (
+    DELETE FROM pour_sample
+    WHERE curve_id = $1
+    RETURNING t_ms, grams, pressure_bar
+),
+merged AS (
+    SELECT DISTINCT ON (s.t_ms) s.t_ms, s.grams, s.pressure_bar
+    FROM (
+        SELECT c.t_ms, c.grams::float8 AS grams, NULLIF(c.pressure_bar, 'NaN')::float8 AS pressure_bar, 0 AS source
+        FROM pour_curve pc,
+            unnest(pc.sample_t_ms, pc.sample_grams, pc.sample_pressure_bar) AS c(t_ms, grams, pressure_bar)
+        WHERE pc.id = $1
+        UNION ALL
+        SELECT t_ms, grams, pressure_bar, 1 FROM staged
+    ) s
+    ORDER BY s.t_ms, s.source
+)
+UPDATE pour_curve
+SET
+    sample_t_ms = COALESCE((SELECT ARRAY_AGG(t_ms ORDER BY t_ms) FROM merged), '{}'),
+    sample_grams = COALESCE((SELECT ARRAY_AGG(grams::real ORDER BY t_ms) FROM merged), '{}'),
+    sample_pressure_bar = (
+        SELECT ARRAY_AGG(COALESCE(pressure_bar, 'NaN')::real ORDER BY t_ms) FROM merged
+        HAVING BOOL_OR(pressure_bar IS NOT NULL)
+    ),
+    sample_count = (SELECT COUNT(*) FROM merged),
+    duration_ms = COALESCE((SELECT MAX(t_ms) FROM merged), 0),
+    final_grams = (SELECT grams FROM merged ORDER BY t_ms DESC LIMIT 1)
+WHERE id = $1
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 14. GET BREW POUR CURVE
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = brew_id
+-- Returns: The brew's curve
+-- Usage: Brew detail and charting
+-- name: GetBrewPourCurve :one
+SELECT * FROM pour_curve
+WHERE brew_id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 15. LIST POUR SAMPLES
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = curve_id
+-- Returns: The curve's staged samples in time order, while it is streamed
+-- Usage: Charting a pour curve live, merged with its compacted samples
+-- Performance: Uses the pour_sample primary key
+-- name: ListPourSamples :many
+SELECT t_ms, grams, pressure_bar FROM pour_sample
+WHERE curve_id = $1
+ORDER BY t_ms;
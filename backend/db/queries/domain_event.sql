@@ -0,0 +1,71 @@
+-- ============================================================================
+-- DOMAIN EVENT QUERIES
+-- ============================================================================
+-- Operations for the domain event outbox and its relay. Events are written
+-- by triggers (see schema/triggers.sql), not by queries.
+
+
+-- ----------------------------------------------------------------------------
+-- 1. CLAIM DOMAIN EVENTS
+-- ----------------------------------------------------------------------------
+-- Parameters: lease_seconds, row_limit
+-- Returns: The oldest due unpublished events, counted as attempted and
+--          leased so other instances skip them until published or the lease
+--          runs out
+-- Usage: Event relay; SKIP LOCKED lets several instances poll safely. Rows
+--        come back in no particular order, so callers sort by id.
+-- Performance: Uses idx_domain_event_due
+-- name: ClaimDomainEvents :many
+UPDATE domain_event e
+SET
+    attempts = e.attempts + 1,
+    next_attempt_at = NOW() + sqlc.arg(lease_seconds)::int * INTERVAL '1 second'
+WHERE e.id IN (
+    SELECT de.id FROM domain_event de
+    WHERE de.published_at IS NULL
+        AND de.next_attempt_at <= NOW()
+    ORDER BY de.id
+    LIMIT sqlc.arg(row_limit)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING e.id, e.topic, e.aggregate_id, e.payload, e.attempts, e.created_at;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. MARK DOMAIN EVENTS PUBLISHED
+-- ----------------------------------------------------------------------------
+-- Parameters: ids
+-- Returns: None
+-- Usage: Event relay, once the broker has accepted a batch
+-- name: MarkDomainEventsPublished :exec
+UPDATE domain_event
+SET published_at = NOW(), last_error = NULL
+WHERE id = ANY(sqlc.arg(ids)::bigint[]);
+
+
+-- ----------------------------------------------------------------------------
+-- 3. RETRY DOMAIN EVENTS
+-- ----------------------------------------------------------------------------
+-- Parameters: error, retry_in_seconds, ids
+-- Returns: None
+-- Usage: Event relay, when a batch couldn't be published; events are
+--        retried until they are
+-- name: RetryDomainEvents :exec
+UPDATE domain_event
+SET
+    last_error = sqlc.arg(error),
+    next_attempt_at = NOW() + sqlc.arg(retry_in_seconds)::int * INTERVAL '1 second'
+WHERE id = ANY(sqlc.arg(ids)::bigint[]);
+
+
+-- ----------------------------------------------------------------------------
+-- 4. DELETE PUBLISHED DOMAIN EVENTS
+-- ----------------------------------------------------------------------------
+-- Parameters: retention_days
+-- Returns: Number of events deleted
+-- Usage: Event relay trims events published longer ago than the retention
+--        period; consumers replay from the broker, not the outbox
+-- Performance: Uses idx_domain_event_published
+-- name: DeletePublishedDomainEvents :execrows
+DELETE FROM domain_event
+WHERE published_at < NOW() - sqlc.arg(retention_days)::int * INTERVAL '1 day';
@@ -0,0 +1,80 @@
+-- ============================================================================
+-- DRAFT QUERIES
+-- ============================================================================
+-- Operations for autosaved drafts of brews and recipes still being entered
+
+
+-- ----------------------------------------------------------------------------
+-- 1. CREATE DRAFT
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id (ULID), $2 = user_id, $3 = kind, $4 = data,
+--             $5 = sync_vector (NULL unless pushed by an offline client)
+-- Returns: The created draft
+-- Usage: User starts entering a brew or recipe
+-- name: CreateDraft :one
+INSERT INTO draft (id, user_id, kind, data, sync_vector)
+VALUES ($1, $2, $3, $4, COALESCE($5::jsonb, '{}'))
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. GET DRAFT
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id, $2 = user_id
+-- Returns: The draft, if it belongs to the user
+-- Usage: Resuming, autosaving or publishing a draft
+-- name: GetDraft :one
+SELECT * FROM draft
+WHERE id = $1 AND user_id = $2;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. LIST USER DRAFTS
+-- ----------------------------------------------------------------------------
+-- Parameters: user_id, kind (NULL for both)
+-- Returns: The user's drafts, most recently saved first
+-- Usage: Drafts screen, and brew history with drafts included
+-- Performance: Uses idx_draft_user_kind
+-- name: ListUserDrafts :many
+SELECT * FROM draft
+WHERE user_id = sqlc.arg(user_id)
+  AND (sqlc.narg(kind)::text IS NULL OR kind = sqlc.narg(kind))
+ORDER BY updated_at DESC, id DESC;
+
+
+-- ----------------------------------------------------------------------------
+-- 4. COUNT USER DRAFTS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id
+-- Returns: How many drafts the user has
+-- Usage: Enforce the per-user draft limit
+-- Performance: Uses idx_draft_user_kind
+-- name: CountUserDrafts :one
+SELECT COUNT(*) FROM draft
+WHERE user_id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 5. UPDATE DRAFT DATA
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id, $2 = user_id, $3 = data,
+--             $4 = sync_vector (NULL for server-side edits)
+-- Returns: The saved draft; no rows if it isn't the user's
+-- Usage: Autosave, with the patch already merged into the stored data, and
+--        drafts pushed by an offline client
+-- name: UpdateDraftData :one
+UPDATE draft
+SET data = $3, sync_vector = COALESCE($4::jsonb, sync_vector)
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 6. DELETE DRAFT
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id, $2 = user_id
+-- Returns: Number of rows deleted; 0 if not the user's
+-- Usage: User discards a draft, or it was published
+-- name: DeleteDraft :execrows
+DELETE FROM draft
+WHERE id = $1 AND user_id = $2;
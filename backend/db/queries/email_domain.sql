@@ -0,0 +1,128 @@
+-- ============================================================================
+-- EMAIL DOMAIN QUERIES
+-- ============================================================================
+-- Operations for the disposable email domain blocklist and the admin
+-- overrides that take precedence over it. Domains are stored lowercased;
+-- lookups pass a domain and each of its parent domains, so subdomains of a
+-- listed domain match too.
+
+
+-- ----------------------------------------------------------------------------
+-- 1. REPLACE DISPOSABLE DOMAINS
+-- ----------------------------------------------------------------------------
+-- Parameters: domains, prune
+-- Returns: None
+-- Usage: Blocklist refresh job swaps in the latest list in one statement.
+--        Without prune, domains are only added, e.g. when the remote list
+--        couldn't be fetched.
+-- name: ReplaceDisposableDomains :exec
+WITH incoming AS (
+    SELECT DISTINCT LOWER(d) AS domain
+    FROM unnest(sqlc.arg(domains)::text[]) AS d
+),
+removed AS (
+    DELETE FROM disposable_domain
+    WHERE sqlc.arg(prune)::boolean
+      AND domain NOT IN (SELECT domain FROM incoming)
+)
+INSERT INTO disposable_domain (domain)
+SELECT domain FROM incoming
+ON CONFLICT (domain) DO NOTHING;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. IS EMAIL DOMAIN BLOCKED
+-- ----------------------------------------------------------------------------
+-- Parameters: domains (an address's domain and its parent domains)
+-- Returns: Whether the most specific override blocks the domain, or
+--          without one, whether the blocklist lists it
+-- Usage: Registration and waitlist signups
+-- Performance: Primary key lookups
+-- name: IsEmailDomainBlocked :one
+SELECT COALESCE(
+    (SELECT o.action = 'block'
+     FROM email_domain_override o
+     WHERE o.domain = ANY(sqlc.arg(domains)::text[])
+     ORDER BY LENGTH(o.domain) DESC
+     LIMIT 1),
+    EXISTS (
+        SELECT 1 FROM disposable_domain d
+        WHERE d.domain = ANY(sqlc.arg(domains)::text[])
+    )
+)::boolean AS blocked;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. COUNT DISPOSABLE DOMAINS
+-- ----------------------------------------------------------------------------
+-- Parameters: None
+-- Returns: Size of the blocklist
+-- Usage: Admin overrides page
+-- name: CountDisposableDomains :one
+SELECT COUNT(*)::int AS count FROM disposable_domain;
+
+
+-- ----------------------------------------------------------------------------
+-- 4. SET EMAIL DOMAIN OVERRIDE
+-- ----------------------------------------------------------------------------
+-- Parameters: domain, action, note, created_by
+-- Returns: The override
+-- Usage: Admin allows or blocks a domain
+-- name: SetEmailDomainOverride :one
+INSERT INTO email_domain_override (domain, action, note, created_by)
+VALUES (LOWER(sqlc.arg(domain)), sqlc.arg(action), sqlc.narg(note), sqlc.narg(created_by))
+ON CONFLICT (domain) DO UPDATE
+SET action = EXCLUDED.action,
+    note = EXCLUDED.note,
+    created_by = EXCLUDED.created_by,
+    created_at = NOW()
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 5. LIST EMAIL DOMAIN OVERRIDES
+-- ----------------------------------------------------------------------------
+-- Parameters: None
+-- Returns: All overrides, alphabetically
+-- Usage: Admin overrides page
+-- name: ListEmailDomainOverrides :many
+SELECT * FROM email_domain_override
+ORDER BY domain;
+
+
+-- ----------------------------------------------------------------------------
+-- 6. DELETE EMAIL DOMAIN OVERRIDE
+-- ----------------------------------------------------------------------------
+-- Parameters: domain
+-- Returns: Rows affected
+-- Usage: Admin removes an override; the blocklist applies again
+-- name: DeleteEmailDomainOverride :execrows
+DELETE FROM email_domain_override
+WHERE domain = LOWER(sqlc.arg(domain));
+
+
+-- ----------------------------------------------------------------------------
+-- 7. FLAG DISPOSABLE EMAIL
+-- ----------------------------------------------------------------------------
+-- Parameters: id
+-- Returns: None
+-- Usage: Registration in flag mode, for a new user with a disposable domain
+-- name: FlagDisposableEmail :exec
+UPDATE "user"
+SET disposable_email = true
+WHERE id = sqlc.arg(id);
+
+
+-- ----------------------------------------------------------------------------
+-- 8. LIST DISPOSABLE EMAIL USERS
+-- ----------------------------------------------------------------------------
+-- Parameters: row_limit, row_offset
+-- Returns: Flagged users, newest first
+-- Usage: Admin review of flagged signups
+-- Performance: Uses idx_user_disposable_email
+-- name: ListDisposableEmailUsers :many
+SELECT id, username, email, joined_at
+FROM "user"
+WHERE disposable_email
+ORDER BY joined_at DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
@@ -0,0 +1,55 @@
+-- ============================================================================
+-- EMAIL SUPPRESSION QUERIES
+-- ============================================================================
+-- Operations for the list of addresses mail is no longer sent to. Addresses
+-- are stored lowercased.
+
+
+-- ----------------------------------------------------------------------------
+-- 1. SUPPRESS EMAIL
+-- ----------------------------------------------------------------------------
+-- Parameters: email, reason, provider, detail
+-- Returns: None
+-- Usage: Mail provider bounce and complaint webhooks, admin suppressions.
+--        An address already suppressed keeps its first reason.
+-- name: SuppressEmail :exec
+INSERT INTO email_suppression (email, reason, provider, detail)
+VALUES (LOWER(sqlc.arg(email)), sqlc.arg(reason), sqlc.narg(provider), sqlc.narg(detail))
+ON CONFLICT (email) DO NOTHING;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. IS EMAIL SUPPRESSED
+-- ----------------------------------------------------------------------------
+-- Parameters: email
+-- Returns: Whether mail to the address is suppressed
+-- Usage: Mailer, before every send
+-- Performance: Primary key lookup
+-- name: IsEmailSuppressed :one
+SELECT EXISTS (
+    SELECT 1 FROM email_suppression WHERE email = LOWER(sqlc.arg(email))
+) AS suppressed;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. LIST EMAIL SUPPRESSIONS
+-- ----------------------------------------------------------------------------
+-- Parameters: row_limit, row_offset
+-- Returns: Suppressed addresses, newest first
+-- Usage: Admin tooling
+-- Performance: Uses idx_email_suppression_created_at
+-- name: ListEmailSuppressions :many
+SELECT * FROM email_suppression
+ORDER BY created_at DESC, email
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+
+-- ----------------------------------------------------------------------------
+-- 4. DELETE EMAIL SUPPRESSION
+-- ----------------------------------------------------------------------------
+-- Parameters: email
+-- Returns: Rows affected
+-- Usage: Admin lifts a suppression, e.g. after the user fixed their mailbox
+-- name: DeleteEmailSuppression :execrows
+DELETE FROM email_suppression
+WHERE email = LOWER(sqlc.arg(email));
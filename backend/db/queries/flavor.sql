@@ -0,0 +1,168 @@
+-- ============================================================================
+-- FLAVOR QUERIES
+-- ============================================================================
+-- Operations for the flavor wheel: taxonomy, tagging brews and bean bags,
+-- and flavor aggregation
+
+
+-- ----------------------------------------------------------------------------
+-- 1. LIST FLAVOR DESCRIPTORS
+-- ----------------------------------------------------------------------------
+-- Parameters: None
+-- Returns: Every flavor wheel descriptor, parents before children
+-- Usage: Build the flavor wheel tree for pickers
+-- name: ListFlavorDescriptors :many
+SELECT * FROM flavor_descriptor
+ORDER BY depth, id;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. COUNT FLAVOR DESCRIPTORS
+-- ----------------------------------------------------------------------------
+-- Parameters: ids (descriptor IDs)
+-- Returns: How many of the IDs exist
+-- Usage: Validate descriptor IDs before tagging
+-- name: CountFlavorDescriptors :one
+SELECT COUNT(*) FROM flavor_descriptor
+WHERE id = ANY(sqlc.arg(ids)::text[]);
+
+
+-- ----------------------------------------------------------------------------
+-- 3. SET BREW FLAVORS
+-- ----------------------------------------------------------------------------
+-- Parameters: brew_id, descriptor_ids (the complete new set)
+-- Returns: Nothing
+-- Usage: Replace a brew's tasting descriptors in one statement
+-- name: SetBrewFlavors :exec
+WITH removed AS (
+    DELETE FROM brew_flavor
+    WHERE brew_id = sqlc.arg(brew_id)
+        AND NOT (descriptor_id = ANY(sqlc.arg(descriptor_ids)::text[]))
+)
+INSERT INTO brew_flavor (brew_id, descriptor_id)
+SELECT sqlc.arg(brew_id), unnest(sqlc.arg(descriptor_ids)::text[])
+ON CONFLICT DO NOTHING;
+
+
+-- ----------------------------------------------------------------------------
+-- 4. LIST BREW FLAVORS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = brew_id
+-- Returns: The brew's descriptors in wheel order
+-- Usage: Show a brew's tasting notes
+-- name: ListBrewFlavors :many
+SELECT fd.*
+FROM brew_flavor bf
+JOIN flavor_descriptor fd ON fd.id = bf.descriptor_id
+WHERE bf.brew_id = $1
+ORDER BY fd.id;
+
+
+-- ----------------------------------------------------------------------------
+-- 5. SET BEAN BAG FLAVORS
+-- ----------------------------------------------------------------------------
+-- Parameters: bean_bag_id, descriptor_ids (the complete new set)
+-- Returns: Nothing
+-- Usage: Replace a bag's descriptors (e.g. the roaster's notes)
+-- name: SetBeanBagFlavors :exec
+WITH removed AS (
+    DELETE FROM bean_bag_flavor
+    WHERE bean_bag_id = sqlc.arg(bean_bag_id)
+        AND NOT (descriptor_id = ANY(sqlc.arg(descriptor_ids)::text[]))
+)
+INSERT INTO bean_bag_flavor (bean_bag_id, descriptor_id)
+SELECT sqlc.arg(bean_bag_id), unnest(sqlc.arg(descriptor_ids)::text[])
+ON CONFLICT DO NOTHING;
+
+
+-- ----------------------------------------------------------------------------
+-- 6. LIST BEAN BAG FLAVORS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = bean_bag_id
+-- Returns: The bag's own descriptors in wheel order
+-- Usage: Show a bag's flavor notes
+-- name: ListBeanBagFlavors :many
+SELECT fd.*
+FROM bean_bag_flavor bbf
+JOIN flavor_descriptor fd ON fd.id = bbf.descriptor_id
+WHERE bbf.bean_bag_id = $1
+ORDER BY fd.id;
+
+
+-- ----------------------------------------------------------------------------
+-- 7. GET BEAN BAG TOP FLAVORS
+-- ----------------------------------------------------------------------------
+-- Parameters: bean_bag_id, row_limit
+-- Returns: The most common descriptors across the bag's own notes and the
+--          brews made from it, with their top-level category
+-- Usage: "What does this bean taste like" summary
+-- Performance: Uses idx_brew_bean_bag
+-- name: GetBeanBagTopFlavors :many
+WITH tags AS (
+    SELECT descriptor_id FROM bean_bag_flavor
+    WHERE bean_bag_id = sqlc.arg(bean_bag_id)
+    UNION ALL
+    SELECT bf.descriptor_id
+    FROM brew_flavor bf
+    JOIN brew b ON b.id = bf.brew_id
+    WHERE b.bean_bag_id = sqlc.arg(bean_bag_id)
+)
+SELECT
+    fd.id,
+    fd.name,
+    fd.depth,
+    split_part(fd.id, '.', 1)::text AS category,
+    COUNT(*) AS mentions
+FROM tags
+JOIN flavor_descriptor fd ON fd.id = tags.descriptor_id
+GROUP BY fd.id
+ORDER BY mentions DESC, fd.id
+LIMIT sqlc.arg(row_limit);
+
+
+-- ----------------------------------------------------------------------------
+-- 8. GET ORIGIN TOP FLAVORS
+-- ----------------------------------------------------------------------------
+-- Parameters: origin (matched case-insensitively), row_limit
+-- Returns: The most common descriptors on public brews of beans from that
+--          origin, with their top-level category and how many brews noted
+--          each
+-- Usage: Discover what an origin typically tastes like
+-- name: GetOriginTopFlavors :many
+SELECT
+    fd.id,
+    fd.name,
+    fd.depth,
+    split_part(fd.id, '.', 1)::text AS category,
+    COUNT(*) AS mentions
+FROM brew_flavor bf
+JOIN brew b ON b.id = bf.brew_id
+JOIN flavor_descriptor fd ON fd.id = bf.descriptor_id
+WHERE LOWER(b.bean_origin) = LOWER(sqlc.arg(origin))
+    AND b.is_public IS NOT FALSE
+GROUP BY fd.id
+ORDER BY mentions DESC, fd.id
+LIMIT sqlc.arg(row_limit);
+
+
+-- ----------------------------------------------------------------------------
+-- 9. LIST RECENT BREW FLAVORS
+-- ----------------------------------------------------------------------------
+-- Parameters: user_id, row_limit
+-- Returns: The descriptors the user has noted on their brews, most recently
+--          noted first
+-- Usage: Recent picks on the log-a-brew form
+-- Performance: Uses idx_brew_created_by
+-- name: ListRecentBrewFlavors :many
+SELECT
+    fd.id,
+    fd.name,
+    fd.depth,
+    MAX(bf.created_at)::timestamptz AS last_used_at
+FROM brew_flavor bf
+JOIN brew b ON b.id = bf.brew_id
+JOIN flavor_descriptor fd ON fd.id = bf.descriptor_id
+WHERE b.created_by = sqlc.arg(user_id)
+GROUP BY fd.id
+ORDER BY last_used_at DESC NULLS LAST, fd.id
+LIMIT sqlc.arg(row_limit);
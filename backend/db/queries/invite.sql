@@ -0,0 +1,118 @@
+-- ============================================================================
+-- INVITE QUERIES
+-- ============================================================================
+-- Operations for invite codes and the referral stats of the users who hand
+-- them out. An invite's uses count against its creator's quota until it is
+-- revoked or expires; then only the uses already redeemed do.
+
+
+-- ----------------------------------------------------------------------------
+-- 1. CREATE INVITE
+-- ----------------------------------------------------------------------------
+-- Parameters: code, inviter_id, max_uses, expires_at, quota (NULL for no
+--             quota)
+-- Returns: The new invite, or no rows if it would exceed the quota
+-- Usage: User creates an invite to share
+-- Note: create_invite locks the inviter's row, so concurrent requests can't
+--       both fit under the quota
+-- name: CreateInvite :one
+SELECT * FROM create_invite(
+    sqlc.arg(inviter_id)::text,
+    sqlc.arg(code)::varchar,
+    sqlc.arg(max_uses)::int,
+    sqlc.narg(expires_at)::timestamptz,
+    sqlc.narg(quota)::int
+);
+
+
+-- ----------------------------------------------------------------------------
+-- 2. GET INVITE
+-- ----------------------------------------------------------------------------
+-- Parameters: code
+-- Returns: The invite, its creator's username, and whether it can still be
+--          redeemed
+-- Usage: Signup page shows who invited the user
+-- name: GetInvite :one
+SELECT
+    i.code,
+    i.inviter_id,
+    u.username AS inviter_username,
+    (i.revoked_at IS NULL
+        AND (i.expires_at IS NULL OR i.expires_at > NOW())
+        AND i.use_count < i.max_uses)::boolean AS redeemable
+FROM invite i
+JOIN "user" u ON u.id = i.inviter_id
+WHERE i.code = sqlc.arg(code);
+
+
+-- ----------------------------------------------------------------------------
+-- 3. LIST USER INVITES
+-- ----------------------------------------------------------------------------
+-- Parameters: inviter_id
+-- Returns: The user's invites, newest first
+-- Usage: User's invites page
+-- Performance: Uses idx_invite_inviter
+-- name: ListUserInvites :many
+SELECT * FROM invite
+WHERE inviter_id = sqlc.arg(inviter_id)
+ORDER BY created_at DESC;
+
+
+-- ----------------------------------------------------------------------------
+-- 4. REVOKE INVITE
+-- ----------------------------------------------------------------------------
+-- Parameters: code, inviter_id
+-- Returns: Rows affected (0 if not the user's or already revoked)
+-- Usage: User revokes an invite; its unredeemed uses return to their quota
+-- name: RevokeInvite :execrows
+UPDATE invite
+SET revoked_at = NOW()
+WHERE code = sqlc.arg(code)
+  AND inviter_id = sqlc.arg(inviter_id)
+  AND revoked_at IS NULL;
+
+
+-- ----------------------------------------------------------------------------
+-- 5. GET REFERRAL STATS
+-- ----------------------------------------------------------------------------
+-- Parameters: inviter_id
+-- Returns: Invite uses allotted against the quota, users who signed up
+--          with the user's invites (in all, in the last 30 days), and how
+--          many of those logged a brew in the last 30 days
+-- Usage: Referral stats endpoint
+-- Performance: Uses idx_invite_inviter, idx_user_invited_by and
+--              idx_brew_created_by
+-- name: GetReferralStats :one
+SELECT
+    (SELECT COALESCE(SUM(
+        CASE
+            WHEN i.revoked_at IS NULL AND (i.expires_at IS NULL OR i.expires_at > NOW()) THEN i.max_uses
+            ELSE i.use_count
+        END
+    ), 0)::int
+     FROM invite i
+     WHERE i.inviter_id = sqlc.arg(inviter_id)) AS allotted_uses,
+    COUNT(*)::int AS signups,
+    COUNT(*) FILTER (WHERE u.joined_at > NOW() - INTERVAL '30 days')::int AS recent_signups,
+    COUNT(*) FILTER (WHERE EXISTS (
+        SELECT 1 FROM brew b
+        WHERE b.created_by = u.id
+          AND b.created_at > NOW() - INTERVAL '30 days'
+    ))::int AS active_referrals
+FROM "user" u
+WHERE u.invited_by = sqlc.arg(inviter_id);
+
+
+-- ----------------------------------------------------------------------------
+-- 6. LIST REFERRALS
+-- ----------------------------------------------------------------------------
+-- Parameters: inviter_id, row_limit
+-- Returns: Users who signed up with the user's invites, newest first
+-- Usage: Referral stats endpoint
+-- Performance: Uses idx_user_invited_by
+-- name: ListReferrals :many
+SELECT id, username, invite_code, joined_at
+FROM "user"
+WHERE invited_by = sqlc.arg(inviter_id)
+ORDER BY joined_at DESC
+LIMIT sqlc.arg(row_limit);
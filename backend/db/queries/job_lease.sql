@@ -0,0 +1,73 @@
+-- ============================================================================
+-- JOB LEASE QUERIES
+-- ============================================================================
+-- Acquiring, renewing and releasing the leases that keep singleton
+-- background jobs on one instance at a time
+
+
+-- ----------------------------------------------------------------------------
+-- 1. ACQUIRE JOB LEASE
+-- ----------------------------------------------------------------------------
+-- Parameters: name, holder, ttl_seconds
+-- Returns: The lease's epoch if holder now holds it for ttl_seconds; no
+--          rows if another holder's lease hasn't expired. The epoch goes
+--          up whenever the lease is taken rather than renewed, including
+--          by a holder whose own lease expired.
+-- Usage: Taking a job's lease, or renewing one already held
+-- Performance: Uses the primary key
+-- name: AcquireJobLease :one
+INSERT INTO job_lease (name, holder, expires_at)
+VALUES (sqlc.arg(name), sqlc.arg(holder), NOW() + sqlc.arg(ttl_seconds)::int * INTERVAL '1 second')
+ON CONFLICT (name) DO UPDATE
+SET holder = EXCLUDED.holder,
+    acquired_at = CASE WHEN job_lease.holder = EXCLUDED.holder AND job_lease.expires_at > NOW()
+                       THEN job_lease.acquired_at ELSE NOW() END,
+    epoch = CASE WHEN job_lease.holder = EXCLUDED.holder AND job_lease.expires_at > NOW()
+                 THEN job_lease.epoch ELSE job_lease.epoch + 1 END,
+    expires_at = EXCLUDED.expires_at
+WHERE job_lease.holder = EXCLUDED.holder OR job_lease.expires_at <= NOW()
+RETURNING epoch;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. RELEASE JOB LEASE
+-- ----------------------------------------------------------------------------
+-- Parameters: name, holder
+-- Returns: Nothing
+-- Usage: A job stopping, so another instance can take it at once. The
+--        lease expires rather than being deleted, so its epoch keeps
+--        counting up.
+-- Performance: Uses the primary key
+-- name: ReleaseJobLease :exec
+UPDATE job_lease
+SET expires_at = NOW()
+WHERE name = sqlc.arg(name) AND holder = sqlc.arg(holder);
+
+
+-- ----------------------------------------------------------------------------
+-- 3. LIST JOB LEASES
+-- ----------------------------------------------------------------------------
+-- Parameters: None
+-- Returns: Every lease, held or expired, by job name
+-- Usage: Admin job lease report
+-- name: ListJobLeases :many
+SELECT * FROM job_lease
+ORDER BY name;
+
+
+-- ----------------------------------------------------------------------------
+-- 4. CHECK JOB LEASE
+-- ----------------------------------------------------------------------------
+-- Parameters: name, holder, epoch
+-- Returns: Whether holder still holds the lease it took at epoch, unexpired
+-- Usage: Fencing a singleton job's writes: a job that lost its lease, even
+--        if its instance has since taken it again, fails the check
+-- Performance: Uses the primary key
+-- name: CheckJobLease :one
+SELECT EXISTS (
+    SELECT 1 FROM job_lease
+    WHERE name = sqlc.arg(name)
+      AND holder = sqlc.arg(holder)
+      AND epoch = sqlc.arg(epoch)
+      AND expires_at > NOW()
+);
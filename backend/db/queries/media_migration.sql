@@ -0,0 +1,123 @@
+-- ============================================================================
+-- MEDIA MIGRATION QUERIES
+-- ============================================================================
+-- Enumerating the objects the database refers to, and tracking which have
+-- been copied to another object store, for the media migration tool
+
+
+-- ----------------------------------------------------------------------------
+-- 1. LIST UNMIGRATED MEDIA OBJECTS
+-- ----------------------------------------------------------------------------
+-- Parameters: after_key, destination, row_limit
+-- Returns: Objects not yet migrated to destination, after after_key in key
+--          order: brew photo originals and renditions, and backup dumps,
+--          with their content type, version and, where recorded, size and
+--          SHA-256. An object migrated at an older version is listed again.
+-- Usage: Media migration tool, paging through the objects left to copy
+-- Performance: Pages through each source on its key's index, then merges
+--              the pages; checks each key against the primary key of
+--              media_migration_object
+-- name: ListUnmigratedMediaObjects :many
+WITH media_object AS (
+    (SELECT original_key AS object_key, content_type, original_bytes AS size_bytes, NULL::varchar AS sha256,
+            created_at AS version
+     FROM brew_photo
+     WHERE original_key > sqlc.arg(after_key)::text
+       AND NOT EXISTS (
+          SELECT 1 FROM media_migration_object m
+          WHERE m.destination = sqlc.arg(destination) AND m.object_key = original_key
+            AND m.version IS NOT DISTINCT FROM created_at
+      )
+     ORDER BY original_key
+     LIMIT sqlc.arg(row_limit))
+    UNION ALL
+    (SELECT image_key, 'image/jpeg', image_bytes, NULL, processed_at
+     FROM brew_photo
+     WHERE image_key > sqlc.arg(after_key)::text
+       AND NOT EXISTS (
+          SELECT 1 FROM media_migration_object m
+          WHERE m.destination = sqlc.arg(destination) AND m.object_key = image_key
+            AND m.version IS NOT DISTINCT FROM processed_at
+      )
+     ORDER BY image_key
+     LIMIT sqlc.arg(row_limit))
+    UNION ALL
+    (SELECT thumbnail_key, 'image/jpeg', thumbnail_bytes, NULL, processed_at
+     FROM brew_photo
+     WHERE thumbnail_key > sqlc.arg(after_key)::text
+       AND NOT EXISTS (
+          SELECT 1 FROM media_migration_object m
+          WHERE m.destination = sqlc.arg(destination) AND m.object_key = thumbnail_key
+            AND m.version IS NOT DISTINCT FROM processed_at
+      )
+     ORDER BY thumbnail_key
+     LIMIT sqlc.arg(row_limit))
+    UNION ALL
+    (SELECT object_key, 'application/octet-stream', size_bytes, sha256, finished_at
+     FROM backup_run
+     WHERE object_key > sqlc.arg(after_key)::text
+       AND NOT EXISTS (
+          SELECT 1 FROM media_migration_object m
+          WHERE m.destination = sqlc.arg(destination) AND m.object_key = object_key
+            AND m.version IS NOT DISTINCT FROM finished_at
+      )
+     ORDER BY object_key
+     LIMIT sqlc.arg(row_limit))
+)
+SELECT o.object_key::text AS object_key, o.content_type::text AS content_type, o.size_bytes, o.sha256, o.version
+FROM media_object o
+ORDER BY o.object_key
+LIMIT sqlc.arg(row_limit);
+
+
+-- ----------------------------------------------------------------------------
+-- 2. COUNT MEDIA OBJECTS
+-- ----------------------------------------------------------------------------
+-- Parameters: destination
+-- Returns: How many objects the database refers to, and how many of them
+--          have been migrated to destination at their current version
+-- Usage: Media migration tool, reporting progress
+-- Performance: Scans brew_photo and backup_run
+-- name: CountMediaObjects :one
+WITH media_object AS (
+    SELECT original_key AS object_key, created_at AS version FROM brew_photo
+    UNION ALL
+    SELECT image_key, processed_at FROM brew_photo WHERE image_key IS NOT NULL
+    UNION ALL
+    SELECT thumbnail_key, processed_at FROM brew_photo WHERE thumbnail_key IS NOT NULL
+    UNION ALL
+    SELECT object_key, finished_at FROM backup_run WHERE object_key IS NOT NULL
+)
+SELECT
+    COUNT(*)::int AS total,
+    COUNT(m.object_key)::int AS migrated
+FROM media_object o
+LEFT JOIN media_migration_object m
+    ON m.destination = sqlc.arg(destination) AND m.object_key = o.object_key
+    AND m.version IS NOT DISTINCT FROM o.version;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. RECORD MEDIA MIGRATION OBJECT
+-- ----------------------------------------------------------------------------
+-- Parameters: destination, object_key, version, size_bytes, sha256
+-- Returns: Nothing
+-- Usage: Media migration tool, once an object's copy is verified
+-- Performance: Uses the primary key
+-- name: RecordMediaMigrationObject :exec
+INSERT INTO media_migration_object (destination, object_key, version, size_bytes, sha256)
+VALUES (sqlc.arg(destination), sqlc.arg(object_key), sqlc.arg(version), sqlc.arg(size_bytes), sqlc.arg(sha256))
+ON CONFLICT (destination, object_key) DO UPDATE
+SET version = EXCLUDED.version, size_bytes = EXCLUDED.size_bytes, sha256 = EXCLUDED.sha256, migrated_at = NOW();
+
+
+-- ----------------------------------------------------------------------------
+-- 4. RESET MEDIA MIGRATION
+-- ----------------------------------------------------------------------------
+-- Parameters: destination
+-- Returns: Nothing
+-- Usage: Media migration tool, starting a migration to destination over
+-- Performance: Uses the primary key
+-- name: ResetMediaMigration :exec
+DELETE FROM media_migration_object
+WHERE destination = sqlc.arg(destination);
@@ -11,8 +11,8 @@
 --             $4 = type, $5 = reference_id, $6 = reference_type
 -- Returns: Created notification record
 -- Usage: Notify user of actions (like, comment, friend request, tag)
--- Types: 'like', 'comment', 'friend_request', 'tag', 'follow'
--- Reference types: 'post', 'comment', 'friendship'
+-- Types: 'like', 'comment', 'friend_request', 'tag', 'follow', 'brew_reminder'
+-- Reference types: 'post', 'comment', 'friendship', 'brew'
 -- name: CreateNotification :one
 INSERT INTO notification (id, recipient_user_id, actor_user_id, type, reference_id, reference_type)
 VALUES ($1, $2, $3, $4, $5, $6)
@@ -0,0 +1,103 @@
+-- ============================================================================
+-- OIDC QUERIES
+-- ============================================================================
+-- Operations for brewd's OIDC provider: the companion service clients
+-- admins register and the authorization codes issued to them
+
+
+-- ----------------------------------------------------------------------------
+-- 1. CREATE OIDC CLIENT
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id, $2 = name, $3 = secret_hash, $4 = redirect_uris
+-- Returns: The created client (the secret itself is never stored)
+-- Usage: Admin registers a companion service
+-- name: CreateOIDCClient :one
+INSERT INTO oidc_client (id, name, secret_hash, redirect_uris)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. LIST OIDC CLIENTS
+-- ----------------------------------------------------------------------------
+-- Parameters: None
+-- Returns: Every client, newest first
+-- Usage: Admin reviews the registered companion services
+-- name: ListOIDCClients :many
+SELECT * FROM oidc_client
+ORDER BY created_at DESC;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. GET OIDC CLIENT
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id
+-- Returns: The client
+-- Usage: Checking a client's redirect URI at authorization and its secret
+--        at the token endpoint
+-- name: GetOIDCClient :one
+SELECT * FROM oidc_client
+WHERE id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 4. DELETE OIDC CLIENT
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id
+-- Returns: Number of rows deleted; 0 if there is no such client
+-- Usage: Admin removes a companion service. Its unredeemed codes go with
+--        it; tokens already issued last until they expire.
+-- name: DeleteOIDCClient :execrows
+DELETE FROM oidc_client
+WHERE id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 5. CREATE OIDC AUTH CODE
+-- ----------------------------------------------------------------------------
+-- Parameters: code_hash, client_id, user_id, redirect_uri, scopes, nonce,
+--             code_challenge, auth_time, ttl_seconds
+-- Returns: None
+-- Usage: A signed-in user is sent back to a client with a code. Expired
+--        codes are deleted in the same statement.
+-- Performance: Uses idx_oidc_auth_code_expires_at
+-- name: CreateOIDCAuthCode :exec
+WITH expired AS (
+    DELETE FROM oidc_auth_code
+    WHERE expires_at < NOW()
+)
+INSERT INTO oidc_auth_code (
+    code_hash, client_id, user_id, redirect_uri, scopes, nonce, code_challenge, auth_time, expires_at
+)
+VALUES (
+    sqlc.arg(code_hash), sqlc.arg(client_id), sqlc.arg(user_id), sqlc.arg(redirect_uri), sqlc.arg(scopes),
+    sqlc.narg(nonce), sqlc.narg(code_challenge), sqlc.arg(auth_time),
+    NOW() + sqlc.arg(ttl_seconds)::int * INTERVAL '1 second'
+);
+
+
+-- ----------------------------------------------------------------------------
+-- 6. REDEEM OIDC AUTH CODE
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = code_hash
+-- Returns: The code, deleted so it can't be redeemed again
+-- Usage: Token endpoint; the caller checks the client, redirect URI, expiry
+--        and PKCE verifier, so a code presented wrongly is spent too
+-- name: RedeemOIDCAuthCode :one
+DELETE FROM oidc_auth_code
+WHERE code_hash = $1
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 7. GET OIDC USER
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id
+-- Returns: The user's claims: username, email, profile picture and when the
+--          profile last changed; no rows if the account was deactivated
+--          or awaits parental consent
+-- Usage: ID tokens and the userinfo endpoint
+-- name: GetOIDCUser :one
+SELECT id, username, email, profile_picture_url, updated_at
+FROM "user"
+WHERE id = $1 AND deactivated_at IS NULL AND NOT consent_pending;
@@ -0,0 +1,275 @@
+-- ============================================================================
+-- OPS STAT QUERIES
+-- ============================================================================
+-- Operations for the admin dashboard's precomputed stats. Days are UTC.
+
+
+-- ----------------------------------------------------------------------------
+-- 1. ADD REQUEST COUNTS
+-- ----------------------------------------------------------------------------
+-- Parameters: day, requests, client_errors, server_errors
+-- Returns: None
+-- Usage: Ops stats job flushes an instance's HTTP counters; counts add up
+--        across instances
+-- name: AddRequestCounts :exec
+INSERT INTO ops_daily_stat (day, requests, client_errors, server_errors)
+VALUES (sqlc.arg(day), sqlc.arg(requests), sqlc.arg(client_errors), sqlc.arg(server_errors))
+ON CONFLICT (day) DO UPDATE
+SET
+    requests = ops_daily_stat.requests + EXCLUDED.requests,
+    client_errors = ops_daily_stat.client_errors + EXCLUDED.client_errors,
+    server_errors = ops_daily_stat.server_errors + EXCLUDED.server_errors;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. REFRESH DAILY STATS
+-- ----------------------------------------------------------------------------
+-- Parameters: since (first day to recompute, through today)
+-- Returns: None
+-- Usage: Ops stats job. A user is active on a day they logged a brew, posted,
+--        liked or commented. Request counts are left alone.
+-- Performance: Uses idx_user_joined_at, idx_brew_created_at,
+--              idx_post_created_at and idx_comment_created_at
+-- name: RefreshDailyStats :exec
+WITH activity AS (
+    SELECT DISTINCT user_id, (created_at AT TIME ZONE 'UTC')::date AS day
+    FROM (
+        SELECT created_by AS user_id, created_at FROM brew
+        WHERE created_at >= (sqlc.arg(since)::date - 6)::timestamp AT TIME ZONE 'UTC'
+            AND created_by IS NOT NULL
+        UNION ALL
+        SELECT owner_id, created_at FROM post
+        WHERE created_at >= (sqlc.arg(since)::date - 6)::timestamp AT TIME ZONE 'UTC'
+        UNION ALL
+        SELECT user_id, created_at FROM post_likes
+        WHERE created_at >= (sqlc.arg(since)::date - 6)::timestamp AT TIME ZONE 'UTC'
+        UNION ALL
+        SELECT owner_id, created_at FROM comment
+        WHERE created_at >= (sqlc.arg(since)::date - 6)::timestamp AT TIME ZONE 'UTC'
+    ) events
+),
+days AS (
+    SELECT generate_series(sqlc.arg(since)::date, (NOW() AT TIME ZONE 'UTC')::date, INTERVAL '1 day')::date AS day
+)
+INSERT INTO ops_daily_stat (day, signups, active_users, weekly_active_users, brews_logged, computed_at)
+SELECT
+    d.day,
+    (SELECT COUNT(*) FROM "user" u
+     WHERE u.joined_at >= d.day::timestamp AT TIME ZONE 'UTC' AND u.joined_at < (d.day + 1)::timestamp AT TIME ZONE 'UTC'),
+    (SELECT COUNT(*) FROM activity a WHERE a.day = d.day),
+    (SELECT COUNT(DISTINCT a.user_id) FROM activity a WHERE a.day BETWEEN d.day - 6 AND d.day),
+    (SELECT COUNT(*) FROM brew b
+     WHERE b.created_at >= d.day::timestamp AT TIME ZONE 'UTC' AND b.created_at < (d.day + 1)::timestamp AT TIME ZONE 'UTC'),
+    NOW()
+FROM days d
+ON CONFLICT (day) DO UPDATE
+SET
+    signups = EXCLUDED.signups,
+    active_users = EXCLUDED.active_users,
+    weekly_active_users = EXCLUDED.weekly_active_users,
+    brews_logged = EXCLUDED.brews_logged,
+    computed_at = EXCLUDED.computed_at;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. REFRESH METHOD STATS
+-- ----------------------------------------------------------------------------
+-- Parameters: since, row_limit
+-- Returns: None
+-- Usage: Ops stats job; replaces the top brew methods by brews logged since
+--        the given time
+-- Performance: Uses idx_brew_created_at
+-- name: RefreshMethodStats :exec
+WITH cleared AS (
+    DELETE FROM ops_method_stat
+)
+INSERT INTO ops_method_stat (brew_method, brew_count)
+SELECT brew_method, COUNT(*)
+FROM brew
+WHERE created_at >= sqlc.arg(since) AND brew_method IS NOT NULL
+GROUP BY brew_method
+ORDER BY COUNT(*) DESC, brew_method
+LIMIT sqlc.arg(row_limit);
+
+
+-- ----------------------------------------------------------------------------
+-- 4. REFRESH QUEUE STATS
+-- ----------------------------------------------------------------------------
+-- Parameters: None
+-- Returns: None
+-- Usage: Ops stats job samples the backlog of each background queue:
+--        automation webhook deliveries, outbox messages, unpublished domain
+--        events and users awaiting badge evaluation
+-- Performance: Pending counts use each queue's partial due index; failures
+--              scan the queue
+-- name: RefreshQueueStats :exec
+INSERT INTO ops_queue_stat (queue, depth, failing, oldest_at, sampled_at)
+SELECT 'automation_delivery',
+    COUNT(*) FILTER (WHERE delivered_at IS NULL AND failed_at IS NULL),
+    COUNT(*) FILTER (WHERE failed_at > NOW() - INTERVAL '1 day'),
+    MIN(created_at) FILTER (WHERE delivered_at IS NULL AND failed_at IS NULL),
+    NOW()
+FROM automation_delivery
+UNION ALL
+SELECT 'outbox_message',
+    COUNT(*) FILTER (WHERE dispatched_at IS NULL AND failed_at IS NULL),
+    COUNT(*) FILTER (WHERE failed_at > NOW() - INTERVAL '1 day'),
+    MIN(created_at) FILTER (WHERE dispatched_at IS NULL AND failed_at IS NULL),
+    NOW()
+FROM outbox_message
+UNION ALL
+SELECT 'domain_event',
+    COUNT(*),
+    COUNT(*) FILTER (WHERE last_error IS NOT NULL),
+    MIN(created_at),
+    NOW()
+FROM domain_event
+WHERE published_at IS NULL
+UNION ALL
+SELECT 'badge_evaluation', COUNT(*), 0, MIN(queued_at), NOW()
+FROM badge_evaluation
+ON CONFLICT (queue) DO UPDATE
+SET
+    depth = EXCLUDED.depth,
+    failing = EXCLUDED.failing,
+    oldest_at = EXCLUDED.oldest_at,
+    sampled_at = EXCLUDED.sampled_at;
+
+
+-- ----------------------------------------------------------------------------
+-- 5. LIST DAILY STATS
+-- ----------------------------------------------------------------------------
+-- Parameters: since (first day)
+-- Returns: Daily stats from that day on, newest first
+-- Usage: Admin stats
+-- name: ListDailyStats :many
+SELECT * FROM ops_daily_stat
+WHERE day >= sqlc.arg(since)
+ORDER BY day DESC;
+
+
+-- ----------------------------------------------------------------------------
+-- 6. LIST METHOD STATS
+-- ----------------------------------------------------------------------------
+-- Parameters: None
+-- Returns: Top brew methods, most logged first
+-- Usage: Admin stats
+-- name: ListMethodStats :many
+SELECT * FROM ops_method_stat
+ORDER BY brew_count DESC, brew_method;
+
+
+-- ----------------------------------------------------------------------------
+-- 7. LIST QUEUE STATS
+-- ----------------------------------------------------------------------------
+-- Parameters: None
+-- Returns: Latest backlog sample of each queue
+-- Usage: Admin stats
+-- name: ListQueueStats :many
+SELECT * FROM ops_queue_stat
+ORDER BY queue;
+
+
+-- ----------------------------------------------------------------------------
+-- 8. LIST QUEUE LAG
+-- ----------------------------------------------------------------------------
+-- Parameters: None
+-- Returns: For each background queue, when its oldest item fell due, or
+--          NULL if nothing is due
+-- Usage: Readiness check; items long overdue mean the queue's worker isn't
+--        running
+-- Performance: Uses each queue's partial due index
+-- name: ListQueueLag :many
+SELECT 'automation_delivery'::text AS queue, MIN(next_attempt_at) AS due_since
+FROM automation_delivery
+WHERE delivered_at IS NULL AND failed_at IS NULL AND next_attempt_at <= NOW()
+UNION ALL
+SELECT 'outbox_message', MIN(next_attempt_at)
+FROM outbox_message
+WHERE dispatched_at IS NULL AND failed_at IS NULL AND next_attempt_at <= NOW()
+UNION ALL
+SELECT 'domain_event', MIN(next_attempt_at)
+FROM domain_event
+WHERE published_at IS NULL AND next_attempt_at <= NOW()
+UNION ALL
+SELECT 'badge_evaluation', MIN(queued_at)
+FROM badge_evaluation;
+
+
+-- ----------------------------------------------------------------------------
+-- 9. LIST TABLE STATS
+-- ----------------------------------------------------------------------------
+-- Parameters: None
+-- Returns: Each table in the schema (partitions included) with its
+--          estimated and live/dead row counts, heap, index and total sizes,
+--          and an estimate of its bloat, largest first
+-- Usage: Admin table report. Row counts and the bloat estimate come from
+--        the planner statistics of the last ANALYZE or autovacuum: bloat
+--        is the heap's pages beyond those its rows would fill at their
+--        average width.
+-- Performance: Reads the catalog and statistics views; no table is scanned
+-- name: ListTableStats :many
+WITH widths AS (
+    SELECT s.tablename, SUM(s.avg_width) AS row_width
+    FROM pg_stats s
+    WHERE s.schemaname = current_schema()
+    GROUP BY s.tablename
+),
+tables AS (
+    SELECT
+        c.oid,
+        c.relname,
+        c.relpages,
+        GREATEST(c.reltuples, 0) AS reltuples,
+        parent.relname AS parent_name,
+        w.row_width
+    FROM pg_class c
+    JOIN pg_namespace n ON n.oid = c.relnamespace
+    LEFT JOIN pg_inherits i ON i.inhrelid = c.oid
+    LEFT JOIN pg_class parent ON parent.oid = i.inhparent
+    LEFT JOIN widths w ON w.tablename = c.relname
+    WHERE n.nspname = current_schema()
+      AND c.relkind IN ('r', 'm')
+)
+SELECT
+    t.relname::text AS table_name,
+    t.parent_name::text AS partition_of,
+    t.reltuples::bigint AS estimated_rows,
+    COALESCE(st.n_live_tup, 0)::bigint AS live_rows,
+    COALESCE(st.n_dead_tup, 0)::bigint AS dead_rows,
+    pg_table_size(t.oid)::bigint AS table_bytes,
+    pg_indexes_size(t.oid)::bigint AS index_bytes,
+    pg_total_relation_size(t.oid)::bigint AS total_bytes,
+    -- Tuple header and line pointer: 28 bytes a row; page header: 24
+    (CASE WHEN t.row_width IS NULL THEN 0
+          ELSE GREATEST(t.relpages - CEIL(t.reltuples * (t.row_width + 28)
+               / (current_setting('block_size')::int - 24)), 0)
+               * current_setting('block_size')::int
+     END)::bigint AS bloat_bytes,
+    st.last_vacuum,
+    st.last_autovacuum,
+    st.last_analyze,
+    st.last_autoanalyze
+FROM tables t
+LEFT JOIN pg_stat_user_tables st ON st.relid = t.oid
+ORDER BY total_bytes DESC, table_name;
+
+
+-- ----------------------------------------------------------------------------
+-- 10. LIST INDEX STATS
+-- ----------------------------------------------------------------------------
+-- Parameters: None
+-- Returns: Each index in the schema with its table, size and the scans
+--          that have used it since statistics were last reset, largest
+--          first
+-- Usage: Admin table report; an index with no scans may be unused
+-- Performance: Reads the catalog and statistics views
+-- name: ListIndexStats :many
+SELECT
+    st.relname::text AS table_name,
+    st.indexrelname::text AS index_name,
+    pg_relation_size(st.indexrelid)::bigint AS index_bytes,
+    st.idx_scan AS scans
+FROM pg_stat_user_indexes st
+WHERE st.schemaname = current_schema()
+ORDER BY index_bytes DESC, index_name;
@@ -0,0 +1,195 @@
+-- ============================================================================
+-- ORGANIZATION QUERIES
+-- ============================================================================
+-- Operations for organizations: membership and roles, and the brews logged
+-- in them. Every query on an organization's resources takes its ID, the
+-- request's tenant, so no query reaches across organizations.
+
+
+-- ----------------------------------------------------------------------------
+-- 1. CREATE ORGANIZATION
+-- ----------------------------------------------------------------------------
+-- Parameters: id, name, kind, owner_id
+-- Returns: The created organization
+-- Usage: Create an organization with its creator as owner, in one statement
+-- name: CreateOrganization :one
+WITH organization_row AS (
+    INSERT INTO organization (id, name, kind)
+    VALUES (sqlc.arg(id), sqlc.arg(name), sqlc.arg(kind))
+    RETURNING *
+), owner_member AS (
+    INSERT INTO organization_member (organization_id, user_id, role)
+    SELECT id, sqlc.arg(owner_id), 'owner' FROM organization_row
+)
+SELECT * FROM organization_row;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. GET ORGANIZATION
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id
+-- Returns: The organization
+-- Usage: Organization page, after membership was checked
+-- name: GetOrganization :one
+SELECT * FROM organization
+WHERE id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. UPDATE ORGANIZATION
+-- ----------------------------------------------------------------------------
+-- Parameters: id, name, kind (NULL leaves a field as is)
+-- Returns: The updated organization
+-- Usage: Owners and admins edit the organization's details
+-- name: UpdateOrganization :one
+UPDATE organization
+SET
+    name = COALESCE(sqlc.narg(name), name),
+    kind = COALESCE(sqlc.narg(kind), kind)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 4. DELETE ORGANIZATION
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id
+-- Returns: Nothing
+-- Usage: An owner deletes the organization with its members and brews
+--        (CASCADE)
+-- name: DeleteOrganization :exec
+DELETE FROM organization
+WHERE id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 5. LIST USER ORGANIZATIONS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id
+-- Returns: Organizations the user belongs to, with their role and member
+--          counts
+-- Usage: Organization switcher
+-- Performance: Uses idx_organization_member_user
+-- name: ListUserOrganizations :many
+SELECT
+    o.id,
+    o.name,
+    o.kind,
+    m.role,
+    o.created_at,
+    (SELECT COUNT(*) FROM organization_member WHERE organization_id = o.id) AS member_count
+FROM organization_member m
+JOIN organization o ON o.id = m.organization_id
+WHERE m.user_id = $1
+ORDER BY o.name;
+
+
+-- ----------------------------------------------------------------------------
+-- 6. GET ORGANIZATION MEMBER ROLE
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = organization_id, $2 = user_id
+-- Returns: The user's role, or no rows if they are not a member
+-- Usage: Resolve the tenant of /orgs/:org_id routes; check a member's role
+--        before changing it
+-- name: GetOrganizationMemberRole :one
+SELECT role FROM organization_member
+WHERE organization_id = $1 AND user_id = $2;
+
+
+-- ----------------------------------------------------------------------------
+-- 7. COUNT ORGANIZATION MEMBERS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = organization_id
+-- Returns: Number of members, owners included
+-- Usage: Organization page
+-- name: CountOrganizationMembers :one
+SELECT COUNT(*) FROM organization_member
+WHERE organization_id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 8. COUNT ORGANIZATION OWNERS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = organization_id
+-- Returns: Number of owners
+-- Usage: Keep the last owner from leaving, being removed or demoted
+-- name: CountOrganizationOwners :one
+SELECT COUNT(*) FROM organization_member
+WHERE organization_id = $1 AND role = 'owner';
+
+
+-- ----------------------------------------------------------------------------
+-- 9. LIST ORGANIZATION MEMBERS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = organization_id
+-- Returns: Members with their usernames, owners and admins first
+-- Usage: Organization member list
+-- name: ListOrganizationMembers :many
+SELECT m.user_id, u.username, m.role, m.created_at
+FROM organization_member m
+JOIN "user" u ON u.id = m.user_id
+WHERE m.organization_id = $1
+ORDER BY CASE m.role WHEN 'owner' THEN 0 WHEN 'admin' THEN 1 ELSE 2 END, u.username;
+
+
+-- ----------------------------------------------------------------------------
+-- 10. ADD ORGANIZATION MEMBER
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = organization_id, $2 = user_id, $3 = role
+-- Returns: The membership, or no rows if the user already is a member
+-- Usage: Owners and admins add a user to the organization
+-- name: AddOrganizationMember :one
+INSERT INTO organization_member (organization_id, user_id, role)
+VALUES ($1, $2, $3)
+ON CONFLICT (organization_id, user_id) DO NOTHING
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 11. UPDATE ORGANIZATION MEMBER ROLE
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = organization_id, $2 = user_id, $3 = role
+-- Returns: The updated membership
+-- Usage: Owners change a member's role
+-- name: UpdateOrganizationMemberRole :one
+UPDATE organization_member
+SET role = $3
+WHERE organization_id = $1 AND user_id = $2
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 12. REMOVE ORGANIZATION MEMBER
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = organization_id, $2 = user_id
+-- Returns: Number of memberships removed (0 or 1)
+-- Usage: A member leaves, or is removed by someone who outranks them. Brews
+--        they logged stay with the organization.
+-- name: RemoveOrganizationMember :execrows
+DELETE FROM organization_member
+WHERE organization_id = $1 AND user_id = $2;
+
+
+-- ----------------------------------------------------------------------------
+-- 13. LIST ORGANIZATION BREWS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = organization_id, $2 = limit, $3 = offset
+-- Returns: Brews logged in the organization, newest first
+-- Usage: The organization's brew log
+-- Performance: Uses idx_brew_organization
+-- name: ListOrganizationBrews :many
+SELECT * FROM brew
+WHERE organization_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2 OFFSET $3;
+
+
+-- ----------------------------------------------------------------------------
+-- 14. GET ORGANIZATION BREW
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id, $2 = organization_id
+-- Returns: The brew, or no rows if it wasn't logged in the organization
+-- Usage: View a brew in the organization's brew log
+-- name: GetOrganizationBrew :one
+SELECT * FROM brew
+WHERE id = $1 AND organization_id = $2;
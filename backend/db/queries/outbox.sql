@@ -0,0 +1,112 @@
+-- ============================================================================
+-- OUTBOX QUERIES
+-- ============================================================================
+-- Operations for the notification and webhook outbox and its dispatcher.
+-- Messages are written by triggers (see schema/triggers.sql), not by
+-- queries.
+
+
+-- ----------------------------------------------------------------------------
+-- 1. CLAIM OUTBOX MESSAGES
+-- ----------------------------------------------------------------------------
+-- Parameters: lease_seconds, row_limit
+-- Returns: The oldest due messages, counted as attempted and leased so other
+--          instances skip them until dispatched or the lease runs out
+-- Usage: Outbox dispatcher; SKIP LOCKED lets several instances poll safely
+-- Performance: Uses idx_outbox_message_due
+-- name: ClaimOutboxMessages :many
+UPDATE outbox_message m
+SET
+    attempts = m.attempts + 1,
+    next_attempt_at = NOW() + sqlc.arg(lease_seconds)::int * INTERVAL '1 second'
+WHERE m.id IN (
+    SELECT om.id FROM outbox_message om
+    WHERE om.next_attempt_at <= NOW()
+        AND om.dispatched_at IS NULL
+        AND om.failed_at IS NULL
+    ORDER BY om.id
+    LIMIT sqlc.arg(row_limit)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING m.id, m.kind, m.payload, m.attempts;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. DISPATCH NOTIFICATION
+-- ----------------------------------------------------------------------------
+-- Parameters: message_id, id (the notification's ID)
+-- Returns: None
+-- Usage: Outbox dispatcher; creates the notification a message describes and
+--        marks the message dispatched in one statement, so a notification
+--        is created once however often the message is retried
+-- name: DispatchNotification :exec
+WITH message AS (
+    UPDATE outbox_message
+    SET dispatched_at = NOW(), last_error = NULL
+    WHERE id = sqlc.arg(message_id)
+        AND dispatched_at IS NULL
+        AND failed_at IS NULL
+    RETURNING payload
+)
+INSERT INTO notification (id, recipient_user_id, actor_user_id, type, reference_id, reference_type)
+SELECT
+    sqlc.arg(id),
+    payload->>'recipient_user_id',
+    payload->>'actor_user_id',
+    payload->>'type',
+    payload->>'reference_id',
+    payload->>'reference_type'
+FROM message;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. DISPATCH WEBHOOK
+-- ----------------------------------------------------------------------------
+-- Parameters: message_id, delivery_ids, webhook_ids (pairs: one delivery ID
+--             per subscribed webhook)
+-- Returns: None
+-- Usage: Outbox dispatcher; queues a webhook event's deliveries and marks
+--        the message dispatched in one statement. With no webhooks the
+--        message is just marked dispatched.
+-- name: DispatchWebhook :exec
+WITH message AS (
+    UPDATE outbox_message
+    SET dispatched_at = NOW(), last_error = NULL
+    WHERE id = sqlc.arg(message_id)
+        AND dispatched_at IS NULL
+        AND failed_at IS NULL
+    RETURNING payload
+)
+INSERT INTO automation_delivery (id, webhook_id, event, payload)
+SELECT d.id, d.webhook_id, message.payload->>'event', message.payload - 'user_id'
+FROM message,
+    unnest(sqlc.arg(delivery_ids)::text[], sqlc.arg(webhook_ids)::text[]) AS d(id, webhook_id);
+
+
+-- ----------------------------------------------------------------------------
+-- 4. RETRY OUTBOX MESSAGE
+-- ----------------------------------------------------------------------------
+-- Parameters: error, retry_in_seconds (NULL to stop retrying), id
+-- Returns: None
+-- Usage: Outbox dispatcher; reschedules a message that couldn't be
+--        dispatched, or marks it failed when out of retries
+-- name: RetryOutboxMessage :exec
+UPDATE outbox_message
+SET
+    last_error = sqlc.arg(error),
+    failed_at = CASE WHEN sqlc.narg(retry_in_seconds)::int IS NULL THEN NOW() END,
+    next_attempt_at = NOW() + COALESCE(sqlc.narg(retry_in_seconds)::int, 0) * INTERVAL '1 second'
+WHERE id = sqlc.arg(id);
+
+
+-- ----------------------------------------------------------------------------
+-- 5. DELETE DISPATCHED OUTBOX MESSAGES
+-- ----------------------------------------------------------------------------
+-- Parameters: retention_days
+-- Returns: Number of messages deleted
+-- Usage: Outbox dispatcher trims messages dispatched longer ago than the
+--        retention period; failed messages are kept for inspection
+-- Performance: Uses idx_outbox_message_dispatched
+-- name: DeleteDispatchedOutboxMessages :execrows
+DELETE FROM outbox_message
+WHERE dispatched_at < NOW() - sqlc.arg(retention_days)::int * INTERVAL '1 day';
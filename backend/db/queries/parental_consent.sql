@@ -0,0 +1,64 @@
+-- ============================================================================
+-- PARENTAL CONSENT QUERIES
+-- ============================================================================
+-- Operations for the consent requests sent to the parents of users who
+-- signed up under the minimum age. Requests are created with the user (see
+-- CreateUser).
+
+
+-- ----------------------------------------------------------------------------
+-- 1. GET PARENTAL CONSENT
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = token_hash
+-- Returns: The username of the user awaiting consent and when the request
+--          expires; no rows if the request is unknown, expired or granted
+-- Usage: Showing a parent what they are consenting to
+-- name: GetParentalConsent :one
+SELECT u.username, pc.expires_at
+FROM parental_consent pc
+JOIN "user" u ON u.id = pc.user_id
+WHERE pc.token_hash = $1
+  AND pc.granted_at IS NULL
+  AND pc.expires_at > NOW();
+
+
+-- ----------------------------------------------------------------------------
+-- 2. GRANT PARENTAL CONSENT
+-- ----------------------------------------------------------------------------
+-- Parameters: token_hash, granted_ip
+-- Returns: The user the consent was for; no rows if the request is unknown,
+--          expired or already granted
+-- Usage: A parent consents, unblocking their child's account in the same
+--        statement
+-- name: GrantParentalConsent :one
+WITH granted AS (
+    UPDATE parental_consent
+    SET granted_at = NOW(), granted_ip = sqlc.narg(granted_ip)
+    WHERE token_hash = sqlc.arg(token_hash)
+      AND granted_at IS NULL
+      AND expires_at > NOW()
+    RETURNING user_id
+)
+UPDATE "user" u
+SET consent_pending = FALSE
+FROM granted
+WHERE u.id = granted.user_id
+RETURNING u.id, u.username;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. RENEW PARENTAL CONSENT
+-- ----------------------------------------------------------------------------
+-- Parameters: user_id, token_hash, ttl_seconds
+-- Returns: The parent's email; no rows if the user's request is still
+--          pending or was granted
+-- Usage: A user awaiting consent logs in after their request expired, so
+--        their parent is asked again with a new token
+-- name: RenewParentalConsent :one
+UPDATE parental_consent
+SET token_hash = sqlc.arg(token_hash),
+    expires_at = NOW() + sqlc.arg(ttl_seconds)::int * INTERVAL '1 second'
+WHERE user_id = sqlc.arg(user_id)
+  AND granted_at IS NULL
+  AND expires_at <= NOW()
+RETURNING parent_email;
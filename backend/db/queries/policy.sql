@@ -0,0 +1,89 @@
+-- ============================================================================
+-- POLICY QUERIES
+-- ============================================================================
+-- Operations for the terms of service and privacy policy: publishing
+-- versions, and recording which versions each user accepted
+
+
+-- ----------------------------------------------------------------------------
+-- 1. CREATE POLICY DOCUMENT
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id, $2 = kind, $3 = version, $4 = url, $5 = summary,
+--             $6 = published_at (NULL publishes it now)
+-- Returns: The created document
+-- Usage: Admin publishes a new version of a policy
+-- name: CreatePolicyDocument :one
+INSERT INTO policy_document (id, kind, version, url, summary, published_at)
+VALUES ($1, $2, $3, $4, $5, COALESCE($6::timestamptz, NOW()))
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. LIST POLICY DOCUMENTS
+-- ----------------------------------------------------------------------------
+-- Parameters: None
+-- Returns: Every version of every policy, including scheduled ones, with
+--          how many users accepted it, latest first
+-- Usage: Admin reviews published versions
+-- Performance: Uses idx_policy_acceptance_document
+-- name: ListPolicyDocuments :many
+SELECT d.id, d.kind, d.version, d.url, d.summary, d.published_at, d.created_at,
+       (SELECT COUNT(*) FROM policy_acceptance pa WHERE pa.document_id = d.id) AS acceptance_count
+FROM policy_document d
+ORDER BY d.published_at DESC, d.kind;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. LIST CURRENT POLICY DOCUMENTS
+-- ----------------------------------------------------------------------------
+-- Parameters: None
+-- Returns: The current version of each policy
+-- Usage: Showing the policies a new user accepts by registering
+-- Performance: Uses idx_policy_document_kind
+-- name: ListCurrentPolicyDocuments :many
+SELECT DISTINCT ON (kind) *
+FROM policy_document
+WHERE published_at <= NOW()
+ORDER BY kind, published_at DESC;
+
+
+-- ----------------------------------------------------------------------------
+-- 4. LIST USER POLICY DOCUMENTS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id
+-- Returns: The current version of each policy, with when the user accepted
+--          it (NULL if they haven't)
+-- Usage: Finding the policies a user must accept before using the API
+-- Performance: Uses idx_policy_document_kind and the acceptance primary key
+-- name: ListUserPolicyDocuments :many
+WITH current_document AS (
+    SELECT DISTINCT ON (kind) *
+    FROM policy_document
+    WHERE published_at <= NOW()
+    ORDER BY kind, published_at DESC
+)
+SELECT d.id, d.kind, d.version, d.url, d.summary, d.published_at, pa.accepted_at
+FROM current_document d
+LEFT JOIN policy_acceptance pa ON pa.document_id = d.id AND pa.user_id = $1
+ORDER BY d.kind;
+
+
+-- ----------------------------------------------------------------------------
+-- 5. ACCEPT POLICY DOCUMENTS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id, $2 = ip, $3 = document_ids
+-- Returns: Number of acceptances recorded. Only current versions are
+--          accepted, and ones already accepted keep their original record.
+-- Usage: Recording acceptance at registration, or of a new version
+-- name: AcceptPolicyDocuments :execrows
+WITH current_document AS (
+    SELECT DISTINCT ON (kind) id
+    FROM policy_document
+    WHERE published_at <= NOW()
+    ORDER BY kind, published_at DESC
+)
+INSERT INTO policy_acceptance (user_id, document_id, ip)
+SELECT $1, id, $2
+FROM current_document
+WHERE id = ANY($3::text[])
+ON CONFLICT (user_id, document_id) DO NOTHING;
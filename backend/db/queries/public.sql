@@ -0,0 +1,222 @@
+-- ============================================================================
+-- PUBLIC CONTENT QUERIES
+-- ============================================================================
+-- Read-only lookups of public recipes and brews for unauthenticated
+-- consumers: the public JSON representation and oEmbed previews
+
+
+-- ----------------------------------------------------------------------------
+-- 1. GET PUBLIC RECIPE
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = recipe_id
+-- Returns: A public recipe with its current revision's parameters and its
+--          owner's username; no rows for private recipes
+-- Usage: Public recipe JSON and oEmbed
+-- name: GetPublicRecipe :one
+SELECT
+    r.id,
+    r.name,
+    r.brew_method,
+    r.current_revision,
+    rr.dose_grams,
+    rr.water_grams,
+    rr.water_temp_c,
+    rr.grind_setting,
+    rr.brew_time_seconds,
+    rr.instructions,
+    u.username,
+    r.created_at,
+    r.updated_at
+FROM recipe r
+JOIN recipe_revision rr ON rr.recipe_id = r.id AND rr.revision = r.current_revision
+JOIN "user" u ON u.id = r.owner_id
+WHERE r.id = $1 AND r.is_public;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. GET PUBLIC BREW
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = brew_id
+-- Returns: A public brew with its brewer's username; no rows for private
+--          brews
+-- Usage: Public brew JSON and oEmbed
+-- name: GetPublicBrew :one
+SELECT
+    b.id,
+    b.name,
+    b.brew_method,
+    b.bean_origin,
+    b.roaster,
+    b.notes,
+    b.dose_grams,
+    b.water_grams,
+    b.water_temp_c,
+    b.grind_setting,
+    b.brew_time_seconds,
+    u.username,
+    b.created_at
+FROM brew b
+JOIN "user" u ON u.id = b.created_by
+WHERE b.id = $1 AND b.is_public;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. LIST PUBLIC RECIPES
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = limit, $2 = offset
+-- Returns: Public recipes with their owner's username, oldest first, so
+--          pages stay stable as recipes are added
+-- Usage: Public recipe index and recipe sitemaps
+-- Performance: Uses idx_recipe_is_public
+-- name: ListPublicRecipes :many
+SELECT
+    r.id,
+    r.name,
+    r.brew_method,
+    u.username,
+    r.updated_at
+FROM recipe r
+JOIN "user" u ON u.id = r.owner_id
+WHERE r.is_public
+ORDER BY r.id
+LIMIT $1 OFFSET $2;
+
+
+-- ----------------------------------------------------------------------------
+-- 4. COUNT PUBLIC RECIPES
+-- ----------------------------------------------------------------------------
+-- Parameters: None
+-- Returns: Number of public recipes
+-- Usage: Sizing the recipe sitemaps
+-- name: CountPublicRecipes :one
+SELECT COUNT(*) FROM recipe
+WHERE is_public;
+
+
+-- ----------------------------------------------------------------------------
+-- 5. LIST PUBLIC PROFILES
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = limit, $2 = offset
+-- Returns: Users with at least one public recipe or brew, oldest first
+-- Usage: Public profile index and profile sitemaps; users with nothing
+--        public are left out as thin pages
+-- name: ListPublicProfiles :many
+SELECT
+    u.id,
+    u.username,
+    u.bio,
+    u.profile_picture_url,
+    u.updated_at
+FROM "user" u
+WHERE EXISTS (SELECT 1 FROM recipe r WHERE r.owner_id = u.id AND r.is_public)
+    OR EXISTS (SELECT 1 FROM brew b WHERE b.created_by = u.id AND b.is_public)
+ORDER BY u.id
+LIMIT $1 OFFSET $2;
+
+
+-- ----------------------------------------------------------------------------
+-- 6. COUNT PUBLIC PROFILES
+-- ----------------------------------------------------------------------------
+-- Parameters: None
+-- Returns: Number of users listed by ListPublicProfiles
+-- Usage: Sizing the profile sitemaps
+-- name: CountPublicProfiles :one
+SELECT COUNT(*) FROM "user" u
+WHERE EXISTS (SELECT 1 FROM recipe r WHERE r.owner_id = u.id AND r.is_public)
+    OR EXISTS (SELECT 1 FROM brew b WHERE b.created_by = u.id AND b.is_public);
+
+
+-- ----------------------------------------------------------------------------
+-- 7. GET PUBLIC PROFILE
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = username (case-insensitive)
+-- Returns: A user's public profile fields with counts of their public
+--          recipes and brews
+-- Usage: Public profile JSON
+-- Performance: Uses idx_user_username_lower
+-- name: GetPublicProfile :one
+SELECT
+    u.id,
+    u.username,
+    u.bio,
+    u.location,
+    u.profile_picture_url,
+    u.joined_at,
+    (SELECT COUNT(*) FROM recipe r WHERE r.owner_id = u.id AND r.is_public) AS recipe_count,
+    (SELECT COUNT(*) FROM brew b WHERE b.created_by = u.id AND b.is_public) AS brew_count
+FROM "user" u
+WHERE LOWER(u.username) = LOWER($1);
+
+
+-- ----------------------------------------------------------------------------
+-- 8. LIST PUBLIC USER BREWS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id, $2 = limit
+-- Returns: The user's public brews, newest first
+-- Usage: Profile Atom feed
+-- Performance: Uses idx_brew_created_by
+-- name: ListPublicUserBrews :many
+SELECT
+    id,
+    name,
+    brew_method,
+    bean_origin,
+    roaster,
+    notes,
+    dose_grams,
+    water_grams,
+    water_temp_c,
+    brew_time_seconds,
+    created_at,
+    updated_at
+FROM brew
+WHERE created_by = $1 AND is_public
+ORDER BY created_at DESC, id DESC
+LIMIT $2;
+
+
+-- ----------------------------------------------------------------------------
+-- 9. GET PUBLIC CLUB
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = club_id
+-- Returns: A public club; no rows for private clubs
+-- Usage: Club Atom feed
+-- name: GetPublicClub :one
+SELECT
+    id,
+    name,
+    description,
+    updated_at
+FROM club
+WHERE id = $1 AND NOT is_private;
+
+
+-- ----------------------------------------------------------------------------
+-- 10. LIST PUBLIC CLUB FEED
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = club_id, $2 = limit
+-- Returns: Shares of public brews and recipes, newest first, with sharer
+--          usernames and brew or recipe summaries; shares of private
+--          items stay visible to members only
+-- Usage: Club Atom feed
+-- Performance: Uses idx_club_share_feed
+-- name: ListPublicClubFeed :many
+SELECT
+    cs.id,
+    u.username,
+    cs.brew_id,
+    b.name AS brew_name,
+    b.brew_method,
+    b.bean_origin,
+    cs.recipe_id,
+    r.name AS recipe_name,
+    r.brew_method AS recipe_brew_method,
+    cs.note,
+    cs.created_at
+FROM club_share cs
+JOIN "user" u ON u.id = cs.user_id
+LEFT JOIN brew b ON b.id = cs.brew_id
+LEFT JOIN recipe r ON r.id = cs.recipe_id
+WHERE cs.club_id = $1 AND (b.is_public OR r.is_public)
+ORDER BY cs.created_at DESC, cs.id DESC
+LIMIT $2;
@@ -0,0 +1,96 @@
+-- ============================================================================
+-- REALTIME QUERIES
+-- ============================================================================
+-- Relaying realtime messages between instances, and tracking who is
+-- connected to each topic across them
+
+
+-- ----------------------------------------------------------------------------
+-- 1. NOTIFY REALTIME
+-- ----------------------------------------------------------------------------
+-- Parameters: payload
+-- Returns: Nothing
+-- Usage: Relaying a realtime message to the other instances, which LISTEN
+--        on brewd_realtime. Payloads must be shorter than 8000 bytes.
+-- name: NotifyRealtime :exec
+SELECT pg_notify('brewd_realtime', sqlc.arg(payload)::text);
+
+
+-- ----------------------------------------------------------------------------
+-- 2. JOIN REALTIME PRESENCE
+-- ----------------------------------------------------------------------------
+-- Parameters: connection_id, topic, user_id, instance_id
+-- Returns: Nothing
+-- Usage: A stream on a topic opening on this instance
+-- name: JoinRealtimePresence :exec
+INSERT INTO realtime_presence (connection_id, topic, user_id, instance_id)
+VALUES (sqlc.arg(connection_id), sqlc.arg(topic), sqlc.arg(user_id), sqlc.arg(instance_id));
+
+
+-- ----------------------------------------------------------------------------
+-- 3. LEAVE REALTIME PRESENCE
+-- ----------------------------------------------------------------------------
+-- Parameters: connection_id
+-- Returns: Nothing
+-- Usage: A stream closing, whatever others its user has open
+-- Performance: Uses the primary key
+-- name: LeaveRealtimePresence :exec
+DELETE FROM realtime_presence
+WHERE connection_id = sqlc.arg(connection_id);
+
+
+-- ----------------------------------------------------------------------------
+-- 4. TOUCH REALTIME PRESENCE
+-- ----------------------------------------------------------------------------
+-- Parameters: instance_id
+-- Returns: Nothing
+-- Usage: Presence heartbeat, keeping this instance's rows fresh
+-- Performance: Uses idx_realtime_presence_instance_id
+-- name: TouchRealtimePresence :exec
+UPDATE realtime_presence
+SET seen_at = NOW()
+WHERE instance_id = sqlc.arg(instance_id);
+
+
+-- ----------------------------------------------------------------------------
+-- 5. DELETE INSTANCE REALTIME PRESENCE
+-- ----------------------------------------------------------------------------
+-- Parameters: instance_id
+-- Returns: Nothing
+-- Usage: An instance shutting down
+-- Performance: Uses idx_realtime_presence_instance_id
+-- name: DeleteInstanceRealtimePresence :exec
+DELETE FROM realtime_presence
+WHERE instance_id = sqlc.arg(instance_id);
+
+
+-- ----------------------------------------------------------------------------
+-- 6. PURGE STALE REALTIME PRESENCE
+-- ----------------------------------------------------------------------------
+-- Parameters: stale_seconds
+-- Returns: The topic of each row deleted
+-- Usage: Presence heartbeat, dropping the rows of instances that stopped
+--        refreshing them, so their topics' presence can be broadcast
+-- Performance: Uses idx_realtime_presence_seen_at
+-- name: PurgeStaleRealtimePresence :many
+DELETE FROM realtime_presence
+WHERE seen_at < NOW() - sqlc.arg(stale_seconds)::int * INTERVAL '1 second'
+RETURNING topic;
+
+
+-- ----------------------------------------------------------------------------
+-- 7. LIST REALTIME PRESENCE
+-- ----------------------------------------------------------------------------
+-- Parameters: topic, stale_seconds
+-- Returns: The users connected to topic on any instance, with usernames, in
+--          username order
+-- Usage: A topic's presence, sent when a stream opens and broadcast when it
+--        changes
+-- Performance: Uses idx_realtime_presence_topic
+-- name: ListRealtimePresence :many
+SELECT DISTINCT p.user_id, u.username
+FROM realtime_presence p
+JOIN "user" u ON u.id = p.user_id
+WHERE p.topic = sqlc.arg(topic)
+  AND p.seen_at >= NOW() - sqlc.arg(stale_seconds)::int * INTERVAL '1 second'
+ORDER BY u.username;
@@ -0,0 +1,335 @@
+-- ============================================================================
+-- RECIPE QUERIES
+-- ============================================================================
+-- Operations for versioned recipes: create, revise, history, and rollback
+
+
+-- ----------------------------------------------------------------------------
+-- 1. CREATE RECIPE
+-- ----------------------------------------------------------------------------
+-- Parameters: id (ULID), owner_id, name, brew_method, is_public, plus the
+--             parameters of revision 1 (grams / Celsius) and its changelog
+-- Returns: The created recipe record
+-- Usage: User creates a recipe; the recipe and its first revision are
+--        inserted in a single statement
+-- name: CreateRecipe :one
+WITH new_recipe AS (
+    INSERT INTO recipe (id, owner_id, name, brew_method, is_public)
+    VALUES (sqlc.arg(id), sqlc.arg(owner_id), sqlc.arg(name), sqlc.arg(brew_method), sqlc.arg(is_public))
+    RETURNING *
+), first_revision AS (
+    INSERT INTO recipe_revision (
+        recipe_id, revision, dose_grams, water_grams, water_temp_c,
+        grind_setting, brew_time_seconds, instructions, changelog, created_by
+    )
+    SELECT
+        id, 1, sqlc.narg(dose_grams), sqlc.narg(water_grams), sqlc.narg(water_temp_c),
+        sqlc.narg(grind_setting), sqlc.narg(brew_time_seconds), sqlc.narg(instructions),
+        sqlc.narg(changelog), owner_id
+    FROM new_recipe
+)
+SELECT * FROM new_recipe;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. GET RECIPE BY ID
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = recipe_id
+-- Returns: Single recipe record
+-- Usage: View a recipe; callers must check is_public / owner_id for access
+-- name: GetRecipeByID :one
+SELECT * FROM recipe
+WHERE id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. GET RECIPE REVISION
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = recipe_id, $2 = revision
+-- Returns: Single revision snapshot
+-- Usage: View a past version, resolve the revision a brew is pinned to
+-- name: GetRecipeRevision :one
+SELECT * FROM recipe_revision
+WHERE recipe_id = $1 AND revision = $2;
+
+
+-- ----------------------------------------------------------------------------
+-- 4. LIST RECIPE REVISIONS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = recipe_id
+-- Returns: Every revision of the recipe, newest first
+-- Usage: Recipe history / changelog (diffs are computed between neighbours)
+-- name: ListRecipeRevisions :many
+SELECT * FROM recipe_revision
+WHERE recipe_id = $1
+ORDER BY revision DESC;
+
+
+-- ----------------------------------------------------------------------------
+-- 5. CREATE RECIPE REVISION
+-- ----------------------------------------------------------------------------
+-- Parameters: recipe_id, the new parameters (grams / Celsius), changelog,
+--             rolled_back_from (NULL unless restoring a revision), created_by
+-- Returns: The new revision
+-- Usage: Edit or roll back a recipe. Bumping current_revision locks the
+--        recipe row, so concurrent edits get consecutive revision numbers.
+-- name: CreateRecipeRevision :one
+WITH bumped AS (
+    UPDATE recipe
+    SET current_revision = current_revision + 1
+    WHERE id = sqlc.arg(recipe_id)
+    RETURNING id, current_revision
+)
+INSERT INTO recipe_revision (
+    recipe_id, revision, dose_grams, water_grams, water_temp_c, grind_setting,
+    brew_time_seconds, instructions, changelog, rolled_back_from, created_by
+)
+SELECT
+    id, current_revision, sqlc.narg(dose_grams), sqlc.narg(water_grams),
+    sqlc.narg(water_temp_c), sqlc.narg(grind_setting), sqlc.narg(brew_time_seconds),
+    sqlc.narg(instructions), sqlc.narg(changelog), sqlc.narg(rolled_back_from),
+    sqlc.narg(created_by)
+FROM bumped
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 6. FORK RECIPE
+-- ----------------------------------------------------------------------------
+-- Parameters: id (ULID), owner_id, name, is_public, source_id, source_revision
+-- Returns: The new recipe, or no rows if the source revision doesn't exist
+-- Usage: Clone a recipe; the fork starts at revision 1 with the source
+--        revision's parameters and links back to it for attribution
+-- name: ForkRecipe :one
+WITH source AS (
+    SELECT
+        rr.recipe_id, rr.revision, rr.dose_grams, rr.water_grams, rr.water_temp_c,
+        rr.grind_setting, rr.brew_time_seconds, rr.instructions, r.brew_method
+    FROM recipe_revision rr
+    JOIN recipe r ON r.id = rr.recipe_id
+    WHERE rr.recipe_id = sqlc.arg(source_id) AND rr.revision = sqlc.arg(source_revision)
+), new_recipe AS (
+    INSERT INTO recipe (id, owner_id, name, brew_method, is_public, forked_from_id, forked_from_revision)
+    SELECT sqlc.arg(id), sqlc.arg(owner_id), sqlc.arg(name), brew_method, sqlc.arg(is_public), recipe_id, revision
+    FROM source
+    RETURNING *
+), first_revision AS (
+    INSERT INTO recipe_revision (
+        recipe_id, revision, dose_grams, water_grams, water_temp_c,
+        grind_setting, brew_time_seconds, instructions, created_by
+    )
+    SELECT
+        n.id, 1, s.dose_grams, s.water_grams, s.water_temp_c,
+        s.grind_setting, s.brew_time_seconds, s.instructions, n.owner_id
+    FROM new_recipe n, source s
+)
+SELECT * FROM new_recipe;
+
+
+-- ----------------------------------------------------------------------------
+-- 7. GET RECIPE FORK TREE
+-- ----------------------------------------------------------------------------
+-- Parameters: recipe_id (tree root), viewer_id
+-- Returns: The recipe and every fork below it, with depth, owner, and how
+--          many brews were made with each; private forks of other users
+--          (and everything below them) are skipped
+-- Usage: Browse a recipe's fork tree, find the most brewed fork
+-- Performance: Uses idx_recipe_forked_from and idx_brew_recipe
+-- name: GetRecipeForkTree :many
+WITH RECURSIVE tree AS (
+    SELECT r.id, 0 AS depth
+    FROM recipe r
+    WHERE r.id = sqlc.arg(recipe_id)
+
+    UNION ALL
+
+    SELECT r.id, t.depth + 1
+    FROM recipe r
+    JOIN tree t ON r.forked_from_id = t.id
+    WHERE r.is_public
+        OR r.owner_id = sqlc.arg(viewer_id)
+        OR EXISTS (
+            SELECT 1 FROM recipe_collaborator rc
+            WHERE rc.recipe_id = r.id
+                AND rc.user_id = sqlc.arg(viewer_id)
+                AND rc.accepted_at IS NOT NULL
+        )
+)
+SELECT
+    r.id,
+    r.name,
+    r.owner_id,
+    u.username AS owner_username,
+    r.forked_from_id,
+    r.forked_from_revision,
+    r.current_revision,
+    t.depth,
+    (SELECT COUNT(*) FROM brew b WHERE b.recipe_id = r.id) AS brew_count,
+    r.created_at
+FROM tree t
+JOIN recipe r ON r.id = t.id
+JOIN "user" u ON u.id = r.owner_id
+ORDER BY t.depth, r.created_at;
+
+
+-- ----------------------------------------------------------------------------
+-- 8. GET RECIPE LINEAGE
+-- ----------------------------------------------------------------------------
+-- Parameters: recipe_id, viewer_id
+-- Returns: The recipes this one descends from, nearest first, and whether
+--          the viewer may see each one
+-- Usage: Attribution ("forked from X, originally by Y")
+-- name: GetRecipeLineage :many
+WITH RECURSIVE lineage AS (
+    SELECT r.forked_from_id AS id, r.forked_from_revision AS revision, 1 AS depth
+    FROM recipe r
+    WHERE r.id = sqlc.arg(recipe_id) AND r.forked_from_id IS NOT NULL
+
+    UNION ALL
+
+    SELECT r.forked_from_id, r.forked_from_revision, l.depth + 1
+    FROM recipe r
+    JOIN lineage l ON r.id = l.id
+    WHERE r.forked_from_id IS NOT NULL
+)
+SELECT
+    r.id,
+    r.name,
+    r.owner_id,
+    u.username AS owner_username,
+    (
+        r.is_public
+        OR r.owner_id = sqlc.arg(viewer_id)
+        OR EXISTS (
+            SELECT 1 FROM recipe_collaborator rc
+            WHERE rc.recipe_id = r.id
+                AND rc.user_id = sqlc.arg(viewer_id)
+                AND rc.accepted_at IS NOT NULL
+        )
+    )::boolean AS visible,
+    l.revision AS forked_revision,
+    l.depth
+FROM lineage l
+JOIN recipe r ON r.id = l.id
+JOIN "user" u ON u.id = r.owner_id
+ORDER BY l.depth;
+
+
+-- ----------------------------------------------------------------------------
+-- 9. INVITE RECIPE COLLABORATOR
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = recipe_id, $2 = user_id, $3 = role, $4 = invited_by
+-- Returns: The invitation; re-inviting an existing collaborator changes
+--          their role and keeps their acceptance
+-- Usage: Recipe owner invites a co-author ('editor') or reader ('viewer')
+-- name: InviteRecipeCollaborator :one
+INSERT INTO recipe_collaborator (recipe_id, user_id, role, invited_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (recipe_id, user_id) DO UPDATE SET role = EXCLUDED.role
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 10. ACCEPT RECIPE INVITATION
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = recipe_id, $2 = user_id
+-- Returns: The accepted collaborator record, or no rows if not pending
+-- Usage: Invitee accepts a collaboration invite
+-- name: AcceptRecipeInvitation :one
+UPDATE recipe_collaborator
+SET accepted_at = NOW()
+WHERE recipe_id = $1 AND user_id = $2 AND accepted_at IS NULL
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 11. UPDATE RECIPE COLLABORATOR ROLE
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = recipe_id, $2 = user_id, $3 = role
+-- Returns: The updated collaborator record
+-- Usage: Owner promotes a viewer to editor or vice versa
+-- name: UpdateRecipeCollaboratorRole :one
+UPDATE recipe_collaborator
+SET role = $3
+WHERE recipe_id = $1 AND user_id = $2
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 12. REMOVE RECIPE COLLABORATOR
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = recipe_id, $2 = user_id
+-- Returns: Number of rows removed
+-- Usage: Owner removes a collaborator, or a collaborator leaves / declines
+-- name: RemoveRecipeCollaborator :execrows
+DELETE FROM recipe_collaborator
+WHERE recipe_id = $1 AND user_id = $2;
+
+
+-- ----------------------------------------------------------------------------
+-- 13. LIST RECIPE COLLABORATORS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = recipe_id
+-- Returns: Collaborators (pending and accepted) with usernames
+-- Usage: Recipe team management screen
+-- name: ListRecipeCollaborators :many
+SELECT
+    rc.user_id,
+    u.username,
+    rc.role,
+    rc.invited_by,
+    rc.accepted_at,
+    rc.created_at
+FROM recipe_collaborator rc
+JOIN "user" u ON u.id = rc.user_id
+WHERE rc.recipe_id = $1
+ORDER BY rc.created_at;
+
+
+-- ----------------------------------------------------------------------------
+-- 14. GET RECIPE COLLABORATOR ROLE
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = recipe_id, $2 = user_id
+-- Returns: The user's role, or no rows if they are not an accepted collaborator
+-- Usage: Access checks for viewing and editing recipes
+-- name: GetRecipeCollaboratorRole :one
+SELECT role FROM recipe_collaborator
+WHERE recipe_id = $1 AND user_id = $2 AND accepted_at IS NOT NULL;
+
+
+-- ----------------------------------------------------------------------------
+-- 15. LIST USER RECIPE INVITATIONS
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id
+-- Returns: Pending invitations with recipe name and inviter username
+-- Usage: Invitations inbox
+-- Performance: Uses idx_recipe_collaborator_user
+-- name: ListUserRecipeInvitations :many
+SELECT
+    rc.recipe_id,
+    r.name AS recipe_name,
+    rc.role,
+    rc.invited_by,
+    u.username AS invited_by_username,
+    rc.created_at
+FROM recipe_collaborator rc
+JOIN recipe r ON r.id = rc.recipe_id
+LEFT JOIN "user" u ON u.id = rc.invited_by
+WHERE rc.user_id = $1 AND rc.accepted_at IS NULL
+ORDER BY rc.created_at DESC;
+
+
+-- ----------------------------------------------------------------------------
+-- 16. UPDATE RECIPE
+-- ----------------------------------------------------------------------------
+-- Parameters: id, owner_id, name, brew_method, is_public
+-- Returns: The updated recipe; no rows if it isn't the owner's
+-- Usage: Owner renames a recipe or changes its method or visibility. These
+--        aren't versioned, so no revision is appended.
+-- name: UpdateRecipe :one
+UPDATE recipe
+SET name = sqlc.arg(name),
+    brew_method = sqlc.narg(brew_method),
+    is_public = sqlc.arg(is_public)
+WHERE id = sqlc.arg(id) AND owner_id = sqlc.arg(owner_id)
+RETURNING *;
@@ -0,0 +1,274 @@
+-- ============================================================================
+-- RECOMMENDATION QUERIES
+-- ============================================================================
+-- Operations for scoring per-user recipe and bean recommendations, reading
+-- them, and "not interested" feedback
+
+
+-- ----------------------------------------------------------------------------
+-- 1. CLAIM RECOMMENDATION USERS
+-- ----------------------------------------------------------------------------
+-- Parameters: stale_before, row_limit
+-- Returns: IDs of users with rated posts whose recommendations were never
+--          scored or scored before stale_before, least recently scored
+--          first; each is stamped as scored now so other workers skip it
+-- Usage: Recommendation worker
+-- Performance: Uses idx_post_owner_id and idx_recommendation_state_computed_at
+-- name: ClaimRecommendationUsers :many
+INSERT INTO recommendation_state (user_id, computed_at)
+SELECT u.id, NOW()
+FROM "user" u
+LEFT JOIN recommendation_state s ON s.user_id = u.id
+WHERE (s.computed_at IS NULL OR s.computed_at < sqlc.arg(stale_before))
+    AND EXISTS (
+        SELECT 1 FROM post p
+        WHERE p.owner_id = u.id AND p.rating IS NOT NULL
+    )
+ORDER BY s.computed_at NULLS FIRST, u.id
+LIMIT sqlc.arg(row_limit)
+ON CONFLICT (user_id) DO UPDATE SET computed_at = EXCLUDED.computed_at
+RETURNING user_id;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. REFRESH RECIPE RECOMMENDATIONS
+-- ----------------------------------------------------------------------------
+-- Parameters: user_id, row_limit
+-- Returns: Nothing; replaces the user's recipe recommendations
+-- Usage: Recommendation worker. The user's ratings of their own brews,
+--        centred on 2.5, give an affinity per brew method and per flavor
+--        wheel category noted on those brews. A public recipe the user
+--        neither owns, has brewed nor dismissed scores its method's
+--        affinity plus the category affinities weighted by their share of
+--        the descriptors on the recipe's public brews. Only positive scores
+--        are kept.
+-- name: RefreshRecipeRecommendations :exec
+WITH cleared AS (
+    DELETE FROM recommendation
+    WHERE user_id = sqlc.arg(user_id) AND kind = 'recipe'
+),
+rated AS (
+    SELECT b.id AS brew_id, b.brew_method, p.rating::float8 - 2.5 AS weight
+    FROM post p
+    JOIN brew b ON b.id = p.brew_id AND b.created_by = p.owner_id
+    WHERE p.owner_id = sqlc.arg(user_id) AND p.rating IS NOT NULL
+),
+method_affinity AS (
+    SELECT brew_method, AVG(weight) AS affinity
+    FROM rated
+    WHERE brew_method IS NOT NULL
+    GROUP BY brew_method
+),
+flavor_affinity AS (
+    SELECT split_part(bf.descriptor_id, '.', 1) AS category, AVG(r.weight) AS affinity
+    FROM rated r
+    JOIN brew_flavor bf ON bf.brew_id = r.brew_id
+    GROUP BY 1
+),
+candidate AS (
+    SELECT r.id, r.brew_method
+    FROM recipe r
+    WHERE r.is_public
+        AND r.owner_id <> sqlc.arg(user_id)
+        AND NOT EXISTS (
+            SELECT 1 FROM brew b
+            WHERE b.recipe_id = r.id AND b.created_by = sqlc.arg(user_id)
+        )
+        AND NOT EXISTS (
+            SELECT 1 FROM recommendation_dismissal d
+            WHERE d.user_id = sqlc.arg(user_id) AND d.kind = 'recipe' AND d.item_id = r.id
+        )
+),
+recipe_flavor AS (
+    SELECT
+        b.recipe_id,
+        split_part(bf.descriptor_id, '.', 1) AS category,
+        COUNT(*)::float8 / SUM(COUNT(*)) OVER (PARTITION BY b.recipe_id) AS share
+    FROM brew b
+    JOIN brew_flavor bf ON bf.brew_id = b.id
+    WHERE b.recipe_id IN (SELECT id FROM candidate) AND b.is_public IS NOT FALSE
+    GROUP BY b.recipe_id, 2
+),
+scored AS (
+    SELECT
+        c.id,
+        COALESCE(ma.affinity, 0) + COALESCE(SUM(rf.share * fa.affinity), 0) AS score
+    FROM candidate c
+    LEFT JOIN method_affinity ma ON ma.brew_method = c.brew_method
+    LEFT JOIN recipe_flavor rf ON rf.recipe_id = c.id
+    LEFT JOIN flavor_affinity fa ON fa.category = rf.category
+    GROUP BY c.id, ma.affinity
+)
+INSERT INTO recommendation (user_id, kind, item_id, score)
+SELECT sqlc.arg(user_id), 'recipe', id, score
+FROM scored
+WHERE score > 0
+ORDER BY score DESC, id
+LIMIT sqlc.arg(row_limit);
+
+
+-- ----------------------------------------------------------------------------
+-- 3. REFRESH BEAN RECOMMENDATIONS
+-- ----------------------------------------------------------------------------
+-- Parameters: user_id, row_limit
+-- Returns: Nothing; replaces the user's bean recommendations
+-- Usage: Recommendation worker. Ratings give affinities as for recipes, per
+--        origin country, process and variety of the bags rated brews used
+--        and per flavor category. A catalogue bean the user has no bag of
+--        (by name and roaster) and hasn't dismissed scores the affinities of
+--        its origin fields plus the category affinities weighted by their
+--        share of the descriptors on public brews of the same roaster and
+--        origin. Only positive scores are kept.
+-- name: RefreshBeanRecommendations :exec
+WITH cleared AS (
+    DELETE FROM recommendation
+    WHERE user_id = sqlc.arg(user_id) AND kind = 'bean'
+),
+rated AS (
+    SELECT
+        b.id AS brew_id,
+        bb.origin_country,
+        bb.process,
+        bb.variety,
+        p.rating::float8 - 2.5 AS weight
+    FROM post p
+    JOIN brew b ON b.id = p.brew_id AND b.created_by = p.owner_id
+    LEFT JOIN bean_bag bb ON bb.id = b.bean_bag_id
+    WHERE p.owner_id = sqlc.arg(user_id) AND p.rating IS NOT NULL
+),
+origin_affinity AS (
+    SELECT 'country' AS field, origin_country AS value, AVG(weight) AS affinity
+    FROM rated WHERE origin_country IS NOT NULL GROUP BY origin_country
+    UNION ALL
+    SELECT 'process', process, AVG(weight)
+    FROM rated WHERE process IS NOT NULL GROUP BY process
+    UNION ALL
+    SELECT 'variety', variety, AVG(weight)
+    FROM rated WHERE variety IS NOT NULL GROUP BY variety
+),
+flavor_affinity AS (
+    SELECT split_part(bf.descriptor_id, '.', 1) AS category, AVG(r.weight) AS affinity
+    FROM rated r
+    JOIN brew_flavor bf ON bf.brew_id = r.brew_id
+    GROUP BY 1
+),
+candidate AS (
+    SELECT bn.id, bn.roaster_name, bn.bean_origin, bn.origin_country, bn.process, bn.variety
+    FROM bean bn
+    WHERE NOT EXISTS (
+            SELECT 1 FROM bean_bag bb
+            WHERE bb.owner_id = sqlc.arg(user_id)
+                AND LOWER(bb.name) = LOWER(bn.name)
+                AND LOWER(COALESCE(bb.roaster, '')) = LOWER(COALESCE(bn.roaster_name, ''))
+        )
+        AND NOT EXISTS (
+            SELECT 1 FROM recommendation_dismissal d
+            WHERE d.user_id = sqlc.arg(user_id) AND d.kind = 'bean' AND d.item_id = bn.id
+        )
+),
+origin_score AS (
+    SELECT c.id, COALESCE(SUM(oa.affinity), 0) AS score
+    FROM candidate c
+    LEFT JOIN origin_affinity oa ON
+        (oa.field = 'country' AND oa.value = c.origin_country)
+        OR (oa.field = 'process' AND oa.value = c.process)
+        OR (oa.field = 'variety' AND oa.value = c.variety)
+    GROUP BY c.id
+),
+bean_flavor AS (
+    SELECT
+        c.id AS bean_id,
+        split_part(bf.descriptor_id, '.', 1) AS category,
+        COUNT(*)::float8 / SUM(COUNT(*)) OVER (PARTITION BY c.id) AS share
+    FROM candidate c
+    JOIN brew b ON LOWER(b.roaster) = LOWER(c.roaster_name)
+        AND LOWER(b.bean_origin) = LOWER(c.bean_origin)
+        AND b.is_public IS NOT FALSE
+    JOIN brew_flavor bf ON bf.brew_id = b.id
+    GROUP BY c.id, 2
+),
+flavor_score AS (
+    SELECT bf.bean_id, SUM(bf.share * fa.affinity) AS score
+    FROM bean_flavor bf
+    JOIN flavor_affinity fa ON fa.category = bf.category
+    GROUP BY bf.bean_id
+),
+scored AS (
+    SELECT os.id, os.score + COALESCE(fs.score, 0) AS score
+    FROM origin_score os
+    LEFT JOIN flavor_score fs ON fs.bean_id = os.id
+)
+INSERT INTO recommendation (user_id, kind, item_id, score)
+SELECT sqlc.arg(user_id), 'bean', id, score
+FROM scored
+WHERE score > 0
+ORDER BY score DESC, id
+LIMIT sqlc.arg(row_limit);
+
+
+-- ----------------------------------------------------------------------------
+-- 4. LIST RECIPE RECOMMENDATIONS
+-- ----------------------------------------------------------------------------
+-- Parameters: user_id, row_limit
+-- Returns: The user's recommended recipes, best first, with their owner's
+--          username and when they were scored. Recipes made private since
+--          are left out.
+-- Usage: Recommendations screen
+-- Performance: Uses idx_recommendation_user_score
+-- name: ListRecipeRecommendations :many
+SELECT
+    r.id,
+    r.name,
+    r.brew_method,
+    u.username AS owner_username,
+    rec.score,
+    rec.computed_at
+FROM recommendation rec
+JOIN recipe r ON r.id = rec.item_id
+JOIN "user" u ON u.id = r.owner_id
+WHERE rec.user_id = sqlc.arg(user_id) AND rec.kind = 'recipe' AND r.is_public
+ORDER BY rec.score DESC, r.id
+LIMIT sqlc.arg(row_limit);
+
+
+-- ----------------------------------------------------------------------------
+-- 5. LIST BEAN RECOMMENDATIONS
+-- ----------------------------------------------------------------------------
+-- Parameters: user_id, row_limit
+-- Returns: The user's recommended catalogue beans, best first, and when
+--          they were scored
+-- Usage: Recommendations screen
+-- Performance: Uses idx_recommendation_user_score
+-- name: ListBeanRecommendations :many
+SELECT
+    bn.id,
+    bn.name,
+    bn.roaster_name,
+    bn.bean_origin,
+    bn.origin_country,
+    bn.process,
+    bn.variety,
+    rec.score,
+    rec.computed_at
+FROM recommendation rec
+JOIN bean bn ON bn.id = rec.item_id
+WHERE rec.user_id = sqlc.arg(user_id) AND rec.kind = 'bean'
+ORDER BY rec.score DESC, bn.id
+LIMIT sqlc.arg(row_limit);
+
+
+-- ----------------------------------------------------------------------------
+-- 6. DISMISS RECOMMENDATION
+-- ----------------------------------------------------------------------------
+-- Parameters: user_id, kind, item_id
+-- Returns: Nothing; records the dismissal (repeats are ignored) and drops
+--          the item from the user's current recommendations
+-- Usage: User marks a recommendation "not interested"
+-- name: DismissRecommendation :exec
+WITH dropped AS (
+    DELETE FROM recommendation
+    WHERE user_id = sqlc.arg(user_id) AND kind = sqlc.arg(kind) AND item_id = sqlc.arg(item_id)
+)
+INSERT INTO recommendation_dismissal (user_id, kind, item_id)
+VALUES (sqlc.arg(user_id), sqlc.arg(kind), sqlc.arg(item_id))
+ON CONFLICT (user_id, kind, item_id) DO NOTHING;
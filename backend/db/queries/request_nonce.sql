@@ -0,0 +1,24 @@
+-- ============================================================================
+-- REQUEST NONCE QUERIES
+-- ============================================================================
+-- Operations for the nonces of signed inbound requests, which reject
+-- replays within the clock-skew tolerance
+
+
+-- ----------------------------------------------------------------------------
+-- 1. CLAIM REQUEST NONCE
+-- ----------------------------------------------------------------------------
+-- Parameters: source, nonce_hash, expires_at
+-- Returns: 1 if the nonce is new, 0 if the source already sent it and it
+--          hasn't expired
+-- Usage: Verifying an inbound request. Expired nonces are deleted in the
+--        same statement.
+-- Performance: Uses the primary key and idx_request_nonce_expires_at
+-- name: ClaimRequestNonce :execrows
+WITH expired AS (
+    DELETE FROM request_nonce
+    WHERE expires_at < NOW()
+)
+INSERT INTO request_nonce (source, nonce_hash, expires_at)
+VALUES (sqlc.arg(source), sqlc.arg(nonce_hash), sqlc.arg(expires_at))
+ON CONFLICT (source, nonce_hash) DO NOTHING;
@@ -0,0 +1,131 @@
+-- ============================================================================
+-- RESOURCE SHARE QUERIES
+-- ============================================================================
+-- Access control for brews and recipes: the shares owners grant to users
+-- and clubs, and the visibility checks that honour them. Handlers load
+-- items through the GetVisible* queries (and GetVisibleBrewsByIDs in
+-- brew.sql) rather than checking access themselves.
+
+
+-- ----------------------------------------------------------------------------
+-- 1. CREATE RESOURCE SHARE
+-- ----------------------------------------------------------------------------
+-- Parameters: id, brew_id or recipe_id, user_id or club_id, created_by
+-- Returns: The created share
+-- Usage: An owner shares a brew or recipe. Sharing an item with the same
+--        grantee twice violates idx_resource_share_item_grantee.
+-- name: CreateResourceShare :one
+INSERT INTO resource_share (id, brew_id, recipe_id, user_id, club_id, created_by)
+VALUES (
+    sqlc.arg(id), sqlc.narg(brew_id), sqlc.narg(recipe_id),
+    sqlc.narg(user_id), sqlc.narg(club_id), sqlc.arg(created_by)
+)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. LIST RESOURCE SHARES
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = item_id (a brew or recipe ID)
+-- Returns: The item's shares with the grantee's username or club name,
+--          oldest first
+-- Usage: Owner reviews who an item is shared with
+-- Performance: Uses idx_resource_share_item_grantee
+-- name: ListResourceShares :many
+SELECT s.id, s.user_id, u.username, s.club_id, cl.name AS club_name, s.created_at
+FROM resource_share s
+LEFT JOIN "user" u ON u.id = s.user_id
+LEFT JOIN club cl ON cl.id = s.club_id
+WHERE COALESCE(s.brew_id, s.recipe_id) = $1
+ORDER BY s.created_at;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. DELETE RESOURCE SHARE
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id, $2 = item_id
+-- Returns: Number of rows deleted (0 if the item has no such share)
+-- Usage: Owner revokes a share
+-- name: DeleteResourceShare :execrows
+DELETE FROM resource_share
+WHERE id = $1 AND COALESCE(brew_id, recipe_id) = $2;
+
+
+-- ----------------------------------------------------------------------------
+-- 4. LIST SHARED WITH USER
+-- ----------------------------------------------------------------------------
+-- Parameters: viewer_id, row_limit, row_offset
+-- Returns: Other users' brews and recipes shared with the viewer directly
+--          or through a club they belong to, once each, most recently
+--          shared first
+-- Usage: "Shared with me" screen
+-- Performance: Uses idx_resource_share_user, idx_resource_share_club and
+--              idx_club_member_user
+-- name: ListSharedWithUser :many
+SELECT shared.brew_id, shared.recipe_id, shared.name, shared.owner_id, shared.owner_username,
+       shared.club_id, shared.shared_at
+FROM (
+    SELECT DISTINCT ON (COALESCE(s.brew_id, s.recipe_id))
+        s.brew_id, s.recipe_id,
+        COALESCE(b.name, r.name)::text AS name,
+        u.id AS owner_id,
+        u.username AS owner_username,
+        s.club_id,
+        s.created_at AS shared_at
+    FROM resource_share s
+    LEFT JOIN brew b ON b.id = s.brew_id
+    LEFT JOIN recipe r ON r.id = s.recipe_id
+    JOIN "user" u ON u.id = COALESCE(b.created_by, r.owner_id)
+    WHERE (s.user_id = sqlc.arg(viewer_id)
+           OR s.club_id IN (SELECT cm.club_id FROM club_member cm WHERE cm.user_id = sqlc.arg(viewer_id)))
+      AND u.id <> sqlc.arg(viewer_id)
+    ORDER BY COALESCE(s.brew_id, s.recipe_id), s.created_at DESC
+) shared
+ORDER BY shared.shared_at DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+
+-- ----------------------------------------------------------------------------
+-- 5. GET VISIBLE BREW
+-- ----------------------------------------------------------------------------
+-- Parameters: brew_id, viewer_id
+-- Returns: The brew if the viewer may see it: they logged it, it's public,
+--          or it's shared with them or a club they belong to; otherwise no
+--          rows
+-- Usage: Viewing a brew and anything attached to it
+-- name: GetVisibleBrew :one
+SELECT b.* FROM brew b
+WHERE b.id = sqlc.arg(brew_id)
+  AND (b.created_by = sqlc.arg(viewer_id)
+       OR b.is_public IS NOT FALSE
+       OR EXISTS (
+           SELECT 1 FROM resource_share s
+           WHERE s.brew_id = b.id
+             AND (s.user_id = sqlc.arg(viewer_id)
+                  OR s.club_id IN (SELECT cm.club_id FROM club_member cm WHERE cm.user_id = sqlc.arg(viewer_id)))
+       ));
+
+
+-- ----------------------------------------------------------------------------
+-- 6. GET VISIBLE RECIPE
+-- ----------------------------------------------------------------------------
+-- Parameters: recipe_id, viewer_id
+-- Returns: The recipe if the viewer may see it: they own it, it's public,
+--          they're an accepted collaborator, or it's shared with them or a
+--          club they belong to; otherwise no rows
+-- Usage: Viewing a recipe, its revisions and forks
+-- name: GetVisibleRecipe :one
+SELECT r.* FROM recipe r
+WHERE r.id = sqlc.arg(recipe_id)
+  AND (r.owner_id = sqlc.arg(viewer_id)
+       OR r.is_public
+       OR EXISTS (
+           SELECT 1 FROM recipe_collaborator rc
+           WHERE rc.recipe_id = r.id AND rc.user_id = sqlc.arg(viewer_id) AND rc.accepted_at IS NOT NULL
+       )
+       OR EXISTS (
+           SELECT 1 FROM resource_share s
+           WHERE s.recipe_id = r.id
+             AND (s.user_id = sqlc.arg(viewer_id)
+                  OR s.club_id IN (SELECT cm.club_id FROM club_member cm WHERE cm.user_id = sqlc.arg(viewer_id)))
+       ));
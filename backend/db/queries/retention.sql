@@ -0,0 +1,189 @@
+-- ============================================================================
+-- RETENTION QUERIES
+-- ============================================================================
+-- Counting and purging rows past their data class's retention period. Each
+-- class has a count, for dry runs and the admin report, and a purge that
+-- deletes one batch, which the retention job repeats until none are left.
+
+
+-- ----------------------------------------------------------------------------
+-- 1. COUNT EXPIRED AUTH EVENTS
+-- ----------------------------------------------------------------------------
+-- Parameters: retention_days
+-- Returns: Number of auth events older than retention_days
+-- Usage: Retention job dry runs, admin retention report
+-- Performance: Uses idx_auth_event_created_at
+-- name: CountExpiredAuthEvents :one
+SELECT COUNT(*) FROM auth_event
+WHERE created_at < NOW() - sqlc.arg(retention_days)::int * INTERVAL '1 day';
+
+
+-- ----------------------------------------------------------------------------
+-- 2. PURGE AUTH EVENTS
+-- ----------------------------------------------------------------------------
+-- Parameters: retention_days, batch_size
+-- Returns: Number of events deleted
+-- Usage: Retention job; deletes up to batch_size auth events older than
+--        retention_days
+-- Performance: Uses idx_auth_event_created_at
+-- name: PurgeAuthEvents :execrows
+DELETE FROM auth_event
+WHERE id IN (
+    SELECT id FROM auth_event
+    WHERE created_at < NOW() - sqlc.arg(retention_days)::int * INTERVAL '1 day'
+    ORDER BY created_at
+    LIMIT sqlc.arg(batch_size)
+);
+
+
+-- ----------------------------------------------------------------------------
+-- 3. COUNT EXPIRED ANALYTICS EVENTS
+-- ----------------------------------------------------------------------------
+-- Parameters: retention_days
+-- Returns: Number of analytics events received more than retention_days ago
+-- Usage: Retention job dry runs, admin retention report
+-- Note: Ages by received_at, since occurred_at is whatever the client sent
+-- Performance: Uses idx_analytics_event_received_at
+-- name: CountExpiredAnalyticsEvents :one
+SELECT COUNT(*) FROM analytics_event
+WHERE received_at < NOW() - sqlc.arg(retention_days)::int * INTERVAL '1 day';
+
+
+-- ----------------------------------------------------------------------------
+-- 4. PURGE ANALYTICS EVENTS
+-- ----------------------------------------------------------------------------
+-- Parameters: retention_days, batch_size
+-- Returns: Number of events deleted
+-- Usage: Retention job; deletes up to batch_size analytics events received
+--        more than retention_days ago
+-- Performance: Uses idx_analytics_event_received_at
+-- name: PurgeAnalyticsEvents :execrows
+DELETE FROM analytics_event
+WHERE id IN (
+    SELECT id FROM analytics_event
+    WHERE received_at < NOW() - sqlc.arg(retention_days)::int * INTERVAL '1 day'
+    ORDER BY received_at
+    LIMIT sqlc.arg(batch_size)
+);
+
+
+-- ----------------------------------------------------------------------------
+-- 5. COUNT EXPIRED NOTIFICATIONS
+-- ----------------------------------------------------------------------------
+-- Parameters: retention_days
+-- Returns: Number of notifications older than retention_days, read or not
+-- Usage: Retention job dry runs, admin retention report
+-- Performance: Uses idx_notification_created_at
+-- name: CountExpiredNotifications :one
+SELECT COUNT(*) FROM notification
+WHERE created_at < NOW() - sqlc.arg(retention_days)::int * INTERVAL '1 day';
+
+
+-- ----------------------------------------------------------------------------
+-- 6. PURGE NOTIFICATIONS
+-- ----------------------------------------------------------------------------
+-- Parameters: retention_days, batch_size
+-- Returns: Number of notifications deleted
+-- Usage: Retention job; deletes up to batch_size notifications older than
+--        retention_days, read or not
+-- Performance: Uses idx_notification_created_at
+-- name: PurgeNotifications :execrows
+DELETE FROM notification
+WHERE id IN (
+    SELECT id FROM notification
+    WHERE created_at < NOW() - sqlc.arg(retention_days)::int * INTERVAL '1 day'
+    ORDER BY created_at
+    LIMIT sqlc.arg(batch_size)
+);
+
+
+-- ----------------------------------------------------------------------------
+-- 7. COUNT EXPIRED REVOKED API KEYS
+-- ----------------------------------------------------------------------------
+-- Parameters: retention_days
+-- Returns: Number of API keys revoked more than retention_days ago
+-- Usage: Retention job dry runs, admin retention report
+-- Performance: Sequential scan; API keys are few
+-- name: CountExpiredRevokedAPIKeys :one
+SELECT COUNT(*) FROM api_key
+WHERE revoked_at < NOW() - sqlc.arg(retention_days)::int * INTERVAL '1 day';
+
+
+-- ----------------------------------------------------------------------------
+-- 8. PURGE REVOKED API KEYS
+-- ----------------------------------------------------------------------------
+-- Parameters: retention_days, batch_size
+-- Returns: Number of API keys deleted
+-- Usage: Retention job; deletes up to batch_size API keys revoked more than
+--        retention_days ago. Devices paired with one keep their readings
+--        and lose the link.
+-- Performance: Sequential scan; API keys are few
+-- name: PurgeRevokedAPIKeys :execrows
+DELETE FROM api_key
+WHERE id IN (
+    SELECT id FROM api_key
+    WHERE revoked_at < NOW() - sqlc.arg(retention_days)::int * INTERVAL '1 day'
+    ORDER BY revoked_at
+    LIMIT sqlc.arg(batch_size)
+);
+
+
+-- ----------------------------------------------------------------------------
+-- 9. COUNT EXPIRED REVOKED CLUB INVITES
+-- ----------------------------------------------------------------------------
+-- Parameters: retention_days
+-- Returns: Number of club invites revoked more than retention_days ago
+-- Usage: Retention job dry runs, admin retention report
+-- Performance: Sequential scan; club invites are few
+-- name: CountExpiredRevokedClubInvites :one
+SELECT COUNT(*) FROM club_invite
+WHERE revoked_at < NOW() - sqlc.arg(retention_days)::int * INTERVAL '1 day';
+
+
+-- ----------------------------------------------------------------------------
+-- 10. PURGE REVOKED CLUB INVITES
+-- ----------------------------------------------------------------------------
+-- Parameters: retention_days, batch_size
+-- Returns: Number of club invites deleted
+-- Usage: Retention job; deletes up to batch_size club invites revoked more
+--        than retention_days ago
+-- Performance: Sequential scan; club invites are few
+-- name: PurgeRevokedClubInvites :execrows
+DELETE FROM club_invite
+WHERE id IN (
+    SELECT id FROM club_invite
+    WHERE revoked_at < NOW() - sqlc.arg(retention_days)::int * INTERVAL '1 day'
+    ORDER BY revoked_at
+    LIMIT sqlc.arg(batch_size)
+);
+
+
+-- ----------------------------------------------------------------------------
+-- 11. COUNT EXPIRED REVOKED INVITES
+-- ----------------------------------------------------------------------------
+-- Parameters: retention_days
+-- Returns: Number of invites revoked more than retention_days ago
+-- Usage: Retention job dry runs, admin retention report
+-- Performance: Sequential scan; invites are few
+-- name: CountExpiredRevokedInvites :one
+SELECT COUNT(*) FROM invite
+WHERE revoked_at < NOW() - sqlc.arg(retention_days)::int * INTERVAL '1 day';
+
+
+-- ----------------------------------------------------------------------------
+-- 12. PURGE REVOKED INVITES
+-- ----------------------------------------------------------------------------
+-- Parameters: retention_days, batch_size
+-- Returns: Number of invites deleted
+-- Usage: Retention job; deletes up to batch_size invites revoked more than
+--        retention_days ago. Users who signed up with one keep its code
+--        and inviter; its redeemed uses stop counting against the quota.
+-- Performance: Sequential scan; invites are few
+-- name: PurgeRevokedInvites :execrows
+DELETE FROM invite
+WHERE code IN (
+    SELECT code FROM invite
+    WHERE revoked_at < NOW() - sqlc.arg(retention_days)::int * INTERVAL '1 day'
+    ORDER BY revoked_at
+    LIMIT sqlc.arg(batch_size)
+);
@@ -0,0 +1,94 @@
+-- ============================================================================
+-- ROASTER QUERIES
+-- ============================================================================
+-- Operations for roaster accounts and their verification
+
+
+-- ----------------------------------------------------------------------------
+-- 1. CREATE ROASTER
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id, $2 = user_id, $3 = name, $4 = website,
+--             $5 = location, $6 = description
+-- Returns: The created roaster
+-- Usage: A user sets up a roaster account; names taken case-insensitively
+--        surface as 23505
+-- name: CreateRoaster :one
+INSERT INTO roaster (id, user_id, name, website, location, description)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. GET ROASTER BY ID
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id
+-- Returns: The roaster
+-- Usage: Roaster profile
+-- name: GetRoasterByID :one
+SELECT * FROM roaster
+WHERE id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. GET ROASTER BY USER ID
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id
+-- Returns: The roaster the user manages
+-- Usage: Publishing official listings and recipes
+-- name: GetRoasterByUserID :one
+SELECT * FROM roaster
+WHERE user_id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 4. UPDATE ROASTER
+-- ----------------------------------------------------------------------------
+-- Parameters: name, website, location, description, id (NULL leaves a
+--             field as is)
+-- Returns: The updated roaster; a new name clears verification, so a
+--          verified account can't be renamed into another brand
+-- Usage: Roaster edits its profile
+-- name: UpdateRoaster :one
+UPDATE roaster
+SET
+    verified_at = CASE
+        WHEN LOWER(COALESCE(sqlc.narg(name), name)) = LOWER(name) THEN verified_at
+    END,
+    name = COALESCE(sqlc.narg(name), name),
+    website = COALESCE(sqlc.narg(website), website),
+    location = COALESCE(sqlc.narg(location), location),
+    description = COALESCE(sqlc.narg(description), description)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 5. LIST ROASTERS
+-- ----------------------------------------------------------------------------
+-- Parameters: verified_only, row_limit, row_offset
+-- Returns: Roasters by name, with their official listing counts
+-- Usage: Roaster directory
+-- name: ListRoasters :many
+SELECT
+    r.id,
+    r.name,
+    r.location,
+    r.verified_at,
+    (SELECT COUNT(*) FROM bean WHERE roaster_id = r.id) AS bean_count
+FROM roaster r
+WHERE r.verified_at IS NOT NULL OR NOT sqlc.arg(verified_only)::boolean
+ORDER BY LOWER(r.name), r.id
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+
+-- ----------------------------------------------------------------------------
+-- 6. SET ROASTER VERIFIED
+-- ----------------------------------------------------------------------------
+-- Parameters: verified, id
+-- Returns: The updated roaster; re-verifying keeps the original verified_at
+-- Usage: Admin verifies a roaster or revokes its verification
+-- name: SetRoasterVerified :one
+UPDATE roaster
+SET verified_at = CASE WHEN sqlc.arg(verified)::boolean THEN COALESCE(verified_at, NOW()) END
+WHERE id = sqlc.arg(id)
+RETURNING *;
@@ -0,0 +1,185 @@
+-- ============================================================================
+-- SCIM QUERIES
+-- ============================================================================
+-- Operations for SCIM provisioning: the clients admins register, and the
+-- student accounts each client creates, updates, deactivates and deletes
+
+
+-- ----------------------------------------------------------------------------
+-- 1. CREATE SCIM CLIENT
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id, $2 = name, $3 = token_prefix, $4 = token_hash
+-- Returns: The created client (the token itself is never stored)
+-- Usage: Admin registers a training program's identity system
+-- name: CreateSCIMClient :one
+INSERT INTO scim_client (id, name, token_prefix, token_hash)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. LIST SCIM CLIENTS
+-- ----------------------------------------------------------------------------
+-- Parameters: None
+-- Returns: Every client with how many accounts it provisioned, newest first
+-- Usage: Admin reviews the registered identity systems
+-- Performance: Uses idx_scim_user_client
+-- name: ListSCIMClients :many
+SELECT c.id, c.name, c.token_prefix, c.last_used_at, c.created_at,
+       (SELECT COUNT(*) FROM scim_user su WHERE su.client_id = c.id) AS user_count
+FROM scim_client c
+ORDER BY c.created_at DESC;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. GET SCIM CLIENT BY HASH
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = token_hash
+-- Returns: The client with that token
+-- Usage: Authenticating a SCIM request
+-- name: GetSCIMClientByHash :one
+SELECT * FROM scim_client
+WHERE token_hash = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 4. TOUCH SCIM CLIENT
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id
+-- Returns: None
+-- Usage: Record when a client was last used; skipped within a minute of
+--        the last write, as provisioning runs send requests in bursts
+-- name: TouchSCIMClient :exec
+UPDATE scim_client
+SET last_used_at = NOW()
+WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute');
+
+
+-- ----------------------------------------------------------------------------
+-- 5. DELETE SCIM CLIENT
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id
+-- Returns: Number of rows deleted; 0 if there is no such client
+-- Usage: Admin removes an identity system. The accounts it provisioned
+--        stay, no longer managed by it.
+-- name: DeleteSCIMClient :execrows
+DELETE FROM scim_client
+WHERE id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 6. CREATE SCIM USER
+-- ----------------------------------------------------------------------------
+-- Parameters: id, username, email, password_hash, active, client_id,
+--             external_id
+-- Returns: The provisioned account
+-- Usage: A client provisions a student. A taken username, email or
+--        external_id fails with a unique violation.
+-- name: CreateSCIMUser :one
+WITH created AS (
+    INSERT INTO "user" (id, username, email, password_hash, joined_at, deactivated_at)
+    VALUES (
+        sqlc.arg(id), sqlc.arg(username), sqlc.arg(email), sqlc.arg(password_hash), NOW(),
+        CASE WHEN sqlc.arg(active)::bool THEN NULL ELSE NOW() END
+    )
+    RETURNING id, username, email, deactivated_at, created_at, updated_at
+), linked AS (
+    INSERT INTO scim_user (user_id, client_id, external_id)
+    SELECT id, sqlc.arg(client_id), sqlc.narg(external_id) FROM created
+    RETURNING external_id
+)
+SELECT created.id, created.username, created.email, linked.external_id,
+       created.deactivated_at, created.created_at, created.updated_at
+FROM created, linked;
+
+
+-- ----------------------------------------------------------------------------
+-- 7. GET SCIM USER
+-- ----------------------------------------------------------------------------
+-- Parameters: client_id, id
+-- Returns: An account the client provisioned
+-- Usage: Reading one account, and the current state a PATCH applies to
+-- name: GetSCIMUser :one
+SELECT u.id, u.username, u.email, su.external_id,
+       u.deactivated_at, u.created_at, u.updated_at
+FROM scim_user su
+JOIN "user" u ON u.id = su.user_id
+WHERE su.client_id = sqlc.arg(client_id) AND su.user_id = sqlc.arg(id);
+
+
+-- ----------------------------------------------------------------------------
+-- 8. LIST SCIM USERS
+-- ----------------------------------------------------------------------------
+-- Parameters: client_id, username, external_id, email, limit, offset; the
+--             filters are optional and username and email are matched
+--             case-insensitively
+-- Returns: One page of the accounts the client provisioned, oldest first
+-- Usage: A client lists accounts, or looks one up before provisioning it
+-- Performance: Uses idx_scim_user_client
+-- name: ListSCIMUsers :many
+SELECT u.id, u.username, u.email, su.external_id,
+       u.deactivated_at, u.created_at, u.updated_at
+FROM scim_user su
+JOIN "user" u ON u.id = su.user_id
+WHERE su.client_id = sqlc.arg(client_id)
+  AND (sqlc.narg(username)::text IS NULL OR LOWER(u.username) = LOWER(sqlc.narg(username)))
+  AND (sqlc.narg(external_id)::text IS NULL OR su.external_id = sqlc.narg(external_id))
+  AND (sqlc.narg(email)::text IS NULL OR LOWER(u.email) = LOWER(sqlc.narg(email)))
+ORDER BY su.created_at, su.user_id
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+
+-- ----------------------------------------------------------------------------
+-- 9. COUNT SCIM USERS
+-- ----------------------------------------------------------------------------
+-- Parameters: client_id, username, external_id, email, as for ListSCIMUsers
+-- Returns: How many accounts match in all
+-- Usage: The total SCIM list responses report alongside a page
+-- name: CountSCIMUsers :one
+SELECT COUNT(*)::int
+FROM scim_user su
+JOIN "user" u ON u.id = su.user_id
+WHERE su.client_id = sqlc.arg(client_id)
+  AND (sqlc.narg(username)::text IS NULL OR LOWER(u.username) = LOWER(sqlc.narg(username)))
+  AND (sqlc.narg(external_id)::text IS NULL OR su.external_id = sqlc.narg(external_id))
+  AND (sqlc.narg(email)::text IS NULL OR LOWER(u.email) = LOWER(sqlc.narg(email)));
+
+
+-- ----------------------------------------------------------------------------
+-- 10. UPDATE SCIM USER
+-- ----------------------------------------------------------------------------
+-- Parameters: client_id, id, username, email, password_hash (optional,
+--             keeps the password), active, external_id
+-- Returns: The updated account, or no rows if the client didn't provision
+--          it
+-- Usage: A client replaces or patches an account. Deactivating an account
+--        already deactivated keeps when that happened.
+-- name: UpdateSCIMUser :one
+WITH linked AS (
+    UPDATE scim_user
+    SET external_id = sqlc.narg(external_id)
+    WHERE client_id = sqlc.arg(client_id) AND user_id = sqlc.arg(id)
+    RETURNING user_id, external_id
+)
+UPDATE "user" u
+SET username = sqlc.arg(username),
+    email = sqlc.arg(email),
+    password_hash = COALESCE(sqlc.narg(password_hash), u.password_hash),
+    deactivated_at = CASE WHEN sqlc.arg(active)::bool THEN NULL ELSE COALESCE(u.deactivated_at, NOW()) END
+FROM linked
+WHERE u.id = linked.user_id
+RETURNING u.id, u.username, u.email, linked.external_id,
+          u.deactivated_at, u.created_at, u.updated_at;
+
+
+-- ----------------------------------------------------------------------------
+-- 11. DELETE SCIM USER
+-- ----------------------------------------------------------------------------
+-- Parameters: client_id, id
+-- Returns: Number of rows deleted; 0 if the client didn't provision it
+-- Usage: A client deprovisions a student. The account and everything in
+--        it are deleted.
+-- name: DeleteSCIMUser :execrows
+DELETE FROM "user"
+WHERE id = sqlc.arg(id)
+  AND id IN (SELECT user_id FROM scim_user WHERE client_id = sqlc.arg(client_id));
@@ -0,0 +1,256 @@
+-- ============================================================================
+-- STEP-UP QUERIES
+-- ============================================================================
+-- Operations for step-up authentication: the login risk signals, step-up
+-- challenges, TOTP enrollment and trusted devices.
+
+
+-- ----------------------------------------------------------------------------
+-- 1. GET LOGIN RISK
+-- ----------------------------------------------------------------------------
+-- Parameters: user_id, user_agent, country, region, failure_window_hours
+-- Returns: Whether the user has signed in before, and if so whether from
+--          this user agent and this country and region (an unknown
+--          location counts as known), and the failed passwords and step-up
+--          codes since their last login within the window
+-- Usage: Login decides whether to hold a login for a step-up challenge
+-- Performance: Uses idx_auth_event_user
+-- name: GetLoginRisk :one
+WITH history AS (
+    SELECT event, user_agent, country, region, created_at
+    FROM auth_event
+    WHERE user_id = sqlc.arg(user_id)
+)
+SELECT
+    EXISTS (
+        SELECT 1 FROM history WHERE event IN ('register', 'login')
+    ) AS has_history,
+    EXISTS (
+        SELECT 1 FROM history
+        WHERE event IN ('register', 'login')
+          AND user_agent IS NOT DISTINCT FROM sqlc.narg(user_agent)
+    ) AS known_device,
+    (sqlc.narg(country)::text IS NULL OR EXISTS (
+        SELECT 1 FROM history
+        WHERE event IN ('register', 'login')
+          AND country = sqlc.narg(country)
+          AND region IS NOT DISTINCT FROM sqlc.narg(region)
+    )) AS known_location,
+    (
+        SELECT COUNT(*) FROM history
+        WHERE event IN ('login_failed', 'step_up_failed')
+          AND created_at > NOW() - sqlc.arg(failure_window_hours)::int * INTERVAL '1 hour'
+          AND created_at > COALESCE(
+              (SELECT MAX(created_at) FROM history WHERE event = 'login'),
+              '-infinity'
+          )
+    )::int AS recent_failures;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. CREATE STEP-UP CHALLENGE
+-- ----------------------------------------------------------------------------
+-- Parameters: id, user_id, method, code_hash, ttl_seconds
+-- Returns: The new challenge
+-- Usage: Login holds a suspicious login. The user's expired challenges are
+--        deleted in the same statement.
+-- Performance: Uses idx_step_up_challenge_user
+-- name: CreateStepUpChallenge :one
+WITH expired AS (
+    DELETE FROM step_up_challenge
+    WHERE user_id = sqlc.arg(user_id)
+      AND expires_at <= NOW()
+)
+INSERT INTO step_up_challenge (id, user_id, method, code_hash, expires_at)
+VALUES (
+    sqlc.arg(id),
+    sqlc.arg(user_id),
+    sqlc.arg(method),
+    sqlc.narg(code_hash),
+    NOW() + sqlc.arg(ttl_seconds)::int * INTERVAL '1 second'
+)
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. ATTEMPT STEP-UP CHALLENGE
+-- ----------------------------------------------------------------------------
+-- Parameters: id, max_attempts
+-- Returns: The challenge, if it hasn't expired or run out of attempts,
+--          with what's needed to check its code and sign the user in
+-- Usage: Step-up verification; counts the attempt before the code is
+--        checked, so concurrent guesses can't exceed max_attempts
+-- name: AttemptStepUpChallenge :one
+WITH attempt AS (
+    UPDATE step_up_challenge
+    SET attempts = attempts + 1
+    WHERE id = sqlc.arg(id)
+      AND expires_at > NOW()
+      AND attempts < sqlc.arg(max_attempts)
+    RETURNING id, user_id, method, code_hash
+)
+SELECT a.id, a.user_id, a.method, a.code_hash,
+       u.username, u.email, u.totp_secret
+FROM attempt a
+JOIN "user" u ON u.id = a.user_id;
+
+
+-- ----------------------------------------------------------------------------
+-- 4. COMPLETE STEP-UP CHALLENGE
+-- ----------------------------------------------------------------------------
+-- Parameters: id, max_attempts
+-- Returns: Number of challenges deleted (0 if it was already used, expired
+--          or used more than max_attempts attempts)
+-- Usage: Step-up verification, after a correct code; only one request can
+--        complete a challenge
+-- name: CompleteStepUpChallenge :execrows
+DELETE FROM step_up_challenge
+WHERE id = sqlc.arg(id)
+  AND expires_at > NOW()
+  AND attempts <= sqlc.arg(max_attempts);
+
+
+-- ----------------------------------------------------------------------------
+-- 5. GET USER TOTP
+-- ----------------------------------------------------------------------------
+-- Parameters: id
+-- Returns: The user's username and TOTP enrollment
+-- Usage: Login picks the step-up method; TOTP enrollment
+-- name: GetUserTOTP :one
+SELECT username, totp_secret, totp_enabled_at
+FROM "user"
+WHERE id = sqlc.arg(id);
+
+
+-- ----------------------------------------------------------------------------
+-- 6. SET PENDING TOTP SECRET
+-- ----------------------------------------------------------------------------
+-- Parameters: id, totp_secret
+-- Returns: Number of users updated (0 if TOTP is already enabled)
+-- Usage: TOTP enrollment starts; replaces an unconfirmed secret
+-- name: SetPendingTOTPSecret :execrows
+UPDATE "user"
+SET totp_secret = sqlc.arg(totp_secret),
+    totp_last_step = NULL
+WHERE id = sqlc.arg(id)
+  AND totp_enabled_at IS NULL;
+
+
+-- ----------------------------------------------------------------------------
+-- 7. ENABLE TOTP
+-- ----------------------------------------------------------------------------
+-- Parameters: id, step
+-- Returns: Number of users updated (0 if there's no pending secret)
+-- Usage: TOTP enrollment is confirmed with a code from the secret
+-- name: EnableTOTP :execrows
+UPDATE "user"
+SET totp_enabled_at = NOW(),
+    totp_last_step = sqlc.arg(step)
+WHERE id = sqlc.arg(id)
+  AND totp_secret IS NOT NULL
+  AND totp_enabled_at IS NULL;
+
+
+-- ----------------------------------------------------------------------------
+-- 8. DISABLE TOTP
+-- ----------------------------------------------------------------------------
+-- Parameters: id
+-- Returns: None
+-- Usage: User turns TOTP off; step-up falls back to emailed codes
+-- name: DisableTOTP :exec
+UPDATE "user"
+SET totp_secret = NULL,
+    totp_enabled_at = NULL,
+    totp_last_step = NULL
+WHERE id = sqlc.arg(id);
+
+
+-- ----------------------------------------------------------------------------
+-- 9. USE TOTP STEP
+-- ----------------------------------------------------------------------------
+-- Parameters: id, step
+-- Returns: Number of users updated (0 if a code from this or a later step
+--          was already used)
+-- Usage: Accepting a TOTP code, so it can't be used twice
+-- name: UseTOTPStep :execrows
+UPDATE "user"
+SET totp_last_step = sqlc.arg(step)
+WHERE id = sqlc.arg(id)
+  AND (totp_last_step IS NULL OR totp_last_step < sqlc.arg(step));
+
+
+-- ----------------------------------------------------------------------------
+-- 10. CREATE TRUSTED DEVICE
+-- ----------------------------------------------------------------------------
+-- Parameters: id, user_id, token_hash, user_agent, ttl_days
+-- Returns: None
+-- Usage: Step-up verification, when the user chooses to trust the device.
+--        The user's expired devices are deleted in the same statement.
+-- name: CreateTrustedDevice :exec
+WITH expired AS (
+    DELETE FROM trusted_device
+    WHERE user_id = sqlc.arg(user_id)
+      AND expires_at <= NOW()
+)
+INSERT INTO trusted_device (id, user_id, token_hash, user_agent, expires_at)
+VALUES (
+    sqlc.arg(id),
+    sqlc.arg(user_id),
+    sqlc.arg(token_hash),
+    sqlc.narg(user_agent),
+    NOW() + sqlc.arg(ttl_days)::int * INTERVAL '1 day'
+);
+
+
+-- ----------------------------------------------------------------------------
+-- 11. USE TRUSTED DEVICE
+-- ----------------------------------------------------------------------------
+-- Parameters: user_id, token_hash
+-- Returns: Number of devices updated (0 if the token isn't a current
+--          trusted device of the user)
+-- Usage: Login skips step-up for a trusted device
+-- name: UseTrustedDevice :execrows
+UPDATE trusted_device
+SET last_used_at = NOW()
+WHERE token_hash = sqlc.arg(token_hash)
+  AND user_id = sqlc.arg(user_id)
+  AND expires_at > NOW();
+
+
+-- ----------------------------------------------------------------------------
+-- 12. LIST TRUSTED DEVICES
+-- ----------------------------------------------------------------------------
+-- Parameters: user_id
+-- Returns: The user's unexpired trusted devices, newest first
+-- Usage: User reviews the devices that skip step-up
+-- Performance: Uses idx_trusted_device_user
+-- name: ListTrustedDevices :many
+SELECT id, user_agent, created_at, last_used_at, expires_at
+FROM trusted_device
+WHERE user_id = sqlc.arg(user_id)
+  AND expires_at > NOW()
+ORDER BY created_at DESC;
+
+
+-- ----------------------------------------------------------------------------
+-- 13. REVOKE TRUSTED DEVICE
+-- ----------------------------------------------------------------------------
+-- Parameters: id, user_id
+-- Returns: Number of devices deleted (0 if the user has no such device)
+-- Usage: User stops trusting a device, e.g. a lost phone
+-- name: RevokeTrustedDevice :execrows
+DELETE FROM trusted_device
+WHERE id = sqlc.arg(id)
+  AND user_id = sqlc.arg(user_id);
+
+
+-- ----------------------------------------------------------------------------
+-- 14. REVOKE TRUSTED DEVICES
+-- ----------------------------------------------------------------------------
+-- Parameters: user_id
+-- Returns: Number of devices deleted
+-- Usage: User stops trusting all their devices
+-- Performance: Uses idx_trusted_device_user
+-- name: RevokeTrustedDevices :execrows
+DELETE FROM trusted_device
+WHERE user_id = sqlc.arg(user_id);
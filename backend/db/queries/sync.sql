@@ -0,0 +1,85 @@
+-- ============================================================================
+-- SYNC QUERIES
+-- ============================================================================
+-- Operations for the mobile client's offline sync: changes since a cursor,
+-- tombstones of deleted records, and revision vectors
+
+
+-- ----------------------------------------------------------------------------
+-- 1. LIST BREW CHANGES
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id, $2 = since (sync_seq cursor), $3 = limit
+-- Returns: The user's personal brews changed after the cursor, oldest change first
+-- Usage: Sync pull
+-- Performance: Uses idx_brew_sync
+-- name: ListBrewChanges :many
+SELECT * FROM brew
+WHERE created_by = $1 AND organization_id IS NULL AND sync_seq > $2
+ORDER BY sync_seq
+LIMIT $3;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. LIST DRAFT CHANGES
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id, $2 = since (sync_seq cursor), $3 = limit
+-- Returns: The user's drafts changed after the cursor, oldest change first
+-- Usage: Sync pull
+-- Performance: Uses idx_draft_sync
+-- name: ListDraftChanges :many
+SELECT * FROM draft
+WHERE user_id = $1 AND sync_seq > $2
+ORDER BY sync_seq
+LIMIT $3;
+
+
+-- ----------------------------------------------------------------------------
+-- 3. LIST TOMBSTONES
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id, $2 = since (sync_seq cursor), $3 = limit
+-- Returns: The user's records deleted after the cursor, oldest first
+-- Usage: Sync pull
+-- Performance: Uses idx_sync_tombstone_user
+-- name: ListTombstones :many
+SELECT * FROM sync_tombstone
+WHERE user_id = $1 AND seq > $2
+ORDER BY seq
+LIMIT $3;
+
+
+-- ----------------------------------------------------------------------------
+-- 4. GET TOMBSTONE
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = resource, $2 = record_id, $3 = user_id
+-- Returns: The user's tombstone for the record, if it was deleted
+-- Usage: A pushed change for a record deleted on the server
+-- name: GetTombstone :one
+SELECT * FROM sync_tombstone
+WHERE resource = $1 AND record_id = $2 AND user_id = $3;
+
+
+-- ----------------------------------------------------------------------------
+-- 5. SET BREW SYNC VECTOR
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id, $2 = created_by, $3 = sync_vector
+-- Returns: The brew with its new vector; no rows if it isn't the user's
+-- Usage: A conflict resolved in the server's favour: the brew keeps its
+--        values under a vector that supersedes the client's
+-- name: SetBrewSyncVector :one
+UPDATE brew
+SET sync_vector = $3
+WHERE id = $1 AND created_by = $2
+RETURNING *;
+
+
+-- ----------------------------------------------------------------------------
+-- 6. SET DRAFT SYNC VECTOR
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id, $2 = user_id, $3 = sync_vector
+-- Returns: The draft with its new vector; no rows if it isn't the user's
+-- Usage: A conflict resolved in the server's favour, as for brews
+-- name: SetDraftSyncVector :one
+UPDATE draft
+SET sync_vector = $3
+WHERE id = $1 AND user_id = $2
+RETURNING *;
@@ -0,0 +1,171 @@
+-- ============================================================================
+-- TRENDING QUERIES
+-- ============================================================================
+-- Operations for ranking trending public recipes and beans, and reading the
+-- precomputed rankings for discovery
+
+
+-- ----------------------------------------------------------------------------
+-- 1. REFRESH TRENDING RECIPES
+-- ----------------------------------------------------------------------------
+-- Parameters: period, since (start of the period), row_limit
+-- Returns: Nothing; replaces the period's trending recipes
+-- Usage: Discover worker. A recipe scores one point per public brew logged
+--        with it since the period started, plus its public post ratings
+--        given since then over 2.5 (a 5-star rating counts as two brews).
+--        Only public recipes are ranked.
+-- Performance: Uses idx_brew_created_at and idx_post_created_at
+-- name: RefreshTrendingRecipes :exec
+WITH cleared AS (
+    DELETE FROM trending_item
+    WHERE period = sqlc.arg(period) AND kind = 'recipe'
+),
+activity AS (
+    SELECT b.recipe_id, 1 AS brewed, NULL::float8 AS rating
+    FROM brew b
+    WHERE b.created_at >= sqlc.arg(since)
+        AND b.is_public IS NOT FALSE
+        AND b.recipe_id IS NOT NULL
+    UNION ALL
+    SELECT b.recipe_id, 0, p.rating::float8
+    FROM post p
+    JOIN brew b ON b.id = p.brew_id
+    WHERE p.created_at >= sqlc.arg(since)
+        AND p.visibility = 'public'
+        AND p.rating IS NOT NULL
+        AND b.recipe_id IS NOT NULL
+),
+scored AS (
+    SELECT
+        a.recipe_id,
+        SUM(a.brewed) AS brew_count,
+        COUNT(a.rating) AS rating_count,
+        AVG(a.rating) AS avg_rating,
+        SUM(a.brewed) + COALESCE(SUM(a.rating), 0) / 2.5 AS score
+    FROM activity a
+    JOIN recipe r ON r.id = a.recipe_id AND r.is_public
+    GROUP BY a.recipe_id
+)
+INSERT INTO trending_item (period, kind, rank, recipe_id, brew_count, rating_count, avg_rating, score)
+SELECT
+    sqlc.arg(period),
+    'recipe',
+    ROW_NUMBER() OVER (ORDER BY score DESC, recipe_id),
+    recipe_id,
+    brew_count,
+    rating_count,
+    avg_rating,
+    score
+FROM scored
+ORDER BY score DESC, recipe_id
+LIMIT sqlc.arg(row_limit);
+
+
+-- ----------------------------------------------------------------------------
+-- 2. REFRESH TRENDING BEANS
+-- ----------------------------------------------------------------------------
+-- Parameters: period, since (start of the period), row_limit
+-- Returns: Nothing; replaces the period's trending beans
+-- Usage: Discover worker. Beans are the roaster and origin public brews
+--        record, compared case-insensitively and shown in their most common
+--        spelling; brews missing either are left out. Scored as for
+--        RefreshTrendingRecipes.
+-- Performance: Uses idx_brew_created_at and idx_post_created_at
+-- name: RefreshTrendingBeans :exec
+WITH cleared AS (
+    DELETE FROM trending_item
+    WHERE period = sqlc.arg(period) AND kind = 'bean'
+),
+activity AS (
+    SELECT b.roaster, b.bean_origin, 1 AS brewed, NULL::float8 AS rating
+    FROM brew b
+    WHERE b.created_at >= sqlc.arg(since)
+        AND b.is_public IS NOT FALSE
+        AND b.roaster IS NOT NULL
+        AND b.bean_origin IS NOT NULL
+    UNION ALL
+    SELECT b.roaster, b.bean_origin, 0, p.rating::float8
+    FROM post p
+    JOIN brew b ON b.id = p.brew_id
+    WHERE p.created_at >= sqlc.arg(since)
+        AND p.visibility = 'public'
+        AND p.rating IS NOT NULL
+        AND b.roaster IS NOT NULL
+        AND b.bean_origin IS NOT NULL
+),
+scored AS (
+    SELECT
+        MODE() WITHIN GROUP (ORDER BY roaster) AS roaster,
+        MODE() WITHIN GROUP (ORDER BY bean_origin) AS bean_origin,
+        SUM(brewed) AS brew_count,
+        COUNT(rating) AS rating_count,
+        AVG(rating) AS avg_rating,
+        SUM(brewed) + COALESCE(SUM(rating), 0) / 2.5 AS score
+    FROM activity
+    GROUP BY LOWER(roaster), LOWER(bean_origin)
+)
+INSERT INTO trending_item (period, kind, rank, roaster, bean_origin, brew_count, rating_count, avg_rating, score)
+SELECT
+    sqlc.arg(period),
+    'bean',
+    ROW_NUMBER() OVER (ORDER BY score DESC, roaster, bean_origin),
+    roaster,
+    bean_origin,
+    brew_count,
+    rating_count,
+    avg_rating,
+    score
+FROM scored
+ORDER BY score DESC, roaster, bean_origin
+LIMIT sqlc.arg(row_limit);
+
+
+-- ----------------------------------------------------------------------------
+-- 3. LIST TRENDING RECIPES
+-- ----------------------------------------------------------------------------
+-- Parameters: period, row_limit
+-- Returns: The period's trending recipes, top first, with their owner's
+--          username and when the ranking was computed. Recipes made private
+--          since are left out.
+-- Usage: Discover screen
+-- Performance: Uses idx_trending_item_period
+-- name: ListTrendingRecipes :many
+SELECT
+    r.id,
+    r.name,
+    r.brew_method,
+    u.username AS owner_username,
+    t.brew_count,
+    t.rating_count,
+    t.avg_rating,
+    t.score,
+    t.computed_at
+FROM trending_item t
+JOIN recipe r ON r.id = t.recipe_id
+JOIN "user" u ON u.id = r.owner_id
+WHERE t.period = sqlc.arg(period) AND t.kind = 'recipe' AND r.is_public
+ORDER BY t.rank
+LIMIT sqlc.arg(row_limit);
+
+
+-- ----------------------------------------------------------------------------
+-- 4. LIST TRENDING BEANS
+-- ----------------------------------------------------------------------------
+-- Parameters: period, row_limit
+-- Returns: The period's trending beans, top first, and when the ranking was
+--          computed
+-- Usage: Discover screen
+-- Performance: Uses idx_trending_item_period
+-- name: ListTrendingBeans :many
+SELECT
+    roaster::text AS roaster,
+    bean_origin::text AS bean_origin,
+    brew_count,
+    rating_count,
+    avg_rating,
+    score,
+    computed_at
+FROM trending_item
+WHERE period = sqlc.arg(period) AND kind = 'bean'
+ORDER BY rank
+LIMIT sqlc.arg(row_limit);
@@ -7,13 +7,29 @@
 -- ----------------------------------------------------------------------------
 -- 1. CREATE USER (Registration)
 -- ----------------------------------------------------------------------------
--- Parameters: $1 = id (ULID), $2 = username, $3 = email, $4 = password_hash
+-- Parameters: id (ULID), username, email, password_hash, birth_date,
+--             parent_email, consent_token_hash, consent_ttl_seconds
 -- Returns: The created user record
--- Usage: Called during user registration
+-- Usage: Called during user registration. A parent_email means the user is
+--        under the minimum age: they're created awaiting consent, and the
+--        request to their parent is created in the same statement.
 -- name: CreateUser :one
-INSERT INTO "user" (id, username, email, password_hash, joined_at)
-VALUES ($1, $2, $3, $4, NOW())
-RETURNING id, username, email, joined_at, created_at;
+WITH created AS (
+    INSERT INTO "user" (id, username, email, password_hash, joined_at, birth_date, consent_pending)
+    VALUES (
+        sqlc.arg(id), sqlc.arg(username), sqlc.arg(email), sqlc.arg(password_hash), NOW(),
+        sqlc.narg(birth_date), sqlc.narg(parent_email)::text IS NOT NULL
+    )
+    RETURNING id, username, email, joined_at, created_at
+), consent AS (
+    INSERT INTO parental_consent (user_id, parent_email, token_hash, expires_at)
+    SELECT id, sqlc.narg(parent_email), sqlc.narg(consent_token_hash),
+           NOW() + sqlc.arg(consent_ttl_seconds)::int * INTERVAL '1 second'
+    FROM created
+    WHERE sqlc.narg(parent_email)::text IS NOT NULL
+)
+SELECT id, username, email, joined_at, created_at
+FROM created;
 
 
 -- ----------------------------------------------------------------------------
@@ -32,12 +48,14 @@ WHERE id = $1;
 -- 3. GET USER BY EMAIL (Authentication)
 -- ----------------------------------------------------------------------------
 -- Parameters: $1 = email
--- Returns: User record including password_hash for authentication
+-- Returns: User record including password_hash for authentication, and
+--          whether the account was deactivated or awaits parental consent
 -- Usage: Login verification (compare hashed passwords)
+-- Performance: Uses idx_user_email_lower
 -- name: GetUserByEmail :one
-SELECT id, username, email, password_hash, profile_picture_url
+SELECT id, username, email, password_hash, profile_picture_url, deactivated_at, consent_pending
 FROM "user"
-WHERE email = $1;
+WHERE LOWER(email) = LOWER($1);
 
 
 -- ----------------------------------------------------------------------------
@@ -56,17 +74,18 @@ WHERE username = $1;
 -- 5. UPDATE USER PROFILE
 -- ----------------------------------------------------------------------------
 -- Parameters: $1 = user_id, $2 = profile_picture_url, $3 = bio, $4 = location
--- Returns: Updated user record
--- Usage: User edits their profile
+-- Returns: Updated user record, with the same columns as GetUserByID
+-- Usage: User edits their profile; every field is written, so NULL clears it
+--        (callers merge partial updates over the current profile)
 -- name: UpdateUserProfile :one
 UPDATE "user"
 SET
-    profile_picture_url = COALESCE($2, profile_picture_url),
-    bio = COALESCE($3, bio),
-    location = COALESCE($4, location),
+    profile_picture_url = $2,
+    bio = $3,
+    location = $4,
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, username, email, profile_picture_url, bio, location, updated_at;
+RETURNING id, username, email, profile_picture_url, bio, location, joined_at;
 
 
 -- ----------------------------------------------------------------------------
@@ -116,9 +135,10 @@ LIMIT 20;
 -- Parameters: $1 = username
 -- Returns: Boolean (true if available, false if taken)
 -- Usage: Real-time validation during registration
+-- Note: Usernames are unique case-insensitively (idx_user_username_lower)
 -- name: CheckUsernameAvailability :one
 SELECT NOT EXISTS (
-    SELECT 1 FROM "user" WHERE username = $1
+    SELECT 1 FROM "user" WHERE LOWER(username) = LOWER($1)
 ) as available;
 
 
@@ -128,9 +148,10 @@ SELECT NOT EXISTS (
 -- Parameters: $1 = email
 -- Returns: Boolean (true if available, false if taken)
 -- Usage: Real-time validation during registration
+-- Note: Emails are unique case-insensitively (idx_user_email_lower)
 -- name: CheckEmailAvailability :one
 SELECT NOT EXISTS (
-    SELECT 1 FROM "user" WHERE email = $1
+    SELECT 1 FROM "user" WHERE LOWER(email) = LOWER($1)
 ) as available;
 
 
@@ -147,3 +168,118 @@ SET
     updated_at = NOW()
 WHERE id = $1
 RETURNING id, email;
+
+
+-- ----------------------------------------------------------------------------
+-- 11. UPDATE USERNAME
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id, $2 = new_username (already normalized)
+-- Returns: Updated user record
+-- Usage: Username change; unique violations surface as 23505
+-- name: UpdateUsername :one
+UPDATE "user"
+SET
+    username = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, username, email;
+
+
+-- ----------------------------------------------------------------------------
+-- 12. GET USER PREFERENCES
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id
+-- Returns: The user's display units, timezone, currency and analytics
+--          consent
+-- Usage: Convert brew payloads to/from canonical metric storage, local day
+--        boundaries, default currency for bean prices, whether to keep
+--        client analytics events
+-- name: GetUserPreferences :one
+SELECT weight_unit, temperature_unit, timezone, currency, analytics_consent
+FROM "user"
+WHERE id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 13. UPDATE USER PREFERENCES
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id, $2 = weight_unit, $3 = temperature_unit,
+--             $4 = timezone, $5 = currency, $6 = analytics_consent
+-- Returns: Updated preferences
+-- Usage: User switches between metric and imperial display, changes
+--        timezone or currency, or opts in or out of analytics
+-- name: UpdateUserPreferences :one
+UPDATE "user"
+SET
+    weight_unit = $2,
+    temperature_unit = $3,
+    timezone = $4,
+    currency = $5,
+    analytics_consent = $6,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING weight_unit, temperature_unit, timezone, currency, analytics_consent;
+
+
+-- ----------------------------------------------------------------------------
+-- 14. GET USER PLAN
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = user_id
+-- Returns: The user's plan tier
+-- Usage: Quota middleware, cached per user
+-- name: GetUserPlan :one
+SELECT plan FROM "user"
+WHERE id = $1;
+
+
+-- ----------------------------------------------------------------------------
+-- 15. CREATE INVITED USER
+-- ----------------------------------------------------------------------------
+-- Parameters: code (the invite's), id, username, email, password_hash,
+--             birth_date, parent_email, consent_token_hash,
+--             consent_ttl_seconds
+-- Returns: Created user record, or no rows if the invite can't be redeemed
+--          (unknown, revoked, expired or used up)
+-- Usage: Registration with an invite. The invite's use is counted in the
+--        same statement, so a failed registration doesn't spend it. A
+--        parent_email creates the user awaiting consent, as in CreateUser.
+-- name: CreateInvitedUser :one
+WITH redeemed AS (
+    UPDATE invite
+    SET use_count = use_count + 1
+    WHERE code = sqlc.arg(code)
+      AND revoked_at IS NULL
+      AND (expires_at IS NULL OR expires_at > NOW())
+      AND use_count < max_uses
+    RETURNING code, inviter_id
+), created AS (
+    INSERT INTO "user" (id, username, email, password_hash, joined_at, invite_code, invited_by,
+                        birth_date, consent_pending)
+    SELECT sqlc.arg(id), sqlc.arg(username), sqlc.arg(email), sqlc.arg(password_hash), NOW(),
+           redeemed.code, redeemed.inviter_id, sqlc.narg(birth_date), sqlc.narg(parent_email)::text IS NOT NULL
+    FROM redeemed
+    RETURNING id, username, email, joined_at, created_at
+), consent AS (
+    INSERT INTO parental_consent (user_id, parent_email, token_hash, expires_at)
+    SELECT id, sqlc.narg(parent_email), sqlc.narg(consent_token_hash),
+           NOW() + sqlc.arg(consent_ttl_seconds)::int * INTERVAL '1 second'
+    FROM created
+    WHERE sqlc.narg(parent_email)::text IS NOT NULL
+)
+SELECT id, username, email, joined_at, created_at
+FROM created;
+
+
+-- ----------------------------------------------------------------------------
+-- 16. IS USER ACTIVE
+-- ----------------------------------------------------------------------------
+-- Parameters: $1 = id
+-- Returns: Whether the user exists and hasn't been deactivated
+-- Usage: Authentication middleware, cached per user, so tokens issued
+--        before an account was deactivated or deleted stop working
+-- Performance: Uses the primary key
+-- name: IsUserActive :one
+SELECT EXISTS (
+    SELECT 1 FROM "user"
+    WHERE id = $1 AND deactivated_at IS NULL
+);
@@ -0,0 +1,74 @@
+-- ============================================================================
+-- USER STORAGE QUERIES
+-- ============================================================================
+-- Reading the bytes users keep in the object store, which triggers on their
+-- photos keep current (see schema/triggers.sql), and reserving bytes for
+-- uploads in flight
+
+
+-- ----------------------------------------------------------------------------
+-- 1. GET USER STORAGE
+-- ----------------------------------------------------------------------------
+-- Parameters: user_id
+-- Returns: Bytes of photos the user stores; 0 without a row
+-- Usage: Storage quota checks at upload, and usage on the profile
+-- Performance: Uses the primary key
+-- name: GetUserStorage :one
+SELECT COALESCE(
+    (SELECT photo_bytes FROM user_storage WHERE user_id = sqlc.arg(user_id)),
+    0
+)::bigint AS photo_bytes;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. LIST TOP STORAGE USERS
+-- ----------------------------------------------------------------------------
+-- Parameters: row_limit, row_offset
+-- Returns: Users storing the most, with their plan and photo count
+-- Usage: Admin storage report
+-- Performance: Uses idx_user_storage_photo_bytes; counts each listed user's
+--              photos with idx_brew_photo_user
+-- name: ListTopStorageUsers :many
+SELECT
+    s.user_id,
+    u.username,
+    u.plan,
+    s.photo_bytes,
+    (SELECT COUNT(*) FROM brew_photo p WHERE p.user_id = s.user_id)::int AS photo_count,
+    s.updated_at
+FROM user_storage s
+JOIN "user" u ON u.id = s.user_id
+WHERE s.photo_bytes > 0
+ORDER BY s.photo_bytes DESC, s.user_id
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+
+-- ----------------------------------------------------------------------------
+-- 3. RESERVE STORAGE
+-- ----------------------------------------------------------------------------
+-- Parameters: user_id, id, size_bytes, limit_bytes, ttl_seconds
+-- Returns: Whether the bytes were reserved; false if they'd take the user
+--          past limit_bytes with what they store and have reserved
+-- Usage: Upload, before the object is stored
+-- Note: reserve_storage locks the user's storage row, so concurrent
+--       uploads can't both fit under the quota
+-- name: ReserveStorage :one
+SELECT reserve_storage(
+    sqlc.arg(user_id)::text,
+    sqlc.arg(id)::text,
+    sqlc.arg(size_bytes)::bigint,
+    sqlc.arg(limit_bytes)::bigint,
+    sqlc.arg(ttl_seconds)::int
+)::boolean AS reserved;
+
+
+-- ----------------------------------------------------------------------------
+-- 4. RELEASE STORAGE
+-- ----------------------------------------------------------------------------
+-- Parameters: id
+-- Returns: Nothing
+-- Usage: Upload, once its row counts the bytes or it failed
+-- Performance: Uses the primary key
+-- name: ReleaseStorage :exec
+DELETE FROM storage_reservation
+WHERE id = sqlc.arg(id);
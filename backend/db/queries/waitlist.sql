@@ -0,0 +1,123 @@
+-- ============================================================================
+-- WAITLIST QUERIES
+-- ============================================================================
+-- Operations for the registration waitlist: queueing emails, approving
+-- them in batches with a single-use invite each, and tracking delivery of
+-- the invitation emails. Emails are stored lowercased.
+
+
+-- ----------------------------------------------------------------------------
+-- 1. JOIN WAITLIST
+-- ----------------------------------------------------------------------------
+-- Parameters: email
+-- Returns: None
+-- Usage: Registration in waitlist mode. Joining twice keeps the original
+--        place in line.
+-- name: JoinWaitlist :exec
+INSERT INTO waitlist_entry (email)
+VALUES (LOWER(sqlc.arg(email)))
+ON CONFLICT (email) DO NOTHING;
+
+
+-- ----------------------------------------------------------------------------
+-- 2. LIST WAITLIST
+-- ----------------------------------------------------------------------------
+-- Parameters: approved, row_limit, row_offset
+-- Returns: Pending entries oldest first, or approved entries newest first
+-- Usage: Admin waitlist page
+-- Performance: Uses idx_waitlist_entry_pending or idx_waitlist_entry_approved
+-- name: ListWaitlist :many
+SELECT * FROM waitlist_entry
+WHERE (approved_at IS NOT NULL) = sqlc.arg(approved)::boolean
+ORDER BY
+    CASE WHEN sqlc.arg(approved)::boolean THEN NULL ELSE created_at END ASC,
+    approved_at DESC,
+    email
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+
+-- ----------------------------------------------------------------------------
+-- 3. APPROVE WAITLIST BATCH
+-- ----------------------------------------------------------------------------
+-- Parameters: emails (NULL for the oldest pending entries), codes (one
+--             invite code per entry to approve; their count is the batch
+--             size), approved_by, expires_at
+-- Returns: The approved entries
+-- Usage: Admin approves a batch. Each entry gets a single-use invite
+--        created by the admin, in the same statement.
+-- name: ApproveWaitlistBatch :many
+WITH picked AS (
+    SELECT email, created_at
+    FROM waitlist_entry
+    WHERE approved_at IS NULL
+      AND (sqlc.narg(emails)::text[] IS NULL OR email = ANY(sqlc.narg(emails)::text[]))
+    ORDER BY created_at, email
+    LIMIT cardinality(sqlc.arg(codes)::text[])
+    FOR UPDATE SKIP LOCKED
+),
+batch AS (
+    SELECT picked.email, c.code
+    FROM (
+        SELECT email, ROW_NUMBER() OVER (ORDER BY created_at, email) AS n
+        FROM picked
+    ) picked
+    JOIN unnest(sqlc.arg(codes)::text[]) WITH ORDINALITY AS c(code, n) ON c.n = picked.n
+),
+invites AS (
+    INSERT INTO invite (code, inviter_id, max_uses, expires_at)
+    SELECT code, sqlc.arg(approved_by), 1, sqlc.narg(expires_at)
+    FROM batch
+)
+UPDATE waitlist_entry w
+SET approved_at = NOW(),
+    approved_by = sqlc.arg(approved_by),
+    invite_code = batch.code
+FROM batch
+WHERE w.email = batch.email
+RETURNING w.*;
+
+
+-- ----------------------------------------------------------------------------
+-- 4. LIST UNSENT WAITLIST INVITES
+-- ----------------------------------------------------------------------------
+-- Parameters: max_attempts, row_limit
+-- Returns: Approved entries whose invitation email hasn't been sent, and
+--          hasn't failed max_attempts times
+-- Usage: Waitlist worker
+-- Performance: Uses idx_waitlist_entry_unsent
+-- name: ListUnsentWaitlistInvites :many
+SELECT email, invite_code
+FROM waitlist_entry
+WHERE approved_at IS NOT NULL
+  AND invite_sent_at IS NULL
+  AND invite_code IS NOT NULL
+  AND send_attempts < sqlc.arg(max_attempts)
+ORDER BY approved_at
+LIMIT sqlc.arg(row_limit);
+
+
+-- ----------------------------------------------------------------------------
+-- 5. MARK WAITLIST INVITE SENT
+-- ----------------------------------------------------------------------------
+-- Parameters: email
+-- Returns: None
+-- Usage: Waitlist worker, after sending the invitation email
+-- name: MarkWaitlistInviteSent :exec
+UPDATE waitlist_entry
+SET invite_sent_at = NOW(),
+    last_error = NULL
+WHERE email = sqlc.arg(email);
+
+
+-- ----------------------------------------------------------------------------
+-- 6. RECORD WAITLIST INVITE FAILURE
+-- ----------------------------------------------------------------------------
+-- Parameters: email, error
+-- Returns: None
+-- Usage: Waitlist worker, when the invitation email can't be sent; it is
+--        retried on the next run until the attempts run out
+-- name: RecordWaitlistInviteFailure :exec
+UPDATE waitlist_entry
+SET send_attempts = send_attempts + 1,
+    last_error = sqlc.arg(error)
+WHERE email = sqlc.arg(email);
@@ -0,0 +1,17 @@
+-- Analytics event table
+-- Client telemetry (screen views, feature usage) from users who consented,
+-- kept when the analytics sink is the database. Separate from server
+-- metrics; properties are whatever the client sent.
+CREATE TABLE analytics_event (
+    id TEXT PRIMARY KEY, -- ULID format
+    user_id TEXT REFERENCES "user"(id) ON DELETE CASCADE,
+    session_id VARCHAR(64),
+    name VARCHAR(64) NOT NULL,
+    properties JSONB NOT NULL DEFAULT '{}',
+    occurred_at TIMESTAMPTZ NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_analytics_event_name ON analytics_event(name, occurred_at);
+CREATE INDEX idx_analytics_event_user ON analytics_event(user_id, occurred_at);
+CREATE INDEX idx_analytics_event_received_at ON analytics_event(received_at);
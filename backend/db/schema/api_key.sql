@@ -0,0 +1,17 @@
+-- API key table
+-- Long-lived keys that companion apps and device bridges use instead of a
+-- login. Only a SHA-256 hash of the key is stored; key_prefix identifies it
+-- in listings. Scopes limit which integration endpoints a key can call.
+CREATE TABLE api_key (
+    id TEXT PRIMARY KEY, -- ULID format
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    key_prefix VARCHAR(16) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_api_key_user ON api_key(user_id);
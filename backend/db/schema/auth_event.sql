@@ -0,0 +1,26 @@
+-- Auth event table
+-- Audit log of registrations and sign-in attempts, one row per issued
+-- session or failed password. Location is coarse (country, region, city)
+-- and only filled in when a GeoIP database is configured. A login from a
+-- country and region the user hasn't signed in from before is marked
+-- new_location, and the login alert worker emails the user about it.
+CREATE TABLE auth_event (
+    id BIGSERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    -- step_up: a login was held for a step-up challenge; step_up_failed: a
+    -- wrong step-up code
+    event VARCHAR(20) NOT NULL CHECK (event IN ('register', 'login', 'login_failed', 'step_up', 'step_up_failed')),
+    ip VARCHAR(45),
+    user_agent TEXT,
+    country VARCHAR(2), -- ISO 3166-1 alpha-2
+    region VARCHAR(100),
+    city VARCHAR(100),
+    new_location BOOLEAN NOT NULL DEFAULT FALSE,
+    alert_sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_auth_event_user ON auth_event(user_id, created_at DESC);
+CREATE INDEX idx_auth_event_created_at ON auth_event(created_at);
+CREATE INDEX idx_auth_event_alert_pending ON auth_event(created_at)
+    WHERE new_location AND alert_sent_at IS NULL;
@@ -0,0 +1,37 @@
+-- Challenge table
+-- An achievement users earn by reaching a threshold on one of their brewing
+-- metrics: brews logged (optionally with one brew method), distinct bean
+-- origins or distinct brew methods
+CREATE TABLE challenge (
+    id TEXT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    metric VARCHAR(20) NOT NULL CHECK (metric IN ('brews', 'origins', 'methods')),
+    brew_method VARCHAR(100) CHECK (brew_method IS NULL OR metric = 'brews'),
+    threshold INTEGER NOT NULL CHECK (threshold > 0),
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_by TEXT REFERENCES "user"(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- User badge table
+-- A challenge a user has completed
+CREATE TABLE user_badge (
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    challenge_id TEXT NOT NULL REFERENCES challenge(id) ON DELETE CASCADE,
+    earned_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, challenge_id)
+);
+
+CREATE INDEX idx_user_badge_challenge ON user_badge(challenge_id);
+
+-- Badge evaluation queue
+-- Users whose challenges need re-checking, e.g. after logging a brew; the
+-- badge worker claims and clears entries
+CREATE TABLE badge_evaluation (
+    user_id TEXT PRIMARY KEY REFERENCES "user"(id) ON DELETE CASCADE,
+    queued_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_badge_evaluation_queued_at ON badge_evaluation(queued_at);
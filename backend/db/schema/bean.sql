@@ -0,0 +1,87 @@
+-- Bean bag table
+-- A bag of coffee beans a user brews from; brews logged against it drive
+-- the run-out forecast
+CREATE TABLE bean_bag (
+    id TEXT PRIMARY KEY, -- ULID format
+    owner_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    roaster TEXT,
+    bean_origin TEXT,
+    weight_grams DOUBLE PRECISION NOT NULL CHECK (weight_grams > 0),
+    roasted_on DATE,
+    -- Days the beans rest after roasting before they're at their best; NULL
+    -- if not tracked
+    rest_days INTEGER CHECK (rest_days IS NULL OR rest_days BETWEEN 0 AND 60),
+    -- Dose assumed for linked brews that didn't record one; NULL uses the
+    -- server default
+    assumed_dose_grams DOUBLE PRECISION CHECK (assumed_dose_grams IS NULL OR assumed_dose_grams > 0),
+    -- Suggest reordering this many days before the bag is forecast to run out
+    reorder_lead_days INTEGER NOT NULL DEFAULT 3 CHECK (reorder_lead_days >= 0),
+    reorder_notified_at TIMESTAMPTZ,
+    -- Price paid, in the minor unit (e.g. cents) of an ISO 4217 currency
+    price_minor BIGINT CHECK (price_minor IS NULL OR price_minor >= 0),
+    currency VARCHAR(3),
+    purchased_on DATE,
+    CONSTRAINT bean_bag_price_check CHECK ((price_minor IS NULL) = (currency IS NULL)),
+    -- Structured origin; country, process and variety use the controlled
+    -- vocabularies served at /api/v1/origins
+    origin_country VARCHAR(2),
+    origin_region VARCHAR(100),
+    farm VARCHAR(255),
+    variety VARCHAR(50),
+    altitude_m INTEGER CHECK (altitude_m IS NULL OR altitude_m BETWEEN 0 AND 6000),
+    process VARCHAR(30) CHECK (
+        process IS NULL OR
+        process IN ('washed', 'natural', 'honey', 'semi_washed', 'wet_hulled',
+                    'anaerobic', 'carbonic_maceration', 'other')
+    ),
+    finished_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Indexes for common queries
+CREATE INDEX idx_bean_bag_owner_id ON bean_bag(owner_id);
+
+-- Bean table
+-- Community catalogue of coffees. Any user can add an entry; entries with a
+-- roaster_id are that roaster's official listings.
+CREATE TABLE bean (
+    id TEXT PRIMARY KEY, -- ULID format
+    name VARCHAR(255) NOT NULL,
+    roaster_name VARCHAR(255),
+    bean_origin TEXT,
+    description TEXT,
+    -- Structured origin; country, process and variety use the controlled
+    -- vocabularies served at /api/v1/origins
+    origin_country VARCHAR(2),
+    origin_region VARCHAR(100),
+    farm VARCHAR(255),
+    variety VARCHAR(50),
+    altitude_m INTEGER CHECK (altitude_m IS NULL OR altitude_m BETWEEN 0 AND 6000),
+    process VARCHAR(30) CHECK (
+        process IS NULL OR
+        process IN ('washed', 'natural', 'honey', 'semi_washed', 'wet_hulled',
+                    'anaerobic', 'carbonic_maceration', 'other')
+    ),
+    roaster_id TEXT REFERENCES roaster(id) ON DELETE SET NULL,
+    created_by TEXT REFERENCES "user"(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_bean_name_lower ON bean(LOWER(name));
+CREATE INDEX idx_bean_roaster_id ON bean(roaster_id);
+CREATE INDEX idx_bean_origin_country ON bean(origin_country);
+CREATE INDEX idx_bean_process ON bean(process);
+
+-- Bean recipe table
+-- Official recipes a roaster publishes on one of its bean listings
+CREATE TABLE bean_recipe (
+    bean_id TEXT NOT NULL REFERENCES bean(id) ON DELETE CASCADE,
+    recipe_id TEXT NOT NULL REFERENCES recipe(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (bean_id, recipe_id)
+);
+
+CREATE INDEX idx_bean_recipe_recipe ON bean_recipe(recipe_id);
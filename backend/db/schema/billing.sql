@@ -0,0 +1,33 @@
+-- Stripe event table
+-- Webhook events already processed, so redeliveries are ignored. An event
+-- is recorded in the same statement that applies it.
+CREATE TABLE stripe_event (
+    id TEXT PRIMARY KEY, -- Stripe event ID (evt_...)
+    type VARCHAR(100) NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- IAP purchase table
+-- App Store and Google Play subscriptions, each bound to the user who first
+-- submitted it. The latest receipt is kept to verify renewals with the
+-- store.
+CREATE TABLE iap_purchase (
+    provider VARCHAR(20) NOT NULL CHECK (provider IN ('apple', 'google')),
+    -- Apple original transaction ID or Google purchase token
+    purchase_id TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    product_id TEXT NOT NULL,
+    receipt TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('active', 'grace_period', 'billing_retry', 'expired', 'revoked')),
+    expires_at TIMESTAMPTZ NOT NULL,
+    grace_until TIMESTAMPTZ,
+    auto_renew BOOLEAN NOT NULL,
+    verified_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (provider, purchase_id)
+);
+
+CREATE INDEX idx_iap_purchase_user_id ON iap_purchase(user_id);
+-- Renewal checks look for live purchases whose paid or grace period ends
+CREATE INDEX idx_iap_purchase_renewal ON iap_purchase(COALESCE(grace_until, expires_at))
+    WHERE status IN ('active', 'grace_period', 'billing_retry');
@@ -1,7 +1,12 @@
 -- Brew table
--- Represents a type of coffee or specific brew configuration
+-- Represents a type of coffee or specific brew configuration. Partitioned by
+-- month on id: ULIDs begin with their creation time, so a range of IDs is a
+-- range of time, and id stays the primary key other tables reference. IDs
+-- are compared bytewise (COLLATE "C"), as ULIDs order. Partitions are
+-- created months ahead by ensure_brew_partition; should maintenance fall
+-- behind, brews no partition covers land in brew_default.
 CREATE TABLE brew (
-    id TEXT PRIMARY KEY, -- ULID format
+    id TEXT COLLATE "C" PRIMARY KEY, -- ULID format
     name VARCHAR(255) NOT NULL,
     brew_method VARCHAR(100) CHECK (
         brew_method IS NULL OR
@@ -15,11 +20,114 @@ CREATE TABLE brew (
     created_by TEXT REFERENCES "user"(id),
     is_public BOOLEAN DEFAULT true,
     created_at TIMESTAMPTZ DEFAULT NOW(),
-    updated_at TIMESTAMPTZ DEFAULT NOW()
-);
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    -- Recipe parameters, stored canonically in grams and degrees Celsius
+    dose_grams DOUBLE PRECISION CHECK (dose_grams IS NULL OR dose_grams > 0),
+    water_grams DOUBLE PRECISION CHECK (water_grams IS NULL OR water_grams > 0),
+    water_temp_c DOUBLE PRECISION CHECK (water_temp_c IS NULL OR (water_temp_c >= 0 AND water_temp_c <= 100)),
+    grind_setting VARCHAR(50),
+    brew_time_seconds INTEGER CHECK (brew_time_seconds IS NULL OR brew_time_seconds >= 0),
+    -- Timer session; long brews (cold brew) can run for hours or days
+    started_at TIMESTAMPTZ,
+    ended_at TIMESTAMPTZ,
+    remind_at TIMESTAMPTZ,
+    reminder_sent_at TIMESTAMPTZ,
+    CONSTRAINT brew_timer_check CHECK (ended_at IS NULL OR (started_at IS NOT NULL AND ended_at >= started_at)),
+    -- Recipe revision this brew was made with
+    recipe_id TEXT,
+    recipe_revision INTEGER,
+    CONSTRAINT brew_recipe_revision_fkey FOREIGN KEY (recipe_id, recipe_revision)
+        REFERENCES recipe_revision(recipe_id, revision) ON DELETE SET NULL,
+    CONSTRAINT brew_recipe_check CHECK ((recipe_id IS NULL) = (recipe_revision IS NULL)),
+    -- Bag of beans this brew used, for consumption forecasting
+    bean_bag_id TEXT REFERENCES bean_bag(id) ON DELETE SET NULL,
+    -- Values of the brewer's custom fields, keyed by field name
+    custom_fields JSONB NOT NULL DEFAULT '{}',
+    -- Offline sync: change sequence and revision vector (set by triggers)
+    sync_seq BIGINT NOT NULL DEFAULT 0,
+    sync_vector JSONB NOT NULL DEFAULT '{}',
+    -- Organization the brew was logged in; its foreign key is declared with
+    -- the organization table
+    organization_id TEXT
+) PARTITION BY RANGE (id);
+
+CREATE TABLE brew_default PARTITION OF brew DEFAULT;
 
 -- Indexes for common queries
 CREATE INDEX idx_brew_created_by ON brew(created_by);
 CREATE INDEX idx_brew_is_public ON brew(is_public);
 CREATE INDEX idx_brew_name ON brew(name);
 CREATE INDEX idx_brew_method ON brew(brew_method);
+CREATE INDEX idx_brew_recipe ON brew(recipe_id, recipe_revision);
+CREATE INDEX idx_brew_bean_bag ON brew(bean_bag_id, created_at);
+CREATE INDEX idx_brew_remind_at ON brew(remind_at) WHERE reminder_sent_at IS NULL;
+CREATE INDEX idx_brew_sync ON brew(created_by, sync_seq);
+CREATE INDEX idx_brew_created_at ON brew(created_at);
+
+-- Lowest ULID with the timestamp ts: its 48-bit millisecond time in
+-- Crockford base32, then zero randomness. Brew partitions are bounded by
+-- these.
+CREATE OR REPLACE FUNCTION ulid_floor(ts TIMESTAMPTZ)
+RETURNS TEXT AS $$
+DECLARE
+    alphabet CONSTANT TEXT := '0123456789ABCDEFGHJKMNPQRSTVWXYZ';
+    ms BIGINT := floor(extract(epoch FROM ts) * 1000);
+    encoded TEXT := '';
+BEGIN
+    FOR i IN 1..10 LOOP
+        encoded := substr(alphabet, (ms % 32)::int + 1, 1) || encoded;
+        ms := ms / 32;
+    END LOOP;
+    RETURN encoded || repeat('0', 16);
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- Creates the partition of brews created in the UTC month containing
+-- month, brew_pYYYY_MM, unless it exists. Returns whether it was created.
+-- Instances maintaining partitions at once are serialized by an advisory
+-- lock.
+CREATE OR REPLACE FUNCTION ensure_brew_partition(month DATE)
+RETURNS BOOLEAN AS $$
+DECLARE
+    month_start TIMESTAMPTZ := date_trunc('month', month::timestamp) AT TIME ZONE 'UTC';
+    partition_name TEXT := 'brew_p' || to_char(month, 'YYYY_MM');
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('brew_partition'));
+    IF to_regclass(partition_name) IS NOT NULL THEN
+        RETURN false;
+    END IF;
+    EXECUTE format('CREATE TABLE %I PARTITION OF brew FOR VALUES FROM (%L) TO (%L)',
+        partition_name, ulid_floor(month_start), ulid_floor(month_start + INTERVAL '1 month'));
+    RETURN true;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Detaches the brew partition brew_pYYYY_MM from brew and renames it
+-- detached_brew_pYYYY_MM, keeping its rows out of the table for operators
+-- to dump and drop. Fails with a foreign key violation while other rows
+-- (flavors, pour curves, posts, shares) still reference its brews. Returns
+-- the detached table's name.
+CREATE OR REPLACE FUNCTION detach_brew_partition(partition_name TEXT)
+RETURNS TEXT AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('brew_partition'));
+    EXECUTE format('ALTER TABLE brew DETACH PARTITION %I', partition_name);
+    EXECUTE format('ALTER TABLE %I RENAME TO %I', partition_name, 'detached_' || partition_name);
+    RETURN 'detached_' || partition_name;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Brew custom field table
+-- A field a user adds to their own brews, e.g. water hardness or filter
+-- paper. Values live in brew.custom_fields under the field's name; unit is
+-- a label, values aren't converted.
+CREATE TABLE brew_custom_field (
+    id TEXT PRIMARY KEY, -- ULID format
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('number', 'text', 'boolean')),
+    unit VARCHAR(20),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (user_id, name)
+);
@@ -0,0 +1,32 @@
+-- Brew archive table
+-- Cold storage for old brews, moved out of brew by the archive job. Each
+-- brew is kept whole as one JSONB document: the brew row, its flavor
+-- descriptors and its pour curve. Documents are compressed as soon as they
+-- outgrow toast_tuple_target, so even small brews are stored compressed.
+-- Archived brews are read-only; history reads them back through
+-- jsonb_populate_record, so columns added to brew later read as NULL.
+CREATE TABLE brew_archive (
+    id TEXT PRIMARY KEY, -- the brew's ULID
+    created_by TEXT REFERENCES "user"(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL,
+    archived_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- {"brew": {...}, "flavors": ["fruity.berry", ...], "pour_curve": {...} | null}
+    data JSONB NOT NULL
+) WITH (toast_tuple_target = 128);
+
+CREATE INDEX idx_brew_archive_created_by ON brew_archive(created_by, created_at DESC);
+
+-- A user's personal brews, live and archived, as brew rows. Organization
+-- brews are left out, as they're the organization's. Stats read brews
+-- through it so archiving doesn't change them; each side is read by its
+-- created_by index, so only the user's archived documents are decompressed.
+CREATE OR REPLACE FUNCTION user_brews(uid TEXT)
+RETURNS SETOF brew AS $$
+    SELECT * FROM brew
+    WHERE created_by = uid AND organization_id IS NULL
+    UNION ALL
+    SELECT ab.*
+    FROM brew_archive a
+    CROSS JOIN LATERAL jsonb_populate_record(NULL::brew, a.data->'brew') ab
+    WHERE a.created_by = uid
+$$ LANGUAGE sql STABLE;
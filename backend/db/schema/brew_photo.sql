@@ -0,0 +1,59 @@
+-- Brew photo table
+-- Photos attached to a brew by its owner. The upload is stored as
+-- original_key and queued: the photo processor claims pending photos,
+-- checks the sniffed content type, strips metadata, resizes the image and
+-- renders a thumbnail, and optionally screens it before marking it ready.
+-- With an upload scanner configured, originals are scanned for malware
+-- first; infected ones are quarantined, moving the original under
+-- quarantine/ and recording the threat as the error.
+-- Claiming a photo leases it until next_attempt_at, so a processor that
+-- dies doesn't strand it; failures are retried with backoff until the
+-- photo is marked failed.
+CREATE TABLE brew_photo (
+    id TEXT PRIMARY KEY, -- ULID format
+    brew_id TEXT NOT NULL REFERENCES brew(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'processing', 'ready', 'failed', 'rejected', 'quarantined')),
+    -- Type sniffed from the uploaded bytes, not the client's header
+    content_type VARCHAR(50) NOT NULL,
+    original_key TEXT NOT NULL,
+    original_bytes BIGINT NOT NULL,
+    -- Set once processed
+    image_key TEXT,
+    thumbnail_key TEXT,
+    width INTEGER,
+    height INTEGER,
+    image_bytes BIGINT,
+    thumbnail_bytes BIGINT,
+    -- Screening score in [0, 1] when a screener is configured
+    screening_score DOUBLE PRECISION,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    error TEXT,
+    processed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_brew_photo_brew ON brew_photo(brew_id, created_at);
+CREATE INDEX idx_brew_photo_user ON brew_photo(user_id);
+CREATE INDEX idx_brew_photo_due ON brew_photo(next_attempt_at, id)
+    WHERE status IN ('pending', 'processing');
+CREATE INDEX idx_brew_photo_quarantined ON brew_photo(processed_at DESC)
+    WHERE status = 'quarantined';
+
+-- Deleted photo object table
+-- Object keys of deleted photos, recorded by a trigger whenever a
+-- brew_photo row goes, including with its brew or owner. The photo
+-- processor sweeps them from the object store, so a failed delete or a
+-- cascade can't leave objects behind. Claiming a key leases it until
+-- next_attempt_at, and failed deletes are retried once the lease runs out.
+CREATE TABLE deleted_photo_object (
+    key TEXT PRIMARY KEY,
+    photo_id TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_deleted_photo_object_due ON deleted_photo_object(next_attempt_at, key);
@@ -0,0 +1,60 @@
+-- Club table
+-- A group of users, e.g. a local coffee club, with its own feed. Anyone can
+-- find and join a public club; private clubs are joined by invite link.
+CREATE TABLE club (
+    id TEXT PRIMARY KEY,
+    owner_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    is_private BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_club_owner_id ON club(owner_id);
+CREATE INDEX idx_club_public ON club(created_at DESC) WHERE NOT is_private;
+
+-- Club member table
+-- Membership and role: the owner manages the club and its moderators;
+-- moderators manage members, invites and the feed
+CREATE TABLE club_member (
+    club_id TEXT NOT NULL REFERENCES club(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'moderator', 'member')),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (club_id, user_id)
+);
+
+CREATE INDEX idx_club_member_user ON club_member(user_id);
+
+-- Club invite table
+-- Invite links. Only a SHA-256 hash of the link token is stored; the token
+-- itself is shown once, when the link is created.
+CREATE TABLE club_invite (
+    id TEXT PRIMARY KEY,
+    club_id TEXT NOT NULL REFERENCES club(id) ON DELETE CASCADE,
+    created_by TEXT REFERENCES "user"(id) ON DELETE SET NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    max_uses INTEGER CHECK (max_uses IS NULL OR max_uses > 0),
+    use_count INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_club_invite_club ON club_invite(club_id);
+
+-- Club share table
+-- A brew or recipe a member shared to the club's feed
+CREATE TABLE club_share (
+    id TEXT PRIMARY KEY,
+    club_id TEXT NOT NULL REFERENCES club(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    brew_id TEXT REFERENCES brew(id) ON DELETE CASCADE,
+    recipe_id TEXT REFERENCES recipe(id) ON DELETE CASCADE,
+    note TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    CONSTRAINT club_share_item_check CHECK ((brew_id IS NULL) <> (recipe_id IS NULL))
+);
+
+CREATE INDEX idx_club_share_feed ON club_share(club_id, created_at DESC);
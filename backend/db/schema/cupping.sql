@@ -0,0 +1,55 @@
+-- Cupping session table
+-- A blind tasting hosted by one user. Samples are shown to participants only
+-- by label until the host reveals the session, after which scores are locked.
+CREATE TABLE cupping_session (
+    id TEXT PRIMARY KEY,
+    host_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    notes TEXT,
+    revealed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_cupping_session_host_id ON cupping_session(host_id);
+
+-- Cupping sample table
+-- One coffee on the cupping table. Labels ('A', 'B', ...) are assigned in a
+-- shuffled order so they don't give away the order samples were entered in.
+CREATE TABLE cupping_sample (
+    id TEXT PRIMARY KEY,
+    session_id TEXT NOT NULL REFERENCES cupping_session(id) ON DELETE CASCADE,
+    label VARCHAR(10) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    roaster TEXT,
+    bean_origin TEXT,
+    bean_bag_id TEXT REFERENCES bean_bag(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (session_id, label)
+);
+
+-- Cupping participant table
+-- Users invited to score a session. The host scores without an invitation.
+CREATE TABLE cupping_participant (
+    session_id TEXT NOT NULL REFERENCES cupping_session(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (session_id, user_id)
+);
+
+CREATE INDEX idx_cupping_participant_user ON cupping_participant(user_id);
+
+-- Cupping score table
+-- One scorer's score for one sample, on the SCA 0-100 cupping scale. Scores
+-- are only visible to their scorer until the session is revealed.
+CREATE TABLE cupping_score (
+    sample_id TEXT NOT NULL REFERENCES cupping_sample(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL CHECK (score >= 0 AND score <= 100),
+    notes TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (sample_id, user_id)
+);
+
+CREATE INDEX idx_cupping_score_user ON cupping_score(user_id);
@@ -0,0 +1,65 @@
+-- TDS reading table
+-- Total dissolved solids measured by a refractometer and sent by its
+-- companion app. Readings attach to the brew whose timer session was running
+-- when they were taken; brew_id is NULL when none was.
+CREATE TABLE tds_reading (
+    id TEXT PRIMARY KEY, -- ULID format
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    brew_id TEXT REFERENCES brew(id) ON DELETE SET NULL,
+    api_key_id TEXT REFERENCES api_key(id) ON DELETE SET NULL,
+    -- Reading ID assigned by the companion app, so resent readings are
+    -- ignored
+    client_id VARCHAR(100),
+    tds_percent DOUBLE PRECISION NOT NULL CHECK (tds_percent > 0 AND tds_percent <= 30),
+    temperature_c DOUBLE PRECISION CHECK (temperature_c IS NULL OR (temperature_c >= 0 AND temperature_c <= 100)),
+    -- Weight of the measured beverage, for extraction yield
+    beverage_grams DOUBLE PRECISION CHECK (beverage_grams IS NULL OR beverage_grams > 0),
+    device_model VARCHAR(100),
+    measured_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    CONSTRAINT tds_reading_client_unique UNIQUE (user_id, client_id)
+);
+
+CREATE INDEX idx_tds_reading_brew ON tds_reading(brew_id, measured_at);
+CREATE INDEX idx_tds_reading_user ON tds_reading(user_id, measured_at DESC);
+
+-- Pour curve table
+-- Weight (and for espresso, pressure) over time streamed from a smart scale
+-- or machine bridge while a brew is poured. A brew has at most one curve;
+-- streaming a new one replaces it. Samples arrive as pour_sample rows and
+-- are compacted into the sample_* arrays, in time order, when the stream
+-- ends. sample_count, duration_ms and final_grams summarise the compacted
+-- samples.
+CREATE TABLE pour_curve (
+    id TEXT PRIMARY KEY, -- ULID format
+    brew_id TEXT NOT NULL UNIQUE REFERENCES brew(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    api_key_id TEXT REFERENCES api_key(id) ON DELETE SET NULL,
+    -- Stream ID assigned by the bridge, so a dropped stream can resume
+    client_id VARCHAR(100) NOT NULL,
+    device_model VARCHAR(100),
+    -- Wall-clock time of the first sample's t_ms = 0
+    started_at TIMESTAMPTZ NOT NULL,
+    sample_count INTEGER NOT NULL DEFAULT 0,
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    final_grams DOUBLE PRECISION,
+    sample_t_ms INTEGER[] NOT NULL DEFAULT '{}',
+    sample_grams REAL[] NOT NULL DEFAULT '{}',
+    -- NULL when no sample had a pressure; NaN for samples without one
+    sample_pressure_bar REAL[],
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    CONSTRAINT pour_curve_client_unique UNIQUE (user_id, client_id)
+);
+
+-- Pour sample table
+-- Samples of a stream in progress, compacted into pour_curve when it ends:
+-- grams on the scale t_ms milliseconds into the pour, and the pressure if
+-- the bridge reports one
+CREATE TABLE pour_sample (
+    curve_id TEXT NOT NULL REFERENCES pour_curve(id) ON DELETE CASCADE,
+    t_ms INTEGER NOT NULL CHECK (t_ms >= 0),
+    grams DOUBLE PRECISION NOT NULL,
+    pressure_bar DOUBLE PRECISION CHECK (pressure_bar IS NULL OR (pressure_bar >= 0 AND pressure_bar <= 20)),
+    PRIMARY KEY (curve_id, t_ms)
+);
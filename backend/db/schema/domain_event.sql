@@ -0,0 +1,21 @@
+-- Domain event table
+-- Outbox of domain events (user.registered, brew.created, recipe.forked),
+-- written by triggers in the same transaction as the change they describe.
+-- The event relay publishes them in id order, retrying with backoff until
+-- published_at is set.
+CREATE TABLE domain_event (
+    id BIGSERIAL PRIMARY KEY,
+    topic VARCHAR(64) NOT NULL,
+    aggregate_id TEXT NOT NULL, -- no foreign key: outlives the row
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    published_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_domain_event_due ON domain_event(next_attempt_at, id)
+    WHERE published_at IS NULL;
+CREATE INDEX idx_domain_event_published ON domain_event(published_at)
+    WHERE published_at IS NOT NULL;
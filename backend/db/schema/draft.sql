@@ -0,0 +1,18 @@
+-- Draft table
+-- A brew or recipe still being entered, autosaved as the user types so it
+-- survives the app closing. data holds the creation payload as sent so far,
+-- unvalidated; publishing validates it and creates the brew or recipe.
+CREATE TABLE draft (
+    id TEXT PRIMARY KEY, -- ULID format
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('brew', 'recipe')),
+    data JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    -- Offline sync: change sequence and revision vector (set by triggers)
+    sync_seq BIGINT NOT NULL DEFAULT 0,
+    sync_vector JSONB NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX idx_draft_user_kind ON draft(user_id, kind, updated_at DESC);
+CREATE INDEX idx_draft_sync ON draft(user_id, sync_seq);
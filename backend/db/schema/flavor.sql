@@ -0,0 +1,32 @@
+-- Flavor descriptor table
+-- The SCA coffee taster's flavor wheel as a tree: categories (depth 1),
+-- subcategories (depth 2) and specific descriptors (depth 3). IDs are dotted
+-- slug paths, e.g. 'fruity.berry.blueberry'. Rows are seeded by migration.
+CREATE TABLE flavor_descriptor (
+    id VARCHAR(100) PRIMARY KEY,
+    parent_id VARCHAR(100) REFERENCES flavor_descriptor(id),
+    name VARCHAR(100) NOT NULL,
+    depth INTEGER NOT NULL CHECK (depth BETWEEN 1 AND 3)
+);
+
+CREATE INDEX idx_flavor_descriptor_parent ON flavor_descriptor(parent_id);
+
+-- Brew flavor table
+-- Flavor wheel descriptors noted when tasting a brew
+CREATE TABLE brew_flavor (
+    brew_id TEXT NOT NULL REFERENCES brew(id) ON DELETE CASCADE,
+    descriptor_id VARCHAR(100) NOT NULL REFERENCES flavor_descriptor(id),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (brew_id, descriptor_id)
+);
+
+CREATE INDEX idx_brew_flavor_descriptor ON brew_flavor(descriptor_id);
+
+-- Bean bag flavor table
+-- Flavor wheel descriptors for a bag of beans, e.g. the roaster's notes
+CREATE TABLE bean_bag_flavor (
+    bean_bag_id TEXT NOT NULL REFERENCES bean_bag(id) ON DELETE CASCADE,
+    descriptor_id VARCHAR(100) NOT NULL REFERENCES flavor_descriptor(id),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (bean_bag_id, descriptor_id)
+);
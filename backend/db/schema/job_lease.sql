@@ -0,0 +1,12 @@
+-- Job lease table
+-- Which instance runs each singleton background job. The holder renews its
+-- lease while the job runs; once it expires, another instance may take it.
+CREATE TABLE job_lease (
+    name VARCHAR(100) PRIMARY KEY,
+    holder VARCHAR(64) NOT NULL,
+    acquired_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    -- Goes up each time the lease is taken, fencing out the writes of a job
+    -- that lost it
+    epoch BIGINT NOT NULL DEFAULT 1
+);
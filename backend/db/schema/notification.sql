@@ -4,9 +4,9 @@ CREATE TABLE notification (
     id TEXT PRIMARY KEY, -- ULID format
     recipient_user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
     actor_user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
-    type VARCHAR(50) NOT NULL CHECK (type IN ('like', 'comment', 'friend_request', 'tag', 'follow')),
+    type VARCHAR(50) NOT NULL CHECK (type IN ('like', 'comment', 'friend_request', 'tag', 'follow', 'brew_reminder', 'bean_reorder', 'badge_earned')),
     reference_id TEXT,
-    reference_type VARCHAR(50) CHECK (reference_type IN ('post', 'comment', 'friendship')),
+    reference_type VARCHAR(50) CHECK (reference_type IN ('post', 'comment', 'friendship', 'brew', 'bean_bag', 'challenge')),
     is_read BOOLEAN DEFAULT false,
     created_at TIMESTAMPTZ DEFAULT NOW()
 );
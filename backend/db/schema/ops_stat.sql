@@ -0,0 +1,37 @@
+-- Ops daily stat table
+-- One row per UTC day for the admin dashboard. The ops stats job recomputes
+-- signups, active users and brews for recent days; request counts are added
+-- by each instance as it flushes its counters.
+CREATE TABLE ops_daily_stat (
+    day DATE PRIMARY KEY,
+    signups INTEGER NOT NULL DEFAULT 0,
+    active_users INTEGER NOT NULL DEFAULT 0,
+    -- Distinct users active in the 7 days ending on day
+    weekly_active_users INTEGER NOT NULL DEFAULT 0,
+    brews_logged INTEGER NOT NULL DEFAULT 0,
+    requests BIGINT NOT NULL DEFAULT 0,
+    client_errors BIGINT NOT NULL DEFAULT 0,
+    server_errors BIGINT NOT NULL DEFAULT 0,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Ops method stat table
+-- Most logged brew methods over the last 30 days, replaced by each run of
+-- the ops stats job
+CREATE TABLE ops_method_stat (
+    brew_method VARCHAR(100) NOT NULL,
+    brew_count INTEGER NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Ops queue stat table
+-- Latest sample of each background queue's backlog
+CREATE TABLE ops_queue_stat (
+    queue VARCHAR(50) PRIMARY KEY,
+    depth BIGINT NOT NULL,
+    -- Items that gave up in the last 24 hours, or for queues that never
+    -- give up, pending items retrying after an error
+    failing BIGINT NOT NULL,
+    oldest_at TIMESTAMPTZ,
+    sampled_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
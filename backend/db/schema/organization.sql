@@ -0,0 +1,33 @@
+-- Organization table
+-- A café, roastery or training program whose members log brews together.
+-- Organization brews belong to the organization: only its members see them,
+-- through its routes, which scope every query to it.
+CREATE TABLE organization (
+    id TEXT PRIMARY KEY, -- ULID format
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('cafe', 'roastery', 'training_program')),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Organization member table
+-- Membership and role: owners manage the organization and its admins;
+-- admins manage members
+CREATE TABLE organization_member (
+    organization_id TEXT NOT NULL REFERENCES organization(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'admin', 'member')),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX idx_organization_member_user ON organization_member(user_id);
+
+-- Brews logged in an organization are deleted with it, and are never
+-- public, so no public listing can reach them
+ALTER TABLE brew
+    ADD CONSTRAINT brew_organization_fkey FOREIGN KEY (organization_id)
+        REFERENCES organization(id) ON DELETE CASCADE,
+    ADD CONSTRAINT brew_organization_private_check CHECK (organization_id IS NULL OR is_public = false);
+
+CREATE INDEX idx_brew_organization ON brew(organization_id, created_at DESC) WHERE organization_id IS NOT NULL;
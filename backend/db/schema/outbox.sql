@@ -0,0 +1,22 @@
+-- Outbox message table
+-- Notifications and webhook events waiting to be dispatched. Triggers write
+-- them in the same transaction as the change that causes them (see
+-- triggers.sql), so a crash can't lose one; the outbox dispatcher creates
+-- the notification or queues the webhook deliveries, retrying with backoff
+-- until dispatched_at or failed_at is set.
+CREATE TABLE outbox_message (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('notification', 'webhook')),
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    dispatched_at TIMESTAMPTZ,
+    failed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_outbox_message_due ON outbox_message(next_attempt_at, id)
+    WHERE dispatched_at IS NULL AND failed_at IS NULL;
+CREATE INDEX idx_outbox_message_dispatched ON outbox_message(dispatched_at)
+    WHERE dispatched_at IS NOT NULL;
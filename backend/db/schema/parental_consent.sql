@@ -0,0 +1,13 @@
+-- Parental consent table
+-- Consent requests sent to the parents of users who signed up under the
+-- minimum age. Only a SHA-256 hash of the emailed token is stored. Once
+-- granted, the row is kept as the record of consent.
+CREATE TABLE parental_consent (
+    user_id TEXT PRIMARY KEY REFERENCES "user"(id) ON DELETE CASCADE,
+    parent_email VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    granted_at TIMESTAMPTZ,
+    granted_ip VARCHAR(45),
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
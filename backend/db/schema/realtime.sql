@@ -0,0 +1,16 @@
+-- Realtime presence table
+-- Who is connected to each realtime topic: a row per open stream, keyed by
+-- an ID the instance serving it picks, so each stream's row comes and goes
+-- with it however a user's streams interleave. Instances refresh seen_at
+-- for their rows, so rows of an instance that died go stale and are pruned.
+CREATE TABLE realtime_presence (
+    connection_id VARCHAR(26) PRIMARY KEY,
+    topic VARCHAR(100) NOT NULL,
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    instance_id VARCHAR(64) NOT NULL,
+    seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_realtime_presence_topic ON realtime_presence(topic);
+CREATE INDEX idx_realtime_presence_instance_id ON realtime_presence(instance_id);
+CREATE INDEX idx_realtime_presence_seen_at ON realtime_presence(seen_at);
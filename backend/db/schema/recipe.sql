@@ -0,0 +1,59 @@
+-- Recipe table
+-- A reusable brew recipe; its parameters are versioned in recipe_revision
+CREATE TABLE recipe (
+    id TEXT PRIMARY KEY, -- ULID format
+    owner_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    brew_method VARCHAR(100) CHECK (
+        brew_method IS NULL OR
+        brew_method IN ('espresso', 'pour_over', 'french_press', 'aeropress',
+                       'cold_brew', 'drip', 'moka_pot', 'siphon', 'chemex',
+                       'v60', 'turkish', 'percolator', 'other')
+    ),
+    is_public BOOLEAN NOT NULL DEFAULT true,
+    current_revision INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    -- Provenance: the recipe and revision this one was forked from
+    forked_from_id TEXT REFERENCES recipe(id) ON DELETE SET NULL,
+    forked_from_revision INTEGER
+);
+
+-- Indexes for common queries
+CREATE INDEX idx_recipe_owner_id ON recipe(owner_id);
+CREATE INDEX idx_recipe_is_public ON recipe(is_public);
+CREATE INDEX idx_recipe_forked_from ON recipe(forked_from_id);
+
+-- Recipe revision table
+-- Immutable snapshot of a recipe's parameters (grams / Celsius). Edits and
+-- rollbacks append a revision, so brews pinned to an earlier one keep it.
+CREATE TABLE recipe_revision (
+    recipe_id TEXT NOT NULL REFERENCES recipe(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL CHECK (revision > 0),
+    dose_grams DOUBLE PRECISION CHECK (dose_grams IS NULL OR dose_grams > 0),
+    water_grams DOUBLE PRECISION CHECK (water_grams IS NULL OR water_grams > 0),
+    water_temp_c DOUBLE PRECISION CHECK (water_temp_c IS NULL OR (water_temp_c >= 0 AND water_temp_c <= 100)),
+    grind_setting VARCHAR(50),
+    brew_time_seconds INTEGER CHECK (brew_time_seconds IS NULL OR brew_time_seconds >= 0),
+    instructions TEXT,
+    changelog TEXT, -- what changed and why
+    rolled_back_from INTEGER, -- revision restored by a rollback
+    created_by TEXT REFERENCES "user"(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (recipe_id, revision)
+);
+
+-- Recipe collaborator table
+-- Users invited to co-maintain a recipe. Editors can add revisions and roll
+-- back; viewers can see private recipes. accepted_at is NULL while pending.
+CREATE TABLE recipe_collaborator (
+    recipe_id TEXT NOT NULL REFERENCES recipe(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('editor', 'viewer')),
+    invited_by TEXT REFERENCES "user"(id) ON DELETE SET NULL,
+    accepted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (recipe_id, user_id)
+);
+
+CREATE INDEX idx_recipe_collaborator_user ON recipe_collaborator(user_id, accepted_at);
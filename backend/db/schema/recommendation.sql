@@ -0,0 +1,34 @@
+-- Recommendation table
+-- Public recipes and catalogue beans suggested to a user, scored by the
+-- recommendation worker from the user's ratings and flavor notes and
+-- replaced on each run. item_id is a recipe or bean ID, by kind.
+CREATE TABLE recommendation (
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('recipe', 'bean')),
+    item_id TEXT NOT NULL,
+    score DOUBLE PRECISION NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_recommendation_user_score ON recommendation(user_id, kind, score DESC);
+
+-- Recommendation state table
+-- When each user's recommendations were last scored; the worker claims
+-- users whose scores are missing or stale by stamping computed_at
+CREATE TABLE recommendation_state (
+    user_id TEXT PRIMARY KEY REFERENCES "user"(id) ON DELETE CASCADE,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_recommendation_state_computed_at ON recommendation_state(computed_at);
+
+-- Recommendation dismissal table
+-- Recipes and beans a user marked "not interested"; never recommended to
+-- them again
+CREATE TABLE recommendation_dismissal (
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('recipe', 'bean')),
+    item_id TEXT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (user_id, kind, item_id)
+);
@@ -0,0 +1,13 @@
+-- Request nonce table
+-- Nonces of signed inbound requests (Stripe webhooks, device bridges), kept
+-- until their timestamp falls outside the clock-skew tolerance, after which
+-- the timestamp check rejects a replay on its own. Nonces are stored hashed,
+-- scoped by source so senders can't collide.
+CREATE TABLE request_nonce (
+    source VARCHAR(50) NOT NULL,
+    nonce_hash VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (source, nonce_hash)
+);
+
+CREATE INDEX idx_request_nonce_expires_at ON request_nonce(expires_at);
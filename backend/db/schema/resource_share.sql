@@ -0,0 +1,24 @@
+-- Resource share table
+-- Access control list entries granting one user, or every member of a
+-- club, read access to a brew or recipe its owner keeps private. Public
+-- items need no entries. Visibility is decided in the query layer: the
+-- GetVisible* queries return an item only to its owner, when it's public,
+-- or to a grantee listed here.
+CREATE TABLE resource_share (
+    id TEXT PRIMARY KEY, -- ULID format
+    brew_id TEXT REFERENCES brew(id) ON DELETE CASCADE,
+    recipe_id TEXT REFERENCES recipe(id) ON DELETE CASCADE,
+    user_id TEXT REFERENCES "user"(id) ON DELETE CASCADE,
+    club_id TEXT REFERENCES club(id) ON DELETE CASCADE,
+    created_by TEXT REFERENCES "user"(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    CONSTRAINT resource_share_item_check CHECK ((brew_id IS NULL) <> (recipe_id IS NULL)),
+    CONSTRAINT resource_share_grantee_check CHECK ((user_id IS NULL) <> (club_id IS NULL))
+);
+
+-- IDs are ULIDs, unique across tables, so the item and grantee IDs alone
+-- identify a share. Also serves listing an item's shares.
+CREATE UNIQUE INDEX idx_resource_share_item_grantee
+    ON resource_share (COALESCE(brew_id, recipe_id), COALESCE(user_id, club_id));
+CREATE INDEX idx_resource_share_user ON resource_share(user_id) WHERE user_id IS NOT NULL;
+CREATE INDEX idx_resource_share_club ON resource_share(club_id) WHERE club_id IS NOT NULL;
@@ -0,0 +1,17 @@
+-- Roaster table
+-- A roaster or coffee brand account, managed by one user. Verified roasters
+-- (checked by an admin) can publish official bean listings and recipes.
+CREATE TABLE roaster (
+    id TEXT PRIMARY KEY, -- ULID format
+    user_id TEXT NOT NULL UNIQUE REFERENCES "user"(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    website TEXT,
+    location TEXT,
+    description TEXT,
+    verified_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Roaster names are unique case-insensitively, so brands can't be impersonated
+CREATE UNIQUE INDEX idx_roaster_name_lower ON roaster(LOWER(name));
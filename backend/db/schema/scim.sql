@@ -0,0 +1,26 @@
+-- SCIM client table
+-- Identity systems of barista-training programs that provision student
+-- accounts over SCIM. Admins register them; each authenticates with a
+-- bearer token, of which only a SHA-256 hash is stored.
+CREATE TABLE scim_client (
+    id TEXT PRIMARY KEY, -- ULID format
+    name VARCHAR(100) NOT NULL,
+    token_prefix VARCHAR(16) NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    last_used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- SCIM user table
+-- The accounts each client provisioned, which are the only ones it can
+-- see or change, with the ID its identity system knows them by. Deleting
+-- a client leaves its accounts as ordinary ones.
+CREATE TABLE scim_user (
+    user_id TEXT PRIMARY KEY REFERENCES "user"(id) ON DELETE CASCADE,
+    client_id TEXT NOT NULL REFERENCES scim_client(id) ON DELETE CASCADE,
+    external_id TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (client_id, external_id)
+);
+
+CREATE INDEX idx_scim_user_client ON scim_user(client_id, created_at);
@@ -0,0 +1,31 @@
+-- Step-up challenge table
+-- Logins held back because they look suspicious, until the user proves
+-- it's them with a code: emailed (its hash is stored) or from their TOTP
+-- app. A challenge is single use and allows a few wrong codes.
+CREATE TABLE step_up_challenge (
+    id TEXT PRIMARY KEY, -- ULID format
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    method VARCHAR(10) NOT NULL CHECK (method IN ('email', 'totp')),
+    code_hash VARCHAR(64), -- email only
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_step_up_challenge_user ON step_up_challenge(user_id);
+
+-- Trusted device table
+-- Devices a user chose to trust after passing a step-up challenge. The
+-- device keeps a random token (its hash is stored) and sends it at login
+-- to skip step-up until it expires.
+CREATE TABLE trusted_device (
+    id TEXT PRIMARY KEY, -- ULID format
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    user_agent TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_trusted_device_user ON trusted_device(user_id, created_at DESC);
@@ -0,0 +1,20 @@
+-- Sync sequence
+-- Orders every change to records the mobile client syncs offline. Each
+-- synced row's sync_seq is the sequence value of its last change, so a
+-- client pulls everything after the highest value it has seen.
+CREATE SEQUENCE sync_seq;
+
+-- Sync tombstone table
+-- Records deletions of synced rows so clients that were offline learn about
+-- them on their next pull. vector is the deleted row's revision vector.
+CREATE TABLE sync_tombstone (
+    resource VARCHAR(20) NOT NULL,
+    record_id TEXT NOT NULL,
+    user_id TEXT NOT NULL, -- no foreign key: outlives the user's rows
+    vector JSONB NOT NULL DEFAULT '{}',
+    seq BIGINT NOT NULL DEFAULT nextval('sync_seq'),
+    deleted_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (resource, record_id)
+);
+
+CREATE INDEX idx_sync_tombstone_user ON sync_tombstone(user_id, seq);
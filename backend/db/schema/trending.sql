@@ -0,0 +1,22 @@
+-- Trending item table
+-- Trending public recipes and beans per rolling period, ranked by the
+-- discover worker and replaced wholesale on each run, so discovery reads a
+-- small precomputed list. Beans are roaster/origin pairs as brews record
+-- them (recipe_id is NULL); recipes leave roaster and bean_origin NULL.
+CREATE TABLE trending_item (
+    period VARCHAR(10) NOT NULL CHECK (period IN ('day', 'week', 'month')),
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('recipe', 'bean')),
+    rank INTEGER NOT NULL CHECK (rank > 0),
+    recipe_id TEXT REFERENCES recipe(id) ON DELETE CASCADE,
+    roaster TEXT,
+    bean_origin TEXT,
+    -- Public brews logged and public post ratings given within the period
+    brew_count INTEGER NOT NULL,
+    rating_count INTEGER NOT NULL,
+    avg_rating DOUBLE PRECISION,
+    score DOUBLE PRECISION NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT trending_item_kind_check CHECK ((kind = 'recipe') = (recipe_id IS NOT NULL))
+);
+
+CREATE INDEX idx_trending_item_period ON trending_item(period, kind, rank);
@@ -41,6 +41,96 @@ BEFORE UPDATE ON brew
 FOR EACH ROW
 EXECUTE FUNCTION update_updated_at_column();
 
+-- Brew custom field table
+CREATE TRIGGER update_brew_custom_field_updated_at
+BEFORE UPDATE ON brew_custom_field
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Roaster table
+CREATE TRIGGER update_roaster_updated_at
+BEFORE UPDATE ON roaster
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Bean table
+CREATE TRIGGER update_bean_updated_at
+BEFORE UPDATE ON bean
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Bean bag table
+CREATE TRIGGER update_bean_bag_updated_at
+BEFORE UPDATE ON bean_bag
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Pour curve table
+CREATE TRIGGER update_pour_curve_updated_at
+BEFORE UPDATE ON pour_curve
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Automation webhook table
+CREATE TRIGGER update_automation_webhook_updated_at
+BEFORE UPDATE ON automation_webhook
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Recipe table
+CREATE TRIGGER update_recipe_updated_at
+BEFORE UPDATE ON recipe
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Draft table
+CREATE TRIGGER update_draft_updated_at
+BEFORE UPDATE ON draft
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Cupping session table
+CREATE TRIGGER update_cupping_session_updated_at
+BEFORE UPDATE ON cupping_session
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Cupping score table
+CREATE TRIGGER update_cupping_score_updated_at
+BEFORE UPDATE ON cupping_score
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Brew event table
+CREATE TRIGGER update_brew_event_updated_at
+BEFORE UPDATE ON brew_event
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Brew event RSVP table
+CREATE TRIGGER update_brew_event_rsvp_updated_at
+BEFORE UPDATE ON brew_event_rsvp
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Club table
+CREATE TRIGGER update_club_updated_at
+BEFORE UPDATE ON club
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Organization table
+CREATE TRIGGER update_organization_updated_at
+BEFORE UPDATE ON organization
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Challenge table
+CREATE TRIGGER update_challenge_updated_at
+BEFORE UPDATE ON challenge
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
 -- Comment table
 CREATE TRIGGER update_comment_updated_at
 BEFORE UPDATE ON comment
@@ -54,19 +144,339 @@ FOR EACH ROW
 EXECUTE FUNCTION update_updated_at_column();
 
 
+-- ----------------------------------------------------------------------------
+-- OFFLINE SYNC TRIGGERS
+-- ----------------------------------------------------------------------------
+-- A user's changes take sync_seq values in commit order: each transaction
+-- changing a user's synced rows waits for the user's earlier ones to
+-- finish, so a pull's cursor never passes a change that commits after it.
+CREATE OR REPLACE FUNCTION sync_lock(owner TEXT)
+RETURNS VOID AS $$
+BEGIN
+    IF owner IS NOT NULL THEN
+        PERFORM pg_advisory_xact_lock(hashtext('sync:' || owner));
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Every insert or update of a synced row takes the next sync_seq value so
+-- pulls see it. A change that leaves sync_vector alone was made by the
+-- server (not a sync push), so the vector's "server" counter is bumped;
+-- pushes write the vector they resolved themselves. Argument: the column
+-- holding the row's owner.
+
+CREATE OR REPLACE FUNCTION sync_track()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM sync_lock(to_jsonb(NEW)->>TG_ARGV[0]);
+    NEW.sync_seq = nextval('sync_seq');
+    IF TG_OP = 'INSERT' THEN
+        IF NEW.sync_vector = '{}'::jsonb THEN
+            NEW.sync_vector = '{"server": 1}'::jsonb;
+        END IF;
+    ELSIF NEW.sync_vector = OLD.sync_vector THEN
+        NEW.sync_vector = jsonb_set(OLD.sync_vector, '{server}',
+            to_jsonb(COALESCE((OLD.sync_vector->>'server')::bigint, 0) + 1));
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Deleting a synced row leaves a tombstone. Brews the archive job moved to
+-- brew_archive in the same statement are left without one, so offline
+-- clients keep them. Arguments: the resource name and the column holding
+-- the row's owner.
+CREATE OR REPLACE FUNCTION sync_tombstone()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_ARGV[0] = 'brew' AND EXISTS (SELECT 1 FROM brew_archive WHERE id = OLD.id) THEN
+        RETURN OLD;
+    END IF;
+    PERFORM sync_lock(to_jsonb(OLD)->>TG_ARGV[1]);
+    INSERT INTO sync_tombstone (resource, record_id, user_id, vector)
+    VALUES (TG_ARGV[0], OLD.id, to_jsonb(OLD)->>TG_ARGV[1], OLD.sync_vector)
+    ON CONFLICT (resource, record_id) DO UPDATE
+    SET vector = EXCLUDED.vector, seq = nextval('sync_seq'), deleted_at = NOW();
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Brew table
+CREATE TRIGGER sync_track_brew
+BEFORE INSERT OR UPDATE ON brew
+FOR EACH ROW
+EXECUTE FUNCTION sync_track('created_by');
+
+CREATE TRIGGER sync_tombstone_brew
+AFTER DELETE ON brew
+FOR EACH ROW
+WHEN (OLD.created_by IS NOT NULL)
+EXECUTE FUNCTION sync_tombstone('brew', 'created_by');
+
+-- Draft table
+CREATE TRIGGER sync_track_draft
+BEFORE INSERT OR UPDATE ON draft
+FOR EACH ROW
+EXECUTE FUNCTION sync_track('user_id');
+
+CREATE TRIGGER sync_tombstone_draft
+AFTER DELETE ON draft
+FOR EACH ROW
+EXECUTE FUNCTION sync_tombstone('draft', 'user_id');
+
+-- ----------------------------------------------------------------------------
+-- DOMAIN EVENT TRIGGERS
+-- ----------------------------------------------------------------------------
+-- Inserts write a domain event to the outbox in the same transaction, so an
+-- event is recorded exactly when its change commits. Arguments: the topic,
+-- then the columns to publish; anything private (password hashes, emails)
+-- is left out.
+
+CREATE OR REPLACE FUNCTION record_domain_event()
+RETURNS TRIGGER AS $$
+DECLARE
+    row_data JSONB := to_jsonb(NEW);
+BEGIN
+    INSERT INTO domain_event (topic, aggregate_id, payload)
+    SELECT TG_ARGV[0], NEW.id, jsonb_object_agg(col, row_data->col)
+    FROM unnest(TG_ARGV[1:]) AS col;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- User table
+CREATE TRIGGER domain_event_user_registered
+AFTER INSERT ON "user"
+FOR EACH ROW
+EXECUTE FUNCTION record_domain_event('user.registered', 'id', 'username', 'created_at');
+
+-- Brew table
+CREATE TRIGGER domain_event_brew_created
+AFTER INSERT ON brew
+FOR EACH ROW
+EXECUTE FUNCTION record_domain_event('brew.created', 'id', 'created_by', 'brew_method', 'recipe_id',
+    'recipe_revision', 'bean_bag_id', 'is_public', 'created_at');
+
+-- Recipe table
+CREATE TRIGGER domain_event_recipe_forked
+AFTER INSERT ON recipe
+FOR EACH ROW
+WHEN (NEW.forked_from_id IS NOT NULL)
+EXECUTE FUNCTION record_domain_event('recipe.forked', 'id', 'owner_id', 'forked_from_id',
+    'forked_from_revision', 'is_public', 'created_at');
+
+-- ----------------------------------------------------------------------------
+-- OUTBOX TRIGGERS
+-- ----------------------------------------------------------------------------
+-- Changes that notify a user or fire their automation webhooks write an
+-- outbox message in the same transaction; the outbox dispatcher delivers it.
+
+-- Notifies a user about a row. Arguments: the notification type, the
+-- reference type, the column holding the user (who is also the actor) and
+-- the column holding the reference.
+CREATE OR REPLACE FUNCTION outbox_notify()
+RETURNS TRIGGER AS $$
+DECLARE
+    row_data JSONB := to_jsonb(NEW);
+BEGIN
+    INSERT INTO outbox_message (kind, payload)
+    VALUES ('notification', jsonb_build_object(
+        'recipient_user_id', row_data->>TG_ARGV[2],
+        'actor_user_id', row_data->>TG_ARGV[2],
+        'type', TG_ARGV[0],
+        'reference_type', TG_ARGV[1],
+        'reference_id', row_data->>TG_ARGV[3]
+    ));
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Fires the brew owner's webhooks when a brew is logged and when its timer
+-- starts or stops
+CREATE OR REPLACE FUNCTION outbox_brew_webhook()
+RETURNS TRIGGER AS $$
+DECLARE
+    webhook_event TEXT;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        webhook_event := 'brew.logged';
+    ELSIF NEW.started_at IS DISTINCT FROM OLD.started_at
+        AND NEW.started_at IS NOT NULL AND NEW.ended_at IS NULL THEN
+        webhook_event := 'brew.started';
+    ELSIF OLD.started_at IS NOT NULL AND OLD.ended_at IS NULL AND NEW.ended_at IS NOT NULL THEN
+        webhook_event := 'brew.stopped';
+    ELSE
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO outbox_message (kind, payload)
+    VALUES ('webhook', jsonb_build_object(
+        'user_id', NEW.created_by,
+        'event', webhook_event,
+        'occurred_at', NOW(),
+        'brew_id', NEW.id,
+        'brew_name', NEW.name,
+        'brew_method', NEW.brew_method,
+        'brew_time_seconds', NEW.brew_time_seconds
+    ));
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Brew table
+CREATE TRIGGER outbox_brew_logged
+AFTER INSERT ON brew
+FOR EACH ROW
+WHEN (NEW.created_by IS NOT NULL)
+EXECUTE FUNCTION outbox_brew_webhook();
+
+CREATE TRIGGER outbox_brew_timer
+AFTER UPDATE OF started_at, ended_at ON brew
+FOR EACH ROW
+WHEN (NEW.created_by IS NOT NULL)
+EXECUTE FUNCTION outbox_brew_webhook();
+
+CREATE TRIGGER outbox_brew_reminder
+AFTER UPDATE OF reminder_sent_at ON brew
+FOR EACH ROW
+WHEN (OLD.reminder_sent_at IS NULL AND NEW.reminder_sent_at IS NOT NULL AND NEW.created_by IS NOT NULL)
+EXECUTE FUNCTION outbox_notify('brew_reminder', 'brew', 'created_by', 'id');
+
+-- Bean bag table
+CREATE TRIGGER outbox_bean_reorder
+AFTER UPDATE OF reorder_notified_at ON bean_bag
+FOR EACH ROW
+WHEN (OLD.reorder_notified_at IS NULL AND NEW.reorder_notified_at IS NOT NULL)
+EXECUTE FUNCTION outbox_notify('bean_reorder', 'bean_bag', 'owner_id', 'id');
+
+-- User badge table
+CREATE TRIGGER outbox_badge_earned
+AFTER INSERT ON user_badge
+FOR EACH ROW
+EXECUTE FUNCTION outbox_notify('badge_earned', 'challenge', 'user_id', 'challenge_id');
+
+-- ----------------------------------------------------------------------------
+-- STORAGE TRIGGERS
+-- ----------------------------------------------------------------------------
+-- Keep user_storage in step with the objects users' rows account for
+
+-- Adds the change in a photo's bytes to its owner's storage. Deletes only
+-- update an existing row, since a photo deleted with its owner has no
+-- storage row left to update.
+CREATE OR REPLACE FUNCTION track_photo_storage()
+RETURNS TRIGGER AS $$
+DECLARE
+    owner_id TEXT;
+    delta BIGINT := 0;
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        owner_id := OLD.user_id;
+        delta := delta - (OLD.original_bytes + COALESCE(OLD.image_bytes, 0) + COALESCE(OLD.thumbnail_bytes, 0));
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        owner_id := NEW.user_id;
+        delta := delta + (NEW.original_bytes + COALESCE(NEW.image_bytes, 0) + COALESCE(NEW.thumbnail_bytes, 0));
+    END IF;
+    IF delta = 0 THEN
+        RETURN NULL;
+    END IF;
+
+    UPDATE user_storage
+    SET photo_bytes = photo_bytes + delta, updated_at = NOW()
+    WHERE user_id = owner_id;
+    IF NOT FOUND AND TG_OP <> 'DELETE' THEN
+        INSERT INTO user_storage (user_id, photo_bytes)
+        VALUES (owner_id, delta)
+        ON CONFLICT (user_id) DO UPDATE
+        SET photo_bytes = user_storage.photo_bytes + EXCLUDED.photo_bytes, updated_at = NOW();
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Brew photo table
+CREATE TRIGGER track_brew_photo_storage
+AFTER INSERT OR DELETE OR UPDATE OF original_bytes, image_bytes, thumbnail_bytes ON brew_photo
+FOR EACH ROW
+EXECUTE FUNCTION track_photo_storage();
+
+-- ----------------------------------------------------------------------------
+-- Record the objects of deleted photos for the photo processor to sweep
+
+-- Records a deleted photo's original and renditions. Runs for photos
+-- deleted with their brew or owner too, which no handler sees.
+CREATE OR REPLACE FUNCTION record_deleted_photo_objects()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO deleted_photo_object (key, photo_id)
+    SELECT k, OLD.id
+    FROM unnest(ARRAY[OLD.original_key, OLD.image_key, OLD.thumbnail_key]) AS k
+    WHERE k IS NOT NULL
+    ON CONFLICT (key) DO NOTHING;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Brew photo table
+CREATE TRIGGER record_deleted_brew_photo_objects
+AFTER DELETE ON brew_photo
+FOR EACH ROW
+EXECUTE FUNCTION record_deleted_photo_objects();
+
 -- ----------------------------------------------------------------------------
 -- NOTES
 -- ----------------------------------------------------------------------------
 -- This trigger must be applied AFTER creating all tables
 -- Recommended schema creation order:
 --   1. user.sql
---   2. brew.sql
---   3. post.sql
---   4. media.sql
---   5. comment.sql
---   6. user_friendships.sql
---   7. post_likes.sql
---   8. comment_likes.sql
---   9. post_user_tags.sql
---  10. notification.sql
---  11. triggers.sql (this file)
+--   2. api_key.sql
+--   3. roaster.sql
+--   4. recipe.sql
+--   5. bean.sql
+--   6. brew.sql
+--   7. device.sql
+--   8. automation.sql
+--   9. flavor.sql
+--  10. cupping.sql
+--  11. brew_event.sql
+--  12. club.sql
+--  13. badge.sql
+--  14. post.sql
+--  15. media.sql
+--  16. comment.sql
+--  17. user_friendships.sql
+--  18. post_likes.sql
+--  19. comment_likes.sql
+--  20. post_user_tags.sql
+--  21. notification.sql
+--  22. draft.sql
+--  23. trending.sql
+--  24. recommendation.sql
+--  25. analytics_event.sql
+--  26. domain_event.sql
+--  27. outbox.sql
+--  28. ops_stat.sql
+--  29. billing.sql
+--  30. email_suppression.sql
+--  31. invite.sql
+--  32. waitlist.sql
+--  33. email_domain.sql
+--  34. auth_event.sql
+--  35. step_up.sql
+--  36. sync.sql
+--  37. oidc.sql
+--  38. scim.sql
+--  39. policy.sql
+--  40. parental_consent.sql
+--  41. resource_share.sql
+--  42. request_nonce.sql
+--  43. brew_archive.sql
+--  44. backup.sql
+--  45. organization.sql
+--  46. realtime.sql
+--  47. job_lease.sql
+--  48. brew_photo.sql
+--  49. user_storage.sql
+--  50. media_migration.sql
+--  51. row_security.sql
+--  52. triggers.sql (this file)
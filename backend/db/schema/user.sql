@@ -10,11 +10,61 @@ CREATE TABLE "user" (
     location TEXT,
     joined_at TIMESTAMPTZ DEFAULT NOW(),
     created_at TIMESTAMPTZ DEFAULT NOW(),
-    updated_at TIMESTAMPTZ DEFAULT NOW()
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    -- Display units for brew values (storage is always metric)
+    weight_unit VARCHAR(10) NOT NULL DEFAULT 'g' CHECK (weight_unit IN ('g', 'oz')),
+    temperature_unit VARCHAR(10) NOT NULL DEFAULT 'c' CHECK (temperature_unit IN ('c', 'f')),
+    -- IANA timezone used for day/week boundaries in stats and streaks
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    -- ISO 4217 currency new bean prices default to
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+    -- Signed into the calendar feed URL; bumping it revokes the old URL
+    calendar_feed_version INTEGER NOT NULL DEFAULT 1,
+    -- Opt-in to client analytics events; without it they're dropped
+    analytics_consent BOOLEAN NOT NULL DEFAULT false,
+    -- Plan tier, which sets the user's quotas (see internal/plans)
+    plan VARCHAR(20) NOT NULL DEFAULT 'free' CHECK (plan IN ('free', 'supporter')),
+    -- Billing: the user's Stripe customer, created at their first checkout,
+    -- and the state of their supporter subscription as last reported by
+    -- its provider (Stripe, or an app store; see iap_purchase)
+    stripe_customer_id TEXT UNIQUE,
+    subscription_provider VARCHAR(20) CHECK (subscription_provider IN ('stripe', 'apple', 'google')),
+    subscription_id TEXT,
+    subscription_status VARCHAR(30),
+    subscription_period_end TIMESTAMPTZ,
+    subscription_cancel_at_period_end BOOLEAN NOT NULL DEFAULT false,
+    -- When the provider event the subscription state came from happened;
+    -- older events arriving late are ignored
+    subscription_event_at TIMESTAMPTZ,
+    -- Referral: the invite the user signed up with and who created it
+    invite_code VARCHAR(16),
+    invited_by TEXT REFERENCES "user"(id) ON DELETE SET NULL,
+    -- Registered with a disposable email domain, in flag mode
+    disposable_email BOOLEAN NOT NULL DEFAULT false,
+    -- TOTP second factor for step-up challenges: the base32 secret, set at
+    -- enrollment and enabled once a code from it is confirmed, and the last
+    -- time step used, so codes can't be replayed
+    totp_secret VARCHAR(64),
+    totp_enabled_at TIMESTAMPTZ,
+    totp_last_step BIGINT,
+    -- Set when a provisioning system deactivates the account (see
+    -- scim_user); a deactivated user can't log in
+    deactivated_at TIMESTAMPTZ,
+    -- Collected at registration where an age gate applies
+    birth_date DATE,
+    -- Set for users who signed up under the minimum age until a parent
+    -- consents (see parental_consent); they can't log in until then
+    consent_pending BOOLEAN NOT NULL DEFAULT FALSE
 );
 
 -- Indexes for common queries
 CREATE INDEX idx_user_username ON "user"(username);
 CREATE INDEX idx_user_email ON "user"(email);
 CREATE INDEX idx_user_joined_at ON "user"(joined_at);
+CREATE INDEX idx_user_invited_by ON "user"(invited_by, joined_at DESC) WHERE invited_by IS NOT NULL;
+CREATE INDEX idx_user_disposable_email ON "user"(joined_at DESC) WHERE disposable_email;
+
+-- Usernames and emails are unique case-insensitively
+CREATE UNIQUE INDEX idx_user_username_lower ON "user"(LOWER(username));
+CREATE UNIQUE INDEX idx_user_email_lower ON "user"(LOWER(email));
 
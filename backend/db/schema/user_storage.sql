@@ -0,0 +1,58 @@
+-- User storage table
+-- Bytes each user keeps in the object store, for storage quotas: a photo's
+-- original and renditions. Triggers on brew_photo keep it current (see
+-- triggers.sql); users without a row store nothing.
+CREATE TABLE user_storage (
+    user_id TEXT PRIMARY KEY REFERENCES "user"(id) ON DELETE CASCADE,
+    photo_bytes BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_user_storage_photo_bytes ON user_storage(photo_bytes DESC);
+
+-- Storage reservation table
+-- Bytes set aside for uploads in flight, so concurrent uploads can't
+-- together exceed a quota each fits under alone. An upload reserves its
+-- size before storing the object and releases it once its row counts the
+-- bytes or it fails; reservations of a server that died lapse at
+-- expires_at.
+CREATE TABLE storage_reservation (
+    id TEXT PRIMARY KEY, -- ULID format
+    user_id TEXT NOT NULL REFERENCES "user"(id) ON DELETE CASCADE,
+    bytes BIGINT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_storage_reservation_user ON storage_reservation(user_id);
+
+-- Reserves size_bytes of uid's storage as reservation unless what they
+-- store, what they have reserved and size_bytes would exceed limit_bytes, returning
+-- whether it did. The user's storage row is locked first, so concurrent
+-- reservations and photo changes for one user run one at a time, and each
+-- statement after the lock sees the reservations the last committed.
+CREATE OR REPLACE FUNCTION reserve_storage(uid TEXT, reservation TEXT, size_bytes BIGINT, limit_bytes BIGINT, ttl_seconds INTEGER)
+RETURNS BOOLEAN AS $$
+DECLARE
+    stored BIGINT;
+    reserved BIGINT;
+BEGIN
+    INSERT INTO user_storage (user_id) VALUES (uid)
+    ON CONFLICT (user_id) DO NOTHING;
+    SELECT s.photo_bytes INTO stored
+    FROM user_storage s
+    WHERE s.user_id = uid
+    FOR NO KEY UPDATE;
+
+    DELETE FROM storage_reservation r WHERE r.user_id = uid AND r.expires_at <= NOW();
+    SELECT COALESCE(SUM(r.bytes), 0) INTO reserved
+    FROM storage_reservation r
+    WHERE r.user_id = uid;
+    IF stored + reserved + size_bytes > limit_bytes THEN
+        RETURN false;
+    END IF;
+
+    INSERT INTO storage_reservation (id, user_id, bytes, expires_at)
+    VALUES (reservation, uid, size_bytes, NOW() + ttl_seconds * INTERVAL '1 second');
+    RETURN true;
+END;
+$$ LANGUAGE plpgsql;
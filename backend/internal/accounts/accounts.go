@@ -0,0 +1,88 @@
+// Package accounts tracks whether users' accounts are still active, so
+// tokens issued before an account was deactivated or deleted stop being
+// accepted.
+package accounts
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/logger"
+)
+
+// cacheTTL is how long a user's state is cached, and so how long a token
+// can keep working on other instances after the account is deactivated
+const cacheTTL = 30 * time.Second
+
+type cachedActive struct {
+	active    bool
+	expiresAt time.Time
+}
+
+// Cache looks up whether users' accounts are active, caching each answer
+// for cacheTTL so authentication doesn't cost a query per request
+type Cache struct {
+	queries *db.Queries
+
+	mu     sync.Mutex
+	users  map[string]cachedActive
+	lastGC time.Time
+}
+
+func NewCache(queries *db.Queries) *Cache {
+	return &Cache{
+		queries: queries,
+		users:   make(map[string]cachedActive),
+		lastGC:  time.Now(),
+	}
+}
+
+// Active reports whether userID exists and hasn't been deactivated. If it
+// can't be looked up the user is let through uncached, rather than failing
+// every request while the database is unreachable.
+func (c *Cache) Active(ctx context.Context, userID string) bool {
+	now := time.Now()
+
+	c.mu.Lock()
+	cached, ok := c.users[userID]
+	c.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.active
+	}
+
+	active, err := c.queries.IsUserActive(ctx, userID)
+	if err != nil {
+		logger.Warn("Failed to check whether user is active", "user_id", userID, "error", err)
+		return true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.collectGarbage(now)
+	c.users[userID] = cachedActive{active: active, expiresAt: now.Add(cacheTTL)}
+	return active
+}
+
+// Forget drops userID's cached answer, for when their account is
+// deactivated, reactivated or deleted
+func (c *Cache) Forget(userID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.users, userID)
+}
+
+// collectGarbage drops expired answers
+func (c *Cache) collectGarbage(now time.Time) {
+	if now.Sub(c.lastGC) < time.Minute {
+		return
+	}
+	c.lastGC = now
+
+	for userID, cached := range c.users {
+		if now.After(cached.expiresAt) {
+			delete(c.users, userID)
+		}
+	}
+}
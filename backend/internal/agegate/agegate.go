@@ -0,0 +1,121 @@
+package agegate
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"brewd/internal/links"
+	"brewd/internal/mail"
+)
+
+// DateLayout is the format of birth dates in requests
+const DateLayout = "2006-01-02"
+
+// maxAge is the oldest age a birth date is believed for
+const maxAge = 130
+
+// ConsentTTL is how long a parent has to answer a consent request
+const ConsentTTL = 7 * 24 * time.Hour
+
+// Gate holds the minimum age to sign up, which can differ by country, e.g.
+// where the age of digital consent is 16 rather than 13. Users under it
+// need a parent's consent, or with ParentalConsent off, can't sign up.
+type Gate struct {
+	DefaultAge      int
+	RegionAges      map[string]int
+	ParentalConsent bool
+}
+
+// New returns the gate for defaultAge and regions, entries of the form
+// "DE=16" keyed by ISO 3166-1 country code. A zero defaultAge and no
+// regions turn the gate off.
+func New(defaultAge int, regions []string, parentalConsent bool) (Gate, error) {
+	gate := Gate{DefaultAge: defaultAge, RegionAges: make(map[string]int), ParentalConsent: parentalConsent}
+	for _, region := range regions {
+		country, age, ok := strings.Cut(region, "=")
+		country = strings.ToUpper(strings.TrimSpace(country))
+		if !ok || len(country) != 2 {
+			return Gate{}, fmt.Errorf("region %q must look like DE=16", region)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(age))
+		if err != nil || n < 0 {
+			return Gate{}, fmt.Errorf("region %q has an invalid age", region)
+		}
+		gate.RegionAges[country] = n
+	}
+	return gate, nil
+}
+
+// Enabled reports whether signups need a birth date
+func (g Gate) Enabled() bool {
+	return g.DefaultAge > 0 || len(g.RegionAges) > 0
+}
+
+// MinimumAge returns the minimum age in country, or the default where the
+// country is unknown or has none of its own
+func (g Gate) MinimumAge(country string) int {
+	if age, ok := g.RegionAges[strings.ToUpper(country)]; ok {
+		return age
+	}
+	return g.DefaultAge
+}
+
+// ParseBirthDate parses a birth date, rejecting dates in the future or
+// implausibly long ago
+func ParseBirthDate(raw string, now time.Time) (time.Time, bool) {
+	birthDate, err := time.Parse(DateLayout, raw)
+	if err != nil || birthDate.After(now) || Age(birthDate, now) > maxAge {
+		return time.Time{}, false
+	}
+	return birthDate, true
+}
+
+// Age returns how old someone born on birthDate is at now, in whole years
+func Age(birthDate, now time.Time) int {
+	age := now.Year() - birthDate.Year()
+	if now.Month() < birthDate.Month() || (now.Month() == birthDate.Month() && now.Day() < birthDate.Day()) {
+		age--
+	}
+	return age
+}
+
+// NewToken returns a random consent token and the hash to store
+func NewToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, Hash(token), nil
+}
+
+// Hash returns the stored form of a consent token
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ConsentURL returns the web page where a parent answers a consent request
+func ConsentURL(site *links.Site, token string) string {
+	return site.Join("parental-consent", token)
+}
+
+// ConsentRequest is the email asking a parent to consent to their child's
+// account
+func ConsentRequest(site *links.Site, parentEmail, username, token string) mail.Message {
+	return mail.Message{
+		To:      parentEmail,
+		Subject: "Your child wants to join brewd",
+		Text: fmt.Sprintf("Someone signed up for brewd as %s and gave this address as their parent's.\n\n"+
+			"They can't use their account until you agree. To review and consent, visit:\n%s\n\n"+
+			"The link is valid for %d days. If you don't recognise this, you can ignore this email "+
+			"and the account stays blocked.\n",
+			username, ConsentURL(site, token), int(ConsentTTL.Hours()/24)),
+	}
+}
@@ -0,0 +1,58 @@
+package apikeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"slices"
+)
+
+// Prefix starts every API key, so leaked keys are easy to recognise
+const Prefix = "brewd_"
+
+// prefixLength is how much of a key is kept in the clear to identify it
+const prefixLength = len(Prefix) + 6
+
+// Scopes an API key can be granted
+const (
+	ScopeTDSReadings = "tds_readings:write"
+	ScopePourCurves  = "pour_curves:write"
+	ScopeAutomations = "automations"
+)
+
+// AnyScope is passed to accept a key whatever its scopes
+const AnyScope = ""
+
+// Scopes lists every scope an API key can be granted
+var Scopes = []string{
+	ScopeTDSReadings,
+	ScopePourCurves,
+	ScopeAutomations,
+}
+
+// ValidScope reports whether scope is a known scope
+func ValidScope(scope string) bool {
+	return slices.Contains(Scopes, scope)
+}
+
+// HasScope reports whether granted includes scope
+func HasScope(granted []string, scope string) bool {
+	return slices.Contains(granted, scope)
+}
+
+// New returns a random API key, its displayable prefix and the hash to store
+func New() (key, prefix, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	key = Prefix + base64.RawURLEncoding.EncodeToString(b)
+	return key, key[:prefixLength], Hash(key), nil
+}
+
+// Hash returns the stored form of an API key
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,144 @@
+package apikeys
+
+import (
+	"sync"
+	"time"
+)
+
+// QuotaWindow is the window an API key's request limit applies to
+const QuotaWindow = time.Minute
+
+// Usage is an API key's quota as of a request
+type Usage struct {
+	// Limit is the requests allowed per window and Remaining how many of
+	// them are left in the current one, which ends at Reset
+	Limit     int
+	Remaining int
+	Reset     time.Time
+	// Burst is the most credits a key can hold and Credits how many it has.
+	// Requests over the window's limit spend a credit instead of failing.
+	Burst   int
+	Credits int
+}
+
+// keyQuota is one key's current window and banked credits
+type keyQuota struct {
+	start   time.Time
+	used    int
+	credits int
+}
+
+// Limiter meters API key requests with soft limits: each key gets Limit
+// requests per QuotaWindow, and requests it doesn't use in a window are
+// banked as burst credits, up to Burst, to spend when it goes over. New and
+// idle keys start with a full bank, so a bridge syncing a backlog after
+// being offline isn't cut off at the window's limit. Counts are kept in
+// memory, per instance.
+type Limiter struct {
+	limit int
+	burst int
+
+	mu     sync.Mutex
+	keys   map[string]*keyQuota
+	lastGC time.Time
+}
+
+// NewLimiter returns a limiter allowing limit requests per window with burst
+// credits, or nil, which allows every request, for a limit of 0
+func NewLimiter(limit, burst int) *Limiter {
+	if limit <= 0 {
+		return nil
+	}
+	return &Limiter{
+		limit:  limit,
+		burst:  burst,
+		keys:   make(map[string]*keyQuota),
+		lastGC: time.Now(),
+	}
+}
+
+// Use counts a request by keyID, returning whether it was allowed and the
+// key's usage after it
+func (l *Limiter) Use(keyID string, now time.Time) (bool, Usage) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.collectGarbage(now)
+
+	q := l.current(keyID, now)
+	switch {
+	case q.used < l.limit:
+		q.used++
+	case q.credits > 0:
+		q.credits--
+	default:
+		return false, l.usage(q)
+	}
+	return true, l.usage(q)
+}
+
+// Peek returns keyID's usage without counting a request
+func (l *Limiter) Peek(keyID string, now time.Time) Usage {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	q, ok := l.keys[keyID]
+	if !ok {
+		return l.usage(&keyQuota{start: now.Truncate(QuotaWindow), credits: l.burst})
+	}
+	return l.usage(l.roll(q, now))
+}
+
+// current returns keyID's quota for the window containing now
+func (l *Limiter) current(keyID string, now time.Time) *keyQuota {
+	q, ok := l.keys[keyID]
+	if !ok {
+		q = &keyQuota{start: now.Truncate(QuotaWindow), credits: l.burst}
+		l.keys[keyID] = q
+		return q
+	}
+	*q = *l.roll(q, now)
+	return q
+}
+
+// roll returns q moved on to the window containing now, crediting the
+// requests left unused in the windows since
+func (l *Limiter) roll(q *keyQuota, now time.Time) *keyQuota {
+	start := now.Truncate(QuotaWindow)
+	if !start.After(q.start) {
+		return q
+	}
+
+	windows := int(start.Sub(q.start) / QuotaWindow)
+	unused := l.limit - q.used
+	if windows > 1 {
+		// Whole windows idle: enough to refill the bank
+		unused += l.burst
+	}
+	return &keyQuota{start: start, credits: min(l.burst, q.credits+unused)}
+}
+
+func (l *Limiter) usage(q *keyQuota) Usage {
+	return Usage{
+		Limit:     l.limit,
+		Remaining: l.limit - q.used,
+		Reset:     q.start.Add(QuotaWindow),
+		Burst:     l.burst,
+		Credits:   q.credits,
+	}
+}
+
+// collectGarbage drops keys idle for a whole window, whose bank is full
+// again like a key without an entry
+func (l *Limiter) collectGarbage(now time.Time) {
+	if now.Sub(l.lastGC) < time.Minute {
+		return
+	}
+	l.lastGC = now
+
+	for keyID, q := range l.keys {
+		if now.Sub(q.start) >= 2*QuotaWindow {
+			delete(l.keys, keyID)
+		}
+	}
+}
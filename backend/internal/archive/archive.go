@@ -0,0 +1,64 @@
+package archive
+
+import (
+	"context"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/lease"
+	"brewd/internal/logger"
+)
+
+// batchSize bounds how many brews a single statement archives, so each
+// holds its row locks briefly
+const batchSize = 500
+
+// Run moves brews created more than afterDays ago into the brew archive
+// every interval until ctx is cancelled. With afterDays 0 or below no brew
+// is archived.
+func Run(ctx context.Context, queries *db.Queries, interval time.Duration, afterDays int) {
+	if afterDays <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := archiveOlder(ctx, queries, afterDays); err != nil {
+				logger.Error("Failed to archive brews", "error", err)
+			}
+		}
+	}
+}
+
+// archiveOlder archives brews created more than afterDays ago in batches
+// until none are left. Brews something still links to are skipped, and stay
+// live.
+func archiveOlder(ctx context.Context, queries *db.Queries, afterDays int) error {
+	var total int64
+	for ctx.Err() == nil {
+		if err := lease.Check(ctx); err != nil {
+			return err
+		}
+		n, err := queries.ArchiveBrews(ctx, db.ArchiveBrewsParams{
+			AfterDays: int32(afterDays),
+			BatchSize: batchSize,
+		})
+		if err != nil {
+			return err
+		}
+		total += n
+		if n < batchSize {
+			break
+		}
+	}
+	if total > 0 {
+		logger.Info("Archived brews", "count", total, "after_days", afterDays)
+	}
+	return nil
+}
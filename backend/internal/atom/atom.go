@@ -0,0 +1,66 @@
+package atom
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// ContentType is the media type of an Atom feed
+const ContentType = "application/atom+xml; charset=utf-8"
+
+const namespace = "http://www.w3.org/2005/Atom"
+
+// Feed is an Atom (RFC 4287) feed
+type Feed struct {
+	XMLName  xml.Name `xml:"feed"`
+	XMLNS    string   `xml:"xmlns,attr"`
+	ID       string   `xml:"id"`
+	Title    string   `xml:"title"`
+	Subtitle string   `xml:"subtitle,omitempty"`
+	Updated  string   `xml:"updated"`
+	Links    []Link   `xml:"link"`
+	Author   *Person  `xml:"author,omitempty"`
+	Entries  []Entry  `xml:"entry"`
+}
+
+// Entry is an item in a feed
+type Entry struct {
+	ID        string  `xml:"id"`
+	Title     string  `xml:"title"`
+	Updated   string  `xml:"updated"`
+	Published string  `xml:"published,omitempty"`
+	Links     []Link  `xml:"link"`
+	Author    *Person `xml:"author,omitempty"`
+	Summary   string  `xml:"summary,omitempty"`
+}
+
+// Link is a related web page: "alternate" for the page a feed or entry
+// describes, "self" for the feed's own URL
+type Link struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+// Person is the author of a feed or entry
+type Person struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+// Time formats t as an Atom date construct
+func Time(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// Write writes feed as an Atom document
+func Write(w io.Writer, feed Feed) error {
+	feed.XMLNS = namespace
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(feed)
+}
@@ -88,4 +88,4 @@ func (s *Service) ValidateToken(tokenString string) (*Claims, error) {
 	}
 
 	return nil, ErrInvalidToken
-}
\ No newline at end of file
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// FuzzValidateToken checks ValidateToken never panics on a malformed
+// token, and only accepts tokens signed by HMAC with the service's
+// secret
+func FuzzValidateToken(f *testing.F) {
+	service := NewService("fuzz-secret", 24)
+	other := NewService("another-secret", 24)
+	expired := NewService("fuzz-secret", -1)
+
+	valid, err := service.GenerateToken("01JQ7Z5V6N8T2K4M9R3B1C0D7E", "golden")
+	if err != nil {
+		f.Fatal(err)
+	}
+	foreign, err := other.GenerateToken("01JQ7Z5V6N8T2K4M9R3B1C0D7E", "golden")
+	if err != nil {
+		f.Fatal(err)
+	}
+	stale, err := expired.GenerateToken("01JQ7Z5V6N8T2K4M9R3B1C0D7E", "golden")
+	if err != nil {
+		f.Fatal(err)
+	}
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, Claims{
+		UserID: "01JQ7Z5V6N8T2K4M9R3B1C0D7E",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		f.Fatal(err)
+	}
+	header, payload, _ := strings.Cut(valid, ".")
+
+	for _, seed := range []string{
+		valid,
+		foreign,
+		stale,
+		unsigned,
+		valid[:len(valid)-1],
+		valid + ".",
+		header + "." + payload,
+		header + ".e30.",
+		"eyJhbGciOiJIUzI1NiJ9.bnVsbA.",
+		"Bearer " + valid,
+		"",
+		"...",
+		"not-a-token",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, token string) {
+		claims, err := service.ValidateToken(token)
+		if err != nil {
+			if claims != nil {
+				t.Errorf("ValidateToken(%q) returned claims with %v", token, err)
+			}
+			if !errors.Is(err, ErrInvalidToken) && !errors.Is(err, ErrExpiredToken) {
+				t.Errorf("ValidateToken(%q) = %v, want ErrInvalidToken or ErrExpiredToken", token, err)
+			}
+			return
+		}
+		if claims == nil {
+			t.Fatalf("ValidateToken(%q) returned no claims", token)
+		}
+		parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+		if err != nil {
+			t.Fatalf("ValidateToken(%q) accepted a token that doesn't parse: %v", token, err)
+		}
+		if _, ok := parsed.Method.(*jwt.SigningMethodHMAC); !ok {
+			t.Errorf("ValidateToken(%q) accepted %s", token, parsed.Method.Alg())
+		}
+		if _, err := other.ValidateToken(token); err == nil {
+			t.Errorf("ValidateToken(%q) is accepted by another secret", token)
+		}
+	})
+}
+
+func BenchmarkGenerateToken(b *testing.B) {
+	service := NewService("bench-secret", 24)
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := service.GenerateToken("01JQ7Z5V6N8T2K4M9R3B1C0D7E", "bench"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkValidateToken(b *testing.B) {
+	service := NewService("bench-secret", 24)
+	token, err := service.GenerateToken("01JQ7Z5V6N8T2K4M9R3B1C0D7E", "bench")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := service.ValidateToken(token); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
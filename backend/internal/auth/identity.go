@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	ErrUsernameLength   = errors.New("username must be between 3 and 30 characters")
+	ErrUsernameChars    = errors.New("username may only contain letters, numbers, underscores and periods")
+	ErrUsernameReserved = errors.New("username is reserved")
+	ErrUsernameBlocked  = errors.New("username is not allowed")
+	ErrInvalidEmail     = errors.New("invalid email address")
+)
+
+const (
+	minUsernameLength = 3
+	maxUsernameLength = 30
+)
+
+// reservedUsernames cannot be registered because they impersonate the service
+// or collide with routes; matched against the folded, separator-free form
+var reservedUsernames = map[string]struct{}{
+	"admin":         {},
+	"administrator": {},
+	"api":           {},
+	"auth":          {},
+	"brewd":         {},
+	"help":          {},
+	"me":            {},
+	"moderator":     {},
+	"official":      {},
+	"root":          {},
+	"security":      {},
+	"staff":         {},
+	"support":       {},
+	"system":        {},
+}
+
+// blockedUsernameTerms are screened as substrings of the folded username,
+// so they also catch names that run words together
+var blockedUsernameTerms = []string{
+	"fuck",
+	"shit",
+	"cunt",
+	"nigger",
+	"faggot",
+	"whore",
+	"rapist",
+}
+
+// blockedTermExceptions are innocent words containing a blocked term, left
+// out of the folded username before it is screened
+var blockedTermExceptions = []string{
+	"therapist",
+	"scunthorpe",
+	"snigger",
+	"shitake",
+}
+
+var folder = cases.Fold()
+
+// NormalizeUsername applies the identity policy to a requested username and
+// returns the canonical form to store. Compatibility characters (e.g. fullwidth
+// letters) are folded with NFKC; the original case is preserved for display
+// while uniqueness is enforced on the case-folded form.
+func NormalizeUsername(username string) (string, error) {
+	normalized := norm.NFKC.String(strings.TrimSpace(username))
+
+	if n := utf8.RuneCountInString(normalized); n < minUsernameLength || n > maxUsernameLength {
+		return "", ErrUsernameLength
+	}
+
+	for _, r := range normalized {
+		if !isUsernameRune(r) {
+			return "", ErrUsernameChars
+		}
+	}
+
+	folded := FoldUsername(normalized)
+	if _, ok := reservedUsernames[strings.NewReplacer("_", "", ".", "").Replace(folded)]; ok {
+		return "", ErrUsernameReserved
+	}
+
+	screened := folded
+	for _, word := range blockedTermExceptions {
+		// A separator keeps the text around the word from joining up
+		screened = strings.ReplaceAll(screened, word, ".")
+	}
+	for _, term := range blockedUsernameTerms {
+		if strings.Contains(screened, term) {
+			return "", ErrUsernameBlocked
+		}
+	}
+
+	return normalized, nil
+}
+
+// FoldUsername returns the case-folded key used to compare usernames
+func FoldUsername(username string) string {
+	return folder.String(username)
+}
+
+// NormalizeEmail returns the canonical lowercase form of an email address
+func NormalizeEmail(email string) (string, error) {
+	normalized := strings.ToLower(norm.NFKC.String(strings.TrimSpace(email)))
+
+	addr, err := mail.ParseAddress(normalized)
+	if err != nil || addr.Address != normalized {
+		return "", ErrInvalidEmail
+	}
+
+	return normalized, nil
+}
+
+// isUsernameRune reports whether r is allowed in a username
+func isUsernameRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') ||
+		(r >= 'A' && r <= 'Z') ||
+		(r >= '0' && r <= '9') ||
+		r == '_' || r == '.'
+}
@@ -0,0 +1,26 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeUsernameBlockedTerms(t *testing.T) {
+	tests := []struct {
+		username string
+		want     error
+	}{
+		{"therapist", nil},
+		{"Physiotherapist_Jo", nil},
+		{"scunthorpe.fc", nil},
+		{"sniggers", nil},
+		{"rapist", ErrUsernameBlocked},
+		{"therapist_fuck", ErrUsernameBlocked},
+		{"FuckYou", ErrUsernameBlocked},
+	}
+	for _, tt := range tests {
+		if _, err := NormalizeUsername(tt.username); !errors.Is(err, tt.want) {
+			t.Errorf("NormalizeUsername(%q) = %v, want %v", tt.username, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"fmt"
+	"testing"
+)
+
+// benchPassword is a passphrase of a typical length
+const benchPassword = "correct horse battery staple"
+
+// BenchmarkHashPassword hashes at the default BCRYPT_COST and the costs
+// deployments raise it to
+func BenchmarkHashPassword(b *testing.B) {
+	for _, cost := range []int{10, 11, 12} {
+		b.Run(fmt.Sprintf("cost=%d", cost), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := HashPassword(benchPassword, cost); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkComparePassword(b *testing.B) {
+	hash, err := HashPassword(benchPassword, 10)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		if !ComparePassword(hash, benchPassword) {
+			b.Fatal("password doesn't match its hash")
+		}
+	}
+}
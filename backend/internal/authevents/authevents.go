@@ -0,0 +1,129 @@
+package authevents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/lease"
+	"brewd/internal/logger"
+	"brewd/internal/mail"
+)
+
+// Events the auth event log records. EventStepUp is a login held for a
+// step-up challenge, and EventStepUpFailed a wrong step-up code.
+const (
+	EventRegister     = "register"
+	EventLogin        = "login"
+	EventLoginFailed  = "login_failed"
+	EventStepUp       = "step_up"
+	EventStepUpFailed = "step_up_failed"
+)
+
+// batchSize bounds how many login alerts a single poll sends
+const batchSize = 100
+
+// alertMaxAgeHours is how long after a login its alert is still sent; a
+// send that keeps failing is given up after it
+const alertMaxAgeHours = 24
+
+// Run emails users about logins from new locations every interval until
+// ctx is cancelled, if alerts is set. Events past retention are purged by
+// the retention job.
+func Run(ctx context.Context, queries *db.Queries, mailer *mail.Mailer, alerts bool, interval time.Duration) {
+	if !alerts {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := sendDue(ctx, queries, mailer); err != nil {
+				logger.Error("Failed to send login alerts", "error", err)
+			}
+		}
+	}
+}
+
+// sendDue sends the alert of each new location login that hasn't had one.
+// A failed send is retried on the next run; a suppressed address counts as
+// sent.
+func sendDue(ctx context.Context, queries *db.Queries, mailer *mail.Mailer) error {
+	due, err := queries.ListPendingLoginAlerts(ctx, db.ListPendingLoginAlertsParams{
+		MaxAgeHours: alertMaxAgeHours,
+		RowLimit:    batchSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	sent := 0
+	for _, login := range due {
+		if err := lease.Check(ctx); err != nil {
+			return err
+		}
+		err := mailer.Send(ctx, alert(login))
+		suppressed := errors.Is(err, mail.ErrSuppressed)
+		if err != nil && !suppressed {
+			logger.Warn("Failed to send login alert", "auth_event_id", login.ID, "error", err)
+			continue
+		}
+
+		if err := queries.MarkLoginAlertSent(ctx, login.ID); err != nil {
+			return err
+		}
+		if !suppressed {
+			sent++
+		}
+	}
+	if sent > 0 {
+		logger.Info("Sent login alerts", "count", sent)
+	}
+	return nil
+}
+
+// alert is the email telling a user about a login from a new location
+func alert(login db.ListPendingLoginAlertsRow) mail.Message {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Hi %s,\n\n", login.Username)
+	fmt.Fprintf(&b, "Your brewd account was just signed in to from a new location.\n\n")
+	fmt.Fprintf(&b, "Location: %s\n", Place(login.City, login.Region, login.Country))
+	fmt.Fprintf(&b, "Time: %s\n", login.CreatedAt.UTC().Format("2 Jan 2006 15:04 MST"))
+	if login.Ip != nil {
+		fmt.Fprintf(&b, "IP address: %s\n", *login.Ip)
+	}
+	if login.UserAgent != nil && *login.UserAgent != "" {
+		fmt.Fprintf(&b, "Device: %s\n", *login.UserAgent)
+	}
+	b.WriteString("\nIf this was you, there's nothing to do. If it wasn't, someone else " +
+		"may know your password: contact support so we can secure your account.\n")
+
+	return mail.Message{
+		To:      login.Email,
+		Subject: "New sign-in to your brewd account",
+		Text:    b.String(),
+	}
+}
+
+// Place formats a coarse location as "City, Region, Country", leaving out
+// the parts that are unknown
+func Place(city, region, country *string) string {
+	parts := make([]string, 0, 3)
+	for _, p := range []*string{city, region, country} {
+		if p != nil && *p != "" {
+			parts = append(parts, *p)
+		}
+	}
+	if len(parts) == 0 {
+		return "Unknown"
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,132 @@
+package automations
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"brewd/internal/db"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// Brew events webhooks can subscribe to. Triggers queue them through the
+// outbox (see db/schema/triggers.sql), with a Payload plus the user's ID.
+const (
+	EventBrewLogged  = "brew.logged"
+	EventBrewStarted = "brew.started"
+	EventBrewStopped = "brew.stopped"
+)
+
+// EventTest is sent when a user tests a webhook
+const EventTest = "test"
+
+// Events lists every event a webhook can subscribe to
+var Events = []string{
+	EventBrewLogged,
+	EventBrewStarted,
+	EventBrewStopped,
+}
+
+// Webhook request body formats
+const (
+	FormatHomeAssistant = "home_assistant"
+	FormatIFTTT         = "ifttt"
+)
+
+// Formats lists every webhook format
+var Formats = []string{
+	FormatHomeAssistant,
+	FormatIFTTT,
+}
+
+// eventPlaceholder in a webhook URL is replaced with the event's name, so
+// one IFTTT URL can trigger a different applet per event
+const eventPlaceholder = "{event}"
+
+var ErrInvalidURL = errors.New("webhook URL must be https on a public host")
+
+// ValidEvent reports whether event is one webhooks can subscribe to
+func ValidEvent(event string) bool {
+	return slices.Contains(Events, event)
+}
+
+// ValidFormat reports whether format is a known webhook format
+func ValidFormat(format string) bool {
+	return slices.Contains(Formats, format)
+}
+
+// Payload describes an event. It is queued with each delivery and rendered
+// in the webhook's format when sent.
+type Payload struct {
+	Event           string    `json:"event"`
+	OccurredAt      time.Time `json:"occurred_at"`
+	BrewID          string    `json:"brew_id,omitempty"`
+	BrewName        string    `json:"brew_name,omitempty"`
+	BrewMethod      *string   `json:"brew_method,omitempty"`
+	BrewTimeSeconds *int32    `json:"brew_time_seconds,omitempty"`
+}
+
+// Body renders p as a format request body. Home Assistant webhook triggers
+// get the payload as is, for templates like {{ trigger.json.brew_name }};
+// IFTTT Webhooks get its value1-value3 ingredients: the brew's name, its
+// method and the event.
+func Body(format string, p Payload) ([]byte, error) {
+	if format == FormatIFTTT {
+		method := ""
+		if p.BrewMethod != nil {
+			method = *p.BrewMethod
+		}
+		return json.Marshal(map[string]string{
+			"value1": p.BrewName,
+			"value2": method,
+			"value3": p.Event,
+		})
+	}
+	return json.Marshal(p)
+}
+
+// URL returns the webhook URL to send event to, with any {event}
+// placeholder replaced by the event name using underscores (brew_started),
+// as IFTTT event names can't contain dots
+func URL(raw, event string) string {
+	return strings.ReplaceAll(raw, eventPlaceholder, strings.ReplaceAll(event, ".", "_"))
+}
+
+// CheckURL checks that raw is an https URL on a host name or public IP
+// address. Host names are checked again when delivering, once resolved.
+func CheckURL(raw string) error {
+	u, err := url.Parse(URL(raw, EventTest))
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" || u.User != nil {
+		return ErrInvalidURL
+	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil && !publicIP(ip) {
+		return ErrInvalidURL
+	}
+	if strings.EqualFold(u.Hostname(), "localhost") {
+		return ErrInvalidURL
+	}
+	return nil
+}
+
+// EnqueueFor queues an encoded payload for one webhook
+func EnqueueFor(ctx context.Context, queries *db.Queries, webhookID, event string, payload []byte) error {
+	return queries.CreateAutomationDelivery(ctx, db.CreateAutomationDeliveryParams{
+		ID:        ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+		WebhookID: webhookID,
+		Event:     event,
+		Payload:   payload,
+	})
+}
+
+// publicIP reports whether ip is routable on the public internet
+func publicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified())
+}
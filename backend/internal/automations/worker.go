@@ -0,0 +1,155 @@
+package automations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/logger"
+)
+
+// batchSize bounds how many deliveries a single poll claims
+const batchSize = 50
+
+// Delivery limits: each request times out after requestTimeout, and
+// failures are retried until maxAttempts
+const (
+	requestTimeout = 10 * time.Second
+	maxAttempts    = 6
+)
+
+// leaseSeconds is how long a claimed delivery is leased: longer than a
+// batch whose every request times out, so no other instance claims a
+// delivery again before its batch reaches it
+const leaseSeconds = batchSize*int32(requestTimeout/time.Second) + 60
+
+// Run sends queued webhook deliveries every interval until ctx is cancelled
+func Run(ctx context.Context, queries *db.Queries, interval time.Duration) {
+	client := newClient()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := deliverDue(ctx, queries, client); err != nil {
+				logger.Error("Failed to deliver automation webhooks", "error", err)
+			}
+		}
+	}
+}
+
+// deliverDue claims due deliveries and sends each, recording the outcome.
+// Failed deliveries are retried with backoff; client errors other than
+// timeouts and rate limits are not retried.
+func deliverDue(ctx context.Context, queries *db.Queries, client *http.Client) error {
+	for {
+		due, err := queries.ClaimDueAutomationDeliveries(ctx, db.ClaimDueAutomationDeliveriesParams{
+			LeaseSeconds: leaseSeconds,
+			RowLimit:     batchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, d := range due {
+			status, err := deliver(ctx, client, d)
+
+			params := db.CompleteAutomationDeliveryParams{ID: d.ID, Status: status}
+			if err != nil {
+				msg := err.Error()
+				params.Error = &msg
+				if d.Attempts < maxAttempts && retryable(status) {
+					// 1, 4, 9, 16 and 25 minutes
+					retry := d.Attempts * d.Attempts * 60
+					params.RetryInSeconds = &retry
+				}
+				logger.Warn("Automation webhook delivery failed", "delivery_id", d.ID, "webhook_id", d.WebhookID,
+					"attempts", d.Attempts, "error", err)
+			}
+			if err := queries.CompleteAutomationDelivery(ctx, params); err != nil {
+				logger.Error("Failed to record automation delivery", "delivery_id", d.ID, "error", err)
+			}
+		}
+
+		if len(due) < batchSize {
+			return nil
+		}
+	}
+}
+
+// deliver sends one delivery, returning the response status if there was
+// one and an error unless it was a success
+func deliver(ctx context.Context, client *http.Client, d db.ClaimDueAutomationDeliveriesRow) (*int32, error) {
+	var p Payload
+	if err := json.Unmarshal(d.Payload, &p); err != nil {
+		return nil, err
+	}
+	body, err := Body(d.Format, p)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, URL(d.Url, d.Event), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "brewd-automations/1")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	status := int32(resp.StatusCode)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &status, fmt.Errorf("webhook responded %d", resp.StatusCode)
+	}
+	return &status, nil
+}
+
+// retryable reports whether a failure with status (nil without a response)
+// may succeed later
+func retryable(status *int32) bool {
+	if status == nil || *status >= 500 {
+		return true
+	}
+	return *status == http.StatusRequestTimeout || *status == http.StatusTooManyRequests
+}
+
+// newClient returns an HTTP client that refuses to connect to loopback,
+// private and link-local addresses, so webhooks can't reach internal
+// services
+func newClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+				return ErrInvalidURL
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Transport: transport}
+}
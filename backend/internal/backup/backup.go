@@ -0,0 +1,486 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/lease"
+	"brewd/internal/logger"
+	"brewd/internal/mail"
+	"brewd/internal/objectstore"
+	"brewd/pkg/database"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/oklog/ulid/v2"
+)
+
+// ErrRunning is returned when a backup is requested while another is in
+// progress, on any instance
+var ErrRunning = errors.New("backup: a backup is already running")
+
+// Triggers of a backup run
+const (
+	TriggerScheduled = "scheduled"
+	TriggerManual    = "manual"
+)
+
+// Outcomes and verifications recorded for a run
+const (
+	statusSucceeded      = "succeeded"
+	statusFailed         = "failed"
+	verificationRestored = "restored"
+	verificationListed   = "listed"
+)
+
+// checkInterval is how often the job checks whether a scheduled backup is
+// due
+const checkInterval = 5 * time.Minute
+
+// pickUpInterval is how often the job looks for a run to perform that was
+// triggered on any instance
+const pickUpInterval = 15 * time.Second
+
+// runTimeout bounds a whole run: dump, upload and verification
+const runTimeout = 4 * time.Hour
+
+// staleHours is how long after starting a run still in progress is taken
+// to be interrupted; longer than runTimeout, so only abandoned runs are
+const staleHours = 6
+
+// keyPrefix is where dumps are stored in the object store
+const keyPrefix = "backups/"
+
+// pruneBatch is how many expired dumps are deleted per check
+const pruneBatch = 100
+
+// Runner takes logical backups of the database with pg_dump, uploads them
+// to an object store and verifies they restore. Without a scratch database
+// a dump is only checked to be readable, by listing its contents.
+type Runner struct {
+	queries  *db.Queries
+	store    objectstore.Store
+	mailer   *mail.Mailer
+	adminIDs []string
+	source   string
+	scratch  string
+}
+
+// NewRunner returns a runner backing up the database at source, a postgres://
+// URL. scratch, if set, is the URL of a database dumps are restored into to
+// verify them; its public schema is dropped each time, so it must not be
+// source. Failures are emailed to the users listed in adminIDs.
+func NewRunner(queries *db.Queries, store objectstore.Store, mailer *mail.Mailer, adminIDs []string, source, scratch string) (*Runner, error) {
+	if scratch != "" {
+		same, err := sameDatabase(source, scratch)
+		if err != nil {
+			return nil, err
+		}
+		if same {
+			return nil, fmt.Errorf("backup: the scratch database is the database being backed up")
+		}
+	}
+
+	return &Runner{
+		queries:  queries,
+		store:    store,
+		mailer:   mailer,
+		adminIDs: adminIDs,
+		source:   source,
+		scratch:  scratch,
+	}, nil
+}
+
+// Trigger records a backup requested by an admin, which Run performs on
+// whichever instance holds the backup job. It returns ErrRunning if a backup
+// is already in progress.
+func (r *Runner) Trigger(ctx context.Context, userID string) (db.BackupRun, error) {
+	return r.start(ctx, TriggerManual, &userID)
+}
+
+// Run performs triggered backups, and a scheduled one whenever interval has
+// passed since the last run started, until ctx is cancelled. With interval
+// 0 backups are only taken when triggered. Dumps are deleted retentionDays
+// after their run started, except the newest successful one; 0 keeps them
+// all. It runs on one instance at a time, under a lease; a run left in
+// progress by an instance that lost the lease is performed again.
+func (r *Runner) Run(ctx context.Context, interval time.Duration, retentionDays int) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	pickUp := time.NewTicker(pickUpInterval)
+	defer pickUp.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-pickUp.C:
+			run, err := r.queries.GetRunningBackupRun(ctx)
+			if err == pgx.ErrNoRows {
+				continue
+			}
+			if err != nil {
+				logger.Error("Failed to get running backup run", "error", err)
+				continue
+			}
+			r.perform(ctx, run)
+		case <-ticker.C:
+			if n, err := r.queries.FailStaleBackupRuns(ctx, staleHours); err != nil {
+				logger.Error("Failed to fail stale backup runs", "error", err)
+			} else if n > 0 {
+				logger.Warn("Marked interrupted backup runs failed", "count", n)
+			}
+			if retentionDays > 0 {
+				r.prune(ctx, retentionDays)
+			}
+			if interval > 0 && r.due(ctx, interval) {
+				run, err := r.start(ctx, TriggerScheduled, nil)
+				if errors.Is(err, ErrRunning) {
+					continue
+				}
+				if err != nil {
+					logger.Error("Failed to start backup", "error", err)
+					continue
+				}
+				r.perform(ctx, run)
+			}
+		}
+	}
+}
+
+// due reports whether interval has passed since the last run started
+func (r *Runner) due(ctx context.Context, interval time.Duration) bool {
+	latest, err := r.queries.GetLatestBackupRun(ctx)
+	if err == pgx.ErrNoRows {
+		return true
+	}
+	if err != nil {
+		logger.Error("Failed to get latest backup run", "error", err)
+		return false
+	}
+	return time.Since(latest.StartedAt.Time) >= interval
+}
+
+// prune deletes the dumps of runs started over retentionDays ago, but not
+// the newest successful one, and clears them from the runs. A dump that
+// fails to delete is tried again at the next check.
+func (r *Runner) prune(ctx context.Context, retentionDays int) {
+	runs, err := r.queries.ListExpiredBackupRuns(ctx, db.ListExpiredBackupRunsParams{
+		RetentionDays: int32(retentionDays),
+		BatchSize:     pruneBatch,
+	})
+	if err != nil {
+		logger.Error("Failed to list expired backups", "error", err)
+		return
+	}
+
+	for _, run := range runs {
+		if err := r.store.Delete(ctx, *run.ObjectKey); err != nil {
+			logger.Error("Failed to delete expired backup", "backup_id", run.ID, "key", *run.ObjectKey, "error", err)
+			continue
+		}
+		if err := r.queries.PruneBackupRun(ctx, run.ID); err != nil {
+			logger.Error("Failed to record expired backup deleted", "backup_id", run.ID, "error", err)
+			continue
+		}
+		logger.Info("Deleted expired backup", "backup_id", run.ID, "key", *run.ObjectKey)
+	}
+}
+
+// start records a new run in progress
+func (r *Runner) start(ctx context.Context, trigger string, requestedBy *string) (db.BackupRun, error) {
+	run, err := r.queries.StartBackupRun(ctx, db.StartBackupRunParams{
+		ID:          ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+		Trigger:     trigger,
+		RequestedBy: requestedBy,
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return run, ErrRunning
+	}
+	return run, err
+}
+
+// outcome is what a run recorded before it finished or failed
+type outcome struct {
+	objectKey      *string
+	sizeBytes      *int64
+	sha256         *string
+	verification   *string
+	tablesRestored *int32
+}
+
+// perform dumps, uploads and verifies, then records the outcome, alerting
+// admins on failure. A job that has lost its lease leaves the run for the
+// instance that took it.
+func (r *Runner) perform(ctx context.Context, run db.BackupRun) {
+	if err := lease.Check(ctx); err != nil {
+		logger.Warn("Left backup to the backup job's new instance", "backup_id", run.ID, "error", err)
+		return
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, runTimeout)
+	defer cancel()
+
+	logger.Info("Starting backup", "backup_id", run.ID, "trigger", run.Trigger)
+	var out outcome
+	err := r.take(runCtx, run, &out)
+
+	status := statusSucceeded
+	var errText *string
+	if err != nil {
+		status = statusFailed
+		text := err.Error()
+		errText = &text
+	}
+
+	// Recorded even if ctx was cancelled mid-run, so the run doesn't stay
+	// in progress until it's taken to be stale
+	finished, finishErr := r.queries.FinishBackupRun(context.WithoutCancel(ctx), db.FinishBackupRunParams{
+		Status:         status,
+		ObjectKey:      out.objectKey,
+		SizeBytes:      out.sizeBytes,
+		Sha256:         out.sha256,
+		Verification:   out.verification,
+		TablesRestored: out.tablesRestored,
+		Error:          errText,
+		ID:             run.ID,
+	})
+	if finishErr != nil {
+		logger.Error("Failed to record backup run", "backup_id", run.ID, "error", finishErr)
+		finished = run
+	}
+
+	if err != nil && ctx.Err() != nil {
+		logger.Warn("Backup interrupted by shutdown", "backup_id", run.ID, "error", err)
+		return
+	}
+	if err != nil {
+		logger.Error("Backup failed", "backup_id", run.ID, "error", err)
+		r.alert(context.WithoutCancel(ctx), finished, err)
+		return
+	}
+	logger.Info("Backup succeeded", "backup_id", run.ID, "key", *out.objectKey, "bytes", *out.sizeBytes,
+		"verification", *out.verification)
+}
+
+// take dumps the database to a temporary file, uploads it and verifies it,
+// filling in out as each step completes
+func (r *Runner) take(ctx context.Context, run db.BackupRun, out *outcome) error {
+	dir, err := os.MkdirTemp("", "brewd-backup-*")
+	if err != nil {
+		return fmt.Errorf("dump: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "brewd.dump")
+
+	env, err := libpqEnv(r.source)
+	if err != nil {
+		return fmt.Errorf("dump: %w", err)
+	}
+	// Row security stays on, rather than failing for a role that can't
+	// bypass it; acting for the server, the policies pass every row
+	env = append(env, "PGOPTIONS=-c app.current_user_id="+database.ServerUser)
+	if err := command(ctx, env, "pg_dump", "--format=custom", "--no-owner", "--no-privileges",
+		"--enable-row-security", "--file="+path); err != nil {
+		return fmt.Errorf("dump: %w", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("dump: %w", err)
+	}
+	defer f.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return fmt.Errorf("dump: %w", err)
+	}
+	sum := hex.EncodeToString(hash.Sum(nil))
+
+	key := keyPrefix + "brewd-" + run.StartedAt.Time.UTC().Format("20060102T150405Z") + "-" + run.ID + ".dump"
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("upload: %w", err)
+	}
+	if err := r.store.Put(ctx, key, f, size, "application/octet-stream"); err != nil {
+		return fmt.Errorf("upload: %w", err)
+	}
+	out.objectKey, out.sizeBytes, out.sha256 = &key, &size, &sum
+
+	verification, tables, err := r.verify(ctx, path)
+	if err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+	out.verification, out.tablesRestored = &verification, tables
+	return nil
+}
+
+// verify restores the dump at path into the scratch database, returning
+// how many tables it restored, or without one lists the dump's contents
+func (r *Runner) verify(ctx context.Context, path string) (string, *int32, error) {
+	if r.scratch == "" {
+		if err := command(ctx, nil, "pg_restore", "--list", path); err != nil {
+			return "", nil, err
+		}
+		return verificationListed, nil, nil
+	}
+
+	conn, err := pgx.Connect(ctx, r.scratch)
+	if err != nil {
+		return "", nil, err
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	// The scratch copy is wiped afterwards too, so user data isn't left
+	// lying around in it
+	reset := func(ctx context.Context) error {
+		_, err := conn.Exec(ctx, "DROP SCHEMA IF EXISTS public CASCADE; CREATE SCHEMA public")
+		return err
+	}
+	if err := reset(ctx); err != nil {
+		return "", nil, err
+	}
+	defer func() {
+		if err := reset(context.WithoutCancel(ctx)); err != nil {
+			logger.Warn("Failed to clear backup scratch database", "error", err)
+		}
+	}()
+
+	env, err := libpqEnv(r.scratch)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := command(ctx, env, "pg_restore", "--exit-on-error", "--single-transaction", "--no-owner",
+		"--no-privileges", "--enable-row-security", "--dbname="+envValue(env, "PGDATABASE"), path); err != nil {
+		return "", nil, err
+	}
+
+	var tables int32
+	err = conn.QueryRow(ctx, "SELECT COUNT(*)::int FROM pg_tables WHERE schemaname = 'public'").Scan(&tables)
+	if err != nil {
+		return "", nil, err
+	}
+	if tables == 0 {
+		return "", nil, fmt.Errorf("the restore created no tables")
+	}
+	return verificationRestored, &tables, nil
+}
+
+// alert emails the admins about a failed run
+func (r *Runner) alert(ctx context.Context, run db.BackupRun, cause error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "A %s backup of the brewd database failed.\n\n", run.Trigger)
+	fmt.Fprintf(&b, "Backup: %s\n", run.ID)
+	fmt.Fprintf(&b, "Started: %s\n", run.StartedAt.Time.UTC().Format("2 Jan 2006 15:04 MST"))
+	fmt.Fprintf(&b, "Error: %s\n", cause)
+	if run.ObjectKey != nil {
+		fmt.Fprintf(&b, "Uploaded as: %s\n", *run.ObjectKey)
+	}
+	b.WriteString("\nThe backup history is at GET /api/v1/admin/backups.\n")
+
+	for _, id := range r.adminIDs {
+		admin, err := r.queries.GetUserByID(ctx, id)
+		if err != nil {
+			logger.Warn("Failed to look up admin for backup alert", "user_id", id, "error", err)
+			continue
+		}
+		err = r.mailer.Send(ctx, mail.Message{
+			To:      admin.Email,
+			Subject: "brewd backup failed",
+			Text:    b.String(),
+		})
+		if err != nil && !errors.Is(err, mail.ErrSuppressed) {
+			logger.Warn("Failed to send backup alert", "user_id", id, "error", err)
+		}
+	}
+}
+
+// command runs a PostgreSQL client program with env added to the
+// environment, returning its error output on failure
+func command(ctx context.Context, env []string, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(os.Environ(), env...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %w: %s", name, err, truncate(msg, 1000))
+		}
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// libpqEnv turns a postgres:// URL into the PG* environment variables the
+// client programs read, keeping the password off their command line
+func libpqEnv(rawURL string) ([]string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database URL: %w", err)
+	}
+	env := []string{"PGHOST=" + u.Hostname(), "PGDATABASE=" + strings.TrimPrefix(u.Path, "/")}
+	if port := u.Port(); port != "" {
+		env = append(env, "PGPORT="+port)
+	}
+	if u.User != nil {
+		env = append(env, "PGUSER="+u.User.Username())
+		if password, ok := u.User.Password(); ok {
+			env = append(env, "PGPASSWORD="+password)
+		}
+	}
+	if sslMode := u.Query().Get("sslmode"); sslMode != "" {
+		env = append(env, "PGSSLMODE="+sslMode)
+	}
+	return env, nil
+}
+
+// envValue returns the value of name in env
+func envValue(env []string, name string) string {
+	for _, kv := range env {
+		if v, ok := strings.CutPrefix(kv, name+"="); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// sameDatabase reports whether two postgres:// URLs name the same database
+// on the same server
+func sameDatabase(a, b string) (bool, error) {
+	ua, err := url.Parse(a)
+	if err != nil {
+		return false, fmt.Errorf("backup: invalid database URL: %w", err)
+	}
+	ub, err := url.Parse(b)
+	if err != nil {
+		return false, fmt.Errorf("backup: invalid scratch database URL: %w", err)
+	}
+	port := func(u *url.URL) string {
+		if p := u.Port(); p != "" {
+			return p
+		}
+		return "5432"
+	}
+	return strings.EqualFold(ua.Hostname(), ub.Hostname()) && port(ua) == port(ub) &&
+		strings.Trim(ua.Path, "/") == strings.Trim(ub.Path, "/"), nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "…"
+}
@@ -0,0 +1,72 @@
+package badges
+
+import (
+	"context"
+
+	"brewd/internal/db"
+)
+
+// Challenge metrics
+const (
+	// MetricBrews counts brews logged, or only those made with the
+	// challenge's brew method when it has one
+	MetricBrews = "brews"
+	// MetricOrigins counts distinct bean origins brewed
+	MetricOrigins = "origins"
+	// MetricMethods counts distinct brew methods used
+	MetricMethods = "methods"
+)
+
+// Totals are a user's brewing figures that challenges are measured against
+type Totals struct {
+	Brews       int64
+	Origins     int64
+	Methods     int64
+	MethodBrews map[string]int64
+}
+
+// LoadTotals gathers userID's brewing totals
+func LoadTotals(ctx context.Context, queries *db.Queries, userID string) (Totals, error) {
+	row, err := queries.GetUserBrewTotals(ctx, &userID)
+	if err != nil {
+		return Totals{}, err
+	}
+	counts, err := queries.ListUserMethodBrewCounts(ctx, &userID)
+	if err != nil {
+		return Totals{}, err
+	}
+
+	totals := Totals{
+		Brews:       row.BrewCount,
+		Origins:     row.OriginCount,
+		Methods:     row.MethodCount,
+		MethodBrews: make(map[string]int64, len(counts)),
+	}
+	for _, c := range counts {
+		totals.MethodBrews[c.BrewMethod] = c.BrewCount
+	}
+	return totals, nil
+}
+
+// Progress returns how far totals are toward challenge, capped at its
+// threshold
+func Progress(challenge db.Challenge, totals Totals) int64 {
+	var value int64
+	switch challenge.Metric {
+	case MetricBrews:
+		value = totals.Brews
+		if challenge.BrewMethod != nil {
+			value = totals.MethodBrews[*challenge.BrewMethod]
+		}
+	case MetricOrigins:
+		value = totals.Origins
+	case MetricMethods:
+		value = totals.Methods
+	}
+	return min(value, int64(challenge.Threshold))
+}
+
+// Completed reports whether totals meet challenge's threshold
+func Completed(challenge db.Challenge, totals Totals) bool {
+	return Progress(challenge, totals) >= int64(challenge.Threshold)
+}
@@ -0,0 +1,79 @@
+package badges
+
+import (
+	"context"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/logger"
+)
+
+// batchSize bounds how many queued users a single poll claims
+const batchSize = 100
+
+// Run awards badges to queued users every interval until ctx is cancelled
+func Run(ctx context.Context, queries *db.Queries, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := evaluateQueued(ctx, queries); err != nil {
+				logger.Error("Failed to evaluate badges", "error", err)
+			}
+		}
+	}
+}
+
+// evaluateQueued claims queued users and awards each the active challenges
+// they have completed. A claimed user is not retried if evaluation fails;
+// their next brew queues them again.
+func evaluateQueued(ctx context.Context, queries *db.Queries) error {
+	challenges, err := queries.ListChallenges(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	for {
+		userIDs, err := queries.ClaimBadgeEvaluations(ctx, batchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, userID := range userIDs {
+			if err := evaluate(ctx, queries, userID, challenges); err != nil {
+				logger.Error("Failed to evaluate user badges", "user_id", userID, "error", err)
+			}
+		}
+
+		if len(userIDs) < batchSize {
+			return nil
+		}
+	}
+}
+
+// evaluate awards userID the completed challenges they don't have yet.
+// Awarding a badge writes its notification to the outbox in the same
+// statement.
+func evaluate(ctx context.Context, queries *db.Queries, userID string, challenges []db.Challenge) error {
+	totals, err := LoadTotals(ctx, queries, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, challenge := range challenges {
+		if !Completed(challenge, totals) {
+			continue
+		}
+		if _, err := queries.AwardBadge(ctx, db.AwardBadgeParams{
+			UserID:      userID,
+			ChallengeID: challenge.ID,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package beans
+
+import (
+	"math"
+	"time"
+)
+
+// Window is how far back consumption is averaged; recent brewing habits
+// predict the run-out date better than the whole life of the bag
+const Window = 14 * 24 * time.Hour
+
+const day = 24 * time.Hour
+
+// Usage is how much of a bag has been brewed. RecentGrams covers the Window
+// before now; FirstBrewAt is nil when no brews are linked to the bag.
+type Usage struct {
+	WeightGrams float64
+	UsedGrams   float64
+	RecentGrams float64
+	FirstBrewAt *time.Time
+}
+
+// Forecast is when a bag is expected to run out at its recent rate. The
+// rate-based fields are nil when there is no recent brewing to go on.
+type Forecast struct {
+	RemainingGrams float64    `json:"remaining_grams"`
+	DailyGrams     *float64   `json:"daily_grams"`
+	DaysLeft       *float64   `json:"days_left"`
+	RunsOutAt      *time.Time `json:"runs_out_at"`
+	ReorderBy      *time.Time `json:"reorder_by"`
+	ShouldReorder  bool       `json:"should_reorder"`
+}
+
+// Predict forecasts a bag's run-out date as of now. The daily rate is the
+// grams used over the Window, or over the time since the first brew if that
+// is shorter (at least one day). ShouldReorder is set once now is within
+// leadDays of running out, or the bag is already empty.
+func Predict(u Usage, leadDays int, now time.Time) Forecast {
+	f := Forecast{RemainingGrams: round(math.Max(u.WeightGrams-u.UsedGrams, 0))}
+	if f.RemainingGrams == 0 {
+		f.ShouldReorder = true
+	}
+	if u.FirstBrewAt == nil || u.RecentGrams <= 0 {
+		return f
+	}
+
+	span := math.Min(now.Sub(*u.FirstBrewAt).Hours(), Window.Hours()) / 24
+	daily := u.RecentGrams / math.Max(span, 1)
+	daysLeft := f.RemainingGrams / daily
+	runsOut := now.Add(time.Duration(daysLeft * float64(day)))
+	reorderBy := runsOut.Add(-time.Duration(leadDays) * day)
+
+	daily = round(daily)
+	daysLeft = round(daysLeft)
+	f.DailyGrams = &daily
+	f.DaysLeft = &daysLeft
+	f.RunsOutAt = &runsOut
+	f.ReorderBy = &reorderBy
+	f.ShouldReorder = f.ShouldReorder || !now.Before(reorderBy)
+	return f
+}
+
+func round(v float64) float64 {
+	return math.Round(v*10) / 10
+}
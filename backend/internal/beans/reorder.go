@@ -0,0 +1,81 @@
+package beans
+
+import (
+	"context"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/lease"
+	"brewd/internal/logger"
+)
+
+// batchSize bounds how many bags a single query scans
+const batchSize = 100
+
+// RunReorderChecks suggests reordering bags that are forecast to run out
+// soon, every interval until ctx is cancelled. Each bag is suggested once;
+// updating the bag re-arms it.
+func RunReorderChecks(ctx context.Context, queries *db.Queries, interval time.Duration, defaultDoseGrams float64) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := checkReorders(ctx, queries, defaultDoseGrams); err != nil {
+				logger.Error("Failed to check bean reorders", "error", err)
+			}
+		}
+	}
+}
+
+func checkReorders(ctx context.Context, queries *db.Queries, defaultDoseGrams float64) error {
+	now := time.Now()
+	afterID := ""
+	for {
+		if err := lease.Check(ctx); err != nil {
+			return err
+		}
+		bags, err := queries.ListReorderCandidates(ctx, db.ListReorderCandidatesParams{
+			DefaultDoseGrams: defaultDoseGrams,
+			WindowStart:      now.Add(-Window),
+			AfterID:          afterID,
+			RowLimit:         batchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, bag := range bags {
+			afterID = bag.ID
+			usage := Usage{
+				WeightGrams: bag.WeightGrams,
+				UsedGrams:   bag.UsedGrams,
+				RecentGrams: bag.RecentGrams,
+			}
+			if bag.FirstBrewAt.Valid {
+				usage.FirstBrewAt = &bag.FirstBrewAt.Time
+			}
+			if !Predict(usage, int(bag.ReorderLeadDays), now).ShouldReorder {
+				continue
+			}
+			if err := suggestReorder(ctx, queries, bag.ID); err != nil {
+				logger.Error("Failed to suggest bean reorder", "bean_bag_id", bag.ID, "error", err)
+			}
+		}
+
+		if len(bags) < batchSize {
+			return nil
+		}
+	}
+}
+
+// suggestReorder claims the bag's suggestion; a bag another worker already
+// claimed is skipped. Claiming it writes the owner's notification to the
+// outbox in the same statement.
+func suggestReorder(ctx context.Context, queries *db.Queries, bagID string) error {
+	_, err := queries.MarkBeanBagReorderNotified(ctx, bagID)
+	return err
+}
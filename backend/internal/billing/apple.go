@@ -0,0 +1,244 @@
+package billing
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// App Store receipt validation endpoints. Receipts from TestFlight and
+// development builds are only valid in the sandbox.
+const (
+	appleProductionURL = "https://buy.itunes.apple.com/verifyReceipt"
+	appleSandboxURL    = "https://sandbox.itunes.apple.com/verifyReceipt"
+)
+
+// verifyReceipt statuses billing acts on. A sandbox receipt sent to
+// production is sent to the sandbox instead. Only the statuses rejecting
+// the receipt itself, as malformed, unauthentic or for a deleted account,
+// reject it; others, such as a wrong shared secret or the App Store being
+// unavailable, are failures to verify it that are tried again.
+const (
+	appleStatusOK              = 0
+	appleStatusMalformed       = 21002
+	appleStatusUnauthenticated = 21003
+	appleStatusExpired         = 21006
+	appleStatusSandboxReceipt  = 21007
+	appleStatusAccountNotFound = 21010
+)
+
+// storeTimeout bounds each app store API request
+const storeTimeout = 15 * time.Second
+
+// AppStore validates App Store receipts with Apple's verifyReceipt service,
+// authenticated by the app's shared secret
+type AppStore struct {
+	sharedSecret string
+	bundleID     string
+	client       *http.Client
+}
+
+func NewAppStore(sharedSecret, bundleID string) *AppStore {
+	return &AppStore{
+		sharedSecret: sharedSecret,
+		bundleID:     bundleID,
+		client:       &http.Client{Timeout: storeTimeout},
+	}
+}
+
+// appleReceiptResponse is the part of a verifyReceipt response billing
+// reads. Dates are milliseconds since the epoch, as strings.
+type appleReceiptResponse struct {
+	Status  int `json:"status"`
+	Receipt struct {
+		BundleID string `json:"bundle_id"`
+	} `json:"receipt"`
+	LatestReceipt     string `json:"latest_receipt"`
+	LatestReceiptInfo []struct {
+		ProductID             string `json:"product_id"`
+		OriginalTransactionID string `json:"original_transaction_id"`
+		ExpiresDateMS         string `json:"expires_date_ms"`
+		CancellationDateMS    string `json:"cancellation_date_ms"`
+	} `json:"latest_receipt_info"`
+	PendingRenewalInfo []struct {
+		OriginalTransactionID    string `json:"original_transaction_id"`
+		AutoRenewStatus          string `json:"auto_renew_status"`
+		IsInBillingRetryPeriod   string `json:"is_in_billing_retry_period"`
+		GracePeriodExpiresDateMS string `json:"grace_period_expires_date_ms"`
+	} `json:"pending_renewal_info"`
+}
+
+// Verify validates a base64 receipt, returning the subscription in it that
+// runs latest
+func (a *AppStore) Verify(ctx context.Context, receipt string) (Purchase, error) {
+	resp, err := a.verify(ctx, appleProductionURL, receipt)
+	if err == nil && resp.Status == appleStatusSandboxReceipt {
+		resp, err = a.verify(ctx, appleSandboxURL, receipt)
+	}
+	if err != nil {
+		return Purchase{}, err
+	}
+
+	// An expired subscription's receipt is still valid, and decoded
+	switch resp.Status {
+	case appleStatusOK, appleStatusExpired:
+	case appleStatusMalformed, appleStatusUnauthenticated, appleStatusAccountNotFound:
+		return Purchase{}, fmt.Errorf("%w: app store status %d", ErrInvalidReceipt, resp.Status)
+	default:
+		return Purchase{}, fmt.Errorf("app store: verifyReceipt failed, status %d", resp.Status)
+	}
+	if resp.Receipt.BundleID != a.bundleID {
+		return Purchase{}, fmt.Errorf("%w: receipt is for bundle %q", ErrInvalidReceipt, resp.Receipt.BundleID)
+	}
+
+	var p Purchase
+	var cancelled bool
+	for _, info := range resp.LatestReceiptInfo {
+		expires := appleTime(info.ExpiresDateMS)
+		if expires == nil || !expires.After(p.ExpiresAt) {
+			continue
+		}
+		p.PurchaseID = info.OriginalTransactionID
+		p.ProductID = info.ProductID
+		p.ExpiresAt = *expires
+		cancelled = info.CancellationDateMS != ""
+	}
+	if p.PurchaseID == "" {
+		return Purchase{}, fmt.Errorf("%w: receipt has no subscription", ErrInvalidReceipt)
+	}
+	p.Receipt = receipt
+	if resp.LatestReceipt != "" {
+		p.Receipt = resp.LatestReceipt
+	}
+
+	var inBillingRetry bool
+	for _, renewal := range resp.PendingRenewalInfo {
+		if renewal.OriginalTransactionID != p.PurchaseID {
+			continue
+		}
+		p.AutoRenew = renewal.AutoRenewStatus == "1"
+		inBillingRetry = renewal.IsInBillingRetryPeriod == "1"
+		p.GraceUntil = appleTime(renewal.GracePeriodExpiresDateMS)
+	}
+
+	now := time.Now()
+	switch {
+	case cancelled:
+		p.Status = StatusRevoked
+	case now.Before(p.ExpiresAt):
+		p.Status = StatusActive
+	case p.GraceUntil != nil && now.Before(*p.GraceUntil):
+		p.Status = StatusGracePeriod
+	case inBillingRetry:
+		p.Status = StatusBillingRetry
+	default:
+		p.Status = StatusExpired
+	}
+	return p, nil
+}
+
+func (a *AppStore) verify(ctx context.Context, url, receipt string) (*appleReceiptResponse, error) {
+	// Fields are plain strings, so they always encode
+	body, _ := json.Marshal(map[string]any{
+		"receipt-data":             receipt,
+		"password":                 a.sharedSecret,
+		"exclude-old-transactions": true,
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("app store: verifyReceipt responded %d", resp.StatusCode)
+	}
+
+	var result appleReceiptResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 4<<20)).Decode(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// appleTime parses a verifyReceipt date, or returns nil if there is none
+func appleTime(ms string) *time.Time {
+	n, err := strconv.ParseInt(ms, 10, 64)
+	if err != nil || n == 0 {
+		return nil
+	}
+	t := time.UnixMilli(n)
+	return &t
+}
+
+// AppleNotificationTransaction returns the original transaction ID an App
+// Store Server Notification is about, from a version 2 notification's
+// signed payload or a version 1 notification's unified receipt. The
+// notification's contents aren't trusted: billing only uses it to decide
+// which stored receipt to verify again.
+func AppleNotificationTransaction(body []byte) (string, error) {
+	var notification struct {
+		SignedPayload  string `json:"signedPayload"`
+		UnifiedReceipt struct {
+			LatestReceiptInfo []struct {
+				OriginalTransactionID string `json:"original_transaction_id"`
+			} `json:"latest_receipt_info"`
+		} `json:"unified_receipt"`
+	}
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return "", err
+	}
+
+	if notification.SignedPayload == "" {
+		if len(notification.UnifiedReceipt.LatestReceiptInfo) == 0 {
+			return "", errors.New("app store: notification has no transaction")
+		}
+		return notification.UnifiedReceipt.LatestReceiptInfo[0].OriginalTransactionID, nil
+	}
+
+	var payload struct {
+		Data struct {
+			SignedTransactionInfo string `json:"signedTransactionInfo"`
+		} `json:"data"`
+	}
+	if err := decodeJWSPayload(notification.SignedPayload, &payload); err != nil {
+		return "", err
+	}
+	var transaction struct {
+		OriginalTransactionID string `json:"originalTransactionId"`
+	}
+	if err := decodeJWSPayload(payload.Data.SignedTransactionInfo, &transaction); err != nil {
+		return "", err
+	}
+	if transaction.OriginalTransactionID == "" {
+		return "", errors.New("app store: notification has no transaction")
+	}
+	return transaction.OriginalTransactionID, nil
+}
+
+// decodeJWSPayload decodes a compact JWS's payload into v without checking
+// its signature
+func decodeJWSPayload(jws string, v any) error {
+	parts := strings.Split(jws, ".")
+	if len(parts) != 3 {
+		return errors.New("app store: malformed signed payload")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(payload, v)
+}
@@ -0,0 +1,25 @@
+package billing
+
+import (
+	"slices"
+
+	"brewd/internal/plans"
+)
+
+// Subscription providers
+const (
+	ProviderStripe = "stripe"
+)
+
+// supportingStatuses are the Stripe subscription statuses that keep a user
+// on the supporter plan. A past_due subscription keeps it while Stripe
+// retries the payment; Stripe cancels it if the retries fail.
+var supportingStatuses = []string{"active", "trialing", "past_due"}
+
+// PlanFor returns the plan a Stripe subscription in status grants
+func PlanFor(status string) string {
+	if slices.Contains(supportingStatuses, status) {
+		return plans.Supporter
+	}
+	return plans.Free
+}
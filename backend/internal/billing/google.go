@@ -0,0 +1,282 @@
+package billing
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// googlePublisherAPI is the base URL of the Google Play Developer API
+const googlePublisherAPI = "https://androidpublisher.googleapis.com/androidpublisher/v3/applications/"
+
+// googlePublisherScope is the OAuth scope the Play Developer API requires
+const googlePublisherScope = "https://www.googleapis.com/auth/androidpublisher"
+
+// Play subscription states billing distinguishes; expired, paused and
+// pending subscriptions aren't entitled
+const (
+	playStateActive      = "SUBSCRIPTION_STATE_ACTIVE"
+	playStateCanceled    = "SUBSCRIPTION_STATE_CANCELED"
+	playStateGracePeriod = "SUBSCRIPTION_STATE_IN_GRACE_PERIOD"
+	playStateOnHold      = "SUBSCRIPTION_STATE_ON_HOLD"
+)
+
+// playAckPending marks a purchase that must be acknowledged within three
+// days, or Google refunds it
+const playAckPending = "ACKNOWLEDGEMENT_STATE_PENDING"
+
+// PlayStore validates Google Play subscription purchase tokens with the
+// Play Developer API, authenticated as a service account
+type PlayStore struct {
+	packageName string
+	clientEmail string
+	privateKey  *rsa.PrivateKey
+	tokenURL    string
+	client      *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewPlayStore returns a Play Store verifier for packageName, using the
+// service account key in credentialsFile (the JSON key Google Cloud
+// issues)
+func NewPlayStore(packageName, credentialsFile string) (*PlayStore, error) {
+	raw, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, err
+	}
+	var credentials struct {
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(raw, &credentials); err != nil {
+		return nil, fmt.Errorf("play store: reading credentials: %w", err)
+	}
+	block, _ := pem.Decode([]byte(credentials.PrivateKey))
+	if block == nil {
+		return nil, errors.New("play store: credentials have no private key")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("play store: parsing private key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("play store: private key is not RSA")
+	}
+
+	return &PlayStore{
+		packageName: packageName,
+		clientEmail: credentials.ClientEmail,
+		privateKey:  rsaKey,
+		tokenURL:    credentials.TokenURI,
+		client:      &http.Client{Timeout: storeTimeout},
+	}, nil
+}
+
+// playSubscription is the part of a subscriptionsv2 purchase billing reads
+type playSubscription struct {
+	SubscriptionState    string `json:"subscriptionState"`
+	AcknowledgementState string `json:"acknowledgementState"`
+	LineItems            []struct {
+		ProductID        string    `json:"productId"`
+		ExpiryTime       time.Time `json:"expiryTime"`
+		AutoRenewingPlan *struct {
+			AutoRenewEnabled bool `json:"autoRenewEnabled"`
+		} `json:"autoRenewingPlan"`
+	} `json:"lineItems"`
+}
+
+// Verify validates a purchase token, acknowledging the purchase if it
+// hasn't been yet
+func (p *PlayStore) Verify(ctx context.Context, purchaseToken string) (Purchase, error) {
+	var sub playSubscription
+	path := p.packageName + "/purchases/subscriptionsv2/tokens/" + url.PathEscape(purchaseToken)
+	status, err := p.call(ctx, http.MethodGet, path, &sub)
+	if err != nil {
+		// Unknown or malformed tokens, and tokens of other apps
+		if status == http.StatusNotFound || status == http.StatusBadRequest || status == http.StatusGone {
+			return Purchase{}, fmt.Errorf("%w: %v", ErrInvalidReceipt, err)
+		}
+		return Purchase{}, err
+	}
+	if len(sub.LineItems) == 0 {
+		return Purchase{}, fmt.Errorf("%w: purchase has no subscription", ErrInvalidReceipt)
+	}
+
+	item := sub.LineItems[0]
+	purchase := Purchase{
+		PurchaseID: purchaseToken,
+		ProductID:  item.ProductID,
+		Receipt:    purchaseToken,
+		ExpiresAt:  item.ExpiryTime,
+		AutoRenew:  item.AutoRenewingPlan != nil && item.AutoRenewingPlan.AutoRenewEnabled,
+	}
+	switch sub.SubscriptionState {
+	case playStateActive, playStateCanceled:
+		// A cancelled subscription stays paid through its expiry
+		purchase.Status = StatusActive
+	case playStateGracePeriod:
+		purchase.Status = StatusGracePeriod
+	case playStateOnHold:
+		purchase.Status = StatusBillingRetry
+	default:
+		purchase.Status = StatusExpired
+	}
+
+	if sub.AcknowledgementState == playAckPending {
+		ackPath := p.packageName + "/purchases/subscriptions/" + url.PathEscape(item.ProductID) +
+			"/tokens/" + url.PathEscape(purchaseToken) + ":acknowledge"
+		if _, err := p.call(ctx, http.MethodPost, ackPath, nil); err != nil {
+			return Purchase{}, fmt.Errorf("play store: acknowledging purchase: %w", err)
+		}
+	}
+	return purchase, nil
+}
+
+// call sends a request to the Play Developer API, decoding the response
+// into v if it isn't nil. It returns the response status, if there was
+// one.
+func (p *PlayStore) call(ctx context.Context, method, path string, v any) (int, error) {
+	token, err := p.token(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var body io.Reader
+	if method == http.MethodPost {
+		body = strings.NewReader("{}")
+	}
+	req, err := http.NewRequestWithContext(ctx, method, googlePublisherAPI+path, body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("play store: %s responded %d", method, resp.StatusCode)
+	}
+	if v == nil {
+		return resp.StatusCode, nil
+	}
+	return resp.StatusCode, json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
+
+// token returns an OAuth access token for the service account, exchanging
+// a signed JWT assertion for a new one when the cached token is about to
+// expire
+func (p *PlayStore) token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if time.Until(p.expiresAt) > time.Minute {
+		return p.accessToken, nil
+	}
+
+	assertion, err := p.assertion(time.Now())
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("play store: token exchange responded %d", resp.StatusCode)
+	}
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&result); err != nil {
+		return "", err
+	}
+
+	p.accessToken = result.AccessToken
+	p.expiresAt = time.Now().Add(time.Duration(result.ExpiresIn) * time.Second)
+	return p.accessToken, nil
+}
+
+// assertion returns a JWT, signed with the service account's key, that
+// requests the Play Developer API scope
+func (p *PlayStore) assertion(now time.Time) (string, error) {
+	// Claims are plain values, so they always encode
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]any{
+		"iss":   p.clientEmail,
+		"scope": googlePublisherScope,
+		"aud":   p.tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, p.privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// PlayNotificationToken returns the purchase token a Play real-time
+// developer notification, pushed by Cloud Pub/Sub, is about. Test
+// notifications and notifications about one-time products have none.
+func PlayNotificationToken(body []byte) (string, error) {
+	var push struct {
+		Message struct {
+			Data []byte `json:"data"`
+		} `json:"message"`
+	}
+	if err := json.Unmarshal(body, &push); err != nil {
+		return "", err
+	}
+	var notification struct {
+		SubscriptionNotification *struct {
+			PurchaseToken string `json:"purchaseToken"`
+		} `json:"subscriptionNotification"`
+	}
+	if err := json.Unmarshal(push.Message.Data, &notification); err != nil {
+		return "", err
+	}
+	if notification.SubscriptionNotification == nil {
+		return "", nil
+	}
+	return notification.SubscriptionNotification.PurchaseToken, nil
+}
@@ -0,0 +1,79 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"brewd/internal/plans"
+)
+
+// App store subscription providers
+const (
+	ProviderApple  = "apple"
+	ProviderGoogle = "google"
+)
+
+// App store purchase statuses, normalized across stores
+const (
+	// The subscription is paid through ExpiresAt
+	StatusActive = "active"
+	// Renewal failed but the store keeps the subscription entitled while
+	// it retries, through GraceUntil
+	StatusGracePeriod = "grace_period"
+	// Renewal failed and the store is still retrying, without entitlement
+	StatusBillingRetry = "billing_retry"
+	// The subscription ran out or was cancelled
+	StatusExpired = "expired"
+	// The purchase was refunded or revoked
+	StatusRevoked = "revoked"
+)
+
+var (
+	ErrInvalidReceipt = errors.New("billing: receipt rejected by the store")
+	ErrOtherPurchase  = errors.New("billing: receipt verified as another purchase")
+)
+
+// Purchase is an app store subscription as the store reports it.
+// PurchaseID identifies the subscription across renewals: Apple's original
+// transaction ID or Google's purchase token. Receipt is what to verify it
+// with next time.
+type Purchase struct {
+	PurchaseID string
+	ProductID  string
+	Receipt    string
+	Status     string
+	ExpiresAt  time.Time
+	GraceUntil *time.Time
+	AutoRenew  bool
+}
+
+// Entitled reports whether the purchase grants the supporter plan at now:
+// a paid period, or a grace period the store grants while it retries a
+// failed renewal
+func (p Purchase) Entitled(now time.Time) bool {
+	switch p.Status {
+	case StatusActive:
+		return now.Before(p.ExpiresAt)
+	case StatusGracePeriod:
+		return p.GraceUntil == nil || now.Before(*p.GraceUntil)
+	default:
+		return false
+	}
+}
+
+// Plan returns the plan the purchase grants at now
+func (p Purchase) Plan(now time.Time) string {
+	if p.Entitled(now) {
+		return plans.Supporter
+	}
+	return plans.Free
+}
+
+// Verifier validates purchases with an app store. The app sells only the
+// supporter subscription, so every subscription product grants it.
+type Verifier interface {
+	// Verify returns the current state of the subscription receipt proves,
+	// or ErrInvalidReceipt if the store rejects it
+	Verify(ctx context.Context, receipt string) (Purchase, error)
+}
@@ -0,0 +1,131 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/lease"
+	"brewd/internal/logger"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// renewalBatchSize bounds how many purchases a single run verifies
+const renewalBatchSize = 100
+
+// Apply saves a purchase verified with provider for userID and applies its
+// state and plan to them. It returns pgx.ErrNoRows if the purchase belongs
+// to another user, or lapsed while another subscription makes the user a
+// supporter.
+func Apply(ctx context.Context, queries *db.Queries, provider, userID string, p Purchase) error {
+	params := db.ApplyIAPPurchaseParams{
+		Provider:   provider,
+		PurchaseID: p.PurchaseID,
+		UserID:     userID,
+		ProductID:  p.ProductID,
+		Receipt:    p.Receipt,
+		Status:     p.Status,
+		ExpiresAt:  pgtype.Timestamptz{Time: p.ExpiresAt, Valid: true},
+		AutoRenew:  p.AutoRenew,
+		Plan:       p.Plan(time.Now()),
+	}
+	if p.GraceUntil != nil {
+		params.GraceUntil = pgtype.Timestamptz{Time: *p.GraceUntil, Valid: true}
+	}
+	_, err := queries.ApplyIAPPurchase(ctx, params)
+	return err
+}
+
+// Reverify verifies a stored purchase with its store again. A receipt the
+// store no longer recognizes, such as a revoked Play purchase token, makes
+// the purchase revoked; a failure to verify it, such as a wrong shared
+// secret, is returned so it's tried again rather than revoking it.
+func Reverify(ctx context.Context, verifier Verifier, stored db.IapPurchase) (Purchase, error) {
+	purchase, err := verifier.Verify(ctx, stored.Receipt)
+	if errors.Is(err, ErrInvalidReceipt) {
+		return Purchase{
+			PurchaseID: stored.PurchaseID,
+			ProductID:  stored.ProductID,
+			Receipt:    stored.Receipt,
+			Status:     StatusRevoked,
+			ExpiresAt:  stored.ExpiresAt.Time,
+		}, nil
+	}
+	if err != nil {
+		return Purchase{}, err
+	}
+	// An App Store receipt covers every subscription of its Apple ID, and
+	// the latest running may be a newer one
+	if purchase.PurchaseID != stored.PurchaseID {
+		return Purchase{}, ErrOtherPurchase
+	}
+	return purchase, nil
+}
+
+// RunRenewals verifies app store purchases again as their paid or grace
+// periods end, every interval until ctx is cancelled, since stores don't
+// notify every renewal or expiry. Purchases of stores without a verifier
+// are left to expire.
+func RunRenewals(ctx context.Context, queries *db.Queries, verifiers map[string]Verifier, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := renewDue(ctx, queries, verifiers); err != nil {
+				logger.Error("Failed to check app store renewals", "error", err)
+			}
+			n, err := expireSubscriptions(ctx, queries)
+			if err != nil {
+				logger.Error("Failed to expire app store subscriptions", "error", err)
+			} else if n > 0 {
+				logger.Info("Expired unverifiable app store subscriptions", "count", n)
+			}
+		}
+	}
+}
+
+// renewDue verifies one batch of due purchases. A purchase that can't be
+// verified stays due and is tried again next run.
+func renewDue(ctx context.Context, queries *db.Queries, verifiers map[string]Verifier) error {
+	due, err := queries.ListDueIAPPurchases(ctx, renewalBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range due {
+		verifier, ok := verifiers[d.Provider]
+		if !ok {
+			continue
+		}
+		purchase, err := Reverify(ctx, verifier, d)
+		if err != nil {
+			logger.Warn("Failed to verify app store renewal", "provider", d.Provider, "user_id", d.UserID,
+				"purchase_id", d.PurchaseID, "error", err)
+			continue
+		}
+
+		if err := lease.Check(ctx); err != nil {
+			return err
+		}
+
+		if err := Apply(ctx, queries, d.Provider, d.UserID, purchase); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			logger.Error("Failed to apply app store renewal", "provider", d.Provider, "user_id", d.UserID, "error", err)
+		}
+	}
+	return nil
+}
+
+// expireSubscriptions expires subscriptions that could no longer be verified
+func expireSubscriptions(ctx context.Context, queries *db.Queries) (int64, error) {
+	if err := lease.Check(ctx); err != nil {
+		return 0, err
+	}
+	return queries.ExpireIAPSubscriptions(ctx)
+}
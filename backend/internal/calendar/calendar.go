@@ -0,0 +1,120 @@
+package calendar
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ContentType is the media type of an iCalendar feed
+const ContentType = "text/calendar; charset=utf-8"
+
+// prodID identifies brewd as the feed's producer
+const prodID = "-//brewd//Calendar Feed//EN"
+
+// refreshInterval is how often subscribed clients are asked to refetch
+const refreshInterval = "PT1H"
+
+// maxLineOctets is the longest content line RFC 5545 allows before folding
+const maxLineOctets = 75
+
+// Event statuses
+const (
+	StatusConfirmed = "CONFIRMED"
+	StatusTentative = "TENTATIVE"
+)
+
+// Event is a calendar entry. All-day events use only the date of Start and
+// End, with End being the day after the last one.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	URL         string
+	Status      string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	// Modified is when the event last changed, for DTSTAMP
+	Modified time.Time
+}
+
+// Write writes an iCalendar (RFC 5545) feed named name listing events
+func Write(w io.Writer, name string, events []Event) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeLine(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", prodID)
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("X-WR-CALNAME", escape(name))
+	line("REFRESH-INTERVAL;VALUE=DURATION", refreshInterval)
+	line("X-PUBLISHED-TTL", refreshInterval)
+	for _, e := range events {
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("DTSTAMP", dateTime(e.Modified))
+		if e.AllDay {
+			line("DTSTART;VALUE=DATE", e.Start.Format("20060102"))
+			line("DTEND;VALUE=DATE", e.End.Format("20060102"))
+		} else {
+			line("DTSTART", dateTime(e.Start))
+			line("DTEND", dateTime(e.End))
+		}
+		line("SUMMARY", escape(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escape(e.Description))
+		}
+		if e.URL != "" {
+			line("URL", e.URL)
+		}
+		if e.Status != "" {
+			line("STATUS", e.Status)
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// dateTime formats t as a UTC date-time value
+func dateTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escape escapes a TEXT value
+func escape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(s)
+}
+
+// writeLine writes a content line terminated by CRLF, folded into lines of
+// at most maxLineOctets octets without splitting UTF-8 sequences.
+// Continuation lines start with a space, which counts toward their length.
+func writeLine(w *bufio.Writer, s string) {
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
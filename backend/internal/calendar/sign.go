@@ -0,0 +1,41 @@
+package calendar
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+)
+
+// signatureBytes is how much of the HMAC is kept in feed URLs
+const signatureBytes = 16
+
+// Signer signs calendar feed URLs so they can be subscribed to without
+// credentials. A signature covers a user's ID and feed version; bumping the
+// version revokes every URL signed for the old one.
+type Signer struct {
+	key []byte
+}
+
+// NewSigner returns a signer keyed by secret
+func NewSigner(secret string) *Signer {
+	// Derive a dedicated key so feed signatures can't be confused with
+	// anything else signed with the same secret
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("brewd calendar feed"))
+	return &Signer{key: mac.Sum(nil)}
+}
+
+// Sign returns the signature for userID's feed at version
+func (s *Signer) Sign(userID string, version int32) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(userID))
+	mac.Write([]byte{0})
+	mac.Write([]byte(strconv.FormatInt(int64(version), 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:signatureBytes])
+}
+
+// Verify reports whether signature is valid for userID's feed at version
+func (s *Signer) Verify(userID string, version int32, signature string) bool {
+	return hmac.Equal([]byte(s.Sign(userID, version)), []byte(signature))
+}
@@ -0,0 +1,70 @@
+package captcha
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Provider names accepted by NewVerifier
+const (
+	ProviderHCaptcha  = "hcaptcha"
+	ProviderTurnstile = "turnstile"
+	ProviderPoW       = "pow"
+	ProviderNone      = "none"
+)
+
+// Header carries the token of a solved CAPTCHA on protected requests
+const Header = "X-Captcha-Token"
+
+// Verification failures. ErrMissing and ErrFailed are the client's to fix;
+// any other error means the provider couldn't be reached.
+var (
+	ErrMissing = errors.New("captcha: token missing")
+	ErrFailed  = errors.New("captcha: verification failed")
+)
+
+// Challenge is what a client needs to present the CAPTCHA: the provider's
+// widget and site key, or a proof-of-work puzzle to solve
+type Challenge struct {
+	Provider string        `json:"provider"`
+	SiteKey  string        `json:"site_key,omitempty"`
+	PoW      *PoWChallenge `json:"pow,omitempty"`
+}
+
+// Verifier checks the token a client got by solving a CAPTCHA.
+// Implementations for other providers plug in here.
+type Verifier interface {
+	Challenge() (Challenge, error)
+	Verify(ctx context.Context, token, remoteIP string) error
+}
+
+// NewVerifier returns the verifier called provider. secret and siteKey are
+// the hCaptcha or Turnstile keys; the proof-of-work verifier signs its
+// puzzles with a key derived from secret and needs difficulty leading zero
+// bits.
+func NewVerifier(provider, secret, siteKey string, difficulty int) (Verifier, error) {
+	switch provider {
+	case ProviderHCaptcha:
+		return newSiteVerifier(provider, hcaptchaVerifyURL, secret, siteKey)
+	case ProviderTurnstile:
+		return newSiteVerifier(provider, turnstileVerifyURL, secret, siteKey)
+	case ProviderPoW:
+		return NewPoW(secret, difficulty)
+	case ProviderNone:
+		return Disabled{}, nil
+	default:
+		return nil, fmt.Errorf("unknown captcha provider %q", provider)
+	}
+}
+
+// Disabled accepts every request, for development
+type Disabled struct{}
+
+func (Disabled) Challenge() (Challenge, error) {
+	return Challenge{Provider: ProviderNone}, nil
+}
+
+func (Disabled) Verify(context.Context, string, string) error {
+	return nil
+}
@@ -0,0 +1,80 @@
+package captcha
+
+import (
+	"sync"
+	"time"
+)
+
+// failureWindow is how long failed logins are remembered
+const failureWindow = 15 * time.Minute
+
+// Failures counts recent failed logins per account, so a CAPTCHA is only
+// asked for once an account sees repeated failures. Counts are kept in
+// memory per instance, like the rate limiter's.
+type Failures struct {
+	threshold int
+
+	mu     sync.Mutex
+	counts map[string]*failureCount
+	lastGC time.Time
+}
+
+type failureCount struct {
+	n    int
+	last time.Time
+}
+
+// NewFailures returns a counter that requires a CAPTCHA after threshold
+// failed logins within 15 minutes. A threshold of 0 always requires one.
+func NewFailures(threshold int) *Failures {
+	return &Failures{threshold: threshold, counts: map[string]*failureCount{}, lastGC: time.Now()}
+}
+
+// Required reports whether logging in to key needs a CAPTCHA
+func (f *Failures) Required(key string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	f.collectGarbage(now)
+	fc, ok := f.counts[key]
+	n := 0
+	if ok && now.Sub(fc.last) < failureWindow {
+		n = fc.n
+	}
+	return n >= f.threshold
+}
+
+// Fail records a failed login to key
+func (f *Failures) Fail(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	fc, ok := f.counts[key]
+	if !ok || now.Sub(fc.last) >= failureWindow {
+		fc = &failureCount{}
+		f.counts[key] = fc
+	}
+	fc.n++
+	fc.last = now
+}
+
+// Reset forgets key's failures after a successful login
+func (f *Failures) Reset(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.counts, key)
+}
+
+// collectGarbage drops counts outside the window, at most once a minute.
+// Callers hold f.mu.
+func (f *Failures) collectGarbage(now time.Time) {
+	if now.Sub(f.lastGC) < time.Minute {
+		return
+	}
+	for key, fc := range f.counts {
+		if now.Sub(fc.last) >= failureWindow {
+			delete(f.counts, key)
+		}
+	}
+	f.lastGC = now
+}
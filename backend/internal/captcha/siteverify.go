@@ -0,0 +1,94 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Verification endpoints of the hosted CAPTCHA providers, which share a
+// request and response format
+const (
+	hcaptchaVerifyURL  = "https://api.hcaptcha.com/siteverify"
+	turnstileVerifyURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+)
+
+// verifyTimeout bounds a call to a provider's verification endpoint
+const verifyTimeout = 10 * time.Second
+
+// siteVerifier verifies tokens from a hosted CAPTCHA widget (hCaptcha or
+// Cloudflare Turnstile) with the provider's siteverify endpoint
+type siteVerifier struct {
+	provider  string
+	verifyURL string
+	secret    string
+	siteKey   string
+	client    *http.Client
+}
+
+// siteVerifyResponse is the part of a siteverify response the verifier
+// reads
+type siteVerifyResponse struct {
+	Success    bool     `json:"success"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+func newSiteVerifier(provider, verifyURL, secret, siteKey string) (*siteVerifier, error) {
+	if secret == "" || siteKey == "" {
+		return nil, errors.New("captcha: secret and site key are required")
+	}
+	return &siteVerifier{
+		provider:  provider,
+		verifyURL: verifyURL,
+		secret:    secret,
+		siteKey:   siteKey,
+		client:    &http.Client{Timeout: verifyTimeout},
+	}, nil
+}
+
+func (v *siteVerifier) Challenge() (Challenge, error) {
+	return Challenge{Provider: v.provider, SiteKey: v.siteKey}, nil
+}
+
+func (v *siteVerifier) Verify(ctx context.Context, token, remoteIP string) error {
+	if token == "" {
+		return ErrMissing
+	}
+
+	form := url.Values{
+		"secret":   {v.secret},
+		"response": {token},
+		"remoteip": {remoteIP},
+	}
+	if v.provider == ProviderHCaptcha {
+		form.Set("sitekey", v.siteKey)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s siteverify returned %s", v.provider, resp.Status)
+	}
+
+	var result siteVerifyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+	if !result.Success {
+		return fmt.Errorf("%w: %s", ErrFailed, strings.Join(result.ErrorCodes, ", "))
+	}
+	return nil
+}
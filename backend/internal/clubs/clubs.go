@@ -0,0 +1,55 @@
+package clubs
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// Membership roles, from most to least privileged
+const (
+	RoleOwner     = "owner"
+	RoleModerator = "moderator"
+	RoleMember    = "member"
+)
+
+// rank orders roles by privilege; non-members rank lowest
+func rank(role string) int {
+	switch role {
+	case RoleOwner:
+		return 3
+	case RoleModerator:
+		return 2
+	case RoleMember:
+		return 1
+	}
+	return 0
+}
+
+// CanModerate reports whether role can manage members, invites and the feed
+func CanModerate(role string) bool {
+	return rank(role) >= rank(RoleModerator)
+}
+
+// Outranks reports whether actor's role is above target's, as needed to
+// remove someone from the club
+func Outranks(actor, target string) bool {
+	return rank(actor) > rank(target)
+}
+
+// NewInviteToken returns a random invite link token and the hash to store
+func NewInviteToken() (token, hash string, err error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashInviteToken(token), nil
+}
+
+// HashInviteToken returns the stored form of an invite token
+func HashInviteToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
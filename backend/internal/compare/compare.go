@@ -0,0 +1,71 @@
+package compare
+
+import "math"
+
+// Field is one parameter lined up across the compared brews. Values follow
+// the order the brews were requested in, with nil for unset values. Deltas
+// are each numeric value minus the first brew's, so the first is always 0;
+// a delta is nil when either side is unset.
+type Field struct {
+	Field   string     `json:"field"`
+	Values  []any      `json:"values"`
+	Deltas  []*float64 `json:"deltas,omitempty"`
+	Differs bool       `json:"differs"`
+}
+
+// Numeric builds a field whose values can be subtracted
+func Numeric(name string, values []*float64) Field {
+	f := Field{
+		Field:  name,
+		Values: make([]any, len(values)),
+		Deltas: make([]*float64, len(values)),
+	}
+	for i, v := range values {
+		f.Values[i] = value(v)
+		if v != nil && values[0] != nil {
+			d := round(*v - *values[0])
+			f.Deltas[i] = &d
+		}
+	}
+	f.Differs = differs(values)
+	return f
+}
+
+// Text builds a field that is only compared for equality
+func Text(name string, values []*string) Field {
+	f := Field{Field: name, Values: make([]any, len(values))}
+	for i, v := range values {
+		f.Values[i] = value(v)
+	}
+	f.Differs = differs(values)
+	return f
+}
+
+// differs reports whether any value (or its presence) differs from the first
+func differs[T comparable](values []*T) bool {
+	if len(values) == 0 {
+		return false
+	}
+	for _, v := range values[1:] {
+		if (v == nil) != (values[0] == nil) {
+			return true
+		}
+		if v != nil && *v != *values[0] {
+			return true
+		}
+	}
+	return false
+}
+
+// value dereferences p, keeping nil as an untyped nil for JSON
+func value[T any](p *T) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+// round trims float noise from unit conversions, e.g. 0.30000000000000004
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
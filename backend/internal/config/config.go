@@ -1,28 +1,259 @@
 package config
 
 import (
-	"os"
 	"fmt"
+	"os"
 	"strconv"
+	"strings"
 )
 
 type Config struct {
-	JWTSecret        string
-	Environment      string
-	LogLevel         string
-	Port             string
-	BcryptCost       int
-	JWTExpirationHrs int
+	JWTSecret                 string
+	Environment               string
+	LogLevel                  string
+	Port                      string
+	BcryptCost                int
+	JWTExpirationHrs          int
+	AvailabilityRateLimit     int
+	ReminderPollSeconds       int
+	ReorderCheckMinutes       int
+	DefaultDoseGrams          int
+	BadgePollSeconds          int
+	AdminUserIDs              []string
+	PublicBaseURL             string
+	PublicRateLimit           int
+	AutomationPollSeconds     int
+	TrendingRefreshMinutes    int
+	RecommendationPollMinutes int
+	AnalyticsSink             string
+	AnalyticsSamplePercent    int
+	EventPublisher            string
+	NATSURL                   string
+	EventPollSeconds          int
+	OutboxPollSeconds         int
+	OpsStatsMinutes           int
+	StripeSecretKey           string
+	StripeWebhookSecret       string
+	StripePriceID             string
+	AppleSharedSecret         string
+	AppleBundleID             string
+	GooglePlayPackageName     string
+	GooglePlayCredentialsFile string
+	GooglePlayNotifyToken     string
+	IAPRenewalPollMinutes     int
+	MailSender                string
+	SMTPURL                   string
+	MailFrom                  string
+	MailWebhookToken          string
+	InviteOnly                bool
+	InviteQuota               int
+	Waitlist                  bool
+	WaitlistPollSeconds       int
+	CaptchaProvider           string
+	CaptchaSecret             string
+	CaptchaSiteKey            string
+	CaptchaPoWDifficulty      int
+	CaptchaLoginFailures      int
+	DisposableEmails          string
+	DisposableDomainsURL      string
+	DisposableRefreshHours    int
+	GeoIPDatabase             string
+	LoginAlerts               bool
+	AuthEventPollSeconds      int
+	AuthEventRetentionDays    int
+	StepUpThreshold           int
+	StepUpFailures            int
+	TrustedDeviceDays         int
+	CookieSecure              bool
+	CookieDomain              string
+	OIDCIssuer                string
+	OIDCSigningKey            string
+	MinimumAge                int
+	MinimumAgeRegions         []string
+	ParentalConsent           bool
+	APIKeyRateLimit           int
+	APIKeyBurst               int
+	RequestClockSkewSeconds   int
+	RequireRequestNonce       bool
+	BrewPartitionCheckHours   int
+	PartitionRetentionMonths  int
+	BrewArchiveAfterDays      int
+	BrewArchivePollMinutes    int
+	ObjectStoreURL            string
+	ObjectStoreAccessKey      string
+	ObjectStoreSecretKey      string
+	BackupIntervalHours       int
+	BackupScratchDatabaseURL  string
+	BackupRetentionDays       int
+	AnalyticsRetentionDays    int
+	NotificationRetentionDays int
+	SoftDeletedRetentionDays  int
+	RetentionDryRun           bool
+	RetentionPollMinutes      int
+	MetricsToken              string
+	SLOAvailabilityPercent    float64
+	SLOLatencyMs              int
+	SLOLatencyPercent         float64
+	ErrorSink                 string
+	ErrorSinkURL              string
+	AccessLogSkipHealth       bool
+	TrustedProxies            []string
+	TrustedPlatform           string
+	TLSCertFile               string
+	TLSKeyFile                string
+	AutocertDomains           []string
+	AutocertCacheDir          string
+	AutocertEmail             string
+	HTTPRedirectAddr          string
+	Listen                    string
+	UnixSocketMode            uint32
+	InternalListen            string
+	WebApp                    bool
+	DrainPlatform             string
+	DrainDelaySeconds         int
+	ShutdownTimeoutSeconds    int
+	RealtimeBroker            string
+	RealtimeDatabaseURL       string
+	PhotoMaxUploadMB          int
+	PhotoPollSeconds          int
+	PhotoScreener             string
+	PhotoScreenerURL          string
+	PhotoScreenThreshold      float64
+	MediaURLSigner            string
+	MediaURLTTLMinutes        int
+	MediaPublicEndpoint       string
+	MediaCDNURL               string
+	CloudFrontKeyPairID       string
+	CloudFrontPrivateKey      string
+	UploadScanner             string
+	UploadScannerURL          string
 }
 
+// defaultDisposableDomainsURL is the community-maintained disposable email
+// domain blocklist
+const defaultDisposableDomainsURL = "https://raw.githubusercontent.com/disposable-email-domains/disposable-email-domains/main/disposable_email_blocklist.conf"
+
 func LoadConfig() *Config {
 	return &Config{
-		JWTSecret:        mustGetEnv("JWT_SECRET"),
-		Environment:      getEnvOrDefault("ENVIRONMENT", "development"),
-		LogLevel:         getEnvOrDefault("LOG_LEVEL", "INFO"),
-		Port:             getEnvOrDefault("PORT", "8080"),
-		BcryptCost:       strToInt(getEnvOrDefault("BCRYPT_COST", "10")),
-		JWTExpirationHrs: strToInt(getEnvOrDefault("JWT_EXPIRATION_HRS", "24")),
+		JWTSecret:                 mustGetEnv("JWT_SECRET"),
+		Environment:               getEnvOrDefault("ENVIRONMENT", "development"),
+		LogLevel:                  getEnvOrDefault("LOG_LEVEL", "INFO"),
+		Port:                      getEnvOrDefault("PORT", "8080"),
+		BcryptCost:                strToInt(getEnvOrDefault("BCRYPT_COST", "10")),
+		JWTExpirationHrs:          strToInt(getEnvOrDefault("JWT_EXPIRATION_HRS", "24")),
+		AvailabilityRateLimit:     strToPositiveInt(getEnvOrDefault("AVAILABILITY_RATE_LIMIT", "30")),
+		ReminderPollSeconds:       strToPositiveInt(getEnvOrDefault("REMINDER_POLL_SECONDS", "60")),
+		ReorderCheckMinutes:       strToPositiveInt(getEnvOrDefault("REORDER_CHECK_MINUTES", "60")),
+		DefaultDoseGrams:          strToPositiveInt(getEnvOrDefault("DEFAULT_DOSE_GRAMS", "18")),
+		BadgePollSeconds:          strToPositiveInt(getEnvOrDefault("BADGE_POLL_SECONDS", "30")),
+		AdminUserIDs:              strToList(os.Getenv("ADMIN_USER_IDS")),
+		PublicBaseURL:             getEnvOrDefault("PUBLIC_BASE_URL", "http://localhost:3000"),
+		PublicRateLimit:           strToPositiveInt(getEnvOrDefault("PUBLIC_RATE_LIMIT", "120")),
+		AutomationPollSeconds:     strToPositiveInt(getEnvOrDefault("AUTOMATION_POLL_SECONDS", "10")),
+		TrendingRefreshMinutes:    strToPositiveInt(getEnvOrDefault("TRENDING_REFRESH_MINUTES", "15")),
+		RecommendationPollMinutes: strToPositiveInt(getEnvOrDefault("RECOMMENDATION_POLL_MINUTES", "10")),
+		AnalyticsSink:             getEnvOrDefault("ANALYTICS_SINK", "db"),
+		AnalyticsSamplePercent:    strToInt(getEnvOrDefault("ANALYTICS_SAMPLE_PERCENT", "100")),
+		EventPublisher:            getEnvOrDefault("EVENT_PUBLISHER", "none"),
+		NATSURL:                   getEnvOrDefault("NATS_URL", "nats://localhost:4222"),
+		EventPollSeconds:          strToPositiveInt(getEnvOrDefault("EVENT_POLL_SECONDS", "5")),
+		OutboxPollSeconds:         strToPositiveInt(getEnvOrDefault("OUTBOX_POLL_SECONDS", "5")),
+		OpsStatsMinutes:           strToPositiveInt(getEnvOrDefault("OPS_STATS_MINUTES", "5")),
+		StripeSecretKey:           os.Getenv("STRIPE_SECRET_KEY"),
+		StripeWebhookSecret:       os.Getenv("STRIPE_WEBHOOK_SECRET"),
+		StripePriceID:             os.Getenv("STRIPE_PRICE_ID"),
+		AppleSharedSecret:         os.Getenv("APPLE_SHARED_SECRET"),
+		AppleBundleID:             getEnvOrDefault("APPLE_BUNDLE_ID", "app.brewd"),
+		GooglePlayPackageName:     getEnvOrDefault("GOOGLE_PLAY_PACKAGE_NAME", "app.brewd"),
+		GooglePlayCredentialsFile: os.Getenv("GOOGLE_PLAY_CREDENTIALS_FILE"),
+		GooglePlayNotifyToken:     os.Getenv("GOOGLE_PLAY_NOTIFICATION_TOKEN"),
+		IAPRenewalPollMinutes:     strToPositiveInt(getEnvOrDefault("IAP_RENEWAL_POLL_MINUTES", "15")),
+		MailSender:                getEnvOrDefault("MAIL_SENDER", "log"),
+		SMTPURL:                   os.Getenv("SMTP_URL"),
+		MailFrom:                  getEnvOrDefault("MAIL_FROM", "brewd <no-reply@brewd.app>"),
+		MailWebhookToken:          os.Getenv("MAIL_WEBHOOK_TOKEN"),
+		InviteOnly:                strToBool(getEnvOrDefault("INVITE_ONLY", "false")),
+		InviteQuota:               strToInt(getEnvOrDefault("INVITE_QUOTA", "5")),
+		Waitlist:                  strToBool(getEnvOrDefault("WAITLIST", "false")),
+		WaitlistPollSeconds:       strToPositiveInt(getEnvOrDefault("WAITLIST_POLL_SECONDS", "30")),
+		CaptchaProvider:           getEnvOrDefault("CAPTCHA_PROVIDER", "none"),
+		CaptchaSecret:             os.Getenv("CAPTCHA_SECRET"),
+		CaptchaSiteKey:            os.Getenv("CAPTCHA_SITE_KEY"),
+		CaptchaPoWDifficulty:      strToInt(getEnvOrDefault("CAPTCHA_POW_DIFFICULTY", "20")),
+		CaptchaLoginFailures:      strToInt(getEnvOrDefault("CAPTCHA_LOGIN_FAILURES", "3")),
+		DisposableEmails:          getEnvOrDefault("DISPOSABLE_EMAILS", "reject"),
+		DisposableDomainsURL:      getEnvOrDefault("DISPOSABLE_DOMAINS_URL", defaultDisposableDomainsURL),
+		DisposableRefreshHours:    strToPositiveInt(getEnvOrDefault("DISPOSABLE_REFRESH_HOURS", "24")),
+		GeoIPDatabase:             os.Getenv("GEOIP_DATABASE"),
+		LoginAlerts:               strToBool(getEnvOrDefault("LOGIN_ALERTS", "true")),
+		AuthEventPollSeconds:      strToPositiveInt(getEnvOrDefault("AUTH_EVENT_POLL_SECONDS", "30")),
+		AuthEventRetentionDays:    strToInt(getEnvOrDefault("AUTH_EVENT_RETENTION_DAYS", "180")),
+		StepUpThreshold:           strToInt(getEnvOrDefault("STEP_UP_THRESHOLD", "2")),
+		StepUpFailures:            strToInt(getEnvOrDefault("STEP_UP_FAILURES", "5")),
+		TrustedDeviceDays:         strToInt(getEnvOrDefault("TRUSTED_DEVICE_DAYS", "30")),
+		CookieSecure:              strToBool(getEnvOrDefault("COOKIE_SECURE", "true")),
+		CookieDomain:              os.Getenv("COOKIE_DOMAIN"),
+		OIDCIssuer:                os.Getenv("OIDC_ISSUER"),
+		OIDCSigningKey:            os.Getenv("OIDC_SIGNING_KEY"),
+		MinimumAge:                strToInt(getEnvOrDefault("MINIMUM_AGE", "0")),
+		MinimumAgeRegions:         strToList(os.Getenv("MINIMUM_AGE_REGIONS")),
+		ParentalConsent:           strToBool(getEnvOrDefault("PARENTAL_CONSENT", "true")),
+		APIKeyRateLimit:           strToInt(getEnvOrDefault("API_KEY_RATE_LIMIT", "60")),
+		APIKeyBurst:               strToInt(getEnvOrDefault("API_KEY_BURST", "120")),
+		RequestClockSkewSeconds:   strToInt(getEnvOrDefault("REQUEST_CLOCK_SKEW_SECONDS", "300")),
+		RequireRequestNonce:       strToBool(getEnvOrDefault("REQUIRE_REQUEST_NONCE", "false")),
+		BrewPartitionCheckHours:   strToPositiveInt(getEnvOrDefault("BREW_PARTITION_CHECK_HOURS", "24")),
+		PartitionRetentionMonths:  strToInt(getEnvOrDefault("BREW_PARTITION_RETENTION_MONTHS", "0")),
+		BrewArchiveAfterDays:      strToInt(getEnvOrDefault("BREW_ARCHIVE_AFTER_DAYS", "0")),
+		BrewArchivePollMinutes:    strToPositiveInt(getEnvOrDefault("BREW_ARCHIVE_POLL_MINUTES", "60")),
+		ObjectStoreURL:            os.Getenv("OBJECT_STORE_URL"),
+		ObjectStoreAccessKey:      os.Getenv("OBJECT_STORE_ACCESS_KEY"),
+		ObjectStoreSecretKey:      os.Getenv("OBJECT_STORE_SECRET_KEY"),
+		BackupIntervalHours:       strToInt(getEnvOrDefault("BACKUP_INTERVAL_HOURS", "24")),
+		BackupScratchDatabaseURL:  os.Getenv("BACKUP_SCRATCH_DATABASE_URL"),
+		BackupRetentionDays:       strToInt(getEnvOrDefault("BACKUP_RETENTION_DAYS", "30")),
+		AnalyticsRetentionDays:    strToInt(getEnvOrDefault("ANALYTICS_EVENT_RETENTION_DAYS", "0")),
+		NotificationRetentionDays: strToInt(getEnvOrDefault("NOTIFICATION_RETENTION_DAYS", "0")),
+		SoftDeletedRetentionDays:  strToInt(getEnvOrDefault("SOFT_DELETED_RETENTION_DAYS", "0")),
+		RetentionDryRun:           strToBool(getEnvOrDefault("RETENTION_DRY_RUN", "false")),
+		RetentionPollMinutes:      strToPositiveInt(getEnvOrDefault("RETENTION_POLL_MINUTES", "60")),
+		MetricsToken:              os.Getenv("METRICS_TOKEN"),
+		SLOAvailabilityPercent:    strToFloat(getEnvOrDefault("SLO_AVAILABILITY_PERCENT", "99.9")),
+		SLOLatencyMs:              strToInt(getEnvOrDefault("SLO_LATENCY_MS", "500")),
+		SLOLatencyPercent:         strToFloat(getEnvOrDefault("SLO_LATENCY_PERCENT", "99")),
+		ErrorSink:                 getEnvOrDefault("ERROR_SINK", "none"),
+		ErrorSinkURL:              os.Getenv("ERROR_SINK_URL"),
+		AccessLogSkipHealth:       strToBool(getEnvOrDefault("ACCESS_LOG_SKIP_HEALTH", "false")),
+		TrustedProxies:            strToList(os.Getenv("TRUSTED_PROXIES")),
+		TrustedPlatform:           os.Getenv("TRUSTED_PLATFORM"),
+		TLSCertFile:               os.Getenv("TLS_CERT_FILE"),
+		TLSKeyFile:                os.Getenv("TLS_KEY_FILE"),
+		AutocertDomains:           strToList(os.Getenv("AUTOCERT_DOMAINS")),
+		AutocertCacheDir:          getEnvOrDefault("AUTOCERT_CACHE_DIR", "autocert"),
+		AutocertEmail:             os.Getenv("AUTOCERT_EMAIL"),
+		HTTPRedirectAddr:          os.Getenv("HTTP_REDIRECT_ADDR"),
+		Listen:                    os.Getenv("LISTEN"),
+		UnixSocketMode:            strToMode(getEnvOrDefault("UNIX_SOCKET_MODE", "0660")),
+		InternalListen:            os.Getenv("INTERNAL_LISTEN"),
+		WebApp:                    strToBool(getEnvOrDefault("WEB_APP", "false")),
+		DrainPlatform:             getEnvOrDefault("DRAIN_PLATFORM", "none"),
+		DrainDelaySeconds:         optionalInt("DRAIN_DELAY_SECONDS"),
+		ShutdownTimeoutSeconds:    optionalInt("SHUTDOWN_TIMEOUT_SECONDS"),
+		RealtimeBroker:            getEnvOrDefault("REALTIME_BROKER", "local"),
+		RealtimeDatabaseURL:       os.Getenv("REALTIME_DATABASE_URL"),
+		PhotoMaxUploadMB:          strToPositiveInt(getEnvOrDefault("PHOTO_MAX_UPLOAD_MB", "15")),
+		PhotoPollSeconds:          strToPositiveInt(getEnvOrDefault("PHOTO_POLL_SECONDS", "5")),
+		PhotoScreener:             getEnvOrDefault("PHOTO_SCREENER", "none"),
+		PhotoScreenerURL:          os.Getenv("PHOTO_SCREENER_URL"),
+		PhotoScreenThreshold:      strToFloat(getEnvOrDefault("PHOTO_SCREEN_THRESHOLD", "0.8")),
+		MediaURLSigner:            getEnvOrDefault("MEDIA_URL_SIGNER", "app"),
+		MediaURLTTLMinutes:        strToInt(getEnvOrDefault("MEDIA_URL_TTL_MINUTES", "60")),
+		MediaPublicEndpoint:       os.Getenv("MEDIA_PUBLIC_ENDPOINT"),
+		MediaCDNURL:               os.Getenv("MEDIA_CDN_URL"),
+		CloudFrontKeyPairID:       os.Getenv("CLOUDFRONT_KEY_PAIR_ID"),
+		CloudFrontPrivateKey:      os.Getenv("CLOUDFRONT_PRIVATE_KEY"),
+		UploadScanner:             getEnvOrDefault("UPLOAD_SCANNER", "none"),
+		UploadScannerURL:          os.Getenv("UPLOAD_SCANNER_URL"),
 	}
 }
 
@@ -54,4 +285,65 @@ func strToInt(val string) int {
 	} else {
 		return intVal
 	}
-}
\ No newline at end of file
+}
+
+// Convert string to an int above zero, for values such as rate limits
+// that can't be turned off
+func strToPositiveInt(val string) int {
+	intVal := strToInt(val)
+	if intVal <= 0 {
+		fmt.Fprintf(os.Stderr, "ERROR: Environment variable must be positive: %d\n", intVal)
+		panic(fmt.Sprintf("Value must be positive: %d", intVal))
+	}
+	return intVal
+}
+
+// Convert an optional variable to int, or -1 when it isn't set so a
+// computed default applies
+func optionalInt(key string) int {
+	if val := os.Getenv(key); val != "" {
+		return strToInt(val)
+	}
+	return -1
+}
+
+// Convert string to float
+func strToFloat(val string) float64 {
+	if floatVal, err := strconv.ParseFloat(val, 64); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: Failed to convert environment variable to float: %v\n", err)
+		panic(err)
+	} else {
+		return floatVal
+	}
+}
+
+// Convert an octal string, e.g. 0660, to a file mode
+func strToMode(val string) uint32 {
+	if mode, err := strconv.ParseUint(val, 8, 32); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: Failed to convert environment variable to file mode: %v\n", err)
+		panic(err)
+	} else {
+		return uint32(mode)
+	}
+}
+
+// Convert string to bool
+func strToBool(val string) bool {
+	if boolVal, err := strconv.ParseBool(val); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: Failed to convert environment variable to bool: %v\n", err)
+		panic(err)
+	} else {
+		return boolVal
+	}
+}
+
+// Split a comma-separated variable, dropping empty entries
+func strToList(val string) []string {
+	var list []string
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
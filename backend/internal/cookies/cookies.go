@@ -0,0 +1,110 @@
+package cookies
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"time"
+)
+
+// Names of the cookies the API sets. Session carries the web client's JWT
+// and Device its trusted device token; scripts can read neither. CSRF is
+// the double-submit token the web client echoes in CSRFHeader.
+const (
+	Session = "brewd_session"
+	CSRF    = "brewd_csrf"
+	Device  = "brewd_device"
+)
+
+// CSRFHeader carries the CSRF cookie's value on cookie-authenticated
+// requests that change state
+const CSRFHeader = "X-CSRF-Token"
+
+// devicePath limits the device cookie to the auth endpoints, the only ones
+// that read it
+const devicePath = "/auth"
+
+// Policy scopes the cookies the API sets. Secure should only be off for
+// local development over plain HTTP. SessionTTL is how long a session
+// cookie lasts, matching the JWT inside it.
+type Policy struct {
+	Secure     bool
+	Domain     string
+	SessionTTL time.Duration
+}
+
+// SetSession stores token in the session cookie with a new CSRF token in
+// the CSRF cookie, and returns the CSRF token
+func (p Policy) SetSession(w http.ResponseWriter, token string) (string, error) {
+	csrf, err := NewCSRFToken()
+	if err != nil {
+		return "", err
+	}
+	p.set(w, Session, token, "/", p.SessionTTL, true, http.SameSiteLaxMode)
+	p.SetCSRF(w, csrf)
+	return csrf, nil
+}
+
+// SetCSRF stores token in the CSRF cookie, where the web client's scripts
+// can read it
+func (p Policy) SetCSRF(w http.ResponseWriter, token string) {
+	p.set(w, CSRF, token, "/", p.SessionTTL, false, http.SameSiteLaxMode)
+}
+
+// ClearSession deletes the session and CSRF cookies
+func (p Policy) ClearSession(w http.ResponseWriter) {
+	p.set(w, Session, "", "/", -1, true, http.SameSiteLaxMode)
+	p.set(w, CSRF, "", "/", -1, false, http.SameSiteLaxMode)
+}
+
+// SetDevice stores a trusted device token in the device cookie for ttl
+func (p Policy) SetDevice(w http.ResponseWriter, token string, ttl time.Duration) {
+	p.set(w, Device, token, devicePath, ttl, true, http.SameSiteStrictMode)
+}
+
+// set writes a cookie; a negative ttl deletes it
+func (p Policy) set(w http.ResponseWriter, name, value, path string, ttl time.Duration, httpOnly bool, sameSite http.SameSite) {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   p.Domain,
+		Secure:   p.Secure,
+		HttpOnly: httpOnly,
+		SameSite: sameSite,
+	}
+	if ttl < 0 {
+		cookie.MaxAge = -1
+	} else {
+		cookie.MaxAge = int(ttl.Seconds())
+		cookie.Expires = time.Now().Add(ttl)
+	}
+	http.SetCookie(w, cookie)
+}
+
+// NewCSRFToken returns a random CSRF token
+func NewCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// ValidCSRF reports whether a cookie-authenticated request may go ahead:
+// safe methods always may, others must echo the CSRF cookie in CSRFHeader.
+// A cross-site page can make the browser send the cookies, but can't read
+// them to set the header.
+func ValidCSRF(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	cookie, err := r.Cookie(CSRF)
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+	header := r.Header.Get(CSRFHeader)
+	return subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(header)) == 1
+}
@@ -0,0 +1,96 @@
+package cupping
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+)
+
+// MaxSamples bounds a session's samples, one per letter
+const MaxSamples = 26
+
+// Labels returns n blind labels ('A', 'B', ...) in a random order, so the
+// label a sample gets doesn't give away where it was entered
+func Labels(n int) []string {
+	labels := make([]string, n)
+	for i := range labels {
+		labels[i] = string(rune('A' + i))
+	}
+	rand.Shuffle(n, func(i, j int) {
+		labels[i], labels[j] = labels[j], labels[i]
+	})
+	return labels
+}
+
+// Stats summarizes a sample's scores, rounded to two decimal places. Fields
+// are nil when there are too few scores: StdDev (the sample standard
+// deviation) needs two, the rest one.
+type Stats struct {
+	Count  int      `json:"count"`
+	Mean   *float64 `json:"mean"`
+	Median *float64 `json:"median"`
+	StdDev *float64 `json:"std_dev"`
+	Min    *float64 `json:"min"`
+	Max    *float64 `json:"max"`
+}
+
+// Describe computes summary statistics for scores
+func Describe(scores []float64) Stats {
+	stats := Stats{Count: len(scores)}
+	if len(scores) == 0 {
+		return stats
+	}
+
+	sorted := append([]float64(nil), scores...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+
+	var sum float64
+	for _, s := range sorted {
+		sum += s
+	}
+	mean := sum / float64(n)
+
+	median := sorted[n/2]
+	if n%2 == 0 {
+		median = (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+
+	stats.Mean = round2(mean)
+	stats.Median = round2(median)
+	stats.Min = round2(sorted[0])
+	stats.Max = round2(sorted[n-1])
+
+	if n > 1 {
+		var sq float64
+		for _, s := range sorted {
+			sq += (s - mean) * (s - mean)
+		}
+		stats.StdDev = round2(math.Sqrt(sq / float64(n-1)))
+	}
+	return stats
+}
+
+// Ranks ranks samples by mean score, highest first. Tied means share a rank
+// (1, 2, 2, 4); samples without scores are unranked (nil).
+func Ranks(means []*float64) []*int {
+	ranks := make([]*int, len(means))
+	for i, m := range means {
+		if m == nil {
+			continue
+		}
+		rank := 1
+		for _, other := range means {
+			if other != nil && *other > *m {
+				rank++
+			}
+		}
+		ranks[i] = &rank
+	}
+	return ranks
+}
+
+func round2(v float64) *float64 {
+	r := math.Round(v*100) / 100
+	return &r
+}
@@ -0,0 +1,48 @@
+package currency
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// Currency is an ISO 4217 currency. Amounts are stored as integers in the
+// minor unit (cents for USD, yen for JPY), so Exponent is the number of
+// decimal places between the two.
+type Currency struct {
+	Code     string
+	Exponent int
+}
+
+// exponents lists the supported currencies and their minor unit exponents
+var exponents = map[string]int{
+	"AUD": 2, "BHD": 3, "BRL": 2, "CAD": 2, "CHF": 2, "CLP": 0, "CNY": 2,
+	"COP": 2, "CZK": 2, "DKK": 2, "EUR": 2, "GBP": 2, "HKD": 2, "HUF": 2,
+	"IDR": 2, "ILS": 2, "INR": 2, "ISK": 0, "JPY": 0, "KES": 2, "KRW": 0,
+	"KWD": 3, "MXN": 2, "MYR": 2, "NOK": 2, "NZD": 2, "PHP": 2, "PLN": 2,
+	"SEK": 2, "SGD": 2, "THB": 2, "TWD": 2, "USD": 2, "VND": 0, "ZAR": 2,
+}
+
+// Lookup resolves a currency code, case-insensitively
+func Lookup(code string) (Currency, error) {
+	code = strings.ToUpper(code)
+	exp, ok := exponents[code]
+	if !ok {
+		return Currency{}, ErrUnknownCurrency
+	}
+	return Currency{Code: code, Exponent: exp}, nil
+}
+
+// ToMinor converts an amount in major units (e.g. 14.50) to minor units
+// (1450), rounding to the nearest minor unit
+func (c Currency) ToMinor(amount float64) int64 {
+	return int64(math.Round(amount * math.Pow10(c.Exponent)))
+}
+
+// FromMinor converts an amount in minor units to major units. Fractional
+// minor amounts (e.g. a cost per cup) are rounded to the nearest minor unit.
+func (c Currency) FromMinor(minor float64) float64 {
+	return math.Round(minor) / math.Pow10(c.Exponent)
+}
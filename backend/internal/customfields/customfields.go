@@ -0,0 +1,132 @@
+package customfields
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"slices"
+	"unicode/utf8"
+
+	"brewd/internal/db"
+)
+
+// Field value types
+const (
+	TypeNumber  = "number"
+	TypeText    = "text"
+	TypeBoolean = "boolean"
+)
+
+// Types lists every field type
+var Types = []string{
+	TypeNumber,
+	TypeText,
+	TypeBoolean,
+}
+
+// MaxFields is how many custom fields a user can define
+const MaxFields = 20
+
+// MaxTextLength is the longest text value, in characters
+const MaxTextLength = 500
+
+// namePattern matches field names: they are JSON keys in brew payloads, so
+// they're kept to snake_case identifiers
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// Rule identifies why a value was rejected
+type Rule string
+
+const (
+	// RuleUnknown is a value for a field the user hasn't defined
+	RuleUnknown Rule = "unknown"
+	// RuleType is a value of the wrong type, or text over MaxTextLength
+	RuleType Rule = "type"
+)
+
+// FieldError is a single rejected value
+type FieldError struct {
+	Name string
+	Type string
+	Rule Rule
+}
+
+// ValidName reports whether name can name a field
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+// ValidType reports whether typ is a field type
+func ValidType(typ string) bool {
+	return slices.Contains(Types, typ)
+}
+
+// Validate checks values against the user's fields, returning the values to
+// store, encoded as a JSON object, and any rejected ones. Null values are
+// left out, so they clear a field.
+func Validate(fields []db.BrewCustomField, values map[string]json.RawMessage) ([]byte, []FieldError) {
+	types := make(map[string]string, len(fields))
+	for _, f := range fields {
+		types[f.Name] = f.Type
+	}
+
+	valid := make(map[string]any, len(values))
+	var errs []FieldError
+	for name, raw := range values {
+		typ, ok := types[name]
+		if !ok {
+			errs = append(errs, FieldError{Name: name, Rule: RuleUnknown})
+			continue
+		}
+		if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			continue
+		}
+		value, ok := decode(typ, raw)
+		if !ok {
+			errs = append(errs, FieldError{Name: name, Type: typ, Rule: RuleType})
+			continue
+		}
+		valid[name] = value
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	// Decoded numbers, strings and booleans always encode
+	encoded, _ := json.Marshal(valid)
+	return encoded, nil
+}
+
+// Decode returns stored values, or an empty set if they can't be decoded
+func Decode(stored []byte) map[string]any {
+	values := map[string]any{}
+	if len(stored) > 0 {
+		_ = json.Unmarshal(stored, &values)
+	}
+	return values
+}
+
+// decode parses raw as a value of typ
+func decode(typ string, raw json.RawMessage) (any, bool) {
+	switch typ {
+	case TypeNumber:
+		var v float64
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, false
+		}
+		return v, true
+	case TypeText:
+		var v string
+		if err := json.Unmarshal(raw, &v); err != nil || utf8.RuneCountInString(v) > MaxTextLength {
+			return nil, false
+		}
+		return v, true
+	case TypeBoolean:
+		var v bool
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, false
+		}
+		return v, true
+	}
+	return nil, false
+}
@@ -0,0 +1,206 @@
+// Package dbtest answers sqlc queries from a script, so handlers and the
+// router can run in tests and benchmarks without a database.
+package dbtest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Answer returns a query's rows for its arguments. A row is the struct
+// sqlc returns for the query, e.g. a db.Club or db.ListClubFeedRow, whose
+// fields are its columns in order; a query of one column is answered with
+// the column's value, converted to the type it's scanned into.
+type Answer func(args []any) []any
+
+// Script answers queries by their sqlc name, e.g. "GetClubByID", or, for
+// statements sqlc didn't generate, by their SQL
+type Script map[string]Answer
+
+// Rows answers every call with rows; with none, the query finds no rows
+// or, run for its effect, changes none
+func Rows(rows ...any) Answer {
+	return func([]any) []any { return rows }
+}
+
+// Params returns a query's arguments as its sqlc params struct, e.g. a
+// db.CreateClubParams, whose fields sqlc passes in order
+func Params[T any](args []any) T {
+	var params T
+	v := reflect.ValueOf(&params).Elem()
+	if v.NumField() != len(args) {
+		panic(fmt.Sprintf("dbtest: %s has %d fields, the query has %d arguments", v.Type(), v.NumField(), len(args)))
+	}
+	for i, arg := range args {
+		if arg != nil {
+			v.Field(i).Set(reflect.ValueOf(arg))
+		}
+	}
+	return params
+}
+
+// DB is a db.DBTX answering queries from a script. A query the script has
+// no answer for fails the test and returns an error.
+type DB struct {
+	tb     testing.TB
+	script Script
+
+	mu   sync.Mutex
+	seen []string
+}
+
+func New(tb testing.TB, script Script) *DB {
+	return &DB{tb: tb, script: script}
+}
+
+// Exec reports the statement affected as many rows as the script answers
+func (d *DB) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	name := d.record(sql)
+	affected, err := d.answer(name, args)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	return pgconn.NewCommandTag(fmt.Sprintf("UPDATE %d", len(affected))), nil
+}
+
+func (d *DB) Query(_ context.Context, sql string, args ...any) (pgx.Rows, error) {
+	name := d.record(sql)
+	rows, err := d.answer(name, args)
+	if err != nil {
+		return nil, err
+	}
+	return &result{name: name, rows: rows, at: -1}, nil
+}
+
+func (d *DB) QueryRow(_ context.Context, sql string, args ...any) pgx.Row {
+	name := d.record(sql)
+	rows, err := d.answer(name, args)
+	return &result{name: name, rows: rows, at: -1, single: true, err: err}
+}
+
+// record notes the query's sqlc name, from the comment sqlc starts it
+// with; other statements are named by their SQL
+func (d *DB) record(sql string) string {
+	name := strings.TrimSpace(sql)
+	if rest, ok := strings.CutPrefix(sql, "-- name: "); ok {
+		name, _, _ = strings.Cut(rest, " ")
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.seen = append(d.seen, name)
+	return name
+}
+
+// Take returns the names of the queries run since it was last called
+func (d *DB) Take() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	seen := d.seen
+	d.seen = nil
+	return seen
+}
+
+func (d *DB) answer(name string, args []any) ([]any, error) {
+	answer, ok := d.script[name]
+	if !ok {
+		// Errorf rather than Fatalf, as handlers may query off the test's
+		// goroutine
+		d.tb.Errorf("dbtest: no answer scripted for %s", name)
+		return nil, fmt.Errorf("dbtest: no answer scripted for %s", name)
+	}
+	return answer(args), nil
+}
+
+// result is a scripted query's rows
+type result struct {
+	name   string
+	rows   []any
+	at     int
+	single bool
+	err    error
+}
+
+func (r *result) Close()                                       {}
+func (r *result) Err() error                                   { return r.err }
+func (r *result) CommandTag() pgconn.CommandTag                { return pgconn.NewCommandTag("SELECT") }
+func (r *result) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (r *result) RawValues() [][]byte                          { return nil }
+func (r *result) Conn() *pgx.Conn                              { return nil }
+
+func (r *result) Next() bool {
+	r.at++
+	return r.at < len(r.rows)
+}
+
+func (r *result) Values() ([]any, error) {
+	return columns(r.rows[r.at]), nil
+}
+
+// columns returns the values of a row's columns: a struct's fields, or the
+// value of a one-column row
+func columns(row any) []any {
+	v := reflect.ValueOf(row)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return []any{row}
+	}
+	values := make([]any, v.NumField())
+	for i := range values {
+		values[i] = v.Field(i).Interface()
+	}
+	return values
+}
+
+// Scan sets dest to the current row's values, converting them to dest's
+// types. A row of a QueryRow is its first.
+func (r *result) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.single {
+		if len(r.rows) == 0 {
+			return pgx.ErrNoRows
+		}
+		r.at = 0
+	}
+	// A column's value may itself be a struct, such as a pgtype.Timestamptz
+	values := []any{r.rows[r.at]}
+	if len(dest) > 1 {
+		values = columns(r.rows[r.at])
+	}
+	if len(values) != len(dest) {
+		r.err = fmt.Errorf("%s scans %d columns, the script answers %d", r.name, len(dest), len(values))
+		return r.err
+	}
+	for i, value := range values {
+		target := reflect.ValueOf(dest[i]).Elem()
+		target.SetZero()
+		if value == nil {
+			continue
+		}
+		v := reflect.ValueOf(value)
+		switch {
+		case v.Type().AssignableTo(target.Type()):
+			target.Set(v)
+		case target.Kind() == reflect.Pointer && v.Type().ConvertibleTo(target.Type().Elem()):
+			p := reflect.New(target.Type().Elem())
+			p.Elem().Set(v.Convert(target.Type().Elem()))
+			target.Set(p)
+		case v.Type().ConvertibleTo(target.Type()):
+			target.Set(v.Convert(target.Type()))
+		default:
+			r.err = fmt.Errorf("%s: column %d is a %s, the script answers a %T", r.name, i, target.Type(), value)
+			return r.err
+		}
+	}
+	return nil
+}
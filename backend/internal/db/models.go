@@ -10,17 +10,292 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AnalyticsEvent struct {
+	ID         string             `json:"id"`
+	UserID     *string            `json:"user_id"`
+	SessionID  *string            `json:"session_id"`
+	Name       string             `json:"name"`
+	Properties []byte             `json:"properties"`
+	OccurredAt pgtype.Timestamptz `json:"occurred_at"`
+	ReceivedAt pgtype.Timestamptz `json:"received_at"`
+}
+
+type ApiKey struct {
+	ID         string             `json:"id"`
+	UserID     string             `json:"user_id"`
+	Name       string             `json:"name"`
+	KeyPrefix  string             `json:"key_prefix"`
+	KeyHash    string             `json:"key_hash"`
+	Scopes     []string           `json:"scopes"`
+	LastUsedAt pgtype.Timestamptz `json:"last_used_at"`
+	RevokedAt  pgtype.Timestamptz `json:"revoked_at"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+type AuthEvent struct {
+	ID          int64              `json:"id"`
+	UserID      string             `json:"user_id"`
+	Event       string             `json:"event"`
+	Ip          *string            `json:"ip"`
+	UserAgent   *string            `json:"user_agent"`
+	Country     *string            `json:"country"`
+	Region      *string            `json:"region"`
+	City        *string            `json:"city"`
+	NewLocation bool               `json:"new_location"`
+	AlertSentAt pgtype.Timestamptz `json:"alert_sent_at"`
+	CreatedAt   time.Time          `json:"created_at"`
+}
+
+type AutomationDelivery struct {
+	ID            string             `json:"id"`
+	WebhookID     string             `json:"webhook_id"`
+	Event         string             `json:"event"`
+	Payload       []byte             `json:"payload"`
+	Attempts      int32              `json:"attempts"`
+	NextAttemptAt pgtype.Timestamptz `json:"next_attempt_at"`
+	DeliveredAt   pgtype.Timestamptz `json:"delivered_at"`
+	FailedAt      pgtype.Timestamptz `json:"failed_at"`
+	CreatedAt     time.Time          `json:"created_at"`
+}
+
+type AutomationWebhook struct {
+	ID              string             `json:"id"`
+	UserID          string             `json:"user_id"`
+	Name            string             `json:"name"`
+	Format          string             `json:"format"`
+	Url             string             `json:"url"`
+	Events          []string           `json:"events"`
+	LastStatus      *int32             `json:"last_status"`
+	LastError       *string            `json:"last_error"`
+	LastDeliveredAt pgtype.Timestamptz `json:"last_delivered_at"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+}
+
+type BackupRun struct {
+	ID             string             `json:"id"`
+	Trigger        string             `json:"trigger"`
+	RequestedBy    *string            `json:"requested_by"`
+	Status         string             `json:"status"`
+	ObjectKey      *string            `json:"object_key"`
+	SizeBytes      *int64             `json:"size_bytes"`
+	Sha256         *string            `json:"sha256"`
+	Verification   *string            `json:"verification"`
+	TablesRestored *int32             `json:"tables_restored"`
+	Error          *string            `json:"error"`
+	StartedAt      pgtype.Timestamptz `json:"started_at"`
+	FinishedAt     pgtype.Timestamptz `json:"finished_at"`
+	PrunedAt       pgtype.Timestamptz `json:"pruned_at"`
+}
+
+type BadgeEvaluation struct {
+	UserID   string             `json:"user_id"`
+	QueuedAt pgtype.Timestamptz `json:"queued_at"`
+}
+
+type Bean struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	RoasterName   *string   `json:"roaster_name"`
+	BeanOrigin    *string   `json:"bean_origin"`
+	Description   *string   `json:"description"`
+	OriginCountry *string   `json:"origin_country"`
+	OriginRegion  *string   `json:"origin_region"`
+	Farm          *string   `json:"farm"`
+	Variety       *string   `json:"variety"`
+	AltitudeM     *int32    `json:"altitude_m"`
+	Process       *string   `json:"process"`
+	RoasterID     *string   `json:"roaster_id"`
+	CreatedBy     *string   `json:"created_by"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type BeanBag struct {
+	ID                string             `json:"id"`
+	OwnerID           string             `json:"owner_id"`
+	Name              string             `json:"name"`
+	Roaster           *string            `json:"roaster"`
+	BeanOrigin        *string            `json:"bean_origin"`
+	WeightGrams       float64            `json:"weight_grams"`
+	RoastedOn         pgtype.Date        `json:"roasted_on"`
+	RestDays          *int32             `json:"rest_days"`
+	AssumedDoseGrams  *float64           `json:"assumed_dose_grams"`
+	ReorderLeadDays   int32              `json:"reorder_lead_days"`
+	ReorderNotifiedAt pgtype.Timestamptz `json:"reorder_notified_at"`
+	PriceMinor        *int64             `json:"price_minor"`
+	Currency          *string            `json:"currency"`
+	PurchasedOn       pgtype.Date        `json:"purchased_on"`
+	OriginCountry     *string            `json:"origin_country"`
+	OriginRegion      *string            `json:"origin_region"`
+	Farm              *string            `json:"farm"`
+	Variety           *string            `json:"variety"`
+	AltitudeM         *int32             `json:"altitude_m"`
+	Process           *string            `json:"process"`
+	FinishedAt        pgtype.Timestamptz `json:"finished_at"`
+	CreatedAt         time.Time          `json:"created_at"`
+	UpdatedAt         time.Time          `json:"updated_at"`
+}
+
+type BeanBagFlavor struct {
+	BeanBagID    string    `json:"bean_bag_id"`
+	DescriptorID string    `json:"descriptor_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type BeanRecipe struct {
+	BeanID    string    `json:"bean_id"`
+	RecipeID  string    `json:"recipe_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 type Brew struct {
-	ID         string    `json:"id"`
-	Name       string    `json:"name"`
-	BrewMethod *string   `json:"brew_method"`
-	BeanOrigin *string   `json:"bean_origin"`
-	Roaster    *string   `json:"roaster"`
-	Notes      *string   `json:"notes"`
-	CreatedBy  *string   `json:"created_by"`
-	IsPublic   *bool     `json:"is_public"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID              string             `json:"id"`
+	Name            string             `json:"name"`
+	BrewMethod      *string            `json:"brew_method"`
+	BeanOrigin      *string            `json:"bean_origin"`
+	Roaster         *string            `json:"roaster"`
+	Notes           *string            `json:"notes"`
+	CreatedBy       *string            `json:"created_by"`
+	IsPublic        *bool              `json:"is_public"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+	DoseGrams       *float64           `json:"dose_grams"`
+	WaterGrams      *float64           `json:"water_grams"`
+	WaterTempC      *float64           `json:"water_temp_c"`
+	GrindSetting    *string            `json:"grind_setting"`
+	BrewTimeSeconds *int32             `json:"brew_time_seconds"`
+	StartedAt       pgtype.Timestamptz `json:"started_at"`
+	EndedAt         pgtype.Timestamptz `json:"ended_at"`
+	RemindAt        pgtype.Timestamptz `json:"remind_at"`
+	ReminderSentAt  pgtype.Timestamptz `json:"reminder_sent_at"`
+	RecipeID        *string            `json:"recipe_id"`
+	RecipeRevision  *int32             `json:"recipe_revision"`
+	BeanBagID       *string            `json:"bean_bag_id"`
+	CustomFields    []byte             `json:"custom_fields"`
+	SyncSeq         int64              `json:"sync_seq"`
+	SyncVector      []byte             `json:"sync_vector"`
+	OrganizationID  *string            `json:"organization_id"`
+}
+
+type BrewArchive struct {
+	ID         string             `json:"id"`
+	CreatedBy  *string            `json:"created_by"`
+	CreatedAt  time.Time          `json:"created_at"`
+	ArchivedAt pgtype.Timestamptz `json:"archived_at"`
+	Data       []byte             `json:"data"`
+}
+
+type BrewCustomField struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Unit      *string   `json:"unit"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type BrewEvent struct {
+	ID             string             `json:"id"`
+	HostID         string             `json:"host_id"`
+	Title          string             `json:"title"`
+	Description    *string            `json:"description"`
+	RecipeID       string             `json:"recipe_id"`
+	RecipeRevision int32              `json:"recipe_revision"`
+	StartsAt       pgtype.Timestamptz `json:"starts_at"`
+	TimerStartedAt pgtype.Timestamptz `json:"timer_started_at"`
+	TimerEndedAt   pgtype.Timestamptz `json:"timer_ended_at"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+}
+
+type BrewEventRsvp struct {
+	EventID   string    `json:"event_id"`
+	UserID    string    `json:"user_id"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type BrewFlavor struct {
+	BrewID       string    `json:"brew_id"`
+	DescriptorID string    `json:"descriptor_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type BrewPhoto struct {
+	ID             string             `json:"id"`
+	BrewID         string             `json:"brew_id"`
+	UserID         string             `json:"user_id"`
+	Status         string             `json:"status"`
+	ContentType    string             `json:"content_type"`
+	OriginalKey    string             `json:"original_key"`
+	OriginalBytes  int64              `json:"original_bytes"`
+	ImageKey       *string            `json:"image_key"`
+	ThumbnailKey   *string            `json:"thumbnail_key"`
+	Width          *int32             `json:"width"`
+	Height         *int32             `json:"height"`
+	ImageBytes     *int64             `json:"image_bytes"`
+	ThumbnailBytes *int64             `json:"thumbnail_bytes"`
+	ScreeningScore *float64           `json:"screening_score"`
+	Attempts       int32              `json:"attempts"`
+	NextAttemptAt  pgtype.Timestamptz `json:"next_attempt_at"`
+	Error          *string            `json:"error"`
+	ProcessedAt    pgtype.Timestamptz `json:"processed_at"`
+	CreatedAt      time.Time          `json:"created_at"`
+}
+
+type Challenge struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description"`
+	Metric      string    `json:"metric"`
+	BrewMethod  *string   `json:"brew_method"`
+	Threshold   int32     `json:"threshold"`
+	IsActive    bool      `json:"is_active"`
+	CreatedBy   *string   `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type Club struct {
+	ID          string    `json:"id"`
+	OwnerID     string    `json:"owner_id"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description"`
+	IsPrivate   bool      `json:"is_private"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type ClubInvite struct {
+	ID        string             `json:"id"`
+	ClubID    string             `json:"club_id"`
+	CreatedBy *string            `json:"created_by"`
+	TokenHash string             `json:"token_hash"`
+	MaxUses   *int32             `json:"max_uses"`
+	UseCount  int32              `json:"use_count"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	RevokedAt pgtype.Timestamptz `json:"revoked_at"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+type ClubMember struct {
+	ClubID    string    `json:"club_id"`
+	UserID    string    `json:"user_id"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ClubShare struct {
+	ID        string    `json:"id"`
+	ClubID    string    `json:"club_id"`
+	UserID    string    `json:"user_id"`
+	BrewID    *string   `json:"brew_id"`
+	RecipeID  *string   `json:"recipe_id"`
+	Note      *string   `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type Comment struct {
@@ -39,6 +314,141 @@ type CommentLike struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type CuppingParticipant struct {
+	SessionID string    `json:"session_id"`
+	UserID    string    `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CuppingSample struct {
+	ID         string    `json:"id"`
+	SessionID  string    `json:"session_id"`
+	Label      string    `json:"label"`
+	Name       string    `json:"name"`
+	Roaster    *string   `json:"roaster"`
+	BeanOrigin *string   `json:"bean_origin"`
+	BeanBagID  *string   `json:"bean_bag_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type CuppingScore struct {
+	SampleID  string    `json:"sample_id"`
+	UserID    string    `json:"user_id"`
+	Score     float64   `json:"score"`
+	Notes     *string   `json:"notes"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CuppingSession struct {
+	ID         string             `json:"id"`
+	HostID     string             `json:"host_id"`
+	Title      string             `json:"title"`
+	Notes      *string            `json:"notes"`
+	RevealedAt pgtype.Timestamptz `json:"revealed_at"`
+	CreatedAt  time.Time          `json:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+}
+
+type DeletedPhotoObject struct {
+	Key           string             `json:"key"`
+	PhotoID       string             `json:"photo_id"`
+	Attempts      int32              `json:"attempts"`
+	NextAttemptAt pgtype.Timestamptz `json:"next_attempt_at"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
+type DisposableDomain struct {
+	Domain string `json:"domain"`
+}
+
+type DomainEvent struct {
+	ID            int64              `json:"id"`
+	Topic         string             `json:"topic"`
+	AggregateID   string             `json:"aggregate_id"`
+	Payload       []byte             `json:"payload"`
+	Attempts      int32              `json:"attempts"`
+	NextAttemptAt pgtype.Timestamptz `json:"next_attempt_at"`
+	LastError     *string            `json:"last_error"`
+	PublishedAt   pgtype.Timestamptz `json:"published_at"`
+	CreatedAt     time.Time          `json:"created_at"`
+}
+
+type Draft struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	Kind       string    `json:"kind"`
+	Data       []byte    `json:"data"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	SyncSeq    int64     `json:"sync_seq"`
+	SyncVector []byte    `json:"sync_vector"`
+}
+
+type EmailDomainOverride struct {
+	Domain    string    `json:"domain"`
+	Action    string    `json:"action"`
+	Note      *string   `json:"note"`
+	CreatedBy *string   `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type EmailSuppression struct {
+	Email     string    `json:"email"`
+	Reason    string    `json:"reason"`
+	Provider  *string   `json:"provider"`
+	Detail    *string   `json:"detail"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type FlavorDescriptor struct {
+	ID       string  `json:"id"`
+	ParentID *string `json:"parent_id"`
+	Name     string  `json:"name"`
+	Depth    int32   `json:"depth"`
+}
+
+type IapPurchase struct {
+	Provider   string             `json:"provider"`
+	PurchaseID string             `json:"purchase_id"`
+	UserID     string             `json:"user_id"`
+	ProductID  string             `json:"product_id"`
+	Receipt    string             `json:"receipt"`
+	Status     string             `json:"status"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+	GraceUntil pgtype.Timestamptz `json:"grace_until"`
+	AutoRenew  bool               `json:"auto_renew"`
+	VerifiedAt pgtype.Timestamptz `json:"verified_at"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+type Invite struct {
+	Code      string             `json:"code"`
+	InviterID string             `json:"inviter_id"`
+	MaxUses   int32              `json:"max_uses"`
+	UseCount  int32              `json:"use_count"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	RevokedAt pgtype.Timestamptz `json:"revoked_at"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+type JobLease struct {
+	Name       string             `json:"name"`
+	Holder     string             `json:"holder"`
+	AcquiredAt pgtype.Timestamptz `json:"acquired_at"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+	Epoch      int64              `json:"epoch"`
+}
+
+type MediaMigrationObject struct {
+	Destination string             `json:"destination"`
+	ObjectKey   string             `json:"object_key"`
+	Version     pgtype.Timestamptz `json:"version"`
+	SizeBytes   int64              `json:"size_bytes"`
+	Sha256      string             `json:"sha256"`
+	MigratedAt  pgtype.Timestamptz `json:"migrated_at"`
+}
+
 type Medium struct {
 	ID           string    `json:"id"`
 	PostID       string    `json:"post_id"`
@@ -59,6 +469,107 @@ type Notification struct {
 	CreatedAt       time.Time `json:"created_at"`
 }
 
+type OidcAuthCode struct {
+	CodeHash      string             `json:"code_hash"`
+	ClientID      string             `json:"client_id"`
+	UserID        string             `json:"user_id"`
+	RedirectUri   string             `json:"redirect_uri"`
+	Scopes        []string           `json:"scopes"`
+	Nonce         *string            `json:"nonce"`
+	CodeChallenge *string            `json:"code_challenge"`
+	AuthTime      pgtype.Timestamptz `json:"auth_time"`
+	ExpiresAt     pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt     time.Time          `json:"created_at"`
+}
+
+type OidcClient struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	SecretHash   string    `json:"secret_hash"`
+	RedirectUris []string  `json:"redirect_uris"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type OpsDailyStat struct {
+	Day               pgtype.Date        `json:"day"`
+	Signups           int32              `json:"signups"`
+	ActiveUsers       int32              `json:"active_users"`
+	WeeklyActiveUsers int32              `json:"weekly_active_users"`
+	BrewsLogged       int32              `json:"brews_logged"`
+	Requests          int64              `json:"requests"`
+	ClientErrors      int64              `json:"client_errors"`
+	ServerErrors      int64              `json:"server_errors"`
+	ComputedAt        pgtype.Timestamptz `json:"computed_at"`
+}
+
+type OpsMethodStat struct {
+	BrewMethod string             `json:"brew_method"`
+	BrewCount  int32              `json:"brew_count"`
+	ComputedAt pgtype.Timestamptz `json:"computed_at"`
+}
+
+type OpsQueueStat struct {
+	Queue     string             `json:"queue"`
+	Depth     int64              `json:"depth"`
+	Failing   int64              `json:"failing"`
+	OldestAt  pgtype.Timestamptz `json:"oldest_at"`
+	SampledAt pgtype.Timestamptz `json:"sampled_at"`
+}
+
+type Organization struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type OrganizationMember struct {
+	OrganizationID string    `json:"organization_id"`
+	UserID         string    `json:"user_id"`
+	Role           string    `json:"role"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type OutboxMessage struct {
+	ID            int64              `json:"id"`
+	Kind          string             `json:"kind"`
+	Payload       []byte             `json:"payload"`
+	Attempts      int32              `json:"attempts"`
+	NextAttemptAt pgtype.Timestamptz `json:"next_attempt_at"`
+	LastError     *string            `json:"last_error"`
+	DispatchedAt  pgtype.Timestamptz `json:"dispatched_at"`
+	FailedAt      pgtype.Timestamptz `json:"failed_at"`
+	CreatedAt     time.Time          `json:"created_at"`
+}
+
+type ParentalConsent struct {
+	UserID      string             `json:"user_id"`
+	ParentEmail string             `json:"parent_email"`
+	TokenHash   string             `json:"token_hash"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+	GrantedAt   pgtype.Timestamptz `json:"granted_at"`
+	GrantedIp   *string            `json:"granted_ip"`
+	CreatedAt   time.Time          `json:"created_at"`
+}
+
+type PolicyAcceptance struct {
+	UserID     string             `json:"user_id"`
+	DocumentID string             `json:"document_id"`
+	Ip         *string            `json:"ip"`
+	AcceptedAt pgtype.Timestamptz `json:"accepted_at"`
+}
+
+type PolicyDocument struct {
+	ID          string             `json:"id"`
+	Kind        string             `json:"kind"`
+	Version     string             `json:"version"`
+	Url         string             `json:"url"`
+	Summary     *string            `json:"summary"`
+	PublishedAt pgtype.Timestamptz `json:"published_at"`
+	CreatedAt   time.Time          `json:"created_at"`
+}
+
 type Post struct {
 	ID          string         `json:"id"`
 	OwnerID     string         `json:"owner_id"`
@@ -83,19 +594,252 @@ type PostUserTag struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
-type User struct {
+type PourCurve struct {
 	ID                string             `json:"id"`
-	Username          string             `json:"username"`
-	Email             string             `json:"email"`
-	PasswordHash      string             `json:"password_hash"`
-	ProfilePictureUrl *string            `json:"profile_picture_url"`
-	Bio               *string            `json:"bio"`
-	Location          *string            `json:"location"`
-	JoinedAt          pgtype.Timestamptz `json:"joined_at"`
+	BrewID            string             `json:"brew_id"`
+	UserID            string             `json:"user_id"`
+	ApiKeyID          *string            `json:"api_key_id"`
+	ClientID          string             `json:"client_id"`
+	DeviceModel       *string            `json:"device_model"`
+	StartedAt         pgtype.Timestamptz `json:"started_at"`
+	SampleCount       int32              `json:"sample_count"`
+	DurationMs        int32              `json:"duration_ms"`
+	FinalGrams        *float64           `json:"final_grams"`
+	SampleTMs         []int32            `json:"sample_t_ms"`
+	SampleGrams       []float32          `json:"sample_grams"`
+	SamplePressureBar []float32          `json:"sample_pressure_bar"`
 	CreatedAt         time.Time          `json:"created_at"`
 	UpdatedAt         time.Time          `json:"updated_at"`
 }
 
+type PourSample struct {
+	CurveID     string   `json:"curve_id"`
+	TMs         int32    `json:"t_ms"`
+	Grams       float64  `json:"grams"`
+	PressureBar *float64 `json:"pressure_bar"`
+}
+
+type RealtimePresence struct {
+	ConnectionID string             `json:"connection_id"`
+	Topic        string             `json:"topic"`
+	UserID       string             `json:"user_id"`
+	InstanceID   string             `json:"instance_id"`
+	SeenAt       pgtype.Timestamptz `json:"seen_at"`
+}
+
+type Recipe struct {
+	ID                 string    `json:"id"`
+	OwnerID            string    `json:"owner_id"`
+	Name               string    `json:"name"`
+	BrewMethod         *string   `json:"brew_method"`
+	IsPublic           bool      `json:"is_public"`
+	CurrentRevision    int32     `json:"current_revision"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+	ForkedFromID       *string   `json:"forked_from_id"`
+	ForkedFromRevision *int32    `json:"forked_from_revision"`
+}
+
+type RecipeCollaborator struct {
+	RecipeID   string             `json:"recipe_id"`
+	UserID     string             `json:"user_id"`
+	Role       string             `json:"role"`
+	InvitedBy  *string            `json:"invited_by"`
+	AcceptedAt pgtype.Timestamptz `json:"accepted_at"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+type RecipeRevision struct {
+	RecipeID        string    `json:"recipe_id"`
+	Revision        int32     `json:"revision"`
+	DoseGrams       *float64  `json:"dose_grams"`
+	WaterGrams      *float64  `json:"water_grams"`
+	WaterTempC      *float64  `json:"water_temp_c"`
+	GrindSetting    *string   `json:"grind_setting"`
+	BrewTimeSeconds *int32    `json:"brew_time_seconds"`
+	Instructions    *string   `json:"instructions"`
+	Changelog       *string   `json:"changelog"`
+	RolledBackFrom  *int32    `json:"rolled_back_from"`
+	CreatedBy       *string   `json:"created_by"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type Recommendation struct {
+	UserID     string             `json:"user_id"`
+	Kind       string             `json:"kind"`
+	ItemID     string             `json:"item_id"`
+	Score      float64            `json:"score"`
+	ComputedAt pgtype.Timestamptz `json:"computed_at"`
+}
+
+type RecommendationDismissal struct {
+	UserID    string    `json:"user_id"`
+	Kind      string    `json:"kind"`
+	ItemID    string    `json:"item_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type RecommendationState struct {
+	UserID     string             `json:"user_id"`
+	ComputedAt pgtype.Timestamptz `json:"computed_at"`
+}
+
+type RequestNonce struct {
+	Source    string             `json:"source"`
+	NonceHash string             `json:"nonce_hash"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+}
+
+type ResourceShare struct {
+	ID        string    `json:"id"`
+	BrewID    *string   `json:"brew_id"`
+	RecipeID  *string   `json:"recipe_id"`
+	UserID    *string   `json:"user_id"`
+	ClubID    *string   `json:"club_id"`
+	CreatedBy *string   `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Roaster struct {
+	ID          string             `json:"id"`
+	UserID      string             `json:"user_id"`
+	Name        string             `json:"name"`
+	Website     *string            `json:"website"`
+	Location    *string            `json:"location"`
+	Description *string            `json:"description"`
+	VerifiedAt  pgtype.Timestamptz `json:"verified_at"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+type ScimClient struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	TokenPrefix string             `json:"token_prefix"`
+	TokenHash   string             `json:"token_hash"`
+	LastUsedAt  pgtype.Timestamptz `json:"last_used_at"`
+	CreatedAt   time.Time          `json:"created_at"`
+}
+
+type ScimUser struct {
+	UserID     string    `json:"user_id"`
+	ClientID   string    `json:"client_id"`
+	ExternalID *string   `json:"external_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type StepUpChallenge struct {
+	ID        string             `json:"id"`
+	UserID    string             `json:"user_id"`
+	Method    string             `json:"method"`
+	CodeHash  *string            `json:"code_hash"`
+	Attempts  int32              `json:"attempts"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+type StripeEvent struct {
+	ID         string             `json:"id"`
+	Type       string             `json:"type"`
+	ReceivedAt pgtype.Timestamptz `json:"received_at"`
+}
+
+type StorageReservation struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Bytes     int64     `json:"bytes"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type SyncTombstone struct {
+	Resource  string             `json:"resource"`
+	RecordID  string             `json:"record_id"`
+	UserID    string             `json:"user_id"`
+	Vector    []byte             `json:"vector"`
+	Seq       int64              `json:"seq"`
+	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+}
+
+type TdsReading struct {
+	ID            string             `json:"id"`
+	UserID        string             `json:"user_id"`
+	BrewID        *string            `json:"brew_id"`
+	ApiKeyID      *string            `json:"api_key_id"`
+	ClientID      *string            `json:"client_id"`
+	TdsPercent    float64            `json:"tds_percent"`
+	TemperatureC  *float64           `json:"temperature_c"`
+	BeverageGrams *float64           `json:"beverage_grams"`
+	DeviceModel   *string            `json:"device_model"`
+	MeasuredAt    pgtype.Timestamptz `json:"measured_at"`
+	CreatedAt     time.Time          `json:"created_at"`
+}
+
+type TrendingItem struct {
+	Period      string             `json:"period"`
+	Kind        string             `json:"kind"`
+	Rank        int32              `json:"rank"`
+	RecipeID    *string            `json:"recipe_id"`
+	Roaster     *string            `json:"roaster"`
+	BeanOrigin  *string            `json:"bean_origin"`
+	BrewCount   int32              `json:"brew_count"`
+	RatingCount int32              `json:"rating_count"`
+	AvgRating   *float64           `json:"avg_rating"`
+	Score       float64            `json:"score"`
+	ComputedAt  pgtype.Timestamptz `json:"computed_at"`
+}
+
+type TrustedDevice struct {
+	ID         string             `json:"id"`
+	UserID     string             `json:"user_id"`
+	TokenHash  string             `json:"token_hash"`
+	UserAgent  *string            `json:"user_agent"`
+	CreatedAt  time.Time          `json:"created_at"`
+	LastUsedAt pgtype.Timestamptz `json:"last_used_at"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+}
+
+type User struct {
+	ID                            string             `json:"id"`
+	Username                      string             `json:"username"`
+	Email                         string             `json:"email"`
+	PasswordHash                  string             `json:"password_hash"`
+	ProfilePictureUrl             *string            `json:"profile_picture_url"`
+	Bio                           *string            `json:"bio"`
+	Location                      *string            `json:"location"`
+	JoinedAt                      pgtype.Timestamptz `json:"joined_at"`
+	CreatedAt                     time.Time          `json:"created_at"`
+	UpdatedAt                     time.Time          `json:"updated_at"`
+	WeightUnit                    string             `json:"weight_unit"`
+	TemperatureUnit               string             `json:"temperature_unit"`
+	Timezone                      string             `json:"timezone"`
+	Currency                      string             `json:"currency"`
+	CalendarFeedVersion           int32              `json:"calendar_feed_version"`
+	AnalyticsConsent              bool               `json:"analytics_consent"`
+	Plan                          string             `json:"plan"`
+	StripeCustomerID              *string            `json:"stripe_customer_id"`
+	SubscriptionProvider          *string            `json:"subscription_provider"`
+	SubscriptionID                *string            `json:"subscription_id"`
+	SubscriptionStatus            *string            `json:"subscription_status"`
+	SubscriptionPeriodEnd         pgtype.Timestamptz `json:"subscription_period_end"`
+	SubscriptionCancelAtPeriodEnd bool               `json:"subscription_cancel_at_period_end"`
+	SubscriptionEventAt           pgtype.Timestamptz `json:"subscription_event_at"`
+	InviteCode                    *string            `json:"invite_code"`
+	InvitedBy                     *string            `json:"invited_by"`
+	DisposableEmail               bool               `json:"disposable_email"`
+	TotpSecret                    *string            `json:"totp_secret"`
+	TotpEnabledAt                 pgtype.Timestamptz `json:"totp_enabled_at"`
+	TotpLastStep                  *int64             `json:"totp_last_step"`
+	DeactivatedAt                 pgtype.Timestamptz `json:"deactivated_at"`
+	BirthDate                     pgtype.Date        `json:"birth_date"`
+	ConsentPending                bool               `json:"consent_pending"`
+}
+
+type UserBadge struct {
+	UserID      string             `json:"user_id"`
+	ChallengeID string             `json:"challenge_id"`
+	EarnedAt    pgtype.Timestamptz `json:"earned_at"`
+}
+
 type UserFriendship struct {
 	UserID    string    `json:"user_id"`
 	FriendID  string    `json:"friend_id"`
@@ -103,3 +847,20 @@ type UserFriendship struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+type UserStorage struct {
+	UserID     string    `json:"user_id"`
+	PhotoBytes int64     `json:"photo_bytes"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type WaitlistEntry struct {
+	Email        string             `json:"email"`
+	CreatedAt    time.Time          `json:"created_at"`
+	ApprovedAt   pgtype.Timestamptz `json:"approved_at"`
+	ApprovedBy   *string            `json:"approved_by"`
+	InviteCode   *string            `json:"invite_code"`
+	InviteSentAt pgtype.Timestamptz `json:"invite_sent_at"`
+	SendAttempts int32              `json:"send_attempts"`
+	LastError    *string            `json:"last_error"`
+}
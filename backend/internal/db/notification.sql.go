@@ -82,8 +82,8 @@ type CreateNotificationParams struct {
 //
 // Returns: Created notification record
 // Usage: Notify user of actions (like, comment, friend request, tag)
-// Types: 'like', 'comment', 'friend_request', 'tag', 'follow'
-// Reference types: 'post', 'comment', 'friendship'
+// Types: 'like', 'comment', 'friend_request', 'tag', 'follow', 'brew_reminder'
+// Reference types: 'post', 'comment', 'friendship', 'brew'
 func (q *Queries) CreateNotification(ctx context.Context, arg CreateNotificationParams) (Notification, error) {
 	row := q.db.QueryRow(ctx, createNotification,
 		arg.ID,
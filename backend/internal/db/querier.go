@@ -31,6 +31,57 @@ type Querier interface {
 	// First query: Update the pending request
 	AcceptFriendRequestUpdate(ctx context.Context, arg AcceptFriendRequestUpdateParams) (AcceptFriendRequestUpdateRow, error)
 	// ----------------------------------------------------------------------------
+	// 5. ACCEPT POLICY DOCUMENTS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id, $2 = ip, $3 = document_ids
+	// Returns: Number of acceptances recorded. Only current versions are
+	//
+	//	accepted, and ones already accepted keep their original record.
+	//
+	// Usage: Recording acceptance at registration, or of a new version
+	AcceptPolicyDocuments(ctx context.Context, arg AcceptPolicyDocumentsParams) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 10. ACCEPT RECIPE INVITATION
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = recipe_id, $2 = user_id
+	// Returns: The accepted collaborator record, or no rows if not pending
+	// Usage: Invitee accepts a collaboration invite
+	AcceptRecipeInvitation(ctx context.Context, arg AcceptRecipeInvitationParams) (RecipeCollaborator, error)
+	// ============================================================================
+	// JOB LEASE QUERIES
+	// ============================================================================
+	// Acquiring, renewing and releasing the leases that keep singleton
+	// background jobs on one instance at a time
+	// ----------------------------------------------------------------------------
+	// 1. ACQUIRE JOB LEASE
+	// ----------------------------------------------------------------------------
+	// Parameters: name, holder, ttl_seconds
+	// Returns: The lease's epoch if holder now holds it for ttl_seconds; no
+	//
+	//	rows if another holder's lease hasn't expired. The epoch goes
+	//	up whenever the lease is taken rather than renewed, including
+	//	by a holder whose own lease expired.
+	//
+	// Usage: Taking a job's lease, or renewing one already held
+	// Performance: Uses the primary key
+	AcquireJobLease(ctx context.Context, arg AcquireJobLeaseParams) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 5. ADD BEAN RECIPE
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = bean_id, $2 = recipe_id
+	// Returns: None
+	// Usage: Roaster publishes an official recipe on its listing; publishing
+	//
+	//	again is a no-op
+	AddBeanRecipe(ctx context.Context, arg AddBeanRecipeParams) error
+	// ----------------------------------------------------------------------------
+	// 9. ADD CLUB MEMBER
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = club_id, $2 = user_id
+	// Returns: Number of members added (0 if already a member)
+	// Usage: User joins a public club
+	AddClubMember(ctx context.Context, arg AddClubMemberParams) (int64, error)
+	// ----------------------------------------------------------------------------
 	// COMMENTS
 	// ----------------------------------------------------------------------------
 	// 9. ADD COMMENT (Top-level)
@@ -40,18 +91,128 @@ type Querier interface {
 	// Note: parent_comment_id is NULL for top-level comments
 	AddComment(ctx context.Context, arg AddCommentParams) (AddCommentRow, error)
 	// ----------------------------------------------------------------------------
+	// 7. ADD CUPPING PARTICIPANT
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = session_id, $2 = user_id
+	// Returns: Nothing; inviting an existing participant is a no-op
+	// Usage: Host invites a user to score
+	AddCuppingParticipant(ctx context.Context, arg AddCuppingParticipantParams) error
+	// ----------------------------------------------------------------------------
 	// 9. ADD MEDIA TO POST
 	// ----------------------------------------------------------------------------
 	// Parameters: $1 = id (ULID), $2 = post_id, $3 = url, $4 = type, $5 = display_order
 	// Returns: Created media record
 	// Usage: Attach photos/videos to a post
 	AddMediaToPost(ctx context.Context, arg AddMediaToPostParams) (Medium, error)
+	// ----------------------------------------------------------------------------
+	// 10. ADD ORGANIZATION MEMBER
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = organization_id, $2 = user_id, $3 = role
+	// Returns: The membership, or no rows if the user already is a member
+	// Usage: Owners and admins add a user to the organization
+	AddOrganizationMember(ctx context.Context, arg AddOrganizationMemberParams) (OrganizationMember, error)
+	// ----------------------------------------------------------------------------
+	// 12. ADD POUR SAMPLES
+	// ----------------------------------------------------------------------------
+	// Parameters: curve_id, t_ms, grams, pressure_bar (arrays of the same
+	//
+	//	length; NaN pressures are stored as NULL)
+	//
+	// Returns: Number of samples stored; samples already staged at the same
+	//
+	//	t_ms are ignored, so resent samples are harmless
+	//
+	// Usage: Each batch of a pour stream
+	AddPourSamples(ctx context.Context, arg AddPourSamplesParams) (int64, error)
 	// 10. ADD REPLY (Threaded comment)
 	// Parameters: $1 = id (ULID), $2 = post_id, $3 = parent_comment_id,
 	//             $4 = owner_id, $5 = content
 	// Returns: Created reply record
 	// Usage: User replies to an existing comment
 	AddReply(ctx context.Context, arg AddReplyParams) (AddReplyRow, error)
+	// ============================================================================
+	// OPS STAT QUERIES
+	// ============================================================================
+	// Operations for the admin dashboard's precomputed stats. Days are UTC.
+	// ----------------------------------------------------------------------------
+	// 1. ADD REQUEST COUNTS
+	// ----------------------------------------------------------------------------
+	// Parameters: day, requests, client_errors, server_errors
+	// Returns: None
+	// Usage: Ops stats job flushes an instance's HTTP counters; counts add up
+	//
+	//	across instances
+	AddRequestCounts(ctx context.Context, arg AddRequestCountsParams) error
+	// ----------------------------------------------------------------------------
+	// 5. APPLY IAP PURCHASE
+	// ----------------------------------------------------------------------------
+	// Parameters: provider, purchase_id, user_id, product_id, receipt, status,
+	//
+	//	expires_at, grace_until, auto_renew, plan
+	//
+	// Returns: The ID of the purchase's user
+	// Usage: Receipt submission, store notifications and renewal checks. Saves
+	//
+	//	a verified purchase and applies its state and plan to its user in
+	//	one statement. Returns no row if the purchase belongs to another
+	//	user, or if it lapsed while another subscription makes the user a
+	//	supporter.
+	ApplyIAPPurchase(ctx context.Context, arg ApplyIAPPurchaseParams) (string, error)
+	// ----------------------------------------------------------------------------
+	// 3. APPLY STRIPE SUBSCRIPTION
+	// ----------------------------------------------------------------------------
+	// Parameters: event_id, event_type, subscription_id, status, period_end,
+	//
+	//	cancel_at_period_end, plan, event_at, customer_id
+	//
+	// Returns: The ID of the customer's user
+	// Usage: Stripe webhook. Records the event and applies the subscription
+	//
+	//	state it carries in one statement, so each event is applied at
+	//	most once; returns no row for an event already processed, an
+	//	unknown customer, or state older than the user's current state.
+	//	A lapsed subscription doesn't end a supporter plan another
+	//	provider's subscription grants.
+	//
+	// Performance: Uses the stripe_customer_id unique index
+	ApplyStripeSubscription(ctx context.Context, arg ApplyStripeSubscriptionParams) (string, error)
+	// ----------------------------------------------------------------------------
+	// 3. APPROVE WAITLIST BATCH
+	// ----------------------------------------------------------------------------
+	// Parameters: emails (NULL for the oldest pending entries), codes (one
+	//
+	//	invite code per entry to approve; their count is the batch
+	//	size), approved_by, expires_at
+	//
+	// Returns: The approved entries
+	// Usage: Admin approves a batch. Each entry gets a single-use invite
+	//
+	//	created by the admin, in the same statement.
+	ApproveWaitlistBatch(ctx context.Context, arg ApproveWaitlistBatchParams) ([]WaitlistEntry, error)
+	// ============================================================================
+	// BREW ARCHIVE QUERIES
+	// ============================================================================
+	// Moving old brews into cold storage, and reading them back in history
+	// ----------------------------------------------------------------------------
+	// 1. ARCHIVE BREWS
+	// ----------------------------------------------------------------------------
+	// Parameters: after_days, batch_size
+	// Returns: Number of brews archived
+	// Usage: Archive job; moves up to batch_size brews created more than
+	//
+	//	after_days ago into brew_archive, with their flavors and pour
+	//	curve, and deletes them from brew. Brews a post, share, TDS
+	//	reading or photo links to are left in place, so nothing loses its
+	//	brew, as are organization brews, which stay in the organization's
+	//	log, and brews whose pour stream was never compacted, which
+	//	would lose their staged samples.
+	//	Archived brews get no sync tombstone (see sync_tombstone), so
+	//	offline clients keep them.
+	//
+	// Performance: Uses idx_brew_created_at; SKIP LOCKED lets instances archive
+	//
+	//	at once without waiting on each other or on users' writes
+	ArchiveBrews(ctx context.Context, arg ArchiveBrewsParams) (int64, error)
 	// ----------------------------------------------------------------------------
 	// 9. ARE USERS FRIENDS?
 	// ----------------------------------------------------------------------------
@@ -60,6 +221,48 @@ type Querier interface {
 	// Usage: Quick check if two users are friends
 	AreUsersFriends(ctx context.Context, arg AreUsersFriendsParams) (bool, error)
 	// ----------------------------------------------------------------------------
+	// 3. ATTEMPT STEP-UP CHALLENGE
+	// ----------------------------------------------------------------------------
+	// Parameters: id, max_attempts
+	// Returns: The challenge, if it hasn't expired or run out of attempts,
+	//
+	//	with what's needed to check its code and sign the user in
+	//
+	// Usage: Step-up verification; counts the attempt before the code is
+	//
+	//	checked, so concurrent guesses can't exceed max_attempts
+	AttemptStepUpChallenge(ctx context.Context, arg AttemptStepUpChallengeParams) (AttemptStepUpChallengeRow, error)
+	// ----------------------------------------------------------------------------
+	// 21. AUTOCOMPLETE ORIGINS
+	// ----------------------------------------------------------------------------
+	// Parameters: user_id, prefix (matched case-insensitively), row_limit
+	// Returns: Bean origins starting with prefix, from the user's brews and
+	//
+	//	bags and the bean catalogue, merged and ordered as for
+	//	AutocompleteRoasters
+	//
+	// Usage: Bean origin field autocomplete
+	AutocompleteOrigins(ctx context.Context, arg AutocompleteOriginsParams) ([]string, error)
+	// ----------------------------------------------------------------------------
+	// 20. AUTOCOMPLETE ROASTERS
+	// ----------------------------------------------------------------------------
+	// Parameters: user_id, prefix (matched case-insensitively), row_limit
+	// Returns: Roaster names starting with prefix, from the user's brews and
+	//
+	//	bags, registered roasters and the bean catalogue. Names differing
+	//	only in case are merged under their most common spelling; names
+	//	the user has used come first, then the most used.
+	//
+	// Usage: Roaster field autocomplete
+	AutocompleteRoasters(ctx context.Context, arg AutocompleteRoastersParams) ([]string, error)
+	// ----------------------------------------------------------------------------
+	// 11. AWARD BADGE
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id, $2 = challenge_id
+	// Returns: Number of rows inserted (0 if the badge was already earned)
+	// Usage: Badge worker; an award queues its notification in the outbox
+	AwardBadge(ctx context.Context, arg AwardBadgeParams) (int64, error)
+	// ----------------------------------------------------------------------------
 	// 12. BLOCK USER
 	// ----------------------------------------------------------------------------
 	// Parameters: $1 = blocker_user_id, $2 = blocked_user_id
@@ -73,6 +276,7 @@ type Querier interface {
 	// Parameters: $1 = email
 	// Returns: Boolean (true if available, false if taken)
 	// Usage: Real-time validation during registration
+	// Note: Emails are unique case-insensitively (idx_user_email_lower)
 	CheckEmailAvailability(ctx context.Context, email string) (bool, error)
 	// ----------------------------------------------------------------------------
 	// 12. CHECK FOR DUPLICATE NOTIFICATION
@@ -90,6 +294,17 @@ type Querier interface {
 	// Returns: Status ('accepted', 'pending', 'blocked', or NULL if no relationship)
 	// Usage: Determine relationship between two users
 	CheckFriendshipStatus(ctx context.Context, arg CheckFriendshipStatusParams) (*string, error)
+	// ----------------------------------------------------------------------------
+	// 4. CHECK JOB LEASE
+	// ----------------------------------------------------------------------------
+	// Parameters: name, holder, epoch
+	// Returns: Whether holder still holds the lease it took at epoch, unexpired
+	// Usage: Fencing a singleton job's writes: a job that lost its lease, even
+	//
+	//	if its instance has since taken it again, fails the check
+	//
+	// Performance: Uses the primary key
+	CheckJobLease(ctx context.Context, arg CheckJobLeaseParams) (bool, error)
 	// 5. CHECK IF USER LIKED POST
 	// Parameters: $1 = post_id, $2 = user_id
 	// Returns: Boolean (true if user liked this post)
@@ -101,473 +316,3716 @@ type Querier interface {
 	// Parameters: $1 = username
 	// Returns: Boolean (true if available, false if taken)
 	// Usage: Real-time validation during registration
+	// Note: Usernames are unique case-insensitively (idx_user_username_lower)
 	CheckUsernameAvailability(ctx context.Context, username string) (bool, error)
+	// ----------------------------------------------------------------------------
+	// 8. CLAIM BADGE EVALUATIONS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = limit
+	// Returns: IDs of the users claimed, oldest first
+	// Usage: Badge worker; SKIP LOCKED lets several workers share the queue
+	ClaimBadgeEvaluations(ctx context.Context, limit int32) ([]string, error)
+	// ----------------------------------------------------------------------------
+	// 5. CLAIM BREW PHOTOS
+	// ----------------------------------------------------------------------------
+	// Parameters: lease_seconds, row_limit
+	// Returns: The oldest due photos, counted as attempted and leased so other
+	//
+	//	instances skip them until processed or the lease runs out
+	//
+	// Usage: Photo processor; photos left processing by an instance that died
+	//
+	//	are claimed again once their lease expires
+	//
+	// Performance: Uses idx_brew_photo_due
+	ClaimBrewPhotos(ctx context.Context, arg ClaimBrewPhotosParams) ([]BrewPhoto, error)
+	// ----------------------------------------------------------------------------
+	// 12. CLAIM DELETED PHOTO OBJECTS
+	// ----------------------------------------------------------------------------
+	// Parameters: lease_seconds, row_limit
+	// Returns: The oldest due object keys of deleted photos, counted as
+	//
+	//	attempted and leased so other instances skip them until swept
+	//	or the lease runs out
+	//
+	// Usage: Photo processor, sweeping deleted photos from the object store;
+	//
+	//	keys it fails to delete are claimed again once their lease expires
+	//
+	// Performance: Uses idx_deleted_photo_object_due
+	ClaimDeletedPhotoObjects(ctx context.Context, arg ClaimDeletedPhotoObjectsParams) ([]DeletedPhotoObject, error)
 	// ============================================================================
-	// NOTIFICATION QUERIES
+	// DOMAIN EVENT QUERIES
 	// ============================================================================
-	// Operations for user notifications: create, fetch, mark as read
+	// Operations for the domain event outbox and its relay. Events are written
+	// by triggers (see schema/triggers.sql), not by queries.
 	// ----------------------------------------------------------------------------
-	// 1. CREATE NOTIFICATION
+	// 1. CLAIM DOMAIN EVENTS
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = id (ULID), $2 = recipient_user_id, $3 = actor_user_id,
-	//             $4 = type, $5 = reference_id, $6 = reference_type
-	// Returns: Created notification record
-	// Usage: Notify user of actions (like, comment, friend request, tag)
-	// Types: 'like', 'comment', 'friend_request', 'tag', 'follow'
-	// Reference types: 'post', 'comment', 'friendship'
-	CreateNotification(ctx context.Context, arg CreateNotificationParams) (Notification, error)
+	// Parameters: lease_seconds, row_limit
+	// Returns: The oldest due unpublished events, counted as attempted and
+	//
+	//	leased so other instances skip them until published or the lease
+	//	runs out
+	//
+	// Usage: Event relay; SKIP LOCKED lets several instances poll safely. Rows
+	//
+	//	come back in no particular order, so callers sort by id.
+	//
+	// Performance: Uses idx_domain_event_due
+	ClaimDomainEvents(ctx context.Context, arg ClaimDomainEventsParams) ([]ClaimDomainEventsRow, error)
+	// ----------------------------------------------------------------------------
+	// 7. CLAIM DUE AUTOMATION DELIVERIES
+	// ----------------------------------------------------------------------------
+	// Parameters: lease_seconds, limit
+	// Returns: Due deliveries with their webhook's format and URL, counted as
+	//
+	//	attempted and leased so other instances skip them until sent or
+	//	the lease runs out
+	//
+	// Usage: Automation delivery worker; SKIP LOCKED lets several instances poll
+	//
+	//	safely
+	//
+	// Performance: Uses idx_automation_delivery_due
+	ClaimDueAutomationDeliveries(ctx context.Context, arg ClaimDueAutomationDeliveriesParams) ([]ClaimDueAutomationDeliveriesRow, error)
+	// ----------------------------------------------------------------------------
+	// 7. CLAIM DUE BREW REMINDERS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = limit
+	// Returns: Running brews whose reminder is due, marked as sent
+	// Usage: Reminder worker; SKIP LOCKED lets several instances poll safely.
+	//
+	//	Marking a reminder sent queues its notification in the outbox.
+	//
+	// Performance: Uses idx_brew_remind_at
+	ClaimDueBrewReminders(ctx context.Context, limit int32) ([]ClaimDueBrewRemindersRow, error)
 	// ============================================================================
-	// POST QUERIES
+	// OUTBOX QUERIES
 	// ============================================================================
-	// Operations for posts: create, read, feed generation, and user posts
+	// Operations for the notification and webhook outbox and its dispatcher.
+	// Messages are written by triggers (see schema/triggers.sql), not by
+	// queries.
 	// ----------------------------------------------------------------------------
-	// 1. CREATE POST
+	// 1. CLAIM OUTBOX MESSAGES
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = id (ULID), $2 = owner_id, $3 = brew_id, $4 = title,
-	//             $5 = description, $6 = rating, $7 = visibility
-	// Returns: The created post record
-	// Usage: User creates a new coffee brew post
-	CreatePost(ctx context.Context, arg CreatePostParams) (Post, error)
+	// Parameters: lease_seconds, row_limit
+	// Returns: The oldest due messages, counted as attempted and leased so other
+	//
+	//	instances skip them until dispatched or the lease runs out
+	//
+	// Usage: Outbox dispatcher; SKIP LOCKED lets several instances poll safely
+	// Performance: Uses idx_outbox_message_due
+	ClaimOutboxMessages(ctx context.Context, arg ClaimOutboxMessagesParams) ([]ClaimOutboxMessagesRow, error)
 	// ============================================================================
-	// USER QUERIES
+	// RECOMMENDATION QUERIES
 	// ============================================================================
-	// Operations for user management: registration, profiles, search, and stats
+	// Operations for scoring per-user recipe and bean recommendations, reading
+	// them, and "not interested" feedback
 	// ----------------------------------------------------------------------------
-	// 1. CREATE USER (Registration)
+	// 1. CLAIM RECOMMENDATION USERS
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = id (ULID), $2 = username, $3 = email, $4 = password_hash
-	// Returns: The created user record
-	// Usage: Called during user registration
-	CreateUser(ctx context.Context, arg CreateUserParams) (CreateUserRow, error)
-	// 14. DELETE COMMENT
-	// Parameters: $1 = comment_id
-	// Returns: Deleted comment id
-	// Usage: User deletes their comment
-	// Note: CASCADE will also delete replies (see comment.sql schema)
-	DeleteComment(ctx context.Context, id string) (string, error)
+	// Parameters: stale_before, row_limit
+	// Returns: IDs of users with rated posts whose recommendations were never
+	//
+	//	scored or scored before stale_before, least recently scored
+	//	first; each is stamped as scored now so other workers skip it
+	//
+	// Usage: Recommendation worker
+	// Performance: Uses idx_post_owner_id and idx_recommendation_state_computed_at
+	ClaimRecommendationUsers(ctx context.Context, arg ClaimRecommendationUsersParams) ([]string, error)
+	// ============================================================================
+	// REQUEST NONCE QUERIES
+	// ============================================================================
+	// Operations for the nonces of signed inbound requests, which reject
+	// replays within the clock-skew tolerance
 	// ----------------------------------------------------------------------------
-	// 7. DELETE NOTIFICATION
+	// 1. CLAIM REQUEST NONCE
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = notification_id, $2 = recipient_user_id
-	// Returns: Deleted notification id
-	// Usage: User dismisses a notification
-	// Note: Includes recipient_user_id check for security
-	DeleteNotification(ctx context.Context, arg DeleteNotificationParams) (string, error)
+	// Parameters: source, nonce_hash, expires_at
+	// Returns: 1 if the nonce is new, 0 if the source already sent it and it
+	//
+	//	hasn't expired
+	//
+	// Usage: Verifying an inbound request. Expired nonces are deleted in the
+	//
+	//	same statement.
+	//
+	// Performance: Uses the primary key and idx_request_nonce_expires_at
+	ClaimRequestNonce(ctx context.Context, arg ClaimRequestNonceParams) (int64, error)
 	// ----------------------------------------------------------------------------
-	// 10. DELETE OLD READ NOTIFICATIONS
+	// 13. COMPACT POUR CURVE
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = recipient_user_id, $2 = days_old (e.g., 30)
-	// Returns: Number of deleted notifications
-	// Usage: Cleanup old notifications (scheduled job)
-	// Note: Only deletes READ notifications older than X days
-	DeleteOldReadNotifications(ctx context.Context, arg DeleteOldReadNotificationsParams) ([]string, error)
+	// Parameters: $1 = curve_id
+	// Returns: The curve with its staged samples merged into the sample_*
+	//
+	//	arrays and sample_count, duration_ms and final_grams
+	//	recalculated. Samples already compacted win over staged ones at
+	//	the same t_ms, as when staging. Missing pressures are compacted
+	//	as NaN.
+	//
+	// Usage: End of a pour stream
+	CompactPourCurve(ctx context.Context, curveID string) (PourCurve, error)
 	// ----------------------------------------------------------------------------
-	// 7. DELETE POST
+	// 8. COMPLETE AUTOMATION DELIVERY
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = post_id
-	// Returns: Deleted post id
-	// Usage: User deletes their post
-	// Note: CASCADE will also delete related media, likes, comments
-	DeletePost(ctx context.Context, id string) (string, error)
-	// 10. GET ACTIVE USERS
-	// Parameters: $1 = days (time window), $2 = limit
-	// Returns: Most active users by post count in time period
-	// Usage: "Active brewers" leaderboard
-	GetActiveUsers(ctx context.Context, arg GetActiveUsersParams) ([]GetActiveUsersRow, error)
+	// Parameters: id, status (HTTP status, NULL if no response), error (NULL on
+	//
+	//	success), retry_in_seconds (NULL to stop retrying)
+	//
+	// Returns: None
+	// Usage: Record a delivery attempt's outcome on the delivery and its
+	//
+	//	webhook. Successful deliveries are marked delivered; failures are
+	//	rescheduled, or marked failed when out of retries.
+	CompleteAutomationDelivery(ctx context.Context, arg CompleteAutomationDeliveryParams) error
 	// ----------------------------------------------------------------------------
-	// 3. GET ALL NOTIFICATIONS (Read and Unread)
+	// 4. COMPLETE STEP-UP CHALLENGE
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = recipient_user_id, $2 = limit, $3 = offset
-	// Returns: All notifications with actor info, paginated
-	// Usage: Notification history page
-	// Performance: Uses idx_notification_recipient
-	GetAllNotifications(ctx context.Context, arg GetAllNotificationsParams) ([]GetAllNotificationsRow, error)
+	// Parameters: id, max_attempts
+	// Returns: Number of challenges deleted (0 if it was already used, expired
+	//
+	//	or used more than max_attempts attempts)
+	//
+	// Usage: Step-up verification, after a correct code; only one request can
+	//
+	//	complete a challenge
+	CompleteStepUpChallenge(ctx context.Context, arg CompleteStepUpChallengeParams) (int64, error)
 	// ----------------------------------------------------------------------------
-	// ENGAGEMENT ANALYTICS
+	// 7. COUNT CLUB MEMBERS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = club_id
+	// Returns: Number of members, owner included
+	// Usage: Club page
+	CountClubMembers(ctx context.Context, clubID string) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 3. COUNT DISPOSABLE DOMAINS
 	// ----------------------------------------------------------------------------
-	// 11. GET AVERAGE ENGAGEMENT BY POST
 	// Parameters: None
-	// Returns: Average likes and comments per post
-	// Usage: Platform health metrics
-	GetAverageEngagementByPost(ctx context.Context) (GetAverageEngagementByPostRow, error)
+	// Returns: Size of the blocklist
+	// Usage: Admin overrides page
+	CountDisposableDomains(ctx context.Context) (int32, error)
 	// ----------------------------------------------------------------------------
-	// 14. GET BLOCKED USERS
+	// 3. COUNT EXPIRED ANALYTICS EVENTS
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = user_id (blocker)
-	// Returns: List of users blocked by current user
-	// Usage: Display blocked users list
-	GetBlockedUsers(ctx context.Context, userID string) ([]GetBlockedUsersRow, error)
+	// Parameters: retention_days
+	// Returns: Number of analytics events received more than retention_days ago
+	// Usage: Retention job dry runs, admin retention report
+	// Note: Ages by received_at, since occurred_at is whatever the client sent
+	// Performance: Uses idx_analytics_event_received_at
+	CountExpiredAnalyticsEvents(ctx context.Context, retentionDays int32) (int64, error)
+	// ============================================================================
+	// RETENTION QUERIES
+	// ============================================================================
+	// Counting and purging rows past their data class's retention period. Each
+	// class has a count, for dry runs and the admin report, and a purge that
+	// deletes one batch, which the retention job repeats until none are left.
 	// ----------------------------------------------------------------------------
-	// CONTENT ANALYTICS
+	// 1. COUNT EXPIRED AUTH EVENTS
 	// ----------------------------------------------------------------------------
-	// 8. GET BREW METHOD DISTRIBUTION
-	// Parameters: None
-	// Returns: Usage statistics by brew method
-	// Usage: "Most popular brew methods" chart
-	GetBrewMethodDistribution(ctx context.Context) ([]GetBrewMethodDistributionRow, error)
-	// 15. GET COMMENT COUNT FOR POST
-	// Parameters: $1 = post_id
-	// Returns: Total number of comments (including replies)
-	// Usage: Display "X comments" on post preview
-	GetCommentCount(ctx context.Context, postID string) (int64, error)
-	// 8. GET COMMENT LIKE COUNT
-	// Parameters: $1 = comment_id
-	// Returns: Number of likes on a comment
-	// Usage: Display like count on comments
-	GetCommentLikeCount(ctx context.Context, commentID string) (int64, error)
-	// 11. GET COMMENTS FOR POST (Top-level only)
-	// Parameters: $1 = post_id
-	// Returns: Top-level comments with user info, reply count, like count
-	// Usage: Display comment section (fetch replies separately)
-	// Performance: Uses idx_comment_post_id and idx_comment_parent_comment_id
-	GetCommentsForPost(ctx context.Context, postID string) ([]GetCommentsForPostRow, error)
-	// 6. GET DAILY ACTIVE USERS
-	// Parameters: $1 = days_back (e.g., 7 for last week)
-	// Returns: Count of users who posted, liked, or commented each day
-	// Usage: DAU/MAU tracking
-	GetDailyActiveUsers(ctx context.Context, dollar_1 interface{}) ([]GetDailyActiveUsersRow, error)
-	// 12. GET ENGAGEMENT RATE BY USER
-	// Parameters: $1 = user_id
-	// Returns: User's posts with engagement metrics
-	// Usage: Show user which posts performed best
-	GetEngagementRateByUser(ctx context.Context, ownerID string) ([]GetEngagementRateByUserRow, error)
+	// Parameters: retention_days
+	// Returns: Number of auth events older than retention_days
+	// Usage: Retention job dry runs, admin retention report
+	// Performance: Uses idx_auth_event_created_at
+	CountExpiredAuthEvents(ctx context.Context, retentionDays int32) (int64, error)
 	// ----------------------------------------------------------------------------
-	// 11. GET FRIEND COUNT
+	// 5. COUNT EXPIRED NOTIFICATIONS
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = user_id
-	// Returns: Number of accepted friends
-	// Usage: Display friend count on profile
-	GetFriendCount(ctx context.Context, userID string) (int64, error)
+	// Parameters: retention_days
+	// Returns: Number of notifications older than retention_days, read or not
+	// Usage: Retention job dry runs, admin retention report
+	// Performance: Uses idx_notification_created_at
+	CountExpiredNotifications(ctx context.Context, retentionDays int32) (int64, error)
 	// ----------------------------------------------------------------------------
-	// 4. GET FRIEND LIST
+	// 7. COUNT EXPIRED REVOKED API KEYS
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = user_id
-	// Returns: All accepted friends with user details
-	// Usage: Display user's friends list
-	// Performance: Uses idx_user_friendships_status, bidirectional storage makes query simple
-	GetFriendList(ctx context.Context, userID string) ([]GetFriendListRow, error)
-	// 7. GET GROWTH METRICS
-	// Parameters: $1 = days (time window)
-	// Returns: New users, posts, and engagement over time
-	// Usage: Growth tracking dashboard
-	GetGrowthMetrics(ctx context.Context, dollar_1 interface{}) ([]GetGrowthMetricsRow, error)
-	// 15. GET INACTIVE USERS
-	// Parameters: $1 = days_inactive (e.g., 30)
-	// Returns: Users who haven't posted, liked, or commented recently
-	// Usage: Re-engagement campaigns
-	GetInactiveUsers(ctx context.Context, dollar_1 interface{}) ([]GetInactiveUsersRow, error)
+	// Parameters: retention_days
+	// Returns: Number of API keys revoked more than retention_days ago
+	// Usage: Retention job dry runs, admin retention report
+	// Performance: Sequential scan; API keys are few
+	CountExpiredRevokedAPIKeys(ctx context.Context, retentionDays int32) (int64, error)
 	// ----------------------------------------------------------------------------
-	// 10. GET MEDIA FOR POST
+	// 9. COUNT EXPIRED REVOKED CLUB INVITES
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = post_id
-	// Returns: All media items for a post, ordered by display_order
-	// Usage: Display post images/videos
-	GetMediaForPost(ctx context.Context, postID string) ([]Medium, error)
-	// 13. GET MOST ENGAGING CONTENT
-	// Parameters: $1 = days, $2 = limit
-	// Returns: Posts with highest engagement rate
-	// Usage: Identify viral content
-	GetMostEngagingContent(ctx context.Context, arg GetMostEngagingContentParams) ([]GetMostEngagingContentRow, error)
+	// Parameters: retention_days
+	// Returns: Number of club invites revoked more than retention_days ago
+	// Usage: Retention job dry runs, admin retention report
+	// Performance: Sequential scan; club invites are few
+	CountExpiredRevokedClubInvites(ctx context.Context, retentionDays int32) (int64, error)
 	// ----------------------------------------------------------------------------
-	// 10. GET MUTUAL FRIENDS
+	// 11. COUNT EXPIRED REVOKED INVITES
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = user_a_id, $2 = user_b_id
-	// Returns: Users who are friends with both user A and user B
+	// Parameters: retention_days
+	// Returns: Number of invites revoked more than retention_days ago
+	// Usage: Retention job dry runs, admin retention report
+	// Performance: Sequential scan; invites are few
+	CountExpiredRevokedInvites(ctx context.Context, retentionDays int32) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 2. COUNT FLAVOR DESCRIPTORS
+	// ----------------------------------------------------------------------------
+	// Parameters: ids (descriptor IDs)
+	// Returns: How many of the IDs exist
+	// Usage: Validate descriptor IDs before tagging
+	CountFlavorDescriptors(ctx context.Context, ids []string) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 2. COUNT MEDIA OBJECTS
+	// ----------------------------------------------------------------------------
+	// Parameters: destination
+	// Returns: How many objects the database refers to, and how many of them
+	//
+	//	have been migrated to destination at their current version
+	//
+	// Usage: Media migration tool, reporting progress
+	// Performance: Scans brew_photo and backup_run
+	CountMediaObjects(ctx context.Context, destination string) (CountMediaObjectsRow, error)
+	// ----------------------------------------------------------------------------
+	// 7. COUNT ORGANIZATION MEMBERS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = organization_id
+	// Returns: Number of members, owners included
+	// Usage: Organization page
+	CountOrganizationMembers(ctx context.Context, organizationID string) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 8. COUNT ORGANIZATION OWNERS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = organization_id
+	// Returns: Number of owners
+	// Usage: Keep the last owner from leaving, being removed or demoted
+	CountOrganizationOwners(ctx context.Context, organizationID string) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 6. COUNT PUBLIC PROFILES
+	// ----------------------------------------------------------------------------
+	// Parameters: None
+	// Returns: Number of users listed by ListPublicProfiles
+	// Usage: Sizing the profile sitemaps
+	CountPublicProfiles(ctx context.Context) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 4. COUNT PUBLIC RECIPES
+	// ----------------------------------------------------------------------------
+	// Parameters: None
+	// Returns: Number of public recipes
+	// Usage: Sizing the recipe sitemaps
+	CountPublicRecipes(ctx context.Context) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 9. COUNT SCIM USERS
+	// ----------------------------------------------------------------------------
+	// Parameters: client_id, username, external_id, email, as for ListSCIMUsers
+	// Returns: How many accounts match in all
+	// Usage: The total SCIM list responses report alongside a page
+	CountSCIMUsers(ctx context.Context, arg CountSCIMUsersParams) (int32, error)
+	// ----------------------------------------------------------------------------
+	// 4. COUNT USER DRAFTS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id
+	// Returns: How many drafts the user has
+	// Usage: Enforce the per-user draft limit
+	// Performance: Uses idx_draft_user_kind
+	CountUserDrafts(ctx context.Context, userID string) (int64, error)
+	// ============================================================================
+	// API KEY QUERIES
+	// ============================================================================
+	// Operations for the API keys companion apps and device bridges authenticate
+	// with
+	// ----------------------------------------------------------------------------
+	// 1. CREATE API KEY
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id, $2 = user_id, $3 = name, $4 = key_prefix,
+	//
+	//	$5 = key_hash, $6 = scopes
+	//
+	// Returns: The created key (the key itself is never stored)
+	// Usage: User issues a key for a companion app
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error)
+	// ----------------------------------------------------------------------------
+	// 6. CREATE AUTOMATION DELIVERY
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id, $2 = webhook_id, $3 = event, $4 = payload
+	// Returns: None
+	// Usage: Queue an event for a webhook; the delivery worker sends it
+	CreateAutomationDelivery(ctx context.Context, arg CreateAutomationDeliveryParams) error
+	// ============================================================================
+	// AUTOMATION QUERIES
+	// ============================================================================
+	// Operations for smart-home webhooks and their delivery queue
+	// ----------------------------------------------------------------------------
+	// 1. CREATE AUTOMATION WEBHOOK
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id, $2 = user_id, $3 = name, $4 = format, $5 = url,
+	//
+	//	$6 = events
+	//
+	// Returns: The created webhook
+	// Usage: User connects Home Assistant or IFTTT
+	CreateAutomationWebhook(ctx context.Context, arg CreateAutomationWebhookParams) (AutomationWebhook, error)
+	// ============================================================================
+	// BEAN CATALOGUE QUERIES
+	// ============================================================================
+	// Operations for the community bean catalogue, roasters' official listings
+	// in it and the official recipes on those listings
+	// ----------------------------------------------------------------------------
+	// 1. CREATE BEAN
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id, $2 = name, $3 = roaster_name, $4 = bean_origin,
+	//
+	//	$5 = description, $6 = roaster_id (official listings only),
+	//	$7 = created_by, $8 = origin_country, $9 = origin_region,
+	//	$10 = farm, $11 = variety, $12 = altitude_m, $13 = process
+	//
+	// Returns: The created bean
+	// Usage: Community entry or official listing
+	CreateBean(ctx context.Context, arg CreateBeanParams) (Bean, error)
+	// ============================================================================
+	// BEAN BAG QUERIES
+	// ============================================================================
+	// Operations for bean bags: create, read, update, and consumption forecasting
+	// ----------------------------------------------------------------------------
+	// 1. CREATE BEAN BAG
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id (ULID), $2 = owner_id, $3 = name, $4 = roaster,
+	//
+	//	$5 = bean_origin, $6 = weight_grams, $7 = roasted_on,
+	//	$8 = assumed_dose_grams, $9 = reorder_lead_days,
+	//	$10 = price_minor, $11 = currency, $12 = purchased_on,
+	//	$13 = origin_country, $14 = origin_region, $15 = farm,
+	//	$16 = variety, $17 = altitude_m, $18 = process,
+	//	$19 = rest_days
+	//
+	// Returns: The created bean bag record
+	// Usage: User adds a new bag of beans (weight already converted to grams,
+	//
+	//	price to minor units)
+	CreateBeanBag(ctx context.Context, arg CreateBeanBagParams) (BeanBag, error)
+	// ============================================================================
+	// BREW QUERIES
+	// ============================================================================
+	// Operations for logged brews: create, read, and brew history
+	// ----------------------------------------------------------------------------
+	// 1. CREATE BREW
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id (ULID), $2 = name, $3 = brew_method, $4 = bean_origin,
+	//
+	//	$5 = roaster, $6 = notes, $7 = created_by, $8 = is_public,
+	//	$9 = dose_grams, $10 = water_grams, $11 = water_temp_c,
+	//	$12 = grind_setting, $13 = brew_time_seconds, $14 = started_at,
+	//	$15 = ended_at, $16 = recipe_id, $17 = recipe_revision,
+	//	$18 = bean_bag_id, $19 = custom_fields (NULL for none),
+	//	$20 = created_at (NULL for now; set when logging past brews),
+	//	$21 = sync_vector (NULL unless pushed by an offline client),
+	//	$22 = organization_id (NULL for a personal brew)
+	//
+	// Returns: The created brew record
+	// Usage: User logs a new brew (values already converted to grams / Celsius,
+	//
+	//	custom fields already validated)
+	CreateBrew(ctx context.Context, arg CreateBrewParams) (Brew, error)
+	// ----------------------------------------------------------------------------
+	// 12. CREATE BREW CUSTOM FIELD
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id (ULID), $2 = user_id, $3 = name, $4 = type, $5 = unit
+	// Returns: The created field
+	// Usage: User adds a field to their brews; fails on a duplicate name
+	CreateBrewCustomField(ctx context.Context, arg CreateBrewCustomFieldParams) (BrewCustomField, error)
+	// ============================================================================
+	// BREW EVENT QUERIES
+	// ============================================================================
+	// Operations for brew-along events: scheduling, RSVPs and the shared timer
+	// ----------------------------------------------------------------------------
+	// 1. CREATE BREW EVENT
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id, $2 = host_id, $3 = title, $4 = description,
+	//
+	//	$5 = recipe_id, $6 = recipe_revision, $7 = starts_at
+	//
+	// Returns: The created event
+	// Usage: Host schedules a brew-along
+	CreateBrewEvent(ctx context.Context, arg CreateBrewEventParams) (BrewEvent, error)
+	// ============================================================================
+	// BREW PHOTO QUERIES
+	// ============================================================================
+	// Photos attached to brews, and the queue the photo processor works
+	// through after each upload
+	// ----------------------------------------------------------------------------
+	// 1. CREATE BREW PHOTO
+	// ----------------------------------------------------------------------------
+	// Parameters: id, brew_id, user_id, content_type, original_key,
+	//
+	//	original_bytes
+	//
+	// Returns: The photo, pending processing
+	// Usage: Uploading a photo, once the original is stored
+	CreateBrewPhoto(ctx context.Context, arg CreateBrewPhotoParams) (BrewPhoto, error)
+	// ============================================================================
+	// BADGE QUERIES
+	// ============================================================================
+	// Operations for challenges, the badges users earn by completing them and
+	// the queue of users awaiting evaluation
+	// ----------------------------------------------------------------------------
+	// 1. CREATE CHALLENGE
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id, $2 = name, $3 = description, $4 = metric,
+	//
+	//	$5 = brew_method, $6 = threshold, $7 = created_by
+	//
+	// Returns: The created challenge
+	// Usage: Admin defines a new challenge
+	CreateChallenge(ctx context.Context, arg CreateChallengeParams) (Challenge, error)
+	// ============================================================================
+	// CLUB QUERIES
+	// ============================================================================
+	// Operations for clubs: membership and roles, invite links and the shared
+	// feed
+	// ----------------------------------------------------------------------------
+	// 1. CREATE CLUB
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id, $2 = owner_id, $3 = name, $4 = description,
+	//
+	//	$5 = is_private
+	//
+	// Returns: The created club
+	// Usage: Create a club with its owner as the first member, in one statement
+	CreateClub(ctx context.Context, arg CreateClubParams) (Club, error)
+	// ----------------------------------------------------------------------------
+	// 13. CREATE CLUB INVITE
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id, $2 = club_id, $3 = created_by, $4 = token_hash,
+	//
+	//	$5 = max_uses, $6 = expires_at
+	//
+	// Returns: The created invite
+	// Usage: Moderator creates an invite link
+	CreateClubInvite(ctx context.Context, arg CreateClubInviteParams) (ClubInvite, error)
+	// ----------------------------------------------------------------------------
+	// 18. CREATE CLUB SHARE
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id, $2 = club_id, $3 = user_id, $4 = brew_id,
+	//
+	//	$5 = recipe_id, $6 = note
+	//
+	// Returns: The share
+	// Usage: Member shares a brew or recipe to the club feed
+	CreateClubShare(ctx context.Context, arg CreateClubShareParams) (ClubShare, error)
+	// ============================================================================
+	// CUPPING QUERIES
+	// ============================================================================
+	// Operations for blind cupping sessions: samples, participants, scores and
+	// the reveal
+	// ----------------------------------------------------------------------------
+	// 1. CREATE CUPPING SESSION
+	// ----------------------------------------------------------------------------
+	// Parameters: id, host_id, title, notes, and parallel sample arrays
+	//
+	//	(sample_ids, labels, names, roasters, bean_origins, bean_bag_ids)
+	//
+	// Returns: The created session
+	// Usage: Host sets up a session and its samples in one statement
+	CreateCuppingSession(ctx context.Context, arg CreateCuppingSessionParams) (CuppingSession, error)
+	// ============================================================================
+	// DRAFT QUERIES
+	// ============================================================================
+	// Operations for autosaved drafts of brews and recipes still being entered
+	// ----------------------------------------------------------------------------
+	// 1. CREATE DRAFT
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id (ULID), $2 = user_id, $3 = kind, $4 = data,
+	//
+	//	$5 = sync_vector (NULL unless pushed by an offline client)
+	//
+	// Returns: The created draft
+	// Usage: User starts entering a brew or recipe
+	CreateDraft(ctx context.Context, arg CreateDraftParams) (Draft, error)
+	// ============================================================================
+	// INVITE QUERIES
+	// ============================================================================
+	// Operations for invite codes and the referral stats of the users who hand
+	// them out. An invite's uses count against its creator's quota until it is
+	// revoked or expires; then only the uses already redeemed do.
+	// ----------------------------------------------------------------------------
+	// 1. CREATE INVITE
+	// ----------------------------------------------------------------------------
+	// Parameters: code, inviter_id, max_uses, expires_at, quota (NULL for no
+	//
+	//	quota)
+	//
+	// Returns: The new invite, or no rows if it would exceed the quota
+	// Usage: User creates an invite to share
+	// Note: create_invite locks the inviter's row, so concurrent requests can't
+	//
+	//	both fit under the quota
+	CreateInvite(ctx context.Context, arg CreateInviteParams) (Invite, error)
+	// ----------------------------------------------------------------------------
+	// 15. CREATE INVITED USER
+	// ----------------------------------------------------------------------------
+	// Parameters: code (the invite's), id, username, email, password_hash,
+	//
+	//	birth_date, parent_email, consent_token_hash,
+	//	consent_ttl_seconds
+	//
+	// Returns: Created user record, or no rows if the invite can't be redeemed
+	//
+	//	(unknown, revoked, expired or used up)
+	//
+	// Usage: Registration with an invite. The invite's use is counted in the
+	//
+	//	same statement, so a failed registration doesn't spend it. A
+	//	parent_email creates the user awaiting consent, as in CreateUser.
+	CreateInvitedUser(ctx context.Context, arg CreateInvitedUserParams) (CreateInvitedUserRow, error)
+	// ============================================================================
+	// NOTIFICATION QUERIES
+	// ============================================================================
+	// Operations for user notifications: create, fetch, mark as read
+	// ----------------------------------------------------------------------------
+	// 1. CREATE NOTIFICATION
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id (ULID), $2 = recipient_user_id, $3 = actor_user_id,
+	//             $4 = type, $5 = reference_id, $6 = reference_type
+	// Returns: Created notification record
+	// Usage: Notify user of actions (like, comment, friend request, tag)
+	// Types: 'like', 'comment', 'friend_request', 'tag', 'follow', 'brew_reminder'
+	// Reference types: 'post', 'comment', 'friendship', 'brew'
+	CreateNotification(ctx context.Context, arg CreateNotificationParams) (Notification, error)
+	// ----------------------------------------------------------------------------
+	// 5. CREATE OIDC AUTH CODE
+	// ----------------------------------------------------------------------------
+	// Parameters: code_hash, client_id, user_id, redirect_uri, scopes, nonce,
+	//
+	//	code_challenge, auth_time, ttl_seconds
+	//
+	// Returns: None
+	// Usage: A signed-in user is sent back to a client with a code. Expired
+	//
+	//	codes are deleted in the same statement.
+	//
+	// Performance: Uses idx_oidc_auth_code_expires_at
+	CreateOIDCAuthCode(ctx context.Context, arg CreateOIDCAuthCodeParams) error
+	// ============================================================================
+	// OIDC QUERIES
+	// ============================================================================
+	// Operations for brewd's OIDC provider: the companion service clients
+	// admins register and the authorization codes issued to them
+	// ----------------------------------------------------------------------------
+	// 1. CREATE OIDC CLIENT
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id, $2 = name, $3 = secret_hash, $4 = redirect_uris
+	// Returns: The created client (the secret itself is never stored)
+	// Usage: Admin registers a companion service
+	CreateOIDCClient(ctx context.Context, arg CreateOIDCClientParams) (OidcClient, error)
+	// ============================================================================
+	// ORGANIZATION QUERIES
+	// ============================================================================
+	// Operations for organizations: membership and roles, and the brews logged
+	// in them. Every query on an organization's resources takes its ID, the
+	// request's tenant, so no query reaches across organizations.
+	// ----------------------------------------------------------------------------
+	// 1. CREATE ORGANIZATION
+	// ----------------------------------------------------------------------------
+	// Parameters: id, name, kind, owner_id
+	// Returns: The created organization
+	// Usage: Create an organization with its creator as owner, in one statement
+	CreateOrganization(ctx context.Context, arg CreateOrganizationParams) (Organization, error)
+	// ============================================================================
+	// POLICY QUERIES
+	// ============================================================================
+	// Operations for the terms of service and privacy policy: publishing
+	// versions, and recording which versions each user accepted
+	// ----------------------------------------------------------------------------
+	// 1. CREATE POLICY DOCUMENT
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id, $2 = kind, $3 = version, $4 = url, $5 = summary,
+	//
+	//	$6 = published_at (NULL publishes it now)
+	//
+	// Returns: The created document
+	// Usage: Admin publishes a new version of a policy
+	CreatePolicyDocument(ctx context.Context, arg CreatePolicyDocumentParams) (PolicyDocument, error)
+	// ============================================================================
+	// POST QUERIES
+	// ============================================================================
+	// Operations for posts: create, read, feed generation, and user posts
+	// ----------------------------------------------------------------------------
+	// 1. CREATE POST
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id (ULID), $2 = owner_id, $3 = brew_id, $4 = title,
+	//             $5 = description, $6 = rating, $7 = visibility
+	// Returns: The created post record
+	// Usage: User creates a new coffee brew post
+	CreatePost(ctx context.Context, arg CreatePostParams) (Post, error)
+	// ----------------------------------------------------------------------------
+	// 11. CREATE POUR CURVE
+	// ----------------------------------------------------------------------------
+	// Parameters: id, brew_id, user_id, api_key_id, client_id, device_model,
+	//
+	//	started_at
+	//
+	// Returns: The created curve, with no samples yet
+	// Usage: Start of a pour stream
+	CreatePourCurve(ctx context.Context, arg CreatePourCurveParams) (PourCurve, error)
+	// ============================================================================
+	// RECIPE QUERIES
+	// ============================================================================
+	// Operations for versioned recipes: create, revise, history, and rollback
+	// ----------------------------------------------------------------------------
+	// 1. CREATE RECIPE
+	// ----------------------------------------------------------------------------
+	// Parameters: id (ULID), owner_id, name, brew_method, is_public, plus the
+	//
+	//	parameters of revision 1 (grams / Celsius) and its changelog
+	//
+	// Returns: The created recipe record
+	// Usage: User creates a recipe; the recipe and its first revision are
+	//
+	//	inserted in a single statement
+	CreateRecipe(ctx context.Context, arg CreateRecipeParams) (Recipe, error)
+	// ----------------------------------------------------------------------------
+	// 5. CREATE RECIPE REVISION
+	// ----------------------------------------------------------------------------
+	// Parameters: recipe_id, the new parameters (grams / Celsius), changelog,
+	//
+	//	rolled_back_from (NULL unless restoring a revision), created_by
+	//
+	// Returns: The new revision
+	// Usage: Edit or roll back a recipe. Bumping current_revision locks the
+	//
+	//	recipe row, so concurrent edits get consecutive revision numbers.
+	CreateRecipeRevision(ctx context.Context, arg CreateRecipeRevisionParams) (RecipeRevision, error)
+	// ============================================================================
+	// RESOURCE SHARE QUERIES
+	// ============================================================================
+	// Access control for brews and recipes: the shares owners grant to users
+	// and clubs, and the visibility checks that honour them. Handlers load
+	// items through the GetVisible* queries (and GetVisibleBrewsByIDs in
+	// brew.sql) rather than checking access themselves.
+	// ----------------------------------------------------------------------------
+	// 1. CREATE RESOURCE SHARE
+	// ----------------------------------------------------------------------------
+	// Parameters: id, brew_id or recipe_id, user_id or club_id, created_by
+	// Returns: The created share
+	// Usage: An owner shares a brew or recipe. Sharing an item with the same
+	//
+	//	grantee twice violates idx_resource_share_item_grantee.
+	CreateResourceShare(ctx context.Context, arg CreateResourceShareParams) (ResourceShare, error)
+	// ============================================================================
+	// ROASTER QUERIES
+	// ============================================================================
+	// Operations for roaster accounts and their verification
+	// ----------------------------------------------------------------------------
+	// 1. CREATE ROASTER
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id, $2 = user_id, $3 = name, $4 = website,
+	//
+	//	$5 = location, $6 = description
+	//
+	// Returns: The created roaster
+	// Usage: A user sets up a roaster account; names taken case-insensitively
+	//
+	//	surface as 23505
+	CreateRoaster(ctx context.Context, arg CreateRoasterParams) (Roaster, error)
+	// ============================================================================
+	// SCIM QUERIES
+	// ============================================================================
+	// Operations for SCIM provisioning: the clients admins register, and the
+	// student accounts each client creates, updates, deactivates and deletes
+	// ----------------------------------------------------------------------------
+	// 1. CREATE SCIM CLIENT
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id, $2 = name, $3 = token_prefix, $4 = token_hash
+	// Returns: The created client (the token itself is never stored)
+	// Usage: Admin registers a training program's identity system
+	CreateSCIMClient(ctx context.Context, arg CreateSCIMClientParams) (ScimClient, error)
+	// ----------------------------------------------------------------------------
+	// 6. CREATE SCIM USER
+	// ----------------------------------------------------------------------------
+	// Parameters: id, username, email, password_hash, active, client_id,
+	//
+	//	external_id
+	//
+	// Returns: The provisioned account
+	// Usage: A client provisions a student. A taken username, email or
+	//
+	//	external_id fails with a unique violation.
+	CreateSCIMUser(ctx context.Context, arg CreateSCIMUserParams) (CreateSCIMUserRow, error)
+	// ----------------------------------------------------------------------------
+	// 2. CREATE STEP-UP CHALLENGE
+	// ----------------------------------------------------------------------------
+	// Parameters: id, user_id, method, code_hash, ttl_seconds
+	// Returns: The new challenge
+	// Usage: Login holds a suspicious login. The user's expired challenges are
+	//
+	//	deleted in the same statement.
+	//
+	// Performance: Uses idx_step_up_challenge_user
+	CreateStepUpChallenge(ctx context.Context, arg CreateStepUpChallengeParams) (StepUpChallenge, error)
+	// ============================================================================
+	// DEVICE QUERIES
+	// ============================================================================
+	// Operations for readings sent by device companion apps
+	// ----------------------------------------------------------------------------
+	// 1. CREATE TDS READING
+	// ----------------------------------------------------------------------------
+	// Parameters: id, user_id, brew_id (NULL attaches the reading to the user's
+	//
+	//	brew whose timer session was running at measured_at, or ended
+	//	at most 30 minutes before it), api_key_id, client_id,
+	//	tds_percent, temperature_c, beverage_grams, device_model,
+	//	measured_at
+	//
+	// Returns: The created reading, or no rows if the user already sent a
+	//
+	//	reading with this client_id
+	//
+	// Usage: Refractometer companion app ingestion
+	// Performance: Uses idx_brew_created_by
+	CreateTDSReading(ctx context.Context, arg CreateTDSReadingParams) (TdsReading, error)
+	// ----------------------------------------------------------------------------
+	// 10. CREATE TRUSTED DEVICE
+	// ----------------------------------------------------------------------------
+	// Parameters: id, user_id, token_hash, user_agent, ttl_days
+	// Returns: None
+	// Usage: Step-up verification, when the user chooses to trust the device.
+	//
+	//	The user's expired devices are deleted in the same statement.
+	CreateTrustedDevice(ctx context.Context, arg CreateTrustedDeviceParams) error
+	// ============================================================================
+	// USER QUERIES
+	// ============================================================================
+	// Operations for user management: registration, profiles, search, and stats
+	// ----------------------------------------------------------------------------
+	// 1. CREATE USER (Registration)
+	// ----------------------------------------------------------------------------
+	// Parameters: id (ULID), username, email, password_hash, birth_date,
+	//
+	//	parent_email, consent_token_hash, consent_ttl_seconds
+	//
+	// Returns: The created user record
+	// Usage: Called during user registration. A parent_email means the user is
+	//
+	//	under the minimum age: they're created awaiting consent, and the
+	//	request to their parent is created in the same statement.
+	CreateUser(ctx context.Context, arg CreateUserParams) (CreateUserRow, error)
+	// ----------------------------------------------------------------------------
+	// 4. DELETE AUTOMATION WEBHOOK
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id, $2 = user_id
+	// Returns: Number of rows deleted; pending deliveries are deleted with it
+	// Usage: User disconnects an integration
+	DeleteAutomationWebhook(ctx context.Context, arg DeleteAutomationWebhookParams) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 15. DELETE BREW CUSTOM FIELD
+	// ----------------------------------------------------------------------------
+	// Parameters: id, user_id
+	// Returns: The deleted field's name, or no rows if not the user's
+	// Usage: User removes a field; its values are removed from their brews
+	// Performance: Uses idx_brew_created_by
+	DeleteBrewCustomField(ctx context.Context, arg DeleteBrewCustomFieldParams) (string, error)
+	// ----------------------------------------------------------------------------
+	// 4. DELETE BREW EVENT
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id
+	// Returns: Nothing
+	// Usage: Host cancels an event (RSVPs CASCADE)
+	DeleteBrewEvent(ctx context.Context, id string) error
+	// ----------------------------------------------------------------------------
+	// 4. DELETE BREW PHOTO
+	// ----------------------------------------------------------------------------
+	// Parameters: id, brew_id
+	// Returns: The deleted photo; its objects are recorded for the photo
+	//
+	//	processor to sweep
+	//
+	// Usage: Owner deleting a photo
+	// Performance: Uses the primary key
+	DeleteBrewPhoto(ctx context.Context, arg DeleteBrewPhotoParams) (BrewPhoto, error)
+	// ----------------------------------------------------------------------------
+	// 10. DELETE BREW POUR CURVE
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = brew_id
+	// Returns: Number of rows deleted
+	// Usage: Replace a brew's curve with a new stream, or discard it
+	DeleteBrewPourCurve(ctx context.Context, brewID string) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 4. DELETE CHALLENGE
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id
+	// Returns: Number of rows deleted
+	// Usage: Admin removes a challenge and every badge earned from it
+	DeleteChallenge(ctx context.Context, id string) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 4. DELETE CLUB
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id
+	// Returns: Nothing
+	// Usage: Owner deletes the club with its members, invites and feed (CASCADE)
+	DeleteClub(ctx context.Context, id string) error
+	// ----------------------------------------------------------------------------
+	// 20. DELETE CLUB SHARE
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id
+	// Returns: Nothing
+	// Usage: Sharer or a moderator removes a share from the feed
+	DeleteClubShare(ctx context.Context, id string) error
+	// 14. DELETE COMMENT
+	// Parameters: $1 = comment_id
+	// Returns: Deleted comment id
+	// Usage: User deletes their comment
+	// Note: CASCADE will also delete replies (see comment.sql schema)
+	DeleteComment(ctx context.Context, id string) (string, error)
+	// ----------------------------------------------------------------------------
+	// 4. DELETE CUPPING SESSION
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id
+	// Returns: Nothing
+	// Usage: Host deletes a session with its samples and scores (CASCADE)
+	DeleteCuppingSession(ctx context.Context, id string) error
+	// ----------------------------------------------------------------------------
+	// 5. DELETE DISPATCHED OUTBOX MESSAGES
+	// ----------------------------------------------------------------------------
+	// Parameters: retention_days
+	// Returns: Number of messages deleted
+	// Usage: Outbox dispatcher trims messages dispatched longer ago than the
+	//
+	//	retention period; failed messages are kept for inspection
+	//
+	// Performance: Uses idx_outbox_message_dispatched
+	DeleteDispatchedOutboxMessages(ctx context.Context, retentionDays int32) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 6. DELETE DRAFT
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id, $2 = user_id
+	// Returns: Number of rows deleted; 0 if not the user's
+	// Usage: User discards a draft, or it was published
+	DeleteDraft(ctx context.Context, arg DeleteDraftParams) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 6. DELETE EMAIL DOMAIN OVERRIDE
+	// ----------------------------------------------------------------------------
+	// Parameters: domain
+	// Returns: Rows affected
+	// Usage: Admin removes an override; the blocklist applies again
+	DeleteEmailDomainOverride(ctx context.Context, domain string) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 4. DELETE EMAIL SUPPRESSION
+	// ----------------------------------------------------------------------------
+	// Parameters: email
+	// Returns: Rows affected
+	// Usage: Admin lifts a suppression, e.g. after the user fixed their mailbox
+	DeleteEmailSuppression(ctx context.Context, email string) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 5. DELETE INSTANCE REALTIME PRESENCE
+	// ----------------------------------------------------------------------------
+	// Parameters: instance_id
+	// Returns: Nothing
+	// Usage: An instance shutting down
+	// Performance: Uses idx_realtime_presence_instance_id
+	DeleteInstanceRealtimePresence(ctx context.Context, instanceID string) error
+	// ----------------------------------------------------------------------------
+	// 7. DELETE NOTIFICATION
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = notification_id, $2 = recipient_user_id
+	// Returns: Deleted notification id
+	// Usage: User dismisses a notification
+	// Note: Includes recipient_user_id check for security
+	DeleteNotification(ctx context.Context, arg DeleteNotificationParams) (string, error)
+	// ----------------------------------------------------------------------------
+	// 4. DELETE OIDC CLIENT
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id
+	// Returns: Number of rows deleted; 0 if there is no such client
+	// Usage: Admin removes a companion service. Its unredeemed codes go with
+	//
+	//	it; tokens already issued last until they expire.
+	DeleteOIDCClient(ctx context.Context, id string) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 10. DELETE OLD READ NOTIFICATIONS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = recipient_user_id, $2 = days_old (e.g., 30)
+	// Returns: Number of deleted notifications
+	// Usage: Cleanup old notifications (scheduled job)
+	// Note: Only deletes READ notifications older than X days
+	DeleteOldReadNotifications(ctx context.Context, arg DeleteOldReadNotificationsParams) ([]string, error)
+	// ----------------------------------------------------------------------------
+	// 4. DELETE ORGANIZATION
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id
+	// Returns: Nothing
+	// Usage: An owner deletes the organization with its members and brews
+	//
+	//	(CASCADE)
+	DeleteOrganization(ctx context.Context, id string) error
+	// ----------------------------------------------------------------------------
+	// 7. DELETE POST
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = post_id
+	// Returns: Deleted post id
+	// Usage: User deletes their post
+	// Note: CASCADE will also delete related media, likes, comments
+	DeletePost(ctx context.Context, id string) (string, error)
+	// ----------------------------------------------------------------------------
+	// 4. DELETE PUBLISHED DOMAIN EVENTS
+	// ----------------------------------------------------------------------------
+	// Parameters: retention_days
+	// Returns: Number of events deleted
+	// Usage: Event relay trims events published longer ago than the retention
+	//
+	//	period; consumers replay from the broker, not the outbox
+	//
+	// Performance: Uses idx_domain_event_published
+	DeletePublishedDomainEvents(ctx context.Context, retentionDays int32) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 10. DELETE QUARANTINED BREW PHOTO
+	// ----------------------------------------------------------------------------
+	// Parameters: id
+	// Returns: The deleted photo; its objects are recorded for the photo
+	//
+	//	processor to sweep
+	//
+	// Usage: Admins disposing of a quarantined upload
+	// Performance: Uses the primary key
+	DeleteQuarantinedBrewPhoto(ctx context.Context, id string) (BrewPhoto, error)
+	// ----------------------------------------------------------------------------
+	// 3. DELETE RESOURCE SHARE
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id, $2 = item_id
+	// Returns: Number of rows deleted (0 if the item has no such share)
+	// Usage: Owner revokes a share
+	DeleteResourceShare(ctx context.Context, arg DeleteResourceShareParams) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 5. DELETE SCIM CLIENT
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id
+	// Returns: Number of rows deleted; 0 if there is no such client
+	// Usage: Admin removes an identity system. The accounts it provisioned
+	//
+	//	stay, no longer managed by it.
+	DeleteSCIMClient(ctx context.Context, id string) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 11. DELETE SCIM USER
+	// ----------------------------------------------------------------------------
+	// Parameters: client_id, id
+	// Returns: Number of rows deleted; 0 if the client didn't provision it
+	// Usage: A client deprovisions a student. The account and everything in
+	//
+	//	it are deleted.
+	DeleteSCIMUser(ctx context.Context, arg DeleteSCIMUserParams) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 7. DELETE TDS READING
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id
+	// Returns: None
+	// Usage: Discard a bad reading
+	DeleteTDSReading(ctx context.Context, id string) error
+	// ----------------------------------------------------------------------------
+	// 3. DETACH BREW PARTITION
+	// ----------------------------------------------------------------------------
+	// Parameters: partition_name
+	// Returns: The name of the detached table
+	// Usage: Taking a partition past retention out of brew. Fails with a
+	//
+	//	foreign key violation while other rows reference its brews.
+	//
+	// Performance: Checks every table referencing brew for rows referencing the
+	//
+	//	partition
+	DetachBrewPartition(ctx context.Context, partitionName string) (string, error)
+	// ----------------------------------------------------------------------------
+	// 8. DISABLE TOTP
+	// ----------------------------------------------------------------------------
+	// Parameters: id
+	// Returns: None
+	// Usage: User turns TOTP off; step-up falls back to emailed codes
+	DisableTOTP(ctx context.Context, id string) error
+	// ----------------------------------------------------------------------------
+	// 6. DISMISS RECOMMENDATION
+	// ----------------------------------------------------------------------------
+	// Parameters: user_id, kind, item_id
+	// Returns: Nothing; records the dismissal (repeats are ignored) and drops
+	//
+	//	the item from the user's current recommendations
+	//
+	// Usage: User marks a recommendation "not interested"
+	DismissRecommendation(ctx context.Context, arg DismissRecommendationParams) error
+	// ----------------------------------------------------------------------------
+	// 2. DISPATCH NOTIFICATION
+	// ----------------------------------------------------------------------------
+	// Parameters: message_id, id (the notification's ID)
+	// Returns: None
+	// Usage: Outbox dispatcher; creates the notification a message describes and
+	//
+	//	marks the message dispatched in one statement, so a notification
+	//	is created once however often the message is retried
+	DispatchNotification(ctx context.Context, arg DispatchNotificationParams) error
+	// ----------------------------------------------------------------------------
+	// 3. DISPATCH WEBHOOK
+	// ----------------------------------------------------------------------------
+	// Parameters: message_id, delivery_ids, webhook_ids (pairs: one delivery ID
+	//
+	//	per subscribed webhook)
+	//
+	// Returns: None
+	// Usage: Outbox dispatcher; queues a webhook event's deliveries and marks
+	//
+	//	the message dispatched in one statement. With no webhooks the
+	//	message is just marked dispatched.
+	DispatchWebhook(ctx context.Context, arg DispatchWebhookParams) error
+	// ----------------------------------------------------------------------------
+	// 7. ENABLE TOTP
+	// ----------------------------------------------------------------------------
+	// Parameters: id, step
+	// Returns: Number of users updated (0 if there's no pending secret)
+	// Usage: TOTP enrollment is confirmed with a code from the secret
+	EnableTOTP(ctx context.Context, arg EnableTOTPParams) (int64, error)
+	// ============================================================================
+	// BREW PARTITION QUERIES
+	// ============================================================================
+	// Maintenance of the monthly partitions of the brew table: creating them
+	// ahead and detaching those past retention
+	// ----------------------------------------------------------------------------
+	// 1. ENSURE BREW PARTITION
+	// ----------------------------------------------------------------------------
+	// Parameters: month
+	// Returns: Whether the partition for month's UTC month was created, false
+	//
+	//	if it already existed
+	//
+	// Usage: Creating partitions ahead of the brews that will fill them
+	// Performance: Scans brew_default for rows in the new range, normally none
+	EnsureBrewPartition(ctx context.Context, month pgtype.Date) (bool, error)
+	// ----------------------------------------------------------------------------
+	// 7. EXPIRE IAP SUBSCRIPTIONS
+	// ----------------------------------------------------------------------------
+	// Parameters: None
+	// Returns: Rows affected
+	// Usage: Renewal checks end the supporter plan of users whose app store
+	//
+	//	subscription ran out over a day ago and couldn't be verified since,
+	//	so a store outage can't extend it indefinitely
+	ExpireIAPSubscriptions(ctx context.Context) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 3. FAIL STALE BACKUP RUNS
+	// ----------------------------------------------------------------------------
+	// Parameters: stale_hours
+	// Returns: Number of runs marked failed
+	// Usage: Backup job; a run still in progress after stale_hours was
+	//
+	//	interrupted, e.g. by a restart, and would block every later run
+	FailStaleBackupRuns(ctx context.Context, staleHours int32) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 2. FINISH BACKUP RUN
+	// ----------------------------------------------------------------------------
+	// Parameters: id, status, object_key, size_bytes, sha256, verification,
+	//
+	//	tables_restored, error (NULL unless failed)
+	//
+	// Returns: The finished run
+	// Usage: Backup job records a run's outcome
+	FinishBackupRun(ctx context.Context, arg FinishBackupRunParams) (BackupRun, error)
+	// ----------------------------------------------------------------------------
+	// 6. FINISH BREW PHOTO
+	// ----------------------------------------------------------------------------
+	// Parameters: status (ready or rejected), image_key, thumbnail_key, width,
+	//
+	//	height, image_bytes, thumbnail_bytes, screening_score, error,
+	//	id
+	//
+	// Returns: Number of photos updated; 0 when the photo was deleted while
+	//
+	//	being processed
+	//
+	// Usage: Photo processor, once a photo is processed
+	// Performance: Uses the primary key
+	FinishBrewPhoto(ctx context.Context, arg FinishBrewPhotoParams) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 7. FLAG DISPOSABLE EMAIL
+	// ----------------------------------------------------------------------------
+	// Parameters: id
+	// Returns: None
+	// Usage: Registration in flag mode, for a new user with a disposable domain
+	FlagDisposableEmail(ctx context.Context, id string) error
+	// ----------------------------------------------------------------------------
+	// 13. FORGET DELETED PHOTO OBJECT
+	// ----------------------------------------------------------------------------
+	// Parameters: key
+	// Returns: None
+	// Usage: Photo processor, once a deleted photo's object is gone from the
+	//
+	//	object store
+	//
+	// Performance: Uses the primary key
+	ForgetDeletedPhotoObject(ctx context.Context, key string) error
+	// ----------------------------------------------------------------------------
+	// 6. FORK RECIPE
+	// ----------------------------------------------------------------------------
+	// Parameters: id (ULID), owner_id, name, is_public, source_id, source_revision
+	// Returns: The new recipe, or no rows if the source revision doesn't exist
+	// Usage: Clone a recipe; the fork starts at revision 1 with the source
+	//
+	//	revision's parameters and links back to it for attribution
+	ForkRecipe(ctx context.Context, arg ForkRecipeParams) (Recipe, error)
+	// ----------------------------------------------------------------------------
+	// 3. GET API KEY BY HASH
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = key_hash
+	// Returns: The unrevoked key with that hash, if its owner's account
+	//
+	//	hasn't been deactivated
+	//
+	// Usage: Authenticating an integration request
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (ApiKey, error)
+	// 10. GET ACTIVE USERS
+	// Parameters: $1 = days (time window), $2 = limit
+	// Returns: Most active users by post count in time period
+	// Usage: "Active brewers" leaderboard
+	GetActiveUsers(ctx context.Context, arg GetActiveUsersParams) ([]GetActiveUsersRow, error)
+	// ----------------------------------------------------------------------------
+	// 3. GET ALL NOTIFICATIONS (Read and Unread)
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = recipient_user_id, $2 = limit, $3 = offset
+	// Returns: All notifications with actor info, paginated
+	// Usage: Notification history page
+	// Performance: Uses idx_notification_recipient
+	GetAllNotifications(ctx context.Context, arg GetAllNotificationsParams) ([]GetAllNotificationsRow, error)
+	// ----------------------------------------------------------------------------
+	// 3. GET ARCHIVED BREW
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = brew_id, $2 = user_id
+	// Returns: The archived brew, if user_id logged it
+	// Usage: Viewing an archived brew; only its owner sees it
+	// Performance: Primary key lookup
+	GetArchivedBrew(ctx context.Context, arg GetArchivedBrewParams) (Brew, error)
+	// ----------------------------------------------------------------------------
+	// 5. GET ARCHIVED BREW POUR CURVE
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = brew_id
+	// Returns: The archived brew's pour curve, if it had one. Only compacted
+	//
+	//	curves are archived, so its samples are all in the row.
+	//
+	// Usage: Charting an archived brew, once its owner has loaded it
+	// Performance: Primary key lookup
+	GetArchivedBrewPourCurve(ctx context.Context, brewID string) (PourCurve, error)
+	// ----------------------------------------------------------------------------
+	// 3. GET AUTOMATION WEBHOOK
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id, $2 = user_id
+	// Returns: One of the user's webhooks
+	// Usage: Sending a test event
+	GetAutomationWebhook(ctx context.Context, arg GetAutomationWebhookParams) (AutomationWebhook, error)
+	// ----------------------------------------------------------------------------
+	// ENGAGEMENT ANALYTICS
+	// ----------------------------------------------------------------------------
+	// 11. GET AVERAGE ENGAGEMENT BY POST
+	// Parameters: None
+	// Returns: Average likes and comments per post
+	// Usage: Platform health metrics
+	GetAverageEngagementByPost(ctx context.Context) (GetAverageEngagementByPostRow, error)
+	// ----------------------------------------------------------------------------
+	// 6. GET BACKUP RUN
+	// ----------------------------------------------------------------------------
+	// Parameters: id
+	// Returns: The run
+	// Usage: Admin backup download link
+	// Performance: Uses the primary key
+	GetBackupRun(ctx context.Context, id string) (BackupRun, error)
+	// ----------------------------------------------------------------------------
+	// 2. GET BEAN BAG BY ID
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = bean_bag_id
+	// Returns: Single bean bag record
+	// Usage: View a bag; callers must check owner_id for access
+	GetBeanBagByID(ctx context.Context, id string) (BeanBag, error)
+	// ----------------------------------------------------------------------------
+	// 7. GET BEAN BAG TOP FLAVORS
+	// ----------------------------------------------------------------------------
+	// Parameters: bean_bag_id, row_limit
+	// Returns: The most common descriptors across the bag's own notes and the
+	//
+	//	brews made from it, with their top-level category
+	//
+	// Usage: "What does this bean taste like" summary
+	// Performance: Uses idx_brew_bean_bag
+	GetBeanBagTopFlavors(ctx context.Context, arg GetBeanBagTopFlavorsParams) ([]GetBeanBagTopFlavorsRow, error)
+	// ----------------------------------------------------------------------------
+	// 5. GET BEAN BAG USAGE
+	// ----------------------------------------------------------------------------
+	// Parameters: bean_bag_id, default_dose_grams (assumed for brews with no
+	//
+	//	dose when the bag has no assumption), window_start
+	//
+	// Returns: Grams used in total and since window_start, the number of
+	//
+	//	linked brews, and when the first one was logged
+	//
+	// Usage: Bean consumption forecast
+	// Performance: Uses idx_brew_bean_bag
+	GetBeanBagUsage(ctx context.Context, arg GetBeanBagUsageParams) (GetBeanBagUsageRow, error)
+	// ----------------------------------------------------------------------------
+	// 2. GET BEAN BY ID
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id
+	// Returns: The bean
+	// Usage: Bean page and edit checks
+	GetBeanByID(ctx context.Context, id string) (Bean, error)
+	// ----------------------------------------------------------------------------
+	// 14. GET BLOCKED USERS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id (blocker)
+	// Returns: List of users blocked by current user
+	// Usage: Display blocked users list
+	GetBlockedUsers(ctx context.Context, userID string) ([]GetBlockedUsersRow, error)
+	// ----------------------------------------------------------------------------
+	// 2. GET BREW BY ID
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = brew_id
+	// Returns: Single brew record
+	// Usage: View a brew; callers must check is_public / created_by for access
+	GetBrewByID(ctx context.Context, id string) (Brew, error)
+	// ----------------------------------------------------------------------------
+	// 2. GET BREW EVENT BY ID
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id
+	// Returns: The event
+	// Usage: Event detail page and access checks
+	GetBrewEventByID(ctx context.Context, id string) (BrewEvent, error)
+	// ----------------------------------------------------------------------------
+	// 8. GET BREW EVENT RSVP COUNTS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = event_id
+	// Returns: How many users are going and maybe going
+	// Usage: Event summaries and realtime RSVP updates
+	GetBrewEventRSVPCounts(ctx context.Context, eventID string) (GetBrewEventRSVPCountsRow, error)
+	// ----------------------------------------------------------------------------
+	// CONTENT ANALYTICS
+	// ----------------------------------------------------------------------------
+	// 8. GET BREW METHOD DISTRIBUTION
+	// Parameters: None
+	// Returns: Usage statistics by brew method
+	// Usage: "Most popular brew methods" chart
+	GetBrewMethodDistribution(ctx context.Context) ([]GetBrewMethodDistributionRow, error)
+	// ----------------------------------------------------------------------------
+	// 3. GET BREW PHOTO
+	// ----------------------------------------------------------------------------
+	// Parameters: id, brew_id
+	// Returns: The photo, if it belongs to the brew
+	// Usage: Photo status and image downloads
+	// Performance: Uses the primary key
+	GetBrewPhoto(ctx context.Context, arg GetBrewPhotoParams) (BrewPhoto, error)
+	// ----------------------------------------------------------------------------
+	// 14. GET BREW POUR CURVE
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = brew_id
+	// Returns: The brew's curve
+	// Usage: Brew detail and charting
+	GetBrewPourCurve(ctx context.Context, brewID string) (PourCurve, error)
+	// ----------------------------------------------------------------------------
+	// 10. GET BREW RATINGS
+	// ----------------------------------------------------------------------------
+	// Parameters: brew_ids (brew IDs)
+	// Returns: Average tasting score and number of scores per brew, from the
+	//
+	//	brewer's own rated posts; unrated brews are omitted
+	//
+	// Usage: Brew comparison
+	// Performance: Uses idx_post_brew_id
+	GetBrewRatings(ctx context.Context, brewIds []string) ([]GetBrewRatingsRow, error)
+	// ============================================================================
+	// CALENDAR FEED QUERIES
+	// ============================================================================
+	// Lookups behind each user's signed iCalendar feed: the feed URL's version
+	// and the scheduled dates the feed lists
+	// ----------------------------------------------------------------------------
+	// 1. GET CALENDAR FEED VERSION
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id
+	// Returns: The version signed into the user's feed URL
+	// Usage: Build the feed URL and verify requests for it
+	GetCalendarFeedVersion(ctx context.Context, id string) (int32, error)
+	// ----------------------------------------------------------------------------
+	// 2. GET CHALLENGE BY ID
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id
+	// Returns: The challenge
+	// Usage: Admin challenge tooling
+	GetChallengeByID(ctx context.Context, id string) (Challenge, error)
+	// ----------------------------------------------------------------------------
+	// 2. GET CLUB BY ID
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id
+	// Returns: The club
+	// Usage: Club page and access checks
+	GetClubByID(ctx context.Context, id string) (Club, error)
+	// ----------------------------------------------------------------------------
+	// 16. GET CLUB INVITE BY TOKEN HASH
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = token_hash
+	// Returns: A usable invite (unrevoked, unexpired, uses left) with its club
+	// Usage: Invite link landing page
+	GetClubInviteByTokenHash(ctx context.Context, tokenHash string) (GetClubInviteByTokenHashRow, error)
+	// ----------------------------------------------------------------------------
+	// 8. GET CLUB MEMBER ROLE
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = club_id, $2 = user_id
+	// Returns: The user's role, or no rows if they are not a member
+	// Usage: Access checks
+	GetClubMemberRole(ctx context.Context, arg GetClubMemberRoleParams) (string, error)
+	// ----------------------------------------------------------------------------
+	// 19. GET CLUB SHARE
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id, $2 = club_id
+	// Returns: The share
+	// Usage: Permission checks before removing a share
+	GetClubShare(ctx context.Context, arg GetClubShareParams) (ClubShare, error)
+	// 15. GET COMMENT COUNT FOR POST
+	// Parameters: $1 = post_id
+	// Returns: Total number of comments (including replies)
+	// Usage: Display "X comments" on post preview
+	GetCommentCount(ctx context.Context, postID string) (int64, error)
+	// 8. GET COMMENT LIKE COUNT
+	// Parameters: $1 = comment_id
+	// Returns: Number of likes on a comment
+	// Usage: Display like count on comments
+	GetCommentLikeCount(ctx context.Context, commentID string) (int64, error)
+	// 11. GET COMMENTS FOR POST (Top-level only)
+	// Parameters: $1 = post_id
+	// Returns: Top-level comments with user info, reply count, like count
+	// Usage: Display comment section (fetch replies separately)
+	// Performance: Uses idx_comment_post_id and idx_comment_parent_comment_id
+	GetCommentsForPost(ctx context.Context, postID string) ([]GetCommentsForPostRow, error)
+	// ----------------------------------------------------------------------------
+	// 2. GET CUPPING SESSION BY ID
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id
+	// Returns: The session
+	// Usage: Load a session for access checks
+	GetCuppingSessionByID(ctx context.Context, id string) (CuppingSession, error)
+	// 6. GET DAILY ACTIVE USERS
+	// Parameters: $1 = days_back (e.g., 7 for last week)
+	// Returns: Count of users who posted, liked, or commented each day
+	// Usage: DAU/MAU tracking
+	GetDailyActiveUsers(ctx context.Context, dollar_1 interface{}) ([]GetDailyActiveUsersRow, error)
+	// ----------------------------------------------------------------------------
+	// 2. GET DRAFT
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id, $2 = user_id
+	// Returns: The draft, if it belongs to the user
+	// Usage: Resuming, autosaving or publishing a draft
+	GetDraft(ctx context.Context, arg GetDraftParams) (Draft, error)
+	// 12. GET ENGAGEMENT RATE BY USER
+	// Parameters: $1 = user_id
+	// Returns: User's posts with engagement metrics
+	// Usage: Show user which posts performed best
+	GetEngagementRateByUser(ctx context.Context, ownerID string) ([]GetEngagementRateByUserRow, error)
+	// ----------------------------------------------------------------------------
+	// 11. GET FRIEND COUNT
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id
+	// Returns: Number of accepted friends
+	// Usage: Display friend count on profile
+	GetFriendCount(ctx context.Context, userID string) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 4. GET FRIEND LIST
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id
+	// Returns: All accepted friends with user details
+	// Usage: Display user's friends list
+	// Performance: Uses idx_user_friendships_status, bidirectional storage makes query simple
+	GetFriendList(ctx context.Context, userID string) ([]GetFriendListRow, error)
+	// 7. GET GROWTH METRICS
+	// Parameters: $1 = days (time window)
+	// Returns: New users, posts, and engagement over time
+	// Usage: Growth tracking dashboard
+	GetGrowthMetrics(ctx context.Context, dollar_1 interface{}) ([]GetGrowthMetricsRow, error)
+	// ----------------------------------------------------------------------------
+	// 4. GET IAP PURCHASE
+	// ----------------------------------------------------------------------------
+	// Parameters: provider, purchase_id
+	// Returns: The app store purchase
+	// Usage: Check who a submitted purchase belongs to; find the receipt a
+	//
+	//	store notification is about
+	GetIAPPurchase(ctx context.Context, arg GetIAPPurchaseParams) (IapPurchase, error)
+	// 15. GET INACTIVE USERS
+	// Parameters: $1 = days_inactive (e.g., 30)
+	// Returns: Users who haven't posted, liked, or commented recently
+	// Usage: Re-engagement campaigns
+	GetInactiveUsers(ctx context.Context, dollar_1 interface{}) ([]GetInactiveUsersRow, error)
+	// ----------------------------------------------------------------------------
+	// 2. GET INVITE
+	// ----------------------------------------------------------------------------
+	// Parameters: code
+	// Returns: The invite, its creator's username, and whether it can still be
+	//
+	//	redeemed
+	//
+	// Usage: Signup page shows who invited the user
+	GetInvite(ctx context.Context, code string) (GetInviteRow, error)
+	// ----------------------------------------------------------------------------
+	// 4. GET LATEST BACKUP RUN
+	// ----------------------------------------------------------------------------
+	// Parameters: None
+	// Returns: The most recently started run
+	// Usage: Backup job decides whether a scheduled run is due
+	// Performance: Uses idx_backup_run_started_at
+	GetLatestBackupRun(ctx context.Context) (BackupRun, error)
+	// ----------------------------------------------------------------------------
+	// 11. GET LATEST SIMILAR BREW
+	// ----------------------------------------------------------------------------
+	// Parameters: created_by, brew_method, bean_bag_id (NULL for any bag)
+	// Returns: The user's most recent brew with the method, preferring one from
+	//
+	//	the given bag; no rows if they never used the method
+	//
+	// Usage: Quick log fills in the parameters of the brew being repeated
+	// Performance: Uses idx_brew_created_by
+	GetLatestSimilarBrew(ctx context.Context, arg GetLatestSimilarBrewParams) (Brew, error)
+	// ============================================================================
+	// STEP-UP QUERIES
+	// ============================================================================
+	// Operations for step-up authentication: the login risk signals, step-up
+	// challenges, TOTP enrollment and trusted devices.
+	// ----------------------------------------------------------------------------
+	// 1. GET LOGIN RISK
+	// ----------------------------------------------------------------------------
+	// Parameters: user_id, user_agent, country, region, failure_window_hours
+	// Returns: Whether the user has signed in before, and if so whether from
+	//
+	//	this user agent and this country and region (an unknown
+	//	location counts as known), and the failed passwords and step-up
+	//	codes since their last login within the window
+	//
+	// Usage: Login decides whether to hold a login for a step-up challenge
+	// Performance: Uses idx_auth_event_user
+	GetLoginRisk(ctx context.Context, arg GetLoginRiskParams) (GetLoginRiskRow, error)
+	// ----------------------------------------------------------------------------
+	// 10. GET MEDIA FOR POST
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = post_id
+	// Returns: All media items for a post, ordered by display_order
+	// Usage: Display post images/videos
+	GetMediaForPost(ctx context.Context, postID string) ([]Medium, error)
+	// 13. GET MOST ENGAGING CONTENT
+	// Parameters: $1 = days, $2 = limit
+	// Returns: Posts with highest engagement rate
+	// Usage: Identify viral content
+	GetMostEngagingContent(ctx context.Context, arg GetMostEngagingContentParams) ([]GetMostEngagingContentRow, error)
+	// ----------------------------------------------------------------------------
+	// 10. GET MUTUAL FRIENDS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_a_id, $2 = user_b_id
+	// Returns: Users who are friends with both user A and user B
 	// Usage: Show mutual friends between two users
 	GetMutualFriends(ctx context.Context, arg GetMutualFriendsParams) ([]GetMutualFriendsRow, error)
 	// ----------------------------------------------------------------------------
-	// 11. GET NOTIFICATION WITH DETAILS
+	// 11. GET NOTIFICATION WITH DETAILS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = notification_id
+	// Returns: Notification with full context (actor, reference object)
+	// Usage: Display rich notification with post/comment preview
+	// Note: This is a base query - you may need to join additional tables
+	//       based on reference_type (post, comment, etc.)
+	GetNotificationWithDetails(ctx context.Context, id string) (GetNotificationWithDetailsRow, error)
+	// ----------------------------------------------------------------------------
+	// 9. GET NOTIFICATIONS BY TYPE
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = recipient_user_id, $2 = type, $3 = limit, $4 = offset
+	// Returns: Notifications filtered by type
+	// Usage: Filter notifications (e.g., show only 'like' notifications)
+	// Performance: Uses idx_notification_type
+	GetNotificationsByType(ctx context.Context, arg GetNotificationsByTypeParams) ([]GetNotificationsByTypeRow, error)
+	// ----------------------------------------------------------------------------
+	// 3. GET OIDC CLIENT
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id
+	// Returns: The client
+	// Usage: Checking a client's redirect URI at authorization and its secret
+	//
+	//	at the token endpoint
+	GetOIDCClient(ctx context.Context, id string) (OidcClient, error)
+	// ----------------------------------------------------------------------------
+	// 7. GET OIDC USER
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id
+	// Returns: The user's claims: username, email, profile picture and when the
+	//
+	//	profile last changed; no rows if the account was deactivated
+	//	or awaits parental consent
+	//
+	// Usage: ID tokens and the userinfo endpoint
+	GetOIDCUser(ctx context.Context, id string) (GetOIDCUserRow, error)
+	// ----------------------------------------------------------------------------
+	// 2. GET ORGANIZATION
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id
+	// Returns: The organization
+	// Usage: Organization page, after membership was checked
+	GetOrganization(ctx context.Context, id string) (Organization, error)
+	// ----------------------------------------------------------------------------
+	// 14. GET ORGANIZATION BREW
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id, $2 = organization_id
+	// Returns: The brew, or no rows if it wasn't logged in the organization
+	// Usage: View a brew in the organization's brew log
+	GetOrganizationBrew(ctx context.Context, arg GetOrganizationBrewParams) (Brew, error)
+	// ----------------------------------------------------------------------------
+	// 6. GET ORGANIZATION MEMBER ROLE
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = organization_id, $2 = user_id
+	// Returns: The user's role, or no rows if they are not a member
+	// Usage: Resolve the tenant of /orgs/:org_id routes; check a member's role
+	//
+	//	before changing it
+	GetOrganizationMemberRole(ctx context.Context, arg GetOrganizationMemberRoleParams) (string, error)
+	// ----------------------------------------------------------------------------
+	// 8. GET ORIGIN TOP FLAVORS
+	// ----------------------------------------------------------------------------
+	// Parameters: origin (matched case-insensitively), row_limit
+	// Returns: The most common descriptors on public brews of beans from that
+	//
+	//	origin, with their top-level category and how many brews noted
+	//	each
+	//
+	// Usage: Discover what an origin typically tastes like
+	GetOriginTopFlavors(ctx context.Context, arg GetOriginTopFlavorsParams) ([]GetOriginTopFlavorsRow, error)
+	// ============================================================================
+	// PARENTAL CONSENT QUERIES
+	// ============================================================================
+	// Operations for the consent requests sent to the parents of users who
+	// signed up under the minimum age. Requests are created with the user (see
+	// CreateUser).
+	// ----------------------------------------------------------------------------
+	// 1. GET PARENTAL CONSENT
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = token_hash
+	// Returns: The username of the user awaiting consent and when the request
+	//
+	//	expires; no rows if the request is unknown, expired or granted
+	//
+	// Usage: Showing a parent what they are consenting to
+	GetParentalConsent(ctx context.Context, tokenHash string) (GetParentalConsentRow, error)
+	// ----------------------------------------------------------------------------
+	// 5. GET PENDING FRIEND REQUESTS (Incoming)
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id (recipient)
+	// Returns: Users who have sent friend requests
+	// Usage: Show incoming friend requests for user to accept/reject
+	// Performance: Uses idx_user_friendships_friend_id
+	GetPendingRequestsReceived(ctx context.Context, friendID string) ([]GetPendingRequestsReceivedRow, error)
+	// ----------------------------------------------------------------------------
+	// 6. GET SENT FRIEND REQUESTS (Outgoing)
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id (requester)
+	// Returns: Users to whom current user has sent pending requests
+	// Usage: Show outgoing friend requests (for cancellation)
+	// Performance: Uses idx_user_friendships_user_id
+	GetPendingRequestsSent(ctx context.Context, userID string) ([]GetPendingRequestsSentRow, error)
+	// ----------------------------------------------------------------------------
+	// PLATFORM ANALYTICS
+	// ----------------------------------------------------------------------------
+	// 5. GET PLATFORM OVERVIEW STATS
+	// Parameters: None
+	// Returns: Platform-wide statistics
+	// Usage: Admin dashboard, homepage stats
+	GetPlatformOverviewStats(ctx context.Context) (GetPlatformOverviewStatsRow, error)
+	// ----------------------------------------------------------------------------
+	// POPULAR/TRENDING CONTENT
+	// ----------------------------------------------------------------------------
+	// 7. GET POPULAR BREWS
+	// Parameters: $1 = days (e.g., 30 for last 30 days), $2 = limit
+	// Returns: Most-used brews in time period
+	// Usage: "Trending brews" section
+	// Performance: Uses idx_post_brew_id and idx_post_created_at
+	GetPopularBrews(ctx context.Context, arg GetPopularBrewsParams) ([]GetPopularBrewsRow, error)
+	// 8. GET POPULAR POSTS
+	// Parameters: $1 = days (time window), $2 = limit
+	// Returns: Posts with most engagement (likes + comments)
+	// Usage: "Trending posts" feed
+	// Performance: Aggregates engagement metrics
+	GetPopularPosts(ctx context.Context, arg GetPopularPostsParams) ([]GetPopularPostsRow, error)
+	// 4. GET POST LIKE COUNT
+	// Parameters: $1 = post_id
+	// Returns: Number of likes on a post
+	// Usage: Display like count
+	GetPostLikeCount(ctx context.Context, postID string) (int64, error)
+	// 3. GET USERS WHO LIKED A POST
+	// Parameters: $1 = post_id, $2 = limit, $3 = offset
+	// Returns: List of users who liked this post
+	// Usage: Display "Liked by X, Y, and 23 others"
+	// Performance: Uses idx_post_likes_post_id
+	GetPostLikers(ctx context.Context, arg GetPostLikersParams) ([]GetPostLikersRow, error)
+	// ----------------------------------------------------------------------------
+	// 2. GET SINGLE POST WITH FULL DETAILS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = post_id
+	// Returns: Post with owner info, brew details, media, like count, comment count
+	// Usage: Display detailed post view
+	// Performance: Multiple LEFT JOINs, uses multiple indexes
+	GetPostWithDetails(ctx context.Context, id string) (GetPostWithDetailsRow, error)
+	// ----------------------------------------------------------------------------
+	// 8. GET POSTS BY BREW
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = brew_id, $2 = limit, $3 = offset
+	// Returns: All posts using a specific brew
+	// Usage: View all posts for a particular coffee brew
+	// Performance: Uses idx_post_brew_id
+	GetPostsByBrew(ctx context.Context, arg GetPostsByBrewParams) ([]GetPostsByBrewRow, error)
+	// 20. GET POSTS WHERE USER IS TAGGED
+	// Parameters: $1 = user_id, $2 = limit, $3 = offset
+	// Returns: Posts where user has been tagged
+	// Usage: "Posts you're tagged in" section on profile
+	GetPostsWhereUserIsTagged(ctx context.Context, arg GetPostsWhereUserIsTaggedParams) ([]GetPostsWhereUserIsTaggedRow, error)
+	// ----------------------------------------------------------------------------
+	// 9. GET POUR CURVE BY CLIENT ID
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id, $2 = client_id
+	// Returns: The curve the user's bridge streamed with that client_id
+	// Usage: Resume a dropped pour stream
+	GetPourCurveByClientID(ctx context.Context, arg GetPourCurveByClientIDParams) (PourCurve, error)
+	// ----------------------------------------------------------------------------
+	// 2. GET PUBLIC BREW
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = brew_id
+	// Returns: A public brew with its brewer's username; no rows for private
+	//
+	//	brews
+	//
+	// Usage: Public brew JSON and oEmbed
+	GetPublicBrew(ctx context.Context, id string) (GetPublicBrewRow, error)
+	// ----------------------------------------------------------------------------
+	// 9. GET PUBLIC CLUB
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = club_id
+	// Returns: A public club; no rows for private clubs
+	// Usage: Club Atom feed
+	GetPublicClub(ctx context.Context, id string) (GetPublicClubRow, error)
+	// ----------------------------------------------------------------------------
+	// 5. GET PUBLIC POSTS (Discovery Feed)
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = limit, $2 = offset
+	// Returns: Recent public posts from all users
+	// Usage: Public discovery feed for non-friends
+	// Performance: Uses idx_post_visibility
+	GetPublicPosts(ctx context.Context, arg GetPublicPostsParams) ([]GetPublicPostsRow, error)
+	// ----------------------------------------------------------------------------
+	// 7. GET PUBLIC PROFILE
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = username (case-insensitive)
+	// Returns: A user's public profile fields with counts of their public
+	//
+	//	recipes and brews
+	//
+	// Usage: Public profile JSON
+	// Performance: Uses idx_user_username_lower
+	GetPublicProfile(ctx context.Context, lower string) (GetPublicProfileRow, error)
+	// ============================================================================
+	// PUBLIC CONTENT QUERIES
+	// ============================================================================
+	// Read-only lookups of public recipes and brews for unauthenticated
+	// consumers: the public JSON representation and oEmbed previews
+	// ----------------------------------------------------------------------------
+	// 1. GET PUBLIC RECIPE
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = recipe_id
+	// Returns: A public recipe with its current revision's parameters and its
+	//
+	//	owner's username; no rows for private recipes
+	//
+	// Usage: Public recipe JSON and oEmbed
+	GetPublicRecipe(ctx context.Context, id string) (GetPublicRecipeRow, error)
+	// 9. GET RATING DISTRIBUTION
+	// Parameters: None
+	// Returns: Count of posts by rating
+	// Usage: Rating distribution chart
+	GetRatingDistribution(ctx context.Context) ([]GetRatingDistributionRow, error)
+	// 13. GET RECENTLY JOINED USERS
+	// Parameters: $1 = days, $2 = limit
+	// Returns: Users who joined recently
+	// Usage: "New to the community" section
+	GetRecentlyJoinedUsers(ctx context.Context, arg GetRecentlyJoinedUsersParams) ([]GetRecentlyJoinedUsersRow, error)
+	// ----------------------------------------------------------------------------
+	// 2. GET RECIPE BY ID
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = recipe_id
+	// Returns: Single recipe record
+	// Usage: View a recipe; callers must check is_public / owner_id for access
+	GetRecipeByID(ctx context.Context, id string) (Recipe, error)
+	// ----------------------------------------------------------------------------
+	// 14. GET RECIPE COLLABORATOR ROLE
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = recipe_id, $2 = user_id
+	// Returns: The user's role, or no rows if they are not an accepted collaborator
+	// Usage: Access checks for viewing and editing recipes
+	GetRecipeCollaboratorRole(ctx context.Context, arg GetRecipeCollaboratorRoleParams) (string, error)
+	// ----------------------------------------------------------------------------
+	// 7. GET RECIPE FORK TREE
+	// ----------------------------------------------------------------------------
+	// Parameters: recipe_id (tree root), viewer_id
+	// Returns: The recipe and every fork below it, with depth, owner, and how
+	//
+	//	many brews were made with each; private forks of other users
+	//	(and everything below them) are skipped
+	//
+	// Usage: Browse a recipe's fork tree, find the most brewed fork
+	// Performance: Uses idx_recipe_forked_from and idx_brew_recipe
+	GetRecipeForkTree(ctx context.Context, arg GetRecipeForkTreeParams) ([]GetRecipeForkTreeRow, error)
+	// ----------------------------------------------------------------------------
+	// 8. GET RECIPE LINEAGE
+	// ----------------------------------------------------------------------------
+	// Parameters: recipe_id, viewer_id
+	// Returns: The recipes this one descends from, nearest first, and whether
+	//
+	//	the viewer may see each one
+	//
+	// Usage: Attribution ("forked from X, originally by Y")
+	GetRecipeLineage(ctx context.Context, arg GetRecipeLineageParams) ([]GetRecipeLineageRow, error)
+	// ----------------------------------------------------------------------------
+	// 3. GET RECIPE REVISION
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = recipe_id, $2 = revision
+	// Returns: Single revision snapshot
+	// Usage: View a past version, resolve the revision a brew is pinned to
+	GetRecipeRevision(ctx context.Context, arg GetRecipeRevisionParams) (RecipeRevision, error)
+	// ----------------------------------------------------------------------------
+	// 5. GET REFERRAL STATS
+	// ----------------------------------------------------------------------------
+	// Parameters: inviter_id
+	// Returns: Invite uses allotted against the quota, users who signed up
+	//
+	//	with the user's invites (in all, in the last 30 days), and how
+	//	many of those logged a brew in the last 30 days
+	//
+	// Usage: Referral stats endpoint
+	// Performance: Uses idx_invite_inviter, idx_user_invited_by and
+	//
+	//	idx_brew_created_by
+	GetReferralStats(ctx context.Context, inviterID string) (GetReferralStatsRow, error)
+	// 12. GET REPLIES TO COMMENT
+	// Parameters: $1 = parent_comment_id
+	// Returns: Replies to a specific comment with user info and like count
+	// Usage: Load threaded replies (nested comments)
+	// Performance: Uses idx_comment_parent_comment_id
+	GetRepliesToComment(ctx context.Context, parentCommentID *string) ([]GetRepliesToCommentRow, error)
+	// ----------------------------------------------------------------------------
+	// 2. GET ROASTER BY ID
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id
+	// Returns: The roaster
+	// Usage: Roaster profile
+	GetRoasterByID(ctx context.Context, id string) (Roaster, error)
+	// ----------------------------------------------------------------------------
+	// 3. GET ROASTER BY USER ID
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id
+	// Returns: The roaster the user manages
+	// Usage: Publishing official listings and recipes
+	GetRoasterByUserID(ctx context.Context, userID string) (Roaster, error)
+	// ----------------------------------------------------------------------------
+	// 7. GET RUNNING BACKUP RUN
+	// ----------------------------------------------------------------------------
+	// Parameters: None
+	// Returns: The run in progress, if any
+	// Usage: Backup job picks up runs admins triggered on any instance, and
+	//
+	//	runs left in progress by an instance that lost the job
+	//
+	// Performance: Uses idx_backup_run_running
+	GetRunningBackupRun(ctx context.Context) (BackupRun, error)
+	// ----------------------------------------------------------------------------
+	// 8. GET RUNNING BREW
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id
+	// Returns: The user's most recently started brew whose timer is running
+	// Usage: Attach a pour stream to the brew being made
+	// Performance: Uses idx_brew_created_by
+	GetRunningBrew(ctx context.Context, createdBy *string) (Brew, error)
+	// ----------------------------------------------------------------------------
+	// 3. GET SCIM CLIENT BY HASH
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = token_hash
+	// Returns: The client with that token
+	// Usage: Authenticating a SCIM request
+	GetSCIMClientByHash(ctx context.Context, tokenHash string) (ScimClient, error)
+	// ----------------------------------------------------------------------------
+	// 7. GET SCIM USER
+	// ----------------------------------------------------------------------------
+	// Parameters: client_id, id
+	// Returns: An account the client provisioned
+	// Usage: Reading one account, and the current state a PATCH applies to
+	GetSCIMUser(ctx context.Context, arg GetSCIMUserParams) (GetSCIMUserRow, error)
+	// ----------------------------------------------------------------------------
+	// RECOMMENDATIONS
+	// ----------------------------------------------------------------------------
+	// 11. GET SIMILAR BREWS
+	// Parameters: $1 = brew_id
+	// Returns: Brews with similar characteristics (same method or origin)
+	// Usage: "You might also like" recommendations
+	GetSimilarBrews(ctx context.Context, id string) ([]GetSimilarBrewsRow, error)
+	// 12. GET SUGGESTED FRIENDS
+	// Parameters: $1 = current_user_id, $2 = limit
+	// Returns: Users with mutual friends (friend-of-friend suggestions)
+	// Usage: "People you may know" recommendations
+	GetSuggestedFriends(ctx context.Context, arg GetSuggestedFriendsParams) ([]GetSuggestedFriendsRow, error)
+	// ----------------------------------------------------------------------------
+	// 3. GET TDS READING
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id
+	// Returns: A single reading; callers must check user_id
+	// Usage: Reattaching or deleting a reading
+	GetTDSReading(ctx context.Context, id string) (TdsReading, error)
+	// ----------------------------------------------------------------------------
+	// 2. GET TDS READING BY CLIENT ID
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id, $2 = client_id
+	// Returns: The reading the user sent with that client_id
+	// Usage: Answer a resent reading with the stored one
+	GetTDSReadingByClientID(ctx context.Context, arg GetTDSReadingByClientIDParams) (TdsReading, error)
+	// ----------------------------------------------------------------------------
+	// 4. GET TOMBSTONE
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = resource, $2 = record_id, $3 = user_id
+	// Returns: The user's tombstone for the record, if it was deleted
+	// Usage: A pushed change for a record deleted on the server
+	GetTombstone(ctx context.Context, arg GetTombstoneParams) (SyncTombstone, error)
+	// 10. GET TOP CONTRIBUTORS
+	// Parameters: $1 = days (time window), $2 = limit
+	// Returns: Most active users by various metrics
+	// Usage: "Top contributors" leaderboard
+	GetTopContributors(ctx context.Context, arg GetTopContributorsParams) ([]GetTopContributorsRow, error)
+	// 16. GET TOP-LEVEL COMMENT COUNT FOR POST
+	// Parameters: $1 = post_id
+	// Returns: Number of top-level comments only (excludes replies)
+	// Usage: Display "X comments" excluding nested replies
+	GetTopLevelCommentCount(ctx context.Context, postID string) (int64, error)
+	// 9. GET TOP RATED BREWS
+	// Parameters: $1 = minimum_post_count, $2 = limit
+	// Returns: Highest-rated brews with enough posts for statistical validity
+	// Usage: "Best brews" discovery
+	GetTopRatedBrews(ctx context.Context, arg GetTopRatedBrewsParams) ([]GetTopRatedBrewsRow, error)
+	// ----------------------------------------------------------------------------
+	// 8. GET UNREAD NOTIFICATION COUNT
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = recipient_user_id
+	// Returns: Number of unread notifications
+	// Usage: Display badge count on notification bell
+	GetUnreadNotificationCount(ctx context.Context, recipientUserID string) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 2. GET UNREAD NOTIFICATIONS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = recipient_user_id, $2 = limit
+	// Returns: Unread notifications with actor info
+	// Usage: Show notification dropdown/badge
+	// Performance: Uses idx_notification_is_read
+	GetUnreadNotifications(ctx context.Context, arg GetUnreadNotificationsParams) ([]GetUnreadNotificationsRow, error)
+	// ============================================================================
+	// ANALYTICS QUERIES
+	// ============================================================================
+	// Operations for statistics, metrics, and insights
+	// ----------------------------------------------------------------------------
+	// USER ANALYTICS
+	// ----------------------------------------------------------------------------
+	// 1. GET USER ACTIVITY STATS
+	// Parameters: $1 = user_id
+	// Returns: Comprehensive user statistics
+	// Usage: User dashboard, admin analytics, insights page
+	// Note: Uses subqueries to prevent Cartesian product and ensure accurate counts
+	GetUserActivityStats(ctx context.Context, id string) (GetUserActivityStatsRow, error)
+	// ----------------------------------------------------------------------------
+	// 10. GET USER BEAN COST BREAKDOWN
+	// ----------------------------------------------------------------------------
+	// Parameters: group_by ('roaster' or 'origin'), default_dose_grams, user_id
+	// Returns: Per roaster (or origin) and currency: priced bags, amount spent,
+	//
+	//	brews (archived ones included) and their bean cost, highest
+	//	spend first. Bags without a roaster (or origin) are grouped
+	//	under a NULL label.
+	//
+	// Usage: Cost breakdown screen
+	GetUserBeanCostBreakdown(ctx context.Context, arg GetUserBeanCostBreakdownParams) ([]GetUserBeanCostBreakdownRow, error)
+	// ============================================================================
+	// BILLING QUERIES
+	// ============================================================================
+	// Operations for supporter subscriptions: Stripe customers, checkout and
+	// webhook-reported subscription state.
+	// ----------------------------------------------------------------------------
+	// 1. GET USER BILLING
+	// ----------------------------------------------------------------------------
+	// Parameters: id
+	// Returns: The user's email, plan, Stripe customer and subscription state
+	// Usage: Start a checkout, show the plan endpoint's subscription
+	GetUserBilling(ctx context.Context, id string) (GetUserBillingRow, error)
+	// ----------------------------------------------------------------------------
+	// 4. GET USER BREW DAYS
+	// ----------------------------------------------------------------------------
+	// Parameters: user_id, timezone (IANA name, e.g. 'America/New_York')
+	// Returns: Personal brew count per local calendar day, newest first,
+	//
+	//	archived brews included
+	//
+	// Usage: Stats and streaks, bucketed by the user's own day boundaries
+	// Performance: Uses idx_brew_created_by and idx_brew_archive_created_by
+	GetUserBrewDays(ctx context.Context, arg GetUserBrewDaysParams) ([]GetUserBrewDaysRow, error)
+	// ----------------------------------------------------------------------------
+	// 6. GET USER BREW EVENT RSVP
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = event_id, $2 = user_id
+	// Returns: The user's RSVP status, or no rows if they haven't answered
+	// Usage: Show the viewer's own answer on the event page
+	GetUserBrewEventRSVP(ctx context.Context, arg GetUserBrewEventRSVPParams) (string, error)
+	// ----------------------------------------------------------------------------
+	// 8. GET USER BREW TIME STATS
+	// ----------------------------------------------------------------------------
+	// Parameters: user_id, long_methods (brew methods timed in hours/days)
+	// Returns: Average brew time for regular and long-duration methods, kept
+	//
+	//	apart so cold brews don't skew the regular average, archived
+	//	brews included
+	//
+	// Usage: Stats page
+	GetUserBrewTimeStats(ctx context.Context, arg GetUserBrewTimeStatsParams) (GetUserBrewTimeStatsRow, error)
+	// ----------------------------------------------------------------------------
+	// 9. GET USER BREW TOTALS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id
+	// Returns: Brews logged, distinct bean origins (case-insensitive) and
+	//
+	//	distinct brew methods
+	//
+	// Usage: Challenge progress
+	GetUserBrewTotals(ctx context.Context, createdBy *string) (GetUserBrewTotalsRow, error)
+	// ----------------------------------------------------------------------------
+	// 3. GET USER BY EMAIL (Authentication)
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = email
+	// Returns: User record including password_hash for authentication, and
+	//
+	//	whether the account was deactivated or awaits parental consent
+	//
+	// Usage: Login verification (compare hashed passwords)
+	// Performance: Uses idx_user_email_lower
+	GetUserByEmail(ctx context.Context, email string) (GetUserByEmailRow, error)
+	// ----------------------------------------------------------------------------
+	// 2. GET USER BY ID
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id
+	// Returns: Single user record (excludes password_hash for security)
+	// Usage: View user profiles, verify authentication
+	GetUserByID(ctx context.Context, id string) (GetUserByIDRow, error)
+	// ----------------------------------------------------------------------------
+	// 4. GET USER BY USERNAME
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = username
+	// Returns: Single user record
+	// Usage: View profiles by username, check username availability
+	GetUserByUsername(ctx context.Context, username string) (GetUserByUsernameRow, error)
+	// ----------------------------------------------------------------------------
+	// 16. GET USER CUSTOM FIELD RATINGS
+	// ----------------------------------------------------------------------------
+	// Parameters: name (custom field), user_id
+	// Returns: Per value of the field: the user's brews with that value, how
+	//
+	//	many of the user's posts about them carry a rating, and the
+	//	average of those ratings; best rated first. Brews without a
+	//	value are left out.
+	//
+	// Usage: "My average rating by filter paper" and similar custom field stats
+	// Performance: Uses idx_brew_created_by
+	GetUserCustomFieldRatings(ctx context.Context, arg GetUserCustomFieldRatingsParams) ([]GetUserCustomFieldRatingsRow, error)
+	// 3. GET USER'S FAVORITE BREW METHODS
+	// Parameters: $1 = user_id
+	// Returns: Brew methods user posts about most
+	// Usage: User preferences insights
+	GetUserFavoriteBrewMethods(ctx context.Context, ownerID string) ([]GetUserFavoriteBrewMethodsRow, error)
+	// ----------------------------------------------------------------------------
+	// 3. GET USER FEED (Posts from Friends)
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = current_user_id, $2 = limit, $3 = offset
+	// Returns: Posts from friends, sorted by recency, with pagination
+	// Usage: Main feed feature - see what friends are brewing
+	// Performance: Uses idx_user_friendships_status and idx_post_created_at
+	GetUserFeed(ctx context.Context, arg GetUserFeedParams) ([]GetUserFeedRow, error)
+	// ----------------------------------------------------------------------------
+	// 8. GET USER MONTHLY BEAN SPEND
+	// ----------------------------------------------------------------------------
+	// Parameters: timezone (IANA name), user_id, since (first month to include)
+	// Returns: Amount spent on priced bags per month and currency, oldest first;
+	//
+	//	a bag counts in the month it was purchased (or added, if unset)
+	//
+	// Usage: Cost stats
+	// Performance: Uses idx_bean_bag_owner_id
+	GetUserMonthlyBeanSpend(ctx context.Context, arg GetUserMonthlyBeanSpendParams) ([]GetUserMonthlyBeanSpendRow, error)
+	// ----------------------------------------------------------------------------
+	// 9. GET USER MONTHLY BREW COSTS
+	// ----------------------------------------------------------------------------
+	// Parameters: timezone (IANA name), default_dose_grams, user_id, since
+	// Returns: Number of brews from priced bags and their bean cost (fractional
+	//
+	//	minor units) per local month and currency, oldest first,
+	//	archived brews included
+	//
+	// Usage: Cost-per-cup stats; a brew costs its dose's share of the bag price
+	// Performance: Uses idx_brew_created_by and idx_brew_archive_created_by
+	GetUserMonthlyBrewCosts(ctx context.Context, arg GetUserMonthlyBrewCostsParams) ([]GetUserMonthlyBrewCostsRow, error)
+	// ----------------------------------------------------------------------------
+	// 11. GET USER ORIGIN RATINGS
+	// ----------------------------------------------------------------------------
+	// Parameters: group_by ('country', 'region', 'variety' or 'process'), user_id
+	// Returns: Per origin value: the user's brews from bags with that value,
+	//
+	//	how many of the user's posts about them carry a rating, and the
+	//	average of those ratings; best rated first. Bags without the
+	//	value are left out.
+	//
+	// Usage: "My average rating by process" and similar origin stats
+	// Performance: Uses idx_brew_created_by
+	GetUserOriginRatings(ctx context.Context, arg GetUserOriginRatingsParams) ([]GetUserOriginRatingsRow, error)
+	// ----------------------------------------------------------------------------
+	// 14. GET USER PLAN
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id
+	// Returns: The user's plan tier
+	// Usage: Quota middleware, cached per user
+	GetUserPlan(ctx context.Context, id string) (string, error)
+	// 2. GET USER POSTING ACTIVITY OVER TIME
+	// Parameters: $1 = user_id, $2 = days (e.g., 30)
+	// Returns: Posts per day in time period
+	// Usage: Activity graph on profile
+	GetUserPostingActivityOverTime(ctx context.Context, arg GetUserPostingActivityOverTimeParams) ([]GetUserPostingActivityOverTimeRow, error)
+	// ----------------------------------------------------------------------------
+	// 4. GET USER'S OWN POSTS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = owner_id
+	// Returns: All posts created by a specific user
+	// Usage: User profile page showing their post history
+	// Performance: Uses idx_post_owner_id
+	GetUserPosts(ctx context.Context, ownerID string) ([]GetUserPostsRow, error)
+	// ----------------------------------------------------------------------------
+	// 12. GET USER PREFERENCES
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id
+	// Returns: The user's display units, timezone, currency and analytics
+	//
+	//	consent
+	//
+	// Usage: Convert brew payloads to/from canonical metric storage, local day
+	//
+	//	boundaries, default currency for bean prices, whether to keep
+	//	client analytics events
+	GetUserPreferences(ctx context.Context, id string) (GetUserPreferencesRow, error)
+	// ----------------------------------------------------------------------------
+	// 6. GET USER PROFILE WITH STATS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id
+	// Returns: User info + post_count, friend_count, avg_rating
+	// Usage: Display rich user profiles with activity statistics
+	// Performance: Uses LEFT JOINs and aggregations, leverages multiple indexes
+	GetUserProfileWithStats(ctx context.Context, id string) (GetUserProfileWithStatsRow, error)
+	// ----------------------------------------------------------------------------
+	// RETENTION & COHORT ANALYSIS
+	// ----------------------------------------------------------------------------
+	// 14. GET USER RETENTION BY COHORT
+	// Parameters: $1 = cohort_month (e.g., '2024-01')
+	// Returns: Users who joined in cohort and posted in subsequent months
+	// Usage: Retention analysis
+	GetUserRetentionByCohort(ctx context.Context, joinedAt pgtype.Timestamptz) ([]GetUserRetentionByCohortRow, error)
+	// ============================================================================
+	// USER STORAGE QUERIES
+	// ============================================================================
+	// Reading the bytes users keep in the object store, which triggers on their
+	// photos keep current (see schema/triggers.sql)
+	// ----------------------------------------------------------------------------
+	// 1. GET USER STORAGE
+	// ----------------------------------------------------------------------------
+	// Parameters: user_id
+	// Returns: Bytes of photos the user stores; 0 without a row
+	// Usage: Storage quota checks at upload, and usage on the profile
+	// Performance: Uses the primary key
+	GetUserStorage(ctx context.Context, userID string) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 5. GET USER TOTP
+	// ----------------------------------------------------------------------------
+	// Parameters: id
+	// Returns: The user's username and TOTP enrollment
+	// Usage: Login picks the step-up method; TOTP enrollment
+	GetUserTOTP(ctx context.Context, id string) (GetUserTOTPRow, error)
+	// 4. GET USER'S TOP BREWS
+	// Parameters: $1 = user_id, $2 = limit
+	// Returns: Brews user has posted about most
+	// Usage: "Your top brews" section
+	GetUserTopBrews(ctx context.Context, arg GetUserTopBrewsParams) ([]GetUserTopBrewsRow, error)
+	// 19. GET USERS TAGGED IN POST
+	// Parameters: $1 = post_id
+	// Returns: List of users tagged in this post
+	// Usage: Display "with X and Y" in post
+	GetUsersTaggedInPost(ctx context.Context, postID string) ([]GetUsersTaggedInPostRow, error)
+	// ----------------------------------------------------------------------------
+	// 5. GET VISIBLE BREW
+	// ----------------------------------------------------------------------------
+	// Parameters: brew_id, viewer_id
+	// Returns: The brew if the viewer may see it: they logged it, it's public,
+	//
+	//	or it's shared with them or a club they belong to; otherwise no
+	//	rows
+	//
+	// Usage: Viewing a brew and anything attached to it
+	GetVisibleBrew(ctx context.Context, arg GetVisibleBrewParams) (Brew, error)
+	// ----------------------------------------------------------------------------
+	// 9. GET VISIBLE BREWS BY IDS
+	// ----------------------------------------------------------------------------
+	// Parameters: ids (brew IDs), viewer_id
+	// Returns: The matching brews the viewer may see, as in GetVisibleBrew, in
+	//
+	//	no particular order; missing and hidden IDs are skipped
+	//
+	// Usage: Brew comparison
+	GetVisibleBrewsByIDs(ctx context.Context, arg GetVisibleBrewsByIDsParams) ([]Brew, error)
+	// ----------------------------------------------------------------------------
+	// 6. GET VISIBLE RECIPE
+	// ----------------------------------------------------------------------------
+	// Parameters: recipe_id, viewer_id
+	// Returns: The recipe if the viewer may see it: they own it, it's public,
+	//
+	//	they're an accepted collaborator, or it's shared with them or a
+	//	club they belong to; otherwise no rows
+	//
+	// Usage: Viewing a recipe, its revisions and forks
+	GetVisibleRecipe(ctx context.Context, arg GetVisibleRecipeParams) (Recipe, error)
+	// ----------------------------------------------------------------------------
+	// 2. GRANT PARENTAL CONSENT
+	// ----------------------------------------------------------------------------
+	// Parameters: token_hash, granted_ip
+	// Returns: The user the consent was for; no rows if the request is unknown,
+	//
+	//	expired or already granted
+	//
+	// Usage: A parent consents, unblocking their child's account in the same
+	//
+	//	statement
+	GrantParentalConsent(ctx context.Context, arg GrantParentalConsentParams) (GrantParentalConsentRow, error)
+	// ============================================================================
+	// ANALYTICS EVENT QUERIES
+	// ============================================================================
+	// Operations for storing client analytics events
+	// ----------------------------------------------------------------------------
+	// 1. INSERT ANALYTICS EVENTS
+	// ----------------------------------------------------------------------------
+	// Parameters: user_id, events (JSON array of objects with id, session_id,
+	//
+	//	name, properties and occurred_at)
+	//
+	// Returns: Nothing
+	// Usage: Database analytics sink; one round trip per client batch
+	InsertAnalyticsEvents(ctx context.Context, arg InsertAnalyticsEventsParams) error
+	// ----------------------------------------------------------------------------
+	// 9. INVITE RECIPE COLLABORATOR
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = recipe_id, $2 = user_id, $3 = role, $4 = invited_by
+	// Returns: The invitation; re-inviting an existing collaborator changes
+	//
+	//	their role and keeps their acceptance
+	//
+	// Usage: Recipe owner invites a co-author ('editor') or reader ('viewer')
+	InviteRecipeCollaborator(ctx context.Context, arg InviteRecipeCollaboratorParams) (RecipeCollaborator, error)
+	// ----------------------------------------------------------------------------
+	// 9. IS CUPPING PARTICIPANT
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = session_id, $2 = user_id
+	// Returns: Whether the user was invited to the session
+	// Usage: Access checks for participants
+	IsCuppingParticipant(ctx context.Context, arg IsCuppingParticipantParams) (bool, error)
+	// ----------------------------------------------------------------------------
+	// 2. IS EMAIL DOMAIN BLOCKED
+	// ----------------------------------------------------------------------------
+	// Parameters: domains (an address's domain and its parent domains)
+	// Returns: Whether the most specific override blocks the domain, or
+	//
+	//	without one, whether the blocklist lists it
+	//
+	// Usage: Registration and waitlist signups
+	// Performance: Primary key lookups
+	IsEmailDomainBlocked(ctx context.Context, domains []string) (bool, error)
+	// ----------------------------------------------------------------------------
+	// 2. IS EMAIL SUPPRESSED
+	// ----------------------------------------------------------------------------
+	// Parameters: email
+	// Returns: Whether mail to the address is suppressed
+	// Usage: Mailer, before every send
+	// Performance: Primary key lookup
+	IsEmailSuppressed(ctx context.Context, email string) (bool, error)
+	// ----------------------------------------------------------------------------
+	// 16. IS USER ACTIVE
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id
+	// Returns: Whether the user exists and hasn't been deactivated
+	// Usage: Authentication middleware, cached per user, so tokens issued
+	//
+	//	before an account was deactivated or deleted stop working
+	//
+	// Performance: Uses the primary key
+	IsUserActive(ctx context.Context, id string) (bool, error)
+	// ----------------------------------------------------------------------------
+	// 2. JOIN REALTIME PRESENCE
+	// ----------------------------------------------------------------------------
+	// Parameters: connection_id, topic, user_id, instance_id
+	// Returns: Nothing
+	// Usage: A stream on a topic opening on this instance
+	JoinRealtimePresence(ctx context.Context, arg JoinRealtimePresenceParams) error
+	// ============================================================================
+	// WAITLIST QUERIES
+	// ============================================================================
+	// Operations for the registration waitlist: queueing emails, approving
+	// them in batches with a single-use invite each, and tracking delivery of
+	// the invitation emails. Emails are stored lowercased.
+	// ----------------------------------------------------------------------------
+	// 1. JOIN WAITLIST
+	// ----------------------------------------------------------------------------
+	// Parameters: email
+	// Returns: None
+	// Usage: Registration in waitlist mode. Joining twice keeps the original
+	//
+	//	place in line.
+	JoinWaitlist(ctx context.Context, email string) error
+	// ----------------------------------------------------------------------------
+	// 3. LEAVE REALTIME PRESENCE
+	// ----------------------------------------------------------------------------
+	// Parameters: connection_id
+	// Returns: Nothing
+	// Usage: A stream closing, whatever others its user has open
+	// Performance: Uses the primary key
+	LeaveRealtimePresence(ctx context.Context, connectionID string) error
+	// ----------------------------------------------------------------------------
+	// COMMENT LIKES
+	// ----------------------------------------------------------------------------
+	// 6. LIKE A COMMENT
+	// Parameters: $1 = comment_id, $2 = user_id
+	// Returns: Created like record
+	// Usage: User likes a comment
+	// Note: ON CONFLICT makes this idempotent
+	LikeComment(ctx context.Context, arg LikeCommentParams) (CommentLike, error)
+	// ============================================================================
+	// INTERACTION QUERIES
+	// ============================================================================
+	// Operations for likes and comments on posts and comments
+	// ----------------------------------------------------------------------------
+	// POST LIKES
+	// ----------------------------------------------------------------------------
+	// 1. LIKE A POST
+	// Parameters: $1 = post_id, $2 = user_id
+	// Returns: Created like record
+	// Usage: User likes a post
+	// Note: ON CONFLICT makes this idempotent (can call multiple times safely)
+	LikePost(ctx context.Context, arg LikePostParams) (PostLike, error)
+	// ----------------------------------------------------------------------------
+	// 4. LIST ARCHIVED BREW FLAVORS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = brew_id
+	// Returns: The archived brew's descriptors in wheel order
+	// Usage: Showing an archived brew's tasting notes, once its owner has
+	//
+	//	loaded it
+	//
+	// Performance: Primary key lookup
+	ListArchivedBrewFlavors(ctx context.Context, brewID string) ([]FlavorDescriptor, error)
+	// ----------------------------------------------------------------------------
+	// 3. LIST AUTH EVENTS
+	// ----------------------------------------------------------------------------
+	// Parameters: user_id (NULL for all users), event (NULL for all events),
+	//
+	//	row_limit, row_offset
+	//
+	// Returns: Auth events with the user's username, newest first
+	// Usage: Admin audit log
+	// Performance: Uses idx_auth_event_user when filtered by user
+	ListAuthEvents(ctx context.Context, arg ListAuthEventsParams) ([]ListAuthEventsRow, error)
+	// ----------------------------------------------------------------------------
+	// 5. LIST BACKUP RUNS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = limit, $2 = offset
+	// Returns: Backup runs, newest first
+	// Usage: Admin backup history
+	// Performance: Uses idx_backup_run_started_at
+	ListBackupRuns(ctx context.Context, arg ListBackupRunsParams) ([]BackupRun, error)
+	// ----------------------------------------------------------------------------
+	// 6. LIST BEAN BAG FLAVORS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = bean_bag_id
+	// Returns: The bag's own descriptors in wheel order
+	// Usage: Show a bag's flavor notes
+	ListBeanBagFlavors(ctx context.Context, beanBagID string) ([]FlavorDescriptor, error)
+	// ----------------------------------------------------------------------------
+	// 7. LIST BEAN RECIPES
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = bean_id
+	// Returns: Public official recipes on a listing, oldest published first
+	// Usage: Bean page
+	ListBeanRecipes(ctx context.Context, beanID string) ([]ListBeanRecipesRow, error)
+	// ----------------------------------------------------------------------------
+	// 5. LIST BEAN RECOMMENDATIONS
+	// ----------------------------------------------------------------------------
+	// Parameters: user_id, row_limit
+	// Returns: The user's recommended catalogue beans, best first, and when
+	//
+	//	they were scored
+	//
+	// Usage: Recommendations screen
+	// Performance: Uses idx_recommendation_user_score
+	ListBeanRecommendations(ctx context.Context, arg ListBeanRecommendationsParams) ([]ListBeanRecommendationsRow, error)
+	// ----------------------------------------------------------------------------
+	// 4. LIST BEANS
+	// ----------------------------------------------------------------------------
+	// Parameters: search (name or roaster contains, case-insensitive; NULL for
+	//
+	//	all), roaster_id, origin_country, process, variety (NULL
+	//	matches any), official_only, row_limit, row_offset
+	//
+	// Returns: Beans with official listings first, then by name, with whether
+	//
+	//	the listing's roaster is verified
+	//
+	// Usage: Bean catalogue browsing and roaster pages
+	ListBeans(ctx context.Context, arg ListBeansParams) ([]ListBeansRow, error)
+	// ============================================================================
+	// SYNC QUERIES
+	// ============================================================================
+	// Operations for the mobile client's offline sync: changes since a cursor,
+	// tombstones of deleted records, and revision vectors
+	// ----------------------------------------------------------------------------
+	// 1. LIST BREW CHANGES
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id, $2 = since (sync_seq cursor), $3 = limit
+	// Returns: The user's personal brews changed after the cursor, oldest change first
+	// Usage: Sync pull
+	// Performance: Uses idx_brew_sync
+	ListBrewChanges(ctx context.Context, arg ListBrewChangesParams) ([]Brew, error)
+	// ----------------------------------------------------------------------------
+	// 7. LIST BREW EVENT RSVPS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = event_id
+	// Returns: Users going or maybe going, with usernames, going first
+	// Usage: Attendee list
+	ListBrewEventRSVPs(ctx context.Context, eventID string) ([]ListBrewEventRSVPsRow, error)
+	// ----------------------------------------------------------------------------
+	// 4. LIST BREW FLAVORS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = brew_id
+	// Returns: The brew's descriptors in wheel order
+	// Usage: Show a brew's tasting notes
+	ListBrewFlavors(ctx context.Context, brewID string) ([]FlavorDescriptor, error)
+	// ----------------------------------------------------------------------------
+	// 2. LIST BREW PARTITIONS
+	// ----------------------------------------------------------------------------
+	// Parameters: none
+	// Returns: The monthly partitions of brew and the month each holds, oldest
+	//
+	//	first. brew_legacy and brew_default aren't listed.
+	//
+	// Usage: Finding partitions past retention
+	// Performance: Reads the catalog
+	ListBrewPartitions(ctx context.Context) ([]ListBrewPartitionsRow, error)
+	// ----------------------------------------------------------------------------
+	// 2. LIST BREW PHOTOS
+	// ----------------------------------------------------------------------------
+	// Parameters: brew_id
+	// Returns: The brew's photos, oldest first, whatever their status
+	// Usage: Brew photo gallery; callers show only ready photos to others
+	// Performance: Uses idx_brew_photo_brew
+	ListBrewPhotos(ctx context.Context, brewID string) ([]BrewPhoto, error)
+	// ----------------------------------------------------------------------------
+	// 4. LIST BREW TDS READINGS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = brew_id
+	// Returns: The brew's readings, in the order they were taken
+	// Usage: Brew detail
+	// Performance: Uses idx_tds_reading_brew
+	ListBrewTDSReadings(ctx context.Context, brewID *string) ([]TdsReading, error)
+	// ----------------------------------------------------------------------------
+	// 3. LIST CALENDAR BREW EVENTS
+	// ----------------------------------------------------------------------------
+	// Parameters: user_id, since (events starting at or after), row_limit
+	// Returns: Brew-alongs the user hosts or is going or maybe going to,
+	//
+	//	soonest first, with the user's RSVP status (NULL when hosting
+	//	without one)
+	//
+	// Usage: Calendar feed
+	// Performance: Uses idx_brew_event_host_id and the RSVP primary key
+	ListCalendarBrewEvents(ctx context.Context, arg ListCalendarBrewEventsParams) ([]ListCalendarBrewEventsRow, error)
+	// ----------------------------------------------------------------------------
+	// 4. LIST CALENDAR RESTING BEAN BAGS
+	// ----------------------------------------------------------------------------
+	// Parameters: owner_id, since (bags ready on or after), row_limit
+	// Returns: The user's open bags with a roast date and resting period, with
+	//
+	//	the date their rest ends, soonest first
+	//
+	// Usage: Calendar feed
+	// Performance: Uses idx_bean_bag_owner_id
+	ListCalendarRestingBeanBags(ctx context.Context, arg ListCalendarRestingBeanBagsParams) ([]ListCalendarRestingBeanBagsRow, error)
+	// ----------------------------------------------------------------------------
+	// 5. LIST CHALLENGES
+	// ----------------------------------------------------------------------------
+	// Parameters: include_inactive
+	// Returns: Challenges ordered by metric and threshold
+	// Usage: Challenge list, badge progress and the badge worker
+	ListChallenges(ctx context.Context, includeInactive bool) ([]Challenge, error)
+	// ----------------------------------------------------------------------------
+	// 21. LIST CLUB FEED
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = club_id, $2 = limit, $3 = offset
+	// Returns: Shares newest first, with sharer usernames and brew or recipe
+	//
+	//	summaries
+	//
+	// Usage: Club feed
+	ListClubFeed(ctx context.Context, arg ListClubFeedParams) ([]ListClubFeedRow, error)
+	// ----------------------------------------------------------------------------
+	// 14. LIST CLUB INVITES
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = club_id
+	// Returns: The club's unrevoked invites, newest first
+	// Usage: Manage invite links
+	ListClubInvites(ctx context.Context, clubID string) ([]ClubInvite, error)
+	// ----------------------------------------------------------------------------
+	// 12. LIST CLUB MEMBERS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = club_id
+	// Returns: Members with usernames: owner, then moderators, then members
+	// Usage: Member list
+	ListClubMembers(ctx context.Context, clubID string) ([]ListClubMembersRow, error)
+	// ----------------------------------------------------------------------------
+	// 10. LIST CUPPING PARTICIPANTS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = session_id
+	// Returns: Participants with usernames and how many samples each has scored
+	// Usage: Track scoring progress without revealing scores
+	ListCuppingParticipants(ctx context.Context, sessionID string) ([]ListCuppingParticipantsRow, error)
+	// ----------------------------------------------------------------------------
+	// 6. LIST CUPPING SAMPLES
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = session_id
+	// Returns: The session's samples by label
+	// Usage: Show the cupping table (identities are hidden by the handler)
+	ListCuppingSamples(ctx context.Context, sessionID string) ([]CuppingSample, error)
+	// ----------------------------------------------------------------------------
+	// 13. LIST CUPPING SCORES
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = session_id
+	// Returns: Every score in the session with scorer usernames
+	// Usage: Aggregate results after the reveal
+	ListCuppingScores(ctx context.Context, sessionID string) ([]ListCuppingScoresRow, error)
+	// ----------------------------------------------------------------------------
+	// 3. LIST CURRENT POLICY DOCUMENTS
+	// ----------------------------------------------------------------------------
+	// Parameters: None
+	// Returns: The current version of each policy
+	// Usage: Showing the policies a new user accepts by registering
+	// Performance: Uses idx_policy_document_kind
+	ListCurrentPolicyDocuments(ctx context.Context) ([]PolicyDocument, error)
+	// ----------------------------------------------------------------------------
+	// 5. LIST DAILY STATS
+	// ----------------------------------------------------------------------------
+	// Parameters: since (first day)
+	// Returns: Daily stats from that day on, newest first
+	// Usage: Admin stats
+	ListDailyStats(ctx context.Context, since pgtype.Date) ([]OpsDailyStat, error)
+	// ----------------------------------------------------------------------------
+	// 8. LIST DISPOSABLE EMAIL USERS
+	// ----------------------------------------------------------------------------
+	// Parameters: row_limit, row_offset
+	// Returns: Flagged users, newest first
+	// Usage: Admin review of flagged signups
+	// Performance: Uses idx_user_disposable_email
+	ListDisposableEmailUsers(ctx context.Context, arg ListDisposableEmailUsersParams) ([]ListDisposableEmailUsersRow, error)
+	// ----------------------------------------------------------------------------
+	// 2. LIST DRAFT CHANGES
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id, $2 = since (sync_seq cursor), $3 = limit
+	// Returns: The user's drafts changed after the cursor, oldest change first
+	// Usage: Sync pull
+	// Performance: Uses idx_draft_sync
+	ListDraftChanges(ctx context.Context, arg ListDraftChangesParams) ([]Draft, error)
+	// ----------------------------------------------------------------------------
+	// 6. LIST DUE IAP PURCHASES
+	// ----------------------------------------------------------------------------
+	// Parameters: row_limit
+	// Returns: Live purchases whose paid or grace period ends within the hour,
+	//
+	//	not verified in the last hour, least recently verified first
+	//
+	// Usage: Renewal checks verify them again, since stores don't notify every
+	//
+	//	renewal or expiry
+	//
+	// Performance: Uses idx_iap_purchase_renewal
+	ListDueIAPPurchases(ctx context.Context, rowLimit int32) ([]IapPurchase, error)
+	// ----------------------------------------------------------------------------
+	// 5. LIST EMAIL DOMAIN OVERRIDES
+	// ----------------------------------------------------------------------------
+	// Parameters: None
+	// Returns: All overrides, alphabetically
+	// Usage: Admin overrides page
+	ListEmailDomainOverrides(ctx context.Context) ([]EmailDomainOverride, error)
+	// ----------------------------------------------------------------------------
+	// 3. LIST EMAIL SUPPRESSIONS
+	// ----------------------------------------------------------------------------
+	// Parameters: row_limit, row_offset
+	// Returns: Suppressed addresses, newest first
+	// Usage: Admin tooling
+	// Performance: Uses idx_email_suppression_created_at
+	ListEmailSuppressions(ctx context.Context, arg ListEmailSuppressionsParams) ([]EmailSuppression, error)
+	// ----------------------------------------------------------------------------
+	// 5. LIST EVENT AUTOMATION WEBHOOKS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id, $2 = event
+	// Returns: IDs of the user's webhooks subscribed to the event
+	// Usage: Outbox dispatcher, queueing deliveries for a brew event
+	// Performance: Uses idx_automation_webhook_user
+	ListEventAutomationWebhooks(ctx context.Context, arg ListEventAutomationWebhooksParams) ([]string, error)
+	// ----------------------------------------------------------------------------
+	// 8. LIST EXPIRED BACKUP RUNS
+	// ----------------------------------------------------------------------------
+	// Parameters: retention_days, batch_size
+	// Returns: Finished runs started over retention_days ago whose dumps are
+	//
+	//	still stored, oldest first, except the newest successful run's
+	//
+	// Usage: Backup job deletes their dumps, then PruneBackupRun
+	// Performance: Uses idx_backup_run_started_at
+	ListExpiredBackupRuns(ctx context.Context, arg ListExpiredBackupRunsParams) ([]BackupRun, error)
+	// ============================================================================
+	// FLAVOR QUERIES
+	// ============================================================================
+	// Operations for the flavor wheel: taxonomy, tagging brews and bean bags,
+	// and flavor aggregation
+	// ----------------------------------------------------------------------------
+	// 1. LIST FLAVOR DESCRIPTORS
+	// ----------------------------------------------------------------------------
+	// Parameters: None
+	// Returns: Every flavor wheel descriptor, parents before children
+	// Usage: Build the flavor wheel tree for pickers
+	ListFlavorDescriptors(ctx context.Context) ([]FlavorDescriptor, error)
+	// ----------------------------------------------------------------------------
+	// 10. LIST INDEX STATS
+	// ----------------------------------------------------------------------------
+	// Parameters: None
+	// Returns: Each index in the schema with its table, size and the scans
+	//
+	//	that have used it since statistics were last reset, largest
+	//	first
+	//
+	// Usage: Admin table report; an index with no scans may be unused
+	// Performance: Reads the catalog and statistics views
+	ListIndexStats(ctx context.Context) ([]ListIndexStatsRow, error)
+	// ----------------------------------------------------------------------------
+	// 3. LIST JOB LEASES
+	// ----------------------------------------------------------------------------
+	// Parameters: None
+	// Returns: Every lease, held or expired, by job name
+	// Usage: Admin job lease report
+	ListJobLeases(ctx context.Context) ([]JobLease, error)
+	// ----------------------------------------------------------------------------
+	// 6. LIST METHOD STATS
+	// ----------------------------------------------------------------------------
+	// Parameters: None
+	// Returns: Top brew methods, most logged first
+	// Usage: Admin stats
+	ListMethodStats(ctx context.Context) ([]OpsMethodStat, error)
+	// ----------------------------------------------------------------------------
+	// 2. LIST OIDC CLIENTS
+	// ----------------------------------------------------------------------------
+	// Parameters: None
+	// Returns: Every client, newest first
+	// Usage: Admin reviews the registered companion services
+	ListOIDCClients(ctx context.Context) ([]OidcClient, error)
+	// ----------------------------------------------------------------------------
+	// 13. LIST ORGANIZATION BREWS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = organization_id, $2 = limit, $3 = offset
+	// Returns: Brews logged in the organization, newest first
+	// Usage: The organization's brew log
+	// Performance: Uses idx_brew_organization
+	ListOrganizationBrews(ctx context.Context, arg ListOrganizationBrewsParams) ([]Brew, error)
+	// ----------------------------------------------------------------------------
+	// 9. LIST ORGANIZATION MEMBERS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = organization_id
+	// Returns: Members with their usernames, owners and admins first
+	// Usage: Organization member list
+	ListOrganizationMembers(ctx context.Context, organizationID string) ([]ListOrganizationMembersRow, error)
+	// ----------------------------------------------------------------------------
+	// 4. LIST PENDING LOGIN ALERTS
+	// ----------------------------------------------------------------------------
+	// Parameters: max_age_hours, row_limit
+	// Returns: New location logins no alert has been sent for, with the
+	//
+	//	user's email and username, oldest first
+	//
+	// Usage: Login alert worker. Alerts older than max_age_hours are no
+	//
+	//	longer useful and are skipped.
+	//
+	// Performance: Uses idx_auth_event_alert_pending
+	ListPendingLoginAlerts(ctx context.Context, arg ListPendingLoginAlertsParams) ([]ListPendingLoginAlertsRow, error)
+	// ----------------------------------------------------------------------------
+	// 2. LIST POLICY DOCUMENTS
+	// ----------------------------------------------------------------------------
+	// Parameters: None
+	// Returns: Every version of every policy, including scheduled ones, with
+	//
+	//	how many users accepted it, latest first
+	//
+	// Usage: Admin reviews published versions
+	// Performance: Uses idx_policy_acceptance_document
+	ListPolicyDocuments(ctx context.Context) ([]ListPolicyDocumentsRow, error)
+	// ----------------------------------------------------------------------------
+	// 15. LIST POUR SAMPLES
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = curve_id
+	// Returns: The curve's staged samples in time order, while it is streamed
+	// Usage: Charting a pour curve live, merged with its compacted samples
+	// Performance: Uses the pour_sample primary key
+	ListPourSamples(ctx context.Context, curveID string) ([]ListPourSamplesRow, error)
+	// ----------------------------------------------------------------------------
+	// 10. LIST PUBLIC CLUB FEED
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = club_id, $2 = limit
+	// Returns: Shares of public brews and recipes, newest first, with sharer
+	//
+	//	usernames and brew or recipe summaries; shares of private
+	//	items stay visible to members only
+	//
+	// Usage: Club Atom feed
+	// Performance: Uses idx_club_share_feed
+	ListPublicClubFeed(ctx context.Context, arg ListPublicClubFeedParams) ([]ListPublicClubFeedRow, error)
+	// ----------------------------------------------------------------------------
+	// 5. LIST PUBLIC CLUBS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = limit, $2 = offset
+	// Returns: Public clubs, newest first, with member counts
+	// Usage: Discover clubs to join
+	ListPublicClubs(ctx context.Context, arg ListPublicClubsParams) ([]ListPublicClubsRow, error)
+	// ----------------------------------------------------------------------------
+	// 5. LIST PUBLIC PROFILES
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = limit, $2 = offset
+	// Returns: Users with at least one public recipe or brew, oldest first
+	// Usage: Public profile index and profile sitemaps; users with nothing
+	//
+	//	public are left out as thin pages
+	ListPublicProfiles(ctx context.Context, arg ListPublicProfilesParams) ([]ListPublicProfilesRow, error)
+	// ----------------------------------------------------------------------------
+	// 3. LIST PUBLIC RECIPES
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = limit, $2 = offset
+	// Returns: Public recipes with their owner's username, oldest first, so
+	//
+	//	pages stay stable as recipes are added
+	//
+	// Usage: Public recipe index and recipe sitemaps
+	// Performance: Uses idx_recipe_is_public
+	ListPublicRecipes(ctx context.Context, arg ListPublicRecipesParams) ([]ListPublicRecipesRow, error)
+	// ----------------------------------------------------------------------------
+	// 8. LIST PUBLIC USER BREWS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id, $2 = limit
+	// Returns: The user's public brews, newest first
+	// Usage: Profile Atom feed
+	// Performance: Uses idx_brew_created_by
+	ListPublicUserBrews(ctx context.Context, arg ListPublicUserBrewsParams) ([]ListPublicUserBrewsRow, error)
+	// ----------------------------------------------------------------------------
+	// 9. LIST QUARANTINED BREW PHOTOS
+	// ----------------------------------------------------------------------------
+	// Parameters: row_limit, row_offset
+	// Returns: Quarantined photos with their uploaders' usernames, most
+	//
+	//	recently quarantined first
+	//
+	// Usage: Admin review of quarantined uploads
+	// Performance: Uses idx_brew_photo_quarantined
+	ListQuarantinedBrewPhotos(ctx context.Context, arg ListQuarantinedBrewPhotosParams) ([]ListQuarantinedBrewPhotosRow, error)
+	// ----------------------------------------------------------------------------
+	// 8. LIST QUEUE LAG
+	// ----------------------------------------------------------------------------
+	// Parameters: None
+	// Returns: For each background queue, when its oldest item fell due, or
+	//
+	//	NULL if nothing is due
+	//
+	// Usage: Readiness check; items long overdue mean the queue's worker isn't
+	//
+	//	running
+	//
+	// Performance: Uses each queue's partial due index
+	ListQueueLag(ctx context.Context) ([]ListQueueLagRow, error)
+	// ----------------------------------------------------------------------------
+	// 7. LIST QUEUE STATS
+	// ----------------------------------------------------------------------------
+	// Parameters: None
+	// Returns: Latest backlog sample of each queue
+	// Usage: Admin stats
+	ListQueueStats(ctx context.Context) ([]OpsQueueStat, error)
+	// ----------------------------------------------------------------------------
+	// 7. LIST REALTIME PRESENCE
+	// ----------------------------------------------------------------------------
+	// Parameters: topic, stale_seconds
+	// Returns: The users connected to topic on any instance, with usernames, in
+	//
+	//	username order
+	//
+	// Usage: A topic's presence, sent when a stream opens and broadcast when it
+	//
+	//	changes
+	//
+	// Performance: Uses idx_realtime_presence_topic
+	ListRealtimePresence(ctx context.Context, arg ListRealtimePresenceParams) ([]ListRealtimePresenceRow, error)
+	// ----------------------------------------------------------------------------
+	// 12. LIST RECENT BEAN BAGS
+	// ----------------------------------------------------------------------------
+	// Parameters: owner_id, row_limit
+	// Returns: The user's open bags, most recently brewed from first; bags not
+	//
+	//	brewed from yet are ordered by when they were added
+	//
+	// Usage: Recent picks on the log-a-brew form
+	// Performance: Uses idx_bean_bag_owner_id and idx_brew_bean_bag
+	ListRecentBeanBags(ctx context.Context, arg ListRecentBeanBagsParams) ([]ListRecentBeanBagsRow, error)
+	// ----------------------------------------------------------------------------
+	// 9. LIST RECENT BREW FLAVORS
+	// ----------------------------------------------------------------------------
+	// Parameters: user_id, row_limit
+	// Returns: The descriptors the user has noted on their brews, most recently
+	//
+	//	noted first
+	//
+	// Usage: Recent picks on the log-a-brew form
+	// Performance: Uses idx_brew_created_by
+	ListRecentBrewFlavors(ctx context.Context, arg ListRecentBrewFlavorsParams) ([]ListRecentBrewFlavorsRow, error)
+	// ----------------------------------------------------------------------------
+	// 18. LIST RECENT BREW METHODS
+	// ----------------------------------------------------------------------------
+	// Parameters: user_id, row_limit
+	// Returns: The methods the user has brewed with, most recently used first,
+	//
+	//	with how many brews used each
+	//
+	// Usage: Recent picks on the log-a-brew form
+	// Performance: Uses idx_brew_created_by
+	ListRecentBrewMethods(ctx context.Context, arg ListRecentBrewMethodsParams) ([]ListRecentBrewMethodsRow, error)
+	// ----------------------------------------------------------------------------
+	// 19. LIST RECENT GRIND SETTINGS
+	// ----------------------------------------------------------------------------
+	// Parameters: user_id, row_limit
+	// Returns: The grind settings the user has brewed with per method, most
+	//
+	//	recently used first
+	//
+	// Usage: Recent picks on the log-a-brew form
+	// Performance: Uses idx_brew_created_by
+	ListRecentGrindSettings(ctx context.Context, arg ListRecentGrindSettingsParams) ([]ListRecentGrindSettingsRow, error)
+	// ----------------------------------------------------------------------------
+	// 13. LIST RECIPE COLLABORATORS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = recipe_id
+	// Returns: Collaborators (pending and accepted) with usernames
+	// Usage: Recipe team management screen
+	ListRecipeCollaborators(ctx context.Context, recipeID string) ([]ListRecipeCollaboratorsRow, error)
+	// ----------------------------------------------------------------------------
+	// 4. LIST RECIPE RECOMMENDATIONS
+	// ----------------------------------------------------------------------------
+	// Parameters: user_id, row_limit
+	// Returns: The user's recommended recipes, best first, with their owner's
+	//
+	//	username and when they were scored. Recipes made private since
+	//	are left out.
+	//
+	// Usage: Recommendations screen
+	// Performance: Uses idx_recommendation_user_score
+	ListRecipeRecommendations(ctx context.Context, arg ListRecipeRecommendationsParams) ([]ListRecipeRecommendationsRow, error)
+	// ----------------------------------------------------------------------------
+	// 4. LIST RECIPE REVISIONS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = recipe_id
+	// Returns: Every revision of the recipe, newest first
+	// Usage: Recipe history / changelog (diffs are computed between neighbours)
+	ListRecipeRevisions(ctx context.Context, recipeID string) ([]RecipeRevision, error)
+	// ----------------------------------------------------------------------------
+	// 6. LIST REFERRALS
+	// ----------------------------------------------------------------------------
+	// Parameters: inviter_id, row_limit
+	// Returns: Users who signed up with the user's invites, newest first
+	// Usage: Referral stats endpoint
+	// Performance: Uses idx_user_invited_by
+	ListReferrals(ctx context.Context, arg ListReferralsParams) ([]ListReferralsRow, error)
+	// ----------------------------------------------------------------------------
+	// 6. LIST REORDER CANDIDATES
+	// ----------------------------------------------------------------------------
+	// Parameters: default_dose_grams, window_start, after_id (keyset cursor,
+	//
+	//	'' to start), limit
+	//
+	// Returns: Open bags without a reorder suggestion yet, with the same usage
+	//
+	//	figures as GetBeanBagUsage, ordered by id
+	//
+	// Usage: Background reorder check
+	ListReorderCandidates(ctx context.Context, arg ListReorderCandidatesParams) ([]ListReorderCandidatesRow, error)
+	// ----------------------------------------------------------------------------
+	// 2. LIST RESOURCE SHARES
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = item_id (a brew or recipe ID)
+	// Returns: The item's shares with the grantee's username or club name,
+	//
+	//	oldest first
+	//
+	// Usage: Owner reviews who an item is shared with
+	// Performance: Uses idx_resource_share_item_grantee
+	ListResourceShares(ctx context.Context, itemID string) ([]ListResourceSharesRow, error)
+	// ----------------------------------------------------------------------------
+	// 5. LIST ROASTERS
+	// ----------------------------------------------------------------------------
+	// Parameters: verified_only, row_limit, row_offset
+	// Returns: Roasters by name, with their official listing counts
+	// Usage: Roaster directory
+	ListRoasters(ctx context.Context, arg ListRoastersParams) ([]ListRoastersRow, error)
+	// ----------------------------------------------------------------------------
+	// 2. LIST SCIM CLIENTS
+	// ----------------------------------------------------------------------------
+	// Parameters: None
+	// Returns: Every client with how many accounts it provisioned, newest first
+	// Usage: Admin reviews the registered identity systems
+	// Performance: Uses idx_scim_user_client
+	ListSCIMClients(ctx context.Context) ([]ListSCIMClientsRow, error)
+	// ----------------------------------------------------------------------------
+	// 8. LIST SCIM USERS
+	// ----------------------------------------------------------------------------
+	// Parameters: client_id, username, external_id, email, limit, offset; the
+	//
+	//	filters are optional and username and email are matched
+	//	case-insensitively
+	//
+	// Returns: One page of the accounts the client provisioned, oldest first
+	// Usage: A client lists accounts, or looks one up before provisioning it
+	// Performance: Uses idx_scim_user_client
+	ListSCIMUsers(ctx context.Context, arg ListSCIMUsersParams) ([]ListSCIMUsersRow, error)
+	// ----------------------------------------------------------------------------
+	// 4. LIST SHARED WITH USER
+	// ----------------------------------------------------------------------------
+	// Parameters: viewer_id, row_limit, row_offset
+	// Returns: Other users' brews and recipes shared with the viewer directly
+	//
+	//	or through a club they belong to, once each, most recently
+	//	shared first
+	//
+	// Usage: "Shared with me" screen
+	// Performance: Uses idx_resource_share_user, idx_resource_share_club and
+	//
+	//	idx_club_member_user
+	ListSharedWithUser(ctx context.Context, arg ListSharedWithUserParams) ([]ListSharedWithUserRow, error)
+	// ----------------------------------------------------------------------------
+	// 9. LIST TABLE STATS
+	// ----------------------------------------------------------------------------
+	// Parameters: None
+	// Returns: Each table in the schema (partitions included) with its
+	//
+	//	estimated and live/dead row counts, heap, index and total sizes,
+	//	and an estimate of its bloat, largest first
+	//
+	// Usage: Admin table report. Row counts and the bloat estimate come from
+	//
+	//	the planner statistics of the last ANALYZE or autovacuum: bloat
+	//	is the heap's pages beyond those its rows would fill at their
+	//	average width.
+	//
+	// Performance: Reads the catalog and statistics views; no table is scanned
+	ListTableStats(ctx context.Context) ([]ListTableStatsRow, error)
+	// ----------------------------------------------------------------------------
+	// 3. LIST TOMBSTONES
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id, $2 = since (sync_seq cursor), $3 = limit
+	// Returns: The user's records deleted after the cursor, oldest first
+	// Usage: Sync pull
+	// Performance: Uses idx_sync_tombstone_user
+	ListTombstones(ctx context.Context, arg ListTombstonesParams) ([]SyncTombstone, error)
+	// ----------------------------------------------------------------------------
+	// 2. LIST TOP STORAGE USERS
+	// ----------------------------------------------------------------------------
+	// Parameters: row_limit, row_offset
+	// Returns: Users storing the most, with their plan and photo count
+	// Usage: Admin storage report
+	// Performance: Uses idx_user_storage_photo_bytes; counts each listed user's
+	//
+	//	photos with idx_brew_photo_user
+	ListTopStorageUsers(ctx context.Context, arg ListTopStorageUsersParams) ([]ListTopStorageUsersRow, error)
+	// ----------------------------------------------------------------------------
+	// 4. LIST TRENDING BEANS
+	// ----------------------------------------------------------------------------
+	// Parameters: period, row_limit
+	// Returns: The period's trending beans, top first, and when the ranking was
+	//
+	//	computed
+	//
+	// Usage: Discover screen
+	// Performance: Uses idx_trending_item_period
+	ListTrendingBeans(ctx context.Context, arg ListTrendingBeansParams) ([]ListTrendingBeansRow, error)
+	// ----------------------------------------------------------------------------
+	// 3. LIST TRENDING RECIPES
+	// ----------------------------------------------------------------------------
+	// Parameters: period, row_limit
+	// Returns: The period's trending recipes, top first, with their owner's
+	//
+	//	username and when the ranking was computed. Recipes made private
+	//	since are left out.
+	//
+	// Usage: Discover screen
+	// Performance: Uses idx_trending_item_period
+	ListTrendingRecipes(ctx context.Context, arg ListTrendingRecipesParams) ([]ListTrendingRecipesRow, error)
+	// ----------------------------------------------------------------------------
+	// 12. LIST TRUSTED DEVICES
+	// ----------------------------------------------------------------------------
+	// Parameters: user_id
+	// Returns: The user's unexpired trusted devices, newest first
+	// Usage: User reviews the devices that skip step-up
+	// Performance: Uses idx_trusted_device_user
+	ListTrustedDevices(ctx context.Context, userID string) ([]ListTrustedDevicesRow, error)
+	// ============================================================================
+	// MEDIA MIGRATION QUERIES
+	// ============================================================================
+	// Enumerating the objects the database refers to, and tracking which have
+	// been copied to another object store, for the media migration tool
+	// ----------------------------------------------------------------------------
+	// 1. LIST UNMIGRATED MEDIA OBJECTS
+	// ----------------------------------------------------------------------------
+	// Parameters: after_key, destination, row_limit
+	// Returns: Objects not yet migrated to destination, after after_key in key
+	//
+	//	order: brew photo originals and renditions, and backup dumps,
+	//	with their content type, version and, where recorded, size and
+	//	SHA-256. An object migrated at an older version is listed again.
+	//
+	// Usage: Media migration tool, paging through the objects left to copy
+	// Performance: Pages through each source on its key's index, then merges
+	//
+	//	the pages; checks each key against the primary key of
+	//	media_migration_object
+	ListUnmigratedMediaObjects(ctx context.Context, arg ListUnmigratedMediaObjectsParams) ([]ListUnmigratedMediaObjectsRow, error)
+	// ----------------------------------------------------------------------------
+	// 4. LIST UNSENT WAITLIST INVITES
+	// ----------------------------------------------------------------------------
+	// Parameters: max_attempts, row_limit
+	// Returns: Approved entries whose invitation email hasn't been sent, and
+	//
+	//	hasn't failed max_attempts times
+	//
+	// Usage: Waitlist worker
+	// Performance: Uses idx_waitlist_entry_unsent
+	ListUnsentWaitlistInvites(ctx context.Context, arg ListUnsentWaitlistInvitesParams) ([]ListUnsentWaitlistInvitesRow, error)
+	// ----------------------------------------------------------------------------
+	// 3. LIST UPCOMING BREW EVENTS
+	// ----------------------------------------------------------------------------
+	// Parameters: since (events starting at or after), row_limit, row_offset
+	// Returns: Events soonest first, with host usernames, recipe names and how
+	//
+	//	many users are going
+	//
+	// Usage: Browse upcoming brew-alongs
+	ListUpcomingBrewEvents(ctx context.Context, arg ListUpcomingBrewEventsParams) ([]ListUpcomingBrewEventsRow, error)
+	// ----------------------------------------------------------------------------
+	// 2. LIST USER API KEYS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id
+	// Returns: The user's keys, including revoked ones, newest first
+	// Usage: API key settings screen
+	// Performance: Uses idx_api_key_user
+	ListUserAPIKeys(ctx context.Context, userID string) ([]ApiKey, error)
+	// ----------------------------------------------------------------------------
+	// 2. LIST USER AUTH EVENTS
+	// ----------------------------------------------------------------------------
+	// Parameters: user_id, row_limit, row_offset
+	// Returns: The user's auth events, newest first
+	// Usage: User reviews their recent sign-ins
+	// Performance: Uses idx_auth_event_user
+	ListUserAuthEvents(ctx context.Context, arg ListUserAuthEventsParams) ([]AuthEvent, error)
+	// ----------------------------------------------------------------------------
+	// 2. LIST USER AUTOMATION WEBHOOKS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id
+	// Returns: The user's webhooks, newest first
+	// Usage: Integration settings
+	// Performance: Uses idx_automation_webhook_user
+	ListUserAutomationWebhooks(ctx context.Context, userID string) ([]AutomationWebhook, error)
+	// ----------------------------------------------------------------------------
+	// 12. LIST USER BADGES
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id
+	// Returns: Badges earned, newest first, including from retired challenges
+	// Usage: Profile badges and badge progress
+	ListUserBadges(ctx context.Context, userID string) ([]ListUserBadgesRow, error)
+	// ----------------------------------------------------------------------------
+	// 3. LIST USER BEAN BAGS
+	// ----------------------------------------------------------------------------
+	// Parameters: owner_id, origin_country, process, variety (NULL matches any)
+	// Returns: The user's bags, open bags first, newest first
+	// Usage: Pantry screen and its origin filters
+	// Performance: Uses idx_bean_bag_owner_id
+	ListUserBeanBags(ctx context.Context, arg ListUserBeanBagsParams) ([]BeanBag, error)
+	// ----------------------------------------------------------------------------
+	// 13. LIST USER BREW CUSTOM FIELDS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id
+	// Returns: The user's custom fields by name
+	// Usage: Field settings, and validating custom fields on brews
+	ListUserBrewCustomFields(ctx context.Context, userID string) ([]BrewCustomField, error)
+	// ----------------------------------------------------------------------------
+	// 2. LIST USER BREW HISTORY
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id, $2 = limit, $3 = offset
+	// Returns: The user's personal brews, live and archived, newest first,
+	//
+	//	each with whether it's archived. Brews logged in organizations
+	//	are listed by the organization instead.
+	//
+	// Usage: Brew history screen
+	// Performance: Each side reads at most limit + offset rows by
+	//
+	//	idx_brew_created_by and idx_brew_archive_created_by, so only
+	//	that many archived documents are decompressed
+	ListUserBrewHistory(ctx context.Context, arg ListUserBrewHistoryParams) ([]ListUserBrewHistoryRow, error)
+	// ----------------------------------------------------------------------------
+	// 3. LIST USER BREWS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id, $2 = limit, $3 = offset
+	// Returns: The user's personal brews, newest first
+	// Usage: Brew history screen
+	// Performance: Uses idx_brew_created_by
+	ListUserBrews(ctx context.Context, arg ListUserBrewsParams) ([]Brew, error)
+	// ----------------------------------------------------------------------------
+	// 6. LIST USER CLUBS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id
+	// Returns: Clubs the user belongs to, with their role and member counts
+	// Usage: "My clubs" screen
+	ListUserClubs(ctx context.Context, userID string) ([]ListUserClubsRow, error)
+	// ----------------------------------------------------------------------------
+	// 12. LIST USER CUPPING SCORES
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = session_id, $2 = user_id
+	// Returns: The user's own scores in the session, by label
+	// Usage: Scorer reviews their scoresheet
+	ListUserCuppingScores(ctx context.Context, arg ListUserCuppingScoresParams) ([]ListUserCuppingScoresRow, error)
+	// ----------------------------------------------------------------------------
+	// 3. LIST USER CUPPING SESSIONS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id
+	// Returns: Sessions the user hosts or was invited to, newest first, with
+	//
+	//	host usernames and sample counts
+	//
+	// Usage: "My cuppings" screen
+	ListUserCuppingSessions(ctx context.Context, userID string) ([]ListUserCuppingSessionsRow, error)
+	// ----------------------------------------------------------------------------
+	// 3. LIST USER DRAFTS
+	// ----------------------------------------------------------------------------
+	// Parameters: user_id, kind (NULL for both)
+	// Returns: The user's drafts, most recently saved first
+	// Usage: Drafts screen, and brew history with drafts included
+	// Performance: Uses idx_draft_user_kind
+	ListUserDrafts(ctx context.Context, arg ListUserDraftsParams) ([]Draft, error)
+	// ----------------------------------------------------------------------------
+	// 3. LIST USER INVITES
+	// ----------------------------------------------------------------------------
+	// Parameters: inviter_id
+	// Returns: The user's invites, newest first
+	// Usage: User's invites page
+	// Performance: Uses idx_invite_inviter
+	ListUserInvites(ctx context.Context, inviterID string) ([]Invite, error)
+	// ----------------------------------------------------------------------------
+	// 10. LIST USER METHOD BREW COUNTS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id
+	// Returns: Brews logged per brew method
+	// Usage: Progress on method-specific challenges
+	ListUserMethodBrewCounts(ctx context.Context, createdBy *string) ([]ListUserMethodBrewCountsRow, error)
+	// ----------------------------------------------------------------------------
+	// 5. LIST USER ORGANIZATIONS
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id
+	// Returns: Organizations the user belongs to, with their role and member
+	//
+	//	counts
+	//
+	// Usage: Organization switcher
+	// Performance: Uses idx_organization_member_user
+	ListUserOrganizations(ctx context.Context, userID string) ([]ListUserOrganizationsRow, error)
+	// ----------------------------------------------------------------------------
+	// 4. LIST USER POLICY DOCUMENTS
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = notification_id
-	// Returns: Notification with full context (actor, reference object)
-	// Usage: Display rich notification with post/comment preview
-	// Note: This is a base query - you may need to join additional tables
-	//       based on reference_type (post, comment, etc.)
-	GetNotificationWithDetails(ctx context.Context, id string) (GetNotificationWithDetailsRow, error)
+	// Parameters: $1 = user_id
+	// Returns: The current version of each policy, with when the user accepted
+	//
+	//	it (NULL if they haven't)
+	//
+	// Usage: Finding the policies a user must accept before using the API
+	// Performance: Uses idx_policy_document_kind and the acceptance primary key
+	ListUserPolicyDocuments(ctx context.Context, userID string) ([]ListUserPolicyDocumentsRow, error)
 	// ----------------------------------------------------------------------------
-	// 9. GET NOTIFICATIONS BY TYPE
+	// 15. LIST USER RECIPE INVITATIONS
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = recipient_user_id, $2 = type, $3 = limit, $4 = offset
-	// Returns: Notifications filtered by type
-	// Usage: Filter notifications (e.g., show only 'like' notifications)
-	// Performance: Uses idx_notification_type
-	GetNotificationsByType(ctx context.Context, arg GetNotificationsByTypeParams) ([]GetNotificationsByTypeRow, error)
+	// Parameters: $1 = user_id
+	// Returns: Pending invitations with recipe name and inviter username
+	// Usage: Invitations inbox
+	// Performance: Uses idx_recipe_collaborator_user
+	ListUserRecipeInvitations(ctx context.Context, userID string) ([]ListUserRecipeInvitationsRow, error)
 	// ----------------------------------------------------------------------------
-	// 5. GET PENDING FRIEND REQUESTS (Incoming)
+	// 5. LIST USER TDS READINGS
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = user_id (recipient)
-	// Returns: Users who have sent friend requests
-	// Usage: Show incoming friend requests for user to accept/reject
-	// Performance: Uses idx_user_friendships_friend_id
-	GetPendingRequestsReceived(ctx context.Context, friendID string) ([]GetPendingRequestsReceivedRow, error)
+	// Parameters: user_id, unattached_only, limit, offset
+	// Returns: The user's readings, newest first; with unattached_only, just
+	//
+	//	those not attached to a brew
+	//
+	// Usage: Reading history and attaching stray readings
+	// Performance: Uses idx_tds_reading_user
+	ListUserTDSReadings(ctx context.Context, arg ListUserTDSReadingsParams) ([]TdsReading, error)
 	// ----------------------------------------------------------------------------
-	// 6. GET SENT FRIEND REQUESTS (Outgoing)
+	// 2. LIST WAITLIST
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = user_id (requester)
-	// Returns: Users to whom current user has sent pending requests
-	// Usage: Show outgoing friend requests (for cancellation)
-	// Performance: Uses idx_user_friendships_user_id
-	GetPendingRequestsSent(ctx context.Context, userID string) ([]GetPendingRequestsSentRow, error)
+	// Parameters: approved, row_limit, row_offset
+	// Returns: Pending entries oldest first, or approved entries newest first
+	// Usage: Admin waitlist page
+	// Performance: Uses idx_waitlist_entry_pending or idx_waitlist_entry_approved
+	ListWaitlist(ctx context.Context, arg ListWaitlistParams) ([]WaitlistEntry, error)
 	// ----------------------------------------------------------------------------
-	// PLATFORM ANALYTICS
+	// 6. MARK ALL NOTIFICATIONS AS READ
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = recipient_user_id
+	// Returns: Number of updated notifications
+	// Usage: "Mark all as read" button
+	MarkAllNotificationsAsRead(ctx context.Context, recipientUserID string) ([]string, error)
+	// ----------------------------------------------------------------------------
+	// 7. MARK BEAN BAG REORDER NOTIFIED
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = bean_bag_id
+	// Returns: Number of rows updated; 0 if another worker got there first
+	// Usage: Claim a bag's reorder suggestion; the claim queues the owner's
+	//
+	//	notification in the outbox
+	MarkBeanBagReorderNotified(ctx context.Context, id string) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 2. MARK DOMAIN EVENTS PUBLISHED
+	// ----------------------------------------------------------------------------
+	// Parameters: ids
+	// Returns: None
+	// Usage: Event relay, once the broker has accepted a batch
+	MarkDomainEventsPublished(ctx context.Context, ids []int64) error
+	// ----------------------------------------------------------------------------
+	// 5. MARK LOGIN ALERT SENT
+	// ----------------------------------------------------------------------------
+	// Parameters: id
+	// Returns: None
+	// Usage: Login alert worker, after the email was sent or suppressed
+	MarkLoginAlertSent(ctx context.Context, id int64) error
+	// ----------------------------------------------------------------------------
+	// 5. MARK MULTIPLE NOTIFICATIONS AS READ
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = recipient_user_id, $2 = array of notification_ids
+	// Returns: Number of updated notifications
+	// Usage: "Mark all as read" or batch mark
+	// Note: ANY($2) allows passing array of IDs
+	MarkMultipleNotificationsAsRead(ctx context.Context, arg MarkMultipleNotificationsAsReadParams) ([]string, error)
+	// ----------------------------------------------------------------------------
+	// 4. MARK NOTIFICATION AS READ
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = notification_id, $2 = recipient_user_id
+	// Returns: Updated notification
+	// Usage: User clicks on a notification
+	// Note: Includes recipient_user_id check for security
+	MarkNotificationAsRead(ctx context.Context, arg MarkNotificationAsReadParams) (MarkNotificationAsReadRow, error)
+	// ----------------------------------------------------------------------------
+	// 5. MARK WAITLIST INVITE SENT
+	// ----------------------------------------------------------------------------
+	// Parameters: email
+	// Returns: None
+	// Usage: Waitlist worker, after sending the invitation email
+	MarkWaitlistInviteSent(ctx context.Context, email string) error
+	// ============================================================================
+	// REALTIME QUERIES
+	// ============================================================================
+	// Relaying realtime messages between instances, and tracking who is
+	// connected to each topic across them
+	// ----------------------------------------------------------------------------
+	// 1. NOTIFY REALTIME
+	// ----------------------------------------------------------------------------
+	// Parameters: payload
+	// Returns: Nothing
+	// Usage: Relaying a realtime message to the other instances, which LISTEN
+	//
+	//	on brewd_realtime. Payloads must be shorter than 8000 bytes.
+	NotifyRealtime(ctx context.Context, payload string) error
+	// ----------------------------------------------------------------------------
+	// 9. PRUNE BACKUP RUN
+	// ----------------------------------------------------------------------------
+	// Parameters: id
+	// Returns: Nothing
+	// Usage: Backup job, once the run's dump is deleted from object storage
+	// Performance: Uses the primary key
+	PruneBackupRun(ctx context.Context, id string) error
+	// ----------------------------------------------------------------------------
+	// 4. PURGE ANALYTICS EVENTS
+	// ----------------------------------------------------------------------------
+	// Parameters: retention_days, batch_size
+	// Returns: Number of events deleted
+	// Usage: Retention job; deletes up to batch_size analytics events received
+	//
+	//	more than retention_days ago
+	//
+	// Performance: Uses idx_analytics_event_received_at
+	PurgeAnalyticsEvents(ctx context.Context, arg PurgeAnalyticsEventsParams) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 2. PURGE AUTH EVENTS
+	// ----------------------------------------------------------------------------
+	// Parameters: retention_days, batch_size
+	// Returns: Number of events deleted
+	// Usage: Retention job; deletes up to batch_size auth events older than
+	//
+	//	retention_days
+	//
+	// Performance: Uses idx_auth_event_created_at
+	PurgeAuthEvents(ctx context.Context, arg PurgeAuthEventsParams) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 6. PURGE NOTIFICATIONS
+	// ----------------------------------------------------------------------------
+	// Parameters: retention_days, batch_size
+	// Returns: Number of notifications deleted
+	// Usage: Retention job; deletes up to batch_size notifications older than
+	//
+	//	retention_days, read or not
+	//
+	// Performance: Uses idx_notification_created_at
+	PurgeNotifications(ctx context.Context, arg PurgeNotificationsParams) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 8. PURGE REVOKED API KEYS
+	// ----------------------------------------------------------------------------
+	// Parameters: retention_days, batch_size
+	// Returns: Number of API keys deleted
+	// Usage: Retention job; deletes up to batch_size API keys revoked more than
+	//
+	//	retention_days ago. Devices paired with one keep their readings
+	//	and lose the link.
+	//
+	// Performance: Sequential scan; API keys are few
+	PurgeRevokedAPIKeys(ctx context.Context, arg PurgeRevokedAPIKeysParams) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 10. PURGE REVOKED CLUB INVITES
+	// ----------------------------------------------------------------------------
+	// Parameters: retention_days, batch_size
+	// Returns: Number of club invites deleted
+	// Usage: Retention job; deletes up to batch_size club invites revoked more
+	//
+	//	than retention_days ago
+	//
+	// Performance: Sequential scan; club invites are few
+	PurgeRevokedClubInvites(ctx context.Context, arg PurgeRevokedClubInvitesParams) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 12. PURGE REVOKED INVITES
+	// ----------------------------------------------------------------------------
+	// Parameters: retention_days, batch_size
+	// Returns: Number of invites deleted
+	// Usage: Retention job; deletes up to batch_size invites revoked more than
+	//
+	//	retention_days ago. Users who signed up with one keep its code
+	//	and inviter; its redeemed uses stop counting against the quota.
+	//
+	// Performance: Sequential scan; invites are few
+	PurgeRevokedInvites(ctx context.Context, arg PurgeRevokedInvitesParams) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 6. PURGE STALE REALTIME PRESENCE
+	// ----------------------------------------------------------------------------
+	// Parameters: stale_seconds
+	// Returns: The topic of each row deleted
+	// Usage: Presence heartbeat, dropping the rows of instances that stopped
+	//
+	//	refreshing them, so their topics' presence can be broadcast
+	//
+	// Performance: Uses idx_realtime_presence_seen_at
+	PurgeStaleRealtimePresence(ctx context.Context, staleSeconds int32) ([]string, error)
+	// ----------------------------------------------------------------------------
+	// 8. QUARANTINE BREW PHOTO
+	// ----------------------------------------------------------------------------
+	// Parameters: original_key, threat, id
+	// Returns: Number of rows affected; 0 if the photo was deleted meanwhile
+	// Usage: Photo processor; quarantines a photo the upload scanner found
+	//
+	//	malware in, once its original has been moved to original_key
+	QuarantineBrewPhoto(ctx context.Context, arg QuarantineBrewPhotoParams) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 7. QUEUE ALL BADGE EVALUATIONS
 	// ----------------------------------------------------------------------------
-	// 5. GET PLATFORM OVERVIEW STATS
 	// Parameters: None
-	// Returns: Platform-wide statistics
-	// Usage: Admin dashboard, homepage stats
-	GetPlatformOverviewStats(ctx context.Context) (GetPlatformOverviewStatsRow, error)
+	// Returns: Number of users queued
+	// Usage: After a challenge is created or changed, so it is awarded for
+	//
+	//	brews already logged
+	QueueAllBadgeEvaluations(ctx context.Context) (int64, error)
 	// ----------------------------------------------------------------------------
-	// POPULAR/TRENDING CONTENT
+	// 6. QUEUE BADGE EVALUATION
 	// ----------------------------------------------------------------------------
-	// 7. GET POPULAR BREWS
-	// Parameters: $1 = days (e.g., 30 for last 30 days), $2 = limit
-	// Returns: Most-used brews in time period
-	// Usage: "Trending brews" section
-	// Performance: Uses idx_post_brew_id and idx_post_created_at
-	GetPopularBrews(ctx context.Context, arg GetPopularBrewsParams) ([]GetPopularBrewsRow, error)
-	// 8. GET POPULAR POSTS
-	// Parameters: $1 = days (time window), $2 = limit
-	// Returns: Posts with most engagement (likes + comments)
-	// Usage: "Trending posts" feed
-	// Performance: Aggregates engagement metrics
-	GetPopularPosts(ctx context.Context, arg GetPopularPostsParams) ([]GetPopularPostsRow, error)
-	// 4. GET POST LIKE COUNT
-	// Parameters: $1 = post_id
-	// Returns: Number of likes on a post
-	// Usage: Display like count
-	GetPostLikeCount(ctx context.Context, postID string) (int64, error)
-	// 3. GET USERS WHO LIKED A POST
-	// Parameters: $1 = post_id, $2 = limit, $3 = offset
-	// Returns: List of users who liked this post
-	// Usage: Display "Liked by X, Y, and 23 others"
-	// Performance: Uses idx_post_likes_post_id
-	GetPostLikers(ctx context.Context, arg GetPostLikersParams) ([]GetPostLikersRow, error)
+	// Parameters: $1 = user_id
+	// Returns: None
+	// Usage: After a user logs a brew; a user already queued keeps their place
+	QueueBadgeEvaluation(ctx context.Context, userID string) error
+	// ============================================================================
+	// AUTH EVENT QUERIES
+	// ============================================================================
+	// Operations for the auth event audit log: recording registrations and
+	// sign-in attempts with their coarse location, listing them for users and
+	// admins, and tracking new location login alerts.
 	// ----------------------------------------------------------------------------
-	// 2. GET SINGLE POST WITH FULL DETAILS
+	// 1. RECORD AUTH EVENT
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = post_id
-	// Returns: Post with owner info, brew details, media, like count, comment count
-	// Usage: Display detailed post view
-	// Performance: Multiple LEFT JOINs, uses multiple indexes
-	GetPostWithDetails(ctx context.Context, id string) (GetPostWithDetailsRow, error)
+	// Parameters: user_id, event, ip, user_agent, country, region, city
+	// Returns: None
+	// Usage: Registration and login. A login is marked new_location when it
+	//
+	//	has a known country, the user has signed in from somewhere known
+	//	before, and never from this country and region.
+	//
+	// Performance: Uses idx_auth_event_user
+	RecordAuthEvent(ctx context.Context, arg RecordAuthEventParams) error
 	// ----------------------------------------------------------------------------
-	// 8. GET POSTS BY BREW
+	// 3. RECORD MEDIA MIGRATION OBJECT
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = brew_id, $2 = limit, $3 = offset
-	// Returns: All posts using a specific brew
-	// Usage: View all posts for a particular coffee brew
-	// Performance: Uses idx_post_brew_id
-	GetPostsByBrew(ctx context.Context, arg GetPostsByBrewParams) ([]GetPostsByBrewRow, error)
-	// 20. GET POSTS WHERE USER IS TAGGED
-	// Parameters: $1 = user_id, $2 = limit, $3 = offset
-	// Returns: Posts where user has been tagged
-	// Usage: "Posts you're tagged in" section on profile
-	GetPostsWhereUserIsTagged(ctx context.Context, arg GetPostsWhereUserIsTaggedParams) ([]GetPostsWhereUserIsTaggedRow, error)
+	// Parameters: destination, object_key, version, size_bytes, sha256
+	// Returns: Nothing
+	// Usage: Media migration tool, once an object's copy is verified
+	// Performance: Uses the primary key
+	RecordMediaMigrationObject(ctx context.Context, arg RecordMediaMigrationObjectParams) error
 	// ----------------------------------------------------------------------------
-	// 5. GET PUBLIC POSTS (Discovery Feed)
+	// 6. RECORD WAITLIST INVITE FAILURE
+	// ----------------------------------------------------------------------------
+	// Parameters: email, error
+	// Returns: None
+	// Usage: Waitlist worker, when the invitation email can't be sent; it is
+	//
+	//	retried on the next run until the attempts run out
+	RecordWaitlistInviteFailure(ctx context.Context, arg RecordWaitlistInviteFailureParams) error
+	// ----------------------------------------------------------------------------
+	// 17. REDEEM CLUB INVITE
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = token_hash, $2 = user_id
+	// Returns: The joined club's ID, or no rows if the invite is no longer
+	//
+	//	usable or the user is already a member (which uses nothing)
+	//
+	// Usage: Join a club through an invite link, counting the use atomically
+	RedeemClubInvite(ctx context.Context, arg RedeemClubInviteParams) (string, error)
+	// ----------------------------------------------------------------------------
+	// 6. REDEEM OIDC AUTH CODE
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = code_hash
+	// Returns: The code, deleted so it can't be redeemed again
+	// Usage: Token endpoint; the caller checks the client, redirect URI, expiry
+	//
+	//	and PKCE verifier, so a code presented wrongly is spent too
+	RedeemOIDCAuthCode(ctx context.Context, codeHash string) (OidcAuthCode, error)
+	// ----------------------------------------------------------------------------
+	// 3. REFRESH BEAN RECOMMENDATIONS
+	// ----------------------------------------------------------------------------
+	// Parameters: user_id, row_limit
+	// Returns: Nothing; replaces the user's bean recommendations
+	// Usage: Recommendation worker. Ratings give affinities as for recipes, per
+	//
+	//	origin country, process and variety of the bags rated brews used
+	//	and per flavor category. A catalogue bean the user has no bag of
+	//	(by name and roaster) and hasn't dismissed scores the affinities of
+	//	its origin fields plus the category affinities weighted by their
+	//	share of the descriptors on public brews of the same roaster and
+	//	origin. Only positive scores are kept.
+	RefreshBeanRecommendations(ctx context.Context, arg RefreshBeanRecommendationsParams) error
+	// ----------------------------------------------------------------------------
+	// 2. REFRESH DAILY STATS
+	// ----------------------------------------------------------------------------
+	// Parameters: since (first day to recompute, through today)
+	// Returns: None
+	// Usage: Ops stats job. A user is active on a day they logged a brew, posted,
+	//
+	//	liked or commented. Request counts are left alone.
+	//
+	// Performance: Uses idx_user_joined_at, idx_brew_created_at,
+	//
+	//	idx_post_created_at and idx_comment_created_at
+	RefreshDailyStats(ctx context.Context, since pgtype.Date) error
+	// ----------------------------------------------------------------------------
+	// 3. REFRESH METHOD STATS
+	// ----------------------------------------------------------------------------
+	// Parameters: since, row_limit
+	// Returns: None
+	// Usage: Ops stats job; replaces the top brew methods by brews logged since
+	//
+	//	the given time
+	//
+	// Performance: Uses idx_brew_created_at
+	RefreshMethodStats(ctx context.Context, arg RefreshMethodStatsParams) error
+	// ----------------------------------------------------------------------------
+	// 4. REFRESH QUEUE STATS
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = limit, $2 = offset
-	// Returns: Recent public posts from all users
-	// Usage: Public discovery feed for non-friends
-	// Performance: Uses idx_post_visibility
-	GetPublicPosts(ctx context.Context, arg GetPublicPostsParams) ([]GetPublicPostsRow, error)
-	// 9. GET RATING DISTRIBUTION
 	// Parameters: None
-	// Returns: Count of posts by rating
-	// Usage: Rating distribution chart
-	GetRatingDistribution(ctx context.Context) ([]GetRatingDistributionRow, error)
-	// 13. GET RECENTLY JOINED USERS
-	// Parameters: $1 = days, $2 = limit
-	// Returns: Users who joined recently
-	// Usage: "New to the community" section
-	GetRecentlyJoinedUsers(ctx context.Context, arg GetRecentlyJoinedUsersParams) ([]GetRecentlyJoinedUsersRow, error)
-	// 12. GET REPLIES TO COMMENT
-	// Parameters: $1 = parent_comment_id
-	// Returns: Replies to a specific comment with user info and like count
-	// Usage: Load threaded replies (nested comments)
-	// Performance: Uses idx_comment_parent_comment_id
-	GetRepliesToComment(ctx context.Context, parentCommentID *string) ([]GetRepliesToCommentRow, error)
+	// Returns: None
+	// Usage: Ops stats job samples the backlog of each background queue:
+	//
+	//	automation webhook deliveries, outbox messages, unpublished domain
+	//	events and users awaiting badge evaluation
+	//
+	// Performance: Pending counts use each queue's partial due index; failures
+	//
+	//	scan the queue
+	RefreshQueueStats(ctx context.Context) error
 	// ----------------------------------------------------------------------------
-	// RECOMMENDATIONS
+	// 2. REFRESH RECIPE RECOMMENDATIONS
 	// ----------------------------------------------------------------------------
-	// 11. GET SIMILAR BREWS
-	// Parameters: $1 = brew_id
-	// Returns: Brews with similar characteristics (same method or origin)
-	// Usage: "You might also like" recommendations
-	GetSimilarBrews(ctx context.Context, id string) ([]GetSimilarBrewsRow, error)
-	// 12. GET SUGGESTED FRIENDS
-	// Parameters: $1 = current_user_id, $2 = limit
-	// Returns: Users with mutual friends (friend-of-friend suggestions)
-	// Usage: "People you may know" recommendations
-	GetSuggestedFriends(ctx context.Context, arg GetSuggestedFriendsParams) ([]GetSuggestedFriendsRow, error)
-	// 10. GET TOP CONTRIBUTORS
-	// Parameters: $1 = days (time window), $2 = limit
-	// Returns: Most active users by various metrics
-	// Usage: "Top contributors" leaderboard
-	GetTopContributors(ctx context.Context, arg GetTopContributorsParams) ([]GetTopContributorsRow, error)
-	// 16. GET TOP-LEVEL COMMENT COUNT FOR POST
-	// Parameters: $1 = post_id
-	// Returns: Number of top-level comments only (excludes replies)
-	// Usage: Display "X comments" excluding nested replies
-	GetTopLevelCommentCount(ctx context.Context, postID string) (int64, error)
-	// 9. GET TOP RATED BREWS
-	// Parameters: $1 = minimum_post_count, $2 = limit
-	// Returns: Highest-rated brews with enough posts for statistical validity
-	// Usage: "Best brews" discovery
-	GetTopRatedBrews(ctx context.Context, arg GetTopRatedBrewsParams) ([]GetTopRatedBrewsRow, error)
+	// Parameters: user_id, row_limit
+	// Returns: Nothing; replaces the user's recipe recommendations
+	// Usage: Recommendation worker. The user's ratings of their own brews,
+	//
+	//	centred on 2.5, give an affinity per brew method and per flavor
+	//	wheel category noted on those brews. A public recipe the user
+	//	neither owns, has brewed nor dismissed scores its method's
+	//	affinity plus the category affinities weighted by their share of
+	//	the descriptors on the recipe's public brews. Only positive scores
+	//	are kept.
+	RefreshRecipeRecommendations(ctx context.Context, arg RefreshRecipeRecommendationsParams) error
+	// ----------------------------------------------------------------------------
+	// 2. REFRESH TRENDING BEANS
+	// ----------------------------------------------------------------------------
+	// Parameters: period, since (start of the period), row_limit
+	// Returns: Nothing; replaces the period's trending beans
+	// Usage: Discover worker. Beans are the roaster and origin public brews
+	//
+	//	record, compared case-insensitively and shown in their most common
+	//	spelling; brews missing either are left out. Scored as for
+	//	RefreshTrendingRecipes.
+	//
+	// Performance: Uses idx_brew_created_at and idx_post_created_at
+	RefreshTrendingBeans(ctx context.Context, arg RefreshTrendingBeansParams) error
+	// ============================================================================
+	// TRENDING QUERIES
+	// ============================================================================
+	// Operations for ranking trending public recipes and beans, and reading the
+	// precomputed rankings for discovery
+	// ----------------------------------------------------------------------------
+	// 1. REFRESH TRENDING RECIPES
+	// ----------------------------------------------------------------------------
+	// Parameters: period, since (start of the period), row_limit
+	// Returns: Nothing; replaces the period's trending recipes
+	// Usage: Discover worker. A recipe scores one point per public brew logged
+	//
+	//	with it since the period started, plus its public post ratings
+	//	given since then over 2.5 (a 5-star rating counts as two brews).
+	//	Only public recipes are ranked.
+	//
+	// Performance: Uses idx_brew_created_at and idx_post_created_at
+	RefreshTrendingRecipes(ctx context.Context, arg RefreshTrendingRecipesParams) error
+	// ----------------------------------------------------------------------------
+	// 3. REJECT/CANCEL FRIEND REQUEST
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id, $2 = friend_id
+	// Returns: Deleted friendship id
+	// Usage: Reject incoming request or cancel outgoing request
+	RejectFriendRequest(ctx context.Context, arg RejectFriendRequestParams) (RejectFriendRequestRow, error)
+	// ----------------------------------------------------------------------------
+	// 2. RELEASE JOB LEASE
+	// ----------------------------------------------------------------------------
+	// Parameters: name, holder
+	// Returns: Nothing
+	// Usage: A job stopping, so another instance can take it at once. The
+	//
+	//	lease expires rather than being deleted, so its epoch keeps
+	//	counting up.
+	//
+	// Performance: Uses the primary key
+	ReleaseJobLease(ctx context.Context, arg ReleaseJobLeaseParams) error
+	// ----------------------------------------------------------------------------
+	// 4. RELEASE STORAGE
+	// ----------------------------------------------------------------------------
+	// Parameters: id
+	// Returns: Nothing
+	// Usage: Upload, once its row counts the bytes or it failed
+	// Performance: Uses the primary key
+	ReleaseStorage(ctx context.Context, id string) error
+	// ----------------------------------------------------------------------------
+	// 6. REMOVE BEAN RECIPE
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = bean_id, $2 = recipe_id
+	// Returns: Number of rows deleted
+	// Usage: Roaster unpublishes an official recipe
+	RemoveBeanRecipe(ctx context.Context, arg RemoveBeanRecipeParams) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 11. REMOVE CLUB MEMBER
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = club_id, $2 = user_id
+	// Returns: Number of members removed; the owner is never removed
+	// Usage: Member leaves, or a moderator removes them
+	RemoveClubMember(ctx context.Context, arg RemoveClubMemberParams) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 8. REMOVE CUPPING PARTICIPANT
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = session_id, $2 = user_id
+	// Returns: Number of participants removed
+	// Usage: Host removes a participant, or a participant leaves. Their scores
+	//
+	//	are dropped unless the session was already revealed.
+	RemoveCuppingParticipant(ctx context.Context, arg RemoveCuppingParticipantParams) (int64, error)
 	// ----------------------------------------------------------------------------
-	// 8. GET UNREAD NOTIFICATION COUNT
+	// 12. REMOVE ORGANIZATION MEMBER
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = recipient_user_id
-	// Returns: Number of unread notifications
-	// Usage: Display badge count on notification bell
-	GetUnreadNotificationCount(ctx context.Context, recipientUserID string) (int64, error)
+	// Parameters: $1 = organization_id, $2 = user_id
+	// Returns: Number of memberships removed (0 or 1)
+	// Usage: A member leaves, or is removed by someone who outranks them. Brews
+	//
+	//	they logged stay with the organization.
+	RemoveOrganizationMember(ctx context.Context, arg RemoveOrganizationMemberParams) (int64, error)
 	// ----------------------------------------------------------------------------
-	// 2. GET UNREAD NOTIFICATIONS
+	// 12. REMOVE RECIPE COLLABORATOR
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = recipient_user_id, $2 = limit
-	// Returns: Unread notifications with actor info
-	// Usage: Show notification dropdown/badge
-	// Performance: Uses idx_notification_is_read
-	GetUnreadNotifications(ctx context.Context, arg GetUnreadNotificationsParams) ([]GetUnreadNotificationsRow, error)
+	// Parameters: $1 = recipe_id, $2 = user_id
+	// Returns: Number of rows removed
+	// Usage: Owner removes a collaborator, or a collaborator leaves / declines
+	RemoveRecipeCollaborator(ctx context.Context, arg RemoveRecipeCollaboratorParams) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 3. RENEW PARENTAL CONSENT
+	// ----------------------------------------------------------------------------
+	// Parameters: user_id, token_hash, ttl_seconds
+	// Returns: The parent's email; no rows if the user's request is still
+	//
+	//	pending or was granted
+	//
+	// Usage: A user awaiting consent logs in after their request expired, so
+	//
+	//	their parent is asked again with a new token
+	RenewParentalConsent(ctx context.Context, arg RenewParentalConsentParams) (string, error)
 	// ============================================================================
-	// ANALYTICS QUERIES
+	// EMAIL DOMAIN QUERIES
 	// ============================================================================
-	// Operations for statistics, metrics, and insights
+	// Operations for the disposable email domain blocklist and the admin
+	// overrides that take precedence over it. Domains are stored lowercased;
+	// lookups pass a domain and each of its parent domains, so subdomains of a
+	// listed domain match too.
 	// ----------------------------------------------------------------------------
-	// USER ANALYTICS
+	// 1. REPLACE DISPOSABLE DOMAINS
 	// ----------------------------------------------------------------------------
-	// 1. GET USER ACTIVITY STATS
-	// Parameters: $1 = user_id
-	// Returns: Comprehensive user statistics
-	// Usage: User dashboard, admin analytics, insights page
-	// Note: Uses subqueries to prevent Cartesian product and ensure accurate counts
-	GetUserActivityStats(ctx context.Context, id string) (GetUserActivityStatsRow, error)
+	// Parameters: domains, prune
+	// Returns: None
+	// Usage: Blocklist refresh job swaps in the latest list in one statement.
+	//
+	//	Without prune, domains are only added, e.g. when the remote list
+	//	couldn't be fetched.
+	ReplaceDisposableDomains(ctx context.Context, arg ReplaceDisposableDomainsParams) error
 	// ----------------------------------------------------------------------------
-	// 3. GET USER BY EMAIL (Authentication)
+	// 11. REQUEUE BREW PHOTOS
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = email
-	// Returns: User record including password_hash for authentication
-	// Usage: Login verification (compare hashed passwords)
-	GetUserByEmail(ctx context.Context, email string) (GetUserByEmailRow, error)
+	// Parameters: statuses
+	// Returns: Number of photos requeued
+	// Usage: Media migration tool, reprocessing photos from their originals,
+	//
+	//	e.g. after rendering or scanning changes. Quarantined photos,
+	//	whose originals have moved, can't be requeued.
+	//
+	// Performance: Scans brew_photo
+	RequeueBrewPhotos(ctx context.Context, statuses []string) (int64, error)
 	// ----------------------------------------------------------------------------
-	// 2. GET USER BY ID
+	// 3. RESERVE STORAGE
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = user_id
-	// Returns: Single user record (excludes password_hash for security)
-	// Usage: View user profiles, verify authentication
-	GetUserByID(ctx context.Context, id string) (GetUserByIDRow, error)
+	// Parameters: user_id, id, size_bytes, limit_bytes, ttl_seconds
+	// Returns: Whether the bytes were reserved; false if they'd take the user
+	//
+	//	past limit_bytes with what they store and have reserved
+	//
+	// Usage: Upload, before the object is stored
+	// Note: reserve_storage locks the user's storage row, so concurrent
+	//
+	//	uploads can't both fit under the quota
+	ReserveStorage(ctx context.Context, arg ReserveStorageParams) (bool, error)
 	// ----------------------------------------------------------------------------
-	// 4. GET USER BY USERNAME
+	// 2. RESET CALENDAR FEED
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = username
-	// Returns: Single user record
-	// Usage: View profiles by username, check username availability
-	GetUserByUsername(ctx context.Context, username string) (GetUserByUsernameRow, error)
-	// 3. GET USER'S FAVORITE BREW METHODS
 	// Parameters: $1 = user_id
-	// Returns: Brew methods user posts about most
-	// Usage: User preferences insights
-	GetUserFavoriteBrewMethods(ctx context.Context, ownerID string) ([]GetUserFavoriteBrewMethodsRow, error)
+	// Returns: The new version
+	// Usage: User revokes a leaked feed URL; the old URL stops verifying
+	ResetCalendarFeed(ctx context.Context, id string) (int32, error)
 	// ----------------------------------------------------------------------------
-	// 3. GET USER FEED (Posts from Friends)
+	// 4. RESET MEDIA MIGRATION
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = current_user_id, $2 = limit, $3 = offset
-	// Returns: Posts from friends, sorted by recency, with pagination
-	// Usage: Main feed feature - see what friends are brewing
-	// Performance: Uses idx_user_friendships_status and idx_post_created_at
-	GetUserFeed(ctx context.Context, arg GetUserFeedParams) ([]GetUserFeedRow, error)
-	// 2. GET USER POSTING ACTIVITY OVER TIME
-	// Parameters: $1 = user_id, $2 = days (e.g., 30)
-	// Returns: Posts per day in time period
-	// Usage: Activity graph on profile
-	GetUserPostingActivityOverTime(ctx context.Context, arg GetUserPostingActivityOverTimeParams) ([]GetUserPostingActivityOverTimeRow, error)
+	// Parameters: destination
+	// Returns: Nothing
+	// Usage: Media migration tool, starting a migration to destination over
+	// Performance: Uses the primary key
+	ResetMediaMigration(ctx context.Context, destination string) error
 	// ----------------------------------------------------------------------------
-	// 4. GET USER'S OWN POSTS
+	// 7. RETRY BREW PHOTO
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = owner_id
-	// Returns: All posts created by a specific user
-	// Usage: User profile page showing their post history
-	// Performance: Uses idx_post_owner_id
-	GetUserPosts(ctx context.Context, ownerID string) ([]GetUserPostsRow, error)
+	// Parameters: error, retry_in_seconds (NULL to stop retrying), id
+	// Returns: None
+	// Usage: Photo processor; reschedules a photo that couldn't be processed,
+	//
+	//	or marks it failed when out of retries or unreadable
+	//
+	// Performance: Uses the primary key
+	RetryBrewPhoto(ctx context.Context, arg RetryBrewPhotoParams) error
 	// ----------------------------------------------------------------------------
-	// 6. GET USER PROFILE WITH STATS
+	// 3. RETRY DOMAIN EVENTS
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = user_id
-	// Returns: User info + post_count, friend_count, avg_rating
-	// Usage: Display rich user profiles with activity statistics
-	// Performance: Uses LEFT JOINs and aggregations, leverages multiple indexes
-	GetUserProfileWithStats(ctx context.Context, id string) (GetUserProfileWithStatsRow, error)
+	// Parameters: error, retry_in_seconds, ids
+	// Returns: None
+	// Usage: Event relay, when a batch couldn't be published; events are
+	//
+	//	retried until they are
+	RetryDomainEvents(ctx context.Context, arg RetryDomainEventsParams) error
 	// ----------------------------------------------------------------------------
-	// RETENTION & COHORT ANALYSIS
+	// 4. RETRY OUTBOX MESSAGE
 	// ----------------------------------------------------------------------------
-	// 14. GET USER RETENTION BY COHORT
-	// Parameters: $1 = cohort_month (e.g., '2024-01')
-	// Returns: Users who joined in cohort and posted in subsequent months
-	// Usage: Retention analysis
-	GetUserRetentionByCohort(ctx context.Context, joinedAt pgtype.Timestamptz) ([]GetUserRetentionByCohortRow, error)
-	// 4. GET USER'S TOP BREWS
-	// Parameters: $1 = user_id, $2 = limit
-	// Returns: Brews user has posted about most
-	// Usage: "Your top brews" section
-	GetUserTopBrews(ctx context.Context, arg GetUserTopBrewsParams) ([]GetUserTopBrewsRow, error)
-	// 19. GET USERS TAGGED IN POST
-	// Parameters: $1 = post_id
-	// Returns: List of users tagged in this post
-	// Usage: Display "with X and Y" in post
-	GetUsersTaggedInPost(ctx context.Context, postID string) ([]GetUsersTaggedInPostRow, error)
+	// Parameters: error, retry_in_seconds (NULL to stop retrying), id
+	// Returns: None
+	// Usage: Outbox dispatcher; reschedules a message that couldn't be
+	//
+	//	dispatched, or marks it failed when out of retries
+	RetryOutboxMessage(ctx context.Context, arg RetryOutboxMessageParams) error
 	// ----------------------------------------------------------------------------
-	// COMMENT LIKES
+	// 5. REVEAL CUPPING SESSION
 	// ----------------------------------------------------------------------------
-	// 6. LIKE A COMMENT
-	// Parameters: $1 = comment_id, $2 = user_id
-	// Returns: Created like record
-	// Usage: User likes a comment
-	// Note: ON CONFLICT makes this idempotent
-	LikeComment(ctx context.Context, arg LikeCommentParams) (CommentLike, error)
-	// ============================================================================
-	// INTERACTION QUERIES
-	// ============================================================================
-	// Operations for likes and comments on posts and comments
+	// Parameters: $1 = id
+	// Returns: The revealed session, or no rows if it was already revealed
+	// Usage: Host reveals the samples and locks scoring
+	RevealCuppingSession(ctx context.Context, id string) (CuppingSession, error)
 	// ----------------------------------------------------------------------------
-	// POST LIKES
+	// 4. REVOKE API KEY
 	// ----------------------------------------------------------------------------
-	// 1. LIKE A POST
-	// Parameters: $1 = post_id, $2 = user_id
-	// Returns: Created like record
-	// Usage: User likes a post
-	// Note: ON CONFLICT makes this idempotent (can call multiple times safely)
-	LikePost(ctx context.Context, arg LikePostParams) (PostLike, error)
+	// Parameters: $1 = id, $2 = user_id
+	// Returns: Number of rows updated; 0 if not the user's or already revoked
+	// Usage: User revokes a key
+	RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (int64, error)
 	// ----------------------------------------------------------------------------
-	// 6. MARK ALL NOTIFICATIONS AS READ
+	// 15. REVOKE CLUB INVITE
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = recipient_user_id
-	// Returns: Number of updated notifications
-	// Usage: "Mark all as read" button
-	MarkAllNotificationsAsRead(ctx context.Context, recipientUserID string) ([]string, error)
+	// Parameters: $1 = id, $2 = club_id
+	// Returns: Number of invites revoked
+	// Usage: Moderator disables an invite link
+	RevokeClubInvite(ctx context.Context, arg RevokeClubInviteParams) (int64, error)
 	// ----------------------------------------------------------------------------
-	// 5. MARK MULTIPLE NOTIFICATIONS AS READ
+	// 4. REVOKE INVITE
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = recipient_user_id, $2 = array of notification_ids
-	// Returns: Number of updated notifications
-	// Usage: "Mark all as read" or batch mark
-	// Note: ANY($2) allows passing array of IDs
-	MarkMultipleNotificationsAsRead(ctx context.Context, arg MarkMultipleNotificationsAsReadParams) ([]string, error)
+	// Parameters: code, inviter_id
+	// Returns: Rows affected (0 if not the user's or already revoked)
+	// Usage: User revokes an invite; its unredeemed uses return to their quota
+	RevokeInvite(ctx context.Context, arg RevokeInviteParams) (int64, error)
 	// ----------------------------------------------------------------------------
-	// 4. MARK NOTIFICATION AS READ
+	// 13. REVOKE TRUSTED DEVICE
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = notification_id, $2 = recipient_user_id
-	// Returns: Updated notification
-	// Usage: User clicks on a notification
-	// Note: Includes recipient_user_id check for security
-	MarkNotificationAsRead(ctx context.Context, arg MarkNotificationAsReadParams) (MarkNotificationAsReadRow, error)
+	// Parameters: id, user_id
+	// Returns: Number of devices deleted (0 if the user has no such device)
+	// Usage: User stops trusting a device, e.g. a lost phone
+	RevokeTrustedDevice(ctx context.Context, arg RevokeTrustedDeviceParams) (int64, error)
 	// ----------------------------------------------------------------------------
-	// 3. REJECT/CANCEL FRIEND REQUEST
+	// 14. REVOKE TRUSTED DEVICES
 	// ----------------------------------------------------------------------------
-	// Parameters: $1 = user_id, $2 = friend_id
-	// Returns: Deleted friendship id
-	// Usage: Reject incoming request or cancel outgoing request
-	RejectFriendRequest(ctx context.Context, arg RejectFriendRequestParams) (RejectFriendRequestRow, error)
+	// Parameters: user_id
+	// Returns: Number of devices deleted
+	// Usage: User stops trusting all their devices
+	// Performance: Uses idx_trusted_device_user
+	RevokeTrustedDevices(ctx context.Context, userID string) (int64, error)
 	// ----------------------------------------------------------------------------
 	// BREW SEARCH
 	// ----------------------------------------------------------------------------
@@ -636,6 +4094,129 @@ type Querier interface {
 	// Note: Creates single 'pending' row, reverse row created on acceptance
 	SendFriendRequest(ctx context.Context, arg SendFriendRequestParams) (SendFriendRequestRow, error)
 	// ----------------------------------------------------------------------------
+	// 5. SET BEAN BAG FLAVORS
+	// ----------------------------------------------------------------------------
+	// Parameters: bean_bag_id, descriptor_ids (the complete new set)
+	// Returns: Nothing
+	// Usage: Replace a bag's descriptors (e.g. the roaster's notes)
+	SetBeanBagFlavors(ctx context.Context, arg SetBeanBagFlavorsParams) error
+	// ----------------------------------------------------------------------------
+	// 3. SET BREW FLAVORS
+	// ----------------------------------------------------------------------------
+	// Parameters: brew_id, descriptor_ids (the complete new set)
+	// Returns: Nothing
+	// Usage: Replace a brew's tasting descriptors in one statement
+	SetBrewFlavors(ctx context.Context, arg SetBrewFlavorsParams) error
+	// ----------------------------------------------------------------------------
+	// 5. SET BREW SYNC VECTOR
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id, $2 = created_by, $3 = sync_vector
+	// Returns: The brew with its new vector; no rows if it isn't the user's
+	// Usage: A conflict resolved in the server's favour: the brew keeps its
+	//
+	//	values under a vector that supersedes the client's
+	SetBrewSyncVector(ctx context.Context, arg SetBrewSyncVectorParams) (Brew, error)
+	// ----------------------------------------------------------------------------
+	// 6. SET DRAFT SYNC VECTOR
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id, $2 = user_id, $3 = sync_vector
+	// Returns: The draft with its new vector; no rows if it isn't the user's
+	// Usage: A conflict resolved in the server's favour, as for brews
+	SetDraftSyncVector(ctx context.Context, arg SetDraftSyncVectorParams) (Draft, error)
+	// ----------------------------------------------------------------------------
+	// 4. SET EMAIL DOMAIN OVERRIDE
+	// ----------------------------------------------------------------------------
+	// Parameters: domain, action, note, created_by
+	// Returns: The override
+	// Usage: Admin allows or blocks a domain
+	SetEmailDomainOverride(ctx context.Context, arg SetEmailDomainOverrideParams) (EmailDomainOverride, error)
+	// ----------------------------------------------------------------------------
+	// 6. SET PENDING TOTP SECRET
+	// ----------------------------------------------------------------------------
+	// Parameters: id, totp_secret
+	// Returns: Number of users updated (0 if TOTP is already enabled)
+	// Usage: TOTP enrollment starts; replaces an unconfirmed secret
+	SetPendingTOTPSecret(ctx context.Context, arg SetPendingTOTPSecretParams) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 6. SET ROASTER VERIFIED
+	// ----------------------------------------------------------------------------
+	// Parameters: verified, id
+	// Returns: The updated roaster; re-verifying keeps the original verified_at
+	// Usage: Admin verifies a roaster or revokes its verification
+	SetRoasterVerified(ctx context.Context, arg SetRoasterVerifiedParams) (Roaster, error)
+	// ----------------------------------------------------------------------------
+	// 2. SET STRIPE CUSTOMER
+	// ----------------------------------------------------------------------------
+	// Parameters: id, stripe_customer_id
+	// Returns: Rows affected; 0 if the user already has a customer
+	// Usage: First checkout links the Stripe customer created for the user
+	SetStripeCustomer(ctx context.Context, arg SetStripeCustomerParams) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 6. SET TDS READING BREW
+	// ----------------------------------------------------------------------------
+	// Parameters: brew_id (NULL detaches), id
+	// Returns: The updated reading
+	// Usage: Attach a reading to a different brew, or detach it
+	SetTDSReadingBrew(ctx context.Context, arg SetTDSReadingBrewParams) (TdsReading, error)
+	// ============================================================================
+	// BACKUP QUERIES
+	// ============================================================================
+	// History of logical backups taken by the backup job
+	// ----------------------------------------------------------------------------
+	// 1. START BACKUP RUN
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id (ULID), $2 = trigger, $3 = requested_by (NULL for
+	//
+	//	scheduled runs)
+	//
+	// Returns: The started run
+	// Usage: Backup job and the admin trigger; fails with a unique violation
+	//
+	//	(idx_backup_run_running) while another run is in progress
+	StartBackupRun(ctx context.Context, arg StartBackupRunParams) (BackupRun, error)
+	// ----------------------------------------------------------------------------
+	// 9. START BREW EVENT TIMER
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id
+	// Returns: The event with its timer (re)started now
+	// Usage: Host starts the shared timer; attendees are notified in realtime
+	StartBrewEventTimer(ctx context.Context, id string) (BrewEvent, error)
+	// ----------------------------------------------------------------------------
+	// 5. START BREW TIMER
+	// ----------------------------------------------------------------------------
+	// Parameters: brew_id, user_id, remind_in_seconds (NULL for no reminder)
+	// Returns: The updated brew record
+	// Usage: Start (or restart) a timer session; clears any previous result
+	StartBrewTimer(ctx context.Context, arg StartBrewTimerParams) (Brew, error)
+	// ----------------------------------------------------------------------------
+	// 10. STOP BREW EVENT TIMER
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id
+	// Returns: The event with its timer stopped, or no rows if none was running
+	// Usage: Host stops the shared timer
+	StopBrewEventTimer(ctx context.Context, id string) (BrewEvent, error)
+	// ----------------------------------------------------------------------------
+	// 6. STOP BREW TIMER
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = brew_id, $2 = user_id
+	// Returns: The updated brew record, or no rows if no timer is running
+	// Usage: End a timer session and record the elapsed brew time
+	StopBrewTimer(ctx context.Context, arg StopBrewTimerParams) (Brew, error)
+	// ============================================================================
+	// EMAIL SUPPRESSION QUERIES
+	// ============================================================================
+	// Operations for the list of addresses mail is no longer sent to. Addresses
+	// are stored lowercased.
+	// ----------------------------------------------------------------------------
+	// 1. SUPPRESS EMAIL
+	// ----------------------------------------------------------------------------
+	// Parameters: email, reason, provider, detail
+	// Returns: None
+	// Usage: Mail provider bounce and complaint webhooks, admin suppressions.
+	//
+	//	An address already suppressed keeps its first reason.
+	SuppressEmail(ctx context.Context, arg SuppressEmailParams) error
+	// ----------------------------------------------------------------------------
 	// POST USER TAGS
 	// ----------------------------------------------------------------------------
 	// 17. TAG USER IN POST
@@ -645,6 +4226,32 @@ type Querier interface {
 	// Note: Should trigger notification (see notification.sql)
 	TagUserInPost(ctx context.Context, arg TagUserInPostParams) (PostUserTag, error)
 	// ----------------------------------------------------------------------------
+	// 5. TOUCH API KEY
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id
+	// Returns: None
+	// Usage: Record when a key was last used; skipped within a minute of the
+	//
+	//	last write so busy integrations don't update the row every request
+	TouchAPIKey(ctx context.Context, id string) error
+	// ----------------------------------------------------------------------------
+	// 4. TOUCH REALTIME PRESENCE
+	// ----------------------------------------------------------------------------
+	// Parameters: instance_id
+	// Returns: Nothing
+	// Usage: Presence heartbeat, keeping this instance's rows fresh
+	// Performance: Uses idx_realtime_presence_instance_id
+	TouchRealtimePresence(ctx context.Context, instanceID string) error
+	// ----------------------------------------------------------------------------
+	// 4. TOUCH SCIM CLIENT
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id
+	// Returns: None
+	// Usage: Record when a client was last used; skipped within a minute of
+	//
+	//	the last write, as provisioning runs send requests in bursts
+	TouchSCIMClient(ctx context.Context, id string) error
+	// ----------------------------------------------------------------------------
 	// 13. UNBLOCK USER
 	// ----------------------------------------------------------------------------
 	// Parameters: $1 = blocker_user_id, $2 = blocked_user_id
@@ -682,12 +4289,106 @@ type Querier interface {
 	// Returns: Deleted tag record
 	// Usage: Remove tag from post
 	UntagUserFromPost(ctx context.Context, arg UntagUserFromPostParams) (UntagUserFromPostRow, error)
+	// ----------------------------------------------------------------------------
+	// 3. UPDATE BEAN
+	// ----------------------------------------------------------------------------
+	// Parameters: name, roaster_name, bean_origin, description, origin fields,
+	//
+	//	id (NULL leaves a field as is)
+	//
+	// Returns: The updated bean
+	// Usage: Creator edits a community entry, or a roaster its listing
+	UpdateBean(ctx context.Context, arg UpdateBeanParams) (Bean, error)
+	// ----------------------------------------------------------------------------
+	// 4. UPDATE BEAN BAG
+	// ----------------------------------------------------------------------------
+	// Parameters: id, owner_id, and optional name, weight_grams,
+	//
+	//	assumed_dose_grams, reorder_lead_days, price_minor, currency,
+	//	purchased_on, origin fields, rest_days, finished (NULL keeps
+	//	the current value)
+	//
+	// Returns: The updated bean bag record, or no rows if not the owner's
+	// Usage: Correct a bag or change its forecast assumptions; changing them
+	//
+	//	re-arms the reorder suggestion
+	UpdateBeanBag(ctx context.Context, arg UpdateBeanBagParams) (BeanBag, error)
+	// ----------------------------------------------------------------------------
+	// 17. UPDATE BREW
+	// ----------------------------------------------------------------------------
+	// Parameters: id, created_by, every editable column (already converted and
+	//
+	//	validated as for CreateBrew), created_at (NULL keeps it),
+	//	sync_vector (NULL for server-side edits)
+	//
+	// Returns: The updated brew; no rows if it isn't the user's
+	// Usage: Applying a brew edited on an offline client
+	UpdateBrew(ctx context.Context, arg UpdateBrewParams) (Brew, error)
+	// ----------------------------------------------------------------------------
+	// 14. UPDATE BREW CUSTOM FIELD
+	// ----------------------------------------------------------------------------
+	// Parameters: unit (NULL clears it), id, user_id
+	// Returns: The updated field, or no rows if not the user's
+	// Usage: Change a field's unit label; names and types are fixed once
+	//
+	//	values are stored under them
+	UpdateBrewCustomField(ctx context.Context, arg UpdateBrewCustomFieldParams) (BrewCustomField, error)
+	// ----------------------------------------------------------------------------
+	// 3. UPDATE CHALLENGE
+	// ----------------------------------------------------------------------------
+	// Parameters: name, description, threshold, is_active, id (NULL leaves a
+	//
+	//	field as is)
+	//
+	// Returns: The updated challenge
+	// Usage: Admin edits or retires a challenge; badges already earned are kept
+	UpdateChallenge(ctx context.Context, arg UpdateChallengeParams) (Challenge, error)
+	// ----------------------------------------------------------------------------
+	// 3. UPDATE CLUB
+	// ----------------------------------------------------------------------------
+	// Parameters: id, name, description, is_private (NULL leaves a field as is)
+	// Returns: The updated club
+	// Usage: Owner edits the club's details
+	UpdateClub(ctx context.Context, arg UpdateClubParams) (Club, error)
+	// ----------------------------------------------------------------------------
+	// 10. UPDATE CLUB MEMBER ROLE
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = club_id, $2 = user_id, $3 = role
+	// Returns: The updated membership, or no rows for non-members and the owner
+	// Usage: Owner promotes a member to moderator or demotes them
+	UpdateClubMemberRole(ctx context.Context, arg UpdateClubMemberRoleParams) (ClubMember, error)
 	// 13. UPDATE COMMENT
 	// Parameters: $1 = comment_id, $2 = content
 	// Returns: Updated comment record
 	// Usage: User edits their comment
 	UpdateComment(ctx context.Context, arg UpdateCommentParams) (UpdateCommentRow, error)
 	// ----------------------------------------------------------------------------
+	// 5. UPDATE DRAFT DATA
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = id, $2 = user_id, $3 = data,
+	//
+	//	$4 = sync_vector (NULL for server-side edits)
+	//
+	// Returns: The saved draft; no rows if it isn't the user's
+	// Usage: Autosave, with the patch already merged into the stored data, and
+	//
+	//	drafts pushed by an offline client
+	UpdateDraftData(ctx context.Context, arg UpdateDraftDataParams) (Draft, error)
+	// ----------------------------------------------------------------------------
+	// 3. UPDATE ORGANIZATION
+	// ----------------------------------------------------------------------------
+	// Parameters: id, name, kind (NULL leaves a field as is)
+	// Returns: The updated organization
+	// Usage: Owners and admins edit the organization's details
+	UpdateOrganization(ctx context.Context, arg UpdateOrganizationParams) (Organization, error)
+	// ----------------------------------------------------------------------------
+	// 11. UPDATE ORGANIZATION MEMBER ROLE
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = organization_id, $2 = user_id, $3 = role
+	// Returns: The updated membership
+	// Usage: Owners change a member's role
+	UpdateOrganizationMemberRole(ctx context.Context, arg UpdateOrganizationMemberRoleParams) (OrganizationMember, error)
+	// ----------------------------------------------------------------------------
 	// 10. UPDATE PASSWORD
 	// ----------------------------------------------------------------------------
 	// Parameters: $1 = user_id, $2 = new_password_hash
@@ -702,12 +4403,114 @@ type Querier interface {
 	// Usage: User edits their post
 	UpdatePost(ctx context.Context, arg UpdatePostParams) (Post, error)
 	// ----------------------------------------------------------------------------
+	// 16. UPDATE RECIPE
+	// ----------------------------------------------------------------------------
+	// Parameters: id, owner_id, name, brew_method, is_public
+	// Returns: The updated recipe; no rows if it isn't the owner's
+	// Usage: Owner renames a recipe or changes its method or visibility. These
+	//
+	//	aren't versioned, so no revision is appended.
+	UpdateRecipe(ctx context.Context, arg UpdateRecipeParams) (Recipe, error)
+	// ----------------------------------------------------------------------------
+	// 11. UPDATE RECIPE COLLABORATOR ROLE
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = recipe_id, $2 = user_id, $3 = role
+	// Returns: The updated collaborator record
+	// Usage: Owner promotes a viewer to editor or vice versa
+	UpdateRecipeCollaboratorRole(ctx context.Context, arg UpdateRecipeCollaboratorRoleParams) (RecipeCollaborator, error)
+	// ----------------------------------------------------------------------------
+	// 4. UPDATE ROASTER
+	// ----------------------------------------------------------------------------
+	// Parameters: name, website, location, description, id (NULL leaves a
+	//
+	//	field as is)
+	//
+	// Returns: The updated roaster; a new name clears verification, so a
+	//
+	//	verified account can't be renamed into another brand
+	//
+	// Usage: Roaster edits its profile
+	UpdateRoaster(ctx context.Context, arg UpdateRoasterParams) (Roaster, error)
+	// ----------------------------------------------------------------------------
+	// 10. UPDATE SCIM USER
+	// ----------------------------------------------------------------------------
+	// Parameters: client_id, id, username, email, password_hash (optional,
+	//
+	//	keeps the password), active, external_id
+	//
+	// Returns: The updated account, or no rows if the client didn't provision
+	//
+	//	it
+	//
+	// Usage: A client replaces or patches an account. Deactivating an account
+	//
+	//	already deactivated keeps when that happened.
+	UpdateSCIMUser(ctx context.Context, arg UpdateSCIMUserParams) (UpdateSCIMUserRow, error)
+	// ----------------------------------------------------------------------------
+	// 13. UPDATE USER PREFERENCES
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id, $2 = weight_unit, $3 = temperature_unit,
+	//
+	//	$4 = timezone, $5 = currency, $6 = analytics_consent
+	//
+	// Returns: Updated preferences
+	// Usage: User switches between metric and imperial display, changes
+	//
+	//	timezone or currency, or opts in or out of analytics
+	UpdateUserPreferences(ctx context.Context, arg UpdateUserPreferencesParams) (UpdateUserPreferencesRow, error)
+	// ----------------------------------------------------------------------------
 	// 5. UPDATE USER PROFILE
 	// ----------------------------------------------------------------------------
 	// Parameters: $1 = user_id, $2 = profile_picture_url, $3 = bio, $4 = location
-	// Returns: Updated user record
-	// Usage: User edits their profile
+	// Returns: Updated user record, with the same columns as GetUserByID
+	// Usage: User edits their profile; every field is written, so NULL clears it
+	//
+	//	(callers merge partial updates over the current profile)
 	UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (UpdateUserProfileRow, error)
+	// ----------------------------------------------------------------------------
+	// 11. UPDATE USERNAME
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = user_id, $2 = new_username (already normalized)
+	// Returns: Updated user record
+	// Usage: Username change; unique violations surface as 23505
+	UpdateUsername(ctx context.Context, arg UpdateUsernameParams) (UpdateUsernameRow, error)
+	// ----------------------------------------------------------------------------
+	// 5. UPSERT BREW EVENT RSVP
+	// ----------------------------------------------------------------------------
+	// Parameters: $1 = event_id, $2 = user_id, $3 = status
+	// Returns: The RSVP
+	// Usage: User answers 'going', 'maybe' or 'not_going', or changes their answer
+	UpsertBrewEventRSVP(ctx context.Context, arg UpsertBrewEventRSVPParams) (BrewEventRsvp, error)
+	// ----------------------------------------------------------------------------
+	// 11. UPSERT CUPPING SCORE
+	// ----------------------------------------------------------------------------
+	// Parameters: user_id, score, notes, session_id, label
+	// Returns: The score, or no rows if the label doesn't exist or the session
+	//
+	//	has been revealed
+	//
+	// Usage: Scorer records or changes their score for a sample
+	UpsertCuppingScore(ctx context.Context, arg UpsertCuppingScoreParams) (CuppingScore, error)
+	// ----------------------------------------------------------------------------
+	// 9. USE TOTP STEP
+	// ----------------------------------------------------------------------------
+	// Parameters: id, step
+	// Returns: Number of users updated (0 if a code from this or a later step
+	//
+	//	was already used)
+	//
+	// Usage: Accepting a TOTP code, so it can't be used twice
+	UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (int64, error)
+	// ----------------------------------------------------------------------------
+	// 11. USE TRUSTED DEVICE
+	// ----------------------------------------------------------------------------
+	// Parameters: user_id, token_hash
+	// Returns: Number of devices updated (0 if the token isn't a current
+	//
+	//	trusted device of the user)
+	//
+	// Usage: Login skips step-up for a trusted device
+	UseTrustedDevice(ctx context.Context, arg UseTrustedDeviceParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
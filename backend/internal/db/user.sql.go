@@ -14,7 +14,7 @@ import (
 
 const checkEmailAvailability = `-- name: CheckEmailAvailability :one
 SELECT NOT EXISTS (
-    SELECT 1 FROM "user" WHERE email = $1
+    SELECT 1 FROM "user" WHERE LOWER(email) = LOWER($1)
 ) as available
 `
 
@@ -24,6 +24,7 @@ SELECT NOT EXISTS (
 // Parameters: $1 = email
 // Returns: Boolean (true if available, false if taken)
 // Usage: Real-time validation during registration
+// Note: Emails are unique case-insensitively (idx_user_email_lower)
 func (q *Queries) CheckEmailAvailability(ctx context.Context, email string) (bool, error) {
 	row := q.db.QueryRow(ctx, checkEmailAvailability, email)
 	var available bool
@@ -33,7 +34,7 @@ func (q *Queries) CheckEmailAvailability(ctx context.Context, email string) (boo
 
 const checkUsernameAvailability = `-- name: CheckUsernameAvailability :one
 SELECT NOT EXISTS (
-    SELECT 1 FROM "user" WHERE username = $1
+    SELECT 1 FROM "user" WHERE LOWER(username) = LOWER($1)
 ) as available
 `
 
@@ -43,6 +44,7 @@ SELECT NOT EXISTS (
 // Parameters: $1 = username
 // Returns: Boolean (true if available, false if taken)
 // Usage: Real-time validation during registration
+// Note: Usernames are unique case-insensitively (idx_user_username_lower)
 func (q *Queries) CheckUsernameAvailability(ctx context.Context, username string) (bool, error) {
 	row := q.db.QueryRow(ctx, checkUsernameAvailability, username)
 	var available bool
@@ -50,19 +52,122 @@ func (q *Queries) CheckUsernameAvailability(ctx context.Context, username string
 	return available, err
 }
 
+const createInvitedUser = `-- name: CreateInvitedUser :one
+WITH redeemed AS (
+    UPDATE invite
+    SET use_count = use_count + 1
+    WHERE code = $1
+      AND revoked_at IS NULL
+      AND (expires_at IS NULL OR expires_at > NOW())
+      AND use_count < max_uses
+    RETURNING code, inviter_id
+), created AS (
+    INSERT INTO "user" (id, username, email, password_hash, joined_at, invite_code, invited_by,
+                        birth_date, consent_pending)
+    SELECT $2, $3, $4, $5, NOW(),
+           redeemed.code, redeemed.inviter_id, $6, $7::text IS NOT NULL
+    FROM redeemed
+    RETURNING id, username, email, joined_at, created_at
+), consent AS (
+    INSERT INTO parental_consent (user_id, parent_email, token_hash, expires_at)
+    SELECT id, $7, $8,
+           NOW() + $9::int * INTERVAL '1 second'
+    FROM created
+    WHERE $7::text IS NOT NULL
+)
+SELECT id, username, email, joined_at, created_at
+FROM created
+`
+
+type CreateInvitedUserParams struct {
+	Code              string      `json:"code"`
+	ID                string      `json:"id"`
+	Username          string      `json:"username"`
+	Email             string      `json:"email"`
+	PasswordHash      string      `json:"password_hash"`
+	BirthDate         pgtype.Date `json:"birth_date"`
+	ParentEmail       *string     `json:"parent_email"`
+	ConsentTokenHash  *string     `json:"consent_token_hash"`
+	ConsentTtlSeconds int32       `json:"consent_ttl_seconds"`
+}
+
+type CreateInvitedUserRow struct {
+	ID        string             `json:"id"`
+	Username  string             `json:"username"`
+	Email     string             `json:"email"`
+	JoinedAt  pgtype.Timestamptz `json:"joined_at"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+// ----------------------------------------------------------------------------
+// 15. CREATE INVITED USER
+// ----------------------------------------------------------------------------
+// Parameters: code (the invite's), id, username, email, password_hash,
+//
+//	birth_date, parent_email, consent_token_hash,
+//	consent_ttl_seconds
+//
+// Returns: Created user record, or no rows if the invite can't be redeemed
+//
+//	(unknown, revoked, expired or used up)
+//
+// Usage: Registration with an invite. The invite's use is counted in the
+//
+//	same statement, so a failed registration doesn't spend it. A
+//	parent_email creates the user awaiting consent, as in CreateUser.
+func (q *Queries) CreateInvitedUser(ctx context.Context, arg CreateInvitedUserParams) (CreateInvitedUserRow, error) {
+	row := q.db.QueryRow(ctx, createInvitedUser,
+		arg.Code,
+		arg.ID,
+		arg.Username,
+		arg.Email,
+		arg.PasswordHash,
+		arg.BirthDate,
+		arg.ParentEmail,
+		arg.ConsentTokenHash,
+		arg.ConsentTtlSeconds,
+	)
+	var i CreateInvitedUserRow
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.JoinedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const createUser = `-- name: CreateUser :one
 
 
-INSERT INTO "user" (id, username, email, password_hash, joined_at)
-VALUES ($1, $2, $3, $4, NOW())
-RETURNING id, username, email, joined_at, created_at
+WITH created AS (
+    INSERT INTO "user" (id, username, email, password_hash, joined_at, birth_date, consent_pending)
+    VALUES (
+        $1, $2, $3, $4, NOW(),
+        $5, $6::text IS NOT NULL
+    )
+    RETURNING id, username, email, joined_at, created_at
+), consent AS (
+    INSERT INTO parental_consent (user_id, parent_email, token_hash, expires_at)
+    SELECT id, $6, $7,
+           NOW() + $8::int * INTERVAL '1 second'
+    FROM created
+    WHERE $6::text IS NOT NULL
+)
+SELECT id, username, email, joined_at, created_at
+FROM created
 `
 
 type CreateUserParams struct {
-	ID           string `json:"id"`
-	Username     string `json:"username"`
-	Email        string `json:"email"`
-	PasswordHash string `json:"password_hash"`
+	ID                string      `json:"id"`
+	Username          string      `json:"username"`
+	Email             string      `json:"email"`
+	PasswordHash      string      `json:"password_hash"`
+	BirthDate         pgtype.Date `json:"birth_date"`
+	ParentEmail       *string     `json:"parent_email"`
+	ConsentTokenHash  *string     `json:"consent_token_hash"`
+	ConsentTtlSeconds int32       `json:"consent_ttl_seconds"`
 }
 
 type CreateUserRow struct {
@@ -80,15 +185,25 @@ type CreateUserRow struct {
 // ----------------------------------------------------------------------------
 // 1. CREATE USER (Registration)
 // ----------------------------------------------------------------------------
-// Parameters: $1 = id (ULID), $2 = username, $3 = email, $4 = password_hash
+// Parameters: id (ULID), username, email, password_hash, birth_date,
+//
+//	parent_email, consent_token_hash, consent_ttl_seconds
+//
 // Returns: The created user record
-// Usage: Called during user registration
+// Usage: Called during user registration. A parent_email means the user is
+//
+//	under the minimum age: they're created awaiting consent, and the
+//	request to their parent is created in the same statement.
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (CreateUserRow, error) {
 	row := q.db.QueryRow(ctx, createUser,
 		arg.ID,
 		arg.Username,
 		arg.Email,
 		arg.PasswordHash,
+		arg.BirthDate,
+		arg.ParentEmail,
+		arg.ConsentTokenHash,
+		arg.ConsentTtlSeconds,
 	)
 	var i CreateUserRow
 	err := row.Scan(
@@ -102,25 +217,31 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (CreateU
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, username, email, password_hash, profile_picture_url
+SELECT id, username, email, password_hash, profile_picture_url, deactivated_at, consent_pending
 FROM "user"
-WHERE email = $1
+WHERE LOWER(email) = LOWER($1)
 `
 
 type GetUserByEmailRow struct {
-	ID                string  `json:"id"`
-	Username          string  `json:"username"`
-	Email             string  `json:"email"`
-	PasswordHash      string  `json:"password_hash"`
-	ProfilePictureUrl *string `json:"profile_picture_url"`
+	ID                string             `json:"id"`
+	Username          string             `json:"username"`
+	Email             string             `json:"email"`
+	PasswordHash      string             `json:"password_hash"`
+	ProfilePictureUrl *string            `json:"profile_picture_url"`
+	DeactivatedAt     pgtype.Timestamptz `json:"deactivated_at"`
+	ConsentPending    bool               `json:"consent_pending"`
 }
 
 // ----------------------------------------------------------------------------
 // 3. GET USER BY EMAIL (Authentication)
 // ----------------------------------------------------------------------------
 // Parameters: $1 = email
-// Returns: User record including password_hash for authentication
+// Returns: User record including password_hash for authentication, and
+//
+//	whether the account was deactivated or awaits parental consent
+//
 // Usage: Login verification (compare hashed passwords)
+// Performance: Uses idx_user_email_lower
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (GetUserByEmailRow, error) {
 	row := q.db.QueryRow(ctx, getUserByEmail, email)
 	var i GetUserByEmailRow
@@ -130,6 +251,8 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (GetUserByEm
 		&i.Email,
 		&i.PasswordHash,
 		&i.ProfilePictureUrl,
+		&i.DeactivatedAt,
+		&i.ConsentPending,
 	)
 	return i, err
 }
@@ -208,6 +331,63 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (GetUs
 	return i, err
 }
 
+const getUserPlan = `-- name: GetUserPlan :one
+SELECT plan FROM "user"
+WHERE id = $1
+`
+
+// ----------------------------------------------------------------------------
+// 14. GET USER PLAN
+// ----------------------------------------------------------------------------
+// Parameters: $1 = user_id
+// Returns: The user's plan tier
+// Usage: Quota middleware, cached per user
+func (q *Queries) GetUserPlan(ctx context.Context, id string) (string, error) {
+	row := q.db.QueryRow(ctx, getUserPlan, id)
+	var plan string
+	err := row.Scan(&plan)
+	return plan, err
+}
+
+const getUserPreferences = `-- name: GetUserPreferences :one
+SELECT weight_unit, temperature_unit, timezone, currency, analytics_consent
+FROM "user"
+WHERE id = $1
+`
+
+type GetUserPreferencesRow struct {
+	WeightUnit       string `json:"weight_unit"`
+	TemperatureUnit  string `json:"temperature_unit"`
+	Timezone         string `json:"timezone"`
+	Currency         string `json:"currency"`
+	AnalyticsConsent bool   `json:"analytics_consent"`
+}
+
+// ----------------------------------------------------------------------------
+// 12. GET USER PREFERENCES
+// ----------------------------------------------------------------------------
+// Parameters: $1 = user_id
+// Returns: The user's display units, timezone, currency and analytics
+//
+//	consent
+//
+// Usage: Convert brew payloads to/from canonical metric storage, local day
+//
+//	boundaries, default currency for bean prices, whether to keep
+//	client analytics events
+func (q *Queries) GetUserPreferences(ctx context.Context, id string) (GetUserPreferencesRow, error) {
+	row := q.db.QueryRow(ctx, getUserPreferences, id)
+	var i GetUserPreferencesRow
+	err := row.Scan(
+		&i.WeightUnit,
+		&i.TemperatureUnit,
+		&i.Timezone,
+		&i.Currency,
+		&i.AnalyticsConsent,
+	)
+	return i, err
+}
+
 const getUserProfileWithStats = `-- name: GetUserProfileWithStats :one
 SELECT
     u.id,
@@ -265,6 +445,30 @@ func (q *Queries) GetUserProfileWithStats(ctx context.Context, id string) (GetUs
 	return i, err
 }
 
+const isUserActive = `-- name: IsUserActive :one
+SELECT EXISTS (
+    SELECT 1 FROM "user"
+    WHERE id = $1 AND deactivated_at IS NULL
+)
+`
+
+// ----------------------------------------------------------------------------
+// 16. IS USER ACTIVE
+// ----------------------------------------------------------------------------
+// Parameters: $1 = id
+// Returns: Whether the user exists and hasn't been deactivated
+// Usage: Authentication middleware, cached per user, so tokens issued
+//
+//	before an account was deactivated or deleted stop working
+//
+// Performance: Uses the primary key
+func (q *Queries) IsUserActive(ctx context.Context, id string) (bool, error) {
+	row := q.db.QueryRow(ctx, isUserActive, id)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const searchUsersByUsernameBasic = `-- name: SearchUsersByUsernameBasic :many
 SELECT id, username, profile_picture_url, bio
 FROM "user"
@@ -344,15 +548,76 @@ func (q *Queries) UpdatePassword(ctx context.Context, arg UpdatePasswordParams)
 	return i, err
 }
 
+const updateUserPreferences = `-- name: UpdateUserPreferences :one
+UPDATE "user"
+SET
+    weight_unit = $2,
+    temperature_unit = $3,
+    timezone = $4,
+    currency = $5,
+    analytics_consent = $6,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING weight_unit, temperature_unit, timezone, currency, analytics_consent
+`
+
+type UpdateUserPreferencesParams struct {
+	ID               string `json:"id"`
+	WeightUnit       string `json:"weight_unit"`
+	TemperatureUnit  string `json:"temperature_unit"`
+	Timezone         string `json:"timezone"`
+	Currency         string `json:"currency"`
+	AnalyticsConsent bool   `json:"analytics_consent"`
+}
+
+type UpdateUserPreferencesRow struct {
+	WeightUnit       string `json:"weight_unit"`
+	TemperatureUnit  string `json:"temperature_unit"`
+	Timezone         string `json:"timezone"`
+	Currency         string `json:"currency"`
+	AnalyticsConsent bool   `json:"analytics_consent"`
+}
+
+// ----------------------------------------------------------------------------
+// 13. UPDATE USER PREFERENCES
+// ----------------------------------------------------------------------------
+// Parameters: $1 = user_id, $2 = weight_unit, $3 = temperature_unit,
+//
+//	$4 = timezone, $5 = currency, $6 = analytics_consent
+//
+// Returns: Updated preferences
+// Usage: User switches between metric and imperial display, changes
+//
+//	timezone or currency, or opts in or out of analytics
+func (q *Queries) UpdateUserPreferences(ctx context.Context, arg UpdateUserPreferencesParams) (UpdateUserPreferencesRow, error) {
+	row := q.db.QueryRow(ctx, updateUserPreferences,
+		arg.ID,
+		arg.WeightUnit,
+		arg.TemperatureUnit,
+		arg.Timezone,
+		arg.Currency,
+		arg.AnalyticsConsent,
+	)
+	var i UpdateUserPreferencesRow
+	err := row.Scan(
+		&i.WeightUnit,
+		&i.TemperatureUnit,
+		&i.Timezone,
+		&i.Currency,
+		&i.AnalyticsConsent,
+	)
+	return i, err
+}
+
 const updateUserProfile = `-- name: UpdateUserProfile :one
 UPDATE "user"
 SET
-    profile_picture_url = COALESCE($2, profile_picture_url),
-    bio = COALESCE($3, bio),
-    location = COALESCE($4, location),
+    profile_picture_url = $2,
+    bio = $3,
+    location = $4,
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, username, email, profile_picture_url, bio, location, updated_at
+RETURNING id, username, email, profile_picture_url, bio, location, joined_at
 `
 
 type UpdateUserProfileParams struct {
@@ -363,21 +628,23 @@ type UpdateUserProfileParams struct {
 }
 
 type UpdateUserProfileRow struct {
-	ID                string    `json:"id"`
-	Username          string    `json:"username"`
-	Email             string    `json:"email"`
-	ProfilePictureUrl *string   `json:"profile_picture_url"`
-	Bio               *string   `json:"bio"`
-	Location          *string   `json:"location"`
-	UpdatedAt         time.Time `json:"updated_at"`
+	ID                string             `json:"id"`
+	Username          string             `json:"username"`
+	Email             string             `json:"email"`
+	ProfilePictureUrl *string            `json:"profile_picture_url"`
+	Bio               *string            `json:"bio"`
+	Location          *string            `json:"location"`
+	JoinedAt          pgtype.Timestamptz `json:"joined_at"`
 }
 
 // ----------------------------------------------------------------------------
 // 5. UPDATE USER PROFILE
 // ----------------------------------------------------------------------------
 // Parameters: $1 = user_id, $2 = profile_picture_url, $3 = bio, $4 = location
-// Returns: Updated user record
-// Usage: User edits their profile
+// Returns: Updated user record, with the same columns as GetUserByID
+// Usage: User edits their profile; every field is written, so NULL clears it
+//
+//	(callers merge partial updates over the current profile)
 func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (UpdateUserProfileRow, error) {
 	row := q.db.QueryRow(ctx, updateUserProfile,
 		arg.ID,
@@ -393,7 +660,40 @@ func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfilePa
 		&i.ProfilePictureUrl,
 		&i.Bio,
 		&i.Location,
-		&i.UpdatedAt,
+		&i.JoinedAt,
 	)
 	return i, err
 }
+
+const updateUsername = `-- name: UpdateUsername :one
+UPDATE "user"
+SET
+    username = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, username, email
+`
+
+type UpdateUsernameParams struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+type UpdateUsernameRow struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+// ----------------------------------------------------------------------------
+// 11. UPDATE USERNAME
+// ----------------------------------------------------------------------------
+// Parameters: $1 = user_id, $2 = new_username (already normalized)
+// Returns: Updated user record
+// Usage: Username change; unique violations surface as 23505
+func (q *Queries) UpdateUsername(ctx context.Context, arg UpdateUsernameParams) (UpdateUsernameRow, error) {
+	row := q.db.QueryRow(ctx, updateUsername, arg.ID, arg.Username)
+	var i UpdateUsernameRow
+	err := row.Scan(&i.ID, &i.Username, &i.Email)
+	return i, err
+}
@@ -0,0 +1,188 @@
+package disposable
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/lease"
+	"brewd/internal/logger"
+)
+
+// Actions taken on registrations from a disposable email domain
+const (
+	ActionReject = "reject"
+	ActionFlag   = "flag"
+	ActionOff    = "off"
+)
+
+// Override actions admins set on a domain
+const (
+	OverrideAllow = "allow"
+	OverrideBlock = "block"
+)
+
+// builtin is the built-in blocklist, merged with the remote one
+//
+//go:embed domains.txt
+var builtin string
+
+// minRemoteDomains is the fewest domains a remote blocklist must have to
+// be used; anything smaller is taken to be a truncated or wrong download
+const minRemoteDomains = 100
+
+// maxListBytes caps the size of a downloaded blocklist
+const maxListBytes = 8 << 20
+
+// fetchTimeout bounds downloading the remote blocklist
+const fetchTimeout = time.Minute
+
+// SourceNone is the blocklist source URL that uses only the built-in list
+const SourceNone = "none"
+
+// Run refreshes the blocklist from the built-in list and sourceURL, unless
+// it is SourceNone, on startup and then every interval until ctx is
+// cancelled
+func Run(ctx context.Context, queries *db.Queries, sourceURL string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := refresh(ctx, queries, sourceURL); err != nil {
+			logger.Error("Failed to refresh disposable email domains", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh replaces the stored blocklist with the built-in list plus the
+// remote one. If the remote list can't be fetched, the stored list is kept
+// as it is, topped up with the built-in domains.
+func refresh(ctx context.Context, queries *db.Queries, sourceURL string) error {
+	domains, err := parse(strings.NewReader(builtin))
+	if err != nil {
+		return err
+	}
+
+	var fetchErr error
+	if sourceURL != SourceNone {
+		remote, err := fetch(ctx, sourceURL)
+		if err != nil {
+			fetchErr = fmt.Errorf("fetching %s: %w", sourceURL, err)
+		}
+		domains = append(domains, remote...)
+	}
+
+	if err := lease.Check(ctx); err != nil {
+		return err
+	}
+	if err := queries.ReplaceDisposableDomains(ctx, db.ReplaceDisposableDomainsParams{
+		Domains: domains,
+		Prune:   fetchErr == nil,
+	}); err != nil {
+		return err
+	}
+	if fetchErr != nil {
+		return fetchErr
+	}
+	logger.Info("Refreshed disposable email domains", "count", len(domains))
+	return nil
+}
+
+// fetch downloads a blocklist with one domain per line
+func fetch(ctx context.Context, sourceURL string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("blocklist source returned %s", resp.Status)
+	}
+
+	domains, err := parse(io.LimitReader(resp.Body, maxListBytes))
+	if err != nil {
+		return nil, err
+	}
+	if len(domains) < minRemoteDomains {
+		return nil, fmt.Errorf("blocklist has only %d domains", len(domains))
+	}
+	return domains, nil
+}
+
+// parse reads one domain per line, skipping blank lines, # comments and
+// anything that isn't a domain
+func parse(r io.Reader) ([]string, error) {
+	var domains []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if domain, ok := NormalizeDomain(line); ok {
+			domains = append(domains, domain)
+		}
+	}
+	return domains, scanner.Err()
+}
+
+// NormalizeDomain lowercases and trims a domain, reporting whether it
+// looks like one: dot-separated labels of letters, digits and hyphens
+func NormalizeDomain(domain string) (string, bool) {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	if len(domain) == 0 || len(domain) > 253 || !strings.Contains(domain, ".") {
+		return "", false
+	}
+	for _, label := range strings.Split(domain, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return "", false
+		}
+		for _, r := range label {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+				return "", false
+			}
+		}
+	}
+	return domain, true
+}
+
+// Blocked reports whether email's domain is disposable: blocked by the
+// most specific admin override covering it, or without one, listed on the
+// blocklist itself or through a parent domain
+func Blocked(ctx context.Context, queries *db.Queries, email string) (bool, error) {
+	_, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return false, nil
+	}
+	return queries.IsEmailDomainBlocked(ctx, candidates(domain))
+}
+
+// candidates is domain and each parent domain with at least two labels,
+// e.g. a.b.example.com, b.example.com and example.com
+func candidates(domain string) []string {
+	domain = strings.ToLower(domain)
+	list := []string{domain}
+	for {
+		_, parent, ok := strings.Cut(domain, ".")
+		if !ok || !strings.Contains(parent, ".") {
+			return list
+		}
+		list = append(list, parent)
+		domain = parent
+	}
+}
@@ -0,0 +1,152 @@
+// Package drain takes an instance out of rotation before it shuts down, so
+// rolling deploys don't drop requests. Draining flips readiness to false,
+// keeps serving for Delay while load balancers stop routing to the
+// instance, then shuts the servers down, giving in-flight requests up to
+// Timeout to finish.
+package drain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"brewd/internal/logger"
+)
+
+// Platforms with preset timings
+const (
+	PlatformNone       = "none"
+	PlatformKubernetes = "kubernetes"
+	PlatformECS        = "ecs"
+)
+
+// Timings are how long each phase of draining takes
+type Timings struct {
+	Delay   time.Duration // How long to keep serving, unready, before shutting down
+	Timeout time.Duration // How long in-flight requests get to finish
+}
+
+// platformTimings are the preset timings of each platform. Both presets fit
+// the platform's default 30 second grace before the process is killed.
+//
+// Kubernetes sends SIGTERM while endpoint removal is still reaching
+// kube-proxy and ingress controllers, so the instance keeps serving for a
+// few seconds first. ECS deregisters a task from its target groups and waits
+// out their deregistration delay before sending SIGTERM, so requests can be
+// drained at once.
+var platformTimings = map[string]Timings{
+	PlatformNone:       {Delay: 0, Timeout: 15 * time.Second},
+	PlatformKubernetes: {Delay: 5 * time.Second, Timeout: 20 * time.Second},
+	PlatformECS:        {Delay: 0, Timeout: 25 * time.Second},
+}
+
+// PlatformTimings returns the preset timings of platform
+func PlatformTimings(platform string) (Timings, error) {
+	timings, ok := platformTimings[platform]
+	if !ok {
+		return Timings{}, fmt.Errorf("unknown drain platform %q (want none, kubernetes or ecs)", platform)
+	}
+	return timings, nil
+}
+
+// Server is a server draining shuts down
+type Server interface {
+	// SetKeepAlivesEnabled(false) asks clients to reconnect, reaching an
+	// instance still in rotation
+	SetKeepAlivesEnabled(enabled bool)
+	// Shutdown stops accepting connections and waits for in-flight requests
+	Shutdown(ctx context.Context) error
+}
+
+// Coordinator tracks whether the instance is draining and runs the drain
+// once it starts
+type Coordinator struct {
+	timings  Timings
+	draining atomic.Bool
+	once     sync.Once
+	started  chan struct{}
+	stopping chan struct{}
+}
+
+func New(timings Timings) *Coordinator {
+	return &Coordinator{timings: timings, started: make(chan struct{}), stopping: make(chan struct{})}
+}
+
+// Timings returns the coordinator's timings
+func (c *Coordinator) Timings() Timings {
+	return c.timings
+}
+
+// Draining reports whether the instance is draining, and so not ready
+func (c *Coordinator) Draining() bool {
+	return c.draining.Load()
+}
+
+// Start starts draining, giving reason in the log. Later calls do nothing.
+func (c *Coordinator) Start(reason string) {
+	c.once.Do(func() {
+		logger.Info("Draining, readiness is now false", "reason", reason,
+			"delay", c.timings.Delay.String(), "timeout", c.timings.Timeout.String())
+		c.draining.Store(true)
+		close(c.started)
+	})
+}
+
+// Started is closed when draining starts
+func (c *Coordinator) Started() <-chan struct{} {
+	return c.started
+}
+
+// Stopping is closed when the servers start shutting down, after the
+// delay. Shutdown waits for responses to finish rather than interrupting
+// them, so long-lived ones such as event streams must end themselves once
+// it's closed; their clients reconnect to an instance still in rotation.
+func (c *Coordinator) Stopping() <-chan struct{} {
+	return c.stopping
+}
+
+// Notify starts draining when the process receives one of signals. A
+// second signal exits at once, for an operator who won't wait.
+func (c *Coordinator) Notify(signals ...os.Signal) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	go func() {
+		sig := <-ch
+		c.Start(sig.String())
+		sig = <-ch
+		logger.Warn("Exiting without finishing the drain", "signal", sig.String())
+		os.Exit(1)
+	}()
+}
+
+// Drain waits for draining to start, keeps serving for the delay, then
+// closes Stopping and shuts servers down together within the timeout
+func (c *Coordinator) Drain(servers ...Server) error {
+	<-c.started
+	for _, s := range servers {
+		s.SetKeepAlivesEnabled(false)
+	}
+	time.Sleep(c.timings.Delay)
+
+	logger.Info("Shutting down servers", "timeout", c.timings.Timeout.String())
+	close(c.stopping)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timings.Timeout)
+	defer cancel()
+
+	errs := make([]error, len(servers))
+	var wg sync.WaitGroup
+	for i, s := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = s.Shutdown(ctx)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,130 @@
+package errorsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"brewd/internal/logger"
+)
+
+// Report is an unexpected server error, such as a recovered panic, with
+// what's known of the request it broke
+type Report struct {
+	RequestID  string    `json:"request_id"`
+	Method     string    `json:"method"`
+	Route      string    `json:"route"`
+	Path       string    `json:"path"`
+	UserID     string    `json:"user_id,omitempty"`
+	Error      string    `json:"error"`
+	Stack      string    `json:"stack"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// Sink receives error reports. Report must not block the request that
+// failed. Implementations for error trackers plug in here.
+type Sink interface {
+	Report(report Report)
+}
+
+// Sink names accepted by NewSink
+const (
+	SinkWebhook = "webhook"
+	SinkNone    = "none"
+)
+
+// NewSink returns the sink called name. The webhook sink posts reports to
+// target.
+func NewSink(name, target string) (Sink, error) {
+	switch name {
+	case SinkWebhook:
+		u, err := url.Parse(target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("webhook error sink needs an http(s) URL, got %q", target)
+		}
+		return NewWebhookSink(u.String()), nil
+	case SinkNone:
+		return Discard{}, nil
+	default:
+		return nil, fmt.Errorf("unknown error sink %q", name)
+	}
+}
+
+// Discard drops reports; they are still logged where they were caught
+type Discard struct{}
+
+func (Discard) Report(Report) {}
+
+// requestTimeout bounds a single report's delivery
+const requestTimeout = 5 * time.Second
+
+// maxInFlight bounds the reports being delivered at once; reports past it
+// are dropped, so a burst of failures can't pile up goroutines
+const maxInFlight = 8
+
+// WebhookSink posts each report as JSON to a URL, in the background
+type WebhookSink struct {
+	url      string
+	client   *http.Client
+	inFlight chan struct{}
+}
+
+// NewWebhookSink returns a sink posting reports to target
+func NewWebhookSink(target string) *WebhookSink {
+	return &WebhookSink{
+		url:      target,
+		client:   &http.Client{Timeout: requestTimeout},
+		inFlight: make(chan struct{}, maxInFlight),
+	}
+}
+
+// Report posts report in the background, dropping it when too many are
+// being delivered
+func (s *WebhookSink) Report(report Report) {
+	select {
+	case s.inFlight <- struct{}{}:
+	default:
+		logger.Warn("Dropped error report", "request_id", report.RequestID)
+		return
+	}
+
+	go func() {
+		defer func() { <-s.inFlight }()
+		if err := s.post(report); err != nil {
+			logger.Warn("Failed to send error report", "request_id", report.RequestID, "error", err)
+		}
+	}()
+}
+
+// post delivers report
+func (s *WebhookSink) post(report Report) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "brewd-errors/1")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("error sink answered %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"brewd/internal/logger"
+)
+
+// Topics of the domain events the outbox records. Triggers write them (see
+// db/schema/triggers.sql); these name them for consumers.
+const (
+	TopicUserRegistered = "user.registered"
+	TopicBrewCreated    = "brew.created"
+	TopicRecipeForked   = "recipe.forked"
+)
+
+// Event is a domain event as published. ID increases with each event, so
+// consumers can order events and drop the duplicates at-least-once
+// delivery brings. Data holds the changed row's public columns.
+type Event struct {
+	ID          int64           `json:"id"`
+	Topic       string          `json:"topic"`
+	AggregateID string          `json:"aggregate_id"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+	Data        json.RawMessage `json:"data"`
+}
+
+// Publisher sends batches of events to a broker. Publish returns once the
+// broker has accepted the whole batch; a failed batch is retried as a
+// whole. Implementations for other brokers (e.g. Kafka) plug in here.
+type Publisher interface {
+	Publish(ctx context.Context, events []Event) error
+	Close() error
+}
+
+// Pinger is implemented by publishers that can check their broker is
+// reachable, for health checks
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Publisher names accepted by NewPublisher
+const (
+	PublisherNATS = "nats"
+	PublisherLog  = "log"
+	PublisherNone = "none"
+)
+
+// NewPublisher returns the publisher called name. natsURL is only used by
+// the NATS publisher.
+func NewPublisher(name, natsURL string) (Publisher, error) {
+	switch name {
+	case PublisherNATS:
+		return NewNATSPublisher(natsURL)
+	case PublisherLog:
+		return LogPublisher{}, nil
+	case PublisherNone:
+		return Discard{}, nil
+	default:
+		return nil, fmt.Errorf("unknown event publisher %q", name)
+	}
+}
+
+// LogPublisher writes events to the application log, for development or
+// for a log pipeline to collect
+type LogPublisher struct{}
+
+func (LogPublisher) Publish(_ context.Context, events []Event) error {
+	for _, e := range events {
+		logger.Info("Domain event",
+			"event_id", e.ID,
+			"topic", e.Topic,
+			"aggregate_id", e.AggregateID,
+			"occurred_at", e.OccurredAt,
+			"data", string(e.Data),
+		)
+	}
+	return nil
+}
+
+func (LogPublisher) Close() error {
+	return nil
+}
+
+// Discard drops events, for deployments without consumers. The outbox is
+// still drained, so it doesn't grow.
+type Discard struct{}
+
+func (Discard) Publish(context.Context, []Event) error {
+	return nil
+}
+
+func (Discard) Close() error {
+	return nil
+}
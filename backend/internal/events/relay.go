@@ -0,0 +1,117 @@
+package events
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/logger"
+)
+
+// batchSize bounds how many events a single claim publishes
+const batchSize = 100
+
+// Relay limits: claimed events are leased for leaseSeconds, failed batches
+// are retried after attempts² × 5 seconds up to maxRetrySeconds, and
+// published events are kept for retentionDays
+const (
+	leaseSeconds    = 60
+	maxRetrySeconds = 300
+	retentionDays   = 7
+)
+
+// trimInterval is how often published events past retention are deleted
+const trimInterval = time.Hour
+
+// Run publishes outbox events to publisher every interval until ctx is
+// cancelled. Events are delivered at least once: a batch the broker
+// accepted but the outbox failed to mark is published again.
+func Run(ctx context.Context, queries *db.Queries, publisher Publisher, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var trimmedAt time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := relayDue(ctx, queries, publisher); err != nil {
+				logger.Error("Failed to relay domain events", "error", err)
+			}
+			if time.Since(trimmedAt) >= trimInterval {
+				trim(ctx, queries)
+				trimmedAt = time.Now()
+			}
+		}
+	}
+}
+
+// relayDue claims due events in batches, oldest first, and publishes each
+// batch. A batch that fails is rescheduled whole, with backoff.
+func relayDue(ctx context.Context, queries *db.Queries, publisher Publisher) error {
+	for {
+		rows, err := queries.ClaimDomainEvents(ctx, db.ClaimDomainEventsParams{
+			LeaseSeconds: leaseSeconds,
+			RowLimit:     batchSize,
+		})
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		batch := make([]Event, 0, len(rows))
+		ids := make([]int64, 0, len(rows))
+		var attempts int32
+		for _, r := range rows {
+			batch = append(batch, Event{
+				ID:          r.ID,
+				Topic:       r.Topic,
+				AggregateID: r.AggregateID,
+				OccurredAt:  r.CreatedAt,
+				Data:        r.Payload,
+			})
+			ids = append(ids, r.ID)
+			attempts = max(attempts, r.Attempts)
+		}
+		slices.SortFunc(batch, func(a, b Event) int { return cmp.Compare(a.ID, b.ID) })
+
+		if err := publisher.Publish(ctx, batch); err != nil {
+			logger.Warn("Failed to publish domain events", "count", len(batch), "first_id", batch[0].ID,
+				"attempts", attempts, "error", err)
+			if err := queries.RetryDomainEvents(ctx, db.RetryDomainEventsParams{
+				Error:          err.Error(),
+				RetryInSeconds: min(attempts*attempts*5, maxRetrySeconds),
+				Ids:            ids,
+			}); err != nil {
+				return err
+			}
+			// The broker is likely down; later batches wait for the next run
+			return nil
+		}
+
+		if err := queries.MarkDomainEventsPublished(ctx, ids); err != nil {
+			return err
+		}
+
+		if len(rows) < batchSize {
+			return nil
+		}
+	}
+}
+
+// trim deletes events published longer ago than the retention period
+func trim(ctx context.Context, queries *db.Queries) {
+	n, err := queries.DeletePublishedDomainEvents(ctx, retentionDays)
+	if err != nil {
+		logger.Error("Failed to trim published domain events", "error", err)
+		return
+	}
+	if n > 0 {
+		logger.Info("Trimmed published domain events", "count", n)
+	}
+}
@@ -0,0 +1,95 @@
+package fieldset
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Allowlist is the set of fields, by JSON name, that may be selected from
+// a response type
+type Allowlist map[string]bool
+
+// Of returns the top-level JSON fields of struct type T, following
+// embedded structs the way encoding/json does
+func Of[T any]() Allowlist {
+	allowed := Allowlist{}
+	addFields(allowed, reflect.TypeFor[T]())
+	return allowed
+}
+
+func addFields(allowed Allowlist, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		switch {
+		case tag == "-" || !f.IsExported() && !f.Anonymous:
+		case f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct:
+			addFields(allowed, f.Type)
+		case tag != "":
+			allowed[tag] = true
+		default:
+			allowed[f.Name] = true
+		}
+	}
+}
+
+// UnknownFieldError is returned when a selection names a field outside the
+// allowlist
+type UnknownFieldError struct {
+	Field string
+}
+
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("fieldset: unknown field %q", e.Field)
+}
+
+// Parse reads a comma-separated selection such as "id,brew_method,rating"
+// against allowed. Blank entries and repeats are ignored; an empty
+// selection returns nil, meaning every field.
+func Parse(selection string, allowed Allowlist) ([]string, error) {
+	var fields []string
+	seen := map[string]bool{}
+	for _, field := range strings.Split(selection, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" || seen[field] {
+			continue
+		}
+		if !allowed[field] {
+			return nil, &UnknownFieldError{Field: field}
+		}
+		seen[field] = true
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// Select projects each item onto fields, plus "id" so clients can still
+// key the results. With no fields the items are returned as they are.
+func Select[T any](items []T, fields []string) any {
+	if len(fields) == 0 {
+		return items
+	}
+
+	selected := make([]map[string]json.RawMessage, 0, len(items))
+	for _, item := range items {
+		// Response types are plain data, so they always encode
+		encoded, _ := json.Marshal(item)
+		var all map[string]json.RawMessage
+		_ = json.Unmarshal(encoded, &all)
+
+		projected := make(map[string]json.RawMessage, len(fields)+1)
+		if id, ok := all["id"]; ok {
+			projected["id"] = id
+		}
+		for _, field := range fields {
+			// Fields with omitempty may be absent from an item
+			if value, ok := all[field]; ok {
+				projected[field] = value
+			}
+		}
+		selected = append(selected, projected)
+	}
+	return selected
+}
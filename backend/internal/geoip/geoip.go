@@ -0,0 +1,89 @@
+package geoip
+
+import (
+	"net/netip"
+
+	"brewd/internal/logger"
+)
+
+// Location is where an IP address is, coarsely: country, first-level
+// region and city, as far as the database knows. Fields it doesn't know
+// are empty.
+type Location struct {
+	Country string `json:"country"`
+	Region  string `json:"region"`
+	City    string `json:"city"`
+}
+
+// Locator resolves IP addresses to coarse locations
+type Locator interface {
+	Locate(ip string) (Location, bool)
+}
+
+// Open returns a locator reading the MaxMind DB at path (GeoLite2 or
+// GeoIP2, City or Country), or one that locates nothing without a path
+func Open(path string) (Locator, error) {
+	if path == "" {
+		return Disabled{}, nil
+	}
+	db, err := openMMDB(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Database{db: db}, nil
+}
+
+// Database locates addresses with a MaxMind DB loaded into memory
+type Database struct {
+	db *mmdb
+}
+
+// Locate looks ip up, reporting false for private and unknown addresses
+func (d *Database) Locate(ip string) (Location, bool) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil || addr.IsPrivate() || addr.IsLoopback() {
+		return Location{}, false
+	}
+	record, err := d.db.lookup(addr)
+	if err != nil {
+		logger.Warn("GeoIP lookup failed", "error", err)
+		return Location{}, false
+	}
+	if record == nil {
+		return Location{}, false
+	}
+
+	loc := Location{
+		Country: str(record, "country", "iso_code"),
+		City:    str(record, "city", "names", "en"),
+	}
+	if subdivisions, ok := record["subdivisions"].([]any); ok && len(subdivisions) > 0 {
+		if first, ok := subdivisions[0].(map[string]any); ok {
+			loc.Region = str(first, "names", "en")
+		}
+	}
+	return loc, loc.Country != ""
+}
+
+// str follows path through nested maps to a string, or returns ""
+func str(m map[string]any, path ...string) string {
+	for i, key := range path {
+		if i == len(path)-1 {
+			s, _ := m[key].(string)
+			return s
+		}
+		next, ok := m[key].(map[string]any)
+		if !ok {
+			return ""
+		}
+		m = next
+	}
+	return ""
+}
+
+// Disabled locates nothing, for deployments without a GeoIP database
+type Disabled struct{}
+
+func (Disabled) Locate(string) (Location, bool) {
+	return Location{}, false
+}
@@ -0,0 +1,294 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+	"os"
+)
+
+// metadataMarker precedes the metadata at the end of a MaxMind DB file
+var metadataMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+// dataSeparatorSize is the run of zero bytes between the search tree and
+// the data section
+const dataSeparatorSize = 16
+
+// maxPointerDepth bounds how many pointers decoding follows in a row, so a
+// corrupt file can't loop forever
+const maxPointerDepth = 32
+
+// Data section field types (MaxMind DB format, version 2)
+const (
+	typeExtended = 0
+	typePointer  = 1
+	typeString   = 2
+	typeDouble   = 3
+	typeBytes    = 4
+	typeUint16   = 5
+	typeUint32   = 6
+	typeMap      = 7
+	typeInt32    = 8
+	typeUint64   = 9
+	typeUint128  = 10
+	typeArray    = 11
+	typeBoolean  = 14
+	typeFloat    = 15
+)
+
+// mmdb reads a MaxMind DB file (e.g. GeoLite2-City.mmdb) held in memory.
+// It supports what lookups need: walking the search tree and decoding a
+// record into maps, slices and scalars.
+type mmdb struct {
+	buf        []byte
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	data       []byte
+	ipv4Start  uint
+}
+
+// openMMDB reads and validates the database at path
+func openMMDB(path string) (*mmdb, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	i := bytes.LastIndex(buf, metadataMarker)
+	if i < 0 {
+		return nil, errors.New("geoip: not a MaxMind DB file")
+	}
+	metaStart := i + len(metadataMarker)
+	raw, _, err := decode(buf[metaStart:], 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("geoip: reading metadata: %w", err)
+	}
+	meta, ok := raw.(map[string]any)
+	if !ok {
+		return nil, errors.New("geoip: metadata is not a map")
+	}
+
+	db := &mmdb{buf: buf}
+	for key, dst := range map[string]*uint{"node_count": &db.nodeCount, "record_size": &db.recordSize, "ip_version": &db.ipVersion} {
+		v, ok := meta[key].(uint64)
+		if !ok {
+			return nil, fmt.Errorf("geoip: metadata has no %s", key)
+		}
+		*dst = uint(v)
+	}
+	if db.recordSize != 24 && db.recordSize != 28 && db.recordSize != 32 {
+		return nil, fmt.Errorf("geoip: unsupported record size %d", db.recordSize)
+	}
+	treeSize := db.nodeCount * db.recordSize / 4
+	if treeSize+dataSeparatorSize > uint(i) {
+		return nil, errors.New("geoip: search tree exceeds file")
+	}
+	db.data = buf[treeSize+dataSeparatorSize : i]
+
+	// IPv4 addresses live under ::/96 in an IPv6 tree
+	if db.ipVersion == 6 {
+		node := uint(0)
+		for range 96 {
+			if node >= db.nodeCount {
+				break
+			}
+			node = db.record(node, 0)
+		}
+		db.ipv4Start = node
+	}
+	return db, nil
+}
+
+// lookup returns the record for addr, or nil if the database has none
+func (db *mmdb) lookup(addr netip.Addr) (map[string]any, error) {
+	addr = addr.Unmap()
+	node := uint(0)
+	bitCount := 128
+	if addr.Is4() {
+		if db.ipVersion == 6 {
+			node = db.ipv4Start
+		}
+		bitCount = 32
+	} else if db.ipVersion == 4 {
+		return nil, nil
+	}
+
+	ip := addr.AsSlice()
+	for i := 0; i < bitCount && node < db.nodeCount; i++ {
+		bit := uint(ip[i/8]>>(7-i%8)) & 1
+		node = db.record(node, bit)
+	}
+	if node == db.nodeCount {
+		return nil, nil
+	}
+	if node < db.nodeCount {
+		return nil, errors.New("geoip: search tree ended on a node")
+	}
+
+	offset := node - db.nodeCount - dataSeparatorSize
+	if offset >= uint(len(db.data)) {
+		return nil, errors.New("geoip: record points outside the data section")
+	}
+	raw, _, err := decode(db.data, offset, 0)
+	if err != nil {
+		return nil, err
+	}
+	record, _ := raw.(map[string]any)
+	return record, nil
+}
+
+// record returns the left (bit 0) or right (bit 1) record of node
+func (db *mmdb) record(node, bit uint) uint {
+	b := db.buf[node*db.recordSize/4:]
+	switch db.recordSize {
+	case 24:
+		b = b[bit*3:]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+	case 28:
+		if bit == 0 {
+			return uint(b[3]&0xF0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3]&0x0F)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default:
+		return uint(binary.BigEndian.Uint32(b[bit*4:]))
+	}
+}
+
+var errCorrupt = errors.New("geoip: corrupt data section")
+
+// decode decodes the field at offset in section, returning it and the
+// offset after it. Pointers are offsets into section.
+func decode(section []byte, offset uint, depth int) (any, uint, error) {
+	if offset >= uint(len(section)) {
+		return nil, 0, errCorrupt
+	}
+	ctrl := section[offset]
+	offset++
+	typ := uint(ctrl >> 5)
+
+	if typ == typePointer {
+		if depth >= maxPointerDepth {
+			return nil, 0, errCorrupt
+		}
+		size := uint(ctrl>>3) & 0x3
+		if offset+size+1 > uint(len(section)) {
+			return nil, 0, errCorrupt
+		}
+		b := section[offset : offset+size+1]
+		var target uint
+		switch size {
+		case 0:
+			target = uint(ctrl&0x7)<<8 | uint(b[0])
+		case 1:
+			target = (uint(ctrl&0x7)<<16 | uint(b[0])<<8 | uint(b[1])) + 2048
+		case 2:
+			target = (uint(ctrl&0x7)<<24 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])) + 526336
+		default:
+			target = uint(binary.BigEndian.Uint32(b))
+		}
+		value, _, err := decode(section, target, depth+1)
+		return value, offset + size + 1, err
+	}
+
+	if typ == typeExtended {
+		if offset >= uint(len(section)) {
+			return nil, 0, errCorrupt
+		}
+		typ = 7 + uint(section[offset])
+		offset++
+	}
+
+	size := uint(ctrl & 0x1f)
+	if size >= 29 {
+		n := size - 28
+		if offset+n > uint(len(section)) {
+			return nil, 0, errCorrupt
+		}
+		extra := uint(0)
+		for _, c := range section[offset : offset+n] {
+			extra = extra<<8 | uint(c)
+		}
+		size = []uint{29, 285, 65821}[n-1] + extra
+		offset += n
+	}
+
+	switch typ {
+	case typeMap:
+		m := make(map[string]any, size)
+		for range size {
+			key, next, err := decode(section, offset, depth)
+			if err != nil {
+				return nil, 0, err
+			}
+			k, ok := key.(string)
+			if !ok {
+				return nil, 0, errCorrupt
+			}
+			value, next, err := decode(section, next, depth)
+			if err != nil {
+				return nil, 0, err
+			}
+			m[k] = value
+			offset = next
+		}
+		return m, offset, nil
+	case typeArray:
+		a := make([]any, 0, size)
+		for range size {
+			value, next, err := decode(section, offset, depth)
+			if err != nil {
+				return nil, 0, err
+			}
+			a = append(a, value)
+			offset = next
+		}
+		return a, offset, nil
+	case typeBoolean:
+		return size != 0, offset, nil
+	}
+
+	if offset+size > uint(len(section)) {
+		return nil, 0, errCorrupt
+	}
+	b := section[offset : offset+size]
+	offset += size
+	switch typ {
+	case typeString:
+		return string(b), offset, nil
+	case typeBytes, typeUint128:
+		return b, offset, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, errCorrupt
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), offset, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, errCorrupt
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), offset, nil
+	case typeUint16, typeUint32, typeUint64:
+		if size > 8 {
+			return nil, 0, errCorrupt
+		}
+		n := uint64(0)
+		for _, c := range b {
+			n = n<<8 | uint64(c)
+		}
+		return n, offset, nil
+	case typeInt32:
+		if size > 4 {
+			return nil, 0, errCorrupt
+		}
+		n := uint32(0)
+		for _, c := range b {
+			n = n<<8 | uint32(c)
+		}
+		return int64(int32(n)), offset, nil
+	default:
+		return nil, 0, fmt.Errorf("geoip: unsupported field type %d", typ)
+	}
+}
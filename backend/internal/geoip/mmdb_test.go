@@ -0,0 +1,420 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// mmdbWriter encodes values into a MaxMind DB data section as the format's
+// specification lays them out. Strings written before are written again as
+// pointers to the first copy, as MaxMind's own writer does.
+type mmdbWriter struct {
+	buf     bytes.Buffer
+	strings map[string]int
+}
+
+// control writes the control byte, and any extended type and size bytes,
+// of a field of typ holding size bytes or entries
+func (w *mmdbWriter) control(typ, size int) {
+	var ctrl byte
+	if typ <= 7 {
+		ctrl = byte(typ) << 5
+	}
+	var extra []byte
+	switch {
+	case size < 29:
+		ctrl |= byte(size)
+	case size < 285:
+		ctrl |= 29
+		extra = []byte{byte(size - 29)}
+	case size < 65821:
+		ctrl |= 30
+		extra = binary.BigEndian.AppendUint16(nil, uint16(size-285))
+	default:
+		ctrl |= 31
+		n := size - 65821
+		extra = []byte{byte(n >> 16), byte(n >> 8), byte(n)}
+	}
+	w.buf.WriteByte(ctrl)
+	if typ > 7 {
+		w.buf.WriteByte(byte(typ - 7))
+	}
+	w.buf.Write(extra)
+}
+
+// write encodes v, returning its offset in the section
+func (w *mmdbWriter) write(v any) int {
+	offset := w.buf.Len()
+	switch v := v.(type) {
+	case string:
+		if target, ok := w.strings[v]; ok && target < 2048 {
+			w.buf.Write([]byte{typePointer<<5 | byte(target>>8), byte(target)})
+			return offset
+		}
+		if w.strings == nil {
+			w.strings = make(map[string]int)
+		}
+		w.strings[v] = offset
+		w.control(typeString, len(v))
+		w.buf.WriteString(v)
+	case map[string]any:
+		w.control(typeMap, len(v))
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			w.write(key)
+			w.write(v[key])
+		}
+	case []any:
+		w.control(typeArray, len(v))
+		for _, item := range v {
+			w.write(item)
+		}
+	case uint64:
+		b := binary.BigEndian.AppendUint64(nil, v)
+		for len(b) > 0 && b[0] == 0 {
+			b = b[1:]
+		}
+		typ := typeUint64
+		if len(b) <= 2 {
+			typ = typeUint16
+		} else if len(b) <= 4 {
+			typ = typeUint32
+		}
+		w.control(typ, len(b))
+		w.buf.Write(b)
+	case int64:
+		w.control(typeInt32, 4)
+		w.buf.Write(binary.BigEndian.AppendUint32(nil, uint32(int32(v))))
+	case float64:
+		w.control(typeDouble, 8)
+		w.buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(v)))
+	case bool:
+		size := 0
+		if v {
+			size = 1
+		}
+		w.control(typeBoolean, size)
+	default:
+		panic("mmdbWriter: unsupported value")
+	}
+	return offset
+}
+
+// fixtureNetwork is a network in a fixture database and its record
+type fixtureNetwork struct {
+	prefix string
+	record map[string]any
+}
+
+// writeFixture writes a MaxMind DB holding networks to a temporary file.
+// An IPv6 database keeps IPv4 networks under ::/96.
+func writeFixture(t *testing.T, ipVersion, recordSize int, networks []fixtureNetwork) string {
+	t.Helper()
+	const (
+		empty = -1
+		data  = -2
+	)
+	type node struct{ records, data [2]int }
+	nodes := []node{{records: [2]int{empty, empty}}}
+
+	var section mmdbWriter
+	for _, network := range networks {
+		prefix := netip.MustParsePrefix(network.prefix)
+		ip := prefix.Addr().AsSlice()
+		bits := prefix.Bits()
+		if ipVersion == 6 && prefix.Addr().Is4() {
+			ip = append(make([]byte, 12), ip...)
+			bits += 96
+		}
+
+		offset := section.write(network.record)
+		n := 0
+		for i := range bits {
+			bit := int(ip[i/8]>>(7-i%8)) & 1
+			if i == bits-1 {
+				nodes[n].records[bit] = data
+				nodes[n].data[bit] = offset
+				break
+			}
+			if nodes[n].records[bit] < 0 {
+				nodes = append(nodes, node{records: [2]int{empty, empty}})
+				nodes[n].records[bit] = len(nodes) - 1
+			}
+			n = nodes[n].records[bit]
+		}
+	}
+
+	var file bytes.Buffer
+	for _, n := range nodes {
+		var values [2]uint32
+		for bit, record := range n.records {
+			switch record {
+			case empty:
+				values[bit] = uint32(len(nodes))
+			case data:
+				values[bit] = uint32(len(nodes) + dataSeparatorSize + n.data[bit])
+			default:
+				values[bit] = uint32(record)
+			}
+		}
+		switch recordSize {
+		case 24:
+			for _, v := range values {
+				file.Write([]byte{byte(v >> 16), byte(v >> 8), byte(v)})
+			}
+		case 28:
+			left, right := values[0], values[1]
+			file.Write([]byte{byte(left >> 16), byte(left >> 8), byte(left),
+				byte(left>>24)<<4 | byte(right>>24),
+				byte(right >> 16), byte(right >> 8), byte(right)})
+		default:
+			file.Write(binary.BigEndian.AppendUint32(nil, values[0]))
+			file.Write(binary.BigEndian.AppendUint32(nil, values[1]))
+		}
+	}
+	file.Write(make([]byte, dataSeparatorSize))
+	file.Write(section.buf.Bytes())
+
+	var meta mmdbWriter
+	meta.write(map[string]any{
+		"binary_format_major_version": uint64(2),
+		"binary_format_minor_version": uint64(0),
+		"build_epoch":                 uint64(1760659200),
+		"database_type":               "brewd-Test-City",
+		"description":                 map[string]any{"en": "brewd test fixture"},
+		"ip_version":                  uint64(ipVersion),
+		"languages":                   []any{"en"},
+		"node_count":                  uint64(len(nodes)),
+		"record_size":                 uint64(recordSize),
+	})
+	file.Write(metadataMarker)
+	file.Write(meta.buf.Bytes())
+
+	path := filepath.Join(t.TempDir(), "fixture.mmdb")
+	if err := os.WriteFile(path, file.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// cityRecord is a GeoIP2 City record
+func cityRecord(country, region, city string) map[string]any {
+	return map[string]any{
+		"city":      map[string]any{"geoname_id": uint64(2643743), "names": map[string]any{"en": city, "de": city}},
+		"continent": map[string]any{"code": "EU", "names": map[string]any{"en": "Europe"}},
+		"country":   map[string]any{"iso_code": country, "is_in_european_union": false},
+		"location": map[string]any{
+			"latitude":        51.5142,
+			"longitude":       -0.0931,
+			"accuracy_radius": uint64(100),
+			"metro_code":      int64(-1),
+		},
+		"subdivisions": []any{
+			map[string]any{"iso_code": "ENG", "names": map[string]any{"en": region}},
+		},
+	}
+}
+
+var fixtureNetworks = []fixtureNetwork{
+	{"81.2.69.0/24", cityRecord("GB", "England", "London")},
+	{"89.160.20.128/25", cityRecord("SE", "Östergötland County", "Linköping")},
+	{"2001:db8::/32", cityRecord("US", "Washington", "Seattle")},
+	// Only a country, as in GeoLite2 Country
+	{"2a02:cf40::/29", map[string]any{"country": map[string]any{"iso_code": "NO"}}},
+}
+
+func TestLocate(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want Location
+		ok   bool
+	}{
+		{"81.2.69.142", Location{"GB", "England", "London"}, true},
+		{"81.2.69.0", Location{"GB", "England", "London"}, true},
+		{"::ffff:81.2.69.142", Location{"GB", "England", "London"}, true},
+		{"89.160.20.200", Location{"SE", "Östergötland County", "Linköping"}, true},
+		{"89.160.20.127", Location{}, false},
+		{"2001:db8:1::1", Location{"US", "Washington", "Seattle"}, true},
+		{"2a02:cf47::1", Location{Country: "NO"}, true},
+		{"8.8.8.8", Location{}, false},
+		{"10.0.0.1", Location{}, false},
+		{"::1", Location{}, false},
+		{"not an ip", Location{}, false},
+	}
+
+	for _, recordSize := range []int{24, 28, 32} {
+		locator, err := Open(writeFixture(t, 6, recordSize, fixtureNetworks))
+		if err != nil {
+			t.Fatalf("record size %d: %v", recordSize, err)
+		}
+		for _, tt := range tests {
+			got, ok := locator.Locate(tt.ip)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("record size %d: Locate(%q) = %+v, %v, want %+v, %v", recordSize, tt.ip, got, ok, tt.want, tt.ok)
+			}
+		}
+	}
+}
+
+func TestLocateIPv4Database(t *testing.T) {
+	locator, err := Open(writeFixture(t, 4, 24, fixtureNetworks[:2]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := locator.Locate("81.2.69.142"); !ok || got.City != "London" {
+		t.Errorf("Locate(81.2.69.142) = %+v, %v, want London", got, ok)
+	}
+	if got, ok := locator.Locate("2001:db8::1"); ok {
+		t.Errorf("Locate(2001:db8::1) = %+v in an IPv4 database", got)
+	}
+}
+
+func TestOpenMMDBRejects(t *testing.T) {
+	dir := t.TempDir()
+	notMMDB := filepath.Join(dir, "not.mmdb")
+	if err := os.WriteFile(notMMDB, []byte("just some bytes"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// A record size the format doesn't define
+	var meta mmdbWriter
+	meta.write(map[string]any{"node_count": uint64(1), "record_size": uint64(20), "ip_version": uint64(6)})
+	badRecordSize := filepath.Join(dir, "bad.mmdb")
+	file := append(make([]byte, 5+dataSeparatorSize), metadataMarker...)
+	if err := os.WriteFile(badRecordSize, append(file, meta.buf.Bytes()...), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// A tree bigger than the file
+	meta = mmdbWriter{}
+	meta.write(map[string]any{"node_count": uint64(1000), "record_size": uint64(24), "ip_version": uint64(6)})
+	truncated := filepath.Join(dir, "truncated.mmdb")
+	if err := os.WriteFile(truncated, append(append([]byte{}, metadataMarker...), meta.buf.Bytes()...), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string]string{
+		notMMDB:       "not a MaxMind DB file",
+		badRecordSize: "unsupported record size 20",
+		truncated:     "search tree exceeds file",
+	} {
+		if _, err := openMMDB(path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: error %v, want one mentioning %q", filepath.Base(path), err, want)
+		}
+	}
+}
+
+func TestRecord28(t *testing.T) {
+	// The middle byte holds the top nibble of each record
+	db := &mmdb{buf: []byte{0xBC, 0xDE, 0xF1, 0xA1, 0x23, 0x45, 0x67}, recordSize: 28}
+	if got := db.record(0, 0); got != 0xABCDEF1 {
+		t.Errorf("left record %#x, want 0xabcdef1", got)
+	}
+	if got := db.record(0, 1); got != 0x1234567 {
+		t.Errorf("right record %#x, want 0x1234567", got)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		section []byte
+		want    any
+	}{
+		{"uint16", []byte{0xA2, 0x12, 0x34}, uint64(0x1234)},
+		{"uint32", []byte{0xC4, 0xFF, 0xFF, 0xFF, 0xFF}, uint64(math.MaxUint32)},
+		{"uint64", []byte{0x08, 0x02, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, uint64(1 << 56)},
+		{"int32", []byte{0x04, 0x01, 0xFF, 0xFF, 0xFF, 0xFE}, int64(-2)},
+		{"double", []byte{0x68, 0x3F, 0xF8, 0, 0, 0, 0, 0, 0}, 1.5},
+		{"float", []byte{0x04, 0x08, 0x3F, 0xC0, 0x00, 0x00}, 1.5},
+		{"true", []byte{0x01, 0x07}, true},
+		{"false", []byte{0x00, 0x07}, false},
+		{"empty string", []byte{0x40}, ""},
+		{"string", []byte{0x43, 'a', 'b', 'c'}, "abc"},
+		{"29-byte string", append([]byte{0x5D, 0x00}, strings.Repeat("x", 29)...), strings.Repeat("x", 29)},
+		{"300-byte string", append([]byte{0x5E, 0x00, 0x0F}, strings.Repeat("x", 300)...), strings.Repeat("x", 300)},
+		{"array", []byte{0x02, 0x04, 0x41, 'a', 0xA1, 0x07}, []any{"a", uint64(7)}},
+	}
+	for _, tt := range tests {
+		got, next, err := decode(tt.section, 0, 0)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !equal(got, tt.want) {
+			t.Errorf("%s: decoded %#v, want %#v", tt.name, got, tt.want)
+		}
+		if next != uint(len(tt.section)) {
+			t.Errorf("%s: next offset %d, want %d", tt.name, next, len(tt.section))
+		}
+	}
+}
+
+func TestDecodePointers(t *testing.T) {
+	// Each pointer size reaches a string at an offset only it can
+	section := make([]byte, 600000)
+	put := func(offset int, s string) {
+		section[offset] = 0x40 | byte(len(s))
+		copy(section[offset+1:], s)
+	}
+	put(100, "size 0")
+	put(2048+300, "size 1")
+	put(526336+70000, "size 2")
+	put(599000, "size 3")
+	pointers := map[string][]byte{
+		"size 0": {0x20, 100},
+		"size 1": {0x28, 0x01, 0x2C},
+		"size 2": {0x30, 0x01, 0x11, 0x70},
+		"size 3": binary.BigEndian.AppendUint32([]byte{0x38}, 599000),
+	}
+	for want, pointer := range pointers {
+		copy(section, pointer)
+		got, next, err := decode(section, 0, 0)
+		if err != nil || got != want {
+			t.Errorf("pointer of %s: decoded %#v, %v, want %q", want, got, err, want)
+		}
+		if next != uint(len(pointer)) {
+			t.Errorf("pointer of %s: next offset %d, want %d after the pointer", want, next, len(pointer))
+		}
+	}
+}
+
+func TestDecodeCorrupt(t *testing.T) {
+	tests := []struct {
+		name    string
+		section []byte
+	}{
+		{"empty", nil},
+		{"truncated string", []byte{0x45, 'a', 'b'}},
+		{"pointer to itself", []byte{0x20, 0x00}},
+		{"pointer past the end", []byte{0x20, 0x10}},
+		{"non-string map key", []byte{0xE1, 0xA1, 0x01, 0x40}},
+		{"long double", []byte{0x69, 0, 0, 0, 0, 0, 0, 0, 0, 0}},
+		{"truncated size", []byte{0x5E, 0x00}},
+	}
+	for _, tt := range tests {
+		if _, _, err := decode(tt.section, 0, 0); !errors.Is(err, errCorrupt) {
+			t.Errorf("%s: error %v, want errCorrupt", tt.name, err)
+		}
+	}
+}
+
+// equal compares decoded values, whose slices == can't
+func equal(a, b any) bool {
+	as, ok := a.([]any)
+	if !ok {
+		return a == b
+	}
+	bs, ok := b.([]any)
+	return ok && slices.EqualFunc(as, bs, equal)
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"net/http"
+	"time"
+
+	"brewd/internal/apikeys"
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oklog/ulid/v2"
+)
+
+// APIKeyRequest represents the API key creation payload
+type APIKeyRequest struct {
+	Name   string   `json:"name" binding:"required,max=100"`
+	Scopes []string `json:"scopes" binding:"required,min=1,dive,required"`
+}
+
+// APIKeyResponse is an API key. Key is only set when the key is created.
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Key        string     `json:"key,omitempty"`
+	KeyPrefix  string     `json:"key_prefix"`
+	Scopes     []string   `json:"scopes"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreateAPIKey issues an API key for a companion app or device bridge. The
+// key is returned once; only its hash is kept.
+func CreateAPIKey(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req APIKeyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		for _, scope := range req.Scopes {
+			if !apikeys.ValidScope(scope) {
+				respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidRequest)
+				return
+			}
+		}
+
+		key, prefix, hash, err := apikeys.New()
+		if err != nil {
+			logger.Error("Failed to generate API key", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeAPIKeyUpdateFailed)
+			return
+		}
+
+		apiKey, err := queries.CreateAPIKey(c.Request.Context(), db.CreateAPIKeyParams{
+			ID:        ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+			UserID:    c.GetString("user_id"),
+			Name:      req.Name,
+			KeyPrefix: prefix,
+			KeyHash:   hash,
+			Scopes:    req.Scopes,
+		})
+		if err != nil {
+			logger.Error("Failed to create API key", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeAPIKeyUpdateFailed)
+			return
+		}
+
+		resp := newAPIKeyResponse(apiKey)
+		resp.Key = key
+		respond.Created(c, resp)
+	}
+}
+
+// ListAPIKeys returns the current user's API keys, newest first, without
+// the keys themselves
+func ListAPIKeys(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keys, err := queries.ListUserAPIKeys(c.Request.Context(), c.GetString("user_id"))
+		if err != nil {
+			logger.Error("Failed to list API keys", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeInternal)
+			return
+		}
+
+		data := make([]APIKeyResponse, 0, len(keys))
+		for _, key := range keys {
+			data = append(data, newAPIKeyResponse(key))
+		}
+
+		respond.OK(c, data)
+	}
+}
+
+// RevokeAPIKey revokes one of the current user's API keys. Revoked keys stay
+// listed but no longer authenticate.
+func RevokeAPIKey(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rows, err := queries.RevokeAPIKey(c.Request.Context(), db.RevokeAPIKeyParams{
+			ID:     c.Param("id"),
+			UserID: c.GetString("user_id"),
+		})
+		if err != nil {
+			logger.Error("Failed to revoke API key", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeAPIKeyUpdateFailed)
+			return
+		}
+		if rows == 0 {
+			respond.Error(c, http.StatusNotFound, i18n.CodeAPIKeyNotFound)
+			return
+		}
+
+		respond.OK(c, nil)
+	}
+}
+
+// APIKeyUsageResponse is an API key's request quota, or a null quota where
+// API key requests aren't limited
+type APIKeyUsageResponse struct {
+	APIKeyID string               `json:"api_key_id"`
+	Quota    *APIKeyQuotaResponse `json:"quota"`
+}
+
+// APIKeyQuotaResponse is an API key's use of its current window and its
+// burst credits
+type APIKeyQuotaResponse struct {
+	WindowSeconds int       `json:"window_seconds"`
+	Limit         int       `json:"limit"`
+	Remaining     int       `json:"remaining"`
+	ResetAt       time.Time `json:"reset_at"`
+	Burst         int       `json:"burst"`
+	Credits       int       `json:"credits"`
+}
+
+// GetAPIKeyUsage returns the calling API key's quota, for integrations to
+// pace themselves. The request itself counts against it.
+func GetAPIKeyUsage(limiter *apikeys.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respond.OK(c, newAPIKeyUsageResponse(limiter, c.GetString("api_key_id")))
+	}
+}
+
+// GetMyAPIKeyUsage returns the quota of one of the current user's API keys
+func GetMyAPIKeyUsage(queries *db.Queries, limiter *apikeys.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keys, err := queries.ListUserAPIKeys(c.Request.Context(), c.GetString("user_id"))
+		if err != nil {
+			logger.Error("Failed to list API keys", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeInternal)
+			return
+		}
+
+		id := c.Param("id")
+		for _, key := range keys {
+			if key.ID == id {
+				respond.OK(c, newAPIKeyUsageResponse(limiter, key.ID))
+				return
+			}
+		}
+		respond.Error(c, http.StatusNotFound, i18n.CodeAPIKeyNotFound)
+	}
+}
+
+func newAPIKeyUsageResponse(limiter *apikeys.Limiter, keyID string) APIKeyUsageResponse {
+	resp := APIKeyUsageResponse{APIKeyID: keyID}
+	if limiter == nil {
+		return resp
+	}
+
+	usage := limiter.Peek(keyID, time.Now())
+	resp.Quota = &APIKeyQuotaResponse{
+		WindowSeconds: int(apikeys.QuotaWindow.Seconds()),
+		Limit:         usage.Limit,
+		Remaining:     usage.Remaining,
+		ResetAt:       usage.Reset,
+		Burst:         usage.Burst,
+		Credits:       usage.Credits,
+	}
+	return resp
+}
+
+func newAPIKeyResponse(key db.ApiKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		KeyPrefix:  key.KeyPrefix,
+		Scopes:     key.Scopes,
+		LastUsedAt: timePtr(key.LastUsedAt),
+		RevokedAt:  timePtr(key.RevokedAt),
+		CreatedAt:  key.CreatedAt,
+	}
+}
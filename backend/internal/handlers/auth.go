@@ -2,36 +2,88 @@ package handlers
 
 import (
 	"crypto/rand"
+	"errors"
 	"net/http"
-	"strings"
 	"time"
 
+	"brewd/internal/agegate"
 	"brewd/internal/auth"
+	"brewd/internal/authevents"
+	"brewd/internal/captcha"
+	"brewd/internal/cookies"
 	"brewd/internal/db"
+	"brewd/internal/geoip"
+	"brewd/internal/i18n"
+	"brewd/internal/invites"
+	"brewd/internal/links"
 	"brewd/internal/logger"
+	"brewd/internal/mail"
+	"brewd/internal/middleware"
+	"brewd/internal/policies"
+	"brewd/internal/respond"
+	"brewd/internal/stepup"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/jackc/pgx/v5"
 	"github.com/oklog/ulid/v2"
 )
 
-// RegisterRequest represents the registration request payload
+// RegisterRequest represents the registration request payload. InviteCode
+// attributes the signup to an invite, and is required when registration is
+// invite-only. Cookie picks the cookie auth mode, as at login.
+// AcceptedPolicies are the IDs of the policy documents the user accepted,
+// which must be the current version of each. BirthDate (YYYY-MM-DD) is
+// required where an age gate applies, and users under the minimum age give
+// ParentEmail to ask a parent's consent.
 type RegisterRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Username string `json:"username" binding:"required,min=3,max=30"`
-	Password string `json:"password" binding:"required,min=8"`
+	Email            string   `json:"email" binding:"required,email"`
+	Username         string   `json:"username" binding:"required,min=3,max=30"`
+	Password         string   `json:"password" binding:"required,min=8"`
+	InviteCode       string   `json:"invite_code" binding:"omitempty,max=16"`
+	AcceptedPolicies []string `json:"accepted_policies" binding:"max=10"`
+	BirthDate        string   `json:"birth_date" binding:"omitempty,max=10"`
+	ParentEmail      string   `json:"parent_email" binding:"omitempty,email,max=255"`
+	Cookie           bool     `json:"cookie"`
 }
 
-// LoginRequest represents the login request payload
+// LoginRequest represents the login request payload. DeviceToken, from a
+// completed step-up that trusted the device, skips step-up. Cookie picks
+// the web client's cookie auth mode over a Bearer token.
 type LoginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
+	Email       string `json:"email" binding:"required,email"`
+	Password    string `json:"password" binding:"required"`
+	DeviceToken string `json:"device_token" binding:"max=64"`
+	Cookie      bool   `json:"cookie"`
 }
 
-// AuthResponse represents the authentication response
+// AvailabilityRequest represents the availability check query parameters
+type AvailabilityRequest struct {
+	Username string `form:"username" binding:"omitempty,min=3,max=30"`
+	Email    string `form:"email" binding:"omitempty,email"`
+}
+
+// AvailabilityResponse reports availability for each field that was checked
+type AvailabilityResponse struct {
+	UsernameAvailable *bool     `json:"username_available,omitempty"`
+	UsernameError     string    `json:"username_error,omitempty"`
+	UsernameErrorCode i18n.Code `json:"username_error_code,omitempty"`
+	EmailAvailable    *bool     `json:"email_available,omitempty"`
+}
+
+// AuthResponse represents the authentication response. In cookie mode the
+// token is in the session cookie instead, and CSRFToken is the value to
+// echo in the X-CSRF-Token header.
 type AuthResponse struct {
-	Token string `json:"token"`
-	User  UserInfo `json:"user"`
+	Token     string   `json:"token,omitempty"`
+	CSRFToken string   `json:"csrf_token,omitempty"`
+	User      UserInfo `json:"user"`
+}
+
+// ConsentPendingResponse answers a registration under the minimum age: the
+// account exists but can't be used until a parent consents
+type ConsentPendingResponse struct {
+	ParentalConsentRequired bool `json:"parental_consent_required"`
 }
 
 // UserInfo represents basic user information returned in auth responses
@@ -41,53 +93,123 @@ type UserInfo struct {
 	Email    string `json:"email"`
 }
 
-// Register handles user registration
-func Register(queries *db.Queries, authService auth.AuthService, bcryptCost int) gin.HandlerFunc {
+// RegistrationPolicy sets who can register. With InviteOnly, only users
+// with an invite can sign up; with Waitlist, users without one join the
+// waitlist instead. DisposableEmails is what happens to addresses at
+// throwaway domains (disposable.ActionReject, ActionFlag or ActionOff).
+// AgeGate sets the minimum age to sign up in each country.
+type RegistrationPolicy struct {
+	InviteOnly       bool
+	Waitlist         bool
+	DisposableEmails string
+	AgeGate          agegate.Gate
+}
+
+// Register handles user registration under policy, signing the user in by
+// Bearer token or cookie. The user must accept the current policies, and
+// their acceptance is recorded. Users under the minimum age for the
+// country locator places them in are created blocked, and mailer asks
+// their parent's consent through a page on site. The signup is recorded in
+// the auth event log.
+func Register(queries *db.Queries, authService auth.AuthService, bcryptCost int, policy RegistrationPolicy,
+	locator geoip.Locator, cookiePolicy cookies.Policy, mailer *mail.Mailer, site *links.Site) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if policy.Waitlist {
+			var entry WaitlistRequest
+			if err := c.ShouldBindBodyWith(&entry, binding.JSON); err != nil {
+				respond.Invalid(c, err)
+				return
+			}
+			if invites.NormalizeCode(entry.InviteCode) == "" {
+				joinWaitlist(c, queries, entry.Email, policy.DisposableEmails)
+				return
+			}
+		}
+
 		var req RegisterRequest
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"success": false,
-				"error":   "Invalid request: " + err.Error(),
-			})
+		if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		inviteCode := invites.NormalizeCode(req.InviteCode)
+		if policy.InviteOnly && inviteCode == "" {
+			respond.Error(c, http.StatusForbidden, i18n.CodeInviteRequired)
 			return
 		}
 
 		ctx := c.Request.Context()
 
+		// Apply identity policy to username and email
+		username, err := auth.NormalizeUsername(req.Username)
+		if err != nil {
+			code := usernamePolicyCode(err)
+			respond.Error(c, http.StatusBadRequest, code)
+			return
+		}
+		email, err := auth.NormalizeEmail(req.Email)
+		if err != nil {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidEmail)
+			return
+		}
+		disposableEmail, ok := checkDisposableEmail(c, queries, policy.DisposableEmails, email)
+		if !ok {
+			return
+		}
+
+		// The current policies must all be accepted, and only those: a
+		// version replaced since the form loaded has to be read again
+		currentPolicies, err := queries.ListCurrentPolicyDocuments(ctx)
+		if err != nil {
+			logger.Error("Failed to list current policy documents", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeRegistrationFailed)
+			return
+		}
+		if policies.Outdated(currentPolicies, req.AcceptedPolicies) {
+			respond.Error(c, http.StatusConflict, i18n.CodePolicyOutdated)
+			return
+		}
+		if len(policies.Missing(currentPolicies, req.AcceptedPolicies)) > 0 {
+			respond.Error(c, http.StatusPreconditionRequired, i18n.CodePolicyAcceptanceRequired)
+			return
+		}
+
+		birthDate, parentEmail, ok := checkAgeGate(c, policy.AgeGate, locator, req, email)
+		if !ok {
+			return
+		}
+		var consentToken string
+		var consentTokenHash *string
+		if parentEmail != nil {
+			token, hash, err := agegate.NewToken()
+			if err != nil {
+				logger.Error("Failed to generate parental consent token", "error", err)
+				respond.Error(c, http.StatusInternalServerError, i18n.CodeRegistrationFailed)
+				return
+			}
+			consentToken, consentTokenHash = token, &hash
+		}
+
 		// Check if email is available
-		emailAvailable, err := queries.CheckEmailAvailability(ctx, req.Email)
+		emailAvailable, err := queries.CheckEmailAvailability(ctx, email)
 		if err != nil {
 			logger.Error("Failed to check email availability", "error", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"success": false,
-				"error":   "Failed to check email availability",
-			})
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeAvailabilityCheckFailed)
 			return
 		}
 		if !emailAvailable {
-			c.JSON(http.StatusConflict, gin.H{
-				"success": false,
-				"error":   "Email already registered",
-			})
+			respond.Error(c, http.StatusConflict, i18n.CodeEmailTaken)
 			return
 		}
 
 		// Check if username is available
-		usernameAvailable, err := queries.CheckUsernameAvailability(ctx, req.Username)
+		usernameAvailable, err := queries.CheckUsernameAvailability(ctx, username)
 		if err != nil {
 			logger.Error("Failed to check username availability", "error", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"success": false,
-				"error":   "Failed to check username availability",
-			})
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeAvailabilityCheckFailed)
 			return
 		}
 		if !usernameAvailable {
-			c.JSON(http.StatusConflict, gin.H{
-				"success": false,
-				"error":   "Username already taken",
-			})
+			respond.Error(c, http.StatusConflict, i18n.CodeUsernameTaken)
 			return
 		}
 
@@ -95,126 +217,264 @@ func Register(queries *db.Queries, authService auth.AuthService, bcryptCost int)
 		passwordHash, err := auth.HashPassword(req.Password, bcryptCost)
 		if err != nil {
 			logger.Error("Failed to hash password", "error", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"success": false,
-				"error":   "Failed to create user",
-			})
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeRegistrationFailed)
 			return
 		}
 
 		// Generate ULID for user ID
 		userID := ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String()
 
-		// Create user
-		user, err := queries.CreateUser(ctx, db.CreateUserParams{
-			ID:           userID,
-			Username:     req.Username,
-			Email:        req.Email,
-			PasswordHash: passwordHash,
-		})
+		// Create user, redeeming their invite in the same statement
+		var user db.CreateUserRow
+		if inviteCode != "" {
+			var row db.CreateInvitedUserRow
+			row, err = queries.CreateInvitedUser(ctx, db.CreateInvitedUserParams{
+				Code:              inviteCode,
+				ID:                userID,
+				Username:          username,
+				Email:             email,
+				PasswordHash:      passwordHash,
+				BirthDate:         birthDate,
+				ParentEmail:       parentEmail,
+				ConsentTokenHash:  consentTokenHash,
+				ConsentTtlSeconds: int32(agegate.ConsentTTL.Seconds()),
+			})
+			user = db.CreateUserRow(row)
+		} else {
+			user, err = queries.CreateUser(ctx, db.CreateUserParams{
+				ID:                userID,
+				Username:          username,
+				Email:             email,
+				PasswordHash:      passwordHash,
+				BirthDate:         birthDate,
+				ParentEmail:       parentEmail,
+				ConsentTokenHash:  consentTokenHash,
+				ConsentTtlSeconds: int32(agegate.ConsentTTL.Seconds()),
+			})
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeInviteInvalid)
+			return
+		}
 		if err != nil {
+			// Lost a race with a concurrent registration for the same identity
+			if isUniqueViolation(err) {
+				respond.Error(c, http.StatusConflict, i18n.CodeIdentityTaken)
+				return
+			}
 			logger.Error("Failed to create user", "error", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"success": false,
-				"error":   "Failed to create user",
-			})
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeRegistrationFailed)
 			return
 		}
 
-		// Generate JWT token
-		token, err := authService.GenerateToken(user.ID, user.Username)
-		if err != nil {
-			logger.Error("Failed to generate token", "error", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"success": false,
-				"error":   "Failed to generate authentication token",
-			})
+		if disposableEmail {
+			// The flag only informs admins, so the registration stands
+			// without it
+			if err := queries.FlagDisposableEmail(ctx, user.ID); err != nil {
+				logger.Error("Failed to flag disposable email", "user_id", user.ID, "error", err)
+			}
+		}
+		if len(req.AcceptedPolicies) > 0 {
+			// Without the record the user is asked to accept again on
+			// their first request, so the registration stands
+			if err := acceptPolicies(c, queries, user.ID, req.AcceptedPolicies); err != nil {
+				logger.Error("Failed to record policy acceptance", "user_id", user.ID, "error", err)
+			}
+		}
+
+		if parentEmail != nil {
+			// The account stays blocked until the parent consents, so
+			// no session is issued
+			requestParentalConsent(c, mailer, site, *parentEmail, user, consentToken)
+			recordAuthEvent(c, queries, locator, user.ID, authevents.EventRegister)
+			logger.Info("User registered awaiting parental consent", "user_id", user.ID, "username", user.Username)
+			respond.Status(c, http.StatusAccepted, ConsentPendingResponse{ParentalConsentRequired: true})
+			return
+		}
+
+		session, ok := issueSession(c, authService, cookiePolicy, UserInfo{
+			ID:       user.ID,
+			Username: user.Username,
+			Email:    user.Email,
+		}, req.Cookie)
+		if !ok {
 			return
 		}
 
-		logger.Info("User registered successfully", "user_id", user.ID, "username", user.Username)
+		recordAuthEvent(c, queries, locator, user.ID, authevents.EventRegister)
 
-		c.JSON(http.StatusCreated, gin.H{
-			"success": true,
-			"data": AuthResponse{
-				Token: token,
-				User: UserInfo{
-					ID:       user.ID,
-					Username: user.Username,
-					Email:    user.Email,
-				},
-			},
-		})
+		logger.Info("User registered successfully", "user_id", user.ID, "username", user.Username,
+			"invited", inviteCode != "")
+
+		respond.Created(c, session)
 	}
 }
 
-// Login handles user authentication
-func Login(queries *db.Queries, authService auth.AuthService) gin.HandlerFunc {
+// Login handles user authentication. Once an account sees repeated failed
+// logins, further attempts need a solved CAPTCHA. Logins and failed
+// passwords are recorded in the auth event log, located with locator, and
+// logins guard finds suspicious are held for a step-up challenge. Accounts
+// awaiting parental consent are refused, and if the request to the parent
+// expired, mailer sends a new one.
+func Login(queries *db.Queries, authService auth.AuthService, verifier captcha.Verifier, failures *captcha.Failures,
+	locator geoip.Locator, guard *stepup.Guard, cookiePolicy cookies.Policy, mailer *mail.Mailer,
+	site *links.Site) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req LoginRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"success": false,
-				"error":   "Invalid request: " + err.Error(),
-			})
+			respond.Invalid(c, err)
 			return
 		}
 
 		ctx := c.Request.Context()
 
 		// Normalize email to lowercase
-		email := strings.ToLower(strings.TrimSpace(req.Email))
+		email, err := auth.NormalizeEmail(req.Email)
+		if err != nil {
+			respond.Error(c, http.StatusUnauthorized, i18n.CodeInvalidCredentials)
+			return
+		}
+		if failures.Required(email) && !middleware.VerifyCaptcha(c, verifier) {
+			return
+		}
 
 		// Get user by email (includes password hash)
 		user, err := queries.GetUserByEmail(ctx, email)
 		if err != nil {
 			if err == pgx.ErrNoRows {
-				c.JSON(http.StatusUnauthorized, gin.H{
-					"success": false,
-					"error":   "Invalid email or password",
-				})
+				failures.Fail(email)
+				respond.Error(c, http.StatusUnauthorized, i18n.CodeInvalidCredentials)
 				return
 			}
 			logger.Error("Failed to get user by email", "error", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"success": false,
-				"error":   "Authentication failed",
-			})
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeAuthenticationFailed)
 			return
 		}
 
 		// Verify password
 		if !auth.ComparePassword(user.PasswordHash, req.Password) {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"error":   "Invalid email or password",
-			})
+			failures.Fail(email)
+			recordAuthEvent(c, queries, locator, user.ID, authevents.EventLoginFailed)
+			respond.Error(c, http.StatusUnauthorized, i18n.CodeInvalidCredentials)
+			return
+		}
+		failures.Reset(email)
+		if user.DeactivatedAt.Valid {
+			respond.Error(c, http.StatusForbidden, i18n.CodeAccountDeactivated)
+			return
+		}
+		if user.ConsentPending {
+			renewParentalConsent(c, queries, mailer, site, user.ID, user.Username)
+			respond.Error(c, http.StatusForbidden, i18n.CodeParentalConsentPending)
 			return
 		}
 
-		// Generate JWT token
-		token, err := authService.GenerateToken(user.ID, user.Username)
-		if err != nil {
-			logger.Error("Failed to generate token", "error", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"success": false,
-				"error":   "Failed to generate authentication token",
-			})
+		if holdForStepUp(c, queries, guard, locator, user, req.DeviceToken) {
+			return
+		}
+
+		session, ok := issueSession(c, authService, cookiePolicy, UserInfo{
+			ID:       user.ID,
+			Username: user.Username,
+			Email:    user.Email,
+		}, req.Cookie)
+		if !ok {
 			return
 		}
 
+		recordAuthEvent(c, queries, locator, user.ID, authevents.EventLogin)
+
 		logger.Info("User logged in successfully", "user_id", user.ID, "username", user.Username)
 
-		c.JSON(http.StatusOK, gin.H{
-			"success": true,
-			"data": AuthResponse{
-				Token: token,
-				User: UserInfo{
-					ID:       user.ID,
-					Username: user.Username,
-					Email:    user.Email,
-				},
-			},
-		})
+		respond.OK(c, session)
+	}
+}
+
+// Logout ends a cookie mode session by clearing its cookies. The JWT is
+// stateless, so Bearer clients just drop it.
+func Logout(cookiePolicy cookies.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cookiePolicy.ClearSession(c.Writer)
+		respond.OK(c, nil)
+	}
+}
+
+// issueSession generates a token for user and returns the auth response
+// carrying it: the token itself, or in cookie mode, the session cookie is
+// set and the response carries its CSRF token instead. On failure it
+// responds with the error and returns false.
+func issueSession(c *gin.Context, authService auth.AuthService, cookiePolicy cookies.Policy, user UserInfo, cookie bool) (AuthResponse, bool) {
+	token, err := authService.GenerateToken(user.ID, user.Username)
+	if err != nil {
+		logger.Error("Failed to generate token", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeTokenGenerationFailed)
+		return AuthResponse{}, false
+	}
+	if !cookie {
+		return AuthResponse{Token: token, User: user}, true
+	}
+
+	csrf, err := cookiePolicy.SetSession(c.Writer, token)
+	if err != nil {
+		logger.Error("Failed to generate CSRF token", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeTokenGenerationFailed)
+		return AuthResponse{}, false
+	}
+	return AuthResponse{CSRFToken: csrf, User: user}, true
+}
+
+// CheckAvailability reports whether a username and/or email can be registered
+func CheckAvailability(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req AvailabilityRequest
+		if err := c.ShouldBindQuery(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		if req.Username == "" && req.Email == "" {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeUsernameOrEmailRequired)
+			return
+		}
+
+		ctx := c.Request.Context()
+		var resp AvailabilityResponse
+
+		if req.Username != "" {
+			username, err := auth.NormalizeUsername(req.Username)
+			if err != nil {
+				// Names rejected by the identity policy are never available
+				available := false
+				resp.UsernameAvailable = &available
+				resp.UsernameErrorCode = usernamePolicyCode(err)
+				resp.UsernameError = i18n.T(c, resp.UsernameErrorCode)
+			} else {
+				available, err := queries.CheckUsernameAvailability(ctx, username)
+				if err != nil {
+					logger.Error("Failed to check username availability", "error", err)
+					respond.Error(c, http.StatusInternalServerError, i18n.CodeAvailabilityCheckFailed)
+					return
+				}
+				resp.UsernameAvailable = &available
+			}
+		}
+
+		if req.Email != "" {
+			email, err := auth.NormalizeEmail(req.Email)
+			if err != nil {
+				respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidEmail)
+				return
+			}
+
+			available, err := queries.CheckEmailAvailability(ctx, email)
+			if err != nil {
+				logger.Error("Failed to check email availability", "error", err)
+				respond.Error(c, http.StatusInternalServerError, i18n.CodeAvailabilityCheckFailed)
+				return
+			}
+			resp.EmailAvailable = &available
+		}
+
+		respond.OK(c, resp)
 	}
 }
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"brewd/internal/db"
+	"brewd/internal/geoip"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxUserAgentLength caps the user agent stored with an auth event
+const maxUserAgentLength = 512
+
+// AuthEventsQuery filters the admin auth event log
+type AuthEventsQuery struct {
+	PageQuery
+	UserID string `form:"user_id" binding:"max=255"`
+	Event  string `form:"event" binding:"omitempty,oneof=register login login_failed step_up step_up_failed"`
+}
+
+// recordAuthEvent adds event for userID to the auth event log. The log is
+// an audit trail, not a gate, so failures are only logged.
+func recordAuthEvent(c *gin.Context, queries *db.Queries, locator geoip.Locator, userID, event string) {
+	params := authEventParams(c, locator, userID, event)
+	if err := queries.RecordAuthEvent(c.Request.Context(), params); err != nil {
+		logger.Error("Failed to record auth event", "user_id", userID, "event", event, "error", err)
+	}
+}
+
+// authEventParams describes event for userID with the request's IP, user
+// agent and, if locator knows it, coarse location
+func authEventParams(c *gin.Context, locator geoip.Locator, userID, event string) db.RecordAuthEventParams {
+	ip := c.ClientIP()
+	params := db.RecordAuthEventParams{
+		UserID:    userID,
+		Event:     event,
+		Ip:        &ip,
+		UserAgent: userAgent(c),
+	}
+	if loc, ok := locator.Locate(ip); ok {
+		params.Country = &loc.Country
+		if loc.Region != "" {
+			params.Region = &loc.Region
+		}
+		if loc.City != "" {
+			params.City = &loc.City
+		}
+	}
+	return params
+}
+
+// userAgent is the request's user agent, truncated for storage, or nil
+// without one
+func userAgent(c *gin.Context) *string {
+	ua := c.Request.UserAgent()
+	if ua == "" {
+		return nil
+	}
+	if len(ua) > maxUserAgentLength {
+		ua = strings.ToValidUTF8(ua[:maxUserAgentLength], "")
+	}
+	return &ua
+}
+
+// ListAuthEvents returns the current user's recent registrations, sign-ins
+// and failed sign-ins, newest first, so they can spot ones that weren't
+// them
+func ListAuthEvents(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var page PageQuery
+		if err := c.ShouldBindQuery(&page); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if page.Limit == 0 {
+			page.Limit = defaultPageLimit
+		}
+
+		userID := c.GetString("user_id")
+		events, err := queries.ListUserAuthEvents(c.Request.Context(), db.ListUserAuthEventsParams{
+			UserID:    userID,
+			RowLimit:  page.Limit,
+			RowOffset: page.Offset,
+		})
+		if err != nil {
+			logger.Error("Failed to list auth events", "user_id", userID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeAuthEventFetchFailed)
+			return
+		}
+
+		respond.Page(c, events, page.Limit, page.Offset, len(events))
+	}
+}
+
+// AdminListAuthEvents returns the auth event log, newest first, optionally
+// for one user or one kind of event
+func AdminListAuthEvents(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query AuthEventsQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if query.Limit == 0 {
+			query.Limit = defaultPageLimit
+		}
+
+		params := db.ListAuthEventsParams{
+			RowLimit:  query.Limit,
+			RowOffset: query.Offset,
+		}
+		if query.UserID != "" {
+			params.UserID = &query.UserID
+		}
+		if query.Event != "" {
+			params.Event = &query.Event
+		}
+		rows, err := queries.ListAuthEvents(c.Request.Context(), params)
+		if err != nil {
+			logger.Error("Failed to list auth events", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeAuthEventFetchFailed)
+			return
+		}
+
+		respond.Page(c, rows, query.Limit, query.Offset, len(rows))
+	}
+}
@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"brewd/internal/automations"
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/oklog/ulid/v2"
+)
+
+// AutomationWebhookRequest represents the webhook creation payload. Events
+// defaults to every brew event.
+type AutomationWebhookRequest struct {
+	Name   string   `json:"name" binding:"required,max=100"`
+	Format string   `json:"format" binding:"required"`
+	URL    string   `json:"url" binding:"required,url,max=2000"`
+	Events []string `json:"events" binding:"omitempty,dive,required"`
+}
+
+// AutomationWebhookResponse is a smart-home webhook with the outcome of its
+// latest delivery
+type AutomationWebhookResponse struct {
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	Format          string     `json:"format"`
+	URL             string     `json:"url"`
+	Events          []string   `json:"events"`
+	LastStatus      *int32     `json:"last_status"`
+	LastError       *string    `json:"last_error"`
+	LastDeliveredAt *time.Time `json:"last_delivered_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// AutomationTimerRequest represents the timer start trigger payload
+type AutomationTimerRequest struct {
+	BrewID *string `json:"brew_id" binding:"omitempty,ulid"`
+}
+
+// AutomationStatus is the current user's brewing state, shaped for a Home
+// Assistant RESTful sensor
+type AutomationStatus struct {
+	Brewing    bool       `json:"brewing"`
+	BrewID     *string    `json:"brew_id"`
+	BrewName   *string    `json:"brew_name"`
+	BrewMethod *string    `json:"brew_method"`
+	StartedAt  *time.Time `json:"started_at"`
+	LastBrewAt *time.Time `json:"last_brew_at"`
+}
+
+// CreateAutomationWebhook connects a Home Assistant or IFTTT webhook to the
+// current user's brew events
+func CreateAutomationWebhook(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req AutomationWebhookRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if len(req.Events) == 0 {
+			req.Events = automations.Events
+		}
+		valid := automations.ValidFormat(req.Format)
+		for _, event := range req.Events {
+			valid = valid && automations.ValidEvent(event)
+		}
+		if !valid {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidRequest)
+			return
+		}
+		if err := automations.CheckURL(req.URL); err != nil {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeAutomationURLInvalid)
+			return
+		}
+
+		webhook, err := queries.CreateAutomationWebhook(c.Request.Context(), db.CreateAutomationWebhookParams{
+			ID:     ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+			UserID: c.GetString("user_id"),
+			Name:   req.Name,
+			Format: req.Format,
+			Url:    req.URL,
+			Events: req.Events,
+		})
+		if err != nil {
+			logger.Error("Failed to create automation webhook", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeAutomationWebhookUpdateFailed)
+			return
+		}
+
+		respond.Created(c, newAutomationWebhookResponse(webhook))
+	}
+}
+
+// ListAutomationWebhooks returns the current user's webhooks, newest first
+func ListAutomationWebhooks(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		webhooks, err := queries.ListUserAutomationWebhooks(c.Request.Context(), c.GetString("user_id"))
+		if err != nil {
+			logger.Error("Failed to list automation webhooks", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeInternal)
+			return
+		}
+
+		data := make([]AutomationWebhookResponse, 0, len(webhooks))
+		for _, webhook := range webhooks {
+			data = append(data, newAutomationWebhookResponse(webhook))
+		}
+
+		respond.OK(c, data)
+	}
+}
+
+// DeleteAutomationWebhook disconnects one of the current user's webhooks,
+// dropping its pending deliveries
+func DeleteAutomationWebhook(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rows, err := queries.DeleteAutomationWebhook(c.Request.Context(), db.DeleteAutomationWebhookParams{
+			ID:     c.Param("id"),
+			UserID: c.GetString("user_id"),
+		})
+		if err != nil {
+			logger.Error("Failed to delete automation webhook", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeAutomationWebhookUpdateFailed)
+			return
+		}
+		if rows == 0 {
+			respond.Error(c, http.StatusNotFound, i18n.CodeAutomationWebhookNotFound)
+			return
+		}
+
+		respond.OK(c, nil)
+	}
+}
+
+// TestAutomationWebhook queues a test event for one of the current user's
+// webhooks; its outcome shows up on the webhook once sent
+func TestAutomationWebhook(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		webhook, err := queries.GetAutomationWebhook(ctx, db.GetAutomationWebhookParams{
+			ID:     c.Param("id"),
+			UserID: c.GetString("user_id"),
+		})
+		if err == pgx.ErrNoRows {
+			respond.Error(c, http.StatusNotFound, i18n.CodeAutomationWebhookNotFound)
+			return
+		}
+
+		var payload []byte
+		if err == nil {
+			payload, err = json.Marshal(automations.Payload{
+				Event:      automations.EventTest,
+				OccurredAt: time.Now().UTC(),
+				BrewName:   "Test brew",
+			})
+		}
+		if err == nil {
+			err = automations.EnqueueFor(ctx, queries, webhook.ID, automations.EventTest, payload)
+		}
+		if err != nil {
+			logger.Error("Failed to queue automation test", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeAutomationWebhookUpdateFailed)
+			return
+		}
+
+		respond.Status(c, http.StatusAccepted, nil)
+	}
+}
+
+// GetAutomationStatus reports whether the API key's user is brewing, for
+// smart-home dashboards
+func GetAutomationStatus(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		userID := c.GetString("user_id")
+
+		var status AutomationStatus
+		running, err := queries.GetRunningBrew(ctx, &userID)
+		if err == nil {
+			status.Brewing = true
+			status.BrewID = &running.ID
+			status.BrewName = &running.Name
+			status.BrewMethod = running.BrewMethod
+			status.StartedAt = timePtr(running.StartedAt)
+		}
+		var latest []db.Brew
+		if err == nil || err == pgx.ErrNoRows {
+			latest, err = queries.ListUserBrews(ctx, db.ListUserBrewsParams{
+				CreatedBy: &userID,
+				Limit:     1,
+			})
+		}
+		if err != nil {
+			logger.Error("Failed to get automation status", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewFetchFailed)
+			return
+		}
+		if len(latest) > 0 {
+			status.LastBrewAt = &latest[0].CreatedAt
+		}
+
+		respond.OK(c, status)
+	}
+}
+
+// StartAutomationTimer starts a timer session from a smart-home trigger, on
+// brew_id or else the user's most recently logged brew
+func StartAutomationTimer(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req AutomationTimerRequest
+		// Triggers often send no body at all
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				respond.Invalid(c, err)
+				return
+			}
+		}
+
+		ctx := c.Request.Context()
+		userID := c.GetString("user_id")
+		brewID := req.BrewID
+		if brewID == nil {
+			latest, err := queries.ListUserBrews(ctx, db.ListUserBrewsParams{
+				CreatedBy: &userID,
+				Limit:     1,
+			})
+			if err != nil {
+				logger.Error("Failed to get latest brew", "error", err)
+				respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewFetchFailed)
+				return
+			}
+			if len(latest) == 0 {
+				respond.Error(c, http.StatusNotFound, i18n.CodeBrewNotFound)
+				return
+			}
+			brewID = &latest[0].ID
+		}
+
+		brew, err := queries.StartBrewTimer(ctx, db.StartBrewTimerParams{
+			ID:     *brewID,
+			UserID: &userID,
+		})
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusNotFound, i18n.CodeBrewNotFound)
+				return
+			}
+			logger.Error("Failed to start brew timer", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeTimerUpdateFailed)
+			return
+		}
+
+		respond.OK(c, newAutomationStatus(brew))
+	}
+}
+
+// StopAutomationTimer stops the user's running timer session from a
+// smart-home trigger
+func StopAutomationTimer(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		userID := c.GetString("user_id")
+
+		running, err := queries.GetRunningBrew(ctx, &userID)
+		if err == nil {
+			running, err = queries.StopBrewTimer(ctx, db.StopBrewTimerParams{
+				ID:        running.ID,
+				CreatedBy: &userID,
+			})
+		}
+		if err == pgx.ErrNoRows {
+			respond.Error(c, http.StatusConflict, i18n.CodeTimerNotRunning)
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to stop brew timer", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeTimerUpdateFailed)
+			return
+		}
+
+		respond.OK(c, newAutomationStatus(running))
+	}
+}
+
+// newAutomationStatus describes brew's timer after a trigger changed it
+func newAutomationStatus(brew db.Brew) AutomationStatus {
+	return AutomationStatus{
+		Brewing:    brew.StartedAt.Valid && !brew.EndedAt.Valid,
+		BrewID:     &brew.ID,
+		BrewName:   &brew.Name,
+		BrewMethod: brew.BrewMethod,
+		StartedAt:  timePtr(brew.StartedAt),
+		LastBrewAt: &brew.CreatedAt,
+	}
+}
+
+func newAutomationWebhookResponse(webhook db.AutomationWebhook) AutomationWebhookResponse {
+	return AutomationWebhookResponse{
+		ID:              webhook.ID,
+		Name:            webhook.Name,
+		Format:          webhook.Format,
+		URL:             webhook.Url,
+		Events:          webhook.Events,
+		LastStatus:      webhook.LastStatus,
+		LastError:       webhook.LastError,
+		LastDeliveredAt: timePtr(webhook.LastDeliveredAt),
+		CreatedAt:       webhook.CreatedAt,
+	}
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"path"
+	"time"
+
+	"brewd/internal/backup"
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/media"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// BackupRunResponse is a logical backup: in progress, or its outcome.
+// Verification is "restored" when the dump was restored into the scratch
+// database, or "listed" when only its contents could be read. PrunedAt is
+// when retention deleted the dump.
+type BackupRunResponse struct {
+	ID             string     `json:"id"`
+	Trigger        string     `json:"trigger"`
+	RequestedBy    *string    `json:"requested_by"`
+	Status         string     `json:"status"`
+	ObjectKey      *string    `json:"object_key"`
+	SizeBytes      *int64     `json:"size_bytes"`
+	SHA256         *string    `json:"sha256"`
+	Verification   *string    `json:"verification"`
+	TablesRestored *int32     `json:"tables_restored"`
+	Error          *string    `json:"error"`
+	StartedAt      *time.Time `json:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at"`
+	PrunedAt       *time.Time `json:"pruned_at"`
+}
+
+func newBackupRunResponse(run db.BackupRun) BackupRunResponse {
+	return BackupRunResponse{
+		ID:             run.ID,
+		Trigger:        run.Trigger,
+		RequestedBy:    run.RequestedBy,
+		Status:         run.Status,
+		ObjectKey:      run.ObjectKey,
+		SizeBytes:      run.SizeBytes,
+		SHA256:         run.Sha256,
+		Verification:   run.Verification,
+		TablesRestored: run.TablesRestored,
+		Error:          run.Error,
+		StartedAt:      timePtr(run.StartedAt),
+		FinishedAt:     timePtr(run.FinishedAt),
+		PrunedAt:       timePtr(run.PrunedAt),
+	}
+}
+
+// AdminListBackups returns the backup history, newest first
+func AdminListBackups(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var page PageQuery
+		if err := c.ShouldBindQuery(&page); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if page.Limit == 0 {
+			page.Limit = defaultPageLimit
+		}
+
+		runs, err := queries.ListBackupRuns(c.Request.Context(), db.ListBackupRunsParams{
+			Limit:  page.Limit,
+			Offset: page.Offset,
+		})
+		if err != nil {
+			logger.Error("Failed to list backup runs", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBackupFetchFailed)
+			return
+		}
+
+		items := make([]BackupRunResponse, 0, len(runs))
+		for _, run := range runs {
+			items = append(items, newBackupRunResponse(run))
+		}
+		respond.Page(c, items, page.Limit, page.Offset, len(items))
+	}
+}
+
+// AdminTriggerBackup starts a backup now, returning the run in progress;
+// its outcome appears in the backup history. runner is nil when no object
+// store is configured.
+func AdminTriggerBackup(runner *backup.Runner) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if runner == nil {
+			respond.Error(c, http.StatusServiceUnavailable, i18n.CodeBackupsUnavailable)
+			return
+		}
+
+		run, err := runner.Trigger(c.Request.Context(), c.GetString("user_id"))
+		if errors.Is(err, backup.ErrRunning) {
+			respond.Error(c, http.StatusConflict, i18n.CodeBackupRunning)
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to start backup", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBackupStartFailed)
+			return
+		}
+
+		respond.Status(c, http.StatusAccepted, newBackupRunResponse(run))
+	}
+}
+
+// BackupDownloadResponse is a signed link to a backup's dump
+type BackupDownloadResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AdminDownloadBackup returns a time-limited link to download a backup's
+// dump from the object store. urls is nil when no object store is
+// configured.
+func AdminDownloadBackup(queries *db.Queries, urls *media.URLs) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if urls == nil {
+			respond.Error(c, http.StatusServiceUnavailable, i18n.CodeBackupsUnavailable)
+			return
+		}
+
+		run, err := queries.GetBackupRun(c.Request.Context(), c.Param("id"))
+		if err == pgx.ErrNoRows {
+			respond.Error(c, http.StatusNotFound, i18n.CodeBackupNotFound)
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to get backup run", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBackupFetchFailed)
+			return
+		}
+		if run.ObjectKey == nil {
+			respond.Error(c, http.StatusConflict, i18n.CodeBackupNotDownloadable)
+			return
+		}
+
+		url, expiresAt, err := urls.Sign(*run.ObjectKey, media.Options{Filename: path.Base(*run.ObjectKey)})
+		if err != nil {
+			logger.Error("Failed to sign backup download", "run_id", run.ID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBackupFetchFailed)
+			return
+		}
+		respond.OK(c, BackupDownloadResponse{URL: url, ExpiresAt: expiresAt})
+	}
+}
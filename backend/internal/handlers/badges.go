@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"net/http"
+	"time"
+
+	"brewd/internal/badges"
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/oklog/ulid/v2"
+)
+
+// ChallengeRequest represents the challenge creation payload. BrewMethod
+// narrows a brews challenge to one method.
+type ChallengeRequest struct {
+	Name        string  `json:"name" binding:"required,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=2000"`
+	Metric      string  `json:"metric" binding:"required,oneof=brews origins methods"`
+	BrewMethod  *string `json:"brew_method" binding:"omitempty,oneof=espresso pour_over french_press aeropress cold_brew drip moka_pot siphon chemex v60 turkish percolator other"`
+	Threshold   int32   `json:"threshold" binding:"required,min=1,max=100000"`
+}
+
+// UpdateChallengeRequest represents the challenge update payload; omitted
+// fields are left unchanged
+type UpdateChallengeRequest struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=2000"`
+	Threshold   *int32  `json:"threshold" binding:"omitempty,min=1,max=100000"`
+	IsActive    *bool   `json:"is_active"`
+}
+
+// ChallengeResponse is a challenge whose badge users can earn
+type ChallengeResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description"`
+	Metric      string    `json:"metric"`
+	BrewMethod  *string   `json:"brew_method"`
+	Threshold   int32     `json:"threshold"`
+	IsActive    bool      `json:"is_active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// BadgeResponse is a badge a user has earned
+type BadgeResponse struct {
+	ChallengeID string    `json:"challenge_id"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description"`
+	Metric      string    `json:"metric"`
+	BrewMethod  *string   `json:"brew_method"`
+	Threshold   int32     `json:"threshold"`
+	EarnedAt    time.Time `json:"earned_at"`
+}
+
+// ChallengeProgress is the current user's progress toward an active
+// challenge
+type ChallengeProgress struct {
+	ChallengeID string     `json:"challenge_id"`
+	Name        string     `json:"name"`
+	Description *string    `json:"description"`
+	Metric      string     `json:"metric"`
+	BrewMethod  *string    `json:"brew_method"`
+	Threshold   int32      `json:"threshold"`
+	Progress    int64      `json:"progress"`
+	EarnedAt    *time.Time `json:"earned_at"`
+}
+
+// ListChallenges returns the active challenges
+func ListChallenges(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respondChallenges(c, queries, false)
+	}
+}
+
+// GetUserBadges returns the badges a user has earned, newest first
+func GetUserBadges(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		user, err := queries.GetUserByID(ctx, c.Param("id"))
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusNotFound, i18n.CodeUserNotFound)
+				return
+			}
+			logger.Error("Failed to get user", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBadgeFetchFailed)
+			return
+		}
+
+		rows, err := queries.ListUserBadges(ctx, user.ID)
+		if err != nil {
+			logger.Error("Failed to list user badges", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBadgeFetchFailed)
+			return
+		}
+
+		resp := make([]BadgeResponse, 0, len(rows))
+		for _, row := range rows {
+			resp = append(resp, BadgeResponse(row))
+		}
+
+		respond.OK(c, resp)
+	}
+}
+
+// GetMyBadges returns the current user's progress toward every active
+// challenge. Badges are awarded in the background, so a completed challenge
+// can briefly show full progress without earned_at.
+func GetMyBadges(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		userID := c.GetString("user_id")
+
+		challenges, err := queries.ListChallenges(ctx, false)
+		if err != nil {
+			logger.Error("Failed to list challenges", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBadgeFetchFailed)
+			return
+		}
+
+		earned, err := queries.ListUserBadges(ctx, userID)
+		if err != nil {
+			logger.Error("Failed to list user badges", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBadgeFetchFailed)
+			return
+		}
+
+		totals, err := badges.LoadTotals(ctx, queries, userID)
+		if err != nil {
+			logger.Error("Failed to load brew totals", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBadgeFetchFailed)
+			return
+		}
+
+		earnedAt := make(map[string]time.Time, len(earned))
+		for _, badge := range earned {
+			earnedAt[badge.ChallengeID] = badge.EarnedAt
+		}
+
+		progress := make([]ChallengeProgress, 0, len(challenges))
+		for _, challenge := range challenges {
+			p := ChallengeProgress{
+				ChallengeID: challenge.ID,
+				Name:        challenge.Name,
+				Description: challenge.Description,
+				Metric:      challenge.Metric,
+				BrewMethod:  challenge.BrewMethod,
+				Threshold:   challenge.Threshold,
+				Progress:    badges.Progress(challenge, totals),
+			}
+			if at, ok := earnedAt[challenge.ID]; ok {
+				p.EarnedAt = &at
+				p.Progress = int64(challenge.Threshold)
+			}
+			progress = append(progress, p)
+		}
+
+		respond.OK(c, progress)
+	}
+}
+
+// AdminListChallenges returns every challenge, including retired ones
+func AdminListChallenges(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respondChallenges(c, queries, true)
+	}
+}
+
+// AdminCreateChallenge defines a new challenge. Users who have already
+// completed it are awarded its badge by the badge worker.
+func AdminCreateChallenge(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ChallengeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if req.BrewMethod != nil && req.Metric != badges.MetricBrews {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidRequest)
+			return
+		}
+
+		userID := c.GetString("user_id")
+		challenge, err := queries.CreateChallenge(c.Request.Context(), db.CreateChallengeParams{
+			ID:          ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+			Name:        req.Name,
+			Description: req.Description,
+			Metric:      req.Metric,
+			BrewMethod:  req.BrewMethod,
+			Threshold:   req.Threshold,
+			CreatedBy:   &userID,
+		})
+		if err != nil {
+			logger.Error("Failed to create challenge", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeChallengeUpdateFailed)
+			return
+		}
+
+		logger.Info("Challenge created", "challenge_id", challenge.ID, "admin_id", userID)
+		queueAllBadgeEvaluations(c, queries)
+
+		respond.Created(c, newChallengeResponse(challenge))
+	}
+}
+
+// AdminUpdateChallenge edits a challenge, or retires it with
+// is_active=false. Badges already earned are kept.
+func AdminUpdateChallenge(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req UpdateChallengeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		challenge, err := queries.UpdateChallenge(c.Request.Context(), db.UpdateChallengeParams{
+			Name:        req.Name,
+			Description: req.Description,
+			Threshold:   req.Threshold,
+			IsActive:    req.IsActive,
+			ID:          c.Param("id"),
+		})
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusNotFound, i18n.CodeChallengeNotFound)
+				return
+			}
+			logger.Error("Failed to update challenge", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeChallengeUpdateFailed)
+			return
+		}
+
+		// A lower threshold or a reactivated challenge can complete it for
+		// users who weren't re-evaluated since
+		if challenge.IsActive && (req.Threshold != nil || req.IsActive != nil) {
+			queueAllBadgeEvaluations(c, queries)
+		}
+
+		respond.OK(c, newChallengeResponse(challenge))
+	}
+}
+
+// AdminDeleteChallenge removes a challenge and every badge earned from it
+func AdminDeleteChallenge(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		deleted, err := queries.DeleteChallenge(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			logger.Error("Failed to delete challenge", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeChallengeUpdateFailed)
+			return
+		}
+		if deleted == 0 {
+			respond.Error(c, http.StatusNotFound, i18n.CodeChallengeNotFound)
+			return
+		}
+
+		logger.Info("Challenge deleted", "challenge_id", c.Param("id"), "admin_id", c.GetString("user_id"))
+		respond.OK(c, nil)
+	}
+}
+
+// respondChallenges writes the active challenges, plus retired ones when
+// includeInactive is set
+func respondChallenges(c *gin.Context, queries *db.Queries, includeInactive bool) {
+	challenges, err := queries.ListChallenges(c.Request.Context(), includeInactive)
+	if err != nil {
+		logger.Error("Failed to list challenges", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeChallengeFetchFailed)
+		return
+	}
+
+	resp := make([]ChallengeResponse, 0, len(challenges))
+	for _, challenge := range challenges {
+		resp = append(resp, newChallengeResponse(challenge))
+	}
+
+	respond.OK(c, resp)
+}
+
+func newChallengeResponse(challenge db.Challenge) ChallengeResponse {
+	return ChallengeResponse{
+		ID:          challenge.ID,
+		Name:        challenge.Name,
+		Description: challenge.Description,
+		Metric:      challenge.Metric,
+		BrewMethod:  challenge.BrewMethod,
+		Threshold:   challenge.Threshold,
+		IsActive:    challenge.IsActive,
+		CreatedAt:   challenge.CreatedAt,
+		UpdatedAt:   challenge.UpdatedAt,
+	}
+}
+
+// queueAllBadgeEvaluations queues every user with brews for the badge
+// worker. Failure is logged rather than returned: the change itself is
+// saved, and users are re-evaluated on their next brew.
+func queueAllBadgeEvaluations(c *gin.Context, queries *db.Queries) {
+	queued, err := queries.QueueAllBadgeEvaluations(c.Request.Context())
+	if err != nil {
+		logger.Warn("Failed to queue badge evaluations", "error", err)
+		return
+	}
+	logger.Info("Queued badge evaluations", "users", queued)
+}
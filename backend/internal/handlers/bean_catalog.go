@@ -0,0 +1,433 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"net/http"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/oklog/ulid/v2"
+)
+
+// BeanRequest represents the bean catalogue entry payload. Official
+// publishes the bean as a listing of the current user's verified roaster,
+// under the roaster's name.
+type BeanRequest struct {
+	Origin
+	Name        string  `json:"name" binding:"required,max=255"`
+	RoasterName *string `json:"roaster_name" binding:"omitempty,max=255"`
+	BeanOrigin  *string `json:"bean_origin" binding:"omitempty,max=255"`
+	Description *string `json:"description" binding:"omitempty,max=2000"`
+	Official    bool    `json:"official"`
+}
+
+// UpdateBeanRequest represents the bean update payload; omitted fields are
+// left unchanged
+type UpdateBeanRequest struct {
+	Origin
+	Name        *string `json:"name" binding:"omitempty,min=1,max=255"`
+	RoasterName *string `json:"roaster_name" binding:"omitempty,max=255"`
+	BeanOrigin  *string `json:"bean_origin" binding:"omitempty,max=255"`
+	Description *string `json:"description" binding:"omitempty,max=2000"`
+}
+
+// BeanQuery represents the bean catalogue query parameters
+type BeanQuery struct {
+	PageQuery
+	OriginQuery
+	Q         string `form:"q" binding:"max=100"`
+	RoasterID string `form:"roaster_id" binding:"max=255"`
+	Official  bool   `form:"official"`
+}
+
+// BeanRoaster is the roaster behind an official listing
+type BeanRoaster struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Verified bool   `json:"verified"`
+}
+
+// BeanRecipe is an official recipe on a bean listing
+type BeanRecipe struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name"`
+	BrewMethod      *string   `json:"brew_method"`
+	CurrentRevision int32     `json:"current_revision"`
+	PublishedAt     time.Time `json:"published_at"`
+}
+
+// BeanResponse is a bean page: the bean, its roaster when it's an official
+// listing, and the roaster's official recipes for it
+type BeanResponse struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	RoasterName *string `json:"roaster_name"`
+	BeanOrigin  *string `json:"bean_origin"`
+	Description *string `json:"description"`
+	Origin
+	Official        bool         `json:"official"`
+	Roaster         *BeanRoaster `json:"roaster"`
+	OfficialRecipes []BeanRecipe `json:"official_recipes"`
+	CreatedBy       *string      `json:"created_by"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+}
+
+// BeanSummary is a bean in the catalogue
+type BeanSummary struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name"`
+	RoasterName     *string   `json:"roaster_name"`
+	BeanOrigin      *string   `json:"bean_origin"`
+	OriginCountry   *string   `json:"origin_country"`
+	Variety         *string   `json:"variety"`
+	Process         *string   `json:"process"`
+	RoasterID       *string   `json:"roaster_id"`
+	Official        bool      `json:"official"`
+	RoasterVerified bool      `json:"roaster_verified"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// CreateBean adds a bean to the community catalogue, or an official listing
+// when requested by a verified roaster
+func CreateBean(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req BeanRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		if !checkOrigin(c, &req.Origin) {
+			return
+		}
+
+		ctx := c.Request.Context()
+		userID := c.GetString("user_id")
+		params := db.CreateBeanParams{
+			ID:            ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+			Name:          req.Name,
+			RoasterName:   req.RoasterName,
+			BeanOrigin:    req.BeanOrigin,
+			Description:   req.Description,
+			CreatedBy:     &userID,
+			OriginCountry: req.OriginCountry,
+			OriginRegion:  req.OriginRegion,
+			Farm:          req.Farm,
+			Variety:       req.Variety,
+			AltitudeM:     req.AltitudeM,
+			Process:       req.Process,
+		}
+		if req.Official {
+			roaster, ok := loadVerifiedRoaster(c, queries, userID)
+			if !ok {
+				return
+			}
+			params.RoasterID = &roaster.ID
+			params.RoasterName = &roaster.Name
+		}
+
+		bean, err := queries.CreateBean(ctx, params)
+		if err != nil {
+			logger.Error("Failed to create bean", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBeanUpdateFailed)
+			return
+		}
+
+		respondBean(c, queries, http.StatusCreated, bean)
+	}
+}
+
+// ListBeans browses the catalogue, official listings first. ?q searches
+// names and roasters, ?roaster_id narrows to one roaster's listings,
+// ?country, ?process and ?variety filter by origin and ?official=true skips
+// community entries.
+func ListBeans(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query BeanQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if query.Limit == 0 {
+			query.Limit = defaultPageLimit
+		}
+
+		params := db.ListBeansParams{
+			OfficialOnly: query.Official,
+			RowLimit:     query.Limit,
+			RowOffset:    query.Offset,
+		}
+		params.OriginCountry, params.Process, params.Variety = originFilter(query.OriginQuery)
+		if query.Q != "" {
+			params.Search = &query.Q
+		}
+		if query.RoasterID != "" {
+			params.RoasterID = &query.RoasterID
+		}
+
+		rows, err := queries.ListBeans(c.Request.Context(), params)
+		if err != nil {
+			logger.Error("Failed to list beans", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBeanFetchFailed)
+			return
+		}
+
+		summaries := make([]BeanSummary, 0, len(rows))
+		for _, row := range rows {
+			summaries = append(summaries, BeanSummary{
+				ID:              row.ID,
+				Name:            row.Name,
+				RoasterName:     row.RoasterName,
+				BeanOrigin:      row.BeanOrigin,
+				OriginCountry:   row.OriginCountry,
+				Variety:         row.Variety,
+				Process:         row.Process,
+				RoasterID:       row.RoasterID,
+				Official:        row.RoasterID != nil,
+				RoasterVerified: row.RoasterVerified,
+				CreatedAt:       row.CreatedAt,
+			})
+		}
+
+		respond.Page(c, summaries, query.Limit, query.Offset, len(summaries))
+	}
+}
+
+// GetBean returns a bean page
+func GetBean(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bean, err := queries.GetBeanByID(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			respondBeanLoadError(c, err)
+			return
+		}
+
+		respondBean(c, queries, http.StatusOK, bean)
+	}
+}
+
+// UpdateBean edits a community entry the current user added, or an official
+// listing of the roaster they manage. Official listings keep the roaster's
+// name.
+func UpdateBean(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req UpdateBeanRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		bean, roaster, ok := loadEditableBean(c, queries)
+		if !ok {
+			return
+		}
+		if roaster != nil {
+			req.RoasterName = nil
+		}
+		if !checkOrigin(c, &req.Origin) {
+			return
+		}
+
+		bean, err := queries.UpdateBean(c.Request.Context(), db.UpdateBeanParams{
+			Name:          req.Name,
+			RoasterName:   req.RoasterName,
+			BeanOrigin:    req.BeanOrigin,
+			Description:   req.Description,
+			OriginCountry: req.OriginCountry,
+			OriginRegion:  req.OriginRegion,
+			Farm:          req.Farm,
+			Variety:       req.Variety,
+			AltitudeM:     req.AltitudeM,
+			Process:       req.Process,
+			ID:            bean.ID,
+		})
+		if err != nil {
+			logger.Error("Failed to update bean", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBeanUpdateFailed)
+			return
+		}
+
+		respondBean(c, queries, http.StatusOK, bean)
+	}
+}
+
+// PublishBeanRecipe publishes one of the roaster's own public recipes as an
+// official recipe on its listing. The roaster must be verified.
+func PublishBeanRecipe(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bean, roaster, ok := loadEditableBean(c, queries)
+		if !ok {
+			return
+		}
+		if roaster == nil {
+			respond.Error(c, http.StatusForbidden, i18n.CodeForbidden)
+			return
+		}
+		if !roaster.VerifiedAt.Valid {
+			respond.Error(c, http.StatusForbidden, i18n.CodeRoasterNotVerified)
+			return
+		}
+
+		ctx := c.Request.Context()
+		recipe, err := queries.GetRecipeByID(ctx, c.Param("recipe_id"))
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusNotFound, i18n.CodeRecipeNotFound)
+				return
+			}
+			logger.Error("Failed to get recipe", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeRecipeFetchFailed)
+			return
+		}
+		if recipe.OwnerID != roaster.UserID || !recipe.IsPublic {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeOfficialRecipeInvalid)
+			return
+		}
+
+		if err := queries.AddBeanRecipe(ctx, db.AddBeanRecipeParams{
+			BeanID:   bean.ID,
+			RecipeID: recipe.ID,
+		}); err != nil {
+			logger.Error("Failed to publish bean recipe", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBeanUpdateFailed)
+			return
+		}
+
+		respondBean(c, queries, http.StatusOK, bean)
+	}
+}
+
+// UnpublishBeanRecipe removes an official recipe from the roaster's listing
+func UnpublishBeanRecipe(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bean, _, ok := loadEditableBean(c, queries)
+		if !ok {
+			return
+		}
+
+		removed, err := queries.RemoveBeanRecipe(c.Request.Context(), db.RemoveBeanRecipeParams{
+			BeanID:   bean.ID,
+			RecipeID: c.Param("recipe_id"),
+		})
+		if err != nil {
+			logger.Error("Failed to unpublish bean recipe", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBeanUpdateFailed)
+			return
+		}
+		if removed == 0 {
+			respond.Error(c, http.StatusNotFound, i18n.CodeRecipeNotFound)
+			return
+		}
+
+		respond.OK(c, nil)
+	}
+}
+
+// loadVerifiedRoaster fetches the roaster userID manages, writing 403 unless
+// it exists and is verified
+func loadVerifiedRoaster(c *gin.Context, queries *db.Queries, userID string) (db.Roaster, bool) {
+	roaster, err := queries.GetRoasterByUserID(c.Request.Context(), userID)
+	if err != nil && err != pgx.ErrNoRows {
+		logger.Error("Failed to get roaster", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeRoasterFetchFailed)
+		return roaster, false
+	}
+	if err == pgx.ErrNoRows || !roaster.VerifiedAt.Valid {
+		respond.Error(c, http.StatusForbidden, i18n.CodeRoasterNotVerified)
+		return roaster, false
+	}
+	return roaster, true
+}
+
+// loadEditableBean fetches the :id bean if the current user may edit it,
+// writing an error response otherwise. Community entries are edited by
+// whoever added them; official listings by the user managing the roaster,
+// which is returned with them (nil for community entries).
+func loadEditableBean(c *gin.Context, queries *db.Queries) (db.Bean, *db.Roaster, bool) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+	bean, err := queries.GetBeanByID(ctx, c.Param("id"))
+	if err != nil {
+		respondBeanLoadError(c, err)
+		return bean, nil, false
+	}
+
+	var roaster *db.Roaster
+	allowed := bean.CreatedBy != nil && *bean.CreatedBy == userID
+	if bean.RoasterID != nil {
+		r, err := queries.GetRoasterByID(ctx, *bean.RoasterID)
+		if err != nil {
+			logger.Error("Failed to get roaster", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeRoasterFetchFailed)
+			return bean, nil, false
+		}
+		roaster = &r
+		allowed = r.UserID == userID
+	}
+	if !allowed {
+		respond.Error(c, http.StatusForbidden, i18n.CodeForbidden)
+		return bean, nil, false
+	}
+	return bean, roaster, true
+}
+
+// respondBeanLoadError writes the response for a failed bean lookup
+func respondBeanLoadError(c *gin.Context, err error) {
+	if err == pgx.ErrNoRows {
+		respond.Error(c, http.StatusNotFound, i18n.CodeBeanNotFound)
+		return
+	}
+	logger.Error("Failed to get bean", "error", err)
+	respond.Error(c, http.StatusInternalServerError, i18n.CodeBeanFetchFailed)
+}
+
+// respondBean writes bean's page with the given status
+func respondBean(c *gin.Context, queries *db.Queries, status int, bean db.Bean) {
+	ctx := c.Request.Context()
+	resp := BeanResponse{
+		ID:              bean.ID,
+		Name:            bean.Name,
+		RoasterName:     bean.RoasterName,
+		BeanOrigin:      bean.BeanOrigin,
+		Description:     bean.Description,
+		Origin:          newOrigin(bean.OriginCountry, bean.OriginRegion, bean.Farm, bean.Variety, bean.AltitudeM, bean.Process),
+		Official:        bean.RoasterID != nil,
+		OfficialRecipes: []BeanRecipe{},
+		CreatedBy:       bean.CreatedBy,
+		CreatedAt:       bean.CreatedAt,
+		UpdatedAt:       bean.UpdatedAt,
+	}
+
+	if bean.RoasterID != nil {
+		roaster, err := queries.GetRoasterByID(ctx, *bean.RoasterID)
+		if err != nil {
+			logger.Error("Failed to get roaster", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBeanFetchFailed)
+			return
+		}
+		resp.Roaster = &BeanRoaster{
+			ID:       roaster.ID,
+			Name:     roaster.Name,
+			Verified: roaster.VerifiedAt.Valid,
+		}
+
+		recipes, err := queries.ListBeanRecipes(ctx, bean.ID)
+		if err != nil {
+			logger.Error("Failed to list bean recipes", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBeanFetchFailed)
+			return
+		}
+		for _, r := range recipes {
+			resp.OfficialRecipes = append(resp.OfficialRecipes, BeanRecipe(r))
+		}
+	}
+
+	respond.Status(c, status, resp)
+}
@@ -0,0 +1,439 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"net/http"
+	"time"
+
+	"brewd/internal/beans"
+	"brewd/internal/currency"
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+	"brewd/internal/units"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/oklog/ulid/v2"
+)
+
+// defaultReorderLeadDays is how early reordering is suggested when a bag
+// doesn't say
+const defaultReorderLeadDays = 3
+
+// BeanBagRequest represents the bean bag creation payload. Weight and
+// assumed_dose are in the caller's weight unit; dates are YYYY-MM-DD. Price
+// is in major units (e.g. 14.50), bounded so it fits in minor units, of
+// currency, which defaults to the user's preferred currency. RestDays is how long the beans rest after roasting;
+// the calendar feed shows the day they're ready.
+type BeanBagRequest struct {
+	Origin
+	Name            string   `json:"name" binding:"required,max=255"`
+	Roaster         *string  `json:"roaster"`
+	BeanOrigin      *string  `json:"bean_origin"`
+	Weight          float64  `json:"weight" binding:"required,gt=0"`
+	RoastedOn       *string  `json:"roasted_on" binding:"omitempty,datetime=2006-01-02"`
+	RestDays        *int32   `json:"rest_days" binding:"omitempty,min=0,max=60"`
+	AssumedDose     *float64 `json:"assumed_dose" binding:"omitempty,gt=0"`
+	ReorderLeadDays *int32   `json:"reorder_lead_days" binding:"omitempty,min=0,max=60"`
+	Price           *float64 `json:"price" binding:"omitempty,gte=0,lte=1000000"`
+	Currency        *string  `json:"currency" binding:"omitempty,len=3"`
+	PurchasedOn     *string  `json:"purchased_on" binding:"omitempty,datetime=2006-01-02"`
+}
+
+// UpdateBeanBagRequest represents the bean bag update payload; omitted fields
+// are left unchanged. Finished marks the bag empty (or reopens it). Currency
+// can only be changed together with price.
+type UpdateBeanBagRequest struct {
+	Origin
+	Name            *string  `json:"name" binding:"omitempty,min=1,max=255"`
+	Weight          *float64 `json:"weight" binding:"omitempty,gt=0"`
+	RestDays        *int32   `json:"rest_days" binding:"omitempty,min=0,max=60"`
+	AssumedDose     *float64 `json:"assumed_dose" binding:"omitempty,gt=0"`
+	ReorderLeadDays *int32   `json:"reorder_lead_days" binding:"omitempty,min=0,max=60"`
+	Price           *float64 `json:"price" binding:"omitempty,gte=0,lte=1000000"`
+	Currency        *string  `json:"currency" binding:"omitempty,len=3"`
+	PurchasedOn     *string  `json:"purchased_on" binding:"omitempty,datetime=2006-01-02"`
+	Finished        *bool    `json:"finished"`
+}
+
+// BeanBagResponse represents a bean bag converted to the caller's units
+type BeanBagResponse struct {
+	ID              string   `json:"id"`
+	Name            string   `json:"name"`
+	Roaster         *string  `json:"roaster"`
+	BeanOrigin      *string  `json:"bean_origin"`
+	Weight          float64  `json:"weight"`
+	RoastedOn       *string  `json:"roasted_on"`
+	RestDays        *int32   `json:"rest_days"`
+	AssumedDose     *float64 `json:"assumed_dose"`
+	ReorderLeadDays int32    `json:"reorder_lead_days"`
+	Price           *float64 `json:"price"`
+	Currency        *string  `json:"currency"`
+	PurchasedOn     *string  `json:"purchased_on"`
+	Origin
+	FinishedAt *time.Time       `json:"finished_at"`
+	Units      units.Preference `json:"units"`
+	CreatedAt  time.Time        `json:"created_at"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+}
+
+// BeanForecastResponse is a bag's run-out forecast in the caller's units.
+// AssumedDose is the dose counted for linked brews that recorded none.
+type BeanForecastResponse struct {
+	BeanBagID     string           `json:"bean_bag_id"`
+	BrewCount     int64            `json:"brew_count"`
+	Remaining     float64          `json:"remaining"`
+	Daily         *float64         `json:"daily"`
+	DaysLeft      *float64         `json:"days_left"`
+	RunsOutAt     *time.Time       `json:"runs_out_at"`
+	ReorderBy     *time.Time       `json:"reorder_by"`
+	ShouldReorder bool             `json:"should_reorder"`
+	AssumedDose   float64          `json:"assumed_dose"`
+	Units         units.Preference `json:"units"`
+}
+
+// CreateBeanBag adds a bag of beans for the current user
+func CreateBeanBag(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req BeanBagRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		pref, ok := requestUnits(c, queries)
+		if !ok {
+			return
+		}
+
+		if !checkOrigin(c, &req.Origin) {
+			return
+		}
+
+		priceMinor, currencyCode, ok := resolvePrice(c, queries, req.Price, req.Currency, nil)
+		if !ok {
+			return
+		}
+
+		leadDays := int32(defaultReorderLeadDays)
+		if req.ReorderLeadDays != nil {
+			leadDays = *req.ReorderLeadDays
+		}
+
+		bag, err := queries.CreateBeanBag(c.Request.Context(), db.CreateBeanBagParams{
+			ID:               ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+			OwnerID:          c.GetString("user_id"),
+			Name:             req.Name,
+			Roaster:          req.Roaster,
+			BeanOrigin:       req.BeanOrigin,
+			WeightGrams:      units.ToGrams(req.Weight, pref.Weight),
+			RoastedOn:        dateParam(req.RoastedOn),
+			AssumedDoseGrams: gramsPtr(req.AssumedDose, pref),
+			ReorderLeadDays:  leadDays,
+			PriceMinor:       priceMinor,
+			Currency:         currencyCode,
+			PurchasedOn:      dateParam(req.PurchasedOn),
+			OriginCountry:    req.OriginCountry,
+			OriginRegion:     req.OriginRegion,
+			Farm:             req.Farm,
+			Variety:          req.Variety,
+			AltitudeM:        req.AltitudeM,
+			Process:          req.Process,
+			RestDays:         req.RestDays,
+		})
+		if err != nil {
+			logger.Error("Failed to create bean bag", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBeanBagCreateFailed)
+			return
+		}
+
+		respond.Created(c, newBeanBagResponse(bag, pref))
+	}
+}
+
+// ListBeanBags returns the current user's bean bags, open bags first.
+// ?country, ?process and ?variety narrow them by origin.
+func ListBeanBags(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query OriginQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		pref, ok := requestUnits(c, queries)
+		if !ok {
+			return
+		}
+
+		country, process, variety := originFilter(query)
+		bags, err := queries.ListUserBeanBags(c.Request.Context(), db.ListUserBeanBagsParams{
+			OwnerID:       c.GetString("user_id"),
+			OriginCountry: country,
+			Process:       process,
+			Variety:       variety,
+		})
+		if err != nil {
+			logger.Error("Failed to list bean bags", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBeanBagFetchFailed)
+			return
+		}
+
+		data := make([]BeanBagResponse, 0, len(bags))
+		for _, bag := range bags {
+			data = append(data, newBeanBagResponse(bag, pref))
+		}
+
+		respond.OK(c, data)
+	}
+}
+
+// GetBeanBag returns one of the current user's bean bags
+func GetBeanBag(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		pref, ok := requestUnits(c, queries)
+		if !ok {
+			return
+		}
+
+		bag, ok := loadOwnedBeanBag(c, queries, c.Param("id"))
+		if !ok {
+			return
+		}
+
+		respond.OK(c, newBeanBagResponse(bag, pref))
+	}
+}
+
+// UpdateBeanBag changes one of the current user's bean bags. Any update
+// re-arms the reorder suggestion, so changed assumptions are re-checked.
+func UpdateBeanBag(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req UpdateBeanBagRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		pref, ok := requestUnits(c, queries)
+		if !ok {
+			return
+		}
+
+		if !checkOrigin(c, &req.Origin) {
+			return
+		}
+
+		existing, ok := loadOwnedBeanBag(c, queries, c.Param("id"))
+		if !ok {
+			return
+		}
+
+		// A new price without a currency keeps the bag's current one
+		priceMinor, currencyCode, ok := resolvePrice(c, queries, req.Price, req.Currency, existing.Currency)
+		if !ok {
+			return
+		}
+
+		bag, err := queries.UpdateBeanBag(c.Request.Context(), db.UpdateBeanBagParams{
+			Name:             req.Name,
+			WeightGrams:      gramsPtr(req.Weight, pref),
+			AssumedDoseGrams: gramsPtr(req.AssumedDose, pref),
+			ReorderLeadDays:  req.ReorderLeadDays,
+			PriceMinor:       priceMinor,
+			Currency:         currencyCode,
+			PurchasedOn:      dateParam(req.PurchasedOn),
+			OriginCountry:    req.OriginCountry,
+			OriginRegion:     req.OriginRegion,
+			Farm:             req.Farm,
+			Variety:          req.Variety,
+			AltitudeM:        req.AltitudeM,
+			Process:          req.Process,
+			RestDays:         req.RestDays,
+			Finished:         req.Finished,
+			ID:               existing.ID,
+			OwnerID:          existing.OwnerID,
+		})
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusNotFound, i18n.CodeBeanBagNotFound)
+				return
+			}
+			logger.Error("Failed to update bean bag", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBeanBagUpdateFailed)
+			return
+		}
+
+		respond.OK(c, newBeanBagResponse(bag, pref))
+	}
+}
+
+// GetBeanBagForecast forecasts when one of the current user's bags will run
+// out, from the brews logged against it. Brews without a dose count as the
+// bag's assumed dose, or defaultDoseGrams if it has none.
+func GetBeanBagForecast(queries *db.Queries, defaultDoseGrams float64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		pref, ok := requestUnits(c, queries)
+		if !ok {
+			return
+		}
+
+		bag, ok := loadOwnedBeanBag(c, queries, c.Param("id"))
+		if !ok {
+			return
+		}
+
+		now := time.Now()
+		usage, err := queries.GetBeanBagUsage(c.Request.Context(), db.GetBeanBagUsageParams{
+			DefaultDoseGrams: defaultDoseGrams,
+			WindowStart:      now.Add(-beans.Window),
+			BeanBagID:        bag.ID,
+		})
+		if err != nil {
+			logger.Error("Failed to get bean bag usage", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBeanBagFetchFailed)
+			return
+		}
+
+		forecast := beans.Predict(beans.Usage{
+			WeightGrams: bag.WeightGrams,
+			UsedGrams:   usage.UsedGrams,
+			RecentGrams: usage.RecentGrams,
+			FirstBrewAt: timePtr(usage.FirstBrewAt),
+		}, int(bag.ReorderLeadDays), now)
+
+		assumedDose := defaultDoseGrams
+		if bag.AssumedDoseGrams != nil {
+			assumedDose = *bag.AssumedDoseGrams
+		}
+
+		resp := BeanForecastResponse{
+			BeanBagID:     bag.ID,
+			BrewCount:     usage.BrewCount,
+			Remaining:     units.FromGrams(forecast.RemainingGrams, pref.Weight),
+			DaysLeft:      forecast.DaysLeft,
+			RunsOutAt:     forecast.RunsOutAt,
+			ReorderBy:     forecast.ReorderBy,
+			ShouldReorder: forecast.ShouldReorder,
+			AssumedDose:   units.FromGrams(assumedDose, pref.Weight),
+			Units:         pref,
+		}
+		if forecast.DailyGrams != nil {
+			v := units.FromGrams(*forecast.DailyGrams, pref.Weight)
+			resp.Daily = &v
+		}
+
+		respond.OK(c, resp)
+	}
+}
+
+// loadOwnedBeanBag fetches one of the current user's bean bags, writing an
+// error response otherwise. Other users' bags are reported as missing.
+func loadOwnedBeanBag(c *gin.Context, queries *db.Queries, bagID string) (db.BeanBag, bool) {
+	bag, err := queries.GetBeanBagByID(c.Request.Context(), bagID)
+	if err != nil && err != pgx.ErrNoRows {
+		logger.Error("Failed to get bean bag", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeBeanBagFetchFailed)
+		return bag, false
+	}
+	if err == pgx.ErrNoRows || bag.OwnerID != c.GetString("user_id") {
+		respond.Error(c, http.StatusNotFound, i18n.CodeBeanBagNotFound)
+		return bag, false
+	}
+	return bag, true
+}
+
+// resolvePrice converts an optional price in major units to minor units of
+// its currency: code if given, else fallback, else the user's preferred
+// currency. It writes an error response for an unknown currency, or a
+// currency sent without a price.
+func resolvePrice(c *gin.Context, queries *db.Queries, price *float64, code, fallback *string) (*int64, *string, bool) {
+	if price == nil {
+		if code != nil {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidRequest)
+			return nil, nil, false
+		}
+		return nil, nil, true
+	}
+
+	if code == nil {
+		code = fallback
+	}
+	if code == nil {
+		prefs, err := queries.GetUserPreferences(c.Request.Context(), c.GetString("user_id"))
+		if err != nil {
+			logger.Error("Failed to get user preferences", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeInternal)
+			return nil, nil, false
+		}
+		code = &prefs.Currency
+	}
+
+	cur, err := currency.Lookup(*code)
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidCurrency)
+		return nil, nil, false
+	}
+	minor := cur.ToMinor(*price)
+	return &minor, &cur.Code, true
+}
+
+// dateParam converts an optional YYYY-MM-DD string, already validated by the
+// datetime binding, to a SQL date
+func dateParam(value *string) pgtype.Date {
+	if value == nil {
+		return pgtype.Date{}
+	}
+	t, _ := time.Parse(time.DateOnly, *value)
+	return pgtype.Date{Time: t, Valid: true}
+}
+
+// gramsPtr converts an optional weight in the caller's unit to grams
+func gramsPtr(value *float64, pref units.Preference) *float64 {
+	if value == nil {
+		return nil
+	}
+	v := units.ToGrams(*value, pref.Weight)
+	return &v
+}
+
+// newBeanBagResponse converts a stored bean bag into the caller's units
+func newBeanBagResponse(bag db.BeanBag, pref units.Preference) BeanBagResponse {
+	resp := BeanBagResponse{
+		ID:              bag.ID,
+		Name:            bag.Name,
+		Roaster:         bag.Roaster,
+		BeanOrigin:      bag.BeanOrigin,
+		Weight:          units.FromGrams(bag.WeightGrams, pref.Weight),
+		RestDays:        bag.RestDays,
+		ReorderLeadDays: bag.ReorderLeadDays,
+		Origin:          newOrigin(bag.OriginCountry, bag.OriginRegion, bag.Farm, bag.Variety, bag.AltitudeM, bag.Process),
+		FinishedAt:      timePtr(bag.FinishedAt),
+		Units:           pref,
+		CreatedAt:       bag.CreatedAt,
+		UpdatedAt:       bag.UpdatedAt,
+	}
+	if bag.RoastedOn.Valid {
+		v := bag.RoastedOn.Time.Format(time.DateOnly)
+		resp.RoastedOn = &v
+	}
+	if bag.AssumedDoseGrams != nil {
+		v := units.FromGrams(*bag.AssumedDoseGrams, pref.Weight)
+		resp.AssumedDose = &v
+	}
+	if bag.PriceMinor != nil && bag.Currency != nil {
+		if cur, err := currency.Lookup(*bag.Currency); err == nil {
+			v := cur.FromMinor(float64(*bag.PriceMinor))
+			resp.Price = &v
+			resp.Currency = bag.Currency
+		}
+	}
+	if bag.PurchasedOn.Valid {
+		v := bag.PurchasedOn.Time.Format(time.DateOnly)
+		resp.PurchasedOn = &v
+	}
+	return resp
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"brewd/internal/billing"
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/links"
+	"brewd/internal/logger"
+	"brewd/internal/plans"
+	"brewd/internal/replay"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// maxStripeEventBytes caps the body of a Stripe webhook event
+const maxStripeEventBytes = 512 << 10
+
+// Web app pages Stripe returns the user to after checkout
+const (
+	checkoutSuccessPath = "settings/billing?checkout=success"
+	checkoutCancelPath  = "settings/billing?checkout=cancelled"
+)
+
+// CheckoutResponse is the Stripe-hosted checkout page to send the user to
+type CheckoutResponse struct {
+	URL string `json:"url"`
+}
+
+// CreateCheckout starts a checkout of the supporter subscription for the
+// current user, creating their Stripe customer on their first checkout.
+// The subscription takes effect when Stripe reports it to the webhook.
+func CreateCheckout(queries *db.Queries, stripe *billing.Stripe, site *links.Site) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if stripe == nil {
+			respond.Error(c, http.StatusServiceUnavailable, i18n.CodeBillingUnavailable)
+			return
+		}
+
+		ctx := c.Request.Context()
+		userID := c.GetString("user_id")
+		user, err := queries.GetUserBilling(ctx, userID)
+		if err != nil {
+			logger.Error("Failed to get user billing", "user_id", userID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeCheckoutFailed)
+			return
+		}
+		if user.Plan == plans.Supporter {
+			respond.Error(c, http.StatusConflict, i18n.CodeAlreadySupporter)
+			return
+		}
+
+		customerID := user.StripeCustomerID
+		if customerID == nil {
+			id, err := stripe.CreateCustomer(ctx, userID, user.Email)
+			if err != nil {
+				logger.Error("Failed to create Stripe customer", "user_id", userID, "error", err)
+				respond.Error(c, http.StatusInternalServerError, i18n.CodeCheckoutFailed)
+				return
+			}
+			// Customer creation is idempotent per user, so a concurrent
+			// checkout that linked first linked the same customer
+			if _, err := queries.SetStripeCustomer(ctx, db.SetStripeCustomerParams{
+				StripeCustomerID: id,
+				ID:               userID,
+			}); err != nil {
+				logger.Error("Failed to save Stripe customer", "user_id", userID, "error", err)
+				respond.Error(c, http.StatusInternalServerError, i18n.CodeCheckoutFailed)
+				return
+			}
+			customerID = &id
+		}
+
+		url, err := stripe.CreateCheckoutSession(ctx, *customerID, userID,
+			site.Join(checkoutSuccessPath), site.Join(checkoutCancelPath))
+		if err != nil {
+			logger.Error("Failed to create Stripe checkout session", "user_id", userID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeCheckoutFailed)
+			return
+		}
+
+		respond.Created(c, CheckoutResponse{URL: url})
+	}
+}
+
+// StripeWebhook applies the subscription changes Stripe reports. Events are
+// verified against webhookSecret, and each signature is accepted once
+// within guard's clock-skew tolerance, so captured deliveries can't be
+// replayed; Stripe signs its retries afresh. Events are applied at most
+// once; events older than the subscription state already applied are
+// ignored, since Stripe doesn't deliver in order. Anything but a 2xx makes
+// Stripe retry.
+func StripeWebhook(queries *db.Queries, tiers *plans.Cache, guard *replay.Guard, webhookSecret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if webhookSecret == "" {
+			respond.Error(c, http.StatusServiceUnavailable, i18n.CodeBillingUnavailable)
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxStripeEventBytes)
+		payload, err := c.GetRawData()
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				respond.Error(c, http.StatusRequestEntityTooLarge, i18n.CodeInvalidRequest)
+				return
+			}
+			respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidRequest)
+			return
+		}
+		header := c.GetHeader("Stripe-Signature")
+		signedAt, err := billing.VerifySignature(payload, header, webhookSecret)
+		if err == nil {
+			err = guard.Check(c.Request.Context(), replay.SourceStripe, header, signedAt, time.Now())
+		}
+		if err != nil {
+			if errors.Is(err, billing.ErrNoSignature) || errors.Is(err, billing.ErrInvalidSignature) ||
+				replay.IsRejection(err) {
+				logger.Warn("Rejected Stripe webhook", "error", err)
+				respond.Error(c, http.StatusBadRequest, i18n.CodeWebhookSignatureInvalid)
+				return
+			}
+			logger.Error("Failed to check Stripe webhook nonce", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeInternal)
+			return
+		}
+
+		var event billing.Event
+		if err := json.Unmarshal(payload, &event); err != nil {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidRequest)
+			return
+		}
+
+		switch event.Type {
+		case billing.EventSubscriptionCreated, billing.EventSubscriptionUpdated, billing.EventSubscriptionDeleted:
+		default:
+			// Stripe only sends the events the endpoint subscribes to;
+			// acknowledge any others so they aren't retried
+			respond.OK(c, nil)
+			return
+		}
+
+		var sub billing.Subscription
+		if err := json.Unmarshal(event.Data.Object, &sub); err != nil {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidRequest)
+			return
+		}
+		params := db.ApplyStripeSubscriptionParams{
+			EventID:           event.ID,
+			EventType:         event.Type,
+			SubscriptionID:    sub.ID,
+			Status:            sub.Status,
+			CancelAtPeriodEnd: sub.CancelAtPeriodEnd,
+			Plan:              billing.PlanFor(sub.Status),
+			EventAt:           pgtype.Timestamptz{Time: time.Unix(event.Created, 0), Valid: true},
+			CustomerID:        sub.Customer,
+		}
+		if end, ok := sub.PeriodEnd(); ok {
+			params.PeriodEnd = pgtype.Timestamptz{Time: end, Valid: true}
+		}
+
+		userID, err := queries.ApplyStripeSubscription(c.Request.Context(), params)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				logger.Info("Stripe event not applied: already processed, stale or for an unknown customer",
+					"event_id", event.ID, "type", event.Type, "customer", sub.Customer)
+				respond.OK(c, nil)
+				return
+			}
+			logger.Error("Failed to apply Stripe subscription", "event_id", event.ID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeInternal)
+			return
+		}
+		tiers.Forget(userID)
+		logger.Info("Applied Stripe subscription", "event_id", event.ID, "type", event.Type,
+			"user_id", userID, "status", sub.Status, "plan", params.Plan)
+
+		respond.OK(c, nil)
+	}
+}
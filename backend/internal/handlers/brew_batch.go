@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+	"brewd/internal/units"
+	"brewd/internal/validation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// BatchBrewRequest represents a batch of up to 50 brews to log. Each item is
+// a Log Brew payload, validated on its own.
+type BatchBrewRequest struct {
+	Brews []json.RawMessage `json:"brews" binding:"required,min=1,max=50"`
+}
+
+// BatchBrewResult is the outcome of one item in a batch, in request order:
+// the logged brew, or the error Log Brew would have returned for it
+type BatchBrewResult struct {
+	Index   int                     `json:"index"`
+	Success bool                    `json:"success"`
+	Data    *BrewResponse           `json:"data,omitempty"`
+	Error   string                  `json:"error,omitempty"`
+	Code    i18n.Code               `json:"code,omitempty"`
+	Details map[string]string       `json:"details,omitempty"`
+	Fields  []validation.FieldError `json:"fields,omitempty"`
+}
+
+// BatchLogBrews logs several brews in one request, for backfilling a paper
+// notebook or uploading brews logged offline. Items are logged one by one
+// and independently: a rejected item doesn't stop the rest, and brews
+// already logged are kept.
+func BatchLogBrews(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req BatchBrewRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		results := make([]BatchBrewResult, 0, len(req.Brews))
+		created := 0
+		for i, raw := range req.Brews {
+			result := logBatchBrew(c, queries, raw)
+			result.Index = i
+			if result.Success {
+				created++
+			}
+			results = append(results, result)
+		}
+
+		respond.OK(c, gin.H{
+			"created": created,
+			"failed":  len(results) - created,
+			"results": results,
+		})
+	}
+}
+
+// logBatchBrew logs one batch item
+func logBatchBrew(c *gin.Context, queries *db.Queries, raw json.RawMessage) BatchBrewResult {
+	var req BrewRequest
+	err := json.Unmarshal(raw, &req)
+	if err == nil {
+		err = binding.Validator.ValidateStruct(&req)
+	}
+	if err != nil {
+		ie := invalidItem(c, err)
+		return BatchBrewResult{Error: ie.Error, Code: ie.Code, Details: ie.Details, Fields: ie.Fields}
+	}
+
+	var brew db.Brew
+	var pref units.Preference
+	if ie, ok := recordItem(c, func() (ok bool) {
+		brew, pref, ok = createBrew(c, queries, req)
+		return ok
+	}); !ok {
+		return BatchBrewResult{Error: ie.Error, Code: ie.Code, Details: ie.Details, Fields: ie.Fields}
+	}
+
+	resp := newBrewResponse(brew, pref)
+	return BatchBrewResult{Success: true, Data: &resp}
+}
+
+// itemError is the error response written for one item of a bulk request
+type itemError struct {
+	Error   string                  `json:"error"`
+	Code    i18n.Code               `json:"code"`
+	Details map[string]string       `json:"details"`
+	Fields  []validation.FieldError `json:"fields"`
+}
+
+// invalidItem is the error for a bulk request item that failed binding
+func invalidItem(c *gin.Context, err error) itemError {
+	tag := i18n.Locale(c)
+	return itemError{
+		Error:   i18n.T(c, i18n.CodeInvalidRequest),
+		Code:    i18n.CodeInvalidRequest,
+		Details: validation.Details(tag, err),
+		Fields:  validation.Errors(tag, err),
+	}
+}
+
+// recordItem runs fn for one item of a bulk request. The single-item
+// helpers write their errors to the response, so fn runs with the response
+// pointed at a recorder, and the error it recorded is returned instead.
+func recordItem(c *gin.Context, fn func() bool) (itemError, bool) {
+	writer := c.Writer
+	rec := &itemRecorder{ResponseWriter: writer}
+	c.Writer = rec
+	ok := fn()
+	c.Writer = writer
+	if ok {
+		return itemError{}, true
+	}
+
+	var ie itemError
+	if err := json.Unmarshal(rec.body.Bytes(), &ie); err != nil || ie.Code == "" {
+		logger.Error("Failed to read item error", "status", rec.status, "error", err)
+		ie = itemError{
+			Error: i18n.T(c, i18n.CodeInternal),
+			Code:  i18n.CodeInternal,
+		}
+	}
+	return ie, false
+}
+
+// itemRecorder captures the response written for one item instead of
+// sending it
+type itemRecorder struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *itemRecorder) WriteHeader(code int) {
+	r.status = code
+}
+
+func (r *itemRecorder) WriteHeaderNow() {}
+
+func (r *itemRecorder) Write(data []byte) (int, error) {
+	return r.body.Write(data)
+}
+
+func (r *itemRecorder) WriteString(s string) (int, error) {
+	return r.body.WriteString(s)
+}
+
+func (r *itemRecorder) Status() int {
+	return r.status
+}
+
+func (r *itemRecorder) Size() int {
+	return r.body.Len()
+}
+
+func (r *itemRecorder) Written() bool {
+	return r.status != 0
+}
@@ -0,0 +1,540 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"io"
+	"net/http"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/drain"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/realtime"
+	"brewd/internal/respond"
+	"brewd/internal/units"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/oklog/ulid/v2"
+)
+
+// Realtime events sent on a brew event's stream
+const (
+	brewEventTimer     = "timer"
+	brewEventRSVP      = "rsvp"
+	brewEventCancelled = "cancelled"
+	brewEventPing      = "ping"
+)
+
+// brewEventListGrace keeps events that started recently in the upcoming
+// list, so brew-alongs in progress can still be joined
+const brewEventListGrace = 3 * time.Hour
+
+// brewEventHeartbeat is how often idle streams are pinged, keeping proxies
+// from closing them
+const brewEventHeartbeat = 25 * time.Second
+
+// BrewEventRequest represents the brew event creation payload. The event
+// pins a revision of a public recipe, the current one when omitted.
+type BrewEventRequest struct {
+	Title          string    `json:"title" binding:"required,max=255"`
+	Description    *string   `json:"description"`
+	RecipeID       string    `json:"recipe_id" binding:"required"`
+	RecipeRevision *int32    `json:"recipe_revision" binding:"omitempty,min=1"`
+	StartsAt       time.Time `json:"starts_at" binding:"required"`
+}
+
+// RSVPRequest represents the RSVP payload
+type RSVPRequest struct {
+	Status string `json:"status" binding:"required,oneof=going maybe not_going"`
+}
+
+// BrewEventResponse is a brew event with its shared recipe in the caller's
+// units. Recipe is nil if the recipe has since been made private.
+type BrewEventResponse struct {
+	ID             string          `json:"id"`
+	HostID         string          `json:"host_id"`
+	Title          string          `json:"title"`
+	Description    *string         `json:"description"`
+	RecipeID       string          `json:"recipe_id"`
+	RecipeRevision int32           `json:"recipe_revision"`
+	Recipe         *RecipeResponse `json:"recipe"`
+	StartsAt       time.Time       `json:"starts_at"`
+	Timer          BrewEventTimer  `json:"timer"`
+	RSVPs          RSVPCounts      `json:"rsvps"`
+	MyRSVP         *string         `json:"my_rsvp"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// BrewEventSummary is an event in the upcoming events list
+type BrewEventSummary struct {
+	ID           string         `json:"id"`
+	HostID       string         `json:"host_id"`
+	HostUsername string         `json:"host_username"`
+	Title        string         `json:"title"`
+	RecipeID     string         `json:"recipe_id"`
+	RecipeName   string         `json:"recipe_name"`
+	StartsAt     time.Time      `json:"starts_at"`
+	Timer        BrewEventTimer `json:"timer"`
+	GoingCount   int64          `json:"going_count"`
+}
+
+// BrewEventTimer is the state of an event's shared timer. Clients count
+// elapsed time from StartedAt, correcting for clock skew with ServerTime.
+type BrewEventTimer struct {
+	Running    bool       `json:"running"`
+	StartedAt  *time.Time `json:"started_at"`
+	EndedAt    *time.Time `json:"ended_at"`
+	ServerTime time.Time  `json:"server_time"`
+}
+
+// RSVPCounts is how many users are going, or maybe going, to an event
+type RSVPCounts struct {
+	Going int64 `json:"going"`
+	Maybe int64 `json:"maybe"`
+}
+
+// RSVPResponse is a user's answer to an event
+type RSVPResponse struct {
+	UserID    string    `json:"user_id"`
+	Username  string    `json:"username,omitempty"`
+	Status    string    `json:"status"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateBrewEvent schedules a brew-along of a public recipe, hosted by the
+// current user
+func CreateBrewEvent(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req BrewEventRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if !req.StartsAt.After(time.Now()) {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeEventStartInPast)
+			return
+		}
+
+		userID := c.GetString("user_id")
+		recipe, rev, ok := loadRecipeRevision(c, queries, req.RecipeID, req.RecipeRevision, userID)
+		if !ok {
+			return
+		}
+		if !recipe.IsPublic {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeEventRecipePrivate)
+			return
+		}
+
+		pref, ok := requestUnits(c, queries)
+		if !ok {
+			return
+		}
+
+		event, err := queries.CreateBrewEvent(c.Request.Context(), db.CreateBrewEventParams{
+			ID:             ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+			HostID:         userID,
+			Title:          req.Title,
+			Description:    req.Description,
+			RecipeID:       recipe.ID,
+			RecipeRevision: rev.Revision,
+			StartsAt:       timestamptz(&req.StartsAt),
+		})
+		if err != nil {
+			logger.Error("Failed to create brew event", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewEventUpdateFailed)
+			return
+		}
+
+		logger.Info("Brew event created", "brew_event_id", event.ID, "recipe_id", recipe.ID)
+
+		resp := newBrewEventResponse(event, RSVPCounts{}, nil)
+		recipeResp := newRecipeResponse(recipe, rev, pref)
+		resp.Recipe = &recipeResp
+		respond.Created(c, resp)
+	}
+}
+
+// ListBrewEvents returns upcoming and in-progress brew events, soonest first
+func ListBrewEvents(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var page PageQuery
+		if err := c.ShouldBindQuery(&page); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if page.Limit == 0 {
+			page.Limit = defaultPageLimit
+		}
+
+		since := time.Now().Add(-brewEventListGrace)
+		rows, err := queries.ListUpcomingBrewEvents(c.Request.Context(), db.ListUpcomingBrewEventsParams{
+			Since:     timestamptz(&since),
+			RowLimit:  page.Limit,
+			RowOffset: page.Offset,
+		})
+		if err != nil {
+			logger.Error("Failed to list brew events", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewEventFetchFailed)
+			return
+		}
+
+		events := make([]BrewEventSummary, 0, len(rows))
+		for _, row := range rows {
+			events = append(events, BrewEventSummary{
+				ID:           row.ID,
+				HostID:       row.HostID,
+				HostUsername: row.HostUsername,
+				Title:        row.Title,
+				RecipeID:     row.RecipeID,
+				RecipeName:   row.RecipeName,
+				StartsAt:     row.StartsAt.Time,
+				Timer:        newBrewEventTimer(row.TimerStartedAt, row.TimerEndedAt),
+				GoingCount:   row.GoingCount,
+			})
+		}
+
+		respond.Page(c, events, page.Limit, page.Offset, len(events))
+	}
+}
+
+// GetBrewEvent returns a brew event with its recipe, RSVP counts and the
+// current user's RSVP
+func GetBrewEvent(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		event, ok := loadBrewEvent(c, queries, false)
+		if !ok {
+			return
+		}
+
+		pref, ok := requestUnits(c, queries)
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+		userID := c.GetString("user_id")
+		counts, err := queries.GetBrewEventRSVPCounts(ctx, event.ID)
+		var myRSVP *string
+		if err == nil {
+			var status string
+			status, err = queries.GetUserBrewEventRSVP(ctx, db.GetUserBrewEventRSVPParams{
+				EventID: event.ID,
+				UserID:  userID,
+			})
+			if err == nil {
+				myRSVP = &status
+			} else if err == pgx.ErrNoRows {
+				err = nil
+			}
+		}
+		var recipe *RecipeResponse
+		if err == nil {
+			recipe, err = brewEventRecipe(ctx, queries, event, userID, pref)
+		}
+		if err != nil {
+			logger.Error("Failed to get brew event details", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewEventFetchFailed)
+			return
+		}
+
+		resp := newBrewEventResponse(event, RSVPCounts(counts), myRSVP)
+		resp.Recipe = recipe
+		respond.OK(c, resp)
+	}
+}
+
+// DeleteBrewEvent cancels one of the current user's events and tells
+// everyone watching it
+func DeleteBrewEvent(queries *db.Queries, hub *realtime.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		event, ok := loadBrewEvent(c, queries, true)
+		if !ok {
+			return
+		}
+
+		if err := queries.DeleteBrewEvent(c.Request.Context(), event.ID); err != nil {
+			logger.Error("Failed to delete brew event", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewEventUpdateFailed)
+			return
+		}
+
+		hub.Publish(brewEventTopic(event.ID), realtime.Message{
+			Event: brewEventCancelled,
+			Data:  gin.H{"event_id": event.ID},
+		})
+
+		respond.OK(c, nil)
+	}
+}
+
+// RSVPBrewEvent records whether the current user is going to an event and
+// broadcasts the new counts
+func RSVPBrewEvent(queries *db.Queries, hub *realtime.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req RSVPRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		event, ok := loadBrewEvent(c, queries, false)
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+		rsvp, err := queries.UpsertBrewEventRSVP(ctx, db.UpsertBrewEventRSVPParams{
+			EventID: event.ID,
+			UserID:  c.GetString("user_id"),
+			Status:  req.Status,
+		})
+		if err != nil {
+			logger.Error("Failed to upsert brew event RSVP", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewEventUpdateFailed)
+			return
+		}
+
+		// The RSVP is saved; a failed count only skips the broadcast
+		if counts, err := queries.GetBrewEventRSVPCounts(ctx, event.ID); err != nil {
+			logger.Warn("Failed to count brew event RSVPs", "brew_event_id", event.ID, "error", err)
+		} else {
+			hub.Publish(brewEventTopic(event.ID), realtime.Message{
+				Event: brewEventRSVP,
+				Data:  RSVPCounts(counts),
+			})
+		}
+
+		respond.OK(c, RSVPResponse{
+			UserID:    rsvp.UserID,
+			Status:    rsvp.Status,
+			UpdatedAt: rsvp.UpdatedAt,
+		})
+	}
+}
+
+// ListBrewEventRSVPs returns the users going or maybe going to an event
+func ListBrewEventRSVPs(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		event, ok := loadBrewEvent(c, queries, false)
+		if !ok {
+			return
+		}
+
+		rows, err := queries.ListBrewEventRSVPs(c.Request.Context(), event.ID)
+		if err != nil {
+			logger.Error("Failed to list brew event RSVPs", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewEventFetchFailed)
+			return
+		}
+
+		rsvps := make([]RSVPResponse, 0, len(rows))
+		for _, row := range rows {
+			rsvps = append(rsvps, RSVPResponse(row))
+		}
+
+		respond.OK(c, rsvps)
+	}
+}
+
+// StartBrewEventTimer starts (or restarts) the shared timer on one of the
+// current user's events and broadcasts it to attendees
+func StartBrewEventTimer(queries *db.Queries, hub *realtime.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		event, ok := loadBrewEvent(c, queries, true)
+		if !ok {
+			return
+		}
+
+		event, err := queries.StartBrewEventTimer(c.Request.Context(), event.ID)
+		if err != nil {
+			logger.Error("Failed to start brew event timer", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeTimerUpdateFailed)
+			return
+		}
+
+		publishBrewEventTimer(c, hub, event)
+	}
+}
+
+// StopBrewEventTimer stops the running shared timer on one of the current
+// user's events and broadcasts it to attendees
+func StopBrewEventTimer(queries *db.Queries, hub *realtime.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		event, ok := loadBrewEvent(c, queries, true)
+		if !ok {
+			return
+		}
+
+		event, err := queries.StopBrewEventTimer(c.Request.Context(), event.ID)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusConflict, i18n.CodeTimerNotRunning)
+				return
+			}
+			logger.Error("Failed to stop brew event timer", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeTimerUpdateFailed)
+			return
+		}
+
+		publishBrewEventTimer(c, hub, event)
+	}
+}
+
+// StreamBrewEvent streams an event's timer, RSVP and presence updates as
+// Server-Sent Events. The stream opens with the current timer, RSVP counts
+// and who is watching, on any instance, then sends each change as it
+// happens. Streams end when the instance shuts down, so shutdown needn't wait
+// out the timeout for them; clients reconnect elsewhere.
+func StreamBrewEvent(queries *db.Queries, hub *realtime.Hub, presence *realtime.Presence, drainer *drain.Coordinator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		event, ok := loadBrewEvent(c, queries, false)
+		if !ok {
+			return
+		}
+
+		// Join before subscribing, so this stream isn't sent its own join,
+		// and subscribe before reading the current state so no change falls
+		// in between the two
+		ctx := c.Request.Context()
+		topic := brewEventTopic(event.ID)
+		leave := presence.Join(ctx, topic, c.GetString("user_id"))
+		defer leave()
+		messages, unsubscribe := hub.Subscribe(topic)
+		defer unsubscribe()
+
+		event, err := queries.GetBrewEventByID(ctx, event.ID)
+		var counts db.GetBrewEventRSVPCountsRow
+		if err == nil {
+			counts, err = queries.GetBrewEventRSVPCounts(ctx, event.ID)
+		}
+		var present realtime.PresenceUpdate
+		if err == nil {
+			present, err = presence.List(ctx, topic)
+		}
+		if err != nil {
+			logger.Error("Failed to get brew event state", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewEventFetchFailed)
+			return
+		}
+
+		c.Header("Cache-Control", "no-cache")
+		c.Header("X-Accel-Buffering", "no")
+		c.SSEvent(brewEventTimer, newBrewEventTimer(event.TimerStartedAt, event.TimerEndedAt))
+		c.SSEvent(brewEventRSVP, RSVPCounts(counts))
+		c.SSEvent(realtime.EventPresence, present)
+		c.Writer.Flush()
+
+		heartbeat := time.NewTicker(brewEventHeartbeat)
+		defer heartbeat.Stop()
+
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case msg, ok := <-messages:
+				if !ok {
+					// Dropped for falling behind; the client reconnects
+					return false
+				}
+				c.SSEvent(msg.Event, msg.Data)
+				return msg.Event != brewEventCancelled
+			case <-heartbeat.C:
+				c.SSEvent(brewEventPing, gin.H{"server_time": time.Now()})
+				return true
+			case <-ctx.Done():
+				return false
+			case <-drainer.Stopping():
+				return false
+			}
+		})
+	}
+}
+
+// loadBrewEvent fetches the :id event, writing an error response otherwise.
+// With hostOnly, users other than the host get 403.
+func loadBrewEvent(c *gin.Context, queries *db.Queries, hostOnly bool) (db.BrewEvent, bool) {
+	event, err := queries.GetBrewEventByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			respond.Error(c, http.StatusNotFound, i18n.CodeBrewEventNotFound)
+			return event, false
+		}
+		logger.Error("Failed to get brew event", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewEventFetchFailed)
+		return event, false
+	}
+	if hostOnly && event.HostID != c.GetString("user_id") {
+		respond.Error(c, http.StatusForbidden, i18n.CodeForbidden)
+		return event, false
+	}
+	return event, true
+}
+
+// brewEventRecipe returns the event's pinned recipe revision for userID, or
+// nil if they can no longer see the recipe
+func brewEventRecipe(ctx context.Context, queries *db.Queries, event db.BrewEvent, userID string, pref units.Preference) (*RecipeResponse, error) {
+	recipe, err := queries.GetVisibleRecipe(ctx, db.GetVisibleRecipeParams{
+		RecipeID: event.RecipeID,
+		ViewerID: userID,
+	})
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rev, err := queries.GetRecipeRevision(ctx, db.GetRecipeRevisionParams{
+		RecipeID: recipe.ID,
+		Revision: event.RecipeRevision,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := newRecipeResponse(recipe, rev, pref)
+	return &resp, nil
+}
+
+// publishBrewEventTimer broadcasts event's timer and writes it as the response
+func publishBrewEventTimer(c *gin.Context, hub *realtime.Hub, event db.BrewEvent) {
+	timer := newBrewEventTimer(event.TimerStartedAt, event.TimerEndedAt)
+	hub.Publish(brewEventTopic(event.ID), realtime.Message{
+		Event: brewEventTimer,
+		Data:  timer,
+	})
+
+	logger.Info("Brew event timer updated", "brew_event_id", event.ID, "running", timer.Running)
+
+	respond.OK(c, timer)
+}
+
+func brewEventTopic(eventID string) string {
+	return "brew_event:" + eventID
+}
+
+func newBrewEventTimer(startedAt, endedAt pgtype.Timestamptz) BrewEventTimer {
+	return BrewEventTimer{
+		Running:    startedAt.Valid && !endedAt.Valid,
+		StartedAt:  timePtr(startedAt),
+		EndedAt:    timePtr(endedAt),
+		ServerTime: time.Now(),
+	}
+}
+
+func newBrewEventResponse(event db.BrewEvent, counts RSVPCounts, myRSVP *string) BrewEventResponse {
+	return BrewEventResponse{
+		ID:             event.ID,
+		HostID:         event.HostID,
+		Title:          event.Title,
+		Description:    event.Description,
+		RecipeID:       event.RecipeID,
+		RecipeRevision: event.RecipeRevision,
+		StartsAt:       event.StartsAt.Time,
+		Timer:          newBrewEventTimer(event.TimerStartedAt, event.TimerEndedAt),
+		RSVPs:          counts,
+		MyRSVP:         myRSVP,
+		CreatedAt:      event.CreatedAt,
+	}
+}
@@ -0,0 +1,933 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"brewd/internal/customfields"
+	"brewd/internal/db"
+	"brewd/internal/fieldset"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/methods"
+	"brewd/internal/respond"
+	"brewd/internal/units"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/oklog/ulid/v2"
+)
+
+// BrewRequest represents the brew creation payload. Dose and water are in the
+// caller's weight unit and water_temp in their temperature unit. Custom
+// fields are keyed by the name of one of the user's custom fields. BrewedAt
+// backdates a brew logged after the fact.
+type BrewRequest struct {
+	Name            string                     `json:"name" binding:"required,max=255"`
+	BrewMethod      *string                    `json:"brew_method" binding:"omitempty,oneof=espresso pour_over french_press aeropress cold_brew drip moka_pot siphon chemex v60 turkish percolator other"`
+	BeanOrigin      *string                    `json:"bean_origin"`
+	Roaster         *string                    `json:"roaster"`
+	Notes           *string                    `json:"notes"`
+	IsPublic        *bool                      `json:"is_public"`
+	Dose            *float64                   `json:"dose" binding:"omitempty,gt=0"`
+	Water           *float64                   `json:"water" binding:"omitempty,gt=0,brew_ratio=dose"`
+	WaterTemp       *float64                   `json:"water_temp" binding:"omitempty,temperature"`
+	GrindSetting    *string                    `json:"grind_setting" binding:"omitempty,max=50,grind_setting"`
+	BrewTimeSeconds *int32                     `json:"brew_time_seconds" binding:"omitempty,gte=0"`
+	StartedAt       *time.Time                 `json:"started_at"`
+	EndedAt         *time.Time                 `json:"ended_at"`
+	RecipeID        *string                    `json:"recipe_id" binding:"omitempty,ulid"`
+	RecipeRevision  *int32                     `json:"recipe_revision" binding:"omitempty,min=1"`
+	BeanBagID       *string                    `json:"bean_bag_id" binding:"omitempty,ulid"`
+	CustomFields    map[string]json.RawMessage `json:"custom_fields"`
+	BrewedAt        *time.Time                 `json:"brewed_at"`
+}
+
+// BrewResponse represents a brew converted to the caller's units. Archived
+// brews were moved to cold storage for their age and are read-only.
+// OrganizationID is set for brews logged in an organization.
+type BrewResponse struct {
+	ID              string           `json:"id"`
+	Name            string           `json:"name"`
+	BrewMethod      *string          `json:"brew_method"`
+	BeanOrigin      *string          `json:"bean_origin"`
+	Roaster         *string          `json:"roaster"`
+	Notes           *string          `json:"notes"`
+	CreatedBy       *string          `json:"created_by"`
+	IsPublic        bool             `json:"is_public"`
+	Dose            *float64         `json:"dose"`
+	Water           *float64         `json:"water"`
+	WaterTemp       *float64         `json:"water_temp"`
+	GrindSetting    *string          `json:"grind_setting"`
+	BrewTimeSeconds *int32           `json:"brew_time_seconds"`
+	StartedAt       *time.Time       `json:"started_at"`
+	EndedAt         *time.Time       `json:"ended_at"`
+	RemindAt        *time.Time       `json:"remind_at"`
+	RecipeID        *string          `json:"recipe_id"`
+	RecipeRevision  *int32           `json:"recipe_revision"`
+	BeanBagID       *string          `json:"bean_bag_id"`
+	OrganizationID  *string          `json:"organization_id"`
+	CustomFields    map[string]any   `json:"custom_fields"`
+	Units           units.Preference `json:"units"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+	Archived        bool             `json:"archived"`
+}
+
+// PageQuery represents limit/offset pagination query parameters
+type PageQuery struct {
+	Limit  int32 `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int32 `form:"offset" binding:"omitempty,min=0"`
+}
+
+const defaultPageLimit = 20
+
+// FieldsQuery selects the fields of each listed item (a sparse fieldset),
+// e.g. fields=id,brew_method,dose; empty selects every field
+type FieldsQuery struct {
+	Fields string `form:"fields" binding:"omitempty,max=500"`
+}
+
+// Fields that list endpoints allow selecting: those of the item type
+var (
+	brewFields      = fieldset.Of[BrewResponse]()
+	clubShareFields = fieldset.Of[ClubShareResponse]()
+)
+
+// brewedAtSkew allows for client clocks running slightly ahead when a brew
+// is backdated
+const brewedAtSkew = 5 * time.Minute
+
+// ListBrewsQuery represents the brew history query parameters; drafts=include
+// adds the user's brew drafts
+type ListBrewsQuery struct {
+	PageQuery
+	FieldsQuery
+	Drafts string `form:"drafts" binding:"omitempty,oneof=include exclude"`
+}
+
+// CreateBrew logs a new brew for the current user
+func CreateBrew(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req BrewRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		brew, pref, ok := createBrew(c, queries, req)
+		if !ok {
+			return
+		}
+
+		respond.Created(c, newBrewResponse(brew, pref))
+	}
+}
+
+// createBrew validates a brew creation payload and logs the brew for the
+// current user. It writes an error response when the brew is rejected.
+func createBrew(c *gin.Context, queries *db.Queries, req BrewRequest) (db.Brew, units.Preference, bool) {
+	params, pref, ok := prepareBrew(c, queries, req)
+	if !ok {
+		return db.Brew{}, pref, false
+	}
+	params.ID = ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String()
+	return insertBrew(c, queries, params, pref)
+}
+
+// insertBrew logs a prepared brew, writing an error response on failure
+func insertBrew(c *gin.Context, queries *db.Queries, params db.CreateBrewParams, pref units.Preference) (db.Brew, units.Preference, bool) {
+	brew, err := queries.CreateBrew(c.Request.Context(), params)
+	if err != nil {
+		logger.Error("Failed to create brew", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewCreateFailed)
+		return db.Brew{}, pref, false
+	}
+
+	brewLogged(c, queries, *params.CreatedBy)
+	return brew, pref, true
+}
+
+// prepareBrew validates a brew payload and resolves it into the brew's
+// columns: parameters in grams and Celsius, defaults from the recipe and
+// bag, and encoded custom fields. The ID is left to the caller. It writes
+// an error response when the payload is rejected.
+func prepareBrew(c *gin.Context, queries *db.Queries, req BrewRequest) (db.CreateBrewParams, units.Preference, bool) {
+	pref, ok := requestUnits(c, queries)
+	if !ok {
+		return db.CreateBrewParams{}, pref, false
+	}
+
+	doseGrams, waterGrams, waterTempC, ok := canonicalParams(c, pref, req.Dose, req.Water, req.WaterTemp)
+	if !ok {
+		return db.CreateBrewParams{}, pref, false
+	}
+
+	if req.BrewedAt != nil && req.BrewedAt.After(time.Now().Add(brewedAtSkew)) {
+		respond.Error(c, http.StatusBadRequest, i18n.CodeBrewedAtInFuture)
+		return db.CreateBrewParams{}, pref, false
+	}
+
+	// A completed session determines the brew time unless one was given
+	brewTime := req.BrewTimeSeconds
+	if req.EndedAt != nil {
+		if req.StartedAt == nil || req.EndedAt.Before(*req.StartedAt) {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidTimeRange)
+			return db.CreateBrewParams{}, pref, false
+		}
+		if brewTime == nil {
+			elapsed := int32(req.EndedAt.Sub(*req.StartedAt) / time.Second)
+			brewTime = &elapsed
+		}
+	}
+
+	userID := c.GetString("user_id")
+	brewMethod, grindSetting := req.BrewMethod, req.GrindSetting
+
+	// Pin the brew to a recipe revision (the current one unless given);
+	// parameters left out of the request come from that revision
+	var recipeRevision *int32
+	if req.RecipeID != nil {
+		recipe, rev, ok := loadRecipeRevision(c, queries, *req.RecipeID, req.RecipeRevision, userID)
+		if !ok {
+			return db.CreateBrewParams{}, pref, false
+		}
+		recipeRevision = &rev.Revision
+		if brewMethod == nil {
+			brewMethod = recipe.BrewMethod
+		}
+		if doseGrams == nil {
+			doseGrams = rev.DoseGrams
+		}
+		if waterGrams == nil {
+			waterGrams = rev.WaterGrams
+		}
+		if waterTempC == nil {
+			waterTempC = rev.WaterTempC
+		}
+		if grindSetting == nil {
+			grindSetting = rev.GrindSetting
+		}
+		if brewTime == nil {
+			brewTime = rev.BrewTimeSeconds
+		}
+	} else if req.RecipeRevision != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidRequest)
+		return db.CreateBrewParams{}, pref, false
+	}
+
+	// Bean details left out of the request come from the bag
+	beanOrigin, roaster := req.BeanOrigin, req.Roaster
+	if req.BeanBagID != nil {
+		bag, ok := loadOwnedBeanBag(c, queries, *req.BeanBagID)
+		if !ok {
+			return db.CreateBrewParams{}, pref, false
+		}
+		if beanOrigin == nil {
+			beanOrigin = bag.BeanOrigin
+		}
+		if roaster == nil {
+			roaster = bag.Roaster
+		}
+	}
+
+	if !checkMethod(c, pref, brewMethod, methods.Values{
+		DoseGrams:       doseGrams,
+		WaterGrams:      waterGrams,
+		WaterTempC:      waterTempC,
+		BrewTimeSeconds: brewTime,
+		GrindSetting:    grindSetting,
+	}) {
+		return db.CreateBrewParams{}, pref, false
+	}
+
+	customFields, ok := checkCustomFields(c, queries, req.CustomFields)
+	if !ok {
+		return db.CreateBrewParams{}, pref, false
+	}
+
+	isPublic := true
+	if req.IsPublic != nil {
+		isPublic = *req.IsPublic
+	}
+
+	return db.CreateBrewParams{
+		Name:            req.Name,
+		BrewMethod:      brewMethod,
+		BeanOrigin:      beanOrigin,
+		Roaster:         roaster,
+		Notes:           req.Notes,
+		CreatedBy:       &userID,
+		IsPublic:        &isPublic,
+		DoseGrams:       doseGrams,
+		WaterGrams:      waterGrams,
+		WaterTempC:      waterTempC,
+		GrindSetting:    grindSetting,
+		BrewTimeSeconds: brewTime,
+		StartedAt:       timestamptz(req.StartedAt),
+		EndedAt:         timestamptz(req.EndedAt),
+		RecipeID:        req.RecipeID,
+		RecipeRevision:  recipeRevision,
+		BeanBagID:       req.BeanBagID,
+		CustomFields:    customFields,
+		CreatedAt:       timestamptz(req.BrewedAt),
+	}, pref, true
+}
+
+// QuickBrewRequest represents the quick log payload: just the method and,
+// optionally, the bag of beans
+type QuickBrewRequest struct {
+	BrewMethod string  `json:"brew_method" binding:"required,oneof=espresso pour_over french_press aeropress cold_brew drip moka_pot siphon chemex v60 turkish percolator other"`
+	BeanBagID  *string `json:"bean_bag_id" binding:"omitempty,ulid"`
+}
+
+// QuickLogBrew logs a brew from a one-tap automation such as an Apple
+// Shortcut. Everything but the method and bag is copied from the user's
+// latest brew with that method, preferring one from the same bag; with no
+// earlier brew it is named after the method. Without a bag, the earlier
+// brew's bag is reused while it's open.
+func QuickLogBrew(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req QuickBrewRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		pref, ok := requestUnits(c, queries)
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+		userID := c.GetString("user_id")
+		isPublic := true
+		params := db.CreateBrewParams{
+			ID:         ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+			BrewMethod: &req.BrewMethod,
+			CreatedBy:  &userID,
+			IsPublic:   &isPublic,
+		}
+
+		last, err := queries.GetLatestSimilarBrew(ctx, db.GetLatestSimilarBrewParams{
+			CreatedBy:  &userID,
+			BrewMethod: &req.BrewMethod,
+			BeanBagID:  req.BeanBagID,
+		})
+		switch {
+		case err == nil:
+			params.Name = last.Name
+			params.BeanOrigin = last.BeanOrigin
+			params.Roaster = last.Roaster
+			if last.IsPublic != nil {
+				params.IsPublic = last.IsPublic
+			}
+			params.DoseGrams = last.DoseGrams
+			params.WaterGrams = last.WaterGrams
+			params.WaterTempC = last.WaterTempC
+			params.GrindSetting = last.GrindSetting
+			params.BrewTimeSeconds = last.BrewTimeSeconds
+			params.RecipeID = last.RecipeID
+			params.RecipeRevision = last.RecipeRevision
+			params.CustomFields = last.CustomFields
+		case err == pgx.ErrNoRows:
+			method, _ := methods.Lookup(req.BrewMethod)
+			params.Name = method.Name
+		default:
+			logger.Error("Failed to get latest similar brew", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewFetchFailed)
+			return
+		}
+
+		switch {
+		case req.BeanBagID != nil:
+			bag, ok := loadOwnedBeanBag(c, queries, *req.BeanBagID)
+			if !ok {
+				return
+			}
+			params.BeanBagID = &bag.ID
+			// Bean details copied from a brew of another bag don't apply
+			if last.BeanBagID == nil || *last.BeanBagID != bag.ID {
+				params.BeanOrigin, params.Roaster = bag.BeanOrigin, bag.Roaster
+			}
+		case last.BeanBagID != nil:
+			bag, err := queries.GetBeanBagByID(ctx, *last.BeanBagID)
+			if err == nil && !bag.FinishedAt.Valid {
+				params.BeanBagID = &bag.ID
+			}
+		}
+
+		if !checkMethod(c, pref, params.BrewMethod, methods.Values{
+			DoseGrams:       params.DoseGrams,
+			WaterGrams:      params.WaterGrams,
+			WaterTempC:      params.WaterTempC,
+			BrewTimeSeconds: params.BrewTimeSeconds,
+			GrindSetting:    params.GrindSetting,
+		}) {
+			return
+		}
+
+		brew, err := queries.CreateBrew(ctx, params)
+		if err != nil {
+			logger.Error("Failed to quick log brew", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewCreateFailed)
+			return
+		}
+
+		brewLogged(c, queries, userID)
+
+		respond.Created(c, newBrewResponse(brew, pref))
+	}
+}
+
+// DuplicateBrewRequest represents the duplicate options: whether the new
+// draft keeps the bean (bag, origin and roaster) and the equipment (brew
+// method and grind setting) of the brew it copies
+type DuplicateBrewRequest struct {
+	CarryBean      bool `json:"carry_bean"`
+	CarryEquipment bool `json:"carry_equipment"`
+}
+
+// DuplicateBrew starts a draft brew from one of the current user's brews
+// ("brew it again"). The name, recipe, parameters, visibility and custom
+// fields are copied; notes and timings belong to the earlier cup and are
+// left out. The bean and equipment are copied on request, and the bag only
+// while it's still open.
+func DuplicateBrew(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req DuplicateBrewRequest
+		// The body is optional; an empty one copies neither bean nor equipment
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				respond.Invalid(c, err)
+				return
+			}
+		}
+
+		brew, ok := loadBrew(c, queries)
+		if !ok {
+			return
+		}
+
+		pref, ok := requestUnits(c, queries)
+		if !ok {
+			return
+		}
+
+		doc := brewDocument(brew, pref)
+		doc.Notes = nil
+		doc.StartedAt, doc.EndedAt = nil, nil
+		if !req.CarryBean {
+			doc.BeanBagID, doc.BeanOrigin, doc.Roaster = nil, nil, nil
+		} else if doc.BeanBagID != nil {
+			bag, err := queries.GetBeanBagByID(c.Request.Context(), *doc.BeanBagID)
+			if err != nil || bag.FinishedAt.Valid {
+				doc.BeanBagID = nil
+			}
+		}
+		if !req.CarryEquipment {
+			doc.BrewMethod, doc.GrindSetting = nil, nil
+		}
+
+		// The document is built from stored values, so it always encodes
+		encoded, _ := json.Marshal(doc)
+		data, ok := mergeDraftData(c, draftKindBrew, nil, encoded)
+		if !ok {
+			return
+		}
+
+		draft, ok := insertDraft(c, queries, draftKindBrew, data)
+		if !ok {
+			return
+		}
+
+		logger.Info("Brew duplicated", "brew_id", brew.ID, "draft_id", draft.ID)
+
+		respond.Created(c, newDraftResponse(draft))
+	}
+}
+
+// brewLogged queues the follow-up work for a newly logged brew: checking
+// challenges. Automation webhooks are queued through the outbox when the
+// brew is inserted.
+func brewLogged(c *gin.Context, queries *db.Queries, userID string) {
+	// Challenges are checked by the badge worker
+	if err := queries.QueueBadgeEvaluation(c.Request.Context(), userID); err != nil {
+		logger.Warn("Failed to queue badge evaluation", "user_id", userID, "error", err)
+	}
+}
+
+// GetBrew returns a single brew visible to the current user, or one of
+// their archived brews
+func GetBrew(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		brew, archived, ok := loadHistoryBrew(c, queries)
+		if !ok {
+			return
+		}
+
+		pref, ok := requestUnits(c, queries)
+		if !ok {
+			return
+		}
+
+		resp := newBrewResponse(brew, pref)
+		resp.Archived = archived
+		respond.OK(c, resp)
+	}
+}
+
+// UpdateBrew applies a JSON Merge Patch to one of the current user's brews.
+// The patch applies to the brew as a Log Brew payload in the caller's units,
+// and the result is validated as one; fields left out keep their values.
+func UpdateBrew(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		brew, ok := loadBrew(c, queries)
+		if !ok {
+			return
+		}
+
+		pref, ok := requestUnits(c, queries)
+		if !ok {
+			return
+		}
+
+		var req BrewRequest
+		touched, ok := bindMergePatch(c, brewDocument(brew, pref), &req)
+		if !ok {
+			return
+		}
+
+		params, _, ok := prepareBrew(c, queries, req)
+		if !ok {
+			return
+		}
+
+		// Parameters the patch left alone keep their stored values rather
+		// than taking a round trip through the caller's units
+		if !touched["dose"] {
+			params.DoseGrams = brew.DoseGrams
+		}
+		if !touched["water"] {
+			params.WaterGrams = brew.WaterGrams
+		}
+		if !touched["water_temp"] {
+			params.WaterTempC = brew.WaterTempC
+		}
+		// Organization brews stay private to the organization
+		if brew.OrganizationID != nil {
+			isPublic := false
+			params.IsPublic = &isPublic
+		}
+
+		updated, ok := saveBrew(c, queries, brew.ID, params, nil)
+		if !ok {
+			return
+		}
+
+		respond.OK(c, newBrewResponse(updated, pref))
+	}
+}
+
+// saveBrew replaces a prepared brew's columns, writing an error response on
+// failure. A nil vector leaves the sync vector for the server to bump.
+func saveBrew(c *gin.Context, queries *db.Queries, id string, params db.CreateBrewParams, vector []byte) (db.Brew, bool) {
+	brew, err := queries.UpdateBrew(c.Request.Context(), db.UpdateBrewParams{
+		Name:            params.Name,
+		BrewMethod:      params.BrewMethod,
+		BeanOrigin:      params.BeanOrigin,
+		Roaster:         params.Roaster,
+		Notes:           params.Notes,
+		IsPublic:        params.IsPublic,
+		DoseGrams:       params.DoseGrams,
+		WaterGrams:      params.WaterGrams,
+		WaterTempC:      params.WaterTempC,
+		GrindSetting:    params.GrindSetting,
+		BrewTimeSeconds: params.BrewTimeSeconds,
+		StartedAt:       params.StartedAt,
+		EndedAt:         params.EndedAt,
+		RecipeID:        params.RecipeID,
+		RecipeRevision:  params.RecipeRevision,
+		BeanBagID:       params.BeanBagID,
+		CustomFields:    params.CustomFields,
+		CreatedAt:       params.CreatedAt,
+		SyncVector:      vector,
+		ID:              id,
+		CreatedBy:       params.CreatedBy,
+	})
+	if err != nil {
+		logger.Error("Failed to update brew", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewUpdateFailed)
+		return db.Brew{}, false
+	}
+	return brew, true
+}
+
+// ListBrews returns the current user's brew history, newest first,
+// including archived brews. Drafts are left out unless requested, and then
+// listed separately on the first page since they aren't brews yet.
+func ListBrews(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query ListBrewsQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		page := query.PageQuery
+		if page.Limit == 0 {
+			page.Limit = defaultPageLimit
+		}
+		fields, ok := parseFields(c, query.Fields, brewFields)
+		if !ok {
+			return
+		}
+
+		pref, ok := requestUnits(c, queries)
+		if !ok {
+			return
+		}
+
+		userID := c.GetString("user_id")
+		brews, err := queries.ListUserBrewHistory(c.Request.Context(), db.ListUserBrewHistoryParams{
+			CreatedBy: &userID,
+			Limit:     page.Limit,
+			Offset:    page.Offset,
+		})
+		if err != nil {
+			logger.Error("Failed to list brews", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewFetchFailed)
+			return
+		}
+
+		items := make([]BrewResponse, 0, len(brews))
+		for _, row := range brews {
+			item := newBrewResponse(row.Brew, pref)
+			item.Archived = row.Archived
+			items = append(items, item)
+		}
+
+		resp := respond.Envelope{
+			Success: true,
+			Data:    fieldset.Select(items, fields),
+			Meta:    &respond.Meta{Limit: page.Limit, Offset: page.Offset, Count: len(items)},
+		}
+		if query.Drafts == "include" && page.Offset == 0 {
+			kind := draftKindBrew
+			drafts, ok := listDrafts(c, queries, &kind)
+			if !ok {
+				return
+			}
+			resp.Included = map[string]any{"drafts": drafts}
+		}
+
+		respond.Write(c, http.StatusOK, resp)
+	}
+}
+
+// parseFields parses a sparse fieldset against the endpoint's allowlist,
+// writing an error response when it names another field
+func parseFields(c *gin.Context, selection string, allowed fieldset.Allowlist) ([]string, bool) {
+	fields, err := fieldset.Parse(selection, allowed)
+	if err != nil {
+		var unknown *fieldset.UnknownFieldError
+		errors.As(err, &unknown)
+		respond.Errorf(c, http.StatusBadRequest, i18n.CodeUnknownField, unknown.Field)
+		return nil, false
+	}
+	return fields, true
+}
+
+// requestUnits resolves the caller's units, writing an error response on failure
+func requestUnits(c *gin.Context, queries *db.Queries) (units.Preference, bool) {
+	pref, err := resolveUnits(c, queries)
+	if err == nil {
+		return pref, true
+	}
+
+	if err == units.ErrUnknownSystem {
+		respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidUnits)
+		return pref, false
+	}
+
+	logger.Error("Failed to resolve unit preferences", "error", err)
+	respond.Error(c, http.StatusInternalServerError, i18n.CodeInternal)
+	return pref, false
+}
+
+// loadBrew fetches the :id brew for the current user to change, writing an
+// error response unless mayChangeBrew lets them with orgRoles. Brews the
+// user may not change are reported as missing.
+func loadBrew(c *gin.Context, queries *db.Queries, orgRoles ...string) (db.Brew, bool) {
+	ctx := c.Request.Context()
+	brew, err := queries.GetBrewByID(ctx, c.Param("id"))
+	if err != nil && err != pgx.ErrNoRows {
+		logger.Error("Failed to get brew", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewFetchFailed)
+		return brew, false
+	}
+	allowed := false
+	if err == nil {
+		if allowed, err = mayChangeBrew(ctx, queries, brew, c.GetString("user_id"), orgRoles...); err != nil {
+			logger.Error("Failed to get organization member role", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewFetchFailed)
+			return brew, false
+		}
+	}
+	if !allowed {
+		respond.Error(c, http.StatusNotFound, i18n.CodeBrewNotFound)
+		return brew, false
+	}
+	return brew, true
+}
+
+// loadVisibleBrew fetches the :id brew if the current user may see it: it's
+// theirs, public, or shared with them. Brews they can't see are reported as
+// missing.
+func loadVisibleBrew(c *gin.Context, queries *db.Queries) (db.Brew, bool) {
+	brew, err := queries.GetVisibleBrew(c.Request.Context(), db.GetVisibleBrewParams{
+		BrewID:   c.Param("id"),
+		ViewerID: c.GetString("user_id"),
+	})
+	if err == pgx.ErrNoRows {
+		respond.Error(c, http.StatusNotFound, i18n.CodeBrewNotFound)
+		return brew, false
+	}
+	if err != nil {
+		logger.Error("Failed to get brew", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewFetchFailed)
+		return brew, false
+	}
+	return brew, true
+}
+
+// loadHistoryBrew fetches the :id brew like loadVisibleBrew, falling back
+// to the current user's archived brews, and reports whether it's archived.
+// Others' archived brews are reported as missing.
+func loadHistoryBrew(c *gin.Context, queries *db.Queries) (db.Brew, bool, bool) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+	archived := false
+	brew, err := queries.GetVisibleBrew(ctx, db.GetVisibleBrewParams{
+		BrewID:   c.Param("id"),
+		ViewerID: userID,
+	})
+	if err == pgx.ErrNoRows {
+		archived = true
+		brew, err = queries.GetArchivedBrew(ctx, db.GetArchivedBrewParams{
+			ID:        c.Param("id"),
+			CreatedBy: &userID,
+		})
+	}
+	if err == pgx.ErrNoRows {
+		respond.Error(c, http.StatusNotFound, i18n.CodeBrewNotFound)
+		return brew, false, false
+	}
+	if err != nil {
+		logger.Error("Failed to get brew", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewFetchFailed)
+		return brew, false, false
+	}
+	return brew, archived, true
+}
+
+// ownsBrew reports whether userID logged brew
+func ownsBrew(brew db.Brew, userID string) bool {
+	return brew.CreatedBy != nil && *brew.CreatedBy == userID
+}
+
+// mayChangeBrew reports whether userID may change brew: they logged it and,
+// for an organization brew, are still a member of the organization, with
+// one of orgRoles if any are given
+func mayChangeBrew(ctx context.Context, queries *db.Queries, brew db.Brew, userID string, orgRoles ...string) (bool, error) {
+	if !ownsBrew(brew, userID) {
+		return false, nil
+	}
+	if brew.OrganizationID == nil {
+		return true, nil
+	}
+	role, err := queries.GetOrganizationMemberRole(ctx, db.GetOrganizationMemberRoleParams{
+		OrganizationID: *brew.OrganizationID,
+		UserID:         userID,
+	})
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return len(orgRoles) == 0 || slices.Contains(orgRoles, role), nil
+}
+
+// newBrewResponse converts a stored brew into the caller's units
+func newBrewResponse(brew db.Brew, pref units.Preference) BrewResponse {
+	resp := BrewResponse{
+		ID:              brew.ID,
+		Name:            brew.Name,
+		BrewMethod:      brew.BrewMethod,
+		BeanOrigin:      brew.BeanOrigin,
+		Roaster:         brew.Roaster,
+		Notes:           brew.Notes,
+		CreatedBy:       brew.CreatedBy,
+		IsPublic:        brew.IsPublic == nil || *brew.IsPublic,
+		GrindSetting:    brew.GrindSetting,
+		BrewTimeSeconds: brew.BrewTimeSeconds,
+		StartedAt:       timePtr(brew.StartedAt),
+		EndedAt:         timePtr(brew.EndedAt),
+		RemindAt:        timePtr(brew.RemindAt),
+		RecipeID:        brew.RecipeID,
+		RecipeRevision:  brew.RecipeRevision,
+		BeanBagID:       brew.BeanBagID,
+		OrganizationID:  brew.OrganizationID,
+		CustomFields:    customfields.Decode(brew.CustomFields),
+		Units:           pref,
+		CreatedAt:       brew.CreatedAt,
+		UpdatedAt:       brew.UpdatedAt,
+	}
+
+	if brew.DoseGrams != nil {
+		v := units.FromGrams(*brew.DoseGrams, pref.Weight)
+		resp.Dose = &v
+	}
+	if brew.WaterGrams != nil {
+		v := units.FromGrams(*brew.WaterGrams, pref.Weight)
+		resp.Water = &v
+	}
+	if brew.WaterTempC != nil {
+		v := units.FromCelsius(*brew.WaterTempC, pref.Temperature)
+		resp.WaterTemp = &v
+	}
+
+	return resp
+}
+
+// brewDocument returns brew as the Log Brew payload that would recreate it,
+// in the caller's units. BrewedAt is left out so the brew keeps its time.
+func brewDocument(brew db.Brew, pref units.Preference) BrewRequest {
+	resp := newBrewResponse(brew, pref)
+	var customFields map[string]json.RawMessage
+	_ = json.Unmarshal(brew.CustomFields, &customFields)
+
+	return BrewRequest{
+		Name:            resp.Name,
+		BrewMethod:      resp.BrewMethod,
+		BeanOrigin:      resp.BeanOrigin,
+		Roaster:         resp.Roaster,
+		Notes:           resp.Notes,
+		IsPublic:        &resp.IsPublic,
+		Dose:            resp.Dose,
+		Water:           resp.Water,
+		WaterTemp:       resp.WaterTemp,
+		GrindSetting:    resp.GrindSetting,
+		BrewTimeSeconds: resp.BrewTimeSeconds,
+		StartedAt:       resp.StartedAt,
+		EndedAt:         resp.EndedAt,
+		RecipeID:        resp.RecipeID,
+		RecipeRevision:  resp.RecipeRevision,
+		BeanBagID:       resp.BeanBagID,
+		CustomFields:    customFields,
+	}
+}
+
+// canonicalParams converts dose, water and water temperature from the
+// caller's units to grams and Celsius, writing an error response when the
+// temperature is out of range
+func canonicalParams(c *gin.Context, pref units.Preference, dose, water, waterTemp *float64) (doseGrams, waterGrams, waterTempC *float64, ok bool) {
+	if dose != nil {
+		v := units.ToGrams(*dose, pref.Weight)
+		doseGrams = &v
+	}
+	if water != nil {
+		v := units.ToGrams(*water, pref.Weight)
+		waterGrams = &v
+	}
+	if waterTemp != nil {
+		v := units.ToCelsius(*waterTemp, pref.Temperature)
+		if v < 0 || v > 100 {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeWaterTempOutOfRange)
+			return nil, nil, nil, false
+		}
+		waterTempC = &v
+	}
+	return doseGrams, waterGrams, waterTempC, true
+}
+
+// checkMethod validates canonical parameters against the brew method schema,
+// writing an error response when they don't fit. No method means no schema.
+func checkMethod(c *gin.Context, pref units.Preference, brewMethod *string, v methods.Values) bool {
+	if brewMethod == nil {
+		return true
+	}
+	method, _ := methods.Lookup(*brewMethod)
+	fieldErrs := method.Validate(v)
+	if len(fieldErrs) == 0 {
+		return true
+	}
+	respond.Details(c, http.StatusBadRequest, i18n.CodeBrewParamsInvalid, methodErrorDetails(c, fieldErrs, pref))
+	return false
+}
+
+// methodParamFields maps method schema parameters to BrewRequest field names
+var methodParamFields = map[string]string{
+	methods.ParamDose:         "dose",
+	methods.ParamWater:        "water",
+	methods.ParamWaterTemp:    "water_temp",
+	methods.ParamBrewTime:     "brew_time_seconds",
+	methods.ParamGrindSetting: "grind_setting",
+	methods.ParamRatio:        "ratio",
+}
+
+// methodErrorDetails localizes method schema errors per request field, with
+// ranges shown in the caller's units
+func methodErrorDetails(c *gin.Context, errs []methods.FieldError, pref units.Preference) map[string]string {
+	details := make(map[string]string, len(errs))
+	for _, fe := range errs {
+		field := methodParamFields[fe.Param]
+		if fe.Rule == methods.RuleRequired {
+			details[field] = i18n.T(c, i18n.CodeParamRequired)
+			continue
+		}
+		lo, hi := formatParam(fe.Param, fe.Range.Min, pref), formatParam(fe.Param, fe.Range.Max, pref)
+		details[field] = i18n.Tf(c, i18n.CodeParamOutOfRange, lo, hi)
+	}
+	return details
+}
+
+// formatParam renders a canonical parameter value in the caller's units
+func formatParam(param string, value float64, pref units.Preference) string {
+	switch param {
+	case methods.ParamDose, methods.ParamWater:
+		return strconv.FormatFloat(units.FromGrams(value, pref.Weight), 'f', -1, 64) + " " + string(pref.Weight)
+	case methods.ParamWaterTemp:
+		return strconv.FormatFloat(units.FromCelsius(value, pref.Temperature), 'f', -1, 64) + " °" + strings.ToUpper(string(pref.Temperature))
+	case methods.ParamBrewTime:
+		return strconv.FormatFloat(value, 'f', -1, 64) + " s"
+	case methods.ParamRatio:
+		return "1:" + strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+}
+
+// timestamptz converts an optional time to a nullable timestamptz
+func timestamptz(t *time.Time) pgtype.Timestamptz {
+	if t == nil {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: *t, Valid: true}
+}
+
+// timePtr converts a nullable timestamptz to an optional time
+func timePtr(ts pgtype.Timestamptz) *time.Time {
+	if !ts.Valid {
+		return nil
+	}
+	return &ts.Time
+}
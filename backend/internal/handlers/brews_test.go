@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/fieldset"
+	"brewd/internal/respond"
+	"brewd/internal/units"
+	"brewd/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FuzzBrewRequestBinding binds brew payloads as CreateBrew does, and as
+// merge patches as UpdateBrew does: neither may panic, a rejected payload
+// must get a 400, and an accepted one must satisfy its binding rules.
+func FuzzBrewRequestBinding(f *testing.F) {
+	if err := validation.Register(); err != nil {
+		f.Fatal(err)
+	}
+	gin.SetMode(gin.TestMode)
+
+	for _, seed := range []string{
+		`{"name":"Morning V60","brew_method":"v60","dose":18,"water":300,"water_temp":94,"grind_setting":"medium-fine","brew_time_seconds":180}`,
+		`{"name":"Backdated","started_at":"2026-03-14T08:30:00Z","ended_at":"2026-03-14T08:33:00Z","brewed_at":"2026-03-13T08:30:00Z"}`,
+		`{"name":"From a recipe","recipe_id":"01JQ7Z5V6N8T2K4M9R3B1C0D7E","recipe_revision":2,"custom_fields":{"bloom":40,"kettle":"Fellow"}}`,
+		`{"name":"","dose":-1,"water":1e309,"water_temp":"hot"}`,
+		`{"name":null,"brew_method":"percolator","custom_fields":[]}`,
+		`{"dose":18,"water":0.5}`,
+		`{"name":"x","grind_setting":"\u0000\ud800"}`,
+		`[]`,
+		`null`,
+		`{`,
+		``,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	doc := brewDocument(db.Brew{
+		ID:        "01JQ7Z5V6N8T2K4M9R3B1C0D7E",
+		Name:      "Morning V60",
+		CreatedAt: time.Date(2026, 3, 14, 8, 30, 0, 0, time.UTC),
+	}, units.Metric)
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		for _, contentType := range []string{"application/json", mergePatchContentType} {
+			c, rec := fuzzContext(contentType, body)
+			var req BrewRequest
+			var ok bool
+			if contentType == mergePatchContentType {
+				_, ok = bindMergePatch(c, doc, &req)
+			} else if err := c.ShouldBindJSON(&req); err != nil {
+				respond.Invalid(c, err)
+			} else {
+				ok = true
+			}
+
+			if !ok {
+				if rec.Code < http.StatusBadRequest || rec.Code >= http.StatusInternalServerError {
+					t.Fatalf("%s: rejected with status %d: %s", contentType, rec.Code, rec.Body.String())
+				}
+				continue
+			}
+			if req.Name == "" || len(req.Name) > 255 {
+				t.Errorf("%s: accepted name %q", contentType, req.Name)
+			}
+			if req.Dose != nil && *req.Dose <= 0 {
+				t.Errorf("%s: accepted dose %v", contentType, *req.Dose)
+			}
+			if req.Water != nil && *req.Water <= 0 {
+				t.Errorf("%s: accepted water %v", contentType, *req.Water)
+			}
+			if req.BrewTimeSeconds != nil && *req.BrewTimeSeconds < 0 {
+				t.Errorf("%s: accepted brew time %d", contentType, *req.BrewTimeSeconds)
+			}
+			if req.RecipeRevision != nil && *req.RecipeRevision < 1 {
+				t.Errorf("%s: accepted recipe revision %d", contentType, *req.RecipeRevision)
+			}
+		}
+	})
+}
+
+// fuzzContext returns a context for a request with body, and its recorder
+func fuzzContext(contentType string, body []byte) (*gin.Context, *httptest.ResponseRecorder) {
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/brews", bytes.NewReader(body))
+	c.Request.Header.Set("Content-Type", contentType)
+	return c, rec
+}
+
+// BenchmarkBrewListJSON encodes a page of brews in the envelope, whole and
+// as a sparse fieldset, for a page and for a long history
+func BenchmarkBrewListJSON(b *testing.B) {
+	for _, n := range []int{100, 1000} {
+		for _, fields := range []string{"", "id,brew_method,dose,created_at"} {
+			name := fmt.Sprintf("n=%d", n)
+			if fields != "" {
+				name += "/fields=" + fields
+			}
+			b.Run(name, func(b *testing.B) {
+				items := benchBrews(n)
+				var selection []string
+				if fields != "" {
+					var err error
+					if selection, err = fieldset.Parse(fields, fieldset.Of[BrewResponse]()); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportAllocs()
+				for b.Loop() {
+					var data any = items
+					if selection != nil {
+						data = fieldset.Select(items, selection)
+					}
+					env := respond.Envelope{Success: true, Data: data, Meta: &respond.Meta{Limit: int32(n), Count: n}}
+					if _, err := json.Marshal(env); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// benchBrews is n brews with every field set, as a user's history lists them
+func benchBrews(n int) []BrewResponse {
+	method, origin, roaster, grind := "v60", "Ethiopia", "Onyx", "18 clicks"
+	notes := "Bright, floral and sweet; a touch under-extracted"
+	user := benchID(0)
+	dose, water, temp := 18.0, 300.0, 94.0
+	seconds := int32(180)
+
+	items := make([]BrewResponse, n)
+	for i := range items {
+		at := benchTime.Add(-time.Duration(i) * 24 * time.Hour)
+		ended := at.Add(3 * time.Minute)
+		items[i] = BrewResponse{
+			ID:              benchID(i),
+			Name:            fmt.Sprintf("Morning V60 #%d", i),
+			BrewMethod:      &method,
+			BeanOrigin:      &origin,
+			Roaster:         &roaster,
+			Notes:           &notes,
+			CreatedBy:       &user,
+			IsPublic:        i%2 == 0,
+			Dose:            &dose,
+			Water:           &water,
+			WaterTemp:       &temp,
+			GrindSetting:    &grind,
+			BrewTimeSeconds: &seconds,
+			StartedAt:       &at,
+			EndedAt:         &ended,
+			CustomFields:    map[string]any{"bloom_seconds": 45, "filter": "Cafec Abaca"},
+			Units:           units.Metric,
+			CreatedAt:       at,
+			UpdatedAt:       ended,
+		}
+	}
+	return items
+}
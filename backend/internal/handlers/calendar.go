@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"brewd/internal/calendar"
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/links"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Calendar feed contents: entries from calendarFeedPast ago onwards, at most
+// calendarFeedLimit of each kind
+const (
+	calendarFeedPast  = 30 * 24 * time.Hour
+	calendarFeedLimit = 500
+)
+
+// brewEventDuration is how long a brew-along is shown as lasting until its
+// timer has been stopped
+const brewEventDuration = time.Hour
+
+// calendarCacheControl lets calendar clients reuse a feed for a few minutes;
+// after that they revalidate it with its ETag
+const calendarCacheControl = "private, max-age=300"
+
+// CalendarFeedResponse is the current user's calendar feed URL. WebcalURL is
+// the same URL with the webcal scheme, which opens a subscription dialog in
+// most calendar apps.
+type CalendarFeedResponse struct {
+	URL       string `json:"url"`
+	WebcalURL string `json:"webcal_url"`
+}
+
+// GetCalendarFeed returns the signed URL of the current user's calendar feed
+func GetCalendarFeed(queries *db.Queries, signer *calendar.Signer, site *links.Site) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+		version, err := queries.GetCalendarFeedVersion(c.Request.Context(), userID)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusNotFound, i18n.CodeUserNotFound)
+				return
+			}
+			logger.Error("Failed to get calendar feed version", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeCalendarFeedFetchFailed)
+			return
+		}
+
+		respond.OK(c, newCalendarFeedResponse(site, signer, userID, version))
+	}
+}
+
+// ResetCalendarFeed revokes the current user's calendar feed URL and returns
+// a new one; calendars subscribed to the old URL stop updating
+func ResetCalendarFeed(queries *db.Queries, signer *calendar.Signer, site *links.Site) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+		version, err := queries.ResetCalendarFeed(c.Request.Context(), userID)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusNotFound, i18n.CodeUserNotFound)
+				return
+			}
+			logger.Error("Failed to reset calendar feed", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeCalendarFeedUpdateFailed)
+			return
+		}
+
+		respond.OK(c, newCalendarFeedResponse(site, signer, userID, version))
+	}
+}
+
+// ServeCalendarFeed serves a user's iCalendar feed at
+// /calendar/:user_id/:signature.ics: brew-alongs they host or are going to
+// and the days their open bean bags finish resting. The feed is built on
+// every request, so it reflects changes as soon as clients refetch it; the
+// ETag lets them skip unchanged feeds.
+func ServeCalendarFeed(queries *db.Queries, signer *calendar.Signer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		userID := c.Param("user_id")
+		signature, found := strings.CutSuffix(c.Param("file"), ".ics")
+		if !found {
+			respondCalendarFeedNotFound(c)
+			return
+		}
+		version, err := queries.GetCalendarFeedVersion(ctx, userID)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respondCalendarFeedNotFound(c)
+				return
+			}
+			respondCalendarFeedError(c, err)
+			return
+		}
+		if !signer.Verify(userID, version, signature) {
+			respondCalendarFeedNotFound(c)
+			return
+		}
+
+		since := time.Now().Add(-calendarFeedPast)
+		brewEvents, err := queries.ListCalendarBrewEvents(ctx, db.ListCalendarBrewEventsParams{
+			UserID:   userID,
+			Since:    pgtype.Timestamptz{Time: since, Valid: true},
+			RowLimit: calendarFeedLimit,
+		})
+		if err != nil {
+			respondCalendarFeedError(c, err)
+			return
+		}
+		bags, err := queries.ListCalendarRestingBeanBags(ctx, db.ListCalendarRestingBeanBagsParams{
+			OwnerID:  userID,
+			Since:    pgtype.Date{Time: since, Valid: true},
+			RowLimit: calendarFeedLimit,
+		})
+		if err != nil {
+			respondCalendarFeedError(c, err)
+			return
+		}
+
+		events := make([]calendar.Event, 0, len(brewEvents)+len(bags))
+		for _, e := range brewEvents {
+			events = append(events, newBrewEventCalendarEvent(e))
+		}
+		for _, bag := range bags {
+			events = append(events, newRestingCalendarEvent(bag))
+		}
+
+		var buf bytes.Buffer
+		if err := calendar.Write(&buf, "brewd", events); err != nil {
+			respondCalendarFeedError(c, err)
+			return
+		}
+
+		sum := sha256.Sum256(buf.Bytes())
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		c.Header("ETag", etag)
+		c.Header("Cache-Control", calendarCacheControl)
+		if strings.Contains(c.GetHeader("If-None-Match"), etag) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+		c.Data(http.StatusOK, calendar.ContentType, buf.Bytes())
+	}
+}
+
+// newCalendarFeedResponse builds userID's feed URL for version under the web
+// app's base URL, which proxies it here
+func newCalendarFeedResponse(site *links.Site, signer *calendar.Signer, userID string, version int32) CalendarFeedResponse {
+	feedURL := site.Join("calendar", userID, signer.Sign(userID, version)+".ics")
+	_, rest, _ := strings.Cut(feedURL, "://")
+	return CalendarFeedResponse{
+		URL:       feedURL,
+		WebcalURL: "webcal://" + rest,
+	}
+}
+
+// newBrewEventCalendarEvent lists a brew-along, tentatively if the user is
+// only maybe going. It ends when its timer was stopped, or brewEventDuration
+// after it starts.
+func newBrewEventCalendarEvent(e db.ListCalendarBrewEventsRow) calendar.Event {
+	end := e.StartsAt.Time.Add(brewEventDuration)
+	if e.TimerEndedAt.Valid && e.TimerEndedAt.Time.After(e.StartsAt.Time) {
+		end = e.TimerEndedAt.Time
+	}
+	status := calendar.StatusConfirmed
+	if e.RsvpStatus != nil && *e.RsvpStatus == "maybe" {
+		status = calendar.StatusTentative
+	}
+
+	description := fmt.Sprintf("Brew-along of %s hosted by %s", e.RecipeName, e.HostUsername)
+	if e.Description != nil && *e.Description != "" {
+		description += "\n\n" + *e.Description
+	}
+	return calendar.Event{
+		UID:         "brew-event-" + e.ID + "@brewd",
+		Summary:     e.Title,
+		Description: description,
+		Status:      status,
+		Start:       e.StartsAt.Time,
+		End:         end,
+		Modified:    e.UpdatedAt,
+	}
+}
+
+// newRestingCalendarEvent lists the day a bean bag finishes resting as an
+// all-day event
+func newRestingCalendarEvent(bag db.ListCalendarRestingBeanBagsRow) calendar.Event {
+	description := "Roasted on " + bag.RoastedOn.Time.Format(time.DateOnly)
+	if bag.Roaster != nil && *bag.Roaster != "" {
+		description += " by " + *bag.Roaster
+	}
+	if bag.RestDays != nil {
+		description += fmt.Sprintf(", rested %d days", *bag.RestDays)
+	}
+	return calendar.Event{
+		UID:         "bean-bag-" + bag.ID + "-rested@brewd",
+		Summary:     bag.Name + " is ready to brew",
+		Description: description,
+		Status:      calendar.StatusConfirmed,
+		Start:       bag.ReadyOn.Time,
+		End:         bag.ReadyOn.Time.AddDate(0, 0, 1),
+		AllDay:      true,
+		Modified:    bag.UpdatedAt,
+	}
+}
+
+func respondCalendarFeedNotFound(c *gin.Context) {
+	respond.Error(c, http.StatusNotFound, i18n.CodeCalendarFeedNotFound)
+}
+
+func respondCalendarFeedError(c *gin.Context, err error) {
+	logger.Error("Failed to build calendar feed", "error", err)
+	respond.Error(c, http.StatusInternalServerError, i18n.CodeCalendarFeedFetchFailed)
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+
+	"brewd/internal/captcha"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCaptcha returns what a client needs to present the CAPTCHA: the
+// provider and its site key, or a fresh proof-of-work puzzle
+func GetCaptcha(verifier captcha.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		challenge, err := verifier.Challenge()
+		if err != nil {
+			logger.Error("Failed to create captcha challenge", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeInternal)
+			return
+		}
+		respond.OK(c, challenge)
+	}
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"net/http"
+	"time"
+
+	"brewd/internal/clubs"
+	"brewd/internal/db"
+	"brewd/internal/fieldset"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+	"brewd/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/oklog/ulid/v2"
+)
+
+// ClubShareRequest represents a club feed share payload; exactly one of
+// BrewID and RecipeID is required
+type ClubShareRequest struct {
+	BrewID   *string `json:"brew_id" binding:"required_without=RecipeID,excluded_with=RecipeID,omitempty,ulid"`
+	RecipeID *string `json:"recipe_id" binding:"required_without=BrewID,excluded_with=BrewID,omitempty,ulid"`
+	Note     *string `json:"note" binding:"omitempty,max=2000"`
+}
+
+// ClubShareResponse is a brew or recipe shared to a club feed
+type ClubShareResponse struct {
+	ID         string    `json:"id"`
+	ItemType   string    `json:"item_type"`
+	UserID     string    `json:"user_id"`
+	Username   string    `json:"username"`
+	BrewID     *string   `json:"brew_id,omitempty"`
+	RecipeID   *string   `json:"recipe_id,omitempty"`
+	Name       *string   `json:"name"`
+	BrewMethod *string   `json:"brew_method"`
+	BeanOrigin *string   `json:"bean_origin,omitempty"`
+	Note       *string   `json:"note"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ListClubFeedQuery represents the club feed query parameters
+type ListClubFeedQuery struct {
+	PageQuery
+	FieldsQuery
+}
+
+// ShareToClub shares one of the current user's brews, or a recipe they own
+// or that is public, to a club they belong to
+func ShareToClub(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ClubShareRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		club, _, ok := loadClub(c, queries, clubs.RoleOwner, clubs.RoleModerator, clubs.RoleMember)
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+		userID := c.GetString("user_id")
+		item := ClubShareResponse{
+			UserID:   userID,
+			Username: c.GetString("username"),
+			Note:     req.Note,
+		}
+		if req.BrewID != nil {
+			brew, err := queries.GetBrewByID(ctx, *req.BrewID)
+			if err != nil && err != pgx.ErrNoRows {
+				logger.Error("Failed to get brew", "error", err)
+				respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewFetchFailed)
+				return
+			}
+			allowed := false
+			if err == nil {
+				// Like ShareBrew, only an organization's owners and admins
+				// share its brews outside it
+				if allowed, err = mayChangeBrew(ctx, queries, brew, userID, tenant.RoleOwner, tenant.RoleAdmin); err != nil {
+					logger.Error("Failed to get organization member role", "error", err)
+					respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewFetchFailed)
+					return
+				}
+			}
+			if !allowed {
+				respond.Error(c, http.StatusNotFound, i18n.CodeBrewNotFound)
+				return
+			}
+			item.ItemType = "brew"
+			item.BrewID = &brew.ID
+			item.Name = &brew.Name
+			item.BrewMethod = brew.BrewMethod
+			item.BeanOrigin = brew.BeanOrigin
+		} else {
+			recipe, _, ok := loadVisibleRecipe(c, queries, *req.RecipeID, userID)
+			if !ok {
+				return
+			}
+			if recipe.OwnerID != userID && !recipe.IsPublic {
+				respond.Error(c, http.StatusForbidden, i18n.CodeClubRecipePrivate)
+				return
+			}
+			item.ItemType = "recipe"
+			item.RecipeID = &recipe.ID
+			item.Name = &recipe.Name
+			item.BrewMethod = recipe.BrewMethod
+		}
+
+		share, err := queries.CreateClubShare(ctx, db.CreateClubShareParams{
+			ID:       ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+			ClubID:   club.ID,
+			UserID:   userID,
+			BrewID:   req.BrewID,
+			RecipeID: req.RecipeID,
+			Note:     req.Note,
+		})
+		if err != nil {
+			logger.Error("Failed to create club share", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeClubUpdateFailed)
+			return
+		}
+		item.ID = share.ID
+		item.CreatedAt = share.CreatedAt
+
+		respond.Created(c, item)
+	}
+}
+
+// ListClubFeed returns the shares in a club the current user belongs to,
+// newest first
+func ListClubFeed(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query ListClubFeedQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		page := query.PageQuery
+		if page.Limit == 0 {
+			page.Limit = defaultPageLimit
+		}
+		fields, ok := parseFields(c, query.Fields, clubShareFields)
+		if !ok {
+			return
+		}
+
+		club, _, ok := loadClub(c, queries, clubs.RoleOwner, clubs.RoleModerator, clubs.RoleMember)
+		if !ok {
+			return
+		}
+
+		rows, err := queries.ListClubFeed(c.Request.Context(), db.ListClubFeedParams{
+			ClubID: club.ID,
+			Limit:  page.Limit,
+			Offset: page.Offset,
+		})
+		if err != nil {
+			logger.Error("Failed to list club feed", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeClubFetchFailed)
+			return
+		}
+
+		feed := make([]ClubShareResponse, 0, len(rows))
+		for _, row := range rows {
+			item := ClubShareResponse{
+				ID:        row.ID,
+				UserID:    row.UserID,
+				Username:  row.Username,
+				Note:      row.Note,
+				CreatedAt: row.CreatedAt,
+			}
+			if row.BrewID != nil {
+				item.ItemType = "brew"
+				item.BrewID = row.BrewID
+				item.Name = row.BrewName
+				item.BrewMethod = row.BrewMethod
+				item.BeanOrigin = row.BeanOrigin
+			} else {
+				item.ItemType = "recipe"
+				item.RecipeID = row.RecipeID
+				item.Name = row.RecipeName
+				item.BrewMethod = row.RecipeBrewMethod
+			}
+			feed = append(feed, item)
+		}
+
+		respond.Page(c, fieldset.Select(feed, fields), page.Limit, page.Offset, len(feed))
+	}
+}
+
+// DeleteClubShare removes a share from a club feed. Sharers can remove their
+// own shares; moderators and the owner can remove any.
+func DeleteClubShare(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		club, role, ok := loadClub(c, queries, clubs.RoleOwner, clubs.RoleModerator, clubs.RoleMember)
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+		share, err := queries.GetClubShare(ctx, db.GetClubShareParams{
+			ID:     c.Param("share_id"),
+			ClubID: club.ID,
+		})
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusNotFound, i18n.CodeClubShareNotFound)
+				return
+			}
+			logger.Error("Failed to get club share", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeClubFetchFailed)
+			return
+		}
+		if share.UserID != c.GetString("user_id") && !clubs.CanModerate(role) {
+			respond.Error(c, http.StatusForbidden, i18n.CodeForbidden)
+			return
+		}
+
+		if err := queries.DeleteClubShare(ctx, share.ID); err != nil {
+			logger.Error("Failed to delete club share", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeClubUpdateFailed)
+			return
+		}
+
+		respond.OK(c, nil)
+	}
+}
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"net/http"
+	"time"
+
+	"brewd/internal/clubs"
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/oklog/ulid/v2"
+)
+
+// defaultInviteHours is how long invite links last when no expiry is given
+const defaultInviteHours = 7 * 24
+
+// ClubInviteRequest represents the invite link creation payload
+type ClubInviteRequest struct {
+	MaxUses        *int32 `json:"max_uses" binding:"omitempty,min=1,max=1000"`
+	ExpiresInHours *int32 `json:"expires_in_hours" binding:"omitempty,min=1,max=720"`
+}
+
+// ClubInviteResponse is an invite link. Token is only returned when the
+// link is created.
+type ClubInviteResponse struct {
+	ID        string     `json:"id"`
+	Token     string     `json:"token,omitempty"`
+	MaxUses   *int32     `json:"max_uses"`
+	UseCount  int32      `json:"use_count"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	CreatedBy *string    `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ClubInvitePreview is what an invite link shows before it is accepted
+type ClubInvitePreview struct {
+	ClubID          string     `json:"club_id"`
+	ClubName        string     `json:"club_name"`
+	ClubDescription *string    `json:"club_description"`
+	IsPrivate       bool       `json:"is_private"`
+	ExpiresAt       *time.Time `json:"expires_at"`
+	IsMember        bool       `json:"is_member"`
+}
+
+// CreateClubInvite creates an invite link to a club the current user
+// moderates
+func CreateClubInvite(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ClubInviteRequest
+		// The body is optional; an empty one creates a default link
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				respond.Invalid(c, err)
+				return
+			}
+		}
+
+		club, _, ok := loadClub(c, queries, clubs.RoleOwner, clubs.RoleModerator)
+		if !ok {
+			return
+		}
+
+		token, hash, err := clubs.NewInviteToken()
+		if err != nil {
+			logger.Error("Failed to generate club invite token", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeClubUpdateFailed)
+			return
+		}
+
+		hours := int32(defaultInviteHours)
+		if req.ExpiresInHours != nil {
+			hours = *req.ExpiresInHours
+		}
+		expiresAt := time.Now().Add(time.Duration(hours) * time.Hour)
+
+		userID := c.GetString("user_id")
+		invite, err := queries.CreateClubInvite(c.Request.Context(), db.CreateClubInviteParams{
+			ID:        ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+			ClubID:    club.ID,
+			CreatedBy: &userID,
+			TokenHash: hash,
+			MaxUses:   req.MaxUses,
+			ExpiresAt: timestamptz(&expiresAt),
+		})
+		if err != nil {
+			logger.Error("Failed to create club invite", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeClubUpdateFailed)
+			return
+		}
+
+		resp := newClubInviteResponse(invite)
+		resp.Token = token
+		respond.Created(c, resp)
+	}
+}
+
+// ListClubInvites returns the unrevoked invite links of a club the current
+// user moderates
+func ListClubInvites(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		club, _, ok := loadClub(c, queries, clubs.RoleOwner, clubs.RoleModerator)
+		if !ok {
+			return
+		}
+
+		invites, err := queries.ListClubInvites(c.Request.Context(), club.ID)
+		if err != nil {
+			logger.Error("Failed to list club invites", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeClubFetchFailed)
+			return
+		}
+
+		resp := make([]ClubInviteResponse, 0, len(invites))
+		for _, invite := range invites {
+			resp = append(resp, newClubInviteResponse(invite))
+		}
+
+		respond.OK(c, resp)
+	}
+}
+
+// RevokeClubInvite disables an invite link to a club the current user
+// moderates
+func RevokeClubInvite(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		club, _, ok := loadClub(c, queries, clubs.RoleOwner, clubs.RoleModerator)
+		if !ok {
+			return
+		}
+
+		revoked, err := queries.RevokeClubInvite(c.Request.Context(), db.RevokeClubInviteParams{
+			ID:     c.Param("invite_id"),
+			ClubID: club.ID,
+		})
+		if err != nil {
+			logger.Error("Failed to revoke club invite", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeClubUpdateFailed)
+			return
+		}
+		if revoked == 0 {
+			respond.Error(c, http.StatusNotFound, i18n.CodeClubInviteNotFound)
+			return
+		}
+
+		respond.OK(c, nil)
+	}
+}
+
+// GetClubInvite previews the club an invite link leads to
+func GetClubInvite(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		preview, ok := loadClubInvite(c, queries)
+		if !ok {
+			return
+		}
+
+		respond.OK(c, preview)
+	}
+}
+
+// AcceptClubInvite joins the club an invite link leads to. Accepting as an
+// existing member does nothing and doesn't use up the link.
+func AcceptClubInvite(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		preview, ok := loadClubInvite(c, queries)
+		if !ok {
+			return
+		}
+
+		if !preview.IsMember {
+			_, err := queries.RedeemClubInvite(c.Request.Context(), db.RedeemClubInviteParams{
+				TokenHash: clubs.HashInviteToken(c.Param("token")),
+				UserID:    c.GetString("user_id"),
+			})
+			if err != nil {
+				// No row: the last use was taken, or the link expired or was
+				// revoked, since it was previewed
+				if err == pgx.ErrNoRows {
+					respond.Error(c, http.StatusNotFound, i18n.CodeClubInviteInvalid)
+					return
+				}
+				logger.Error("Failed to redeem club invite", "error", err)
+				respond.Error(c, http.StatusInternalServerError, i18n.CodeClubUpdateFailed)
+				return
+			}
+			logger.Info("Club invite redeemed", "club_id", preview.ClubID)
+		}
+
+		respond.OK(c, gin.H{
+			"club_id": preview.ClubID,
+		})
+	}
+}
+
+// loadClubInvite previews the usable invite for the :token link, writing an
+// error response otherwise
+func loadClubInvite(c *gin.Context, queries *db.Queries) (ClubInvitePreview, bool) {
+	ctx := c.Request.Context()
+	invite, err := queries.GetClubInviteByTokenHash(ctx, clubs.HashInviteToken(c.Param("token")))
+	isMember := false
+	if err == nil {
+		_, err = queries.GetClubMemberRole(ctx, db.GetClubMemberRoleParams{
+			ClubID: invite.ClubID,
+			UserID: c.GetString("user_id"),
+		})
+		isMember = err == nil
+		if err == pgx.ErrNoRows {
+			err = nil
+		}
+	}
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			respond.Error(c, http.StatusNotFound, i18n.CodeClubInviteInvalid)
+			return ClubInvitePreview{}, false
+		}
+		logger.Error("Failed to get club invite", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeClubFetchFailed)
+		return ClubInvitePreview{}, false
+	}
+
+	return ClubInvitePreview{
+		ClubID:          invite.ClubID,
+		ClubName:        invite.ClubName,
+		ClubDescription: invite.ClubDescription,
+		IsPrivate:       invite.ClubIsPrivate,
+		ExpiresAt:       timePtr(invite.ExpiresAt),
+		IsMember:        isMember,
+	}, true
+}
+
+func newClubInviteResponse(invite db.ClubInvite) ClubInviteResponse {
+	return ClubInviteResponse{
+		ID:        invite.ID,
+		MaxUses:   invite.MaxUses,
+		UseCount:  invite.UseCount,
+		ExpiresAt: timePtr(invite.ExpiresAt),
+		CreatedBy: invite.CreatedBy,
+		CreatedAt: invite.CreatedAt,
+	}
+}
@@ -0,0 +1,435 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"net/http"
+	"time"
+
+	"brewd/internal/clubs"
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/oklog/ulid/v2"
+)
+
+// ClubRequest represents the club creation payload
+type ClubRequest struct {
+	Name        string  `json:"name" binding:"required,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=2000"`
+	IsPrivate   bool    `json:"is_private"`
+}
+
+// UpdateClubRequest represents the club update payload; omitted fields are
+// left unchanged
+type UpdateClubRequest struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=2000"`
+	IsPrivate   *bool   `json:"is_private"`
+}
+
+// UpdateClubMemberRequest represents the member role change payload
+type UpdateClubMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=moderator member"`
+}
+
+// ClubResponse is a club as seen by the current user. MyRole is nil for
+// non-members.
+type ClubResponse struct {
+	ID          string    `json:"id"`
+	OwnerID     string    `json:"owner_id"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description"`
+	IsPrivate   bool      `json:"is_private"`
+	MemberCount int64     `json:"member_count"`
+	MyRole      *string   `json:"my_role"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ClubSummary is a club in a list of clubs. Role is only set in the current
+// user's own list.
+type ClubSummary struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description"`
+	IsPrivate   bool      `json:"is_private"`
+	MemberCount int64     `json:"member_count"`
+	Role        *string   `json:"role,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ClubMemberResponse is a club member
+type ClubMemberResponse struct {
+	UserID    string    `json:"user_id"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateClub creates a club owned by the current user
+func CreateClub(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ClubRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		club, err := queries.CreateClub(c.Request.Context(), db.CreateClubParams{
+			ID:          ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+			OwnerID:     c.GetString("user_id"),
+			Name:        req.Name,
+			Description: req.Description,
+			IsPrivate:   req.IsPrivate,
+		})
+		if err != nil {
+			logger.Error("Failed to create club", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeClubUpdateFailed)
+			return
+		}
+
+		logger.Info("Club created", "club_id", club.ID)
+
+		respond.Created(c, newClubResponse(club, 1, clubs.RoleOwner))
+	}
+}
+
+// ListClubs returns public clubs, newest first
+func ListClubs(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var page PageQuery
+		if err := c.ShouldBindQuery(&page); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if page.Limit == 0 {
+			page.Limit = defaultPageLimit
+		}
+
+		rows, err := queries.ListPublicClubs(c.Request.Context(), db.ListPublicClubsParams{
+			Limit:  page.Limit,
+			Offset: page.Offset,
+		})
+		if err != nil {
+			logger.Error("Failed to list public clubs", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeClubFetchFailed)
+			return
+		}
+
+		summaries := make([]ClubSummary, 0, len(rows))
+		for _, row := range rows {
+			summaries = append(summaries, ClubSummary{
+				ID:          row.ID,
+				Name:        row.Name,
+				Description: row.Description,
+				MemberCount: row.MemberCount,
+				CreatedAt:   row.CreatedAt,
+			})
+		}
+
+		respond.Page(c, summaries, page.Limit, page.Offset, len(summaries))
+	}
+}
+
+// ListMyClubs returns the clubs the current user belongs to, with their role
+func ListMyClubs(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rows, err := queries.ListUserClubs(c.Request.Context(), c.GetString("user_id"))
+		if err != nil {
+			logger.Error("Failed to list user clubs", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeClubFetchFailed)
+			return
+		}
+
+		summaries := make([]ClubSummary, 0, len(rows))
+		for _, row := range rows {
+			summaries = append(summaries, ClubSummary{
+				ID:          row.ID,
+				Name:        row.Name,
+				Description: row.Description,
+				IsPrivate:   row.IsPrivate,
+				MemberCount: row.MemberCount,
+				Role:        &row.Role,
+				CreatedAt:   row.CreatedAt,
+			})
+		}
+
+		respond.OK(c, summaries)
+	}
+}
+
+// GetClub returns a club visible to the current user
+func GetClub(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		club, role, ok := loadClub(c, queries)
+		if !ok {
+			return
+		}
+
+		count, err := queries.CountClubMembers(c.Request.Context(), club.ID)
+		if err != nil {
+			logger.Error("Failed to count club members", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeClubFetchFailed)
+			return
+		}
+
+		respond.OK(c, newClubResponse(club, count, role))
+	}
+}
+
+// UpdateClub edits one of the current user's clubs
+func UpdateClub(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req UpdateClubRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		club, _, ok := loadClub(c, queries, clubs.RoleOwner)
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+		updated, err := queries.UpdateClub(ctx, db.UpdateClubParams{
+			Name:        req.Name,
+			Description: req.Description,
+			IsPrivate:   req.IsPrivate,
+			ID:          club.ID,
+		})
+		var count int64
+		if err == nil {
+			count, err = queries.CountClubMembers(ctx, club.ID)
+		}
+		if err != nil {
+			logger.Error("Failed to update club", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeClubUpdateFailed)
+			return
+		}
+
+		respond.OK(c, newClubResponse(updated, count, clubs.RoleOwner))
+	}
+}
+
+// DeleteClub deletes one of the current user's clubs with its members,
+// invites and feed
+func DeleteClub(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		club, _, ok := loadClub(c, queries, clubs.RoleOwner)
+		if !ok {
+			return
+		}
+
+		if err := queries.DeleteClub(c.Request.Context(), club.ID); err != nil {
+			logger.Error("Failed to delete club", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeClubUpdateFailed)
+			return
+		}
+
+		logger.Info("Club deleted", "club_id", club.ID)
+
+		respond.OK(c, nil)
+	}
+}
+
+// JoinClub adds the current user to a public club. Private clubs are joined
+// through invite links instead.
+func JoinClub(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		club, role, ok := loadClub(c, queries)
+		if !ok {
+			return
+		}
+
+		if role == "" {
+			if _, err := queries.AddClubMember(c.Request.Context(), db.AddClubMemberParams{
+				ClubID: club.ID,
+				UserID: c.GetString("user_id"),
+			}); err != nil {
+				logger.Error("Failed to add club member", "error", err)
+				respond.Error(c, http.StatusInternalServerError, i18n.CodeClubUpdateFailed)
+				return
+			}
+			role = clubs.RoleMember
+		}
+
+		respond.OK(c, gin.H{
+			"club_id": club.ID,
+			"role":    role,
+		})
+	}
+}
+
+// ListClubMembers returns a visible club's members, owner and moderators
+// first
+func ListClubMembers(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		club, _, ok := loadClub(c, queries)
+		if !ok {
+			return
+		}
+
+		rows, err := queries.ListClubMembers(c.Request.Context(), club.ID)
+		if err != nil {
+			logger.Error("Failed to list club members", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeClubFetchFailed)
+			return
+		}
+
+		members := make([]ClubMemberResponse, 0, len(rows))
+		for _, row := range rows {
+			members = append(members, ClubMemberResponse(row))
+		}
+
+		respond.OK(c, members)
+	}
+}
+
+// UpdateClubMemberRole makes a member of one of the current user's clubs a
+// moderator, or back to a member
+func UpdateClubMemberRole(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req UpdateClubMemberRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		club, _, ok := loadClub(c, queries, clubs.RoleOwner)
+		if !ok {
+			return
+		}
+		if c.Param("user_id") == club.OwnerID {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeClubOwnerCannotLeave)
+			return
+		}
+
+		member, err := queries.UpdateClubMemberRole(c.Request.Context(), db.UpdateClubMemberRoleParams{
+			ClubID: club.ID,
+			UserID: c.Param("user_id"),
+			Role:   req.Role,
+		})
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusNotFound, i18n.CodeClubMemberNotFound)
+				return
+			}
+			logger.Error("Failed to update club member role", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeClubUpdateFailed)
+			return
+		}
+
+		respond.OK(c, gin.H{
+			"user_id": member.UserID,
+			"role":    member.Role,
+		})
+	}
+}
+
+// RemoveClubMember removes a member from a club. Members can leave; the
+// owner and moderators can remove anyone below them. The owner can't leave.
+func RemoveClubMember(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		club, role, ok := loadClub(c, queries, clubs.RoleOwner, clubs.RoleModerator, clubs.RoleMember)
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+		targetID := c.Param("user_id")
+		if targetID == club.OwnerID {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeClubOwnerCannotLeave)
+			return
+		}
+		if targetID != c.GetString("user_id") {
+			targetRole, err := queries.GetClubMemberRole(ctx, db.GetClubMemberRoleParams{
+				ClubID: club.ID,
+				UserID: targetID,
+			})
+			if err != nil && err != pgx.ErrNoRows {
+				logger.Error("Failed to get club member role", "error", err)
+				respond.Error(c, http.StatusInternalServerError, i18n.CodeClubUpdateFailed)
+				return
+			}
+			if err == nil && !clubs.Outranks(role, targetRole) {
+				respond.Error(c, http.StatusForbidden, i18n.CodeForbidden)
+				return
+			}
+		}
+
+		removed, err := queries.RemoveClubMember(ctx, db.RemoveClubMemberParams{
+			ClubID: club.ID,
+			UserID: targetID,
+		})
+		if err != nil {
+			logger.Error("Failed to remove club member", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeClubUpdateFailed)
+			return
+		}
+		if removed == 0 {
+			respond.Error(c, http.StatusNotFound, i18n.CodeClubMemberNotFound)
+			return
+		}
+
+		respond.OK(c, nil)
+	}
+}
+
+// loadClub fetches the :id club and the current user's role in it ("" for
+// non-members), writing an error response otherwise. Private clubs are
+// reported as missing to non-members. When roles are given, users without
+// one of them get 403.
+func loadClub(c *gin.Context, queries *db.Queries, roles ...string) (db.Club, string, bool) {
+	ctx := c.Request.Context()
+	club, err := queries.GetClubByID(ctx, c.Param("id"))
+	role := ""
+	if err == nil {
+		role, err = queries.GetClubMemberRole(ctx, db.GetClubMemberRoleParams{
+			ClubID: club.ID,
+			UserID: c.GetString("user_id"),
+		})
+		if err == pgx.ErrNoRows {
+			role, err = "", nil
+		}
+	}
+	if err != nil && err != pgx.ErrNoRows {
+		logger.Error("Failed to get club", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeClubFetchFailed)
+		return club, role, false
+	}
+	if err == pgx.ErrNoRows || (club.IsPrivate && role == "") {
+		respond.Error(c, http.StatusNotFound, i18n.CodeClubNotFound)
+		return club, role, false
+	}
+	if len(roles) == 0 {
+		return club, role, true
+	}
+
+	for _, allowed := range roles {
+		if role == allowed {
+			return club, role, true
+		}
+	}
+	respond.Error(c, http.StatusForbidden, i18n.CodeForbidden)
+	return club, role, false
+}
+
+func newClubResponse(club db.Club, memberCount int64, role string) ClubResponse {
+	resp := ClubResponse{
+		ID:          club.ID,
+		OwnerID:     club.OwnerID,
+		Name:        club.Name,
+		Description: club.Description,
+		IsPrivate:   club.IsPrivate,
+		MemberCount: memberCount,
+		CreatedAt:   club.CreatedAt,
+	}
+	if role != "" {
+		resp.MyRole = &role
+	}
+	return resp
+}
@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// InviteCollaboratorRequest represents the collaborator invitation payload
+type InviteCollaboratorRequest struct {
+	Username string `json:"username" binding:"required"`
+	Role     string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+// UpdateCollaboratorRequest represents the collaborator role change payload
+type UpdateCollaboratorRequest struct {
+	Role string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+// CollaboratorResponse is a recipe collaborator. AcceptedAt is nil while the
+// invitation is pending.
+type CollaboratorResponse struct {
+	UserID     string     `json:"user_id"`
+	Username   string     `json:"username"`
+	Role       string     `json:"role"`
+	InvitedBy  *string    `json:"invited_by"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// RecipeInvitationResponse is a pending invitation to collaborate on
+// another user's recipe
+type RecipeInvitationResponse struct {
+	RecipeID          string    `json:"recipe_id"`
+	RecipeName        string    `json:"recipe_name"`
+	Role              string    `json:"role"`
+	InvitedBy         *string   `json:"invited_by"`
+	InvitedByUsername *string   `json:"invited_by_username"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// InviteRecipeCollaborator invites a user to one of the current user's
+// recipes as an editor or viewer. Re-inviting an existing collaborator
+// changes their role.
+func InviteRecipeCollaborator(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req InviteCollaboratorRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		recipe, ok := loadRecipeWithRole(c, queries, recipeRoleOwner)
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+		invitee, err := queries.GetUserByUsername(ctx, req.Username)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusNotFound, i18n.CodeUserNotFound)
+				return
+			}
+			logger.Error("Failed to get user by username", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeCollaboratorUpdateFailed)
+			return
+		}
+		if invitee.ID == recipe.OwnerID {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeCannotInviteSelf)
+			return
+		}
+
+		collaborator, err := queries.InviteRecipeCollaborator(ctx, db.InviteRecipeCollaboratorParams{
+			RecipeID:  recipe.ID,
+			UserID:    invitee.ID,
+			Role:      req.Role,
+			InvitedBy: &recipe.OwnerID,
+		})
+		if err != nil {
+			logger.Error("Failed to invite recipe collaborator", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeCollaboratorUpdateFailed)
+			return
+		}
+
+		respond.Created(c, newCollaboratorResponse(collaborator, invitee.Username))
+	}
+}
+
+// ListRecipeCollaborators returns a recipe's collaborators, pending and
+// accepted. Only the owner and collaborators can see the list.
+func ListRecipeCollaborators(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		recipe, ok := loadRecipeWithRole(c, queries, recipeRoleOwner, recipeRoleEditor, recipeRoleViewer)
+		if !ok {
+			return
+		}
+
+		rows, err := queries.ListRecipeCollaborators(c.Request.Context(), recipe.ID)
+		if err != nil {
+			logger.Error("Failed to list recipe collaborators", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeCollaboratorFetchFailed)
+			return
+		}
+
+		collaborators := make([]CollaboratorResponse, 0, len(rows))
+		for _, row := range rows {
+			collaborators = append(collaborators, CollaboratorResponse{
+				UserID:     row.UserID,
+				Username:   row.Username,
+				Role:       row.Role,
+				InvitedBy:  row.InvitedBy,
+				AcceptedAt: timePtr(row.AcceptedAt),
+				CreatedAt:  row.CreatedAt,
+			})
+		}
+
+		respond.OK(c, collaborators)
+	}
+}
+
+// UpdateRecipeCollaboratorRole changes a collaborator's role on one of the
+// current user's recipes
+func UpdateRecipeCollaboratorRole(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req UpdateCollaboratorRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		recipe, ok := loadRecipeWithRole(c, queries, recipeRoleOwner)
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+		collaborator, err := queries.UpdateRecipeCollaboratorRole(ctx, db.UpdateRecipeCollaboratorRoleParams{
+			RecipeID: recipe.ID,
+			UserID:   c.Param("user_id"),
+			Role:     req.Role,
+		})
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusNotFound, i18n.CodeCollaboratorNotFound)
+				return
+			}
+			logger.Error("Failed to update recipe collaborator role", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeCollaboratorUpdateFailed)
+			return
+		}
+
+		// The username is informational; a failed lookup leaves it blank
+		username := ""
+		if user, err := queries.GetUserByID(ctx, collaborator.UserID); err == nil {
+			username = user.Username
+		}
+
+		respond.OK(c, newCollaboratorResponse(collaborator, username))
+	}
+}
+
+// RemoveRecipeCollaborator removes a collaborator from a recipe. The owner
+// can remove anyone; collaborators can remove themselves to leave the recipe
+// or decline a pending invitation.
+func RemoveRecipeCollaborator(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		recipeID := c.Param("id")
+		targetID := c.Param("user_id")
+
+		// A pending invitee can't view a private recipe yet, so self-removal
+		// skips the recipe lookup and relies on the row existing
+		if targetID != c.GetString("user_id") {
+			recipe, ok := loadRecipeWithRole(c, queries, recipeRoleOwner)
+			if !ok {
+				return
+			}
+			recipeID = recipe.ID
+		}
+
+		removed, err := queries.RemoveRecipeCollaborator(c.Request.Context(), db.RemoveRecipeCollaboratorParams{
+			RecipeID: recipeID,
+			UserID:   targetID,
+		})
+		if err != nil {
+			logger.Error("Failed to remove recipe collaborator", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeCollaboratorUpdateFailed)
+			return
+		}
+		if removed == 0 {
+			respond.Error(c, http.StatusNotFound, i18n.CodeCollaboratorNotFound)
+			return
+		}
+
+		respond.OK(c, nil)
+	}
+}
+
+// AcceptRecipeInvitation accepts the current user's pending invitation to a
+// recipe, granting the invited role
+func AcceptRecipeInvitation(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+
+		collaborator, err := queries.AcceptRecipeInvitation(c.Request.Context(), db.AcceptRecipeInvitationParams{
+			RecipeID: c.Param("id"),
+			UserID:   userID,
+		})
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusNotFound, i18n.CodeInvitationNotFound)
+				return
+			}
+			logger.Error("Failed to accept recipe invitation", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeCollaboratorUpdateFailed)
+			return
+		}
+
+		respond.OK(c, newCollaboratorResponse(collaborator, c.GetString("username")))
+	}
+}
+
+// ListMyRecipeInvitations returns the current user's pending recipe
+// invitations, newest first
+func ListMyRecipeInvitations(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		invitations, err := queries.ListUserRecipeInvitations(c.Request.Context(), c.GetString("user_id"))
+		if err != nil {
+			logger.Error("Failed to list recipe invitations", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeCollaboratorFetchFailed)
+			return
+		}
+
+		resp := make([]RecipeInvitationResponse, 0, len(invitations))
+		for _, invitation := range invitations {
+			resp = append(resp, RecipeInvitationResponse(invitation))
+		}
+
+		respond.OK(c, resp)
+	}
+}
+
+func newCollaboratorResponse(collaborator db.RecipeCollaborator, username string) CollaboratorResponse {
+	return CollaboratorResponse{
+		UserID:     collaborator.UserID,
+		Username:   username,
+		Role:       collaborator.Role,
+		InvitedBy:  collaborator.InvitedBy,
+		AcceptedAt: timePtr(collaborator.AcceptedAt),
+		CreatedAt:  collaborator.CreatedAt,
+	}
+}
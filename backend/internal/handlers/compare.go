@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strings"
+
+	"brewd/internal/compare"
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+	"brewd/internal/units"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Bounds on how many brews one comparison can line up
+const (
+	minCompareBrews = 2
+	maxCompareBrews = 10
+)
+
+// CompareQuery represents the brew comparison query parameters; IDs is a
+// comma-separated list of brew IDs
+type CompareQuery struct {
+	IDs string `form:"ids" binding:"required"`
+}
+
+// ComparedBrew identifies one column of a comparison
+type ComparedBrew struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// CompareResponse lines up the selected brews' parameters and tasting scores
+// field by field, with deltas against the first brew
+type CompareResponse struct {
+	Brews  []ComparedBrew   `json:"brews"`
+	Fields []compare.Field  `json:"fields"`
+	Units  units.Preference `json:"units"`
+}
+
+// CompareBrews returns a side-by-side comparison of the brews named in ?ids,
+// in the caller's units. The first brew is the baseline for deltas.
+func CompareBrews(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query CompareQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		ids := splitIDs(query.IDs)
+		if len(ids) < minCompareBrews || len(ids) > maxCompareBrews {
+			respond.Errorf(c, http.StatusBadRequest, i18n.CodeInvalidCompareIDs, minCompareBrews, maxCompareBrews)
+			return
+		}
+
+		pref, ok := requestUnits(c, queries)
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+		userID := c.GetString("user_id")
+		rows, err := queries.GetVisibleBrewsByIDs(ctx, db.GetVisibleBrewsByIDsParams{
+			Ids:      ids,
+			ViewerID: userID,
+		})
+		if err != nil {
+			logger.Error("Failed to get brews for comparison", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewFetchFailed)
+			return
+		}
+
+		// Line brews up in request order; a missing or hidden brew fails
+		// the whole comparison rather than leaving a gap
+		byID := make(map[string]db.Brew, len(rows))
+		for _, brew := range rows {
+			byID[brew.ID] = brew
+		}
+		brews := make([]db.Brew, 0, len(ids))
+		for _, id := range ids {
+			brew, found := byID[id]
+			if !found {
+				respond.Error(c, http.StatusNotFound, i18n.CodeBrewNotFound)
+				return
+			}
+			brews = append(brews, brew)
+		}
+
+		ratings, err := queries.GetBrewRatings(ctx, ids)
+		if err != nil {
+			logger.Error("Failed to get brew ratings", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewFetchFailed)
+			return
+		}
+
+		respond.OK(c, newCompareResponse(brews, ratings, pref))
+	}
+}
+
+// splitIDs parses a comma-separated ID list, dropping blanks and repeats
+func splitIDs(raw string) []string {
+	seen := make(map[string]bool)
+	ids := []string{}
+	for _, id := range strings.Split(raw, ",") {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// newCompareResponse builds the comparison fields for brews, already in
+// request order
+func newCompareResponse(brews []db.Brew, ratings []db.GetBrewRatingsRow, pref units.Preference) CompareResponse {
+	scores := make(map[string]db.GetBrewRatingsRow, len(ratings))
+	for _, r := range ratings {
+		scores[r.BrewID] = r
+	}
+
+	n := len(brews)
+	resp := CompareResponse{Brews: make([]ComparedBrew, n), Units: pref}
+	method := make([]*string, n)
+	origin := make([]*string, n)
+	roaster := make([]*string, n)
+	recipe := make([]*string, n)
+	grind := make([]*string, n)
+	dose := make([]*float64, n)
+	water := make([]*float64, n)
+	ratio := make([]*float64, n)
+	temp := make([]*float64, n)
+	brewTime := make([]*float64, n)
+	rating := make([]*float64, n)
+	ratingCount := make([]*float64, n)
+	for i, brew := range brews {
+		b := newBrewResponse(brew, pref)
+		resp.Brews[i] = ComparedBrew{ID: b.ID, Name: b.Name}
+		method[i] = b.BrewMethod
+		origin[i] = b.BeanOrigin
+		roaster[i] = b.Roaster
+		recipe[i] = b.RecipeID
+		grind[i] = b.GrindSetting
+		dose[i] = b.Dose
+		water[i] = b.Water
+		temp[i] = b.WaterTemp
+		if b.BrewTimeSeconds != nil {
+			v := float64(*b.BrewTimeSeconds)
+			brewTime[i] = &v
+		}
+		// Brew ratio (water per gram of coffee) is unit-free, so brews
+		// logged at different sizes still compare
+		if brew.DoseGrams != nil && brew.WaterGrams != nil {
+			v := math.Round(*brew.WaterGrams / *brew.DoseGrams * 100) / 100
+			ratio[i] = &v
+		}
+		if s, found := scores[brew.ID]; found {
+			avg := math.Round(s.AvgRating*100) / 100
+			count := float64(s.RatingCount)
+			rating[i] = &avg
+			ratingCount[i] = &count
+		}
+	}
+
+	resp.Fields = []compare.Field{
+		compare.Text("brew_method", method),
+		compare.Text("bean_origin", origin),
+		compare.Text("roaster", roaster),
+		compare.Text("recipe_id", recipe),
+		compare.Numeric("dose", dose),
+		compare.Numeric("water", water),
+		compare.Numeric("ratio", ratio),
+		compare.Numeric("water_temp", temp),
+		compare.Text("grind_setting", grind),
+		compare.Numeric("brew_time_seconds", brewTime),
+		compare.Numeric("rating", rating),
+		compare.Numeric("rating_count", ratingCount),
+	}
+	return resp
+}
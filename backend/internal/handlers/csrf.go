@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+
+	"brewd/internal/cookies"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CSRFResponse is the token to echo in the X-CSRF-Token header
+type CSRFResponse struct {
+	CSRFToken string `json:"csrf_token"`
+}
+
+// GetCSRFToken returns the web client's CSRF token, setting a new one in
+// the CSRF cookie if it has none, e.g. when it has lost track of the token
+// or before logging in. The token stays the same while the cookie lasts.
+func GetCSRFToken(cookiePolicy cookies.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token, err := c.Cookie(cookies.CSRF); err == nil && token != "" {
+			respond.OK(c, CSRFResponse{CSRFToken: token})
+			return
+		}
+
+		token, err := cookies.NewCSRFToken()
+		if err != nil {
+			logger.Error("Failed to generate CSRF token", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeInternal)
+			return
+		}
+		cookiePolicy.SetCSRF(c.Writer, token)
+
+		respond.OK(c, CSRFResponse{CSRFToken: token})
+	}
+}
@@ -0,0 +1,605 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"net/http"
+	"time"
+
+	"brewd/internal/cupping"
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/oklog/ulid/v2"
+)
+
+// Roles a user can hold in a cupping session. Hosts manage the session and
+// see sample identities; participants only see labels until the reveal.
+const (
+	cuppingRoleHost        = "host"
+	cuppingRoleParticipant = "participant"
+)
+
+// CuppingSessionRequest represents the cupping session creation payload
+type CuppingSessionRequest struct {
+	Title   string                 `json:"title" binding:"required,max=255"`
+	Notes   *string                `json:"notes"`
+	Samples []CuppingSampleRequest `json:"samples" binding:"required,min=2,max=26,dive"`
+}
+
+// CuppingSampleRequest represents one sample on the cupping table. Details
+// left out come from the linked bean bag, if any.
+type CuppingSampleRequest struct {
+	Name       *string `json:"name" binding:"required_without=BeanBagID,omitempty,min=1,max=255"`
+	Roaster    *string `json:"roaster"`
+	BeanOrigin *string `json:"bean_origin"`
+	BeanBagID  *string `json:"bean_bag_id"`
+}
+
+// InviteParticipantRequest represents the cupping invitation payload
+type InviteParticipantRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+
+// CuppingScoreRequest represents a score on the SCA 0-100 cupping scale
+type CuppingScoreRequest struct {
+	Score *float64 `json:"score" binding:"required,min=0,max=100"`
+	Notes *string  `json:"notes" binding:"omitempty,max=2000"`
+}
+
+// CuppingSessionResponse is a cupping session as seen by one of its members
+type CuppingSessionResponse struct {
+	ID           string                       `json:"id"`
+	HostID       string                       `json:"host_id"`
+	Title        string                       `json:"title"`
+	Notes        *string                      `json:"notes"`
+	Role         string                       `json:"role"`
+	RevealedAt   *time.Time                   `json:"revealed_at"`
+	Samples      []CuppingSampleResponse      `json:"samples"`
+	Participants []CuppingParticipantResponse `json:"participants"`
+	CreatedAt    time.Time                    `json:"created_at"`
+}
+
+// CuppingSampleResponse is a sample on the cupping table. Everything but the
+// label is nil for participants until the session is revealed.
+type CuppingSampleResponse struct {
+	Label      string  `json:"label"`
+	ID         *string `json:"id"`
+	Name       *string `json:"name"`
+	Roaster    *string `json:"roaster"`
+	BeanOrigin *string `json:"bean_origin"`
+	BeanBagID  *string `json:"bean_bag_id"`
+}
+
+// CuppingParticipantResponse is an invited participant and how many samples
+// they have scored so far
+type CuppingParticipantResponse struct {
+	UserID      string    `json:"user_id"`
+	Username    string    `json:"username"`
+	ScoredCount int64     `json:"scored_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// CuppingSessionSummary is a session in the current user's session list
+type CuppingSessionSummary struct {
+	ID           string     `json:"id"`
+	HostID       string     `json:"host_id"`
+	HostUsername string     `json:"host_username"`
+	Title        string     `json:"title"`
+	SampleCount  int64      `json:"sample_count"`
+	RevealedAt   *time.Time `json:"revealed_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// CuppingScoreResponse is one of the current user's scores
+type CuppingScoreResponse struct {
+	Label     string    `json:"label"`
+	Score     float64   `json:"score"`
+	Notes     *string   `json:"notes"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CuppingResultResponse is a revealed sample with its score statistics and
+// every scorer's score. Rank is nil for samples nobody scored.
+type CuppingResultResponse struct {
+	Label      string               `json:"label"`
+	Name       string               `json:"name"`
+	Roaster    *string              `json:"roaster"`
+	BeanOrigin *string              `json:"bean_origin"`
+	BeanBagID  *string              `json:"bean_bag_id"`
+	Rank       *int                 `json:"rank"`
+	Stats      cupping.Stats        `json:"stats"`
+	Scores     []CuppingResultScore `json:"scores"`
+}
+
+// CuppingResultScore is one scorer's score for a revealed sample
+type CuppingResultScore struct {
+	UserID   string  `json:"user_id"`
+	Username string  `json:"username"`
+	Score    float64 `json:"score"`
+	Notes    *string `json:"notes"`
+}
+
+// CreateCuppingSession sets up a blind cupping session hosted by the current
+// user. Samples are given labels in a random order.
+func CreateCuppingSession(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req CuppingSessionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		params := db.CreateCuppingSessionParams{
+			ID:     ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+			HostID: c.GetString("user_id"),
+			Title:  req.Title,
+			Notes:  req.Notes,
+			Labels: cupping.Labels(len(req.Samples)),
+		}
+		for _, sample := range req.Samples {
+			// Sample details left out of the request come from the bag
+			name, roaster, beanOrigin := sample.Name, sample.Roaster, sample.BeanOrigin
+			if sample.BeanBagID != nil {
+				bag, ok := loadOwnedBeanBag(c, queries, *sample.BeanBagID)
+				if !ok {
+					return
+				}
+				if name == nil {
+					name = &bag.Name
+				}
+				if roaster == nil {
+					roaster = bag.Roaster
+				}
+				if beanOrigin == nil {
+					beanOrigin = bag.BeanOrigin
+				}
+			}
+
+			params.SampleIds = append(params.SampleIds, ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String())
+			params.Names = append(params.Names, *name)
+			params.Roasters = append(params.Roasters, roaster)
+			params.BeanOrigins = append(params.BeanOrigins, beanOrigin)
+			params.BeanBagIds = append(params.BeanBagIds, sample.BeanBagID)
+		}
+
+		ctx := c.Request.Context()
+		session, err := queries.CreateCuppingSession(ctx, params)
+		if err != nil {
+			logger.Error("Failed to create cupping session", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeCuppingUpdateFailed)
+			return
+		}
+
+		logger.Info("Cupping session created", "cupping_session_id", session.ID, "samples", len(req.Samples))
+
+		respondCuppingSession(c, queries, session, cuppingRoleHost, http.StatusCreated)
+	}
+}
+
+// ListCuppingSessions returns the sessions the current user hosts or was
+// invited to, newest first
+func ListCuppingSessions(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rows, err := queries.ListUserCuppingSessions(c.Request.Context(), c.GetString("user_id"))
+		if err != nil {
+			logger.Error("Failed to list cupping sessions", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeCuppingFetchFailed)
+			return
+		}
+
+		sessions := make([]CuppingSessionSummary, 0, len(rows))
+		for _, row := range rows {
+			sessions = append(sessions, CuppingSessionSummary{
+				ID:           row.ID,
+				HostID:       row.HostID,
+				HostUsername: row.HostUsername,
+				Title:        row.Title,
+				SampleCount:  row.SampleCount,
+				RevealedAt:   timePtr(row.RevealedAt),
+				CreatedAt:    row.CreatedAt,
+			})
+		}
+
+		respond.OK(c, sessions)
+	}
+}
+
+// GetCuppingSession returns a session with its samples and participants.
+// Participants see sample identities only after the reveal.
+func GetCuppingSession(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session, role, ok := loadCuppingSession(c, queries, cuppingRoleHost, cuppingRoleParticipant)
+		if !ok {
+			return
+		}
+
+		respondCuppingSession(c, queries, session, role, http.StatusOK)
+	}
+}
+
+// DeleteCuppingSession deletes one of the current user's sessions along with
+// its samples and scores
+func DeleteCuppingSession(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session, _, ok := loadCuppingSession(c, queries, cuppingRoleHost)
+		if !ok {
+			return
+		}
+
+		if err := queries.DeleteCuppingSession(c.Request.Context(), session.ID); err != nil {
+			logger.Error("Failed to delete cupping session", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeCuppingUpdateFailed)
+			return
+		}
+
+		respond.OK(c, nil)
+	}
+}
+
+// InviteCuppingParticipant invites a user to score one of the current user's
+// sessions before it is revealed
+func InviteCuppingParticipant(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req InviteParticipantRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		session, _, ok := loadCuppingSession(c, queries, cuppingRoleHost)
+		if !ok || !requireUnrevealed(c, session) {
+			return
+		}
+
+		ctx := c.Request.Context()
+		invitee, err := queries.GetUserByUsername(ctx, req.Username)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusNotFound, i18n.CodeUserNotFound)
+				return
+			}
+			logger.Error("Failed to get user by username", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeCuppingUpdateFailed)
+			return
+		}
+		if invitee.ID == session.HostID {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeCannotInviteSelf)
+			return
+		}
+
+		if err := queries.AddCuppingParticipant(ctx, db.AddCuppingParticipantParams{
+			SessionID: session.ID,
+			UserID:    invitee.ID,
+		}); err != nil {
+			logger.Error("Failed to add cupping participant", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeCuppingUpdateFailed)
+			return
+		}
+
+		respond.Created(c, gin.H{
+			"user_id":  invitee.ID,
+			"username": invitee.Username,
+		})
+	}
+}
+
+// RemoveCuppingParticipant removes a participant from a session. The host
+// can remove anyone; participants can remove themselves. Their scores are
+// discarded unless the session was already revealed.
+func RemoveCuppingParticipant(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.Param("user_id")
+		roles := []string{cuppingRoleHost}
+		if userID == c.GetString("user_id") {
+			roles = append(roles, cuppingRoleParticipant)
+		}
+
+		session, _, ok := loadCuppingSession(c, queries, roles...)
+		if !ok {
+			return
+		}
+
+		removed, err := queries.RemoveCuppingParticipant(c.Request.Context(), db.RemoveCuppingParticipantParams{
+			SessionID: session.ID,
+			UserID:    userID,
+		})
+		if err != nil {
+			logger.Error("Failed to remove cupping participant", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeCuppingUpdateFailed)
+			return
+		}
+		if removed == 0 {
+			respond.Error(c, http.StatusNotFound, i18n.CodeCuppingParticipantNotFound)
+			return
+		}
+
+		respond.OK(c, nil)
+	}
+}
+
+// ScoreCuppingSample records the current user's score for the :label sample.
+// Scores can be changed until the session is revealed.
+func ScoreCuppingSample(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req CuppingScoreRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		session, _, ok := loadCuppingSession(c, queries, cuppingRoleHost, cuppingRoleParticipant)
+		if !ok || !requireUnrevealed(c, session) {
+			return
+		}
+
+		score, err := queries.UpsertCuppingScore(c.Request.Context(), db.UpsertCuppingScoreParams{
+			UserID:    c.GetString("user_id"),
+			Score:     *req.Score,
+			Notes:     req.Notes,
+			SessionID: session.ID,
+			Label:     c.Param("label"),
+		})
+		if err != nil {
+			// No row means the label is unknown, or the session was revealed
+			// since it was loaded
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusNotFound, i18n.CodeCuppingSampleNotFound)
+				return
+			}
+			logger.Error("Failed to upsert cupping score", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeCuppingUpdateFailed)
+			return
+		}
+
+		respond.OK(c, CuppingScoreResponse{
+			Label:     c.Param("label"),
+			Score:     score.Score,
+			Notes:     score.Notes,
+			UpdatedAt: score.UpdatedAt,
+		})
+	}
+}
+
+// ListMyCuppingScores returns the current user's own scores in a session.
+// Other scorers' scores stay hidden until the results are revealed.
+func ListMyCuppingScores(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session, _, ok := loadCuppingSession(c, queries, cuppingRoleHost, cuppingRoleParticipant)
+		if !ok {
+			return
+		}
+
+		rows, err := queries.ListUserCuppingScores(c.Request.Context(), db.ListUserCuppingScoresParams{
+			SessionID: session.ID,
+			UserID:    c.GetString("user_id"),
+		})
+		if err != nil {
+			logger.Error("Failed to list cupping scores", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeCuppingFetchFailed)
+			return
+		}
+
+		scores := make([]CuppingScoreResponse, 0, len(rows))
+		for _, row := range rows {
+			scores = append(scores, CuppingScoreResponse{
+				Label:     row.Label,
+				Score:     row.Score,
+				Notes:     row.Notes,
+				UpdatedAt: row.UpdatedAt,
+			})
+		}
+
+		respond.OK(c, scores)
+	}
+}
+
+// RevealCuppingSession reveals one of the current user's sessions, showing
+// sample identities and results to everyone and locking scores
+func RevealCuppingSession(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session, _, ok := loadCuppingSession(c, queries, cuppingRoleHost)
+		if !ok {
+			return
+		}
+
+		revealed, err := queries.RevealCuppingSession(c.Request.Context(), session.ID)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusConflict, i18n.CodeCuppingRevealed)
+				return
+			}
+			logger.Error("Failed to reveal cupping session", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeCuppingUpdateFailed)
+			return
+		}
+
+		logger.Info("Cupping session revealed", "cupping_session_id", revealed.ID)
+
+		respondCuppingSession(c, queries, revealed, cuppingRoleHost, http.StatusOK)
+	}
+}
+
+// GetCuppingResults returns a revealed session's samples ranked by mean
+// score, with summary statistics and every scorer's score
+func GetCuppingResults(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session, _, ok := loadCuppingSession(c, queries, cuppingRoleHost, cuppingRoleParticipant)
+		if !ok {
+			return
+		}
+		if !session.RevealedAt.Valid {
+			respond.Error(c, http.StatusConflict, i18n.CodeCuppingNotRevealed)
+			return
+		}
+
+		ctx := c.Request.Context()
+		samples, err := queries.ListCuppingSamples(ctx, session.ID)
+		if err != nil {
+			logger.Error("Failed to list cupping samples", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeCuppingFetchFailed)
+			return
+		}
+
+		rows, err := queries.ListCuppingScores(ctx, session.ID)
+		if err != nil {
+			logger.Error("Failed to list cupping scores", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeCuppingFetchFailed)
+			return
+		}
+
+		scores := make(map[string][]CuppingResultScore, len(samples))
+		for _, row := range rows {
+			scores[row.SampleID] = append(scores[row.SampleID], CuppingResultScore{
+				UserID:   row.UserID,
+				Username: row.Username,
+				Score:    row.Score,
+				Notes:    row.Notes,
+			})
+		}
+
+		results := make([]CuppingResultResponse, 0, len(samples))
+		means := make([]*float64, 0, len(samples))
+		for _, sample := range samples {
+			sampleScores := scores[sample.ID]
+			values := make([]float64, 0, len(sampleScores))
+			for _, s := range sampleScores {
+				values = append(values, s.Score)
+			}
+			if sampleScores == nil {
+				sampleScores = []CuppingResultScore{}
+			}
+
+			stats := cupping.Describe(values)
+			means = append(means, stats.Mean)
+			results = append(results, CuppingResultResponse{
+				Label:      sample.Label,
+				Name:       sample.Name,
+				Roaster:    sample.Roaster,
+				BeanOrigin: sample.BeanOrigin,
+				BeanBagID:  sample.BeanBagID,
+				Stats:      stats,
+				Scores:     sampleScores,
+			})
+		}
+		for i, rank := range cupping.Ranks(means) {
+			results[i].Rank = rank
+		}
+
+		respond.OK(c, gin.H{
+			"session_id":  session.ID,
+			"revealed_at": session.RevealedAt.Time,
+			"samples":     results,
+		})
+	}
+}
+
+// loadCuppingSession fetches the :id session for an action that requires one
+// of roles, writing an error response otherwise. Sessions are reported as
+// missing to users who are neither host nor participant.
+func loadCuppingSession(c *gin.Context, queries *db.Queries, roles ...string) (db.CuppingSession, string, bool) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+
+	session, err := queries.GetCuppingSessionByID(ctx, c.Param("id"))
+	role := ""
+	if err == nil {
+		if session.HostID == userID {
+			role = cuppingRoleHost
+		} else {
+			var invited bool
+			invited, err = queries.IsCuppingParticipant(ctx, db.IsCuppingParticipantParams{
+				SessionID: session.ID,
+				UserID:    userID,
+			})
+			if invited {
+				role = cuppingRoleParticipant
+			}
+		}
+	}
+	if err != nil && err != pgx.ErrNoRows {
+		logger.Error("Failed to get cupping session", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeCuppingFetchFailed)
+		return session, role, false
+	}
+	if err == pgx.ErrNoRows || role == "" {
+		respond.Error(c, http.StatusNotFound, i18n.CodeCuppingNotFound)
+		return session, role, false
+	}
+
+	for _, allowed := range roles {
+		if role == allowed {
+			return session, role, true
+		}
+	}
+	respond.Error(c, http.StatusForbidden, i18n.CodeForbidden)
+	return session, role, false
+}
+
+// requireUnrevealed writes 409 and returns false once session is revealed
+func requireUnrevealed(c *gin.Context, session db.CuppingSession) bool {
+	if !session.RevealedAt.Valid {
+		return true
+	}
+	respond.Error(c, http.StatusConflict, i18n.CodeCuppingRevealed)
+	return false
+}
+
+// respondCuppingSession writes session with its samples and participants as
+// role sees them
+func respondCuppingSession(c *gin.Context, queries *db.Queries, session db.CuppingSession, role string, status int) {
+	ctx := c.Request.Context()
+	samples, err := queries.ListCuppingSamples(ctx, session.ID)
+	if err != nil {
+		logger.Error("Failed to list cupping samples", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeCuppingFetchFailed)
+		return
+	}
+
+	participants, err := queries.ListCuppingParticipants(ctx, session.ID)
+	if err != nil {
+		logger.Error("Failed to list cupping participants", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeCuppingFetchFailed)
+		return
+	}
+
+	blind := role != cuppingRoleHost && !session.RevealedAt.Valid
+	resp := CuppingSessionResponse{
+		ID:           session.ID,
+		HostID:       session.HostID,
+		Title:        session.Title,
+		Notes:        session.Notes,
+		Role:         role,
+		RevealedAt:   timePtr(session.RevealedAt),
+		Samples:      make([]CuppingSampleResponse, 0, len(samples)),
+		Participants: make([]CuppingParticipantResponse, 0, len(participants)),
+		CreatedAt:    session.CreatedAt,
+	}
+	for _, sample := range samples {
+		if blind {
+			resp.Samples = append(resp.Samples, CuppingSampleResponse{Label: sample.Label})
+			continue
+		}
+		resp.Samples = append(resp.Samples, CuppingSampleResponse{
+			Label:      sample.Label,
+			ID:         &sample.ID,
+			Name:       &sample.Name,
+			Roaster:    sample.Roaster,
+			BeanOrigin: sample.BeanOrigin,
+			BeanBagID:  sample.BeanBagID,
+		})
+	}
+	for _, p := range participants {
+		resp.Participants = append(resp.Participants, CuppingParticipantResponse{
+			UserID:      p.UserID,
+			Username:    p.Username,
+			ScoredCount: p.ScoredCount,
+			CreatedAt:   p.CreatedAt,
+		})
+	}
+
+	respond.Status(c, status, resp)
+}
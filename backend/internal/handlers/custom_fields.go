@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"brewd/internal/customfields"
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/oklog/ulid/v2"
+)
+
+// CustomFieldRequest represents the custom field creation payload. Name is
+// the key the field's values use in brew payloads; unit is a label shown
+// with them.
+type CustomFieldRequest struct {
+	Name string  `json:"name" binding:"required"`
+	Type string  `json:"type" binding:"required,oneof=number text boolean"`
+	Unit *string `json:"unit" binding:"omitempty,max=20"`
+}
+
+// UpdateCustomFieldRequest represents the custom field update payload; only
+// the unit can change, and null clears it
+type UpdateCustomFieldRequest struct {
+	Unit *string `json:"unit" binding:"omitempty,max=20"`
+}
+
+// CustomFieldResponse represents one of the user's custom fields
+type CustomFieldResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Unit      *string   `json:"unit"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CustomFieldRatingResponse is the user's brews with one value of a custom
+// field and the average rating they gave them
+type CustomFieldRatingResponse struct {
+	Value       string   `json:"value"`
+	BrewCount   int64    `json:"brew_count"`
+	RatingCount int64    `json:"rating_count"`
+	AvgRating   *float64 `json:"avg_rating"`
+}
+
+// CreateCustomField adds a custom field to the current user's brews
+func CreateCustomField(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req CustomFieldRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if !customfields.ValidName(req.Name) {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeCustomFieldNameInvalid)
+			return
+		}
+
+		ctx := c.Request.Context()
+		userID := c.GetString("user_id")
+		existing, err := queries.ListUserBrewCustomFields(ctx, userID)
+		if err != nil {
+			logger.Error("Failed to list custom fields", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeCustomFieldUpdateFailed)
+			return
+		}
+		if len(existing) >= customfields.MaxFields {
+			respond.Errorf(c, http.StatusConflict, i18n.CodeCustomFieldLimit, customfields.MaxFields)
+			return
+		}
+
+		field, err := queries.CreateBrewCustomField(ctx, db.CreateBrewCustomFieldParams{
+			ID:     ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+			UserID: userID,
+			Name:   req.Name,
+			Type:   req.Type,
+			Unit:   req.Unit,
+		})
+		if err != nil {
+			if isUniqueViolation(err) {
+				respond.Error(c, http.StatusConflict, i18n.CodeCustomFieldTaken)
+				return
+			}
+			logger.Error("Failed to create custom field", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeCustomFieldUpdateFailed)
+			return
+		}
+
+		respond.Created(c, newCustomFieldResponse(field))
+	}
+}
+
+// ListCustomFields returns the current user's custom fields by name
+func ListCustomFields(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fields, err := queries.ListUserBrewCustomFields(c.Request.Context(), c.GetString("user_id"))
+		if err != nil {
+			logger.Error("Failed to list custom fields", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeCustomFieldFetchFailed)
+			return
+		}
+
+		items := make([]CustomFieldResponse, 0, len(fields))
+		for _, f := range fields {
+			items = append(items, newCustomFieldResponse(f))
+		}
+
+		respond.OK(c, items)
+	}
+}
+
+// UpdateCustomField changes the unit of one of the current user's custom
+// fields
+func UpdateCustomField(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req UpdateCustomFieldRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		field, err := queries.UpdateBrewCustomField(c.Request.Context(), db.UpdateBrewCustomFieldParams{
+			Unit:   req.Unit,
+			ID:     c.Param("id"),
+			UserID: c.GetString("user_id"),
+		})
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusNotFound, i18n.CodeCustomFieldNotFound)
+				return
+			}
+			logger.Error("Failed to update custom field", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeCustomFieldUpdateFailed)
+			return
+		}
+
+		respond.OK(c, newCustomFieldResponse(field))
+	}
+}
+
+// DeleteCustomField removes one of the current user's custom fields and its
+// values from their brews
+func DeleteCustomField(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_, err := queries.DeleteBrewCustomField(c.Request.Context(), db.DeleteBrewCustomFieldParams{
+			ID:     c.Param("id"),
+			UserID: c.GetString("user_id"),
+		})
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusNotFound, i18n.CodeCustomFieldNotFound)
+				return
+			}
+			logger.Error("Failed to delete custom field", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeCustomFieldUpdateFailed)
+			return
+		}
+
+		respond.OK(c, nil)
+	}
+}
+
+// GetMyCustomFieldStats groups the current user's brews by the value of one
+// of their custom fields (:name) and averages the ratings the user gave them
+// in posts. Brews without a value are left out.
+func GetMyCustomFieldStats(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		userID := c.GetString("user_id")
+		fields, err := queries.ListUserBrewCustomFields(ctx, userID)
+		if err != nil {
+			logger.Error("Failed to list custom fields", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeStatsFetchFailed)
+			return
+		}
+		var field *db.BrewCustomField
+		for i := range fields {
+			if fields[i].Name == c.Param("name") {
+				field = &fields[i]
+			}
+		}
+		if field == nil {
+			respond.Error(c, http.StatusNotFound, i18n.CodeCustomFieldNotFound)
+			return
+		}
+
+		rows, err := queries.GetUserCustomFieldRatings(ctx, db.GetUserCustomFieldRatingsParams{
+			Name:   field.Name,
+			UserID: &userID,
+		})
+		if err != nil {
+			logger.Error("Failed to get custom field ratings", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeStatsFetchFailed)
+			return
+		}
+
+		groups := make([]CustomFieldRatingResponse, 0, len(rows))
+		for _, row := range rows {
+			groups = append(groups, CustomFieldRatingResponse{
+				Value:       row.Value,
+				BrewCount:   row.BrewCount,
+				RatingCount: row.RatingCount,
+				AvgRating:   row.AvgRating,
+			})
+		}
+
+		respond.OK(c, gin.H{
+			"field":  newCustomFieldResponse(*field),
+			"groups": groups,
+		})
+	}
+}
+
+// checkCustomFields validates brew custom field values against the current
+// user's fields, returning them encoded for storage. It writes an error
+// response, with details per field, when one is rejected.
+func checkCustomFields(c *gin.Context, queries *db.Queries, values map[string]json.RawMessage) ([]byte, bool) {
+	if len(values) == 0 {
+		return nil, true
+	}
+
+	fields, err := queries.ListUserBrewCustomFields(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		logger.Error("Failed to list custom fields", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeCustomFieldFetchFailed)
+		return nil, false
+	}
+
+	encoded, fieldErrs := customfields.Validate(fields, values)
+	if len(fieldErrs) == 0 {
+		return encoded, true
+	}
+	details := make(map[string]string, len(fieldErrs))
+	for _, fe := range fieldErrs {
+		key := "custom_fields." + fe.Name
+		switch {
+		case fe.Rule == customfields.RuleUnknown:
+			details[key] = i18n.T(c, i18n.CodeCustomFieldUnknown)
+		case fe.Type == customfields.TypeNumber:
+			details[key] = i18n.T(c, i18n.CodeCustomFieldNumber)
+		case fe.Type == customfields.TypeText:
+			details[key] = i18n.Tf(c, i18n.CodeCustomFieldText, customfields.MaxTextLength)
+		default:
+			details[key] = i18n.T(c, i18n.CodeCustomFieldBoolean)
+		}
+	}
+	respond.Details(c, http.StatusBadRequest, i18n.CodeCustomFieldsInvalid, details)
+	return nil, false
+}
+
+func newCustomFieldResponse(f db.BrewCustomField) CustomFieldResponse {
+	return CustomFieldResponse{
+		ID:        f.ID,
+		Name:      f.Name,
+		Type:      f.Type,
+		Unit:      f.Unit,
+		CreatedAt: f.CreatedAt,
+		UpdatedAt: f.UpdatedAt,
+	}
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+	"brewd/internal/trending"
+
+	"github.com/gin-gonic/gin"
+)
+
+// discoverCacheControl lets clients reuse trending lists for a few minutes;
+// they're only recomputed periodically anyway
+const discoverCacheControl = "private, max-age=300"
+
+// DiscoverQuery represents the discovery query parameters
+type DiscoverQuery struct {
+	Period string `form:"period" binding:"omitempty,oneof=day week month"`
+	Limit  int32  `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+// TrendingRecipe is a public recipe and its activity over the period
+type TrendingRecipe struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	BrewMethod    *string  `json:"brew_method"`
+	OwnerUsername string   `json:"owner_username"`
+	BrewCount     int32    `json:"brew_count"`
+	RatingCount   int32    `json:"rating_count"`
+	AvgRating     *float64 `json:"avg_rating"`
+	Score         float64  `json:"score"`
+}
+
+// TrendingBean is a roaster's bean from an origin and its activity over
+// the period
+type TrendingBean struct {
+	Roaster     string   `json:"roaster"`
+	BeanOrigin  string   `json:"bean_origin"`
+	BrewCount   int32    `json:"brew_count"`
+	RatingCount int32    `json:"rating_count"`
+	AvgRating   *float64 `json:"avg_rating"`
+	Score       float64  `json:"score"`
+}
+
+// DiscoverResponse represents the trending recipes and beans over a period.
+// ComputedAt is when they were last ranked, nil before the first ranking.
+type DiscoverResponse struct {
+	Period     string           `json:"period"`
+	ComputedAt *time.Time       `json:"computed_at"`
+	Recipes    []TrendingRecipe `json:"recipes"`
+	Beans      []TrendingBean   `json:"beans"`
+}
+
+// Discover returns the trending public recipes and beans over a rolling
+// period (?period=day, week or month), as last ranked by the trending
+// worker
+func Discover(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query DiscoverQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if query.Period == "" {
+			query.Period = trending.DefaultPeriod
+		}
+		if query.Limit == 0 {
+			query.Limit = defaultPageLimit
+		}
+
+		ctx := c.Request.Context()
+		recipes, err := queries.ListTrendingRecipes(ctx, db.ListTrendingRecipesParams{
+			Period:   query.Period,
+			RowLimit: query.Limit,
+		})
+		if err != nil {
+			logger.Error("Failed to list trending recipes", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeTrendingFetchFailed)
+			return
+		}
+		beans, err := queries.ListTrendingBeans(ctx, db.ListTrendingBeansParams{
+			Period:   query.Period,
+			RowLimit: query.Limit,
+		})
+		if err != nil {
+			logger.Error("Failed to list trending beans", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeTrendingFetchFailed)
+			return
+		}
+
+		resp := DiscoverResponse{
+			Period:  query.Period,
+			Recipes: make([]TrendingRecipe, 0, len(recipes)),
+			Beans:   make([]TrendingBean, 0, len(beans)),
+		}
+		for _, r := range recipes {
+			resp.ComputedAt = timePtr(r.ComputedAt)
+			resp.Recipes = append(resp.Recipes, TrendingRecipe{
+				ID:            r.ID,
+				Name:          r.Name,
+				BrewMethod:    r.BrewMethod,
+				OwnerUsername: r.OwnerUsername,
+				BrewCount:     r.BrewCount,
+				RatingCount:   r.RatingCount,
+				AvgRating:     r.AvgRating,
+				Score:         r.Score,
+			})
+		}
+		for _, b := range beans {
+			resp.ComputedAt = timePtr(b.ComputedAt)
+			resp.Beans = append(resp.Beans, TrendingBean{
+				Roaster:     b.Roaster,
+				BeanOrigin:  b.BeanOrigin,
+				BrewCount:   b.BrewCount,
+				RatingCount: b.RatingCount,
+				AvgRating:   b.AvgRating,
+				Score:       b.Score,
+			})
+		}
+
+		c.Header("Cache-Control", discoverCacheControl)
+		respond.OK(c, resp)
+	}
+}
@@ -0,0 +1,338 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/mergepatch"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/jackc/pgx/v5"
+	"github.com/oklog/ulid/v2"
+)
+
+// Kinds of content a draft can become
+const (
+	draftKindBrew   = "brew"
+	draftKindRecipe = "recipe"
+)
+
+// maxDrafts is how many drafts a user can keep
+const maxDrafts = 50
+
+// maxDraftBytes caps a draft's stored data
+const maxDraftBytes = 64 << 10
+
+// DraftRequest represents the draft creation payload. Data is the brew or
+// recipe creation payload entered so far; required fields can be missing.
+type DraftRequest struct {
+	Kind string          `json:"kind" binding:"required,oneof=brew recipe"`
+	Data json.RawMessage `json:"data"`
+}
+
+// DraftQuery represents the draft list filter
+type DraftQuery struct {
+	Kind *string `form:"kind" binding:"omitempty,oneof=brew recipe"`
+}
+
+// DraftResponse represents a draft with its data as saved
+type DraftResponse struct {
+	ID        string          `json:"id"`
+	Kind      string          `json:"kind"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// CreateDraft starts a draft brew or recipe for the current user
+func CreateDraft(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req DraftRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		// Merging into nothing normalizes the data and drops nulls
+		patch := []byte(req.Data)
+		if len(patch) == 0 {
+			patch = []byte("{}")
+		}
+		data, ok := mergeDraftData(c, req.Kind, nil, patch)
+		if !ok {
+			return
+		}
+
+		draft, ok := insertDraft(c, queries, req.Kind, data)
+		if !ok {
+			return
+		}
+
+		respond.Created(c, newDraftResponse(draft))
+	}
+}
+
+// ListDrafts returns the current user's drafts, most recently saved first,
+// optionally only those of one kind
+func ListDrafts(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query DraftQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		items, ok := listDrafts(c, queries, query.Kind)
+		if !ok {
+			return
+		}
+
+		respond.OK(c, items)
+	}
+}
+
+// GetDraft returns one of the current user's drafts
+func GetDraft(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		draft, ok := loadDraft(c, queries)
+		if !ok {
+			return
+		}
+
+		respond.OK(c, newDraftResponse(draft))
+	}
+}
+
+// AutosaveDraft merges a partial payload into one of the current user's
+// drafts as a JSON Merge Patch: fields sent replace the saved ones, null
+// clears a field, and fields left out are kept
+func AutosaveDraft(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		patch, err := c.GetRawData()
+		if err != nil {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidRequest)
+			return
+		}
+
+		draft, ok := loadDraft(c, queries)
+		if !ok {
+			return
+		}
+
+		data, ok := mergeDraftData(c, draft.Kind, draft.Data, patch)
+		if !ok {
+			return
+		}
+
+		draft, err = queries.UpdateDraftData(c.Request.Context(), db.UpdateDraftDataParams{
+			ID:     draft.ID,
+			UserID: draft.UserID,
+			Data:   data,
+		})
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				// Published or discarded since it was loaded
+				respond.Error(c, http.StatusNotFound, i18n.CodeDraftNotFound)
+				return
+			}
+			logger.Error("Failed to save draft", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeDraftUpdateFailed)
+			return
+		}
+
+		respond.OK(c, newDraftResponse(draft))
+	}
+}
+
+// DeleteDraft discards one of the current user's drafts
+func DeleteDraft(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		deleted, err := queries.DeleteDraft(c.Request.Context(), db.DeleteDraftParams{
+			ID:     c.Param("id"),
+			UserID: c.GetString("user_id"),
+		})
+		if err != nil {
+			logger.Error("Failed to delete draft", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeDraftUpdateFailed)
+			return
+		}
+		if deleted == 0 {
+			respond.Error(c, http.StatusNotFound, i18n.CodeDraftNotFound)
+			return
+		}
+
+		respond.OK(c, nil)
+	}
+}
+
+// PublishDraft creates the brew or recipe a draft holds, validated exactly
+// as if it had been posted, and discards the draft. A draft that is still
+// incomplete is kept and the missing fields are returned in details.
+func PublishDraft(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		draft, ok := loadDraft(c, queries)
+		if !ok {
+			return
+		}
+
+		switch draft.Kind {
+		case draftKindBrew:
+			var req BrewRequest
+			if !bindDraft(c, draft, &req) {
+				return
+			}
+			brew, pref, ok := createBrew(c, queries, req)
+			if !ok {
+				return
+			}
+			discardDraft(c, queries, draft)
+			respond.Created(c, newBrewResponse(brew, pref))
+		case draftKindRecipe:
+			var req CreateRecipeRequest
+			if !bindDraft(c, draft, &req) {
+				return
+			}
+			recipe, rev, pref, ok := createRecipe(c, queries, req)
+			if !ok {
+				return
+			}
+			discardDraft(c, queries, draft)
+			respond.Created(c, newRecipeResponse(recipe, rev, pref))
+		}
+	}
+}
+
+// insertDraft saves data, already merged with mergeDraftData, as a new
+// draft of kind for the current user, writing an error response when the
+// user has too many drafts or the save fails
+func insertDraft(c *gin.Context, queries *db.Queries, kind string, data []byte) (db.Draft, bool) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+	count, err := queries.CountUserDrafts(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to count drafts", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeDraftUpdateFailed)
+		return db.Draft{}, false
+	}
+	if count >= maxDrafts {
+		respond.Errorf(c, http.StatusConflict, i18n.CodeDraftLimit, maxDrafts)
+		return db.Draft{}, false
+	}
+
+	draft, err := queries.CreateDraft(ctx, db.CreateDraftParams{
+		ID:     ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+		UserID: userID,
+		Kind:   kind,
+		Data:   data,
+	})
+	if err != nil {
+		logger.Error("Failed to create draft", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeDraftUpdateFailed)
+		return db.Draft{}, false
+	}
+	return draft, true
+}
+
+// listDrafts loads the current user's drafts, writing an error response on
+// failure
+func listDrafts(c *gin.Context, queries *db.Queries, kind *string) ([]DraftResponse, bool) {
+	drafts, err := queries.ListUserDrafts(c.Request.Context(), db.ListUserDraftsParams{
+		UserID: c.GetString("user_id"),
+		Kind:   kind,
+	})
+	if err != nil {
+		logger.Error("Failed to list drafts", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeDraftFetchFailed)
+		return nil, false
+	}
+
+	items := make([]DraftResponse, 0, len(drafts))
+	for _, d := range drafts {
+		items = append(items, newDraftResponse(d))
+	}
+	return items, true
+}
+
+// loadDraft fetches the current user's draft named by the :id route
+// parameter, writing an error response if it can't be loaded
+func loadDraft(c *gin.Context, queries *db.Queries) (db.Draft, bool) {
+	draft, err := queries.GetDraft(c.Request.Context(), db.GetDraftParams{
+		ID:     c.Param("id"),
+		UserID: c.GetString("user_id"),
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			respond.Error(c, http.StatusNotFound, i18n.CodeDraftNotFound)
+			return db.Draft{}, false
+		}
+		logger.Error("Failed to get draft", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeDraftFetchFailed)
+		return db.Draft{}, false
+	}
+	return draft, true
+}
+
+// mergeDraftData applies patch to a draft's data. The result must still
+// decode as the kind's creation payload, so that a draft can always be
+// published once complete; rules such as required fields aren't checked.
+func mergeDraftData(c *gin.Context, kind string, data, patch []byte) ([]byte, bool) {
+	merged, err := mergepatch.Apply(data, patch)
+	if err == nil {
+		if kind == draftKindBrew {
+			err = json.Unmarshal(merged, &BrewRequest{})
+		} else {
+			err = json.Unmarshal(merged, &CreateRecipeRequest{})
+		}
+	}
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidRequest)
+		return nil, false
+	}
+	if len(merged) > maxDraftBytes {
+		respond.Error(c, http.StatusRequestEntityTooLarge, i18n.CodeDraftTooLarge)
+		return nil, false
+	}
+	return merged, true
+}
+
+// bindDraft decodes and validates a draft's data into req, writing an error
+// response with the failing fields when the draft isn't complete
+func bindDraft(c *gin.Context, draft db.Draft, req any) bool {
+	err := json.Unmarshal(draft.Data, req)
+	if err == nil {
+		err = binding.Validator.ValidateStruct(req)
+	}
+	if err != nil {
+		respond.Rejected(c, http.StatusBadRequest, i18n.CodeDraftIncomplete, err)
+		return false
+	}
+	return true
+}
+
+// discardDraft deletes a published draft. The brew or recipe already
+// exists, so a failure is only logged; the draft can still be discarded.
+func discardDraft(c *gin.Context, queries *db.Queries, draft db.Draft) {
+	if _, err := queries.DeleteDraft(c.Request.Context(), db.DeleteDraftParams{
+		ID:     draft.ID,
+		UserID: draft.UserID,
+	}); err != nil {
+		logger.Warn("Failed to delete published draft", "draft_id", draft.ID, "error", err)
+	}
+}
+
+func newDraftResponse(d db.Draft) DraftResponse {
+	return DraftResponse{
+		ID:        d.ID,
+		Kind:      d.Kind,
+		Data:      d.Data,
+		CreatedAt: d.CreatedAt,
+		UpdatedAt: d.UpdatedAt,
+	}
+}
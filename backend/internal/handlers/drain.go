@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+
+	"brewd/internal/drain"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DrainResponse is the drain's timings, as it starts
+type DrainResponse struct {
+	Status         string `json:"status"`
+	DelaySeconds   int    `json:"delay_seconds"`
+	TimeoutSeconds int    `json:"timeout_seconds"`
+}
+
+// Drain starts draining the instance: readiness turns false at once, and the
+// servers shut down after the drain delay. Starting an instance that is
+// already draining changes nothing.
+func Drain(drainer *drain.Coordinator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		drainer.Start("drain endpoint")
+		timings := drainer.Timings()
+		respond.Status(c, http.StatusAccepted, DrainResponse{
+			Status:         "draining",
+			DelaySeconds:   int(timings.Delay.Seconds()),
+			TimeoutSeconds: int(timings.Timeout.Seconds()),
+		})
+	}
+}
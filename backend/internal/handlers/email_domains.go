@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"net/http"
+
+	"brewd/internal/db"
+	"brewd/internal/disposable"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmailDomainOverrideRequest allows a domain the disposable blocklist
+// lists, or blocks one it misses
+type EmailDomainOverrideRequest struct {
+	Action string  `json:"action" binding:"required,oneof=allow block"`
+	Note   *string `json:"note" binding:"omitempty,max=500"`
+}
+
+// EmailDomainsResponse is the size of the disposable blocklist and the
+// admin overrides that take precedence over it
+type EmailDomainsResponse struct {
+	BlocklistSize int32                    `json:"blocklist_size"`
+	Overrides     []db.EmailDomainOverride `json:"overrides"`
+}
+
+// checkDisposableEmail applies action to a normalized email at a
+// disposable domain: rejecting it, or reporting it should be flagged. It
+// responds with an error and returns ok false if registration can't go
+// ahead.
+func checkDisposableEmail(c *gin.Context, queries *db.Queries, action, email string) (flag, ok bool) {
+	if action == disposable.ActionOff {
+		return false, true
+	}
+
+	blocked, err := disposable.Blocked(c.Request.Context(), queries, email)
+	if err != nil {
+		logger.Error("Failed to check disposable email domain", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeRegistrationFailed)
+		return false, false
+	}
+	if blocked && action == disposable.ActionReject {
+		respond.Error(c, http.StatusBadRequest, i18n.CodeDisposableEmail)
+		return false, false
+	}
+	return blocked, true
+}
+
+// AdminListEmailDomains returns the size of the disposable blocklist and
+// every admin override
+func AdminListEmailDomains(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		size, err := queries.CountDisposableDomains(ctx)
+		if err != nil {
+			logger.Error("Failed to count disposable domains", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeEmailDomainFetchFailed)
+			return
+		}
+		overrides, err := queries.ListEmailDomainOverrides(ctx)
+		if err != nil {
+			logger.Error("Failed to list email domain overrides", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeEmailDomainFetchFailed)
+			return
+		}
+
+		respond.OK(c, EmailDomainsResponse{BlocklistSize: size, Overrides: overrides})
+	}
+}
+
+// AdminSetEmailDomain allows or blocks a domain and its subdomains,
+// whatever the blocklist says
+func AdminSetEmailDomain(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		domain, ok := disposable.NormalizeDomain(c.Param("domain"))
+		if !ok {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidDomain)
+			return
+		}
+		var req EmailDomainOverrideRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		adminID := c.GetString("user_id")
+		override, err := queries.SetEmailDomainOverride(c.Request.Context(), db.SetEmailDomainOverrideParams{
+			Domain:    domain,
+			Action:    req.Action,
+			Note:      req.Note,
+			CreatedBy: &adminID,
+		})
+		if err != nil {
+			logger.Error("Failed to set email domain override", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeEmailDomainUpdateFailed)
+			return
+		}
+
+		logger.Info("Email domain override set", "domain", domain, "action", req.Action, "admin_id", adminID)
+		respond.OK(c, override)
+	}
+}
+
+// AdminDeleteEmailDomain removes a domain's override, so the blocklist
+// applies to it again
+func AdminDeleteEmailDomain(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		domain, ok := disposable.NormalizeDomain(c.Param("domain"))
+		if !ok {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidDomain)
+			return
+		}
+
+		deleted, err := queries.DeleteEmailDomainOverride(c.Request.Context(), domain)
+		if err != nil {
+			logger.Error("Failed to delete email domain override", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeEmailDomainUpdateFailed)
+			return
+		}
+		if deleted == 0 {
+			respond.Error(c, http.StatusNotFound, i18n.CodeEmailDomainNotFound)
+			return
+		}
+
+		logger.Info("Email domain override removed", "domain", domain, "admin_id", c.GetString("user_id"))
+		respond.OK(c, nil)
+	}
+}
+
+// AdminListDisposableEmailUsers returns users flagged for registering with
+// a disposable email domain, newest first
+func AdminListDisposableEmailUsers(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var page PageQuery
+		if err := c.ShouldBindQuery(&page); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if page.Limit == 0 {
+			page.Limit = defaultPageLimit
+		}
+
+		rows, err := queries.ListDisposableEmailUsers(c.Request.Context(), db.ListDisposableEmailUsersParams{
+			RowLimit:  page.Limit,
+			RowOffset: page.Offset,
+		})
+		if err != nil {
+			logger.Error("Failed to list disposable email users", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeEmailDomainFetchFailed)
+			return
+		}
+
+		respond.Page(c, rows, page.Limit, page.Offset, len(rows))
+	}
+}
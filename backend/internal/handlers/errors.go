@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"errors"
+
+	"brewd/internal/auth"
+	"brewd/internal/i18n"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// usernamePolicyCode maps an identity policy violation to its error code
+func usernamePolicyCode(err error) i18n.Code {
+	switch {
+	case errors.Is(err, auth.ErrUsernameLength):
+		return i18n.CodeUsernameLength
+	case errors.Is(err, auth.ErrUsernameChars):
+		return i18n.CodeUsernameChars
+	case errors.Is(err, auth.ErrUsernameReserved):
+		return i18n.CodeUsernameReserved
+	case errors.Is(err, auth.ErrUsernameBlocked):
+		return i18n.CodeUsernameBlocked
+	default:
+		return i18n.CodeInvalidRequest
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+	"brewd/internal/telemetry"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oklog/ulid/v2"
+)
+
+// maxEventBatchBytes caps the body of one batch of analytics events
+const maxEventBatchBytes = 256 << 10
+
+// AnalyticsEventRequest is one client analytics event. OccurredAt defaults
+// to when the batch is received.
+type AnalyticsEventRequest struct {
+	Name       string                     `json:"name" binding:"required,max=64,event_name"`
+	SessionID  *string                    `json:"session_id" binding:"omitempty,min=1,max=64"`
+	Properties map[string]json.RawMessage `json:"properties" binding:"max=50"`
+	OccurredAt *time.Time                 `json:"occurred_at"`
+}
+
+// IngestEventsRequest is a batch of analytics events
+type IngestEventsRequest struct {
+	Events []AnalyticsEventRequest `json:"events" binding:"required,min=1,max=100,dive"`
+}
+
+// IngestEventsResponse reports how many events of a batch were kept and
+// how many were dropped for lack of consent or by sampling
+type IngestEventsResponse struct {
+	Accepted int `json:"accepted"`
+	Dropped  int `json:"dropped"`
+}
+
+// IngestEvents records a batch of the current user's client analytics
+// events in sink. Events are only kept for users who have consented to
+// analytics and fall within samplePercent; the rest are accepted and
+// dropped, so clients need not track either.
+func IngestEvents(queries *db.Queries, sink telemetry.Sink, samplePercent int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxEventBatchBytes)
+
+		var req IngestEventsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				respond.Error(c, http.StatusRequestEntityTooLarge, i18n.CodeEventBatchTooLarge)
+				return
+			}
+			respond.Invalid(c, err)
+			return
+		}
+
+		ctx := c.Request.Context()
+		userID := c.GetString("user_id")
+		prefs, err := queries.GetUserPreferences(ctx, userID)
+		if err != nil {
+			logger.Error("Failed to get preferences for analytics", "user_id", userID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeEventIngestFailed)
+			return
+		}
+		if !prefs.AnalyticsConsent || !telemetry.Sampled(userID, samplePercent) {
+			respond.Status(c, http.StatusAccepted, IngestEventsResponse{Dropped: len(req.Events)})
+			return
+		}
+
+		now := time.Now()
+		events := make([]telemetry.Event, 0, len(req.Events))
+		for _, e := range req.Events {
+			// Client clocks drift; an event can't have happened after it
+			// arrived
+			occurredAt := now
+			if e.OccurredAt != nil && e.OccurredAt.Before(now) {
+				occurredAt = *e.OccurredAt
+			}
+			properties := json.RawMessage("{}")
+			if len(e.Properties) > 0 {
+				// Properties were decoded from JSON, so they always encode
+				properties, _ = json.Marshal(e.Properties)
+			}
+			events = append(events, telemetry.Event{
+				ID:         ulid.MustNew(ulid.Timestamp(now), rand.Reader).String(),
+				SessionID:  e.SessionID,
+				Name:       e.Name,
+				Properties: properties,
+				OccurredAt: occurredAt,
+			})
+		}
+
+		if err := sink.Write(ctx, userID, events); err != nil {
+			logger.Error("Failed to write analytics events", "user_id", userID, "count", len(events), "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeEventIngestFailed)
+			return
+		}
+
+		respond.Status(c, http.StatusAccepted, IngestEventsResponse{Accepted: len(events)})
+	}
+}
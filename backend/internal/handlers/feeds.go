@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"time"
+
+	"brewd/internal/atom"
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/links"
+	"brewd/internal/logger"
+	"brewd/internal/methods"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// feedEntryLimit is how many of the newest entries a feed lists
+const feedEntryLimit = 50
+
+// UserFeed serves an Atom feed of a user's public brews, newest first. Feed
+// URLs are under the web app's base URL, which proxies them here.
+func UserFeed(queries *db.Queries, site *links.Site) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		profile, err := queries.GetPublicProfile(ctx, c.Param("username"))
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusNotFound, i18n.CodeUserNotFound)
+				return
+			}
+			respondFeedError(c, err)
+			return
+		}
+
+		brews, err := queries.ListPublicUserBrews(ctx, db.ListPublicUserBrewsParams{
+			CreatedBy: &profile.ID,
+			Limit:     feedEntryLimit,
+		})
+		if err != nil {
+			respondFeedError(c, err)
+			return
+		}
+
+		profileURL := site.URL(links.KindUser, profile.Username)
+		author := &atom.Person{Name: profile.Username, URI: profileURL}
+		// A feed without entries was last updated when the user joined
+		updated := profile.JoinedAt.Time
+		if !profile.JoinedAt.Valid {
+			updated = time.Now()
+		}
+		entries := make([]atom.Entry, 0, len(brews))
+		for _, b := range brews {
+			brewURL := site.URL(links.KindBrew, b.ID)
+			summary := []string{embedSummary(b.BrewMethod, b.DoseGrams, b.WaterGrams, b.WaterTempC, b.BrewTimeSeconds)}
+			if b.BeanOrigin != nil {
+				summary = append(summary, *b.BeanOrigin)
+			}
+			if b.Notes != nil {
+				summary = append(summary, *b.Notes)
+			}
+			entries = append(entries, atom.Entry{
+				ID:        brewURL,
+				Title:     b.Name,
+				Updated:   atom.Time(b.UpdatedAt),
+				Published: atom.Time(b.CreatedAt),
+				Links:     []atom.Link{{Rel: "alternate", Type: "text/html", Href: brewURL}},
+				Author:    author,
+				Summary:   joinSummary(summary),
+			})
+			if b.UpdatedAt.After(updated) {
+				updated = b.UpdatedAt
+			}
+		}
+
+		feed := atom.Feed{
+			ID:      profileURL,
+			Title:   "@" + profile.Username + " on brewd",
+			Updated: atom.Time(updated),
+			Links: []atom.Link{
+				{Rel: "alternate", Type: "text/html", Href: profileURL},
+				{Rel: "self", Type: "application/atom+xml", Href: site.Join("feeds", "users", profile.Username)},
+			},
+			Author:  author,
+			Entries: entries,
+		}
+		if profile.Bio != nil {
+			feed.Subtitle = *profile.Bio
+		}
+		writeFeed(c, feed)
+	}
+}
+
+// ClubFeed serves an Atom feed of a public club's activity: the public brews
+// and recipes members shared to it, newest first
+func ClubFeed(queries *db.Queries, site *links.Site) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		club, err := queries.GetPublicClub(ctx, c.Param("id"))
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusNotFound, i18n.CodeClubNotFound)
+				return
+			}
+			respondFeedError(c, err)
+			return
+		}
+
+		shares, err := queries.ListPublicClubFeed(ctx, db.ListPublicClubFeedParams{
+			ClubID: club.ID,
+			Limit:  feedEntryLimit,
+		})
+		if err != nil {
+			respondFeedError(c, err)
+			return
+		}
+
+		updated := club.UpdatedAt
+		entries := make([]atom.Entry, 0, len(shares))
+		for _, s := range shares {
+			// Only shares of public items are listed, so the shared
+			// item's name is always there
+			title, itemURL, method := "", "", s.BrewMethod
+			if s.BrewID != nil {
+				title, itemURL = *s.BrewName, site.URL(links.KindBrew, *s.BrewID)
+			} else {
+				title, itemURL, method = *s.RecipeName, site.URL(links.KindRecipe, *s.RecipeID), s.RecipeBrewMethod
+			}
+
+			var summary []string
+			if s.Note != nil {
+				summary = append(summary, *s.Note)
+			}
+			if method != nil {
+				if m, ok := methods.Lookup(*method); ok {
+					summary = append(summary, m.Name)
+				}
+			}
+			if s.BeanOrigin != nil {
+				summary = append(summary, *s.BeanOrigin)
+			}
+			entries = append(entries, atom.Entry{
+				ID:        "urn:brewd:club-share:" + s.ID,
+				Title:     "@" + s.Username + " shared " + title,
+				Updated:   atom.Time(s.CreatedAt),
+				Published: atom.Time(s.CreatedAt),
+				Links:     []atom.Link{{Rel: "alternate", Type: "text/html", Href: itemURL}},
+				Author:    &atom.Person{Name: s.Username, URI: site.URL(links.KindUser, s.Username)},
+				Summary:   joinSummary(summary),
+			})
+			if s.CreatedAt.After(updated) {
+				updated = s.CreatedAt
+			}
+		}
+
+		clubURL := site.Join("clubs", club.ID)
+		feed := atom.Feed{
+			ID:      clubURL,
+			Title:   club.Name + " on brewd",
+			Updated: atom.Time(updated),
+			Links: []atom.Link{
+				{Rel: "alternate", Type: "text/html", Href: clubURL},
+				{Rel: "self", Type: "application/atom+xml", Href: site.Join("feeds", "clubs", club.ID)},
+			},
+			// Entries carry their sharers, so the feed needs no author
+			Entries: entries,
+		}
+		if club.Description != nil {
+			feed.Subtitle = *club.Description
+		}
+		writeFeed(c, feed)
+	}
+}
+
+// joinSummary joins the non-empty parts of an entry summary into lines
+func joinSummary(parts []string) string {
+	var lines []string
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			lines = append(lines, p)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func writeFeed(c *gin.Context, feed atom.Feed) {
+	var buf bytes.Buffer
+	if err := atom.Write(&buf, feed); err != nil {
+		respondFeedError(c, err)
+		return
+	}
+	c.Header("Cache-Control", publicCacheControl)
+	c.Data(http.StatusOK, atom.ContentType, buf.Bytes())
+}
+
+func respondFeedError(c *gin.Context, err error) {
+	logger.Error("Failed to build feed", "error", err)
+	respond.Error(c, http.StatusInternalServerError, i18n.CodeInternal)
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/db/dbtest"
+	"brewd/internal/links"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// benchTime is when the benchmarks' brews and shares were made
+var benchTime = time.Date(2026, 3, 14, 8, 30, 0, 0, time.UTC)
+
+// BenchmarkUserFeed serves a user's Atom feed of feedEntryLimit brews,
+// from the query's rows to the written feed
+func BenchmarkUserFeed(b *testing.B) {
+	site, err := links.NewSite("https://brewd.example")
+	if err != nil {
+		b.Fatal(err)
+	}
+	bio := "Filter coffee, mostly"
+	method, origin, roaster := "v60", "Ethiopia", "Tim Wendelboe"
+	notes := "Bright & floral <3"
+	dose, water, temp, seconds := 18.0, 300.0, 94.0, int32(180)
+	brews := make([]any, feedEntryLimit)
+	for i := range brews {
+		at := benchTime.Add(-time.Duration(i) * 24 * time.Hour)
+		brews[i] = db.ListPublicUserBrewsRow{
+			ID: benchID(i), Name: fmt.Sprintf("Morning V60 #%d", i), BrewMethod: &method, BeanOrigin: &origin,
+			Roaster: &roaster, Notes: &notes, DoseGrams: &dose, WaterGrams: &water, WaterTempC: &temp,
+			BrewTimeSeconds: &seconds, CreatedAt: at, UpdatedAt: at,
+		}
+	}
+	conn := dbtest.New(b, dbtest.Script{
+		"GetPublicProfile": dbtest.Rows(db.GetPublicProfileRow{
+			ID: benchID(0), Username: "bench", Bio: &bio,
+			JoinedAt: pgtype.Timestamptz{Time: benchTime.AddDate(-1, 0, 0), Valid: true}, BrewCount: feedEntryLimit,
+		}),
+		"ListPublicUserBrews": dbtest.Rows(brews...),
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/feeds/users/:username", UserFeed(db.New(conn), site))
+	benchServe(b, conn, router, "/feeds/users/bench")
+}
+
+// BenchmarkListClubFeed serves a page of a club's feed, from binding the
+// query through the query's rows to the encoded envelope, whole and as a
+// sparse fieldset
+func BenchmarkListClubFeed(b *testing.B) {
+	note, name, method, origin := "Try this one", "Morning V60", "v60", "Ethiopia"
+	shares := make([]any, 100)
+	for i := range shares {
+		brewID := benchID(i)
+		shares[i] = db.ListClubFeedRow{
+			ID: benchID(1000 + i), UserID: benchID(0), Username: "bench", BrewID: &brewID, BrewName: &name,
+			BrewMethod: &method, BeanOrigin: &origin, Note: &note, CreatedAt: benchTime.Add(-time.Duration(i) * time.Hour),
+		}
+	}
+	conn := dbtest.New(b, dbtest.Script{
+		"GetClubByID": dbtest.Rows(db.Club{
+			ID: benchID(2000), OwnerID: benchID(0), Name: "Filter Friends", CreatedAt: benchTime, UpdatedAt: benchTime,
+		}),
+		"GetClubMemberRole": dbtest.Rows("member"),
+		"ListClubFeed":      dbtest.Rows(shares...),
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/clubs/:id/feed", func(c *gin.Context) { c.Set("user_id", benchID(0)) }, ListClubFeed(db.New(conn)))
+	for _, query := range []string{"limit=100", "limit=100&fields=id,item_type,name,created_at"} {
+		b.Run(query, func(b *testing.B) {
+			benchServe(b, conn, router, "/clubs/"+benchID(2000)+"/feed?"+query)
+		})
+	}
+}
+
+// benchServe serves GET target with router, which must answer 200
+func benchServe(b *testing.B, conn *dbtest.DB, router http.Handler, target string) {
+	b.Helper()
+	b.ReportAllocs()
+	for b.Loop() {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusOK {
+			b.Fatalf("GET %s: status %d: %s", target, rec.Code, rec.Body.String())
+		}
+		conn.Take()
+	}
+}
+
+// benchID is the i-th of a run of ULIDs
+func benchID(i int) string {
+	return fmt.Sprintf("01JQ7Z5V6N8T2K4M9R3B%06d", i)
+}
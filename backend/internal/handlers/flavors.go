@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultTopFlavors is how many descriptors top-flavor lists return by default
+const defaultTopFlavors = 10
+
+// FlavorNode is a flavor wheel descriptor with its more specific children
+type FlavorNode struct {
+	ID       string       `json:"id"`
+	Name     string       `json:"name"`
+	Depth    int32        `json:"depth"`
+	Children []FlavorNode `json:"children,omitempty"`
+}
+
+// SetFlavorsRequest represents a flavor tagging payload. DescriptorIDs is the
+// complete new set; an empty list clears it.
+type SetFlavorsRequest struct {
+	DescriptorIDs []string `json:"descriptor_ids" binding:"required,max=50,dive,required,max=100"`
+}
+
+// TopFlavorsQuery represents the origin flavor query parameters
+type TopFlavorsQuery struct {
+	Origin string `form:"origin" binding:"required,max=255"`
+	Limit  int32  `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+// TopFlavor is a descriptor and how often it was noted
+type TopFlavor struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Depth    int32  `json:"depth"`
+	Category string `json:"category"`
+	Mentions int64  `json:"mentions"`
+}
+
+// ListFlavors returns the SCA flavor wheel as a tree of categories,
+// subcategories and descriptors
+func ListFlavors(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		descriptors, err := queries.ListFlavorDescriptors(c.Request.Context())
+		if err != nil {
+			logger.Error("Failed to list flavor descriptors", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeFlavorFetchFailed)
+			return
+		}
+
+		respond.OK(c, flavorTree(descriptors))
+	}
+}
+
+// SetBrewFlavors replaces the flavor descriptors on one of the current
+// user's brews
+func SetBrewFlavors(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ids, ok := bindFlavorIDs(c, queries)
+		if !ok {
+			return
+		}
+
+		brew, ok := loadBrew(c, queries)
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+		if err := queries.SetBrewFlavors(ctx, db.SetBrewFlavorsParams{
+			BrewID:        brew.ID,
+			DescriptorIds: ids,
+		}); err != nil {
+			logger.Error("Failed to set brew flavors", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeFlavorUpdateFailed)
+			return
+		}
+
+		respondFlavors(c, queries.ListBrewFlavors, brew.ID)
+	}
+}
+
+// GetBrewFlavors returns the flavor descriptors on a brew visible to the
+// current user, or on one of their archived brews
+func GetBrewFlavors(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		brew, archived, ok := loadHistoryBrew(c, queries)
+		if !ok {
+			return
+		}
+
+		if archived {
+			respondFlavors(c, queries.ListArchivedBrewFlavors, brew.ID)
+			return
+		}
+		respondFlavors(c, queries.ListBrewFlavors, brew.ID)
+	}
+}
+
+// SetBeanBagFlavors replaces the flavor descriptors on one of the current
+// user's bean bags, e.g. the roaster's tasting notes
+func SetBeanBagFlavors(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ids, ok := bindFlavorIDs(c, queries)
+		if !ok {
+			return
+		}
+
+		bag, ok := loadOwnedBeanBag(c, queries, c.Param("id"))
+		if !ok {
+			return
+		}
+
+		if err := queries.SetBeanBagFlavors(c.Request.Context(), db.SetBeanBagFlavorsParams{
+			BeanBagID:     bag.ID,
+			DescriptorIds: ids,
+		}); err != nil {
+			logger.Error("Failed to set bean bag flavors", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeFlavorUpdateFailed)
+			return
+		}
+
+		respondFlavors(c, queries.ListBeanBagFlavors, bag.ID)
+	}
+}
+
+// GetBeanBagFlavors returns one of the current user's bean bags' own
+// descriptors, plus the most common descriptors across the bag and every
+// brew made from it
+func GetBeanBagFlavors(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bag, ok := loadOwnedBeanBag(c, queries, c.Param("id"))
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+		descriptors, err := queries.ListBeanBagFlavors(ctx, bag.ID)
+		if err != nil {
+			logger.Error("Failed to list bean bag flavors", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeFlavorFetchFailed)
+			return
+		}
+
+		rows, err := queries.GetBeanBagTopFlavors(ctx, db.GetBeanBagTopFlavorsParams{
+			BeanBagID: bag.ID,
+			RowLimit:  defaultTopFlavors,
+		})
+		if err != nil {
+			logger.Error("Failed to get bean bag top flavors", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeFlavorFetchFailed)
+			return
+		}
+
+		top := make([]TopFlavor, 0, len(rows))
+		for _, row := range rows {
+			top = append(top, TopFlavor(row))
+		}
+
+		respond.OK(c, gin.H{
+			"descriptors": flavorList(descriptors),
+			"top":         top,
+		})
+	}
+}
+
+// GetOriginFlavors returns the most common descriptors on public brews of
+// beans from ?origin
+func GetOriginFlavors(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query TopFlavorsQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if query.Limit == 0 {
+			query.Limit = defaultTopFlavors
+		}
+
+		rows, err := queries.GetOriginTopFlavors(c.Request.Context(), db.GetOriginTopFlavorsParams{
+			Origin:   query.Origin,
+			RowLimit: query.Limit,
+		})
+		if err != nil {
+			logger.Error("Failed to get origin top flavors", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeFlavorFetchFailed)
+			return
+		}
+
+		top := make([]TopFlavor, 0, len(rows))
+		for _, row := range rows {
+			top = append(top, TopFlavor(row))
+		}
+
+		respond.OK(c, gin.H{
+			"origin": query.Origin,
+			"top":    top,
+		})
+	}
+}
+
+// bindFlavorIDs binds a SetFlavorsRequest and checks every descriptor exists,
+// writing an error response otherwise. Repeated IDs are dropped.
+func bindFlavorIDs(c *gin.Context, queries *db.Queries) ([]string, bool) {
+	var req SetFlavorsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respond.Invalid(c, err)
+		return nil, false
+	}
+
+	seen := make(map[string]bool, len(req.DescriptorIDs))
+	ids := make([]string, 0, len(req.DescriptorIDs))
+	for _, id := range req.DescriptorIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	found, err := queries.CountFlavorDescriptors(c.Request.Context(), ids)
+	if err != nil {
+		logger.Error("Failed to count flavor descriptors", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeFlavorUpdateFailed)
+		return nil, false
+	}
+	if found != int64(len(ids)) {
+		respond.Error(c, http.StatusBadRequest, i18n.CodeUnknownFlavor)
+		return nil, false
+	}
+	return ids, true
+}
+
+// respondFlavors writes the descriptors that list returns for id
+func respondFlavors(c *gin.Context, list func(ctx context.Context, id string) ([]db.FlavorDescriptor, error), id string) {
+	descriptors, err := list(c.Request.Context(), id)
+	if err != nil {
+		logger.Error("Failed to list flavors", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeFlavorFetchFailed)
+		return
+	}
+
+	respond.OK(c, flavorList(descriptors))
+}
+
+// flavorList converts descriptors to childless nodes
+func flavorList(descriptors []db.FlavorDescriptor) []FlavorNode {
+	nodes := make([]FlavorNode, 0, len(descriptors))
+	for _, d := range descriptors {
+		nodes = append(nodes, FlavorNode{ID: d.ID, Name: d.Name, Depth: d.Depth})
+	}
+	return nodes
+}
+
+// flavorTree nests descriptors under their parents. Descriptors must be
+// ordered parents first, as ListFlavorDescriptors returns them.
+func flavorTree(descriptors []db.FlavorDescriptor) []FlavorNode {
+	children := make(map[string][]db.FlavorDescriptor)
+	var roots []db.FlavorDescriptor
+	for _, d := range descriptors {
+		if d.ParentID == nil {
+			roots = append(roots, d)
+		} else {
+			children[*d.ParentID] = append(children[*d.ParentID], d)
+		}
+	}
+
+	var build func([]db.FlavorDescriptor) []FlavorNode
+	build = func(level []db.FlavorDescriptor) []FlavorNode {
+		nodes := make([]FlavorNode, 0, len(level))
+		for _, d := range level {
+			nodes = append(nodes, FlavorNode{
+				ID:       d.ID,
+				Name:     d.Name,
+				Depth:    d.Depth,
+				Children: build(children[d.ID]),
+			})
+		}
+		return nodes
+	}
+	return build(roots)
+}
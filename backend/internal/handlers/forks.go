@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"net/http"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oklog/ulid/v2"
+)
+
+// ForkRecipeRequest represents the fork payload. Revision defaults to the
+// source's current revision and Name to the source's name. IsPublic is
+// ignored when forking a private recipe.
+type ForkRecipeRequest struct {
+	Name     *string `json:"name" binding:"omitempty,max=255"`
+	Revision *int32  `json:"revision" binding:"omitempty,min=1"`
+	IsPublic *bool   `json:"is_public"`
+}
+
+// ForkNode represents one recipe in a fork tree
+type ForkNode struct {
+	ID                 string    `json:"id"`
+	Name               string    `json:"name"`
+	OwnerID            string    `json:"owner_id"`
+	OwnerUsername      string    `json:"owner_username"`
+	ForkedFromID       *string   `json:"forked_from_id"`
+	ForkedFromRevision *int32    `json:"forked_from_revision"`
+	CurrentRevision    int32     `json:"current_revision"`
+	Depth              int32     `json:"depth"`
+	BrewCount          int64     `json:"brew_count"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// Ancestor represents a recipe a fork descends from
+type Ancestor struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	OwnerID        string `json:"owner_id"`
+	OwnerUsername  string `json:"owner_username"`
+	ForkedRevision *int32 `json:"forked_revision"`
+	Depth          int32  `json:"depth"`
+}
+
+// ForkTreeResponse represents a recipe's attribution graph: where it came
+// from, every fork below it, and the most brewed of those forks
+type ForkTreeResponse struct {
+	RecipeID   string     `json:"recipe_id"`
+	Ancestors  []Ancestor `json:"ancestors"`
+	Forks      []ForkNode `json:"forks"`
+	MostBrewed *ForkNode  `json:"most_brewed"`
+}
+
+// ForkRecipe clones a recipe into a new recipe owned by the current user,
+// linked back to the source revision for attribution
+func ForkRecipe(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ForkRecipeRequest
+		// The body is optional; an empty one forks the current revision
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				respond.Invalid(c, err)
+				return
+			}
+		}
+
+		userID := c.GetString("user_id")
+		source, sourceRev, ok := loadRecipeRevision(c, queries, c.Param("id"), req.Revision, userID)
+		if !ok {
+			return
+		}
+
+		// Anyone can fork a public recipe. A private one can only be forked
+		// by its owner and editors, and the fork stays private too.
+		ctx := c.Request.Context()
+		isPublic := true
+		if !source.IsPublic {
+			role, err := recipeRole(ctx, queries, source, userID)
+			if err != nil {
+				logger.Error("Failed to get recipe role", "error", err)
+				respond.Error(c, http.StatusInternalServerError, i18n.CodeRecipeFetchFailed)
+				return
+			}
+			if role != recipeRoleOwner && role != recipeRoleEditor {
+				respond.Error(c, http.StatusForbidden, i18n.CodeForbidden)
+				return
+			}
+			isPublic = false
+		} else if req.IsPublic != nil {
+			isPublic = *req.IsPublic
+		}
+
+		pref, ok := requestUnits(c, queries)
+		if !ok {
+			return
+		}
+
+		name := source.Name
+		if req.Name != nil {
+			name = *req.Name
+		}
+
+		fork, err := queries.ForkRecipe(ctx, db.ForkRecipeParams{
+			SourceID:       source.ID,
+			SourceRevision: sourceRev.Revision,
+			ID:             ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+			OwnerID:        userID,
+			Name:           name,
+			IsPublic:       isPublic,
+		})
+		if err != nil {
+			logger.Error("Failed to fork recipe", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeRecipeCreateFailed)
+			return
+		}
+
+		rev, err := queries.GetRecipeRevision(ctx, db.GetRecipeRevisionParams{
+			RecipeID: fork.ID,
+			Revision: fork.CurrentRevision,
+		})
+		if err != nil {
+			respondRevisionError(c, err)
+			return
+		}
+
+		logger.Info("Recipe forked", "recipe_id", fork.ID, "source_id", source.ID, "source_revision", sourceRev.Revision)
+
+		respond.Created(c, newRecipeResponse(fork, rev, pref))
+	}
+}
+
+// GetRecipeForks returns a recipe's fork tree and lineage. Private recipes the
+// user can't view are left out of both.
+func GetRecipeForks(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+		recipe, _, ok := loadVisibleRecipe(c, queries, c.Param("id"), userID)
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+		rows, err := queries.GetRecipeForkTree(ctx, db.GetRecipeForkTreeParams{
+			RecipeID: recipe.ID,
+			ViewerID: userID,
+		})
+		if err != nil {
+			logger.Error("Failed to get recipe fork tree", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeRecipeFetchFailed)
+			return
+		}
+
+		lineage, err := queries.GetRecipeLineage(ctx, db.GetRecipeLineageParams{
+			RecipeID: recipe.ID,
+			ViewerID: userID,
+		})
+		if err != nil {
+			logger.Error("Failed to get recipe lineage", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeRecipeFetchFailed)
+			return
+		}
+
+		resp := ForkTreeResponse{
+			RecipeID:  recipe.ID,
+			Ancestors: make([]Ancestor, 0, len(lineage)),
+			Forks:     make([]ForkNode, 0, len(rows)),
+		}
+		for _, a := range lineage {
+			if !a.Visible {
+				continue
+			}
+			resp.Ancestors = append(resp.Ancestors, Ancestor{
+				ID:             a.ID,
+				Name:           a.Name,
+				OwnerID:        a.OwnerID,
+				OwnerUsername:  a.OwnerUsername,
+				ForkedRevision: a.ForkedRevision,
+				Depth:          a.Depth,
+			})
+		}
+		for _, row := range rows {
+			resp.Forks = append(resp.Forks, ForkNode{
+				ID:                 row.ID,
+				Name:               row.Name,
+				OwnerID:            row.OwnerID,
+				OwnerUsername:      row.OwnerUsername,
+				ForkedFromID:       row.ForkedFromID,
+				ForkedFromRevision: row.ForkedFromRevision,
+				CurrentRevision:    row.CurrentRevision,
+				Depth:              row.Depth,
+				BrewCount:          row.BrewCount,
+				CreatedAt:          row.CreatedAt,
+			})
+		}
+
+		// Rows are ordered by depth then age, so ties go to the older fork
+		for i := range resp.Forks {
+			node := &resp.Forks[i]
+			if node.Depth == 0 {
+				continue
+			}
+			if resp.MostBrewed == nil || node.BrewCount > resp.MostBrewed.BrewCount {
+				resp.MostBrewed = node
+			}
+		}
+
+		respond.OK(c, resp)
+	}
+}
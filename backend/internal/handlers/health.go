@@ -3,50 +3,74 @@ package handlers
 import (
 	"net/http"
 
+	"brewd/internal/drain"
+	"brewd/internal/health"
+	"brewd/internal/respond"
 	"brewd/pkg/database"
 	"github.com/gin-gonic/gin"
 )
 
 // HealthCheck returns basic API health status (deprecated, use HealthCheckWithDB)
 func HealthCheck(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data": gin.H{
-			"status": "healthy",
-		},
+	respond.OK(c, gin.H{
+		"status": "healthy",
 	})
 }
 
-// HealthCheckWithDB returns a handler that checks both API and database health
-func HealthCheckWithDB(pool *database.Pool) gin.HandlerFunc {
+// HealthCheckWithDB returns a handler that checks API and database health,
+// along with the downstream dependencies registered with checks. It
+// answers 503 when the database or a required dependency is unhealthy;
+// optional ones only mark the status degraded. While the instance drains it
+// answers 503 draining without checking anything, so load balancers take it
+// out of rotation.
+func HealthCheckWithDB(pool *database.Pool, checks *health.Registry, drainer *drain.Coordinator) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if drainer.Draining() {
+			respond.Write(c, http.StatusServiceUnavailable, respond.Envelope{
+				Data: gin.H{
+					"status":     health.StatusDraining,
+					"api_status": health.StatusDraining,
+				},
+			})
+			return
+		}
+
 		// Use the pool's built-in health check with metrics and stats
 		healthStatus := pool.HealthCheck(c.Request.Context())
+		report := checks.Check(c.Request.Context())
 
 		// Return 503 if database is unhealthy
 		if !healthStatus.Healthy {
-			c.JSON(http.StatusServiceUnavailable, gin.H{
-				"success": false,
-				"data": gin.H{
-					"api_status": "healthy",
-					"db_status":  "unhealthy",
-					"db_error":   healthStatus.Error,
+			respond.Write(c, http.StatusServiceUnavailable, respond.Envelope{
+				Data: gin.H{
+					"status":           health.StatusUnhealthy,
+					"api_status":       "healthy",
+					"db_status":        "unhealthy",
+					"db_error":         healthStatus.Error,
 					"response_time_ms": healthStatus.ResponseTime.Milliseconds(),
-					"pool_stats": healthStatus.Stats,
+					"pool_stats":       healthStatus.Stats,
+					"dependencies":     report.Checks,
 				},
 			})
 			return
 		}
 
+		data := gin.H{
+			"status":           report.Status,
+			"api_status":       "healthy",
+			"db_status":        "healthy",
+			"response_time_ms": healthStatus.ResponseTime.Milliseconds(),
+			"pool_stats":       healthStatus.Stats,
+			"dependencies":     report.Checks,
+		}
+
+		// Return 503 if a required dependency is unhealthy
+		if report.Status == health.StatusUnhealthy {
+			respond.Write(c, http.StatusServiceUnavailable, respond.Envelope{Data: data})
+			return
+		}
+
 		// Return 200 with full health details
-		c.JSON(http.StatusOK, gin.H{
-			"success": true,
-			"data": gin.H{
-				"api_status": "healthy",
-				"db_status":  "healthy",
-				"response_time_ms": healthStatus.ResponseTime.Milliseconds(),
-				"pool_stats": healthStatus.Stats,
-			},
-		})
+		respond.OK(c, data)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"brewd/internal/billing"
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/plans"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxStoreNotificationBytes caps the body of an app store notification
+const maxStoreNotificationBytes = 256 << 10
+
+// AppleReceiptRequest is an App Store receipt, base64 encoded as StoreKit
+// provides it
+type AppleReceiptRequest struct {
+	ReceiptData string `json:"receipt_data" binding:"required,max=1048576"`
+}
+
+// GooglePurchaseRequest is a Google Play subscription purchase token
+type GooglePurchaseRequest struct {
+	PurchaseToken string `json:"purchase_token" binding:"required,max=1024"`
+}
+
+// SubmitAppleReceipt verifies an App Store receipt with Apple and applies
+// the supporter subscription in it to the current user
+func SubmitAppleReceipt(queries *db.Queries, tiers *plans.Cache, verifiers map[string]billing.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req AppleReceiptRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		submitPurchase(c, queries, tiers, verifiers, billing.ProviderApple, req.ReceiptData)
+	}
+}
+
+// SubmitGooglePurchase verifies a Google Play purchase token with Google,
+// acknowledging the purchase, and applies its supporter subscription to
+// the current user
+func SubmitGooglePurchase(queries *db.Queries, tiers *plans.Cache, verifiers map[string]billing.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req GooglePurchaseRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		submitPurchase(c, queries, tiers, verifiers, billing.ProviderGoogle, req.PurchaseToken)
+	}
+}
+
+// submitPurchase verifies receipt with provider's store and applies it to
+// the current user, responding with their plan. A purchase belongs to the
+// first user who submits it.
+func submitPurchase(c *gin.Context, queries *db.Queries, tiers *plans.Cache, verifiers map[string]billing.Verifier,
+	provider, receipt string) {
+	verifier, ok := verifiers[provider]
+	if !ok {
+		respond.Error(c, http.StatusServiceUnavailable, i18n.CodeBillingUnavailable)
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+	purchase, err := verifier.Verify(ctx, receipt)
+	if err != nil {
+		if errors.Is(err, billing.ErrInvalidReceipt) {
+			logger.Warn("Rejected app store receipt", "provider", provider, "user_id", userID, "error", err)
+			respond.Error(c, http.StatusUnprocessableEntity, i18n.CodeReceiptInvalid)
+			return
+		}
+		logger.Error("Failed to verify app store receipt", "provider", provider, "user_id", userID, "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodePurchaseVerifyFailed)
+		return
+	}
+
+	existing, err := queries.GetIAPPurchase(ctx, db.GetIAPPurchaseParams{
+		Provider:   provider,
+		PurchaseID: purchase.PurchaseID,
+	})
+	if err == nil && existing.UserID != userID {
+		respond.Error(c, http.StatusConflict, i18n.CodePurchaseInUse)
+		return
+	}
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		logger.Error("Failed to get app store purchase", "provider", provider, "user_id", userID, "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodePurchaseVerifyFailed)
+		return
+	}
+
+	if err := billing.Apply(ctx, queries, provider, userID, purchase); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		logger.Error("Failed to apply app store purchase", "provider", provider, "user_id", userID, "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodePurchaseVerifyFailed)
+		return
+	}
+	tiers.Forget(userID)
+
+	respondPlan(c, queries, userID)
+}
+
+// AppleNotification handles App Store Server Notifications about renewals,
+// grace periods, expiries and refunds. The notification only says which
+// purchase changed; its stored receipt is verified with Apple again.
+func AppleNotification(queries *db.Queries, tiers *plans.Cache, verifiers map[string]billing.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		verifier, ok := verifiers[billing.ProviderApple]
+		if !ok {
+			respond.Error(c, http.StatusServiceUnavailable, i18n.CodeBillingUnavailable)
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxStoreNotificationBytes)
+		body, err := c.GetRawData()
+		if err != nil {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidRequest)
+			return
+		}
+		transactionID, err := billing.AppleNotificationTransaction(body)
+		if err != nil {
+			logger.Warn("Rejected App Store notification", "error", err)
+			respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidRequest)
+			return
+		}
+
+		reverifyPurchase(c, queries, tiers, verifier, billing.ProviderApple, transactionID)
+	}
+}
+
+// GoogleNotification handles Google Play real-time developer notifications
+// pushed by Cloud Pub/Sub to a URL carrying token. Like App Store
+// notifications, they only say which purchase to verify again.
+func GoogleNotification(queries *db.Queries, tiers *plans.Cache, verifiers map[string]billing.Verifier, token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		verifier, ok := verifiers[billing.ProviderGoogle]
+		if !ok || token == "" {
+			respond.Error(c, http.StatusServiceUnavailable, i18n.CodeBillingUnavailable)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(c.Query("token")), []byte(token)) != 1 {
+			respond.Error(c, http.StatusUnauthorized, i18n.CodeWebhookSignatureInvalid)
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxStoreNotificationBytes)
+		body, err := c.GetRawData()
+		if err != nil {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidRequest)
+			return
+		}
+		purchaseToken, err := billing.PlayNotificationToken(body)
+		if err != nil {
+			logger.Warn("Rejected Play notification", "error", err)
+			respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidRequest)
+			return
+		}
+		if purchaseToken == "" {
+			// A test notification, or one about a one-time product
+			respond.OK(c, nil)
+			return
+		}
+
+		reverifyPurchase(c, queries, tiers, verifier, billing.ProviderGoogle, purchaseToken)
+	}
+}
+
+// reverifyPurchase verifies a stored purchase with its store again and
+// applies the result. Purchases no user has submitted yet are ignored; the
+// app submits them. Only a failure to reach the store or database is an
+// error, so the store retries the notification.
+func reverifyPurchase(c *gin.Context, queries *db.Queries, tiers *plans.Cache, verifier billing.Verifier, provider, purchaseID string) {
+	ctx := c.Request.Context()
+	stored, err := queries.GetIAPPurchase(ctx, db.GetIAPPurchaseParams{
+		Provider:   provider,
+		PurchaseID: purchaseID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		respond.OK(c, nil)
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to get app store purchase", "provider", provider, "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeInternal)
+		return
+	}
+
+	purchase, err := billing.Reverify(ctx, verifier, stored)
+	if errors.Is(err, billing.ErrOtherPurchase) {
+		logger.Warn("App store notification not applied", "provider", provider, "user_id", stored.UserID,
+			"purchase_id", stored.PurchaseID, "error", err)
+		respond.OK(c, nil)
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to verify app store purchase", "provider", provider, "user_id", stored.UserID, "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeInternal)
+		return
+	}
+
+	if err := billing.Apply(ctx, queries, provider, stored.UserID, purchase); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		logger.Error("Failed to apply app store purchase", "provider", provider, "user_id", stored.UserID, "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeInternal)
+		return
+	}
+	tiers.Forget(stored.UserID)
+	logger.Info("Applied app store notification", "provider", provider, "user_id", stored.UserID,
+		"status", purchase.Status)
+
+	respond.OK(c, nil)
+}
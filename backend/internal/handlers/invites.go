@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/invites"
+	"brewd/internal/links"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// recentReferralsLimit is how many of the newest referred users the
+// referral stats list
+const recentReferralsLimit = 20
+
+// CreateInviteRequest sets how many signups an invite allows and for how
+// long. MaxUses defaults to 1; without ExpiresInDays it never expires.
+type CreateInviteRequest struct {
+	MaxUses       int32  `json:"max_uses" binding:"omitempty,min=1,max=100"`
+	ExpiresInDays *int32 `json:"expires_in_days" binding:"omitempty,min=1,max=365"`
+}
+
+// InviteResponse is an invite and the link to share it by
+type InviteResponse struct {
+	Code      string     `json:"code"`
+	URL       string     `json:"url"`
+	MaxUses   int32      `json:"max_uses"`
+	UseCount  int32      `json:"use_count"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// InviteCheckResponse tells the signup page whether an invite can be
+// redeemed, and who it's from
+type InviteCheckResponse struct {
+	Code            string `json:"code"`
+	InviterUsername string `json:"inviter_username"`
+	Redeemable      bool   `json:"redeemable"`
+}
+
+// InviteQuotaResponse is how many invite uses a user may hand out. Limit
+// and Remaining are null for admins, who have no quota.
+type InviteQuotaResponse struct {
+	Limit     *int32 `json:"limit"`
+	Used      int32  `json:"used"`
+	Remaining *int32 `json:"remaining"`
+}
+
+// ReferralResponse is a user who signed up with one of the current user's
+// invites
+type ReferralResponse struct {
+	ID         string     `json:"id"`
+	Username   string     `json:"username"`
+	InviteCode *string    `json:"invite_code"`
+	JoinedAt   *time.Time `json:"joined_at"`
+}
+
+// ReferralStatsResponse summarizes the signups the current user's invites
+// brought in. Active referrals logged a brew in the last 30 days.
+type ReferralStatsResponse struct {
+	Signups         int32               `json:"signups"`
+	RecentSignups   int32               `json:"recent_signups"`
+	ActiveReferrals int32               `json:"active_referrals"`
+	Invites         InviteQuotaResponse `json:"invites"`
+	Recent          []ReferralResponse  `json:"recent"`
+}
+
+// CreateInvite creates an invite for the current user to share. Each use
+// it allows counts against their quota of invite uses until it is revoked
+// or expires; admins have no quota.
+func CreateInvite(queries *db.Queries, site *links.Site, quota int, adminUserIDs []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req CreateInviteRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if req.MaxUses == 0 {
+			req.MaxUses = 1
+		}
+
+		code, err := invites.NewCode()
+		if err != nil {
+			logger.Error("Failed to generate invite code", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeInviteUpdateFailed)
+			return
+		}
+		userID := c.GetString("user_id")
+		params := db.CreateInviteParams{
+			InviterID: userID,
+			Code:      code,
+			MaxUses:   req.MaxUses,
+			Quota:     inviteQuota(userID, quota, adminUserIDs),
+		}
+		if req.ExpiresInDays != nil {
+			expiresAt := time.Now().AddDate(0, 0, int(*req.ExpiresInDays))
+			params.ExpiresAt = timestamptz(&expiresAt)
+		}
+
+		invite, err := queries.CreateInvite(c.Request.Context(), params)
+		if errors.Is(err, pgx.ErrNoRows) {
+			respond.Error(c, http.StatusConflict, i18n.CodeInviteQuotaExceeded)
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to create invite", "user_id", userID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeInviteUpdateFailed)
+			return
+		}
+
+		logger.Info("Invite created", "user_id", userID, "max_uses", invite.MaxUses)
+		respond.Created(c, newInviteResponse(site, invite))
+	}
+}
+
+// ListInvites returns the current user's invites, newest first
+func ListInvites(queries *db.Queries, site *links.Site) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+		rows, err := queries.ListUserInvites(c.Request.Context(), userID)
+		if err != nil {
+			logger.Error("Failed to list invites", "user_id", userID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeInviteFetchFailed)
+			return
+		}
+
+		response := make([]InviteResponse, 0, len(rows))
+		for _, invite := range rows {
+			response = append(response, newInviteResponse(site, invite))
+		}
+		respond.OK(c, response)
+	}
+}
+
+// RevokeInvite revokes one of the current user's invites. Signups it
+// already brought in keep their attribution.
+func RevokeInvite(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+		revoked, err := queries.RevokeInvite(c.Request.Context(), db.RevokeInviteParams{
+			Code:      invites.NormalizeCode(c.Param("code")),
+			InviterID: userID,
+		})
+		if err != nil {
+			logger.Error("Failed to revoke invite", "user_id", userID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeInviteUpdateFailed)
+			return
+		}
+		if revoked == 0 {
+			respond.Error(c, http.StatusNotFound, i18n.CodeInviteNotFound)
+			return
+		}
+
+		respond.OK(c, nil)
+	}
+}
+
+// CheckInvite tells the signup page whether an invite can be redeemed and
+// who created it
+func CheckInvite(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		invite, err := queries.GetInvite(c.Request.Context(), invites.NormalizeCode(c.Param("code")))
+		if errors.Is(err, pgx.ErrNoRows) {
+			respond.Error(c, http.StatusNotFound, i18n.CodeInviteNotFound)
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to get invite", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeInviteFetchFailed)
+			return
+		}
+
+		respond.OK(c, InviteCheckResponse{
+			Code:            invite.Code,
+			InviterUsername: invite.InviterUsername,
+			Redeemable:      invite.Redeemable,
+		})
+	}
+}
+
+// GetReferralStats returns the signups the current user's invites brought
+// in, the newest of them, and what's left of their invite quota
+func GetReferralStats(queries *db.Queries, quota int, adminUserIDs []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		userID := c.GetString("user_id")
+		stats, err := queries.GetReferralStats(ctx, userID)
+		if err != nil {
+			logger.Error("Failed to get referral stats", "user_id", userID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeInviteFetchFailed)
+			return
+		}
+		rows, err := queries.ListReferrals(ctx, db.ListReferralsParams{
+			InviterID: userID,
+			RowLimit:  recentReferralsLimit,
+		})
+		if err != nil {
+			logger.Error("Failed to list referrals", "user_id", userID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeInviteFetchFailed)
+			return
+		}
+
+		response := ReferralStatsResponse{
+			Signups:         stats.Signups,
+			RecentSignups:   stats.RecentSignups,
+			ActiveReferrals: stats.ActiveReferrals,
+			Invites:         InviteQuotaResponse{Used: stats.AllottedUses},
+			Recent:          make([]ReferralResponse, 0, len(rows)),
+		}
+		if limit := inviteQuota(userID, quota, adminUserIDs); limit != nil {
+			remaining := max(*limit-stats.AllottedUses, 0)
+			response.Invites.Limit = limit
+			response.Invites.Remaining = &remaining
+		}
+		for _, row := range rows {
+			response.Recent = append(response.Recent, ReferralResponse{
+				ID:         row.ID,
+				Username:   row.Username,
+				InviteCode: row.InviteCode,
+				JoinedAt:   timePtr(row.JoinedAt),
+			})
+		}
+
+		respond.OK(c, response)
+	}
+}
+
+// inviteQuota is userID's quota of invite uses, or nil for admins
+func inviteQuota(userID string, quota int, adminUserIDs []string) *int32 {
+	if slices.Contains(adminUserIDs, userID) {
+		return nil
+	}
+	limit := int32(quota)
+	return &limit
+}
+
+func newInviteResponse(site *links.Site, invite db.Invite) InviteResponse {
+	return InviteResponse{
+		Code:      invite.Code,
+		URL:       invites.URL(site, invite.Code),
+		MaxUses:   invite.MaxUses,
+		UseCount:  invite.UseCount,
+		ExpiresAt: timePtr(invite.ExpiresAt),
+		RevokedAt: timePtr(invite.RevokedAt),
+		CreatedAt: invite.CreatedAt,
+	}
+}
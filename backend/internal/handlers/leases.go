@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JobLeaseResponse is which instance runs a singleton background job. An
+// expired lease is free for any instance to take.
+type JobLeaseResponse struct {
+	Job        string    `json:"job"`
+	Holder     string    `json:"holder"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Epoch      int64     `json:"epoch"`
+	Expired    bool      `json:"expired"`
+}
+
+// AdminListJobLeases lists the leases of singleton background jobs, showing
+// which instance runs each
+func AdminListJobLeases(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rows, err := queries.ListJobLeases(c.Request.Context())
+		if err != nil {
+			logger.Error("Failed to list job leases", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeJobLeasesFetchFailed)
+			return
+		}
+
+		now := time.Now()
+		leases := make([]JobLeaseResponse, 0, len(rows))
+		for _, row := range rows {
+			leases = append(leases, JobLeaseResponse{
+				Job:        row.Name,
+				Holder:     row.Holder,
+				AcquiredAt: row.AcquiredAt.Time,
+				ExpiresAt:  row.ExpiresAt.Time,
+				Epoch:      row.Epoch,
+				Expired:    !row.ExpiresAt.Time.After(now),
+			})
+		}
+		respond.OK(c, leases)
+	}
+}
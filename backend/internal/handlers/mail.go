@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/mail"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxMailWebhookBytes caps the body of a mail provider webhook
+const maxMailWebhookBytes = 1 << 20
+
+// SuppressEmailRequest suppresses an address by hand, e.g. at its owner's
+// request
+type SuppressEmailRequest struct {
+	Email  string  `json:"email" binding:"required,email,max=254"`
+	Detail *string `json:"detail" binding:"omitempty,max=500"`
+}
+
+// TestEmailRequest is the recipient of a test email
+type TestEmailRequest struct {
+	To string `json:"to" binding:"required,email,max=254"`
+}
+
+// MailWebhook handles a mail provider's bounce and complaint webhooks,
+// posted to a URL carrying token, and suppresses the addresses they
+// report. The provider is named by the :provider path parameter.
+func MailWebhook(queries *db.Queries, token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			respond.Error(c, http.StatusServiceUnavailable, i18n.CodeMailUnavailable)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(c.Query("token")), []byte(token)) != 1 {
+			respond.Error(c, http.StatusUnauthorized, i18n.CodeWebhookSignatureInvalid)
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxMailWebhookBytes)
+		body, err := c.GetRawData()
+		if err != nil {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidRequest)
+			return
+		}
+
+		provider := c.Param("provider")
+		suppressions, confirmURL, err := mail.ParseEvents(provider, body)
+		if err != nil {
+			logger.Warn("Rejected mail webhook", "provider", provider, "error", err)
+			respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidRequest)
+			return
+		}
+
+		ctx := c.Request.Context()
+		if confirmURL != "" {
+			if err := mail.ConfirmSubscription(ctx, confirmURL); err != nil {
+				logger.Error("Failed to confirm mail webhook subscription", "provider", provider, "error", err)
+				respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidRequest)
+				return
+			}
+			logger.Info("Confirmed mail webhook subscription", "provider", provider)
+			respond.OK(c, nil)
+			return
+		}
+
+		for _, s := range suppressions {
+			email := mail.NormalizeAddress(s.Email)
+			if email == "" {
+				continue
+			}
+			var detail *string
+			if s.Detail != "" {
+				detail = &s.Detail
+			}
+			if err := queries.SuppressEmail(ctx, db.SuppressEmailParams{
+				Email:    email,
+				Reason:   s.Reason,
+				Provider: &provider,
+				Detail:   detail,
+			}); err != nil {
+				// The provider retries the webhook
+				logger.Error("Failed to suppress email", "provider", provider, "error", err)
+				respond.Error(c, http.StatusInternalServerError, i18n.CodeInternal)
+				return
+			}
+			logger.Info("Email suppressed", "provider", provider, "reason", s.Reason)
+		}
+
+		respond.OK(c, nil)
+	}
+}
+
+// AdminListEmailSuppressions returns suppressed addresses, newest first
+func AdminListEmailSuppressions(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var page PageQuery
+		if err := c.ShouldBindQuery(&page); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if page.Limit == 0 {
+			page.Limit = defaultPageLimit
+		}
+
+		suppressions, err := queries.ListEmailSuppressions(c.Request.Context(), db.ListEmailSuppressionsParams{
+			RowLimit:  page.Limit,
+			RowOffset: page.Offset,
+		})
+		if err != nil {
+			logger.Error("Failed to list email suppressions", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeEmailSuppressionFetchFailed)
+			return
+		}
+
+		respond.Page(c, suppressions, page.Limit, page.Offset, len(suppressions))
+	}
+}
+
+// AdminSuppressEmail adds an address to the suppression list by hand
+func AdminSuppressEmail(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req SuppressEmailRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		if err := queries.SuppressEmail(c.Request.Context(), db.SuppressEmailParams{
+			Email:  mail.NormalizeAddress(req.Email),
+			Reason: mail.ReasonManual,
+			Detail: req.Detail,
+		}); err != nil {
+			logger.Error("Failed to suppress email", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeEmailSuppressionUpdateFailed)
+			return
+		}
+
+		logger.Info("Email suppressed", "reason", mail.ReasonManual, "admin_id", c.GetString("user_id"))
+		respond.OK(c, nil)
+	}
+}
+
+// AdminUnsuppressEmail removes an address from the suppression list, e.g.
+// once its owner has fixed their mailbox
+func AdminUnsuppressEmail(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		deleted, err := queries.DeleteEmailSuppression(c.Request.Context(), mail.NormalizeAddress(c.Param("email")))
+		if err != nil {
+			logger.Error("Failed to delete email suppression", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeEmailSuppressionUpdateFailed)
+			return
+		}
+		if deleted == 0 {
+			respond.Error(c, http.StatusNotFound, i18n.CodeEmailSuppressionNotFound)
+			return
+		}
+
+		logger.Info("Email suppression removed", "admin_id", c.GetString("user_id"))
+		respond.OK(c, nil)
+	}
+}
+
+// AdminSendTestEmail sends a test email through mailer, to check the mail
+// configuration. Suppressed recipients are refused like any other mail.
+func AdminSendTestEmail(mailer *mail.Mailer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req TestEmailRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		err := mailer.Send(c.Request.Context(), mail.Message{
+			To:      req.To,
+			Subject: "brewd test email",
+			Text:    "This is a test email from brewd. If you can read it, mail is set up correctly.",
+		})
+		if errors.Is(err, mail.ErrSuppressed) {
+			respond.Error(c, http.StatusConflict, i18n.CodeEmailSuppressed)
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to send test email", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeEmailSendFailed)
+			return
+		}
+
+		logger.Info("Test email sent", "admin_id", c.GetString("user_id"))
+		respond.OK(c, nil)
+	}
+}
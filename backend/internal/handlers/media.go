@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/media"
+	"brewd/internal/objectstore"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServeMedia streams an object from the store through a URL signed by the
+// app media signer. The URL is the credential, so no session is needed;
+// it is refused once expired.
+func ServeMedia(store objectstore.Store, signer *media.AppSigner) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimPrefix(c.Param("key"), "/")
+		expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+		filename := c.Query("filename")
+		if err != nil || !signer.Verify(key, expires, filename, c.Query("signature")) {
+			respond.Error(c, http.StatusForbidden, i18n.CodeMediaLinkInvalid)
+			return
+		}
+
+		body, err := store.Get(c.Request.Context(), key)
+		if errors.Is(err, objectstore.ErrNotFound) {
+			respond.Error(c, http.StatusNotFound, i18n.CodeMediaNotFound)
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to read media object", "key", key, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeMediaNotFound)
+			return
+		}
+		defer body.Close()
+
+		contentType := mime.TypeByExtension(path.Ext(key))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		// The link may be cached until it expires, by the browser only
+		maxAge := max(0, expires-time.Now().Unix())
+		c.Header("Cache-Control", "private, max-age="+strconv.FormatInt(maxAge, 10))
+		c.Header("X-Content-Type-Options", "nosniff")
+		if filename != "" {
+			c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+		}
+		c.Header("Content-Type", contentType)
+		c.Status(http.StatusOK)
+		if _, err := io.Copy(c.Writer, body); err != nil {
+			logger.Warn("Failed to stream media object", "key", key, "error", err)
+		}
+	}
+}
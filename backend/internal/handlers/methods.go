@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"brewd/internal/methods"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListMethods returns the brew method catalog with each method's expected
+// parameters and ranges, in canonical units
+func ListMethods() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respond.OK(c, methods.Catalog)
+	}
+}
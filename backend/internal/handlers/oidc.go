@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"brewd/internal/accounts"
+	"brewd/internal/auth"
+	"brewd/internal/cookies"
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/links"
+	"brewd/internal/logger"
+	"brewd/internal/oidc"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// OAuth error codes (RFC 6749 and OpenID Connect), which the protocol
+// endpoints answer with instead of the API's envelope
+const (
+	oauthInvalidRequest          = "invalid_request"
+	oauthInvalidClient           = "invalid_client"
+	oauthInvalidGrant            = "invalid_grant"
+	oauthInvalidScope            = "invalid_scope"
+	oauthInvalidToken            = "invalid_token"
+	oauthUnsupportedGrantType    = "unsupported_grant_type"
+	oauthUnsupportedResponseType = "unsupported_response_type"
+	oauthLoginRequired           = "login_required"
+	oauthServerError             = "server_error"
+)
+
+// OIDCAuthorizeQuery is an OIDC authentication request
+type OIDCAuthorizeQuery struct {
+	ResponseType        string `form:"response_type"`
+	ClientID            string `form:"client_id"`
+	RedirectURI         string `form:"redirect_uri"`
+	Scope               string `form:"scope"`
+	State               string `form:"state"`
+	Nonce               string `form:"nonce"`
+	CodeChallenge       string `form:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method"`
+	Prompt              string `form:"prompt"`
+}
+
+// OIDCTokenRequest is a token request, form encoded. The client
+// authenticates with HTTP Basic or the client_id and client_secret fields.
+type OIDCTokenRequest struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+// OIDCTokenResponse is a successful token response
+type OIDCTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	IDToken     string `json:"id_token"`
+	Scope       string `json:"scope"`
+}
+
+// OAuthError is a protocol endpoint's error response
+type OAuthError struct {
+	Error string `json:"error"`
+}
+
+// OIDCDiscovery serves the provider's OpenID configuration
+func OIDCDiscovery(provider *oidc.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=3600")
+		c.JSON(http.StatusOK, provider.Discovery())
+	}
+}
+
+// OIDCKeys serves the public key ID and access tokens are signed with
+func OIDCKeys(provider *oidc.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=3600")
+		c.JSON(http.StatusOK, provider.Keys())
+	}
+}
+
+// OIDCAuthorize signs the user in to a companion service: the browser
+// arrives from the client and is sent back to its redirect URI with an
+// authorization code. Users are recognised by the cookie mode session;
+// without one they're sent to the web app's login page, which returns
+// them here after, as are users whose account has since been deactivated.
+// Clients are first-party services registered by admins, so there is no
+// consent screen.
+func OIDCAuthorize(queries *db.Queries, provider *oidc.Provider, authService auth.AuthService, activeCache *accounts.Cache, site *links.Site) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query OIDCAuthorizeQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		// Until the redirect URI is known to be the client's, errors are
+		// shown here rather than sent to it
+		ctx := c.Request.Context()
+		client, err := queries.GetOIDCClient(ctx, query.ClientID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			logger.Error("Failed to get OIDC client", "client_id", query.ClientID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeOIDCAuthorizeFailed)
+			return
+		}
+		if err != nil || !slices.Contains(client.RedirectUris, query.RedirectURI) {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeOIDCClientInvalid)
+			return
+		}
+
+		fail := func(code string) {
+			redirectWith(c, query.RedirectURI, url.Values{"error": {code}, "state": {query.State}})
+		}
+		if query.ResponseType != "code" {
+			fail(oauthUnsupportedResponseType)
+			return
+		}
+		scopes, ok := oidc.ParseScopes(query.Scope)
+		if !ok {
+			fail(oauthInvalidScope)
+			return
+		}
+		if query.CodeChallenge != "" && (query.CodeChallengeMethod != "S256" || !oidc.ValidChallenge(query.CodeChallenge)) {
+			fail(oauthInvalidRequest)
+			return
+		}
+
+		var claims *auth.Claims
+		if token, err := c.Cookie(cookies.Session); err == nil {
+			claims, _ = authService.ValidateToken(token)
+		}
+		if claims != nil && !activeCache.Active(ctx, claims.UserID) {
+			claims = nil
+		}
+		if claims == nil {
+			if query.Prompt == "none" {
+				fail(oauthLoginRequired)
+				return
+			}
+			next := provider.Issuer() + c.Request.URL.RequestURI()
+			c.Redirect(http.StatusFound, site.Join("login")+"?"+url.Values{"next": {next}}.Encode())
+			return
+		}
+
+		code, hash, err := oidc.NewToken()
+		if err != nil {
+			logger.Error("Failed to generate OIDC authorization code", "error", err)
+			fail(oauthServerError)
+			return
+		}
+		params := db.CreateOIDCAuthCodeParams{
+			CodeHash:    hash,
+			ClientID:    client.ID,
+			UserID:      claims.UserID,
+			RedirectUri: query.RedirectURI,
+			Scopes:      scopes,
+			AuthTime:    pgtype.Timestamptz{Time: claims.IssuedAt.Time, Valid: true},
+			TtlSeconds:  int32(oidc.CodeTTL / time.Second),
+		}
+		if query.Nonce != "" {
+			params.Nonce = &query.Nonce
+		}
+		if query.CodeChallenge != "" {
+			params.CodeChallenge = &query.CodeChallenge
+		}
+		if err := queries.CreateOIDCAuthCode(ctx, params); err != nil {
+			logger.Error("Failed to create OIDC authorization code", "client_id", client.ID, "user_id", claims.UserID, "error", err)
+			fail(oauthServerError)
+			return
+		}
+
+		redirectWith(c, query.RedirectURI, url.Values{"code": {code}, "state": {query.State}})
+	}
+}
+
+// redirectWith redirects to a client's redirect URI with params added to
+// its query; an empty state is left out
+func redirectWith(c *gin.Context, redirectURI string, params url.Values) {
+	// Registered redirect URIs were validated, so they always parse
+	u, _ := url.Parse(redirectURI)
+	q := u.Query()
+	for k, v := range params {
+		if v[0] != "" {
+			q[k] = v
+		}
+	}
+	u.RawQuery = q.Encode()
+	c.Redirect(http.StatusFound, u.String())
+}
+
+// OIDCToken redeems an authorization code for an ID token and an access
+// token. A code can be redeemed once, by the client it was issued to, with
+// the same redirect URI and, if the client sent a PKCE challenge, the
+// matching verifier.
+func OIDCToken(queries *db.Queries, provider *oidc.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "no-store")
+
+		var req OIDCTokenRequest
+		if err := c.ShouldBindWith(&req, binding.FormPost); err != nil {
+			c.JSON(http.StatusBadRequest, OAuthError{Error: oauthInvalidRequest})
+			return
+		}
+		clientID, secret := req.ClientID, req.ClientSecret
+		if user, pass, ok := c.Request.BasicAuth(); ok {
+			// Basic credentials are form encoded first (RFC 6749 §2.3.1)
+			clientID, _ = url.QueryUnescape(user)
+			secret, _ = url.QueryUnescape(pass)
+		}
+
+		ctx := c.Request.Context()
+		client, err := queries.GetOIDCClient(ctx, clientID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			logger.Error("Failed to get OIDC client", "client_id", clientID, "error", err)
+			c.JSON(http.StatusInternalServerError, OAuthError{Error: oauthServerError})
+			return
+		}
+		if err != nil || subtle.ConstantTimeCompare([]byte(oidc.Hash(secret)), []byte(client.SecretHash)) != 1 {
+			c.JSON(http.StatusUnauthorized, OAuthError{Error: oauthInvalidClient})
+			return
+		}
+		if req.GrantType != "authorization_code" {
+			c.JSON(http.StatusBadRequest, OAuthError{Error: oauthUnsupportedGrantType})
+			return
+		}
+
+		code, err := queries.RedeemOIDCAuthCode(ctx, oidc.Hash(req.Code))
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			logger.Error("Failed to redeem OIDC authorization code", "client_id", client.ID, "error", err)
+			c.JSON(http.StatusInternalServerError, OAuthError{Error: oauthServerError})
+			return
+		}
+		if err != nil || code.ClientID != client.ID || code.RedirectUri != req.RedirectURI ||
+			!code.ExpiresAt.Time.After(time.Now()) ||
+			(code.CodeChallenge != nil && !oidc.VerifyChallenge(*code.CodeChallenge, req.CodeVerifier)) {
+			c.JSON(http.StatusBadRequest, OAuthError{Error: oauthInvalidGrant})
+			return
+		}
+
+		user, err := queries.GetOIDCUser(ctx, code.UserID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusBadRequest, OAuthError{Error: oauthInvalidGrant})
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to get OIDC user", "user_id", code.UserID, "error", err)
+			c.JSON(http.StatusInternalServerError, OAuthError{Error: oauthServerError})
+			return
+		}
+
+		var nonce string
+		if code.Nonce != nil {
+			nonce = *code.Nonce
+		}
+		idToken, err := provider.IDToken(client.ID, user.ID, oidc.NewProfile(user, code.Scopes), nonce, code.AuthTime.Time)
+		if err != nil {
+			logger.Error("Failed to sign ID token", "client_id", client.ID, "error", err)
+			c.JSON(http.StatusInternalServerError, OAuthError{Error: oauthServerError})
+			return
+		}
+		accessToken, err := provider.AccessToken(client.ID, user.ID, code.Scopes)
+		if err != nil {
+			logger.Error("Failed to sign access token", "client_id", client.ID, "error", err)
+			c.JSON(http.StatusInternalServerError, OAuthError{Error: oauthServerError})
+			return
+		}
+
+		c.JSON(http.StatusOK, OIDCTokenResponse{
+			AccessToken: accessToken,
+			TokenType:   "Bearer",
+			ExpiresIn:   int(oidc.TokenTTL / time.Second),
+			IDToken:     idToken,
+			Scope:       strings.Join(code.Scopes, " "),
+		})
+	}
+}
+
+// OIDCUserInfo returns the claims an access token's scopes release about
+// its user, current as of the request
+func OIDCUserInfo(queries *db.Queries, provider *oidc.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "no-store")
+
+		token, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		var claims *oidc.AccessClaims
+		if found {
+			claims, _ = provider.ValidateAccessToken(token)
+		}
+		if claims == nil {
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			c.JSON(http.StatusUnauthorized, OAuthError{Error: oauthInvalidToken})
+			return
+		}
+
+		user, err := queries.GetOIDCUser(c.Request.Context(), claims.Subject)
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			c.JSON(http.StatusUnauthorized, OAuthError{Error: oauthInvalidToken})
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to get OIDC user", "user_id", claims.Subject, "error", err)
+			c.JSON(http.StatusInternalServerError, OAuthError{Error: oauthServerError})
+			return
+		}
+
+		c.JSON(http.StatusOK, oidc.UserInfo{
+			Subject: user.ID,
+			Profile: oidc.NewProfile(user, strings.Fields(claims.Scope)),
+		})
+	}
+}
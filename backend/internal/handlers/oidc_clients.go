@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"net/http"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/oidc"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oklog/ulid/v2"
+)
+
+// OIDCClientRequest registers a companion service with the OIDC provider
+type OIDCClientRequest struct {
+	Name         string   `json:"name" binding:"required,max=100"`
+	RedirectURIs []string `json:"redirect_uris" binding:"required,min=1,max=10,dive,required,max=2000"`
+}
+
+// OIDCClientResponse is a registered client. ClientSecret is only set
+// when the client is created.
+type OIDCClientResponse struct {
+	ClientID     string    `json:"client_id"`
+	Name         string    `json:"name"`
+	ClientSecret string    `json:"client_secret,omitempty"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// AdminCreateOIDCClient registers a companion service that signs users in
+// with brewd. Its secret is returned once; only the hash is kept.
+func AdminCreateOIDCClient(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req OIDCClientRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		for _, uri := range req.RedirectURIs {
+			if !oidc.ValidRedirectURI(uri) {
+				respond.Error(c, http.StatusBadRequest, i18n.CodeRedirectURIInvalid)
+				return
+			}
+		}
+
+		secret, hash, err := oidc.NewToken()
+		if err != nil {
+			logger.Error("Failed to generate OIDC client secret", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeOIDCClientUpdateFailed)
+			return
+		}
+
+		client, err := queries.CreateOIDCClient(c.Request.Context(), db.CreateOIDCClientParams{
+			ID:           ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+			Name:         req.Name,
+			SecretHash:   hash,
+			RedirectUris: req.RedirectURIs,
+		})
+		if err != nil {
+			logger.Error("Failed to create OIDC client", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeOIDCClientUpdateFailed)
+			return
+		}
+
+		resp := newOIDCClientResponse(client)
+		resp.ClientSecret = secret
+		respond.Created(c, resp)
+	}
+}
+
+// AdminListOIDCClients returns every registered client, newest first,
+// without their secrets
+func AdminListOIDCClients(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clients, err := queries.ListOIDCClients(c.Request.Context())
+		if err != nil {
+			logger.Error("Failed to list OIDC clients", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeOIDCClientFetchFailed)
+			return
+		}
+
+		data := make([]OIDCClientResponse, 0, len(clients))
+		for _, client := range clients {
+			data = append(data, newOIDCClientResponse(client))
+		}
+
+		respond.OK(c, data)
+	}
+}
+
+// AdminDeleteOIDCClient removes a client, so users can no longer sign in
+// to it. Tokens it already holds last until they expire.
+func AdminDeleteOIDCClient(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rows, err := queries.DeleteOIDCClient(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			logger.Error("Failed to delete OIDC client", "client_id", c.Param("id"), "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeOIDCClientUpdateFailed)
+			return
+		}
+		if rows == 0 {
+			respond.Error(c, http.StatusNotFound, i18n.CodeOIDCClientNotFound)
+			return
+		}
+
+		respond.OK(c, nil)
+	}
+}
+
+func newOIDCClientResponse(client db.OidcClient) OIDCClientResponse {
+	return OIDCClientResponse{
+		ClientID:     client.ID,
+		Name:         client.Name,
+		RedirectURIs: client.RedirectUris,
+		CreatedAt:    client.CreatedAt,
+	}
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// defaultOpsStatDays is how many days of daily stats are returned unless
+// asked for another number
+const defaultOpsStatDays = 30
+
+// OpsStatsQuery selects how many days of daily stats to return, today
+// included
+type OpsStatsQuery struct {
+	Days int `form:"days" binding:"omitempty,min=1,max=90"`
+}
+
+// DailyOpsStatResponse is one UTC day of activity and HTTP traffic.
+// ErrorRate is the share of requests answered with a 5xx status.
+type DailyOpsStatResponse struct {
+	Day               string  `json:"day"`
+	Signups           int32   `json:"signups"`
+	ActiveUsers       int32   `json:"active_users"`
+	WeeklyActiveUsers int32   `json:"weekly_active_users"`
+	BrewsLogged       int32   `json:"brews_logged"`
+	Requests          int64   `json:"requests"`
+	ClientErrors      int64   `json:"client_errors"`
+	ServerErrors      int64   `json:"server_errors"`
+	ErrorRate         float64 `json:"error_rate"`
+}
+
+type MethodOpsStatResponse struct {
+	BrewMethod string `json:"brew_method"`
+	BrewCount  int32  `json:"brew_count"`
+}
+
+// QueueOpsStatResponse is a background queue's backlog when last sampled
+type QueueOpsStatResponse struct {
+	Queue     string     `json:"queue"`
+	Depth     int64      `json:"depth"`
+	Failing   int64      `json:"failing"`
+	OldestAt  *time.Time `json:"oldest_at"`
+	SampledAt *time.Time `json:"sampled_at"`
+}
+
+// OpsStatsResponse is the admin dashboard. DAU and WAU are today's;
+// ComputedAt is when the daily stats were last aggregated.
+type OpsStatsResponse struct {
+	ComputedAt *time.Time              `json:"computed_at"`
+	DAU        int32                   `json:"dau"`
+	WAU        int32                   `json:"wau"`
+	Daily      []DailyOpsStatResponse  `json:"daily"`
+	TopMethods []MethodOpsStatResponse `json:"top_methods"`
+	Queues     []QueueOpsStatResponse  `json:"queues"`
+}
+
+// AdminGetStats returns the admin dashboard's stats, as last aggregated by
+// the ops stats job
+func AdminGetStats(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query OpsStatsQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		days := query.Days
+		if days == 0 {
+			days = defaultOpsStatDays
+		}
+
+		ctx := c.Request.Context()
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		daily, err := queries.ListDailyStats(ctx, pgtype.Date{Time: today.AddDate(0, 0, 1-days), Valid: true})
+		if err != nil {
+			logger.Error("Failed to list daily ops stats", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeOpsStatsFetchFailed)
+			return
+		}
+		methods, err := queries.ListMethodStats(ctx)
+		if err != nil {
+			logger.Error("Failed to list brew method stats", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeOpsStatsFetchFailed)
+			return
+		}
+		queues, err := queries.ListQueueStats(ctx)
+		if err != nil {
+			logger.Error("Failed to list queue stats", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeOpsStatsFetchFailed)
+			return
+		}
+
+		response := OpsStatsResponse{
+			Daily:      make([]DailyOpsStatResponse, 0, len(daily)),
+			TopMethods: make([]MethodOpsStatResponse, 0, len(methods)),
+			Queues:     make([]QueueOpsStatResponse, 0, len(queues)),
+		}
+		// Days are newest first
+		if len(daily) > 0 {
+			response.ComputedAt = timePtr(daily[0].ComputedAt)
+			response.DAU = daily[0].ActiveUsers
+			response.WAU = daily[0].WeeklyActiveUsers
+		}
+		for _, d := range daily {
+			var errorRate float64
+			if d.Requests > 0 {
+				errorRate = float64(d.ServerErrors) / float64(d.Requests)
+			}
+			response.Daily = append(response.Daily, DailyOpsStatResponse{
+				Day:               d.Day.Time.Format(time.DateOnly),
+				Signups:           d.Signups,
+				ActiveUsers:       d.ActiveUsers,
+				WeeklyActiveUsers: d.WeeklyActiveUsers,
+				BrewsLogged:       d.BrewsLogged,
+				Requests:          d.Requests,
+				ClientErrors:      d.ClientErrors,
+				ServerErrors:      d.ServerErrors,
+				ErrorRate:         errorRate,
+			})
+		}
+		for _, m := range methods {
+			response.TopMethods = append(response.TopMethods, MethodOpsStatResponse{
+				BrewMethod: m.BrewMethod,
+				BrewCount:  m.BrewCount,
+			})
+		}
+		for _, q := range queues {
+			response.Queues = append(response.Queues, QueueOpsStatResponse{
+				Queue:     q.Queue,
+				Depth:     q.Depth,
+				Failing:   q.Failing,
+				OldestAt:  timePtr(q.OldestAt),
+				SampledAt: timePtr(q.SampledAt),
+			})
+		}
+
+		respond.OK(c, response)
+	}
+}
+
+// IndexStatResponse is one of a table's indexes. Scans counts the scans
+// that used it since Postgres statistics were last reset.
+type IndexStatResponse struct {
+	Name  string `json:"name"`
+	Bytes int64  `json:"bytes"`
+	Scans int64  `json:"scans"`
+}
+
+// TableStatResponse is a table's size and growth, from Postgres's catalog
+// and planner statistics. PartitionOf names the parent of a partition.
+// BloatBytes estimates the heap's space beyond what its rows need, and
+// BloatRatio its share of the heap.
+type TableStatResponse struct {
+	Table         string              `json:"table"`
+	PartitionOf   *string             `json:"partition_of"`
+	EstimatedRows int64               `json:"estimated_rows"`
+	LiveRows      int64               `json:"live_rows"`
+	DeadRows      int64               `json:"dead_rows"`
+	TableBytes    int64               `json:"table_bytes"`
+	IndexBytes    int64               `json:"index_bytes"`
+	TotalBytes    int64               `json:"total_bytes"`
+	BloatBytes    int64               `json:"bloat_bytes"`
+	BloatRatio    float64             `json:"bloat_ratio"`
+	LastVacuumAt  *time.Time          `json:"last_vacuum_at"`
+	LastAnalyzeAt *time.Time          `json:"last_analyze_at"`
+	Indexes       []IndexStatResponse `json:"indexes"`
+}
+
+// TableStatsResponse is the admin table report, largest tables first
+type TableStatsResponse struct {
+	TotalBytes int64               `json:"total_bytes"`
+	Tables     []TableStatResponse `json:"tables"`
+}
+
+// AdminGetTableStats reports each table's row counts, sizes, indexes and
+// estimated bloat, read live from the catalog
+func AdminGetTableStats(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		tables, err := queries.ListTableStats(ctx)
+		if err != nil {
+			logger.Error("Failed to list table stats", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeOpsStatsFetchFailed)
+			return
+		}
+		indexes, err := queries.ListIndexStats(ctx)
+		if err != nil {
+			logger.Error("Failed to list index stats", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeOpsStatsFetchFailed)
+			return
+		}
+
+		byTable := make(map[string][]IndexStatResponse)
+		for _, idx := range indexes {
+			byTable[idx.TableName] = append(byTable[idx.TableName], IndexStatResponse{
+				Name:  idx.IndexName,
+				Bytes: idx.IndexBytes,
+				Scans: idx.Scans,
+			})
+		}
+
+		response := TableStatsResponse{Tables: make([]TableStatResponse, 0, len(tables))}
+		for _, t := range tables {
+			var bloatRatio float64
+			if t.TableBytes > 0 {
+				bloatRatio = min(float64(t.BloatBytes)/float64(t.TableBytes), 1)
+			}
+			tableIndexes := byTable[t.TableName]
+			if tableIndexes == nil {
+				tableIndexes = []IndexStatResponse{}
+			}
+			response.TotalBytes += t.TotalBytes
+			response.Tables = append(response.Tables, TableStatResponse{
+				Table:         t.TableName,
+				PartitionOf:   t.PartitionOf,
+				EstimatedRows: t.EstimatedRows,
+				LiveRows:      t.LiveRows,
+				DeadRows:      t.DeadRows,
+				TableBytes:    t.TableBytes,
+				IndexBytes:    t.IndexBytes,
+				TotalBytes:    t.TotalBytes,
+				BloatBytes:    t.BloatBytes,
+				BloatRatio:    bloatRatio,
+				LastVacuumAt:  latest(t.LastVacuum, t.LastAutovacuum),
+				LastAnalyzeAt: latest(t.LastAnalyze, t.LastAutoanalyze),
+				Indexes:       tableIndexes,
+			})
+		}
+
+		respond.OK(c, response)
+	}
+}
+
+// latest returns the later of two optional times, manual and automatic runs
+// of the same maintenance
+func latest(a, b pgtype.Timestamptz) *time.Time {
+	if !b.Valid || (a.Valid && a.Time.After(b.Time)) {
+		return timePtr(a)
+	}
+	return timePtr(b)
+}
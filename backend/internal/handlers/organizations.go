@@ -0,0 +1,551 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"net/http"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/fieldset"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+	"brewd/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/oklog/ulid/v2"
+)
+
+// OrganizationRequest represents the organization creation payload
+type OrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+	Kind string `json:"kind" binding:"required,oneof=cafe roastery training_program"`
+}
+
+// UpdateOrganizationRequest represents the organization update payload;
+// omitted fields are left unchanged
+type UpdateOrganizationRequest struct {
+	Name *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Kind *string `json:"kind" binding:"omitempty,oneof=cafe roastery training_program"`
+}
+
+// AddOrganizationMemberRequest adds a user to an organization by username
+type AddOrganizationMemberRequest struct {
+	Username string `json:"username" binding:"required"`
+	Role     string `json:"role" binding:"required,oneof=owner admin member"`
+}
+
+// UpdateOrganizationMemberRequest represents the member role change payload
+type UpdateOrganizationMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=owner admin member"`
+}
+
+// ListOrganizationBrewsQuery represents the organization brew log query
+// parameters
+type ListOrganizationBrewsQuery struct {
+	PageQuery
+	FieldsQuery
+}
+
+// OrganizationResponse is an organization as seen by one of its members
+type OrganizationResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Kind        string    `json:"kind"`
+	MemberCount int64     `json:"member_count"`
+	MyRole      string    `json:"my_role"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// OrganizationMemberResponse is an organization member
+type OrganizationMemberResponse struct {
+	UserID    string    `json:"user_id"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateOrganization creates an organization owned by the current user
+func CreateOrganization(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req OrganizationRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		org, err := queries.CreateOrganization(c.Request.Context(), db.CreateOrganizationParams{
+			ID:      ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+			Name:    req.Name,
+			Kind:    req.Kind,
+			OwnerID: c.GetString("user_id"),
+		})
+		if err != nil {
+			logger.Error("Failed to create organization", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeOrganizationUpdateFailed)
+			return
+		}
+
+		logger.Info("Organization created", "organization_id", org.ID)
+
+		respond.Created(c, newOrganizationResponse(org, 1, tenant.RoleOwner))
+	}
+}
+
+// ListMyOrganizations returns the organizations the current user belongs
+// to, with their role
+func ListMyOrganizations(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rows, err := queries.ListUserOrganizations(c.Request.Context(), c.GetString("user_id"))
+		if err != nil {
+			logger.Error("Failed to list user organizations", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeOrganizationFetchFailed)
+			return
+		}
+
+		orgs := make([]OrganizationResponse, 0, len(rows))
+		for _, row := range rows {
+			orgs = append(orgs, OrganizationResponse{
+				ID:          row.ID,
+				Name:        row.Name,
+				Kind:        row.Kind,
+				MemberCount: row.MemberCount,
+				MyRole:      row.Role,
+				CreatedAt:   row.CreatedAt,
+			})
+		}
+
+		respond.OK(c, orgs)
+	}
+}
+
+// GetOrganization returns the tenant organization
+func GetOrganization(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		t, ok := requireTenant(c)
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+		org, err := queries.GetOrganization(ctx, t.OrganizationID)
+		var count int64
+		if err == nil {
+			count, err = queries.CountOrganizationMembers(ctx, t.OrganizationID)
+		}
+		if err != nil {
+			logger.Error("Failed to get organization", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeOrganizationFetchFailed)
+			return
+		}
+
+		respond.OK(c, newOrganizationResponse(org, count, t.Role))
+	}
+}
+
+// UpdateOrganization edits the tenant organization; owners and admins only
+func UpdateOrganization(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req UpdateOrganizationRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		t, ok := requireTenant(c, tenant.RoleOwner, tenant.RoleAdmin)
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+		org, err := queries.UpdateOrganization(ctx, db.UpdateOrganizationParams{
+			Name: req.Name,
+			Kind: req.Kind,
+			ID:   t.OrganizationID,
+		})
+		var count int64
+		if err == nil {
+			count, err = queries.CountOrganizationMembers(ctx, t.OrganizationID)
+		}
+		if err != nil {
+			logger.Error("Failed to update organization", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeOrganizationUpdateFailed)
+			return
+		}
+
+		respond.OK(c, newOrganizationResponse(org, count, t.Role))
+	}
+}
+
+// DeleteOrganization deletes the tenant organization with its members and
+// brews; owners only
+func DeleteOrganization(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		t, ok := requireTenant(c, tenant.RoleOwner)
+		if !ok {
+			return
+		}
+
+		if err := queries.DeleteOrganization(c.Request.Context(), t.OrganizationID); err != nil {
+			logger.Error("Failed to delete organization", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeOrganizationUpdateFailed)
+			return
+		}
+
+		logger.Info("Organization deleted", "organization_id", t.OrganizationID)
+
+		respond.OK(c, nil)
+	}
+}
+
+// ListOrganizationMembers returns the tenant organization's members, owners
+// and admins first
+func ListOrganizationMembers(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		t, ok := requireTenant(c)
+		if !ok {
+			return
+		}
+
+		rows, err := queries.ListOrganizationMembers(c.Request.Context(), t.OrganizationID)
+		if err != nil {
+			logger.Error("Failed to list organization members", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeOrganizationFetchFailed)
+			return
+		}
+
+		members := make([]OrganizationMemberResponse, 0, len(rows))
+		for _, row := range rows {
+			members = append(members, OrganizationMemberResponse(row))
+		}
+
+		respond.OK(c, members)
+	}
+}
+
+// AddOrganizationMember adds a user to the tenant organization. Admins add
+// members; owners add anyone.
+func AddOrganizationMember(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req AddOrganizationMemberRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		t, ok := requireTenant(c, tenant.RoleOwner, tenant.RoleAdmin)
+		if !ok {
+			return
+		}
+		if !tenant.CanGrant(t.Role, req.Role) {
+			respond.Error(c, http.StatusForbidden, i18n.CodeForbidden)
+			return
+		}
+
+		ctx := c.Request.Context()
+		user, err := queries.GetUserByUsername(ctx, req.Username)
+		if err == pgx.ErrNoRows {
+			respond.Error(c, http.StatusNotFound, i18n.CodeUserNotFound)
+			return
+		}
+		var member db.OrganizationMember
+		if err == nil {
+			member, err = queries.AddOrganizationMember(ctx, db.AddOrganizationMemberParams{
+				OrganizationID: t.OrganizationID,
+				UserID:         user.ID,
+				Role:           req.Role,
+			})
+		}
+		if err == pgx.ErrNoRows {
+			respond.Error(c, http.StatusConflict, i18n.CodeOrganizationMemberExists)
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to add organization member", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeOrganizationUpdateFailed)
+			return
+		}
+
+		respond.Created(c, OrganizationMemberResponse{
+			UserID:    member.UserID,
+			Username:  user.Username,
+			Role:      member.Role,
+			CreatedAt: member.CreatedAt,
+		})
+	}
+}
+
+// UpdateOrganizationMemberRole changes a member's role in the tenant
+// organization. Owners change anyone's role; admins can't grant or revoke
+// admin. The last owner can't be demoted.
+func UpdateOrganizationMemberRole(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req UpdateOrganizationMemberRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		t, ok := requireTenant(c, tenant.RoleOwner, tenant.RoleAdmin)
+		if !ok {
+			return
+		}
+
+		targetID := c.Param("user_id")
+		targetRole, ok := organizationMemberRole(c, queries, t, targetID)
+		if !ok {
+			return
+		}
+		if !tenant.CanGrant(t.Role, req.Role) || !tenant.CanRemove(t.Role, targetRole) {
+			respond.Error(c, http.StatusForbidden, i18n.CodeForbidden)
+			return
+		}
+		if targetRole == tenant.RoleOwner && req.Role != tenant.RoleOwner && !keepsOwner(c, queries, t) {
+			return
+		}
+
+		member, err := queries.UpdateOrganizationMemberRole(c.Request.Context(), db.UpdateOrganizationMemberRoleParams{
+			OrganizationID: t.OrganizationID,
+			UserID:         targetID,
+			Role:           req.Role,
+		})
+		if err == pgx.ErrNoRows {
+			respond.Error(c, http.StatusNotFound, i18n.CodeOrganizationMemberNotFound)
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to update organization member role", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeOrganizationUpdateFailed)
+			return
+		}
+
+		respond.OK(c, gin.H{
+			"user_id": member.UserID,
+			"role":    member.Role,
+		})
+	}
+}
+
+// RemoveOrganizationMember removes a member from the tenant organization.
+// Members can leave; owners remove anyone and admins remove members. The
+// last owner can't leave or be removed. Brews they logged stay with the
+// organization.
+func RemoveOrganizationMember(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		t, ok := requireTenant(c)
+		if !ok {
+			return
+		}
+
+		targetID := c.Param("user_id")
+		targetRole, ok := organizationMemberRole(c, queries, t, targetID)
+		if !ok {
+			return
+		}
+		if targetID != c.GetString("user_id") && !tenant.CanRemove(t.Role, targetRole) {
+			respond.Error(c, http.StatusForbidden, i18n.CodeForbidden)
+			return
+		}
+		if targetRole == tenant.RoleOwner && !keepsOwner(c, queries, t) {
+			return
+		}
+
+		removed, err := queries.RemoveOrganizationMember(c.Request.Context(), db.RemoveOrganizationMemberParams{
+			OrganizationID: t.OrganizationID,
+			UserID:         targetID,
+		})
+		if err != nil {
+			logger.Error("Failed to remove organization member", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeOrganizationUpdateFailed)
+			return
+		}
+		if removed == 0 {
+			respond.Error(c, http.StatusNotFound, i18n.CodeOrganizationMemberNotFound)
+			return
+		}
+
+		respond.OK(c, nil)
+	}
+}
+
+// CreateOrganizationBrew logs a brew in the tenant organization. It takes
+// the Log Brew payload; organization brews are never public.
+func CreateOrganizationBrew(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req BrewRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		t, ok := requireTenant(c)
+		if !ok {
+			return
+		}
+
+		params, pref, ok := prepareBrew(c, queries, req)
+		if !ok {
+			return
+		}
+		isPublic := false
+		params.ID = ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String()
+		params.IsPublic = &isPublic
+		params.OrganizationID = &t.OrganizationID
+
+		brew, pref, ok := insertBrew(c, queries, params, pref)
+		if !ok {
+			return
+		}
+
+		respond.Created(c, newBrewResponse(brew, pref))
+	}
+}
+
+// ListOrganizationBrews returns the brews logged in the tenant
+// organization, newest first
+func ListOrganizationBrews(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query ListOrganizationBrewsQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		page := query.PageQuery
+		if page.Limit == 0 {
+			page.Limit = defaultPageLimit
+		}
+		fields, ok := parseFields(c, query.Fields, brewFields)
+		if !ok {
+			return
+		}
+
+		t, ok := requireTenant(c)
+		if !ok {
+			return
+		}
+
+		pref, ok := requestUnits(c, queries)
+		if !ok {
+			return
+		}
+
+		brews, err := queries.ListOrganizationBrews(c.Request.Context(), db.ListOrganizationBrewsParams{
+			OrganizationID: &t.OrganizationID,
+			Limit:          page.Limit,
+			Offset:         page.Offset,
+		})
+		if err != nil {
+			logger.Error("Failed to list organization brews", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewFetchFailed)
+			return
+		}
+
+		items := make([]BrewResponse, 0, len(brews))
+		for _, brew := range brews {
+			items = append(items, newBrewResponse(brew, pref))
+		}
+
+		respond.Page(c, fieldset.Select(items, fields), page.Limit, page.Offset, len(items))
+	}
+}
+
+// GetOrganizationBrew returns a brew logged in the tenant organization
+func GetOrganizationBrew(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		t, ok := requireTenant(c)
+		if !ok {
+			return
+		}
+
+		brew, err := queries.GetOrganizationBrew(c.Request.Context(), db.GetOrganizationBrewParams{
+			ID:             c.Param("id"),
+			OrganizationID: &t.OrganizationID,
+		})
+		if err == pgx.ErrNoRows {
+			respond.Error(c, http.StatusNotFound, i18n.CodeBrewNotFound)
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to get organization brew", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewFetchFailed)
+			return
+		}
+
+		pref, ok := requestUnits(c, queries)
+		if !ok {
+			return
+		}
+
+		respond.OK(c, newBrewResponse(brew, pref))
+	}
+}
+
+// requireTenant returns the organization RequireOrganization resolved for
+// the request, writing an error response otherwise. Requests without one
+// are reported as missing rather than reaching another organization. When
+// roles are given, members without one of them get 403.
+func requireTenant(c *gin.Context, roles ...string) (tenant.Tenant, bool) {
+	t, ok := tenant.FromContext(c.Request.Context())
+	if !ok {
+		respond.Error(c, http.StatusNotFound, i18n.CodeOrganizationNotFound)
+		return t, false
+	}
+	if len(roles) == 0 {
+		return t, true
+	}
+
+	for _, allowed := range roles {
+		if t.Role == allowed {
+			return t, true
+		}
+	}
+	respond.Error(c, http.StatusForbidden, i18n.CodeForbidden)
+	return t, false
+}
+
+// organizationMemberRole returns userID's role in the tenant organization,
+// writing an error response when they aren't a member
+func organizationMemberRole(c *gin.Context, queries *db.Queries, t tenant.Tenant, userID string) (string, bool) {
+	role, err := queries.GetOrganizationMemberRole(c.Request.Context(), db.GetOrganizationMemberRoleParams{
+		OrganizationID: t.OrganizationID,
+		UserID:         userID,
+	})
+	if err == pgx.ErrNoRows {
+		respond.Error(c, http.StatusNotFound, i18n.CodeOrganizationMemberNotFound)
+		return "", false
+	}
+	if err != nil {
+		logger.Error("Failed to get organization member role", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeOrganizationUpdateFailed)
+		return "", false
+	}
+	return role, true
+}
+
+// keepsOwner reports whether the tenant organization has another owner, so
+// one can step down, writing an error response otherwise
+func keepsOwner(c *gin.Context, queries *db.Queries, t tenant.Tenant) bool {
+	owners, err := queries.CountOrganizationOwners(c.Request.Context(), t.OrganizationID)
+	if err != nil {
+		logger.Error("Failed to count organization owners", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeOrganizationUpdateFailed)
+		return false
+	}
+	if owners <= 1 {
+		respond.Error(c, http.StatusBadRequest, i18n.CodeOrganizationLastOwner)
+		return false
+	}
+	return true
+}
+
+func newOrganizationResponse(org db.Organization, memberCount int64, role string) OrganizationResponse {
+	return OrganizationResponse{
+		ID:          org.ID,
+		Name:        org.Name,
+		Kind:        org.Kind,
+		MemberCount: memberCount,
+		MyRole:      role,
+		CreatedAt:   org.CreatedAt,
+	}
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"net/http"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/origins"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Origin is the structured origin of a bean bag or catalogue bean. Country
+// (ISO 3166-1 alpha-2), variety and process take terms from the vocabularies
+// at GET /origins. In updates, omitted fields are left unchanged.
+type Origin struct {
+	OriginCountry *string `json:"origin_country" binding:"omitempty,len=2"`
+	OriginRegion  *string `json:"origin_region" binding:"omitempty,max=100"`
+	Farm          *string `json:"farm" binding:"omitempty,max=255"`
+	Variety       *string `json:"variety" binding:"omitempty,max=50"`
+	AltitudeM     *int32  `json:"altitude_m" binding:"omitempty,min=0,max=6000"`
+	Process       *string `json:"process" binding:"omitempty,max=30"`
+}
+
+// OriginQuery represents the origin filters on bean lists
+type OriginQuery struct {
+	Country string `form:"country" binding:"omitempty,len=2"`
+	Process string `form:"process" binding:"max=30"`
+	Variety string `form:"variety" binding:"max=50"`
+}
+
+// OriginStatsQuery represents the origin stats query parameters
+type OriginStatsQuery struct {
+	By string `form:"by" binding:"omitempty,oneof=country region variety process"`
+}
+
+// OriginRatingResponse is the current user's brews and ratings for one
+// origin value. Name is the vocabulary's display name, unset for regions.
+type OriginRatingResponse struct {
+	Value       string   `json:"value"`
+	Name        *string  `json:"name"`
+	BrewCount   int64    `json:"brew_count"`
+	RatingCount int64    `json:"rating_count"`
+	AvgRating   *float64 `json:"avg_rating"`
+}
+
+// ListOrigins returns the controlled vocabularies for origin country,
+// variety and process
+func ListOrigins() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respond.OK(c, gin.H{
+			"countries": origins.Countries,
+			"varieties": origins.Varieties,
+			"processes": origins.Processes,
+		})
+	}
+}
+
+// GetMyOriginStats groups the current user's brews by an origin field of
+// their bean bag (?by=country, region, variety or process, the default) and
+// averages the ratings the user gave them in posts. Brews without a bag, or
+// whose bag doesn't set the field, are left out.
+func GetMyOriginStats(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query OriginStatsQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if query.By == "" {
+			query.By = "process"
+		}
+
+		userID := c.GetString("user_id")
+		rows, err := queries.GetUserOriginRatings(c.Request.Context(), db.GetUserOriginRatingsParams{
+			GroupBy: query.By,
+			UserID:  &userID,
+		})
+		if err != nil {
+			logger.Error("Failed to get origin ratings", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeStatsFetchFailed)
+			return
+		}
+
+		var vocabulary origins.Vocabulary
+		switch query.By {
+		case "country":
+			vocabulary = origins.Countries
+		case "variety":
+			vocabulary = origins.Varieties
+		case "process":
+			vocabulary = origins.Processes
+		}
+
+		stats := make([]OriginRatingResponse, 0, len(rows))
+		for _, row := range rows {
+			item := OriginRatingResponse{
+				Value:       row.Value,
+				BrewCount:   row.BrewCount,
+				RatingCount: row.RatingCount,
+				AvgRating:   row.AvgRating,
+			}
+			if term, ok := vocabulary.Lookup(row.Value); ok {
+				item.Name = &term.Name
+			}
+			stats = append(stats, item)
+		}
+
+		respond.OK(c, gin.H{
+			"by":     query.By,
+			"groups": stats,
+		})
+	}
+}
+
+// checkOrigin upper-cases origin's country code and checks its country,
+// variety and process against the vocabularies, writing an error response
+// if one isn't recognised
+func checkOrigin(c *gin.Context, origin *Origin) bool {
+	valid := true
+	if origin.OriginCountry != nil {
+		code, ok := origins.NormalizeCountry(*origin.OriginCountry)
+		origin.OriginCountry = &code
+		valid = ok
+	}
+	if origin.Variety != nil && !origins.Varieties.Contains(*origin.Variety) {
+		valid = false
+	}
+	if origin.Process != nil && !origins.Processes.Contains(*origin.Process) {
+		valid = false
+	}
+	if !valid {
+		respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidOrigin)
+	}
+	return valid
+}
+
+// originFilter converts an origin query to nullable filter values, "" for
+// no filter
+func originFilter(query OriginQuery) (country, process, variety *string) {
+	if query.Country != "" {
+		code, _ := origins.NormalizeCountry(query.Country)
+		country = &code
+	}
+	if query.Process != "" {
+		process = &query.Process
+	}
+	if query.Variety != "" {
+		variety = &query.Variety
+	}
+	return country, process, variety
+}
+
+func newOrigin(country, region, farm, variety *string, altitudeM *int32, process *string) Origin {
+	return Origin{
+		OriginCountry: country,
+		OriginRegion:  region,
+		Farm:          farm,
+		Variety:       variety,
+		AltitudeM:     altitudeM,
+		Process:       process,
+	}
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"brewd/internal/agegate"
+	"brewd/internal/auth"
+	"brewd/internal/db"
+	"brewd/internal/geoip"
+	"brewd/internal/i18n"
+	"brewd/internal/links"
+	"brewd/internal/logger"
+	"brewd/internal/mail"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ParentalConsentResponse describes a consent request to the parent
+// answering it
+type ParentalConsentResponse struct {
+	Username  string     `json:"username"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// GetParentalConsent shows a parent which account a consent link is for
+func GetParentalConsent(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		consent, err := queries.GetParentalConsent(c.Request.Context(), agegate.Hash(c.Param("token")))
+		if errors.Is(err, pgx.ErrNoRows) {
+			respond.Error(c, http.StatusNotFound, i18n.CodeParentalConsentInvalid)
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to get parental consent", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeParentalConsentFailed)
+			return
+		}
+
+		respond.OK(c, ParentalConsentResponse{
+			Username:  consent.Username,
+			ExpiresAt: timePtr(consent.ExpiresAt),
+		})
+	}
+}
+
+// GrantParentalConsent records a parent's consent from the request's IP,
+// unblocking their child's account
+func GrantParentalConsent(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := c.ClientIP()
+		user, err := queries.GrantParentalConsent(c.Request.Context(), db.GrantParentalConsentParams{
+			GrantedIp: &ip,
+			TokenHash: agegate.Hash(c.Param("token")),
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			respond.Error(c, http.StatusNotFound, i18n.CodeParentalConsentInvalid)
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to grant parental consent", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeParentalConsentFailed)
+			return
+		}
+
+		logger.Info("Parental consent granted", "user_id", user.ID)
+
+		respond.OK(c, ParentalConsentResponse{Username: user.Username})
+	}
+}
+
+// checkAgeGate checks the registering user's birth date against gate, for
+// the country locator places the request in. It returns the birth date to
+// store and, for a user under the minimum age, their parent's email. On
+// failure it responds with the error and returns false.
+func checkAgeGate(c *gin.Context, gate agegate.Gate, locator geoip.Locator, req RegisterRequest,
+	email string) (pgtype.Date, *string, bool) {
+	if req.BirthDate == "" {
+		if gate.Enabled() {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeBirthDateRequired)
+			return pgtype.Date{}, nil, false
+		}
+		return pgtype.Date{}, nil, true
+	}
+
+	now := time.Now().UTC()
+	birthDate, ok := agegate.ParseBirthDate(req.BirthDate, now)
+	if !ok {
+		respond.Error(c, http.StatusBadRequest, i18n.CodeBirthDateInvalid)
+		return pgtype.Date{}, nil, false
+	}
+	date := pgtype.Date{Time: birthDate, Valid: true}
+	if !gate.Enabled() {
+		return date, nil, true
+	}
+
+	loc, _ := locator.Locate(c.ClientIP())
+	if agegate.Age(birthDate, now) >= gate.MinimumAge(loc.Country) {
+		return date, nil, true
+	}
+
+	// Under the minimum age: only with a parent's consent, where allowed
+	if !gate.ParentalConsent {
+		respond.Error(c, http.StatusForbidden, i18n.CodeAgeRequirementNotMet)
+		return pgtype.Date{}, nil, false
+	}
+	if req.ParentEmail == "" {
+		respond.Error(c, http.StatusForbidden, i18n.CodeParentalConsentRequired)
+		return pgtype.Date{}, nil, false
+	}
+	parentEmail, err := auth.NormalizeEmail(req.ParentEmail)
+	if err != nil || parentEmail == email {
+		respond.Error(c, http.StatusBadRequest, i18n.CodeParentEmailInvalid)
+		return pgtype.Date{}, nil, false
+	}
+	return date, &parentEmail, true
+}
+
+// requestParentalConsent emails parentEmail the consent request for user.
+// If it can't be sent, the user is asked to log in again once it expires,
+// which sends a new one.
+func requestParentalConsent(c *gin.Context, mailer *mail.Mailer, site *links.Site, parentEmail string,
+	user db.CreateUserRow, token string) {
+	msg := agegate.ConsentRequest(site, parentEmail, user.Username, token)
+	if err := mailer.Send(c.Request.Context(), msg); err != nil {
+		logger.Error("Failed to send parental consent request", "user_id", user.ID, "error", err)
+	}
+}
+
+// renewParentalConsent asks the parent of a user awaiting consent again
+// with a new token, if their request expired. Failures are only logged, as
+// the user is refused either way.
+func renewParentalConsent(c *gin.Context, queries *db.Queries, mailer *mail.Mailer, site *links.Site,
+	userID, username string) {
+	token, hash, err := agegate.NewToken()
+	if err != nil {
+		logger.Error("Failed to generate parental consent token", "error", err)
+		return
+	}
+
+	parentEmail, err := queries.RenewParentalConsent(c.Request.Context(), db.RenewParentalConsentParams{
+		TokenHash:  hash,
+		TtlSeconds: int32(agegate.ConsentTTL.Seconds()),
+		UserID:     userID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// The request is still pending
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to renew parental consent", "user_id", userID, "error", err)
+		return
+	}
+
+	msg := agegate.ConsentRequest(site, parentEmail, username, token)
+	if err := mailer.Send(c.Request.Context(), msg); err != nil {
+		logger.Error("Failed to send parental consent request", "user_id", userID, "error", err)
+	}
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"brewd/internal/i18n"
+	"brewd/internal/mergepatch"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// mergePatchContentType is the media type of a JSON Merge Patch (RFC 7386)
+const mergePatchContentType = "application/merge-patch+json"
+
+// bindMergePatch applies the request body, a JSON Merge Patch, to doc (the
+// resource as the caller sees it) and binds the result into req, validated
+// like a full payload. It returns the top-level fields the patch touched,
+// and writes an error response when the patch or its result is rejected.
+func bindMergePatch(c *gin.Context, doc, req any) (map[string]bool, bool) {
+	if ct := c.ContentType(); ct != mergePatchContentType && ct != binding.MIMEJSON {
+		respond.Error(c, http.StatusUnsupportedMediaType, i18n.CodeUnsupportedMediaType)
+		return nil, false
+	}
+
+	patch, err := c.GetRawData()
+	var fields map[string]json.RawMessage
+	if err == nil {
+		err = json.Unmarshal(patch, &fields)
+	}
+	if err != nil || fields == nil {
+		respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidRequest)
+		return nil, false
+	}
+
+	// The document is built from stored values, so it always encodes
+	current, _ := json.Marshal(doc)
+	merged, err := mergepatch.Apply(current, patch)
+	if err == nil {
+		err = json.Unmarshal(merged, req)
+	}
+	if err == nil {
+		err = binding.Validator.ValidateStruct(req)
+	}
+	if err != nil {
+		respond.Invalid(c, err)
+		return nil, false
+	}
+
+	touched := make(map[string]bool, len(fields))
+	for field := range fields {
+		touched[field] = true
+	}
+	return touched, true
+}
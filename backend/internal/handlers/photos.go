@@ -0,0 +1,388 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/media"
+	"brewd/internal/objectstore"
+	"brewd/internal/photos"
+	"brewd/internal/plans"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/oklog/ulid/v2"
+)
+
+// photoFormOverhead is how much a multipart upload may exceed the photo
+// size limit by, for its boundaries and part headers
+const photoFormOverhead = 64 << 10
+
+// photoCacheControl lets clients keep a photo's renditions; a photo's
+// renditions never change once it is ready
+const photoCacheControl = "private, max-age=86400"
+
+// BrewPhotoResponse is a brew photo and how far its processing has got.
+// Width, height and the renditions are only available once it is ready;
+// Error says why a failed photo couldn't be processed. The rendition URLs
+// are signed and stop working at URLsExpireAt.
+type BrewPhotoResponse struct {
+	ID           string     `json:"id"`
+	BrewID       string     `json:"brew_id"`
+	Status       string     `json:"status"`
+	ContentType  string     `json:"content_type"`
+	Width        *int32     `json:"width"`
+	Height       *int32     `json:"height"`
+	ImageURL     *string    `json:"image_url"`
+	ThumbnailURL *string    `json:"thumbnail_url"`
+	URLsExpireAt *time.Time `json:"urls_expire_at"`
+	Error        *string    `json:"error,omitempty"`
+	ProcessedAt  *time.Time `json:"processed_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// UploadBrewPhoto attaches a photo to a brew the current user owns. The
+// multipart field photo holds a JPEG, PNG, GIF or WebP image, recognised
+// from its bytes, and must fit in what remains of the user's storage quota.
+// The original is stored and the photo returned pending; the photo
+// processor renders it shortly after.
+func UploadBrewPhoto(queries *db.Queries, store objectstore.Store, tiers *plans.Cache, maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if store == nil {
+			respond.Error(c, http.StatusServiceUnavailable, i18n.CodePhotosUnavailable)
+			return
+		}
+		brew, ok := loadBrew(c, queries)
+		if !ok {
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+photoFormOverhead)
+		header, err := c.FormFile("photo")
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				respond.Error(c, http.StatusRequestEntityTooLarge, i18n.CodePhotoTooLarge)
+				return
+			}
+			respond.Error(c, http.StatusBadRequest, i18n.CodePhotoRequired)
+			return
+		}
+		if header.Size > maxBytes {
+			respond.Error(c, http.StatusRequestEntityTooLarge, i18n.CodePhotoTooLarge)
+			return
+		}
+		// Renditions count too once processed, but are far smaller than
+		// the original; the quota is checked against what is uploaded. The
+		// bytes are reserved until the photo's row counts them.
+		release, ok := reserveStorage(c, queries, tiers, c.GetString("user_id"), header.Size)
+		if !ok {
+			return
+		}
+		defer release()
+		file, err := header.Open()
+		if err != nil {
+			logger.Error("Failed to open uploaded photo", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodePhotoUploadFailed)
+			return
+		}
+		defer file.Close()
+
+		head := make([]byte, 512)
+		n, err := io.ReadFull(file, head)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			logger.Error("Failed to read uploaded photo", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodePhotoUploadFailed)
+			return
+		}
+		contentType, ok := photos.Sniff(head[:n])
+		if !ok {
+			respond.Error(c, http.StatusUnsupportedMediaType, i18n.CodePhotoTypeUnsupported)
+			return
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			logger.Error("Failed to read uploaded photo", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodePhotoUploadFailed)
+			return
+		}
+
+		ctx := c.Request.Context()
+		id := ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String()
+		originalKey := photos.OriginalKey(id)
+		if err := store.Put(ctx, originalKey, file, header.Size, contentType); err != nil {
+			logger.Error("Failed to store uploaded photo", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodePhotoUploadFailed)
+			return
+		}
+
+		photo, err := queries.CreateBrewPhoto(ctx, db.CreateBrewPhotoParams{
+			ID:            id,
+			BrewID:        brew.ID,
+			UserID:        c.GetString("user_id"),
+			ContentType:   contentType,
+			OriginalKey:   originalKey,
+			OriginalBytes: header.Size,
+		})
+		if err != nil {
+			logger.Error("Failed to create brew photo", "error", err)
+			photos.Remove(ctx, store, db.BrewPhoto{ID: id, OriginalKey: originalKey})
+			respond.Error(c, http.StatusInternalServerError, i18n.CodePhotoUploadFailed)
+			return
+		}
+
+		respond.Status(c, http.StatusAccepted, newBrewPhotoResponse(photo, nil))
+	}
+}
+
+// ListBrewPhotos lists a visible brew's photos. Its owner sees every photo
+// with its processing status; others only see ready photos.
+func ListBrewPhotos(queries *db.Queries, urls *media.URLs) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		brew, ok := loadVisibleBrew(c, queries)
+		if !ok {
+			return
+		}
+
+		rows, err := queries.ListBrewPhotos(c.Request.Context(), brew.ID)
+		if err != nil {
+			logger.Error("Failed to list brew photos", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodePhotoFetchFailed)
+			return
+		}
+
+		owner := ownsBrew(brew, c.GetString("user_id"))
+		resp := make([]BrewPhotoResponse, 0, len(rows))
+		for _, photo := range rows {
+			if owner || photo.Status == photos.StatusReady {
+				resp = append(resp, newBrewPhotoResponse(photo, urls))
+			}
+		}
+		respond.OK(c, resp)
+	}
+}
+
+// GetBrewPhoto returns a photo of a visible brew, so its owner can follow
+// its processing
+func GetBrewPhoto(queries *db.Queries, urls *media.URLs) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		brew, ok := loadVisibleBrew(c, queries)
+		if !ok {
+			return
+		}
+		photo, ok := loadBrewPhoto(c, queries, brew)
+		if !ok {
+			return
+		}
+		respond.OK(c, newBrewPhotoResponse(photo, urls))
+	}
+}
+
+// GetBrewPhotoImage streams a ready photo's full-size rendition, or its
+// thumbnail, as JPEG
+func GetBrewPhotoImage(queries *db.Queries, store objectstore.Store, thumbnail bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if store == nil {
+			respond.Error(c, http.StatusServiceUnavailable, i18n.CodePhotosUnavailable)
+			return
+		}
+		brew, ok := loadVisibleBrew(c, queries)
+		if !ok {
+			return
+		}
+		photo, ok := loadBrewPhoto(c, queries, brew)
+		if !ok {
+			return
+		}
+		key := photo.ImageKey
+		if thumbnail {
+			key = photo.ThumbnailKey
+		}
+		if photo.Status != photos.StatusReady || key == nil {
+			respond.Error(c, http.StatusConflict, i18n.CodePhotoNotReady)
+			return
+		}
+
+		body, err := store.Get(c.Request.Context(), *key)
+		if err != nil {
+			logger.Error("Failed to read brew photo", "photo_id", photo.ID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodePhotoFetchFailed)
+			return
+		}
+		defer body.Close()
+
+		size := photo.ImageBytes
+		if thumbnail {
+			size = photo.ThumbnailBytes
+		}
+		c.Header("Cache-Control", photoCacheControl)
+		c.Header("X-Content-Type-Options", "nosniff")
+		if size != nil {
+			c.Header("Content-Length", strconv.FormatInt(*size, 10))
+		}
+		c.Header("Content-Type", "image/jpeg")
+		c.Status(http.StatusOK)
+		if _, err := io.Copy(c.Writer, body); err != nil {
+			logger.Warn("Failed to stream brew photo", "photo_id", photo.ID, "error", err)
+		}
+	}
+}
+
+// DeleteBrewPhoto deletes a photo from a brew the current user owns. Its
+// stored original and renditions are swept by the photo processor.
+func DeleteBrewPhoto(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		brew, ok := loadBrew(c, queries)
+		if !ok {
+			return
+		}
+
+		_, err := queries.DeleteBrewPhoto(c.Request.Context(), db.DeleteBrewPhotoParams{
+			ID:     c.Param("photo_id"),
+			BrewID: brew.ID,
+		})
+		if err == pgx.ErrNoRows {
+			respond.Error(c, http.StatusNotFound, i18n.CodePhotoNotFound)
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to delete brew photo", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodePhotoDeleteFailed)
+			return
+		}
+
+		respond.OK(c, nil)
+	}
+}
+
+// loadBrewPhoto fetches the :photo_id photo of brew. Photos that aren't
+// ready are only shown to the brew's owner; to others they are missing.
+func loadBrewPhoto(c *gin.Context, queries *db.Queries, brew db.Brew) (db.BrewPhoto, bool) {
+	photo, err := queries.GetBrewPhoto(c.Request.Context(), db.GetBrewPhotoParams{
+		ID:     c.Param("photo_id"),
+		BrewID: brew.ID,
+	})
+	if err != nil && err != pgx.ErrNoRows {
+		logger.Error("Failed to get brew photo", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodePhotoFetchFailed)
+		return photo, false
+	}
+	if err == pgx.ErrNoRows || (photo.Status != photos.StatusReady && !ownsBrew(brew, c.GetString("user_id"))) {
+		respond.Error(c, http.StatusNotFound, i18n.CodePhotoNotFound)
+		return photo, false
+	}
+	return photo, true
+}
+
+// newBrewPhotoResponse converts a stored photo, signing its rendition URLs
+// with urls when it is ready
+func newBrewPhotoResponse(photo db.BrewPhoto, urls *media.URLs) BrewPhotoResponse {
+	resp := BrewPhotoResponse{
+		ID:          photo.ID,
+		BrewID:      photo.BrewID,
+		Status:      photo.Status,
+		ContentType: photo.ContentType,
+		Width:       photo.Width,
+		Height:      photo.Height,
+		ProcessedAt: timePtr(photo.ProcessedAt),
+		CreatedAt:   photo.CreatedAt,
+	}
+	// Errors of photos still being retried are transient
+	if photo.Status == photos.StatusFailed {
+		resp.Error = photo.Error
+	}
+	if urls != nil && photo.Status == photos.StatusReady && photo.ImageKey != nil && photo.ThumbnailKey != nil {
+		imageURL, expiresAt, imageErr := urls.Sign(*photo.ImageKey, media.Options{})
+		thumbnailURL, _, thumbnailErr := urls.Sign(*photo.ThumbnailKey, media.Options{})
+		if err := errors.Join(imageErr, thumbnailErr); err != nil {
+			logger.Error("Failed to sign brew photo URLs", "photo_id", photo.ID, "error", err)
+		} else {
+			resp.ImageURL, resp.ThumbnailURL, resp.URLsExpireAt = &imageURL, &thumbnailURL, &expiresAt
+		}
+	}
+	return resp
+}
+
+// QuarantinedPhotoResponse is a photo the upload scanner found malware in
+type QuarantinedPhotoResponse struct {
+	ID            string     `json:"id"`
+	BrewID        string     `json:"brew_id"`
+	UserID        string     `json:"user_id"`
+	Username      string     `json:"username"`
+	ContentType   string     `json:"content_type"`
+	ObjectKey     string     `json:"object_key"`
+	SizeBytes     int64      `json:"size_bytes"`
+	Threat        *string    `json:"threat"`
+	QuarantinedAt *time.Time `json:"quarantined_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// AdminListQuarantinedPhotos lists quarantined photos, most recently
+// quarantined first
+func AdminListQuarantinedPhotos(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var page PageQuery
+		if err := c.ShouldBindQuery(&page); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if page.Limit == 0 {
+			page.Limit = defaultPageLimit
+		}
+
+		rows, err := queries.ListQuarantinedBrewPhotos(c.Request.Context(), db.ListQuarantinedBrewPhotosParams{
+			RowLimit:  page.Limit,
+			RowOffset: page.Offset,
+		})
+		if err != nil {
+			logger.Error("Failed to list quarantined brew photos", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodePhotoFetchFailed)
+			return
+		}
+
+		items := make([]QuarantinedPhotoResponse, 0, len(rows))
+		for _, row := range rows {
+			items = append(items, QuarantinedPhotoResponse{
+				ID:            row.ID,
+				BrewID:        row.BrewID,
+				UserID:        row.UserID,
+				Username:      row.Username,
+				ContentType:   row.ContentType,
+				ObjectKey:     row.OriginalKey,
+				SizeBytes:     row.OriginalBytes,
+				Threat:        row.Threat,
+				QuarantinedAt: timePtr(row.ProcessedAt),
+				CreatedAt:     row.CreatedAt,
+			})
+		}
+		respond.Page(c, items, page.Limit, page.Offset, len(items))
+	}
+}
+
+// AdminDeleteQuarantinedPhoto deletes a quarantined photo; the photo
+// processor sweeps its upload. Photos that aren't quarantined are left to
+// their owners.
+func AdminDeleteQuarantinedPhoto(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		photo, err := queries.DeleteQuarantinedBrewPhoto(c.Request.Context(), c.Param("photo_id"))
+		if err == pgx.ErrNoRows {
+			respond.Error(c, http.StatusNotFound, i18n.CodePhotoNotFound)
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to delete quarantined brew photo", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodePhotoDeleteFailed)
+			return
+		}
+
+		logger.Info("Quarantined brew photo deleted", "photo_id", photo.ID, "admin_id", c.GetString("user_id"))
+		respond.OK(c, nil)
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/plans"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SubscriptionResponse is the state of a user's supporter subscription as
+// last reported by its provider
+type SubscriptionResponse struct {
+	Provider          string     `json:"provider"`
+	Status            *string    `json:"status"`
+	PeriodEnd         *time.Time `json:"period_end"`
+	CancelAtPeriodEnd bool       `json:"cancel_at_period_end"`
+}
+
+// PlanResponse is a user's plan tier, its limits and their subscription, if
+// they have had one. Current API call usage is in the response's
+// X-RateLimit-* headers.
+type PlanResponse struct {
+	Plan         string                `json:"plan"`
+	Limits       plans.Limits          `json:"limits"`
+	Subscription *SubscriptionResponse `json:"subscription"`
+}
+
+// GetMyPlan returns the current user's plan, its limits and their
+// subscription
+func GetMyPlan(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respondPlan(c, queries, c.GetString("user_id"))
+	}
+}
+
+// respondPlan responds with userID's plan, its limits and their
+// subscription
+func respondPlan(c *gin.Context, queries *db.Queries, userID string) {
+	user, err := queries.GetUserBilling(c.Request.Context(), userID)
+	if err != nil {
+		logger.Error("Failed to get user billing", "user_id", userID, "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodePlanFetchFailed)
+		return
+	}
+
+	response := PlanResponse{
+		Plan:   user.Plan,
+		Limits: plans.LimitsFor(user.Plan),
+	}
+	if user.SubscriptionProvider != nil {
+		response.Subscription = &SubscriptionResponse{
+			Provider:          *user.SubscriptionProvider,
+			Status:            user.SubscriptionStatus,
+			PeriodEnd:         timePtr(user.SubscriptionPeriodEnd),
+			CancelAtPeriodEnd: user.SubscriptionCancelAtPeriodEnd,
+		}
+	}
+
+	respond.OK(c, response)
+}
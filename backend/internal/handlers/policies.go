@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"net/http"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/policies"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oklog/ulid/v2"
+)
+
+// PolicyDocumentResponse is a version of a policy. AcceptedAt is set on a
+// user's own list once they've accepted it, and AcceptanceCount on the
+// admin list.
+type PolicyDocumentResponse struct {
+	ID              string     `json:"id"`
+	Kind            string     `json:"kind"`
+	Version         string     `json:"version"`
+	URL             string     `json:"url"`
+	Summary         *string    `json:"summary"`
+	PublishedAt     *time.Time `json:"published_at"`
+	AcceptedAt      *time.Time `json:"accepted_at,omitempty"`
+	AcceptanceCount *int64     `json:"acceptance_count,omitempty"`
+}
+
+// AcceptPoliciesRequest accepts policy documents by ID
+type AcceptPoliciesRequest struct {
+	DocumentIDs []string `json:"document_ids" binding:"required,min=1,max=10,dive,required"`
+}
+
+// PublishPolicyRequest publishes a new version of a policy. Without
+// PublishedAt it takes effect immediately; a future time schedules it.
+type PublishPolicyRequest struct {
+	Kind        string     `json:"kind" binding:"required,oneof=terms privacy"`
+	Version     string     `json:"version" binding:"required,max=20"`
+	URL         string     `json:"url" binding:"required,url,max=2000"`
+	Summary     *string    `json:"summary" binding:"omitempty,max=2000"`
+	PublishedAt *time.Time `json:"published_at"`
+}
+
+// ListPolicies returns the current version of each policy, for clients to
+// show before registration. Registering accepts them by ID.
+func ListPolicies(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		docs, err := queries.ListCurrentPolicyDocuments(c.Request.Context())
+		if err != nil {
+			logger.Error("Failed to list current policy documents", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodePolicyFetchFailed)
+			return
+		}
+
+		data := make([]PolicyDocumentResponse, 0, len(docs))
+		for _, doc := range docs {
+			data = append(data, newPolicyDocumentResponse(doc))
+		}
+
+		respond.OK(c, data)
+	}
+}
+
+// ListMyPolicies returns the current version of each policy with when the
+// user accepted it. Ones without accepted_at are what a 428
+// policy_acceptance_required response is waiting on.
+func ListMyPolicies(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		data, ok := listMyPolicies(c, queries, c.GetString("user_id"))
+		if !ok {
+			return
+		}
+
+		respond.OK(c, data)
+	}
+}
+
+// AcceptPolicies records the user's acceptance of policy documents. A
+// document that is no longer current is rejected with 409 Conflict, as the
+// user accepted a version that was since replaced and must read the new
+// one.
+func AcceptPolicies(queries *db.Queries, cache *policies.Cache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+
+		var req AcceptPoliciesRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		current, err := queries.ListCurrentPolicyDocuments(c.Request.Context())
+		if err != nil {
+			logger.Error("Failed to list current policy documents", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodePolicyFetchFailed)
+			return
+		}
+		if policies.Outdated(current, req.DocumentIDs) {
+			respond.Error(c, http.StatusConflict, i18n.CodePolicyOutdated)
+			return
+		}
+
+		if err := acceptPolicies(c, queries, userID, req.DocumentIDs); err != nil {
+			logger.Error("Failed to accept policy documents", "user_id", userID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodePolicyUpdateFailed)
+			return
+		}
+		cache.Forget(userID)
+
+		data, ok := listMyPolicies(c, queries, userID)
+		if !ok {
+			return
+		}
+
+		respond.OK(c, data)
+	}
+}
+
+// AdminPublishPolicy publishes a new version of a policy. Once it takes
+// effect, every user must accept it before using the API again.
+func AdminPublishPolicy(queries *db.Queries, cache *policies.Cache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req PublishPolicyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		doc, err := queries.CreatePolicyDocument(c.Request.Context(), db.CreatePolicyDocumentParams{
+			ID:          ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+			Kind:        req.Kind,
+			Version:     req.Version,
+			Url:         req.URL,
+			Summary:     req.Summary,
+			PublishedAt: timestamptz(req.PublishedAt),
+		})
+		if err != nil {
+			if isUniqueViolation(err) {
+				respond.Error(c, http.StatusConflict, i18n.CodePolicyVersionTaken)
+				return
+			}
+			logger.Error("Failed to create policy document", "kind", req.Kind, "version", req.Version, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodePolicyUpdateFailed)
+			return
+		}
+		cache.Reset()
+
+		logger.Info("Policy published", "kind", doc.Kind, "version", doc.Version, "published_at", doc.PublishedAt.Time)
+
+		respond.Created(c, newPolicyDocumentResponse(doc))
+	}
+}
+
+// AdminListPolicies returns every version of every policy, including
+// scheduled ones, with how many users accepted each
+func AdminListPolicies(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		docs, err := queries.ListPolicyDocuments(c.Request.Context())
+		if err != nil {
+			logger.Error("Failed to list policy documents", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodePolicyFetchFailed)
+			return
+		}
+
+		data := make([]PolicyDocumentResponse, 0, len(docs))
+		for _, doc := range docs {
+			count := doc.AcceptanceCount
+			data = append(data, PolicyDocumentResponse{
+				ID:              doc.ID,
+				Kind:            doc.Kind,
+				Version:         doc.Version,
+				URL:             doc.Url,
+				Summary:         doc.Summary,
+				PublishedAt:     timePtr(doc.PublishedAt),
+				AcceptanceCount: &count,
+			})
+		}
+
+		respond.OK(c, data)
+	}
+}
+
+// listMyPolicies loads the current policies with userID's acceptance of
+// each, responding with an error if it can't
+func listMyPolicies(c *gin.Context, queries *db.Queries, userID string) ([]PolicyDocumentResponse, bool) {
+	docs, err := queries.ListUserPolicyDocuments(c.Request.Context(), userID)
+	if err != nil {
+		logger.Error("Failed to list user policy documents", "user_id", userID, "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodePolicyFetchFailed)
+		return nil, false
+	}
+
+	data := make([]PolicyDocumentResponse, 0, len(docs))
+	for _, doc := range docs {
+		data = append(data, PolicyDocumentResponse{
+			ID:          doc.ID,
+			Kind:        doc.Kind,
+			Version:     doc.Version,
+			URL:         doc.Url,
+			Summary:     doc.Summary,
+			PublishedAt: timePtr(doc.PublishedAt),
+			AcceptedAt:  timePtr(doc.AcceptedAt),
+		})
+	}
+	return data, true
+}
+
+// acceptPolicies records userID's acceptance of documentIDs from the
+// request's IP. Documents that aren't current are skipped.
+func acceptPolicies(c *gin.Context, queries *db.Queries, userID string, documentIDs []string) error {
+	ip := c.ClientIP()
+	_, err := queries.AcceptPolicyDocuments(c.Request.Context(), db.AcceptPolicyDocumentsParams{
+		UserID:      userID,
+		Ip:          &ip,
+		DocumentIds: documentIDs,
+	})
+	return err
+}
+
+func newPolicyDocumentResponse(doc db.PolicyDocument) PolicyDocumentResponse {
+	return PolicyDocumentResponse{
+		ID:          doc.ID,
+		Kind:        doc.Kind,
+		Version:     doc.Version,
+		URL:         doc.Url,
+		Summary:     doc.Summary,
+		PublishedAt: timePtr(doc.PublishedAt),
+	}
+}
@@ -0,0 +1,395 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/pours"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/oklog/ulid/v2"
+)
+
+// Pour stream limits: samples are stored in batches of pourBatchSize, and a
+// curve holds at most maxPourSamples (an hour at 10 Hz)
+const (
+	pourBatchSize      = 50
+	maxPourSamples     = 36000
+	maxPourStreamBytes = 4 << 20
+)
+
+// Downsampling resolutions accepted for charting
+const (
+	minPourResolution = 10 * time.Millisecond
+	maxPourResolution = time.Minute
+)
+
+// PourStreamQuery represents the pour stream query parameters. The body is
+// the stream itself.
+type PourStreamQuery struct {
+	ClientID    string     `form:"client_id" binding:"required,max=100"`
+	BrewID      *string    `form:"brew_id" binding:"omitempty,min=1,max=255"`
+	DeviceModel *string    `form:"device_model" binding:"omitempty,max=100"`
+	StartedAt   *time.Time `form:"started_at" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// PourCurveQuery represents the pour curve query parameters. Resolution is
+// a duration such as 1s or 250ms.
+type PourCurveQuery struct {
+	Resolution string `form:"resolution" binding:"omitempty,max=20"`
+}
+
+// PourSampleResponse is one point of a pour curve, with the flow rate since
+// the previous point
+type PourSampleResponse struct {
+	TMs         int32    `json:"t_ms"`
+	Grams       float64  `json:"grams"`
+	FlowGPS     float64  `json:"flow_gps"`
+	PressureBar *float64 `json:"pressure_bar"`
+}
+
+// PourCurveResponse is a brew's pour curve. Samples are only included when
+// fetching the curve for charting.
+type PourCurveResponse struct {
+	ID           string               `json:"id"`
+	BrewID       string               `json:"brew_id"`
+	ClientID     string               `json:"client_id"`
+	DeviceModel  *string              `json:"device_model"`
+	StartedAt    *time.Time           `json:"started_at"`
+	SampleCount  int32                `json:"sample_count"`
+	DurationMs   int32                `json:"duration_ms"`
+	FinalGrams   *float64             `json:"final_grams"`
+	CreatedAt    time.Time            `json:"created_at"`
+	UpdatedAt    time.Time            `json:"updated_at"`
+	ResolutionMs *int32               `json:"resolution_ms,omitempty"`
+	Samples      []PourSampleResponse `json:"samples,omitempty"`
+}
+
+// StreamPourCurve ingests a smart scale bridge's gram-by-gram pour data,
+// authenticated with an API key. The body is a chunked stream of
+// newline-delimited JSON samples, stored as they arrive so a dropped stream
+// keeps what was sent, then compacted into the curve when it ends;
+// reconnecting with the same client_id resumes the curve. New curves attach
+// to brew_id, or else to the brew whose timer is running, replacing any
+// curve it had.
+func StreamPourCurve(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query PourStreamQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		curve, created, ok := openPourCurve(c, queries, query)
+		if !ok {
+			return
+		}
+
+		// Keep storing samples if the bridge disconnects mid-stream
+		ctx := context.WithoutCancel(c.Request.Context())
+		reader := pours.NewReader(http.MaxBytesReader(c.Writer, c.Request.Body, maxPourStreamBytes))
+		count := int64(curve.SampleCount)
+		batch := make([]pours.Sample, 0, pourBatchSize)
+		var readErr, storeErr error
+		for {
+			sample, err := reader.Read()
+			if err == nil {
+				batch = append(batch, sample)
+			}
+
+			// Flush full batches, and whenever the bridge pauses so live
+			// charts stay current
+			if len(batch) > 0 && (err != nil || len(batch) == pourBatchSize || !reader.Buffered()) {
+				if count+int64(len(batch)) > maxPourSamples {
+					readErr = errPourCurveTooLong
+					break
+				}
+				n, err := addPourSamples(ctx, queries, curve.ID, batch)
+				if err != nil {
+					storeErr = err
+					break
+				}
+				count += n
+				batch = batch[:0]
+			}
+
+			if err != nil {
+				if err != io.EOF {
+					readErr = err
+				}
+				break
+			}
+		}
+
+		if storeErr == nil {
+			var err error
+			curve, err = queries.CompactPourCurve(ctx, curve.ID)
+			storeErr = err
+		}
+		if storeErr != nil {
+			logger.Error("Failed to store pour samples", "pour_curve_id", curve.ID, "error", storeErr)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodePourCurveUpdateFailed)
+			return
+		}
+
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case readErr == nil:
+		case errors.Is(readErr, pours.ErrInvalidSample):
+			respond.Details(c, http.StatusBadRequest, i18n.CodePourSampleInvalid, map[string]string{
+				"line": strconv.Itoa(reader.Line()),
+			})
+			return
+		case readErr == errPourCurveTooLong, errors.As(readErr, &maxBytesErr):
+			respond.Error(c, http.StatusRequestEntityTooLarge, i18n.CodePourCurveTooLong)
+			return
+		default:
+			// The bridge went away; it resumes with the same client_id
+			logger.Warn("Pour stream interrupted", "pour_curve_id", curve.ID, "samples", curve.SampleCount, "error", readErr)
+			respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidRequest)
+			return
+		}
+
+		status := http.StatusOK
+		if created {
+			status = http.StatusCreated
+		}
+		respond.Status(c, status, newPourCurveResponse(curve, nil))
+	}
+}
+
+// GetBrewPourCurve returns a visible or archived brew's pour curve for
+// charting: every sample, including those of a stream still in progress, or
+// with ?resolution= at most one point per window
+func GetBrewPourCurve(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query PourCurveQuery
+		err := c.ShouldBindQuery(&query)
+		var resolution time.Duration
+		if err == nil && query.Resolution != "" {
+			resolution, err = time.ParseDuration(query.Resolution)
+			if err == nil && (resolution < minPourResolution || resolution > maxPourResolution) {
+				err = errors.New("resolution out of range")
+			}
+		}
+		if err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		brew, archived, ok := loadHistoryBrew(c, queries)
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+		getCurve := queries.GetBrewPourCurve
+		if archived {
+			getCurve = queries.GetArchivedBrewPourCurve
+		}
+		curve, err := getCurve(ctx, brew.ID)
+		if err == pgx.ErrNoRows {
+			respond.Error(c, http.StatusNotFound, i18n.CodePourCurveNotFound)
+			return
+		}
+		// An archived curve was compacted before it was archived, so has no
+		// staged samples left
+		var rows []db.ListPourSamplesRow
+		if err == nil && !archived {
+			rows, err = queries.ListPourSamples(ctx, curve.ID)
+		}
+		if err != nil {
+			logger.Error("Failed to get pour curve", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodePourCurveFetchFailed)
+			return
+		}
+
+		staged := make([]pours.Sample, 0, len(rows))
+		for _, row := range rows {
+			staged = append(staged, pours.Sample{TMs: row.TMs, Grams: row.Grams, PressureBar: row.PressureBar})
+		}
+		samples := pours.Merge(compactedPourSamples(curve), staged)
+
+		var resolutionMs *int32
+		if resolution > 0 {
+			ms := int32(resolution.Milliseconds())
+			samples = pours.Downsample(samples, ms)
+			resolutionMs = &ms
+		}
+
+		resp := newPourCurveResponse(curve, samples)
+		resp.ResolutionMs = resolutionMs
+		respond.OK(c, resp)
+	}
+}
+
+// DeleteBrewPourCurve discards the current user's brew's pour curve
+func DeleteBrewPourCurve(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		brew, ok := loadBrew(c, queries)
+		if !ok {
+			return
+		}
+
+		rows, err := queries.DeleteBrewPourCurve(c.Request.Context(), brew.ID)
+		if err != nil {
+			logger.Error("Failed to delete pour curve", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodePourCurveUpdateFailed)
+			return
+		}
+		if rows == 0 {
+			respond.Error(c, http.StatusNotFound, i18n.CodePourCurveNotFound)
+			return
+		}
+
+		respond.OK(c, nil)
+	}
+}
+
+var errPourCurveTooLong = errors.New("pour curve has too many samples")
+
+// openPourCurve returns the curve the stream resumes, or creates one on the
+// stream's brew, writing an error response if there is none
+func openPourCurve(c *gin.Context, queries *db.Queries, query PourStreamQuery) (db.PourCurve, bool, bool) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+
+	curve, err := queries.GetPourCurveByClientID(ctx, db.GetPourCurveByClientIDParams{
+		UserID:   userID,
+		ClientID: query.ClientID,
+	})
+	if err == nil {
+		return curve, false, true
+	}
+	if err != pgx.ErrNoRows {
+		logger.Error("Failed to get pour curve", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodePourCurveFetchFailed)
+		return curve, false, false
+	}
+
+	var brewID string
+	if query.BrewID != nil {
+		if !checkOwnedBrew(c, queries, *query.BrewID, userID) {
+			return curve, false, false
+		}
+		brewID = *query.BrewID
+	} else {
+		brew, err := queries.GetRunningBrew(ctx, &userID)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusConflict, i18n.CodeNoRunningBrew)
+				return curve, false, false
+			}
+			logger.Error("Failed to get running brew", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewFetchFailed)
+			return curve, false, false
+		}
+		brewID = brew.ID
+	}
+
+	startedAt := time.Now()
+	if query.StartedAt != nil {
+		startedAt = *query.StartedAt
+	}
+	apiKeyID := c.GetString("api_key_id")
+
+	_, err = queries.DeleteBrewPourCurve(ctx, brewID)
+	if err == nil {
+		curve, err = queries.CreatePourCurve(ctx, db.CreatePourCurveParams{
+			ID:          ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+			BrewID:      brewID,
+			UserID:      userID,
+			ApiKeyID:    &apiKeyID,
+			ClientID:    query.ClientID,
+			DeviceModel: query.DeviceModel,
+			StartedAt:   pgtype.Timestamptz{Time: startedAt, Valid: true},
+		})
+	}
+	if err != nil {
+		logger.Error("Failed to create pour curve", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodePourCurveUpdateFailed)
+		return curve, false, false
+	}
+
+	logger.Info("Pour stream started", "pour_curve_id", curve.ID, "brew_id", brewID)
+	return curve, true, true
+}
+
+// addPourSamples stages a batch of samples, returning how many were new
+func addPourSamples(ctx context.Context, queries *db.Queries, curveID string, batch []pours.Sample) (int64, error) {
+	tMs := make([]int32, len(batch))
+	grams := make([]float64, len(batch))
+	pressure := make([]float64, len(batch))
+	for i, sample := range batch {
+		tMs[i] = sample.TMs
+		grams[i] = sample.Grams
+		pressure[i] = math.NaN()
+		if sample.PressureBar != nil {
+			pressure[i] = *sample.PressureBar
+		}
+	}
+	return queries.AddPourSamples(ctx, db.AddPourSamplesParams{
+		CurveID:     curveID,
+		TMs:         tMs,
+		Grams:       grams,
+		PressureBar: pressure,
+	})
+}
+
+// compactedPourSamples unpacks a curve's sample arrays
+func compactedPourSamples(curve db.PourCurve) []pours.Sample {
+	samples := make([]pours.Sample, len(curve.SampleTMs))
+	for i, tMs := range curve.SampleTMs {
+		samples[i] = pours.Sample{TMs: tMs}
+		if i < len(curve.SampleGrams) {
+			samples[i].Grams = float64(curve.SampleGrams[i])
+		}
+		if i < len(curve.SamplePressureBar) {
+			if p := curve.SamplePressureBar[i]; !math.IsNaN(float64(p)) {
+				pressure := float64(p)
+				samples[i].PressureBar = &pressure
+			}
+		}
+	}
+	return samples
+}
+
+func newPourCurveResponse(curve db.PourCurve, samples []pours.Sample) PourCurveResponse {
+	resp := PourCurveResponse{
+		ID:          curve.ID,
+		BrewID:      curve.BrewID,
+		ClientID:    curve.ClientID,
+		DeviceModel: curve.DeviceModel,
+		StartedAt:   timePtr(curve.StartedAt),
+		SampleCount: curve.SampleCount,
+		DurationMs:  curve.DurationMs,
+		FinalGrams:  curve.FinalGrams,
+		CreatedAt:   curve.CreatedAt,
+		UpdatedAt:   curve.UpdatedAt,
+	}
+	if samples != nil {
+		flow := pours.Flow(samples)
+		resp.Samples = make([]PourSampleResponse, len(samples))
+		for i, sample := range samples {
+			resp.Samples[i] = PourSampleResponse{
+				TMs:         sample.TMs,
+				Grams:       sample.Grams,
+				FlowGPS:     flow[i],
+				PressureBar: sample.PressureBar,
+			}
+		}
+	}
+	return resp
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http/pprof"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Pprof serves the runtime profiles of net/http/pprof under
+// /debug/pprof/*profile, for the internal listener only
+func Pprof() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Param("profile") {
+		case "/cmdline":
+			pprof.Cmdline(c.Writer, c.Request)
+		case "/profile":
+			pprof.Profile(c.Writer, c.Request)
+		case "/symbol":
+			pprof.Symbol(c.Writer, c.Request)
+		case "/trace":
+			pprof.Trace(c.Writer, c.Request)
+		default:
+			// The index, and named profiles such as heap and goroutine
+			pprof.Index(c.Writer, c.Request)
+		}
+	}
+}
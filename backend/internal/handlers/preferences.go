@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"net/http"
+
+	"brewd/internal/currency"
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+	"brewd/internal/stats"
+	"brewd/internal/units"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// PreferencesResponse represents the current user's preferences
+type PreferencesResponse struct {
+	WeightUnit       units.WeightUnit      `json:"weight_unit"`
+	TemperatureUnit  units.TemperatureUnit `json:"temperature_unit"`
+	Timezone         string                `json:"timezone"`
+	Currency         string                `json:"currency"`
+	AnalyticsConsent bool                  `json:"analytics_consent"`
+}
+
+// UpdatePreferencesRequest represents a partial preferences update. UnitSystem
+// sets both units at once; individual units override it when also provided.
+type UpdatePreferencesRequest struct {
+	UnitSystem       *string `json:"unit_system" binding:"omitempty,oneof=metric imperial"`
+	WeightUnit       *string `json:"weight_unit" binding:"omitempty,oneof=g oz"`
+	TemperatureUnit  *string `json:"temperature_unit" binding:"omitempty,oneof=c f"`
+	Timezone         *string `json:"timezone" binding:"omitempty,max=64"`
+	Currency         *string `json:"currency" binding:"omitempty,len=3"`
+	AnalyticsConsent *bool   `json:"analytics_consent"`
+}
+
+// GetPreferences returns the current user's preferences
+func GetPreferences(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		prefs, err := queries.GetUserPreferences(c.Request.Context(), c.GetString("user_id"))
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusNotFound, i18n.CodeUserNotFound)
+				return
+			}
+			logger.Error("Failed to get user preferences", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeInternal)
+			return
+		}
+
+		respond.OK(c, PreferencesResponse{
+			WeightUnit:       units.WeightUnit(prefs.WeightUnit),
+			TemperatureUnit:  units.TemperatureUnit(prefs.TemperatureUnit),
+			Timezone:         prefs.Timezone,
+			Currency:         prefs.Currency,
+			AnalyticsConsent: prefs.AnalyticsConsent,
+		})
+	}
+}
+
+// UpdatePreferences applies a partial update to the current user's preferences
+func UpdatePreferences(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req UpdatePreferencesRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		if req.Timezone != nil {
+			if _, err := stats.LoadLocation(*req.Timezone); err != nil {
+				respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidTimezone)
+				return
+			}
+		}
+
+		var cur currency.Currency
+		if req.Currency != nil {
+			var err error
+			if cur, err = currency.Lookup(*req.Currency); err != nil {
+				respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidCurrency)
+				return
+			}
+		}
+
+		ctx := c.Request.Context()
+		userID := c.GetString("user_id")
+
+		current, err := queries.GetUserPreferences(ctx, userID)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusNotFound, i18n.CodeUserNotFound)
+				return
+			}
+			logger.Error("Failed to get user preferences", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodePreferencesUpdateFailed)
+			return
+		}
+
+		// Merge the request over the stored preferences
+		pref := units.Preference{
+			Weight:      units.WeightUnit(current.WeightUnit),
+			Temperature: units.TemperatureUnit(current.TemperatureUnit),
+		}
+		if req.UnitSystem != nil {
+			pref, _ = units.ParseSystem(*req.UnitSystem)
+		}
+		if req.WeightUnit != nil {
+			pref.Weight = units.WeightUnit(*req.WeightUnit)
+		}
+		if req.TemperatureUnit != nil {
+			pref.Temperature = units.TemperatureUnit(*req.TemperatureUnit)
+		}
+		timezone := current.Timezone
+		if req.Timezone != nil {
+			timezone = *req.Timezone
+		}
+		currencyCode := current.Currency
+		if req.Currency != nil {
+			currencyCode = cur.Code
+		}
+		analyticsConsent := current.AnalyticsConsent
+		if req.AnalyticsConsent != nil {
+			analyticsConsent = *req.AnalyticsConsent
+		}
+
+		updated, err := queries.UpdateUserPreferences(ctx, db.UpdateUserPreferencesParams{
+			ID:               userID,
+			WeightUnit:       string(pref.Weight),
+			TemperatureUnit:  string(pref.Temperature),
+			Timezone:         timezone,
+			Currency:         currencyCode,
+			AnalyticsConsent: analyticsConsent,
+		})
+		if err != nil {
+			logger.Error("Failed to update user preferences", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodePreferencesUpdateFailed)
+			return
+		}
+
+		respond.OK(c, PreferencesResponse{
+			WeightUnit:       units.WeightUnit(updated.WeightUnit),
+			TemperatureUnit:  units.TemperatureUnit(updated.TemperatureUnit),
+			Timezone:         updated.Timezone,
+			Currency:         updated.Currency,
+			AnalyticsConsent: updated.AnalyticsConsent,
+		})
+	}
+}
+
+// resolveUnits returns the units a request reads and writes brew values in:
+// the user's stored preference, overridden by a ?units=metric|imperial query
+func resolveUnits(c *gin.Context, queries *db.Queries) (units.Preference, error) {
+	if system := c.Query("units"); system != "" {
+		return units.ParseSystem(system)
+	}
+
+	prefs, err := queries.GetUserPreferences(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		return units.Preference{}, err
+	}
+
+	return units.Preference{
+		Weight:      units.WeightUnit(prefs.WeightUnit),
+		Temperature: units.TemperatureUnit(prefs.TemperatureUnit),
+	}, nil
+}
@@ -0,0 +1,431 @@
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/links"
+	"brewd/internal/logger"
+	"brewd/internal/methods"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// publicCacheControl lets link unfurlers and CDNs cache public content briefly
+const publicCacheControl = "public, max-age=300"
+
+// Default and largest oEmbed card size, in pixels
+const (
+	embedWidth  = 480
+	embedHeight = 200
+)
+
+// PublicAuthor is the user behind public content
+type PublicAuthor struct {
+	Username string `json:"username"`
+}
+
+// PublicRecipe is the stable public representation of a recipe at its
+// current revision. Parameters are in grams, degrees Celsius and seconds.
+type PublicRecipe struct {
+	Type            string       `json:"type"`
+	ID              string       `json:"id"`
+	URL             string       `json:"url"`
+	Name            string       `json:"name"`
+	BrewMethod      *string      `json:"brew_method"`
+	Revision        int32        `json:"revision"`
+	DoseGrams       *float64     `json:"dose_grams"`
+	WaterGrams      *float64     `json:"water_grams"`
+	WaterTempC      *float64     `json:"water_temp_c"`
+	GrindSetting    *string      `json:"grind_setting"`
+	BrewTimeSeconds *int32       `json:"brew_time_seconds"`
+	Instructions    *string      `json:"instructions"`
+	Author          PublicAuthor `json:"author"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+}
+
+// PublicBrew is the stable public representation of a brew. Parameters are
+// in grams, degrees Celsius and seconds.
+type PublicBrew struct {
+	Type            string       `json:"type"`
+	ID              string       `json:"id"`
+	URL             string       `json:"url"`
+	Name            string       `json:"name"`
+	BrewMethod      *string      `json:"brew_method"`
+	BeanOrigin      *string      `json:"bean_origin"`
+	Roaster         *string      `json:"roaster"`
+	Notes           *string      `json:"notes"`
+	DoseGrams       *float64     `json:"dose_grams"`
+	WaterGrams      *float64     `json:"water_grams"`
+	WaterTempC      *float64     `json:"water_temp_c"`
+	GrindSetting    *string      `json:"grind_setting"`
+	BrewTimeSeconds *int32       `json:"brew_time_seconds"`
+	Author          PublicAuthor `json:"author"`
+	CreatedAt       time.Time    `json:"created_at"`
+}
+
+// PublicRecipeSummary is a public recipe in the public recipe index
+type PublicRecipeSummary struct {
+	ID         string       `json:"id"`
+	URL        string       `json:"url"`
+	Name       string       `json:"name"`
+	BrewMethod *string      `json:"brew_method"`
+	Author     PublicAuthor `json:"author"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+}
+
+// PublicProfile is the stable public representation of a user profile
+type PublicProfile struct {
+	Username          string     `json:"username"`
+	URL               string     `json:"url"`
+	Bio               *string    `json:"bio"`
+	Location          *string    `json:"location"`
+	ProfilePictureURL *string    `json:"profile_picture_url"`
+	RecipeCount       int64      `json:"recipe_count"`
+	BrewCount         int64      `json:"brew_count"`
+	JoinedAt          *time.Time `json:"joined_at"`
+}
+
+// PublicProfileSummary is a profile in the public profile index
+type PublicProfileSummary struct {
+	Username          string    `json:"username"`
+	URL               string    `json:"url"`
+	Bio               *string   `json:"bio"`
+	ProfilePictureURL *string   `json:"profile_picture_url"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// OEmbedQuery represents the oEmbed request parameters
+type OEmbedQuery struct {
+	URL       string `form:"url" binding:"required,max=2000"`
+	Format    string `form:"format"`
+	MaxWidth  int    `form:"maxwidth" binding:"min=0"`
+	MaxHeight int    `form:"maxheight" binding:"min=0"`
+}
+
+// OEmbedResponse is an oEmbed 1.0 rich response
+type OEmbedResponse struct {
+	Version      string `json:"version"`
+	Type         string `json:"type"`
+	Title        string `json:"title"`
+	AuthorName   string `json:"author_name"`
+	ProviderName string `json:"provider_name"`
+	ProviderURL  string `json:"provider_url"`
+	CacheAge     int    `json:"cache_age"`
+	HTML         string `json:"html"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+}
+
+// GetPublicRecipe returns a public recipe without authentication
+func GetPublicRecipe(queries *db.Queries, site *links.Site) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		recipe, ok := loadPublicRecipe(c, queries, c.Param("id"))
+		if !ok {
+			return
+		}
+
+		c.Header("Cache-Control", publicCacheControl)
+		respond.OK(c, newPublicRecipe(recipe, site))
+	}
+}
+
+// GetPublicBrew returns a public brew without authentication
+func GetPublicBrew(queries *db.Queries, site *links.Site) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		brew, ok := loadPublicBrew(c, queries, c.Param("id"))
+		if !ok {
+			return
+		}
+
+		c.Header("Cache-Control", publicCacheControl)
+		respond.OK(c, newPublicBrew(brew, site))
+	}
+}
+
+// ListPublicRecipes pages through every public recipe, oldest first
+func ListPublicRecipes(queries *db.Queries, site *links.Site) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var page PageQuery
+		if err := c.ShouldBindQuery(&page); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if page.Limit == 0 {
+			page.Limit = defaultPageLimit
+		}
+
+		rows, err := queries.ListPublicRecipes(c.Request.Context(), db.ListPublicRecipesParams{
+			Limit:  page.Limit,
+			Offset: page.Offset,
+		})
+		if err != nil {
+			logger.Error("Failed to list public recipes", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeRecipeFetchFailed)
+			return
+		}
+
+		recipes := make([]PublicRecipeSummary, 0, len(rows))
+		for _, row := range rows {
+			recipes = append(recipes, PublicRecipeSummary{
+				ID:         row.ID,
+				URL:        site.URL(links.KindRecipe, row.ID),
+				Name:       row.Name,
+				BrewMethod: row.BrewMethod,
+				Author:     PublicAuthor{Username: row.Username},
+				UpdatedAt:  row.UpdatedAt,
+			})
+		}
+
+		c.Header("Cache-Control", publicCacheControl)
+		respond.Page(c, recipes, page.Limit, page.Offset, len(recipes))
+	}
+}
+
+// ListPublicProfiles pages through the profiles of users with public
+// recipes or brews, oldest first
+func ListPublicProfiles(queries *db.Queries, site *links.Site) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var page PageQuery
+		if err := c.ShouldBindQuery(&page); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if page.Limit == 0 {
+			page.Limit = defaultPageLimit
+		}
+
+		rows, err := queries.ListPublicProfiles(c.Request.Context(), db.ListPublicProfilesParams{
+			Limit:  page.Limit,
+			Offset: page.Offset,
+		})
+		if err != nil {
+			logger.Error("Failed to list public profiles", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeInternal)
+			return
+		}
+
+		profiles := make([]PublicProfileSummary, 0, len(rows))
+		for _, row := range rows {
+			profiles = append(profiles, PublicProfileSummary{
+				Username:          row.Username,
+				URL:               site.URL(links.KindUser, row.Username),
+				Bio:               row.Bio,
+				ProfilePictureURL: row.ProfilePictureUrl,
+				UpdatedAt:         row.UpdatedAt,
+			})
+		}
+
+		c.Header("Cache-Control", publicCacheControl)
+		respond.Page(c, profiles, page.Limit, page.Offset, len(profiles))
+	}
+}
+
+// GetPublicProfile returns a user's public profile by username
+func GetPublicProfile(queries *db.Queries, site *links.Site) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		profile, err := queries.GetPublicProfile(c.Request.Context(), c.Param("username"))
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusNotFound, i18n.CodeUserNotFound)
+				return
+			}
+			logger.Error("Failed to get public profile", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeInternal)
+			return
+		}
+
+		c.Header("Cache-Control", publicCacheControl)
+		respond.OK(c, PublicProfile{
+			Username:          profile.Username,
+			URL:               site.URL(links.KindUser, profile.Username),
+			Bio:               profile.Bio,
+			Location:          profile.Location,
+			ProfilePictureURL: profile.ProfilePictureUrl,
+			RecipeCount:       profile.RecipeCount,
+			BrewCount:         profile.BrewCount,
+			JoinedAt:          timePtr(profile.JoinedAt),
+		})
+	}
+}
+
+// OEmbed is the oEmbed provider endpoint for recipe and brew web pages. It
+// answers with a rich card so blogs and chat apps unfurl brewd links, and
+// 404s for URLs that aren't public brewd content.
+func OEmbed(queries *db.Queries, site *links.Site) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query OEmbedQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if query.Format != "" && query.Format != "json" {
+			respond.Error(c, http.StatusNotImplemented, i18n.CodeEmbedFormatUnsupported)
+			return
+		}
+
+		kind, id, ok := site.Parse(query.URL)
+		if !ok || kind == links.KindUser {
+			respond.Error(c, http.StatusNotFound, i18n.CodeEmbedURLUnsupported)
+			return
+		}
+
+		var title, author, summary string
+		switch kind {
+		case links.KindRecipe:
+			recipe, ok := loadPublicRecipe(c, queries, id)
+			if !ok {
+				return
+			}
+			title, author = recipe.Name, recipe.Username
+			summary = embedSummary(recipe.BrewMethod, recipe.DoseGrams, recipe.WaterGrams, recipe.WaterTempC, recipe.BrewTimeSeconds)
+		case links.KindBrew:
+			brew, ok := loadPublicBrew(c, queries, id)
+			if !ok {
+				return
+			}
+			title, author = brew.Name, brew.Username
+			summary = embedSummary(brew.BrewMethod, brew.DoseGrams, brew.WaterGrams, brew.WaterTempC, brew.BrewTimeSeconds)
+		}
+
+		c.Header("Cache-Control", publicCacheControl)
+		c.JSON(http.StatusOK, OEmbedResponse{
+			Version:      "1.0",
+			Type:         "rich",
+			Title:        title,
+			AuthorName:   author,
+			ProviderName: "brewd",
+			ProviderURL:  site.BaseURL(),
+			CacheAge:     300,
+			HTML:         embedHTML(kind, id, site.URL(kind, id), title, author, summary),
+			Width:        embedSize(embedWidth, query.MaxWidth),
+			Height:       embedSize(embedHeight, query.MaxHeight),
+		})
+	}
+}
+
+// loadPublicRecipe fetches a public recipe, writing an error response
+// otherwise. Private recipes are reported as not found.
+func loadPublicRecipe(c *gin.Context, queries *db.Queries, id string) (db.GetPublicRecipeRow, bool) {
+	recipe, err := queries.GetPublicRecipe(c.Request.Context(), id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			respond.Error(c, http.StatusNotFound, i18n.CodeRecipeNotFound)
+			return recipe, false
+		}
+		logger.Error("Failed to get public recipe", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeRecipeFetchFailed)
+		return recipe, false
+	}
+	return recipe, true
+}
+
+// loadPublicBrew fetches a public brew, writing an error response otherwise.
+// Private brews are reported as not found.
+func loadPublicBrew(c *gin.Context, queries *db.Queries, id string) (db.GetPublicBrewRow, bool) {
+	brew, err := queries.GetPublicBrew(c.Request.Context(), id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			respond.Error(c, http.StatusNotFound, i18n.CodeBrewNotFound)
+			return brew, false
+		}
+		logger.Error("Failed to get public brew", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewFetchFailed)
+		return brew, false
+	}
+	return brew, true
+}
+
+func newPublicRecipe(recipe db.GetPublicRecipeRow, site *links.Site) PublicRecipe {
+	return PublicRecipe{
+		Type:            links.KindRecipe,
+		ID:              recipe.ID,
+		URL:             site.URL(links.KindRecipe, recipe.ID),
+		Name:            recipe.Name,
+		BrewMethod:      recipe.BrewMethod,
+		Revision:        recipe.CurrentRevision,
+		DoseGrams:       recipe.DoseGrams,
+		WaterGrams:      recipe.WaterGrams,
+		WaterTempC:      recipe.WaterTempC,
+		GrindSetting:    recipe.GrindSetting,
+		BrewTimeSeconds: recipe.BrewTimeSeconds,
+		Instructions:    recipe.Instructions,
+		Author:          PublicAuthor{Username: recipe.Username},
+		CreatedAt:       recipe.CreatedAt,
+		UpdatedAt:       recipe.UpdatedAt,
+	}
+}
+
+func newPublicBrew(brew db.GetPublicBrewRow, site *links.Site) PublicBrew {
+	return PublicBrew{
+		Type:            links.KindBrew,
+		ID:              brew.ID,
+		URL:             site.URL(links.KindBrew, brew.ID),
+		Name:            brew.Name,
+		BrewMethod:      brew.BrewMethod,
+		BeanOrigin:      brew.BeanOrigin,
+		Roaster:         brew.Roaster,
+		Notes:           brew.Notes,
+		DoseGrams:       brew.DoseGrams,
+		WaterGrams:      brew.WaterGrams,
+		WaterTempC:      brew.WaterTempC,
+		GrindSetting:    brew.GrindSetting,
+		BrewTimeSeconds: brew.BrewTimeSeconds,
+		Author:          PublicAuthor{Username: brew.Username},
+		CreatedAt:       brew.CreatedAt,
+	}
+}
+
+// embedSummary is the one-line parameter summary on an embed card, e.g.
+// "V60 · 15 g · 250 g water · 94 °C · 3:30"
+func embedSummary(brewMethod *string, dose, water, waterTemp *float64, brewTime *int32) string {
+	var parts []string
+	if brewMethod != nil {
+		if method, ok := methods.Lookup(*brewMethod); ok {
+			parts = append(parts, method.Name)
+		}
+	}
+	if dose != nil {
+		parts = append(parts, fmt.Sprintf("%g g", *dose))
+	}
+	if water != nil {
+		parts = append(parts, fmt.Sprintf("%g g water", *water))
+	}
+	if waterTemp != nil {
+		parts = append(parts, fmt.Sprintf("%g °C", *waterTemp))
+	}
+	if brewTime != nil {
+		parts = append(parts, fmt.Sprintf("%d:%02d", *brewTime/60, *brewTime%60))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// embedHTML renders the self-contained card embedded in place of a link
+func embedHTML(kind, id, url, title, author, summary string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<blockquote class="brewd-embed" data-brewd-type="%s" data-brewd-id="%s">`, kind, html.EscapeString(id))
+	fmt.Fprintf(&b, `<p><a href="%s">%s</a></p>`, html.EscapeString(url), html.EscapeString(title))
+	if summary != "" {
+		fmt.Fprintf(&b, `<p>%s</p>`, html.EscapeString(summary))
+	}
+	fmt.Fprintf(&b, `<p>by @%s on brewd</p>`, html.EscapeString(author))
+	b.WriteString(`</blockquote>`)
+	return b.String()
+}
+
+// embedSize fits a default card dimension within the consumer's maximum,
+// if it gave one
+func embedSize(size, limit int) int {
+	if limit > 0 && limit < size {
+		return limit
+	}
+	return size
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultSuggestions is how many entries recent and autocomplete lists
+// return by default
+const defaultSuggestions = 10
+
+// RecentQuery represents the recent entries query parameters
+type RecentQuery struct {
+	Limit int32 `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+// AutocompleteQuery represents the autocomplete query parameters
+type AutocompleteQuery struct {
+	Q     string `form:"q" binding:"required,max=100"`
+	Limit int32  `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+// RecentBeanBag is an open bag of beans and when it was last brewed from
+type RecentBeanBag struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Roaster    *string    `json:"roaster"`
+	BeanOrigin *string    `json:"bean_origin"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+// RecentMethod is a brew method and how often and when it was last used
+type RecentMethod struct {
+	BrewMethod string     `json:"brew_method"`
+	BrewCount  int64      `json:"brew_count"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+// RecentGrindSetting is a grind setting used with a brew method
+type RecentGrindSetting struct {
+	GrindSetting string     `json:"grind_setting"`
+	BrewMethod   *string    `json:"brew_method"`
+	BrewCount    int64      `json:"brew_count"`
+	LastUsedAt   *time.Time `json:"last_used_at"`
+}
+
+// RecentFlavor is a flavor descriptor and when it was last noted
+type RecentFlavor struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Depth      int32      `json:"depth"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+// RecentResponse is what the current user reaches for when logging a brew,
+// each list most recently used first
+type RecentResponse struct {
+	BeanBags      []RecentBeanBag      `json:"bean_bags"`
+	Methods       []RecentMethod       `json:"methods"`
+	GrindSettings []RecentGrindSetting `json:"grind_settings"`
+	Flavors       []RecentFlavor       `json:"flavors"`
+}
+
+// GetRecent returns the current user's recently used bean bags, methods,
+// grind settings and flavor descriptors, to prefill the log-a-brew form.
+// Only open bags are listed.
+func GetRecent(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query RecentQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if query.Limit == 0 {
+			query.Limit = defaultSuggestions
+		}
+
+		ctx := c.Request.Context()
+		userID := c.GetString("user_id")
+		bags, err := queries.ListRecentBeanBags(ctx, db.ListRecentBeanBagsParams{
+			OwnerID:  userID,
+			RowLimit: query.Limit,
+		})
+		if err != nil {
+			logger.Error("Failed to list recent bean bags", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeSuggestionFetchFailed)
+			return
+		}
+		methods, err := queries.ListRecentBrewMethods(ctx, db.ListRecentBrewMethodsParams{
+			UserID:   &userID,
+			RowLimit: query.Limit,
+		})
+		if err != nil {
+			logger.Error("Failed to list recent brew methods", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeSuggestionFetchFailed)
+			return
+		}
+		grinds, err := queries.ListRecentGrindSettings(ctx, db.ListRecentGrindSettingsParams{
+			UserID:   &userID,
+			RowLimit: query.Limit,
+		})
+		if err != nil {
+			logger.Error("Failed to list recent grind settings", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeSuggestionFetchFailed)
+			return
+		}
+		flavors, err := queries.ListRecentBrewFlavors(ctx, db.ListRecentBrewFlavorsParams{
+			UserID:   &userID,
+			RowLimit: query.Limit,
+		})
+		if err != nil {
+			logger.Error("Failed to list recent flavors", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeSuggestionFetchFailed)
+			return
+		}
+
+		resp := RecentResponse{
+			BeanBags:      make([]RecentBeanBag, 0, len(bags)),
+			Methods:       make([]RecentMethod, 0, len(methods)),
+			GrindSettings: make([]RecentGrindSetting, 0, len(grinds)),
+			Flavors:       make([]RecentFlavor, 0, len(flavors)),
+		}
+		for _, b := range bags {
+			resp.BeanBags = append(resp.BeanBags, RecentBeanBag{
+				ID:         b.ID,
+				Name:       b.Name,
+				Roaster:    b.Roaster,
+				BeanOrigin: b.BeanOrigin,
+				LastUsedAt: timePtr(b.LastUsedAt),
+			})
+		}
+		for _, m := range methods {
+			resp.Methods = append(resp.Methods, RecentMethod{
+				BrewMethod: m.BrewMethod,
+				BrewCount:  m.BrewCount,
+				LastUsedAt: timePtr(m.LastUsedAt),
+			})
+		}
+		for _, g := range grinds {
+			resp.GrindSettings = append(resp.GrindSettings, RecentGrindSetting{
+				GrindSetting: g.GrindSetting,
+				BrewMethod:   g.BrewMethod,
+				BrewCount:    g.BrewCount,
+				LastUsedAt:   timePtr(g.LastUsedAt),
+			})
+		}
+		for _, f := range flavors {
+			resp.Flavors = append(resp.Flavors, RecentFlavor{
+				ID:         f.ID,
+				Name:       f.Name,
+				Depth:      f.Depth,
+				LastUsedAt: timePtr(f.LastUsedAt),
+			})
+		}
+
+		respond.OK(c, resp)
+	}
+}
+
+// AutocompleteRoasters returns roaster names starting with ?q=, those the
+// current user has used first
+func AutocompleteRoasters(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query AutocompleteQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if query.Limit == 0 {
+			query.Limit = defaultSuggestions
+		}
+
+		userID := c.GetString("user_id")
+		names, err := queries.AutocompleteRoasters(c.Request.Context(), db.AutocompleteRoastersParams{
+			UserID:   &userID,
+			Prefix:   query.Q,
+			RowLimit: query.Limit,
+		})
+		if err != nil {
+			logger.Error("Failed to autocomplete roasters", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeSuggestionFetchFailed)
+			return
+		}
+
+		respond.OK(c, names)
+	}
+}
+
+// AutocompleteOrigins returns bean origins starting with ?q=, those the
+// current user has used first
+func AutocompleteOrigins(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query AutocompleteQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if query.Limit == 0 {
+			query.Limit = defaultSuggestions
+		}
+
+		userID := c.GetString("user_id")
+		names, err := queries.AutocompleteOrigins(c.Request.Context(), db.AutocompleteOriginsParams{
+			UserID:   &userID,
+			Prefix:   query.Q,
+			RowLimit: query.Limit,
+		})
+		if err != nil {
+			logger.Error("Failed to autocomplete origins", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeSuggestionFetchFailed)
+			return
+		}
+
+		respond.OK(c, names)
+	}
+}
@@ -0,0 +1,652 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/methods"
+	"brewd/internal/recipes"
+	"brewd/internal/respond"
+	"brewd/internal/units"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/oklog/ulid/v2"
+)
+
+// RecipeParamsRequest holds a revision's parameters in the caller's units
+type RecipeParamsRequest struct {
+	Dose            *float64 `json:"dose" binding:"omitempty,gt=0"`
+	Water           *float64 `json:"water" binding:"omitempty,gt=0,brew_ratio=dose"`
+	WaterTemp       *float64 `json:"water_temp" binding:"omitempty,temperature"`
+	GrindSetting    *string  `json:"grind_setting" binding:"omitempty,max=50,grind_setting"`
+	BrewTimeSeconds *int32   `json:"brew_time_seconds" binding:"omitempty,gte=0"`
+	Instructions    *string  `json:"instructions"`
+	Changelog       *string  `json:"changelog" binding:"omitempty,max=500"`
+}
+
+// CreateRecipeRequest represents the recipe creation payload
+type CreateRecipeRequest struct {
+	Name       string  `json:"name" binding:"required,max=255"`
+	BrewMethod *string `json:"brew_method" binding:"omitempty,oneof=espresso pour_over french_press aeropress cold_brew drip moka_pot siphon chemex v60 turkish percolator other"`
+	IsPublic   *bool   `json:"is_public"`
+	RecipeParamsRequest
+}
+
+// RollbackRecipeRequest represents a rollback to an earlier revision
+type RollbackRecipeRequest struct {
+	Revision  int32   `json:"revision" binding:"required,min=1"`
+	Changelog *string `json:"changelog" binding:"omitempty,max=500"`
+}
+
+// RecipeResponse represents a recipe with its current revision's parameters
+type RecipeResponse struct {
+	ID              string           `json:"id"`
+	OwnerID         string           `json:"owner_id"`
+	Name            string           `json:"name"`
+	BrewMethod      *string          `json:"brew_method"`
+	IsPublic        bool             `json:"is_public"`
+	CurrentRevision int32            `json:"current_revision"`
+	ForkedFrom      *ForkSource      `json:"forked_from"`
+	Params          recipes.Params   `json:"params"`
+	Units           units.Preference `json:"units"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+}
+
+// ForkSource identifies the recipe revision a fork was cloned from
+type ForkSource struct {
+	RecipeID string `json:"recipe_id"`
+	Revision *int32 `json:"revision"`
+}
+
+// RevisionResponse represents one revision and what changed since the
+// previous one
+type RevisionResponse struct {
+	Revision       int32            `json:"revision"`
+	Params         recipes.Params   `json:"params"`
+	Changes        []recipes.Change `json:"changes"`
+	Changelog      *string          `json:"changelog"`
+	RolledBackFrom *int32           `json:"rolled_back_from"`
+	CreatedBy      *string          `json:"created_by"`
+	CreatedAt      time.Time        `json:"created_at"`
+}
+
+// CreateRecipe creates a recipe owned by the current user at revision 1
+func CreateRecipe(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req CreateRecipeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		recipe, rev, pref, ok := createRecipe(c, queries, req)
+		if !ok {
+			return
+		}
+
+		respond.Created(c, newRecipeResponse(recipe, rev, pref))
+	}
+}
+
+// createRecipe validates a recipe creation payload and creates the recipe
+// for the current user. It writes an error response when the recipe is
+// rejected.
+func createRecipe(c *gin.Context, queries *db.Queries, req CreateRecipeRequest) (db.Recipe, db.RecipeRevision, units.Preference, bool) {
+	pref, ok := requestUnits(c, queries)
+	if !ok {
+		return db.Recipe{}, db.RecipeRevision{}, pref, false
+	}
+
+	doseGrams, waterGrams, waterTempC, ok := canonicalParams(c, pref, req.Dose, req.Water, req.WaterTemp)
+	if !ok {
+		return db.Recipe{}, db.RecipeRevision{}, pref, false
+	}
+	if !checkMethod(c, pref, req.BrewMethod, methods.Values{
+		DoseGrams:       doseGrams,
+		WaterGrams:      waterGrams,
+		WaterTempC:      waterTempC,
+		BrewTimeSeconds: req.BrewTimeSeconds,
+		GrindSetting:    req.GrindSetting,
+	}) {
+		return db.Recipe{}, db.RecipeRevision{}, pref, false
+	}
+
+	isPublic := true
+	if req.IsPublic != nil {
+		isPublic = *req.IsPublic
+	}
+
+	ctx := c.Request.Context()
+	recipe, err := queries.CreateRecipe(ctx, db.CreateRecipeParams{
+		ID:              ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+		OwnerID:         c.GetString("user_id"),
+		Name:            req.Name,
+		BrewMethod:      req.BrewMethod,
+		IsPublic:        isPublic,
+		DoseGrams:       doseGrams,
+		WaterGrams:      waterGrams,
+		WaterTempC:      waterTempC,
+		GrindSetting:    req.GrindSetting,
+		BrewTimeSeconds: req.BrewTimeSeconds,
+		Instructions:    req.Instructions,
+		Changelog:       req.Changelog,
+	})
+	if err != nil {
+		logger.Error("Failed to create recipe", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeRecipeCreateFailed)
+		return db.Recipe{}, db.RecipeRevision{}, pref, false
+	}
+
+	rev, err := queries.GetRecipeRevision(ctx, db.GetRecipeRevisionParams{
+		RecipeID: recipe.ID,
+		Revision: recipe.CurrentRevision,
+	})
+	if err != nil {
+		logger.Error("Failed to get recipe revision", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeRecipeFetchFailed)
+		return db.Recipe{}, db.RecipeRevision{}, pref, false
+	}
+
+	return recipe, rev, pref, true
+}
+
+// GetRecipe returns a recipe at its current revision
+func GetRecipe(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		recipe, rev, ok := loadRecipeRevision(c, queries, c.Param("id"), nil, c.GetString("user_id"))
+		if !ok {
+			return
+		}
+
+		pref, ok := requestUnits(c, queries)
+		if !ok {
+			return
+		}
+
+		respond.OK(c, newRecipeResponse(recipe, rev, pref))
+	}
+}
+
+// ReviseRecipe replaces the recipe's parameters by appending a new revision;
+// earlier revisions, and brews pinned to them, are left untouched. Owners and
+// editors may revise.
+func ReviseRecipe(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req RecipeParamsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		recipe, ok := loadRecipeWithRole(c, queries, recipeRoleOwner, recipeRoleEditor)
+		if !ok {
+			return
+		}
+
+		pref, ok := requestUnits(c, queries)
+		if !ok {
+			return
+		}
+
+		doseGrams, waterGrams, waterTempC, ok := canonicalParams(c, pref, req.Dose, req.Water, req.WaterTemp)
+		if !ok {
+			return
+		}
+		if !checkMethod(c, pref, recipe.BrewMethod, methods.Values{
+			DoseGrams:       doseGrams,
+			WaterGrams:      waterGrams,
+			WaterTempC:      waterTempC,
+			BrewTimeSeconds: req.BrewTimeSeconds,
+			GrindSetting:    req.GrindSetting,
+		}) {
+			return
+		}
+
+		userID := c.GetString("user_id")
+		createRevision(c, queries, recipe, pref, db.CreateRecipeRevisionParams{
+			RecipeID:        recipe.ID,
+			DoseGrams:       doseGrams,
+			WaterGrams:      waterGrams,
+			WaterTempC:      waterTempC,
+			GrindSetting:    req.GrindSetting,
+			BrewTimeSeconds: req.BrewTimeSeconds,
+			Instructions:    req.Instructions,
+			Changelog:       req.Changelog,
+			CreatedBy:       &userID,
+		})
+	}
+}
+
+// UpdateRecipe applies a JSON Merge Patch to a recipe, as a Create Recipe
+// payload holding its current parameters in the caller's units. Changed
+// parameters are appended as a new revision, just as Revise Recipe does;
+// the name, method and visibility aren't versioned and are updated in
+// place. Owners and editors may patch parameters; only owners may patch
+// the rest.
+func UpdateRecipe(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		recipe, ok := loadRecipeWithRole(c, queries, recipeRoleOwner, recipeRoleEditor)
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+		rev, err := queries.GetRecipeRevision(ctx, db.GetRecipeRevisionParams{
+			RecipeID: recipe.ID,
+			Revision: recipe.CurrentRevision,
+		})
+		if err != nil {
+			respondRevisionError(c, err)
+			return
+		}
+
+		pref, ok := requestUnits(c, queries)
+		if !ok {
+			return
+		}
+
+		current := revisionParams(rev, pref)
+		var req CreateRecipeRequest
+		touched, ok := bindMergePatch(c, CreateRecipeRequest{
+			Name:       recipe.Name,
+			BrewMethod: recipe.BrewMethod,
+			IsPublic:   &recipe.IsPublic,
+			RecipeParamsRequest: RecipeParamsRequest{
+				Dose:            current.Dose,
+				Water:           current.Water,
+				WaterTemp:       current.WaterTemp,
+				GrindSetting:    current.GrindSetting,
+				BrewTimeSeconds: current.BrewTimeSeconds,
+				Instructions:    current.Instructions,
+			},
+		}, &req)
+		if !ok {
+			return
+		}
+
+		isPublic := true
+		if req.IsPublic != nil {
+			isPublic = *req.IsPublic
+		}
+		userID := c.GetString("user_id")
+		metadataChanged := req.Name != recipe.Name || isPublic != recipe.IsPublic ||
+			(req.BrewMethod == nil) != (recipe.BrewMethod == nil) ||
+			(req.BrewMethod != nil && *req.BrewMethod != *recipe.BrewMethod)
+		if metadataChanged && recipe.OwnerID != userID {
+			respond.Error(c, http.StatusForbidden, i18n.CodeForbidden)
+			return
+		}
+
+		doseGrams, waterGrams, waterTempC, ok := canonicalParams(c, pref, req.Dose, req.Water, req.WaterTemp)
+		if !ok {
+			return
+		}
+		// Parameters the patch left alone keep their stored values rather
+		// than taking a round trip through the caller's units
+		if !touched["dose"] {
+			doseGrams = rev.DoseGrams
+		}
+		if !touched["water"] {
+			waterGrams = rev.WaterGrams
+		}
+		if !touched["water_temp"] {
+			waterTempC = rev.WaterTempC
+		}
+		if !checkMethod(c, pref, req.BrewMethod, methods.Values{
+			DoseGrams:       doseGrams,
+			WaterGrams:      waterGrams,
+			WaterTempC:      waterTempC,
+			BrewTimeSeconds: req.BrewTimeSeconds,
+			GrindSetting:    req.GrindSetting,
+		}) {
+			return
+		}
+
+		if metadataChanged {
+			recipe, err = queries.UpdateRecipe(ctx, db.UpdateRecipeParams{
+				Name:       req.Name,
+				BrewMethod: req.BrewMethod,
+				IsPublic:   isPublic,
+				ID:         recipe.ID,
+				OwnerID:    userID,
+			})
+			if err != nil {
+				logger.Error("Failed to update recipe", "error", err)
+				respond.Error(c, http.StatusInternalServerError, i18n.CodeRecipeUpdateFailed)
+				return
+			}
+			logger.Info("Recipe updated", "recipe_id", recipe.ID)
+		}
+
+		next := db.CreateRecipeRevisionParams{
+			RecipeID:        recipe.ID,
+			DoseGrams:       doseGrams,
+			WaterGrams:      waterGrams,
+			WaterTempC:      waterTempC,
+			GrindSetting:    req.GrindSetting,
+			BrewTimeSeconds: req.BrewTimeSeconds,
+			Instructions:    req.Instructions,
+			Changelog:       req.Changelog,
+			CreatedBy:       &userID,
+		}
+		if len(recipes.Diff(current, revisionParams(db.RecipeRevision{
+			DoseGrams:       next.DoseGrams,
+			WaterGrams:      next.WaterGrams,
+			WaterTempC:      next.WaterTempC,
+			GrindSetting:    next.GrindSetting,
+			BrewTimeSeconds: next.BrewTimeSeconds,
+			Instructions:    next.Instructions,
+		}, pref))) > 0 {
+			createRevision(c, queries, recipe, pref, next)
+			return
+		}
+
+		respond.OK(c, newRecipeResponse(recipe, rev, pref))
+	}
+}
+
+// RollbackRecipe restores an earlier revision's parameters as a new revision,
+// so the history between them is preserved. Owners and editors may roll back.
+func RollbackRecipe(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req RollbackRecipeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		recipe, ok := loadRecipeWithRole(c, queries, recipeRoleOwner, recipeRoleEditor)
+		if !ok {
+			return
+		}
+
+		target, err := queries.GetRecipeRevision(c.Request.Context(), db.GetRecipeRevisionParams{
+			RecipeID: recipe.ID,
+			Revision: req.Revision,
+		})
+		if err != nil {
+			respondRevisionError(c, err)
+			return
+		}
+
+		pref, ok := requestUnits(c, queries)
+		if !ok {
+			return
+		}
+
+		userID := c.GetString("user_id")
+		createRevision(c, queries, recipe, pref, db.CreateRecipeRevisionParams{
+			RecipeID:        recipe.ID,
+			DoseGrams:       target.DoseGrams,
+			WaterGrams:      target.WaterGrams,
+			WaterTempC:      target.WaterTempC,
+			GrindSetting:    target.GrindSetting,
+			BrewTimeSeconds: target.BrewTimeSeconds,
+			Instructions:    target.Instructions,
+			Changelog:       req.Changelog,
+			RolledBackFrom:  &target.Revision,
+			CreatedBy:       &userID,
+		})
+	}
+}
+
+// ListRecipeRevisions returns a recipe's history, newest first, with each
+// revision's changes relative to the one before it
+func ListRecipeRevisions(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		recipe, _, ok := loadVisibleRecipe(c, queries, c.Param("id"), c.GetString("user_id"))
+		if !ok {
+			return
+		}
+
+		pref, ok := requestUnits(c, queries)
+		if !ok {
+			return
+		}
+
+		revs, err := queries.ListRecipeRevisions(c.Request.Context(), recipe.ID)
+		if err != nil {
+			logger.Error("Failed to list recipe revisions", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeRecipeFetchFailed)
+			return
+		}
+
+		// Revisions are newest first, so each one's predecessor follows it
+		items := make([]RevisionResponse, len(revs))
+		for i, rev := range revs {
+			var prev *db.RecipeRevision
+			if i+1 < len(revs) {
+				prev = &revs[i+1]
+			}
+			items[i] = newRevisionResponse(rev, prev, pref)
+		}
+
+		respond.OK(c, items)
+	}
+}
+
+// GetRecipeRevision returns a single revision and its changes from the
+// previous one
+func GetRecipeRevision(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		revision, err := strconv.ParseInt(c.Param("revision"), 10, 32)
+		if err != nil || revision < 1 {
+			respond.Error(c, http.StatusNotFound, i18n.CodeRevisionNotFound)
+			return
+		}
+		pinned := int32(revision)
+
+		_, rev, ok := loadRecipeRevision(c, queries, c.Param("id"), &pinned, c.GetString("user_id"))
+		if !ok {
+			return
+		}
+
+		var prev *db.RecipeRevision
+		if rev.Revision > 1 {
+			p, err := queries.GetRecipeRevision(c.Request.Context(), db.GetRecipeRevisionParams{
+				RecipeID: rev.RecipeID,
+				Revision: rev.Revision - 1,
+			})
+			if err != nil {
+				logger.Error("Failed to get recipe revision", "error", err)
+				respond.Error(c, http.StatusInternalServerError, i18n.CodeRecipeFetchFailed)
+				return
+			}
+			prev = &p
+		}
+
+		pref, ok := requestUnits(c, queries)
+		if !ok {
+			return
+		}
+
+		respond.OK(c, newRevisionResponse(rev, prev, pref))
+	}
+}
+
+// createRevision appends a revision and writes the resulting recipe response
+func createRevision(c *gin.Context, queries *db.Queries, recipe db.Recipe, pref units.Preference, params db.CreateRecipeRevisionParams) {
+	rev, err := queries.CreateRecipeRevision(c.Request.Context(), params)
+	if err != nil {
+		logger.Error("Failed to create recipe revision", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeRecipeUpdateFailed)
+		return
+	}
+
+	logger.Info("Recipe revised", "recipe_id", recipe.ID, "revision", rev.Revision)
+
+	recipe.CurrentRevision = rev.Revision
+	respond.OK(c, newRecipeResponse(recipe, rev, pref))
+}
+
+// Roles a user can hold on a recipe. Owners and editors can revise; only
+// owners manage collaborators.
+const (
+	recipeRoleOwner  = "owner"
+	recipeRoleEditor = "editor"
+	recipeRoleViewer = "viewer"
+)
+
+// recipeRole returns userID's role on recipe, or "" when they have none
+func recipeRole(ctx context.Context, queries *db.Queries, recipe db.Recipe, userID string) (string, error) {
+	if recipe.OwnerID == userID {
+		return recipeRoleOwner, nil
+	}
+	role, err := queries.GetRecipeCollaboratorRole(ctx, db.GetRecipeCollaboratorRoleParams{
+		RecipeID: recipe.ID,
+		UserID:   userID,
+	})
+	if err == pgx.ErrNoRows {
+		return "", nil
+	}
+	return role, err
+}
+
+// loadVisibleRecipe fetches a recipe the user may view along with their role
+// on it, writing an error response otherwise. Private recipes are reported
+// as missing to anyone but the owner, collaborators, and users it's shared
+// with, who have no role.
+func loadVisibleRecipe(c *gin.Context, queries *db.Queries, recipeID, userID string) (db.Recipe, string, bool) {
+	ctx := c.Request.Context()
+	recipe, err := queries.GetVisibleRecipe(ctx, db.GetVisibleRecipeParams{
+		RecipeID: recipeID,
+		ViewerID: userID,
+	})
+	role := ""
+	if err == nil {
+		role, err = recipeRole(ctx, queries, recipe, userID)
+	}
+	if err == pgx.ErrNoRows {
+		respond.Error(c, http.StatusNotFound, i18n.CodeRecipeNotFound)
+		return recipe, role, false
+	}
+	if err != nil {
+		logger.Error("Failed to get recipe", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeRecipeFetchFailed)
+		return recipe, role, false
+	}
+	return recipe, role, true
+}
+
+// loadRecipeWithRole fetches the :id recipe for an action that requires one of
+// roles, writing 403 when the user can see the recipe but lacks the role
+func loadRecipeWithRole(c *gin.Context, queries *db.Queries, roles ...string) (db.Recipe, bool) {
+	recipe, role, ok := loadVisibleRecipe(c, queries, c.Param("id"), c.GetString("user_id"))
+	if !ok {
+		return recipe, false
+	}
+	for _, allowed := range roles {
+		if role == allowed {
+			return recipe, true
+		}
+	}
+	respond.Error(c, http.StatusForbidden, i18n.CodeForbidden)
+	return recipe, false
+}
+
+// loadRecipeRevision fetches a visible recipe and one of its revisions, the
+// current one when revision is nil
+func loadRecipeRevision(c *gin.Context, queries *db.Queries, recipeID string, revision *int32, userID string) (db.Recipe, db.RecipeRevision, bool) {
+	recipe, _, ok := loadVisibleRecipe(c, queries, recipeID, userID)
+	if !ok {
+		return recipe, db.RecipeRevision{}, false
+	}
+
+	pinned := recipe.CurrentRevision
+	if revision != nil {
+		pinned = *revision
+	}
+
+	rev, err := queries.GetRecipeRevision(c.Request.Context(), db.GetRecipeRevisionParams{
+		RecipeID: recipe.ID,
+		Revision: pinned,
+	})
+	if err != nil {
+		respondRevisionError(c, err)
+		return recipe, rev, false
+	}
+	return recipe, rev, true
+}
+
+// respondRevisionError writes the response for a failed revision lookup
+func respondRevisionError(c *gin.Context, err error) {
+	if err == pgx.ErrNoRows {
+		respond.Error(c, http.StatusNotFound, i18n.CodeRevisionNotFound)
+		return
+	}
+	logger.Error("Failed to get recipe revision", "error", err)
+	respond.Error(c, http.StatusInternalServerError, i18n.CodeRecipeFetchFailed)
+}
+
+// revisionParams converts a stored revision's parameters to the caller's units
+func revisionParams(rev db.RecipeRevision, pref units.Preference) recipes.Params {
+	params := recipes.Params{
+		GrindSetting:    rev.GrindSetting,
+		BrewTimeSeconds: rev.BrewTimeSeconds,
+		Instructions:    rev.Instructions,
+	}
+	if rev.DoseGrams != nil {
+		v := units.FromGrams(*rev.DoseGrams, pref.Weight)
+		params.Dose = &v
+	}
+	if rev.WaterGrams != nil {
+		v := units.FromGrams(*rev.WaterGrams, pref.Weight)
+		params.Water = &v
+	}
+	if rev.WaterTempC != nil {
+		v := units.FromCelsius(*rev.WaterTempC, pref.Temperature)
+		params.WaterTemp = &v
+	}
+	return params
+}
+
+// newRecipeResponse builds a recipe response around the given revision
+func newRecipeResponse(recipe db.Recipe, rev db.RecipeRevision, pref units.Preference) RecipeResponse {
+	var forkedFrom *ForkSource
+	if recipe.ForkedFromID != nil {
+		forkedFrom = &ForkSource{RecipeID: *recipe.ForkedFromID, Revision: recipe.ForkedFromRevision}
+	}
+
+	return RecipeResponse{
+		ID:              recipe.ID,
+		OwnerID:         recipe.OwnerID,
+		Name:            recipe.Name,
+		BrewMethod:      recipe.BrewMethod,
+		IsPublic:        recipe.IsPublic,
+		CurrentRevision: recipe.CurrentRevision,
+		ForkedFrom:      forkedFrom,
+		Params:          revisionParams(rev, pref),
+		Units:           pref,
+		CreatedAt:       recipe.CreatedAt,
+		UpdatedAt:       recipe.UpdatedAt,
+	}
+}
+
+// newRevisionResponse builds a revision response, diffed against prev (nil
+// for the first revision, whose changes are everything it sets)
+func newRevisionResponse(rev db.RecipeRevision, prev *db.RecipeRevision, pref units.Preference) RevisionResponse {
+	params := revisionParams(rev, pref)
+
+	var before recipes.Params
+	if prev != nil {
+		before = revisionParams(*prev, pref)
+	}
+
+	return RevisionResponse{
+		Revision:       rev.Revision,
+		Params:         params,
+		Changes:        recipes.Diff(before, params),
+		Changelog:      rev.Changelog,
+		RolledBackFrom: rev.RolledBackFrom,
+		CreatedBy:      rev.CreatedBy,
+		CreatedAt:      rev.CreatedAt,
+	}
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecommendationsQuery represents the recommendations query parameters
+type RecommendationsQuery struct {
+	Limit int32 `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+// NotInterestedRequest represents "not interested" feedback on a
+// recommended recipe or bean
+type NotInterestedRequest struct {
+	Kind string `json:"kind" binding:"required,oneof=recipe bean"`
+	ID   string `json:"id" binding:"required,ulid"`
+}
+
+// RecommendedRecipe is a public recipe suggested to the current user.
+// Score ranks suggestions; it has no meaning on its own.
+type RecommendedRecipe struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	BrewMethod    *string `json:"brew_method"`
+	OwnerUsername string  `json:"owner_username"`
+	Score         float64 `json:"score"`
+}
+
+// RecommendedBean is a catalogue bean suggested to the current user
+type RecommendedBean struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	RoasterName   *string `json:"roaster_name"`
+	BeanOrigin    *string `json:"bean_origin"`
+	OriginCountry *string `json:"origin_country"`
+	Process       *string `json:"process"`
+	Variety       *string `json:"variety"`
+	Score         float64 `json:"score"`
+}
+
+// RecommendationsResponse represents the current user's recommendations.
+// ComputedAt is when they were scored, nil before the first scoring.
+type RecommendationsResponse struct {
+	ComputedAt *time.Time          `json:"computed_at"`
+	Recipes    []RecommendedRecipe `json:"recipes"`
+	Beans      []RecommendedBean   `json:"beans"`
+}
+
+// GetRecommendations returns the recipes and beans suggested to the current
+// user from their ratings and flavor notes, as last scored by the
+// recommendation worker
+func GetRecommendations(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query RecommendationsQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if query.Limit == 0 {
+			query.Limit = defaultPageLimit
+		}
+
+		ctx := c.Request.Context()
+		userID := c.GetString("user_id")
+		recipes, err := queries.ListRecipeRecommendations(ctx, db.ListRecipeRecommendationsParams{
+			UserID:   userID,
+			RowLimit: query.Limit,
+		})
+		if err != nil {
+			logger.Error("Failed to list recipe recommendations", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeRecommendationFetchFailed)
+			return
+		}
+		beans, err := queries.ListBeanRecommendations(ctx, db.ListBeanRecommendationsParams{
+			UserID:   userID,
+			RowLimit: query.Limit,
+		})
+		if err != nil {
+			logger.Error("Failed to list bean recommendations", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeRecommendationFetchFailed)
+			return
+		}
+
+		resp := RecommendationsResponse{
+			Recipes: make([]RecommendedRecipe, 0, len(recipes)),
+			Beans:   make([]RecommendedBean, 0, len(beans)),
+		}
+		for _, r := range recipes {
+			resp.ComputedAt = timePtr(r.ComputedAt)
+			resp.Recipes = append(resp.Recipes, RecommendedRecipe{
+				ID:            r.ID,
+				Name:          r.Name,
+				BrewMethod:    r.BrewMethod,
+				OwnerUsername: r.OwnerUsername,
+				Score:         r.Score,
+			})
+		}
+		for _, b := range beans {
+			resp.ComputedAt = timePtr(b.ComputedAt)
+			resp.Beans = append(resp.Beans, RecommendedBean{
+				ID:            b.ID,
+				Name:          b.Name,
+				RoasterName:   b.RoasterName,
+				BeanOrigin:    b.BeanOrigin,
+				OriginCountry: b.OriginCountry,
+				Process:       b.Process,
+				Variety:       b.Variety,
+				Score:         b.Score,
+			})
+		}
+
+		respond.OK(c, resp)
+	}
+}
+
+// MarkNotInterested removes a recipe or bean from the current user's
+// recommendations and keeps it from being recommended to them again
+func MarkNotInterested(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req NotInterestedRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		userID := c.GetString("user_id")
+		if err := queries.DismissRecommendation(c.Request.Context(), db.DismissRecommendationParams{
+			UserID: userID,
+			Kind:   req.Kind,
+			ItemID: req.ID,
+		}); err != nil {
+			logger.Error("Failed to dismiss recommendation", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeRecommendationUpdateFailed)
+			return
+		}
+
+		respond.OK(c, nil)
+	}
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+	"brewd/internal/retention"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RetentionClassResponse is a data class's retention period, 0 when it is
+// kept forever, and how many of its rows are past it
+type RetentionClassResponse struct {
+	Class         string `json:"class"`
+	RetentionDays int    `json:"retention_days"`
+	Expired       int64  `json:"expired"`
+}
+
+// RetentionReportResponse is the admin retention report. With dry_run set
+// the retention job only logs what it would purge.
+type RetentionReportResponse struct {
+	DryRun  bool                     `json:"dry_run"`
+	Classes []RetentionClassResponse `json:"classes"`
+}
+
+// AdminGetRetention reports each data class's retention period and the rows
+// the next retention run would purge, counted live without deleting any
+func AdminGetRetention(job *retention.Job) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reports, err := job.Report(c.Request.Context())
+		if err != nil {
+			logger.Error("Failed to report retention", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeRetentionFetchFailed)
+			return
+		}
+
+		classes := make([]RetentionClassResponse, 0, len(reports))
+		for _, r := range reports {
+			days := r.Days
+			if days < 0 {
+				days = 0
+			}
+			classes = append(classes, RetentionClassResponse{
+				Class:         r.Class,
+				RetentionDays: days,
+				Expired:       r.Expired,
+			})
+		}
+		respond.OK(c, RetentionReportResponse{DryRun: job.DryRun(), Classes: classes})
+	}
+}
@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"net/http"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/oklog/ulid/v2"
+)
+
+// RoasterRequest represents the roaster account creation payload
+type RoasterRequest struct {
+	Name        string  `json:"name" binding:"required,max=100"`
+	Website     *string `json:"website" binding:"omitempty,url,max=500"`
+	Location    *string `json:"location" binding:"omitempty,max=255"`
+	Description *string `json:"description" binding:"omitempty,max=2000"`
+}
+
+// UpdateRoasterRequest represents the roaster update payload; omitted
+// fields are left unchanged
+type UpdateRoasterRequest struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Website     *string `json:"website" binding:"omitempty,url,max=500"`
+	Location    *string `json:"location" binding:"omitempty,max=255"`
+	Description *string `json:"description" binding:"omitempty,max=2000"`
+}
+
+// RoasterQuery represents the roaster directory query parameters
+type RoasterQuery struct {
+	PageQuery
+	Verified bool `form:"verified"`
+}
+
+// RoasterResponse is a roaster account
+type RoasterResponse struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"user_id"`
+	Name        string     `json:"name"`
+	Website     *string    `json:"website"`
+	Location    *string    `json:"location"`
+	Description *string    `json:"description"`
+	Verified    bool       `json:"verified"`
+	VerifiedAt  *time.Time `json:"verified_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// RoasterSummary is a roaster in the directory
+type RoasterSummary struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Location  *string `json:"location"`
+	Verified  bool    `json:"verified"`
+	BeanCount int64   `json:"bean_count"`
+}
+
+// CreateRoaster sets up a roaster account for the current user. Accounts
+// start unverified until an admin verifies them.
+func CreateRoaster(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req RoasterRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		ctx := c.Request.Context()
+		userID := c.GetString("user_id")
+		_, err := queries.GetRoasterByUserID(ctx, userID)
+		if err == nil {
+			respond.Error(c, http.StatusConflict, i18n.CodeRoasterExists)
+			return
+		}
+		if err != pgx.ErrNoRows {
+			logger.Error("Failed to get roaster", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeRoasterUpdateFailed)
+			return
+		}
+
+		roaster, err := queries.CreateRoaster(ctx, db.CreateRoasterParams{
+			ID:          ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+			UserID:      userID,
+			Name:        req.Name,
+			Website:     req.Website,
+			Location:    req.Location,
+			Description: req.Description,
+		})
+		if err != nil {
+			respondRoasterSaveError(c, err)
+			return
+		}
+
+		respond.Created(c, newRoasterResponse(roaster))
+	}
+}
+
+// ListRoasters returns roasters by name, only verified ones with
+// ?verified=true
+func ListRoasters(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query RoasterQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if query.Limit == 0 {
+			query.Limit = defaultPageLimit
+		}
+
+		rows, err := queries.ListRoasters(c.Request.Context(), db.ListRoastersParams{
+			VerifiedOnly: query.Verified,
+			RowLimit:     query.Limit,
+			RowOffset:    query.Offset,
+		})
+		if err != nil {
+			logger.Error("Failed to list roasters", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeRoasterFetchFailed)
+			return
+		}
+
+		summaries := make([]RoasterSummary, 0, len(rows))
+		for _, row := range rows {
+			summaries = append(summaries, RoasterSummary{
+				ID:        row.ID,
+				Name:      row.Name,
+				Location:  row.Location,
+				Verified:  row.VerifiedAt.Valid,
+				BeanCount: row.BeanCount,
+			})
+		}
+
+		respond.Page(c, summaries, query.Limit, query.Offset, len(summaries))
+	}
+}
+
+// GetRoaster returns a roaster account
+func GetRoaster(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roaster, ok := loadRoaster(c, queries, false)
+		if !ok {
+			return
+		}
+
+		respond.OK(c, newRoasterResponse(roaster))
+	}
+}
+
+// GetMyRoaster returns the current user's roaster account
+func GetMyRoaster(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roaster, err := queries.GetRoasterByUserID(c.Request.Context(), c.GetString("user_id"))
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusNotFound, i18n.CodeRoasterNotFound)
+				return
+			}
+			logger.Error("Failed to get roaster", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeRoasterFetchFailed)
+			return
+		}
+
+		respond.OK(c, newRoasterResponse(roaster))
+	}
+}
+
+// UpdateRoaster edits the current user's roaster account. Renaming it
+// clears its verification.
+func UpdateRoaster(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req UpdateRoasterRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		roaster, ok := loadRoaster(c, queries, true)
+		if !ok {
+			return
+		}
+
+		roaster, err := queries.UpdateRoaster(c.Request.Context(), db.UpdateRoasterParams{
+			Name:        req.Name,
+			Website:     req.Website,
+			Location:    req.Location,
+			Description: req.Description,
+			ID:          roaster.ID,
+		})
+		if err != nil {
+			respondRoasterSaveError(c, err)
+			return
+		}
+
+		respond.OK(c, newRoasterResponse(roaster))
+	}
+}
+
+// AdminVerifyRoaster marks a roaster as verified
+func AdminVerifyRoaster(queries *db.Queries) gin.HandlerFunc {
+	return setRoasterVerified(queries, true)
+}
+
+// AdminUnverifyRoaster revokes a roaster's verification. Its official
+// listings stay, but it can't publish new ones.
+func AdminUnverifyRoaster(queries *db.Queries) gin.HandlerFunc {
+	return setRoasterVerified(queries, false)
+}
+
+func setRoasterVerified(queries *db.Queries, verified bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roaster, err := queries.SetRoasterVerified(c.Request.Context(), db.SetRoasterVerifiedParams{
+			Verified: verified,
+			ID:       c.Param("id"),
+		})
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusNotFound, i18n.CodeRoasterNotFound)
+				return
+			}
+			logger.Error("Failed to set roaster verification", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeRoasterUpdateFailed)
+			return
+		}
+
+		logger.Info("Roaster verification changed", "roaster_id", roaster.ID, "verified", verified, "admin_id", c.GetString("user_id"))
+		respond.OK(c, newRoasterResponse(roaster))
+	}
+}
+
+// loadRoaster fetches the :id roaster, writing an error response otherwise.
+// With ownerOnly, roasters the current user doesn't manage get 403.
+func loadRoaster(c *gin.Context, queries *db.Queries, ownerOnly bool) (db.Roaster, bool) {
+	roaster, err := queries.GetRoasterByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			respond.Error(c, http.StatusNotFound, i18n.CodeRoasterNotFound)
+			return roaster, false
+		}
+		logger.Error("Failed to get roaster", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeRoasterFetchFailed)
+		return roaster, false
+	}
+	if ownerOnly && roaster.UserID != c.GetString("user_id") {
+		respond.Error(c, http.StatusForbidden, i18n.CodeForbidden)
+		return roaster, false
+	}
+	return roaster, true
+}
+
+// respondRoasterSaveError writes the response for a failed roaster insert
+// or update
+func respondRoasterSaveError(c *gin.Context, err error) {
+	if isUniqueViolation(err) {
+		respond.Error(c, http.StatusConflict, i18n.CodeRoasterNameTaken)
+		return
+	}
+	logger.Error("Failed to save roaster", "error", err)
+	respond.Error(c, http.StatusInternalServerError, i18n.CodeRoasterUpdateFailed)
+}
+
+func newRoasterResponse(roaster db.Roaster) RoasterResponse {
+	return RoasterResponse{
+		ID:          roaster.ID,
+		UserID:      roaster.UserID,
+		Name:        roaster.Name,
+		Website:     roaster.Website,
+		Location:    roaster.Location,
+		Description: roaster.Description,
+		Verified:    roaster.VerifiedAt.Valid,
+		VerifiedAt:  timePtr(roaster.VerifiedAt),
+		CreatedAt:   roaster.CreatedAt,
+	}
+}
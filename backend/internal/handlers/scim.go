@@ -0,0 +1,495 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"brewd/internal/accounts"
+	"brewd/internal/auth"
+	"brewd/internal/db"
+	"brewd/internal/logger"
+	"brewd/internal/scim"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/oklog/ulid/v2"
+)
+
+// minSCIMPasswordLength matches the minimum registration accepts
+const minSCIMPasswordLength = 8
+
+// SCIMServiceProviderConfig describes the SCIM features brewd supports
+func SCIMServiceProviderConfig() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writeSCIM(c, http.StatusOK, scim.Config())
+	}
+}
+
+// SCIMResourceTypes lists the resources brewd provisions
+func SCIMResourceTypes() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		types := scim.ResourceTypes()
+		writeSCIM(c, http.StatusOK, scim.ListResponse{
+			Schemas:      []string{scim.SchemaListResponse},
+			TotalResults: len(types),
+			StartIndex:   1,
+			ItemsPerPage: len(types),
+			Resources:    types,
+		})
+	}
+}
+
+// SCIMListUsers returns a page of the accounts the client provisioned,
+// optionally filtered to one userName, externalId or email address
+func SCIMListUsers(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clientID := c.GetString("scim_client_id")
+		startIndex, err := strconv.Atoi(c.DefaultQuery("startIndex", "1"))
+		if err != nil {
+			writeSCIMError(c, scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue, "startIndex must be a number"))
+			return
+		}
+		count, err := strconv.Atoi(c.DefaultQuery("count", strconv.Itoa(scim.DefaultCount)))
+		if err != nil {
+			writeSCIMError(c, scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue, "count must be a number"))
+			return
+		}
+		startIndex = max(startIndex, 1)
+		count = min(max(count, 0), scim.MaxResults)
+
+		params := db.ListSCIMUsersParams{
+			ClientID:  clientID,
+			RowLimit:  int32(count),
+			RowOffset: int32(startIndex - 1),
+		}
+		if raw := c.Query("filter"); raw != "" {
+			filter, scimErr := scim.ParseFilter(raw)
+			if scimErr != nil {
+				writeSCIMError(c, scimErr)
+				return
+			}
+			switch filter.Attribute {
+			case scim.FilterUserName:
+				params.Username = &filter.Value
+			case scim.FilterExternalID:
+				params.ExternalID = &filter.Value
+			case scim.FilterEmail:
+				params.Email = &filter.Value
+			}
+		}
+
+		ctx := c.Request.Context()
+		total, err := queries.CountSCIMUsers(ctx, db.CountSCIMUsersParams{
+			ClientID:   params.ClientID,
+			Username:   params.Username,
+			ExternalID: params.ExternalID,
+			Email:      params.Email,
+		})
+		if err != nil {
+			logger.Error("Failed to count SCIM users", "scim_client_id", clientID, "error", err)
+			writeSCIMError(c, scimInternalError)
+			return
+		}
+		var rows []db.ListSCIMUsersRow
+		if count > 0 {
+			rows, err = queries.ListSCIMUsers(ctx, params)
+			if err != nil {
+				logger.Error("Failed to list SCIM users", "scim_client_id", clientID, "error", err)
+				writeSCIMError(c, scimInternalError)
+				return
+			}
+		}
+
+		users := make([]scim.User, 0, len(rows))
+		for _, row := range rows {
+			users = append(users, newSCIMUser(db.GetSCIMUserRow(row)))
+		}
+		writeSCIM(c, http.StatusOK, scim.ListResponse{
+			Schemas:      []string{scim.SchemaListResponse},
+			TotalResults: int(total),
+			StartIndex:   startIndex,
+			ItemsPerPage: len(users),
+			Resources:    users,
+		})
+	}
+}
+
+// SCIMGetUser returns one of the accounts the client provisioned
+func SCIMGetUser(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		row, scimErr := getSCIMUser(c.Request.Context(), queries, c.GetString("scim_client_id"), c.Param("id"))
+		if scimErr != nil {
+			writeSCIMError(c, scimErr)
+			return
+		}
+		writeSCIM(c, http.StatusOK, newSCIMUser(row))
+	}
+}
+
+// SCIMCreateUser provisions an account. Without a password the student
+// can't log in until the client sets one.
+func SCIMCreateUser(queries *db.Queries, bcryptCost int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var user scim.User
+		if err := c.ShouldBindJSON(&user); err != nil {
+			writeSCIMError(c, scimSyntaxError)
+			return
+		}
+		row, scimErr := createSCIMUser(c.Request.Context(), queries, c.GetString("scim_client_id"), bcryptCost, user)
+		if scimErr != nil {
+			writeSCIMError(c, scimErr)
+			return
+		}
+		c.Header("Location", scim.UserLocation(row.ID))
+		writeSCIM(c, http.StatusCreated, newSCIMUser(row))
+	}
+}
+
+// SCIMReplaceUser replaces an account's attributes; a missing password
+// keeps the current one
+func SCIMReplaceUser(queries *db.Queries, activeCache *accounts.Cache, bcryptCost int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var user scim.User
+		if err := c.ShouldBindJSON(&user); err != nil {
+			writeSCIMError(c, scimSyntaxError)
+			return
+		}
+		row, scimErr := updateSCIMUser(c.Request.Context(), queries, activeCache, c.GetString("scim_client_id"), c.Param("id"), bcryptCost, user)
+		if scimErr != nil {
+			writeSCIMError(c, scimErr)
+			return
+		}
+		writeSCIM(c, http.StatusOK, newSCIMUser(row))
+	}
+}
+
+// SCIMPatchUser changes some of an account's attributes, e.g. setting
+// active to false to deactivate it
+func SCIMPatchUser(queries *db.Queries, activeCache *accounts.Cache, bcryptCost int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var patch scim.PatchRequest
+		if err := c.ShouldBindJSON(&patch); err != nil {
+			writeSCIMError(c, scimSyntaxError)
+			return
+		}
+		row, scimErr := patchSCIMUser(c.Request.Context(), queries, activeCache, c.GetString("scim_client_id"), c.Param("id"), bcryptCost, patch)
+		if scimErr != nil {
+			writeSCIMError(c, scimErr)
+			return
+		}
+		writeSCIM(c, http.StatusOK, newSCIMUser(row))
+	}
+}
+
+// SCIMDeleteUser deprovisions an account, deleting it and everything in it
+func SCIMDeleteUser(queries *db.Queries, activeCache *accounts.Cache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if scimErr := deleteSCIMUser(c.Request.Context(), queries, activeCache, c.GetString("scim_client_id"), c.Param("id")); scimErr != nil {
+			writeSCIMError(c, scimErr)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// SCIMBulk processes a batch of user operations in order, so a program
+// can provision or deprovision a class in one request. Each operation
+// succeeds or fails on its own; processing stops once failOnErrors
+// operations have failed.
+func SCIMBulk(queries *db.Queries, activeCache *accounts.Cache, bcryptCost int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, scim.MaxBulkBytes)
+
+		var req scim.BulkRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				writeSCIMError(c, scim.NewError(http.StatusRequestEntityTooLarge, "",
+					fmt.Sprintf("bulk requests are limited to %d bytes", scim.MaxBulkBytes)))
+				return
+			}
+			writeSCIMError(c, scimSyntaxError)
+			return
+		}
+		if len(req.Operations) > scim.MaxBulkOperations {
+			writeSCIMError(c, scim.NewError(http.StatusRequestEntityTooLarge, "",
+				fmt.Sprintf("bulk requests are limited to %d operations", scim.MaxBulkOperations)))
+			return
+		}
+
+		ctx := c.Request.Context()
+		clientID := c.GetString("scim_client_id")
+		results := make([]scim.BulkResult, 0, len(req.Operations))
+		failed := 0
+		for _, op := range req.Operations {
+			result := runSCIMBulkOperation(ctx, queries, activeCache, clientID, bcryptCost, op)
+			results = append(results, result)
+			if result.Response != nil {
+				failed++
+				if req.FailOnErrors > 0 && failed >= req.FailOnErrors {
+					break
+				}
+			}
+		}
+
+		writeSCIM(c, http.StatusOK, scim.BulkResponse{
+			Schemas:    []string{scim.SchemaBulkResponse},
+			Operations: results,
+		})
+	}
+}
+
+// runSCIMBulkOperation runs one operation of a bulk request
+func runSCIMBulkOperation(ctx context.Context, queries *db.Queries, activeCache *accounts.Cache, clientID string, bcryptCost int, op scim.BulkOperation) scim.BulkResult {
+	result := scim.BulkResult{Method: op.Method, BulkID: op.BulkID}
+	fail := func(scimErr *scim.Error) scim.BulkResult {
+		result.Status = scimErr.Status
+		result.Response = scimErr
+		return result
+	}
+
+	method := strings.ToUpper(op.Method)
+	id, hasID := strings.CutPrefix(op.Path, "/Users/")
+	if (method == http.MethodPost && op.Path != "/Users") || (method != http.MethodPost && (!hasID || id == "")) {
+		return fail(scim.NewError(http.StatusBadRequest, scim.ErrorInvalidPath,
+			fmt.Sprintf("%s %s is not a supported operation", op.Method, op.Path)))
+	}
+
+	var row db.GetSCIMUserRow
+	var scimErr *scim.Error
+	status := http.StatusOK
+	switch method {
+	case http.MethodPost:
+		var user scim.User
+		if err := json.Unmarshal(op.Data, &user); err != nil {
+			return fail(scimSyntaxError)
+		}
+		row, scimErr = createSCIMUser(ctx, queries, clientID, bcryptCost, user)
+		status = http.StatusCreated
+	case http.MethodPut:
+		var user scim.User
+		if err := json.Unmarshal(op.Data, &user); err != nil {
+			return fail(scimSyntaxError)
+		}
+		row, scimErr = updateSCIMUser(ctx, queries, activeCache, clientID, id, bcryptCost, user)
+	case http.MethodPatch:
+		var patch scim.PatchRequest
+		if err := json.Unmarshal(op.Data, &patch); err != nil {
+			return fail(scimSyntaxError)
+		}
+		row, scimErr = patchSCIMUser(ctx, queries, activeCache, clientID, id, bcryptCost, patch)
+	case http.MethodDelete:
+		scimErr = deleteSCIMUser(ctx, queries, activeCache, clientID, id)
+		status = http.StatusNoContent
+	default:
+		return fail(scim.NewError(http.StatusMethodNotAllowed, "", fmt.Sprintf("unsupported method %q", op.Method)))
+	}
+	if scimErr != nil {
+		return fail(scimErr)
+	}
+
+	if method == http.MethodDelete {
+		result.Location = scim.UserLocation(id)
+	} else {
+		result.Location = scim.UserLocation(row.ID)
+	}
+	result.Status = strconv.Itoa(status)
+	return result
+}
+
+// Common SCIM errors
+var (
+	scimSyntaxError   = scim.NewError(http.StatusBadRequest, scim.ErrorInvalidSyntax, "the request body is not a valid SCIM message")
+	scimNotFoundError = scim.NewError(http.StatusNotFound, "", "user not found")
+	scimConflictError = scim.NewError(http.StatusConflict, scim.ErrorUniqueness, "userName, email or externalId is already taken")
+	scimInternalError = scim.NewError(http.StatusInternalServerError, "", "internal error")
+)
+
+func getSCIMUser(ctx context.Context, queries *db.Queries, clientID, id string) (db.GetSCIMUserRow, *scim.Error) {
+	row, err := queries.GetSCIMUser(ctx, db.GetSCIMUserParams{ClientID: clientID, ID: id})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return row, scimNotFoundError
+		}
+		logger.Error("Failed to get SCIM user", "scim_client_id", clientID, "user_id", id, "error", err)
+		return row, scimInternalError
+	}
+	return row, nil
+}
+
+// scimAccount is a SCIM user's attributes, checked and normalized like a
+// registration's
+type scimAccount struct {
+	username     string
+	email        string
+	passwordHash *string
+	active       bool
+}
+
+// newSCIMAccount checks and normalizes user's attributes, hashing its
+// password if it has one
+func newSCIMAccount(user scim.User, bcryptCost int) (scimAccount, *scim.Error) {
+	username, err := auth.NormalizeUsername(user.UserName)
+	if err != nil {
+		return scimAccount{}, scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue, "userName: "+err.Error())
+	}
+	email, err := auth.NormalizeEmail(user.PrimaryEmail())
+	if err != nil {
+		return scimAccount{}, scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue, "emails: a valid email address is required")
+	}
+	account := scimAccount{username: username, email: email, active: user.IsActive()}
+
+	if user.Password != "" {
+		if len(user.Password) < minSCIMPasswordLength {
+			return scimAccount{}, scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue,
+				fmt.Sprintf("password must be at least %d characters", minSCIMPasswordLength))
+		}
+		hash, err := auth.HashPassword(user.Password, bcryptCost)
+		if err != nil {
+			logger.Error("Failed to hash SCIM user password", "error", err)
+			return scimAccount{}, scimInternalError
+		}
+		account.passwordHash = &hash
+	}
+	return account, nil
+}
+
+// createSCIMUser provisions an account for the client
+func createSCIMUser(ctx context.Context, queries *db.Queries, clientID string, bcryptCost int, user scim.User) (db.GetSCIMUserRow, *scim.Error) {
+	account, scimErr := newSCIMAccount(user, bcryptCost)
+	if scimErr != nil {
+		return db.GetSCIMUserRow{}, scimErr
+	}
+	if account.passwordHash == nil {
+		// No one knows this password, so the account can't log in until
+		// the client sets one
+		hash, err := auth.HashPassword(rand.Text(), bcryptCost)
+		if err != nil {
+			logger.Error("Failed to hash SCIM user password", "error", err)
+			return db.GetSCIMUserRow{}, scimInternalError
+		}
+		account.passwordHash = &hash
+	}
+
+	row, err := queries.CreateSCIMUser(ctx, db.CreateSCIMUserParams{
+		ID:           ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+		Username:     account.username,
+		Email:        account.email,
+		PasswordHash: *account.passwordHash,
+		Active:       account.active,
+		ClientID:     clientID,
+		ExternalID:   user.ExternalID,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return db.GetSCIMUserRow{}, scimConflictError
+		}
+		logger.Error("Failed to create SCIM user", "scim_client_id", clientID, "error", err)
+		return db.GetSCIMUserRow{}, scimInternalError
+	}
+
+	logger.Info("SCIM user provisioned", "scim_client_id", clientID, "user_id", row.ID)
+	return db.GetSCIMUserRow(row), nil
+}
+
+// updateSCIMUser replaces the attributes of one of the client's accounts
+func updateSCIMUser(ctx context.Context, queries *db.Queries, activeCache *accounts.Cache, clientID, id string, bcryptCost int, user scim.User) (db.GetSCIMUserRow, *scim.Error) {
+	account, scimErr := newSCIMAccount(user, bcryptCost)
+	if scimErr != nil {
+		return db.GetSCIMUserRow{}, scimErr
+	}
+
+	row, err := queries.UpdateSCIMUser(ctx, db.UpdateSCIMUserParams{
+		ExternalID:   user.ExternalID,
+		ClientID:     clientID,
+		ID:           id,
+		Username:     account.username,
+		Email:        account.email,
+		PasswordHash: account.passwordHash,
+		Active:       account.active,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.GetSCIMUserRow{}, scimNotFoundError
+		}
+		if isUniqueViolation(err) {
+			return db.GetSCIMUserRow{}, scimConflictError
+		}
+		logger.Error("Failed to update SCIM user", "scim_client_id", clientID, "user_id", id, "error", err)
+		return db.GetSCIMUserRow{}, scimInternalError
+	}
+
+	// Tokens the user holds stop working here at once, and on other
+	// instances once their cached answer expires
+	activeCache.Forget(id)
+	if !account.active {
+		logger.Info("SCIM user deactivated", "scim_client_id", clientID, "user_id", id)
+	}
+	return db.GetSCIMUserRow(row), nil
+}
+
+// patchSCIMUser applies a patch to the current state of one of the
+// client's accounts
+func patchSCIMUser(ctx context.Context, queries *db.Queries, activeCache *accounts.Cache, clientID, id string, bcryptCost int, patch scim.PatchRequest) (db.GetSCIMUserRow, *scim.Error) {
+	row, scimErr := getSCIMUser(ctx, queries, clientID, id)
+	if scimErr != nil {
+		return row, scimErr
+	}
+	user := newSCIMUser(row)
+	if scimErr := patch.Apply(&user); scimErr != nil {
+		return row, scimErr
+	}
+	return updateSCIMUser(ctx, queries, activeCache, clientID, id, bcryptCost, user)
+}
+
+// deleteSCIMUser deletes one of the client's accounts
+func deleteSCIMUser(ctx context.Context, queries *db.Queries, activeCache *accounts.Cache, clientID, id string) *scim.Error {
+	rows, err := queries.DeleteSCIMUser(ctx, db.DeleteSCIMUserParams{ID: id, ClientID: clientID})
+	if err != nil {
+		logger.Error("Failed to delete SCIM user", "scim_client_id", clientID, "user_id", id, "error", err)
+		return scimInternalError
+	}
+	if rows == 0 {
+		return scimNotFoundError
+	}
+	activeCache.Forget(id)
+
+	logger.Info("SCIM user deprovisioned", "scim_client_id", clientID, "user_id", id)
+	return nil
+}
+
+func newSCIMUser(row db.GetSCIMUserRow) scim.User {
+	active := !row.DeactivatedAt.Valid
+	return scim.User{
+		Schemas:    []string{scim.SchemaUser},
+		ID:         row.ID,
+		ExternalID: row.ExternalID,
+		UserName:   row.Username,
+		Emails:     []scim.Email{{Value: row.Email, Primary: true}},
+		Active:     &active,
+		Meta: &scim.Meta{
+			ResourceType: "User",
+			Created:      &row.CreatedAt,
+			LastModified: &row.UpdatedAt,
+			Location:     scim.UserLocation(row.ID),
+		},
+	}
+}
+
+// writeSCIM sends a SCIM response
+func writeSCIM(c *gin.Context, status int, body any) {
+	c.Header("Content-Type", scim.ContentType)
+	c.JSON(status, body)
+}
+
+// writeSCIMError sends a SCIM error response
+func writeSCIMError(c *gin.Context, err *scim.Error) {
+	writeSCIM(c, err.StatusCode(), err)
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"net/http"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+	"brewd/internal/scim"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oklog/ulid/v2"
+)
+
+// SCIMClientRequest registers a training program's identity system
+type SCIMClientRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// SCIMClientResponse is a registered SCIM client. Token is only set when
+// the client is created.
+type SCIMClientResponse struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Token       string     `json:"token,omitempty"`
+	TokenPrefix string     `json:"token_prefix"`
+	UserCount   int64      `json:"user_count"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// AdminCreateSCIMClient registers an identity system that provisions
+// student accounts. Its token is returned once; only the hash is kept.
+func AdminCreateSCIMClient(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req SCIMClientRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		token, prefix, hash, err := scim.NewToken()
+		if err != nil {
+			logger.Error("Failed to generate SCIM client token", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeSCIMClientUpdateFailed)
+			return
+		}
+
+		client, err := queries.CreateSCIMClient(c.Request.Context(), db.CreateSCIMClientParams{
+			ID:          ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+			Name:        req.Name,
+			TokenPrefix: prefix,
+			TokenHash:   hash,
+		})
+		if err != nil {
+			logger.Error("Failed to create SCIM client", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeSCIMClientUpdateFailed)
+			return
+		}
+
+		respond.Created(c, SCIMClientResponse{
+			ID:          client.ID,
+			Name:        client.Name,
+			Token:       token,
+			TokenPrefix: client.TokenPrefix,
+			CreatedAt:   client.CreatedAt,
+		})
+	}
+}
+
+// AdminListSCIMClients returns every registered client with how many
+// accounts it provisioned, newest first, without their tokens
+func AdminListSCIMClients(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clients, err := queries.ListSCIMClients(c.Request.Context())
+		if err != nil {
+			logger.Error("Failed to list SCIM clients", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeSCIMClientFetchFailed)
+			return
+		}
+
+		data := make([]SCIMClientResponse, 0, len(clients))
+		for _, client := range clients {
+			data = append(data, SCIMClientResponse{
+				ID:          client.ID,
+				Name:        client.Name,
+				TokenPrefix: client.TokenPrefix,
+				UserCount:   client.UserCount,
+				LastUsedAt:  timePtr(client.LastUsedAt),
+				CreatedAt:   client.CreatedAt,
+			})
+		}
+
+		respond.OK(c, data)
+	}
+}
+
+// AdminDeleteSCIMClient removes a client and its token. The accounts it
+// provisioned stay, as ordinary accounts.
+func AdminDeleteSCIMClient(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rows, err := queries.DeleteSCIMClient(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			logger.Error("Failed to delete SCIM client", "scim_client_id", c.Param("id"), "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeSCIMClientUpdateFailed)
+			return
+		}
+		if rows == 0 {
+			respond.Error(c, http.StatusNotFound, i18n.CodeSCIMClientNotFound)
+			return
+		}
+
+		respond.OK(c, nil)
+	}
+}
@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"net/http"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+	"brewd/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/oklog/ulid/v2"
+)
+
+// ShareRequest shares a brew or recipe with one user, by username, or with
+// every member of a club the owner belongs to
+type ShareRequest struct {
+	Username *string `json:"username" binding:"omitempty,min=3,max=30"`
+	ClubID   *string `json:"club_id" binding:"omitempty,max=26"`
+}
+
+// ShareResponse is one grantee of a brew or recipe
+type ShareResponse struct {
+	ID        string    `json:"id"`
+	UserID    *string   `json:"user_id,omitempty"`
+	Username  *string   `json:"username,omitempty"`
+	ClubID    *string   `json:"club_id,omitempty"`
+	ClubName  *string   `json:"club_name,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SharedItemResponse is another user's brew or recipe shared with the
+// current user, directly or through ClubID
+type SharedItemResponse struct {
+	BrewID        *string   `json:"brew_id,omitempty"`
+	RecipeID      *string   `json:"recipe_id,omitempty"`
+	Name          string    `json:"name"`
+	OwnerID       string    `json:"owner_id"`
+	OwnerUsername string    `json:"owner_username"`
+	ClubID        *string   `json:"club_id,omitempty"`
+	SharedAt      time.Time `json:"shared_at"`
+}
+
+// sharedItem is the brew or recipe a share is for; exactly one is set
+type sharedItem struct {
+	BrewID   *string
+	RecipeID *string
+}
+
+func (item sharedItem) id() string {
+	if item.BrewID != nil {
+		return *item.BrewID
+	}
+	return *item.RecipeID
+}
+
+// ShareBrew shares one of the current user's brews with a user or club.
+// The brew can stay private; shares let its grantees see it anyway. Only
+// an organization's owners and admins share its brews outside it.
+func ShareBrew(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		brew, ok := loadBrew(c, queries, tenant.RoleOwner, tenant.RoleAdmin)
+		if !ok {
+			return
+		}
+		createShare(c, queries, sharedItem{BrewID: &brew.ID})
+	}
+}
+
+// ListBrewShares lists who one of the current user's brews is shared with
+func ListBrewShares(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		brew, ok := loadBrew(c, queries)
+		if !ok {
+			return
+		}
+		listShares(c, queries, brew.ID)
+	}
+}
+
+// UnshareBrew revokes a share of one of the current user's brews
+func UnshareBrew(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		brew, ok := loadBrew(c, queries)
+		if !ok {
+			return
+		}
+		deleteShare(c, queries, brew.ID)
+	}
+}
+
+// ShareRecipe shares a recipe the current user owns with a user or club,
+// who can view it but not edit it
+func ShareRecipe(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		recipe, ok := loadRecipeWithRole(c, queries, recipeRoleOwner)
+		if !ok {
+			return
+		}
+		createShare(c, queries, sharedItem{RecipeID: &recipe.ID})
+	}
+}
+
+// ListRecipeShares lists who a recipe the current user owns is shared with
+func ListRecipeShares(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		recipe, ok := loadRecipeWithRole(c, queries, recipeRoleOwner)
+		if !ok {
+			return
+		}
+		listShares(c, queries, recipe.ID)
+	}
+}
+
+// UnshareRecipe revokes a share of a recipe the current user owns
+func UnshareRecipe(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		recipe, ok := loadRecipeWithRole(c, queries, recipeRoleOwner)
+		if !ok {
+			return
+		}
+		deleteShare(c, queries, recipe.ID)
+	}
+}
+
+// ListSharedWithMe returns other users' brews and recipes shared with the
+// current user or their clubs, most recently shared first
+func ListSharedWithMe(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var page PageQuery
+		if err := c.ShouldBindQuery(&page); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if page.Limit == 0 {
+			page.Limit = defaultPageLimit
+		}
+
+		userID := c.GetString("user_id")
+		items, err := queries.ListSharedWithUser(c.Request.Context(), db.ListSharedWithUserParams{
+			ViewerID:  userID,
+			RowLimit:  page.Limit,
+			RowOffset: page.Offset,
+		})
+		if err != nil {
+			logger.Error("Failed to list items shared with user", "user_id", userID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeShareFetchFailed)
+			return
+		}
+
+		data := make([]SharedItemResponse, 0, len(items))
+		for _, item := range items {
+			data = append(data, SharedItemResponse(item))
+		}
+
+		respond.Page(c, data, page.Limit, page.Offset, len(data))
+	}
+}
+
+// createShare grants the request's user or club access to item. Users
+// can't share with themselves, and only with clubs they belong to.
+func createShare(c *gin.Context, queries *db.Queries, item sharedItem) {
+	var req ShareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respond.Invalid(c, err)
+		return
+	}
+	if (req.Username == nil) == (req.ClubID == nil) {
+		respond.Error(c, http.StatusBadRequest, i18n.CodeShareGranteeInvalid)
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+	params := db.CreateResourceShareParams{
+		ID:        ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+		BrewID:    item.BrewID,
+		RecipeID:  item.RecipeID,
+		CreatedBy: userID,
+	}
+
+	if req.Username != nil {
+		grantee, err := queries.GetUserByUsername(ctx, *req.Username)
+		if err == pgx.ErrNoRows {
+			respond.Error(c, http.StatusNotFound, i18n.CodeUserNotFound)
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to get user by username", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeShareUpdateFailed)
+			return
+		}
+		if grantee.ID == userID {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeShareGranteeInvalid)
+			return
+		}
+		params.UserID = &grantee.ID
+	} else {
+		_, err := queries.GetClubMemberRole(ctx, db.GetClubMemberRoleParams{
+			ClubID: *req.ClubID,
+			UserID: userID,
+		})
+		if err == pgx.ErrNoRows {
+			respond.Error(c, http.StatusNotFound, i18n.CodeClubNotFound)
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to get club member role", "club_id", *req.ClubID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeShareUpdateFailed)
+			return
+		}
+		params.ClubID = req.ClubID
+	}
+
+	share, err := queries.CreateResourceShare(ctx, params)
+	if err != nil {
+		if isUniqueViolation(err) {
+			respond.Error(c, http.StatusConflict, i18n.CodeShareExists)
+			return
+		}
+		logger.Error("Failed to create share", "item_id", item.id(), "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeShareUpdateFailed)
+		return
+	}
+
+	resp := ShareResponse{
+		ID:        share.ID,
+		UserID:    share.UserID,
+		ClubID:    share.ClubID,
+		CreatedAt: share.CreatedAt,
+	}
+	if req.Username != nil {
+		resp.Username = req.Username
+	}
+	respond.Created(c, resp)
+}
+
+// listShares responds with the grantees of the item itemID
+func listShares(c *gin.Context, queries *db.Queries, itemID string) {
+	shares, err := queries.ListResourceShares(c.Request.Context(), itemID)
+	if err != nil {
+		logger.Error("Failed to list shares", "item_id", itemID, "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeShareFetchFailed)
+		return
+	}
+
+	data := make([]ShareResponse, 0, len(shares))
+	for _, share := range shares {
+		data = append(data, ShareResponse(share))
+	}
+
+	respond.OK(c, data)
+}
+
+// deleteShare revokes the :share_id share of the item itemID
+func deleteShare(c *gin.Context, queries *db.Queries, itemID string) {
+	rows, err := queries.DeleteResourceShare(c.Request.Context(), db.DeleteResourceShareParams{
+		ID:     c.Param("share_id"),
+		ItemID: itemID,
+	})
+	if err != nil {
+		logger.Error("Failed to delete share", "item_id", itemID, "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeShareUpdateFailed)
+		return
+	}
+	if rows == 0 {
+		respond.Error(c, http.StatusNotFound, i18n.CodeShareNotFound)
+		return
+	}
+
+	respond.OK(c, nil)
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/links"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+	"brewd/internal/sitemap"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sitemapPageSize is how many URLs each content sitemap lists, well under
+// the protocol's limit
+const sitemapPageSize = 10000
+
+// sitemapCacheControl lets crawlers and CDNs cache sitemaps for an hour
+const sitemapCacheControl = "public, max-age=3600"
+
+// Content sitemaps, named <kind>-<page>.xml
+const (
+	sitemapRecipes  = "recipes"
+	sitemapProfiles = "users"
+)
+
+// SitemapIndex lists the content sitemaps: public recipes and the profiles
+// of users with public content, sitemapPageSize URLs each. Sitemap URLs are
+// under the web app's base URL, which proxies them here.
+func SitemapIndex(queries *db.Queries, site *links.Site) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		recipeCount, err := queries.CountPublicRecipes(ctx)
+		if err != nil {
+			respondSitemapError(c, err)
+			return
+		}
+		profileCount, err := queries.CountPublicProfiles(ctx)
+		if err != nil {
+			respondSitemapError(c, err)
+			return
+		}
+
+		var entries []sitemap.Entry
+		for _, part := range []struct {
+			name  string
+			count int64
+		}{
+			{sitemapRecipes, recipeCount},
+			{sitemapProfiles, profileCount},
+		} {
+			for page := int64(1); page <= (part.count+sitemapPageSize-1)/sitemapPageSize; page++ {
+				entries = append(entries, sitemap.Entry{
+					Loc: site.Join("sitemaps", fmt.Sprintf("%s-%d.xml", part.name, page)),
+				})
+			}
+		}
+
+		var buf bytes.Buffer
+		if err := sitemap.WriteIndex(&buf, entries); err != nil {
+			respondSitemapError(c, err)
+			return
+		}
+		c.Header("Cache-Control", sitemapCacheControl)
+		c.Data(http.StatusOK, "application/xml; charset=utf-8", buf.Bytes())
+	}
+}
+
+// Sitemap serves one content sitemap, e.g. /sitemaps/recipes-1.xml
+func Sitemap(queries *db.Queries, site *links.Site) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name, page, ok := parseSitemapFile(c.Param("file"))
+		if !ok {
+			respond.Error(c, http.StatusNotFound, i18n.CodeSitemapNotFound)
+			return
+		}
+
+		ctx := c.Request.Context()
+		offset := int32((page - 1) * sitemapPageSize)
+		var urls []sitemap.URL
+		switch name {
+		case sitemapRecipes:
+			rows, err := queries.ListPublicRecipes(ctx, db.ListPublicRecipesParams{
+				Limit:  sitemapPageSize,
+				Offset: offset,
+			})
+			if err != nil {
+				respondSitemapError(c, err)
+				return
+			}
+			for _, row := range rows {
+				urls = append(urls, sitemap.URL{
+					Loc:     site.URL(links.KindRecipe, row.ID),
+					LastMod: sitemap.LastMod(row.UpdatedAt),
+				})
+			}
+		case sitemapProfiles:
+			rows, err := queries.ListPublicProfiles(ctx, db.ListPublicProfilesParams{
+				Limit:  sitemapPageSize,
+				Offset: offset,
+			})
+			if err != nil {
+				respondSitemapError(c, err)
+				return
+			}
+			for _, row := range rows {
+				urls = append(urls, sitemap.URL{
+					Loc:     site.URL(links.KindUser, row.Username),
+					LastMod: sitemap.LastMod(row.UpdatedAt),
+				})
+			}
+		}
+
+		// The first page always exists, even when empty
+		if len(urls) == 0 && page > 1 {
+			respond.Error(c, http.StatusNotFound, i18n.CodeSitemapNotFound)
+			return
+		}
+
+		var buf bytes.Buffer
+		if err := sitemap.WriteURLSet(&buf, urls); err != nil {
+			respondSitemapError(c, err)
+			return
+		}
+		c.Header("Cache-Control", sitemapCacheControl)
+		c.Data(http.StatusOK, "application/xml; charset=utf-8", buf.Bytes())
+	}
+}
+
+// parseSitemapFile splits a content sitemap file name into its content
+// name and 1-based page
+func parseSitemapFile(file string) (string, int, bool) {
+	base, found := strings.CutSuffix(file, ".xml")
+	if !found {
+		return "", 0, false
+	}
+	name, pageStr, found := strings.Cut(base, "-")
+	if !found || (name != sitemapRecipes && name != sitemapProfiles) {
+		return "", 0, false
+	}
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 || page > 100000 {
+		return "", 0, false
+	}
+	return name, page, true
+}
+
+func respondSitemapError(c *gin.Context, err error) {
+	logger.Error("Failed to build sitemap", "error", err)
+	respond.Error(c, http.StatusInternalServerError, i18n.CodeInternal)
+}
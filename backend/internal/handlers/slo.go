@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+	"brewd/internal/slo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SLOGroupResponse is an endpoint group's requests over the summary window:
+// the share that didn't fail and that were fast enough, and the share of
+// each error budget left, negative once overspent
+type SLOGroupResponse struct {
+	Group                  string  `json:"group"`
+	Requests               int64   `json:"requests"`
+	Errors                 int64   `json:"errors"`
+	SlowRequests           int64   `json:"slow_requests"`
+	SuccessRatio           float64 `json:"success_ratio"`
+	LatencyRatio           float64 `json:"latency_ratio"`
+	AvailabilityBudgetLeft float64 `json:"availability_budget_left"`
+	LatencyBudgetLeft      float64 `json:"latency_budget_left"`
+}
+
+// SLOObjectivesResponse are the objectives every endpoint group is held to
+type SLOObjectivesResponse struct {
+	AvailabilityPercent float64 `json:"availability_percent"`
+	LatencyMs           int64   `json:"latency_ms"`
+	LatencyPercent      float64 `json:"latency_percent"`
+}
+
+// SLOSummaryResponse is the admin SLO summary for this instance
+type SLOSummaryResponse struct {
+	WindowMinutes int                   `json:"window_minutes"`
+	Objectives    SLOObjectivesResponse `json:"objectives"`
+	Groups        []SLOGroupResponse    `json:"groups"`
+}
+
+// Metrics serves the SLO metrics in the Prometheus text format to scrapers
+// sending token as a bearer token
+func Metrics(recorder *slo.Recorder, token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			respond.Error(c, http.StatusServiceUnavailable, i18n.CodeMetricsUnavailable)
+			return
+		}
+		bearer, _ := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			respond.Error(c, http.StatusUnauthorized, i18n.CodeAuthenticationFailed)
+			return
+		}
+
+		writeMetrics(c, recorder)
+	}
+}
+
+// InternalMetrics serves the SLO metrics to any scraper, for the internal
+// listener, which isn't reachable from the internet
+func InternalMetrics(recorder *slo.Recorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writeMetrics(c, recorder)
+	}
+}
+
+// writeMetrics writes the SLO metrics in the Prometheus text format
+func writeMetrics(c *gin.Context, recorder *slo.Recorder) {
+	c.Header("Content-Type", slo.ContentType)
+	c.Status(http.StatusOK)
+	if err := recorder.WritePrometheus(c.Writer); err != nil {
+		logger.Warn("Failed to write metrics", "error", err)
+	}
+}
+
+// AdminGetSLO summarizes each endpoint group's success and latency ratios
+// over the last hour against the objectives, with the error budget left
+func AdminGetSLO(recorder *slo.Recorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		objectives := recorder.Objectives()
+		summaries := recorder.Summary()
+
+		groups := make([]SLOGroupResponse, 0, len(summaries))
+		for _, s := range summaries {
+			groups = append(groups, SLOGroupResponse{
+				Group:                  s.Group,
+				Requests:               s.Requests,
+				Errors:                 s.Errors,
+				SlowRequests:           s.Slow,
+				SuccessRatio:           s.SuccessRatio,
+				LatencyRatio:           s.LatencyRatio,
+				AvailabilityBudgetLeft: s.AvailabilityBudgetLeft,
+				LatencyBudgetLeft:      s.LatencyBudgetLeft,
+			})
+		}
+		respond.OK(c, SLOSummaryResponse{
+			WindowMinutes: int(slo.Window.Minutes()),
+			Objectives: SLOObjectivesResponse{
+				AvailabilityPercent: objectives.Availability,
+				LatencyMs:           objectives.LatencyThreshold.Milliseconds(),
+				LatencyPercent:      objectives.Latency,
+			},
+			Groups: groups,
+		})
+	}
+}
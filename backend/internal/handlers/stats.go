@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"brewd/internal/currency"
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/methods"
+	"brewd/internal/respond"
+	"brewd/internal/stats"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// GetMyStats returns the current user's brew totals and streaks, with day and
+// week boundaries in the user's own timezone
+func GetMyStats(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		userID := c.GetString("user_id")
+
+		loc, ok := userLocation(c, queries)
+		if !ok {
+			return
+		}
+
+		rows, err := queries.GetUserBrewDays(ctx, db.GetUserBrewDaysParams{
+			Timezone: loc.String(),
+			UserID:   userID,
+		})
+		if err != nil {
+			logger.Error("Failed to get brew days", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeStatsFetchFailed)
+			return
+		}
+
+		times, err := queries.GetUserBrewTimeStats(ctx, db.GetUserBrewTimeStatsParams{
+			LongMethods: methods.LongDurationIDs(),
+			UserID:      userID,
+		})
+		if err != nil {
+			logger.Error("Failed to get brew time stats", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeStatsFetchFailed)
+			return
+		}
+
+		days := make([]stats.Day, 0, len(rows))
+		for _, row := range rows {
+			days = append(days, stats.Day{Date: row.BrewDate.Time, Count: row.BrewCount})
+		}
+
+		summary := stats.Summarize(days, time.Now(), loc)
+		summary.AvgBrewTimeSeconds = times.AvgBrewTimeSeconds
+		summary.AvgLongBrewTimeSeconds = times.AvgLongBrewTimeSeconds
+
+		respond.OK(c, summary)
+	}
+}
+
+// defaultCostMonths is the ?months window of cost stats when none is given
+const defaultCostMonths = 12
+
+// CostStatsQuery represents the cost stats query parameters
+type CostStatsQuery struct {
+	Months int `form:"months" binding:"omitempty,min=1,max=36"`
+}
+
+// CostBreakdownQuery represents the cost breakdown query parameters
+type CostBreakdownQuery struct {
+	By string `form:"by" binding:"omitempty,oneof=roaster origin"`
+}
+
+// GetMyCostStats returns the current user's monthly bean spend and cost per
+// brew over the last ?months local calendar months (default 12), per
+// currency. Brews without a dose cost defaultDoseGrams unless their bag
+// assumes another.
+func GetMyCostStats(queries *db.Queries, defaultDoseGrams float64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query CostStatsQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if query.Months == 0 {
+			query.Months = defaultCostMonths
+		}
+
+		loc, ok := userLocation(c, queries)
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+		userID := c.GetString("user_id")
+		y, m, _ := time.Now().In(loc).Date()
+		since := pgtype.Date{
+			Time:  time.Date(y, m-time.Month(query.Months-1), 1, 0, 0, 0, 0, time.UTC),
+			Valid: true,
+		}
+
+		spendRows, err := queries.GetUserMonthlyBeanSpend(ctx, db.GetUserMonthlyBeanSpendParams{
+			Timezone: loc.String(),
+			UserID:   userID,
+			Since:    since,
+		})
+		if err != nil {
+			logger.Error("Failed to get monthly bean spend", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeStatsFetchFailed)
+			return
+		}
+
+		costRows, err := queries.GetUserMonthlyBrewCosts(ctx, db.GetUserMonthlyBrewCostsParams{
+			Timezone:         loc.String(),
+			DefaultDoseGrams: defaultDoseGrams,
+			UserID:           userID,
+			Since:            since,
+		})
+		if err != nil {
+			logger.Error("Failed to get monthly brew costs", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeStatsFetchFailed)
+			return
+		}
+
+		spend := make([]stats.Spend, 0, len(spendRows))
+		for _, row := range spendRows {
+			spend = append(spend, stats.Spend{
+				Month:      row.Month.Time,
+				Currency:   row.Currency,
+				SpentMinor: row.SpentMinor,
+				BagCount:   row.BagCount,
+			})
+		}
+		brews := make([]stats.BrewCost, 0, len(costRows))
+		for _, row := range costRows {
+			brews = append(brews, stats.BrewCost{
+				Month:     row.Month.Time,
+				Currency:  row.Currency,
+				BrewCount: row.BrewCount,
+				CostMinor: row.CostMinor,
+			})
+		}
+
+		respond.OK(c, stats.SummarizeCosts(spend, brews))
+	}
+}
+
+// GetMyCostBreakdown returns the current user's all-time bean spend and cost
+// per brew grouped by roaster, or by origin with ?by=origin
+func GetMyCostBreakdown(queries *db.Queries, defaultDoseGrams float64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		by, groups, ok := costBreakdown(c, queries, defaultDoseGrams)
+		if !ok {
+			return
+		}
+
+		respond.OK(c, gin.H{
+			"by":     by,
+			"groups": groups,
+		})
+	}
+}
+
+// csvContentType is the media type of spreadsheet exports
+const csvContentType = "text/csv; charset=utf-8"
+
+// ExportMyCostBreakdown returns the current user's cost breakdown, grouped
+// as GetMyCostBreakdown does, as a CSV spreadsheet download
+func ExportMyCostBreakdown(queries *db.Queries, defaultDoseGrams float64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		by, groups, ok := costBreakdown(c, queries, defaultDoseGrams)
+		if !ok {
+			return
+		}
+
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		w.Write([]string{by, "currency", "bag_count", "spent", "brew_count", "brew_cost", "cost_per_brew"})
+		for _, group := range groups {
+			var label, costPerBrew string
+			if group.Label != nil {
+				label = *group.Label
+			}
+			if group.CostPerBrew != nil {
+				costPerBrew = formatAmount(*group.CostPerBrew)
+			}
+			w.Write([]string{
+				label,
+				group.Currency,
+				strconv.FormatInt(group.BagCount, 10),
+				formatAmount(group.Spent),
+				strconv.FormatInt(group.BrewCount, 10),
+				formatAmount(group.BrewCost),
+				costPerBrew,
+			})
+		}
+		w.Flush()
+
+		c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": "costs-by-" + by + ".csv"}))
+		c.Data(http.StatusOK, csvContentType, buf.Bytes())
+	}
+}
+
+// formatAmount formats a major-unit amount for a spreadsheet, without
+// exponents or trailing zeros
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', -1, 64)
+}
+
+// costBreakdown groups the current user's all-time bean spend by ?by,
+// writing an error response if it can't be computed
+func costBreakdown(c *gin.Context, queries *db.Queries, defaultDoseGrams float64) (string, []stats.CostGroup, bool) {
+	var query CostBreakdownQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respond.Invalid(c, err)
+		return "", nil, false
+	}
+	if query.By == "" {
+		query.By = "roaster"
+	}
+
+	rows, err := queries.GetUserBeanCostBreakdown(c.Request.Context(), db.GetUserBeanCostBreakdownParams{
+		GroupBy:          query.By,
+		UserID:           c.GetString("user_id"),
+		DefaultDoseGrams: defaultDoseGrams,
+	})
+	if err != nil {
+		logger.Error("Failed to get bean cost breakdown", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeStatsFetchFailed)
+		return "", nil, false
+	}
+
+	groups := make([]stats.CostGroup, 0, len(rows))
+	for _, row := range rows {
+		cur, err := currency.Lookup(row.Currency)
+		if err != nil {
+			continue
+		}
+		groups = append(groups, stats.CostGroup{
+			Label:       row.Label,
+			Currency:    cur.Code,
+			BagCount:    row.BagCount,
+			Spent:       cur.FromMinor(float64(row.SpentMinor)),
+			BrewCount:   row.BrewCount,
+			BrewCost:    cur.FromMinor(row.CostMinor),
+			CostPerBrew: stats.CostPerBrew(cur, row.CostMinor, row.BrewCount),
+		})
+	}
+	return query.By, groups, true
+}
+
+// userLocation resolves the current user's timezone, writing an error
+// response if the user can't be loaded. A stored zone the runtime can't
+// resolve falls back to UTC rather than failing the whole request.
+func userLocation(c *gin.Context, queries *db.Queries) (*time.Location, bool) {
+	userID := c.GetString("user_id")
+	prefs, err := queries.GetUserPreferences(c.Request.Context(), userID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			respond.Error(c, http.StatusNotFound, i18n.CodeUserNotFound)
+			return nil, false
+		}
+		logger.Error("Failed to get user preferences", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeStatsFetchFailed)
+		return nil, false
+	}
+
+	loc, err := stats.LoadLocation(prefs.Timezone)
+	if err != nil {
+		logger.Warn("Unknown stored timezone, using UTC", "user_id", userID, "timezone", prefs.Timezone)
+		return time.UTC, true
+	}
+	return loc, true
+}
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"time"
+
+	"brewd/internal/auth"
+	"brewd/internal/authevents"
+	"brewd/internal/captcha"
+	"brewd/internal/cookies"
+	"brewd/internal/db"
+	"brewd/internal/geoip"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+	"brewd/internal/stepup"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/oklog/ulid/v2"
+)
+
+// StepUpResponse tells a user their login needs a step-up code before a
+// token is issued
+type StepUpResponse struct {
+	StepUpRequired bool      `json:"step_up_required"`
+	ChallengeID    string    `json:"challenge_id"`
+	Method         string    `json:"method"`
+	Reasons        []string  `json:"reasons"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// StepUpRequest answers a step-up challenge. TrustDevice asks for a device
+// token that skips step-up on later logins; with DeviceCookie, for web
+// clients, it's set in an HttpOnly cookie instead of returned. Cookie
+// picks the cookie auth mode, as at login.
+type StepUpRequest struct {
+	ChallengeID  string `json:"challenge_id" binding:"required,max=26"`
+	Code         string `json:"code" binding:"required,len=6,numeric"`
+	TrustDevice  bool   `json:"trust_device"`
+	DeviceCookie bool   `json:"device_cookie"`
+	Cookie       bool   `json:"cookie"`
+}
+
+// StepUpVerifyResponse is the login completed by a step-up code, with the
+// device token if the device is now trusted and didn't ask for a cookie
+type StepUpVerifyResponse struct {
+	AuthResponse
+	DeviceTrusted bool   `json:"device_trusted"`
+	DeviceToken   string `json:"device_token,omitempty"`
+}
+
+// holdForStepUp decides whether a login with a correct password needs a
+// step-up challenge, and if so creates one, emails its code if that's the
+// method, and responds with it. A valid trusted device token, from the
+// request or the device cookie, skips the check. It reports whether it
+// responded.
+func holdForStepUp(c *gin.Context, queries *db.Queries, guard *stepup.Guard, locator geoip.Locator,
+	user db.GetUserByEmailRow, deviceToken string) bool {
+	ctx := c.Request.Context()
+
+	if deviceToken == "" {
+		deviceToken, _ = c.Cookie(cookies.Device)
+	}
+	if deviceToken != "" {
+		trusted, err := queries.UseTrustedDevice(ctx, db.UseTrustedDeviceParams{
+			UserID:    user.ID,
+			TokenHash: stepup.HashDeviceToken(deviceToken),
+		})
+		if err != nil {
+			logger.Error("Failed to check trusted device", "user_id", user.ID, "error", err)
+		} else if trusted > 0 {
+			return false
+		}
+	}
+
+	totp, err := queries.GetUserTOTP(ctx, user.ID)
+	if err != nil {
+		logger.Error("Failed to get TOTP enrollment", "user_id", user.ID, "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeStepUpFailed)
+		return true
+	}
+	risk := stepup.Risk{TwoFactor: totp.TotpEnabledAt.Valid}
+
+	origin := authEventParams(c, locator, user.ID, authevents.EventStepUp)
+	if !risk.TwoFactor && guard.Suspicion() {
+		row, err := queries.GetLoginRisk(ctx, db.GetLoginRiskParams{
+			UserID:             user.ID,
+			UserAgent:          origin.UserAgent,
+			Country:            origin.Country,
+			Region:             origin.Region,
+			FailureWindowHours: int32(stepup.FailureWindow.Hours()),
+		})
+		if err != nil {
+			logger.Error("Failed to assess login risk", "user_id", user.ID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeAuthenticationFailed)
+			return true
+		}
+		risk.HasHistory = row.HasHistory
+		risk.KnownDevice = row.KnownDevice
+		risk.KnownLocation = row.KnownLocation
+		risk.RecentFailures = int(row.RecentFailures)
+	}
+	reasons, required := guard.Assess(risk)
+	if !required {
+		return false
+	}
+
+	// Users with an authenticator app answer from it; everyone else gets
+	// an emailed code
+	params := db.CreateStepUpChallengeParams{
+		ID:         ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+		UserID:     user.ID,
+		Method:     stepup.MethodTOTP,
+		TtlSeconds: int32(stepup.ChallengeTTL.Seconds()),
+	}
+	var code string
+	if !risk.TwoFactor {
+		var hash string
+		code, hash, err = stepup.NewCode()
+		if err != nil {
+			logger.Error("Failed to generate step-up code", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeStepUpFailed)
+			return true
+		}
+		params.Method = stepup.MethodEmail
+		params.CodeHash = &hash
+	}
+
+	challenge, err := queries.CreateStepUpChallenge(ctx, params)
+	if err != nil {
+		logger.Error("Failed to create step-up challenge", "user_id", user.ID, "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeStepUpFailed)
+		return true
+	}
+	if challenge.Method == stepup.MethodEmail {
+		if err := guard.SendCode(ctx, user.Email, user.Username, code); err != nil {
+			logger.Error("Failed to send step-up code", "user_id", user.ID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeStepUpFailed)
+			return true
+		}
+	}
+
+	if err := queries.RecordAuthEvent(ctx, origin); err != nil {
+		logger.Error("Failed to record auth event", "user_id", user.ID, "event", origin.Event, "error", err)
+	}
+	logger.Info("Login held for step-up", "user_id", user.ID, "method", challenge.Method, "reasons", reasons)
+
+	respond.Status(c, http.StatusAccepted, StepUpResponse{
+		StepUpRequired: true,
+		ChallengeID:    challenge.ID,
+		Method:         challenge.Method,
+		Reasons:        reasons,
+		ExpiresAt:      challenge.ExpiresAt.Time,
+	})
+	return true
+}
+
+// VerifyStepUp completes a login held for a step-up challenge with the
+// emailed or TOTP code, optionally trusting the device for
+// trustedDeviceDays. Wrong codes count as failed logins toward the CAPTCHA
+// and step-up thresholds.
+func VerifyStepUp(queries *db.Queries, authService auth.AuthService, failures *captcha.Failures,
+	locator geoip.Locator, cookiePolicy cookies.Policy, trustedDeviceDays int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req StepUpRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		// The attempt is counted before the code is checked, so concurrent
+		// guesses can't get past the limit
+		ctx := c.Request.Context()
+		challenge, err := queries.AttemptStepUpChallenge(ctx, db.AttemptStepUpChallengeParams{
+			ID:          req.ChallengeID,
+			MaxAttempts: stepup.MaxAttempts,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				respond.Error(c, http.StatusUnauthorized, i18n.CodeStepUpExpired)
+				return
+			}
+			logger.Error("Failed to get step-up challenge", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeAuthenticationFailed)
+			return
+		}
+
+		valid := false
+		switch challenge.Method {
+		case stepup.MethodEmail:
+			valid = challenge.CodeHash != nil &&
+				subtle.ConstantTimeCompare([]byte(stepup.HashCode(req.Code)), []byte(*challenge.CodeHash)) == 1
+		case stepup.MethodTOTP:
+			if challenge.TotpSecret == nil {
+				break
+			}
+			step, ok := auth.ValidateTOTP(*challenge.TotpSecret, req.Code, time.Now())
+			if !ok {
+				break
+			}
+			// A code already used, e.g. one seen over a shoulder, is refused
+			fresh, err := queries.UseTOTPStep(ctx, db.UseTOTPStepParams{ID: challenge.UserID, Step: &step})
+			if err != nil {
+				logger.Error("Failed to record TOTP step", "user_id", challenge.UserID, "error", err)
+				respond.Error(c, http.StatusInternalServerError, i18n.CodeAuthenticationFailed)
+				return
+			}
+			valid = fresh > 0
+		}
+		if !valid {
+			failures.Fail(challenge.Email)
+			recordAuthEvent(c, queries, locator, challenge.UserID, authevents.EventStepUpFailed)
+			respond.Error(c, http.StatusUnauthorized, i18n.CodeStepUpInvalid)
+			return
+		}
+
+		// Only one request completes a challenge
+		completed, err := queries.CompleteStepUpChallenge(ctx, db.CompleteStepUpChallengeParams{
+			ID:          challenge.ID,
+			MaxAttempts: stepup.MaxAttempts,
+		})
+		if err != nil {
+			logger.Error("Failed to complete step-up challenge", "user_id", challenge.UserID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeAuthenticationFailed)
+			return
+		}
+		if completed == 0 {
+			respond.Error(c, http.StatusUnauthorized, i18n.CodeStepUpExpired)
+			return
+		}
+		failures.Reset(challenge.Email)
+
+		session, ok := issueSession(c, authService, cookiePolicy, UserInfo{
+			ID:       challenge.UserID,
+			Username: challenge.Username,
+			Email:    challenge.Email,
+		}, req.Cookie)
+		if !ok {
+			return
+		}
+		resp := StepUpVerifyResponse{AuthResponse: session}
+
+		if req.TrustDevice {
+			// The login stands without the device token; the device is
+			// just asked again next time
+			deviceToken, hash, err := stepup.NewDeviceToken()
+			if err == nil {
+				err = queries.CreateTrustedDevice(ctx, db.CreateTrustedDeviceParams{
+					ID:        ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+					UserID:    challenge.UserID,
+					TokenHash: hash,
+					UserAgent: userAgent(c),
+					TtlDays:   int32(trustedDeviceDays),
+				})
+			}
+			switch {
+			case err != nil:
+				logger.Error("Failed to trust device", "user_id", challenge.UserID, "error", err)
+			case req.DeviceCookie:
+				cookiePolicy.SetDevice(c.Writer, deviceToken, time.Duration(trustedDeviceDays)*24*time.Hour)
+				resp.DeviceTrusted = true
+			default:
+				resp.DeviceToken = deviceToken
+				resp.DeviceTrusted = true
+			}
+		}
+
+		recordAuthEvent(c, queries, locator, challenge.UserID, authevents.EventLogin)
+
+		logger.Info("User logged in after step-up", "user_id", challenge.UserID, "method", challenge.Method,
+			"trusted_device", resp.DeviceTrusted)
+
+		respond.OK(c, resp)
+	}
+}
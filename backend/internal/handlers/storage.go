@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/plans"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oklog/ulid/v2"
+)
+
+// storageReservationTTL is how long an upload's reservation lasts; longer
+// than any upload takes, so it only lapses for a server that died
+const storageReservationTTL = 15 * time.Minute
+
+// StorageUsageResponse is how much a user stores against their plan's
+// storage quota. Photo originals and renditions all count.
+type StorageUsageResponse struct {
+	UsedBytes  int64 `json:"used_bytes"`
+	LimitBytes int64 `json:"limit_bytes"`
+	PhotoBytes int64 `json:"photo_bytes"`
+}
+
+// StorageUserResponse is a user's storage, for the admin storage report
+type StorageUserResponse struct {
+	UserID     string    `json:"user_id"`
+	Username   string    `json:"username"`
+	Plan       string    `json:"plan"`
+	UsedBytes  int64     `json:"used_bytes"`
+	LimitBytes int64     `json:"limit_bytes"`
+	PhotoCount int32     `json:"photo_count"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// AdminListStorageUsage lists the users storing the most, largest first,
+// against their plans' quotas
+func AdminListStorageUsage(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var page PageQuery
+		if err := c.ShouldBindQuery(&page); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if page.Limit == 0 {
+			page.Limit = defaultPageLimit
+		}
+
+		rows, err := queries.ListTopStorageUsers(c.Request.Context(), db.ListTopStorageUsersParams{
+			RowLimit:  page.Limit,
+			RowOffset: page.Offset,
+		})
+		if err != nil {
+			logger.Error("Failed to list storage usage", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeStorageFetchFailed)
+			return
+		}
+
+		items := make([]StorageUserResponse, 0, len(rows))
+		for _, row := range rows {
+			items = append(items, StorageUserResponse{
+				UserID:     row.UserID,
+				Username:   row.Username,
+				Plan:       row.Plan,
+				UsedBytes:  row.PhotoBytes,
+				LimitBytes: plans.LimitsFor(row.Plan).PhotoStorageBytes,
+				PhotoCount: row.PhotoCount,
+				UpdatedAt:  row.UpdatedAt,
+			})
+		}
+		respond.Page(c, items, page.Limit, page.Offset, len(items))
+	}
+}
+
+// storageUsage returns userID's storage against the quota of plan
+func storageUsage(ctx context.Context, queries *db.Queries, userID, plan string) (StorageUsageResponse, error) {
+	photoBytes, err := queries.GetUserStorage(ctx, userID)
+	if err != nil {
+		return StorageUsageResponse{}, err
+	}
+	return StorageUsageResponse{
+		UsedBytes:  photoBytes,
+		LimitBytes: plans.LimitsFor(plan).PhotoStorageBytes,
+		PhotoBytes: photoBytes,
+	}, nil
+}
+
+// reserveStorage reserves size bytes of userID's storage for an upload,
+// answering 403 storage_quota_exceeded if they'd take them past their
+// plan's quota with what they store and other uploads in flight. The
+// returned function releases the reservation and must be called once the
+// upload's row counts the bytes or it failed.
+func reserveStorage(c *gin.Context, queries *db.Queries, tiers *plans.Cache, userID string, size int64) (func(), bool) {
+	ctx := c.Request.Context()
+	plan := tiers.Get(ctx, userID)
+	limit := plans.LimitsFor(plan).PhotoStorageBytes
+	id := ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String()
+	reserved, err := queries.ReserveStorage(ctx, db.ReserveStorageParams{
+		UserID:     userID,
+		ID:         id,
+		SizeBytes:  size,
+		LimitBytes: limit,
+		TtlSeconds: int32(storageReservationTTL / time.Second),
+	})
+	if err != nil {
+		logger.Error("Failed to reserve user storage", "user_id", userID, "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeStorageFetchFailed)
+		return nil, false
+	}
+	if !reserved {
+		usage, err := storageUsage(ctx, queries, userID, plan)
+		if err != nil {
+			logger.Error("Failed to get user storage", "user_id", userID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeStorageFetchFailed)
+			return nil, false
+		}
+		respond.Details(c, http.StatusForbidden, i18n.CodeStorageQuotaExceeded, map[string]string{
+			"plan":        plan,
+			"limit_bytes": strconv.FormatInt(usage.LimitBytes, 10),
+			"used_bytes":  strconv.FormatInt(usage.UsedBytes, 10),
+		})
+		return nil, false
+	}
+
+	return func() {
+		// The upload's request may be over; releasing mustn't be cut short
+		// with it, or the bytes stay reserved until the reservation lapses
+		if err := queries.ReleaseStorage(context.WithoutCancel(ctx), id); err != nil {
+			logger.Warn("Failed to release storage reservation", "user_id", userID, "error", err)
+		}
+	}, true
+}
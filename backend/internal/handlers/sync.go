@@ -0,0 +1,588 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+	"brewd/internal/units"
+	"brewd/internal/validation"
+	"brewd/internal/vclock"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/jackc/pgx/v5"
+	"github.com/oklog/ulid/v2"
+)
+
+// Resources the offline sync covers
+const (
+	syncResourceBrew  = "brew"
+	syncResourceDraft = "draft"
+)
+
+// Conflict policies: which side's values a record keeps when a client and
+// the server changed it concurrently
+const (
+	syncServerWins = "server_wins"
+	syncClientWins = "client_wins"
+)
+
+// syncPolicies is each resource's conflict policy. The server changes brews
+// itself (timers, reminders, custom field deletes), so it keeps them; drafts
+// are only edited by their owner, so the latest push wins.
+var syncPolicies = map[string]string{
+	syncResourceBrew:  syncServerWins,
+	syncResourceDraft: syncClientWins,
+}
+
+// Outcomes of a pushed change
+const (
+	syncApplied   = "applied"
+	syncUnchanged = "unchanged"
+	syncRejected  = "rejected"
+)
+
+// Limits on pushed revision vectors
+const (
+	maxSyncReplicas   = 32
+	maxSyncReplicaLen = 64
+)
+
+const defaultSyncLimit = 200
+
+// SyncPullQuery represents the pull cursor and page size
+type SyncPullQuery struct {
+	Since int64 `form:"since" binding:"omitempty,min=0"`
+	Limit int32 `form:"limit" binding:"omitempty,min=1,max=500"`
+}
+
+// SyncPushRequest represents a batch of changes made offline, applied in
+// order
+type SyncPushRequest struct {
+	Changes []SyncChange `json:"changes" binding:"required,min=1,max=100,dive"`
+}
+
+// SyncChange is one record changed offline. Vector is the record's revision
+// vector after the change; data is the record as the create endpoint takes
+// it (a Log Brew payload, or a draft's kind and data).
+type SyncChange struct {
+	Resource string          `json:"resource" binding:"required,oneof=brew draft"`
+	ID       string          `json:"id" binding:"required"`
+	Op       string          `json:"op" binding:"required,oneof=upsert delete"`
+	Vector   vclock.Vector   `json:"vector" binding:"required"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// SyncRecord is a record's current server state. Data is the record as its
+// get endpoint returns it, left out for deleted records.
+type SyncRecord struct {
+	Resource string        `json:"resource"`
+	ID       string        `json:"id"`
+	Seq      int64         `json:"seq"`
+	Vector   vclock.Vector `json:"vector"`
+	Deleted  bool          `json:"deleted"`
+	Data     any           `json:"data,omitempty"`
+}
+
+// SyncResult is the outcome of one pushed change, in request order. Record
+// is the server's state afterwards, for the client to store with its vector.
+type SyncResult struct {
+	Index    int                     `json:"index"`
+	Resource string                  `json:"resource"`
+	ID       string                  `json:"id"`
+	Status   string                  `json:"status"`
+	Conflict bool                    `json:"conflict"`
+	Record   *SyncRecord             `json:"record,omitempty"`
+	Error    string                  `json:"error,omitempty"`
+	Code     i18n.Code               `json:"code,omitempty"`
+	Details  map[string]string       `json:"details,omitempty"`
+	Fields   []validation.FieldError `json:"fields,omitempty"`
+}
+
+// SyncPull returns the current user's records changed or deleted after the
+// since cursor, oldest change first. The client stores cursor and pulls
+// again while has_more is set.
+func SyncPull(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query SyncPullQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if query.Limit == 0 {
+			query.Limit = defaultSyncLimit
+		}
+
+		pref, ok := requestUnits(c, queries)
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+		userID := c.GetString("user_id")
+		brews, err := queries.ListBrewChanges(ctx, db.ListBrewChangesParams{
+			CreatedBy: &userID,
+			SyncSeq:   query.Since,
+			Limit:     query.Limit,
+		})
+		if err != nil {
+			respondSyncError(c, err)
+			return
+		}
+		drafts, err := queries.ListDraftChanges(ctx, db.ListDraftChangesParams{
+			UserID:  userID,
+			SyncSeq: query.Since,
+			Limit:   query.Limit,
+		})
+		if err != nil {
+			respondSyncError(c, err)
+			return
+		}
+		tombstones, err := queries.ListTombstones(ctx, db.ListTombstonesParams{
+			UserID: userID,
+			Seq:    query.Since,
+			Limit:  query.Limit,
+		})
+		if err != nil {
+			respondSyncError(c, err)
+			return
+		}
+
+		records := make([]SyncRecord, 0, len(brews)+len(drafts)+len(tombstones))
+		for _, b := range brews {
+			records = append(records, brewSyncRecord(b, pref))
+		}
+		for _, d := range drafts {
+			records = append(records, draftSyncRecord(d))
+		}
+		for _, t := range tombstones {
+			records = append(records, tombstoneSyncRecord(t))
+		}
+
+		// Each list holds the oldest changes of its kind, so the oldest
+		// limit of them all are a complete page. A full list may have more.
+		sort.Slice(records, func(i, j int) bool { return records[i].Seq < records[j].Seq })
+		limit := int(query.Limit)
+		hasMore := len(records) > limit || len(brews) == limit || len(drafts) == limit || len(tombstones) == limit
+		if len(records) > limit {
+			records = records[:limit]
+		}
+		cursor := query.Since
+		if len(records) > 0 {
+			cursor = records[len(records)-1].Seq
+		}
+
+		respond.OK(c, gin.H{
+			"changes":  records,
+			"cursor":   cursor,
+			"has_more": hasMore,
+		})
+	}
+}
+
+// SyncPush applies changes the current user made offline, one by one and in
+// order. Each change's vector is compared with the server's: a change the
+// server has already seen is left alone, a newer one is applied, and a
+// concurrent one is a conflict settled by the resource's policy.
+func SyncPush(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req SyncPushRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		pref, ok := requestUnits(c, queries)
+		if !ok {
+			return
+		}
+
+		results := make([]SyncResult, 0, len(req.Changes))
+		for i, ch := range req.Changes {
+			code, ok := checkSyncChange(&ch)
+			result := SyncResult{Resource: ch.Resource, ID: ch.ID}
+			if !ok {
+				result.rejected(itemError{Error: i18n.T(c, code), Code: code})
+			} else if ch.Resource == syncResourceBrew {
+				pushBrew(c, queries, pref, ch, &result)
+			} else {
+				pushDraft(c, queries, ch, &result)
+			}
+			result.Index = i
+			results = append(results, result)
+		}
+
+		respond.OK(c, gin.H{
+			"results": results,
+		})
+	}
+}
+
+// pushBrew applies one pushed brew change
+func pushBrew(c *gin.Context, queries *db.Queries, pref units.Preference, ch SyncChange, result *SyncResult) {
+	if ch.Op == "delete" {
+		result.rejected(itemError{
+			Error: i18n.T(c, i18n.CodeSyncDeleteUnsupported),
+			Code:  i18n.CodeSyncDeleteUnsupported,
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+	var req BrewRequest
+	if ie, ok := bindSyncData(c, ch, &req); !ok {
+		result.rejected(ie)
+		return
+	}
+
+	existing, err := queries.GetBrewByID(ctx, ch.ID)
+	if err == pgx.ErrNoRows {
+		if pushToTombstone(c, queries, ch, result) || pushToArchivedBrew(c, queries, ch, result) {
+			return
+		}
+		var brew db.Brew
+		if ie, ok := recordItem(c, func() bool {
+			params, _, ok := prepareBrew(c, queries, req)
+			if !ok {
+				return false
+			}
+			params.ID = ch.ID
+			params.SyncVector = ch.Vector.Encode()
+			brew, _, ok = insertBrew(c, queries, params, pref)
+			return ok
+		}); !ok {
+			result.rejected(ie)
+			return
+		}
+		result.applied(brewSyncRecord(brew, pref), false)
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to get brew", "error", err)
+		result.rejected(itemError{Error: i18n.T(c, i18n.CodeBrewFetchFailed), Code: i18n.CodeBrewFetchFailed})
+		return
+	}
+	// Organization brews are kept in the organization's log, not synced
+	if !ownsBrew(existing, userID) || existing.OrganizationID != nil {
+		result.rejected(itemError{Error: i18n.T(c, i18n.CodeBrewNotFound), Code: i18n.CodeBrewNotFound})
+		return
+	}
+
+	stored := vclock.Decode(existing.SyncVector)
+	apply, conflict := resolveSync(ch, stored)
+	if !apply {
+		brew := existing
+		if conflict {
+			// The server's values stand under a vector that supersedes
+			// both, so the client takes them on its next pull
+			brew, err = queries.SetBrewSyncVector(ctx, db.SetBrewSyncVectorParams{
+				ID:         existing.ID,
+				CreatedBy:  &userID,
+				SyncVector: vclock.Merge(ch.Vector, stored).Bump(vclock.Server).Encode(),
+			})
+			if err != nil {
+				logger.Error("Failed to set brew sync vector", "error", err)
+				result.rejected(itemError{Error: i18n.T(c, i18n.CodeBrewUpdateFailed), Code: i18n.CodeBrewUpdateFailed})
+				return
+			}
+		}
+		result.unchanged(brewSyncRecord(brew, pref), conflict)
+		return
+	}
+
+	var brew db.Brew
+	if ie, ok := recordItem(c, func() bool {
+		params, _, ok := prepareBrew(c, queries, req)
+		if !ok {
+			return false
+		}
+		brew, ok = saveBrew(c, queries, existing.ID, params, vclock.Merge(ch.Vector, stored).Encode())
+		return ok
+	}); !ok {
+		result.rejected(ie)
+		return
+	}
+	result.applied(brewSyncRecord(brew, pref), conflict)
+}
+
+// pushDraft applies one pushed draft change
+func pushDraft(c *gin.Context, queries *db.Queries, ch SyncChange, result *SyncResult) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+	var req DraftRequest
+	if ch.Op != "delete" {
+		if ie, ok := bindSyncData(c, ch, &req); !ok {
+			result.rejected(ie)
+			return
+		}
+	}
+
+	existing, err := queries.GetDraft(ctx, db.GetDraftParams{ID: ch.ID, UserID: userID})
+	if err == pgx.ErrNoRows {
+		if pushToTombstone(c, queries, ch, result) {
+			return
+		}
+		if ch.Op == "delete" {
+			// Never synced before it was deleted
+			result.unchanged(SyncRecord{Resource: ch.Resource, ID: ch.ID, Vector: ch.Vector, Deleted: true}, false)
+			return
+		}
+		var draft db.Draft
+		if ie, ok := recordItem(c, func() bool {
+			data, ok := mergeDraftData(c, req.Kind, nil, draftPatch(req.Data))
+			if !ok {
+				return false
+			}
+			count, err := queries.CountUserDrafts(ctx, userID)
+			if err == nil && count >= maxDrafts {
+				respond.Errorf(c, http.StatusConflict, i18n.CodeDraftLimit, maxDrafts)
+				return false
+			}
+			if err == nil {
+				draft, err = queries.CreateDraft(ctx, db.CreateDraftParams{
+					ID:         ch.ID,
+					UserID:     userID,
+					Kind:       req.Kind,
+					Data:       data,
+					SyncVector: ch.Vector.Encode(),
+				})
+			}
+			if err != nil {
+				respondDraftPushError(c, err)
+				return false
+			}
+			return true
+		}); !ok {
+			result.rejected(ie)
+			return
+		}
+		result.applied(draftSyncRecord(draft), false)
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to get draft", "error", err)
+		result.rejected(itemError{Error: i18n.T(c, i18n.CodeDraftFetchFailed), Code: i18n.CodeDraftFetchFailed})
+		return
+	}
+
+	stored := vclock.Decode(existing.SyncVector)
+	apply, conflict := resolveSync(ch, stored)
+	if !apply {
+		draft := existing
+		if conflict {
+			draft, err = queries.SetDraftSyncVector(ctx, db.SetDraftSyncVectorParams{
+				ID:         existing.ID,
+				UserID:     userID,
+				SyncVector: vclock.Merge(ch.Vector, stored).Bump(vclock.Server).Encode(),
+			})
+			if err != nil {
+				logger.Error("Failed to set draft sync vector", "error", err)
+				result.rejected(itemError{Error: i18n.T(c, i18n.CodeDraftUpdateFailed), Code: i18n.CodeDraftUpdateFailed})
+				return
+			}
+		}
+		result.unchanged(draftSyncRecord(draft), conflict)
+		return
+	}
+
+	if ch.Op == "delete" {
+		if _, err := queries.DeleteDraft(ctx, db.DeleteDraftParams{ID: existing.ID, UserID: userID}); err != nil {
+			logger.Error("Failed to delete draft", "error", err)
+			result.rejected(itemError{Error: i18n.T(c, i18n.CodeDraftUpdateFailed), Code: i18n.CodeDraftUpdateFailed})
+			return
+		}
+		result.applied(SyncRecord{Resource: ch.Resource, ID: ch.ID, Vector: vclock.Merge(ch.Vector, stored), Deleted: true}, conflict)
+		return
+	}
+
+	var draft db.Draft
+	if ie, ok := recordItem(c, func() bool {
+		if req.Kind != existing.Kind {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidRequest)
+			return false
+		}
+		data, ok := mergeDraftData(c, req.Kind, nil, draftPatch(req.Data))
+		if !ok {
+			return false
+		}
+		draft, err = queries.UpdateDraftData(ctx, db.UpdateDraftDataParams{
+			ID:         existing.ID,
+			UserID:     userID,
+			Data:       data,
+			SyncVector: vclock.Merge(ch.Vector, stored).Encode(),
+		})
+		if err != nil {
+			respondDraftPushError(c, err)
+			return false
+		}
+		return true
+	}); !ok {
+		result.rejected(ie)
+		return
+	}
+	result.applied(draftSyncRecord(draft), conflict)
+}
+
+// pushToTombstone settles a change to a record the server has deleted, as
+// if the deletion were the stored revision. It reports whether the change
+// was settled; false means the record should be created.
+func pushToTombstone(c *gin.Context, queries *db.Queries, ch SyncChange, result *SyncResult) bool {
+	tomb, err := queries.GetTombstone(c.Request.Context(), db.GetTombstoneParams{
+		Resource: ch.Resource,
+		RecordID: ch.ID,
+		UserID:   c.GetString("user_id"),
+	})
+	if err == pgx.ErrNoRows {
+		return false
+	}
+	if err != nil {
+		logger.Error("Failed to get tombstone", "error", err)
+		result.rejected(itemError{Error: i18n.T(c, i18n.CodeSyncFetchFailed), Code: i18n.CodeSyncFetchFailed})
+		return true
+	}
+
+	apply, conflict := resolveSync(ch, vclock.Decode(tomb.Vector))
+	if apply && ch.Op != "delete" {
+		return false
+	}
+	result.unchanged(tombstoneSyncRecord(tomb), conflict)
+	return true
+}
+
+// pushToArchivedBrew rejects a change to one of the user's archived brews,
+// which have no tombstone and can't be recreated under the same ID. It
+// reports whether the change was settled.
+func pushToArchivedBrew(c *gin.Context, queries *db.Queries, ch SyncChange, result *SyncResult) bool {
+	userID := c.GetString("user_id")
+	_, err := queries.GetArchivedBrew(c.Request.Context(), db.GetArchivedBrewParams{
+		ID:        ch.ID,
+		CreatedBy: &userID,
+	})
+	if err == pgx.ErrNoRows {
+		return false
+	}
+	if err != nil {
+		logger.Error("Failed to get archived brew", "error", err)
+		result.rejected(itemError{Error: i18n.T(c, i18n.CodeBrewFetchFailed), Code: i18n.CodeBrewFetchFailed})
+		return true
+	}
+	result.rejected(itemError{Error: i18n.T(c, i18n.CodeBrewArchived), Code: i18n.CodeBrewArchived})
+	return true
+}
+
+// resolveSync decides whether a pushed change is applied over the stored
+// revision, and whether the two conflict
+func resolveSync(ch SyncChange, stored vclock.Vector) (apply, conflict bool) {
+	switch vclock.Compare(ch.Vector, stored) {
+	case vclock.After:
+		return true, false
+	case vclock.Concurrent:
+		return syncPolicies[ch.Resource] == syncClientWins, true
+	}
+	// The server already has this revision, or a newer one
+	return false, false
+}
+
+// checkSyncChange checks a change's ID and vector, returning the error code
+// to reject it with. The ID is canonicalized to upper case, as the server
+// writes ULIDs, so a lower-case one finds the same record.
+func checkSyncChange(ch *SyncChange) (i18n.Code, bool) {
+	id, err := ulid.ParseStrict(ch.ID)
+	if err != nil {
+		return i18n.CodeSyncIDInvalid, false
+	}
+	ch.ID = id.String()
+	if len(ch.Vector) == 0 || len(ch.Vector) > maxSyncReplicas {
+		return i18n.CodeSyncVectorInvalid, false
+	}
+	for replica, n := range ch.Vector {
+		if replica == "" || len(replica) > maxSyncReplicaLen || n <= 0 {
+			return i18n.CodeSyncVectorInvalid, false
+		}
+	}
+	return "", true
+}
+
+// bindSyncData decodes and validates a pushed record into req
+func bindSyncData(c *gin.Context, ch SyncChange, req any) (itemError, bool) {
+	err := json.Unmarshal(ch.Data, req)
+	if err == nil {
+		err = binding.Validator.ValidateStruct(req)
+	}
+	if err != nil {
+		return invalidItem(c, err), false
+	}
+	return itemError{}, true
+}
+
+// draftPatch returns pushed draft data as a patch over nothing, which
+// normalizes it the way Create Draft does
+func draftPatch(data json.RawMessage) []byte {
+	if len(data) == 0 {
+		return []byte("{}")
+	}
+	return data
+}
+
+func respondDraftPushError(c *gin.Context, err error) {
+	if isUniqueViolation(err) {
+		// Another user's draft has the ID
+		respond.Error(c, http.StatusBadRequest, i18n.CodeSyncIDInvalid)
+		return
+	}
+	logger.Error("Failed to save pushed draft", "error", err)
+	respond.Error(c, http.StatusInternalServerError, i18n.CodeDraftUpdateFailed)
+}
+
+func respondSyncError(c *gin.Context, err error) {
+	logger.Error("Failed to list sync changes", "error", err)
+	respond.Error(c, http.StatusInternalServerError, i18n.CodeSyncFetchFailed)
+}
+
+func (r *SyncResult) applied(record SyncRecord, conflict bool) {
+	r.Status, r.Conflict, r.Record = syncApplied, conflict, &record
+}
+
+func (r *SyncResult) unchanged(record SyncRecord, conflict bool) {
+	r.Status, r.Conflict, r.Record = syncUnchanged, conflict, &record
+}
+
+func (r *SyncResult) rejected(ie itemError) {
+	r.Status, r.Error, r.Code, r.Details, r.Fields = syncRejected, ie.Error, ie.Code, ie.Details, ie.Fields
+}
+
+func brewSyncRecord(b db.Brew, pref units.Preference) SyncRecord {
+	return SyncRecord{
+		Resource: syncResourceBrew,
+		ID:       b.ID,
+		Seq:      b.SyncSeq,
+		Vector:   vclock.Decode(b.SyncVector),
+		Data:     newBrewResponse(b, pref),
+	}
+}
+
+func draftSyncRecord(d db.Draft) SyncRecord {
+	return SyncRecord{
+		Resource: syncResourceDraft,
+		ID:       d.ID,
+		Seq:      d.SyncSeq,
+		Vector:   vclock.Decode(d.SyncVector),
+		Data:     newDraftResponse(d),
+	}
+}
+
+func tombstoneSyncRecord(t db.SyncTombstone) SyncRecord {
+	return SyncRecord{
+		Resource: t.Resource,
+		ID:       t.RecordID,
+		Seq:      t.Seq,
+		Vector:   vclock.Decode(t.Vector),
+		Deleted:  true,
+	}
+}
@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"net/http"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/oklog/ulid/v2"
+)
+
+// maxReadingClockSkew is how far in the future a device may date a reading
+const maxReadingClockSkew = 5 * time.Minute
+
+// TDSReadingInput is a single refractometer reading sent by a companion
+// app. MeasuredAt defaults to when the reading is received; BrewID overrides
+// attaching it to the brew session running at the time.
+type TDSReadingInput struct {
+	ClientID      *string    `json:"client_id" binding:"omitempty,min=1,max=100"`
+	TDSPercent    float64    `json:"tds_percent" binding:"required,gt=0,lte=30"`
+	TemperatureC  *float64   `json:"temperature_c" binding:"omitempty,temperature=c"`
+	BeverageGrams *float64   `json:"beverage_grams" binding:"omitempty,gt=0"`
+	DeviceModel   *string    `json:"device_model" binding:"omitempty,max=100"`
+	MeasuredAt    *time.Time `json:"measured_at"`
+	BrewID        *string    `json:"brew_id" binding:"omitempty,ulid"`
+}
+
+// TDSReadingBatch represents the refractometer ingestion payload. Apps can
+// send readings buffered while offline in one batch.
+type TDSReadingBatch struct {
+	Readings []TDSReadingInput `json:"readings" binding:"required,min=1,max=100,dive"`
+}
+
+// UpdateTDSReadingRequest represents the reading update payload; a null
+// brew_id detaches the reading
+type UpdateTDSReadingRequest struct {
+	BrewID *string `json:"brew_id" binding:"omitempty,ulid"`
+}
+
+// TDSReadingQuery represents the reading history query parameters
+type TDSReadingQuery struct {
+	PageQuery
+	Unattached bool `form:"unattached"`
+}
+
+// TDSReadingResponse is a refractometer reading. ExtractionYield (percent)
+// is only set on a brew's readings, when both the beverage weight and the
+// brew's dose are known.
+type TDSReadingResponse struct {
+	ID              string     `json:"id"`
+	BrewID          *string    `json:"brew_id"`
+	ClientID        *string    `json:"client_id"`
+	TDSPercent      float64    `json:"tds_percent"`
+	TemperatureC    *float64   `json:"temperature_c"`
+	BeverageGrams   *float64   `json:"beverage_grams"`
+	ExtractionYield *float64   `json:"extraction_yield,omitempty"`
+	DeviceModel     *string    `json:"device_model"`
+	MeasuredAt      *time.Time `json:"measured_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// IngestTDSReadings stores readings from a refractometer companion app,
+// authenticated with an API key. Each reading attaches to the user's brew
+// whose timer session was running when it was taken (or ended up to 30
+// minutes earlier) unless it names a brew. Resent readings with the same
+// client_id return the stored reading.
+func IngestTDSReadings(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req TDSReadingBatch
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		ctx := c.Request.Context()
+		userID := c.GetString("user_id")
+		apiKeyID := c.GetString("api_key_id")
+		now := time.Now()
+
+		// Check every reading before storing any, so a rejected batch can be
+		// resent whole
+		for _, reading := range req.Readings {
+			if reading.MeasuredAt != nil && reading.MeasuredAt.After(now.Add(maxReadingClockSkew)) {
+				respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidRequest)
+				return
+			}
+			if reading.BrewID != nil && !checkOwnedBrew(c, queries, *reading.BrewID, userID) {
+				return
+			}
+		}
+
+		readings := make([]TDSReadingResponse, 0, len(req.Readings))
+		for _, reading := range req.Readings {
+			measuredAt := now
+			if reading.MeasuredAt != nil {
+				measuredAt = *reading.MeasuredAt
+			}
+
+			stored, err := queries.CreateTDSReading(ctx, db.CreateTDSReadingParams{
+				ID:            ulid.MustNew(ulid.Timestamp(now), rand.Reader).String(),
+				UserID:        userID,
+				BrewID:        reading.BrewID,
+				MeasuredAt:    pgtype.Timestamptz{Time: measuredAt, Valid: true},
+				ApiKeyID:      &apiKeyID,
+				ClientID:      reading.ClientID,
+				TdsPercent:    reading.TDSPercent,
+				TemperatureC:  reading.TemperatureC,
+				BeverageGrams: reading.BeverageGrams,
+				DeviceModel:   reading.DeviceModel,
+			})
+			if err == pgx.ErrNoRows {
+				stored, err = queries.GetTDSReadingByClientID(ctx, db.GetTDSReadingByClientIDParams{
+					UserID:   userID,
+					ClientID: reading.ClientID,
+				})
+			}
+			if err != nil {
+				logger.Error("Failed to store TDS reading", "error", err)
+				respond.Error(c, http.StatusInternalServerError, i18n.CodeTDSReadingUpdateFailed)
+				return
+			}
+			readings = append(readings, newTDSReadingResponse(stored, nil))
+		}
+
+		respond.Created(c, readings)
+	}
+}
+
+// ListBrewTDSReadings returns a visible brew's readings in the order they
+// were taken, with extraction yields where possible. Archived brews have
+// none, as brews with readings aren't archived.
+func ListBrewTDSReadings(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		brew, _, ok := loadHistoryBrew(c, queries)
+		if !ok {
+			return
+		}
+
+		rows, err := queries.ListBrewTDSReadings(c.Request.Context(), &brew.ID)
+		if err != nil {
+			logger.Error("Failed to list brew TDS readings", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeTDSReadingFetchFailed)
+			return
+		}
+
+		readings := make([]TDSReadingResponse, 0, len(rows))
+		for _, row := range rows {
+			readings = append(readings, newTDSReadingResponse(row, brew.DoseGrams))
+		}
+
+		respond.OK(c, readings)
+	}
+}
+
+// ListMyTDSReadings returns the current user's readings, newest first;
+// ?unattached=true lists only those not attached to a brew
+func ListMyTDSReadings(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query TDSReadingQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if query.Limit == 0 {
+			query.Limit = defaultPageLimit
+		}
+
+		rows, err := queries.ListUserTDSReadings(c.Request.Context(), db.ListUserTDSReadingsParams{
+			UserID:         c.GetString("user_id"),
+			UnattachedOnly: query.Unattached,
+			RowLimit:       query.Limit,
+			RowOffset:      query.Offset,
+		})
+		if err != nil {
+			logger.Error("Failed to list TDS readings", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeTDSReadingFetchFailed)
+			return
+		}
+
+		readings := make([]TDSReadingResponse, 0, len(rows))
+		for _, row := range rows {
+			readings = append(readings, newTDSReadingResponse(row, nil))
+		}
+
+		respond.Page(c, readings, query.Limit, query.Offset, len(readings))
+	}
+}
+
+// UpdateTDSReading attaches one of the current user's readings to another of
+// their brews, or detaches it
+func UpdateTDSReading(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req UpdateTDSReadingRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		reading, ok := loadOwnedTDSReading(c, queries)
+		if !ok {
+			return
+		}
+		if req.BrewID != nil && !checkOwnedBrew(c, queries, *req.BrewID, reading.UserID) {
+			return
+		}
+
+		reading, err := queries.SetTDSReadingBrew(c.Request.Context(), db.SetTDSReadingBrewParams{
+			BrewID: req.BrewID,
+			ID:     reading.ID,
+		})
+		if err != nil {
+			logger.Error("Failed to update TDS reading", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeTDSReadingUpdateFailed)
+			return
+		}
+
+		respond.OK(c, newTDSReadingResponse(reading, nil))
+	}
+}
+
+// DeleteTDSReading discards one of the current user's readings
+func DeleteTDSReading(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reading, ok := loadOwnedTDSReading(c, queries)
+		if !ok {
+			return
+		}
+
+		if err := queries.DeleteTDSReading(c.Request.Context(), reading.ID); err != nil {
+			logger.Error("Failed to delete TDS reading", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeTDSReadingUpdateFailed)
+			return
+		}
+
+		respond.OK(c, nil)
+	}
+}
+
+// loadOwnedTDSReading fetches the :id reading if it belongs to the current
+// user, writing an error response otherwise
+func loadOwnedTDSReading(c *gin.Context, queries *db.Queries) (db.TdsReading, bool) {
+	reading, err := queries.GetTDSReading(c.Request.Context(), c.Param("id"))
+	if err != nil && err != pgx.ErrNoRows {
+		logger.Error("Failed to get TDS reading", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeTDSReadingFetchFailed)
+		return reading, false
+	}
+	if err == pgx.ErrNoRows || reading.UserID != c.GetString("user_id") {
+		respond.Error(c, http.StatusNotFound, i18n.CodeTDSReadingNotFound)
+		return reading, false
+	}
+	return reading, true
+}
+
+// checkOwnedBrew reports whether brewID is one of userID's brews, writing an
+// error response otherwise
+func checkOwnedBrew(c *gin.Context, queries *db.Queries, brewID, userID string) bool {
+	brew, err := queries.GetBrewByID(c.Request.Context(), brewID)
+	if err != nil && err != pgx.ErrNoRows {
+		logger.Error("Failed to get brew", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewFetchFailed)
+		return false
+	}
+	allowed := false
+	if err == nil {
+		if allowed, err = mayChangeBrew(c.Request.Context(), queries, brew, userID); err != nil {
+			logger.Error("Failed to get organization member role", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeBrewFetchFailed)
+			return false
+		}
+	}
+	if !allowed {
+		respond.Error(c, http.StatusNotFound, i18n.CodeBrewNotFound)
+		return false
+	}
+	return true
+}
+
+// newTDSReadingResponse converts a stored reading. With the brew's dose, it
+// estimates extraction yield as TDS × beverage weight ÷ dose.
+func newTDSReadingResponse(reading db.TdsReading, doseGrams *float64) TDSReadingResponse {
+	resp := TDSReadingResponse{
+		ID:            reading.ID,
+		BrewID:        reading.BrewID,
+		ClientID:      reading.ClientID,
+		TDSPercent:    reading.TdsPercent,
+		TemperatureC:  reading.TemperatureC,
+		BeverageGrams: reading.BeverageGrams,
+		DeviceModel:   reading.DeviceModel,
+		MeasuredAt:    timePtr(reading.MeasuredAt),
+		CreatedAt:     reading.CreatedAt,
+	}
+	if doseGrams != nil && *doseGrams > 0 && reading.BeverageGrams != nil {
+		v := reading.TdsPercent * *reading.BeverageGrams / *doseGrams
+		resp.ExtractionYield = &v
+	}
+	return resp
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// StartTimerRequest represents the timer start payload. RemindInSeconds
+// schedules a steeping reminder notification, e.g. 18 hours for cold brew.
+type StartTimerRequest struct {
+	RemindInSeconds *int32 `json:"remind_in_seconds" binding:"omitempty,min=1"`
+}
+
+// StartBrewTimer starts (or restarts) a timer session on one of the current
+// user's brews
+func StartBrewTimer(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req StartTimerRequest
+		// The body is optional; an empty one starts a timer without a reminder
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				respond.Invalid(c, err)
+				return
+			}
+		}
+
+		pref, ok := requestUnits(c, queries)
+		if !ok {
+			return
+		}
+
+		userID := c.GetString("user_id")
+		brew, err := queries.StartBrewTimer(c.Request.Context(), db.StartBrewTimerParams{
+			RemindInSeconds: req.RemindInSeconds,
+			ID:              c.Param("id"),
+			UserID:          &userID,
+		})
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusNotFound, i18n.CodeBrewNotFound)
+				return
+			}
+			logger.Error("Failed to start brew timer", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeTimerUpdateFailed)
+			return
+		}
+
+		respond.OK(c, newBrewResponse(brew, pref))
+	}
+}
+
+// StopBrewTimer ends the running timer session on one of the current user's
+// brews and records the elapsed brew time
+func StopBrewTimer(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		userID := c.GetString("user_id")
+
+		pref, ok := requestUnits(c, queries)
+		if !ok {
+			return
+		}
+
+		brew, err := queries.StopBrewTimer(ctx, db.StopBrewTimerParams{
+			ID:        c.Param("id"),
+			CreatedBy: &userID,
+		})
+		if err == nil {
+			respond.OK(c, newBrewResponse(brew, pref))
+			return
+		}
+		if err != pgx.ErrNoRows {
+			logger.Error("Failed to stop brew timer", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeTimerUpdateFailed)
+			return
+		}
+
+		// No rows: tell a missing brew apart from one with no running timer
+		existing, err := queries.GetBrewByID(ctx, c.Param("id"))
+		if err != nil || existing.CreatedBy == nil || *existing.CreatedBy != userID {
+			respond.Error(c, http.StatusNotFound, i18n.CodeBrewNotFound)
+			return
+		}
+		respond.Error(c, http.StatusConflict, i18n.CodeTimerNotRunning)
+	}
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"brewd/internal/auth"
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+)
+
+// totpIssuer names the account in authenticator apps
+const totpIssuer = "brewd"
+
+// TOTPSetupResponse is a new TOTP secret, as text and as the otpauth://
+// URI authenticator apps scan from a QR code
+type TOTPSetupResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// TOTPCodeRequest carries a code from the user's authenticator app
+type TOTPCodeRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+// SetupTOTP starts TOTP enrollment for the current user with a new secret.
+// Step-up keeps using emailed codes until the secret is confirmed, and
+// setting up again replaces an unconfirmed secret.
+func SetupTOTP(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		userID := c.GetString("user_id")
+		totp, err := queries.GetUserTOTP(ctx, userID)
+		if err != nil {
+			logger.Error("Failed to get TOTP enrollment", "user_id", userID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeTOTPUpdateFailed)
+			return
+		}
+		if totp.TotpEnabledAt.Valid {
+			respond.Error(c, http.StatusConflict, i18n.CodeTOTPAlreadyEnabled)
+			return
+		}
+
+		secret, err := auth.NewTOTPSecret()
+		if err != nil {
+			logger.Error("Failed to generate TOTP secret", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeTOTPUpdateFailed)
+			return
+		}
+		updated, err := queries.SetPendingTOTPSecret(ctx, db.SetPendingTOTPSecretParams{
+			ID:         userID,
+			TotpSecret: &secret,
+		})
+		if err != nil {
+			logger.Error("Failed to set TOTP secret", "user_id", userID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeTOTPUpdateFailed)
+			return
+		}
+		// Lost a race with a concurrent confirmation
+		if updated == 0 {
+			respond.Error(c, http.StatusConflict, i18n.CodeTOTPAlreadyEnabled)
+			return
+		}
+
+		respond.OK(c, TOTPSetupResponse{
+			Secret: secret,
+			URI:    auth.TOTPURI(totpIssuer, totp.Username, secret),
+		})
+	}
+}
+
+// ConfirmTOTP enables the current user's pending TOTP secret, given a code
+// from it that shows the authenticator app has it
+func ConfirmTOTP(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req TOTPCodeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		ctx := c.Request.Context()
+		userID := c.GetString("user_id")
+		totp, err := queries.GetUserTOTP(ctx, userID)
+		if err != nil {
+			logger.Error("Failed to get TOTP enrollment", "user_id", userID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeTOTPUpdateFailed)
+			return
+		}
+		if totp.TotpEnabledAt.Valid {
+			respond.Error(c, http.StatusConflict, i18n.CodeTOTPAlreadyEnabled)
+			return
+		}
+		if totp.TotpSecret == nil {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeTOTPNotSetUp)
+			return
+		}
+
+		step, ok := auth.ValidateTOTP(*totp.TotpSecret, req.Code, time.Now())
+		if !ok {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeTOTPInvalid)
+			return
+		}
+		enabled, err := queries.EnableTOTP(ctx, db.EnableTOTPParams{ID: userID, Step: &step})
+		if err != nil {
+			logger.Error("Failed to enable TOTP", "user_id", userID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeTOTPUpdateFailed)
+			return
+		}
+		if enabled == 0 {
+			respond.Error(c, http.StatusConflict, i18n.CodeTOTPAlreadyEnabled)
+			return
+		}
+
+		logger.Info("TOTP enabled", "user_id", userID)
+		respond.OK(c, nil)
+	}
+}
+
+// DisableTOTP turns the current user's TOTP off, given a current code, so
+// step-up falls back to emailed codes
+func DisableTOTP(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req TOTPCodeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		ctx := c.Request.Context()
+		userID := c.GetString("user_id")
+		totp, err := queries.GetUserTOTP(ctx, userID)
+		if err != nil {
+			logger.Error("Failed to get TOTP enrollment", "user_id", userID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeTOTPUpdateFailed)
+			return
+		}
+		if !totp.TotpEnabledAt.Valid || totp.TotpSecret == nil {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeTOTPNotEnabled)
+			return
+		}
+
+		step, ok := auth.ValidateTOTP(*totp.TotpSecret, req.Code, time.Now())
+		if ok {
+			fresh, err := queries.UseTOTPStep(ctx, db.UseTOTPStepParams{ID: userID, Step: &step})
+			if err != nil {
+				logger.Error("Failed to record TOTP step", "user_id", userID, "error", err)
+				respond.Error(c, http.StatusInternalServerError, i18n.CodeTOTPUpdateFailed)
+				return
+			}
+			ok = fresh > 0
+		}
+		if !ok {
+			respond.Error(c, http.StatusBadRequest, i18n.CodeTOTPInvalid)
+			return
+		}
+
+		if err := queries.DisableTOTP(ctx, userID); err != nil {
+			logger.Error("Failed to disable TOTP", "user_id", userID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeTOTPUpdateFailed)
+			return
+		}
+
+		logger.Info("TOTP disabled", "user_id", userID)
+		respond.OK(c, nil)
+	}
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListTrustedDevices returns the current user's trusted devices, newest
+// first. Tokens are never shown again after they're issued.
+func ListTrustedDevices(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+		devices, err := queries.ListTrustedDevices(c.Request.Context(), userID)
+		if err != nil {
+			logger.Error("Failed to list trusted devices", "user_id", userID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeTrustedDeviceFetchFailed)
+			return
+		}
+
+		respond.OK(c, devices)
+	}
+}
+
+// RevokeTrustedDevice stops trusting one of the current user's devices, so
+// its next login goes through step-up again
+func RevokeTrustedDevice(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+		rows, err := queries.RevokeTrustedDevice(c.Request.Context(), db.RevokeTrustedDeviceParams{
+			ID:     c.Param("id"),
+			UserID: userID,
+		})
+		if err != nil {
+			logger.Error("Failed to revoke trusted device", "user_id", userID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeTrustedDeviceUpdateFailed)
+			return
+		}
+		if rows == 0 {
+			respond.Error(c, http.StatusNotFound, i18n.CodeTrustedDeviceNotFound)
+			return
+		}
+
+		respond.OK(c, nil)
+	}
+}
+
+// RevokeTrustedDevices stops trusting all of the current user's devices
+func RevokeTrustedDevices(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+		rows, err := queries.RevokeTrustedDevices(c.Request.Context(), userID)
+		if err != nil {
+			logger.Error("Failed to revoke trusted devices", "user_id", userID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeTrustedDeviceUpdateFailed)
+			return
+		}
+
+		logger.Info("Trusted devices revoked", "user_id", userID, "count", rows)
+		respond.OK(c, nil)
+	}
+}
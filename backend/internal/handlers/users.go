@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"brewd/internal/auth"
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/plans"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// ChangeUsernameRequest represents the username change payload
+type ChangeUsernameRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=30"`
+}
+
+// ChangeUsername updates the current user's username under the identity policy
+// and returns a fresh token, since the username is embedded in the claims
+func ChangeUsername(queries *db.Queries, authService auth.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ChangeUsernameRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		username, err := auth.NormalizeUsername(req.Username)
+		if err != nil {
+			code := usernamePolicyCode(err)
+			respond.Error(c, http.StatusBadRequest, code)
+			return
+		}
+
+		ctx := c.Request.Context()
+		userID := c.GetString("user_id")
+
+		// Changing only the case of your own username is allowed
+		if auth.FoldUsername(username) != auth.FoldUsername(c.GetString("username")) {
+			available, err := queries.CheckUsernameAvailability(ctx, username)
+			if err != nil {
+				logger.Error("Failed to check username availability", "error", err)
+				respond.Error(c, http.StatusInternalServerError, i18n.CodeAvailabilityCheckFailed)
+				return
+			}
+			if !available {
+				respond.Error(c, http.StatusConflict, i18n.CodeUsernameTaken)
+				return
+			}
+		}
+
+		user, err := queries.UpdateUsername(ctx, db.UpdateUsernameParams{
+			ID:       userID,
+			Username: username,
+		})
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				respond.Error(c, http.StatusNotFound, i18n.CodeUserNotFound)
+				return
+			}
+			if isUniqueViolation(err) {
+				respond.Error(c, http.StatusConflict, i18n.CodeUsernameTaken)
+				return
+			}
+			logger.Error("Failed to update username", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeUsernameUpdateFailed)
+			return
+		}
+
+		token, err := authService.GenerateToken(user.ID, user.Username)
+		if err != nil {
+			logger.Error("Failed to generate token", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeTokenGenerationFailed)
+			return
+		}
+
+		logger.Info("Username changed", "user_id", user.ID, "username", user.Username)
+
+		respond.OK(c, AuthResponse{
+			Token: token,
+			User: UserInfo{
+				ID:       user.ID,
+				Username: user.Username,
+				Email:    user.Email,
+			},
+		})
+	}
+}
+
+// ProfileResponse represents the current user's profile, with their
+// storage usage
+type ProfileResponse struct {
+	ID                string               `json:"id"`
+	Username          string               `json:"username"`
+	Email             string               `json:"email"`
+	ProfilePictureURL *string              `json:"profile_picture_url"`
+	Bio               *string              `json:"bio"`
+	Location          *string              `json:"location"`
+	JoinedAt          *time.Time           `json:"joined_at"`
+	Storage           StorageUsageResponse `json:"storage"`
+}
+
+// ProfileRequest represents the editable profile fields. It is the document
+// Update Profile's merge patch applies to, so null clears a field.
+type ProfileRequest struct {
+	ProfilePictureURL *string `json:"profile_picture_url" binding:"omitempty,url,max=2048"`
+	Bio               *string `json:"bio" binding:"omitempty,max=500"`
+	Location          *string `json:"location" binding:"omitempty,max=100"`
+}
+
+// GetProfile returns the current user's profile
+func GetProfile(queries *db.Queries, tiers *plans.Cache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := loadProfile(c, queries)
+		if !ok {
+			return
+		}
+
+		respondProfile(c, queries, tiers, user)
+	}
+}
+
+// UpdateProfile applies a JSON Merge Patch to the current user's profile:
+// fields left out of the patch keep their values and null clears one
+func UpdateProfile(queries *db.Queries, tiers *plans.Cache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := loadProfile(c, queries)
+		if !ok {
+			return
+		}
+
+		var req ProfileRequest
+		if _, ok := bindMergePatch(c, ProfileRequest{
+			ProfilePictureURL: user.ProfilePictureUrl,
+			Bio:               user.Bio,
+			Location:          user.Location,
+		}, &req); !ok {
+			return
+		}
+
+		updated, err := queries.UpdateUserProfile(c.Request.Context(), db.UpdateUserProfileParams{
+			ID:                user.ID,
+			ProfilePictureUrl: req.ProfilePictureURL,
+			Bio:               req.Bio,
+			Location:          req.Location,
+		})
+		if err != nil {
+			logger.Error("Failed to update profile", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeProfileUpdateFailed)
+			return
+		}
+
+		logger.Info("Profile updated", "user_id", updated.ID)
+
+		respondProfile(c, queries, tiers, db.GetUserByIDRow(updated))
+	}
+}
+
+// loadProfile fetches the current user, writing an error response on failure
+func loadProfile(c *gin.Context, queries *db.Queries) (db.GetUserByIDRow, bool) {
+	user, err := queries.GetUserByID(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			respond.Error(c, http.StatusNotFound, i18n.CodeUserNotFound)
+			return user, false
+		}
+		logger.Error("Failed to get user", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeInternal)
+		return user, false
+	}
+	return user, true
+}
+
+// respondProfile responds with user's profile and their storage usage
+func respondProfile(c *gin.Context, queries *db.Queries, tiers *plans.Cache, user db.GetUserByIDRow) {
+	resp := newProfileResponse(user)
+	storage, err := storageUsage(c.Request.Context(), queries, user.ID, tiers.Get(c.Request.Context(), user.ID))
+	if err != nil {
+		logger.Error("Failed to get user storage", "user_id", user.ID, "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeStorageFetchFailed)
+		return
+	}
+	resp.Storage = storage
+	respond.OK(c, resp)
+}
+
+// newProfileResponse builds the profile response for a user record
+func newProfileResponse(user db.GetUserByIDRow) ProfileResponse {
+	return ProfileResponse{
+		ID:                user.ID,
+		Username:          user.Username,
+		Email:             user.Email,
+		ProfilePictureURL: user.ProfilePictureUrl,
+		Bio:               user.Bio,
+		Location:          user.Location,
+		JoinedAt:          timePtr(user.JoinedAt),
+	}
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"brewd/internal/auth"
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/invites"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+	"brewd/internal/waitlist"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WaitlistRequest is the part of a registration read in waitlist mode.
+// Registrations with an invite code go ahead; the rest join the waitlist.
+type WaitlistRequest struct {
+	Email      string `json:"email" binding:"required,email"`
+	InviteCode string `json:"invite_code" binding:"omitempty,max=16"`
+}
+
+// WaitlistResponse tells a registering user they joined the waitlist
+// rather than getting an account
+type WaitlistResponse struct {
+	Waitlisted bool `json:"waitlisted"`
+}
+
+// WaitlistEntryResponse is a waitlisted email, with the invite it got once
+// approved
+type WaitlistEntryResponse struct {
+	Email        string     `json:"email"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ApprovedAt   *time.Time `json:"approved_at"`
+	ApprovedBy   *string    `json:"approved_by"`
+	InviteCode   *string    `json:"invite_code"`
+	InviteSentAt *time.Time `json:"invite_sent_at"`
+	SendAttempts int32      `json:"send_attempts"`
+	LastError    *string    `json:"last_error"`
+}
+
+// WaitlistQuery selects pending or approved waitlist entries
+type WaitlistQuery struct {
+	PageQuery
+	Status string `form:"status" binding:"omitempty,oneof=pending approved"`
+}
+
+// ApproveWaitlistRequest approves either the Count oldest pending entries
+// or the given Emails
+type ApproveWaitlistRequest struct {
+	Count  int      `json:"count" binding:"required_without=Emails,excluded_with=Emails,omitempty,min=1,max=500"`
+	Emails []string `json:"emails" binding:"omitempty,min=1,max=500,dive,email"`
+}
+
+// joinWaitlist queues email on the waitlist, unless it is at a disposable
+// domain and disposableEmails rejects those. The response is the same
+// whether or not the address already has an account or a place in line.
+func joinWaitlist(c *gin.Context, queries *db.Queries, email, disposableEmails string) {
+	email, err := auth.NormalizeEmail(email)
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidEmail)
+		return
+	}
+	if _, ok := checkDisposableEmail(c, queries, disposableEmails, email); !ok {
+		return
+	}
+
+	if err := queries.JoinWaitlist(c.Request.Context(), email); err != nil {
+		logger.Error("Failed to join waitlist", "error", err)
+		respond.Error(c, http.StatusInternalServerError, i18n.CodeRegistrationFailed)
+		return
+	}
+
+	respond.Status(c, http.StatusAccepted, WaitlistResponse{Waitlisted: true})
+}
+
+// AdminListWaitlist returns pending waitlist entries oldest first, or with
+// status=approved, approved entries newest first
+func AdminListWaitlist(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query WaitlistQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+		if query.Limit == 0 {
+			query.Limit = defaultPageLimit
+		}
+
+		entries, err := queries.ListWaitlist(c.Request.Context(), db.ListWaitlistParams{
+			Approved:  query.Status == "approved",
+			RowLimit:  query.Limit,
+			RowOffset: query.Offset,
+		})
+		if err != nil {
+			logger.Error("Failed to list waitlist", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeWaitlistFetchFailed)
+			return
+		}
+
+		resp := newWaitlistEntryResponses(entries)
+		respond.Page(c, resp, query.Limit, query.Offset, len(resp))
+	}
+}
+
+// AdminApproveWaitlist approves a batch of waitlist entries, creating a
+// single-use invite from the admin for each. The waitlist worker mails
+// the invites.
+func AdminApproveWaitlist(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ApproveWaitlistRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.Invalid(c, err)
+			return
+		}
+
+		batchSize := req.Count
+		var emails []string
+		if len(req.Emails) > 0 {
+			batchSize = len(req.Emails)
+			for _, email := range req.Emails {
+				normalized, err := auth.NormalizeEmail(email)
+				if err != nil {
+					respond.Error(c, http.StatusBadRequest, i18n.CodeInvalidEmail)
+					return
+				}
+				emails = append(emails, normalized)
+			}
+		}
+
+		// One invite code per entry approved; unused ones are discarded
+		codes := make([]string, 0, batchSize)
+		for range batchSize {
+			code, err := invites.NewCode()
+			if err != nil {
+				logger.Error("Failed to generate invite code", "error", err)
+				respond.Error(c, http.StatusInternalServerError, i18n.CodeWaitlistUpdateFailed)
+				return
+			}
+			codes = append(codes, code)
+		}
+
+		adminID := c.GetString("user_id")
+		expiresAt := time.Now().Add(waitlist.InviteValidity)
+		approved, err := queries.ApproveWaitlistBatch(c.Request.Context(), db.ApproveWaitlistBatchParams{
+			Emails:     emails,
+			Codes:      codes,
+			ApprovedBy: adminID,
+			ExpiresAt:  timestamptz(&expiresAt),
+		})
+		if err != nil {
+			logger.Error("Failed to approve waitlist batch", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeWaitlistUpdateFailed)
+			return
+		}
+
+		logger.Info("Waitlist batch approved", "count", len(approved), "admin_id", adminID)
+		respond.OK(c, newWaitlistEntryResponses(approved))
+	}
+}
+
+func newWaitlistEntryResponses(entries []db.WaitlistEntry) []WaitlistEntryResponse {
+	resp := make([]WaitlistEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		resp = append(resp, WaitlistEntryResponse{
+			Email:        entry.Email,
+			CreatedAt:    entry.CreatedAt,
+			ApprovedAt:   timePtr(entry.ApprovedAt),
+			ApprovedBy:   entry.ApprovedBy,
+			InviteCode:   entry.InviteCode,
+			InviteSentAt: timePtr(entry.InviteSentAt),
+			SendAttempts: entry.SendAttempts,
+			LastError:    entry.LastError,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+
+	"brewd/internal/i18n"
+	"brewd/internal/respond"
+	"brewd/internal/webapp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// webAppIndex is the web app's entry page, served for its client-side
+// routes
+const webAppIndex = "index.html"
+
+// WebApp serves the web app's pages in files for requests no API route or
+// static file matched. A path naming a page the export rendered serves its
+// .html file; other page loads (requests accepting HTML) get index.html,
+// so client-side routes load the app. Anything else is a JSON 404. Pages
+// are revalidated on every load.
+func WebApp(files fs.FS) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			respond.Error(c, http.StatusNotFound, i18n.CodeRouteNotFound)
+			return
+		}
+
+		name := strings.TrimPrefix(path.Clean(c.Request.URL.Path), "/")
+		if name == "" {
+			name = webAppIndex
+		}
+		if path.Ext(name) == "" && webapp.Exists(files, name+".html") {
+			name += ".html"
+		}
+		if path.Ext(name) != ".html" || !webapp.Exists(files, name) {
+			if path.Ext(name) != "" || !strings.Contains(c.GetHeader("Accept"), "text/html") {
+				respond.Error(c, http.StatusNotFound, i18n.CodeRouteNotFound)
+				return
+			}
+			name = webAppIndex
+		}
+
+		webapp.Serve(c.Writer, c.Request, files, name, webapp.CachePage)
+	}
+}
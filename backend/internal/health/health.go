@@ -0,0 +1,164 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Statuses of a check and of the report as a whole. A report is degraded
+// when only optional checks fail.
+const (
+	StatusHealthy   = "healthy"
+	StatusDegraded  = "degraded"
+	StatusUnhealthy = "unhealthy"
+)
+
+// StatusDraining is reported, instead of running the checks, while the
+// instance drains before shutting down
+const StatusDraining = "draining"
+
+// Default options for checks that don't set their own
+const (
+	DefaultTimeout  = 5 * time.Second
+	DefaultCacheTTL = 30 * time.Second
+)
+
+// Checker checks that a downstream dependency is reachable and working.
+// Implementations for new dependencies (a cache, object storage) register
+// one with the Registry.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts a function to Checker
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Options configure how a checker is run
+type Options struct {
+	// Timeout bounds each run of the check
+	Timeout time.Duration
+	// CacheTTL is how long a result is reused, so frequent probes from load
+	// balancers don't reach the dependency each time
+	CacheTTL time.Duration
+	// Optional checks degrade the report when they fail, rather than making
+	// the instance unready: it can still serve most requests without them
+	Optional bool
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Name       string    `json:"name"`
+	Status     string    `json:"status"`
+	Optional   bool      `json:"optional"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// Report is the outcome of every registered check
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// check is a registered checker and its latest result
+type check struct {
+	name    string
+	checker Checker
+	opts    Options
+
+	mu     sync.Mutex
+	result Result
+}
+
+// Registry runs the checks of downstream dependencies for the readiness
+// endpoint. Each check runs with its own timeout, concurrently with the
+// others, and its result is cached for its CacheTTL.
+type Registry struct {
+	mu     sync.Mutex
+	checks []*check
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a check called name. Zero options take the defaults.
+func (r *Registry) Register(name string, checker Checker, opts Options) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.CacheTTL <= 0 {
+		opts.CacheTTL = DefaultCacheTTL
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, &check{name: name, checker: checker, opts: opts})
+}
+
+// Check runs every check, reusing results younger than their CacheTTL, and
+// reports them in the order they were registered
+func (r *Registry) Check(ctx context.Context) Report {
+	r.mu.Lock()
+	checks := append([]*check(nil), r.checks...)
+	r.mu.Unlock()
+
+	report := Report{Status: StatusHealthy, Checks: make([]Result, len(checks))}
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Checks[i] = c.run(ctx)
+		}()
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status == StatusHealthy {
+			continue
+		}
+		if !result.Optional {
+			report.Status = StatusUnhealthy
+			break
+		}
+		report.Status = StatusDegraded
+	}
+	return report
+}
+
+// run returns the cached result if it's fresh, or else checks again. Runs
+// of one check are serialized, so concurrent probes share one result.
+func (c *check) run(ctx context.Context) Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if !c.result.CheckedAt.IsZero() && now.Sub(c.result.CheckedAt) < c.opts.CacheTTL {
+		return c.result
+	}
+
+	// The result is shared, so a probe that hangs up mustn't cut it short
+	checkCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.opts.Timeout)
+	defer cancel()
+
+	err := c.checker.Check(checkCtx)
+	c.result = Result{
+		Name:       c.name,
+		Status:     StatusHealthy,
+		Optional:   c.opts.Optional,
+		DurationMs: time.Since(now).Milliseconds(),
+		CheckedAt:  now,
+	}
+	if err != nil {
+		c.result.Status = StatusUnhealthy
+		c.result.Error = err.Error()
+	}
+	return c.result
+}
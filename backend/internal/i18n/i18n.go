@@ -0,0 +1,72 @@
+package i18n
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// Code is a stable, client-facing error identifier. Codes never change once
+// published; only the localized messages attached to them do.
+type Code string
+
+// Context key holding the negotiated language.Tag
+const contextKey = "locale"
+
+// Supported lists the languages with message catalogs; the first is the fallback
+var Supported = []language.Tag{
+	language.English,
+	language.Spanish,
+	language.French,
+}
+
+var matcher = language.NewMatcher(Supported)
+
+// Negotiate picks the best supported language for an Accept-Language header
+func Negotiate(acceptLanguage string) language.Tag {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return Supported[0]
+	}
+	_, index, _ := matcher.Match(tags...)
+	return Supported[index]
+}
+
+// SetLocale stores the negotiated language on the request context
+func SetLocale(c *gin.Context, tag language.Tag) {
+	c.Set(contextKey, tag)
+}
+
+// Locale returns the request's negotiated language, defaulting to English
+func Locale(c *gin.Context) language.Tag {
+	if v, ok := c.Get(contextKey); ok {
+		if tag, ok := v.(language.Tag); ok {
+			return tag
+		}
+	}
+	return Supported[0]
+}
+
+// Message returns the localized message for code, falling back to English and
+// finally to the code itself when no translation exists
+func Message(tag language.Tag, code Code) string {
+	if msg, ok := catalogs[tag][code]; ok {
+		return msg
+	}
+	if msg, ok := catalogs[Supported[0]][code]; ok {
+		return msg
+	}
+	return string(code)
+}
+
+// T returns the message for code in the request's negotiated language
+func T(c *gin.Context, code Code) string {
+	return Message(Locale(c), code)
+}
+
+// Tf returns the message for code formatted with args, for messages that
+// carry placeholders
+func Tf(c *gin.Context, code Code, args ...any) string {
+	return fmt.Sprintf(T(c, code), args...)
+}
@@ -0,0 +1,1048 @@
+package i18n
+
+import "golang.org/x/text/language"
+
+// Error codes returned in the "code" field of error responses
+const (
+	CodeInvalidRequest                Code = "invalid_request"
+	CodeUsernameOrEmailRequired       Code = "username_or_email_required"
+	CodeInvalidEmail                  Code = "invalid_email"
+	CodeUsernameLength                Code = "username_length"
+	CodeUsernameChars                 Code = "username_chars"
+	CodeUsernameReserved              Code = "username_reserved"
+	CodeUsernameBlocked               Code = "username_blocked"
+	CodeEmailTaken                    Code = "email_taken"
+	CodeUsernameTaken                 Code = "username_taken"
+	CodeIdentityTaken                 Code = "identity_taken"
+	CodeRateLimited                   Code = "rate_limited"
+	CodeInternal                      Code = "internal_error"
+	CodeInvalidCredentials            Code = "invalid_credentials"
+	CodeAuthHeaderRequired            Code = "auth_header_required"
+	CodeAuthHeaderInvalid             Code = "auth_header_invalid"
+	CodeTokenRequired                 Code = "token_required"
+	CodeTokenExpired                  Code = "token_expired"
+	CodeTokenInvalid                  Code = "token_invalid"
+	CodeTokenGenerationFailed         Code = "token_generation_failed"
+	CodeUserNotFound                  Code = "user_not_found"
+	CodeAvailabilityCheckFailed       Code = "availability_check_failed"
+	CodeRegistrationFailed            Code = "registration_failed"
+	CodeAuthenticationFailed          Code = "authentication_failed"
+	CodeUsernameUpdateFailed          Code = "username_update_failed"
+	CodeBrewNotFound                  Code = "brew_not_found"
+	CodeBrewArchived                  Code = "brew_archived"
+	CodeBrewCreateFailed              Code = "brew_create_failed"
+	CodeBrewFetchFailed               Code = "brew_fetch_failed"
+	CodeInvalidUnits                  Code = "invalid_units"
+	CodeWaterTempOutOfRange           Code = "water_temp_out_of_range"
+	CodePreferencesUpdateFailed       Code = "preferences_update_failed"
+	CodeInvalidTimezone               Code = "invalid_timezone"
+	CodeStatsFetchFailed              Code = "stats_fetch_failed"
+	CodeBrewParamsInvalid             Code = "brew_params_invalid"
+	CodeParamRequired                 Code = "param_required"
+	CodeParamOutOfRange               Code = "param_out_of_range"
+	CodeInvalidTimeRange              Code = "invalid_time_range"
+	CodeTimerNotRunning               Code = "timer_not_running"
+	CodeTimerUpdateFailed             Code = "timer_update_failed"
+	CodeForbidden                     Code = "forbidden"
+	CodeRecipeNotFound                Code = "recipe_not_found"
+	CodeRevisionNotFound              Code = "revision_not_found"
+	CodeRecipeCreateFailed            Code = "recipe_create_failed"
+	CodeRecipeFetchFailed             Code = "recipe_fetch_failed"
+	CodeRecipeUpdateFailed            Code = "recipe_update_failed"
+	CodeCollaboratorNotFound          Code = "collaborator_not_found"
+	CodeInvitationNotFound            Code = "invitation_not_found"
+	CodeCannotInviteSelf              Code = "cannot_invite_self"
+	CodeCollaboratorUpdateFailed      Code = "collaborator_update_failed"
+	CodeCollaboratorFetchFailed       Code = "collaborator_fetch_failed"
+	CodeInvalidCompareIDs             Code = "invalid_compare_ids"
+	CodeBeanBagNotFound               Code = "bean_bag_not_found"
+	CodeBeanBagCreateFailed           Code = "bean_bag_create_failed"
+	CodeBeanBagFetchFailed            Code = "bean_bag_fetch_failed"
+	CodeBeanBagUpdateFailed           Code = "bean_bag_update_failed"
+	CodeInvalidCurrency               Code = "invalid_currency"
+	CodeUnknownFlavor                 Code = "unknown_flavor"
+	CodeFlavorFetchFailed             Code = "flavor_fetch_failed"
+	CodeFlavorUpdateFailed            Code = "flavor_update_failed"
+	CodeCuppingNotFound               Code = "cupping_session_not_found"
+	CodeCuppingSampleNotFound         Code = "cupping_sample_not_found"
+	CodeCuppingParticipantNotFound    Code = "cupping_participant_not_found"
+	CodeCuppingRevealed               Code = "cupping_session_revealed"
+	CodeCuppingNotRevealed            Code = "cupping_session_not_revealed"
+	CodeCuppingFetchFailed            Code = "cupping_fetch_failed"
+	CodeCuppingUpdateFailed           Code = "cupping_update_failed"
+	CodeBrewEventNotFound             Code = "brew_event_not_found"
+	CodeBrewEventFetchFailed          Code = "brew_event_fetch_failed"
+	CodeBrewEventUpdateFailed         Code = "brew_event_update_failed"
+	CodeEventRecipePrivate            Code = "event_recipe_private"
+	CodeEventStartInPast              Code = "event_start_in_past"
+	CodeClubNotFound                  Code = "club_not_found"
+	CodeClubFetchFailed               Code = "club_fetch_failed"
+	CodeClubUpdateFailed              Code = "club_update_failed"
+	CodeClubInviteInvalid             Code = "club_invite_invalid"
+	CodeClubInviteNotFound            Code = "club_invite_not_found"
+	CodeClubMemberNotFound            Code = "club_member_not_found"
+	CodeClubOwnerCannotLeave          Code = "club_owner_cannot_leave"
+	CodeClubShareNotFound             Code = "club_share_not_found"
+	CodeClubRecipePrivate             Code = "club_recipe_private"
+	CodeChallengeNotFound             Code = "challenge_not_found"
+	CodeChallengeFetchFailed          Code = "challenge_fetch_failed"
+	CodeChallengeUpdateFailed         Code = "challenge_update_failed"
+	CodeBadgeFetchFailed              Code = "badge_fetch_failed"
+	CodeRoasterNotFound               Code = "roaster_not_found"
+	CodeRoasterFetchFailed            Code = "roaster_fetch_failed"
+	CodeRoasterUpdateFailed           Code = "roaster_update_failed"
+	CodeRoasterExists                 Code = "roaster_exists"
+	CodeRoasterNameTaken              Code = "roaster_name_taken"
+	CodeRoasterNotVerified            Code = "roaster_not_verified"
+	CodeBeanNotFound                  Code = "bean_not_found"
+	CodeBeanFetchFailed               Code = "bean_fetch_failed"
+	CodeBeanUpdateFailed              Code = "bean_update_failed"
+	CodeOfficialRecipeInvalid         Code = "official_recipe_invalid"
+	CodeInvalidOrigin                 Code = "invalid_origin"
+	CodeEmbedURLUnsupported           Code = "embed_url_unsupported"
+	CodeEmbedFormatUnsupported        Code = "embed_format_unsupported"
+	CodeSitemapNotFound               Code = "sitemap_not_found"
+	CodeAPIKeyRequired                Code = "api_key_required"
+	CodeAPIKeyInvalid                 Code = "api_key_invalid"
+	CodeAPIKeyScope                   Code = "api_key_scope"
+	CodeAPIKeyNotFound                Code = "api_key_not_found"
+	CodeAPIKeyUpdateFailed            Code = "api_key_update_failed"
+	CodeTDSReadingNotFound            Code = "tds_reading_not_found"
+	CodeTDSReadingFetchFailed         Code = "tds_reading_fetch_failed"
+	CodeTDSReadingUpdateFailed        Code = "tds_reading_update_failed"
+	CodePourCurveNotFound             Code = "pour_curve_not_found"
+	CodePourCurveFetchFailed          Code = "pour_curve_fetch_failed"
+	CodePourCurveUpdateFailed         Code = "pour_curve_update_failed"
+	CodePourSampleInvalid             Code = "pour_sample_invalid"
+	CodePourCurveTooLong              Code = "pour_curve_too_long"
+	CodeNoRunningBrew                 Code = "no_running_brew"
+	CodeAutomationWebhookNotFound     Code = "automation_webhook_not_found"
+	CodeAutomationWebhookUpdateFailed Code = "automation_webhook_update_failed"
+	CodeAutomationURLInvalid          Code = "automation_url_invalid"
+	CodeCalendarFeedNotFound          Code = "calendar_feed_not_found"
+	CodeCalendarFeedFetchFailed       Code = "calendar_feed_fetch_failed"
+	CodeCalendarFeedUpdateFailed      Code = "calendar_feed_update_failed"
+	CodeCustomFieldNotFound           Code = "custom_field_not_found"
+	CodeCustomFieldFetchFailed        Code = "custom_field_fetch_failed"
+	CodeCustomFieldUpdateFailed       Code = "custom_field_update_failed"
+	CodeCustomFieldNameInvalid        Code = "custom_field_name_invalid"
+	CodeCustomFieldTaken              Code = "custom_field_taken"
+	CodeCustomFieldLimit              Code = "custom_field_limit"
+	CodeCustomFieldsInvalid           Code = "custom_fields_invalid"
+	CodeCustomFieldUnknown            Code = "custom_field_unknown"
+	CodeCustomFieldNumber             Code = "custom_field_number"
+	CodeCustomFieldText               Code = "custom_field_text"
+	CodeCustomFieldBoolean            Code = "custom_field_boolean"
+	CodeDraftNotFound                 Code = "draft_not_found"
+	CodeDraftFetchFailed              Code = "draft_fetch_failed"
+	CodeDraftUpdateFailed             Code = "draft_update_failed"
+	CodeDraftLimit                    Code = "draft_limit"
+	CodeDraftTooLarge                 Code = "draft_too_large"
+	CodeDraftIncomplete               Code = "draft_incomplete"
+	CodeBrewedAtInFuture              Code = "brewed_at_in_future"
+	CodeSyncFetchFailed               Code = "sync_fetch_failed"
+	CodeSyncIDInvalid                 Code = "sync_id_invalid"
+	CodeSyncVectorInvalid             Code = "sync_vector_invalid"
+	CodeSyncDeleteUnsupported         Code = "sync_delete_unsupported"
+	CodeBrewUpdateFailed              Code = "brew_update_failed"
+	CodeUnsupportedMediaType          Code = "unsupported_media_type"
+	CodeProfileUpdateFailed           Code = "profile_update_failed"
+	CodeUnknownField                  Code = "unknown_field"
+	CodeSuggestionFetchFailed         Code = "suggestion_fetch_failed"
+	CodeTrendingFetchFailed           Code = "trending_fetch_failed"
+	CodeRecommendationFetchFailed     Code = "recommendation_fetch_failed"
+	CodeRecommendationUpdateFailed    Code = "recommendation_update_failed"
+	CodeEventBatchTooLarge            Code = "event_batch_too_large"
+	CodeEventIngestFailed             Code = "event_ingest_failed"
+	CodeOpsStatsFetchFailed           Code = "ops_stats_fetch_failed"
+	CodeQuotaExceeded                 Code = "quota_exceeded"
+	CodeBillingUnavailable            Code = "billing_unavailable"
+	CodeCheckoutFailed                Code = "checkout_failed"
+	CodeAlreadySupporter              Code = "already_supporter"
+	CodeWebhookSignatureInvalid       Code = "webhook_signature_invalid"
+	CodeSupporterRequired             Code = "supporter_required"
+	CodePlanFetchFailed               Code = "plan_fetch_failed"
+	CodeReceiptInvalid                Code = "receipt_invalid"
+	CodePurchaseVerifyFailed          Code = "purchase_verify_failed"
+	CodePurchaseInUse                 Code = "purchase_in_use"
+	CodeMailUnavailable               Code = "mail_unavailable"
+	CodeEmailSuppressionFetchFailed   Code = "email_suppression_fetch_failed"
+	CodeEmailSuppressionUpdateFailed  Code = "email_suppression_update_failed"
+	CodeEmailSuppressionNotFound      Code = "email_suppression_not_found"
+	CodeEmailSuppressed               Code = "email_suppressed"
+	CodeEmailSendFailed               Code = "email_send_failed"
+	CodeInviteRequired                Code = "invite_required"
+	CodeInviteInvalid                 Code = "invite_invalid"
+	CodeInviteNotFound                Code = "invite_not_found"
+	CodeInviteQuotaExceeded           Code = "invite_quota_exceeded"
+	CodeInviteUpdateFailed            Code = "invite_update_failed"
+	CodeInviteFetchFailed             Code = "invite_fetch_failed"
+	CodeWaitlistFetchFailed           Code = "waitlist_fetch_failed"
+	CodeWaitlistUpdateFailed          Code = "waitlist_update_failed"
+	CodeCaptchaRequired               Code = "captcha_required"
+	CodeCaptchaFailed                 Code = "captcha_failed"
+	CodeCaptchaUnavailable            Code = "captcha_unavailable"
+	CodeDisposableEmail               Code = "disposable_email"
+	CodeEmailDomainFetchFailed        Code = "email_domain_fetch_failed"
+	CodeEmailDomainUpdateFailed       Code = "email_domain_update_failed"
+	CodeEmailDomainNotFound           Code = "email_domain_not_found"
+	CodeInvalidDomain                 Code = "invalid_domain"
+	CodeAuthEventFetchFailed          Code = "auth_event_fetch_failed"
+	CodeStepUpFailed                  Code = "step_up_failed"
+	CodeStepUpInvalid                 Code = "step_up_invalid"
+	CodeStepUpExpired                 Code = "step_up_expired"
+	CodeTOTPAlreadyEnabled            Code = "totp_already_enabled"
+	CodeTOTPNotSetUp                  Code = "totp_not_set_up"
+	CodeTOTPNotEnabled                Code = "totp_not_enabled"
+	CodeTOTPInvalid                   Code = "totp_invalid"
+	CodeTOTPUpdateFailed              Code = "totp_update_failed"
+	CodeTrustedDeviceFetchFailed      Code = "trusted_device_fetch_failed"
+	CodeTrustedDeviceUpdateFailed     Code = "trusted_device_update_failed"
+	CodeTrustedDeviceNotFound         Code = "trusted_device_not_found"
+	CodeCSRFInvalid                   Code = "csrf_invalid"
+	CodeOIDCClientInvalid             Code = "oidc_client_invalid"
+	CodeOIDCAuthorizeFailed           Code = "oidc_authorize_failed"
+	CodeOIDCClientFetchFailed         Code = "oidc_client_fetch_failed"
+	CodeOIDCClientUpdateFailed        Code = "oidc_client_update_failed"
+	CodeOIDCClientNotFound            Code = "oidc_client_not_found"
+	CodeRedirectURIInvalid            Code = "redirect_uri_invalid"
+	CodeAccountDeactivated            Code = "account_deactivated"
+	CodeSCIMClientFetchFailed         Code = "scim_client_fetch_failed"
+	CodeSCIMClientUpdateFailed        Code = "scim_client_update_failed"
+	CodeSCIMClientNotFound            Code = "scim_client_not_found"
+	CodePolicyAcceptanceRequired      Code = "policy_acceptance_required"
+	CodePolicyOutdated                Code = "policy_outdated"
+	CodePolicyFetchFailed             Code = "policy_fetch_failed"
+	CodePolicyUpdateFailed            Code = "policy_update_failed"
+	CodePolicyVersionTaken            Code = "policy_version_taken"
+	CodeBirthDateRequired             Code = "birth_date_required"
+	CodeBirthDateInvalid              Code = "birth_date_invalid"
+	CodeAgeRequirementNotMet          Code = "age_requirement_not_met"
+	CodeParentEmailInvalid            Code = "parent_email_invalid"
+	CodeParentalConsentPending        Code = "parental_consent_pending"
+	CodeParentalConsentInvalid        Code = "parental_consent_invalid"
+	CodeParentalConsentFailed         Code = "parental_consent_failed"
+	CodeParentalConsentRequired       Code = "parental_consent_required"
+	CodeShareNotFound                 Code = "share_not_found"
+	CodeShareExists                   Code = "share_exists"
+	CodeShareGranteeInvalid           Code = "share_grantee_invalid"
+	CodeShareFetchFailed              Code = "share_fetch_failed"
+	CodeShareUpdateFailed             Code = "share_update_failed"
+	CodeAPIKeyQuotaExceeded           Code = "api_key_quota_exceeded"
+	CodeRequestTimestampInvalid       Code = "request_timestamp_invalid"
+	CodeRequestReplayed               Code = "request_replayed"
+	CodeRequestSignatureInvalid       Code = "request_signature_invalid"
+	CodeRequestBodyTooLarge           Code = "request_body_too_large"
+	CodeBackupsUnavailable            Code = "backups_unavailable"
+	CodeBackupRunning                 Code = "backup_running"
+	CodeBackupStartFailed             Code = "backup_start_failed"
+	CodeBackupFetchFailed             Code = "backup_fetch_failed"
+	CodeOrganizationNotFound          Code = "organization_not_found"
+	CodeOrganizationFetchFailed       Code = "organization_fetch_failed"
+	CodeOrganizationUpdateFailed      Code = "organization_update_failed"
+	CodeOrganizationMemberNotFound    Code = "organization_member_not_found"
+	CodeOrganizationMemberExists      Code = "organization_member_exists"
+	CodeOrganizationLastOwner         Code = "organization_last_owner"
+	CodeRetentionFetchFailed          Code = "retention_fetch_failed"
+	CodeMetricsUnavailable            Code = "metrics_unavailable"
+	CodeRouteNotFound                 Code = "route_not_found"
+	CodeJobLeasesFetchFailed          Code = "job_leases_fetch_failed"
+	CodePhotosUnavailable             Code = "photos_unavailable"
+	CodePhotoRequired                 Code = "photo_required"
+	CodePhotoTooLarge                 Code = "photo_too_large"
+	CodePhotoTypeUnsupported          Code = "photo_type_unsupported"
+	CodePhotoUploadFailed             Code = "photo_upload_failed"
+	CodePhotoNotFound                 Code = "photo_not_found"
+	CodePhotoFetchFailed              Code = "photo_fetch_failed"
+	CodePhotoNotReady                 Code = "photo_not_ready"
+	CodePhotoDeleteFailed             Code = "photo_delete_failed"
+	CodeMediaLinkInvalid              Code = "media_link_invalid"
+	CodeMediaNotFound                 Code = "media_not_found"
+	CodeBackupNotFound                Code = "backup_not_found"
+	CodeBackupNotDownloadable         Code = "backup_not_downloadable"
+	CodeStorageQuotaExceeded          Code = "storage_quota_exceeded"
+	CodeStorageFetchFailed            Code = "storage_fetch_failed"
+)
+
+var catalogs = map[language.Tag]map[Code]string{
+	language.English: {
+		CodeInvalidRequest:                "Invalid request",
+		CodeUsernameOrEmailRequired:       "Username or email is required",
+		CodeInvalidEmail:                  "Invalid email address",
+		CodeUsernameLength:                "Username must be between 3 and 30 characters",
+		CodeUsernameChars:                 "Username may only contain letters, numbers, underscores and periods",
+		CodeUsernameReserved:              "Username is reserved",
+		CodeUsernameBlocked:               "Username is not allowed",
+		CodeEmailTaken:                    "Email already registered",
+		CodeUsernameTaken:                 "Username already taken",
+		CodeIdentityTaken:                 "Username or email already registered",
+		CodeRateLimited:                   "Too many requests, please try again later",
+		CodeInternal:                      "Something went wrong, please try again",
+		CodeInvalidCredentials:            "Invalid email or password",
+		CodeAuthHeaderRequired:            "Authorization header required",
+		CodeAuthHeaderInvalid:             "Invalid authorization header format",
+		CodeTokenRequired:                 "Token required",
+		CodeTokenExpired:                  "Token has expired",
+		CodeTokenInvalid:                  "Invalid token",
+		CodeTokenGenerationFailed:         "Failed to generate authentication token",
+		CodeUserNotFound:                  "User not found",
+		CodeAvailabilityCheckFailed:       "Failed to check availability",
+		CodeRegistrationFailed:            "Failed to create user",
+		CodeAuthenticationFailed:          "Authentication failed",
+		CodeUsernameUpdateFailed:          "Failed to update username",
+		CodeBrewNotFound:                  "Brew not found",
+		CodeBrewArchived:                  "This brew is archived and can't be changed",
+		CodeBrewCreateFailed:              "Failed to create brew",
+		CodeBrewFetchFailed:               "Failed to load brews",
+		CodeInvalidUnits:                  "Unknown unit system or unit",
+		CodeWaterTempOutOfRange:           "Water temperature must be between 0 and 100 °C (32 and 212 °F)",
+		CodePreferencesUpdateFailed:       "Failed to update preferences",
+		CodeInvalidTimezone:               "Unknown timezone",
+		CodeStatsFetchFailed:              "Failed to get stats",
+		CodeBrewParamsInvalid:             "Brew parameters don't fit the brew method",
+		CodeParamRequired:                 "Required for this brew method",
+		CodeParamOutOfRange:               "Must be between %s and %s for this brew method",
+		CodeInvalidTimeRange:              "ended_at requires started_at and must not be before it",
+		CodeTimerNotRunning:               "No timer is running for this brew",
+		CodeTimerUpdateFailed:             "Failed to update brew timer",
+		CodeForbidden:                     "You don't have permission to do that",
+		CodeRecipeNotFound:                "Recipe not found",
+		CodeRevisionNotFound:              "Recipe revision not found",
+		CodeRecipeCreateFailed:            "Failed to create recipe",
+		CodeRecipeFetchFailed:             "Failed to get recipe",
+		CodeRecipeUpdateFailed:            "Failed to update recipe",
+		CodeCollaboratorNotFound:          "Collaborator not found",
+		CodeInvitationNotFound:            "Invitation not found",
+		CodeCannotInviteSelf:              "You cannot invite yourself",
+		CodeCollaboratorUpdateFailed:      "Failed to update collaborators",
+		CodeCollaboratorFetchFailed:       "Failed to fetch collaborators",
+		CodeInvalidCompareIDs:             "Select between %d and %d brews to compare",
+		CodeBeanBagNotFound:               "Bean bag not found",
+		CodeBeanBagCreateFailed:           "Failed to create bean bag",
+		CodeBeanBagFetchFailed:            "Failed to load bean bags",
+		CodeBeanBagUpdateFailed:           "Failed to update bean bag",
+		CodeInvalidCurrency:               "Unknown or unsupported currency",
+		CodeUnknownFlavor:                 "Unknown flavor descriptor",
+		CodeFlavorFetchFailed:             "Failed to load flavors",
+		CodeFlavorUpdateFailed:            "Failed to update flavors",
+		CodeCuppingNotFound:               "Cupping session not found",
+		CodeCuppingSampleNotFound:         "Cupping sample not found",
+		CodeCuppingParticipantNotFound:    "Participant not found",
+		CodeCuppingRevealed:               "This cupping session has already been revealed",
+		CodeCuppingNotRevealed:            "Results are available once the host reveals the session",
+		CodeCuppingFetchFailed:            "Failed to fetch cupping session",
+		CodeCuppingUpdateFailed:           "Failed to update cupping session",
+		CodeBrewEventNotFound:             "Brew event not found",
+		CodeBrewEventFetchFailed:          "Failed to fetch brew events",
+		CodeBrewEventUpdateFailed:         "Failed to update brew event",
+		CodeEventRecipePrivate:            "Only public recipes can be brewed along",
+		CodeEventStartInPast:              "Events must start in the future",
+		CodeClubNotFound:                  "Club not found",
+		CodeClubFetchFailed:               "Failed to fetch club",
+		CodeClubUpdateFailed:              "Failed to update club",
+		CodeClubInviteInvalid:             "This invite link is invalid or has expired",
+		CodeClubInviteNotFound:            "Invite not found",
+		CodeClubMemberNotFound:            "Club member not found",
+		CodeClubOwnerCannotLeave:          "The owner can't leave or be removed; delete the club instead",
+		CodeClubShareNotFound:             "Shared item not found",
+		CodeClubRecipePrivate:             "Only your own or public recipes can be shared",
+		CodeChallengeNotFound:             "Challenge not found",
+		CodeChallengeFetchFailed:          "Failed to fetch challenges",
+		CodeChallengeUpdateFailed:         "Failed to save challenge",
+		CodeBadgeFetchFailed:              "Failed to fetch badges",
+		CodeRoasterNotFound:               "Roaster not found",
+		CodeRoasterFetchFailed:            "Failed to load roasters",
+		CodeRoasterUpdateFailed:           "Failed to save roaster",
+		CodeRoasterExists:                 "You already have a roaster account",
+		CodeRoasterNameTaken:              "Roaster name is already taken",
+		CodeRoasterNotVerified:            "Only verified roasters can publish official listings",
+		CodeBeanNotFound:                  "Bean not found",
+		CodeBeanFetchFailed:               "Failed to load beans",
+		CodeBeanUpdateFailed:              "Failed to save bean",
+		CodeOfficialRecipeInvalid:         "Official recipes must be your own public recipes",
+		CodeInvalidOrigin:                 "Unknown origin country, variety or process",
+		CodeEmbedURLUnsupported:           "URL is not a public brewd recipe or brew",
+		CodeEmbedFormatUnsupported:        "Only the json oEmbed format is supported",
+		CodeSitemapNotFound:               "Sitemap not found",
+		CodeAPIKeyRequired:                "API key required",
+		CodeAPIKeyInvalid:                 "Invalid or revoked API key",
+		CodeAPIKeyScope:                   "API key lacks the required scope",
+		CodeAPIKeyNotFound:                "API key not found",
+		CodeAPIKeyUpdateFailed:            "Failed to save API key",
+		CodeTDSReadingNotFound:            "TDS reading not found",
+		CodeTDSReadingFetchFailed:         "Failed to get TDS readings",
+		CodeTDSReadingUpdateFailed:        "Failed to save TDS reading",
+		CodePourCurveNotFound:             "Pour curve not found",
+		CodePourCurveFetchFailed:          "Failed to get pour curve",
+		CodePourCurveUpdateFailed:         "Failed to save pour curve",
+		CodePourSampleInvalid:             "Invalid pour sample",
+		CodePourCurveTooLong:              "Pour curve is too long",
+		CodeNoRunningBrew:                 "No brew timer is running",
+		CodeAutomationWebhookNotFound:     "Webhook not found",
+		CodeAutomationWebhookUpdateFailed: "Failed to save webhook",
+		CodeAutomationURLInvalid:          "Webhook URL must be an https URL on a public host",
+		CodeCalendarFeedNotFound:          "Calendar feed not found",
+		CodeCalendarFeedFetchFailed:       "Failed to fetch calendar feed",
+		CodeCalendarFeedUpdateFailed:      "Failed to reset calendar feed",
+		CodeCustomFieldNotFound:           "Custom field not found",
+		CodeCustomFieldFetchFailed:        "Failed to fetch custom fields",
+		CodeCustomFieldUpdateFailed:       "Failed to update custom field",
+		CodeCustomFieldNameInvalid:        "Field names must start with a lowercase letter and use only lowercase letters, digits and underscores (up to 50)",
+		CodeCustomFieldTaken:              "You already have a custom field with this name",
+		CodeCustomFieldLimit:              "You can define at most %d custom fields",
+		CodeCustomFieldsInvalid:           "Some custom field values are invalid",
+		CodeCustomFieldUnknown:            "Not one of your custom fields",
+		CodeCustomFieldNumber:             "Must be a number",
+		CodeCustomFieldText:               "Must be text of at most %d characters",
+		CodeCustomFieldBoolean:            "Must be true or false",
+		CodeDraftNotFound:                 "Draft not found",
+		CodeDraftFetchFailed:              "Failed to fetch drafts",
+		CodeDraftUpdateFailed:             "Failed to save draft",
+		CodeDraftLimit:                    "You can keep at most %d drafts",
+		CodeDraftTooLarge:                 "Draft is too large",
+		CodeDraftIncomplete:               "Draft is missing required fields or has invalid values",
+		CodeBrewedAtInFuture:              "brewed_at can't be in the future",
+		CodeSyncFetchFailed:               "Failed to fetch changes",
+		CodeSyncIDInvalid:                 "Record IDs must be ULIDs",
+		CodeSyncVectorInvalid:             "vector must map replica IDs to positive change counts",
+		CodeSyncDeleteUnsupported:         "This kind of record can't be deleted",
+		CodeBrewUpdateFailed:              "Failed to update brew",
+		CodeUnsupportedMediaType:          "Content-Type must be application/merge-patch+json or application/json",
+		CodeProfileUpdateFailed:           "Failed to update profile",
+		CodeUnknownField:                  "Unknown field: %s",
+		CodeSuggestionFetchFailed:         "Failed to load suggestions",
+		CodeTrendingFetchFailed:           "Failed to load trending",
+		CodeRecommendationFetchFailed:     "Failed to load recommendations",
+		CodeRecommendationUpdateFailed:    "Failed to save recommendation feedback",
+		CodeEventBatchTooLarge:            "Event batch is too large",
+		CodeEventIngestFailed:             "Failed to record events",
+		CodeOpsStatsFetchFailed:           "Failed to fetch stats",
+		CodeQuotaExceeded:                 "Your plan's quota has been used up, please try again after it resets",
+		CodeBillingUnavailable:            "Billing is not available",
+		CodeCheckoutFailed:                "Failed to start checkout",
+		CodeAlreadySupporter:              "You are already a supporter",
+		CodeWebhookSignatureInvalid:       "Invalid webhook signature",
+		CodeSupporterRequired:             "This feature is for supporters",
+		CodePlanFetchFailed:               "Failed to fetch plan",
+		CodeReceiptInvalid:                "The store rejected this purchase",
+		CodePurchaseVerifyFailed:          "Failed to verify purchase",
+		CodePurchaseInUse:                 "This purchase belongs to another account",
+		CodeMailUnavailable:               "Email is not available",
+		CodeEmailSuppressionFetchFailed:   "Failed to fetch email suppressions",
+		CodeEmailSuppressionUpdateFailed:  "Failed to update email suppressions",
+		CodeEmailSuppressionNotFound:      "Email address is not suppressed",
+		CodeEmailSuppressed:               "Email address is suppressed",
+		CodeEmailSendFailed:               "Failed to send email",
+		CodeInviteRequired:                "An invite is required to sign up",
+		CodeInviteInvalid:                 "Invite is invalid, expired or used up",
+		CodeInviteNotFound:                "Invite not found",
+		CodeInviteQuotaExceeded:           "You have no invites left",
+		CodeInviteUpdateFailed:            "Failed to update invites",
+		CodeInviteFetchFailed:             "Failed to fetch invites",
+		CodeWaitlistFetchFailed:           "Failed to fetch the waitlist",
+		CodeWaitlistUpdateFailed:          "Failed to update the waitlist",
+		CodeCaptchaRequired:               "Complete the CAPTCHA to continue",
+		CodeCaptchaFailed:                 "CAPTCHA verification failed",
+		CodeCaptchaUnavailable:            "CAPTCHA verification is unavailable",
+		CodeDisposableEmail:               "Disposable email addresses can't be used",
+		CodeEmailDomainFetchFailed:        "Failed to fetch email domains",
+		CodeEmailDomainUpdateFailed:       "Failed to update email domains",
+		CodeEmailDomainNotFound:           "Email domain override not found",
+		CodeInvalidDomain:                 "Invalid domain",
+		CodeAuthEventFetchFailed:          "Failed to fetch sign-in history",
+		CodeStepUpFailed:                  "Failed to start sign-in verification",
+		CodeStepUpInvalid:                 "Incorrect verification code",
+		CodeStepUpExpired:                 "Verification expired, please sign in again",
+		CodeTOTPAlreadyEnabled:            "Authenticator app is already enabled",
+		CodeTOTPNotSetUp:                  "Set up an authenticator app first",
+		CodeTOTPNotEnabled:                "Authenticator app is not enabled",
+		CodeTOTPInvalid:                   "Incorrect authenticator code",
+		CodeTOTPUpdateFailed:              "Failed to update authenticator app settings",
+		CodeTrustedDeviceFetchFailed:      "Failed to fetch trusted devices",
+		CodeTrustedDeviceUpdateFailed:     "Failed to update trusted devices",
+		CodeTrustedDeviceNotFound:         "Trusted device not found",
+		CodeCSRFInvalid:                   "Missing or invalid CSRF token",
+		CodeOIDCClientInvalid:             "Unknown client or redirect URI",
+		CodeOIDCAuthorizeFailed:           "Failed to sign in to the service",
+		CodeOIDCClientFetchFailed:         "Failed to fetch OIDC clients",
+		CodeOIDCClientUpdateFailed:        "Failed to update OIDC clients",
+		CodeOIDCClientNotFound:            "OIDC client not found",
+		CodeRedirectURIInvalid:            "Redirect URIs must be absolute HTTPS URLs without a fragment",
+		CodeAccountDeactivated:            "This account has been deactivated",
+		CodeSCIMClientFetchFailed:         "Failed to fetch SCIM clients",
+		CodeSCIMClientUpdateFailed:        "Failed to update SCIM clients",
+		CodeSCIMClientNotFound:            "SCIM client not found",
+		CodePolicyAcceptanceRequired:      "You need to accept the current terms of service and privacy policy",
+		CodePolicyOutdated:                "A newer version of this policy has been published; review it and accept again",
+		CodePolicyFetchFailed:             "Failed to load policies",
+		CodePolicyUpdateFailed:            "Failed to save policy",
+		CodePolicyVersionTaken:            "That version of this policy already exists",
+		CodeBirthDateRequired:             "Date of birth is required",
+		CodeBirthDateInvalid:              "Invalid date of birth",
+		CodeAgeRequirementNotMet:          "You're not old enough to create an account",
+		CodeParentEmailInvalid:            "Enter a parent's email address that isn't your own",
+		CodeParentalConsentPending:        "This account is waiting for a parent's consent",
+		CodeParentalConsentInvalid:        "This consent link is invalid or has expired",
+		CodeParentalConsentFailed:         "Failed to record consent",
+		CodeParentalConsentRequired:       "A parent's consent is needed to create an account at your age; enter their email address",
+		CodeShareNotFound:                 "Share not found",
+		CodeShareExists:                   "This is already shared with them",
+		CodeShareGranteeInvalid:           "Share with either a user or a club you belong to, not yourself",
+		CodeShareFetchFailed:              "Failed to load shares",
+		CodeShareUpdateFailed:             "Failed to update sharing",
+		CodeAPIKeyQuotaExceeded:           "This API key's request quota and burst credits have been used up, please try again after the window resets",
+		CodeRequestTimestampInvalid:       "The request's timestamp or nonce is missing, or its timestamp is too far from the server's clock",
+		CodeRequestReplayed:               "This request was already received",
+		CodeRequestSignatureInvalid:       "The request's signature is missing or doesn't match it",
+		CodeRequestBodyTooLarge:           "The request body is too large",
+		CodeBackupsUnavailable:            "Backups are not configured",
+		CodeBackupRunning:                 "A backup is already running",
+		CodeBackupStartFailed:             "Failed to start backup",
+		CodeBackupFetchFailed:             "Failed to fetch backups",
+		CodeOrganizationNotFound:          "Organization not found",
+		CodeOrganizationFetchFailed:       "Failed to fetch organization",
+		CodeOrganizationUpdateFailed:      "Failed to update organization",
+		CodeOrganizationMemberNotFound:    "Organization member not found",
+		CodeOrganizationMemberExists:      "This user is already a member",
+		CodeOrganizationLastOwner:         "An organization needs at least one owner; make another member owner first",
+		CodeRetentionFetchFailed:          "Failed to fetch the retention report",
+		CodeMetricsUnavailable:            "Metrics are not enabled",
+		CodeRouteNotFound:                 "Not found",
+		CodeJobLeasesFetchFailed:          "Failed to fetch the job leases",
+		CodePhotosUnavailable:             "Photo uploads are not configured",
+		CodePhotoRequired:                 "A photo file is required in the photo field",
+		CodePhotoTooLarge:                 "Photo is too large",
+		CodePhotoTypeUnsupported:          "Photos must be JPEG, PNG, GIF or WebP images",
+		CodePhotoUploadFailed:             "Failed to upload photo",
+		CodePhotoNotFound:                 "Photo not found",
+		CodePhotoFetchFailed:              "Failed to load photos",
+		CodePhotoNotReady:                 "Photo is still being processed or could not be processed",
+		CodePhotoDeleteFailed:             "Failed to delete photo",
+		CodeMediaLinkInvalid:              "Media link is invalid or has expired",
+		CodeMediaNotFound:                 "Media not found",
+		CodeBackupNotFound:                "Backup not found",
+		CodeBackupNotDownloadable:         "Backup has no dump to download",
+		CodeStorageQuotaExceeded:          "Your plan's storage quota would be exceeded, please delete some photos or upgrade your plan",
+		CodeStorageFetchFailed:            "Failed to fetch storage usage",
+	},
+	language.Spanish: {
+		CodeInvalidRequest:                "Solicitud no válida",
+		CodeUsernameOrEmailRequired:       "Se requiere nombre de usuario o correo electrónico",
+		CodeInvalidEmail:                  "Dirección de correo electrónico no válida",
+		CodeUsernameLength:                "El nombre de usuario debe tener entre 3 y 30 caracteres",
+		CodeUsernameChars:                 "El nombre de usuario solo puede contener letras, números, guiones bajos y puntos",
+		CodeUsernameReserved:              "El nombre de usuario está reservado",
+		CodeUsernameBlocked:               "El nombre de usuario no está permitido",
+		CodeEmailTaken:                    "El correo electrónico ya está registrado",
+		CodeUsernameTaken:                 "El nombre de usuario ya está en uso",
+		CodeIdentityTaken:                 "El nombre de usuario o el correo electrónico ya están registrados",
+		CodeRateLimited:                   "Demasiadas solicitudes, inténtalo de nuevo más tarde",
+		CodeInternal:                      "Algo salió mal, inténtalo de nuevo",
+		CodeInvalidCredentials:            "Correo electrónico o contraseña no válidos",
+		CodeAuthHeaderRequired:            "Se requiere el encabezado de autorización",
+		CodeAuthHeaderInvalid:             "Formato de encabezado de autorización no válido",
+		CodeTokenRequired:                 "Se requiere un token",
+		CodeTokenExpired:                  "El token ha caducado",
+		CodeTokenInvalid:                  "Token no válido",
+		CodeTokenGenerationFailed:         "No se pudo generar el token de autenticación",
+		CodeUserNotFound:                  "Usuario no encontrado",
+		CodeAvailabilityCheckFailed:       "No se pudo comprobar la disponibilidad",
+		CodeRegistrationFailed:            "No se pudo crear el usuario",
+		CodeAuthenticationFailed:          "Error de autenticación",
+		CodeUsernameUpdateFailed:          "No se pudo actualizar el nombre de usuario",
+		CodeBrewNotFound:                  "Preparación no encontrada",
+		CodeBrewArchived:                  "Esta preparación está archivada y no se puede modificar",
+		CodeBrewCreateFailed:              "No se pudo crear la preparación",
+		CodeBrewFetchFailed:               "No se pudieron cargar las preparaciones",
+		CodeInvalidUnits:                  "Sistema de unidades o unidad desconocida",
+		CodeWaterTempOutOfRange:           "La temperatura del agua debe estar entre 0 y 100 °C (32 y 212 °F)",
+		CodePreferencesUpdateFailed:       "No se pudieron actualizar las preferencias",
+		CodeInvalidTimezone:               "Zona horaria desconocida",
+		CodeStatsFetchFailed:              "No se pudieron obtener las estadísticas",
+		CodeBrewParamsInvalid:             "Los parámetros no corresponden al método de preparación",
+		CodeParamRequired:                 "Obligatorio para este método de preparación",
+		CodeParamOutOfRange:               "Debe estar entre %s y %s para este método de preparación",
+		CodeInvalidTimeRange:              "ended_at requiere started_at y no puede ser anterior a este",
+		CodeTimerNotRunning:               "No hay un temporizador en marcha para esta preparación",
+		CodeTimerUpdateFailed:             "No se pudo actualizar el temporizador",
+		CodeForbidden:                     "No tienes permiso para hacer eso",
+		CodeRecipeNotFound:                "Receta no encontrada",
+		CodeRevisionNotFound:              "Revisión de la receta no encontrada",
+		CodeRecipeCreateFailed:            "No se pudo crear la receta",
+		CodeRecipeFetchFailed:             "No se pudo obtener la receta",
+		CodeRecipeUpdateFailed:            "No se pudo actualizar la receta",
+		CodeCollaboratorNotFound:          "Colaborador no encontrado",
+		CodeInvitationNotFound:            "Invitación no encontrada",
+		CodeCannotInviteSelf:              "No puedes invitarte a ti mismo",
+		CodeCollaboratorUpdateFailed:      "No se pudieron actualizar los colaboradores",
+		CodeCollaboratorFetchFailed:       "No se pudieron obtener los colaboradores",
+		CodeInvalidCompareIDs:             "Selecciona entre %d y %d preparaciones para comparar",
+		CodeBeanBagNotFound:               "Bolsa de café no encontrada",
+		CodeBeanBagCreateFailed:           "No se pudo crear la bolsa de café",
+		CodeBeanBagFetchFailed:            "No se pudieron cargar las bolsas de café",
+		CodeBeanBagUpdateFailed:           "No se pudo actualizar la bolsa de café",
+		CodeInvalidCurrency:               "Moneda desconocida o no admitida",
+		CodeUnknownFlavor:                 "Descriptor de sabor desconocido",
+		CodeFlavorFetchFailed:             "No se pudieron cargar los sabores",
+		CodeFlavorUpdateFailed:            "No se pudieron actualizar los sabores",
+		CodeCuppingNotFound:               "Sesión de cata no encontrada",
+		CodeCuppingSampleNotFound:         "Muestra de cata no encontrada",
+		CodeCuppingParticipantNotFound:    "Participante no encontrado",
+		CodeCuppingRevealed:               "Esta sesión de cata ya ha sido revelada",
+		CodeCuppingNotRevealed:            "Los resultados estarán disponibles cuando el anfitrión revele la sesión",
+		CodeCuppingFetchFailed:            "Error al obtener la sesión de cata",
+		CodeCuppingUpdateFailed:           "Error al actualizar la sesión de cata",
+		CodeBrewEventNotFound:             "Evento de preparación no encontrado",
+		CodeBrewEventFetchFailed:          "Error al obtener los eventos de preparación",
+		CodeBrewEventUpdateFailed:         "Error al actualizar el evento de preparación",
+		CodeEventRecipePrivate:            "Solo se pueden usar recetas públicas en eventos",
+		CodeEventStartInPast:              "Los eventos deben comenzar en el futuro",
+		CodeClubNotFound:                  "Club no encontrado",
+		CodeClubFetchFailed:               "Error al obtener el club",
+		CodeClubUpdateFailed:              "Error al actualizar el club",
+		CodeClubInviteInvalid:             "Este enlace de invitación no es válido o ha caducado",
+		CodeClubInviteNotFound:            "Invitación no encontrada",
+		CodeClubMemberNotFound:            "Miembro del club no encontrado",
+		CodeClubOwnerCannotLeave:          "El propietario no puede salir ni ser eliminado; elimina el club en su lugar",
+		CodeClubShareNotFound:             "Elemento compartido no encontrado",
+		CodeClubRecipePrivate:             "Solo puedes compartir tus propias recetas o recetas públicas",
+		CodeChallengeNotFound:             "Reto no encontrado",
+		CodeChallengeFetchFailed:          "No se pudieron obtener los retos",
+		CodeChallengeUpdateFailed:         "No se pudo guardar el reto",
+		CodeBadgeFetchFailed:              "No se pudieron obtener las insignias",
+		CodeRoasterNotFound:               "Tostador no encontrado",
+		CodeRoasterFetchFailed:            "No se pudieron cargar los tostadores",
+		CodeRoasterUpdateFailed:           "No se pudo guardar el tostador",
+		CodeRoasterExists:                 "Ya tienes una cuenta de tostador",
+		CodeRoasterNameTaken:              "El nombre del tostador ya está en uso",
+		CodeRoasterNotVerified:            "Solo los tostadores verificados pueden publicar fichas oficiales",
+		CodeBeanNotFound:                  "Café no encontrado",
+		CodeBeanFetchFailed:               "No se pudieron cargar los cafés",
+		CodeBeanUpdateFailed:              "No se pudo guardar el café",
+		CodeOfficialRecipeInvalid:         "Las recetas oficiales deben ser recetas públicas propias",
+		CodeInvalidOrigin:                 "País de origen, variedad o proceso desconocido",
+		CodeEmbedURLUnsupported:           "La URL no es una receta o preparación pública de brewd",
+		CodeEmbedFormatUnsupported:        "Solo se admite el formato oEmbed json",
+		CodeSitemapNotFound:               "Mapa del sitio no encontrado",
+		CodeAPIKeyRequired:                "Se requiere una clave de API",
+		CodeAPIKeyInvalid:                 "Clave de API no válida o revocada",
+		CodeAPIKeyScope:                   "La clave de API no tiene el permiso necesario",
+		CodeAPIKeyNotFound:                "Clave de API no encontrada",
+		CodeAPIKeyUpdateFailed:            "No se pudo guardar la clave de API",
+		CodeTDSReadingNotFound:            "Lectura de TDS no encontrada",
+		CodeTDSReadingFetchFailed:         "No se pudieron obtener las lecturas de TDS",
+		CodeTDSReadingUpdateFailed:        "No se pudo guardar la lectura de TDS",
+		CodePourCurveNotFound:             "Curva de vertido no encontrada",
+		CodePourCurveFetchFailed:          "No se pudo obtener la curva de vertido",
+		CodePourCurveUpdateFailed:         "No se pudo guardar la curva de vertido",
+		CodePourSampleInvalid:             "Muestra de vertido no válida",
+		CodePourCurveTooLong:              "La curva de vertido es demasiado larga",
+		CodeNoRunningBrew:                 "No hay ningún temporizador de preparación en marcha",
+		CodeAutomationWebhookNotFound:     "Webhook no encontrado",
+		CodeAutomationWebhookUpdateFailed: "No se pudo guardar el webhook",
+		CodeAutomationURLInvalid:          "La URL del webhook debe ser https en un host público",
+		CodeCalendarFeedNotFound:          "Calendario no encontrado",
+		CodeCalendarFeedFetchFailed:       "No se pudo obtener el calendario",
+		CodeCalendarFeedUpdateFailed:      "No se pudo restablecer el calendario",
+		CodeCustomFieldNotFound:           "Campo personalizado no encontrado",
+		CodeCustomFieldFetchFailed:        "No se pudieron obtener los campos personalizados",
+		CodeCustomFieldUpdateFailed:       "No se pudo actualizar el campo personalizado",
+		CodeCustomFieldNameInvalid:        "Los nombres de campo deben empezar por una letra minúscula y usar solo minúsculas, dígitos y guiones bajos (hasta 50)",
+		CodeCustomFieldTaken:              "Ya tienes un campo personalizado con este nombre",
+		CodeCustomFieldLimit:              "Puedes definir como máximo %d campos personalizados",
+		CodeCustomFieldsInvalid:           "Algunos valores de campos personalizados no son válidos",
+		CodeCustomFieldUnknown:            "No es uno de tus campos personalizados",
+		CodeCustomFieldNumber:             "Debe ser un número",
+		CodeCustomFieldText:               "Debe ser un texto de %d caracteres como máximo",
+		CodeCustomFieldBoolean:            "Debe ser true o false",
+		CodeDraftNotFound:                 "Borrador no encontrado",
+		CodeDraftFetchFailed:              "No se pudieron obtener los borradores",
+		CodeDraftUpdateFailed:             "No se pudo guardar el borrador",
+		CodeDraftLimit:                    "Puedes guardar como máximo %d borradores",
+		CodeDraftTooLarge:                 "El borrador es demasiado grande",
+		CodeDraftIncomplete:               "Al borrador le faltan campos obligatorios o tiene valores no válidos",
+		CodeBrewedAtInFuture:              "brewed_at no puede estar en el futuro",
+		CodeSyncFetchFailed:               "No se pudieron obtener los cambios",
+		CodeSyncIDInvalid:                 "Los ID de registro deben ser ULID",
+		CodeSyncVectorInvalid:             "vector debe asignar a cada ID de réplica un número de cambios positivo",
+		CodeSyncDeleteUnsupported:         "Este tipo de registro no se puede eliminar",
+		CodeBrewUpdateFailed:              "No se pudo actualizar la preparación",
+		CodeUnsupportedMediaType:          "El Content-Type debe ser application/merge-patch+json o application/json",
+		CodeProfileUpdateFailed:           "No se pudo actualizar el perfil",
+		CodeUnknownField:                  "Campo desconocido: %s",
+		CodeSuggestionFetchFailed:         "No se pudieron cargar las sugerencias",
+		CodeTrendingFetchFailed:           "No se pudieron cargar las tendencias",
+		CodeRecommendationFetchFailed:     "No se pudieron cargar las recomendaciones",
+		CodeRecommendationUpdateFailed:    "No se pudo guardar la opinión sobre la recomendación",
+		CodeEventBatchTooLarge:            "El lote de eventos es demasiado grande",
+		CodeEventIngestFailed:             "No se pudieron registrar los eventos",
+		CodeOpsStatsFetchFailed:           "No se pudieron obtener las estadísticas",
+		CodeQuotaExceeded:                 "Has agotado la cuota de tu plan, inténtalo de nuevo cuando se restablezca",
+		CodeBillingUnavailable:            "La facturación no está disponible",
+		CodeCheckoutFailed:                "No se pudo iniciar el pago",
+		CodeAlreadySupporter:              "Ya eres colaborador",
+		CodeWebhookSignatureInvalid:       "Firma de webhook no válida",
+		CodeSupporterRequired:             "Esta función es para colaboradores",
+		CodePlanFetchFailed:               "No se pudo obtener el plan",
+		CodeReceiptInvalid:                "La tienda rechazó esta compra",
+		CodePurchaseVerifyFailed:          "No se pudo verificar la compra",
+		CodePurchaseInUse:                 "Esta compra pertenece a otra cuenta",
+		CodeMailUnavailable:               "El correo electrónico no está disponible",
+		CodeEmailSuppressionFetchFailed:   "No se pudieron obtener las supresiones de correo",
+		CodeEmailSuppressionUpdateFailed:  "No se pudieron actualizar las supresiones de correo",
+		CodeEmailSuppressionNotFound:      "La dirección de correo no está suprimida",
+		CodeEmailSuppressed:               "La dirección de correo está suprimida",
+		CodeEmailSendFailed:               "No se pudo enviar el correo",
+		CodeInviteRequired:                "Se necesita una invitación para registrarse",
+		CodeInviteInvalid:                 "La invitación no es válida, ha caducado o ya se usó",
+		CodeInviteNotFound:                "Invitación no encontrada",
+		CodeInviteQuotaExceeded:           "No te quedan invitaciones",
+		CodeInviteUpdateFailed:            "No se pudieron actualizar las invitaciones",
+		CodeInviteFetchFailed:             "No se pudieron obtener las invitaciones",
+		CodeWaitlistFetchFailed:           "No se pudo obtener la lista de espera",
+		CodeWaitlistUpdateFailed:          "No se pudo actualizar la lista de espera",
+		CodeCaptchaRequired:               "Completa el CAPTCHA para continuar",
+		CodeCaptchaFailed:                 "La verificación CAPTCHA falló",
+		CodeCaptchaUnavailable:            "La verificación CAPTCHA no está disponible",
+		CodeDisposableEmail:               "No se pueden usar direcciones de correo desechables",
+		CodeEmailDomainFetchFailed:        "No se pudieron obtener los dominios de correo",
+		CodeEmailDomainUpdateFailed:       "No se pudieron actualizar los dominios de correo",
+		CodeEmailDomainNotFound:           "Excepción de dominio de correo no encontrada",
+		CodeInvalidDomain:                 "Dominio no válido",
+		CodeAuthEventFetchFailed:          "No se pudo obtener el historial de inicios de sesión",
+		CodeStepUpFailed:                  "No se pudo iniciar la verificación del inicio de sesión",
+		CodeStepUpInvalid:                 "Código de verificación incorrecto",
+		CodeStepUpExpired:                 "La verificación ha caducado, inicia sesión de nuevo",
+		CodeTOTPAlreadyEnabled:            "La aplicación de autenticación ya está activada",
+		CodeTOTPNotSetUp:                  "Configura primero una aplicación de autenticación",
+		CodeTOTPNotEnabled:                "La aplicación de autenticación no está activada",
+		CodeTOTPInvalid:                   "Código de autenticación incorrecto",
+		CodeTOTPUpdateFailed:              "No se pudo actualizar la configuración de la aplicación de autenticación",
+		CodeTrustedDeviceFetchFailed:      "No se pudieron obtener los dispositivos de confianza",
+		CodeTrustedDeviceUpdateFailed:     "No se pudieron actualizar los dispositivos de confianza",
+		CodeTrustedDeviceNotFound:         "Dispositivo de confianza no encontrado",
+		CodeCSRFInvalid:                   "Token CSRF ausente o no válido",
+		CodeOIDCClientInvalid:             "Cliente o URI de redirección desconocidos",
+		CodeOIDCAuthorizeFailed:           "No se pudo iniciar sesión en el servicio",
+		CodeOIDCClientFetchFailed:         "No se pudieron obtener los clientes OIDC",
+		CodeOIDCClientUpdateFailed:        "No se pudieron actualizar los clientes OIDC",
+		CodeOIDCClientNotFound:            "Cliente OIDC no encontrado",
+		CodeRedirectURIInvalid:            "Las URI de redirección deben ser URL HTTPS absolutas sin fragmento",
+		CodeAccountDeactivated:            "Esta cuenta ha sido desactivada",
+		CodeSCIMClientFetchFailed:         "No se pudieron obtener los clientes SCIM",
+		CodeSCIMClientUpdateFailed:        "No se pudieron actualizar los clientes SCIM",
+		CodeSCIMClientNotFound:            "Cliente SCIM no encontrado",
+		CodePolicyAcceptanceRequired:      "Debes aceptar los términos del servicio y la política de privacidad vigentes",
+		CodePolicyOutdated:                "Se ha publicado una versión más reciente de esta política; revísala y acéptala de nuevo",
+		CodePolicyFetchFailed:             "No se pudieron cargar las políticas",
+		CodePolicyUpdateFailed:            "No se pudo guardar la política",
+		CodePolicyVersionTaken:            "Esa versión de esta política ya existe",
+		CodeBirthDateRequired:             "La fecha de nacimiento es obligatoria",
+		CodeBirthDateInvalid:              "Fecha de nacimiento no válida",
+		CodeAgeRequirementNotMet:          "No tienes la edad suficiente para crear una cuenta",
+		CodeParentEmailInvalid:            "Introduce el correo electrónico de uno de tus padres, distinto del tuyo",
+		CodeParentalConsentPending:        "Esta cuenta está a la espera del consentimiento de uno de los padres",
+		CodeParentalConsentInvalid:        "Este enlace de consentimiento no es válido o ha caducado",
+		CodeParentalConsentFailed:         "No se pudo registrar el consentimiento",
+		CodeParentalConsentRequired:       "Para crear una cuenta a tu edad se necesita el consentimiento de uno de tus padres; introduce su correo electrónico",
+		CodeShareNotFound:                 "Elemento compartido no encontrado",
+		CodeShareExists:                   "Ya está compartido con ellos",
+		CodeShareGranteeInvalid:           "Comparte con un usuario o con un club al que pertenezcas, no contigo mismo",
+		CodeShareFetchFailed:              "No se pudieron cargar los elementos compartidos",
+		CodeShareUpdateFailed:             "No se pudo actualizar el uso compartido",
+		CodeAPIKeyQuotaExceeded:           "La cuota de solicitudes y los créditos de ráfaga de esta clave de API se han agotado, inténtalo de nuevo cuando se restablezca la ventana",
+		CodeRequestTimestampInvalid:       "Falta la marca de tiempo o el nonce de la solicitud, o su marca de tiempo está demasiado lejos del reloj del servidor",
+		CodeRequestReplayed:               "Esta solicitud ya se recibió",
+		CodeRequestSignatureInvalid:       "Falta la firma de la solicitud o no coincide con ella",
+		CodeRequestBodyTooLarge:           "El cuerpo de la solicitud es demasiado grande",
+		CodeBackupsUnavailable:            "Las copias de seguridad no están configuradas",
+		CodeBackupRunning:                 "Ya hay una copia de seguridad en curso",
+		CodeBackupStartFailed:             "No se pudo iniciar la copia de seguridad",
+		CodeBackupFetchFailed:             "No se pudieron obtener las copias de seguridad",
+		CodeOrganizationNotFound:          "Organización no encontrada",
+		CodeOrganizationFetchFailed:       "No se pudo obtener la organización",
+		CodeOrganizationUpdateFailed:      "No se pudo actualizar la organización",
+		CodeOrganizationMemberNotFound:    "Miembro de la organización no encontrado",
+		CodeOrganizationMemberExists:      "Este usuario ya es miembro",
+		CodeOrganizationLastOwner:         "Una organización necesita al menos un propietario; nombra primero a otro miembro propietario",
+		CodeRetentionFetchFailed:          "No se pudo obtener el informe de retención",
+		CodeMetricsUnavailable:            "Las métricas no están habilitadas",
+		CodeRouteNotFound:                 "No encontrado",
+		CodeJobLeasesFetchFailed:          "No se pudieron obtener las concesiones de tareas",
+		CodePhotosUnavailable:             "La subida de fotos no está configurada",
+		CodePhotoRequired:                 "Se requiere un archivo de foto en el campo photo",
+		CodePhotoTooLarge:                 "La foto es demasiado grande",
+		CodePhotoTypeUnsupported:          "Las fotos deben ser imágenes JPEG, PNG, GIF o WebP",
+		CodePhotoUploadFailed:             "No se pudo subir la foto",
+		CodePhotoNotFound:                 "Foto no encontrada",
+		CodePhotoFetchFailed:              "No se pudieron cargar las fotos",
+		CodePhotoNotReady:                 "La foto aún se está procesando o no se pudo procesar",
+		CodePhotoDeleteFailed:             "No se pudo eliminar la foto",
+		CodeMediaLinkInvalid:              "El enlace multimedia no es válido o ha caducado",
+		CodeMediaNotFound:                 "Archivo multimedia no encontrado",
+		CodeBackupNotFound:                "Copia de seguridad no encontrada",
+		CodeBackupNotDownloadable:         "La copia de seguridad no tiene un volcado para descargar",
+		CodeStorageQuotaExceeded:          "Se superaría la cuota de almacenamiento de tu plan, elimina algunas fotos o mejora tu plan",
+		CodeStorageFetchFailed:            "No se pudo obtener el uso de almacenamiento",
+	},
+	language.French: {
+		CodeInvalidRequest:                "Requête invalide",
+		CodeUsernameOrEmailRequired:       "Le nom d'utilisateur ou l'adresse e-mail est requis",
+		CodeInvalidEmail:                  "Adresse e-mail invalide",
+		CodeUsernameLength:                "Le nom d'utilisateur doit contenir entre 3 et 30 caractères",
+		CodeUsernameChars:                 "Le nom d'utilisateur ne peut contenir que des lettres, des chiffres, des tirets bas et des points",
+		CodeUsernameReserved:              "Ce nom d'utilisateur est réservé",
+		CodeUsernameBlocked:               "Ce nom d'utilisateur n'est pas autorisé",
+		CodeEmailTaken:                    "Cette adresse e-mail est déjà enregistrée",
+		CodeUsernameTaken:                 "Ce nom d'utilisateur est déjà pris",
+		CodeIdentityTaken:                 "Ce nom d'utilisateur ou cette adresse e-mail est déjà enregistré",
+		CodeRateLimited:                   "Trop de requêtes, veuillez réessayer plus tard",
+		CodeInternal:                      "Une erreur s'est produite, veuillez réessayer",
+		CodeInvalidCredentials:            "Adresse e-mail ou mot de passe invalide",
+		CodeAuthHeaderRequired:            "En-tête d'autorisation requis",
+		CodeAuthHeaderInvalid:             "Format d'en-tête d'autorisation invalide",
+		CodeTokenRequired:                 "Jeton requis",
+		CodeTokenExpired:                  "Le jeton a expiré",
+		CodeTokenInvalid:                  "Jeton invalide",
+		CodeTokenGenerationFailed:         "Impossible de générer le jeton d'authentification",
+		CodeUserNotFound:                  "Utilisateur introuvable",
+		CodeAvailabilityCheckFailed:       "Impossible de vérifier la disponibilité",
+		CodeRegistrationFailed:            "Impossible de créer l'utilisateur",
+		CodeAuthenticationFailed:          "Échec de l'authentification",
+		CodeUsernameUpdateFailed:          "Impossible de mettre à jour le nom d'utilisateur",
+		CodeBrewNotFound:                  "Préparation introuvable",
+		CodeBrewArchived:                  "Cette préparation est archivée et ne peut pas être modifiée",
+		CodeBrewCreateFailed:              "Impossible de créer la préparation",
+		CodeBrewFetchFailed:               "Impossible de charger les préparations",
+		CodeInvalidUnits:                  "Système d'unités ou unité inconnu",
+		CodeWaterTempOutOfRange:           "La température de l'eau doit être comprise entre 0 et 100 °C (32 et 212 °F)",
+		CodePreferencesUpdateFailed:       "Impossible de mettre à jour les préférences",
+		CodeInvalidTimezone:               "Fuseau horaire inconnu",
+		CodeStatsFetchFailed:              "Impossible de récupérer les statistiques",
+		CodeBrewParamsInvalid:             "Les paramètres ne correspondent pas à la méthode d'extraction",
+		CodeParamRequired:                 "Obligatoire pour cette méthode d'extraction",
+		CodeParamOutOfRange:               "Doit être compris entre %s et %s pour cette méthode d'extraction",
+		CodeInvalidTimeRange:              "ended_at exige started_at et ne peut pas le précéder",
+		CodeTimerNotRunning:               "Aucun minuteur n'est en cours pour cette préparation",
+		CodeTimerUpdateFailed:             "Impossible de mettre à jour le minuteur",
+		CodeForbidden:                     "Vous n'avez pas la permission de faire cela",
+		CodeRecipeNotFound:                "Recette introuvable",
+		CodeRevisionNotFound:              "Révision de la recette introuvable",
+		CodeRecipeCreateFailed:            "Impossible de créer la recette",
+		CodeRecipeFetchFailed:             "Impossible de récupérer la recette",
+		CodeRecipeUpdateFailed:            "Impossible de mettre à jour la recette",
+		CodeCollaboratorNotFound:          "Collaborateur introuvable",
+		CodeInvitationNotFound:            "Invitation introuvable",
+		CodeCannotInviteSelf:              "Vous ne pouvez pas vous inviter vous-même",
+		CodeCollaboratorUpdateFailed:      "Impossible de mettre à jour les collaborateurs",
+		CodeCollaboratorFetchFailed:       "Impossible de récupérer les collaborateurs",
+		CodeInvalidCompareIDs:             "Sélectionnez entre %d et %d infusions à comparer",
+		CodeBeanBagNotFound:               "Sachet de café introuvable",
+		CodeBeanBagCreateFailed:           "Impossible de créer le sachet de café",
+		CodeBeanBagFetchFailed:            "Impossible de charger les sachets de café",
+		CodeBeanBagUpdateFailed:           "Impossible de mettre à jour le sachet de café",
+		CodeInvalidCurrency:               "Devise inconnue ou non prise en charge",
+		CodeUnknownFlavor:                 "Descripteur de saveur inconnu",
+		CodeFlavorFetchFailed:             "Impossible de charger les saveurs",
+		CodeFlavorUpdateFailed:            "Impossible de mettre à jour les saveurs",
+		CodeCuppingNotFound:               "Session de dégustation introuvable",
+		CodeCuppingSampleNotFound:         "Échantillon de dégustation introuvable",
+		CodeCuppingParticipantNotFound:    "Participant introuvable",
+		CodeCuppingRevealed:               "Cette session de dégustation a déjà été révélée",
+		CodeCuppingNotRevealed:            "Les résultats seront disponibles une fois la session révélée par l'hôte",
+		CodeCuppingFetchFailed:            "Échec de la récupération de la session de dégustation",
+		CodeCuppingUpdateFailed:           "Échec de la mise à jour de la session de dégustation",
+		CodeBrewEventNotFound:             "Événement de préparation introuvable",
+		CodeBrewEventFetchFailed:          "Échec de la récupération des événements de préparation",
+		CodeBrewEventUpdateFailed:         "Échec de la mise à jour de l'événement de préparation",
+		CodeEventRecipePrivate:            "Seules les recettes publiques peuvent être utilisées pour un événement",
+		CodeEventStartInPast:              "Les événements doivent commencer dans le futur",
+		CodeClubNotFound:                  "Club introuvable",
+		CodeClubFetchFailed:               "Échec de la récupération du club",
+		CodeClubUpdateFailed:              "Échec de la mise à jour du club",
+		CodeClubInviteInvalid:             "Ce lien d'invitation est invalide ou a expiré",
+		CodeClubInviteNotFound:            "Invitation introuvable",
+		CodeClubMemberNotFound:            "Membre du club introuvable",
+		CodeClubOwnerCannotLeave:          "Le propriétaire ne peut pas quitter le club ni en être retiré ; supprimez plutôt le club",
+		CodeClubShareNotFound:             "Élément partagé introuvable",
+		CodeClubRecipePrivate:             "Seules vos propres recettes ou les recettes publiques peuvent être partagées",
+		CodeChallengeNotFound:             "Défi introuvable",
+		CodeChallengeFetchFailed:          "Impossible de récupérer les défis",
+		CodeChallengeUpdateFailed:         "Impossible d'enregistrer le défi",
+		CodeBadgeFetchFailed:              "Impossible de récupérer les badges",
+		CodeRoasterNotFound:               "Torréfacteur introuvable",
+		CodeRoasterFetchFailed:            "Impossible de charger les torréfacteurs",
+		CodeRoasterUpdateFailed:           "Impossible d'enregistrer le torréfacteur",
+		CodeRoasterExists:                 "Vous avez déjà un compte de torréfacteur",
+		CodeRoasterNameTaken:              "Ce nom de torréfacteur est déjà pris",
+		CodeRoasterNotVerified:            "Seuls les torréfacteurs vérifiés peuvent publier des fiches officielles",
+		CodeBeanNotFound:                  "Café introuvable",
+		CodeBeanFetchFailed:               "Impossible de charger les cafés",
+		CodeBeanUpdateFailed:              "Impossible d'enregistrer le café",
+		CodeOfficialRecipeInvalid:         "Les recettes officielles doivent être vos propres recettes publiques",
+		CodeInvalidOrigin:                 "Pays d'origine, variété ou procédé inconnu",
+		CodeEmbedURLUnsupported:           "L'URL n'est pas une recette ou une préparation publique de brewd",
+		CodeEmbedFormatUnsupported:        "Seul le format oEmbed json est pris en charge",
+		CodeSitemapNotFound:               "Plan du site introuvable",
+		CodeAPIKeyRequired:                "Clé d'API requise",
+		CodeAPIKeyInvalid:                 "Clé d'API invalide ou révoquée",
+		CodeAPIKeyScope:                   "La clé d'API n'a pas la portée requise",
+		CodeAPIKeyNotFound:                "Clé d'API introuvable",
+		CodeAPIKeyUpdateFailed:            "Impossible d'enregistrer la clé d'API",
+		CodeTDSReadingNotFound:            "Mesure de TDS introuvable",
+		CodeTDSReadingFetchFailed:         "Impossible de récupérer les mesures de TDS",
+		CodeTDSReadingUpdateFailed:        "Impossible d'enregistrer la mesure de TDS",
+		CodePourCurveNotFound:             "Courbe de versement introuvable",
+		CodePourCurveFetchFailed:          "Impossible de récupérer la courbe de versement",
+		CodePourCurveUpdateFailed:         "Impossible d'enregistrer la courbe de versement",
+		CodePourSampleInvalid:             "Échantillon de versement invalide",
+		CodePourCurveTooLong:              "La courbe de versement est trop longue",
+		CodeNoRunningBrew:                 "Aucun minuteur de préparation n'est en cours",
+		CodeAutomationWebhookNotFound:     "Webhook introuvable",
+		CodeAutomationWebhookUpdateFailed: "Impossible d'enregistrer le webhook",
+		CodeAutomationURLInvalid:          "L'URL du webhook doit être en https sur un hôte public",
+		CodeCalendarFeedNotFound:          "Calendrier introuvable",
+		CodeCalendarFeedFetchFailed:       "Impossible de récupérer le calendrier",
+		CodeCalendarFeedUpdateFailed:      "Impossible de réinitialiser le calendrier",
+		CodeCustomFieldNotFound:           "Champ personnalisé introuvable",
+		CodeCustomFieldFetchFailed:        "Impossible de récupérer les champs personnalisés",
+		CodeCustomFieldUpdateFailed:       "Impossible de mettre à jour le champ personnalisé",
+		CodeCustomFieldNameInvalid:        "Les noms de champ doivent commencer par une minuscule et n'utiliser que des minuscules, des chiffres et des tirets bas (50 au maximum)",
+		CodeCustomFieldTaken:              "Vous avez déjà un champ personnalisé portant ce nom",
+		CodeCustomFieldLimit:              "Vous pouvez définir au maximum %d champs personnalisés",
+		CodeCustomFieldsInvalid:           "Certaines valeurs de champs personnalisés ne sont pas valides",
+		CodeCustomFieldUnknown:            "Ne fait pas partie de vos champs personnalisés",
+		CodeCustomFieldNumber:             "Doit être un nombre",
+		CodeCustomFieldText:               "Doit être un texte de %d caractères au maximum",
+		CodeCustomFieldBoolean:            "Doit être true ou false",
+		CodeDraftNotFound:                 "Brouillon introuvable",
+		CodeDraftFetchFailed:              "Impossible de récupérer les brouillons",
+		CodeDraftUpdateFailed:             "Impossible d'enregistrer le brouillon",
+		CodeDraftLimit:                    "Vous pouvez conserver au maximum %d brouillons",
+		CodeDraftTooLarge:                 "Le brouillon est trop volumineux",
+		CodeDraftIncomplete:               "Il manque des champs obligatoires au brouillon ou certaines valeurs ne sont pas valides",
+		CodeBrewedAtInFuture:              "brewed_at ne peut pas être dans le futur",
+		CodeSyncFetchFailed:               "Impossible de récupérer les modifications",
+		CodeSyncIDInvalid:                 "Les identifiants d'enregistrement doivent être des ULID",
+		CodeSyncVectorInvalid:             "vector doit associer à chaque identifiant de réplique un nombre de modifications positif",
+		CodeSyncDeleteUnsupported:         "Ce type d'enregistrement ne peut pas être supprimé",
+		CodeBrewUpdateFailed:              "Impossible de mettre à jour la préparation",
+		CodeUnsupportedMediaType:          "Le Content-Type doit être application/merge-patch+json ou application/json",
+		CodeProfileUpdateFailed:           "Impossible de mettre à jour le profil",
+		CodeUnknownField:                  "Champ inconnu : %s",
+		CodeSuggestionFetchFailed:         "Impossible de charger les suggestions",
+		CodeTrendingFetchFailed:           "Impossible de charger les tendances",
+		CodeRecommendationFetchFailed:     "Impossible de charger les recommandations",
+		CodeRecommendationUpdateFailed:    "Impossible d'enregistrer l'avis sur la recommandation",
+		CodeEventBatchTooLarge:            "Le lot d'événements est trop volumineux",
+		CodeEventIngestFailed:             "Impossible d'enregistrer les événements",
+		CodeOpsStatsFetchFailed:           "Impossible de récupérer les statistiques",
+		CodeQuotaExceeded:                 "Le quota de votre forfait est épuisé, veuillez réessayer après sa réinitialisation",
+		CodeBillingUnavailable:            "La facturation n'est pas disponible",
+		CodeCheckoutFailed:                "Impossible de démarrer le paiement",
+		CodeAlreadySupporter:              "Vous êtes déjà soutien",
+		CodeWebhookSignatureInvalid:       "Signature de webhook invalide",
+		CodeSupporterRequired:             "Cette fonctionnalité est réservée aux soutiens",
+		CodePlanFetchFailed:               "Impossible de récupérer le forfait",
+		CodeReceiptInvalid:                "La boutique a refusé cet achat",
+		CodePurchaseVerifyFailed:          "Impossible de vérifier l'achat",
+		CodePurchaseInUse:                 "Cet achat appartient à un autre compte",
+		CodeMailUnavailable:               "L'e-mail n'est pas disponible",
+		CodeEmailSuppressionFetchFailed:   "Impossible de récupérer les suppressions d'e-mail",
+		CodeEmailSuppressionUpdateFailed:  "Impossible de mettre à jour les suppressions d'e-mail",
+		CodeEmailSuppressionNotFound:      "L'adresse e-mail n'est pas supprimée",
+		CodeEmailSuppressed:               "L'adresse e-mail est supprimée",
+		CodeEmailSendFailed:               "Impossible d'envoyer l'e-mail",
+		CodeInviteRequired:                "Une invitation est nécessaire pour s'inscrire",
+		CodeInviteInvalid:                 "L'invitation est invalide, expirée ou épuisée",
+		CodeInviteNotFound:                "Invitation introuvable",
+		CodeInviteQuotaExceeded:           "Vous n'avez plus d'invitations",
+		CodeInviteUpdateFailed:            "Impossible de mettre à jour les invitations",
+		CodeInviteFetchFailed:             "Impossible de récupérer les invitations",
+		CodeWaitlistFetchFailed:           "Impossible de récupérer la liste d'attente",
+		CodeWaitlistUpdateFailed:          "Impossible de mettre à jour la liste d'attente",
+		CodeCaptchaRequired:               "Complétez le CAPTCHA pour continuer",
+		CodeCaptchaFailed:                 "La vérification CAPTCHA a échoué",
+		CodeCaptchaUnavailable:            "La vérification CAPTCHA n'est pas disponible",
+		CodeDisposableEmail:               "Les adresses e-mail jetables ne sont pas acceptées",
+		CodeEmailDomainFetchFailed:        "Impossible de récupérer les domaines d'e-mail",
+		CodeEmailDomainUpdateFailed:       "Impossible de mettre à jour les domaines d'e-mail",
+		CodeEmailDomainNotFound:           "Exception de domaine d'e-mail introuvable",
+		CodeInvalidDomain:                 "Domaine invalide",
+		CodeAuthEventFetchFailed:          "Impossible de récupérer l'historique des connexions",
+		CodeStepUpFailed:                  "Impossible de lancer la vérification de connexion",
+		CodeStepUpInvalid:                 "Code de vérification incorrect",
+		CodeStepUpExpired:                 "La vérification a expiré, veuillez vous reconnecter",
+		CodeTOTPAlreadyEnabled:            "L'application d'authentification est déjà activée",
+		CodeTOTPNotSetUp:                  "Configurez d'abord une application d'authentification",
+		CodeTOTPNotEnabled:                "L'application d'authentification n'est pas activée",
+		CodeTOTPInvalid:                   "Code d'authentification incorrect",
+		CodeTOTPUpdateFailed:              "Impossible de mettre à jour les paramètres de l'application d'authentification",
+		CodeTrustedDeviceFetchFailed:      "Impossible de récupérer les appareils de confiance",
+		CodeTrustedDeviceUpdateFailed:     "Impossible de mettre à jour les appareils de confiance",
+		CodeTrustedDeviceNotFound:         "Appareil de confiance introuvable",
+		CodeCSRFInvalid:                   "Jeton CSRF manquant ou invalide",
+		CodeOIDCClientInvalid:             "Client ou URI de redirection inconnu",
+		CodeOIDCAuthorizeFailed:           "Impossible de se connecter au service",
+		CodeOIDCClientFetchFailed:         "Impossible de récupérer les clients OIDC",
+		CodeOIDCClientUpdateFailed:        "Impossible de mettre à jour les clients OIDC",
+		CodeOIDCClientNotFound:            "Client OIDC introuvable",
+		CodeRedirectURIInvalid:            "Les URI de redirection doivent être des URL HTTPS absolues sans fragment",
+		CodeAccountDeactivated:            "Ce compte a été désactivé",
+		CodeSCIMClientFetchFailed:         "Impossible de récupérer les clients SCIM",
+		CodeSCIMClientUpdateFailed:        "Impossible de mettre à jour les clients SCIM",
+		CodeSCIMClientNotFound:            "Client SCIM introuvable",
+		CodePolicyAcceptanceRequired:      "Vous devez accepter les conditions d'utilisation et la politique de confidentialité en vigueur",
+		CodePolicyOutdated:                "Une version plus récente de cette politique a été publiée ; consultez-la et acceptez-la à nouveau",
+		CodePolicyFetchFailed:             "Impossible de charger les politiques",
+		CodePolicyUpdateFailed:            "Impossible d'enregistrer la politique",
+		CodePolicyVersionTaken:            "Cette version de cette politique existe déjà",
+		CodeBirthDateRequired:             "La date de naissance est obligatoire",
+		CodeBirthDateInvalid:              "Date de naissance invalide",
+		CodeAgeRequirementNotMet:          "Vous n'avez pas l'âge requis pour créer un compte",
+		CodeParentEmailInvalid:            "Saisissez l'adresse e-mail d'un parent, différente de la vôtre",
+		CodeParentalConsentPending:        "Ce compte attend le consentement d'un parent",
+		CodeParentalConsentInvalid:        "Ce lien de consentement est invalide ou a expiré",
+		CodeParentalConsentFailed:         "Impossible d'enregistrer le consentement",
+		CodeParentalConsentRequired:       "À votre âge, le consentement d'un parent est nécessaire pour créer un compte ; saisissez son adresse e-mail",
+		CodeShareNotFound:                 "Partage introuvable",
+		CodeShareExists:                   "C'est déjà partagé avec eux",
+		CodeShareGranteeInvalid:           "Partagez avec un utilisateur ou un club dont vous êtes membre, pas avec vous-même",
+		CodeShareFetchFailed:              "Impossible de charger les partages",
+		CodeShareUpdateFailed:             "Impossible de mettre à jour le partage",
+		CodeAPIKeyQuotaExceeded:           "Le quota de requêtes et les crédits de rafale de cette clé d'API sont épuisés, veuillez réessayer après la réinitialisation de la fenêtre",
+		CodeRequestTimestampInvalid:       "L'horodatage ou le nonce de la requête est manquant, ou son horodatage est trop éloigné de l'horloge du serveur",
+		CodeRequestReplayed:               "Cette requête a déjà été reçue",
+		CodeRequestSignatureInvalid:       "La signature de la requête est manquante ou ne lui correspond pas",
+		CodeRequestBodyTooLarge:           "Le corps de la requête est trop volumineux",
+		CodeBackupsUnavailable:            "Les sauvegardes ne sont pas configurées",
+		CodeBackupRunning:                 "Une sauvegarde est déjà en cours",
+		CodeBackupStartFailed:             "Impossible de démarrer la sauvegarde",
+		CodeBackupFetchFailed:             "Impossible de récupérer les sauvegardes",
+		CodeOrganizationNotFound:          "Organisation introuvable",
+		CodeOrganizationFetchFailed:       "Impossible de récupérer l'organisation",
+		CodeOrganizationUpdateFailed:      "Impossible de mettre à jour l'organisation",
+		CodeOrganizationMemberNotFound:    "Membre de l'organisation introuvable",
+		CodeOrganizationMemberExists:      "Cet utilisateur est déjà membre",
+		CodeOrganizationLastOwner:         "Une organisation doit avoir au moins un propriétaire ; nommez d'abord un autre membre propriétaire",
+		CodeRetentionFetchFailed:          "Impossible de récupérer le rapport de conservation",
+		CodeMetricsUnavailable:            "Les métriques ne sont pas activées",
+		CodeRouteNotFound:                 "Introuvable",
+		CodeJobLeasesFetchFailed:          "Impossible de récupérer les baux des tâches",
+		CodePhotosUnavailable:             "L'envoi de photos n'est pas configuré",
+		CodePhotoRequired:                 "Un fichier photo est requis dans le champ photo",
+		CodePhotoTooLarge:                 "La photo est trop volumineuse",
+		CodePhotoTypeUnsupported:          "Les photos doivent être des images JPEG, PNG, GIF ou WebP",
+		CodePhotoUploadFailed:             "Échec de l'envoi de la photo",
+		CodePhotoNotFound:                 "Photo introuvable",
+		CodePhotoFetchFailed:              "Échec du chargement des photos",
+		CodePhotoNotReady:                 "La photo est encore en cours de traitement ou n'a pas pu être traitée",
+		CodePhotoDeleteFailed:             "Échec de la suppression de la photo",
+		CodeMediaLinkInvalid:              "Le lien multimédia est invalide ou a expiré",
+		CodeMediaNotFound:                 "Média introuvable",
+		CodeBackupNotFound:                "Sauvegarde introuvable",
+		CodeBackupNotDownloadable:         "La sauvegarde n'a pas de vidage à télécharger",
+		CodeStorageQuotaExceeded:          "Le quota de stockage de votre forfait serait dépassé, veuillez supprimer des photos ou changer de forfait",
+		CodeStorageFetchFailed:            "Échec de la récupération de l'utilisation du stockage",
+	},
+}
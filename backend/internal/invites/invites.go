@@ -0,0 +1,45 @@
+package invites
+
+import (
+	"crypto/rand"
+	"net/url"
+	"strings"
+
+	"brewd/internal/links"
+)
+
+// codeLength is the number of characters in an invite code
+const codeLength = 10
+
+// alphabet is Crockford's base32, which leaves out I, L, O and U so codes
+// read back unambiguously when typed by hand
+const alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// signupPath is the web app's signup page, which invite links open with
+// the code in the invite query parameter
+const signupPath = "signup"
+
+// NewCode returns a random invite code, e.g. 7KQ2MX9D4R
+func NewCode() (string, error) {
+	b := make([]byte, codeLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		// 256 is a multiple of 32, so every character is equally likely
+		b[i] = alphabet[b[i]%32]
+	}
+	return string(b), nil
+}
+
+// NormalizeCode uppercases and trims a code as typed, so invites redeem
+// regardless of case
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// URL is the link an invite is shared by, e.g.
+// https://brewd.app/signup?invite=7KQ2MX9D4R
+func URL(site *links.Site, code string) string {
+	return site.Join(signupPath) + "?" + url.Values{"invite": {code}}.Encode()
+}
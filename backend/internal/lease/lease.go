@@ -0,0 +1,181 @@
+// Package lease keeps singleton background jobs on one instance at a time.
+// A job runs on the instance holding its lease, which renews it while the
+// job runs; the others stand by and take the lease once it expires, so a
+// job moves on within the TTL of its instance dying. Leases live in Postgres
+// rows rather than advisory locks, which pgbouncer can't hold for a session.
+//
+// Each taking of a lease has a new epoch. A job checks its epoch with Check
+// before each batch of writes, so one that is slow to stop after losing its
+// lease can't keep writing while another instance runs the job.
+package lease
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/logger"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrLost is returned by Check once a job's lease is lost
+var ErrLost = errors.New("lease: job lease lost")
+
+// Lease timing. A holder that can't renew stops its job a renewal interval
+// before its lease expires, so the job doesn't run on two instances at once;
+// Check fences out a job slow to stop.
+const (
+	TTL           = 30 * time.Second
+	renewInterval = 10 * time.Second
+)
+
+// stopTimeout is how long a job gets to return once its lease is lost. One
+// still running is then left behind, failing Check, rather than holding up
+// the instance's standby.
+const stopTimeout = renewInterval
+
+// fence is the lease a job's context was given for
+type fence struct {
+	manager *Manager
+	name    string
+	epoch   int64
+}
+
+type fenceKey struct{}
+
+// Manager runs jobs under leases held by this instance
+type Manager struct {
+	queries *db.Queries
+	holder  string
+}
+
+// NewManager creates a manager taking leases as holder, which must be unique
+// to this instance
+func NewManager(queries *db.Queries, holder string) *Manager {
+	return &Manager{queries: queries, holder: holder}
+}
+
+// Run runs job while this instance holds the lease called name, until ctx
+// is cancelled or the job returns on its own. Without the lease it tries
+// for it every renewal interval. When the lease can't be renewed, the job's
+// context is cancelled and Run stands by again once it returns.
+func (m *Manager) Run(ctx context.Context, name string, job func(ctx context.Context)) {
+	ticker := time.NewTicker(renewInterval)
+	defer ticker.Stop()
+
+	for {
+		if epoch, held, _ := m.acquire(ctx, name); held {
+			logger.Info("Acquired job lease", "job", name, "holder", m.holder, "epoch", epoch)
+			if finished := m.hold(ctx, fence{manager: m, name: name, epoch: epoch}, job); finished {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// hold runs job while renewing f's lease, then releases it. It reports
+// whether the job finished, rather than being stopped for a lost lease.
+func (m *Manager) hold(ctx context.Context, f fence, job func(ctx context.Context)) bool {
+	name := f.name
+	jobCtx, cancel := context.WithCancel(context.WithValue(ctx, fenceKey{}, f))
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		job(jobCtx)
+	}()
+
+	ticker := time.NewTicker(renewInterval)
+	defer ticker.Stop()
+	renewed := time.Now()
+	for {
+		select {
+		case <-done:
+			m.release(ctx, name)
+			return true
+		case <-ticker.C:
+			// Taking the lease anew, after it expired, fences out the job
+			// as surely as another holder taking it
+			epoch, held, err := m.acquire(ctx, name)
+			if held && epoch == f.epoch {
+				renewed = time.Now()
+				continue
+			}
+			// A failed renewal leaves the lease held until it expires
+			if err != nil && time.Since(renewed) < TTL-renewInterval {
+				continue
+			}
+			logger.Warn("Lost job lease, stopping job", "job", name, "holder", m.holder)
+			cancel()
+			select {
+			case <-done:
+			case <-time.After(stopTimeout):
+				logger.Error("Job still running after losing its lease; its writes are fenced",
+					"job", name, "holder", m.holder, "epoch", f.epoch)
+			}
+			return false
+		}
+	}
+}
+
+// acquire takes or renews name's lease, reporting its epoch and whether
+// this instance holds it. Without an error, not holding it means another
+// instance does.
+func (m *Manager) acquire(ctx context.Context, name string) (int64, bool, error) {
+	epoch, err := m.queries.AcquireJobLease(ctx, db.AcquireJobLeaseParams{
+		Name:       name,
+		Holder:     m.holder,
+		TtlSeconds: int32(TTL / time.Second),
+	})
+	if err == pgx.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Warn("Failed to acquire job lease", "job", name, "error", err)
+		}
+		return 0, false, err
+	}
+	return epoch, true, nil
+}
+
+// release gives up name's lease, so another instance can take it at once
+func (m *Manager) release(ctx context.Context, name string) {
+	// The job may have stopped for shutdown; releasing mustn't be cut short
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := m.queries.ReleaseJobLease(ctx, db.ReleaseJobLeaseParams{Name: name, Holder: m.holder}); err != nil {
+		logger.Warn("Failed to release job lease", "job", name, "error", err)
+	}
+}
+
+// Check returns ErrLost if the lease ctx's job was started under has been
+// lost, even if this instance has since taken it again. Singleton jobs call
+// it before each batch of writes. Outside a job run under a lease it
+// returns nil.
+func Check(ctx context.Context) error {
+	f, ok := ctx.Value(fenceKey{}).(fence)
+	if !ok {
+		return nil
+	}
+	held, err := f.manager.queries.CheckJobLease(ctx, db.CheckJobLeaseParams{
+		Name:   f.name,
+		Holder: f.manager.holder,
+		Epoch:  f.epoch,
+	})
+	if err != nil {
+		return err
+	}
+	if !held {
+		return ErrLost
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package links
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Kinds of content with a public web page
+const (
+	KindRecipe = "recipe"
+	KindBrew   = "brew"
+	KindUser   = "user"
+)
+
+// paths maps each kind to the first path segment of its web page
+var paths = map[string]string{
+	KindRecipe: "recipes",
+	KindBrew:   "brews",
+	KindUser:   "users",
+}
+
+// Site builds and recognises the public web URLs of brewd content, e.g.
+// https://brewd.app/recipes/:id. Profiles are keyed by username rather than
+// ID.
+type Site struct {
+	base *url.URL
+}
+
+// NewSite parses the web app's base URL
+func NewSite(baseURL string) (*Site, error) {
+	base, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("base URL %q must be absolute", baseURL)
+	}
+	return &Site{base: base}, nil
+}
+
+// BaseURL returns the web app's base URL, without a trailing slash
+func (s *Site) BaseURL() string {
+	return s.base.String()
+}
+
+// URL returns the web page of the content of the given kind
+func (s *Site) URL(kind, id string) string {
+	return s.Join(paths[kind], id)
+}
+
+// Join returns the URL of a path under the web app's base URL
+func (s *Site) Join(elem ...string) string {
+	return s.base.JoinPath(elem...).String()
+}
+
+// Parse resolves a web page URL to the kind and ID of its content. The
+// scheme, a www. prefix, a trailing slash, the query and the fragment are
+// ignored.
+func (s *Site) Parse(raw string) (kind, id string, ok bool) {
+	u, err := url.Parse(raw)
+	if err != nil || !strings.EqualFold(strings.TrimPrefix(u.Host, "www."), strings.TrimPrefix(s.base.Host, "www.")) {
+		return "", "", false
+	}
+	rest, found := strings.CutPrefix(u.Path, s.base.Path+"/")
+	if !found {
+		return "", "", false
+	}
+	segments := strings.Split(strings.TrimSuffix(rest, "/"), "/")
+	if len(segments) != 2 || segments[1] == "" {
+		return "", "", false
+	}
+	for k, path := range paths {
+		if segments[0] == path {
+			return k, segments[1], true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,211 @@
+package mail
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Mail providers whose bounce and complaint webhooks are understood
+const (
+	ProviderPostmark = "postmark"
+	ProviderSendGrid = "sendgrid"
+	ProviderSES      = "ses"
+)
+
+// Suppression reasons. Bounces and complaints come from provider webhooks;
+// manual suppressions from admins.
+const (
+	ReasonBounce    = "bounce"
+	ReasonComplaint = "complaint"
+	ReasonManual    = "manual"
+)
+
+// Suppression is an address a provider reported as undeliverable or as
+// having marked our mail as spam. Only permanent failures are reported;
+// soft bounces (full mailboxes, greylisting) are left for the provider to
+// retry.
+type Suppression struct {
+	Email  string
+	Reason string
+	Detail string
+}
+
+// ParseEvents reads the suppressions in a webhook body from provider. An
+// SES subscription confirmation returns its confirmation URL instead, to
+// be passed to ConfirmSubscription.
+func ParseEvents(provider string, body []byte) (suppressions []Suppression, confirmURL string, err error) {
+	switch provider {
+	case ProviderPostmark:
+		suppressions, err = parsePostmark(body)
+	case ProviderSendGrid:
+		suppressions, err = parseSendGrid(body)
+	case ProviderSES:
+		return parseSES(body)
+	default:
+		err = fmt.Errorf("unknown mail provider %q", provider)
+	}
+	return suppressions, "", err
+}
+
+// postmarkEvent is the part of a Postmark bounce or spam complaint webhook
+// the parser reads
+type postmarkEvent struct {
+	RecordType  string `json:"RecordType"`
+	Type        string `json:"Type"`
+	Email       string `json:"Email"`
+	Description string `json:"Description"`
+}
+
+// postmarkPermanent are the Postmark bounce types that mean the address
+// won't accept mail
+var postmarkPermanent = map[string]bool{
+	"HardBounce":          true,
+	"BadEmailAddress":     true,
+	"ManuallyDeactivated": true,
+	"SpamNotification":    true,
+}
+
+func parsePostmark(body []byte) ([]Suppression, error) {
+	var e postmarkEvent
+	if err := json.Unmarshal(body, &e); err != nil {
+		return nil, err
+	}
+	switch {
+	case e.RecordType == "SpamComplaint" || e.Type == "SpamComplaint":
+		return []Suppression{{Email: e.Email, Reason: ReasonComplaint, Detail: e.Description}}, nil
+	case e.RecordType == "Bounce" && postmarkPermanent[e.Type]:
+		return []Suppression{{Email: e.Email, Reason: ReasonBounce, Detail: e.Type + ": " + e.Description}}, nil
+	}
+	return nil, nil
+}
+
+// sendGridEvent is the part of a SendGrid event webhook event the parser
+// reads. Bounces have type "bounce" when permanent and "blocked" when not.
+type sendGridEvent struct {
+	Event  string `json:"event"`
+	Email  string `json:"email"`
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+func parseSendGrid(body []byte) ([]Suppression, error) {
+	var events []sendGridEvent
+	if err := json.Unmarshal(body, &events); err != nil {
+		return nil, err
+	}
+	var suppressions []Suppression
+	for _, e := range events {
+		switch {
+		case e.Event == "spamreport":
+			suppressions = append(suppressions, Suppression{Email: e.Email, Reason: ReasonComplaint})
+		case e.Event == "bounce" && e.Type != "blocked":
+			suppressions = append(suppressions, Suppression{Email: e.Email, Reason: ReasonBounce, Detail: e.Reason})
+		}
+	}
+	return suppressions, nil
+}
+
+// snsEnvelope is an Amazon SNS HTTP delivery, carrying an SES notification
+// as its message
+type snsEnvelope struct {
+	Type         string `json:"Type"`
+	Message      string `json:"Message"`
+	SubscribeURL string `json:"SubscribeURL"`
+}
+
+// sesNotification is the part of an SES bounce or complaint notification
+// the parser reads. Event publishing names the type eventType rather than
+// notificationType.
+type sesNotification struct {
+	NotificationType string `json:"notificationType"`
+	EventType        string `json:"eventType"`
+	Bounce           struct {
+		BounceType        string `json:"bounceType"`
+		BouncedRecipients []struct {
+			EmailAddress   string `json:"emailAddress"`
+			DiagnosticCode string `json:"diagnosticCode"`
+		} `json:"bouncedRecipients"`
+	} `json:"bounce"`
+	Complaint struct {
+		ComplainedRecipients []struct {
+			EmailAddress string `json:"emailAddress"`
+		} `json:"complainedRecipients"`
+		ComplaintFeedbackType string `json:"complaintFeedbackType"`
+	} `json:"complaint"`
+}
+
+func parseSES(body []byte) ([]Suppression, string, error) {
+	var envelope snsEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, "", err
+	}
+	switch envelope.Type {
+	case "SubscriptionConfirmation":
+		return nil, envelope.SubscribeURL, nil
+	case "Notification":
+	default:
+		return nil, "", nil
+	}
+
+	var n sesNotification
+	if err := json.Unmarshal([]byte(envelope.Message), &n); err != nil {
+		return nil, "", err
+	}
+	kind := n.NotificationType
+	if kind == "" {
+		kind = n.EventType
+	}
+
+	var suppressions []Suppression
+	switch kind {
+	case "Bounce":
+		if n.Bounce.BounceType != "Permanent" {
+			return nil, "", nil
+		}
+		for _, r := range n.Bounce.BouncedRecipients {
+			suppressions = append(suppressions, Suppression{Email: r.EmailAddress, Reason: ReasonBounce, Detail: r.DiagnosticCode})
+		}
+	case "Complaint":
+		for _, r := range n.Complaint.ComplainedRecipients {
+			suppressions = append(suppressions, Suppression{Email: r.EmailAddress, Reason: ReasonComplaint,
+				Detail: n.Complaint.ComplaintFeedbackType})
+		}
+	}
+	return suppressions, "", nil
+}
+
+// confirmTimeout bounds confirming an SNS subscription
+const confirmTimeout = 10 * time.Second
+
+// ConfirmSubscription confirms an SNS subscription by visiting its
+// confirmation URL, which must be an https URL on amazonaws.com so a
+// forged confirmation can't make the server fetch arbitrary URLs
+func ConfirmSubscription(ctx context.Context, confirmURL string) error {
+	u, err := url.Parse(confirmURL)
+	if err != nil || u.Scheme != "https" || !strings.HasSuffix(u.Hostname(), ".amazonaws.com") {
+		return fmt.Errorf("invalid SNS confirmation URL %q", confirmURL)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, confirmTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("SNS confirmation returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"brewd/internal/db"
+	"brewd/internal/logger"
+)
+
+// Message is a plain-text email to one recipient
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+}
+
+// Sender delivers messages to a mail server or provider. Implementations
+// for provider APIs plug in here.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Pinger is implemented by senders that can check their server is
+// reachable, for health checks
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Sender names accepted by NewSender
+const (
+	SenderSMTP = "smtp"
+	SenderLog  = "log"
+	SenderNone = "none"
+)
+
+// NewSender returns the sender called name. smtpURL and from are only used
+// by the SMTP sender.
+func NewSender(name, smtpURL, from string) (Sender, error) {
+	switch name {
+	case SenderSMTP:
+		return NewSMTPSender(smtpURL, from)
+	case SenderLog:
+		return LogSender{}, nil
+	case SenderNone:
+		return Discard{}, nil
+	default:
+		return nil, fmt.Errorf("unknown mail sender %q", name)
+	}
+}
+
+// ErrSuppressed is returned for messages to an address on the suppression
+// list, which are not sent
+var ErrSuppressed = errors.New("mail: recipient is suppressed")
+
+// Mailer sends messages through a Sender, skipping recipients on the
+// suppression list: addresses that hard bounced, complained, or were
+// suppressed by an admin. Mailing them again hurts deliverability for
+// everyone else.
+type Mailer struct {
+	sender  Sender
+	queries *db.Queries
+}
+
+func NewMailer(sender Sender, queries *db.Queries) *Mailer {
+	return &Mailer{sender: sender, queries: queries}
+}
+
+// Send sends msg unless its recipient is suppressed, in which case it
+// returns ErrSuppressed
+func (m *Mailer) Send(ctx context.Context, msg Message) error {
+	suppressed, err := m.queries.IsEmailSuppressed(ctx, NormalizeAddress(msg.To))
+	if err != nil {
+		return fmt.Errorf("checking suppression list: %w", err)
+	}
+	if suppressed {
+		return ErrSuppressed
+	}
+	return m.sender.Send(ctx, msg)
+}
+
+// NormalizeAddress lowercases and trims an address, as suppressions are
+// stored
+func NormalizeAddress(address string) string {
+	return strings.ToLower(strings.TrimSpace(address))
+}
+
+// LogSender writes messages to the application log instead of sending
+// them, for development
+type LogSender struct{}
+
+func (LogSender) Send(_ context.Context, msg Message) error {
+	logger.Info("Email", "to", msg.To, "subject", msg.Subject, "text", msg.Text)
+	return nil
+}
+
+// Discard drops messages, for deployments that don't send mail
+type Discard struct{}
+
+func (Discard) Send(context.Context, Message) error {
+	return nil
+}
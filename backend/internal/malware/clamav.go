@@ -0,0 +1,113 @@
+package malware
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// clamdChunkSize is how much of an upload is sent in each INSTREAM chunk
+const clamdChunkSize = 64 << 10
+
+// ClamdScanner streams uploads to ClamAV's clamd with its INSTREAM command
+type ClamdScanner struct {
+	network string
+	address string
+}
+
+// NewClamdScanner returns a scanner using the clamd listening at address on
+// network, "tcp" or "unix"
+func NewClamdScanner(network, address string) *ClamdScanner {
+	return &ClamdScanner{network: network, address: address}
+}
+
+func (s *ClamdScanner) Scan(ctx context.Context, body io.Reader) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, scanTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return "", fmt.Errorf("clamd: %w", err)
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	// The z prefix asks for NUL-terminated commands and replies; the
+	// upload follows as length-prefixed chunks ending with an empty one
+	if _, err := io.WriteString(conn, "zINSTREAM\x00"); err != nil {
+		return "", fmt.Errorf("clamd: %w", err)
+	}
+	w := bufio.NewWriterSize(conn, clamdChunkSize+4)
+	chunk := make([]byte, clamdChunkSize)
+	for {
+		n, err := io.ReadFull(body, chunk)
+		if n > 0 {
+			if err := writeClamdChunk(w, chunk[:n]); err != nil {
+				return "", s.interrupted(conn, err)
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	if err := writeClamdChunk(w, nil); err != nil {
+		return "", s.interrupted(conn, err)
+	}
+	if err := w.Flush(); err != nil {
+		return "", s.interrupted(conn, err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil {
+		return "", fmt.Errorf("clamd: reading reply: %w", err)
+	}
+	return parseClamdReply(strings.TrimSuffix(reply, "\x00"))
+}
+
+// interrupted explains a failed write. clamd replies and hangs up when an
+// upload exceeds its StreamMaxLength, so its reply is more useful than the
+// write error.
+func (s *ClamdScanner) interrupted(conn net.Conn, err error) error {
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if reply, readErr := bufio.NewReader(conn).ReadString(0); readErr == nil {
+		return fmt.Errorf("clamd: %s", strings.TrimSuffix(reply, "\x00"))
+	}
+	return fmt.Errorf("clamd: %w", err)
+}
+
+func writeClamdChunk(w *bufio.Writer, chunk []byte) error {
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(chunk)))
+	if _, err := w.Write(size[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(chunk)
+	return err
+}
+
+// parseClamdReply reads a reply such as "stream: OK" or
+// "stream: Eicar-Test-Signature FOUND"
+func parseClamdReply(reply string) (string, error) {
+	result := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case result == "OK":
+		return "", nil
+	case strings.HasSuffix(result, " FOUND"):
+		return strings.TrimSuffix(result, " FOUND"), nil
+	case result == "":
+		return "", errors.New("clamd: empty reply")
+	default:
+		return "", fmt.Errorf("clamd: %s", result)
+	}
+}
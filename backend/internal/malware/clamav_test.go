@@ -0,0 +1,160 @@
+package malware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakeClamd answers INSTREAM commands as clamd does, finding infectedMarker
+// in uploads. With limit set it refuses uploads longer than that, as clamd
+// does past its StreamMaxLength. Each upload's chunk sizes are sent on
+// chunks.
+type fakeClamd struct {
+	limit  int
+	chunks chan []int
+}
+
+func (f *fakeClamd) handle(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	command, err := r.ReadString(0)
+	if err != nil {
+		return
+	}
+	if command != "zINSTREAM\x00" {
+		io.WriteString(conn, "UNKNOWN COMMAND\x00")
+		return
+	}
+
+	var body bytes.Buffer
+	var sizes []int
+	for {
+		var size uint32
+		if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+			return
+		}
+		if size == 0 {
+			break
+		}
+		sizes = append(sizes, int(size))
+		if _, err := io.CopyN(&body, r, int64(size)); err != nil {
+			return
+		}
+		if f.limit > 0 && body.Len() > f.limit {
+			io.WriteString(conn, "INSTREAM size limit exceeded. ERROR\x00")
+			// Drain the rest, so the client reads the reply rather than
+			// a reset connection
+			conn.(*net.TCPConn).CloseWrite()
+			io.Copy(io.Discard, r)
+			return
+		}
+	}
+	if f.chunks != nil {
+		f.chunks <- sizes
+	}
+
+	if bytes.Contains(body.Bytes(), []byte(infectedMarker)) {
+		io.WriteString(conn, "stream: Test-Signature FOUND\x00")
+		return
+	}
+	io.WriteString(conn, "stream: OK\x00")
+}
+
+func TestClamdScan(t *testing.T) {
+	l := listen(t)
+	clamd := &fakeClamd{chunks: make(chan []int, 1)}
+	serve(t, l, clamd.handle)
+	scanner := NewClamdScanner("tcp", l.Addr().String())
+
+	tests := []struct {
+		name   string
+		body   string
+		threat string
+		chunks []int
+	}{
+		{"clean", "a photo", "", []int{7}},
+		{"infected", "a photo " + infectedMarker, "Test-Signature", []int{16}},
+		{"empty", "", "", nil},
+		{"chunked", strings.Repeat("x", clamdChunkSize*2+10), "", []int{clamdChunkSize, clamdChunkSize, 10}},
+	}
+	for _, tt := range tests {
+		threat, err := scanner.Scan(context.Background(), strings.NewReader(tt.body))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if threat != tt.threat {
+			t.Errorf("%s: threat %q, want %q", tt.name, threat, tt.threat)
+		}
+		if chunks := <-clamd.chunks; !slices.Equal(chunks, tt.chunks) {
+			t.Errorf("%s: sent chunks %v, want %v", tt.name, chunks, tt.chunks)
+		}
+	}
+}
+
+func TestClamdScanUnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clamd.ctl")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serve(t, l, (&fakeClamd{}).handle)
+
+	scanner, err := New(ScannerClamAV, "unix://"+path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	threat, err := scanner.Scan(context.Background(), strings.NewReader(infectedMarker))
+	if err != nil || threat != "Test-Signature" {
+		t.Errorf("Scan = %q, %v, want Test-Signature", threat, err)
+	}
+}
+
+func TestClamdScanOverLimit(t *testing.T) {
+	l := listen(t)
+	serve(t, l, (&fakeClamd{limit: clamdChunkSize}).handle)
+	scanner := NewClamdScanner("tcp", l.Addr().String())
+
+	_, err := scanner.Scan(context.Background(), strings.NewReader(strings.Repeat("x", clamdChunkSize*4)))
+	if err == nil || !strings.Contains(err.Error(), "size limit exceeded") {
+		t.Errorf("error %v, want clamd's size limit reply", err)
+	}
+}
+
+func TestClamdScanUnreachable(t *testing.T) {
+	l := listen(t)
+	address := l.Addr().String()
+	l.Close()
+
+	if _, err := NewClamdScanner("tcp", address).Scan(context.Background(), strings.NewReader("a photo")); err == nil {
+		t.Error("scanned with clamd down")
+	}
+}
+
+func TestParseClamdReply(t *testing.T) {
+	tests := []struct {
+		reply  string
+		threat string
+		err    bool
+	}{
+		{"stream: OK", "", false},
+		{"stream: Eicar-Test-Signature FOUND", "Eicar-Test-Signature", false},
+		{"stream: Win.Test.EICAR_HDB-1 FOUND", "Win.Test.EICAR_HDB-1", false},
+		{"INSTREAM size limit exceeded. ERROR", "", true},
+		{"stream: Can't allocate memory ERROR", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		threat, err := parseClamdReply(tt.reply)
+		if threat != tt.threat || (err != nil) != tt.err {
+			t.Errorf("parseClamdReply(%q) = %q, %v, want %q, error %v", tt.reply, threat, err, tt.threat, tt.err)
+		}
+	}
+}
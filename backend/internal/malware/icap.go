@@ -0,0 +1,133 @@
+package malware
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// icapDefaultPort is ICAP's registered port
+const icapDefaultPort = "1344"
+
+// ICAPScanner sends uploads to an ICAP antivirus service (RFC 3507) as the
+// body of an HTTP response to modify (RESPMOD). The service answers 204 No
+// Content for clean uploads; anything it would modify or block is taken
+// as infected.
+type ICAPScanner struct {
+	service *url.URL
+}
+
+// NewICAPScanner returns a scanner using the ICAP service at service, e.g.
+// icap://icap:1344/avscan
+func NewICAPScanner(service *url.URL) *ICAPScanner {
+	return &ICAPScanner{service: service}
+}
+
+// icapThreatHeaders are where ICAP services name the threat they found:
+// X-Infection-Found is the draft standard; the others are in common use
+var icapThreatHeaders = []string{"X-Infection-Found", "X-Virus-Id", "X-Violations-Found", "X-Virus-Name"}
+
+func (s *ICAPScanner) Scan(ctx context.Context, body io.Reader) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, scanTimeout)
+	defer cancel()
+
+	address := s.service.Host
+	if s.service.Port() == "" {
+		address = net.JoinHostPort(s.service.Hostname(), icapDefaultPort)
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return "", fmt.Errorf("icap: %w", err)
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	// The encapsulated request and response headers only give the service
+	// context; the upload is the response body, sent chunked
+	reqHeader := "GET /upload HTTP/1.1\r\nHost: brewd\r\n\r\n"
+	resHeader := "HTTP/1.1 200 OK\r\nContent-Type: application/octet-stream\r\nTransfer-Encoding: chunked\r\n\r\n"
+	w := bufio.NewWriter(conn)
+	fmt.Fprintf(w, "RESPMOD %s ICAP/1.0\r\n", s.service.String())
+	fmt.Fprintf(w, "Host: %s\r\n", s.service.Host)
+	fmt.Fprintf(w, "User-Agent: brewd-malware/1\r\n")
+	fmt.Fprintf(w, "Allow: 204\r\n")
+	fmt.Fprintf(w, "Encapsulated: req-hdr=0, res-hdr=%d, res-body=%d\r\n\r\n", len(reqHeader), len(reqHeader)+len(resHeader))
+	w.WriteString(reqHeader)
+	w.WriteString(resHeader)
+
+	chunk := make([]byte, 64<<10)
+	for {
+		n, err := io.ReadFull(body, chunk)
+		if n > 0 {
+			fmt.Fprintf(w, "%x\r\n", n)
+			w.Write(chunk[:n])
+			w.WriteString("\r\n")
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	w.WriteString("0\r\n\r\n")
+	if err := w.Flush(); err != nil {
+		return "", fmt.Errorf("icap: %w", err)
+	}
+
+	reply := textproto.NewReader(bufio.NewReader(conn))
+	status, err := reply.ReadLine()
+	if err != nil {
+		return "", fmt.Errorf("icap: reading reply: %w", err)
+	}
+	header, err := reply.ReadMIMEHeader()
+	if err != nil {
+		return "", fmt.Errorf("icap: reading reply: %w", err)
+	}
+	return parseICAPReply(status, header)
+}
+
+// parseICAPReply reads the service's verdict from its status line and
+// headers
+func parseICAPReply(status string, header textproto.MIMEHeader) (string, error) {
+	version, rest, _ := strings.Cut(status, " ")
+	codeText, reason, _ := strings.Cut(rest, " ")
+	code, err := strconv.Atoi(codeText)
+	if !strings.HasPrefix(version, "ICAP/") || err != nil {
+		return "", fmt.Errorf("icap: invalid status line %q", status)
+	}
+
+	switch code {
+	case 204:
+		return "", nil
+	case 200:
+		for _, name := range icapThreatHeaders {
+			if value := header.Get(name); value != "" {
+				return icapThreatName(value), nil
+			}
+		}
+		return "unknown threat", nil
+	default:
+		return "", fmt.Errorf("icap: service answered %d %s", code, reason)
+	}
+}
+
+// icapThreatName takes the threat from an X-Infection-Found value such as
+// "Type=0; Resolution=2; Threat=Eicar-Test-Signature;", or returns other
+// headers' values as they are
+func icapThreatName(value string) string {
+	for _, field := range strings.Split(value, ";") {
+		if name, ok := strings.CutPrefix(strings.TrimSpace(field), "Threat="); ok && name != "" {
+			return name
+		}
+	}
+	return strings.TrimSpace(value)
+}
@@ -0,0 +1,161 @@
+package malware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http/httputil"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// icapRequest is what a fake ICAP service received
+type icapRequest struct {
+	line      string
+	header    textproto.MIMEHeader
+	reqHeader string
+	resHeader string
+	body      []byte
+}
+
+// fakeICAP is an ICAP antivirus service answering RESPMOD requests with
+// reply, or by scanning for infectedMarker without one. Requests are sent
+// on requests.
+type fakeICAP struct {
+	reply    string
+	requests chan icapRequest
+}
+
+func (f *fakeICAP) handle(conn net.Conn) {
+	r := textproto.NewReader(bufio.NewReader(conn))
+	var req icapRequest
+	var err error
+	if req.line, err = r.ReadLine(); err != nil {
+		return
+	}
+	if req.header, err = r.ReadMIMEHeader(); err != nil {
+		return
+	}
+
+	// Encapsulated gives the offsets of the HTTP headers and body
+	offsets := make(map[string]int)
+	for _, field := range strings.Split(req.header.Get("Encapsulated"), ",") {
+		name, offset, _ := strings.Cut(strings.TrimSpace(field), "=")
+		offsets[name], _ = strconv.Atoi(offset)
+	}
+	headers := make([]byte, offsets["res-body"])
+	if _, err := io.ReadFull(r.R, headers); err != nil {
+		return
+	}
+	req.reqHeader = string(headers[offsets["req-hdr"]:offsets["res-hdr"]])
+	req.resHeader = string(headers[offsets["res-hdr"]:])
+	if req.body, err = io.ReadAll(httputil.NewChunkedReader(r.R)); err != nil {
+		return
+	}
+	f.requests <- req
+
+	reply := f.reply
+	if reply == "" && bytes.Contains(req.body, []byte(infectedMarker)) {
+		reply = "ICAP/1.0 200 OK\r\n" +
+			"ISTag: \"fake-1\"\r\n" +
+			"X-Infection-Found: Type=0; Resolution=2; Threat=Test-Signature;\r\n" +
+			"Encapsulated: null-body=0\r\n\r\n"
+	} else if reply == "" {
+		reply = "ICAP/1.0 204 No Content\r\nISTag: \"fake-1\"\r\n\r\n"
+	}
+	io.WriteString(conn, reply)
+}
+
+func newTestICAPScanner(t *testing.T, service *fakeICAP) *ICAPScanner {
+	t.Helper()
+	l := listen(t)
+	serve(t, l, service.handle)
+	u, err := url.Parse("icap://" + l.Addr().String() + "/avscan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewICAPScanner(u)
+}
+
+func TestICAPScan(t *testing.T) {
+	service := &fakeICAP{requests: make(chan icapRequest, 1)}
+	scanner := newTestICAPScanner(t, service)
+
+	tests := []struct {
+		name   string
+		body   string
+		threat string
+	}{
+		{"clean", "a photo", ""},
+		{"infected", "a photo " + infectedMarker, "Test-Signature"},
+		{"empty", "", ""},
+		{"chunked", strings.Repeat("x", 64<<10+10), ""},
+	}
+	for _, tt := range tests {
+		threat, err := scanner.Scan(context.Background(), strings.NewReader(tt.body))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if threat != tt.threat {
+			t.Errorf("%s: threat %q, want %q", tt.name, threat, tt.threat)
+		}
+
+		req := <-service.requests
+		if want := fmt.Sprintf("RESPMOD %s ICAP/1.0", scanner.service); req.line != want {
+			t.Errorf("%s: request line %q, want %q", tt.name, req.line, want)
+		}
+		if got := req.header.Get("Allow"); got != "204" {
+			t.Errorf("%s: Allow %q, want 204", tt.name, got)
+		}
+		if !strings.HasPrefix(req.reqHeader, "GET ") || !strings.HasSuffix(req.reqHeader, "\r\n\r\n") {
+			t.Errorf("%s: encapsulated request header %q", tt.name, req.reqHeader)
+		}
+		if !strings.HasPrefix(req.resHeader, "HTTP/1.1 200 OK\r\n") || !strings.HasSuffix(req.resHeader, "\r\n\r\n") {
+			t.Errorf("%s: encapsulated response header %q", tt.name, req.resHeader)
+		}
+		if string(req.body) != tt.body {
+			t.Errorf("%s: service received %d bytes, want %d", tt.name, len(req.body), len(tt.body))
+		}
+	}
+}
+
+func TestICAPScanError(t *testing.T) {
+	service := &fakeICAP{reply: "ICAP/1.0 500 Server Error\r\n\r\n", requests: make(chan icapRequest, 1)}
+	scanner := newTestICAPScanner(t, service)
+
+	_, err := scanner.Scan(context.Background(), strings.NewReader("a photo"))
+	if err == nil || !strings.Contains(err.Error(), "500 Server Error") {
+		t.Errorf("error %v, want the service's 500", err)
+	}
+}
+
+func TestParseICAPReply(t *testing.T) {
+	tests := []struct {
+		status string
+		header textproto.MIMEHeader
+		threat string
+		err    bool
+	}{
+		{"ICAP/1.0 204 No Content", nil, "", false},
+		{"ICAP/1.0 200 OK", textproto.MIMEHeader{"X-Infection-Found": {"Type=0; Resolution=2; Threat=Eicar-Test-Signature;"}}, "Eicar-Test-Signature", false},
+		{"ICAP/1.0 200 OK", textproto.MIMEHeader{"X-Virus-Id": {"EICAR Test String"}}, "EICAR Test String", false},
+		{"ICAP/1.0 200 OK", textproto.MIMEHeader{"X-Violations-Found": {"1"}}, "1", false},
+		{"ICAP/1.0 200 OK", textproto.MIMEHeader{}, "unknown threat", false},
+		{"ICAP/1.0 404 ICAP Service not found", nil, "", true},
+		{"HTTP/1.1 200 OK", nil, "", true},
+		{"garbage", nil, "", true},
+	}
+	for _, tt := range tests {
+		threat, err := parseICAPReply(tt.status, tt.header)
+		if threat != tt.threat || (err != nil) != tt.err {
+			t.Errorf("parseICAPReply(%q, %v) = %q, %v, want %q, error %v", tt.status, tt.header, threat, err, tt.threat, tt.err)
+		}
+	}
+}
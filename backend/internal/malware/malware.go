@@ -0,0 +1,62 @@
+// Package malware scans uploads for viruses and other malware before they
+// are processed or served. Scanners are external services: ClamAV's clamd,
+// or any ICAP server with an antivirus service.
+package malware
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+)
+
+// Scanner checks uploads for malware
+type Scanner interface {
+	// Scan reads body to the end and returns the name of the threat found
+	// in it, or "" if it is clean. Errors mean the upload couldn't be
+	// scanned, not that it is unsafe.
+	Scan(ctx context.Context, body io.Reader) (threat string, err error)
+}
+
+// Scanner names accepted by New
+const (
+	ScannerClamAV = "clamav"
+	ScannerICAP   = "icap"
+	ScannerNone   = "none"
+)
+
+// scanTimeout bounds a single scan, from connecting to the verdict
+const scanTimeout = 60 * time.Second
+
+// New returns the scanner called name, or nil for none. target addresses
+// the scanning service:
+//   - clamav: clamd at tcp://host:3310 or unix:///run/clamav/clamd.ctl
+//   - icap: an ICAP antivirus service, e.g. icap://host:1344/avscan
+func New(name, target string) (Scanner, error) {
+	switch name {
+	case ScannerClamAV:
+		u, err := url.Parse(target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid clamd address %q: %w", target, err)
+		}
+		switch {
+		case u.Scheme == "tcp" && u.Host != "":
+			return NewClamdScanner("tcp", u.Host), nil
+		case u.Scheme == "unix" && u.Path != "":
+			return NewClamdScanner("unix", u.Path), nil
+		default:
+			return nil, fmt.Errorf("clamav scanner needs a tcp:// or unix:// address, got %q", target)
+		}
+	case ScannerICAP:
+		u, err := url.Parse(target)
+		if err != nil || u.Scheme != "icap" || u.Host == "" || u.Path == "" {
+			return nil, fmt.Errorf("icap scanner needs an icap://host:port/service URL, got %q", target)
+		}
+		return NewICAPScanner(u), nil
+	case ScannerNone:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown upload scanner %q", name)
+	}
+}
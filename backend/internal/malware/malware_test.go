@@ -0,0 +1,60 @@
+package malware
+
+import (
+	"net"
+	"testing"
+)
+
+// infectedMarker is what the fake scanning services take as malware
+const infectedMarker = "INFECTED"
+
+// serve accepts connections on l until the test ends, handling each with
+// handle
+func serve(t *testing.T, l net.Listener, handle func(conn net.Conn)) {
+	t.Helper()
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+}
+
+// listen listens on a free local TCP port
+func listen(t *testing.T) net.Listener {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name, target string
+		ok           bool
+	}{
+		{ScannerClamAV, "tcp://clamd:3310", true},
+		{ScannerClamAV, "unix:///run/clamav/clamd.ctl", true},
+		{ScannerClamAV, "clamd:3310", false},
+		{ScannerICAP, "icap://icap:1344/avscan", true},
+		{ScannerICAP, "icap://icap:1344", false},
+		{ScannerICAP, "http://icap:1344/avscan", false},
+		{ScannerNone, "", true},
+		{"sophos", "", false},
+	}
+	for _, tt := range tests {
+		_, err := New(tt.name, tt.target)
+		if (err == nil) != tt.ok {
+			t.Errorf("New(%q, %q): error %v, want ok %v", tt.name, tt.target, err, tt.ok)
+		}
+	}
+}
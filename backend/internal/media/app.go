@@ -0,0 +1,60 @@
+package media
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"strconv"
+	"time"
+
+	"brewd/internal/links"
+)
+
+// AppPath is where brewd serves objects signed by the app signer
+const AppPath = "/media"
+
+// AppSigner signs URLs under AppPath on the site, which brewd serves from
+// the object store after checking their signature
+type AppSigner struct {
+	site *links.Site
+	key  []byte
+}
+
+// NewAppSigner creates a signer for URLs on site, keyed from secret
+func NewAppSigner(site *links.Site, secret string) *AppSigner {
+	// Derive a dedicated key so media signatures can't be confused with
+	// anything else signed with the same secret
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("brewd media url"))
+	return &AppSigner{site: site, key: mac.Sum(nil)}
+}
+
+func (s *AppSigner) SignURL(key string, expires time.Time, opts Options) (string, error) {
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+	if opts.Filename != "" {
+		query.Set("filename", opts.Filename)
+	}
+	query.Set("signature", s.sign(key, expires.Unix(), opts.Filename))
+	return s.site.Join(AppPath, key) + "?" + query.Encode(), nil
+}
+
+// Verify reports whether signature is valid for key, expiring at the Unix
+// time expires and downloaded as filename, and hasn't expired
+func (s *AppSigner) Verify(key string, expires int64, filename, signature string) bool {
+	if time.Now().Unix() >= expires {
+		return false
+	}
+	return hmac.Equal([]byte(s.sign(key, expires, filename)), []byte(signature))
+}
+
+func (s *AppSigner) sign(key string, expires int64, filename string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(key))
+	mac.Write([]byte{0})
+	mac.Write([]byte(strconv.FormatInt(expires, 10)))
+	mac.Write([]byte{0})
+	mac.Write([]byte(filename))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
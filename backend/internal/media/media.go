@@ -0,0 +1,109 @@
+// Package media issues time-limited URLs for private objects in the object
+// store, such as brew photos and backup dumps, so clients download them
+// from storage or a CDN rather than through the API. A signer makes the
+// URLs for where objects are served from:
+//   - app: brewd serves objects itself under /media, checking an HMAC in
+//     the URL; works with any store, including a local directory
+//   - s3: S3 (or MinIO) presigned URLs, signed for a public endpoint when
+//     the store is only reachable internally
+//   - cloudfront: CloudFront signed URLs with a canned policy, for a
+//     distribution in front of the bucket
+package media
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"brewd/internal/links"
+	"brewd/internal/objectstore"
+)
+
+// Signer names accepted by New
+const (
+	SignerApp        = "app"
+	SignerS3         = "s3"
+	SignerCloudFront = "cloudfront"
+)
+
+// Options shape how a signed object is downloaded
+type Options struct {
+	// Filename, if set, makes browsers save the object under this name
+	// rather than show it. CloudFront ignores it unless its cache policy
+	// forwards the query string to the origin.
+	Filename string
+}
+
+// Signer signs URLs for objects
+type Signer interface {
+	// SignURL returns a URL from which the object under key can be
+	// downloaded until expires
+	SignURL(key string, expires time.Time, opts Options) (string, error)
+}
+
+// Config selects and configures a signer
+type Config struct {
+	Signer string
+	// TTL is how long URLs stay valid at least
+	TTL time.Duration
+
+	// app: the site URLs are served on, and the secret their HMAC key is
+	// derived from
+	Site   *links.Site
+	Secret string
+
+	// s3: the store, which must be an S3 store, and the endpoint clients
+	// reach it through, if not the store's own
+	Store          objectstore.Store
+	PublicEndpoint string
+
+	// cloudfront: the distribution's URL, and the ID and path to the PEM
+	// RSA private key of its trusted key pair
+	CDNURL     string
+	KeyPairID  string
+	PrivateKey string
+}
+
+// URLs signs object URLs with a signer, valid for a TTL
+type URLs struct {
+	signer Signer
+	ttl    time.Duration
+}
+
+// New returns URLs signed by the signer config names
+func New(config Config) (*URLs, error) {
+	if config.TTL <= 0 {
+		return nil, errors.New("media URL TTL must be positive")
+	}
+
+	var signer Signer
+	var err error
+	switch config.Signer {
+	case SignerApp:
+		signer = NewAppSigner(config.Site, config.Secret)
+	case SignerS3:
+		// Links last up to two TTLs past the hour they're signed in
+		if 2*config.TTL+time.Hour > 7*24*time.Hour {
+			return nil, errors.New("s3 media URLs can't outlast a week; lower the TTL")
+		}
+		signer, err = NewS3Signer(config.Store, config.PublicEndpoint)
+	case SignerCloudFront:
+		signer, err = NewCloudFrontSigner(config.CDNURL, config.KeyPairID, config.PrivateKey)
+	default:
+		return nil, fmt.Errorf("unknown media URL signer %q (want app, s3 or cloudfront)", config.Signer)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &URLs{signer: signer, ttl: config.TTL}, nil
+}
+
+// Sign returns a signed URL for the object under key and when it expires.
+// Expiry is rounded up to a multiple of the TTL, so an object's URL stays
+// the same for a while and clients can cache what it points to; URLs stay
+// valid for between one and two TTLs.
+func (u *URLs) Sign(key string, opts Options) (string, time.Time, error) {
+	expires := time.Now().Truncate(u.ttl).Add(2 * u.ttl)
+	signed, err := u.signer.SignURL(key, expires, opts)
+	return signed, expires, err
+}
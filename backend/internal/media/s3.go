@@ -0,0 +1,48 @@
+package media
+
+import (
+	"errors"
+	"mime"
+	"net/url"
+	"time"
+
+	"brewd/internal/objectstore"
+)
+
+// S3Signer presigns S3 URLs, which S3 and MinIO check themselves
+type S3Signer struct {
+	store *objectstore.S3Store
+}
+
+// NewS3Signer creates a signer for store, which must be an S3 store. With
+// publicEndpoint set, URLs address the bucket through it instead, e.g. the
+// public URL of a MinIO that brewd reaches on an internal address.
+func NewS3Signer(store objectstore.Store, publicEndpoint string) (*S3Signer, error) {
+	s3, ok := store.(*objectstore.S3Store)
+	if !ok {
+		return nil, errors.New("s3 media URL signer needs an s3:// OBJECT_STORE_URL")
+	}
+	if publicEndpoint != "" {
+		var err error
+		if s3, err = s3.WithEndpoint(publicEndpoint); err != nil {
+			return nil, err
+		}
+	}
+	return &S3Signer{store: s3}, nil
+}
+
+// SignURL signs as of the start of the hour, so the URL changes at most
+// hourly within an expiry window
+func (s *S3Signer) SignURL(key string, expires time.Time, opts Options) (string, error) {
+	signedAt := time.Now().Truncate(time.Hour)
+	query := url.Values{}
+	if opts.Filename != "" {
+		query.Set("response-content-disposition", attachment(opts.Filename))
+	}
+	return s.store.PresignGet(key, signedAt, expires.Sub(signedAt), query)
+}
+
+// attachment is the Content-Disposition saving a download as filename
+func attachment(filename string) string {
+	return mime.FormatMediaType("attachment", map[string]string{"filename": filename})
+}
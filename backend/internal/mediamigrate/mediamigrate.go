@@ -0,0 +1,262 @@
+// Package mediamigrate copies the objects brewd keeps in its object store,
+// brew photo uploads and renditions and backup dumps, to another store,
+// e.g. from a local directory to S3. Objects are found through the rows
+// that refer to them. Each copy is verified by reading it back and
+// comparing SHA-256 checksums, and recorded in media_migration_object with
+// the version its row had, so an interrupted migration resumes where it
+// stopped and an object rewritten since it was copied is copied again.
+package mediamigrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/logger"
+	"brewd/internal/objectstore"
+)
+
+// pageSize is how many objects are listed at a time
+const pageSize = 100
+
+// Progress counts a migration's objects. Total and Migrated cover every
+// run to the destination; the rest only this one.
+type Progress struct {
+	Total    int
+	Migrated int
+	Copied   int
+	Bytes    int64
+	Missing  int
+	Failed   int
+}
+
+// Migrator copies objects from one store to another
+type Migrator struct {
+	queries     *db.Queries
+	source      objectstore.Store
+	destination objectstore.Store
+	name        string
+	workers     int
+
+	mu       sync.Mutex
+	progress Progress
+}
+
+// New creates a migrator copying from source to destination. name
+// identifies the destination in media_migration_object, which records what
+// has been copied to it; workers objects are copied at once.
+func New(queries *db.Queries, source, destination objectstore.Store, name string, workers int) *Migrator {
+	return &Migrator{
+		queries:     queries,
+		source:      source,
+		destination: destination,
+		name:        name,
+		workers:     max(workers, 1),
+	}
+}
+
+// errMissing marks objects the database refers to that the source store
+// doesn't have
+var errMissing = errors.New("object missing from source store")
+
+// Reset forgets what has been copied to the destination, so the next run
+// copies everything again
+func (m *Migrator) Reset(ctx context.Context) error {
+	return m.queries.ResetMediaMigration(ctx, m.name)
+}
+
+// Run copies every object not yet copied to the destination, reporting
+// progress every interval. Objects that fail to copy are logged and left
+// for the next run; Run only fails if it can't list the objects.
+func (m *Migrator) Run(ctx context.Context, interval time.Duration) (Progress, error) {
+	counts, err := m.queries.CountMediaObjects(ctx, m.name)
+	if err != nil {
+		return Progress{}, err
+	}
+	m.progress = Progress{Total: int(counts.Total), Migrated: int(counts.Migrated)}
+	logger.Info("Media migration starting", "destination", m.name, "objects", counts.Total, "migrated", counts.Migrated)
+
+	objects := make(chan db.ListUnmigratedMediaObjectsRow)
+	var wg sync.WaitGroup
+	for range m.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for object := range objects {
+				m.migrate(ctx, object)
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go m.report(interval, done)
+	err = m.list(ctx, objects)
+	close(objects)
+	wg.Wait()
+	close(done)
+
+	progress := m.snapshot()
+	if err == nil {
+		err = ctx.Err()
+	}
+	return progress, err
+}
+
+// list feeds the objects left to copy to objects, in key order
+func (m *Migrator) list(ctx context.Context, objects chan<- db.ListUnmigratedMediaObjectsRow) error {
+	after := ""
+	for {
+		page, err := m.queries.ListUnmigratedMediaObjects(ctx, db.ListUnmigratedMediaObjectsParams{
+			AfterKey:    after,
+			Destination: m.name,
+			RowLimit:    pageSize,
+		})
+		if err != nil {
+			return err
+		}
+		for _, object := range page {
+			select {
+			case objects <- object:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+		if len(page) < pageSize {
+			return nil
+		}
+		after = page[len(page)-1].ObjectKey
+	}
+}
+
+// migrate copies an object and records it, counting the outcome
+func (m *Migrator) migrate(ctx context.Context, object db.ListUnmigratedMediaObjectsRow) {
+	size, sum, err := m.copy(ctx, object)
+	if err == nil {
+		err = m.queries.RecordMediaMigrationObject(ctx, db.RecordMediaMigrationObjectParams{
+			Destination: m.name,
+			ObjectKey:   object.ObjectKey,
+			Version:     object.Version,
+			SizeBytes:   size,
+			Sha256:      sum,
+		})
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case err == nil:
+		m.progress.Copied++
+		m.progress.Migrated++
+		m.progress.Bytes += size
+	case errors.Is(err, errMissing):
+		m.progress.Missing++
+		logger.Warn("Media object missing", "key", object.ObjectKey)
+	case ctx.Err() != nil:
+		// Interrupted; the object is copied on the next run
+	default:
+		m.progress.Failed++
+		logger.Error("Failed to migrate media object", "key", object.ObjectKey, "error", err)
+	}
+}
+
+// copy copies an object through a temporary file, checking it against the
+// size and checksum its row records, and verifies the destination's copy
+// has the same checksum. It returns the object's size and SHA-256.
+func (m *Migrator) copy(ctx context.Context, object db.ListUnmigratedMediaObjectsRow) (int64, string, error) {
+	body, err := m.source.Get(ctx, object.ObjectKey)
+	if errors.Is(err, objectstore.ErrNotFound) {
+		return 0, "", errMissing
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	defer body.Close()
+
+	f, err := os.CreateTemp("", "mediamigrate-*")
+	if err != nil {
+		return 0, "", err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	size, sum, err := digest(io.TeeReader(body, f))
+	if err != nil {
+		return 0, "", fmt.Errorf("reading source: %w", err)
+	}
+	if object.SizeBytes != nil && *object.SizeBytes != size {
+		return 0, "", fmt.Errorf("source is %d bytes, recorded as %d", size, *object.SizeBytes)
+	}
+	if object.Sha256 != nil && *object.Sha256 != sum {
+		return 0, "", fmt.Errorf("source has SHA-256 %s, recorded as %s", sum, *object.Sha256)
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, "", err
+	}
+	if err := m.destination.Put(ctx, object.ObjectKey, f, size, object.ContentType); err != nil {
+		return 0, "", fmt.Errorf("writing destination: %w", err)
+	}
+
+	copied, err := m.destination.Get(ctx, object.ObjectKey)
+	if err != nil {
+		return 0, "", fmt.Errorf("reading back destination: %w", err)
+	}
+	defer copied.Close()
+	copiedSize, copiedSum, err := digest(copied)
+	if err != nil {
+		return 0, "", fmt.Errorf("reading back destination: %w", err)
+	}
+	if copiedSize != size || copiedSum != sum {
+		return 0, "", fmt.Errorf("destination copy has SHA-256 %s (%d bytes), source %s (%d bytes)", copiedSum, copiedSize, sum, size)
+	}
+	return size, sum, nil
+}
+
+// report logs progress every interval until done is closed
+func (m *Migrator) report(interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	start := time.Now()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			p := m.snapshot()
+			elapsed := time.Since(start).Seconds()
+			logger.Info("Media migration progress",
+				"migrated", p.Migrated,
+				"total", p.Total,
+				"copied", p.Copied,
+				"copied_mb", p.Bytes>>20,
+				"mb_per_second", float64(p.Bytes>>20)/elapsed,
+				"missing", p.Missing,
+				"failed", p.Failed,
+			)
+		}
+	}
+}
+
+func (m *Migrator) snapshot() Progress {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.progress
+}
+
+// digest reads r to the end, returning its size and hex SHA-256
+func digest(r io.Reader) (int64, string, error) {
+	h := sha256.New()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return 0, "", err
+	}
+	return n, hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,60 @@
+package mergepatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// ErrNotObject is returned when a document or patch isn't a JSON object
+var ErrNotObject = errors.New("mergepatch: not a JSON object")
+
+// Apply merges patch into doc following JSON Merge Patch (RFC 7386): keys in
+// the patch replace the document's, null removes a key, and nested objects
+// are merged the same way. Both must be JSON objects; an empty doc is
+// treated as {}.
+func Apply(doc, patch []byte) ([]byte, error) {
+	target := map[string]any{}
+	if len(doc) > 0 {
+		var err error
+		if target, err = decodeObject(doc); err != nil {
+			return nil, err
+		}
+	}
+	p, err := decodeObject(patch)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(merge(target, p))
+}
+
+// decodeObject decodes a JSON object, keeping numbers as written so large
+// integers survive the round trip
+func decodeObject(data []byte) (map[string]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var obj map[string]any
+	if err := dec.Decode(&obj); err != nil || obj == nil || dec.More() {
+		return nil, ErrNotObject
+	}
+	return obj, nil
+}
+
+func merge(target, patch map[string]any) map[string]any {
+	for key, value := range patch {
+		if value == nil {
+			delete(target, key)
+			continue
+		}
+		if nested, ok := value.(map[string]any); ok {
+			existing, _ := target[key].(map[string]any)
+			if existing == nil {
+				existing = map[string]any{}
+			}
+			target[key] = merge(existing, nested)
+			continue
+		}
+		target[key] = value
+	}
+	return target
+}
@@ -0,0 +1,128 @@
+package methods
+
+// Catalog lists every brew method accepted by the brew.brew_method column.
+// Ranges are deliberately generous: they catch typos and unit mix-ups, not
+// unconventional recipes.
+var Catalog = []Method{
+	{
+		ID:        "espresso",
+		Name:      "Espresso",
+		Dose:      required(5, 30),
+		Water:     required(10, 120), // beverage yield
+		WaterTemp: optional(80, 100),
+		BrewTime:  optional(5, 90),
+		Ratio:     &Range{Min: 1, Max: 5},
+	},
+	{
+		ID:        "v60",
+		Name:      "V60",
+		Dose:      required(8, 60),
+		Water:     required(100, 1000),
+		WaterTemp: optional(80, 100),
+		BrewTime:  optional(60, 480),
+		Ratio:     &Range{Min: 10, Max: 20},
+	},
+	{
+		ID:        "pour_over",
+		Name:      "Pour Over",
+		Dose:      required(8, 80),
+		Water:     required(100, 1500),
+		WaterTemp: optional(80, 100),
+		BrewTime:  optional(60, 600),
+		Ratio:     &Range{Min: 10, Max: 20},
+	},
+	{
+		ID:        "chemex",
+		Name:      "Chemex",
+		Dose:      required(15, 80),
+		Water:     required(200, 1500),
+		WaterTemp: optional(80, 100),
+		BrewTime:  optional(120, 600),
+		Ratio:     &Range{Min: 12, Max: 20},
+	},
+	{
+		ID:        "aeropress",
+		Name:      "AeroPress",
+		Dose:      required(8, 35),
+		Water:     required(30, 300), // inverted/concentrate recipes use little water
+		WaterTemp: optional(60, 100),
+		BrewTime:  optional(20, 480),
+		Ratio:     &Range{Min: 3, Max: 20},
+	},
+	{
+		ID:        "french_press",
+		Name:      "French Press",
+		Dose:      required(10, 100),
+		Water:     required(150, 1500),
+		WaterTemp: optional(80, 100),
+		BrewTime:  optional(120, 900),
+		Ratio:     &Range{Min: 8, Max: 20},
+	},
+	{
+		ID:           "cold_brew",
+		Name:         "Cold Brew",
+		Dose:         required(20, 1000),
+		Water:        required(100, 5000),
+		WaterTemp:    optional(0, 30),
+		BrewTime:     optional(4*3600, 48*3600),
+		Ratio:        &Range{Min: 3, Max: 18},
+		LongDuration: true,
+	},
+	{
+		ID:        "drip",
+		Name:      "Drip",
+		Dose:      required(10, 150),
+		Water:     required(150, 2500),
+		WaterTemp: optional(80, 100),
+		BrewTime:  optional(120, 900),
+		Ratio:     &Range{Min: 12, Max: 20},
+	},
+	{
+		ID:        "moka_pot",
+		Name:      "Moka Pot",
+		Dose:      required(5, 40),
+		Water:     required(50, 500),
+		WaterTemp: optional(20, 100),
+		BrewTime:  optional(60, 600),
+		Ratio:     &Range{Min: 4, Max: 14},
+	},
+	{
+		ID:        "siphon",
+		Name:      "Siphon",
+		Dose:      required(10, 60),
+		Water:     required(150, 1000),
+		WaterTemp: optional(80, 100),
+		BrewTime:  optional(45, 300),
+		Ratio:     &Range{Min: 10, Max: 20},
+	},
+	{
+		ID:        "turkish",
+		Name:      "Turkish",
+		Dose:      required(4, 30),
+		Water:     required(40, 300),
+		WaterTemp: optional(20, 100),
+		BrewTime:  optional(60, 420),
+		Ratio:     &Range{Min: 6, Max: 15},
+	},
+	{
+		ID:        "percolator",
+		Name:      "Percolator",
+		Dose:      required(15, 200),
+		Water:     required(200, 3000),
+		WaterTemp: optional(80, 100),
+		BrewTime:  optional(180, 1200),
+		Ratio:     &Range{Min: 10, Max: 20},
+	},
+	{
+		ID:   "other",
+		Name: "Other",
+	},
+}
+
+func required(lo, hi float64) Param {
+	return Param{Required: true, Range: &Range{Min: lo, Max: hi}}
+}
+
+func optional(lo, hi float64) Param {
+	return Param{Range: &Range{Min: lo, Max: hi}}
+}
@@ -0,0 +1,130 @@
+package methods
+
+// Range is an inclusive bound on a brew parameter, in canonical units
+// (grams, degrees Celsius, seconds, or grams of water per gram of coffee)
+type Range struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// Contains reports whether value lies within the range
+func (r Range) Contains(value float64) bool {
+	return value >= r.Min && value <= r.Max
+}
+
+// Param describes what a method expects of a single brew parameter
+type Param struct {
+	Required bool   `json:"required"`
+	Range    *Range `json:"range,omitempty"`
+}
+
+// Method is a brew method schema: the parameters it expects and the ranges
+// that make sense for it
+type Method struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Dose         Param  `json:"dose_grams"`
+	Water        Param  `json:"water_grams"`
+	WaterTemp    Param  `json:"water_temp_c"`
+	BrewTime     Param  `json:"brew_time_seconds"`
+	GrindSetting Param  `json:"grind_setting"`
+	Ratio        *Range `json:"ratio,omitempty"`
+	// LongDuration methods steep for hours or days; their brew times are
+	// reported separately so they don't skew averages
+	LongDuration bool `json:"long_duration"`
+}
+
+// Brew parameter names used in FieldError.Param
+const (
+	ParamDose         = "dose_grams"
+	ParamWater        = "water_grams"
+	ParamWaterTemp    = "water_temp_c"
+	ParamBrewTime     = "brew_time_seconds"
+	ParamGrindSetting = "grind_setting"
+	ParamRatio        = "ratio"
+)
+
+// Rule identifies which constraint a parameter failed
+type Rule string
+
+const (
+	RuleRequired Rule = "required"
+	RuleRange    Rule = "range"
+)
+
+// FieldError is a single parameter that doesn't fit the method schema
+type FieldError struct {
+	Param string
+	Rule  Rule
+	Range *Range
+}
+
+// Values are the canonical brew parameters to validate
+type Values struct {
+	DoseGrams       *float64
+	WaterGrams      *float64
+	WaterTempC      *float64
+	BrewTimeSeconds *int32
+	GrindSetting    *string
+}
+
+// Validate checks v against the method schema and returns one error per
+// offending parameter, in a stable order
+func (m Method) Validate(v Values) []FieldError {
+	var errs []FieldError
+
+	check := func(name string, p Param, value *float64) {
+		if value == nil {
+			if p.Required {
+				errs = append(errs, FieldError{Param: name, Rule: RuleRequired})
+			}
+			return
+		}
+		if p.Range != nil && !p.Range.Contains(*value) {
+			errs = append(errs, FieldError{Param: name, Rule: RuleRange, Range: p.Range})
+		}
+	}
+
+	var brewTime *float64
+	if v.BrewTimeSeconds != nil {
+		t := float64(*v.BrewTimeSeconds)
+		brewTime = &t
+	}
+
+	check(ParamDose, m.Dose, v.DoseGrams)
+	check(ParamWater, m.Water, v.WaterGrams)
+	check(ParamWaterTemp, m.WaterTemp, v.WaterTempC)
+	check(ParamBrewTime, m.BrewTime, brewTime)
+	if m.GrindSetting.Required && (v.GrindSetting == nil || *v.GrindSetting == "") {
+		errs = append(errs, FieldError{Param: ParamGrindSetting, Rule: RuleRequired})
+	}
+
+	if m.Ratio != nil && v.DoseGrams != nil && v.WaterGrams != nil && *v.DoseGrams > 0 {
+		if !m.Ratio.Contains(*v.WaterGrams / *v.DoseGrams) {
+			errs = append(errs, FieldError{Param: ParamRatio, Rule: RuleRange, Range: m.Ratio})
+		}
+	}
+
+	return errs
+}
+
+// LongDurationIDs returns the ids of long-duration methods
+func LongDurationIDs() []string {
+	ids := []string{}
+	for _, m := range Catalog {
+		if m.LongDuration {
+			ids = append(ids, m.ID)
+		}
+	}
+	return ids
+}
+
+// Lookup returns the catalog entry for a brew_method id
+func Lookup(id string) (Method, bool) {
+	for _, m := range Catalog {
+		if m.ID == id {
+			return m, true
+		}
+	}
+	return Method{}, false
+}
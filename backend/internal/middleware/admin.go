@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+
+	"brewd/internal/i18n"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireAdmin is middleware that restricts routes to the given user IDs. It
+// must run after RequireAuth.
+func RequireAdmin(adminUserIDs []string) gin.HandlerFunc {
+	admins := make(map[string]bool, len(adminUserIDs))
+	for _, id := range adminUserIDs {
+		admins[id] = true
+	}
+
+	return func(c *gin.Context) {
+		if !admins[c.GetString("user_id")] {
+			respond.Error(c, http.StatusForbidden, i18n.CodeForbidden)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
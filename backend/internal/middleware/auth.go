@@ -4,31 +4,38 @@ import (
 	"net/http"
 	"strings"
 
+	"brewd/internal/accounts"
 	"brewd/internal/auth"
+	"brewd/internal/cookies"
+	"brewd/internal/i18n"
+	"brewd/internal/respond"
+	"brewd/pkg/database"
 
 	"github.com/gin-gonic/gin"
 )
 
-// RequireAuth is middleware that validates JWT tokens and protects routes
-func RequireAuth(authService auth.AuthService) gin.HandlerFunc {
+// RequireAuth is middleware that validates JWT tokens and protects routes.
+// The token comes from the Authorization header, or for the web client's
+// cookie mode, the session cookie; the CSRF middleware guards the latter.
+// Tokens of accounts that have since been deactivated or deleted are
+// refused, as activeCache reports them.
+func RequireAuth(authService auth.AuthService, activeCache *accounts.Cache) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"error":   "Authorization header required",
-			})
+			if _, err := c.Cookie(cookies.Session); err == nil {
+				requireCookieAuth(c, authService, activeCache)
+				return
+			}
+			respond.Error(c, http.StatusUnauthorized, i18n.CodeAuthHeaderRequired)
 			c.Abort()
 			return
 		}
 
 		// Check Bearer prefix
 		if !strings.HasPrefix(authHeader, "Bearer ") {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"error":   "Invalid authorization header format",
-			})
+			respond.Error(c, http.StatusUnauthorized, i18n.CodeAuthHeaderInvalid)
 			c.Abort()
 			return
 		}
@@ -36,37 +43,57 @@ func RequireAuth(authService auth.AuthService) gin.HandlerFunc {
 		// Extract token
 		token := strings.TrimPrefix(authHeader, "Bearer ")
 		if token == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"error":   "Token required",
-			})
+			respond.Error(c, http.StatusUnauthorized, i18n.CodeTokenRequired)
 			c.Abort()
 			return
 		}
 
-		// Validate token
-		claims, err := authService.ValidateToken(token)
-		if err != nil {
-			if err == auth.ErrExpiredToken {
-				c.JSON(http.StatusUnauthorized, gin.H{
-					"success": false,
-					"error":   "Token has expired",
-				})
-			} else {
-				c.JSON(http.StatusUnauthorized, gin.H{
-					"success": false,
-					"error":   "Invalid token",
-				})
-			}
-			c.Abort()
-			return
-		}
+		authenticate(c, authService, activeCache, token)
+	}
+}
+
+// requireCookieAuth authenticates a request by its session cookie
+func requireCookieAuth(c *gin.Context, authService auth.AuthService, activeCache *accounts.Cache) {
+	token, _ := c.Cookie(cookies.Session)
+	if token == "" {
+		respond.Error(c, http.StatusUnauthorized, i18n.CodeTokenRequired)
+		c.Abort()
+		return
+	}
 
-		// Attach user information to context
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
+	authenticate(c, authService, activeCache, token)
+}
 
-		// Continue to the next handler
-		c.Next()
+// authenticate validates token and attaches its user to the request, if
+// their account is still active
+func authenticate(c *gin.Context, authService auth.AuthService, activeCache *accounts.Cache, token string) {
+	claims, err := authService.ValidateToken(token)
+	if err != nil {
+		if err == auth.ErrExpiredToken {
+			respond.Error(c, http.StatusUnauthorized, i18n.CodeTokenExpired)
+		} else {
+			respond.Error(c, http.StatusUnauthorized, i18n.CodeTokenInvalid)
+		}
+		c.Abort()
+		return
+	}
+	if !activeCache.Active(c.Request.Context(), claims.UserID) {
+		respond.Error(c, http.StatusUnauthorized, i18n.CodeAccountDeactivated)
+		c.Abort()
+		return
 	}
+
+	// Attach user information to context
+	setUser(c, claims.UserID)
+	c.Set("username", claims.Username)
+
+	// Continue to the next handler
+	c.Next()
+}
+
+// setUser attaches the authenticated user to the request, and to the
+// queries it sends, for the row security policies
+func setUser(c *gin.Context, userID string) {
+	c.Set("user_id", userID)
+	c.Request = c.Request.WithContext(database.WithCurrentUser(c.Request.Context(), userID))
 }
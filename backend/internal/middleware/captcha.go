@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"brewd/internal/captcha"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireCaptcha is middleware that requires a solved CAPTCHA, its token in
+// the X-Captcha-Token header, to blunt bots on public endpoints
+func RequireCaptcha(verifier captcha.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !VerifyCaptcha(c, verifier) {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// VerifyCaptcha checks the CAPTCHA token in the X-Captcha-Token header,
+// responding with an error if it doesn't pass, for handlers that only
+// sometimes require one
+func VerifyCaptcha(c *gin.Context, verifier captcha.Verifier) bool {
+	err := verifier.Verify(c.Request.Context(), c.GetHeader(captcha.Header), c.ClientIP())
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, captcha.ErrMissing):
+		respond.Error(c, http.StatusForbidden, i18n.CodeCaptchaRequired)
+	case errors.Is(err, captcha.ErrFailed):
+		respond.Error(c, http.StatusForbidden, i18n.CodeCaptchaFailed)
+	default:
+		logger.Error("Failed to verify captcha", "error", err)
+		respond.Error(c, http.StatusServiceUnavailable, i18n.CodeCaptchaUnavailable)
+	}
+	return false
+}
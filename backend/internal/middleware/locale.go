@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"brewd/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Locale negotiates the response language from the Accept-Language header
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tag := i18n.Negotiate(c.GetHeader("Accept-Language"))
+		i18n.SetLocale(c, tag)
+
+		c.Header("Content-Language", tag.String())
+		c.Writer.Header().Add("Vary", "Accept-Language")
+
+		c.Next()
+	}
+}
@@ -1,24 +1,45 @@
 package middleware
 
 import (
+	"net/url"
 	"time"
 
+	"brewd/internal/logger"
+	"brewd/internal/respond"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"brewd/internal/logger"
 )
 
-// Returns a Gin middleware that logs HTTP requests and responses
-func Logger() gin.HandlerFunc {
+// RequestIDHeader carries the request ID in both directions
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds a caller-supplied request ID
+const maxRequestIDLength = 128
+
+// healthCheckRoutes are the routes load balancers and scrapers poll, which
+// Logger can leave out of the access log
+var healthCheckRoutes = map[string]bool{
+	"/health":  true,
+	"/metrics": true,
+}
+
+// Returns a Gin middleware that logs HTTP requests and responses. Each
+// request is logged with its matched route rather than its raw path, so
+// IDs and tokens in URLs stay out of the log. With skipHealthChecks,
+// successful health check and metrics requests aren't logged.
+func Logger(skipHealthChecks bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Generate unique request ID for tracing
-		requestID := uuid.New().String()
-		c.Set("request_id", requestID)
+		// Reuse the caller's request ID for tracing across services, or
+		// generate one; either way it is echoed back
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.New().String()
+		}
+		c.Set(respond.RequestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
 
 		// Start timer
 		start := time.Now()
-		path := c.Request.URL.Path
-		method := c.Request.Method
 
 		// Process request
 		c.Next()
@@ -26,35 +47,41 @@ func Logger() gin.HandlerFunc {
 		// Calculate duration
 		duration := time.Since(start)
 		statusCode := c.Writer.Status()
+		route := c.FullPath()
+		if skipHealthChecks && statusCode < 400 && healthCheckRoutes[route] {
+			return
+		}
+		if route == "" {
+			route = "unmatched"
+		}
+
+		fields := []any{
+			"request_id", requestID,
+			"method", c.Request.Method,
+			"route", route,
+			"status", statusCode,
+			"duration_ms", duration.Milliseconds(),
+			"bytes", max(c.Writer.Size(), 0),
+			"client_ip", c.ClientIP(),
+		}
+		if userID := c.GetString("user_id"); userID != "" {
+			fields = append(fields, "user_id", userID)
+		}
+		if referer := redactReferer(c.Request.Referer()); referer != "" {
+			fields = append(fields, "referer", referer)
+		}
+		if userAgent := c.Request.UserAgent(); userAgent != "" {
+			fields = append(fields, "user_agent", userAgent)
+		}
+		fields = append(fields, proxyFields(c)...)
 
 		// Determine log level based on status code
 		if statusCode >= 500 {
-			logger.Error("HTTP request",
-				"request_id", requestID,
-				"method", method,
-				"path", path,
-				"status", statusCode,
-				"duration_ms", duration.Milliseconds(),
-				"client_ip", c.ClientIP(),
-			)
+			logger.Error("HTTP request", fields...)
 		} else if statusCode >= 400 {
-			logger.Warn("HTTP request",
-				"request_id", requestID,
-				"method", method,
-				"path", path,
-				"status", statusCode,
-				"duration_ms", duration.Milliseconds(),
-				"client_ip", c.ClientIP(),
-			)
+			logger.Warn("HTTP request", fields...)
 		} else {
-			logger.Info("HTTP request",
-				"request_id", requestID,
-				"method", method,
-				"path", path,
-				"status", statusCode,
-				"duration_ms", duration.Milliseconds(),
-				"client_ip", c.ClientIP(),
-			)
+			logger.Info("HTTP request", fields...)
 		}
 
 		// Log any errors that occurred during request processing
@@ -68,3 +95,60 @@ func Logger() gin.HandlerFunc {
 		}
 	}
 }
+
+// proxyFields describes the proxies a request came through: the peer that
+// connected, and the forwarding headers it set, when any. client_ip is
+// derived from these as the trusted proxies allow.
+func proxyFields(c *gin.Context) []any {
+	forwardedFor := c.GetHeader("X-Forwarded-For")
+	realIP := c.GetHeader("X-Real-IP")
+	forwardedProto := c.GetHeader("X-Forwarded-Proto")
+	if forwardedFor == "" && realIP == "" && forwardedProto == "" {
+		return nil
+	}
+
+	fields := []any{"remote_addr", c.Request.RemoteAddr}
+	if forwardedFor != "" {
+		fields = append(fields, "forwarded_for", forwardedFor)
+	}
+	if realIP != "" {
+		fields = append(fields, "real_ip", realIP)
+	}
+	if forwardedProto != "" {
+		fields = append(fields, "forwarded_proto", forwardedProto)
+	}
+	return fields
+}
+
+// redactReferer strips the query, fragment and credentials from a Referer,
+// where pages carry tokens and personal data, leaving the page it names.
+// A Referer that isn't a URL isn't logged at all.
+func redactReferer(referer string) string {
+	if referer == "" {
+		return ""
+	}
+	u, err := url.Parse(referer)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ""
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.ForceQuery = false
+	u.Fragment = ""
+	u.RawFragment = ""
+	return u.String()
+}
+
+// validRequestID reports whether a caller-supplied request ID is safe to log
+// and echo: non-empty, bounded, and printable ASCII without spaces
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"brewd/internal/opsstats"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecordRequests counts each request and its response status in recorder,
+// for the admin dashboard's error rates
+func RecordRequests(recorder *opsstats.Recorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		recorder.Record(c.Writer.Status())
+	}
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+
+	"brewd/internal/i18n"
+	"brewd/internal/plans"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireSupporter is middleware that limits a route to users on the
+// supporter plan, rejecting others with 403 Forbidden. It must run after
+// authentication.
+func RequireSupporter(tiers *plans.Cache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if tiers.Get(c.Request.Context(), c.GetString("user_id")) != plans.Supporter {
+			respond.Error(c, http.StatusForbidden, i18n.CodeSupporterRequired)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
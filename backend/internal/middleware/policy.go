@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+
+	"brewd/internal/i18n"
+	"brewd/internal/policies"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePolicies is middleware that holds users who haven't accepted the
+// current version of every policy, rejecting their requests with 428
+// Precondition Required until they accept. It must run after
+// authentication.
+func RequirePolicies(cache *policies.Cache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if cache.Pending(c.Request.Context(), c.GetString("user_id")) {
+			respond.Error(c, http.StatusPreconditionRequired, i18n.CodePolicyAcceptanceRequired)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
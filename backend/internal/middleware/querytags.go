@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"brewd/internal/respond"
+	"brewd/pkg/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QueryTags tags the SQL sent for the request with its route pattern and
+// request ID, so slow statements can be traced back to the endpoint that
+// sent them. Must run after Logger, which assigns the request ID.
+func QueryTags() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := database.WithQueryTags(c.Request.Context(), database.QueryTags{
+			Route:     c.FullPath(),
+			RequestID: c.GetString(respond.RequestIDKey),
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
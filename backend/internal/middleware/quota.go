@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"brewd/internal/i18n"
+	"brewd/internal/plans"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Quota headers, set on every metered response. API key requests report
+// their burst credits instead of a resource.
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+	RateLimitResourceHeader  = "X-RateLimit-Resource"
+	RateLimitBurstHeader     = "X-RateLimit-Burst-Remaining"
+)
+
+// quotaWindow counts a user's uses of a resource in one window
+type quotaWindow struct {
+	start time.Time
+	reset time.Time
+	used  int
+}
+
+// quotaCounter is an in-memory fixed window counter keyed by user ID.
+// Windows are aligned to the clock, so they reset on the hour or at UTC
+// midnight.
+type quotaCounter struct {
+	mu      sync.Mutex
+	windows map[string]*quotaWindow
+	lastGC  time.Time
+}
+
+// Quota returns middleware that allows each user the uses of resource their
+// plan grants per window, rejecting requests over the limit with 429 Too
+// Many Requests. It reports the user's quota in X-RateLimit-* headers:
+// the limit, how many uses remain and when the window resets (Unix
+// seconds). It must run after authentication.
+func Quota(tiers *plans.Cache, resource string) gin.HandlerFunc {
+	qc := &quotaCounter{
+		windows: make(map[string]*quotaWindow),
+		lastGC:  time.Now(),
+	}
+
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+		plan := tiers.Get(c.Request.Context(), userID)
+		limit, window := plans.LimitsFor(plan).Rate(resource)
+
+		allowed, remaining, reset := qc.use(userID, limit, window, time.Now())
+		c.Header(RateLimitLimitHeader, strconv.Itoa(limit))
+		c.Header(RateLimitRemainingHeader, strconv.Itoa(remaining))
+		c.Header(RateLimitResetHeader, strconv.FormatInt(reset.Unix(), 10))
+		c.Header(RateLimitResourceHeader, resource)
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(time.Until(reset).Seconds())+1))
+			respond.Details(c, http.StatusTooManyRequests, i18n.CodeQuotaExceeded, map[string]string{
+				"resource": resource,
+				"plan":     plan,
+				"limit":    strconv.Itoa(limit),
+				"reset_at": reset.UTC().Format(time.RFC3339),
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// use counts a use by key against limit per window, returning whether it
+// was allowed, how many uses remain and when the window resets
+func (qc *quotaCounter) use(key string, limit int, window time.Duration, now time.Time) (bool, int, time.Time) {
+	qc.mu.Lock()
+	defer qc.mu.Unlock()
+
+	qc.collectGarbage(now)
+
+	start := now.Truncate(window)
+	w, ok := qc.windows[key]
+	if !ok || !w.start.Equal(start) {
+		w = &quotaWindow{start: start, reset: start.Add(window)}
+		qc.windows[key] = w
+	}
+
+	if w.used >= limit {
+		return false, 0, w.reset
+	}
+	w.used++
+	return true, limit - w.used, w.reset
+}
+
+// collectGarbage drops windows that have ended
+func (qc *quotaCounter) collectGarbage(now time.Time) {
+	if now.Sub(qc.lastGC) < time.Minute {
+		return
+	}
+	qc.lastGC = now
+
+	for key, w := range qc.windows {
+		if !now.Before(w.reset) {
+			delete(qc.windows, key)
+		}
+	}
+}
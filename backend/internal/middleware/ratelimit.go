@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"brewd/internal/i18n"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bucket tracks the remaining tokens for a single client
+type bucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// rateLimiter is an in-memory token bucket limiter keyed by client IP
+type rateLimiter struct {
+	mu       sync.Mutex
+	buckets  map[string]*bucket
+	capacity float64
+	refill   float64 // tokens per second
+	lastGC   time.Time
+}
+
+// RateLimit returns middleware that allows `limit` requests per `window` for each
+// client IP, rejecting requests over the limit with 429 Too Many Requests
+func RateLimit(limit int, window time.Duration) gin.HandlerFunc {
+	rl := &rateLimiter{
+		buckets:  make(map[string]*bucket),
+		capacity: float64(limit),
+		refill:   float64(limit) / window.Seconds(),
+		lastGC:   time.Now(),
+	}
+
+	return func(c *gin.Context) {
+		allowed, retryAfter := rl.allow(c.ClientIP(), time.Now())
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			respond.Error(c, http.StatusTooManyRequests, i18n.CodeRateLimited)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// allow consumes a token for key, returning how long to wait when none are left
+func (rl *rateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.collectGarbage(now)
+
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &bucket{tokens: rl.capacity, lastSeen: now}
+		rl.buckets[key] = b
+	}
+
+	// Refill tokens for the time elapsed since the last request
+	elapsed := now.Sub(b.lastSeen).Seconds()
+	b.tokens = math.Min(rl.capacity, b.tokens+elapsed*rl.refill)
+	b.lastSeen = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / rl.refill * float64(time.Second))
+		return false, wait
+	}
+
+	b.tokens--
+	return true, 0
+}
+
+// collectGarbage drops buckets that have been idle long enough to be full again
+func (rl *rateLimiter) collectGarbage(now time.Time) {
+	if now.Sub(rl.lastGC) < time.Minute {
+		return
+	}
+	rl.lastGC = now
+
+	fullAfter := time.Duration(rl.capacity / rl.refill * float64(time.Second))
+	for key, b := range rl.buckets {
+		if now.Sub(b.lastSeen) > fullAfter {
+			delete(rl.buckets, key)
+		}
+	}
+}
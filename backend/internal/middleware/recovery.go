@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"syscall"
+	"time"
+
+	"brewd/internal/errorsink"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery recovers from panics in later handlers: it logs the panic with
+// its stack and the request ID, reports it to sink, and answers 500 with
+// problem details unless a response was already started. A client that
+// hung up gets no response. It must run after Logger, which sets the
+// request ID.
+func Recovery(sink errorsink.Sink) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			// net/http's signal to abort the response quietly
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			requestID := c.GetString(respond.RequestIDKey)
+			stack := string(debug.Stack())
+			err := fmt.Sprint(rec)
+			if brokenPipe(rec) {
+				logger.Warn("Client hung up", "request_id", requestID, "path", c.Request.URL.Path, "error", err)
+				c.Abort()
+				return
+			}
+
+			logger.Error("Recovered from panic",
+				"request_id", requestID,
+				"method", c.Request.Method,
+				"route", c.FullPath(),
+				"path", c.Request.URL.Path,
+				"error", err,
+				"stack", stack,
+			)
+			sink.Report(errorsink.Report{
+				RequestID:  requestID,
+				Method:     c.Request.Method,
+				Route:      c.FullPath(),
+				Path:       c.Request.URL.Path,
+				UserID:     c.GetString("user_id"),
+				Error:      err,
+				Stack:      stack,
+				OccurredAt: time.Now().UTC(),
+			})
+
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			respond.WriteProblem(c, http.StatusInternalServerError, i18n.CodeInternal)
+			c.Abort()
+		}()
+
+		c.Next()
+	}
+}
+
+// brokenPipe reports whether rec is a write to a connection the client
+// closed, which needs no response and isn't a bug
+func brokenPipe(rec any) bool {
+	err, ok := rec.(error)
+	if !ok {
+		return false
+	}
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		return false
+	}
+	var sysErr *os.SyscallError
+	if errors.As(opErr, &sysErr) {
+		return errors.Is(sysErr.Err, syscall.EPIPE) || errors.Is(sysErr.Err, syscall.ECONNRESET)
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"brewd/internal/db"
+	"brewd/internal/logger"
+	"brewd/internal/scim"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// RequireSCIMClient is middleware that authenticates SCIM requests with a
+// client token, sent as "Authorization: Bearer <token>", answering with
+// SCIM errors. It sets scim_client_id.
+func RequireSCIMClient(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !found || token == "" {
+			abortSCIM(c, scim.NewError(http.StatusUnauthorized, "", "a client token is required"))
+			return
+		}
+
+		ctx := c.Request.Context()
+		client, err := queries.GetSCIMClientByHash(ctx, scim.Hash(token))
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				abortSCIM(c, scim.NewError(http.StatusUnauthorized, "", "invalid client token"))
+			} else {
+				logger.Error("Failed to get SCIM client", "error", err)
+				abortSCIM(c, scim.NewError(http.StatusInternalServerError, "", "internal error"))
+			}
+			return
+		}
+
+		if err := queries.TouchSCIMClient(ctx, client.ID); err != nil {
+			logger.Warn("Failed to record SCIM client use", "scim_client_id", client.ID, "error", err)
+		}
+
+		c.Set("scim_client_id", client.ID)
+		c.Next()
+	}
+}
+
+func abortSCIM(c *gin.Context, err *scim.Error) {
+	c.Header("Content-Type", scim.ContentType)
+	c.AbortWithStatusJSON(err.StatusCode(), err)
+}
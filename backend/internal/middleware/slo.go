@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"time"
+
+	"brewd/internal/slo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MeasureRequests records each request's route, status and latency in
+// recorder, for the SLO metrics
+func MeasureRequests(recorder *slo.Recorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		recorder.Observe(c.FullPath(), c.Request.Method, c.Writer.Status(), time.Since(start))
+	}
+}
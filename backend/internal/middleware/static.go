@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+
+	"brewd/internal/webapp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Static serves the web app's files in files, other than its pages:
+// precompressed as brotli or gzip when the client accepts it, and cached
+// for good when their names carry a content hash. Requests for pages and
+// for paths that aren't files go on to the next handler.
+func Static(files fs.FS) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+		name := strings.TrimPrefix(path.Clean(c.Request.URL.Path), "/")
+		if name == "" || path.Ext(name) == ".html" || !webapp.Exists(files, name) {
+			c.Next()
+			return
+		}
+
+		cacheControl := webapp.CacheAsset
+		if webapp.Hashed(name) {
+			cacheControl = webapp.CacheImmutable
+		}
+		webapp.Serve(c.Writer, c.Request, files, name, cacheControl)
+		c.Abort()
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+
+	"brewd/internal/db"
+	"brewd/internal/i18n"
+	"brewd/internal/logger"
+	"brewd/internal/respond"
+	"brewd/internal/tenant"
+	"brewd/pkg/database"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// RequireOrganization is middleware for routes under /orgs/:org_id. It
+// checks the current user belongs to the organization and threads it
+// through the request context as the tenant, and into the query tags.
+// Organizations the user doesn't belong to get 404 Not Found, so their
+// existence isn't revealed. It must run after authentication.
+func RequireOrganization(queries *db.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		orgID := c.Param("org_id")
+		role, err := queries.GetOrganizationMemberRole(ctx, db.GetOrganizationMemberRoleParams{
+			OrganizationID: orgID,
+			UserID:         c.GetString("user_id"),
+		})
+		if err == pgx.ErrNoRows {
+			respond.Error(c, http.StatusNotFound, i18n.CodeOrganizationNotFound)
+			c.Abort()
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to get organization membership", "error", err)
+			respond.Error(c, http.StatusInternalServerError, i18n.CodeOrganizationFetchFailed)
+			c.Abort()
+			return
+		}
+
+		ctx = tenant.WithTenant(ctx, tenant.Tenant{OrganizationID: orgID, Role: role})
+		tags, _ := database.QueryTagsFromContext(ctx)
+		tags.Tenant = orgID
+		ctx = database.WithQueryTags(ctx, tags)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,162 @@
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound is returned for keys with no object
+var ErrNotFound = errors.New("objectstore: object not found")
+
+// ErrInvalidKey is returned for keys that are empty, absolute or climb out
+// of the store with ".."
+var ErrInvalidKey = errors.New("objectstore: invalid key")
+
+// Store keeps objects by key, e.g. "backups/brewd-20261017T030000Z.dump".
+// Keys use "/" as a separator whatever the backend.
+type Store interface {
+	// Put stores size bytes read from body under key, replacing any object
+	// already there
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Get opens the object under key; the caller closes it
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object under key; deleting a missing key succeeds
+	Delete(ctx context.Context, key string) error
+}
+
+// New returns the store rawURL names:
+//   - file:///var/lib/brewd/objects keeps objects in a local directory
+//   - s3://bucket/prefix?region=eu-west-1 keeps them in an S3 bucket, under
+//     prefix if given. endpoint=http://minio:9000 selects another
+//     S3-compatible service, such as MinIO, addressed path-style.
+//
+// accessKey and secretKey are only used by S3.
+func New(rawURL, accessKey, secretKey string) (Store, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("objectstore: invalid URL: %w", err)
+	}
+
+	switch u.Scheme {
+	case "file":
+		if u.Path == "" {
+			return nil, fmt.Errorf("objectstore: file URL needs a directory")
+		}
+		return &FileStore{Root: u.Path}, nil
+	case "s3":
+		if u.Host == "" {
+			return nil, fmt.Errorf("objectstore: s3 URL needs a bucket")
+		}
+		if accessKey == "" || secretKey == "" {
+			return nil, fmt.Errorf("objectstore: s3 needs an access key and secret key")
+		}
+		return NewS3Store(S3Config{
+			Bucket:    u.Host,
+			Prefix:    strings.Trim(u.Path, "/"),
+			Region:    u.Query().Get("region"),
+			Endpoint:  u.Query().Get("endpoint"),
+			AccessKey: accessKey,
+			SecretKey: secretKey,
+		})
+	default:
+		return nil, fmt.Errorf("objectstore: unsupported scheme %q", u.Scheme)
+	}
+}
+
+// pingKey is the object Ping writes and removes
+const pingKey = "health/ping"
+
+// Ping checks store can be written to and deleted from, by putting and
+// removing a small object under pingKey, for health checks
+func Ping(ctx context.Context, store Store) error {
+	body := "ok"
+	if err := store.Put(ctx, pingKey, strings.NewReader(body), int64(len(body)), "text/plain"); err != nil {
+		return err
+	}
+	return store.Delete(ctx, pingKey)
+}
+
+// checkKey rejects keys that could escape the store
+func checkKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return ErrInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
+
+// FileStore keeps objects as files under Root, for development and
+// single-host deployments
+type FileStore struct {
+	Root string
+}
+
+func (s *FileStore) path(key string) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.Root, filepath.FromSlash(key)), nil
+}
+
+// Put writes the object to a temporary file and renames it into place, so
+// readers never see a partial object
+func (s *FileStore) Put(_ context.Context, key string, body io.Reader, size int64, _ string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := io.Copy(tmp, body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if n != size {
+		return fmt.Errorf("objectstore: wrote %d bytes of %d", n, size)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *FileStore) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (s *FileStore) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
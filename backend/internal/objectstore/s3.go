@@ -0,0 +1,288 @@
+package objectstore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultS3Region is used when an S3 URL names no region
+const defaultS3Region = "us-east-1"
+
+// unsignedPayload tells S3 the body isn't part of the signature, so
+// uploads stream without being hashed first. Requests still go over TLS.
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// maxPresignExpiry is the longest a presigned URL may stay valid
+const maxPresignExpiry = 7 * 24 * time.Hour
+
+// S3Config selects a bucket on S3 or an S3-compatible service
+type S3Config struct {
+	Bucket string
+	// Prefix, if set, is prepended to every key with a "/"
+	Prefix string
+	Region string
+	// Endpoint is the base URL of an S3-compatible service such as MinIO,
+	// whose buckets are addressed path-style; empty for AWS
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+}
+
+// S3Store keeps objects in an S3 bucket, signing requests with AWS
+// Signature Version 4
+type S3Store struct {
+	config S3Config
+	base   *url.URL
+	client *http.Client
+}
+
+func NewS3Store(config S3Config) (*S3Store, error) {
+	if config.Region == "" {
+		config.Region = defaultS3Region
+	}
+
+	var base *url.URL
+	var err error
+	if config.Endpoint != "" {
+		base, err = url.Parse(strings.TrimSuffix(config.Endpoint, "/") + "/" + config.Bucket)
+	} else {
+		base, err = url.Parse(fmt.Sprintf("https://%s.s3.%s.amazonaws.com", config.Bucket, config.Region))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("objectstore: invalid S3 endpoint: %w", err)
+	}
+
+	return &S3Store{config: config, base: base, client: &http.Client{}}, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	req, err := s.request(ctx, http.MethodPut, key, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.request(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	req, err := s.request(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req)
+	if err == ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// WithEndpoint returns a copy of the store addressing its bucket through
+// endpoint, path-style. Presigned URLs are signed for the host clients use,
+// so a MinIO only reachable by brewd on an internal address can presign
+// URLs for its public address.
+func (s *S3Store) WithEndpoint(endpoint string) (*S3Store, error) {
+	config := s.config
+	config.Endpoint = endpoint
+	return NewS3Store(config)
+}
+
+// PresignGet returns a URL from which the object under key can be downloaded
+// without credentials for expiry after now, which S3 caps at a week. query
+// adds response overrides such as response-content-disposition.
+func (s *S3Store) PresignGet(key string, now time.Time, expiry time.Duration, query url.Values) (string, error) {
+	if expiry <= 0 || expiry > maxPresignExpiry {
+		return "", fmt.Errorf("objectstore: presigned URLs expire within %s", maxPresignExpiry)
+	}
+	u, err := s.objectURL(key)
+	if err != nil {
+		return "", err
+	}
+
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := now.Format("20060102") + "/" + s.config.Region + "/s3/aws4_request"
+	params := url.Values{}
+	for name, values := range query {
+		params[name] = values
+	}
+	params.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	params.Set("X-Amz-Credential", s.config.AccessKey+"/"+scope)
+	params.Set("X-Amz-Date", amzDate)
+	params.Set("X-Amz-Expires", strconv.Itoa(int(expiry/time.Second)))
+	params.Set("X-Amz-SignedHeaders", "host")
+	u.RawQuery = canonicalQuery(params)
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		u.EscapedPath(),
+		u.RawQuery,
+		"host:" + u.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hexSHA256(canonicalRequest),
+	}, "\n")
+
+	signature := hex.EncodeToString(hmacSHA256(s.signingKey(now), stringToSign))
+	u.RawQuery += "&X-Amz-Signature=" + signature
+	return u.String(), nil
+}
+
+// objectURL returns the URL of the object under key
+func (s *S3Store) objectURL(key string) (*url.URL, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	if s.config.Prefix != "" {
+		key = s.config.Prefix + "/" + key
+	}
+
+	u := *s.base
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + key
+	return &u, nil
+}
+
+// request builds a request for the object under key
+func (s *S3Store) request(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	return http.NewRequestWithContext(ctx, method, u.String(), body)
+}
+
+// do signs and sends req, turning error statuses into errors. The caller
+// closes the body of a successful response.
+func (s *S3Store) do(req *http.Request) (*http.Response, error) {
+	s.sign(req, time.Now().UTC())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return nil, fmt.Errorf("objectstore: S3 %s returned %d: %s", req.Method, resp.StatusCode, detail)
+}
+
+// sign adds the Signature Version 4 Authorization header for a request
+// made at now. It signs the host and every X-Amz-* header; the payload
+// goes unsigned unless X-Amz-Content-Sha256 is already set to its hash.
+func (s *S3Store) sign(req *http.Request, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	payloadHash := req.Header.Get("X-Amz-Content-Sha256")
+	if payloadHash == "" {
+		payloadHash = unsignedPayload
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.config.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hexSHA256(canonicalRequest),
+	}, "\n")
+
+	signature := hex.EncodeToString(hmacSHA256(s.signingKey(now), stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKey, scope, signedHeaders, signature))
+}
+
+// signingKey derives the Signature Version 4 key for requests made on now's
+// date
+func (s *S3Store) signingKey(now time.Time) []byte {
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, "s3")
+	return hmacSHA256(key, "aws4_request")
+}
+
+// canonicalQuery encodes params sorted by name, escaping as Signature
+// Version 4 requires: spaces as %20 rather than +
+func canonicalQuery(params url.Values) string {
+	return strings.ReplaceAll(params.Encode(), "+", "%20")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hexSHA256(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
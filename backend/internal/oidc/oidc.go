@@ -0,0 +1,126 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"net/url"
+	"slices"
+	"strings"
+
+	"brewd/internal/db"
+)
+
+// Scopes a client can request. Every request needs openid; profile
+// releases the username and profile picture, email the email address.
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+)
+
+// Scopes lists every scope a client can request
+var Scopes = []string{
+	ScopeOpenID,
+	ScopeProfile,
+	ScopeEmail,
+}
+
+// ParseScopes returns the known scopes of a space-separated scope request,
+// in the order of Scopes; unknown ones are ignored, as the spec allows. ok
+// is false without openid.
+func ParseScopes(raw string) (scopes []string, ok bool) {
+	requested := strings.Fields(raw)
+	for _, scope := range Scopes {
+		if slices.Contains(requested, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, slices.Contains(scopes, ScopeOpenID)
+}
+
+// NewToken returns a random client secret or authorization code and the
+// hash to store
+func NewToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, Hash(token), nil
+}
+
+// Hash returns the stored form of a client secret or authorization code
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ValidChallenge reports whether challenge is a PKCE S256 code challenge:
+// a base64url SHA-256 hash
+func ValidChallenge(challenge string) bool {
+	b, err := base64.RawURLEncoding.DecodeString(challenge)
+	return err == nil && len(b) == sha256.Size
+}
+
+// VerifyChallenge reports whether verifier is the PKCE code verifier the
+// S256 challenge was made from
+func VerifyChallenge(challenge, verifier string) bool {
+	sum := sha256.Sum256([]byte(verifier))
+	return subtle.ConstantTimeCompare([]byte(base64.RawURLEncoding.EncodeToString(sum[:])), []byte(challenge)) == 1
+}
+
+// ValidRedirectURI reports whether raw can be registered as a client's
+// redirect URI: an absolute HTTPS URL without a fragment, or HTTP on
+// localhost for development
+func ValidRedirectURI(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || u.Fragment != "" || u.User != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "https":
+		return true
+	case "http":
+		host := u.Hostname()
+		return host == "localhost" || host == "127.0.0.1" || host == "::1"
+	default:
+		return false
+	}
+}
+
+// Profile is the user claims the granted scopes release, in ID tokens and
+// from the userinfo endpoint
+type Profile struct {
+	Name              string `json:"name,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Picture           string `json:"picture,omitempty"`
+	UpdatedAt         int64  `json:"updated_at,omitempty"`
+	Email             string `json:"email,omitempty"`
+}
+
+// UserInfo is the userinfo endpoint's response
+type UserInfo struct {
+	Subject string `json:"sub"`
+	Profile
+}
+
+// NewProfile returns the claims about user that scopes release. brewd
+// doesn't verify email addresses, so email_verified is never claimed.
+func NewProfile(user db.GetOIDCUserRow, scopes []string) Profile {
+	var p Profile
+	if slices.Contains(scopes, ScopeProfile) {
+		p.Name = user.Username
+		p.PreferredUsername = user.Username
+		if user.ProfilePictureUrl != nil {
+			p.Picture = *user.ProfilePictureUrl
+		}
+		p.UpdatedAt = user.UpdatedAt.Unix()
+	}
+	if slices.Contains(scopes, ScopeEmail) {
+		p.Email = user.Email
+	}
+	return p
+}
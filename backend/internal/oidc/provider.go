@@ -0,0 +1,238 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"brewd/internal/keys"
+	"brewd/internal/logger"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Lifetimes of authorization codes and tokens. There are no refresh
+// tokens: a client sends the user through authorization again, which
+// needs no interaction while they're signed in to brewd.
+const (
+	CodeTTL  = 5 * time.Minute
+	TokenTTL = time.Hour
+)
+
+// Endpoint paths under the issuer URL, where the server mounts them
+const (
+	AuthorizePath = "/oidc/authorize"
+	TokenPath     = "/oidc/token"
+	UserInfoPath  = "/oidc/userinfo"
+	KeysPath      = "/oidc/jwks"
+	DiscoveryPath = "/.well-known/openid-configuration"
+)
+
+// accessTokenType is the typ header of access tokens (RFC 9068), so an ID
+// token signed by the same key can't be passed off as one
+const accessTokenType = "at+jwt"
+
+// ErrInvalidToken is returned for access tokens that are malformed,
+// expired, or not signed by this provider
+var ErrInvalidToken = errors.New("invalid access token")
+
+// IDTokenClaims are the claims of an ID token
+type IDTokenClaims struct {
+	Profile
+	Nonce    string `json:"nonce,omitempty"`
+	AuthTime int64  `json:"auth_time"`
+	jwt.RegisteredClaims
+}
+
+// AccessClaims are the claims of an access token, which only the userinfo
+// endpoint accepts
+type AccessClaims struct {
+	Scope    string `json:"scope"`
+	ClientID string `json:"client_id"`
+	jwt.RegisteredClaims
+}
+
+// Discovery is the provider's OpenID configuration document
+type Discovery struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+// JWK is an RSA public key in JSON Web Key form
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+}
+
+// JWKS is the set of keys tokens are signed with
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// Provider signs and verifies the tokens of brewd's OIDC provider with an
+// RSA key, so clients can check ID tokens against the published public key
+type Provider struct {
+	issuer string
+	key    *rsa.PrivateKey
+	keyID  string
+}
+
+// NewProvider returns a provider identified by issuer, the API's public
+// base URL, signing with the PEM RSA private key in keyFile. Without a
+// key file a key is generated, which only suits development: tokens stop
+// verifying when the server restarts, and instances don't share it.
+func NewProvider(issuer, keyFile string) (*Provider, error) {
+	u, err := url.Parse(issuer)
+	if err != nil || u.Scheme == "" || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("issuer %q must be an absolute URL without a query or fragment", issuer)
+	}
+
+	var key *rsa.PrivateKey
+	if keyFile == "" {
+		logger.Warn("No OIDC signing key configured; generating one that lasts until restart")
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+	} else {
+		key, err = keys.ReadRSAPrivateKey(keyFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(der)
+
+	return &Provider{
+		issuer: strings.TrimRight(issuer, "/"),
+		key:    key,
+		keyID:  base64.RawURLEncoding.EncodeToString(sum[:8]),
+	}, nil
+}
+
+// Issuer returns the provider's issuer URL
+func (p *Provider) Issuer() string {
+	return p.issuer
+}
+
+// Discovery returns the provider's OpenID configuration
+func (p *Provider) Discovery() Discovery {
+	return Discovery{
+		Issuer:                            p.issuer,
+		AuthorizationEndpoint:             p.issuer + AuthorizePath,
+		TokenEndpoint:                     p.issuer + TokenPath,
+		UserInfoEndpoint:                  p.issuer + UserInfoPath,
+		JWKSURI:                           p.issuer + KeysPath,
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code"},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{jwt.SigningMethodRS256.Alg()},
+		ScopesSupported:                   Scopes,
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+		ClaimsSupported: []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce",
+			"name", "preferred_username", "picture", "updated_at", "email"},
+	}
+}
+
+// Keys returns the public key tokens are signed with
+func (p *Provider) Keys() JWKS {
+	return JWKS{Keys: []JWK{{
+		KeyType:   "RSA",
+		Use:       "sig",
+		Algorithm: jwt.SigningMethodRS256.Alg(),
+		KeyID:     p.keyID,
+		N:         base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+		E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+	}}}
+}
+
+// IDToken returns an ID token for clientID about the user who signed in
+// at authTime, carrying profile and the client's nonce
+func (p *Provider) IDToken(clientID, userID string, profile Profile, nonce string, authTime time.Time) (string, error) {
+	now := time.Now()
+	return p.sign("JWT", IDTokenClaims{
+		Profile:  profile,
+		Nonce:    nonce,
+		AuthTime: authTime.Unix(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    p.issuer,
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{clientID},
+			ExpiresAt: jwt.NewNumericDate(now.Add(TokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	})
+}
+
+// AccessToken returns an access token granting clientID the user's claims
+// under scopes at the userinfo endpoint
+func (p *Provider) AccessToken(clientID, userID string, scopes []string) (string, error) {
+	now := time.Now()
+	return p.sign(accessTokenType, AccessClaims{
+		Scope:    strings.Join(scopes, " "),
+		ClientID: clientID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    p.issuer,
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{p.issuer},
+			ExpiresAt: jwt.NewNumericDate(now.Add(TokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	})
+}
+
+func (p *Provider) sign(typ string, claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["typ"] = typ
+	token.Header["kid"] = p.keyID
+	return token.SignedString(p.key)
+}
+
+// ValidateAccessToken verifies an access token and returns its claims
+func (p *Provider) ValidateAccessToken(tokenString string) (*AccessClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &AccessClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if token.Header["typ"] != accessTokenType {
+			return nil, ErrInvalidToken
+		}
+		return &p.key.PublicKey, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithIssuer(p.issuer),
+		jwt.WithAudience(p.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if claims, ok := token.Claims.(*AccessClaims); ok && token.Valid {
+		return claims, nil
+	}
+
+	return nil, ErrInvalidToken
+}
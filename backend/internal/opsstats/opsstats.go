@@ -0,0 +1,162 @@
+package opsstats
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/health"
+	"brewd/internal/logger"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Windows the ops stats job recomputes: daily stats for the last
+// backfillDays on its first run and refreshDays after that, and the top
+// MethodLimit brew methods over methodDays
+const (
+	backfillDays = 30
+	refreshDays  = 2
+	methodDays   = 30
+	MethodLimit  = 10
+)
+
+// requestCounts is one UTC day's HTTP request tally
+type requestCounts struct {
+	requests     int64
+	clientErrors int64
+	serverErrors int64
+}
+
+// Recorder tallies this instance's HTTP requests and error responses per
+// UTC day, until the ops stats job flushes them to the database
+type Recorder struct {
+	mu     sync.Mutex
+	counts map[time.Time]*requestCounts
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{counts: make(map[time.Time]*requestCounts)}
+}
+
+// Record counts a request that was answered with status
+func (r *Recorder) Record(status int) {
+	day := time.Now().UTC().Truncate(24 * time.Hour)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts, ok := r.counts[day]
+	if !ok {
+		counts = &requestCounts{}
+		r.counts[day] = counts
+	}
+	counts.requests++
+	switch {
+	case status >= 500:
+		counts.serverErrors++
+	case status >= 400:
+		counts.clientErrors++
+	}
+}
+
+// Flush adds the tallied counts to the database and resets them. Days that
+// fail to save are kept for the next flush.
+func (r *Recorder) Flush(ctx context.Context, queries *db.Queries) {
+	r.mu.Lock()
+	pending := r.counts
+	r.counts = make(map[time.Time]*requestCounts)
+	r.mu.Unlock()
+
+	for day, counts := range pending {
+		err := queries.AddRequestCounts(ctx, db.AddRequestCountsParams{
+			Day:          pgtype.Date{Time: day, Valid: true},
+			Requests:     counts.requests,
+			ClientErrors: counts.clientErrors,
+			ServerErrors: counts.serverErrors,
+		})
+		if err == nil {
+			continue
+		}
+		logger.Error("Failed to save request counts", "day", day.Format(time.DateOnly), "error", err)
+		r.restore(day, counts)
+	}
+}
+
+// restore adds counts back to day's tally
+func (r *Recorder) restore(day time.Time, counts *requestCounts) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.counts[day]
+	if !ok {
+		r.counts[day] = counts
+		return
+	}
+	current.requests += counts.requests
+	current.clientErrors += counts.clientErrors
+	current.serverErrors += counts.serverErrors
+}
+
+// Run aggregates the admin dashboard's stats now and then every interval
+// until ctx is cancelled, flushing recorder's request counts each time
+func Run(ctx context.Context, queries *db.Queries, recorder *Recorder, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	days := backfillDays
+	for {
+		recorder.Flush(ctx, queries)
+		refresh(ctx, queries, time.Now(), days)
+		days = refreshDays
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh recomputes the daily stats of the last days days, the top brew
+// methods and the queue backlogs. A stat that fails keeps its previous
+// values until the next run.
+func refresh(ctx context.Context, queries *db.Queries, now time.Time, days int) {
+	today := now.UTC().Truncate(24 * time.Hour)
+	since := pgtype.Date{Time: today.AddDate(0, 0, 1-days), Valid: true}
+	if err := queries.RefreshDailyStats(ctx, since); err != nil {
+		logger.Error("Failed to refresh daily ops stats", "error", err)
+	}
+	if err := queries.RefreshMethodStats(ctx, db.RefreshMethodStatsParams{
+		Since:    now.AddDate(0, 0, -methodDays),
+		RowLimit: MethodLimit,
+	}); err != nil {
+		logger.Error("Failed to refresh brew method stats", "error", err)
+	}
+	if err := queries.RefreshQueueStats(ctx); err != nil {
+		logger.Error("Failed to refresh queue stats", "error", err)
+	}
+}
+
+// QueueCheck returns a health check failing when any background queue has
+// had an item due for over maxLag, as when its worker has stopped
+func QueueCheck(queries *db.Queries, maxLag time.Duration) health.CheckerFunc {
+	return func(ctx context.Context) error {
+		queues, err := queries.ListQueueLag(ctx)
+		if err != nil {
+			return err
+		}
+
+		var stalled []string
+		for _, q := range queues {
+			if q.DueSince.Valid && time.Since(q.DueSince.Time) > maxLag {
+				stalled = append(stalled, q.Queue)
+			}
+		}
+		if len(stalled) > 0 {
+			return fmt.Errorf("items overdue by over %s in %s", maxLag, strings.Join(stalled, ", "))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,124 @@
+package origins
+
+import "strings"
+
+// Term is an entry in a controlled origin vocabulary
+type Term struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Vocabulary is a list of accepted terms for one origin field
+type Vocabulary []Term
+
+// Lookup returns the term with the given ID
+func (v Vocabulary) Lookup(id string) (Term, bool) {
+	for _, term := range v {
+		if term.ID == id {
+			return term, true
+		}
+	}
+	return Term{}, false
+}
+
+// Contains reports whether id is an accepted term
+func (v Vocabulary) Contains(id string) bool {
+	_, ok := v.Lookup(id)
+	return ok
+}
+
+// Countries lists coffee-producing countries by ISO 3166-1 alpha-2 code
+var Countries = Vocabulary{
+	{"BO", "Bolivia"},
+	{"BR", "Brazil"},
+	{"BI", "Burundi"},
+	{"CM", "Cameroon"},
+	{"CN", "China"},
+	{"CO", "Colombia"},
+	{"CR", "Costa Rica"},
+	{"CU", "Cuba"},
+	{"CD", "DR Congo"},
+	{"DO", "Dominican Republic"},
+	{"EC", "Ecuador"},
+	{"SV", "El Salvador"},
+	{"ET", "Ethiopia"},
+	{"GT", "Guatemala"},
+	{"HT", "Haiti"},
+	{"HN", "Honduras"},
+	{"IN", "India"},
+	{"ID", "Indonesia"},
+	{"JM", "Jamaica"},
+	{"KE", "Kenya"},
+	{"LA", "Laos"},
+	{"MW", "Malawi"},
+	{"MX", "Mexico"},
+	{"MM", "Myanmar"},
+	{"NP", "Nepal"},
+	{"NI", "Nicaragua"},
+	{"PA", "Panama"},
+	{"PG", "Papua New Guinea"},
+	{"PE", "Peru"},
+	{"PH", "Philippines"},
+	{"RW", "Rwanda"},
+	{"TZ", "Tanzania"},
+	{"TH", "Thailand"},
+	{"TL", "Timor-Leste"},
+	{"UG", "Uganda"},
+	{"US", "United States"}, // Hawaii, Puerto Rico
+	{"VE", "Venezuela"},
+	{"VN", "Vietnam"},
+	{"YE", "Yemen"},
+	{"ZM", "Zambia"},
+	{"ZW", "Zimbabwe"},
+}
+
+// Processes lists the post-harvest processing methods accepted by the
+// process columns
+var Processes = Vocabulary{
+	{"washed", "Washed"},
+	{"natural", "Natural"},
+	{"honey", "Honey"},
+	{"semi_washed", "Semi-washed"},
+	{"wet_hulled", "Wet-hulled"},
+	{"anaerobic", "Anaerobic"},
+	{"carbonic_maceration", "Carbonic Maceration"},
+	{"other", "Other"},
+}
+
+// Varieties lists common coffee cultivars. Blends and anything unlisted use
+// "other".
+var Varieties = Vocabulary{
+	{"bourbon", "Bourbon"},
+	{"yellow_bourbon", "Yellow Bourbon"},
+	{"pink_bourbon", "Pink Bourbon"},
+	{"typica", "Typica"},
+	{"caturra", "Caturra"},
+	{"catuai", "Catuaí"},
+	{"mundo_novo", "Mundo Novo"},
+	{"gesha", "Gesha"},
+	{"pacamara", "Pacamara"},
+	{"pacas", "Pacas"},
+	{"maragogype", "Maragogype"},
+	{"villa_sarchi", "Villa Sarchi"},
+	{"sl28", "SL28"},
+	{"sl34", "SL34"},
+	{"ruiru_11", "Ruiru 11"},
+	{"batian", "Batian"},
+	{"castillo", "Castillo"},
+	{"colombia", "Colombia"},
+	{"tabi", "Tabi"},
+	{"catimor", "Catimor"},
+	{"sarchimor", "Sarchimor"},
+	{"java", "Java"},
+	{"ethiopian_landrace", "Ethiopian Landrace"},
+	{"robusta", "Robusta"},
+	{"liberica", "Liberica"},
+	{"other", "Other"},
+}
+
+// NormalizeCountry resolves a country code case-insensitively, returning
+// the stored (upper-case) form
+func NormalizeCountry(code string) (string, bool) {
+	code = strings.ToUpper(code)
+	return code, Countries.Contains(code)
+}
@@ -0,0 +1,151 @@
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/logger"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// Message kinds. Triggers write them (see db/schema/triggers.sql): a
+// notification for a user, or an automation event for a user's webhooks.
+const (
+	KindNotification = "notification"
+	KindWebhook      = "webhook"
+)
+
+// batchSize bounds how many messages a single poll claims
+const batchSize = 100
+
+// Dispatch limits: a claimed message is leased for leaseSeconds, failures
+// are retried until maxAttempts, and dispatched messages are kept for
+// retentionDays
+const (
+	leaseSeconds  = 60
+	maxAttempts   = 8
+	retentionDays = 7
+)
+
+// trimInterval is how often dispatched messages past retention are deleted
+const trimInterval = time.Hour
+
+// webhookMessage is the part of a webhook message's payload the dispatcher
+// reads; the rest is the automation payload
+type webhookMessage struct {
+	UserID string `json:"user_id"`
+	Event  string `json:"event"`
+}
+
+// Run dispatches outbox messages every interval until ctx is cancelled
+func Run(ctx context.Context, queries *db.Queries, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var trimmedAt time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := dispatchDue(ctx, queries); err != nil {
+				logger.Error("Failed to dispatch outbox messages", "error", err)
+			}
+			if time.Since(trimmedAt) >= trimInterval {
+				trim(ctx, queries)
+				trimmedAt = time.Now()
+			}
+		}
+	}
+}
+
+// dispatchDue claims due messages and dispatches each. Failures are retried
+// with backoff until maxAttempts, then the message is marked failed.
+func dispatchDue(ctx context.Context, queries *db.Queries) error {
+	for {
+		due, err := queries.ClaimOutboxMessages(ctx, db.ClaimOutboxMessagesParams{
+			LeaseSeconds: leaseSeconds,
+			RowLimit:     batchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, m := range due {
+			err := dispatch(ctx, queries, m)
+			if err == nil {
+				continue
+			}
+
+			params := db.RetryOutboxMessageParams{ID: m.ID, Error: err.Error()}
+			if m.Attempts < maxAttempts {
+				// 30 seconds, then 2, 4.5, 8, ... minutes
+				retry := m.Attempts * m.Attempts * 30
+				params.RetryInSeconds = &retry
+			}
+			logger.Warn("Outbox message dispatch failed", "message_id", m.ID, "kind", m.Kind,
+				"attempts", m.Attempts, "error", err)
+			if err := queries.RetryOutboxMessage(ctx, params); err != nil {
+				logger.Error("Failed to record outbox dispatch", "message_id", m.ID, "error", err)
+			}
+		}
+
+		if len(due) < batchSize {
+			return nil
+		}
+	}
+}
+
+// dispatch creates a notification message's notification, or queues a
+// webhook message's delivery to each of the user's webhooks subscribed to
+// its event. Either way the message is marked dispatched in the same
+// statement.
+func dispatch(ctx context.Context, queries *db.Queries, m db.ClaimOutboxMessagesRow) error {
+	switch m.Kind {
+	case KindNotification:
+		return queries.DispatchNotification(ctx, db.DispatchNotificationParams{
+			MessageID: m.ID,
+			ID:        ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String(),
+		})
+	case KindWebhook:
+		var msg webhookMessage
+		if err := json.Unmarshal(m.Payload, &msg); err != nil {
+			return err
+		}
+		webhookIDs, err := queries.ListEventAutomationWebhooks(ctx, db.ListEventAutomationWebhooksParams{
+			UserID: msg.UserID,
+			Event:  msg.Event,
+		})
+		if err != nil {
+			return err
+		}
+		deliveryIDs := make([]string, 0, len(webhookIDs))
+		for range webhookIDs {
+			deliveryIDs = append(deliveryIDs, ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String())
+		}
+		return queries.DispatchWebhook(ctx, db.DispatchWebhookParams{
+			MessageID:   m.ID,
+			DeliveryIds: deliveryIDs,
+			WebhookIds:  webhookIDs,
+		})
+	default:
+		return fmt.Errorf("unknown outbox message kind %q", m.Kind)
+	}
+}
+
+// trim deletes messages dispatched longer ago than the retention period
+func trim(ctx context.Context, queries *db.Queries) {
+	n, err := queries.DeleteDispatchedOutboxMessages(ctx, retentionDays)
+	if err != nil {
+		logger.Error("Failed to trim dispatched outbox messages", "error", err)
+		return
+	}
+	if n > 0 {
+		logger.Info("Trimmed dispatched outbox messages", "count", n)
+	}
+}
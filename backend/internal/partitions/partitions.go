@@ -0,0 +1,103 @@
+package partitions
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/lease"
+	"brewd/internal/logger"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// MonthsAhead is how many months after the current one have brew
+// partitions created ahead of time
+const MonthsAhead = 3
+
+// Run maintains the brew table's monthly partitions now and then every
+// interval until ctx is cancelled. With retentionMonths above 0, partitions
+// of brews older than that many whole months are detached.
+func Run(ctx context.Context, queries *db.Queries, interval time.Duration, retentionMonths int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		maintain(ctx, queries, time.Now(), retentionMonths)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// maintain creates the partitions of the MonthsAhead months after the
+// current one, then detaches those past retention. The current month's was
+// created ahead, or is brew_legacy's range just after partitioning; a month
+// that fails is retried on the next run.
+func maintain(ctx context.Context, queries *db.Queries, now time.Time, retentionMonths int) {
+	thisMonth := monthStart(now)
+	for i := 1; i <= MonthsAhead; i++ {
+		if err := lease.Check(ctx); err != nil {
+			logger.Warn("Stopped maintaining brew partitions", "error", err)
+			return
+		}
+		month := thisMonth.AddDate(0, i, 0)
+		created, err := queries.EnsureBrewPartition(ctx, pgtype.Date{Time: month, Valid: true})
+		if err != nil {
+			logger.Error("Failed to create brew partition", "month", month.Format("2006-01"), "error", err)
+			continue
+		}
+		if created {
+			logger.Info("Created brew partition", "month", month.Format("2006-01"))
+		}
+	}
+
+	if retentionMonths <= 0 {
+		return
+	}
+
+	cutoff := thisMonth.AddDate(0, -retentionMonths, 0)
+	partitions, err := queries.ListBrewPartitions(ctx)
+	if err != nil {
+		logger.Error("Failed to list brew partitions", "error", err)
+		return
+	}
+	for _, partition := range partitions {
+		if !partition.Month.Time.Before(cutoff) {
+			break
+		}
+		if err := lease.Check(ctx); err != nil {
+			logger.Warn("Stopped maintaining brew partitions", "error", err)
+			return
+		}
+		detached, err := queries.DetachBrewPartition(ctx, partition.Name)
+		if isForeignKeyViolation(err) {
+			logger.Warn("Brew partition past retention is still referenced", "partition", partition.Name, "error", err)
+			continue
+		}
+		if err != nil {
+			logger.Error("Failed to detach brew partition", "partition", partition.Name, "error", err)
+			continue
+		}
+		logger.Info("Detached brew partition", "partition", partition.Name, "table", detached)
+	}
+}
+
+// monthStart returns the start of t's month in UTC, which partitions are
+// aligned to
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// isForeignKeyViolation reports whether err is a Postgres foreign key
+// violation: rows elsewhere still reference the partition's brews
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
@@ -0,0 +1,148 @@
+package photos
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+// exifSegment returns an APP1 segment holding a TIFF structure in order
+// whose first IFD has an orientation tag of type typ
+func exifSegment(order binary.ByteOrder, typ uint16, orientation uint16) []byte {
+	var tiff bytes.Buffer
+	if order == binary.LittleEndian {
+		tiff.WriteString("II")
+	} else {
+		tiff.WriteString("MM")
+	}
+	_ = binary.Write(&tiff, order, uint16(42))
+	_ = binary.Write(&tiff, order, uint32(8))
+	// Two entries: the camera make, which must be skipped, then the
+	// orientation. Each is a tag, type, count and value.
+	_ = binary.Write(&tiff, order, uint16(2))
+	_ = binary.Write(&tiff, order, struct {
+		Tag, Type uint16
+		Count     uint32
+		Value     [4]byte
+	}{0x010F, 2, 4, [4]byte{'A', 'C', 'M', 0}})
+	_ = binary.Write(&tiff, order, struct {
+		Tag, Type  uint16
+		Count      uint32
+		Value, Pad uint16
+	}{0x0112, typ, 1, orientation, 0})
+	// No next IFD
+	_ = binary.Write(&tiff, order, uint32(0))
+
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(2+len(payload)))
+	return append(segment, payload...)
+}
+
+// withSegment returns the JPEG data with segment inserted after its SOI
+// marker, where cameras write EXIF
+func withSegment(data, segment []byte) []byte {
+	out := append([]byte{}, data[:2]...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+// testJPEG encodes a w by h gray JPEG
+func testJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 0x80
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	plain := testJPEG(t, 4, 2)
+	truncated := withSegment(plain, exifSegment(binary.BigEndian, 3, 6))
+	truncated = truncated[:2+10]
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"no EXIF", plain, 1},
+		{"big-endian", withSegment(plain, exifSegment(binary.BigEndian, 3, 6)), 6},
+		{"little-endian", withSegment(plain, exifSegment(binary.LittleEndian, 3, 8)), 8},
+		{"after another segment", withSegment(plain, append([]byte{0xFF, 0xE0, 0, 4, 'J', 'F'}, exifSegment(binary.BigEndian, 3, 3)...)), 3},
+		{"out of range", withSegment(plain, exifSegment(binary.BigEndian, 3, 9)), 1},
+		{"not a SHORT", withSegment(plain, exifSegment(binary.BigEndian, 4, 6)), 1},
+		{"truncated segment", truncated, 1},
+		{"not a JPEG", []byte("\x89PNG\r\n\x1a\n"), 1},
+		{"empty", nil, 1},
+	}
+	for _, tt := range tests {
+		if got := jpegOrientation(tt.data); got != tt.want {
+			t.Errorf("%s: jpegOrientation = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTIFFOrientationMalformed(t *testing.T) {
+	for _, tiff := range [][]byte{
+		nil,
+		[]byte("MM\x00\x2a"),
+		[]byte("XX\x00\x2a\x00\x00\x00\x08\x00\x00"),
+		// First IFD past the end
+		[]byte("MM\x00\x2a\x00\x00\xff\xff\x00\x00"),
+		// More entries than the data holds
+		[]byte("MM\x00\x2a\x00\x00\x00\x08\x00\x05\x01\x12\x00\x03"),
+	} {
+		if got := tiffOrientation(tiff); got != 1 {
+			t.Errorf("tiffOrientation(%q) = %d, want 1", tiff, got)
+		}
+	}
+}
+
+// TestOrient turns a 3 by 2 image with a red top-left and green top-right
+// pixel, checking where each orientation puts those pixels
+func TestOrient(t *testing.T) {
+	red := color.RGBA{R: 0xFF, A: 0xFF}
+	green := color.RGBA{G: 0xFF, A: 0xFF}
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	src.Set(0, 0, red)
+	src.Set(2, 0, green)
+
+	tests := []struct {
+		orientation int
+		size        image.Point
+		red, green  image.Point
+	}{
+		{1, image.Pt(3, 2), image.Pt(0, 0), image.Pt(2, 0)},
+		{2, image.Pt(3, 2), image.Pt(2, 0), image.Pt(0, 0)},
+		{3, image.Pt(3, 2), image.Pt(2, 1), image.Pt(0, 1)},
+		{4, image.Pt(3, 2), image.Pt(0, 1), image.Pt(2, 1)},
+		{5, image.Pt(2, 3), image.Pt(0, 0), image.Pt(0, 2)},
+		{6, image.Pt(2, 3), image.Pt(1, 0), image.Pt(1, 2)},
+		{7, image.Pt(2, 3), image.Pt(1, 2), image.Pt(1, 0)},
+		{8, image.Pt(2, 3), image.Pt(0, 2), image.Pt(0, 0)},
+		{0, image.Pt(3, 2), image.Pt(0, 0), image.Pt(2, 0)},
+		{9, image.Pt(3, 2), image.Pt(0, 0), image.Pt(2, 0)},
+	}
+	for _, tt := range tests {
+		got := orient(src, tt.orientation)
+		if size := got.Bounds().Size(); size != tt.size {
+			t.Errorf("orientation %d: size %v, want %v", tt.orientation, size, tt.size)
+			continue
+		}
+		if c := color.RGBAModel.Convert(got.At(tt.red.X, tt.red.Y)); c != red {
+			t.Errorf("orientation %d: %v is %v, want red", tt.orientation, tt.red, c)
+		}
+		if c := color.RGBAModel.Convert(got.At(tt.green.X, tt.green.Y)); c != green {
+			t.Errorf("orientation %d: %v is %v, want green", tt.orientation, tt.green, c)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package photos
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	stddraw "image/draw"
+	"image/jpeg"
+	"net/http"
+
+	// Decoders for the accepted upload types besides JPEG
+	_ "image/gif"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Content types accepted for upload, as sniffed by http.DetectContentType
+var accepted = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// Processing limits. Images are scaled down to fit within maxDimension, and
+// thumbnails within thumbnailDimension; larger sources than maxPixels are
+// refused before decoding, so a small file can't expand into gigabytes.
+const (
+	maxDimension       = 2048
+	thumbnailDimension = 400
+	maxPixels          = 50_000_000
+	jpegQuality        = 85
+)
+
+// errUnreadable wraps failures retrying can't fix, such as a corrupt or
+// oversized image
+var errUnreadable = errors.New("photos: unreadable image")
+
+// Sniff returns the content type of an upload from its first bytes, and
+// whether it is an accepted image type. The client's Content-Type isn't
+// trusted.
+func Sniff(head []byte) (string, bool) {
+	contentType := http.DetectContentType(head)
+	return contentType, accepted[contentType]
+}
+
+// rendition is an encoded JPEG and its dimensions
+type rendition struct {
+	data          []byte
+	width, height int
+}
+
+// render decodes original, turns it upright and encodes the full-size image
+// and its thumbnail as JPEG. Re-encoding drops EXIF and any other metadata
+// the original carried, such as the location it was taken at.
+func render(original []byte) (full, thumbnail rendition, err error) {
+	if contentType, ok := Sniff(original); !ok {
+		return full, thumbnail, fmt.Errorf("%w: unsupported content type %s", errUnreadable, contentType)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(original))
+	if err != nil {
+		return full, thumbnail, fmt.Errorf("%w: %v", errUnreadable, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return full, thumbnail, fmt.Errorf("%w: %dx%d is too large", errUnreadable, cfg.Width, cfg.Height)
+	}
+
+	src, format, err := image.Decode(bytes.NewReader(original))
+	if err != nil {
+		return full, thumbnail, fmt.Errorf("%w: %v", errUnreadable, err)
+	}
+	orientation := 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(original)
+	}
+
+	if full, err = encode(src, maxDimension, orientation); err != nil {
+		return full, thumbnail, err
+	}
+	thumbnail, err = encode(src, thumbnailDimension, orientation)
+	return full, thumbnail, err
+}
+
+// encode scales src down to fit within limit pixels on each side, flattens
+// any transparency onto white, turns it upright and encodes it as JPEG.
+// Scaling first keeps turning cheap.
+func encode(src image.Image, limit, orientation int) (rendition, error) {
+	b := src.Bounds()
+	w, h := fit(b.Dx(), b.Dy(), limit)
+
+	scaled := image.NewRGBA(image.Rect(0, 0, w, h))
+	stddraw.Draw(scaled, scaled.Bounds(), image.NewUniform(color.White), image.Point{}, stddraw.Src)
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), src, b, draw.Over, nil)
+	upright := orient(scaled, orientation)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, upright, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return rendition{}, err
+	}
+	ub := upright.Bounds()
+	return rendition{data: buf.Bytes(), width: ub.Dx(), height: ub.Dy()}, nil
+}
+
+// fit returns w by h scaled down to fit within limit on each side, keeping
+// the aspect ratio. Images already within it keep their size.
+func fit(w, h, limit int) (int, int) {
+	if w <= limit && h <= limit {
+		return w, h
+	}
+	if w >= h {
+		return limit, max(1, h*limit/w)
+	}
+	return max(1, w*limit/h), limit
+}
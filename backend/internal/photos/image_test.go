@@ -0,0 +1,134 @@
+package photos
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// testPNG encodes img as PNG
+func testPNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// decodeJPEG decodes a rendition, which must be a JPEG of the size it
+// records
+func decodeJPEG(t *testing.T, r rendition) image.Image {
+	t.Helper()
+	img, err := jpeg.Decode(bytes.NewReader(r.data))
+	if err != nil {
+		t.Fatalf("rendition isn't a JPEG: %v", err)
+	}
+	if size := img.Bounds().Size(); size != image.Pt(r.width, r.height) {
+		t.Fatalf("rendition is %v, records %dx%d", size, r.width, r.height)
+	}
+	return img
+}
+
+func TestRenderScalesDown(t *testing.T) {
+	original := testPNG(t, image.NewGray(image.Rect(0, 0, 3000, 1500)))
+
+	full, thumbnail, err := render(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := decodeJPEG(t, full).Bounds().Size(); size != image.Pt(2048, 1024) {
+		t.Errorf("image is %v, want 2048x1024", size)
+	}
+	if size := decodeJPEG(t, thumbnail).Bounds().Size(); size != image.Pt(400, 200) {
+		t.Errorf("thumbnail is %v, want 400x200", size)
+	}
+}
+
+// TestRenderOrientsAndStripsEXIF renders a JPEG recorded sideways and
+// checks the rendition is upright and carries no EXIF
+func TestRenderOrientsAndStripsEXIF(t *testing.T) {
+	original := withSegment(testJPEG(t, 40, 20), exifSegment(binary.BigEndian, 3, 6))
+
+	full, thumbnail, err := render(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []rendition{full, thumbnail} {
+		if size := decodeJPEG(t, r).Bounds().Size(); size != image.Pt(20, 40) {
+			t.Errorf("rendition is %v, want 20x40", size)
+		}
+		if bytes.Contains(r.data, []byte("Exif\x00\x00")) {
+			t.Error("rendition carries EXIF")
+		}
+	}
+}
+
+func TestRenderFlattensTransparency(t *testing.T) {
+	original := testPNG(t, image.NewNRGBA(image.Rect(0, 0, 8, 8)))
+
+	full, _, err := render(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, g, b, _ := decodeJPEG(t, full).At(4, 4).RGBA()
+	if r>>8 < 0xF0 || g>>8 < 0xF0 || b>>8 < 0xF0 {
+		t.Errorf("transparent pixel is %v, want white", color.RGBA64{R: uint16(r), G: uint16(g), B: uint16(b)})
+	}
+}
+
+// oversizedPNG returns the start of a PNG whose header claims w by h
+// pixels, which is all DecodeConfig reads
+func oversizedPNG(w, h uint32) []byte {
+	header := make([]byte, 13)
+	binary.BigEndian.PutUint32(header[0:], w)
+	binary.BigEndian.PutUint32(header[4:], h)
+	header[8], header[9] = 8, 2 // 8-bit RGB
+
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(header)))
+	chunk = append(chunk, "IHDR"...)
+	chunk = append(chunk, header...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+	return append([]byte("\x89PNG\r\n\x1a\n"), chunk...)
+}
+
+func TestRenderRejectsUnreadable(t *testing.T) {
+	png := testPNG(t, image.NewGray(image.Rect(0, 0, 4, 4)))
+	tests := []struct {
+		name     string
+		original []byte
+	}{
+		{"not an image", []byte("just some text")},
+		{"truncated", png[:len(png)/2]},
+		{"too many pixels", oversizedPNG(10000, 10000)},
+	}
+	for _, tt := range tests {
+		if _, _, err := render(tt.original); !errors.Is(err, errUnreadable) {
+			t.Errorf("%s: render = %v, want errUnreadable", tt.name, err)
+		}
+	}
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		w, h, limit int
+		want        image.Point
+	}{
+		{100, 50, 400, image.Pt(100, 50)},
+		{400, 400, 400, image.Pt(400, 400)},
+		{800, 400, 400, image.Pt(400, 200)},
+		{400, 800, 400, image.Pt(200, 400)},
+		{10000, 1, 400, image.Pt(400, 1)},
+	}
+	for _, tt := range tests {
+		if w, h := fit(tt.w, tt.h, tt.limit); image.Pt(w, h) != tt.want {
+			t.Errorf("fit(%d, %d, %d) = %dx%d, want %v", tt.w, tt.h, tt.limit, w, h, tt.want)
+		}
+	}
+}
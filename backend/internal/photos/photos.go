@@ -0,0 +1,353 @@
+// Package photos processes brew photos after upload. Uploads are stored as
+// they arrive and queued in brew_photo; the processor claims them,
+// optionally scans them for malware, renders an upright, metadata-free JPEG
+// and thumbnail of each, optionally screens it, and marks it ready,
+// rejected, quarantined or failed. Only processed renditions are ever
+// served. Deleted photos' objects are swept from the store by the same
+// processor.
+package photos
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/logger"
+	"brewd/internal/mail"
+	"brewd/internal/malware"
+	"brewd/internal/objectstore"
+)
+
+// Photo statuses
+const (
+	StatusPending    = "pending"
+	StatusProcessing = "processing"
+	StatusReady      = "ready"
+	StatusFailed     = "failed"
+	StatusRejected   = "rejected"
+	// Malware was found in the upload; its original is kept under
+	// QuarantineKey for admins to review
+	StatusQuarantined = "quarantined"
+)
+
+// batchSize bounds how many photos a single poll claims
+const batchSize = 10
+
+// Processing limits: a claimed photo is leased for leaseSeconds, and
+// failures are retried until maxAttempts
+const (
+	leaseSeconds = 300
+	maxAttempts  = 5
+)
+
+// OriginalKey is where a photo's upload is stored, as received
+func OriginalKey(id string) string {
+	return "photos/" + id + "/original"
+}
+
+// QuarantineKey is where a quarantined photo's upload is moved, apart from
+// other photos so it can be locked down or purged on its own
+func QuarantineKey(id string) string {
+	return "quarantine/photos/" + id + "/original"
+}
+
+// ImageKey is where a photo's full-size rendition is stored
+func ImageKey(id string) string {
+	return "photos/" + id + "/image.jpg"
+}
+
+// ThumbnailKey is where a photo's thumbnail is stored
+func ThumbnailKey(id string) string {
+	return "photos/" + id + "/thumbnail.jpg"
+}
+
+// Processor works through the photo queue
+type Processor struct {
+	queries   *db.Queries
+	store     objectstore.Store
+	mailer    *mail.Mailer
+	adminIDs  []string
+	scanner   malware.Scanner
+	screener  Screener
+	threshold float64
+}
+
+// NewProcessor creates a processor reading and writing photos in store.
+// With a scanner, uploads it finds malware in are quarantined and the
+// admins emailed. With a screener, photos scoring threshold or more are
+// rejected.
+func NewProcessor(queries *db.Queries, store objectstore.Store, mailer *mail.Mailer, adminIDs []string,
+	scanner malware.Scanner, screener Screener, threshold float64) *Processor {
+	return &Processor{
+		queries:   queries,
+		store:     store,
+		mailer:    mailer,
+		adminIDs:  adminIDs,
+		scanner:   scanner,
+		screener:  screener,
+		threshold: threshold,
+	}
+}
+
+// Run processes queued photos, and sweeps deleted photos' objects from the
+// store, every interval until ctx is cancelled
+func (p *Processor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.processDue(ctx); err != nil {
+				logger.Error("Failed to process brew photos", "error", err)
+			}
+			if err := p.sweep(ctx); err != nil {
+				logger.Error("Failed to sweep deleted brew photos", "error", err)
+			}
+		}
+	}
+}
+
+// processDue claims due photos and processes each. Failures are retried
+// with backoff until maxAttempts, then the photo is marked failed; images
+// that can't be read fail at once.
+func (p *Processor) processDue(ctx context.Context) error {
+	for {
+		due, err := p.queries.ClaimBrewPhotos(ctx, db.ClaimBrewPhotosParams{
+			LeaseSeconds: leaseSeconds,
+			RowLimit:     batchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, photo := range due {
+			err := p.process(ctx, photo)
+			if err == nil {
+				continue
+			}
+			if ctx.Err() != nil {
+				// Shutting down; the lease runs out and another instance
+				// picks the photo up
+				return nil
+			}
+
+			params := db.RetryBrewPhotoParams{ID: photo.ID, Error: err.Error()}
+			if photo.Attempts < maxAttempts && !errors.Is(err, errUnreadable) {
+				// 30 seconds, then 2, 4.5, 8 minutes
+				retry := photo.Attempts * photo.Attempts * 30
+				params.RetryInSeconds = &retry
+			}
+			logger.Warn("Brew photo processing failed", "photo_id", photo.ID, "attempts", photo.Attempts, "error", err)
+			if err := p.queries.RetryBrewPhoto(ctx, params); err != nil {
+				logger.Error("Failed to record brew photo processing", "photo_id", photo.ID, "error", err)
+			}
+		}
+
+		if len(due) < batchSize {
+			return nil
+		}
+	}
+}
+
+// sweep deletes the objects of deleted photos from the store, as recorded
+// by the record_deleted_brew_photo_objects trigger. Keys whose delete fails
+// are left leased, and swept again once the lease runs out.
+func (p *Processor) sweep(ctx context.Context) error {
+	for {
+		due, err := p.queries.ClaimDeletedPhotoObjects(ctx, db.ClaimDeletedPhotoObjectsParams{
+			LeaseSeconds: leaseSeconds,
+			RowLimit:     batchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, object := range due {
+			if err := p.store.Delete(ctx, object.Key); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				logger.Warn("Failed to delete brew photo object", "photo_id", object.PhotoID, "key", object.Key, "attempts", object.Attempts, "error", err)
+				continue
+			}
+			if err := p.queries.ForgetDeletedPhotoObject(ctx, object.Key); err != nil {
+				return err
+			}
+		}
+
+		if len(due) < batchSize {
+			return nil
+		}
+	}
+}
+
+// process scans a photo's original, renders it, screens the rendition and
+// stores the renditions. Rejected and quarantined photos keep only their
+// original, for review.
+func (p *Processor) process(ctx context.Context, photo db.BrewPhoto) error {
+	original, err := p.read(ctx, photo.OriginalKey)
+	if err != nil {
+		return err
+	}
+	if p.scanner != nil {
+		// Scanned before decoding, so the decoders never see malware
+		threat, err := p.scanner.Scan(ctx, bytes.NewReader(original))
+		if err != nil {
+			return fmt.Errorf("scanning upload: %w", err)
+		}
+		if threat != "" {
+			return p.quarantine(ctx, photo, original, threat)
+		}
+	}
+	full, thumbnail, err := render(original)
+	if err != nil {
+		return err
+	}
+
+	var score *float64
+	if p.screener != nil {
+		s, err := p.screener.Screen(ctx, full.data)
+		if err != nil {
+			return err
+		}
+		score = &s
+		if s >= p.threshold {
+			logger.Info("Rejected brew photo", "photo_id", photo.ID, "score", s)
+			_, err := p.queries.FinishBrewPhoto(ctx, db.FinishBrewPhotoParams{
+				ID:             photo.ID,
+				Status:         StatusRejected,
+				ScreeningScore: score,
+			})
+			return err
+		}
+	}
+
+	imageKey, thumbnailKey := ImageKey(photo.ID), ThumbnailKey(photo.ID)
+	if err := p.write(ctx, imageKey, full.data); err != nil {
+		return err
+	}
+	if err := p.write(ctx, thumbnailKey, thumbnail.data); err != nil {
+		return err
+	}
+
+	width, height := int32(full.width), int32(full.height)
+	imageBytes, thumbnailBytes := int64(len(full.data)), int64(len(thumbnail.data))
+	n, err := p.queries.FinishBrewPhoto(ctx, db.FinishBrewPhotoParams{
+		ID:             photo.ID,
+		Status:         StatusReady,
+		ImageKey:       &imageKey,
+		ThumbnailKey:   &thumbnailKey,
+		Width:          &width,
+		Height:         &height,
+		ImageBytes:     &imageBytes,
+		ThumbnailBytes: &thumbnailBytes,
+		ScreeningScore: score,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		// Deleted while it was processed; its renditions would be orphaned
+		Remove(ctx, p.store, db.BrewPhoto{ID: photo.ID, OriginalKey: photo.OriginalKey, ImageKey: &imageKey, ThumbnailKey: &thumbnailKey})
+		return nil
+	}
+	logger.Info("Processed brew photo", "photo_id", photo.ID, "width", width, "height", height)
+	return nil
+}
+
+// quarantine moves an infected photo's original under QuarantineKey, marks
+// the photo quarantined and emails the admins
+func (p *Processor) quarantine(ctx context.Context, photo db.BrewPhoto, original []byte, threat string) error {
+	key := QuarantineKey(photo.ID)
+	if err := p.store.Put(ctx, key, bytes.NewReader(original), int64(len(original)), photo.ContentType); err != nil {
+		return err
+	}
+	n, err := p.queries.QuarantineBrewPhoto(ctx, db.QuarantineBrewPhotoParams{
+		ID:          photo.ID,
+		OriginalKey: key,
+		Threat:      &threat,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		// Deleted while it was scanned
+		Remove(ctx, p.store, db.BrewPhoto{ID: photo.ID, OriginalKey: key})
+		return nil
+	}
+	if err := p.store.Delete(ctx, photo.OriginalKey); err != nil {
+		logger.Warn("Failed to delete quarantined brew photo upload", "photo_id", photo.ID, "key", photo.OriginalKey, "error", err)
+	}
+
+	logger.Warn("Quarantined brew photo", "photo_id", photo.ID, "user_id", photo.UserID, "threat", threat)
+	p.alert(ctx, photo, threat)
+	return nil
+}
+
+// alert emails the admins about a quarantined photo
+func (p *Processor) alert(ctx context.Context, photo db.BrewPhoto, threat string) {
+	var b strings.Builder
+	b.WriteString("The upload scanner found malware in a brew photo, which has been quarantined.\n\n")
+	fmt.Fprintf(&b, "Photo: %s\n", photo.ID)
+	fmt.Fprintf(&b, "Brew: %s\n", photo.BrewID)
+	fmt.Fprintf(&b, "Uploaded by: %s\n", photo.UserID)
+	fmt.Fprintf(&b, "Uploaded: %s\n", photo.CreatedAt.UTC().Format("2 Jan 2006 15:04 MST"))
+	fmt.Fprintf(&b, "Threat: %s\n", threat)
+	fmt.Fprintf(&b, "Kept as: %s\n", QuarantineKey(photo.ID))
+	b.WriteString("\nQuarantined photos are at GET /api/v1/admin/photos/quarantined.\n")
+
+	for _, id := range p.adminIDs {
+		admin, err := p.queries.GetUserByID(ctx, id)
+		if err != nil {
+			logger.Warn("Failed to look up admin for quarantine alert", "user_id", id, "error", err)
+			continue
+		}
+		err = p.mailer.Send(ctx, mail.Message{
+			To:      admin.Email,
+			Subject: "brewd quarantined an upload",
+			Text:    b.String(),
+		})
+		if err != nil && !errors.Is(err, mail.ErrSuppressed) {
+			logger.Warn("Failed to send quarantine alert", "user_id", id, "error", err)
+		}
+	}
+}
+
+// Remove deletes a photo's objects from store, logging failures. The
+// processor uses it for renditions written after their photo was deleted,
+// which the sweep missed; deleted rows' own keys are left to the sweep.
+func Remove(ctx context.Context, store objectstore.Store, photo db.BrewPhoto) {
+	keys := []string{photo.OriginalKey}
+	if photo.ImageKey != nil {
+		keys = append(keys, *photo.ImageKey)
+	}
+	if photo.ThumbnailKey != nil {
+		keys = append(keys, *photo.ThumbnailKey)
+	}
+	for _, key := range keys {
+		if err := store.Delete(ctx, key); err != nil {
+			logger.Warn("Failed to delete brew photo object", "photo_id", photo.ID, "key", key, "error", err)
+		}
+	}
+}
+
+func (p *Processor) read(ctx context.Context, key string) ([]byte, error) {
+	body, err := p.store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+func (p *Processor) write(ctx context.Context, key string, data []byte) error {
+	return p.store.Put(ctx, key, bytes.NewReader(data), int64(len(data)), "image/jpeg")
+}
@@ -0,0 +1,93 @@
+package photos
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Screener scores how likely a processed photo is to be unsafe for work, from
+// 0 (safe) to 1. Photos scoring at or above the configured threshold are
+// rejected. Implementations for moderation services plug in here.
+type Screener interface {
+	Screen(ctx context.Context, image []byte) (float64, error)
+}
+
+// Screener names accepted by NewScreener
+const (
+	ScreenerWebhook = "webhook"
+	ScreenerNone    = "none"
+)
+
+// NewScreener returns the screener called name, or nil for none. The
+// webhook screener posts photos to target.
+func NewScreener(name, target string) (Screener, error) {
+	switch name {
+	case ScreenerWebhook:
+		u, err := url.Parse(target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("webhook photo screener needs an http(s) URL, got %q", target)
+		}
+		return NewWebhookScreener(u.String()), nil
+	case ScreenerNone:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown photo screener %q", name)
+	}
+}
+
+// screenTimeout bounds a single screening request
+const screenTimeout = 30 * time.Second
+
+// WebhookScreener posts each photo as image/jpeg to a URL, which answers
+// with a JSON object such as {"score": 0.02}
+type WebhookScreener struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookScreener returns a screener posting photos to target
+func NewWebhookScreener(target string) *WebhookScreener {
+	return &WebhookScreener{
+		url:    target,
+		client: &http.Client{Timeout: screenTimeout},
+	}
+}
+
+// screenResult is the webhook's answer
+type screenResult struct {
+	Score *float64 `json:"score"`
+}
+
+func (s *WebhookScreener) Screen(ctx context.Context, image []byte) (float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(image))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "image/jpeg")
+	req.Header.Set("User-Agent", "brewd-photos/1")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("photo screener answered %s", resp.Status)
+	}
+
+	var result screenResult
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&result); err != nil {
+		return 0, fmt.Errorf("photo screener answered invalid JSON: %w", err)
+	}
+	if result.Score == nil || *result.Score < 0 || *result.Score > 1 {
+		return 0, errors.New("photo screener answered no score in [0, 1]")
+	}
+	return *result.Score, nil
+}
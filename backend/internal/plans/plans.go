@@ -0,0 +1,130 @@
+package plans
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/logger"
+)
+
+// Plan tiers a user can be on
+const (
+	Free      = "free"
+	Supporter = "supporter"
+)
+
+// Metered resources, each allowed a number of uses per window
+const (
+	ResourceAPICalls = "api_calls"
+)
+
+// Limits are a plan's quotas
+type Limits struct {
+	PhotoStorageBytes int64 `json:"photo_storage_bytes"`
+	APICallsPerHour   int   `json:"api_calls_per_hour"`
+}
+
+// Tiers are each plan's limits
+var Tiers = map[string]Limits{
+	Free: {
+		PhotoStorageBytes: 500 << 20,
+		APICallsPerHour:   1000,
+	},
+	Supporter: {
+		PhotoStorageBytes: 10 << 30,
+		APICallsPerHour:   5000,
+	},
+}
+
+// LimitsFor returns plan's limits, or the free plan's for an unknown plan
+func LimitsFor(plan string) Limits {
+	if limits, ok := Tiers[plan]; ok {
+		return limits
+	}
+	return Tiers[Free]
+}
+
+// Rate returns how many uses of resource the limits allow per window
+func (l Limits) Rate(resource string) (limit int, window time.Duration) {
+	switch resource {
+	case ResourceAPICalls:
+		return l.APICallsPerHour, time.Hour
+	default:
+		return 0, 0
+	}
+}
+
+// cacheTTL is how long a user's plan is cached, and so how long a plan
+// change can take to reach another instance's quotas
+const cacheTTL = time.Minute
+
+type cachedPlan struct {
+	plan      string
+	expiresAt time.Time
+}
+
+// Cache looks up users' plans, caching each for cacheTTL so quotas don't
+// cost a query per request
+type Cache struct {
+	queries *db.Queries
+
+	mu     sync.Mutex
+	plans  map[string]cachedPlan
+	lastGC time.Time
+}
+
+func NewCache(queries *db.Queries) *Cache {
+	return &Cache{
+		queries: queries,
+		plans:   make(map[string]cachedPlan),
+		lastGC:  time.Now(),
+	}
+}
+
+// Get returns userID's plan. If it can't be looked up the user gets the
+// free plan until the next lookup, rather than failing their request.
+func (c *Cache) Get(ctx context.Context, userID string) string {
+	now := time.Now()
+
+	c.mu.Lock()
+	cached, ok := c.plans[userID]
+	c.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.plan
+	}
+
+	plan, err := c.queries.GetUserPlan(ctx, userID)
+	if err != nil {
+		logger.Warn("Failed to get user plan", "user_id", userID, "error", err)
+		return Free
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.collectGarbage(now)
+	c.plans[userID] = cachedPlan{plan: plan, expiresAt: now.Add(cacheTTL)}
+	return plan
+}
+
+// Forget drops userID's cached plan, for when it changes
+func (c *Cache) Forget(userID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.plans, userID)
+}
+
+// collectGarbage drops expired plans
+func (c *Cache) collectGarbage(now time.Time) {
+	if now.Sub(c.lastGC) < time.Minute {
+		return
+	}
+	c.lastGC = now
+
+	for userID, cached := range c.plans {
+		if now.After(cached.expiresAt) {
+			delete(c.plans, userID)
+		}
+	}
+}
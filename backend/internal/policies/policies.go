@@ -0,0 +1,145 @@
+package policies
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/logger"
+)
+
+// Kinds of policy document users accept
+const (
+	Terms   = "terms"
+	Privacy = "privacy"
+)
+
+// Kinds lists every kind of policy document
+var Kinds = []string{Terms, Privacy}
+
+// Pending returns the IDs of the current documents in docs the user hasn't
+// accepted
+func Pending(docs []db.ListUserPolicyDocumentsRow) []string {
+	pending := []string{}
+	for _, doc := range docs {
+		if !doc.AcceptedAt.Valid {
+			pending = append(pending, doc.ID)
+		}
+	}
+	return pending
+}
+
+// Missing returns the IDs of the current documents not among accepted, the
+// IDs a user accepted
+func Missing(current []db.PolicyDocument, accepted []string) []string {
+	ids := make(map[string]bool, len(accepted))
+	for _, id := range accepted {
+		ids[id] = true
+	}
+	missing := []string{}
+	for _, doc := range current {
+		if !ids[doc.ID] {
+			missing = append(missing, doc.ID)
+		}
+	}
+	return missing
+}
+
+// Outdated reports whether any of accepted isn't a current document, as
+// when a user accepts a version that was replaced after they read it
+func Outdated(current []db.PolicyDocument, accepted []string) bool {
+	ids := make(map[string]bool, len(current))
+	for _, doc := range current {
+		ids[doc.ID] = true
+	}
+	for _, id := range accepted {
+		if !ids[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheTTL is how long a user's pending documents are cached, and so how
+// long a version published on another instance, or coming into effect at
+// its scheduled time, can take to be enforced
+const cacheTTL = time.Minute
+
+type cachedPending struct {
+	pending   bool
+	expiresAt time.Time
+}
+
+// Cache looks up whether users have policies to accept, caching each
+// answer for cacheTTL so enforcing them doesn't cost a query per request
+type Cache struct {
+	queries *db.Queries
+
+	mu     sync.Mutex
+	users  map[string]cachedPending
+	lastGC time.Time
+}
+
+func NewCache(queries *db.Queries) *Cache {
+	return &Cache{
+		queries: queries,
+		users:   make(map[string]cachedPending),
+		lastGC:  time.Now(),
+	}
+}
+
+// Pending reports whether userID has a current document to accept. If it
+// can't be looked up the user isn't held up, rather than failing their
+// request.
+func (c *Cache) Pending(ctx context.Context, userID string) bool {
+	now := time.Now()
+
+	c.mu.Lock()
+	cached, ok := c.users[userID]
+	c.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.pending
+	}
+
+	docs, err := c.queries.ListUserPolicyDocuments(ctx, userID)
+	if err != nil {
+		logger.Warn("Failed to get user policy documents", "user_id", userID, "error", err)
+		return false
+	}
+	pending := len(Pending(docs)) > 0
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.collectGarbage(now)
+	c.users[userID] = cachedPending{pending: pending, expiresAt: now.Add(cacheTTL)}
+	return pending
+}
+
+// Forget drops userID's cached answer, for when they accept a document
+func (c *Cache) Forget(userID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.users, userID)
+}
+
+// Reset drops every cached answer, for when a version is published
+func (c *Cache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.users)
+}
+
+// collectGarbage drops expired answers
+func (c *Cache) collectGarbage(now time.Time) {
+	if now.Sub(c.lastGC) < time.Minute {
+		return
+	}
+	c.lastGC = now
+
+	for userID, cached := range c.users {
+		if now.After(cached.expiresAt) {
+			delete(c.users, userID)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package pours
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// MaxDurationMs is the latest sample time accepted, one hour into a pour
+const MaxDurationMs = 60 * 60 * 1000
+
+// Limits for a sample; slightly negative weights happen after taring
+const (
+	minGrams    = -100
+	maxGrams    = 10000
+	maxPressure = 20
+)
+
+// maxLineBytes caps a single sample line
+const maxLineBytes = 1024
+
+var ErrInvalidSample = errors.New("invalid pour sample")
+
+// Sample is the weight on a smart scale TMs milliseconds into a pour, and
+// for espresso the machine's pressure if its bridge reports one
+type Sample struct {
+	TMs         int32    `json:"t_ms"`
+	Grams       float64  `json:"grams"`
+	PressureBar *float64 `json:"pressure_bar"`
+}
+
+// Reader reads samples streamed as newline-delimited JSON, one
+// {"t_ms": 1200, "grams": 12.4} object per line, optionally with
+// "pressure_bar". Other fields are ignored, so bridges can pass through
+// whatever their device reports.
+type Reader struct {
+	r    *bufio.Reader
+	line int
+}
+
+// NewReader reads samples from r
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReaderSize(r, maxLineBytes)}
+}
+
+// Read returns the next sample, skipping blank lines, or io.EOF at the end
+// of the stream. Malformed or out of range samples return an error wrapping
+// ErrInvalidSample.
+func (r *Reader) Read() (Sample, error) {
+	for {
+		raw, err := r.r.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			r.line++
+			return Sample{}, fmt.Errorf("%w: line %d is too long", ErrInvalidSample, r.line)
+		}
+		if err != nil && err != io.EOF {
+			return Sample{}, err
+		}
+		if len(raw) > 0 {
+			r.line++
+		}
+
+		raw = bytes.TrimSpace(raw)
+		if len(raw) == 0 {
+			if err == io.EOF {
+				return Sample{}, io.EOF
+			}
+			continue
+		}
+
+		var in struct {
+			TMs         *int32   `json:"t_ms"`
+			Grams       *float64 `json:"grams"`
+			PressureBar *float64 `json:"pressure_bar"`
+		}
+		if json.Unmarshal(raw, &in) != nil || in.TMs == nil || in.Grams == nil {
+			return Sample{}, fmt.Errorf("%w: line %d", ErrInvalidSample, r.line)
+		}
+		if *in.TMs < 0 || *in.TMs > MaxDurationMs || *in.Grams < minGrams || *in.Grams > maxGrams ||
+			(in.PressureBar != nil && (*in.PressureBar < 0 || *in.PressureBar > maxPressure)) {
+			return Sample{}, fmt.Errorf("%w: line %d is out of range", ErrInvalidSample, r.line)
+		}
+		return Sample{TMs: *in.TMs, Grams: *in.Grams, PressureBar: in.PressureBar}, nil
+	}
+}
+
+// Buffered reports whether another line has already been received, so
+// callers can tell when the sender has paused
+func (r *Reader) Buffered() bool {
+	return r.r.Buffered() > 0
+}
+
+// Line returns the number of the last line read, counting from 1
+func (r *Reader) Line() int {
+	return r.line
+}
+
+// Merge combines two time-ordered sample lists into one, keeping a's
+// sample where both have one at the same time
+func Merge(a, b []Sample) []Sample {
+	merged := make([]Sample, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case j == len(b) || (i < len(a) && a[i].TMs < b[j].TMs):
+			merged = append(merged, a[i])
+			i++
+		case i == len(a) || b[j].TMs < a[i].TMs:
+			merged = append(merged, b[j])
+			j++
+		default:
+			merged = append(merged, a[i])
+			i++
+			j++
+		}
+	}
+	return merged
+}
+
+// Downsample reduces time-ordered samples to at most one per resolutionMs
+// window, for charting. Weight is cumulative, so each window keeps the time
+// and weight of its last sample; pressure is averaged over the window's
+// samples that have one.
+func Downsample(samples []Sample, resolutionMs int32) []Sample {
+	if resolutionMs <= 1 || len(samples) == 0 {
+		return samples
+	}
+
+	var out []Sample
+	var pressureSum float64
+	var pressureCount int
+	for i, sample := range samples {
+		if sample.PressureBar != nil {
+			pressureSum += *sample.PressureBar
+			pressureCount++
+		}
+		if i+1 < len(samples) && samples[i+1].TMs/resolutionMs == sample.TMs/resolutionMs {
+			continue
+		}
+
+		point := Sample{TMs: sample.TMs, Grams: sample.Grams}
+		if pressureCount > 0 {
+			mean := math.Round(pressureSum/float64(pressureCount)*100) / 100
+			point.PressureBar = &mean
+		}
+		out = append(out, point)
+		pressureSum, pressureCount = 0, 0
+	}
+	return out
+}
+
+// Flow returns the flow rate into each sample from the previous one, in
+// grams per second, rounded to 0.01 g/s. The first sample's flow is 0.
+func Flow(samples []Sample) []float64 {
+	flow := make([]float64, len(samples))
+	for i := 1; i < len(samples); i++ {
+		dt := float64(samples[i].TMs-samples[i-1].TMs) / 1000
+		if dt <= 0 {
+			continue
+		}
+		flow[i] = math.Round((samples[i].Grams-samples[i-1].Grams)/dt*100) / 100
+	}
+	return flow
+}
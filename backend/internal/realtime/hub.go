@@ -0,0 +1,123 @@
+package realtime
+
+import (
+	"sync"
+
+	"brewd/internal/logger"
+)
+
+// subscriberBuffer is how many messages a subscriber may fall behind before
+// it is dropped
+const subscriberBuffer = 16
+
+// Message is an event published to a topic, e.g. a brew event's timer
+// starting. Data is marshalled to JSON for clients.
+type Message struct {
+	Event string
+	Data  any
+}
+
+// Hub fans messages out to the subscribers of each topic within this
+// process, and through its relay, if any, to the hubs of other instances.
+// Publishing never blocks: a subscriber that falls too far behind has its
+// channel closed, so its client reconnects and resyncs.
+type Hub struct {
+	mu     sync.Mutex
+	topics map[string]map[chan Message]struct{}
+	relay  *Relay
+}
+
+// NewHub creates an empty hub
+func NewHub() *Hub {
+	return &Hub{topics: make(map[string]map[chan Message]struct{})}
+}
+
+// Subscribe registers for topic's messages. The returned function
+// unsubscribes and must be called once the subscriber is done.
+func (h *Hub) Subscribe(topic string) (<-chan Message, func()) {
+	ch := make(chan Message, subscriberBuffer)
+
+	h.mu.Lock()
+	if h.topics[topic] == nil {
+		h.topics[topic] = make(map[chan Message]struct{})
+	}
+	h.topics[topic][ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.remove(topic, ch)
+	}
+}
+
+// SetRelay relays the hub's messages to other instances. It must be called
+// before the hub is used.
+func (h *Hub) SetRelay(relay *Relay) {
+	h.relay = relay
+}
+
+// Publish sends msg to topic's current subscribers on every instance. With
+// a relay, a message too large to relay is rejected rather than reaching
+// only this instance's subscribers.
+func (h *Hub) Publish(topic string, msg Message) {
+	if h.relay == nil {
+		h.deliver(topic, msg)
+		return
+	}
+	p, err := h.relay.encode(topic, msg)
+	if err != nil {
+		logger.Error("Rejected realtime message", "topic", topic, "event", msg.Event, "error", err)
+		return
+	}
+	h.deliver(topic, msg)
+	h.relay.send(p)
+}
+
+// deliver sends msg to topic's subscribers in this process
+func (h *Hub) deliver(topic string, msg Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.topics[topic] {
+		select {
+		case ch <- msg:
+		default:
+			h.remove(topic, ch)
+		}
+	}
+}
+
+// Subscribers returns how many subscribers topic has
+func (h *Hub) Subscribers(topic string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.topics[topic])
+}
+
+// dropAll closes every subscriber's channel, so clients reconnect and
+// resync after messages may have been missed
+func (h *Hub) dropAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for topic, subs := range h.topics {
+		for ch := range subs {
+			h.remove(topic, ch)
+		}
+	}
+}
+
+// remove closes and forgets a subscriber; h.mu must be held. Removing one
+// that was already dropped is a no-op.
+func (h *Hub) remove(topic string, ch chan Message) {
+	subs := h.topics[topic]
+	if _, ok := subs[ch]; !ok {
+		return
+	}
+	delete(subs, ch)
+	close(ch)
+	if len(subs) == 0 {
+		delete(h.topics, topic)
+	}
+}
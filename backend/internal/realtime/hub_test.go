@@ -0,0 +1,40 @@
+package realtime
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPublishRejectsUnrelayable(t *testing.T) {
+	hub := NewHub()
+	relay := NewRelay(hub, nil, "", "instance-a")
+	hub.SetRelay(relay)
+	messages, unsubscribe := hub.Subscribe("brew-event:1")
+	defer unsubscribe()
+
+	hub.Publish("brew-event:1", Message{Event: "timer", Data: map[string]string{"state": "running"}})
+	select {
+	case msg := <-messages:
+		if msg.Event != "timer" {
+			t.Errorf("delivered %q, want timer", msg.Event)
+		}
+	default:
+		t.Fatal("a small message wasn't delivered")
+	}
+	if len(relay.outbox) != 1 {
+		t.Fatalf("queued %d messages to relay, want 1", len(relay.outbox))
+	}
+	if p := <-relay.outbox; len(p.payload) >= maxPayload || !strings.Contains(p.payload, `"o":"instance-a"`) {
+		t.Errorf("payload = %s", p.payload)
+	}
+
+	hub.Publish("brew-event:1", Message{Event: "presence", Data: strings.Repeat("x", maxPayload)})
+	select {
+	case msg := <-messages:
+		t.Errorf("delivered %q, which is too large to relay", msg.Event)
+	default:
+	}
+	if len(relay.outbox) != 0 {
+		t.Errorf("queued a message too large to relay")
+	}
+}
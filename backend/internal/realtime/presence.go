@@ -0,0 +1,153 @@
+package realtime
+
+import (
+	"context"
+	"crypto/rand"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/logger"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// EventPresence is the message broadcast on a topic when who is connected
+// to it changes
+const EventPresence = "presence"
+
+// presenceHeartbeat is how often an instance refreshes its presence rows
+const presenceHeartbeat = 30 * time.Second
+
+// presenceStaleSeconds is how long presence rows last without a refresh,
+// after which their instance is taken to have died
+const presenceStaleSeconds = 90
+
+// PresentUser is a user connected to a topic
+type PresentUser struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+}
+
+// PresenceUpdate is who is connected to a topic, on any instance
+type PresenceUpdate struct {
+	Count int           `json:"count"`
+	Users []PresentUser `json:"users"`
+}
+
+// Presence tracks who is connected to each topic across instances. Each
+// open stream has a row, and the topic's presence is broadcast through the
+// hub when one opens or closes.
+type Presence struct {
+	queries    *db.Queries
+	hub        *Hub
+	instanceID string
+}
+
+// NewPresence creates a presence tracker for this instance
+func NewPresence(queries *db.Queries, hub *Hub, instanceID string) *Presence {
+	return &Presence{
+		queries:    queries,
+		hub:        hub,
+		instanceID: instanceID,
+	}
+}
+
+// Join marks userID present on topic while a stream is open. The returned
+// function marks the stream closed and must be called once it is. The user
+// stays present until their last stream on any instance closes.
+func (p *Presence) Join(ctx context.Context, topic, userID string) func() {
+	connectionID := ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String()
+	err := p.queries.JoinRealtimePresence(ctx, db.JoinRealtimePresenceParams{
+		ConnectionID: connectionID,
+		Topic:        topic,
+		UserID:       userID,
+		InstanceID:   p.instanceID,
+	})
+	if err != nil {
+		logger.Warn("Failed to record realtime presence", "topic", topic, "error", err)
+		return func() {}
+	}
+	p.broadcast(ctx, topic)
+
+	return func() {
+		// The stream's request is over; leaving mustn't be cut short with it
+		ctx := context.WithoutCancel(ctx)
+		if err := p.queries.LeaveRealtimePresence(ctx, connectionID); err != nil {
+			logger.Warn("Failed to clear realtime presence", "topic", topic, "error", err)
+			return
+		}
+		p.broadcast(ctx, topic)
+	}
+}
+
+// List returns who is connected to topic on any instance
+func (p *Presence) List(ctx context.Context, topic string) (PresenceUpdate, error) {
+	rows, err := p.queries.ListRealtimePresence(ctx, db.ListRealtimePresenceParams{
+		Topic:        topic,
+		StaleSeconds: presenceStaleSeconds,
+	})
+	if err != nil {
+		return PresenceUpdate{}, err
+	}
+
+	users := make([]PresentUser, 0, len(rows))
+	for _, row := range rows {
+		users = append(users, PresentUser(row))
+	}
+	return PresenceUpdate{Count: len(users), Users: users}, nil
+}
+
+// Run keeps this instance's presence rows fresh and prunes those of
+// instances that died, until ctx is cancelled. It then clears this
+// instance's rows.
+func (p *Presence) Run(ctx context.Context) {
+	ticker := time.NewTicker(presenceHeartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := p.queries.DeleteInstanceRealtimePresence(context.Background(), p.instanceID); err != nil {
+				logger.Warn("Failed to clear realtime presence on shutdown", "error", err)
+			}
+			return
+		case <-ticker.C:
+			p.heartbeat(ctx)
+		}
+	}
+}
+
+// heartbeat refreshes this instance's rows, then prunes stale ones and
+// broadcasts the presence of their topics
+func (p *Presence) heartbeat(ctx context.Context) {
+	if err := p.queries.TouchRealtimePresence(ctx, p.instanceID); err != nil {
+		logger.Warn("Failed to refresh realtime presence", "error", err)
+		return
+	}
+
+	topics, err := p.queries.PurgeStaleRealtimePresence(ctx, presenceStaleSeconds)
+	if err != nil {
+		logger.Warn("Failed to prune stale realtime presence", "error", err)
+		return
+	}
+	seen := make(map[string]bool, len(topics))
+	for _, topic := range topics {
+		if !seen[topic] {
+			seen[topic] = true
+			p.broadcast(ctx, topic)
+		}
+	}
+	if len(topics) > 0 {
+		logger.Info("Pruned stale realtime presence", "rows", len(topics), "topics", len(seen))
+	}
+}
+
+// broadcast publishes topic's presence to its subscribers on every instance
+func (p *Presence) broadcast(ctx context.Context, topic string) {
+	update, err := p.List(ctx, topic)
+	if err != nil {
+		logger.Warn("Failed to list realtime presence", "topic", topic, "error", err)
+		return
+	}
+	p.hub.Publish(topic, Message{Event: EventPresence, Data: update})
+}
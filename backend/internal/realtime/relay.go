@@ -0,0 +1,179 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/logger"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// channel is the Postgres notification channel messages are relayed on
+const channel = "brewd_realtime"
+
+// maxPayload bounds notification payloads, which Postgres requires to be
+// shorter than 8000 bytes
+const maxPayload = 8000
+
+// relayBuffer is how many messages may wait to be relayed before new ones
+// are dropped
+const relayBuffer = 256
+
+// Backoff between attempts to reconnect the listener
+const (
+	minReconnectBackoff = time.Second
+	maxReconnectBackoff = 30 * time.Second
+)
+
+// envelope is a relayed message as sent in a notification
+type envelope struct {
+	Origin string          `json:"o"`
+	Topic  string          `json:"t"`
+	Event  string          `json:"e"`
+	Data   json.RawMessage `json:"d"`
+}
+
+// pending is an encoded message waiting to be relayed
+type pending struct {
+	topic   string
+	event   string
+	payload string
+}
+
+// Relay carries messages between the hubs of instances with Postgres
+// LISTEN/NOTIFY, so an event reaches users connected to any instance.
+// Messages are sent with NOTIFY through the pool and received on a
+// dedicated session connection, which pgbouncer in transaction pooling mode
+// can't provide, so connString may name the database directly.
+//
+// Notifications aren't stored: when the listener reconnects, messages sent
+// meanwhile are lost, so the hub drops its subscribers and their clients
+// resync.
+type Relay struct {
+	hub        *Hub
+	queries    *db.Queries
+	connString string
+	instanceID string
+	outbox     chan pending
+}
+
+// NewRelay creates a relay for hub, identifying this instance's messages by
+// instanceID so it doesn't deliver them twice
+func NewRelay(hub *Hub, queries *db.Queries, connString, instanceID string) *Relay {
+	return &Relay{
+		hub:        hub,
+		queries:    queries,
+		connString: connString,
+		instanceID: instanceID,
+		outbox:     make(chan pending, relayBuffer),
+	}
+}
+
+// Run sends queued messages and delivers other instances' messages to the
+// hub until ctx is cancelled, reconnecting the listener when it fails
+func (r *Relay) Run(ctx context.Context) {
+	go r.sendLoop(ctx)
+
+	backoff := minReconnectBackoff
+	for connected := false; ; {
+		err := r.listen(ctx, func() {
+			if connected {
+				r.hub.dropAll()
+			}
+			connected = true
+			backoff = minReconnectBackoff
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Warn("Realtime listener disconnected, reconnecting", "error", err, "backoff", backoff.String())
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxReconnectBackoff)
+	}
+}
+
+// encode returns msg as a notification payload, failing if it is too
+// large for one
+func (r *Relay) encode(topic string, msg Message) (pending, error) {
+	data, err := json.Marshal(msg.Data)
+	if err != nil {
+		return pending{}, err
+	}
+	payload, err := json.Marshal(envelope{Origin: r.instanceID, Topic: topic, Event: msg.Event, Data: data})
+	if err != nil {
+		return pending{}, err
+	}
+	if len(payload) >= maxPayload {
+		return pending{}, fmt.Errorf("payload of %d bytes isn't shorter than %d", len(payload), maxPayload)
+	}
+	return pending{topic: topic, event: msg.Event, payload: string(payload)}, nil
+}
+
+// send queues an encoded message to be relayed, dropping it when the queue
+// is full so publishing never blocks
+func (r *Relay) send(p pending) {
+	select {
+	case r.outbox <- p:
+	default:
+		logger.Warn("Realtime relay queue full, dropping message", "topic", p.topic, "event", p.event)
+	}
+}
+
+// sendLoop notifies other instances of queued messages
+func (r *Relay) sendLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case p := <-r.outbox:
+			r.notify(ctx, p)
+		}
+	}
+}
+
+// notify sends one message to the other instances
+func (r *Relay) notify(ctx context.Context, p pending) {
+	if err := r.queries.NotifyRealtime(ctx, p.payload); err != nil {
+		logger.Warn("Failed to relay realtime message", "topic", p.topic, "event", p.event, "error", err)
+	}
+}
+
+// listen delivers other instances' messages until the connection fails or
+// ctx is cancelled, calling connected once it is listening
+func (r *Relay) listen(ctx context.Context, connected func()) error {
+	conn, err := pgx.Connect(ctx, r.connString)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+channel); err != nil {
+		return err
+	}
+	logger.Info("Realtime listener connected", "channel", channel)
+	connected()
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var env envelope
+		if err := json.Unmarshal([]byte(n.Payload), &env); err != nil {
+			logger.Warn("Ignoring malformed realtime notification", "error", err)
+			continue
+		}
+		if env.Origin == r.instanceID {
+			continue
+		}
+		r.hub.deliver(env.Topic, Message{Event: env.Event, Data: env.Data})
+	}
+}
@@ -0,0 +1,50 @@
+package recipes
+
+// Params are the versioned parameters of a recipe revision
+type Params struct {
+	Dose            *float64 `json:"dose"`
+	Water           *float64 `json:"water"`
+	WaterTemp       *float64 `json:"water_temp"`
+	GrindSetting    *string  `json:"grind_setting"`
+	BrewTimeSeconds *int32   `json:"brew_time_seconds"`
+	Instructions    *string  `json:"instructions"`
+}
+
+// Change is one parameter that differs between two revisions. From and To
+// are nil when the parameter was unset on that side.
+type Change struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// Diff lists the parameters that changed from one revision to the next, in
+// a stable field order
+func Diff(from, to Params) []Change {
+	changes := []Change{}
+	changes = appendChange(changes, "dose", from.Dose, to.Dose)
+	changes = appendChange(changes, "water", from.Water, to.Water)
+	changes = appendChange(changes, "water_temp", from.WaterTemp, to.WaterTemp)
+	changes = appendChange(changes, "grind_setting", from.GrindSetting, to.GrindSetting)
+	changes = appendChange(changes, "brew_time_seconds", from.BrewTimeSeconds, to.BrewTimeSeconds)
+	changes = appendChange(changes, "instructions", from.Instructions, to.Instructions)
+	return changes
+}
+
+func appendChange[T comparable](changes []Change, field string, from, to *T) []Change {
+	if from == nil && to == nil {
+		return changes
+	}
+	if from != nil && to != nil && *from == *to {
+		return changes
+	}
+	return append(changes, Change{Field: field, From: value(from), To: value(to)})
+}
+
+// value dereferences p, keeping nil as an untyped nil for JSON
+func value[T any](p *T) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
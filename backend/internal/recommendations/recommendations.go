@@ -0,0 +1,84 @@
+package recommendations
+
+import (
+	"context"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/logger"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Kinds of item that can be recommended
+const (
+	KindRecipe = "recipe"
+	KindBean   = "bean"
+)
+
+// MaxAge is how long a user's recommendations are kept before they're
+// scored again
+const MaxAge = 24 * time.Hour
+
+// Size is how many recipes and beans are kept per user
+const Size = 50
+
+// batchSize bounds how many users a single claim scores
+const batchSize = 100
+
+// Run scores the recommendations of users whose scores are missing or older
+// than MaxAge every interval until ctx is cancelled
+func Run(ctx context.Context, queries *db.Queries, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := scoreStale(ctx, queries); err != nil {
+				logger.Error("Failed to score recommendations", "error", err)
+			}
+		}
+	}
+}
+
+// scoreStale claims users due for scoring and scores each. A claimed user
+// whose scoring fails keeps their previous recommendations until they're
+// due again.
+func scoreStale(ctx context.Context, queries *db.Queries) error {
+	staleBefore := pgtype.Timestamptz{Time: time.Now().Add(-MaxAge), Valid: true}
+	for {
+		userIDs, err := queries.ClaimRecommendationUsers(ctx, db.ClaimRecommendationUsersParams{
+			StaleBefore: staleBefore,
+			RowLimit:    batchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, userID := range userIDs {
+			if err := score(ctx, queries, userID); err != nil {
+				logger.Error("Failed to score user recommendations", "user_id", userID, "error", err)
+			}
+		}
+
+		if len(userIDs) < batchSize {
+			return nil
+		}
+	}
+}
+
+func score(ctx context.Context, queries *db.Queries, userID string) error {
+	if err := queries.RefreshRecipeRecommendations(ctx, db.RefreshRecipeRecommendationsParams{
+		UserID:   userID,
+		RowLimit: Size,
+	}); err != nil {
+		return err
+	}
+	return queries.RefreshBeanRecommendations(ctx, db.RefreshBeanRecommendationsParams{
+		UserID:   userID,
+		RowLimit: Size,
+	})
+}
@@ -0,0 +1,46 @@
+package reminders
+
+import (
+	"context"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/logger"
+)
+
+// batchSize bounds how many reminders a single poll claims
+const batchSize = 100
+
+// Run delivers due brew reminders as notifications every interval until ctx
+// is cancelled
+func Run(ctx context.Context, queries *db.Queries, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := deliverDue(ctx, queries); err != nil {
+				logger.Error("Failed to deliver brew reminders", "error", err)
+			}
+		}
+	}
+}
+
+// deliverDue claims due reminders. Marking a reminder sent writes its
+// notification to the outbox in the same statement, and the outbox
+// dispatcher notifies the brew's owner.
+func deliverDue(ctx context.Context, queries *db.Queries) error {
+	for {
+		due, err := queries.ClaimDueBrewReminders(ctx, batchSize)
+		if err != nil {
+			return err
+		}
+
+		if len(due) < batchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package replay
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"brewd/internal/db"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// DefaultSkew is how far a request's timestamp may be from the server's
+// clock by default, either way
+const DefaultSkew = 5 * time.Minute
+
+// maxNonceLength bounds the nonces senders choose
+const maxNonceLength = 200
+
+// Sources of protected requests, which scope their nonces
+const (
+	SourceStripe = "stripe"
+	SourceDevice = "device"
+)
+
+// UnsignedPayload stands in for the body hash of a streamed request, whose
+// body can't be hashed before it's handled, as in AWS Signature Version 4
+const UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+var (
+	ErrMissing   = errors.New("replay: missing request timestamp or nonce")
+	ErrStale     = errors.New("replay: request timestamp outside clock-skew tolerance")
+	ErrReplayed  = errors.New("replay: request nonce already used")
+	ErrSignature = errors.New("replay: request signature invalid")
+)
+
+// Guard rejects replayed inbound requests. A request carries the time it
+// was sent and a nonce; it's accepted within Skew of the server's clock,
+// and only once, as the nonce is remembered until the timestamp would be
+// rejected anyway. Nonces are kept in the database, so a replay is caught
+// whichever instance it reaches.
+type Guard struct {
+	queries *db.Queries
+	Skew    time.Duration
+}
+
+func NewGuard(queries *db.Queries, skew time.Duration) *Guard {
+	if skew <= 0 {
+		skew = DefaultSkew
+	}
+	return &Guard{queries: queries, Skew: skew}
+}
+
+// Check accepts a request from source sent at timestamp with nonce, or
+// returns why it's rejected. Errors other than those of this package are
+// failures to record the nonce.
+func (g *Guard) Check(ctx context.Context, source, nonce string, timestamp, now time.Time) error {
+	if nonce == "" || len(nonce) > maxNonceLength || timestamp.IsZero() {
+		return ErrMissing
+	}
+	if err := CheckTimestamp(timestamp, now, g.Skew); err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256([]byte(nonce))
+	rows, err := g.queries.ClaimRequestNonce(ctx, db.ClaimRequestNonceParams{
+		Source:    source,
+		NonceHash: hex.EncodeToString(sum[:]),
+		ExpiresAt: pgtype.Timestamptz{Time: timestamp.Add(g.Skew), Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrReplayed
+	}
+	return nil
+}
+
+// CheckTimestamp returns ErrStale unless timestamp is within skew of now
+func CheckTimestamp(timestamp, now time.Time, skew time.Duration) error {
+	if age := now.Sub(timestamp); age > skew || age < -skew {
+		return ErrStale
+	}
+	return nil
+}
+
+// ParseTimestamp parses a timestamp in Unix seconds, as sent in headers
+func ParseTimestamp(raw string) (time.Time, error) {
+	seconds, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return time.Time{}, ErrMissing
+	}
+	return time.Unix(seconds, 0), nil
+}
+
+// Sign returns the hex HMAC-SHA256, keyed with key, of a request's method,
+// URI (path and query), body hash (hex SHA-256, or UnsignedPayload), and
+// its timestamp and nonce as sent, one per line. Signing the timestamp and
+// nonce with the rest means a captured pair can't be reused on another
+// request.
+func Sign(key, method, uri, bodyHash, timestamp, nonce string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(strings.Join([]string{method, uri, bodyHash, timestamp, nonce}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify returns ErrSignature unless signature is Sign's for the request
+func Verify(signature, key, method, uri, bodyHash, timestamp, nonce string) error {
+	want := Sign(key, method, uri, bodyHash, timestamp, nonce)
+	if !hmac.Equal([]byte(strings.ToLower(signature)), []byte(want)) {
+		return ErrSignature
+	}
+	return nil
+}
+
+// HashBody returns the hex SHA-256 of a request body, as Sign takes it
+func HashBody(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// IsRejection reports whether err rejects the request, rather than being a
+// failure to check it
+func IsRejection(err error) bool {
+	return errors.Is(err, ErrMissing) || errors.Is(err, ErrStale) || errors.Is(err, ErrReplayed) ||
+		errors.Is(err, ErrSignature)
+}
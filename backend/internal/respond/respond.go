@@ -0,0 +1,132 @@
+package respond
+
+import (
+	"net/http"
+
+	"brewd/internal/i18n"
+	"brewd/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDKey is the context key the request ID is stored under
+const RequestIDKey = "request_id"
+
+// Envelope is the body of every JSON API response. Successful responses
+// carry Data (omitted when there is none); failed ones carry a localized
+// Error, its stable Code and, for validation failures, the failing Fields,
+// also summarized as messages by field in Details.
+type Envelope struct {
+	Success   bool                    `json:"success"`
+	Data      any                     `json:"data,omitempty"`
+	Included  map[string]any          `json:"included,omitempty"`
+	Meta      *Meta                   `json:"meta,omitempty"`
+	Error     string                  `json:"error,omitempty"`
+	Code      i18n.Code               `json:"code,omitempty"`
+	Details   map[string]string       `json:"details,omitempty"`
+	Fields    []validation.FieldError `json:"fields,omitempty"`
+	RequestID string                  `json:"request_id,omitempty"`
+}
+
+// Meta describes the page of a paginated list
+type Meta struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+	Count  int   `json:"count"`
+}
+
+// Write sends env with status, stamped with the request ID
+func Write(c *gin.Context, status int, env Envelope) {
+	env.RequestID = c.GetString(RequestIDKey)
+	c.JSON(status, env)
+}
+
+// OK sends data with 200; nil data sends a bare success
+func OK(c *gin.Context, data any) {
+	Write(c, http.StatusOK, Envelope{Success: true, Data: data})
+}
+
+// Created sends a newly created resource with 201
+func Created(c *gin.Context, data any) {
+	Write(c, http.StatusCreated, Envelope{Success: true, Data: data})
+}
+
+// Status sends data with a success status other than 200 or 201
+func Status(c *gin.Context, status int, data any) {
+	Write(c, status, Envelope{Success: true, Data: data})
+}
+
+// Page sends one page of a limit/offset list, where items is the page's
+// length
+func Page(c *gin.Context, data any, limit, offset int32, items int) {
+	Write(c, http.StatusOK, Envelope{
+		Success: true,
+		Data:    data,
+		Meta:    &Meta{Limit: limit, Offset: offset, Count: items},
+	})
+}
+
+// Error sends the localized error for code
+func Error(c *gin.Context, status int, code i18n.Code) {
+	Write(c, status, Envelope{Error: i18n.T(c, code), Code: code})
+}
+
+// Errorf sends the localized error for code, formatted with args
+func Errorf(c *gin.Context, status int, code i18n.Code, args ...any) {
+	Write(c, status, Envelope{Error: i18n.Tf(c, code, args...), Code: code})
+}
+
+// Details sends the localized error for code with per-field details
+func Details(c *gin.Context, status int, code i18n.Code, details map[string]string) {
+	Write(c, status, Envelope{Error: i18n.T(c, code), Code: code, Details: details})
+}
+
+// Invalid sends 400 invalid_request for a request that failed binding
+func Invalid(c *gin.Context, err error) {
+	Rejected(c, http.StatusBadRequest, i18n.CodeInvalidRequest, err)
+}
+
+// Rejected sends the localized error for code along with the fields err,
+// a binding error, rejected
+func Rejected(c *gin.Context, status int, code i18n.Code, err error) {
+	tag := i18n.Locale(c)
+	Write(c, status, Envelope{
+		Error:   i18n.T(c, code),
+		Code:    code,
+		Details: validation.Details(tag, err),
+		Fields:  validation.Errors(tag, err),
+	})
+}
+
+// ProblemContentType is the media type of RFC 9457 problem details
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 9457 problem details body. It also carries the
+// envelope's error fields, so clients reading either format find the
+// localized Error, its Code and the request ID.
+type Problem struct {
+	Type      string    `json:"type"`
+	Title     string    `json:"title"`
+	Status    int       `json:"status"`
+	Detail    string    `json:"detail"`
+	Success   bool      `json:"success"`
+	Error     string    `json:"error"`
+	Code      i18n.Code `json:"code"`
+	RequestID string    `json:"request_id,omitempty"`
+}
+
+// WriteProblem sends the localized error for code as problem details with
+// status
+func WriteProblem(c *gin.Context, status int, code i18n.Code) {
+	message := i18n.T(c, code)
+	c.Header("Content-Type", ProblemContentType)
+	c.JSON(status, Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    message,
+		Error:     message,
+		Code:      code,
+		RequestID: c.GetString(RequestIDKey),
+	})
+}
@@ -0,0 +1,224 @@
+package retention
+
+import (
+	"context"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/lease"
+	"brewd/internal/logger"
+)
+
+// Data classes with a retention period. Soft-deleted records are API keys,
+// club invites and invites that were revoked, kept so far for their
+// history.
+const (
+	ClassAuthEvents      = "auth_events"
+	ClassAnalyticsEvents = "analytics_events"
+	ClassNotifications   = "notifications"
+	ClassSoftDeleted     = "soft_deleted"
+)
+
+// batchSize bounds how many rows a single statement purges, so each holds
+// its row locks briefly
+const batchSize = 1000
+
+// Policy is how many days each data class is kept; 0 or below keeps it
+// forever
+type Policy struct {
+	AuthEventDays    int
+	AnalyticsDays    int
+	NotificationDays int
+	SoftDeletedDays  int
+}
+
+// Report is a data class's retention period and the rows past it
+type Report struct {
+	Class   string
+	Days    int
+	Expired int64
+}
+
+// table counts and purges one table's rows past a retention period. purge
+// deletes a single batch.
+type table struct {
+	count func(q *db.Queries, ctx context.Context, days int32) (int64, error)
+	purge func(q *db.Queries, ctx context.Context, days int32) (int64, error)
+}
+
+// class is a data class, with the tables it is kept in
+type class struct {
+	name   string
+	days   func(Policy) int
+	tables []table
+}
+
+var classes = []class{
+	{
+		name: ClassAuthEvents,
+		days: func(p Policy) int { return p.AuthEventDays },
+		tables: []table{{
+			count: (*db.Queries).CountExpiredAuthEvents,
+			purge: func(q *db.Queries, ctx context.Context, days int32) (int64, error) {
+				return q.PurgeAuthEvents(ctx, db.PurgeAuthEventsParams{RetentionDays: days, BatchSize: batchSize})
+			},
+		}},
+	},
+	{
+		name: ClassAnalyticsEvents,
+		days: func(p Policy) int { return p.AnalyticsDays },
+		tables: []table{{
+			count: (*db.Queries).CountExpiredAnalyticsEvents,
+			purge: func(q *db.Queries, ctx context.Context, days int32) (int64, error) {
+				return q.PurgeAnalyticsEvents(ctx, db.PurgeAnalyticsEventsParams{RetentionDays: days, BatchSize: batchSize})
+			},
+		}},
+	},
+	{
+		name: ClassNotifications,
+		days: func(p Policy) int { return p.NotificationDays },
+		tables: []table{{
+			count: (*db.Queries).CountExpiredNotifications,
+			purge: func(q *db.Queries, ctx context.Context, days int32) (int64, error) {
+				return q.PurgeNotifications(ctx, db.PurgeNotificationsParams{RetentionDays: days, BatchSize: batchSize})
+			},
+		}},
+	},
+	{
+		name: ClassSoftDeleted,
+		days: func(p Policy) int { return p.SoftDeletedDays },
+		tables: []table{
+			{
+				count: (*db.Queries).CountExpiredRevokedAPIKeys,
+				purge: func(q *db.Queries, ctx context.Context, days int32) (int64, error) {
+					return q.PurgeRevokedAPIKeys(ctx, db.PurgeRevokedAPIKeysParams{RetentionDays: days, BatchSize: batchSize})
+				},
+			},
+			{
+				count: (*db.Queries).CountExpiredRevokedClubInvites,
+				purge: func(q *db.Queries, ctx context.Context, days int32) (int64, error) {
+					return q.PurgeRevokedClubInvites(ctx, db.PurgeRevokedClubInvitesParams{RetentionDays: days, BatchSize: batchSize})
+				},
+			},
+			{
+				count: (*db.Queries).CountExpiredRevokedInvites,
+				purge: func(q *db.Queries, ctx context.Context, days int32) (int64, error) {
+					return q.PurgeRevokedInvites(ctx, db.PurgeRevokedInvitesParams{RetentionDays: days, BatchSize: batchSize})
+				},
+			},
+		},
+	},
+}
+
+// Job purges each data class's rows past its retention period. In a dry
+// run it only reports how many rows it would purge.
+type Job struct {
+	queries *db.Queries
+	policy  Policy
+	dryRun  bool
+}
+
+// NewJob returns a job enforcing policy
+func NewJob(queries *db.Queries, policy Policy, dryRun bool) *Job {
+	return &Job{queries: queries, policy: policy, dryRun: dryRun}
+}
+
+// DryRun reports whether the job only reports what it would purge
+func (j *Job) DryRun() bool {
+	return j.dryRun
+}
+
+// Run enforces the policy every interval until ctx is cancelled
+func (j *Job) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			j.enforce(ctx)
+		}
+	}
+}
+
+// Report counts each data class's rows past its retention period, as the
+// next run would purge them. Classes kept forever report none.
+func (j *Job) Report(ctx context.Context) ([]Report, error) {
+	reports := make([]Report, 0, len(classes))
+	for _, c := range classes {
+		report := Report{Class: c.name, Days: c.days(j.policy)}
+		if report.Days > 0 {
+			for _, t := range c.tables {
+				n, err := t.count(j.queries, ctx, int32(report.Days))
+				if err != nil {
+					return nil, err
+				}
+				report.Expired += n
+			}
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// enforce purges, or in a dry run counts, each data class with a retention
+// period. A class that fails is logged and the others still run.
+func (j *Job) enforce(ctx context.Context) {
+	for _, c := range classes {
+		days := c.days(j.policy)
+		if days <= 0 {
+			continue
+		}
+
+		if j.dryRun {
+			var expired int64
+			for _, t := range c.tables {
+				n, err := t.count(j.queries, ctx, int32(days))
+				if err != nil {
+					logger.Error("Failed to count expired rows", "class", c.name, "error", err)
+					break
+				}
+				expired += n
+			}
+			if expired > 0 {
+				logger.Info("Retention dry run", "class", c.name, "retention_days", days, "would_purge", expired)
+			}
+			continue
+		}
+
+		var purged int64
+		for _, t := range c.tables {
+			n, err := purge(ctx, j.queries, t, int32(days))
+			purged += n
+			if err != nil {
+				logger.Error("Failed to purge expired rows", "class", c.name, "error", err)
+				break
+			}
+		}
+		if purged > 0 {
+			logger.Info("Purged expired rows", "class", c.name, "retention_days", days, "count", purged)
+		}
+	}
+}
+
+// purge deletes a table's rows past days in batches until none are left,
+// returning how many it deleted
+func purge(ctx context.Context, queries *db.Queries, t table, days int32) (int64, error) {
+	var total int64
+	for ctx.Err() == nil {
+		if err := lease.Check(ctx); err != nil {
+			return total, err
+		}
+		n, err := t.purge(queries, ctx, days)
+		if err != nil {
+			return total, err
+		}
+		total += n
+		if n < batchSize {
+			break
+		}
+	}
+	return total, nil
+}
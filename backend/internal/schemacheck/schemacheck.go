@@ -0,0 +1,118 @@
+package schemacheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strconv"
+	"strings"
+
+	"brewd/db/migrations"
+	"brewd/internal/db"
+	"brewd/internal/health"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// RequiredExtensions are the Postgres extensions the schema relies on:
+// plpgsql for its triggers
+var RequiredExtensions = []string{"plpgsql"}
+
+// undefinedTable is the Postgres error code for a missing table, as
+// schema_migrations is before the first migration
+const undefinedTable = "42P01"
+
+// Status compares the database's schema with the migrations the server was
+// built with. Version and Dirty are golang-migrate's schema_migrations
+// record; Dirty means a migration failed partway.
+type Status struct {
+	Version           int64
+	Dirty             bool
+	Expected          int64
+	MissingExtensions []string
+}
+
+// Ready reports whether the server can run against the schema. A schema
+// ahead of the server is fine, as during a rolling deploy migrations are
+// applied before new servers replace old ones.
+func (s Status) Ready() bool {
+	return s.Version >= s.Expected && !s.Dirty && len(s.MissingExtensions) == 0
+}
+
+// Err describes why the schema isn't ready, or returns nil
+func (s Status) Err() error {
+	switch {
+	case s.Dirty:
+		return fmt.Errorf("migration %d failed partway and needs fixing", s.Version)
+	case s.Version < s.Expected:
+		return fmt.Errorf("schema is at version %d, behind %d: run ./migrate.sh up", s.Version, s.Expected)
+	case len(s.MissingExtensions) > 0:
+		return fmt.Errorf("missing extensions: %s", strings.Join(s.MissingExtensions, ", "))
+	}
+	return nil
+}
+
+// Expected returns the latest migration version embedded in the server
+func Expected() (int64, error) {
+	entries, err := fs.ReadDir(migrations.FS, ".")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest int64
+	for _, entry := range entries {
+		prefix, _, _ := strings.Cut(entry.Name(), "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("migration %q has no version", entry.Name())
+		}
+		latest = max(latest, version)
+	}
+	return latest, nil
+}
+
+// Check reads the database's schema version and extensions
+func Check(ctx context.Context, conn db.DBTX) (Status, error) {
+	expected, err := Expected()
+	if err != nil {
+		return Status{}, err
+	}
+	status := Status{Expected: expected}
+
+	err = conn.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&status.Version, &status.Dirty)
+	var pgErr *pgconn.PgError
+	if err != nil && !(errors.As(err, &pgErr) && pgErr.Code == undefinedTable) && !errors.Is(err, pgx.ErrNoRows) {
+		return Status{}, fmt.Errorf("reading schema version: %w", err)
+	}
+
+	rows, err := conn.Query(ctx, "SELECT extname FROM pg_extension")
+	if err != nil {
+		return Status{}, fmt.Errorf("listing extensions: %w", err)
+	}
+	installed, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return Status{}, fmt.Errorf("listing extensions: %w", err)
+	}
+	for _, ext := range RequiredExtensions {
+		if !slices.Contains(installed, ext) {
+			status.MissingExtensions = append(status.MissingExtensions, ext)
+		}
+	}
+	return status, nil
+}
+
+// HealthCheck returns a readiness check failing until the schema is ready,
+// so an instance started before its migrations were applied gets traffic
+// once they are
+func HealthCheck(conn db.DBTX) health.CheckerFunc {
+	return func(ctx context.Context) error {
+		status, err := Check(ctx, conn)
+		if err != nil {
+			return err
+		}
+		return status.Err()
+	}
+}
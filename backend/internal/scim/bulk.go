@@ -0,0 +1,35 @@
+package scim
+
+import "encoding/json"
+
+// BulkRequest is a batch of operations on users. Processing stops after
+// FailOnErrors failures, if it is set.
+type BulkRequest struct {
+	Schemas      []string        `json:"schemas"`
+	FailOnErrors int             `json:"failOnErrors"`
+	Operations   []BulkOperation `json:"Operations"`
+}
+
+// BulkOperation is one request of a batch: POST to /Users, or PUT, PATCH
+// or DELETE of /Users/{id}
+type BulkOperation struct {
+	Method string          `json:"method"`
+	BulkID string          `json:"bulkId,omitempty"`
+	Path   string          `json:"path"`
+	Data   json.RawMessage `json:"data,omitempty"`
+}
+
+// BulkResponse reports the outcome of each operation processed
+type BulkResponse struct {
+	Schemas    []string     `json:"schemas"`
+	Operations []BulkResult `json:"Operations"`
+}
+
+// BulkResult is one operation's outcome; Response is set for failures
+type BulkResult struct {
+	Method   string `json:"method"`
+	BulkID   string `json:"bulkId,omitempty"`
+	Location string `json:"location,omitempty"`
+	Status   string `json:"status"`
+	Response *Error `json:"response,omitempty"`
+}
@@ -0,0 +1,53 @@
+package scim
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Attributes users can be filtered on
+const (
+	FilterUserName   = "userName"
+	FilterExternalID = "externalId"
+	FilterEmail      = "emails.value"
+)
+
+// Filter is an equality filter on one attribute, the only kind brewd
+// supports. Provisioning systems use it to find an account before
+// creating it, e.g. userName eq "ana".
+type Filter struct {
+	Attribute string
+	Value     string
+}
+
+// ParseFilter parses a filter of the form `attribute eq "value"`
+func ParseFilter(raw string) (Filter, *Error) {
+	invalid := NewError(http.StatusBadRequest, ErrorInvalidFilter,
+		`only filters of the form userName, externalId or emails.value eq "value" are supported`)
+
+	attr, rest, ok := strings.Cut(strings.TrimSpace(raw), " ")
+	if !ok {
+		return Filter{}, invalid
+	}
+	op, value, ok := strings.Cut(strings.TrimSpace(rest), " ")
+	if !ok || !strings.EqualFold(op, "eq") {
+		return Filter{}, invalid
+	}
+	var f Filter
+	if err := json.Unmarshal([]byte(strings.TrimSpace(value)), &f.Value); err != nil {
+		return Filter{}, invalid
+	}
+
+	switch attributeName(attr) {
+	case "username":
+		f.Attribute = FilterUserName
+	case "externalid":
+		f.Attribute = FilterExternalID
+	case "emails", "emails.value":
+		f.Attribute = FilterEmail
+	default:
+		return Filter{}, invalid
+	}
+	return f, nil
+}
@@ -0,0 +1,116 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// PatchRequest is a PATCH request's list of operations
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// PatchOperation adds, replaces or removes one attribute, or without a
+// path, the attributes of value
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Apply applies the operations to user, which holds the account's current
+// state, in order. Attributes brewd doesn't store are ignored.
+func (r PatchRequest) Apply(user *User) *Error {
+	for _, op := range r.Operations {
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+			if op.Path != "" {
+				if err := set(user, op.Path, op.Value); err != nil {
+					return err
+				}
+				continue
+			}
+			var attrs map[string]json.RawMessage
+			if err := json.Unmarshal(op.Value, &attrs); err != nil {
+				return NewError(http.StatusBadRequest, ErrorInvalidValue, "value must be an object when there is no path")
+			}
+			for attr, value := range attrs {
+				if err := set(user, attr, value); err != nil {
+					return err
+				}
+			}
+		case "remove":
+			if err := remove(user, op.Path); err != nil {
+				return err
+			}
+		default:
+			return NewError(http.StatusBadRequest, ErrorInvalidSyntax, fmt.Sprintf("unknown op %q", op.Op))
+		}
+	}
+	return nil
+}
+
+// set sets one attribute of user to value
+func set(user *User, attr string, value json.RawMessage) *Error {
+	name := attributeName(attr)
+	var err error
+	switch {
+	case name == "username":
+		err = json.Unmarshal(value, &user.UserName)
+	case name == "externalid":
+		err = json.Unmarshal(value, &user.ExternalID)
+	case name == "password":
+		err = json.Unmarshal(value, &user.Password)
+	case name == "active":
+		var active bool
+		active, err = parseBool(value)
+		user.Active = &active
+	case name == "emails":
+		err = json.Unmarshal(value, &user.Emails)
+	case isEmailValuePath(name):
+		var email string
+		err = json.Unmarshal(value, &email)
+		user.Emails = []Email{{Value: email, Primary: true}}
+	}
+	if err != nil {
+		return NewError(http.StatusBadRequest, ErrorInvalidValue, fmt.Sprintf("invalid value for %s", attr))
+	}
+	return nil
+}
+
+// remove clears one attribute of user. Only externalId is optional.
+func remove(user *User, attr string) *Error {
+	switch name := attributeName(attr); {
+	case name == "":
+		return NewError(http.StatusBadRequest, ErrorInvalidPath, "remove needs a path")
+	case name == "externalid":
+		user.ExternalID = nil
+	case name == "username" || name == "password" || name == "active" || name == "emails" || isEmailValuePath(name):
+		return NewError(http.StatusBadRequest, ErrorMutability, fmt.Sprintf("%s can't be removed", attr))
+	}
+	return nil
+}
+
+// isEmailValuePath reports whether name addresses the value of the email
+// address, as emails.value or filtered like emails[type eq "work"].value
+func isEmailValuePath(name string) bool {
+	return name == "emails.value" || (strings.HasPrefix(name, "emails[") && strings.HasSuffix(name, "].value"))
+}
+
+// parseBool decodes a boolean, which some provisioning systems send as a
+// string ("False")
+func parseBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return false, err
+	}
+	return strconv.ParseBool(s)
+}
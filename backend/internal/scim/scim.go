@@ -0,0 +1,250 @@
+package scim
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema URNs of the resources and messages brewd speaks (RFC 7643 and
+// RFC 7644)
+const (
+	SchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SchemaResourceType          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+	SchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaBulkRequest           = "urn:ietf:params:scim:api:messages:2.0:BulkRequest"
+	SchemaBulkResponse          = "urn:ietf:params:scim:api:messages:2.0:BulkResponse"
+	SchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// ContentType is the media type of SCIM requests and responses
+const ContentType = "application/scim+json"
+
+// BasePath is where the SCIM endpoints are mounted
+const BasePath = "/scim/v2"
+
+// Limits on list pages and bulk requests
+const (
+	DefaultCount      = 100
+	MaxResults        = 200
+	MaxBulkOperations = 100
+	MaxBulkBytes      = 1 << 20
+)
+
+// TokenPrefix starts every SCIM client token, so leaked tokens are easy
+// to recognise
+const TokenPrefix = "brewd_scim_"
+
+// tokenPrefixLength is how much of a token is kept in the clear to
+// identify it
+const tokenPrefixLength = len(TokenPrefix) + 6
+
+// NewToken returns a random client token, its displayable prefix and the
+// hash to store
+func NewToken() (token, prefix, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	token = TokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return token, token[:tokenPrefixLength], Hash(token), nil
+}
+
+// Hash returns the stored form of a client token
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Error types (scimType) of SCIM error responses
+const (
+	ErrorInvalidFilter = "invalidFilter"
+	ErrorInvalidSyntax = "invalidSyntax"
+	ErrorInvalidPath   = "invalidPath"
+	ErrorInvalidValue  = "invalidValue"
+	ErrorUniqueness    = "uniqueness"
+	ErrorMutability    = "mutability"
+	ErrorTooMany       = "tooMany"
+)
+
+// Error is a SCIM error response. Details are for the provisioning
+// system's logs, so they aren't localized.
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// NewError returns the error response for status, with an optional
+// scimType
+func NewError(status int, scimType, detail string) *Error {
+	return &Error{
+		Schemas:  []string{SchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Detail
+}
+
+// StatusCode returns the error's HTTP status
+func (e *Error) StatusCode() int {
+	if status, err := strconv.Atoi(e.Status); err == nil {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// Email is one of a user's email addresses. brewd accounts have one, so
+// only the primary address (or the first) is kept.
+type Email struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// Meta describes a resource
+type Meta struct {
+	ResourceType string     `json:"resourceType"`
+	Created      *time.Time `json:"created,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Location     string     `json:"location"`
+}
+
+// User is the User resource. Attributes brewd doesn't store, like name
+// and displayName, are accepted and dropped. Password is write-only.
+type User struct {
+	Schemas    []string `json:"schemas"`
+	ID         string   `json:"id,omitempty"`
+	ExternalID *string  `json:"externalId,omitempty"`
+	UserName   string   `json:"userName"`
+	Emails     []Email  `json:"emails,omitempty"`
+	Active     *bool    `json:"active,omitempty"`
+	Password   string   `json:"password,omitempty"`
+	Meta       *Meta    `json:"meta,omitempty"`
+}
+
+// PrimaryEmail returns the user's primary email address, or the first if
+// none is marked primary
+func (u User) PrimaryEmail() string {
+	for _, email := range u.Emails {
+		if email.Primary {
+			return email.Value
+		}
+	}
+	if len(u.Emails) > 0 {
+		return u.Emails[0].Value
+	}
+	return ""
+}
+
+// IsActive reports whether the user is active; it is unless it says not
+func (u User) IsActive() bool {
+	return u.Active == nil || *u.Active
+}
+
+// UserLocation returns the path of a User resource
+func UserLocation(id string) string {
+	return BasePath + "/Users/" + id
+}
+
+// ListResponse is one page of a list of resources. StartIndex is 1-based.
+type ListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    any      `json:"Resources"`
+}
+
+type supported struct {
+	Supported bool `json:"supported"`
+}
+
+// ServiceProviderConfig describes which SCIM features brewd supports
+type ServiceProviderConfig struct {
+	Schemas []string  `json:"schemas"`
+	Patch   supported `json:"patch"`
+	Bulk    struct {
+		Supported      bool `json:"supported"`
+		MaxOperations  int  `json:"maxOperations"`
+		MaxPayloadSize int  `json:"maxPayloadSize"`
+	} `json:"bulk"`
+	Filter struct {
+		Supported  bool `json:"supported"`
+		MaxResults int  `json:"maxResults"`
+	} `json:"filter"`
+	ChangePassword        supported              `json:"changePassword"`
+	Sort                  supported              `json:"sort"`
+	ETag                  supported              `json:"etag"`
+	AuthenticationSchemes []AuthenticationScheme `json:"authenticationSchemes"`
+	Meta                  Meta                   `json:"meta"`
+}
+
+// AuthenticationScheme is a way SCIM clients authenticate
+type AuthenticationScheme struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Config returns brewd's service provider configuration
+func Config() ServiceProviderConfig {
+	config := ServiceProviderConfig{
+		Schemas:        []string{SchemaServiceProviderConfig},
+		Patch:          supported{Supported: true},
+		ChangePassword: supported{Supported: true},
+		AuthenticationSchemes: []AuthenticationScheme{{
+			Type:        "oauthbearertoken",
+			Name:        "Bearer token",
+			Description: "The client token an admin issued, as Authorization: Bearer <token>",
+		}},
+		Meta: Meta{ResourceType: "ServiceProviderConfig", Location: BasePath + "/ServiceProviderConfig"},
+	}
+	config.Bulk.Supported = true
+	config.Bulk.MaxOperations = MaxBulkOperations
+	config.Bulk.MaxPayloadSize = MaxBulkBytes
+	config.Filter.Supported = true
+	config.Filter.MaxResults = MaxResults
+	return config
+}
+
+// ResourceType describes a resource and where its endpoint is
+type ResourceType struct {
+	Schemas  []string `json:"schemas"`
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Endpoint string   `json:"endpoint"`
+	Schema   string   `json:"schema"`
+	Meta     Meta     `json:"meta"`
+}
+
+// ResourceTypes lists the resources brewd provisions: only users
+func ResourceTypes() []ResourceType {
+	return []ResourceType{{
+		Schemas:  []string{SchemaResourceType},
+		ID:       "User",
+		Name:     "User",
+		Endpoint: "/Users",
+		Schema:   SchemaUser,
+		Meta:     Meta{ResourceType: "ResourceType", Location: BasePath + "/ResourceTypes/User"},
+	}}
+}
+
+// attributeName returns the lowercase name of an attribute, without the
+// User schema's URN if the client qualified it with one
+func attributeName(attr string) string {
+	attr = strings.ToLower(strings.TrimSpace(attr))
+	return strings.TrimPrefix(attr, strings.ToLower(SchemaUser)+":")
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Listen specs accepted by Listen, besides a TCP address
+const (
+	unixPrefix    = "unix:"
+	systemdPrefix = "systemd"
+)
+
+// listenFDsStart is the first file descriptor systemd passes sockets on
+const listenFDsStart = 3
+
+// Listen opens the listener spec names:
+//
+//   - A TCP address, e.g. :8080
+//   - unix:/path/to.sock, a unix socket created with mode, replacing a
+//     stale one left by an earlier run
+//   - systemd, the first socket passed by systemd socket activation, or
+//     systemd:name, the one its unit names name (FileDescriptorName=)
+func Listen(spec string, mode fs.FileMode) (net.Listener, error) {
+	switch {
+	case strings.HasPrefix(spec, unixPrefix):
+		return listenUnix(strings.TrimPrefix(spec, unixPrefix), mode)
+	case spec == systemdPrefix:
+		return systemdListener("")
+	case strings.HasPrefix(spec, systemdPrefix+":"):
+		return systemdListener(strings.TrimPrefix(spec, systemdPrefix+":"))
+	default:
+		return net.Listen("tcp", spec)
+	}
+}
+
+// listenUnix listens on a unix socket at path with mode
+func listenUnix(path string, mode fs.FileMode) (net.Listener, error) {
+	if path == "" {
+		return nil, errors.New("unix listener needs a socket path")
+	}
+	// A socket file outlives the process that made it; remove it only if
+	// nothing is listening on it
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&fs.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and isn't a socket", path)
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s is in use", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// systemdFiles are the sockets systemd passed, read once since the
+// environment describing them is cleared
+var systemdFiles map[string]*os.File
+
+// systemdOrder lists the passed sockets' names in the order passed
+var systemdOrder []string
+
+// systemdListener returns the socket systemd passed named name, or the
+// first one for an empty name. Each socket can be taken once.
+func systemdListener(name string) (net.Listener, error) {
+	if systemdFiles == nil {
+		if err := readSystemdFiles(); err != nil {
+			return nil, err
+		}
+	}
+
+	if name == "" && len(systemdOrder) > 0 {
+		name = systemdOrder[0]
+	}
+	f, ok := systemdFiles[name]
+	if !ok {
+		return nil, fmt.Errorf("systemd passed no socket named %q", name)
+	}
+	delete(systemdFiles, name)
+	defer f.Close()
+
+	return net.FileListener(f)
+}
+
+// readSystemdFiles takes the sockets systemd passed under the socket
+// activation protocol (LISTEN_PID, LISTEN_FDS and LISTEN_FDNAMES), and
+// clears those variables so child processes don't take them too
+func readSystemdFiles() error {
+	defer func() {
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+	}()
+
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return errors.New("no sockets were passed by systemd socket activation")
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count < 1 {
+		return errors.New("no sockets were passed by systemd socket activation")
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	systemdFiles = make(map[string]*os.File, count)
+	for i := 0; i < count; i++ {
+		fd := listenFDsStart + i
+		syscall.CloseOnExec(fd)
+
+		name := strconv.Itoa(fd)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		systemdFiles[name] = os.NewFile(uintptr(fd), name)
+		systemdOrder = append(systemdOrder, name)
+	}
+	return nil
+}
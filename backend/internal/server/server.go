@@ -0,0 +1,274 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"brewd/internal/logger"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// autocertRedirectAddr is where the HTTP redirect listens with autocert when
+// no address is configured. Let's Encrypt only sends HTTP challenges to port
+// 80, and the TLS-ALPN challenge only to 443, which the API may not be on.
+const autocertRedirectAddr = ":80"
+
+// readHeaderTimeout bounds how long a client may take to send its request
+// headers, so idle connections can't be held open
+const readHeaderTimeout = 10 * time.Second
+
+// ErrTLSConflict is returned when both certificate files and autocert
+// domains are configured
+var ErrTLSConflict = errors.New("set either TLS_CERT_FILE and TLS_KEY_FILE or AUTOCERT_DOMAINS, not both")
+
+// ErrTLSIncomplete is returned when only one of the certificate and key
+// files is configured
+var ErrTLSIncomplete = errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+
+// Config is how the server listens. TLS is terminated with the certificate
+// and key files, or with certificates for AutocertDomains obtained from
+// Let's Encrypt; with neither the server speaks plain HTTP.
+type Config struct {
+	Name             string      // Names the listener in logs, e.g. api
+	Addr             string      // Address the API listens on, e.g. :8080
+	Listen           string      // Listener spec overriding Addr; see Listen
+	UnixSocketMode   fs.FileMode // Mode of a unix socket the API listens on
+	TLSCertFile      string      // PEM certificate chain
+	TLSKeyFile       string      // PEM private key
+	AutocertDomains  []string    // Domains to obtain certificates for
+	AutocertCacheDir string      // Where obtained certificates are kept
+	AutocertEmail    string      // Contact for the Let's Encrypt account
+	RedirectAddr     string      // Plain HTTP address redirecting to HTTPS; :80 by default with autocert
+}
+
+// TLS reports whether the server terminates TLS
+func (c Config) TLS() bool {
+	return c.TLSCertFile != "" || c.TLSKeyFile != "" || len(c.AutocertDomains) > 0
+}
+
+// Validate checks the TLS settings are consistent
+func (c Config) Validate() error {
+	files := c.TLSCertFile != "" || c.TLSKeyFile != ""
+	if files && (c.TLSCertFile == "" || c.TLSKeyFile == "") {
+		return ErrTLSIncomplete
+	}
+	if files && len(c.AutocertDomains) > 0 {
+		return ErrTLSConflict
+	}
+	return nil
+}
+
+// Server serves the API, terminating TLS when configured
+type Server struct {
+	config   Config
+	http     *http.Server
+	redirect *http.Server
+	manager  *autocert.Manager
+}
+
+// New returns a server for handler. Over TLS it serves HTTP/2 as well as
+// HTTP/1.1.
+func New(config Config, handler http.Handler) (*Server, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		config: config,
+		http: &http.Server{
+			Addr:              config.Addr,
+			Handler:           handler,
+			ReadHeaderTimeout: readHeaderTimeout,
+		},
+	}
+	if !config.TLS() {
+		return s, nil
+	}
+
+	s.http.Protocols = new(http.Protocols)
+	s.http.Protocols.SetHTTP1(true)
+	s.http.Protocols.SetHTTP2(true)
+	s.http.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+
+	// Certificates are obtained on the first handshake for each domain and
+	// renewed before they expire. The TLS-ALPN challenge is answered on the
+	// API's port; the HTTP challenge on the redirect listener.
+	redirectAddr := config.RedirectAddr
+	var redirect http.Handler
+	if len(config.AutocertDomains) > 0 {
+		if redirectAddr == "" {
+			redirectAddr = autocertRedirectAddr
+		}
+		redirect = redirectHandler(config.Addr, config.AutocertDomains)
+		s.manager = &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(config.AutocertDomains...),
+			Cache:      autocert.DirCache(config.AutocertCacheDir),
+			Email:      config.AutocertEmail,
+		}
+		s.http.TLSConfig = s.manager.TLSConfig()
+		s.http.TLSConfig.MinVersion = tls.VersionTLS12
+		redirect = s.manager.HTTPHandler(redirect)
+	} else if redirectAddr != "" {
+		hosts, err := certificateHosts(config.TLSCertFile)
+		if err != nil {
+			return nil, err
+		}
+		redirect = redirectHandler(config.Addr, hosts)
+	}
+
+	if redirectAddr != "" {
+		s.redirect = &http.Server{
+			Addr:              redirectAddr,
+			Handler:           redirect,
+			ReadHeaderTimeout: readHeaderTimeout,
+		}
+	}
+	return s, nil
+}
+
+// ListenAndServe serves until the server fails or is shut down
+func (s *Server) ListenAndServe() error {
+	spec := s.config.Listen
+	if spec == "" {
+		spec = s.config.Addr
+	}
+	ln, err := Listen(spec, s.config.UnixSocketMode)
+	if err != nil {
+		return err
+	}
+	if ln.Addr().Network() == "unix" {
+		s.http.Handler = localPeer(s.http.Handler)
+	}
+
+	if s.redirect != nil {
+		go func() {
+			logger.Info("Redirecting HTTP to HTTPS", "addr", s.redirect.Addr)
+			if err := s.redirect.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error("HTTP redirect listener failed", "error", err)
+			}
+		}()
+	}
+
+	switch {
+	case s.manager != nil:
+		logger.Info("Starting server", "name", s.config.Name, "listen", spec, "tls", "autocert", "domains", s.config.AutocertDomains)
+		return s.http.ServeTLS(ln, "", "")
+	case s.config.TLS():
+		logger.Info("Starting server", "name", s.config.Name, "listen", spec, "tls", "files")
+		return s.http.ServeTLS(ln, s.config.TLSCertFile, s.config.TLSKeyFile)
+	default:
+		logger.Info("Starting server", "name", s.config.Name, "listen", spec)
+		return s.http.Serve(ln)
+	}
+}
+
+// SetKeepAlivesEnabled controls HTTP keep-alives; with them off, responses
+// close their connection
+func (s *Server) SetKeepAlivesEnabled(enabled bool) {
+	s.http.SetKeepAlivesEnabled(enabled)
+	if s.redirect != nil {
+		s.redirect.SetKeepAlivesEnabled(enabled)
+	}
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests
+// to finish until ctx is done. ListenAndServe then returns
+// http.ErrServerClosed.
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s.redirect != nil {
+		if err := s.redirect.Shutdown(ctx); err != nil {
+			logger.Warn("Failed to shut down HTTP redirect listener", "error", err)
+		}
+	}
+	return s.http.Shutdown(ctx)
+}
+
+// unixPeerAddr is the peer address given to requests over a unix socket,
+// which have none. They come from a proxy on the same host, which
+// TRUSTED_PROXIES can then trust to name the client.
+const unixPeerAddr = "127.0.0.1:0"
+
+// localPeer gives requests over a unix socket the loopback address as peer
+func localPeer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, err := net.SplitHostPort(r.RemoteAddr); err != nil {
+			r.RemoteAddr = unixPeerAddr
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// certificateHosts returns the names the first certificate in the PEM file
+// is for
+func certificateHosts(file string) ([]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("%s: no PEM certificate", file)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	hosts := append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		hosts = append(hosts, ip.String())
+	}
+	return hosts, nil
+}
+
+// redirectHandler redirects requests to the same URL over HTTPS, on the
+// port of addr unless it is 443. Only requests for one of hosts, which may
+// be wildcards like *.example.com, are redirected, so a forged Host header
+// can't send clients elsewhere; others get 421.
+func redirectHandler(addr string, hosts []string) http.Handler {
+	_, port, _ := net.SplitHostPort(addr)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(r.Host); err == nil {
+			host = h
+		}
+		if !matchHost(hosts, host) {
+			http.Error(w, "unknown host", http.StatusMisdirectedRequest)
+			return
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	})
+}
+
+// matchHost reports whether host is one of hosts, ignoring case. A wildcard
+// matches a single leftmost label, as in certificates.
+func matchHost(hosts []string, host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, h := range hosts {
+		h = strings.ToLower(h)
+		if h == host {
+			return true
+		}
+		if suffix, ok := strings.CutPrefix(h, "*"); ok && strings.HasPrefix(suffix, ".") {
+			label, rest, found := strings.Cut(host, ".")
+			if found && label != "" && "."+rest == suffix {
+				return true
+			}
+		}
+	}
+	return false
+}
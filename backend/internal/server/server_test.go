@@ -0,0 +1,36 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRedirectHandler(t *testing.T) {
+	handler := redirectHandler(":8443", []string{"brewd.example.com", "*.brewd.example.com"})
+	tests := []struct {
+		host     string
+		status   int
+		location string
+	}{
+		{"brewd.example.com", http.StatusMovedPermanently, "https://brewd.example.com:8443/brews?page=2"},
+		{"BREWD.example.com:80", http.StatusMovedPermanently, "https://BREWD.example.com:8443/brews?page=2"},
+		{"eu.brewd.example.com", http.StatusMovedPermanently, "https://eu.brewd.example.com:8443/brews?page=2"},
+		{"a.eu.brewd.example.com", http.StatusMisdirectedRequest, ""},
+		{"evil.example.com", http.StatusMisdirectedRequest, ""},
+		{"brewd.example.com.evil.com", http.StatusMisdirectedRequest, ""},
+		{"", http.StatusMisdirectedRequest, ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "http://placeholder/brews?page=2", nil)
+		r.Host = tt.host
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != tt.status {
+			t.Errorf("%q: status %d, want %d", tt.host, w.Code, tt.status)
+		}
+		if got := w.Header().Get("Location"); got != tt.location {
+			t.Errorf("%q: Location %q, want %q", tt.host, got, tt.location)
+		}
+	}
+}
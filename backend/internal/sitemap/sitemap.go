@@ -0,0 +1,59 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// MaxURLs is the most URLs the sitemap protocol allows in one sitemap
+const MaxURLs = 50000
+
+const namespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// URL is a page entry in a sitemap
+type URL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// Entry is a sitemap entry in a sitemap index
+type Entry struct {
+	Loc string `xml:"loc"`
+}
+
+type urlSet struct {
+	XMLName xml.Name `xml:"urlset"`
+	XMLNS   string   `xml:"xmlns,attr"`
+	URLs    []URL    `xml:"url"`
+}
+
+type index struct {
+	XMLName  xml.Name `xml:"sitemapindex"`
+	XMLNS    string   `xml:"xmlns,attr"`
+	Sitemaps []Entry  `xml:"sitemap"`
+}
+
+// LastMod formats a modification time as a sitemap lastmod value
+func LastMod(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// WriteURLSet writes a sitemap listing urls
+func WriteURLSet(w io.Writer, urls []URL) error {
+	return write(w, urlSet{XMLNS: namespace, URLs: urls})
+}
+
+// WriteIndex writes a sitemap index listing sitemaps
+func WriteIndex(w io.Writer, sitemaps []Entry) error {
+	return write(w, index{XMLNS: namespace, Sitemaps: sitemaps})
+}
+
+func write(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(v)
+}
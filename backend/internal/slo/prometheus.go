@@ -0,0 +1,144 @@
+package slo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the Prometheus text exposition format WritePrometheus
+// writes
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WritePrometheus writes the recorder's metrics in the Prometheus text
+// format:
+//
+//   - brewd_http_requests_total{group,route,method,status}, requests by
+//     response status
+//   - brewd_http_request_duration_seconds{group,route,method,status_class},
+//     a latency histogram with a bucket at the latency threshold
+//   - brewd_sli_requests_total, brewd_sli_errors_total and
+//     brewd_sli_slow_requests_total{group}, the availability and latency
+//     SLIs of each endpoint group
+//   - brewd_slo_availability_objective, brewd_slo_latency_objective and
+//     brewd_slo_latency_threshold_seconds, the objectives as ratios, so
+//     alert rules can compute burn rates without hardcoding them
+func (r *Recorder) WritePrometheus(w io.Writer) error {
+	var buf bytes.Buffer
+	r.write(&buf)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// write formats the metrics into buf, holding the lock so they're
+// consistent, without waiting on the client
+func (r *Recorder) write(buf *bytes.Buffer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	requests := make([]requestKey, 0, len(r.requests))
+	for k := range r.requests {
+		requests = append(requests, k)
+	}
+	latencies := make([]latencyKey, 0, len(r.latency))
+	for k := range r.latency {
+		latencies = append(latencies, k)
+	}
+	groups := make([]string, 0, len(r.groups))
+	for g := range r.groups {
+		groups = append(groups, g)
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		a, b := requests[i], requests[j]
+		if a.route != b.route {
+			return a.route < b.route
+		}
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		return a.status < b.status
+	})
+	sort.Slice(latencies, func(i, j int) bool {
+		a, b := latencies[i], latencies[j]
+		if a.route != b.route {
+			return a.route < b.route
+		}
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		return a.class < b.class
+	})
+	sort.Strings(groups)
+
+	header(buf, "brewd_http_requests_total", "counter", "HTTP requests by route and response status.")
+	for _, k := range requests {
+		fmt.Fprintf(buf, "brewd_http_requests_total{%s} %d\n",
+			labels("group", k.group, "route", k.route, "method", k.method, "status", strconv.Itoa(k.status)),
+			r.requests[k])
+	}
+
+	header(buf, "brewd_http_request_duration_seconds", "histogram", "HTTP request latency by route and response status class.")
+	for _, k := range latencies {
+		h := r.latency[k]
+		base := []string{"group", k.group, "route", k.route, "method", k.method, "status_class", k.class}
+		for i, le := range r.buckets {
+			fmt.Fprintf(buf, "brewd_http_request_duration_seconds_bucket{%s} %d\n",
+				labels(append(base, "le", formatFloat(le))...), h.counts[i])
+		}
+		count := h.counts[len(r.buckets)]
+		fmt.Fprintf(buf, "brewd_http_request_duration_seconds_bucket{%s} %d\n", labels(append(base, "le", "+Inf")...), count)
+		fmt.Fprintf(buf, "brewd_http_request_duration_seconds_sum{%s} %s\n", labels(base...), formatFloat(h.sum))
+		fmt.Fprintf(buf, "brewd_http_request_duration_seconds_count{%s} %d\n", labels(base...), count)
+	}
+
+	header(buf, "brewd_sli_requests_total", "counter", "Requests counted towards the SLIs, by endpoint group.")
+	for _, g := range groups {
+		fmt.Fprintf(buf, "brewd_sli_requests_total{%s} %d\n", labels("group", g), r.groups[g].requests)
+	}
+	header(buf, "brewd_sli_errors_total", "counter", "Requests that failed with a 5xx, by endpoint group.")
+	for _, g := range groups {
+		fmt.Fprintf(buf, "brewd_sli_errors_total{%s} %d\n", labels("group", g), r.groups[g].errors)
+	}
+	header(buf, "brewd_sli_slow_requests_total", "counter", "Requests slower than the latency threshold, by endpoint group.")
+	for _, g := range groups {
+		fmt.Fprintf(buf, "brewd_sli_slow_requests_total{%s} %d\n", labels("group", g), r.groups[g].slow)
+	}
+
+	header(buf, "brewd_slo_availability_objective", "gauge", "Share of requests that must not fail.")
+	fmt.Fprintf(buf, "brewd_slo_availability_objective %s\n", formatFloat(r.objectives.Availability/100))
+	header(buf, "brewd_slo_latency_objective", "gauge", "Share of requests that must be answered within the latency threshold.")
+	fmt.Fprintf(buf, "brewd_slo_latency_objective %s\n", formatFloat(r.objectives.Latency/100))
+	header(buf, "brewd_slo_latency_threshold_seconds", "gauge", "How fast a request must be answered to count as fast.")
+	fmt.Fprintf(buf, "brewd_slo_latency_threshold_seconds %s\n", formatFloat(r.objectives.LatencyThreshold.Seconds()))
+}
+
+// header writes a metric's HELP and TYPE lines
+func header(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// labels formats name, value pairs as a label set, escaping the values
+func labels(pairs ...string) string {
+	var b strings.Builder
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(pairs[i])
+		b.WriteString(`="`)
+		b.WriteString(labelEscaper.Replace(pairs[i+1]))
+		b.WriteByte('"')
+	}
+	return b.String()
+}
+
+// labelEscaper escapes a label value as the text format requires
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatFloat formats v as the text format expects
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,253 @@
+package slo
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// windowMinutes is how far back the admin summary looks, in one-minute
+// slots
+const windowMinutes = 60
+
+// Window is the period the admin summary covers
+const Window = windowMinutes * time.Minute
+
+// GroupUnmatched is the endpoint group of requests no route matched
+const GroupUnmatched = "unmatched"
+
+// latencyBuckets are the latency histogram's upper bounds, in seconds. The
+// latency objective's threshold is added, so alerts can read its bucket.
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// methods are the request methods labelled by name; others, which only
+// unmatched requests can have, are labelled OTHER so labels stay few
+var methods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+// Objectives are the service level objectives every endpoint group is held
+// to. A request is good for availability unless it fails with a 5xx, and
+// for latency when it is answered within LatencyThreshold.
+type Objectives struct {
+	Availability     float64       // Percent of requests that must not fail
+	LatencyThreshold time.Duration // How fast a request must be answered
+	Latency          float64       // Percent of requests that must be that fast
+}
+
+// requestKey labels a request counter
+type requestKey struct {
+	group, route, method string
+	status               int
+}
+
+// latencyKey labels a latency histogram. Statuses are grouped by class, so
+// histograms stay few.
+type latencyKey struct {
+	group, route, method, class string
+}
+
+// histogram counts requests into latency buckets. counts[i] is the number
+// no slower than buckets[i]; the last counts all.
+type histogram struct {
+	counts []int64
+	sum    float64
+}
+
+// slot is one minute's tally of a group's requests
+type slot struct {
+	minute   int64
+	requests int64
+	errors   int64
+	slow     int64
+}
+
+// groupTally is a group's requests since the recorder started, and over the
+// last windowMinutes
+type groupTally struct {
+	requests int64
+	errors   int64
+	slow     int64
+	window   [windowMinutes]slot
+}
+
+// Recorder measures this instance's requests against the objectives, as
+// Prometheus metrics and as a summary per endpoint group
+type Recorder struct {
+	objectives Objectives
+	buckets    []float64
+
+	mu       sync.Mutex
+	requests map[requestKey]int64
+	latency  map[latencyKey]*histogram
+	groups   map[string]*groupTally
+}
+
+// NewRecorder returns a recorder holding requests to objectives
+func NewRecorder(objectives Objectives) *Recorder {
+	buckets := latencyBuckets
+	if threshold := objectives.LatencyThreshold.Seconds(); threshold > 0 && !slices.Contains(buckets, threshold) {
+		buckets = append(slices.Clone(buckets), threshold)
+		slices.Sort(buckets)
+	}
+
+	return &Recorder{
+		objectives: objectives,
+		buckets:    buckets,
+		requests:   make(map[requestKey]int64),
+		latency:    make(map[latencyKey]*histogram),
+		groups:     make(map[string]*groupTally),
+	}
+}
+
+// Objectives returns the objectives requests are held to
+func (r *Recorder) Objectives() Objectives {
+	return r.objectives
+}
+
+// Observe records a request to route, the pattern it matched (empty when
+// none did), answered with status after took
+func (r *Recorder) Observe(route, method string, status int, took time.Duration) {
+	group := Group(route)
+	if route == "" {
+		route = GroupUnmatched
+	}
+	if !slices.Contains(methods, method) {
+		method = "OTHER"
+	}
+	failed := status >= 500
+	slow := took > r.objectives.LatencyThreshold
+	seconds := took.Seconds()
+	minute := time.Now().Unix() / 60
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.requests[requestKey{group: group, route: route, method: method, status: status}]++
+
+	lk := latencyKey{group: group, route: route, method: method, class: statusClass(status)}
+	h, ok := r.latency[lk]
+	if !ok {
+		h = &histogram{counts: make([]int64, len(r.buckets)+1)}
+		r.latency[lk] = h
+	}
+	for i, le := range r.buckets {
+		if seconds <= le {
+			h.counts[i]++
+		}
+	}
+	h.counts[len(r.buckets)]++
+	h.sum += seconds
+
+	tally, ok := r.groups[group]
+	if !ok {
+		tally = &groupTally{}
+		r.groups[group] = tally
+	}
+	s := &tally.window[minute%windowMinutes]
+	if s.minute != minute {
+		*s = slot{minute: minute}
+	}
+	tally.requests++
+	s.requests++
+	if failed {
+		tally.errors++
+		s.errors++
+	}
+	if slow {
+		tally.slow++
+		s.slow++
+	}
+}
+
+// GroupSummary is an endpoint group's requests over the window, with the
+// share that met each objective and the error budget left. A budget of 1
+// is untouched and 0 spent; it goes negative once overspent.
+type GroupSummary struct {
+	Group                  string
+	Requests               int64
+	Errors                 int64
+	Slow                   int64
+	SuccessRatio           float64
+	LatencyRatio           float64
+	AvailabilityBudgetLeft float64
+	LatencyBudgetLeft      float64
+}
+
+// Summary reports each endpoint group's requests over the last Window,
+// groups in name order
+func (r *Recorder) Summary() []GroupSummary {
+	minute := time.Now().Unix() / 60
+
+	r.mu.Lock()
+	summaries := make([]GroupSummary, 0, len(r.groups))
+	for group, tally := range r.groups {
+		s := GroupSummary{Group: group}
+		for _, slot := range tally.window {
+			if minute-slot.minute < windowMinutes {
+				s.Requests += slot.requests
+				s.Errors += slot.errors
+				s.Slow += slot.slow
+			}
+		}
+		summaries = append(summaries, s)
+	}
+	r.mu.Unlock()
+
+	for i := range summaries {
+		s := &summaries[i]
+		s.SuccessRatio = goodRatio(s.Requests, s.Errors)
+		s.LatencyRatio = goodRatio(s.Requests, s.Slow)
+		s.AvailabilityBudgetLeft = budgetLeft(s.SuccessRatio, r.objectives.Availability)
+		s.LatencyBudgetLeft = budgetLeft(s.LatencyRatio, r.objectives.Latency)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Group < summaries[j].Group })
+	return summaries
+}
+
+// Group is the endpoint group of route: its first path segment, after the
+// /api/v1 or /public/v1 prefix of versioned routes, e.g. brews for
+// /api/v1/brews/:id. Budgets are kept per group.
+func Group(route string) string {
+	if route == "" {
+		return GroupUnmatched
+	}
+	path := strings.TrimPrefix(route, "/")
+	if rest, ok := strings.CutPrefix(path, "api/v1/"); ok {
+		path = rest
+	} else if strings.HasPrefix(path, "public/v1") {
+		return "public"
+	}
+	segment, _, _ := strings.Cut(path, "/")
+	if segment == "" || strings.HasPrefix(segment, ":") {
+		return "root"
+	}
+	return segment
+}
+
+// statusClass is the class of status, e.g. 4xx
+func statusClass(status int) string {
+	return strconv.Itoa(status/100) + "xx"
+}
+
+// goodRatio is the share of requests that weren't bad; 1 without requests
+func goodRatio(requests, bad int64) float64 {
+	if requests == 0 {
+		return 1
+	}
+	return float64(requests-bad) / float64(requests)
+}
+
+// budgetLeft is the share of the error budget of an objective, in percent,
+// that a good ratio leaves
+func budgetLeft(good, objective float64) float64 {
+	budget := 1 - objective/100
+	if budget <= 0 {
+		if good < 1 {
+			return 0
+		}
+		return 1
+	}
+	return 1 - (1-good)/budget
+}
@@ -0,0 +1,165 @@
+package stats
+
+import (
+	"sort"
+	"time"
+
+	"brewd/internal/currency"
+)
+
+// Spend is the amount spent on beans in one month and currency. Month is
+// midnight UTC of the month's first day, as returned for a SQL DATE.
+type Spend struct {
+	Month      time.Time
+	Currency   string
+	SpentMinor int64
+	BagCount   int64
+}
+
+// BrewCost is the bean cost of the brews made in one month from bags priced
+// in one currency, in fractional minor units
+type BrewCost struct {
+	Month     time.Time
+	Currency  string
+	BrewCount int64
+	CostMinor float64
+}
+
+// CostMonth is one month of bean spending in one currency, in major units.
+// Amounts in different currencies are never converted or summed together.
+type CostMonth struct {
+	Month       string   `json:"month"` // YYYY-MM
+	Currency    string   `json:"currency"`
+	Spent       float64  `json:"spent"`
+	BagCount    int64    `json:"bag_count"`
+	BrewCount   int64    `json:"brew_count"`
+	BrewCost    float64  `json:"brew_cost"`
+	CostPerBrew *float64 `json:"cost_per_brew"`
+}
+
+// CostTotal sums a currency's months
+type CostTotal struct {
+	Currency    string   `json:"currency"`
+	Spent       float64  `json:"spent"`
+	BagCount    int64    `json:"bag_count"`
+	BrewCount   int64    `json:"brew_count"`
+	BrewCost    float64  `json:"brew_cost"`
+	CostPerBrew *float64 `json:"cost_per_brew"`
+}
+
+// CostSummary is a user's bean spending and cost per cup by month
+type CostSummary struct {
+	Months []CostMonth `json:"months"`
+	Totals []CostTotal `json:"totals"`
+}
+
+type costKey struct {
+	month    time.Time
+	currency string
+}
+
+// SummarizeCosts merges monthly spend and brew costs into one row per month
+// and currency (oldest first), plus per-currency totals. Rows in currencies
+// that are no longer supported are skipped.
+func SummarizeCosts(spend []Spend, brews []BrewCost) CostSummary {
+	type acc struct {
+		spentMinor, bagCount, brewCount int64
+		costMinor                       float64
+	}
+	months := make(map[costKey]*acc)
+	get := func(k costKey) *acc {
+		if months[k] == nil {
+			months[k] = &acc{}
+		}
+		return months[k]
+	}
+	for _, s := range spend {
+		a := get(costKey{s.Month.UTC(), s.Currency})
+		a.spentMinor += s.SpentMinor
+		a.bagCount += s.BagCount
+	}
+	for _, b := range brews {
+		a := get(costKey{b.Month.UTC(), b.Currency})
+		a.brewCount += b.BrewCount
+		a.costMinor += b.CostMinor
+	}
+
+	keys := make([]costKey, 0, len(months))
+	for k := range months {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].month.Equal(keys[j].month) {
+			return keys[i].month.Before(keys[j].month)
+		}
+		return keys[i].currency < keys[j].currency
+	})
+
+	summary := CostSummary{Months: []CostMonth{}, Totals: []CostTotal{}}
+	totals := make(map[string]*acc)
+	var order []string
+	for _, k := range keys {
+		cur, err := currency.Lookup(k.currency)
+		if err != nil {
+			continue
+		}
+		a := months[k]
+		summary.Months = append(summary.Months, CostMonth{
+			Month:       k.month.Format("2006-01"),
+			Currency:    cur.Code,
+			Spent:       cur.FromMinor(float64(a.spentMinor)),
+			BagCount:    a.bagCount,
+			BrewCount:   a.brewCount,
+			BrewCost:    cur.FromMinor(a.costMinor),
+			CostPerBrew: CostPerBrew(cur, a.costMinor, a.brewCount),
+		})
+
+		t := totals[cur.Code]
+		if t == nil {
+			t = &acc{}
+			totals[cur.Code] = t
+			order = append(order, cur.Code)
+		}
+		t.spentMinor += a.spentMinor
+		t.bagCount += a.bagCount
+		t.brewCount += a.brewCount
+		t.costMinor += a.costMinor
+	}
+
+	sort.Strings(order)
+	for _, code := range order {
+		cur, _ := currency.Lookup(code)
+		t := totals[code]
+		summary.Totals = append(summary.Totals, CostTotal{
+			Currency:    code,
+			Spent:       cur.FromMinor(float64(t.spentMinor)),
+			BagCount:    t.bagCount,
+			BrewCount:   t.brewCount,
+			BrewCost:    cur.FromMinor(t.costMinor),
+			CostPerBrew: CostPerBrew(cur, t.costMinor, t.brewCount),
+		})
+	}
+	return summary
+}
+
+// CostPerBrew is the average bean cost of brewCount brews in major units, or
+// nil when there are none
+func CostPerBrew(cur currency.Currency, costMinor float64, brewCount int64) *float64 {
+	if brewCount == 0 {
+		return nil
+	}
+	v := cur.FromMinor(costMinor / float64(brewCount))
+	return &v
+}
+
+// CostGroup is bean spending for one roaster (or origin) in one currency.
+// Label is nil for bags that don't name one.
+type CostGroup struct {
+	Label       *string  `json:"label"`
+	Currency    string   `json:"currency"`
+	BagCount    int64    `json:"bag_count"`
+	Spent       float64  `json:"spent"`
+	BrewCount   int64    `json:"brew_count"`
+	BrewCost    float64  `json:"brew_cost"`
+	CostPerBrew *float64 `json:"cost_per_brew"`
+}
@@ -0,0 +1,99 @@
+package stats
+
+import (
+	"errors"
+	"time"
+
+	// Embed the IANA database so timezone lookups don't depend on the host
+	_ "time/tzdata"
+)
+
+var ErrUnknownTimezone = errors.New("unknown timezone")
+
+// LoadLocation resolves an IANA timezone name. Empty and "Local" are rejected
+// so a user's boundaries never depend on the server's own zone.
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, ErrUnknownTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ErrUnknownTimezone
+	}
+	return loc, nil
+}
+
+// Day is the number of brews logged on one local calendar day. Date is
+// midnight UTC of that day, as returned for a SQL DATE.
+type Day struct {
+	Date  time.Time
+	Count int64
+}
+
+// Summary is a user's brewing activity bucketed by their local day boundaries
+type Summary struct {
+	Timezone      string `json:"timezone"`
+	TotalBrews    int64  `json:"total_brews"`
+	BrewsToday    int64  `json:"brews_today"`
+	BrewsThisWeek int64  `json:"brews_this_week"`
+	CurrentStreak int    `json:"current_streak"`
+	LongestStreak int    `json:"longest_streak"`
+	// Average brew times, with long-duration methods (cold brew) kept apart
+	AvgBrewTimeSeconds     float64 `json:"avg_brew_time_seconds"`
+	AvgLongBrewTimeSeconds float64 `json:"avg_long_brew_time_seconds"`
+}
+
+// Summarize computes totals and streaks from per-day counts (any order) as of
+// now in loc. Weeks start on Monday. A streak is a run of consecutive days
+// with at least one brew; the current streak is still alive if the last brew
+// was yesterday, so it doesn't reset until a full local day is missed.
+func Summarize(days []Day, now time.Time, loc *time.Location) Summary {
+	today := civilDate(now.In(loc))
+	weekStart := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+
+	s := Summary{Timezone: loc.String()}
+	active := make(map[time.Time]bool, len(days))
+	for _, d := range days {
+		date := civilDate(d.Date.UTC())
+		s.TotalBrews += d.Count
+		if date.Equal(today) {
+			s.BrewsToday += d.Count
+		}
+		if !date.Before(weekStart) && !date.After(today) {
+			s.BrewsThisWeek += d.Count
+		}
+		if d.Count > 0 {
+			active[date] = true
+		}
+	}
+
+	// Longest streak: walk forward from each day that starts a run
+	for date := range active {
+		if active[date.AddDate(0, 0, -1)] {
+			continue
+		}
+		n := 0
+		for d := date; active[d]; d = d.AddDate(0, 0, 1) {
+			n++
+		}
+		if n > s.LongestStreak {
+			s.LongestStreak = n
+		}
+	}
+
+	start := today
+	if !active[start] {
+		start = today.AddDate(0, 0, -1)
+	}
+	for d := start; active[d]; d = d.AddDate(0, 0, -1) {
+		s.CurrentStreak++
+	}
+
+	return s
+}
+
+// civilDate truncates t to midnight UTC of its calendar date in t's location
+func civilDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,145 @@
+package stepup
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+
+	"brewd/internal/mail"
+)
+
+// Methods a step-up challenge is answered with: a code emailed to the
+// user, or one from their authenticator app if they've enrolled TOTP
+const (
+	MethodEmail = "email"
+	MethodTOTP  = "totp"
+)
+
+// Reasons a login needs step-up: it looks suspicious, or the user enabled
+// TOTP and so asked for a second factor on every untrusted device
+const (
+	ReasonNewDevice   = "new_device"
+	ReasonNewLocation = "new_location"
+	ReasonFailures    = "failed_logins"
+	ReasonTwoFactor   = "two_factor"
+)
+
+// Challenge limits: a challenge expires after ChallengeTTL and allows
+// MaxAttempts codes, right or wrong
+const (
+	ChallengeTTL = 10 * time.Minute
+	MaxAttempts  = 5
+)
+
+// FailureWindow is how far back failed passwords and step-up codes count
+// toward ReasonFailures
+const FailureWindow = 24 * time.Hour
+
+// codeDigits is the length of an emailed code
+const codeDigits = 6
+
+// Risk is what's known about a login with a correct password. TwoFactor is
+// whether the user enabled TOTP.
+type Risk struct {
+	TwoFactor bool
+	// HasHistory is whether the user has registered or signed in before;
+	// without history nothing is new
+	HasHistory     bool
+	KnownDevice    bool
+	KnownLocation  bool
+	RecentFailures int
+}
+
+// Guard decides which logins need a step-up challenge and mails the
+// codes. A login is challenged when at least threshold of its suspicious
+// signals apply; a threshold of 0 turns that off. Users with TOTP are
+// challenged regardless.
+type Guard struct {
+	mailer    *mail.Mailer
+	threshold int
+	failures  int
+}
+
+// NewGuard returns a guard that challenges logins with at least threshold
+// reasons, counting failures or more recent failed attempts as one
+func NewGuard(mailer *mail.Mailer, threshold, failures int) *Guard {
+	return &Guard{mailer: mailer, threshold: threshold, failures: failures}
+}
+
+// Suspicion reports whether logins are checked for suspicious signals,
+// which needs the user's history
+func (g *Guard) Suspicion() bool {
+	return g.threshold > 0
+}
+
+// Assess returns why a login needs step-up and whether it does
+func (g *Guard) Assess(risk Risk) (reasons []string, required bool) {
+	if risk.TwoFactor {
+		return []string{ReasonTwoFactor}, true
+	}
+	if !g.Suspicion() {
+		return nil, false
+	}
+	if risk.HasHistory && !risk.KnownDevice {
+		reasons = append(reasons, ReasonNewDevice)
+	}
+	if risk.HasHistory && !risk.KnownLocation {
+		reasons = append(reasons, ReasonNewLocation)
+	}
+	if g.failures > 0 && risk.RecentFailures >= g.failures {
+		reasons = append(reasons, ReasonFailures)
+	}
+	return reasons, len(reasons) >= g.threshold
+}
+
+// SendCode emails a step-up code to the user
+func (g *Guard) SendCode(ctx context.Context, email, username, code string) error {
+	return g.mailer.Send(ctx, mail.Message{
+		To:      email,
+		Subject: "Your brewd sign-in code",
+		Text: fmt.Sprintf("Hi %s,\n\n"+
+			"Someone, hopefully you, is signing in to your brewd account from a new device or place. "+
+			"To finish signing in, enter this code:\n\n%s\n\n"+
+			"It expires in %d minutes. If this wasn't you, your password may be known to someone else: "+
+			"contact support so we can secure your account.\n",
+			username, code, int(ChallengeTTL.Minutes())),
+	})
+}
+
+// NewCode returns a random numeric code to email and the hash to store
+func NewCode() (code, hash string, err error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", "", err
+	}
+	code = fmt.Sprintf("%0*d", codeDigits, n)
+	return code, HashCode(code), nil
+}
+
+// HashCode returns the stored form of an emailed code
+func HashCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewDeviceToken returns a random trusted device token and the hash to
+// store
+func NewDeviceToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashDeviceToken(token), nil
+}
+
+// HashDeviceToken returns the stored form of a trusted device token
+func HashDeviceToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
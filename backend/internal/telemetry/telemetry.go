@@ -0,0 +1,99 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/logger"
+)
+
+// Event is a client analytics event: a screen view or use of a feature.
+// Properties is a JSON object chosen by the client.
+type Event struct {
+	ID         string          `json:"id"`
+	SessionID  *string         `json:"session_id"`
+	Name       string          `json:"name"`
+	Properties json.RawMessage `json:"properties"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// Sink receives a user's batch of analytics events. Implementations for
+// other backends (e.g. a message broker) plug in here.
+type Sink interface {
+	Write(ctx context.Context, userID string, events []Event) error
+}
+
+// Sink names accepted by NewSink
+const (
+	SinkDB   = "db"
+	SinkLog  = "log"
+	SinkNone = "none"
+)
+
+// NewSink returns the sink called name
+func NewSink(name string, queries *db.Queries) (Sink, error) {
+	switch name {
+	case SinkDB:
+		return DBSink{queries: queries}, nil
+	case SinkLog:
+		return LogSink{}, nil
+	case SinkNone:
+		return Discard{}, nil
+	default:
+		return nil, fmt.Errorf("unknown analytics sink %q", name)
+	}
+}
+
+// Sampled reports whether userID's events are kept at percent (0–100).
+// Users are sampled rather than events, so kept sessions are whole.
+func Sampled(userID string, percent int) bool {
+	if percent >= 100 {
+		return true
+	}
+	h := fnv.New32a()
+	h.Write([]byte(userID))
+	return int(h.Sum32()%100) < percent
+}
+
+// DBSink stores events in the analytics_event table
+type DBSink struct {
+	queries *db.Queries
+}
+
+func (s DBSink) Write(ctx context.Context, userID string, events []Event) error {
+	// Events are plain data, so they always encode
+	encoded, _ := json.Marshal(events)
+	return s.queries.InsertAnalyticsEvents(ctx, db.InsertAnalyticsEventsParams{
+		UserID: &userID,
+		Events: encoded,
+	})
+}
+
+// LogSink writes events to the application log, for development or for a
+// log pipeline to collect
+type LogSink struct{}
+
+func (LogSink) Write(_ context.Context, userID string, events []Event) error {
+	for _, e := range events {
+		logger.Info("Analytics event",
+			"event_id", e.ID,
+			"user_id", userID,
+			"session_id", e.SessionID,
+			"name", e.Name,
+			"properties", string(e.Properties),
+			"occurred_at", e.OccurredAt,
+		)
+	}
+	return nil
+}
+
+// Discard drops events, turning analytics off
+type Discard struct{}
+
+func (Discard) Write(context.Context, string, []Event) error {
+	return nil
+}
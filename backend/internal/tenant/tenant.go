@@ -0,0 +1,63 @@
+package tenant
+
+import "context"
+
+// Membership roles, from most to least privileged
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+// Tenant is the organization a request acts in, and the current user's role
+// in it. Queries on an organization's resources take OrganizationID from
+// the tenant, never from the request, so a request can't reach past the
+// organization its membership was checked for.
+type Tenant struct {
+	OrganizationID string
+	Role           string
+}
+
+type tenantKey struct{}
+
+// WithTenant returns ctx carrying t
+func WithTenant(ctx context.Context, t Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey{}, t)
+}
+
+// FromContext returns the tenant ctx carries, if any
+func FromContext(ctx context.Context) (Tenant, bool) {
+	t, ok := ctx.Value(tenantKey{}).(Tenant)
+	return t, ok
+}
+
+// rank orders roles by privilege; non-members rank lowest
+func rank(role string) int {
+	switch role {
+	case RoleOwner:
+		return 3
+	case RoleAdmin:
+		return 2
+	case RoleMember:
+		return 1
+	}
+	return 0
+}
+
+// CanManage reports whether role can edit the organization and manage its
+// members
+func CanManage(role string) bool {
+	return rank(role) >= rank(RoleAdmin)
+}
+
+// CanGrant reports whether actor may give someone role: owners grant any
+// role, admins only membership
+func CanGrant(actor, role string) bool {
+	return actor == RoleOwner || (CanManage(actor) && rank(role) < rank(actor))
+}
+
+// CanRemove reports whether actor may remove a member with target's role:
+// owners remove anyone, others only those they outrank
+func CanRemove(actor, target string) bool {
+	return actor == RoleOwner || rank(actor) > rank(target)
+}
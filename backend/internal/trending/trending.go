@@ -0,0 +1,66 @@
+package trending
+
+import (
+	"context"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/lease"
+	"brewd/internal/logger"
+)
+
+// Periods are the rolling windows trending is ranked over, by name
+var Periods = map[string]time.Duration{
+	"day":   24 * time.Hour,
+	"week":  7 * 24 * time.Hour,
+	"month": 30 * 24 * time.Hour,
+}
+
+// DefaultPeriod is the period discovery shows unless asked for another
+const DefaultPeriod = "week"
+
+// Size is how many recipes and beans are kept per period
+const Size = 50
+
+// Run ranks trending recipes and beans for every period now and then every
+// interval until ctx is cancelled
+func Run(ctx context.Context, queries *db.Queries, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		refresh(ctx, queries, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh replaces each period's rankings. A period that fails keeps its
+// previous rankings until the next run.
+func refresh(ctx context.Context, queries *db.Queries, now time.Time) {
+	for period, window := range Periods {
+		if err := lease.Check(ctx); err != nil {
+			logger.Warn("Stopped ranking trending recipes and beans", "error", err)
+			return
+		}
+		since := now.Add(-window)
+		if err := queries.RefreshTrendingRecipes(ctx, db.RefreshTrendingRecipesParams{
+			Period:   period,
+			Since:    since,
+			RowLimit: Size,
+		}); err != nil {
+			logger.Error("Failed to rank trending recipes", "period", period, "error", err)
+		}
+		if err := queries.RefreshTrendingBeans(ctx, db.RefreshTrendingBeansParams{
+			Period:   period,
+			Since:    since,
+			RowLimit: Size,
+		}); err != nil {
+			logger.Error("Failed to rank trending beans", "period", period, "error", err)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package units
+
+import (
+	"errors"
+	"math"
+)
+
+var ErrUnknownSystem = errors.New("unknown unit system")
+
+// WeightUnit is the unit used for dose and water weights
+type WeightUnit string
+
+// TemperatureUnit is the unit used for water temperature
+type TemperatureUnit string
+
+const (
+	Grams  WeightUnit = "g"
+	Ounces WeightUnit = "oz"
+
+	Celsius    TemperatureUnit = "c"
+	Fahrenheit TemperatureUnit = "f"
+)
+
+const gramsPerOunce = 28.349523125
+
+// Preference is the pair of units a user reads and writes brew values in.
+// Values are always stored canonically in grams and degrees Celsius.
+type Preference struct {
+	Weight      WeightUnit      `json:"weight"`
+	Temperature TemperatureUnit `json:"temperature"`
+}
+
+var (
+	Metric   = Preference{Weight: Grams, Temperature: Celsius}
+	Imperial = Preference{Weight: Ounces, Temperature: Fahrenheit}
+)
+
+// ParseSystem resolves a named unit system ("metric" or "imperial")
+func ParseSystem(system string) (Preference, error) {
+	switch system {
+	case "metric":
+		return Metric, nil
+	case "imperial":
+		return Imperial, nil
+	default:
+		return Preference{}, ErrUnknownSystem
+	}
+}
+
+// ToGrams converts a weight in unit to canonical grams
+func ToGrams(value float64, unit WeightUnit) float64 {
+	if unit == Ounces {
+		return round(value*gramsPerOunce, 2)
+	}
+	return value
+}
+
+// FromGrams converts canonical grams to unit for display
+func FromGrams(grams float64, unit WeightUnit) float64 {
+	if unit == Ounces {
+		return round(grams/gramsPerOunce, 2)
+	}
+	return round(grams, 1)
+}
+
+// ToCelsius converts a temperature in unit to canonical degrees Celsius
+func ToCelsius(value float64, unit TemperatureUnit) float64 {
+	if unit == Fahrenheit {
+		return round((value-32)*5/9, 2)
+	}
+	return value
+}
+
+// FromCelsius converts canonical degrees Celsius to unit for display
+func FromCelsius(celsius float64, unit TemperatureUnit) float64 {
+	if unit == Fahrenheit {
+		return round(celsius*9/5+32, 1)
+	}
+	return round(celsius, 1)
+}
+
+// round rounds value to the given number of decimal places
+func round(value float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(value*p) / p
+}
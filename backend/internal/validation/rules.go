@@ -0,0 +1,189 @@
+package validation
+
+import (
+	"reflect"
+	"regexp"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	"github.com/oklog/ulid/v2"
+)
+
+// Brew ratios are water per unit of coffee, in the same unit: from a
+// ristretto to a weak batch brew
+const (
+	MinBrewRatio = 1
+	MaxBrewRatio = 30
+)
+
+// grindSettingPattern admits the ways grinders label settings: numbers,
+// clicks and ranges ("18", "2.1.0", "12 clicks", "5-6") and named settings
+// ("medium-fine", "#3 (Comandante)")
+var grindSettingPattern = regexp.MustCompile(`^[\p{L}\p{N}#(]([\p{L}\p{N} .,:/+#()'-]*[\p{L}\p{N}.)'])?$`)
+
+// eventNamePattern admits analytics event names: lowercase snake_case words
+// separated by dots ("screen_view", "timer.started")
+var eventNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z][a-z0-9_]*)*$`)
+
+// rule is a custom validation tag with its message in each language. {0}
+// in a message is the field name and {1} the tag's parameter.
+type rule struct {
+	fn       validator.Func
+	messages map[string]string
+}
+
+var rules = map[string]rule{
+	// brew_ratio=dose on the water field checks water against the dose, the
+	// sibling field with that client-facing name, both in the caller's
+	// weight unit. Either being unset passes.
+	"brew_ratio": {
+		fn: brewRatio,
+		messages: map[string]string{
+			"en": "{0} must be between 1 and 30 times the dose",
+			"es": "{0} debe estar entre 1 y 30 veces la dosis",
+			"fr": "{0} doit être entre 1 et 30 fois la dose",
+		},
+	},
+	// temperature=c and temperature=f bound a water temperature between
+	// freezing and boiling in that unit. Without a unit the value is in the
+	// caller's unit, so only the bounds common to both are checked; the
+	// exact range is checked once converted.
+	"temperature": {
+		fn: temperature,
+		messages: map[string]string{
+			"en": "{0} must be between freezing and boiling",
+			"es": "{0} debe estar entre el punto de congelación y el de ebullición",
+			"fr": "{0} doit être entre le point de congélation et le point d'ébullition",
+		},
+	},
+	"grind_setting": {
+		fn: grindSetting,
+		messages: map[string]string{
+			"en": "{0} may only contain letters, numbers, spaces and . , : / + - # ( ) '",
+			"es": "{0} solo puede contener letras, números, espacios y . , : / + - # ( ) '",
+			"fr": "{0} ne peut contenir que des lettres, des chiffres, des espaces et . , : / + - # ( ) '",
+		},
+	},
+	"event_name": {
+		fn: eventName,
+		messages: map[string]string{
+			"en": "{0} must be lowercase words separated by _ or .",
+			"es": "{0} debe ser palabras en minúsculas separadas por _ o .",
+			"fr": "{0} doit être des mots en minuscules séparés par _ ou .",
+		},
+	},
+	"ulid": {
+		fn: isULID,
+		messages: map[string]string{
+			"en": "{0} must be a valid ID",
+			"es": "{0} debe ser un ID válido",
+			"fr": "{0} doit être un identifiant valide",
+		},
+	},
+}
+
+// typeMessages are shown for a value of the wrong JSON type; {1} is the
+// expected type
+var typeMessages = map[string]string{
+	"en": "{0} must be a JSON {1}",
+	"es": "{0} debe ser de tipo JSON {1}",
+	"fr": "{0} doit être de type JSON {1}",
+}
+
+func brewRatio(fl validator.FieldLevel) bool {
+	water, ok := floatValue(fl.Field())
+	if !ok {
+		return true
+	}
+	dose, ok := floatValue(siblingField(fl.Parent(), fl.Param()))
+	if !ok || dose <= 0 {
+		return true
+	}
+	ratio := water / dose
+	return ratio >= MinBrewRatio && ratio <= MaxBrewRatio
+}
+
+func temperature(fl validator.FieldLevel) bool {
+	v, ok := floatValue(fl.Field())
+	if !ok {
+		return true
+	}
+	switch fl.Param() {
+	case "c":
+		return v >= 0 && v <= 100
+	case "f":
+		return v >= 32 && v <= 212
+	default:
+		return v >= 0 && v <= 212
+	}
+}
+
+func grindSetting(fl validator.FieldLevel) bool {
+	return grindSettingPattern.MatchString(fl.Field().String())
+}
+
+func eventName(fl validator.FieldLevel) bool {
+	return eventNamePattern.MatchString(fl.Field().String())
+}
+
+func isULID(fl validator.FieldLevel) bool {
+	_, err := ulid.ParseStrict(fl.Field().String())
+	return err == nil
+}
+
+// siblingField finds the field of parent that clients know as name
+func siblingField(parent reflect.Value, name string) reflect.Value {
+	for parent.Kind() == reflect.Pointer && !parent.IsNil() {
+		parent = parent.Elem()
+	}
+	if parent.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	for i := 0; i < parent.NumField(); i++ {
+		if fieldName(parent.Type().Field(i)) == name {
+			return parent.Field(i)
+		}
+	}
+	return reflect.Value{}
+}
+
+// floatValue reads a number, dereferencing pointers; nil and invalid values
+// report false
+func floatValue(v reflect.Value) (float64, bool) {
+	for v.IsValid() && v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return 0, false
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return 0, false
+	}
+	switch {
+	case v.CanFloat():
+		return v.Float(), true
+	case v.CanInt():
+		return float64(v.Int()), true
+	}
+	return 0, false
+}
+
+// registerRuleTranslations adds the custom rules' messages in locale
+func registerRuleTranslations(v *validator.Validate, trans ut.Translator, locale string) error {
+	for tag, r := range rules {
+		msg := r.messages[locale]
+		err := v.RegisterTranslation(tag, trans,
+			func(ut ut.Translator) error {
+				return ut.Add(tag, msg, true)
+			},
+			func(ut ut.Translator, fe validator.FieldError) string {
+				t, _ := ut.T(fe.Tag(), fe.Field(), fe.Param())
+				return t
+			},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return trans.Add(RuleType, typeMessages[locale], true)
+}
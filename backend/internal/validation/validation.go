@@ -0,0 +1,152 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/fr"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	es_translations "github.com/go-playground/validator/v10/translations/es"
+	fr_translations "github.com/go-playground/validator/v10/translations/fr"
+	"golang.org/x/text/language"
+)
+
+var universal = ut.New(en.New(), en.New(), es.New(), fr.New())
+
+// FieldError is one rejected request field: the client-facing field name,
+// the rule it broke with the rule's parameter, and a localized message
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// RuleType is the rule reported for a value of the wrong JSON type
+const RuleType = "type"
+
+// Register installs the custom rules and localized messages on gin's
+// binding validator, and reports fields by their JSON/query names instead
+// of Go struct field names
+func Register() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("unexpected binding validator engine")
+	}
+
+	v.RegisterTagNameFunc(fieldName)
+
+	for tag, rule := range rules {
+		if err := v.RegisterValidation(tag, rule.fn); err != nil {
+			return err
+		}
+	}
+
+	registrations := map[string]func(*validator.Validate, ut.Translator) error{
+		"en": en_translations.RegisterDefaultTranslations,
+		"es": es_translations.RegisterDefaultTranslations,
+		"fr": fr_translations.RegisterDefaultTranslations,
+	}
+	for locale, register := range registrations {
+		trans, _ := universal.GetTranslator(locale)
+		if err := register(v, trans); err != nil {
+			return err
+		}
+		if err := registerRuleTranslations(v, trans, locale); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Errors converts a binding error into localized per-field errors, in the
+// order the fields failed. Errors that don't concern a field (e.g.
+// malformed JSON) return nil.
+func Errors(tag language.Tag, err error) []FieldError {
+	base, _ := tag.Base()
+	trans, _ := universal.GetTranslator(base.String())
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		expected := jsonType(typeErr.Type)
+		msg, terr := trans.T(RuleType, typeErr.Field, expected)
+		if terr != nil {
+			msg = typeErr.Field + ": " + expected
+		}
+		return []FieldError{{Field: typeErr.Field, Rule: RuleType, Param: expected, Message: msg}}
+	}
+
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return nil
+	}
+
+	fieldErrs := make([]FieldError, 0, len(verrs))
+	for _, fe := range verrs {
+		fieldErrs = append(fieldErrs, FieldError{
+			Field:   fe.Field(),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: fe.Translate(trans),
+		})
+	}
+	return fieldErrs
+}
+
+// Details converts a binding error into localized messages keyed by field.
+// Errors that don't concern a field return nil.
+func Details(tag language.Tag, err error) map[string]string {
+	fieldErrs := Errors(tag, err)
+	if fieldErrs == nil {
+		return nil
+	}
+	details := make(map[string]string, len(fieldErrs))
+	for _, fe := range fieldErrs {
+		details[fe.Field] = fe.Message
+	}
+	return details
+}
+
+// fieldName returns the name clients use for a struct field
+func fieldName(fld reflect.StructField) string {
+	for _, key := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(fld.Tag.Get(key), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return fld.Name
+}
+
+// jsonType names the JSON type a Go type decodes from
+func jsonType(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		if t.String() == "time.Time" {
+			return "string"
+		}
+		return "object"
+	default:
+		return "number"
+	}
+}
@@ -0,0 +1,99 @@
+package vclock
+
+import (
+	"encoding/json"
+	"maps"
+)
+
+// Server is the replica name the server counts its own changes under
+const Server = "server"
+
+// Vector is a record's revision vector: how many changes each replica (the
+// server, or a client device by its replica ID) has made to it. A replica
+// bumps its own counter for every change it makes.
+type Vector map[string]int64
+
+// Order is how two vectors relate
+type Order int
+
+const (
+	// Equal vectors describe the same revision
+	Equal Order = iota
+	// Before means the first vector's revision is an ancestor of the second's
+	Before
+	// After means the first vector's revision descends from the second's
+	After
+	// Concurrent vectors were changed independently: a conflict
+	Concurrent
+)
+
+// Compare orders a against b
+func Compare(a, b Vector) Order {
+	less, greater := false, false
+	for replica, n := range a {
+		switch m := b[replica]; {
+		case n < m:
+			less = true
+		case n > m:
+			greater = true
+		}
+	}
+	for replica, m := range b {
+		if _, ok := a[replica]; !ok && m > 0 {
+			less = true
+		}
+	}
+
+	switch {
+	case less && greater:
+		return Concurrent
+	case less:
+		return Before
+	case greater:
+		return After
+	}
+	return Equal
+}
+
+// Merge returns the smallest vector that descends from both a and b
+func Merge(a, b Vector) Vector {
+	merged := maps.Clone(a)
+	if merged == nil {
+		merged = Vector{}
+	}
+	for replica, n := range b {
+		if n > merged[replica] {
+			merged[replica] = n
+		}
+	}
+	return merged
+}
+
+// Bump returns v with one more change counted for replica
+func (v Vector) Bump(replica string) Vector {
+	bumped := maps.Clone(v)
+	if bumped == nil {
+		bumped = Vector{}
+	}
+	bumped[replica]++
+	return bumped
+}
+
+// Decode parses a stored vector; anything unreadable is the empty vector
+func Decode(stored []byte) Vector {
+	v := Vector{}
+	if len(stored) > 0 {
+		_ = json.Unmarshal(stored, &v)
+	}
+	return v
+}
+
+// Encode returns v for storage
+func (v Vector) Encode() []byte {
+	if v == nil {
+		return []byte("{}")
+	}
+	// A map of strings to integers always encodes
+	encoded, _ := json.Marshal(v)
+	return encoded
+}
@@ -0,0 +1,98 @@
+package waitlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"brewd/internal/db"
+	"brewd/internal/invites"
+	"brewd/internal/lease"
+	"brewd/internal/links"
+	"brewd/internal/logger"
+	"brewd/internal/mail"
+)
+
+// batchSize bounds how many invitation emails a single poll sends
+const batchSize = 100
+
+// maxAttempts is how many times an invitation email is tried before the
+// entry is left for an admin to look at
+const maxAttempts = 5
+
+// InviteValidity is how long the invite an approved entry gets stays
+// redeemable
+const InviteValidity = 30 * 24 * time.Hour
+
+// Run mails the invites of approved waitlist entries every interval until
+// ctx is cancelled
+func Run(ctx context.Context, queries *db.Queries, mailer *mail.Mailer, site *links.Site, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := sendDue(ctx, queries, mailer, site); err != nil {
+				logger.Error("Failed to send waitlist invites", "error", err)
+			}
+		}
+	}
+}
+
+// sendDue sends the invitation email of each approved entry that hasn't
+// had one. A failed send is recorded and retried on the next run.
+func sendDue(ctx context.Context, queries *db.Queries, mailer *mail.Mailer, site *links.Site) error {
+	due, err := queries.ListUnsentWaitlistInvites(ctx, db.ListUnsentWaitlistInvitesParams{
+		MaxAttempts: maxAttempts,
+		RowLimit:    batchSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	sent := 0
+	for _, entry := range due {
+		if err := lease.Check(ctx); err != nil {
+			return err
+		}
+		err := mailer.Send(ctx, invitation(site, entry.Email, *entry.InviteCode))
+		if err != nil {
+			if !errors.Is(err, mail.ErrSuppressed) {
+				logger.Warn("Failed to send waitlist invite", "error", err)
+			}
+			msg := err.Error()
+			if err := queries.RecordWaitlistInviteFailure(ctx, db.RecordWaitlistInviteFailureParams{
+				Email: entry.Email,
+				Error: &msg,
+			}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := queries.MarkWaitlistInviteSent(ctx, entry.Email); err != nil {
+			return err
+		}
+		sent++
+	}
+	if sent > 0 {
+		logger.Info("Sent waitlist invites", "count", sent)
+	}
+	return nil
+}
+
+// invitation is the email inviting a waitlisted address to sign up
+func invitation(site *links.Site, email, code string) mail.Message {
+	return mail.Message{
+		To:      email,
+		Subject: "You're off the brewd waitlist",
+		Text: fmt.Sprintf("Good news: your spot on the brewd waitlist came up.\n\n"+
+			"Create your account here:\n%s\n\n"+
+			"Or enter invite code %s when you sign up. The invite is valid for %d days.\n",
+			invites.URL(site, code), code, int(InviteValidity.Hours()/24)),
+	}
+}
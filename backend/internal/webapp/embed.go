@@ -0,0 +1,12 @@
+//go:build embedweb
+
+package webapp
+
+import "embed"
+
+// dist is the web app's export, copied here by build.sh
+//
+//go:embed all:dist
+var dist embed.FS
+
+const embedded = true
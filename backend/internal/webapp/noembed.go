@@ -0,0 +1,10 @@
+//go:build !embedweb
+
+package webapp
+
+import "embed"
+
+// dist is empty without the embedweb tag
+var dist embed.FS
+
+const embedded = false
@@ -0,0 +1,83 @@
+package webapp
+
+import (
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Cache lifetimes of the web app's files. Hashed files never change under
+// their name; pages are revalidated so a deploy reaches clients at once.
+const (
+	CacheImmutable = "public, max-age=31536000, immutable"
+	CacheAsset     = "public, max-age=3600"
+	CachePage      = "no-cache"
+)
+
+// encoding is a precompressed variant cmd/webassets writes beside a file
+type encoding struct {
+	name string // Content-Encoding token
+	ext  string // Suffix of the variant's file name
+}
+
+// encodings are the precompressed variants, by preference
+var encodings = []encoding{{"br", ".br"}, {"gzip", ".gz"}}
+
+// hashedName matches file names carrying a content hash, e.g.
+// icon.3f2a9c1d0b7e4a56.png or Expo's entry-<hash>.js
+var hashedName = regexp.MustCompile(`[.-][0-9a-f]{8,}\.[A-Za-z0-9]+$`)
+
+// Hashed reports whether name carries a content hash, so it can be cached
+// forever
+func Hashed(name string) bool {
+	return hashedName.MatchString(path.Base(name))
+}
+
+// Exists reports whether name is a regular file in files
+func Exists(files fs.FS, name string) bool {
+	info, err := fs.Stat(files, name)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// Serve writes name from files with cacheControl, as its precompressed
+// variant when the client accepts that encoding
+func Serve(w http.ResponseWriter, r *http.Request, files fs.FS, name, cacheControl string) {
+	h := w.Header()
+	h.Set("Cache-Control", cacheControl)
+	h.Add("Vary", "Accept-Encoding")
+
+	// A variant's type can't be sniffed from its compressed bytes, so only
+	// files of a known type are sent compressed
+	if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+		h.Set("Content-Type", ctype)
+		for _, enc := range encodings {
+			if accepts(r, enc.name) && Exists(files, name+enc.ext) {
+				h.Set("Content-Encoding", enc.name)
+				http.ServeFileFS(w, r, files, name+enc.ext)
+				return
+			}
+		}
+	}
+	http.ServeFileFS(w, r, files, name)
+}
+
+// accepts reports whether the request's Accept-Encoding allows coding
+func accepts(r *http.Request, coding string) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		token, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(token), coding) {
+			continue
+		}
+		q, found := strings.CutPrefix(strings.TrimSpace(params), "q=")
+		if !found {
+			return true
+		}
+		weight, err := strconv.ParseFloat(q, 64)
+		return err == nil && weight > 0
+	}
+	return false
+}
@@ -0,0 +1,21 @@
+// Package webapp holds the built web frontend, embedded into binaries built
+// with the embedweb tag so small deployments can ship the API and the web
+// app as one binary. build.sh with EMBED_WEB=1 exports the web app into
+// dist and builds with the tag.
+package webapp
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// ErrNotEmbedded is returned by FS for binaries built without the web app
+var ErrNotEmbedded = errors.New("the web app isn't embedded; build with -tags embedweb")
+
+// FS returns the embedded web app, rooted at its index.html
+func FS() (fs.FS, error) {
+	if !embedded {
+		return nil, ErrNotEmbedded
+	}
+	return fs.Sub(dist, "dist")
+}
@@ -15,26 +15,47 @@ var (
 	ErrDatabaseURLRequired  = fmt.Errorf("DATABASE_URL environment variable is required")
 	ErrInvalidDatabaseURL   = fmt.Errorf("failed to parse DATABASE_URL")
 	ErrInvalidPort          = fmt.Errorf("invalid port number")
+	ErrInvalidHost          = fmt.Errorf("host must be a name or address in printable ASCII")
 	ErrDatabaseNameRequired = fmt.Errorf("database name is required in URL")
 	ErrPasswordRequired     = fmt.Errorf("password is required in DATABASE_URL")
+	ErrInvalidSSLMode       = fmt.Errorf("sslmode must be disable, allow, prefer, require, verify-ca or verify-full")
+	ErrInvalidMinConns      = fmt.Errorf("DB_MIN_CONNS must be a number from 0 to the maximum connections")
+	ErrInvalidQueryRetries  = fmt.Errorf("DB_QUERY_RETRIES must be a non-negative number and DB_QUERY_RETRY_BACKOFF_MS one up to 60000")
+	ErrRowSecurityPgBouncer = fmt.Errorf("DB_ROW_SECURITY can't be used with DB_PGBOUNCER: settings don't stay with a pgbouncer client")
 )
 
+// sslModes are the sslmode values libpq and pgx accept
+var sslModes = map[string]bool{
+	"disable": true, "allow": true, "prefer": true, "require": true, "verify-ca": true, "verify-full": true,
+}
+
+// maxRetryBackoffMs bounds DB_QUERY_RETRY_BACKOFF_MS, whose doublings
+// would otherwise overflow a time.Duration
+const maxRetryBackoffMs = 60 * 1000
+
 // Config holds database connection configuration
 type Config struct {
-	Host            string        // Database host address
-	Port            int           // Database port number
-	Database        string        // Database name
-	Username        string        // Database username
-	Password        string        // Database password
-	MaxConns        int32         // Maximum number of live connections
-	MinConns        int32         // Minimum number of live connections
-	MaxConnLifetime time.Duration // Maximum lifetime of a single connection
-	MaxConnIdleTime time.Duration // Maximum idle time before connection closure
-	ConnectTimeout  time.Duration // Timeout for establishing connections
-	QueryTimeout    time.Duration // Timeout for individual queries
-	MaxRetries      int           // Maximum number of connection retry attempts
-	RetryInterval   time.Duration // Duration between retry attempts
-	SSLMode         string        // SSL mode (disable, prefer, require)
+	Host              string        // Database host address
+	Port              int           // Database port number
+	Database          string        // Database name
+	Username          string        // Database username
+	Password          string        // Database password
+	MaxConns          int32         // Maximum number of live connections
+	MinConns          int32         // Minimum number of live connections
+	MaxConnLifetime   time.Duration // Maximum lifetime of a single connection
+	MaxConnIdleTime   time.Duration // Maximum idle time before connection closure
+	ConnectTimeout    time.Duration // Timeout for establishing connections
+	QueryTimeout      time.Duration // Timeout for individual queries
+	MaxRetries        int           // Maximum number of connection retry attempts
+	RetryInterval     time.Duration // Duration between retry attempts
+	SSLMode           string        // SSL mode (disable, prefer, require)
+	WarmUp            bool          // Open MinConns connections and prime them before serving
+	PgBouncer         bool          // Use the simple protocol, for transaction-pooling pgbouncer
+	QueryTags         bool          // Comment queries with the application, route and request ID
+	RowSecurity       bool          // Set app.current_user_id on each connection for row security
+	ApplicationName   string        // Application named in query tags
+	QueryRetries      int           // Retries of a query failing with a transient error
+	QueryRetryBackoff time.Duration // Wait before the first retry, doubled for each after
 }
 
 // LoadConfigFromEnv loads database configuration from environment variables
@@ -56,12 +77,19 @@ func LoadConfigFromEnv() (*Config, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to split host:port: %w", err)
 	}
+	// ConnectionString can only write back a host in printable ASCII
+	if strings.ContainsFunc(host, func(r rune) bool { return r <= ' ' || r > '~' }) {
+		return nil, ErrInvalidHost
+	}
 
 	// Convert port from string to integer
 	portInt, err := strconv.Atoi(portStr)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrInvalidPort, err)
 	}
+	if portInt < 1 || portInt > 65535 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidPort, portInt)
+	}
 
 	// Parse database name from URL path
 	_, db, found := strings.Cut(u.Path, "/")
@@ -87,30 +115,79 @@ func LoadConfigFromEnv() (*Config, error) {
 			sslMode = "prefer"
 		}
 	}
+	if !sslModes[sslMode] {
+		return nil, ErrInvalidSSLMode
+	}
 
 	// Create configuration with parsed values and reasonable defaults
 	config := Config{
-		Host:            host,
-		Port:            portInt,
-		Database:        db,
-		Username:        username,
-		Password:        password,
-		MaxConns:        20,
-		MinConns:        5,
-		MaxConnLifetime: time.Minute * 60,
-		MaxConnIdleTime: time.Minute * 5,
-		ConnectTimeout:  time.Second * 30,
-		QueryTimeout:    time.Second * 30,
-		MaxRetries:      5,
-		RetryInterval:   time.Second * 10,
-		SSLMode:         sslMode,
+		Host:              host,
+		Port:              portInt,
+		Database:          db,
+		Username:          username,
+		Password:          password,
+		MaxConns:          20,
+		MinConns:          5,
+		MaxConnLifetime:   time.Minute * 60,
+		MaxConnIdleTime:   time.Minute * 5,
+		ConnectTimeout:    time.Second * 30,
+		QueryTimeout:      time.Second * 30,
+		MaxRetries:        5,
+		RetryInterval:     time.Second * 10,
+		SSLMode:           sslMode,
+		WarmUp:            strings.EqualFold(os.Getenv("DB_WARM_UP"), "true"),
+		PgBouncer:         strings.EqualFold(os.Getenv("DB_PGBOUNCER"), "true"),
+		QueryTags:         strings.EqualFold(os.Getenv("DB_QUERY_TAGS"), "true"),
+		RowSecurity:       strings.EqualFold(os.Getenv("DB_ROW_SECURITY"), "true"),
+		ApplicationName:   os.Getenv("DB_APPLICATION_NAME"),
+		QueryRetries:      2,
+		QueryRetryBackoff: 50 * time.Millisecond,
+	}
+	if config.ApplicationName == "" {
+		config.ApplicationName = DefaultApplicationName
+	}
+
+	// Override the minimum connections, which warm-up opens
+	if minConns := os.Getenv("DB_MIN_CONNS"); minConns != "" {
+		n, err := strconv.Atoi(minConns)
+		if err != nil || n < 0 || n > int(config.MaxConns) {
+			return nil, ErrInvalidMinConns
+		}
+		config.MinConns = int32(n)
+	}
+
+	// Override the retries of transient query errors
+	if retries := os.Getenv("DB_QUERY_RETRIES"); retries != "" {
+		n, err := strconv.Atoi(retries)
+		if err != nil || n < 0 {
+			return nil, ErrInvalidQueryRetries
+		}
+		config.QueryRetries = n
+	}
+	if backoff := os.Getenv("DB_QUERY_RETRY_BACKOFF_MS"); backoff != "" {
+		ms, err := strconv.Atoi(backoff)
+		if err != nil || ms < 0 || ms > maxRetryBackoffMs {
+			return nil, ErrInvalidQueryRetries
+		}
+		config.QueryRetryBackoff = time.Duration(ms) * time.Millisecond
+	}
+
+	if config.RowSecurity && config.PgBouncer {
+		return nil, ErrRowSecurityPgBouncer
 	}
 
 	return &config, nil
 }
 
-// ConnectionString returns the connection string for pgx
+// ConnectionString returns the connection string for pgx, escaping the
+// credentials and database name and bracketing an IPv6 host
 func (c *Config) ConnectionString() string {
-	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
-		c.Username, c.Password, c.Host, c.Port, c.Database, c.SSLMode)
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.Username, c.Password),
+		Host:     net.JoinHostPort(c.Host, strconv.Itoa(c.Port)),
+		Path:     "/" + c.Database,
+		RawQuery: url.Values{"sslmode": {c.SSLMode}}.Encode(),
+	}
+	return u.String()
 }
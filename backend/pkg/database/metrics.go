@@ -13,9 +13,10 @@ type Metrics struct {
 	ActiveConnections int64
 
 	// Query metrics
-	TotalQueries  int64
-	FailedQueries int64
-	QueryDuration int64 // nanoseconds
+	TotalQueries   int64
+	FailedQueries  int64
+	RetriedQueries int64 // retries of queries failing with transient errors
+	QueryDuration  int64 // nanoseconds
 
 	// Health check metrics
 	HealthChecks       int64
@@ -53,6 +54,11 @@ func (m *Metrics) IncrementFailedQueries() {
 	atomic.AddInt64(&m.FailedQueries, 1)
 }
 
+// IncrementRetriedQueries increments the retried queries counter
+func (m *Metrics) IncrementRetriedQueries() {
+	atomic.AddInt64(&m.RetriedQueries, 1)
+}
+
 // AddQueryDuration adds to the total query duration
 func (m *Metrics) AddQueryDuration(duration time.Duration) {
 	atomic.AddInt64(&m.QueryDuration, duration.Nanoseconds())
@@ -81,6 +87,7 @@ func (m *Metrics) GetMetrics() Metrics {
 		ActiveConnections:  atomic.LoadInt64(&m.ActiveConnections),
 		TotalQueries:       atomic.LoadInt64(&m.TotalQueries),
 		FailedQueries:      atomic.LoadInt64(&m.FailedQueries),
+		RetriedQueries:     atomic.LoadInt64(&m.RetriedQueries),
 		QueryDuration:      atomic.LoadInt64(&m.QueryDuration),
 		HealthChecks:       atomic.LoadInt64(&m.HealthChecks),
 		FailedHealthChecks: atomic.LoadInt64(&m.FailedHealthChecks),
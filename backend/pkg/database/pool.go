@@ -45,6 +45,38 @@ func NewPool(ctx context.Context, config *Config) (*Pool, error) {
 	pgxConfig.MaxConnIdleTime = config.MaxConnIdleTime
 	pgxConfig.ConnConfig.ConnectTimeout = config.ConnectTimeout
 
+	// Behind pgbouncer in transaction pooling mode, consecutive statements
+	// can run on different server connections, so statements prepared on
+	// one aren't there on the next. Send queries with the simple protocol
+	// instead. Tagged queries differ with every request, so caching their
+	// prepared statements would only churn the cache: send those in one
+	// round trip with an unnamed statement.
+	switch {
+	case config.PgBouncer:
+		pgxConfig.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+	case config.QueryTags:
+		pgxConfig.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeExec
+	}
+	if config.PgBouncer || config.QueryTags {
+		pgxConfig.ConnConfig.StatementCacheCapacity = 0
+		pgxConfig.ConnConfig.DescriptionCacheCapacity = 0
+
+		// Without a statement description pgx can't tell a parameter's
+		// type, and would encode []byte as bytea. The schema has no bytea
+		// columns; []byte parameters are all JSON, so send them as jsonb.
+		pgxConfig.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+			conn.TypeMap().RegisterDefaultPgType([]byte(nil), "jsonb")
+			return nil
+		}
+	}
+
+	// Row security policies act for the user in app.current_user_id, set
+	// on each connection as a query acquires it, at the cost of a round
+	// trip per acquire
+	if config.RowSecurity {
+		pgxConfig.PrepareConn = prepareRowSecurity
+	}
+
 	// Initialize metrics before attempting connection
 	metrics := NewMetrics()
 
@@ -88,6 +120,59 @@ func NewPool(ctx context.Context, config *Config) (*Pool, error) {
 	return customPool, nil
 }
 
+// warmUpQuery primes each connection opened by WarmUp
+const warmUpQuery = "SELECT 1"
+
+// WarmUp opens MinConns connections at once and runs a priming query on
+// each, so the first requests after a deploy don't wait for connections to
+// be established. It returns how many connections it primed.
+func (p *Pool) WarmUp(ctx context.Context) (int, error) {
+	n := int(p.config.MinConns)
+	conns := make([]*pgxpool.Conn, 0, n)
+	defer func() {
+		// Released together, so each priming acquires a new connection
+		// rather than reusing one another just released
+		for _, conn := range conns {
+			conn.Release()
+		}
+		p.updateActiveConnections()
+	}()
+
+	type acquired struct {
+		conn *pgxpool.Conn
+		err  error
+	}
+	results := make(chan acquired, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			conn, err := p.Pool.Acquire(ctx)
+			if err == nil {
+				if _, err = conn.Exec(ctx, warmUpQuery); err != nil {
+					conn.Release()
+					conn = nil
+				}
+			}
+			results <- acquired{conn: conn, err: err}
+		}()
+	}
+
+	var firstErr error
+	for i := 0; i < n; i++ {
+		result := <-results
+		if result.err != nil {
+			if firstErr == nil {
+				firstErr = result.err
+			}
+			continue
+		}
+		conns = append(conns, result.conn)
+	}
+	if firstErr != nil {
+		return len(conns), fmt.Errorf("warming up connection pool: %w", firstErr)
+	}
+	return len(conns), nil
+}
+
 // Close gracefully closes the connection pool
 func (p *Pool) Close() {
 	// Close the underlying pgxpool
@@ -112,12 +197,21 @@ func (p *Pool) updateActiveConnections() {
 	p.metrics.SetActiveConnections(int64(stats.AcquiredConns()))
 }
 
-// Query wraps pgxpool.Pool.Query with metrics tracking
+// Query wraps pgxpool.Pool.Query with metrics tracking, query tags and
+// retries of transient errors
 func (p *Pool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
 	start := time.Now()
 	p.metrics.IncrementQueries()
 
-	rows, err := p.Pool.Query(ctx, sql, args...)
+	// Only errors before rows are returned can be retried: once they're
+	// read, running the query again would repeat them
+	sql = p.tag(ctx, sql)
+	var rows pgx.Rows
+	err := p.retry(ctx, func() error {
+		var err error
+		rows, err = p.Pool.Query(ctx, sql, args...)
+		return err
+	})
 	duration := time.Since(start)
 	p.metrics.AddQueryDuration(duration)
 
@@ -129,12 +223,17 @@ func (p *Pool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, er
 	return rows, nil
 }
 
-// QueryRow wraps pgxpool.Pool.QueryRow with metrics tracking
+// QueryRow wraps pgxpool.Pool.QueryRow with metrics tracking, query tags and
+// retries of transient errors
 func (p *Pool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
 	start := time.Now()
 	p.metrics.IncrementQueries()
 
-	row := p.Pool.QueryRow(ctx, sql, args...)
+	sql = p.tag(ctx, sql)
+	var row pgx.Row = p.Pool.QueryRow(ctx, sql, args...)
+	if p.retryPolicy(ctx).Attempts > 0 {
+		row = &retryRow{p: p, ctx: ctx, sql: sql, args: args, row: row}
+	}
 	duration := time.Since(start)
 	p.metrics.AddQueryDuration(duration)
 
@@ -143,12 +242,19 @@ func (p *Pool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
 	return row
 }
 
-// Exec wraps pgxpool.Pool.Exec with metrics tracking
+// Exec wraps pgxpool.Pool.Exec with metrics tracking, query tags and
+// retries of transient errors
 func (p *Pool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
 	start := time.Now()
 	p.metrics.IncrementQueries()
 
-	tag, err := p.Pool.Exec(ctx, sql, args...)
+	sql = p.tag(ctx, sql)
+	var tag pgconn.CommandTag
+	err := p.retry(ctx, func() error {
+		var err error
+		tag, err = p.Pool.Exec(ctx, sql, args...)
+		return err
+	})
 	duration := time.Since(start)
 	p.metrics.AddQueryDuration(duration)
 
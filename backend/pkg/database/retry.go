@@ -0,0 +1,174 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrRetriesExhausted wraps a transient error that outlasted its retries
+var ErrRetriesExhausted = fmt.Errorf("transient query error")
+
+// SQLSTATEs of errors whose statement was rolled back and can run again
+const (
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+)
+
+// sqlStateClassConnection is the class of connection exceptions, such as
+// 08006 connection_failure
+const sqlStateClassConnection = "08"
+
+// RetryPolicy configures how a call retries transient errors
+type RetryPolicy struct {
+	// Attempts is how many times a call is retried after its first
+	// attempt; 0 disables retrying
+	Attempts int
+	// Backoff is the wait before the first retry, doubled for each after,
+	// with jitter
+	Backoff time.Duration
+	// Idempotent calls are also retried when the connection failed after
+	// the statement may have reached the server. Others are retried only
+	// when it certainly didn't run: a serialization failure or deadlock
+	// rolled it back, or the connection failed before it was sent.
+	Idempotent bool
+}
+
+type retryPolicyKey struct{}
+
+// WithRetryPolicy returns ctx carrying policy, which overrides the pool's
+// default for the queries sent with it
+func WithRetryPolicy(ctx context.Context, policy RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
+// RetryPolicyFromContext returns the policy ctx carries, if any
+func RetryPolicyFromContext(ctx context.Context) (RetryPolicy, bool) {
+	policy, ok := ctx.Value(retryPolicyKey{}).(RetryPolicy)
+	return policy, ok
+}
+
+// IsTransient reports whether err may succeed if its statement is run
+// again: a serialization failure, a deadlock, or a failed connection
+func IsTransient(err error) bool {
+	return retryable(err, true)
+}
+
+// Retry calls fn until it succeeds, fails with an error policy doesn't
+// retry, or runs out of attempts, waiting between attempts. A transaction
+// that fails with a serialization failure must be retried whole, so fn
+// should begin and commit it. Once attempts run out, a transient error is
+// wrapped in ErrRetriesExhausted.
+func Retry(ctx context.Context, policy RetryPolicy, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || !retryable(err, policy.Idempotent) || ctx.Err() != nil {
+			return err
+		}
+		if attempt >= policy.Attempts {
+			if attempt == 0 {
+				return err
+			}
+			return fmt.Errorf("%w after %d attempts: %w", ErrRetriesExhausted, attempt+1, err)
+		}
+
+		timer := time.NewTimer(backoff(policy.Backoff, attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the wait before retry number attempt+1: base doubled for
+// each earlier retry, less up to half of it at random, so clients that
+// failed together don't retry together
+func backoff(base time.Duration, attempt int) time.Duration {
+	d := base << min(attempt, 10)
+	if d <= 0 {
+		return 0
+	}
+	return d - rand.N(d/2+1)
+}
+
+// retryable reports whether err's statement can run again: always if it
+// was rolled back or never sent, and after a failed connection only if
+// idempotent
+func retryable(err error, idempotent bool) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, pgx.ErrNoRows) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == sqlStateSerializationFailure, pgErr.Code == sqlStateDeadlockDetected:
+			return true
+		case len(pgErr.Code) == 5 && pgErr.Code[:2] == sqlStateClassConnection:
+			return idempotent
+		default:
+			return false
+		}
+	}
+
+	if pgconn.SafeToRetry(err) {
+		return true
+	}
+	if !idempotent {
+		return false
+	}
+	var netErr net.Error
+	var connectErr *pgconn.ConnectError
+	return errors.As(err, &netErr) || errors.As(err, &connectErr) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// retryPolicy returns the policy for queries sent with ctx
+func (p *Pool) retryPolicy(ctx context.Context) RetryPolicy {
+	if policy, ok := RetryPolicyFromContext(ctx); ok {
+		return policy
+	}
+	return RetryPolicy{Attempts: p.config.QueryRetries, Backoff: p.config.QueryRetryBackoff}
+}
+
+// retry runs fn with the policy for ctx, counting retries in the metrics
+func (p *Pool) retry(ctx context.Context, fn func() error) error {
+	attempts := 0
+	return Retry(ctx, p.retryPolicy(ctx), func() error {
+		if attempts++; attempts > 1 {
+			p.metrics.IncrementRetriedQueries()
+		}
+		return fn()
+	})
+}
+
+// retryRow is a row whose Scan runs its query again on transient errors,
+// which pgx.Row only reports once scanned
+type retryRow struct {
+	p    *Pool
+	ctx  context.Context
+	sql  string
+	args []any
+	row  pgx.Row
+}
+
+func (r *retryRow) Scan(dest ...any) error {
+	return r.p.retry(r.ctx, func() error {
+		row := r.row
+		if row == nil {
+			row = r.p.Pool.QueryRow(r.ctx, r.sql, r.args...)
+		}
+		r.row = nil
+		return row.Scan(dest...)
+	})
+}
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// setCurrentUserQuery sets the user the row security policies act for on
+// a connection; an empty value is no user, which reaches only public rows
+const setCurrentUserQuery = "SELECT set_config('app.current_user_id', $1, false)"
+
+// ServerUser is the current user of the queries the server sends for
+// itself, such as background jobs', which the policies let reach every row
+const ServerUser = "server"
+
+type currentUserKey struct{}
+
+// WithCurrentUser returns ctx carrying the user its queries act for. With
+// RowSecurity set, the connection running each query sent with ctx has
+// app.current_user_id set to userID.
+func WithCurrentUser(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, currentUserKey{}, userID)
+}
+
+// AsServer returns ctx marked as acting for the server itself rather than
+// for a user
+func AsServer(ctx context.Context) context.Context {
+	return WithCurrentUser(ctx, ServerUser)
+}
+
+// CurrentUserFromContext returns the user ctx carries, if any
+func CurrentUserFromContext(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(currentUserKey{}).(string)
+	return userID, ok
+}
+
+// prepareRowSecurity sets app.current_user_id on a connection as it's
+// acquired, to the user ctx carries or to none. A connection whose setting
+// can't be written is destroyed rather than reused with a stale user.
+func prepareRowSecurity(ctx context.Context, conn *pgx.Conn) (bool, error) {
+	userID, _ := CurrentUserFromContext(ctx)
+	if _, err := conn.Exec(ctx, setCurrentUserQuery, userID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
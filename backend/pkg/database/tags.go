@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"strings"
+)
+
+// DefaultApplicationName tags queries when DB_APPLICATION_NAME isn't set
+const DefaultApplicationName = "brewd"
+
+// maxTagValueLength bounds each value written into a query's comment
+const maxTagValueLength = 128
+
+// QueryTags identify where the queries sent with a context come from, so
+// slow statements in pg_stat_statements can be traced back to endpoints
+type QueryTags struct {
+	Route     string // Route pattern, e.g. /api/v1/brews/:id
+	RequestID string // Request ID, as logged and returned in X-Request-ID
+	Tenant    string // Organization the request acts in, if any
+}
+
+type queryTagsKey struct{}
+
+// WithQueryTags returns ctx carrying tags for the queries sent with it
+func WithQueryTags(ctx context.Context, tags QueryTags) context.Context {
+	return context.WithValue(ctx, queryTagsKey{}, tags)
+}
+
+// QueryTagsFromContext returns the tags ctx carries, if any
+func QueryTagsFromContext(ctx context.Context) (QueryTags, bool) {
+	tags, ok := ctx.Value(queryTagsKey{}).(QueryTags)
+	return tags, ok
+}
+
+// tag appends a comment naming the application and, from ctx, the route,
+// request ID and tenant to sql, e.g. /* app=brewd route=/api/v1/brews
+// req=... */. Its statement is normalized in pg_stat_statements without the
+// comment, which is kept in the text of the first one recorded.
+func (p *Pool) tag(ctx context.Context, sql string) string {
+	if !p.config.QueryTags {
+		return sql
+	}
+
+	var b strings.Builder
+	b.Grow(len(sql) + 128)
+	b.WriteString(sql)
+	b.WriteString("\n/* app=")
+	b.WriteString(tagValue(p.config.ApplicationName))
+	if tags, ok := QueryTagsFromContext(ctx); ok {
+		if tags.Route != "" {
+			b.WriteString(" route=")
+			b.WriteString(tagValue(tags.Route))
+		}
+		if tags.RequestID != "" {
+			b.WriteString(" req=")
+			b.WriteString(tagValue(tags.RequestID))
+		}
+		if tags.Tenant != "" {
+			b.WriteString(" tenant=")
+			b.WriteString(tagValue(tags.Tenant))
+		}
+	}
+	b.WriteString(" */")
+	return b.String()
+}
+
+// tagValue makes v safe inside a comment: printable ASCII without spaces,
+// with no '*' to end the comment early, and bounded
+func tagValue(v string) string {
+	if len(v) > maxTagValueLength {
+		v = v[:maxTagValueLength]
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || r == '*' {
+			return '_'
+		}
+		return r
+	}, v)
+}
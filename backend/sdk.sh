@@ -0,0 +1,75 @@
+#!/bin/bash
+
+set -e
+
+# Generates the official client SDKs from the OpenAPI document: a Go
+# module in ../sdk/go and an npm package in ../sdk/typescript. With
+# PUBLISH=1 and VERSION set, the Go module is pushed to GO_SDK_REPO tagged
+# v$VERSION and the npm package is published; npm reads its token from
+# .npmrc or NODE_AUTH_TOKEN.
+
+OAPI_CODEGEN="github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen@v2.4.1"
+GO_SDK_MODULE="${GO_SDK_MODULE:-github.com/jwd0526/brewd-go}"
+
+if [ "${PUBLISH:-0}" = "1" ] && [ -z "$VERSION" ]; then
+    echo "VERSION must be set to publish" >&2
+    exit 1
+fi
+
+echo "Generating OpenAPI document..."
+
+go run ./cmd/openapi -o api/openapi.json
+
+echo "Generating Go SDK..."
+
+(
+    cd ../sdk/go
+    rm -f brewd.gen.go go.mod go.sum
+    go run "$OAPI_CODEGEN" -config oapi-codegen.yaml ../../backend/api/openapi.json
+    go mod init "$GO_SDK_MODULE"
+    go mod tidy
+    go vet ./...
+)
+
+echo "Generating TypeScript SDK..."
+
+(
+    cd ../sdk/typescript
+    npm install --no-audit --no-fund
+    npx openapi-typescript ../../backend/api/openapi.json -o src/schema.ts
+    npx tsc
+)
+
+if [ "${PUBLISH:-0}" != "1" ]; then
+    echo "SDKs generated!"
+    exit 0
+fi
+
+if [ -z "$GO_SDK_REPO" ]; then
+    echo "GO_SDK_REPO must be set to publish" >&2
+    exit 1
+fi
+
+echo "Publishing Go SDK v$VERSION..."
+
+checkout=$(mktemp -d)
+trap 'rm -rf "$checkout"' EXIT
+git clone --depth 1 "$GO_SDK_REPO" "$checkout"
+cp ../sdk/go/brewd.gen.go ../sdk/go/go.mod ../sdk/go/go.sum "$checkout"/
+(
+    cd "$checkout"
+    git add brewd.gen.go go.mod go.sum
+    git commit --allow-empty -m "Generate from API v$VERSION"
+    git tag "v$VERSION"
+    git push origin HEAD "v$VERSION"
+)
+
+echo "Publishing TypeScript SDK $VERSION..."
+
+(
+    cd ../sdk/typescript
+    npm version "$VERSION" --no-git-tag-version --allow-same-version
+    npm publish --access public
+)
+
+echo "SDKs published!"
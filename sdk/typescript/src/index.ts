@@ -0,0 +1,18 @@
+// The brewd API client. Its types are generated into schema.ts from the
+// server's OpenAPI document by backend/sdk.sh.
+import createFetchClient, { type ClientOptions } from "openapi-fetch";
+
+import type { components, paths } from "./schema";
+
+export type { components, operations, paths } from "./schema";
+
+/** The envelope of a failed request */
+export type ApiError = components["schemas"]["Error"];
+
+/**
+ * Creates a client for the API at baseUrl. Requests authenticate with the
+ * headers given, e.g. `Authorization: Bearer <token>` or `X-API-Key`.
+ */
+export function createClient(baseUrl: string, options: Omit<ClientOptions, "baseUrl"> = {}) {
+  return createFetchClient<paths>({ ...options, baseUrl });
+}